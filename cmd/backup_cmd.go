@@ -29,6 +29,21 @@ var BackupCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(BackupCmd)
 	BackupCmd.AddCommand(backup_cmd.BackupAllDatabasesCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupGroupCmd)
 	BackupCmd.AddCommand(backup_cmd.BackupSelectionCmd)
 	BackupCmd.AddCommand(backup_cmd.BackupUserCMD)
+	BackupCmd.AddCommand(backup_cmd.BackupScheduleCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupPreflightCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupConvertCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupRekeyCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupSLACmd)
+	BackupCmd.AddCommand(backup_cmd.BackupSnapshotCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupPhysicalCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupServerStateCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupListCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupShowCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupPruneCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupPinCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupUnpinCmd)
+	BackupCmd.AddCommand(backup_cmd.BackupVerifyCmd)
 }
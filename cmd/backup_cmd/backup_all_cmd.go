@@ -1,12 +1,11 @@
 package backup_cmd
 
 import (
-	"os"
-
 	"sfDBTools/internal/config"
 	"sfDBTools/internal/core/backup/all_databases/mysqldump"
 	"sfDBTools/internal/logger"
 	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/maintenance"
 
 	"github.com/spf13/cobra"
 )
@@ -48,18 +47,24 @@ sfDBTools backup all --source_host localhost --source_user root --output-dir ./b
 sfDBTools backup all --source_host localhost --source_user root --encrypt
 
 # Backup schema only (no data)
-sfDBTools backup all --source_host localhost --source_user root --data=false`,
+sfDBTools backup all --source_host localhost --source_user root --data=false
+
+# Backup a production server that must never be written to
+sfDBTools backup all --source_host prod-db --source_user backup --source-protect
+
+# Pass extra mysqldump options (recorded in the manifest under dump_args)
+sfDBTools backup all --source_host localhost --source_user root --dump-arg=--hex-blob --dump-arg=--skip-dump-date`,
 
 	Annotations: map[string]string{
 		"command":  "backup",
 		"category": "backup",
 	},
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := executeAllDatabasesBackup(cmd, Lg); err != nil {
-			lg, _ := logger.Get()
-			lg.Error("All databases backup failed", logger.Error(err))
-			os.Exit(1)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, err := maintenance.Enter(cmd, "backup-all")
+		if err != nil {
+			return err
 		}
+		return session.Close(executeAllDatabasesBackup(cmd, Lg))
 	},
 }
 
@@ -74,6 +79,8 @@ func executeAllDatabasesBackup(cmd *cobra.Command, lg *logger.Logger) error {
 func init() {
 	// Add all common backup flags
 	backup_utils.AddCommonBackupFlags(BackupAllDatabasesCmd)
+	backup_utils.AddSourceProtectFlags(BackupAllDatabasesCmd)
+	backup_utils.AddDumpArgFlag(BackupAllDatabasesCmd)
 
 	// Additional backup options specific to all databases backup
 	_, _, _, _,
@@ -0,0 +1,120 @@
+package backup_cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/compression"
+	"sfDBTools/utils/fs/policy"
+	"sfDBTools/utils/sanitize"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+var BackupConvertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Write a copy of a dump that loads on MySQL or older servers",
+	Long: `Rewrite MariaDB-specific syntax in a SQL dump and write the result to a new file.
+
+The same sanitizer runs during 'restore single --sanitize'. It removes the
+sandbox-mode directive, comments out sequences and their SETVAL calls, drops
+nextval() defaults, converts engines such as Aria or ColumnStore to --engine,
+removes page compression, encryption and system versioning table options, and
+maps uca1400 collations and the utf8mb3 charset name to names MySQL and older
+servers know. Row data is copied unchanged.
+
+The input may be compressed; the output is compressed according to its file
+extension (.gz, .zst, .zlib). Encrypted backups must be restored or decrypted first.`,
+	Example: `sfDBTools backup convert --file ./backup/app.sql.gz
+sfDBTools backup convert --file ./backup/app.sql.gz --output ./export/app_mysql.sql.gz --engine MyISAM`,
+	Annotations: map[string]string{
+		"command":  "backup",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeBackupConvert(cmd)
+	},
+}
+
+func init() {
+	BackupConvertCmd.Flags().String("file", "", "dump file to convert (required)")
+	BackupConvertCmd.Flags().String("output", "", "converted file (default: <file>.sanitized with the same extension)")
+	BackupConvertCmd.Flags().String("engine", "InnoDB", "engine replacing non-portable storage engines")
+}
+
+func executeBackupConvert(cmd *cobra.Command) error {
+	lg, _ := logger.Get()
+	input := common.GetStringFlagOrEnv(cmd, "file", "BACKUP_FILE", "")
+	if input == "" {
+		return common.WithExitCode(fmt.Errorf("--file is required"), common.ExitUsage)
+	}
+	if strings.HasSuffix(strings.ToLower(input), ".enc") {
+		return common.WithExitCode(fmt.Errorf("%s is encrypted; convert an unencrypted dump", input), common.ExitUsage)
+	}
+	output := common.GetStringFlagOrEnv(cmd, "output", "BACKUP_CONVERT_OUTPUT", sanitizedName(input))
+	if output == input {
+		return common.WithExitCode(fmt.Errorf("--output must differ from --file"), common.ExitUsage)
+	}
+	engine, _ := cmd.Flags().GetString("engine")
+
+	terminal.Headers("Backup Tools - Convert Dump")
+	terminal.PrintInfo(fmt.Sprintf("Converting %s -> %s", input, output))
+
+	in, err := os.Open(input)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", input, err)
+	}
+	defer in.Close()
+	reader, _, err := compression.NewAutoDecompressingReader(in, compression.DetectCompressionTypeFromFile(input))
+	if err != nil {
+		return fmt.Errorf("failed to decompress %s: %w", input, err)
+	}
+	defer reader.Close()
+	sanitizer := sanitize.NewReader(reader, engine)
+
+	out, err := policy.CreateFile(output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	writer, err := compression.NewCompressingWriter(out, compression.CompressionConfig{
+		Type:  compression.DetectCompressionTypeFromFile(output),
+		Level: compression.LevelDefault,
+	})
+	if err != nil {
+		out.Close()
+		os.Remove(output)
+		return err
+	}
+
+	written, err := io.Copy(writer, sanitizer)
+	if err == nil {
+		err = writer.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(output)
+		return fmt.Errorf("conversion failed: %w", err)
+	}
+
+	lg.Info("Dump converted", logger.String("input", input), logger.String("output", output), logger.Int64("bytes", written))
+	sanitize.Display(sanitizer.Report(), lg)
+	warnings.PrintSummary()
+	terminal.PrintSuccess(fmt.Sprintf("Converted dump written to %s (%s uncompressed)", output, common.FormatSize(written)))
+	return nil
+}
+
+// sanitizedName inserts ".sanitized" before the .sql extension of path
+func sanitizedName(path string) string {
+	if i := strings.LastIndex(strings.ToLower(path), ".sql"); i >= 0 {
+		return path[:i] + ".sanitized" + path[i:]
+	}
+	return path + ".sanitized"
+}
@@ -0,0 +1,211 @@
+package backup_cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"sfDBTools/internal/config"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/backup/catalog"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/common/flags"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+// BackupListCmd lists the backups recorded in the backup catalog
+var BackupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the backups recorded in the backup catalog",
+	Long: `List the backups recorded in the backup catalog, newest first, with their size,
+duration, encryption, checksum and retention expiry.
+
+Every backup that writes a manifest is recorded in the catalog
+(backup.catalog.path, by default catalog.db in the backup directory). Backups
+taken before the catalog existed are added with 'sfDBTools catalog index'.
+
+The State column shows whether the backup file is still on disk and whether its
+retention has passed. --sort orders the table by any column (sizes and numbers
+by value), --page-size and --page print one page of a long list and --pager
+scrolls through it on a terminal.`,
+	Example: `sfDBTools backup list
+sfDBTools backup list --database appdb --since 7d
+sfDBTools backup list --type all_databases --limit 10 --json
+sfDBTools backup list --expired
+sfDBTools backup list --limit 0 --sort size --desc --pager`,
+	Annotations: map[string]string{
+		"command":  "backup",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeBackupList(cmd)
+	},
+}
+
+func executeBackupList(cmd *cobra.Command) error {
+	filter := catalog.Filter{
+		Database: common.GetStringFlagOrEnv(cmd, "database", "BACKUP_LIST_DATABASE", ""),
+		Type:     common.GetStringFlagOrEnv(cmd, "type", "BACKUP_LIST_TYPE", ""),
+	}
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		d, err := common.ParseDurationWithDays(since)
+		if err != nil {
+			return common.WithExitCode(fmt.Errorf("invalid --since %q: %w", since, err), common.ExitUsage)
+		}
+		filter.Since = time.Now().Add(-d)
+	}
+	expiredOnly, _ := cmd.Flags().GetBool("expired")
+	if !expiredOnly {
+		filter.Limit, _ = cmd.Flags().GetInt("limit")
+	}
+
+	cat, path, err := openBackupCatalog(cmd)
+	if err != nil {
+		return err
+	}
+	entries, err := cat.List(filter)
+	cat.Close()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if expiredOnly {
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.Expired(now) && !e.Pinned {
+				kept = append(kept, e)
+			}
+		}
+		entries = kept
+		if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 && len(entries) > limit {
+			entries = entries[:limit]
+		}
+	}
+
+	if output.JSON() {
+		if entries == nil {
+			entries = []catalog.Entry{}
+		}
+		output.SetResult(entries)
+		return nil
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		warnings.Take()
+		if entries == nil {
+			entries = []catalog.Entry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode backup list: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	terminal.Headers("Backup - List")
+	if len(entries) == 0 {
+		terminal.PrintInfo(fmt.Sprintf("No matching backups in %s", path))
+		return nil
+	}
+	headers := []string{"ID", "Date", "Database", "Type", "Size", "Duration", "Encrypted", "Checksum", "Expires", "State"}
+	rows := make([][]string, 0, len(entries))
+	var total int64
+	for i := range entries {
+		e := &entries[i]
+		total += e.Size
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", e.ID),
+			e.BackupDate.Local().Format("2006-01-02 15:04"),
+			e.Database,
+			e.Type,
+			common.FormatSize(e.Size),
+			common.HumanizeDuration(e.Duration.Round(time.Second)),
+			yesNo(e.Encrypted),
+			shortChecksum(e),
+			expiresCell(e),
+			stateCell(e, now),
+		})
+	}
+	if err := terminal.RenderTable(headers, rows, common.GetTableOptions(cmd)); err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	terminal.PrintInfo(fmt.Sprintf("%d backup(s), %s in total. Details: sfDBTools backup show <id>", len(entries), common.FormatSize(total)))
+	return nil
+}
+
+// openBackupCatalog opens --catalog-db, or the catalog configured in config.yaml
+func openBackupCatalog(cmd *cobra.Command) (*catalog.Catalog, string, error) {
+	cfg, err := config.Get()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+	path := common.GetStringFlagOrEnv(cmd, "catalog-db", "BACKUP_CATALOG", backup_utils.CatalogPath(cfg))
+	if path == "" {
+		return nil, "", common.WithExitCode(fmt.Errorf("backup catalog is not set; use --catalog-db, backup.catalog.path or backup.storage.base_directory"), common.ExitConfig)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, "", fmt.Errorf("backup catalog %s does not exist yet; run a backup or 'sfDBTools catalog index'", path)
+	}
+	cat, err := catalog.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return cat, path, nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func shortChecksum(e *catalog.Entry) string {
+	if e.Checksum == "" {
+		return "-"
+	}
+	if len(e.Checksum) > 12 {
+		return e.Checksum[:12]
+	}
+	return e.Checksum
+}
+
+func expiresCell(e *catalog.Entry) string {
+	if e.Pinned {
+		return "pinned"
+	}
+	if e.ExpiresAt.IsZero() {
+		return "never"
+	}
+	return e.ExpiresAt.Local().Format("2006-01-02")
+}
+
+// stateCell tells whether the backup file is still there and within retention
+func stateCell(e *catalog.Entry, now time.Time) string {
+	if _, err := os.Stat(e.File); os.IsNotExist(err) {
+		if e.RemoteKey != "" {
+			return terminal.ColorText("remote", terminal.ColorBlue)
+		}
+		return terminal.ColorText("missing", terminal.ColorRed)
+	}
+	if e.Expired(now) && !e.Pinned {
+		return terminal.ColorText("expired", terminal.ColorYellow)
+	}
+	return terminal.ColorText("ok", terminal.ColorGreen)
+}
+
+func init() {
+	BackupListCmd.Flags().String("database", "", "only backups of this database (or group name)")
+	BackupListCmd.Flags().String("type", "", "only backups of this type: single, all_databases, consistency_group or snapshot")
+	BackupListCmd.Flags().String("since", "", "only backups taken within this period, e.g. 7d or 12h")
+	BackupListCmd.Flags().Int("limit", 50, "show at most this many backups (0 for all)")
+	BackupListCmd.Flags().Bool("expired", false, "only unpinned backups whose retention has passed")
+	BackupListCmd.Flags().String("catalog-db", "", "backup catalog file (default: backup.catalog.path or catalog.db in the backup directory)")
+	BackupListCmd.Flags().Bool("json", false, "print the backups as JSON")
+	flags.AddTableFlags(BackupListCmd)
+}
@@ -0,0 +1,73 @@
+package backup_cmd
+
+import (
+	"fmt"
+
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// BackupPinCmd protects a backup from retention pruning
+var BackupPinCmd = &cobra.Command{
+	Use:   "pin <id|file|manifest>",
+	Short: "Protect a backup from retention pruning",
+	Long: `Pin a backup so 'backup prune' and the cleanup after every backup keep it,
+whatever its retention. The pin is stored in the manifest and the backup
+catalog. Use 'backup unpin' to let retention apply again.`,
+	Example: `sfDBTools backup pin 42 --reason "before the 2025 migration"
+sfDBTools backup pin appdb_20250101_020000_full.sql.gz.enc`,
+	Args: cobra.ExactArgs(1),
+	Annotations: map[string]string{
+		"command":  "backup",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reason, _ := cmd.Flags().GetString("reason")
+		return executeBackupPin(cmd, args[0], true, reason)
+	},
+}
+
+// BackupUnpinCmd lets retention apply to a pinned backup again
+var BackupUnpinCmd = &cobra.Command{
+	Use:   "unpin <id|file|manifest>",
+	Short: "Let retention pruning apply to a pinned backup again",
+	Args:  cobra.ExactArgs(1),
+	Annotations: map[string]string{
+		"command":  "backup",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeBackupPin(cmd, args[0], false, "")
+	},
+}
+
+func executeBackupPin(cmd *cobra.Command, ref string, pinned bool, reason string) error {
+	cat, _, err := openBackupCatalog(cmd)
+	if err != nil {
+		return err
+	}
+	defer cat.Close()
+	e, err := cat.Find(ref)
+	if err != nil {
+		return err
+	}
+	if err := backup_utils.SetPinned(cat, e, pinned, reason); err != nil {
+		return err
+	}
+	if pinned {
+		terminal.PrintSuccess(fmt.Sprintf("Backup %d (%s, %s) is pinned", e.ID, e.Database, e.BackupDate.Local().Format("2006-01-02 15:04")))
+	} else {
+		terminal.PrintSuccess(fmt.Sprintf("Backup %d (%s, %s) is no longer pinned; it expires %s", e.ID, e.Database,
+			e.BackupDate.Local().Format("2006-01-02 15:04"), expiresCell(e)))
+	}
+	return nil
+}
+
+func init() {
+	BackupPinCmd.Flags().String("reason", "", "why the backup is kept, shown by 'backup show'")
+	for _, c := range []*cobra.Command{BackupPinCmd, BackupUnpinCmd} {
+		c.Flags().String("catalog-db", "", "backup catalog file (default: backup.catalog.path or catalog.db in the backup directory)")
+	}
+}
@@ -0,0 +1,74 @@
+package backup_cmd
+
+import (
+	"fmt"
+	"strings"
+
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+var BackupPreflightCmd = &cobra.Command{
+	Use:   "preflight",
+	Short: "Check that the backup user holds the privileges a backup needs",
+	Long: `Verify the configured backup user holds SELECT, LOCK TABLES (or BACKUP_ADMIN),
+SHOW VIEW, TRIGGER, EVENT, RELOAD and REPLICATION CLIENT (BINLOG MONITOR on
+MariaDB 10.5+), and print the GRANT statements that add any missing privilege.
+
+Without --source_db the privileges are checked for a backup of all databases.
+Exits with status 1 when a privilege is missing.`,
+	Example: `sfDBTools backup preflight --config ./config/mydb.cnf.enc
+sfDBTools backup preflight --config ./config/mydb.cnf.enc --source_db app,billing`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeBackupPreflight(cmd)
+	},
+}
+
+func init() {
+	BackupPreflightCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc)")
+	BackupPreflightCmd.Flags().String("source_host", "", "database host")
+	BackupPreflightCmd.Flags().Int("source_port", 0, "database port")
+	BackupPreflightCmd.Flags().String("source_user", "", "backup user")
+	BackupPreflightCmd.Flags().String("source_password", "", "backup user password")
+	BackupPreflightCmd.Flags().String("source_db", "", "comma-separated databases to check (default: all databases)")
+}
+
+func executeBackupPreflight(cmd *cobra.Command) error {
+	terminal.Headers("Backup Tools - Privilege Preflight")
+
+	host, port, user, password, _, err := backup_utils.ResolveBackupConnection(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database connection: %w", err)
+	}
+	var databases []string
+	for _, name := range strings.Split(common.GetStringFlagOrEnv(cmd, "source_db", "SOURCE_DB", ""), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			databases = append(databases, name)
+		}
+	}
+
+	db, err := database.GetWithoutDB(database.Config{Host: host, Port: port, User: user, Password: password})
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s:%d: %w", host, port, err)
+	}
+	defer db.Close()
+
+	report, err := backup_utils.CheckBackupPrivileges(db, databases)
+	if err != nil {
+		return err
+	}
+	if len(databases) == 0 {
+		terminal.PrintInfo(fmt.Sprintf("Checking %s for a backup of all databases", report.Account))
+	} else {
+		terminal.PrintInfo(fmt.Sprintf("Checking %s for a backup of %s", report.Account, strings.Join(databases, ", ")))
+	}
+	backup_utils.DisplayPrivilegeReport(report)
+	if !report.OK() {
+		return fmt.Errorf("%d privilege(s) missing for %s", len(report.Missing), report.Account)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package backup_cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"sfDBTools/internal/config"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// BackupPruneCmd deletes backups whose retention has passed
+var BackupPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete backups whose retention has passed, locally and in remote storage",
+	Long: `Delete the backups of the backup catalog whose retention has passed: the backup
+file, its manifest, their copies in remote storage (backup.remote) and the
+catalog entry.
+
+A backup expires at its backup date plus the retention it was taken with
+(--retention-days / backup.retention.days). --retention-days here applies one
+retention to every backup instead.
+
+Kept regardless of expiry:
+  - pinned backups ('sfDBTools backup pin')
+  - the newest backup of every database, so a database whose backups stopped
+    running still has one to restore
+
+Backups also prune after every run when backup.retention.cleanup_enabled is
+set. Use --dry-run to see what would be deleted.`,
+	Example: `sfDBTools backup prune --dry-run
+sfDBTools backup prune --yes
+sfDBTools backup prune --database appdb --retention-days 14
+sfDBTools backup prune --local-only`,
+	Annotations: map[string]string{
+		"command":  "backup",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeBackupPrune(cmd)
+	},
+}
+
+func executeBackupPrune(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	opts := backup_utils.PruneOptions{
+		RetentionDays: common.GetIntFlagOrEnv(cmd, "retention-days", "PRUNE_RETENTION_DAYS", 0),
+		Database:      common.GetStringFlagOrEnv(cmd, "database", "PRUNE_DATABASE", ""),
+		BaseDir:       common.GetStringFlagOrEnv(cmd, "backup-dir", "BACKUP_DIR", cfg.Backup.Storage.BaseDirectory),
+		DryRun:        common.GetBoolFlagOrEnv(cmd, "dry-run", "PRUNE_DRY_RUN", false),
+	}
+	if opts.RetentionDays < 0 {
+		return common.WithExitCode(fmt.Errorf("--retention-days must not be negative"), common.ExitUsage)
+	}
+	localOnly, _ := cmd.Flags().GetBool("local-only")
+	if cfg.Backup.Remote.Bucket != "" && !localOnly {
+		if opts.Remote, err = backup_utils.NewRemoteStore(cfg.Backup.Remote); err != nil {
+			return fmt.Errorf("%w (use --local-only to prune local files only)", err)
+		}
+	}
+
+	cat, path, err := openBackupCatalog(cmd)
+	if err != nil {
+		return err
+	}
+	defer cat.Close()
+
+	terminal.Headers("Backup - Prune")
+	// A dry run first, so the confirmation lists exactly what will be deleted
+	preview := opts
+	preview.DryRun = true
+	plan, err := backup_utils.Prune(cat, preview)
+	if err != nil {
+		return err
+	}
+	for _, e := range plan.Pinned {
+		terminal.PrintInfo(fmt.Sprintf("Keeping pinned backup %d (%s, %s)", e.ID, e.Database, e.BackupDate.Local().Format("2006-01-02")))
+	}
+	if len(plan.Pruned) == 0 {
+		terminal.PrintSuccess(fmt.Sprintf("No expired backups in %s", path))
+		return nil
+	}
+	printPruneTable(plan)
+	if opts.DryRun {
+		terminal.PrintInfo(fmt.Sprintf("Dry run: %d backup(s), %s would be deleted", len(plan.Pruned), common.FormatSize(plan.Freed)))
+		return nil
+	}
+
+	skipConfirm := terminal.AssumeYes()
+	if !skipConfirm {
+		summary := terminal.OperationPlan{
+			Operation: "Backup Prune",
+			Source:    path,
+			Target:    "backup files, manifests and catalog entries",
+			Destructive: []string{
+				fmt.Sprintf("Delete %d expired backup(s), %s", len(plan.Pruned), common.FormatSize(plan.Freed)),
+			},
+			Backup: terminal.BackupSafety{
+				Protected: true,
+				Detail:    "pinned backups and the newest backup of every database are kept",
+			},
+		}
+		if opts.Remote != nil {
+			summary.Destructive = append(summary.Destructive, "Delete their copies in remote storage")
+		}
+		if !terminal.ConfirmPlan(summary, "Delete expired backups?") {
+			return common.WithExitCode(errors.New("prune cancelled by user"), common.ExitCancelled)
+		}
+	}
+
+	result, err := backup_utils.Prune(cat, opts)
+	if err != nil {
+		return err
+	}
+	kept := 0
+	for _, item := range result.Pruned {
+		if item.RemoteKept {
+			kept++
+		}
+	}
+	if kept > 0 {
+		terminal.PrintWarning(fmt.Sprintf("%d backup(s) have a remote copy that was kept; their catalog entries stay", kept))
+	}
+	if failed := result.Failed(); len(failed) > 0 {
+		for _, item := range failed {
+			terminal.PrintError(fmt.Sprintf("Backup %d: %v", item.Entry.ID, item.Err))
+		}
+		return fmt.Errorf("%d of %d expired backup(s) could not be deleted", len(failed), len(result.Pruned))
+	}
+	terminal.PrintSuccess(fmt.Sprintf("%d expired backup(s) deleted, %s freed", len(result.Pruned), common.FormatSize(result.Freed)))
+	return nil
+}
+
+func printPruneTable(result *backup_utils.PruneResult) {
+	rows := make([][]string, 0, len(result.Pruned))
+	for _, item := range result.Pruned {
+		e := item.Entry
+		remote := "-"
+		if e.RemoteKey != "" {
+			remote = e.RemoteKey
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", e.ID),
+			e.BackupDate.Local().Format("2006-01-02 15:04"),
+			e.Database,
+			common.FormatSize(e.Size),
+			item.ExpiredAt.Local().Format("2006-01-02"),
+			e.File,
+			remote,
+		})
+	}
+	terminal.FormatTable([]string{"ID", "Date", "Database", "Size", "Expired", "File", "Remote"}, rows)
+}
+
+func init() {
+	BackupPruneCmd.Flags().Bool("dry-run", false, "list the expired backups without deleting them")
+	BackupPruneCmd.Flags().Int("retention-days", 0, "apply this retention to every backup instead of the one it was taken with")
+	BackupPruneCmd.Flags().String("database", "", "only prune backups of this database (or group name)")
+	BackupPruneCmd.Flags().Bool("local-only", false, "do not delete copies in remote storage")
+	BackupPruneCmd.Flags().String("backup-dir", "", "backup directory (default: backup.storage.base_directory)")
+	BackupPruneCmd.Flags().String("catalog-db", "", "backup catalog file (default: backup.catalog.path or catalog.db in the backup directory)")
+}
@@ -0,0 +1,133 @@
+package backup_cmd
+
+import (
+	"fmt"
+	"os"
+
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/backup/catalog"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// BackupRekeyCmd rotates the encryption key of encrypted backups
+var BackupRekeyCmd = &cobra.Command{
+	Use:   "rekey <id|file|manifest>...",
+	Short: "Rotate the encryption password or key file of encrypted backups",
+	Long: `Make encrypted backups readable with a new password or key file.
+
+Backups are encrypted with a random data key, which the file header stores
+wrapped under a key derived from the password (PBKDF2-SHA512) or key file
+(HKDF-SHA256). Rekeying unwraps the data key with the current secret and wraps
+it with the new one, so only the header is rewritten and the data is copied
+unchanged. Backups written before this format are decrypted and encrypted
+again completely.
+
+The current password comes from SFDB_ENCRYPTION_PASSWORD or a prompt, the new
+one from SFDB_NEW_ENCRYPTION_PASSWORD or a prompt asked twice. Key files must
+hold at least 32 bytes and be readable by their owner only. Each file is
+replaced atomically; the size and checksum in its manifest and catalog entry
+are updated when the backup is in the catalog.`,
+	Example: `sfDBTools backup rekey ./backup/appdb_20250101_020000.sql.gz.enc
+sfDBTools backup rekey 41 42 --new-key-file /etc/sfdbtools/backup.key
+sfDBTools backup rekey appdb.sql.gz.enc --key-file /etc/sfdbtools/old.key --new-key-file /etc/sfdbtools/backup.key`,
+	Args: cobra.MinimumNArgs(1),
+	Annotations: map[string]string{
+		"command":  "backup",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeBackupRekey(cmd, args)
+	},
+}
+
+func init() {
+	BackupRekeyCmd.Flags().String("key-file", "", "key file the backups are encrypted with (default: SFDB_ENCRYPTION_KEY_FILE, else a password)")
+	BackupRekeyCmd.Flags().String("new-key-file", "", "key file to encrypt the backups with instead of a new password")
+	BackupRekeyCmd.Flags().String("catalog-db", "", "backup catalog file (default: backup.catalog.path or <base_directory>/catalog.db)")
+}
+
+func executeBackupRekey(cmd *cobra.Command, refs []string) error {
+	lg, _ := logger.Get()
+	keyFile := common.GetStringFlagOrEnv(cmd, "key-file", crypto.ENV_ENCRYPTION_KEY_FILE, "")
+	newKeyFile, _ := cmd.Flags().GetString("new-key-file")
+	if newKeyFile != "" && newKeyFile == keyFile {
+		return common.WithExitCode(fmt.Errorf("--new-key-file must differ from the current key file"), common.ExitUsage)
+	}
+
+	terminal.Headers("Backup Tools - Rekey")
+
+	// Backups outside the catalog can still be rekeyed by file
+	cat, _, err := openBackupCatalog(cmd)
+	if err != nil {
+		lg.Debug("Rekeying without the backup catalog", logger.Error(err))
+		cat = nil
+	} else {
+		defer cat.Close()
+	}
+	type target struct {
+		file  string
+		entry *catalog.Entry
+	}
+	targets := make([]target, 0, len(refs))
+	for _, ref := range refs {
+		t := target{file: ref}
+		if cat != nil {
+			if e, err := cat.Find(ref); err == nil {
+				t = target{file: e.File, entry: e}
+			}
+		}
+		if _, err := os.Stat(t.file); err != nil {
+			return common.WithExitCode(fmt.Errorf("backup %s not found: %w", ref, err), common.ExitUsage)
+		}
+		targets = append(targets, t)
+	}
+
+	oldSecret, err := crypto.GetEncryptionSecret("Enter current encryption password: ", keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to get encryption password: %w", err)
+	}
+	newSecret := crypto.Secret{KeyFile: newKeyFile}
+	if newKeyFile == "" {
+		if newSecret.Passphrase, err = crypto.GetNewEncryptionPassword("Enter new encryption password: "); err != nil {
+			return fmt.Errorf("failed to get new encryption password: %w", err)
+		}
+	}
+
+	rows := make([][]string, 0, len(targets))
+	var failed int
+	for _, t := range targets {
+		result, err := crypto.RekeyFile(t.file, oldSecret, newSecret)
+		if err != nil {
+			failed++
+			lg.Error("Rekey failed", logger.String("file", t.file), logger.Error(err))
+			rows = append(rows, []string{t.file, "-", "-", "failed: " + err.Error()})
+			continue
+		}
+		status := "header rewritten"
+		if result.Reencrypted {
+			status = "re-encrypted"
+		}
+		if t.entry != nil {
+			if err := backup_utils.RecordRewrite(cat, t.entry); err != nil {
+				lg.Warn("Manifest not updated after rekey", logger.String("file", t.file), logger.Error(err))
+				status += ", manifest not updated"
+			}
+		} else if cat != nil {
+			status += ", not in catalog"
+		}
+		lg.Info("Backup rekeyed", logger.String("file", t.file), logger.String("old_key_id", result.OldKeyID), logger.String("new_key_id", result.NewKeyID))
+		rows = append(rows, []string{t.file, result.OldKeyID, result.NewKeyID, status})
+	}
+
+	terminal.FormatTable([]string{"File", "Old key ID", "New key ID", "Status"}, rows)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d backups could not be rekeyed", failed, len(targets))
+	}
+	terminal.PrintSuccess(fmt.Sprintf("%d backups now use the %s", len(targets), newSecret))
+	return nil
+}
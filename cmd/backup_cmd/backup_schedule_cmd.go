@@ -0,0 +1,230 @@
+package backup_cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/config/model"
+	"sfDBTools/internal/core/backup/schedule"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/maintenance"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// BackupScheduleCmd groups commands that hand backup scheduling over to the OS
+var BackupScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage OS-native schedules for configured backups",
+	Long: `Manage OS-native scheduling for the schedules listed under backup.schedules in config.yaml.
+Each enabled schedule becomes a systemd service (running 'sfDBTools backup <command>',
+or 'sfDBTools restore drill' for command: drill) and a timer that triggers it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+	Annotations: map[string]string{
+		"command":  "backup",
+		"category": "backup",
+	},
+}
+
+var installSystemdCmd = &cobra.Command{
+	Use:   "install-systemd",
+	Short: "Install systemd service and timer units for each configured schedule",
+	Long: `Render a systemd service and timer for every enabled schedule in backup.schedules,
+install them into /etc/systemd/system, then enable and start the timers.
+
+Services run from the directory that holds the active config/ folder so the same
+config.yaml is used. Secrets such as SFDB_ENCRYPTION_PASSWORD can be placed in
+/etc/sfDBTools/schedule.env, which every service loads when present.
+
+Calendars are pinned to each schedule's timezone (backup.schedules[].timezone,
+defaulting to general.locale.timezone). When an upcoming DST change skips or
+repeats a schedule's time, the timer also fires at the end of the skipped hour
+and the service is guarded so the backup runs exactly once that day.`,
+	Example: `sfDBTools backup schedule install-systemd --dry-run
+sfDBTools backup schedule install-systemd`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeInstallSystemd(cmd)
+	},
+}
+
+var uninstallSystemdCmd = &cobra.Command{
+	Use:   "uninstall-systemd",
+	Short: "Stop, disable and remove all sfDBTools backup systemd units",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removed, err := schedule.UninstallSystemd()
+		if err != nil {
+			return fmt.Errorf("failed to uninstall systemd units: %w", err)
+		}
+		if len(removed) == 0 {
+			terminal.PrintInfo("No sfDBTools backup units installed")
+			return nil
+		}
+		for _, name := range removed {
+			terminal.PrintInfo(fmt.Sprintf("Removed %s", name))
+		}
+		terminal.PrintSuccess(fmt.Sprintf("Removed %d systemd unit(s)", len(removed)))
+		return nil
+	},
+}
+
+func executeInstallSystemd(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	binary, _ := cmd.Flags().GetString("binary")
+	if binary == "" {
+		if binary, err = os.Executable(); err != nil {
+			return fmt.Errorf("failed to determine executable path: %w", err)
+		}
+	}
+	if binary, err = filepath.Abs(binary); err != nil {
+		return fmt.Errorf("failed to resolve binary path: %w", err)
+	}
+
+	workDir, _ := cmd.Flags().GetString("workdir")
+	if workDir == "" && config.Dir() != "" {
+		workDir = filepath.Dir(config.Dir())
+	}
+
+	units, err := schedule.RenderAll(cfg.Backup.Schedules, schedule.UnitOptions{
+		BinaryPath: binary,
+		WorkDir:    workDir,
+		Timezone:   cfg.General.Locale.Timezone,
+	})
+	if err != nil {
+		return err
+	}
+	if len(units) == 0 {
+		terminal.PrintWarning("No enabled schedules found under backup.schedules in config.yaml")
+		return nil
+	}
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		for _, u := range units {
+			fmt.Printf("# %s\n%s\n# %s\n%s\n", u.ServiceName, u.Service, u.TimerName, u.Timer)
+		}
+		printDSTIssues(units)
+		printWindowConflicts(cfg, units)
+		return nil
+	}
+
+	if err := schedule.InstallSystemd(units); err != nil {
+		return err
+	}
+
+	headers := []string{"Schedule", "Command", "OnCalendar", "Timezone", "Timer"}
+	rows := make([][]string, 0, len(units))
+	for _, u := range units {
+		timezone := u.Timezone
+		if timezone == "" {
+			timezone = "server local"
+		}
+		rows = append(rows, []string{u.Schedule.Name, u.Schedule.Command, strings.Join(u.OnCalendar, ", "), timezone, u.TimerName})
+	}
+	terminal.FormatTable(headers, rows)
+	printDSTIssues(units)
+	printWindowConflicts(cfg, units)
+	terminal.PrintSuccess(fmt.Sprintf("Installed and enabled %d backup timer(s)", len(units)))
+	terminal.PrintInfo("Check upcoming runs with: systemctl list-timers 'sfdbtools-backup-*'")
+	return nil
+}
+
+// Exit codes systemd's ExecCondition understands: 1 skips the unit, 255 fails it.
+const (
+	guardSkip   = 1
+	guardFailed = 255
+)
+
+var guardCmd = &cobra.Command{
+	Use:    "guard",
+	Short:  "Decide whether a DST-guarded schedule may run (used by generated units)",
+	Hidden: true,
+	Long: `Used as ExecCondition by services whose schedule is affected by a DST change.
+Exits 0 when the schedule has not run yet on the current local day in its
+timezone, and 1 (skip) otherwise, so a backup neither runs twice when clocks go
+back nor is lost when its time is skipped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Get()
+		if err != nil {
+			return common.WithExitCode(fmt.Errorf("failed to load config: %w", err), guardFailed)
+		}
+
+		name, _ := cmd.Flags().GetString("name")
+		timezone, _ := cmd.Flags().GetString("timezone")
+		s := model.BackupSchedule{Name: name, Timezone: timezone}
+		for _, configured := range cfg.Backup.Schedules {
+			if configured.Name == name {
+				s = configured
+				if timezone != "" {
+					s.Timezone = timezone
+				}
+				break
+			}
+		}
+		if timezone, err = schedule.ScheduleTimezone(s, cfg.General.Locale.Timezone); err != nil {
+			return common.WithExitCode(fmt.Errorf("schedule guard failed: %w", err), guardFailed)
+		}
+
+		allowed, err := schedule.Guard(s, timezone, time.Now())
+		if err != nil {
+			return common.WithExitCode(fmt.Errorf("schedule guard failed for %s: %w", name, err), guardFailed)
+		}
+		if !allowed {
+			return common.WithExitCode(fmt.Errorf("schedule %s already ran today in %s, skipping", name, timezone), guardSkip)
+		}
+		return nil
+	},
+}
+
+// printDSTIssues warns about schedules whose time is skipped or repeated by an upcoming DST change
+func printDSTIssues(units []*schedule.Units) {
+	for _, u := range units {
+		for _, issue := range u.DSTIssues {
+			terminal.PrintWarning(fmt.Sprintf("Schedule %s (%s): %s; guarded to run once that day", u.Schedule.Name, u.Timezone, issue))
+		}
+	}
+}
+
+func init() {
+	installSystemdCmd.Flags().String("binary", "", "path of the sfDBTools binary used by the units (default: current executable)")
+	installSystemdCmd.Flags().String("workdir", "", "working directory for the units (default: parent of the active config directory)")
+	installSystemdCmd.Flags().Bool("dry-run", false, "print the rendered units without installing them")
+
+	BackupScheduleCmd.AddCommand(installSystemdCmd)
+	BackupScheduleCmd.AddCommand(uninstallSystemdCmd)
+
+	guardCmd.Flags().String("name", "", "schedule name")
+	guardCmd.Flags().String("timezone", "", "timezone the schedule runs in")
+	guardCmd.MarkFlagRequired("name")
+	BackupScheduleCmd.AddCommand(guardCmd)
+}
+
+// printWindowConflicts warns about schedules whose time never falls inside a
+// maintenance window; such runs are refused or deferred when they trigger
+func printWindowConflicts(cfg *model.Config, units []*schedule.Units) {
+	windows, err := maintenance.Load(cfg.Maintenance, cfg.General.Locale.Timezone)
+	if err != nil {
+		terminal.PrintWarning(err.Error())
+		return
+	}
+	if !windows.Enabled() {
+		return
+	}
+	for _, u := range units {
+		clock := schedule.CalendarClock(u.Schedule.OnCalendar)
+		if clock == "" || windows.CoversClock(clock) {
+			continue
+		}
+		terminal.PrintWarning(fmt.Sprintf("Schedule %s runs at %s, outside the maintenance windows (%s); it will be refused or deferred unless its args include --ignore-window",
+			u.Schedule.Name, clock, windows))
+	}
+}
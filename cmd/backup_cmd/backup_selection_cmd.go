@@ -2,13 +2,14 @@ package backup_cmd
 
 import (
 	"fmt"
-	"os"
 
 	"sfDBTools/internal/config"
-	backup_single_mysqldump "sfDBTools/internal/core/backup/single/mysqldump"
+	"sfDBTools/internal/core/backup/engine"
 	"sfDBTools/internal/logger"
 	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/maintenance"
 	"sfDBTools/utils/terminal"
 
 	"github.com/spf13/cobra"
@@ -22,7 +23,31 @@ var BackupSelectionCmd = &cobra.Command{
 2. Specific database: Use --source_db to backup a single database
 3. From file: Use --db_list to backup databases listed in a text file
 
-Note: --source_db and --db_list flags are mutually exclusive.`,
+Note: --source_db and --db_list flags are mutually exclusive.
+
+--recent-partitions TABLE=N limits a huge append-only table to the rows of its N
+most recent range partitions (counting the current one). Such tables are dumped
+in a separate pass after the rest of the database, and the manifest lists them
+under partial_tables.
+
+--parallel N backs up up to N databases at the same time, printing a line as
+each one starts and finishes and a summary table at the end. With --encrypt
+the password is asked once up front.
+
+--output s3://bucket/prefix streams each dump straight to S3 or an S3-compatible
+service (MinIO) with a multipart upload, so the dump never has to fit on local
+disk; only the part being uploaded is spooled to the temp directory. Parts are
+sent with their MD5 and retried on failure, and the finished object is checked
+against the number of bytes written. Region, endpoint and credentials profile
+come from backup.remote. The manifest is kept under --output-dir and copied next
+to the object, so 'catalog reconcile' tracks streamed backups like uploaded ones.
+
+--engine mydumper dumps with mydumper instead of mysqldump: several threads dump
+tables in chunks of backup.mydumper.rows rows at the same time, which shortens
+the backup window of large schemas considerably. The dump directory is stored as
+one <db>.mydumper.tar file, compressed and encrypted like any other backup, and
+'restore single' loads it with myloader. --dump-arg, --recent-partitions and
+--output need the mysqldump engine.`,
 	Example: `# Interactive selection
 sfDBTools backup selection --source_host localhost --source_user root
 
@@ -30,8 +55,23 @@ sfDBTools backup selection --source_host localhost --source_user root
 sfDBTools backup selection --config ./config/mydb.cnf.enc --source_db mydb
 
 # From database list file
-sfDBTools backup selection --config ./config/mydb.cnf.enc --db_list ./databases.txt`,
-	Run: func(cmd *cobra.Command, args []string) {
+sfDBTools backup selection --config ./config/mydb.cnf.enc --db_list ./databases.txt
+
+# Back up the databases of a list file, 8 at a time
+sfDBTools backup selection --config ./config/mydb.cnf.enc --db_list ./databases.txt --parallel 8
+
+# Stream the dump to MinIO without using local disk
+sfDBTools backup selection --config ./config/mydb.cnf.enc --source_db mydb --output s3://db-backups/prod
+
+# Only the last 3 partitions of a large append-only table
+sfDBTools backup selection --config ./config/mydb.cnf.enc --source_db mydb --recent-partitions events=3
+
+# Extra mysqldump options, validated against an allowlist
+sfDBTools backup selection --config ./config/mydb.cnf.enc --source_db mydb --dump-arg=--hex-blob --dump-arg=--ignore-table=mydb.audit_log
+
+# Multi-threaded dump of a large database with mydumper, zstd compressed
+sfDBTools backup selection --config ./config/mydb.cnf.enc --source_db bigdb --engine mydumper --engine-threads 8 --compression zstd`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		lg, _ := logger.Get()
 
 		// Clear terminal and show header
@@ -39,39 +79,39 @@ sfDBTools backup selection --config ./config/mydb.cnf.enc --db_list ./databases.
 		// Get the values of both flags
 		dbListPath, err := cmd.Flags().GetString("db_list")
 		if err != nil {
-			lg.Error("Failed to get db_list flag", logger.Error(err))
-			os.Exit(1)
+			return common.WithExitCode(fmt.Errorf("failed to get db_list flag: %w", err), common.ExitUsage)
 		}
 
 		sourceDB, err := cmd.Flags().GetString("source_db")
 		if err != nil {
-			lg.Error("Failed to get source_db flag", logger.Error(err))
-			os.Exit(1)
+			return common.WithExitCode(fmt.Errorf("failed to get source_db flag: %w", err), common.ExitUsage)
 		}
 
 		// Check for mutually exclusive flags
 		if dbListPath != "" && sourceDB != "" {
-			lg.Error("Cannot use both --source_db and --db_list flags simultaneously")
-			fmt.Printf("Error: Cannot use both --source_db and --db_list flags simultaneously\n")
-			os.Exit(1)
+			return common.WithExitCode(fmt.Errorf("cannot use both --source_db and --db_list flags simultaneously"), common.ExitUsage)
+		}
+
+		session, err := maintenance.Enter(cmd, "backup-selection")
+		if err != nil {
+			return err
 		}
 
 		// Route to appropriate execution function
 		if dbListPath != "" {
 			// Execute list backup from file
-			if err := executeListBackup(cmd); err != nil {
+			if err := session.Close(executeListBackup(cmd)); err != nil {
 				lg.Error("List backup failed", logger.Error(err))
-				fmt.Printf("Error: %v\n", err)
-				os.Exit(1)
+				return err
 			}
 		} else {
 			// Execute selection backup (either specific DB or interactive selection)
-			if err := executeSelectionBackup(cmd); err != nil {
+			if err := session.Close(executeSelectionBackup(cmd)); err != nil {
 				lg.Error("Selection backup failed", logger.Error(err))
-				fmt.Printf("Error: %v\n", err)
-				os.Exit(1)
+				return err
 			}
 		}
+		return nil
 	},
 }
 
@@ -112,7 +152,7 @@ func executeSelectionBackup(cmd *cobra.Command) error {
 			logger.String("database", sourceDB))
 	} else {
 		// Otherwise, let user select multiple databases interactively
-		selectedDatabases, err = info.SelectMultipleDatabasesInteractive(dbConfig)
+		selectedDatabases, err = info.SelectMultipleDatabasesInteractive(cmd.Context(), dbConfig)
 		if err != nil {
 			return fmt.Errorf("failed to select databases: %w", err)
 		}
@@ -128,9 +168,10 @@ func executeSelectionBackup(cmd *cobra.Command) error {
 
 	// 4. Execute backup for selected databases
 	_, err = backup_utils.ExecuteMultipleDatabaseBackup(
+		cmd.Context(),
 		backupConfig,
 		selectedDatabases,
-		backup_single_mysqldump.BackupSingle,
+		engine.BackupSingle,
 		"Selection",
 	)
 
@@ -147,11 +188,16 @@ func executeListBackup(cmd *cobra.Command) error {
 	lg.Info("Starting list backup process")
 
 	// Execute the complete list backup workflow using the reusable utility
-	return backup_utils.ExecuteListBackupWorkflow(cmd, backup_single_mysqldump.BackupSingle)
+	return backup_utils.ExecuteListBackupWorkflow(cmd, engine.BackupSingle)
 }
 
 func init() {
 	backup_utils.AddCommonBackupFlags(BackupSelectionCmd)
+	backup_utils.AddSourceProtectFlags(BackupSelectionCmd)
+	backup_utils.AddDumpArgFlag(BackupSelectionCmd)
+	backup_utils.AddEngineFlags(BackupSelectionCmd)
+	backup_utils.AddParallelFlag(BackupSelectionCmd)
+	backup_utils.AddRemoteOutputFlag(BackupSelectionCmd)
 
 	// Additional backup options
 	_, _, _, _,
@@ -162,6 +208,8 @@ func init() {
 	BackupSelectionCmd.Flags().Int("retention-days", defaultRetentionDays, "retention period in days")
 	BackupSelectionCmd.Flags().Bool("calculate-checksum", defaultCalculateChecksum, "calculate SHA256 checksum of backup file")
 
+	BackupSelectionCmd.Flags().StringSlice("recent-partitions", nil, "dump only the N most recent partitions of a range-partitioned table, as TABLE=N (repeatable)")
+
 	// Required flag for database list
 	BackupSelectionCmd.Flags().String("db_list", "", "path to text file containing list of database names (optional, will show selection if not provided)")
 }
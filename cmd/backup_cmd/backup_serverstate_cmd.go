@@ -0,0 +1,105 @@
+package backup_cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/serverstate"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+var BackupServerStateCmd = &cobra.Command{
+	Use:   "serverstate",
+	Short: "Capture events, plugins, UDFs, changed global variables and replication setup",
+	Long: `Capture the server-level state that database and grant backups do not cover
+into a JSON bundle, so a rebuilt host can be brought back to the same setup
+with 'sfDBTools restore serverstate':
+
+- events, with the sql_mode, time zone and character set they were created under
+- plugins loaded from shared libraries and loadable functions (UDFs)
+- global variables whose value differs from the built-in default
+- the replication source of a replica (without its password)
+
+The bundle is written to <output-dir>/<date>/serverstate/serverstate_<timestamp>.json.
+Sections the backup user cannot read are left empty and reported as warnings.`,
+	Example: `sfDBTools backup serverstate --config ./config/mydb.cnf.enc
+sfDBTools backup serverstate --source_host db1 --source_user root --output-dir ./backups`,
+	Annotations: map[string]string{
+		"command":  "backup",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeServerStateBackup(cmd)
+	},
+}
+
+func executeServerStateBackup(cmd *cobra.Command) error {
+	lg, err := logger.Get()
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	terminal.Headers("Backup Tools - Server State Backup")
+
+	host, port, user, password, _, err := backup_utils.ResolveBackupConnection(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database connection: %w", err)
+	}
+	_, _, _, defaultOutputDir, _, _, _, _, _, _, _, _, _ := config.GetBackupDefaults()
+	outputDir := common.GetStringFlagOrEnv(cmd, "output-dir", "OUTPUT_DIR", defaultOutputDir)
+
+	db, err := database.GetWithoutDB(database.Config{Host: host, Port: port, User: user, Password: password})
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s:%d: %w", host, port, err)
+	}
+	defer db.Close()
+
+	bundle, captureWarnings, err := serverstate.Capture(db, host, port)
+	if err != nil {
+		return err
+	}
+	for _, w := range captureWarnings {
+		lg.Warn("Server state incomplete", logger.String("detail", w))
+	}
+
+	now := common.LocalNow()
+	outputFile := filepath.Join(outputDir, now.Format("2006_01_02"), "serverstate", "serverstate_"+now.Format("20060102_150405")+".json")
+	if err := bundle.Save(outputFile); err != nil {
+		return err
+	}
+
+	replication := "-"
+	if r := bundle.Replication; r != nil {
+		replication = fmt.Sprintf("%s@%s:%d", r.SourceUser, r.SourceHost, r.SourcePort)
+	}
+	warnings.PrintSummary()
+	terminal.PrintSuccess("Server state captured")
+	terminal.FormatTable([]string{"Item", "Value"}, [][]string{
+		{"Server", fmt.Sprintf("%s:%d (%s)", host, port, bundle.ServerVersion)},
+		{"Events", fmt.Sprint(len(bundle.Events))},
+		{"Plugins", fmt.Sprint(len(bundle.Plugins))},
+		{"UDFs", fmt.Sprint(len(bundle.UDFs))},
+		{"Changed variables", fmt.Sprint(len(bundle.Variables))},
+		{"Replication source", replication},
+		{"Output file", outputFile},
+	})
+	return nil
+}
+
+func init() {
+	_, _, _, defaultOutputDir, _, _, _, _, _, _, _, _, _ := config.GetBackupDefaults()
+
+	BackupServerStateCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc)")
+	BackupServerStateCmd.Flags().String("source_host", "", "source database host")
+	BackupServerStateCmd.Flags().Int("source_port", 0, "source database port")
+	BackupServerStateCmd.Flags().String("source_user", "", "source database user")
+	BackupServerStateCmd.Flags().String("source_password", "", "source database password")
+	BackupServerStateCmd.Flags().String("output-dir", defaultOutputDir, "output directory")
+}
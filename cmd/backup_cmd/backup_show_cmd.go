@@ -0,0 +1,126 @@
+package backup_cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/utils/backup/catalog"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+// BackupShowCmd shows one backup of the backup catalog
+var BackupShowCmd = &cobra.Command{
+	Use:   "show <id|file|manifest>",
+	Short: "Show the catalog record of one backup",
+	Long: `Show everything the backup catalog records about one backup. The backup is
+given by its ID from 'backup list', its backup file (path or file name) or the
+path of its manifest.`,
+	Example: `sfDBTools backup show 42
+sfDBTools backup show appdb_20250101_020000_full.sql.gz.enc
+sfDBTools backup show 42 --json`,
+	Args: cobra.ExactArgs(1),
+	Annotations: map[string]string{
+		"command":  "backup",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeBackupShow(cmd, args[0])
+	},
+}
+
+func executeBackupShow(cmd *cobra.Command, ref string) error {
+	cat, _, err := openBackupCatalog(cmd)
+	if err != nil {
+		return err
+	}
+	e, err := cat.Find(ref)
+	cat.Close()
+	if err != nil {
+		return err
+	}
+
+	if output.JSON() {
+		output.SetResult(e)
+		return nil
+	}
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		warnings.Take()
+		data, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode backup: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	terminal.Headers(fmt.Sprintf("Backup - %d", e.ID))
+	compression := "none"
+	if e.Compressed {
+		compression = e.CompressionType
+	}
+	checksum := "-"
+	if e.Checksum != "" {
+		checksum = e.ChecksumAlgorithm + ":" + e.Checksum
+	}
+	rows := [][]string{
+		{"Database", e.Database},
+		{"Type", e.Type},
+		{"Date", e.BackupDate.Local().Format("2006-01-02 15:04:05 MST")},
+		{"Server", fmt.Sprintf("%s:%d", e.Host, e.Port)},
+		{"File", e.File},
+		{"Manifest", e.Manifest},
+		{"Size", common.FormatSize(e.Size)},
+		{"Duration", common.HumanizeDuration(e.Duration.Round(time.Second))},
+		{"Compression", compression},
+		{"Encrypted", yesNo(e.Encrypted)},
+		{"Checksum", checksum},
+		{"Expires", expiresCell(e)},
+		{"Remote", remoteCell(e)},
+		{"State", stateCell(e, time.Now())},
+		{"Recorded", e.RecordedAt.Local().Format("2006-01-02 15:04:05")},
+	}
+	if e.Pinned && e.PinReason != "" {
+		rows = append(rows, []string{"Pin reason", e.PinReason})
+	}
+	rows = append(rows, []string{"Verified", verifiedCell(e)})
+	if len(e.Databases) > 0 {
+		rows = append(rows[:2], append([][]string{{"Members", strings.Join(e.Databases, ", ")}}, rows[2:]...)...)
+	}
+	terminal.FormatTable([]string{"Field", "Value"}, rows)
+	return nil
+}
+
+func remoteCell(e *catalog.Entry) string {
+	if e.RemoteKey == "" {
+		return "-"
+	}
+	return e.RemoteKey
+}
+
+func verifiedCell(e *catalog.Entry) string {
+	v := e.Verification
+	if v == nil {
+		return "never"
+	}
+	kind := "checksum"
+	if v.Deep {
+		kind = "deep"
+	}
+	outcome := terminal.ColorText("passed", terminal.ColorGreen)
+	if !v.Success {
+		outcome = terminal.ColorText("failed", terminal.ColorRed)
+	}
+	return fmt.Sprintf("%s %s %s: %s", v.At.Local().Format("2006-01-02 15:04"), kind, outcome, v.Detail)
+}
+
+func init() {
+	BackupShowCmd.Flags().String("catalog-db", "", "backup catalog file (default: backup.catalog.path or catalog.db in the backup directory)")
+	BackupShowCmd.Flags().Bool("json", false, "print the backup as JSON")
+}
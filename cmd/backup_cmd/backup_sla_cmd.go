@@ -0,0 +1,199 @@
+package backup_cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/backup/sla"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+// SLA grades: at or above slaGood is green, at or above slaWarning yellow, red below
+const (
+	slaGood    = 99.0
+	slaWarning = 95.0
+)
+
+// BackupSLACmd reports how reliably the scheduled backups ran
+var BackupSLACmd = &cobra.Command{
+	Use:   "sla",
+	Short: "Show the on-time success rate of scheduled backups per database",
+	Long: `Compute, per database, the percentage of scheduled backups that succeeded on time
+over the last --window, together with the mean backup duration, its trend and
+the run that deviated most from it.
+
+Expected runs come from backup.schedules in config.yaml: 'all' schedules are
+reported as all_databases, 'snapshot' schedules as snapshot, 'group' schedules
+by their --group and 'selection' schedules by their --source_db (a selection
+from a list covers every database backed up on its own). A scheduled run is on time when a backup started in its
+slot and finished within --grace (plus the schedule's randomized_delay); a
+backup finishing later is late, and a slot without one is missed.
+
+Successful runs are read from the manifests under the backup directory, failed
+runs from the job status files. Use --json or --output to export the report.`,
+	Example: `sfDBTools backup sla
+sfDBTools backup sla --window 30d
+sfDBTools backup sla --window 90d --grace 4h --output sla-q3.json`,
+	Annotations: map[string]string{
+		"command":  "backup",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeBackupSLA(cmd)
+	},
+}
+
+func executeBackupSLA(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	baseDir := common.GetStringFlagOrEnv(cmd, "backup-dir", "BACKUP_DIR", cfg.Backup.Storage.BaseDirectory)
+	if baseDir == "" {
+		return fmt.Errorf("backup directory is not set; use --backup-dir or backup.storage.base_directory")
+	}
+
+	opts := sla.Options{
+		BaseDir:   baseDir,
+		Schedules: cfg.Backup.Schedules,
+		Timezone:  cfg.General.Locale.Timezone,
+	}
+	window, _ := cmd.Flags().GetString("window")
+	if opts.Window, err = common.ParseDurationWithDays(window); err != nil || opts.Window <= 0 {
+		return common.WithExitCode(fmt.Errorf("invalid --window %q: use a duration such as 30d or 72h", window), common.ExitUsage)
+	}
+	grace, _ := cmd.Flags().GetString("grace")
+	if opts.Grace, err = common.ParseDurationWithDays(grace); err != nil {
+		return common.WithExitCode(fmt.Errorf("invalid --grace %q: %w", grace, err), common.ExitUsage)
+	}
+
+	report, err := sla.Compute(opts)
+	if err != nil {
+		return err
+	}
+	asJSON, _ := cmd.Flags().GetBool("json")
+	collected := warnings.Items()
+	if asJSON {
+		// The report carries them; a summary after it would break the JSON
+		collected = warnings.Take()
+	}
+	for _, w := range collected {
+		if !slices.Contains(report.Warnings, w.Message) {
+			report.Warnings = append(report.Warnings, w.Message)
+		}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode SLA report: %w", err)
+	}
+	if output, _ := cmd.Flags().GetString("output"); output != "" {
+		if err := fs.NewManager().File().WriteFile(output, data); err != nil {
+			return fmt.Errorf("failed to write SLA report: %w", err)
+		}
+		defer terminal.PrintSuccess(fmt.Sprintf("SLA report saved to %s", output))
+	}
+	if output.JSON() {
+		output.SetResult(report)
+		return nil
+	}
+	if asJSON {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	terminal.Headers("Backup - SLA")
+	terminal.PrintInfo(fmt.Sprintf("%s to %s (grace %s)", report.From.Format("2006-01-02 15:04"), report.To.Format("2006-01-02 15:04"), common.HumanizeDuration(opts.Grace)))
+	for _, warning := range report.Warnings {
+		terminal.PrintWarning(warning)
+	}
+	if len(report.Rows) == 0 {
+		terminal.PrintInfo(fmt.Sprintf("No backups or schedules found in the last %s", window))
+		return nil
+	}
+
+	headers := []string{"Database", "Expected", "On time", "Late", "Missed", "Failed", "SLA", "Mean duration", "Trend", "Largest deviation"}
+	rows := make([][]string, 0, len(report.Rows))
+	for _, r := range report.Rows {
+		rows = append(rows, []string{
+			r.Database,
+			fmt.Sprintf("%d", r.Expected),
+			fmt.Sprintf("%d", r.OnTime),
+			fmt.Sprintf("%d", r.Late),
+			fmt.Sprintf("%d", r.Missed),
+			fmt.Sprintf("%d", r.Failed),
+			slaCell(r),
+			meanDurationCell(r),
+			trendCell(r),
+			deviationCell(r),
+		})
+	}
+	terminal.FormatTable(headers, rows)
+	return nil
+}
+
+// slaCell renders the success rate colored by grade, or "unscheduled"
+func slaCell(r *sla.Row) string {
+	if r.SuccessPercent == nil {
+		if len(r.Schedules) == 0 {
+			return "unscheduled"
+		}
+		return "-"
+	}
+	pct := *r.SuccessPercent
+	color := terminal.ColorRed
+	switch {
+	case pct >= slaGood:
+		color = terminal.ColorGreen
+	case pct >= slaWarning:
+		color = terminal.ColorYellow
+	}
+	return terminal.ColorText(fmt.Sprintf("%.1f%%", pct), color)
+}
+
+func meanDurationCell(r *sla.Row) string {
+	if r.Runs == 0 {
+		return "-"
+	}
+	return common.HumanizeDuration(seconds(r.MeanDuration))
+}
+
+// trendCell shows how much slower (+) or faster (-) the recent half of the window was
+func trendCell(r *sla.Row) string {
+	if r.DurationTrend == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+.0f%%", *r.DurationTrend)
+}
+
+func deviationCell(r *sla.Row) string {
+	if r.LargestDeviationAt == nil {
+		return "-"
+	}
+	deviation := common.HumanizeDuration(seconds(r.LargestDeviation))
+	if r.LargestDeviation >= 0 {
+		deviation = "+" + deviation
+	}
+	return fmt.Sprintf("%s (%s)", deviation, r.LargestDeviationAt.Local().Format("2006-01-02"))
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Second)
+}
+
+func init() {
+	BackupSLACmd.Flags().String("window", "30d", "period to evaluate, e.g. 30d or 72h")
+	BackupSLACmd.Flags().String("grace", "2h", "time after the scheduled start within which a backup must finish to be on time")
+	BackupSLACmd.Flags().String("backup-dir", "", "backup directory holding the manifests (default: backup.storage.base_directory)")
+	BackupSLACmd.Flags().Bool("json", false, "print the report as JSON")
+	BackupSLACmd.Flags().String("output", "", "also write the report as JSON to this file")
+}
@@ -2,12 +2,13 @@ package backup_cmd
 
 import (
 	"fmt"
-	"os"
 
 	"sfDBTools/internal/config"
 	user_grants_backup "sfDBTools/internal/core/backup/user_grants"
 	"sfDBTools/internal/logger"
 	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/output"
 	"sfDBTools/utils/terminal"
 
 	"github.com/spf13/cobra"
@@ -20,13 +21,8 @@ var BackupUserCMD = &cobra.Command{
 	Example: `sfDBTools backup user --source_host localhost --source_user root
 sfDBTools backup user --config ./config/mydb.cnf.enc
 sfDBTools backup user --source_host localhost --source_user root --output-dir ./backups`,
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := executeUserGrantsBackup(cmd); err != nil {
-			lg, _ := logger.Get()
-			lg.Error("User grants backup failed", logger.Error(err))
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
-		}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeUserGrantsBackup(cmd)
 	},
 }
 
@@ -66,10 +62,11 @@ func executeUserGrantsBackup(cmd *cobra.Command) error {
 		VerifyDisk:        backupConfig.VerifyDisk,
 		RetentionDays:     backupConfig.RetentionDays,
 		CalculateChecksum: backupConfig.CalculateChecksum,
+		ChecksumAlgorithm: backupConfig.ChecksumAlgorithm,
 	}
 
 	// 4. Execute user grants backup using the new package
-	result, err := user_grants_backup.BackupUserGrants(options)
+	result, err := user_grants_backup.BackupUserGrants(cmd.Context(), options)
 	if err != nil {
 		return fmt.Errorf("user grants backup failed: %w", err)
 	}
@@ -81,10 +78,11 @@ func executeUserGrantsBackup(cmd *cobra.Command) error {
 		logger.String("duration", result.Duration.String()),
 		logger.Int("total_users", result.TotalUsers))
 
+	output.SetResult(result)
 	fmt.Printf("User grants backup completed successfully:\n")
 	fmt.Printf("  Output file: %s\n", result.OutputFile)
-	fmt.Printf("  File size: %d bytes\n", result.OutputSize)
-	fmt.Printf("  Duration: %s\n", result.Duration.String())
+	fmt.Printf("  File size: %s\n", common.HumanizeSize(result.OutputSize))
+	fmt.Printf("  Duration: %s\n", common.HumanizeDuration(result.Duration))
 	fmt.Printf("  Total users: %d\n", result.TotalUsers)
 	fmt.Printf("  Compression: %v\n", result.CompressionUsed)
 	fmt.Printf("  Encryption: %v\n", result.EncryptionUsed)
@@ -94,6 +92,7 @@ func executeUserGrantsBackup(cmd *cobra.Command) error {
 
 func init() {
 	backup_utils.AddCommonBackupFlags(BackupUserCMD)
+	backup_utils.AddSourceProtectFlags(BackupUserCMD)
 
 	// Additional backup options
 	_, _, _, _,
@@ -0,0 +1,219 @@
+package backup_cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/backup/verify"
+	"sfDBTools/utils/backup/catalog"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/secrets"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+// BackupVerifyCmd checks that a backup is intact and, with --deep, restorable
+var BackupVerifyCmd = &cobra.Command{
+	Use:   "verify <id|file|manifest>",
+	Short: "Verify a backup, optionally by restoring it into a sandbox",
+	Long: `Verify one backup of the backup catalog.
+
+Without --deep the backup file is compared with the checksum in its manifest.
+
+With --deep the backup is also restored and the restored data is checked:
+every table must pass CHECKSUM TABLE and a row count, and the table, view,
+routine and trigger counts must match the manifest. This proves the backup can
+actually be restored, not just that its bytes are unchanged.
+
+The restore goes into a throwaway mariadbd instance started on 127.0.0.1 with
+a fresh data directory in --work-dir, which is removed afterwards. When a
+sandbox server is configured (--sandbox-host or backup.verification.sandbox)
+single-database backups are restored there into a scratch schema instead;
+all-databases and consistency group backups always use a throwaway instance.
+
+The outcome is recorded in the catalog and shown by 'backup show'. The command
+exits non-zero when the backup is not intact or not restorable.`,
+	Example: `sfDBTools backup verify 42
+sfDBTools backup verify 42 --deep
+sfDBTools backup verify appdb_20250101_020000_full.sql.gz.enc --deep --work-dir /var/tmp
+sfDBTools backup verify 42 --deep --sandbox-host 10.0.0.30 --sandbox-user verify --json`,
+	Args: cobra.ExactArgs(1),
+	Annotations: map[string]string{
+		"command":  "backup",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeBackupVerify(cmd, args[0])
+	},
+}
+
+func executeBackupVerify(cmd *cobra.Command, ref string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	sandboxCfg := cfg.Backup.Verification.Sandbox
+	deep, _ := cmd.Flags().GetBool("deep")
+	keep, _ := cmd.Flags().GetBool("keep")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	asJSON = asJSON || output.JSON()
+	startTimeout, err := common.ParseDurationWithDays(common.GetStringFlagOrEnv(cmd, "start-timeout", "VERIFY_START_TIMEOUT", "2m"))
+	if err != nil {
+		return common.WithExitCode(fmt.Errorf("invalid --start-timeout: %w", err), common.ExitUsage)
+	}
+
+	opts := verify.Options{
+		Deep:          deep,
+		KeepSandbox:   keep,
+		ScratchPrefix: "_verify_",
+		WorkDir:       common.GetStringFlagOrEnv(cmd, "work-dir", "VERIFY_WORK_DIR", sandboxCfg.WorkDir),
+		StartTimeout:  startTimeout,
+	}
+	if host := common.GetStringFlagOrEnv(cmd, "sandbox-host", "VERIFY_SANDBOX_HOST", sandboxCfg.Host); deep && host != "" {
+		user, password, err := secrets.ResolveCredentials(
+			common.GetStringFlagOrEnv(cmd, "sandbox-user", "VERIFY_SANDBOX_USER", sandboxCfg.User),
+			common.GetStringFlagOrEnv(cmd, "sandbox-password", "VERIFY_SANDBOX_PASSWORD", sandboxCfg.Password))
+		if err != nil {
+			return fmt.Errorf("failed to resolve sandbox credentials: %w", err)
+		}
+		opts.Sandbox = &database.Config{
+			Host:     host,
+			Port:     common.GetIntFlagOrEnv(cmd, "sandbox-port", "VERIFY_SANDBOX_PORT", defaultInt(sandboxCfg.Port, 3306)),
+			User:     user,
+			Password: password,
+		}
+	}
+
+	// The catalog stays closed during the restore so backups can record meanwhile
+	cat, _, err := openBackupCatalog(cmd)
+	if err != nil {
+		return err
+	}
+	e, err := cat.Find(ref)
+	cat.Close()
+	if err != nil {
+		return err
+	}
+	opts.Entry = *e
+
+	if !asJSON {
+		terminal.Headers(fmt.Sprintf("Backup - Verify %d", e.ID))
+		if deep {
+			terminal.PrintInfo(fmt.Sprintf("Restoring %s (%s) into a sandbox; this takes about as long as a restore", e.Database, e.Type))
+		}
+	}
+	result, err := verify.Run(cmd.Context(), opts)
+	if err != nil {
+		return err
+	}
+
+	if cat, _, err := openBackupCatalog(cmd); err == nil {
+		err = cat.Update(e.ID, func(entry *catalog.Entry) {
+			entry.Verification = &catalog.Verification{
+				At:      time.Now().UTC(),
+				Deep:    deep,
+				Success: result.Success(),
+				Detail:  result.Summary(),
+			}
+		})
+		cat.Close()
+		if err != nil {
+			terminal.PrintWarning(fmt.Sprintf("Failed to record the verification in the catalog: %v", err))
+		}
+	}
+
+	if output.JSON() {
+		output.SetResult(result)
+	} else if asJSON {
+		warnings.Take()
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode verification result: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		displayVerifyResult(result)
+	}
+	if !result.Success() {
+		return fmt.Errorf("backup %d failed verification: %s", e.ID, result.Summary())
+	}
+	return nil
+}
+
+func displayVerifyResult(result *verify.Result) {
+	rows := [][]string{
+		{"File", result.File},
+		{"Checksum", result.Checksum},
+	}
+	if result.Deep {
+		rows = append(rows, []string{"Sandbox", defaultString(result.Sandbox, "-")})
+	}
+	rows = append(rows, []string{"Duration", common.HumanizeDuration(result.Duration)})
+	terminal.FormatTable([]string{"Field", "Value"}, rows)
+
+	if len(result.Databases) > 0 {
+		var tableRows [][]string
+		for _, d := range result.Databases {
+			failed := 0
+			var problems []string
+			for _, t := range d.Tables {
+				if t.Error != "" {
+					failed++
+					problems = append(problems, t.Name+": "+t.Error)
+				}
+			}
+			status := terminal.ColorText("ok", terminal.ColorGreen)
+			if failed > 0 {
+				status = terminal.ColorText(fmt.Sprintf("%d failed", failed), terminal.ColorRed)
+			}
+			tableRows = append(tableRows, []string{d.Name, d.Schema, fmt.Sprintf("%d", len(d.Tables)), fmt.Sprintf("%d", d.Rows), status})
+			for _, p := range problems {
+				terminal.PrintError(fmt.Sprintf("%s.%s", d.Name, p))
+			}
+		}
+		terminal.FormatTable([]string{"Database", "Restored as", "Tables", "Rows", "Checks"}, tableRows)
+	}
+	if len(result.Mismatches) > 0 {
+		terminal.PrintWarning("Restored objects differ from the manifest: " + strings.Join(result.Mismatches, "; "))
+	}
+
+	if result.Success() {
+		terminal.PrintSuccess("Backup verified: " + result.Summary())
+	} else {
+		terminal.PrintError("Backup failed verification: " + result.Summary())
+	}
+}
+
+func defaultString(val, fallback string) string {
+	if val != "" {
+		return val
+	}
+	return fallback
+}
+
+func defaultInt(val, fallback int) int {
+	if val != 0 {
+		return val
+	}
+	return fallback
+}
+
+func init() {
+	BackupVerifyCmd.Flags().Bool("deep", false, "restore the backup into a sandbox and check the restored tables")
+	BackupVerifyCmd.Flags().String("sandbox-host", "", "sandbox server to restore into (default backup.verification.sandbox.host; empty starts a throwaway instance)")
+	BackupVerifyCmd.Flags().Int("sandbox-port", 0, "sandbox server port")
+	BackupVerifyCmd.Flags().String("sandbox-user", "", "sandbox server user")
+	BackupVerifyCmd.Flags().String("sandbox-password", "", "sandbox server password")
+	BackupVerifyCmd.Flags().String("work-dir", "", "directory for the throwaway instance (default backup.verification.sandbox.work_dir or the temp directory)")
+	BackupVerifyCmd.Flags().String("start-timeout", "2m", "how long to wait for the throwaway instance to start")
+	BackupVerifyCmd.Flags().Bool("keep", false, "keep the scratch schema or throwaway instance for inspection")
+	BackupVerifyCmd.Flags().String("catalog-db", "", "backup catalog file (default: backup.catalog.path or catalog.db in the backup directory)")
+	BackupVerifyCmd.Flags().Bool("json", false, "print the result as JSON")
+}
@@ -2,10 +2,10 @@ package backup_restore
 
 import (
 	"fmt"
-	"os"
 
 	"sfDBTools/internal/logger"
 	backup_restore_utils "sfDBTools/utils/backup_restore"
+	"sfDBTools/utils/maintenance"
 
 	"github.com/spf13/cobra"
 )
@@ -23,7 +23,8 @@ Flow:
 5. Backup production databases
 6. Restore target databases from production backups
 7. Grant privileges to existing users for target databases
-8. Restore original max_statement_time
+8. Apply seed fixtures to the target database (with --seed-dir)
+9. Restore original max_statement_time
 
 Database Naming:
 - Production: dbsf_nbc_{{acc}}
@@ -40,19 +41,21 @@ User Pattern:
 sfDBTools backup-restore production --target=training --acc=dataon
 
 # Copy production to staging for client123 account
-sfDBTools backup-restore production --target=staging --acc=client123 --config mydb.cnf.enc`,
+sfDBTools backup-restore production --target=staging --acc=client123 --config mydb.cnf.enc
+
+# Copy production to training and load training fixtures
+sfDBTools backup-restore production --target=training --acc=dataon --seed-dir ./fixtures/training`,
 
 	Annotations: map[string]string{
 		"command":  "backup-restore",
 		"category": "backup-restore",
 	},
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := executeBackupRestoreProduction(cmd); err != nil {
-			lg, _ := logger.Get()
-			lg.Error("Backup restore production failed", logger.Error(err))
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, err := maintenance.Enter(cmd, "backup-restore")
+		if err != nil {
+			return err
 		}
+		return session.Close(executeBackupRestoreProduction(cmd))
 	},
 }
 
@@ -81,7 +84,7 @@ func executeBackupRestoreProduction(cmd *cobra.Command) error {
 	}
 
 	// Execute backup restore process
-	if err := backup_restore_utils.ExecuteBackupRestoreProduction(config); err != nil {
+	if err := backup_restore_utils.ExecuteBackupRestoreProduction(cmd.Context(), config); err != nil {
 		lg.Error("Backup restore operation failed", logger.Error(err))
 		return fmt.Errorf("backup restore failed: %w", err)
 	}
@@ -99,7 +102,8 @@ func init() {
 	BackupRestoreProductionCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc)")
 	BackupRestoreProductionCmd.Flags().Bool("encrypt", false, "encrypt backup files (default: false)")
 	BackupRestoreProductionCmd.Flags().Bool("dry-run", false, "show what would be done without executing")
-	BackupRestoreProductionCmd.Flags().Bool("yes", false, "skip confirmation prompts")
+	BackupRestoreProductionCmd.Flags().String("seed-dir", "", "fixture directory applied to the target database after the restore (see 'db seed')")
+	BackupRestoreProductionCmd.Flags().StringArray("var", nil, "seed template variable as name=value (repeatable); account and target are set automatically")
 
 	// Mark required flags
 	BackupRestoreProductionCmd.MarkFlagRequired("target")
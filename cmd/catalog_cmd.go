@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	catalog_cmd "sfDBTools/cmd/catalog_cmd"
+	"sfDBTools/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var CatalogCmd = &cobra.Command{
+	Use:   "catalog",
+	Short: "Backup catalog and manifest maintenance commands",
+	Long:  "Maintenance commands for backup manifests (metadata JSON), the backup catalog, the restore drill catalog and remote backup storage.",
+	Run: func(cmd *cobra.Command, args []string) {
+		lg, _ := logger.Get()
+		lg.Info("Catalog command executed")
+		cmd.Help()
+	},
+	Example: `sfDBTools catalog upgrade --dry-run
+sfDBTools catalog reconcile --dry-run
+sfDBTools catalog index --prune`,
+	Annotations: map[string]string{
+		"command":  "catalog",
+		"category": "backup",
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(CatalogCmd)
+	CatalogCmd.AddCommand(catalog_cmd.CatalogUpgradeCmd)
+	CatalogCmd.AddCommand(catalog_cmd.CatalogReconcileCmd)
+	CatalogCmd.AddCommand(catalog_cmd.CatalogIndexCmd)
+}
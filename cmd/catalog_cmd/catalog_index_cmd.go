@@ -0,0 +1,80 @@
+package catalog_cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sfDBTools/internal/config"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/backup/catalog"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+var CatalogIndexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Record the backup manifests under the backup directory in the backup catalog",
+	Long: `Walk the backup directory and record every backup manifest in the backup
+catalog that 'backup list' and 'backup show' read.
+
+Backups are recorded as they finish; this command adds backups taken before the
+catalog existed, or while it could not be opened, and refreshes entries whose
+manifest changed. Recording is idempotent: a manifest keeps its catalog ID.
+With --prune, entries whose manifest no longer exists (e.g. removed by
+retention cleanup) are removed.`,
+	Example: `sfDBTools catalog index
+sfDBTools catalog index --prune
+sfDBTools catalog index --backup-dir /backup/mariadb --catalog-db /var/lib/sfDBTools/catalog.db`,
+	Annotations: map[string]string{
+		"command":  "catalog",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeCatalogIndex(cmd)
+	},
+}
+
+func executeCatalogIndex(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	baseDir := common.GetStringFlagOrEnv(cmd, "backup-dir", "BACKUP_DIR", cfg.Backup.Storage.BaseDirectory)
+	if baseDir == "" {
+		return fmt.Errorf("backup directory is not set; use --backup-dir or backup.storage.base_directory")
+	}
+	defaultPath := backup_utils.CatalogPath(cfg)
+	if defaultPath == "" {
+		defaultPath = filepath.Join(baseDir, catalog.FileName)
+	}
+	path := common.GetStringFlagOrEnv(cmd, "catalog-db", "BACKUP_CATALOG", defaultPath)
+	retentionDays := common.GetIntFlagOrEnv(cmd, "retention-days", "RETENTION_DAYS", cfg.Backup.Retention.Days)
+	prune, _ := cmd.Flags().GetBool("prune")
+
+	terminal.Headers("Catalog - Index")
+	terminal.PrintInfo(fmt.Sprintf("Recording the manifests under %s in %s", baseDir, path))
+	cat, err := catalog.Open(path)
+	if err != nil {
+		return err
+	}
+	defer cat.Close()
+
+	result, err := backup_utils.IndexCatalog(cat, baseDir, retentionDays, prune)
+	if err != nil {
+		return err
+	}
+	for _, manifest := range result.Pruned {
+		terminal.PrintWarning("Removed entry of deleted manifest " + manifest)
+	}
+	terminal.PrintSuccess(fmt.Sprintf("%d backup(s) recorded, %d stale entries removed", result.Recorded, len(result.Pruned)))
+	return nil
+}
+
+func init() {
+	CatalogIndexCmd.Flags().String("backup-dir", "", "backup directory to scan for manifests (default: backup.storage.base_directory)")
+	CatalogIndexCmd.Flags().String("catalog-db", "", "backup catalog file (default: backup.catalog.path or catalog.db in the backup directory)")
+	CatalogIndexCmd.Flags().Int("retention-days", 0, "retention used for the expiry of indexed backups (default: backup.retention.days)")
+	CatalogIndexCmd.Flags().Bool("prune", false, "remove entries whose manifest no longer exists")
+}
@@ -0,0 +1,146 @@
+package catalog_cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sfDBTools/internal/config"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+var CatalogReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Reconcile backup manifests with remote (S3) storage",
+	Long: `Compare the backup catalog (the manifests under the backup directory) with the
+objects in the remote bucket and record the result in every manifest.
+
+Bucket lifecycle rules delete old objects without sfDBTools noticing. Entries
+whose artifact is gone from the bucket and from local disk are marked vanished.
+Entries still on local disk but missing remotely are reported, and with
+--reupload those younger than --reupload-within are uploaded again. Objects in
+the bucket that no manifest refers to are listed as untracked.
+
+The bucket is accessed through the aws CLI; credentials come from the usual AWS
+environment or backup.remote.profile. The command exits non-zero when catalog
+and storage diverge, unless --report-only is given.`,
+	Example: `sfDBTools catalog reconcile --dry-run
+sfDBTools catalog reconcile --reupload --reupload-within 3d
+sfDBTools catalog reconcile --bucket db-backups --prefix prod/mariadb --endpoint-url https://minio.local:9000`,
+	Annotations: map[string]string{
+		"command":  "catalog",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeCatalogReconcile(cmd)
+	},
+}
+
+func executeCatalogReconcile(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	baseDir := common.GetStringFlagOrEnv(cmd, "backup-dir", "BACKUP_DIR", cfg.Backup.Storage.BaseDirectory)
+	if baseDir == "" {
+		return fmt.Errorf("backup directory is not set; use --backup-dir or backup.storage.base_directory")
+	}
+
+	remote := cfg.Backup.Remote
+	remote.Bucket = common.GetStringFlagOrEnv(cmd, "bucket", "BACKUP_REMOTE_BUCKET", remote.Bucket)
+	remote.Prefix = common.GetStringFlagOrEnv(cmd, "prefix", "BACKUP_REMOTE_PREFIX", remote.Prefix)
+	remote.EndpointURL = common.GetStringFlagOrEnv(cmd, "endpoint-url", "BACKUP_REMOTE_ENDPOINT", remote.EndpointURL)
+	store, err := backup_utils.NewRemoteStore(remote)
+	if err != nil {
+		return err
+	}
+
+	opts := backup_utils.ReconcileOptions{BaseDir: baseDir}
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	if reupload, _ := cmd.Flags().GetBool("reupload"); reupload {
+		within, _ := cmd.Flags().GetString("reupload-within")
+		if opts.ReuploadWithin, err = common.ParseDurationWithDays(within); err != nil {
+			return common.WithExitCode(fmt.Errorf("invalid --reupload-within %q: %w", within, err), common.ExitUsage)
+		}
+	}
+	reportOnly, _ := cmd.Flags().GetBool("report-only")
+
+	terminal.Headers("Catalog - Remote Reconcile")
+	terminal.PrintInfo(fmt.Sprintf("Comparing %s with %s", baseDir, store.URL(strings.Trim(remote.Prefix, "/"))))
+
+	result, err := backup_utils.ReconcileRemote(store, opts)
+	if err != nil {
+		return err
+	}
+
+	var table [][]string
+	var failed int
+	for _, e := range result.Entries {
+		if e.Err != nil {
+			failed++
+		}
+		if e.Status == backup_utils.RemoteStatusPresent && e.Err == nil {
+			continue
+		}
+		rel, relErr := filepath.Rel(baseDir, e.Manifest)
+		if relErr != nil {
+			rel = e.Manifest
+		}
+		status := e.Status
+		if e.Previous != "" && e.Previous != e.Status {
+			status = fmt.Sprintf("%s (was %s)", e.Status, e.Previous)
+		}
+		if e.Err != nil {
+			status += ": " + e.Err.Error()
+		}
+		table = append(table, []string{rel, e.Database, e.BackupDate.Local().Format("2006-01-02 15:04"), status})
+	}
+	if len(table) > 0 {
+		terminal.PrintSubHeader("Catalog entries out of sync")
+		terminal.FormatTable([]string{"Manifest", "Database", "Backup Date", "Status"}, table)
+	}
+	if len(result.Untracked) > 0 {
+		terminal.PrintSubHeader("Remote objects not in the catalog")
+		rows := make([][]string, 0, len(result.Untracked))
+		for _, o := range result.Untracked {
+			rows = append(rows, []string{o.Key, common.FormatSize(o.Size), o.LastModified.Local().Format("2006-01-02 15:04")})
+		}
+		terminal.FormatTable([]string{"Key", "Size", "Last Modified"}, rows)
+	}
+
+	summary := fmt.Sprintf("%d entries: %d present, %d uploaded, %d missing, %d vanished, %d size mismatch; %d untracked objects",
+		len(result.Entries),
+		result.Count(backup_utils.RemoteStatusPresent),
+		result.Count(backup_utils.RemoteStatusUploaded),
+		result.Count(backup_utils.RemoteStatusMissing),
+		result.Count(backup_utils.RemoteStatusVanished),
+		result.Count(backup_utils.RemoteStatusSizeMismatch),
+		len(result.Untracked))
+	if opts.DryRun {
+		summary = "Dry run, manifests not updated: " + summary
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%s; %d entries could not be updated or uploaded", summary, failed)
+	}
+	if result.Diverged() && !reportOnly {
+		return fmt.Errorf("catalog and remote storage diverge: %s", summary)
+	}
+	terminal.PrintSuccess(summary)
+	return nil
+}
+
+func init() {
+	CatalogReconcileCmd.Flags().String("backup-dir", "", "backup directory to scan for manifests (default: backup.storage.base_directory)")
+	CatalogReconcileCmd.Flags().String("bucket", "", "remote bucket (default: backup.remote.bucket)")
+	CatalogReconcileCmd.Flags().String("prefix", "", "key prefix of the backups in the bucket (default: backup.remote.prefix)")
+	CatalogReconcileCmd.Flags().String("endpoint-url", "", "S3-compatible endpoint (default: backup.remote.endpoint_url)")
+	CatalogReconcileCmd.Flags().Bool("reupload", false, "upload backups that are missing remotely but still on local disk")
+	CatalogReconcileCmd.Flags().String("reupload-within", "7d", "only re-upload backups younger than this")
+	CatalogReconcileCmd.Flags().Bool("report-only", false, "exit zero even when catalog and storage diverge")
+	CatalogReconcileCmd.Flags().Bool("dry-run", false, "report divergence without updating manifests or uploading")
+}
@@ -0,0 +1,155 @@
+package catalog_cmd
+
+import (
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/schema"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+var CatalogUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Migrate backup manifests and the drill catalog to the current schema version",
+	Long: `Bulk-migrate every backup manifest under the backup directory and the restore
+drill catalog to the schema version of this build.
+
+Older artifacts are also upgraded automatically the first time they are read;
+this command does it ahead of time, for example before making a backup volume
+read-only. Modification times are preserved so retention is not affected.
+
+Artifacts written by a newer sfDBTools release are reported as unsupported and
+the command exits non-zero.`,
+	Example: `sfDBTools catalog upgrade
+sfDBTools catalog upgrade --dry-run
+sfDBTools catalog upgrade --backup-dir /backup/mariadb --catalog /backup/mariadb/restore_drills.json`,
+	Annotations: map[string]string{
+		"command":  "catalog",
+		"category": "backup",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeCatalogUpgrade(cmd)
+	},
+}
+
+// upgradeRow is one artifact that needed attention
+type upgradeRow struct {
+	path, kind, from, result string
+}
+
+func executeCatalogUpgrade(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	baseDir := common.GetStringFlagOrEnv(cmd, "backup-dir", "BACKUP_DIR", cfg.Backup.Storage.BaseDirectory)
+	if baseDir == "" {
+		return fmt.Errorf("backup directory is not set; use --backup-dir or backup.storage.base_directory")
+	}
+	catalog := common.GetStringFlagOrEnv(cmd, "catalog", "DRILL_CATALOG", cfg.Backup.Drill.Catalog)
+	if catalog == "" {
+		catalog = filepath.Join(baseDir, "restore_drills.json")
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	terminal.Headers("Catalog - Schema Upgrade")
+	var rows []upgradeRow
+	var current, failed int
+
+	err = filepath.WalkDir(baseDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") || path == catalog {
+			return nil
+		}
+		from, ok, err := schema.UpgradeManifest(path, dryRun)
+		switch {
+		case err != nil:
+			failed++
+			rows = append(rows, upgradeRow{path, "manifest", versionLabel(from), upgradeError(err)})
+		case !ok:
+		case from == schema.ManifestVersion:
+			current++
+		default:
+			rows = append(rows, upgradeRow{path, "manifest", versionLabel(from), upgradedLabel(schema.ManifestVersion, dryRun)})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", baseDir, err)
+	}
+
+	if _, statErr := os.Stat(catalog); statErr == nil {
+		from, err := schema.UpgradeCatalog(catalog, dryRun)
+		switch {
+		case err != nil:
+			failed++
+			rows = append(rows, upgradeRow{catalog, "drill catalog", versionLabel(from), upgradeError(err)})
+		case from == schema.CatalogVersion:
+			current++
+		default:
+			rows = append(rows, upgradeRow{catalog, "drill catalog", versionLabel(from), upgradedLabel(schema.CatalogVersion, dryRun)})
+		}
+	}
+
+	if len(rows) > 0 {
+		table := make([][]string, 0, len(rows))
+		for _, r := range rows {
+			rel, relErr := filepath.Rel(baseDir, r.path)
+			if relErr != nil || strings.HasPrefix(rel, "..") {
+				rel = r.path
+			}
+			table = append(table, []string{rel, r.kind, r.from, r.result})
+		}
+		terminal.FormatTable([]string{"Artifact", "Kind", "Version", "Result"}, table)
+	}
+
+	upgraded := len(rows) - failed
+	summary := fmt.Sprintf("%d upgraded, %d already current, %d failed", upgraded, current, failed)
+	if dryRun {
+		summary = fmt.Sprintf("Dry run: %d would be upgraded, %d already current, %d failed", upgraded, current, failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s", summary)
+	}
+	terminal.PrintSuccess(summary)
+	return nil
+}
+
+func versionLabel(v int) string {
+	if v == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("v%d", v)
+}
+
+func upgradedLabel(to int, dryRun bool) string {
+	if dryRun {
+		return fmt.Sprintf("would upgrade to v%d", to)
+	}
+	return fmt.Sprintf("upgraded to v%d", to)
+}
+
+// upgradeError shortens version errors since the table already shows the path
+func upgradeError(err error) string {
+	var verr *schema.VersionError
+	if errors.As(err, &verr) {
+		return fmt.Sprintf("unsupported (supports up to v%d)", verr.Supported)
+	}
+	return err.Error()
+}
+
+func init() {
+	CatalogUpgradeCmd.Flags().String("backup-dir", "", "backup directory to scan for manifests (default: backup.storage.base_directory)")
+	CatalogUpgradeCmd.Flags().String("catalog", "", "restore drill catalog file (default: backup.drill.catalog or <backup-dir>/restore_drills.json)")
+	CatalogUpgradeCmd.Flags().Bool("dry-run", false, "report what would be upgraded without writing")
+}
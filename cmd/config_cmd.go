@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	config_cmd "sfDBTools/cmd/config_cmd"
+
+	"github.com/spf13/cobra"
+)
+
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the sfDBTools configuration",
+	Long:  "Inspect config.yaml, e.g. check it for mistakes before it is used.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+	Annotations: map[string]string{
+		"command":  "config",
+		"category": "system",
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ConfigCmd)
+	ConfigCmd.AddCommand(config_cmd.ValidateCmd)
+}
@@ -0,0 +1,109 @@
+package config_cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/config/lint"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// ValidateCmd lints config.yaml and reports every problem with its line
+var ValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check config.yaml for mistakes before it is used",
+	Long: `Load config.yaml and report every problem with its line number instead of
+failing later in the middle of an operation:
+
+- YAML syntax errors and values of the wrong type
+- Unknown keys, which are silently ignored at startup (typos, wrong indentation)
+- Required fields and values checked at startup (general, log level and format)
+- Paths: the database config, backup, log and temp directories exist and are
+  writable where needed
+- Permissions: config.yaml not writable by other users, no plaintext database
+  password in a world-readable file
+- Log rotation sizes and retention
+- Backup defaults: engine, compression algorithm and level, retention, drill
+  durations, remote provider and schedules
+
+Without --file the config.yaml sfDBTools would load is checked. Nothing is
+modified. The command exits with code 3 when errors are found, and also on
+warnings with --strict.`,
+	Example: `sfDBTools config validate
+sfDBTools config validate --file /etc/sfDBTools/config/config.yaml.new
+sfDBTools config validate --strict --output json`,
+	Annotations: map[string]string{
+		"command":  "config validate",
+		"category": "system",
+		"config":   "optional",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeValidate(cmd)
+	},
+}
+
+func init() {
+	ValidateCmd.Flags().String("file", "", "config file to check (default: the config.yaml sfDBTools loads)")
+	ValidateCmd.Flags().Bool("strict", false, "also fail on warnings")
+}
+
+func executeValidate(cmd *cobra.Command) error {
+	path := common.GetStringFlagOrEnv(cmd, "file", "SFDB_CONFIG_FILE", "")
+	if path == "" {
+		if config.Dir() == "" {
+			_, err := config.Get()
+			return common.WithExitCode(fmt.Errorf("no config.yaml found (%v); use --file", err), common.ExitConfig)
+		}
+		path = filepath.Join(config.Dir(), "config.yaml")
+	}
+	strict := common.GetBoolFlagOrEnv(cmd, "strict", "SFDB_CONFIG_STRICT", false)
+
+	report, err := lint.File(path)
+	if err != nil {
+		return common.WithExitCode(fmt.Errorf("failed to read %s: %w", path, err), common.ExitConfig)
+	}
+	output.SetResult(report)
+
+	errs, warns := report.Count(lint.SeverityError), report.Count(lint.SeverityWarning)
+	if !output.JSON() {
+		printIssues(report)
+	}
+	summary := fmt.Sprintf("%s: %d errors, %d warnings", path, errs, warns)
+	if errs > 0 || (strict && warns > 0) {
+		return common.WithExitCode(fmt.Errorf("config validation failed (%s)", summary), common.ExitConfig)
+	}
+	if warns > 0 {
+		terminal.PrintWarning(summary)
+		return nil
+	}
+	terminal.PrintSuccess(summary)
+	return nil
+}
+
+// printIssues prints one compiler-style line per issue, file:line: severity:
+// key: message, followed by the hint
+func printIssues(report *lint.Report) {
+	for _, issue := range report.Issues {
+		location := report.File
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d", report.File, issue.Line)
+		}
+		label := terminal.ColorText("error", terminal.ColorRed)
+		if issue.Severity == lint.SeverityWarning {
+			label = terminal.ColorText("warning", terminal.ColorYellow)
+		}
+		message := issue.Message
+		if issue.Key != "" {
+			message = issue.Key + ": " + message
+		}
+		fmt.Printf("%s: %s: %s\n", location, label, message)
+		if issue.Hint != "" {
+			fmt.Printf("    %s\n", issue.Hint)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/dashboard"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+var DashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "Show a full-screen overview of a server that refreshes periodically",
+	Long: `Open a full-screen view of one server with four panels:
+
+- Service: the MariaDB service on this host, the installed version, and the
+  status, uptime and connections of the server
+- Databases: every user database with its size and table count, largest first
+- Recent backups: the newest entries of the backup catalog and whether they
+  were verified
+- Replication: the role of the server, replica thread state and lag
+
+The data is collected again every --interval and on 'r'. Tab, the arrow keys
+or 1-4 move the focus between panels; up/down, j/k and PgUp/PgDn scroll the
+focused panel. q, Esc or Ctrl+C close the dashboard.
+
+The dashboard needs an interactive terminal and does not support --output json;
+use 'mariadb health' and 'backup list' for scripts.`,
+	Example: `sfDBTools dashboard
+sfDBTools dashboard --config ./config/db1.cnf.enc --interval 10s
+sfDBTools dashboard --source_host 10.0.0.2 --source_user monitor --backups 20`,
+	Annotations: map[string]string{
+		"command":  "dashboard",
+		"category": "system",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeDashboard(cmd)
+	},
+}
+
+func executeDashboard(cmd *cobra.Command) error {
+	if output.JSON() {
+		return common.WithExitCode(fmt.Errorf("dashboard is interactive and has no JSON output"), common.ExitUsage)
+	}
+	if !terminal.CanOpenScreen() {
+		return common.WithExitCode(fmt.Errorf("dashboard needs an interactive terminal"), common.ExitUsage)
+	}
+	interval, err := time.ParseDuration(common.GetStringFlagOrEnv(cmd, "interval", "DASHBOARD_INTERVAL", "5s"))
+	if err != nil || interval < time.Second {
+		return common.WithExitCode(fmt.Errorf("invalid --interval: expected a duration of at least 1s"), common.ExitUsage)
+	}
+	backups := common.GetIntFlagOrEnv(cmd, "backups", "DASHBOARD_BACKUPS", 10)
+	if backups < 1 {
+		return common.WithExitCode(fmt.Errorf("invalid --backups %d: must be at least 1", backups), common.ExitUsage)
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	host, port, user, password, _, err := backup_utils.ResolveDatabaseConnection(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database connection: %w", err)
+	}
+
+	opts := dashboard.Options{
+		Server:      database.Config{Host: host, Port: port, User: user, Password: password},
+		CatalogPath: common.GetStringFlagOrEnv(cmd, "catalog-db", "BACKUP_CATALOG", backup_utils.CatalogPath(cfg)),
+		Backups:     backups,
+		Interval:    interval,
+	}
+
+	// Log lines on the console would tear the screen; the dashboard shows
+	// errors in its panels instead, and the warnings of every refresh are not
+	// worth a summary afterwards
+	lg, _ := logger.Get()
+	console := lg.Out
+	lg.SetOutput(io.Discard)
+	defer func() {
+		lg.SetOutput(console)
+		warnings.Take()
+	}()
+	return dashboard.Run(cmd.Context(), opts)
+}
+
+func init() {
+	rootCmd.AddCommand(DashboardCmd)
+	DashboardCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc) of the server")
+	DashboardCmd.Flags().String("source_host", "", "database host")
+	DashboardCmd.Flags().Int("source_port", 0, "database port")
+	DashboardCmd.Flags().String("source_user", "", "database user")
+	DashboardCmd.Flags().String("source_password", "", "database password")
+	DashboardCmd.Flags().String("interval", "5s", "time between two refreshes")
+	DashboardCmd.Flags().Int("backups", 10, "number of recent backups shown")
+	DashboardCmd.Flags().String("catalog-db", "", "backup catalog file (default: from config.yaml)")
+}
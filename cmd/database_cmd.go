@@ -9,9 +9,10 @@ import (
 
 // DatabaseCmd root untuk operasi manajemen database (non-backup)
 var DatabaseCmd = &cobra.Command{
-	Use:   "database",
-	Short: "Perintah manajemen database (drop, dsb)",
-	Long:  "Kumpulan subcommand untuk operasi administrasi database yang bersifat destruktif atau manajerial.",
+	Use:     "database",
+	Aliases: []string{"db"},
+	Short:   "Perintah manajemen database (drop, clone, seed, subset, migrate-schema, quota, dsb)",
+	Long:    "Kumpulan subcommand untuk operasi administrasi database yang bersifat destruktif atau manajerial.",
 	Run: func(cmd *cobra.Command, args []string) {
 		lg, _ := logger.Get()
 		lg.Info("Menjalankan perintah database (menampilkan help)")
@@ -26,4 +27,10 @@ var DatabaseCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(DatabaseCmd)
 	DatabaseCmd.AddCommand(database_cmd.DatabaseDropCmd)
+	DatabaseCmd.AddCommand(database_cmd.DatabaseCloneCmd)
+	DatabaseCmd.AddCommand(database_cmd.DatabasePartitionsCmd)
+	DatabaseCmd.AddCommand(database_cmd.DatabaseSeedCmd)
+	DatabaseCmd.AddCommand(database_cmd.DatabaseMigrateSchemaCmd)
+	DatabaseCmd.AddCommand(database_cmd.DatabaseQuotaCmd)
+	DatabaseCmd.AddCommand(database_cmd.DatabaseSubsetCmd)
 }
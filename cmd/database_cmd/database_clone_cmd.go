@@ -0,0 +1,138 @@
+package database_cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sfDBTools/internal/core/clone"
+	"sfDBTools/utils/common"
+	dbConfig "sfDBTools/utils/database"
+	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/maintenance"
+	migrate_utils "sfDBTools/utils/migrate"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// streamThroughput is the assumed dump+load rate used for the plan estimate
+const streamThroughput = 10 * 1024 * 1024
+
+var DatabaseCloneCmd = &cobra.Command{
+	Use:   "clone",
+	Short: "Copy a database to another server by streaming mysqldump into the target",
+	Long: `Copy a database from the source server to the target server without writing
+a backup file: mysqldump output is piped straight into the target's mysql client.
+
+  --compress   : compress both client connections (default on)
+  --fallback   : if the stream breaks, retry by dumping to a compressed temp
+                 file and loading that (default on)
+
+The target database is created with the source's character set when missing.
+Tables that already exist in the target database are dropped and recreated.
+Progress can be followed with 'sfDBTools system jobs'.`,
+	Example: `sfDBTools db clone --source-config a.cnf.enc --target-config b.cnf.enc --db app
+sfDBTools db clone --source-config a.cnf.enc --target-config b.cnf.enc --db app --target-db app_copy
+sfDBTools db clone --source-config a.cnf.enc --target-host 10.0.0.9 --target-user admin --db app --fallback=false`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, err := maintenance.Enter(cmd, "clone")
+		if err != nil {
+			return err
+		}
+		return session.Close(executeDatabaseClone(cmd))
+	},
+}
+
+func init() {
+	migrate_utils.AddMigrationConnectionFlags(DatabaseCloneCmd)
+
+	DatabaseCloneCmd.Flags().String("db", "", "source database to clone (required)")
+	DatabaseCloneCmd.Flags().String("target-db", "", "target database name (default: same as --db)")
+	DatabaseCloneCmd.Flags().Bool("compress", true, "compress the source and target client connections")
+	DatabaseCloneCmd.Flags().Bool("fallback", true, "retry through a temporary dump file if the stream breaks")
+}
+
+func executeDatabaseClone(cmd *cobra.Command) error {
+	sourceDB := common.GetStringFlagOrEnv(cmd, "db", "CLONE_DB", "")
+	if sourceDB == "" {
+		return fmt.Errorf("--db is required")
+	}
+	targetDB := common.GetStringFlagOrEnv(cmd, "target-db", "CLONE_TARGET_DB", sourceDB)
+
+	srcHost, srcPort, srcUser, srcPass, _, err := migrate_utils.ResolveSourceDatabaseConnection(cmd)
+	if err != nil {
+		return err
+	}
+	tgtHost, tgtPort, tgtUser, tgtPass, _, err := migrate_utils.ResolveTargetDatabaseConnection(cmd)
+	if err != nil {
+		return err
+	}
+	if srcHost == tgtHost && srcPort == tgtPort && sourceDB == targetDB {
+		return fmt.Errorf("source and target are the same database (%s:%d/%s)", srcHost, srcPort, sourceDB)
+	}
+
+	compress, _ := cmd.Flags().GetBool("compress")
+	fallback, _ := cmd.Flags().GetBool("fallback")
+	skipConfirm := terminal.AssumeYes()
+	opts := clone.Options{
+		Source:   dbConfig.Config{Host: srcHost, Port: srcPort, User: srcUser, Password: srcPass, DBName: sourceDB},
+		Target:   dbConfig.Config{Host: tgtHost, Port: tgtPort, User: tgtUser, Password: tgtPass, DBName: targetDB},
+		Compress: compress,
+		Fallback: fallback,
+		TempDir:  common.GetStringFlagOrEnv(cmd, "temp-dir", "CLONE_TEMP_DIR", ""),
+	}
+
+	if err := dbConfig.ValidateDatabase(opts.Source); err != nil {
+		return fmt.Errorf("source database check failed: %w", err)
+	}
+
+	if !skipConfirm && !terminal.ConfirmPlan(buildClonePlan(cmd.Context(), opts), "Proceed with clone?") {
+		return fmt.Errorf("clone cancelled by user")
+	}
+
+	result, err := clone.Run(cmd.Context(), opts)
+	if result != nil {
+		displayCloneResult(result)
+	}
+	if err != nil {
+		return err
+	}
+	terminal.PrintSuccess(fmt.Sprintf("Database %s cloned to %s", opts.Source.DBName, opts.Target.DBName))
+	return nil
+}
+
+// buildClonePlan describes the clone, flagging tables that will be overwritten
+func buildClonePlan(ctx context.Context, opts clone.Options) terminal.OperationPlan {
+	plan := terminal.OperationPlan{
+		Operation: "Database Clone",
+		Source:    fmt.Sprintf("%s:%d/%s", opts.Source.Host, opts.Source.Port, opts.Source.DBName),
+		Target:    fmt.Sprintf("%s:%d/%s", opts.Target.Host, opts.Target.Port, opts.Target.DBName),
+		Actions:   []string{"Stream mysqldump directly into the target"},
+		Backup:    terminal.BackupSafety{Protected: true, Detail: "source is only read"},
+	}
+	if opts.Fallback {
+		plan.Actions = append(plan.Actions, "Fall back to a temporary dump file if the stream breaks")
+	}
+	if srcInfo, err := info.GetDatabaseInfo(ctx, opts.Source); err == nil && srcInfo.SizeBytes > 0 {
+		plan.EstimatedDuration = time.Duration(srcInfo.SizeBytes/streamThroughput) * time.Second
+	}
+	if count, err := clone.TargetTableCount(opts.Target); err == nil && count > 0 {
+		plan.Destructive = []string{fmt.Sprintf("Overwrite tables in %s (%d existing tables)", opts.Target.DBName, count)}
+		plan.Backup = terminal.BackupSafety{Detail: "existing target tables are dropped and recreated"}
+	}
+	return plan
+}
+
+func displayCloneResult(r *clone.Result) {
+	terminal.PrintSubHeader("Clone Result")
+	fmt.Printf("Mode:        %s\n", r.Mode)
+	fmt.Printf("Transferred: %s\n", common.HumanizeSize(r.Bytes))
+	fmt.Printf("Duration:    %s\n", common.HumanizeDuration(r.Duration))
+	if r.Duration > 0 {
+		fmt.Printf("Throughput:  %s\n", common.HumanizeRate(float64(r.Bytes)/r.Duration.Seconds()))
+	}
+	if r.FallbackReason != "" {
+		terminal.PrintWarning("Stream broke and file mode was used: " + r.FallbackReason)
+	}
+}
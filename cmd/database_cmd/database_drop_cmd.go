@@ -12,6 +12,7 @@ import (
 	dbConfig "sfDBTools/utils/database"
 	dbAction "sfDBTools/utils/database/action"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/terminal"
 
 	"github.com/spf13/cobra"
 )
@@ -39,14 +40,8 @@ Contoh:
   sfDBTools database drop --config ./conf.cnf.enc --db_list dblist.txt
   sfDBTools database drop --config ./conf.cnf.enc --exclude audit --exclude staging
   sfDBTools database drop --config ./conf.cnf.enc (interactive select)`,
-	Run: func(cmd *cobra.Command, args []string) {
-		lg, _ := logger.Get()
-
-		if err := executeDatabaseDrop(cmd); err != nil {
-			lg.Error("Database drop failed", logger.Error(err))
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
-		}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeDatabaseDrop(cmd)
 	},
 }
 
@@ -59,7 +54,6 @@ func init() {
 	DatabaseDropCmd.Flags().StringSlice("exclude", []string{}, "Database names to exclude from drop")
 	DatabaseDropCmd.Flags().Bool("dry-run", false, "Simulate only, no actual drop")
 	DatabaseDropCmd.Flags().Bool("force", false, "Continue dropping remaining databases even if one fails")
-	DatabaseDropCmd.Flags().Bool("yes", false, "Skip all confirmations (DANGEROUS)")
 
 	hideIrrelevantFlags(DatabaseDropCmd)
 }
@@ -87,7 +81,7 @@ func executeDatabaseDrop(cmd *cobra.Command) error {
 	dbListPath, _ := cmd.Flags().GetString("db_list")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	force, _ := cmd.Flags().GetBool("force")
-	skipConfirm, _ := cmd.Flags().GetBool("yes")
+	skipConfirm := terminal.AssumeYes()
 	excludes, _ := cmd.Flags().GetStringSlice("exclude")
 
 	used := 0
@@ -205,70 +199,13 @@ func interactiveSelectDatabases(all []string) ([]string, error) {
 	if len(filtered) == 0 {
 		return nil, errors.New("no user databases available")
 	}
-	for i, name := range filtered {
-		fmt.Printf("[%d] %s\n", i+1, name)
-	}
-	fmt.Println("Enter:")
-	fmt.Println("  *                -> select all above")
-	fmt.Println("  comma numbers    -> e.g. 1,3,5")
-	fmt.Println("  ranges           -> e.g. 2-4")
-	fmt.Println("  names            -> e.g. db1,db2")
-	fmt.Print("Selection: ")
-	reader := bufio.NewReader(os.Stdin)
-	raw, _ := reader.ReadString('\n')
-	raw = strings.TrimSpace(raw)
-	if raw == "" {
-		return nil, nil
-	}
-	if raw == "*" {
-		return filtered, nil
-	}
-	parts := strings.Split(raw, ",")
-	selectedMap := map[string]struct{}{}
-	var result []string
-
-	add := func(name string) {
-		if _, ok := selectedMap[name]; !ok {
-			selectedMap[name] = struct{}{}
-			result = append(result, name)
-		}
+	indexes, err := terminal.MultiSelect("", filtered)
+	if err != nil {
+		return nil, err
 	}
-
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p == "" {
-			continue
-		}
-		if strings.Contains(p, "-") {
-			rp := strings.SplitN(p, "-", 2)
-			if len(rp) == 2 {
-				var sIdx, eIdx int
-				if _, e1 := fmt.Sscanf(strings.TrimSpace(rp[0]), "%d", &sIdx); e1 == nil {
-					if _, e2 := fmt.Sscanf(strings.TrimSpace(rp[1]), "%d", &eIdx); e2 == nil && sIdx > 0 && eIdx >= sIdx && eIdx <= len(filtered) {
-						for i := sIdx; i <= eIdx; i++ {
-							add(filtered[i-1])
-						}
-						continue
-					}
-				}
-			}
-		}
-		var idx int
-		if _, scanErr := fmt.Sscanf(p, "%d", &idx); scanErr == nil && idx > 0 && idx <= len(filtered) {
-			add(filtered[idx-1])
-			continue
-		}
-		found := false
-		for _, candidate := range filtered {
-			if candidate == p {
-				add(candidate)
-				found = true
-				break
-			}
-		}
-		if !found {
-			fmt.Printf("Warning: token '%s' not matched (ignored)\n", p)
-		}
+	result := make([]string, len(indexes))
+	for i, idx := range indexes {
+		result[i] = filtered[idx]
 	}
 	return result, nil
 }
@@ -0,0 +1,240 @@
+package database_cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/core/schemamigrate"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	dbConfig "sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+var DatabaseMigrateSchemaCmd = &cobra.Command{
+	Use:   "migrate-schema",
+	Short: "Apply versioned schema migrations (up/down/status)",
+	Long: `Manage the schema of an application database with versioned migration files.
+
+A migration directory holds pairs of files named <version>_<name>.up.sql and
+<version>_<name>.down.sql, e.g. 20260115093000_add_invoice_index.up.sql. They are
+applied in version order and recorded in the ` + schemamigrate.TrackingTable + `
+table of the target database.
+
+Migrations without DDL run in a single transaction together with their tracking
+row. DDL commits implicitly in MySQL/MariaDB, so migrations with DDL run statement
+by statement; a failure leaves the version marked dirty and further runs stop
+until the schema is repaired and the version is retried with 'up --force'.
+
+A database lock prevents two runs against the same database at the same time.`,
+	Example: `sfDBTools db migrate-schema status --config ./config/mydb.cnf.enc --target_db app --dir ./migrations
+sfDBTools db migrate-schema up --config ./config/mydb.cnf.enc --target_db app --dir ./migrations --dry-run
+sfDBTools db migrate-schema up --config ./config/mydb.cnf.enc --target_db app --dir ./migrations --steps 1
+sfDBTools db migrate-schema down --config ./config/mydb.cnf.enc --target_db app --dir ./migrations`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var migrateSchemaUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeMigrateSchema(cmd, schemamigrate.Up)
+	},
+}
+
+var migrateSchemaDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Roll back the most recently applied migrations (one by default)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeMigrateSchema(cmd, schemamigrate.Down)
+	},
+}
+
+var migrateSchemaStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show applied and pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeMigrateSchemaStatus(cmd)
+	},
+}
+
+func init() {
+	flags := DatabaseMigrateSchemaCmd.PersistentFlags()
+	flags.String("config", "", "encrypted configuration file (.cnf.enc)")
+	flags.String("source_host", "", "database host")
+	flags.Int("source_port", 0, "database port")
+	flags.String("source_user", "", "database user")
+	flags.String("source_password", "", "database password")
+	flags.String("dir", "migrations", "migration directory")
+	flags.String("target_db", "", "database to migrate (required)")
+
+	for _, c := range []*cobra.Command{migrateSchemaUpCmd, migrateSchemaDownCmd} {
+		c.Flags().Bool("dry-run", false, "print the statements with EXPLAIN plans and table sizes without running them")
+		c.Flags().Duration("lock-timeout", 10*time.Second, "how long to wait for a concurrent run to finish")
+	}
+	migrateSchemaUpCmd.Flags().Int("steps", 0, "apply at most this many migrations (0 = all pending)")
+	migrateSchemaUpCmd.Flags().Bool("force", false, "retry a migration left dirty by a failed run")
+	migrateSchemaDownCmd.Flags().Int("steps", 1, "number of migrations to roll back")
+
+	DatabaseMigrateSchemaCmd.AddCommand(migrateSchemaUpCmd, migrateSchemaDownCmd, migrateSchemaStatusCmd)
+}
+
+// migrateSchemaOptions resolves the connection and the flags shared by all subcommands
+func migrateSchemaOptions(cmd *cobra.Command) (schemamigrate.Options, error) {
+	var opts schemamigrate.Options
+	opts.Dir = common.GetStringFlagOrEnv(cmd, "dir", "MIGRATIONS_DIR", "migrations")
+	targetDB := common.GetStringFlagOrEnv(cmd, "target_db", "TARGET_DB", "")
+	if targetDB == "" {
+		return opts, common.WithExitCode(fmt.Errorf("--target_db is required"), common.ExitUsage)
+	}
+	host, port, user, password, _, err := backup_utils.ResolveDatabaseConnection(cmd)
+	if err != nil {
+		return opts, fmt.Errorf("failed to resolve database connection: %w", err)
+	}
+	opts.DB = dbConfig.Config{Host: host, Port: port, User: user, Password: password, DBName: targetDB}
+	return opts, nil
+}
+
+func executeMigrateSchema(cmd *cobra.Command, direction string) error {
+	opts, err := migrateSchemaOptions(cmd)
+	if err != nil {
+		return err
+	}
+	opts.Steps, _ = cmd.Flags().GetInt("steps")
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	opts.LockTimeout, _ = cmd.Flags().GetDuration("lock-timeout")
+	if direction == schemamigrate.Up {
+		opts.Force, _ = cmd.Flags().GetBool("force")
+	}
+
+	terminal.Headers(fmt.Sprintf("Database - Schema Migration (%s)", direction))
+	terminal.PrintInfo(fmt.Sprintf("Database %s, migrations from %s", opts.DB.DBName, opts.Dir))
+
+	results, err := schemamigrate.Run(direction, opts)
+	if opts.DryRun {
+		displayMigrationPlan(results)
+	} else {
+		displayMigrationResults(results)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(results) == 0:
+		terminal.PrintSuccess("Nothing to do, the schema is up to date")
+	case opts.DryRun:
+		terminal.PrintInfo(fmt.Sprintf("Dry run: %d migration(s) would be %s", len(results), migrationVerb(direction)))
+	default:
+		terminal.PrintSuccess(fmt.Sprintf("%d migration(s) %s on %s", len(results), migrationVerb(direction), opts.DB.DBName))
+	}
+	return nil
+}
+
+func executeMigrateSchemaStatus(cmd *cobra.Command) error {
+	opts, err := migrateSchemaOptions(cmd)
+	if err != nil {
+		return err
+	}
+	terminal.Headers("Database - Schema Migration Status")
+
+	migrations, err := schemamigrate.Status(opts)
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		terminal.PrintInfo(fmt.Sprintf("No migrations found in %s", opts.Dir))
+		return nil
+	}
+
+	rows := make([][]string, 0, len(migrations))
+	counts := make(map[string]int)
+	for _, m := range migrations {
+		counts[m.Status]++
+		applied := "-"
+		if !m.AppliedAt.IsZero() && m.Status != schemamigrate.StatusPending {
+			applied = m.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		down := "yes"
+		if m.DownPath == "" {
+			down = "no"
+		}
+		rows = append(rows, []string{strconv.FormatInt(m.Version, 10), m.Name, m.Status, applied, down})
+	}
+	terminal.FormatTable([]string{"Version", "Name", "Status", "Applied At", "Down"}, rows)
+
+	fmt.Printf("\n%d applied, %d pending", counts[schemamigrate.StatusApplied]+counts[schemamigrate.StatusChanged], counts[schemamigrate.StatusPending])
+	if counts[schemamigrate.StatusDirty] > 0 {
+		fmt.Printf(", %d dirty", counts[schemamigrate.StatusDirty])
+	}
+	fmt.Println()
+	if counts[schemamigrate.StatusChanged] > 0 {
+		terminal.PrintWarning(fmt.Sprintf("%d applied migration(s) were edited afterwards; add a new migration instead of changing applied ones", counts[schemamigrate.StatusChanged]))
+	}
+	if counts[schemamigrate.StatusMissing] > 0 {
+		terminal.PrintWarning(fmt.Sprintf("%d applied migration(s) have no file in %s", counts[schemamigrate.StatusMissing], opts.Dir))
+	}
+	if counts[schemamigrate.StatusDirty] > 0 {
+		return fmt.Errorf("a migration failed midway; repair the schema, then run 'up --force'")
+	}
+	return nil
+}
+
+func displayMigrationResults(results []schemamigrate.Result) {
+	if len(results) == 0 {
+		return
+	}
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		mode := "statement by statement"
+		if r.Transactional {
+			mode = "transaction"
+		}
+		rows = append(rows, []string{
+			strconv.FormatInt(r.Migration.Version, 10),
+			r.Migration.Name,
+			strconv.Itoa(r.Statements),
+			mode,
+			r.Duration.Round(time.Millisecond).String(),
+		})
+	}
+	terminal.FormatTable([]string{"Version", "Name", "Statements", "Mode", "Duration"}, rows)
+}
+
+func displayMigrationPlan(results []schemamigrate.Result) {
+	for _, r := range results {
+		mode := "statement by statement (contains DDL)"
+		if r.Transactional {
+			mode = "single transaction"
+		}
+		terminal.PrintSubHeader(fmt.Sprintf("%d_%s (%s, %s)", r.Migration.Version, r.Migration.Name, r.Direction, mode))
+		for i, step := range r.Plan {
+			fmt.Printf("  %d. [%s] %s\n", i+1, step.Kind, previewStatement(step.SQL))
+			if step.Detail != "" {
+				fmt.Printf("     %s\n", step.Detail)
+			}
+		}
+	}
+}
+
+// previewStatement squeezes a statement onto one line for the dry-run listing
+func previewStatement(stmt string) string {
+	s := strings.Join(strings.Fields(stmt), " ")
+	if len(s) > 160 {
+		s = s[:157] + "..."
+	}
+	return s
+}
+
+func migrationVerb(direction string) string {
+	if direction == schemamigrate.Down {
+		return "rolled back"
+	}
+	return "applied"
+}
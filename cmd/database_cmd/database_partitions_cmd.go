@@ -0,0 +1,210 @@
+package database_cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"sfDBTools/internal/core/partition"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	dbConfig "sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+var DatabasePartitionsCmd = &cobra.Command{
+	Use:   "partitions",
+	Short: "Inspect and rotate partitioned tables",
+	Long: `Inspect partitioned tables and keep time-range partitions rotated.
+
+  list   : show partitioned tables with their partitions, bounds, rows and size
+  rotate : create upcoming range partitions and drop expired ones per policy
+
+Rotation supports RANGE partitioning on TO_DAYS(col), UNIX_TIMESTAMP(col) or
+YEAR(col) and RANGE COLUMNS on a single date column.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var partitionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List partitioned tables and their partitions",
+	Example: `sfDBTools db partitions list --config ./config/app.cnf.enc --source_db app
+sfDBTools db partitions list --config ./config/app.cnf.enc --source_db app --table events`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executePartitionsList(cmd)
+	},
+}
+
+var partitionsRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Create upcoming range partitions and drop expired ones",
+	Long: `Apply a retention policy to a time-range partitioned table.
+
+  --keep N   : keep the N most recent partitions, counting the one receiving rows
+               now; older partitions are dropped together with their rows
+  --ahead N  : make sure N empty partitions exist after the current one
+  --interval : span of new partitions (day, week, month, year); inferred from
+               the existing bounds when omitted
+
+New partitions are split off a MAXVALUE partition when the table has one.`,
+	Example: `sfDBTools db partitions rotate --config ./config/app.cnf.enc --source_db app --table events --keep 12
+sfDBTools db partitions rotate --config ./config/app.cnf.enc --source_db app --table events --keep 12 --ahead 3 --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executePartitionsRotate(cmd)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{partitionsListCmd, partitionsRotateCmd} {
+		c.Flags().String("config", "", "encrypted configuration file (.cnf.enc)")
+		c.Flags().String("source_host", "", "database host")
+		c.Flags().Int("source_port", 0, "database port")
+		c.Flags().String("source_user", "", "database user")
+		c.Flags().String("source_password", "", "database password")
+		c.Flags().String("source_db", "", "database containing the tables (required)")
+		c.Flags().String("table", "", "partitioned table")
+	}
+	partitionsRotateCmd.Flags().Int("keep", 0, "partitions to keep up to and including the current one (0: drop nothing)")
+	partitionsRotateCmd.Flags().Int("ahead", 3, "empty partitions to keep ready after the current one")
+	partitionsRotateCmd.Flags().String("interval", "", "span of new partitions: day, week, month or year (default: inferred)")
+	partitionsRotateCmd.Flags().Bool("dry-run", false, "show the planned changes without applying them")
+	partitionsRotateCmd.MarkFlagRequired("table")
+
+	DatabasePartitionsCmd.AddCommand(partitionsListCmd)
+	DatabasePartitionsCmd.AddCommand(partitionsRotateCmd)
+}
+
+// connectPartitions opens a connection for the partition commands
+func connectPartitions(cmd *cobra.Command) (*sql.DB, string, error) {
+	schema := common.GetStringFlagOrEnv(cmd, "source_db", "SOURCE_DB", "")
+	if schema == "" {
+		return nil, "", fmt.Errorf("--source_db is required")
+	}
+	host, port, user, password, _, err := backup_utils.ResolveDatabaseConnection(cmd)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve database connection: %w", err)
+	}
+	db, err := dbConfig.GetWithoutDB(dbConfig.Config{Host: host, Port: port, User: user, Password: password, DBName: schema})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to connect to %s:%d: %w", host, port, err)
+	}
+	return db, schema, nil
+}
+
+func executePartitionsList(cmd *cobra.Command) error {
+	db, schema, err := connectPartitions(cmd)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	table, _ := cmd.Flags().GetString("table")
+	tables, err := partition.List(cmd.Context(), db, schema, table)
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		if table != "" {
+			return fmt.Errorf("table %s.%s is not partitioned or does not exist", schema, table)
+		}
+		terminal.PrintInfo(fmt.Sprintf("No partitioned tables in %s", schema))
+		return nil
+	}
+
+	for _, t := range tables {
+		terminal.PrintSubHeader(fmt.Sprintf("%s (%s %s)", t.Name, t.Method, t.Expression))
+		rows := make([][]string, 0, len(t.Partitions))
+		var total int64
+		for _, p := range t.Partitions {
+			rows = append(rows, []string{p.Name, p.Description, strconv.FormatInt(p.Rows, 10), common.HumanizeSize(p.SizeBytes)})
+			total += p.SizeBytes
+		}
+		terminal.FormatTable([]string{"Partition", "Bound", "Rows (est.)", "Size"}, rows)
+		fmt.Printf("%d partitions, %s\n", len(t.Partitions), common.HumanizeSize(total))
+	}
+	return nil
+}
+
+func executePartitionsRotate(cmd *cobra.Command) error {
+	keep, _ := cmd.Flags().GetInt("keep")
+	ahead, _ := cmd.Flags().GetInt("ahead")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	skipConfirm := terminal.AssumeYes()
+	table, _ := cmd.Flags().GetString("table")
+	rawInterval, _ := cmd.Flags().GetString("interval")
+	interval, err := partition.ParseInterval(rawInterval)
+	if err != nil {
+		return err
+	}
+	if keep < 0 || ahead < 0 {
+		return fmt.Errorf("--keep and --ahead must not be negative")
+	}
+
+	db, schema, err := connectPartitions(cmd)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tables, err := partition.List(cmd.Context(), db, schema, table)
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return fmt.Errorf("table %s.%s is not partitioned or does not exist", schema, table)
+	}
+
+	plan, err := partition.PlanRotation(tables[0], partition.RotateOptions{Keep: keep, Ahead: ahead, Interval: interval}, common.LocalNow())
+	if err != nil {
+		return err
+	}
+	if len(plan.Statements) == 0 {
+		terminal.PrintSuccess(fmt.Sprintf("Partitions of %s.%s already match the policy", schema, table))
+		return nil
+	}
+
+	summary := buildRotatePlan(schema, plan)
+	if dryRun {
+		terminal.RenderPlanSummary(summary)
+		for _, stmt := range plan.Statements {
+			fmt.Println(stmt + ";")
+		}
+		return nil
+	}
+	if !skipConfirm && !terminal.ConfirmPlan(summary, "Apply partition changes?") {
+		return fmt.Errorf("partition rotation cancelled by user")
+	}
+
+	if err := partition.Apply(cmd.Context(), db, plan); err != nil {
+		return err
+	}
+	terminal.PrintSuccess(fmt.Sprintf("Rotated %s.%s: %d partition(s) added, %d dropped", schema, table, len(plan.Add), len(plan.Drop)))
+	return nil
+}
+
+// buildRotatePlan describes a rotation, listing dropped partitions as destructive
+func buildRotatePlan(schema string, plan *partition.Plan) terminal.OperationPlan {
+	summary := terminal.OperationPlan{
+		Operation: "Partition Rotation",
+		Target:    fmt.Sprintf("%s.%s", schema, plan.Table.Name),
+		Backup:    terminal.BackupSafety{Detail: "dropped partitions are not backed up; run a backup first if their rows are needed"},
+	}
+	if plan.Current != "" {
+		summary.Actions = append(summary.Actions, "Current partition: "+plan.Current)
+	}
+	for _, p := range plan.Add {
+		summary.Actions = append(summary.Actions, fmt.Sprintf("Create %s (until %s)", p.Name, p.Upper.Format("2006-01-02")))
+	}
+	for _, p := range plan.Drop {
+		summary.Destructive = append(summary.Destructive,
+			fmt.Sprintf("Drop %s (bound %s, ~%d rows, %s)", p.Name, p.Description, p.Rows, common.HumanizeSize(p.SizeBytes)))
+	}
+	if len(plan.Drop) == 0 {
+		summary.Backup = terminal.BackupSafety{Protected: true, Detail: "no partitions are dropped"}
+	}
+	return summary
+}
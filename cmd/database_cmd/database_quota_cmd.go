@@ -0,0 +1,173 @@
+package database_cmd
+
+import (
+	"fmt"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/quota"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/system"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+var DatabaseQuotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Check database sizes against quotas, alert on thresholds and optionally enforce hard limits",
+	Long: `Check database sizes against the per-database quotas in the quota section of
+config.yaml, e.g.:
+
+  quota:
+    warn_at: [80, 90]
+    databases:
+      - name: dbsf_nbc_x_temp
+        max_size: 50GB
+        hard_limit: 55GB
+        app_users: ["'app'@'%'"]
+
+Names are globs ("dbsf_*_temp"). Crossing a warn_at percentage of max_size, max_size
+itself or the hard limit raises an alert through the notification section (log,
+webhook, command), once per change of level; falling back under raises one too.
+
+With enforcement (quota.enforce or --enforce), INSERT on the database is revoked
+from its app_users while it is above the hard limit and granted back once it is
+below again. Only database-level grants can be revoked; accounts with a global or
+wildcard INSERT grant are reported instead. Every alert, revoke and grant is
+appended to quota.audit_log.
+
+Without --once the check repeats every quota.interval (--interval) and can run as
+a systemd Type=notify service.`,
+	Example: `sfDBTools database quota --config ./config/db1.cnf.enc --once
+sfDBTools database quota --config ./config/db1.cnf.enc --once --dry-run
+sfDBTools database quota --config ./config/db1.cnf.enc --enforce --interval 1m`,
+	Annotations: map[string]string{
+		"command":  "database",
+		"category": "administration",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeDatabaseQuota(cmd)
+	},
+}
+
+func executeDatabaseQuota(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	limits, err := quota.ParseLimits(cfg.Quota)
+	if err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	if len(limits) == 0 {
+		return common.WithExitCode(fmt.Errorf("no quotas configured; add databases to the quota section of config.yaml"), common.ExitUsage)
+	}
+
+	opts := quota.Options{
+		Config:  cfg.Quota,
+		Enforce: common.GetBoolFlagOrEnv(cmd, "enforce", "QUOTA_ENFORCE", cfg.Quota.Enforce),
+		DryRun:  common.GetBoolFlagOrEnv(cmd, "dry-run", "QUOTA_DRY_RUN", false),
+	}
+	interval, err := common.ParseDurationWithDays(common.GetStringFlagOrEnv(cmd, "interval", "QUOTA_INTERVAL", cfg.Quota.Interval))
+	if err != nil || interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	host, port, user, password, _, err := backup_utils.ResolveDatabaseConnection(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database connection: %w", err)
+	}
+	db, err := database.GetWithoutDB(database.Config{Host: host, Port: port, User: user, Password: password})
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s:%d: %w", host, port, err)
+	}
+	defer db.Close()
+
+	if common.GetBoolFlagOrEnv(cmd, "once", "QUOTA_ONCE", false) {
+		terminal.Headers("Database Quota")
+		res, err := quota.Check(db, limits, opts)
+		if err != nil {
+			return err
+		}
+		displayQuotaResult(res, opts)
+		return nil
+	}
+
+	lg, _ := logger.Get()
+	daemon := system.NewDaemon("quota")
+	defer daemon.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var heartbeat <-chan time.Time
+	if tick := daemon.WatchdogTick(); tick > 0 {
+		t := time.NewTicker(tick)
+		defer t.Stop()
+		heartbeat = t.C
+	}
+
+	fmt.Printf("Checking database quotas on %s:%d every %s. Press Ctrl+C to stop.\n", host, port, interval)
+	daemon.Ready(fmt.Sprintf("checking quotas on %s:%d", host, port))
+	for {
+		res, err := quota.Check(db, limits, opts)
+		if err != nil {
+			lg.Error("Quota check failed", logger.Error(err))
+		} else {
+			displayQuotaResult(res, opts)
+		}
+		daemon.Alive()
+
+		select {
+		case <-daemon.Context().Done():
+			fmt.Println("stopping quota monitor")
+			return nil
+		case <-heartbeat:
+			daemon.Alive()
+		case <-ticker.C:
+		}
+	}
+}
+
+func displayQuotaResult(res *quota.Result, opts quota.Options) {
+	fmt.Printf("\n[%s]\n", common.LocalNow().Format("2006-01-02 15:04:05"))
+	rows := make([][]string, 0, len(res.Statuses))
+	for _, s := range res.Statuses {
+		level := s.Label()
+		switch s.Level {
+		case quota.LevelHard:
+			level = terminal.ColorText(level, terminal.ColorRed)
+		case quota.LevelOver, quota.LevelWarn:
+			level = terminal.ColorText(level, terminal.ColorYellow)
+		}
+		rows = append(rows, []string{s.Database, common.FormatSize(s.Size), common.FormatSize(s.Limit.MaxSize),
+			common.FormatSize(s.Limit.HardLimit), fmt.Sprintf("%.1f%%", s.Percent), level})
+	}
+	if len(rows) == 0 {
+		terminal.PrintInfo("No database matches a configured quota")
+	} else {
+		terminal.FormatTable([]string{"Database", "Size", "Quota", "Hard Limit", "Used", "Level"}, rows)
+	}
+	for _, a := range res.Actions {
+		terminal.PrintWarning(a)
+	}
+	if opts.DryRun {
+		terminal.PrintInfo("Dry run: no alerts sent, nothing enforced")
+	} else if res.Alerts > 0 {
+		terminal.PrintInfo(fmt.Sprintf("%d alert(s) raised; see %s", res.Alerts, opts.Config.AuditLog))
+	}
+}
+
+func init() {
+	DatabaseQuotaCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc)")
+	DatabaseQuotaCmd.Flags().String("source_host", "", "database host")
+	DatabaseQuotaCmd.Flags().Int("source_port", 0, "database port")
+	DatabaseQuotaCmd.Flags().String("source_user", "", "database user")
+	DatabaseQuotaCmd.Flags().String("source_password", "", "database password")
+	DatabaseQuotaCmd.Flags().Bool("once", false, "check once and exit instead of monitoring")
+	DatabaseQuotaCmd.Flags().String("interval", "", "time between checks (default quota.interval)")
+	DatabaseQuotaCmd.Flags().Bool("enforce", false, "revoke INSERT from app users above the hard limit (default quota.enforce)")
+	DatabaseQuotaCmd.Flags().Bool("dry-run", false, "only show sizes against quotas: no alerts, enforcement or audit")
+}
@@ -0,0 +1,107 @@
+package database_cmd
+
+import (
+	"fmt"
+
+	"sfDBTools/internal/core/seed"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	dbConfig "sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+var DatabaseSeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Apply ordered SQL fixture files to a database (training/demo data)",
+	Long: `Apply the .sql files of a fixture directory to a database in lexical order of
+their path, e.g. 001_schema.sql, 002_master_data.sql, 010_demo_users.sql.
+
+Fixtures may use {{client_code}}, {{app_name}} and {{database}}, plus any
+variable given with --var name=value. An undefined variable is an error.
+
+Applied fixtures are recorded in the ` + seed.TrackingTable + ` table of the target
+database, so running the command again only applies new files. A fixture whose
+content changed after it was applied stops the run unless --reapply-changed is set.`,
+	Example: `sfDBTools db seed --config ./config/mydb.cnf.enc --dir ./fixtures --target_db app_training
+sfDBTools db seed --config ./config/mydb.cnf.enc --dir ./fixtures --target_db app_demo --create --var company="Demo Corp"
+sfDBTools db seed --config ./config/mydb.cnf.enc --dir ./fixtures --target_db app_training --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeDatabaseSeed(cmd)
+	},
+}
+
+func init() {
+	DatabaseSeedCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc)")
+	DatabaseSeedCmd.Flags().String("source_host", "", "database host")
+	DatabaseSeedCmd.Flags().Int("source_port", 0, "database port")
+	DatabaseSeedCmd.Flags().String("source_user", "", "database user")
+	DatabaseSeedCmd.Flags().String("source_password", "", "database password")
+	DatabaseSeedCmd.Flags().String("dir", "", "fixture directory with .sql files (required)")
+	DatabaseSeedCmd.Flags().String("target_db", "", "database to seed (required)")
+	DatabaseSeedCmd.Flags().StringArray("var", nil, "template variable as name=value (repeatable)")
+	DatabaseSeedCmd.Flags().Bool("create", false, "create the target database when it does not exist")
+	DatabaseSeedCmd.Flags().Bool("reapply-changed", false, "apply again fixtures whose content changed since they were applied")
+	DatabaseSeedCmd.Flags().Bool("dry-run", false, "show which fixtures would be applied")
+}
+
+func executeDatabaseSeed(cmd *cobra.Command) error {
+	dir := common.GetStringFlagOrEnv(cmd, "dir", "SEED_DIR", "")
+	targetDB := common.GetStringFlagOrEnv(cmd, "target_db", "TARGET_DB", "")
+	if dir == "" || targetDB == "" {
+		return common.WithExitCode(fmt.Errorf("--dir and --target_db are required"), common.ExitUsage)
+	}
+	rawVars, _ := cmd.Flags().GetStringArray("var")
+	vars, err := seed.ParseVars(rawVars)
+	if err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+
+	terminal.Headers("Database - Seed Fixtures")
+	host, port, user, password, _, err := backup_utils.ResolveDatabaseConnection(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database connection: %w", err)
+	}
+	cfg := dbConfig.Config{Host: host, Port: port, User: user, Password: password, DBName: targetDB}
+
+	opts := seed.Options{DB: cfg, Dir: dir, Vars: vars}
+	opts.ReapplyChanged, _ = cmd.Flags().GetBool("reapply-changed")
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+
+	if create, _ := cmd.Flags().GetBool("create"); create && !opts.DryRun {
+		if err := dbConfig.EnsureDatabase(cfg); err != nil {
+			return err
+		}
+	}
+
+	fixtures, err := seed.Apply(opts)
+	displaySeedResult(fixtures)
+	if err != nil {
+		return err
+	}
+	applied := 0
+	for _, f := range fixtures {
+		if f.Status == seed.StatusApplied || (opts.DryRun && f.Status != seed.StatusSkipped) {
+			applied++
+		}
+	}
+	if opts.DryRun {
+		terminal.PrintInfo(fmt.Sprintf("Dry run: %d of %d fixture(s) would be applied to %s", applied, len(fixtures), targetDB))
+		return nil
+	}
+	terminal.PrintSuccess(fmt.Sprintf("Applied %d of %d fixture(s) to %s", applied, len(fixtures), targetDB))
+	return nil
+}
+
+// displaySeedResult lists every fixture with its status
+func displaySeedResult(fixtures []*seed.Fixture) {
+	if len(fixtures) == 0 {
+		return
+	}
+	rows := make([][]string, 0, len(fixtures))
+	for _, f := range fixtures {
+		rows = append(rows, []string{f.Name, f.Checksum[:12], f.Status})
+	}
+	terminal.FormatTable([]string{"Fixture", "Checksum", "Status"}, rows)
+}
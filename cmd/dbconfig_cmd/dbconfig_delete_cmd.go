@@ -1,13 +1,9 @@
 package dbconfig_cmd
 
 import (
-	"os"
-
 	"sfDBTools/internal/core/dbconfig/delete"
-	"sfDBTools/internal/logger"
 	"sfDBTools/utils/common/flags"
 	"sfDBTools/utils/dbconfig"
-	"sfDBTools/utils/terminal"
 
 	"github.com/spf13/cobra"
 )
@@ -17,13 +13,8 @@ var DeleteCmd = &cobra.Command{
 	Short: "Delete encrypted database configuration files",
 	Long: `Delete encrypted database configuration files.
 ⚠️  WARNING: Deleted files cannot be recovered. Use with caution.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := executeDelete(cmd, args); err != nil {
-			lg, _ := logger.Get()
-			lg.Error("Failed to delete config", logger.Error(err))
-			terminal.PrintError("Delete operation failed")
-			os.Exit(1)
-		}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeDelete(cmd, args)
 	},
 }
 
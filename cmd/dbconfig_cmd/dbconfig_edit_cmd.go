@@ -1,8 +1,6 @@
 package dbconfig_cmd
 
 import (
-	"os"
-
 	"sfDBTools/internal/core/dbconfig/edit"
 	"sfDBTools/utils/common/flags"
 	"sfDBTools/utils/dbconfig"
@@ -18,16 +16,15 @@ var EditCmd = &cobra.Command{
 If no file is specified, it will list all available encrypted config files
 and allow you to choose one. You can modify name, host, port, user, and password.
 If the name changes, the file will be renamed accordingly.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := executeEdit(cmd); err != nil {
 			terminal.PrintError("Edit operation failed")
 			terminal.WaitForEnterWithMessage("Press Enter to continue...")
-			os.Exit(1)
-		} else {
-			terminal.PrintSuccess("Configuration updated successfully!")
-			terminal.WaitForEnterWithMessage("Press Enter to continue...")
-			return
+			return err
 		}
+		terminal.PrintSuccess("Configuration updated successfully!")
+		terminal.WaitForEnterWithMessage("Press Enter to continue...")
+		return nil
 	},
 }
 
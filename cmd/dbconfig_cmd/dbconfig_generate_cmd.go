@@ -1,8 +1,6 @@
 package dbconfig_cmd
 
 import (
-	"os"
-
 	"sfDBTools/internal/core/dbconfig/generate"
 	"sfDBTools/utils/common/flags"
 	"sfDBTools/utils/common/parsing"
@@ -22,21 +20,25 @@ combined with an encryption password from environment variable or interactive in
 For automation, you can use flags to provide database parameters:
 --name, --host, --port, --user
 
+For a primary with replicas, list the other hosts with --hosts (host:port,...)
+and choose how they are used with --policy: first-available (default) or
+prefer-replica-for-backup. Restores and migrations always use the current
+primary; backups may run on a healthy replica.
+
 Sensitive data (passwords) must be provided via environment variables:
 - Encryption password: SFDB_ENCRYPTION_PASSWORD
 - Database password: SFDB_PASSWORD
 
 If environment variables are not set, you will be prompted interactively.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := execDBConfigGenerate(cmd); err != nil {
 			terminal.PrintError("Generation failed")
 			terminal.WaitForEnterWithMessage("Press Enter to continue...")
-			os.Exit(1)
-		} else {
-			terminal.PrintSuccess("Generation completed successfully")
-			terminal.WaitForEnterWithMessage("Press Enter to continue...")
-			return
+			return err
 		}
+		terminal.PrintSuccess("Generation completed successfully")
+		terminal.WaitForEnterWithMessage("Press Enter to continue...")
+		return nil
 	},
 }
 
@@ -1,8 +1,6 @@
 package dbconfig_cmd
 
 import (
-	"os"
-
 	"sfDBTools/internal/core/dbconfig/show"
 	"sfDBTools/utils/common/flags"
 	"sfDBTools/utils/dbconfig"
@@ -18,16 +16,14 @@ var ShowCmd = &cobra.Command{
 If no file is specified, it will list all available encrypted config files
 and allow you to choose one. Database password will be displayed in plain text.
 You will always be prompted for the encryption password (environment variables are ignored for security).`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := executeShow(cmd); err != nil {
 			terminal.PrintError("Show operation failed")
 			terminal.WaitForEnterWithMessage("Press Enter to continue...")
-			os.Exit(1)
-		} else {
-			terminal.PrintSuccess("Show operation completed successfully!")
-			return
+			return err
 		}
-
+		terminal.PrintSuccess("Show operation completed successfully!")
+		return nil
 	},
 }
 
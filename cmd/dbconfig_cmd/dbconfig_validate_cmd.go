@@ -1,8 +1,6 @@
 package dbconfig_cmd
 
 import (
-	"os"
-
 	"sfDBTools/internal/core/dbconfig/validate"
 	"sfDBTools/internal/logger"
 	"sfDBTools/utils/common/flags"
@@ -18,7 +16,7 @@ var ValidateCmd = &cobra.Command{
 	Long: `Validate that the encrypted database configuration can be properly decrypted
 and test the actual database connection. If no file is specified, it will list all 
 available encrypted config files and allow you to choose one.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// Clear screen and show header
 		terminal.Headers("Validate Database Configuration")
 
@@ -27,8 +25,9 @@ available encrypted config files and allow you to choose one.`,
 			lg.Error("Failed to validate config", logger.Error(err))
 			terminal.PrintError("Validation failed")
 			terminal.WaitForEnterWithMessage("Press Enter to continue...")
-			os.Exit(1)
+			return err
 		}
+		return nil
 	},
 }
 
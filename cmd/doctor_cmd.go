@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/doctor"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+var DoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment sfDBTools runs in and suggest fixes",
+	Long: `Run self-diagnostics and print a pass/warn/fail report with remediation hints:
+
+- Configuration: config.yaml loads, the database config directory is readable,
+  configured timezones exist
+- Filesystem: log, temp, job status and backup directories are writable
+- Client binaries: mysql and mysqldump are in PATH (mysqlbinlog, rsync and aws
+  are optional unless remote storage is configured)
+- Encryption: SFDB_ENCRYPTION_PASSWORD is set and decrypts every .cnf.enc in
+  the database config directory
+- Network: the database servers of those configs, MaxScale, the notification
+  webhook, Slack webhook and SMTP server, and the remote storage endpoint
+  accept TCP connections
+- Catalog: backup manifests are readable and their artifacts exist with the
+  recorded size; the restore drill catalog parses
+- Clock: the clock skew between this host and each reachable server
+
+The doctor never prompts and never modifies anything. It also runs when
+config.yaml cannot be loaded, to report why. The command exits non-zero when
+any check fails; warnings do not affect the exit code.`,
+	Example: `sfDBTools doctor
+SFDB_ENCRYPTION_PASSWORD=... sfDBTools doctor
+sfDBTools doctor --source_host db1 --source_user root --max-skew 2s`,
+	Annotations: map[string]string{
+		"command":  "doctor",
+		"category": "system",
+		"config":   "optional",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeDoctor(cmd)
+	},
+}
+
+func executeDoctor(cmd *cobra.Command) error {
+	terminal.Headers("Doctor")
+	appCfg, cfgErr := config.Get()
+	opts := doctor.Options{
+		Config:    appCfg,
+		ConfigErr: cfgErr,
+		ConfigDir: config.Dir(),
+	}
+
+	timeout, err := time.ParseDuration(common.GetStringFlagOrEnv(cmd, "timeout", "DOCTOR_TIMEOUT", "3s"))
+	if err != nil {
+		return common.WithExitCode(fmt.Errorf("invalid --timeout: %w", err), common.ExitUsage)
+	}
+	maxSkew, err := time.ParseDuration(common.GetStringFlagOrEnv(cmd, "max-skew", "DOCTOR_MAX_SKEW", "5s"))
+	if err != nil {
+		return common.WithExitCode(fmt.Errorf("invalid --max-skew: %w", err), common.ExitUsage)
+	}
+	opts.DialTimeout, opts.MaxSkew = timeout, maxSkew
+
+	// An explicit server replaces the configured ones for the clock check;
+	// without one nothing is prompted for
+	if cfgErr == nil && (common.GetStringFlagOrEnv(cmd, "config", "BACKUP_CONFIG", "") != "" || cmd.Flags().Changed("source_host")) {
+		host, port, user, password, _, err := backup_utils.ResolveDatabaseConnection(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to resolve database connection: %w", err)
+		}
+		opts.Server = &database.Config{Host: host, Port: port, User: user, Password: password}
+	}
+
+	report := doctor.Run(opts)
+	printDoctorReport(report)
+
+	fails, warns := report.Count(doctor.StatusFail), report.Count(doctor.StatusWarn)
+	summary := fmt.Sprintf("%d checks: %d passed, %d warnings, %d failed",
+		len(report.Checks), report.Count(doctor.StatusPass), warns, fails)
+	if fails > 0 {
+		return fmt.Errorf("doctor found problems (%s)", summary)
+	}
+	if warns > 0 {
+		terminal.PrintWarning(summary)
+		return nil
+	}
+	terminal.PrintSuccess(summary)
+	return nil
+}
+
+// printDoctorReport prints one table per category followed by the hints of
+// the checks that did not pass
+func printDoctorReport(report *doctor.Report) {
+	for _, category := range report.Categories() {
+		terminal.PrintSubHeader(category)
+		var rows [][]string
+		var hints []string
+		for _, c := range report.Checks {
+			if c.Category != category {
+				continue
+			}
+			rows = append(rows, []string{doctorStatusLabel(c.Status), c.Name, c.Detail})
+			if c.Status != doctor.StatusPass && c.Hint != "" {
+				hints = append(hints, c.Name+": "+c.Hint)
+			}
+		}
+		terminal.FormatTable([]string{"Status", "Check", "Detail"}, rows)
+		for _, hint := range hints {
+			terminal.PrintInfo(hint)
+		}
+	}
+}
+
+func doctorStatusLabel(status doctor.Status) string {
+	label := strings.ToUpper(string(status))
+	switch status {
+	case doctor.StatusPass:
+		return terminal.ColorText(label, terminal.ColorGreen)
+	case doctor.StatusWarn:
+		return terminal.ColorText(label, terminal.ColorYellow)
+	}
+	return terminal.ColorText(label, terminal.ColorRed)
+}
+
+func init() {
+	rootCmd.AddCommand(DoctorCmd)
+	DoctorCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc) of a server to check the clock against")
+	DoctorCmd.Flags().String("source_host", "", "database host to check the clock against")
+	DoctorCmd.Flags().Int("source_port", 0, "database port")
+	DoctorCmd.Flags().String("source_user", "", "database user")
+	DoctorCmd.Flags().String("source_password", "", "database password")
+	DoctorCmd.Flags().String("timeout", "3s", "connect timeout for each network target")
+	DoctorCmd.Flags().String("max-skew", "5s", "clock skew above which the check fails")
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	dr_cmd "sfDBTools/cmd/dr_cmd"
+	"sfDBTools/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var DRCmd = &cobra.Command{
+	Use:   "dr",
+	Short: "Disaster recovery runbooks",
+	Long:  "Guided disaster recovery: rebuild a dead server from its recorded version, configuration, server state, backups and accounts.",
+	Run: func(cmd *cobra.Command, args []string) {
+		lg, _ := logger.Get()
+		lg.Info("DR command executed")
+		cmd.Help()
+	},
+	Example: `sfDBTools dr restore-host --source-host db1 --dry-run`,
+	Annotations: map[string]string{
+		"command":  "dr",
+		"category": "restore",
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(DRCmd)
+	DRCmd.AddCommand(dr_cmd.RestoreHostCmd)
+}
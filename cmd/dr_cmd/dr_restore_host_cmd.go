@@ -0,0 +1,218 @@
+package dr_cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/dr"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	mariadb_config "sfDBTools/utils/mariadb/config"
+	restore_utils "sfDBTools/utils/restore"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+var RestoreHostCmd = &cobra.Command{
+	Use:   "restore-host",
+	Short: "Rebuild a dead database server on a fresh host, step by step",
+	Long: `Walk through rebuilding a dead server on this host from what sfDBTools
+recorded about it:
+
+  1. detect-os            check that the operating system is supported
+  2. install-mariadb      install the recorded MariaDB version (skipped when running)
+  3. server-config        install the saved my.cnf (--server-config) and restart
+  4. serverstate          apply plugins, UDFs and variables of the server state bundle
+  5. restore-data         restore the newest full backups from the catalog
+  6. users                recreate the accounts exported with 'mariadb user export'
+  7. events-replication   recreate events and point the server at its replication source
+  8. health-check         verify version, restored tables, accounts and replication
+
+The newest all-databases backup of --source-host is restored, followed by every
+single-database backup taken after it. sfDBTools takes full logical backups
+only, so there are no incrementals to apply. With --from-remote, manifests and
+backup files missing locally are downloaded from backup.remote first.
+
+The MariaDB version comes from --version, the server state bundle or the
+backup manifests. The newest bundle under the backup directory is used unless
+--serverstate names one.
+
+Progress is saved to the state file after every step. When a step fails, fix
+the cause and run the command again: completed steps are skipped, and a
+restore continues with the first backup not yet restored. The state file keeps
+the timeline of every attempt for the incident report; --restart plans again.`,
+	Example: `sfDBTools dr restore-host --source-host db1 --target_host localhost --target_user root --dry-run
+sfDBTools dr restore-host --source-host db1 --config ./config/newhost.cnf.enc --server-config ./saved/db1-server.cnf --users-file ./saved/db1-users.yaml
+sfDBTools dr restore-host --source-host db1 --config ./config/newhost.cnf.enc --from-remote --backup-dir /var/backup/restore
+SFDB_REPLICATION_PASSWORD=secret sfDBTools dr restore-host --source-host db1 --config ./config/newhost.cnf.enc --start-replica`,
+	Annotations: map[string]string{
+		"command":  "dr",
+		"category": "restore",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeRestoreHost(cmd)
+	},
+}
+
+func executeRestoreHost(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	baseDir := common.GetStringFlagOrEnv(cmd, "backup-dir", "BACKUP_DIR", cfg.Backup.Storage.BaseDirectory)
+	if baseDir == "" {
+		return common.WithExitCode(fmt.Errorf("backup directory is not set; use --backup-dir or backup.storage.base_directory"), common.ExitUsage)
+	}
+
+	opts := dr.Options{
+		BaseDir:             baseDir,
+		SourceHost:          common.GetStringFlagOrEnv(cmd, "source-host", "DR_SOURCE_HOST", ""),
+		Restart:             common.GetBoolFlagOrEnv(cmd, "restart", "DR_RESTART", false),
+		Version:             common.GetStringFlagOrEnv(cmd, "version", "DR_MARIADB_VERSION", ""),
+		ServerConfig:        common.GetStringFlagOrEnv(cmd, "server-config", "DR_SERVER_CONFIG", ""),
+		ConfigPath:          cfg.MariaDB.ConfigDir,
+		ServerStateFile:     common.GetStringFlagOrEnv(cmd, "serverstate", "DR_SERVERSTATE_FILE", ""),
+		UsersFile:           common.GetStringFlagOrEnv(cmd, "users-file", "DR_USERS_FILE", ""),
+		ReplaceUsers:        common.GetBoolFlagOrEnv(cmd, "replace-users", "DR_REPLACE_USERS", false),
+		ReplicationPassword: common.GetStringFlagOrEnv(cmd, "replication-password", "SFDB_REPLICATION_PASSWORD", ""),
+		StartReplica:        common.GetBoolFlagOrEnv(cmd, "start-replica", "DR_START_REPLICA", false),
+	}
+	if opts.SourceHost == "" {
+		hosts, err := dr.SourceHosts(baseDir)
+		if err != nil {
+			return err
+		}
+		if len(hosts) != 1 {
+			return common.WithExitCode(fmt.Errorf("--source-host is required; hosts in the catalog: %s", strings.Join(hosts, ", ")), common.ExitUsage)
+		}
+		opts.SourceHost = hosts[0]
+	}
+	opts.StatePath = common.GetStringFlagOrEnv(cmd, "state-file", "DR_STATE_FILE",
+		filepath.Join(baseDir, "dr", "restore-host_"+strings.NewReplacer("/", "_", ":", "_").Replace(opts.SourceHost)+".json"))
+
+	if common.GetBoolFlagOrEnv(cmd, "from-remote", "DR_FROM_REMOTE", false) {
+		remote := cfg.Backup.Remote
+		remote.Bucket = common.GetStringFlagOrEnv(cmd, "bucket", "BACKUP_REMOTE_BUCKET", remote.Bucket)
+		remote.Prefix = common.GetStringFlagOrEnv(cmd, "prefix", "BACKUP_REMOTE_PREFIX", remote.Prefix)
+		remote.EndpointURL = common.GetStringFlagOrEnv(cmd, "endpoint-url", "BACKUP_REMOTE_ENDPOINT", remote.EndpointURL)
+		if opts.Remote, err = backup_utils.NewRemoteStore(remote); err != nil {
+			return err
+		}
+	}
+	if opts.Configure, err = mariadb_config.ResolveMariaDBConfigureConfig(cmd); err != nil {
+		return err
+	}
+
+	host, port, user, password, _, err := restore_utils.ResolveDatabaseConnection(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database connection: %w", err)
+	}
+	opts.Target = database.Config{Host: host, Port: port, User: user, Password: password}
+
+	terminal.Headers("Disaster Recovery - Restore Host")
+	state, err := dr.Prepare(opts)
+	if err != nil {
+		return err
+	}
+	if state.Finished() {
+		displayRestoreHostSteps(state)
+		terminal.PrintSuccess(fmt.Sprintf("The rebuild of %s is already complete; use --restart to run it again", state.SourceHost))
+		return nil
+	}
+
+	plan := buildRestoreHostPlan(state)
+	if common.GetBoolFlagOrEnv(cmd, "dry-run", "DR_DRY_RUN", false) {
+		terminal.RenderPlanSummary(plan)
+		displayRestoreHostSteps(state)
+		terminal.PrintInfo("Dry run: the plan is saved in " + opts.StatePath + "; nothing else was changed")
+		return nil
+	}
+	skipConfirm := terminal.AssumeYes()
+	if !skipConfirm && !terminal.ConfirmPlan(plan, "Proceed with the rebuild?") {
+		return common.WithExitCode(fmt.Errorf("rebuild cancelled by user"), common.ExitUsage)
+	}
+
+	err = dr.Run(cmd.Context(), opts, state)
+	displayRestoreHostSteps(state)
+	terminal.PrintInfo("Step timeline for the incident report: " + opts.StatePath)
+	if err != nil {
+		return fmt.Errorf("%w; fix the cause and run the command again to resume", err)
+	}
+	warnings.PrintSummary()
+	terminal.PrintSuccess(fmt.Sprintf("%s rebuilt on %s", state.SourceHost, state.Target))
+	return nil
+}
+
+func buildRestoreHostPlan(state *dr.State) terminal.OperationPlan {
+	plan := terminal.OperationPlan{
+		Operation: "Restore Host",
+		Source:    fmt.Sprintf("%s (backups of %s)", state.SourceHost, state.Plan.Backups[0].Date.Local().Format("2006-01-02 15:04")),
+		Target:    state.Target,
+		Backup: terminal.BackupSafety{
+			Protected: true,
+			Detail:    "the target is expected to be a fresh host; databases already on it are overwritten",
+		},
+	}
+	plan.Actions = append(plan.Actions, fmt.Sprintf("install MariaDB %s (from %s)", state.Plan.MariaDBVersion, state.Plan.VersionSource))
+	if state.Plan.ServerConfig != "" {
+		plan.Actions = append(plan.Actions, fmt.Sprintf("install %s as %s and restart", state.Plan.ServerConfig, state.Plan.ConfigPath))
+	}
+	if state.Plan.ServerState != "" {
+		plan.Actions = append(plan.Actions, "apply server state "+state.Plan.ServerState)
+	}
+	for _, b := range state.Plan.Backups {
+		plan.Databases = append(plan.Databases, b.Label())
+		plan.Destructive = append(plan.Destructive, fmt.Sprintf("restore %s from %s (%s)", b.Label(), filepath.Base(b.File), b.Date.Local().Format("2006-01-02 15:04")))
+	}
+	if state.Plan.UsersFile != "" {
+		plan.Actions = append(plan.Actions, "recreate accounts from "+state.Plan.UsersFile)
+	}
+	plan.Actions = append(plan.Actions, "run health checks")
+	return plan
+}
+
+func displayRestoreHostSteps(state *dr.State) {
+	rows := make([][]string, 0, len(dr.Steps))
+	for _, name := range dr.Steps {
+		r := state.Step(name)
+		detail := r.Detail
+		if r.Error != "" {
+			detail = strings.TrimSpace(detail + " " + r.Error)
+		}
+		attempts := ""
+		if r.Attempts > 0 {
+			attempts = fmt.Sprint(r.Attempts)
+		}
+		rows = append(rows, []string{name, strings.ToUpper(r.Status), attempts, r.Duration, detail})
+	}
+	terminal.FormatTable([]string{"Step", "Status", "Attempts", "Duration", "Detail"}, rows)
+}
+
+func init() {
+	RestoreHostCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc) of the rebuilt server")
+	RestoreHostCmd.Flags().String("target_host", "", "rebuilt database host")
+	RestoreHostCmd.Flags().Int("target_port", 0, "rebuilt database port")
+	RestoreHostCmd.Flags().String("target_user", "", "rebuilt database user")
+	RestoreHostCmd.Flags().String("target_password", "", "rebuilt database password")
+	RestoreHostCmd.Flags().String("source-host", "", "host of the dead server as recorded in the backup manifests (default: the only host in the catalog)")
+	RestoreHostCmd.Flags().String("backup-dir", "", "backup directory holding the catalog (default backup.storage.base_directory)")
+	RestoreHostCmd.Flags().String("state-file", "", "progress and incident timeline of the rebuild (default <backup-dir>/dr/restore-host_<source-host>.json)")
+	RestoreHostCmd.Flags().Bool("restart", false, "ignore the saved progress and plan the rebuild again")
+	RestoreHostCmd.Flags().String("version", "", "MariaDB version to install instead of the recorded one")
+	RestoreHostCmd.Flags().String("server-config", "", "saved my.cnf of the dead server, installed as mariadb.config_dir")
+	RestoreHostCmd.Flags().String("serverstate", "", "server state bundle to apply (default: the newest of the host in the backup directory)")
+	RestoreHostCmd.Flags().String("users-file", "", "accounts exported with 'mariadb user export'")
+	RestoreHostCmd.Flags().Bool("replace-users", false, "recreate accounts that already exist")
+	RestoreHostCmd.Flags().String("replication-password", "", "password of the replication user (or SFDB_REPLICATION_PASSWORD)")
+	RestoreHostCmd.Flags().Bool("start-replica", false, "start replication after configuring it")
+	RestoreHostCmd.Flags().Bool("from-remote", false, "download manifests and backup files missing locally from backup.remote")
+	RestoreHostCmd.Flags().String("bucket", "", "remote bucket (default backup.remote.bucket)")
+	RestoreHostCmd.Flags().String("prefix", "", "key prefix in the bucket (default backup.remote.prefix)")
+	RestoreHostCmd.Flags().String("endpoint-url", "", "S3-compatible endpoint (default backup.remote.endpoint_url)")
+	RestoreHostCmd.Flags().Bool("dry-run", false, "plan the rebuild and save it to the state file without running any step")
+}
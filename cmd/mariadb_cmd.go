@@ -22,5 +22,12 @@ func init() {
 	MariaDBCmd.AddCommand(mariadb_cmd.Check)
 	MariaDBCmd.AddCommand(mariadb_cmd.ConfigureMariadbCMD)
 	MariaDBCmd.AddCommand(mariadb_cmd.InstallCmd)
+	MariaDBCmd.AddCommand(mariadb_cmd.UpgradeCmd)
 	MariaDBCmd.AddCommand(mariadb_cmd.RemoveCmd)
+	MariaDBCmd.AddCommand(mariadb_cmd.KeysCmd)
+	MariaDBCmd.AddCommand(mariadb_cmd.UserCmd)
+	MariaDBCmd.AddCommand(mariadb_cmd.ChangesCmd)
+	MariaDBCmd.AddCommand(mariadb_cmd.ProvisionCmd)
+	MariaDBCmd.AddCommand(mariadb_cmd.ReplicationCmd)
+	MariaDBCmd.AddCommand(mariadb_cmd.HealthCmd)
 }
@@ -1,8 +1,6 @@
 package mariadb_cmd
 
 import (
-	"context"
-
 	"sfDBTools/internal/core/mariadb/configure"
 	mariadb_config "sfDBTools/utils/mariadb/config"
 	"sfDBTools/utils/terminal"
@@ -22,7 +20,27 @@ var ConfigureMariadbCMD = &cobra.Command{
 - Auto-tuning based on system resources
 - Port and network configuration
 
-This command will safely migrate existing data if directories are changed.`,
+This command will safely migrate existing data if directories are changed.
+
+With --review-config the generated server.cnf is shown as a list of changes
+against the current configuration before anything is applied; the full file
+can be paged with changed lines highlighted, or edited in $EDITOR. Edits are
+checked by the option file parser before the file is written and MariaDB is
+restarted.
+
+With --non-interactive nothing is prompted, so the command can run from
+automation (Ansible, CI) without a TTY. Values are taken from flags, then
+SFDBTOOLS_MARIADB_* environment variables, then the YAML --answers-file, then
+the mariadb section of config.yaml; the confirmation is skipped.
+
+With --dry-run every step runs up to the point of changing the system and the
+command then reports what would change: the server.cnf diff, the directories
+that would be created, the data migration plan (copy method such as rsync, file
+count and size) and the service steps. Nothing is stopped, copied or written.`,
+	Example: `sfDBTools mariadb configure
+sfDBTools mariadb configure --non-interactive --answers-file /etc/sfDBTools/configure.yaml
+SFDBTOOLS_MARIADB_SERVER_ID=2 sfDBTools mariadb configure --non-interactive --data-dir /data/mysql --migrate-data
+sfDBTools mariadb configure --non-interactive --data-dir /data/mysql --dry-run`,
 	RunE: executeMariaDBConfigure,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := executeMariaDBConfigure(cmd, args); err != nil {
@@ -45,7 +63,7 @@ func executeMariaDBConfigure(cmd *cobra.Command, args []string) error {
 	}
 
 	// 2. Panggil core business logic
-	return configure.RunMariaDBConfigure(context.Background(), config)
+	return configure.RunMariaDBConfigure(cmd.Context(), config)
 }
 
 func init() {
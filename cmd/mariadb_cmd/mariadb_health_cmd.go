@@ -0,0 +1,136 @@
+package mariadb_cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/core/mariadb/health"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+// HealthCmd reports the health of a running server
+var HealthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Report uptime, connections, buffer pool, replication, disk and slow queries of a server",
+	Long: `Connect to a server and report its health:
+
+  - uptime and version
+  - connections against max_connections, aborted clients and connects
+  - InnoDB buffer pool usage and hit ratio
+  - replication role, thread state and Seconds_Behind_Master
+  - usage of the file system holding the datadir (local servers only)
+  - slow queries since startup and per hour
+
+Every figure is compared with fixed thresholds; the overall status is ok, warn
+or crit and the findings explain why. --format json prints the report for
+monitoring scripts.`,
+	Example: `sfDBTools mariadb health
+sfDBTools mariadb health --config ./config/db1.cnf.enc --format json
+sfDBTools mariadb health --host 10.0.0.2 --user monitor --format json | jq -r .status`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeHealth(cmd)
+	},
+}
+
+func executeHealth(cmd *cobra.Command) error {
+	format := strings.ToLower(common.GetStringFlagOrEnv(cmd, "format", "MARIADB_HEALTH_FORMAT", "table"))
+	if format != "table" && format != "json" {
+		return common.WithExitCode(fmt.Errorf("invalid --format %q: expected table or json", format), common.ExitUsage)
+	}
+	dbConfig, err := resolveUserDBConfig(cmd, "config", "MARIADB_CONFIG")
+	if err != nil {
+		return err
+	}
+
+	report, err := health.Check(dbConfig)
+	if err != nil {
+		return err
+	}
+	if output.JSON() {
+		output.SetResult(report)
+		return nil
+	}
+	if format == "json" {
+		// A warnings summary after the report would break the JSON
+		warnings.Take()
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode health report: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	terminal.Headers("MariaDB - Health")
+	terminal.PrintInfo(fmt.Sprintf("%s, MariaDB %s, up %s", report.Server, report.Version,
+		common.HumanizeDuration(time.Duration(report.UptimeSeconds)*time.Second)))
+
+	c, bp, sq := report.Connections, report.BufferPool, report.SlowQueries
+	rows := [][]string{
+		{"Connections", fmt.Sprintf("%d / %d (%.1f%%)", c.Connected, c.Max, c.UsagePercent),
+			fmt.Sprintf("running %d, max used %d, aborted clients %d, aborted connects %d", c.Running, c.MaxUsed, c.AbortedClients, c.AbortedConnect)},
+		{"Buffer pool", fmt.Sprintf("%.1f%% used", bp.UsagePercent),
+			fmt.Sprintf("size %s, dirty pages %d, hit ratio %.2f%%", common.FormatSize(bp.SizeBytes), bp.PagesDirty, bp.HitRatioPercent)},
+		{"Replication", report.Replication.Role, replicationDetail(report.Replication)},
+	}
+	if d := report.Disk; d != nil {
+		rows = append(rows, []string{"Disk", fmt.Sprintf("%.1f%% used", d.UsedPercent),
+			fmt.Sprintf("%s: %s free of %s", d.Datadir, common.FormatSize(d.Free), common.FormatSize(d.Total))})
+	} else {
+		rows = append(rows, []string{"Disk", "-", "remote server; datadir not measured"})
+	}
+	slowLog := "off"
+	if sq.LogEnabled {
+		slowLog = "on"
+	}
+	rows = append(rows, []string{"Slow queries", fmt.Sprintf("%d", sq.Count),
+		fmt.Sprintf("%.2f/hour, long_query_time %gs, slow_query_log %s", sq.PerHour, sq.LongQueryTime, slowLog)})
+	terminal.FormatTable([]string{"Section", "Value", "Detail"}, rows)
+
+	warnings.PrintSummary()
+	for _, f := range report.Findings {
+		if f.Status == health.StatusCrit {
+			terminal.PrintError(fmt.Sprintf("[%s] %s", f.Section, f.Message))
+		} else {
+			terminal.PrintWarning(fmt.Sprintf("[%s] %s", f.Section, f.Message))
+		}
+	}
+	if report.Status == health.StatusOK {
+		terminal.PrintSuccess("Server is healthy")
+	}
+	return nil
+}
+
+func replicationDetail(r health.Replication) string {
+	switch r.Role {
+	case "replica":
+		lag := "unknown"
+		if r.LagSeconds != nil {
+			lag = fmt.Sprintf("%ds", *r.LagSeconds)
+		}
+		detail := fmt.Sprintf("IO %s, SQL %s, lag %s", r.IORunning, r.SQLRunning, lag)
+		if r.LastError != "" {
+			detail += ", last error: " + r.LastError
+		}
+		return detail
+	case "primary":
+		return fmt.Sprintf("%d replica(s) connected", r.Replicas)
+	}
+	return "no replication configured"
+}
+
+func init() {
+	HealthCmd.Flags().String("format", "table", "output format: table or json")
+	HealthCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc) of the server")
+	HealthCmd.Flags().String("host", "", "MariaDB host (default from config)")
+	HealthCmd.Flags().Int("port", 0, "MariaDB port (default from config)")
+	HealthCmd.Flags().String("user", "", "MariaDB user (default from config)")
+	HealthCmd.Flags().String("password", "", "MariaDB password (default from config)")
+}
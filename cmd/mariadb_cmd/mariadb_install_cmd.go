@@ -1,11 +1,11 @@
 package mariadb_cmd
 
 import (
-	"context"
-
 	"sfDBTools/internal/core/mariadb/install"
 	"sfDBTools/internal/logger"
 	mariadb_config "sfDBTools/utils/mariadb/config"
+	"sfDBTools/utils/mariadb/discovery"
+	"sfDBTools/utils/output"
 	"sfDBTools/utils/terminal"
 
 	"github.com/spf13/cobra"
@@ -42,16 +42,21 @@ Contoh penggunaan:
   
   # Instalasi dengan environment variable
   SFDBTOOLS_MARIADB_VERSION=10.11 sudo sfdbtools mariadb install`,
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := executeMariaDBInstall(cmd, Lg); err != nil {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := executeMariaDBInstall(cmd, Lg)
+		if output.JSON() {
+			// Tidak ada prompt di mode JSON; hasil dan error ada di record akhir
+			return err
+		}
+		if err != nil {
 			terminal.PrintError("Instalasi MariaDB gagal")
 			terminal.WaitForEnterWithMessage("Tekan Enter untuk melanjutkan...")
 			// Jangan panggil os.Exit di sini; biarkan Cobra menangani exit code
-		} else {
-			terminal.PrintSuccess("Instalasi MariaDB selesai")
-			terminal.WaitForEnterWithMessage("Tekan Enter untuk melanjutkan...")
-			return
+			return err
 		}
+		terminal.PrintSuccess("Instalasi MariaDB selesai")
+		terminal.WaitForEnterWithMessage("Tekan Enter untuk melanjutkan...")
+		return nil
 	},
 }
 
@@ -64,7 +69,9 @@ func init() {
 // executeMariaDBInstall menjalankan command instalasi MariaDB
 func executeMariaDBInstall(cmd *cobra.Command, lg *logger.Logger) error {
 	// Clear screen untuk UX yang lebih baik
-	terminal.ClearScreen()
+	if !output.JSON() {
+		terminal.ClearScreen()
+	}
 
 	// Resolve konfigurasi dari flags dan environment
 	cfg, err := mariadb_config.ResolveMariaDBInstallConfig(cmd)
@@ -83,11 +90,17 @@ func executeMariaDBInstall(cmd *cobra.Command, lg *logger.Logger) error {
 		logger.Bool("non_interactive", cfg.NonInteractive))
 
 	// Jalankan instalasi - semua logic di core
-	ctx := context.Background()
+	ctx := cmd.Context()
 	if err := install.RunMariaDBInstall(ctx, cfg, cfgPost); err != nil {
 		// Spinner already displayed a user-facing error; return the error to Cobra.
 		return err
 	}
 
+	// Hasil untuk --output json: instalasi yang terdeteksi setelah selesai
+	if installation, err := discovery.DiscoverMariaDBInstallation(); err == nil {
+		output.SetResult(installation)
+	} else {
+		output.SetResult(map[string]string{"version": cfg.Version})
+	}
 	return nil
 }
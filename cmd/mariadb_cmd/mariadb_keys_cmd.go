@@ -0,0 +1,179 @@
+package mariadb_cmd
+
+import (
+	"fmt"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/mariadb/keys"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// KeysCmd groups encryption key management commands
+var KeysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage data-at-rest encryption keys (file_key_management)",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Add a new encryption key, re-encrypt tables with it and retire old keys",
+	Long: `Rotate the InnoDB data-at-rest encryption key managed by the file_key_management plugin.
+
+Steps:
+  1. Append a new key id to the key file (the old file is backed up)
+  2. Set innodb_default_encryption_key_id to the new id in the server config
+  3. Restart MariaDB so the plugin loads the new key
+  4. Rebuild each user table with ENCRYPTION_KEY_ID=<new id>, showing progress
+  5. With --retire-old, remove keys no tablespace uses anymore (key 1 is always kept)
+
+Rebuilding tables takes time and I/O proportional to their size.`,
+	Example: `sfDBTools mariadb keys rotate
+sfDBTools mariadb keys rotate --retire-old
+sfDBTools mariadb keys rotate --skip-restart   # prepare key and config only`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeKeysRotate(cmd)
+	},
+}
+
+var keysStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which key ids encrypt which tablespaces and re-encryption progress",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeKeysStatus(cmd)
+	},
+}
+
+func resolveKeysDBConfig(cmd *cobra.Command) (database.Config, error) {
+	host, port, user, password, err := config.GetDatabaseCredentials()
+	if err != nil {
+		return database.Config{}, err
+	}
+	return database.Config{
+		Host:     common.GetStringFlagOrEnv(cmd, "host", "MARIADB_HOST", host),
+		Port:     common.GetIntFlagOrEnv(cmd, "port", "MARIADB_PORT", port),
+		User:     common.GetStringFlagOrEnv(cmd, "user", "MARIADB_USER", user),
+		Password: common.GetStringFlagOrEnv(cmd, "password", "MARIADB_PASSWORD", password),
+	}, nil
+}
+
+func executeKeysRotate(cmd *cobra.Command) error {
+	dbConfig, err := resolveKeysDBConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	skipRestart, _ := cmd.Flags().GetBool("skip-restart")
+	retireOld, _ := cmd.Flags().GetBool("retire-old")
+	opts := keys.RotateOptions{
+		DB:          dbConfig,
+		KeyFile:     common.GetStringFlagOrEnv(cmd, "key-file", "MARIADB_KEY_FILE", ""),
+		ConfigFile:  common.GetStringFlagOrEnv(cmd, "config-file", "MARIADB_CONFIG_FILE", ""),
+		ServiceName: common.GetStringFlagOrEnv(cmd, "service", "MARIADB_SERVICE", ""),
+		SkipRestart: skipRestart,
+		RetireOld:   retireOld,
+	}
+
+	terminal.Headers("MariaDB - Encryption Key Rotation")
+	if !skipRestart && !terminal.Confirm("This restarts MariaDB and rebuilds encrypted tables. Continue?", false) {
+		return fmt.Errorf("key rotation cancelled by user")
+	}
+
+	result, err := keys.Rotate(opts)
+	if result != nil {
+		displayRotateResult(result)
+	}
+	return err
+}
+
+func displayRotateResult(r *keys.RotateResult) {
+	if r.NewKeyID == 0 {
+		return
+	}
+	terminal.PrintSubHeader("Rotation Result")
+	fmt.Printf("Key id:            %d -> %d\n", r.OldKeyID, r.NewKeyID)
+	fmt.Printf("Key file backup:   %s\n", r.KeyFileBackup)
+	fmt.Printf("Server config:     %s\n", r.ConfigFile)
+
+	if r.Pending {
+		terminal.PrintWarning("Restart skipped: restart MariaDB, then run 'sfDBTools mariadb keys rotate' again or re-encrypt tables manually")
+		return
+	}
+	fmt.Printf("Re-encrypted:      %d table(s)\n", len(r.Reencrypted))
+	for name, err := range r.Failed {
+		terminal.PrintError(fmt.Sprintf("%s: %v", name, err))
+	}
+	for _, name := range r.Skipped {
+		terminal.PrintWarning(fmt.Sprintf("Skipped %s (encoded name, re-encrypt manually)", name))
+	}
+	if len(r.Retired) > 0 {
+		terminal.PrintInfo(fmt.Sprintf("Retired key ids: %v", r.Retired))
+	}
+	if len(r.Failed) == 0 {
+		terminal.PrintSuccess("Encryption key rotated")
+	}
+}
+
+func executeKeysStatus(cmd *cobra.Command) error {
+	dbConfig, err := resolveKeysDBConfig(cmd)
+	if err != nil {
+		return err
+	}
+	db, err := database.GetWithoutDB(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var defaultKey int
+	if err := db.QueryRow("SELECT @@GLOBAL.innodb_default_encryption_key_id").Scan(&defaultKey); err != nil {
+		return fmt.Errorf("failed to read innodb_default_encryption_key_id: %w", err)
+	}
+	tablespaces, err := keys.ListTablespaces(db)
+	if err != nil {
+		return err
+	}
+
+	terminal.PrintSubHeader(fmt.Sprintf("Encryption keys (default key id: %d)", defaultKey))
+	var rows [][]string
+	for _, u := range keys.SummarizeUsage(tablespaces) {
+		rows = append(rows, []string{fmt.Sprintf("%d", u.KeyID), fmt.Sprintf("%d", u.Tablespaces), fmt.Sprintf("%d", u.Rotating)})
+	}
+	terminal.FormatTable([]string{"Key ID", "Tablespaces", "Rotating"}, rows)
+
+	var rotating [][]string
+	for _, ts := range tablespaces {
+		if ts.Rotating && ts.PagesTotal > 0 {
+			pct := float64(ts.PagesDone) * 100 / float64(ts.PagesTotal)
+			rotating = append(rotating, []string{ts.Name, fmt.Sprintf("%d", ts.KeyID), fmt.Sprintf("%.1f%%", pct)})
+		}
+	}
+	if len(rotating) > 0 {
+		terminal.PrintSubHeader("Tablespaces being re-encrypted")
+		terminal.FormatTable([]string{"Tablespace", "Key ID", "Progress"}, rotating)
+	}
+	return nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{keysRotateCmd, keysStatusCmd} {
+		c.Flags().String("host", "", "MariaDB host (default from config)")
+		c.Flags().Int("port", 0, "MariaDB port (default from config)")
+		c.Flags().String("user", "", "MariaDB user (default from config)")
+		c.Flags().String("password", "", "MariaDB password (default from config)")
+	}
+	keysRotateCmd.Flags().String("key-file", "", "key file path (default: @@file_key_management_filename)")
+	keysRotateCmd.Flags().String("config-file", "", "server config file holding the encryption settings (default: discovered)")
+	keysRotateCmd.Flags().String("service", "", "MariaDB service name (default: discovered)")
+	keysRotateCmd.Flags().Bool("skip-restart", false, "only add the key and update config, without restarting or re-encrypting")
+	keysRotateCmd.Flags().Bool("retire-old", false, "remove keys no longer used by any tablespace after re-encryption")
+
+	KeysCmd.AddCommand(keysRotateCmd)
+	KeysCmd.AddCommand(keysStatusCmd)
+}
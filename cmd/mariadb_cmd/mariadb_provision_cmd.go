@@ -0,0 +1,139 @@
+package mariadb_cmd
+
+import (
+	"fmt"
+
+	"sfDBTools/internal/core/mariadb/provision"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// ProvisionCmd applies a provisioning template of databases, users and grants
+var ProvisionCmd = &cobra.Command{
+	Use:   "provision",
+	Short: "Create databases, users and grants from a provisioning template",
+	Long: `Apply a YAML provisioning template to a server. The template lists databases,
+users with their passwords and grants, databases to drop and whether anonymous
+users are removed. 'mariadb install' applies the template configured as
+config_dir.mariadb_provisioning; this command applies it (or --file) again, e.g.
+after adding a user.
+
+Values are Go templates: {{ .ClientCode }} is general.client_code,
+{{ env "NAME" }} reads an environment variable and {{ file "/path" }} a file,
+so passwords come from the environment or a secret file instead of the template.
+
+Applying is idempotent. Databases and users are only created when missing and
+grants are added. An existing user keeps its password unless its entry sets
+reset_password. When a user that does not exist has no password, nothing is
+changed.`,
+	Example: `SFDB_PAPP_PASSWORD=... sfDBTools mariadb provision --dry-run
+sfDBTools mariadb provision --file ./config/templates/provisioning.yaml --config ./config/local.cnf.enc
+sfDBTools mariadb provision --client-code acme --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeProvision(cmd)
+	},
+}
+
+func executeProvision(cmd *cobra.Command) error {
+	path := ""
+	clientCode := ""
+	if Cfg != nil {
+		path = Cfg.ConfigDir.MariaDBProvisioning
+		clientCode = Cfg.General.ClientCode
+	}
+	path = common.GetStringFlagOrEnv(cmd, "file", "MARIADB_PROVISIONING", path)
+	if path == "" {
+		return common.WithExitCode(fmt.Errorf("--file is required when config_dir.mariadb_provisioning is not set"), common.ExitUsage)
+	}
+	clientCode = common.GetStringFlagOrEnv(cmd, "client-code", "CLIENT_CODE", clientCode)
+
+	tmpl, err := provision.Load(path, provision.Values{ClientCode: clientCode})
+	if err != nil {
+		return common.WithExitCode(err, common.ExitConfig)
+	}
+	dbConfig, err := resolveUserDBConfig(cmd, "config", "MARIADB_CONFIG")
+	if err != nil {
+		return err
+	}
+	db, err := database.GetWithoutDB(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	runner := provision.NewDBRunner(cmd.Context(), db)
+
+	terminal.Headers("MariaDB - Provisioning")
+	plan, err := provision.Plan(tmpl, runner)
+	if err != nil {
+		return err
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		for _, stmt := range plan.Statements {
+			fmt.Println(stmt.Display + ";")
+		}
+		return nil
+	}
+
+	skipConfirm := terminal.AssumeYes()
+	if !skipConfirm {
+		confirm := terminal.OperationPlan{
+			Operation: "Provisioning",
+			Source:    path,
+			Target:    fmt.Sprintf("%s:%d", dbConfig.Host, dbConfig.Port),
+			Actions: []string{
+				fmt.Sprintf("Create %d database(s) if missing", len(tmpl.Databases)),
+				fmt.Sprintf("Create %d user(s) and apply the grants of %d", len(plan.Created), len(tmpl.Users)),
+			},
+			Backup: terminal.BackupSafety{Protected: true, Detail: "existing databases and users are kept"},
+		}
+		if len(plan.Reset) > 0 {
+			confirm.Actions = append(confirm.Actions, fmt.Sprintf("Reset the password of %d existing user(s)", len(plan.Reset)))
+		}
+		for _, name := range tmpl.DropDatabases {
+			confirm.Destructive = append(confirm.Destructive, "Drop database "+name+" if it exists")
+		}
+		if tmpl.DropAnonymousUsers {
+			confirm.Destructive = append(confirm.Destructive, "Remove anonymous users")
+		}
+		if !terminal.ConfirmPlan(confirm, "Apply provisioning?") {
+			return common.WithExitCode(fmt.Errorf("provisioning cancelled by user"), common.ExitCancelled)
+		}
+	}
+
+	result, err := provision.Apply(tmpl, runner)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(tmpl.Users))
+	status := make(map[string]string)
+	for _, name := range result.Created {
+		status[name] = terminal.ColorText("created", terminal.ColorGreen)
+	}
+	for _, name := range result.Reset {
+		status[name] = "password reset"
+	}
+	for _, name := range result.Skipped {
+		status[name] = "exists"
+	}
+	for _, u := range tmpl.Users {
+		rows = append(rows, []string{u.Name(), status[u.Name()], fmt.Sprintf("%d", len(u.Grants))})
+	}
+	terminal.FormatTable([]string{"User", "Status", "Grants"}, rows)
+	terminal.PrintSuccess(fmt.Sprintf("Provisioning applied to %s:%d (%d statements)", dbConfig.Host, dbConfig.Port, len(result.Statements)))
+	return nil
+}
+
+func init() {
+	ProvisionCmd.Flags().String("file", "", "provisioning template (default: config_dir.mariadb_provisioning)")
+	ProvisionCmd.Flags().String("client-code", "", "value of {{ .ClientCode }} (default: general.client_code)")
+	ProvisionCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc) of the server")
+	ProvisionCmd.Flags().String("host", "", "MariaDB host (default from config)")
+	ProvisionCmd.Flags().Int("port", 0, "MariaDB port (default from config)")
+	ProvisionCmd.Flags().String("user", "", "MariaDB user (default from config)")
+	ProvisionCmd.Flags().String("password", "", "MariaDB password (default from config)")
+	ProvisionCmd.Flags().Bool("dry-run", false, "print the statements (passwords masked) without applying them")
+}
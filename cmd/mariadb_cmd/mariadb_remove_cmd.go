@@ -1,8 +1,6 @@
 package mariadb_cmd
 
 import (
-	"context"
-
 	"sfDBTools/internal/core/mariadb/remove"
 	"sfDBTools/internal/logger"
 	"sfDBTools/utils/common"
@@ -89,7 +87,7 @@ func executeMariaDBRemove(cmd *cobra.Command, args []string, lg *logger.Logger)
 		logger.Bool("backup_data", cfg.BackupData))
 
 	// Jalankan penghapusan - semua logic di core
-	ctx := context.Background()
+	ctx := cmd.Context()
 	if err := remove.RunMariaDBRemove(ctx, cfg); err != nil {
 		lg.Error("Penghapusan MariaDB gagal", logger.Error(err))
 		terminal.SafePrintln("❌ Penghapusan gagal: " + err.Error())
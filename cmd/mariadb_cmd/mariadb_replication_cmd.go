@@ -0,0 +1,224 @@
+package mariadb_cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/core/replication"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/secrets"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+// ReplicationCmd groups primary/replica replication commands
+var ReplicationCmd = &cobra.Command{
+	Use:   "replication",
+	Short: "Set up primary/replica replication between two servers",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var replicationSetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Build a replica from a running primary and start replication",
+	Long: `Set up replication from a running primary to a replica in one run:
+
+  1. check the primary: binary logging must be on; server_id 0 and
+     binlog_format=STATEMENT are corrected at runtime
+  2. create the replication account on the primary (REPLICATION SLAVE)
+  3. give the replica a server_id no other server of the topology uses
+  4. copy a consistent snapshot of the primary's user databases to the
+     replica (mysqldump --single-transaction --master-data=2, piped straight in)
+  5. CHANGE MASTER TO the snapshot's binlog position (or its GTID with
+     --use-gtid) and START SLAVE
+  6. wait until both replication threads run and Seconds_Behind_Master is at
+     most --max-lag
+
+Binary logging cannot be switched on at runtime: when it is off the setup stops
+before changing anything. Settings changed at runtime are reported so they can
+be added to the server configuration. Accounts are not copied; use
+'mariadb user export' and 'mariadb user import' for them.
+
+A replica that already replicates is refused unless --force, which resets its
+replication first.`,
+	Example: `SFDB_REPLICATION_PASSWORD=secret sfDBTools mariadb replication setup --primary-config ./config/db1.cnf.enc --replica-config ./config/db2.cnf.enc
+sfDBTools mariadb replication setup --primary-host 10.0.0.1 --primary-user root --replica-host 10.0.0.2 --replica-user root --use-gtid --dry-run
+sfDBTools mariadb replication setup --primary-config ./config/db1.cnf.enc --primary-address db1.internal --replica-config ./config/db2.cnf.enc --force --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeReplicationSetup(cmd)
+	},
+}
+
+func executeReplicationSetup(cmd *cobra.Command) error {
+	primary, err := resolveReplicationEndpoint(cmd, "primary")
+	if err != nil {
+		return err
+	}
+	replica, err := resolveReplicationEndpoint(cmd, "replica")
+	if err != nil {
+		return err
+	}
+	maxLag, err := time.ParseDuration(common.GetStringFlagOrEnv(cmd, "max-lag", "REPLICATION_MAX_LAG", "60s"))
+	if err != nil || maxLag < 0 {
+		return common.WithExitCode(fmt.Errorf("invalid --max-lag: expected a duration such as 30s"), common.ExitUsage)
+	}
+	verifyTimeout, err := time.ParseDuration(common.GetStringFlagOrEnv(cmd, "verify-timeout", "REPLICATION_VERIFY_TIMEOUT", "5m"))
+	if err != nil || verifyTimeout <= 0 {
+		return common.WithExitCode(fmt.Errorf("invalid --verify-timeout: expected a duration such as 5m"), common.ExitUsage)
+	}
+	opts := replication.SetupOptions{
+		Primary:             primary,
+		Replica:             replica,
+		PrimaryAddress:      common.GetStringFlagOrEnv(cmd, "primary-address", "REPLICATION_PRIMARY_ADDRESS", ""),
+		ReplicationUser:     common.GetStringFlagOrEnv(cmd, "replication-user", "SFDB_REPLICATION_USER", "repl"),
+		ReplicationPassword: common.GetStringFlagOrEnv(cmd, "replication-password", "SFDB_REPLICATION_PASSWORD", ""),
+		ReplicationHost:     common.GetStringFlagOrEnv(cmd, "replication-host", "SFDB_REPLICATION_HOST", "%"),
+		ReplicaServerID:     common.GetIntFlagOrEnv(cmd, "replica-server-id", "REPLICATION_REPLICA_SERVER_ID", 0),
+		UseGTID:             common.GetBoolFlagOrEnv(cmd, "use-gtid", "REPLICATION_USE_GTID", false),
+		Force:               common.GetBoolFlagOrEnv(cmd, "force", "REPLICATION_FORCE", false),
+		MaxLag:              maxLag,
+		VerifyTimeout:       verifyTimeout,
+	}
+	if opts.ReplicationPassword, err = secrets.Resolve(opts.ReplicationPassword); err != nil {
+		return err
+	}
+	if opts.ReplicationPassword == "" {
+		opts.ReplicationPassword = terminal.AskPassword("Password for the replication user "+opts.ReplicationUser, "")
+	}
+	if opts.ReplicationPassword == "" {
+		return common.WithExitCode(errors.New("the replication user needs a password (--replication-password or SFDB_REPLICATION_PASSWORD)"), common.ExitUsage)
+	}
+
+	terminal.Headers("Replication - Setup")
+	plan, err := replication.PrepareSetup(opts)
+	if err != nil {
+		return err
+	}
+	summary := buildReplicationSetupPlan(plan)
+	if common.GetBoolFlagOrEnv(cmd, "dry-run", "REPLICATION_DRY_RUN", false) {
+		terminal.RenderPlanSummary(summary)
+		terminal.PrintInfo("Dry run: nothing was changed")
+		return nil
+	}
+	skipConfirm := terminal.AssumeYes()
+	if !skipConfirm && !terminal.ConfirmPlan(summary, "Set up replication?") {
+		return common.WithExitCode(errors.New("replication setup cancelled by user"), common.ExitCancelled)
+	}
+
+	result, err := replication.Setup(plan)
+	if err != nil {
+		return err
+	}
+	warnings.PrintSummary()
+	position := fmt.Sprintf("%s:%d", result.Bookmark.LogFile, result.Bookmark.LogPosition)
+	if opts.UseGTID {
+		position = "GTID " + result.Bookmark.GTIDPosition
+	}
+	terminal.PrintSuccess(fmt.Sprintf("%s:%d replicates from %s:%d, started at %s (%s copied)",
+		replica.Host, replica.Port, plan.PrimaryAddress, primary.Port, position, common.FormatSize(result.Bytes)))
+	if result.CaughtUp {
+		terminal.PrintSuccess(fmt.Sprintf("Replica is %s behind the primary", result.Lag))
+	} else {
+		terminal.PrintWarning(fmt.Sprintf("Replica is still %s behind the primary after %s; check it with SHOW SLAVE STATUS", result.Lag, opts.VerifyTimeout))
+	}
+	return nil
+}
+
+// resolveReplicationEndpoint reads --<role>-config, or --<role>-host and the
+// other connection flags of the primary or replica
+func resolveReplicationEndpoint(cmd *cobra.Command, role string) (database.Config, error) {
+	env := strings.ToUpper(role)
+	if configFile := common.GetStringFlagOrEnv(cmd, role+"-config", env+"_CONFIG", ""); configFile != "" {
+		if err := common.ValidateConfigFile(configFile); err != nil {
+			return database.Config{}, fmt.Errorf("invalid %s config file: %w", role, err)
+		}
+		host, port, user, password, err := common.GetDatabaseConfigFromEncrypted(configFile)
+		if err != nil {
+			return database.Config{}, fmt.Errorf("failed to load %s config: %w", role, err)
+		}
+		return database.Config{Host: host, Port: port, User: user, Password: password}, nil
+	}
+
+	host := common.GetStringFlagOrEnv(cmd, role+"-host", env+"_HOST", "")
+	if host == "" {
+		return database.Config{}, common.WithExitCode(fmt.Errorf("--%s-config or --%s-host is required", role, role), common.ExitUsage)
+	}
+	user, password, err := secrets.ResolveCredentials(
+		common.GetStringFlagOrEnv(cmd, role+"-user", env+"_USER", "root"),
+		common.GetStringFlagOrEnv(cmd, role+"-password", env+"_PASSWORD", ""))
+	if err != nil {
+		return database.Config{}, err
+	}
+	return database.Config{
+		Host:     host,
+		Port:     common.GetIntFlagOrEnv(cmd, role+"-port", env+"_PORT", 3306),
+		User:     user,
+		Password: password,
+	}, nil
+}
+
+func buildReplicationSetupPlan(plan *replication.SetupPlan) terminal.OperationPlan {
+	summary := terminal.OperationPlan{
+		Operation: "Replication Setup",
+		Source:    fmt.Sprintf("%s:%d (MariaDB %s)", plan.Primary.Host, plan.Primary.Port, plan.PrimaryVersion),
+		Target:    fmt.Sprintf("%s:%d (server_id %d)", plan.Replica.Host, plan.Replica.Port, plan.ReplicaServerID),
+		Databases: plan.Databases,
+		Backup: terminal.BackupSafety{
+			Protected: len(plan.Overwritten) == 0,
+			Detail:    "the replica is expected to be empty; back up databases listed as overwritten first",
+		},
+	}
+	if plan.SnapshotBytes > 0 {
+		summary.Actions = append(summary.Actions, fmt.Sprintf("Copy about %s from the primary", common.FormatSize(plan.SnapshotBytes)))
+	}
+	for _, stmt := range plan.PrimaryStatements {
+		summary.Actions = append(summary.Actions, "primary: "+replication.MaskPassword(stmt, plan.ReplicationPassword))
+	}
+	for _, stmt := range plan.ReplicaStatements {
+		summary.Actions = append(summary.Actions, "replica: "+stmt)
+	}
+	for _, stmt := range plan.ChangeMasterPreview() {
+		summary.Actions = append(summary.Actions, "replica: "+replication.MaskPassword(stmt, plan.ReplicationPassword))
+	}
+	summary.Actions = append(summary.Actions, fmt.Sprintf("Wait up to %s for Seconds_Behind_Master <= %s", plan.VerifyTimeout, plan.MaxLag))
+
+	if len(plan.Overwritten) > 0 {
+		summary.Destructive = append(summary.Destructive, "Overwrite replica databases: "+strings.Join(plan.Overwritten, ", "))
+	}
+	if plan.ResetReplication {
+		summary.Destructive = append(summary.Destructive, "RESET SLAVE ALL on the replica (drops its current replication setup)")
+	}
+	if len(plan.Extra) > 0 {
+		summary.Actions = append(summary.Actions, "Leave replica-only databases as they are: "+strings.Join(plan.Extra, ", "))
+	}
+	return summary
+}
+
+func init() {
+	for _, role := range []string{"primary", "replica"} {
+		replicationSetupCmd.Flags().String(role+"-config", "", "encrypted configuration file (.cnf.enc) of the "+role)
+		replicationSetupCmd.Flags().String(role+"-host", "", role+" host (without --"+role+"-config)")
+		replicationSetupCmd.Flags().Int(role+"-port", 0, role+" port (default 3306)")
+		replicationSetupCmd.Flags().String(role+"-user", "", role+" admin user (default root)")
+		replicationSetupCmd.Flags().String(role+"-password", "", role+" admin password, or a secret reference (vault://, awssm://, envfile://)")
+	}
+	replicationSetupCmd.Flags().String("primary-address", "", "host the replica connects to the primary at (default: the primary host)")
+	replicationSetupCmd.Flags().String("replication-user", "repl", "replication user created on the primary (or SFDB_REPLICATION_USER)")
+	replicationSetupCmd.Flags().String("replication-password", "", "password of the replication user (or SFDB_REPLICATION_PASSWORD)")
+	replicationSetupCmd.Flags().String("replication-host", "%", "host part of the replication account (or SFDB_REPLICATION_HOST)")
+	replicationSetupCmd.Flags().Int("replica-server-id", 0, "server_id for the replica (default: its own unless another server of the topology uses it)")
+	replicationSetupCmd.Flags().Bool("use-gtid", false, "replicate by GTID (MASTER_USE_GTID = slave_pos) instead of binlog file and position")
+	replicationSetupCmd.Flags().Bool("force", false, "replace replication the replica already has configured")
+	replicationSetupCmd.Flags().String("max-lag", "60s", "Seconds_Behind_Master the replica must reach to count as caught up")
+	replicationSetupCmd.Flags().String("verify-timeout", "5m", "how long to wait for the replica to catch up")
+	replicationSetupCmd.Flags().Bool("dry-run", false, "show the plan without changing anything")
+
+	ReplicationCmd.AddCommand(replicationSetupCmd)
+}
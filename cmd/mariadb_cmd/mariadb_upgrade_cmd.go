@@ -0,0 +1,148 @@
+package mariadb_cmd
+
+import (
+	"fmt"
+
+	"sfDBTools/internal/core/mariadb/install"
+	"sfDBTools/utils/common"
+	mariadb_config "sfDBTools/utils/mariadb/config"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// UpgradeCmd mengupgrade MariaDB yang terinstall dengan snapshot untuk rollback
+var UpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade MariaDB server ke versi dari flag atau konfigurasi",
+	Long: `Upgrade MariaDB server yang terinstall ke versi yang ditentukan melalui flag
+--version atau versi default dari file konfigurasi.
+
+Command ini akan:
+1. Menyimpan snapshot versi paket MariaDB yang terinstall, file konfigurasi
+   (/etc/my.cnf, /etc/my.cnf.d, /etc/mysql) dan file repository ke
+   /var/lib/sfDBTools/upgrade/<timestamp>
+2. Setup repository resmi MariaDB untuk versi tujuan
+3. Mengupgrade paket MariaDB yang terinstall
+4. Me-restart service dan memeriksa server berjalan dengan versi tujuan
+
+Dengan --rollback, bila upgrade atau pemeriksaan setelahnya gagal, repository
+lama dipasang kembali, paket diinstall ulang dengan versi persis dari snapshot
+dan konfigurasi dikembalikan. Tanpa --rollback, jalankan
+'mariadb upgrade rollback' untuk mengembalikan snapshot secara manual.
+
+Data directory tidak ikut di-snapshot. Server yang sudah berjalan dengan versi
+baru (mis. setelah mariadb-upgrade) belum tentu bisa dibuka oleh versi lama;
+buat backup sebelum upgrade. Upgrade memerlukan hak akses root (sudo).`,
+	Example: `  # Upgrade ke versi dari config file
+  sudo sfdbtools mariadb upgrade
+
+  # Upgrade ke 10.11 dan kembalikan versi lama bila gagal
+  sudo sfdbtools mariadb upgrade --version 10.11 --rollback`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeMariaDBUpgrade(cmd)
+	},
+}
+
+var upgradeRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Kembalikan paket dan konfigurasi MariaDB dari snapshot upgrade",
+	Long: `Install ulang paket MariaDB dengan versi persis dari snapshot yang dibuat
+'mariadb upgrade', pasang kembali repository lama dan kembalikan konfigurasi.
+Tanpa --snapshot, snapshot terbaru di /var/lib/sfDBTools/upgrade dipakai.`,
+	Example: `  sudo sfdbtools mariadb upgrade rollback
+  sudo sfdbtools mariadb upgrade rollback --snapshot /var/lib/sfDBTools/upgrade/20250101-020000`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeMariaDBUpgradeRollback(cmd)
+	},
+}
+
+func init() {
+	UpgradeCmd.Flags().StringP("version", "v", "", "Versi MariaDB tujuan upgrade (default dari config atau 10.6.23)")
+	UpgradeCmd.Flags().Bool("rollback", false, "Kembalikan versi dan konfigurasi sebelumnya bila upgrade atau pemeriksaan setelahnya gagal")
+	upgradeRollbackCmd.Flags().String("snapshot", "", "Direktori snapshot yang dikembalikan (default: snapshot terbaru)")
+	UpgradeCmd.AddCommand(upgradeRollbackCmd)
+}
+
+// executeMariaDBUpgrade menjalankan command upgrade MariaDB
+func executeMariaDBUpgrade(cmd *cobra.Command) error {
+	cfg, err := mariadb_config.ResolveMariaDBInstallConfig(cmd)
+	if err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	opts := install.UpgradeOptions{
+		Rollback: common.GetBoolFlagOrEnv(cmd, "rollback", "SFDBTOOLS_UPGRADE_ROLLBACK", false),
+	}
+
+	plan := terminal.OperationPlan{
+		Operation: "MariaDB Upgrade",
+		Target:    "MariaDB " + cfg.Version,
+		Actions: []string{
+			"Snapshot versi paket, konfigurasi dan repository",
+			"Upgrade paket MariaDB dan restart service",
+		},
+		Destructive: []string{"Service MariaDB di-restart"},
+		Backup:      terminal.BackupSafety{Detail: "paket dan konfigurasi di-snapshot; data directory tidak"},
+	}
+	if opts.Rollback {
+		plan.Actions = append(plan.Actions, "Rollback otomatis bila upgrade atau pemeriksaan gagal")
+	}
+	if !terminal.ConfirmPlan(plan, "Lanjutkan upgrade?") {
+		return fmt.Errorf("upgrade dibatalkan oleh user")
+	}
+
+	result, err := install.RunMariaDBUpgrade(cmd.Context(), cfg, opts)
+	if result != nil {
+		output.SetResult(result)
+		if result.SnapshotDir != "" && !output.JSON() {
+			fmt.Printf("Snapshot: %s\n", result.SnapshotDir)
+		}
+	}
+	if err != nil {
+		terminal.PrintError("Upgrade MariaDB gagal")
+		return err
+	}
+	terminal.PrintSuccess(fmt.Sprintf("MariaDB diupgrade dari %s ke %s", result.PreviousVersion, result.Version))
+	return nil
+}
+
+// executeMariaDBUpgradeRollback mengembalikan snapshot upgrade secara manual
+func executeMariaDBUpgradeRollback(cmd *cobra.Command) error {
+	dir := common.GetStringFlagOrEnv(cmd, "snapshot", "SFDBTOOLS_UPGRADE_SNAPSHOT", "")
+	if dir == "" {
+		latest, err := install.LatestUpgradeSnapshot()
+		if err != nil {
+			return err
+		}
+		dir = latest
+	}
+	snap, err := install.LoadUpgradeSnapshot(dir)
+	if err != nil {
+		return err
+	}
+
+	plan := terminal.OperationPlan{
+		Operation: "MariaDB Upgrade Rollback",
+		Source:    snap.Dir,
+		Target:    "MariaDB " + snap.Version,
+		Actions: []string{
+			"Pasang kembali repository dari snapshot",
+			fmt.Sprintf("Install ulang %d paket dengan versi dari snapshot", len(snap.Packages)),
+			"Kembalikan konfigurasi dari snapshot",
+		},
+		Destructive: []string{"Paket MariaDB di-downgrade dan service di-restart", "Konfigurasi saat ini diganti dengan salinan di snapshot"},
+		Backup:      terminal.BackupSafety{Detail: "data directory tidak disentuh"},
+	}
+	if !terminal.ConfirmPlan(plan, "Lanjutkan rollback?") {
+		return fmt.Errorf("rollback dibatalkan oleh user")
+	}
+
+	if err := install.RollbackMariaDBUpgrade(snap); err != nil {
+		terminal.PrintError("Rollback MariaDB gagal")
+		return err
+	}
+	output.SetResult(map[string]string{"version": snap.Version, "snapshot_dir": snap.Dir})
+	terminal.PrintSuccess(fmt.Sprintf("MariaDB dikembalikan ke %s", snap.Version))
+	return nil
+}
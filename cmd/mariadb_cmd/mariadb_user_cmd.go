@@ -0,0 +1,260 @@
+package mariadb_cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/mariadb/users"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/secrets"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// UserCmd groups account export and import commands
+var UserCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Export and import database accounts between servers",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var userExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export users, authentication, TLS requirements, limits and grants to YAML",
+	Long: `Export accounts into a portable YAML file that 'mariadb user import' can apply to another server.
+
+Each entry holds the authentication plugin and stored hash, the REQUIRE (TLS)
+settings, resource limits and the account's GRANT statements. Roles are exported
+too. Built-in accounts (root, mariadb.sys, ...) and configured system users are
+skipped unless --include-system is given.
+
+The file contains password hashes: keep it as protected as a backup.`,
+	Example: `sfDBTools mariadb user export --file users.yaml
+sfDBTools mariadb user export --file app_users.yaml --users app,report@10.0.%
+sfDBTools mariadb user export --file users.yaml --source-config ./config/source.cnf.enc`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeUserExport(cmd)
+	},
+}
+
+var userImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Recreate users and grants from a YAML export on a target server",
+	Long: `Import accounts from a file written by 'mariadb user export'.
+
+Before anything is changed each account is checked against the target:
+  - its authentication plugin must be active there
+  - its stored hash must match the format that plugin expects
+    (e.g. '*' + 40 hex for mysql_native_password)
+Incompatible accounts are skipped. Accounts that already exist are skipped
+unless --replace is given, which recreates them with CREATE OR REPLACE.`,
+	Example: `sfDBTools mariadb user import --file users.yaml --target-config ./config/target.cnf.enc
+sfDBTools mariadb user import --file users.yaml --target-config ./config/target.cnf.enc --dry-run
+sfDBTools mariadb user import --file users.yaml --host 10.0.0.5 --user admin --replace`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeUserImport(cmd)
+	},
+}
+
+// resolveUserDBConfig loads the connection from an encrypted config file given
+// by configFlag, falling back to the default credentials and connection flags
+func resolveUserDBConfig(cmd *cobra.Command, configFlag, configEnv string) (database.Config, error) {
+	if configFile := common.GetStringFlagOrEnv(cmd, configFlag, configEnv, ""); configFile != "" {
+		if err := common.ValidateConfigFile(configFile); err != nil {
+			return database.Config{}, fmt.Errorf("invalid %s file: %w", configFlag, err)
+		}
+		host, port, user, password, err := common.GetDatabaseConfigFromEncrypted(configFile)
+		if err != nil {
+			return database.Config{}, fmt.Errorf("failed to load %s: %w", configFile, err)
+		}
+		return database.Config{Host: host, Port: port, User: user, Password: password}, nil
+	}
+
+	host, port, user, password, err := config.GetDatabaseCredentials()
+	if err != nil {
+		return database.Config{}, err
+	}
+	user, password, err = secrets.ResolveCredentials(
+		common.GetStringFlagOrEnv(cmd, "user", "MARIADB_USER", user),
+		common.GetStringFlagOrEnv(cmd, "password", "MARIADB_PASSWORD", password))
+	if err != nil {
+		return database.Config{}, err
+	}
+	return database.Config{
+		Host:     common.GetStringFlagOrEnv(cmd, "host", "MARIADB_HOST", host),
+		Port:     common.GetIntFlagOrEnv(cmd, "port", "MARIADB_PORT", port),
+		User:     user,
+		Password: password,
+	}, nil
+}
+
+func userFilter(cmd *cobra.Command) []string {
+	raw, _ := cmd.Flags().GetString("users")
+	var names []string
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func executeUserExport(cmd *cobra.Command) error {
+	file, _ := cmd.Flags().GetString("file")
+	if file == "" {
+		return fmt.Errorf("--file is required")
+	}
+	dbConfig, err := resolveUserDBConfig(cmd, "source-config", "SOURCE_CONFIG")
+	if err != nil {
+		return err
+	}
+	db, err := database.GetWithoutDB(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	includeSystem, _ := cmd.Flags().GetBool("include-system")
+	doc, err := users.Export(db, users.ExportOptions{
+		SourceHost:    fmt.Sprintf("%s:%d", dbConfig.Host, dbConfig.Port),
+		Users:         userFilter(cmd),
+		IncludeSystem: includeSystem,
+	})
+	if err != nil {
+		return err
+	}
+	if len(doc.Users) == 0 {
+		return fmt.Errorf("no accounts matched the export filter")
+	}
+	if err := users.Save(file, doc); err != nil {
+		return err
+	}
+
+	terminal.PrintSuccess(fmt.Sprintf("Exported %d account(s) from %s (%s) to %s", len(doc.Users), doc.SourceHost, doc.ServerVersion, file))
+	return nil
+}
+
+func executeUserImport(cmd *cobra.Command) error {
+	file, _ := cmd.Flags().GetString("file")
+	if file == "" {
+		return fmt.Errorf("--file is required")
+	}
+	doc, err := users.Load(file)
+	if err != nil {
+		return err
+	}
+	dbConfig, err := resolveUserDBConfig(cmd, "target-config", "TARGET_CONFIG")
+	if err != nil {
+		return err
+	}
+	db, err := database.GetWithoutDB(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	replace, _ := cmd.Flags().GetBool("replace")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	skipConfirm := terminal.AssumeYes()
+	opts := users.ImportOptions{Replace: replace, DryRun: dryRun}
+
+	doc.Select(userFilter(cmd))
+	if len(doc.Users) == 0 {
+		return fmt.Errorf("no accounts in %s matched the import filter", file)
+	}
+
+	terminal.Headers("MariaDB - User Import")
+	issues, err := users.CheckCompatibility(db, doc)
+	if err != nil {
+		return err
+	}
+	displayUserIssues(issues)
+
+	if !dryRun && !skipConfirm {
+		plan := terminal.OperationPlan{
+			Operation: "User Import",
+			Source:    fmt.Sprintf("%s (%s, exported %s)", file, doc.ServerVersion, common.HumanizeTime(doc.ExportedAt)),
+			Target:    fmt.Sprintf("%s:%d", dbConfig.Host, dbConfig.Port),
+			Actions:   []string{fmt.Sprintf("Create up to %d account(s) and apply their grants", len(doc.Users))},
+			Backup:    terminal.BackupSafety{Protected: !replace, Detail: "existing accounts are left untouched"},
+		}
+		if replace {
+			plan.Destructive = []string{"Recreate existing accounts (CREATE OR REPLACE)"}
+			plan.Backup.Detail = "replaced accounts lose their current password and grants"
+		}
+		if !terminal.ConfirmPlan(plan, "Proceed with import?") {
+			return fmt.Errorf("user import cancelled by user")
+		}
+	}
+
+	result, err := users.Import(db, doc, opts)
+	if err != nil {
+		return err
+	}
+	displayImportResult(result, dryRun)
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d account(s) failed to import", len(result.Failed))
+	}
+	return nil
+}
+
+func displayUserIssues(issues []users.Issue) {
+	if len(issues) == 0 {
+		terminal.PrintSuccess("All accounts are compatible with the target")
+		return
+	}
+	terminal.PrintSubHeader("Compatibility Check")
+	var rows [][]string
+	for _, issue := range issues {
+		severity := "⚠️  warning"
+		if issue.Fatal {
+			severity = "❌ skipped"
+		}
+		rows = append(rows, []string{issue.Account, severity, issue.Message})
+	}
+	terminal.FormatTable([]string{"Account", "Result", "Details"}, rows)
+}
+
+func displayImportResult(r *users.ImportResult, dryRun bool) {
+	if dryRun {
+		terminal.PrintSubHeader("Statements (dry run, hashes redacted)")
+		for _, stmt := range r.Statements {
+			fmt.Println(stmt + ";")
+		}
+	}
+	for name, reason := range r.Skipped {
+		terminal.PrintWarning(fmt.Sprintf("Skipped %s: %s", name, reason))
+	}
+	for name, err := range r.Failed {
+		terminal.PrintError(fmt.Sprintf("%s: %v", name, err))
+	}
+	if !dryRun {
+		terminal.PrintSuccess(fmt.Sprintf("Created %d, replaced %d, skipped %d, failed %d account(s)",
+			len(r.Created), len(r.Replaced), len(r.Skipped), len(r.Failed)))
+	}
+}
+
+func init() {
+	for _, c := range []*cobra.Command{userExportCmd, userImportCmd} {
+		c.Flags().String("file", "", "YAML file to write or read (required)")
+		c.Flags().String("users", "", "comma-separated user or user@host names (default: all)")
+		c.Flags().String("host", "", "MariaDB host (default from config)")
+		c.Flags().Int("port", 0, "MariaDB port (default from config)")
+		c.Flags().String("user", "", "MariaDB user (default from config)")
+		c.Flags().String("password", "", "MariaDB password (default from config)")
+	}
+	userExportCmd.Flags().String("source-config", "", "source encrypted configuration file (.cnf.enc)")
+	userExportCmd.Flags().Bool("include-system", false, "also export built-in accounts and configured system users")
+
+	userImportCmd.Flags().String("target-config", "", "target encrypted configuration file (.cnf.enc)")
+	userImportCmd.Flags().Bool("replace", false, "recreate accounts that already exist on the target")
+	userImportCmd.Flags().Bool("dry-run", false, "show the statements without changing the target")
+
+	UserCmd.AddCommand(userExportCmd)
+	UserCmd.AddCommand(userImportCmd)
+}
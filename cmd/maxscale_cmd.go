@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	maxscale_cmd "sfDBTools/cmd/maxscale_cmd"
+	"sfDBTools/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var MaxScaleCmd = &cobra.Command{
+	Use:   "maxscale",
+	Short: "MaxScale proxy integration commands",
+	Long:  "Install MaxScale, configure it for the detected database servers, control its service, reload it and query server states through its REST API.",
+	Run: func(cmd *cobra.Command, args []string) {
+		lg, _ := logger.Get()
+		lg.Info("MaxScale command executed")
+		cmd.Help()
+	},
+	Annotations: map[string]string{
+		"command":  "maxscale",
+		"category": "proxy",
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(MaxScaleCmd)
+	MaxScaleCmd.AddCommand(maxscale_cmd.InstallCmd)
+	MaxScaleCmd.AddCommand(maxscale_cmd.ConfigureCmd)
+	MaxScaleCmd.AddCommand(maxscale_cmd.ServiceCmd)
+	MaxScaleCmd.AddCommand(maxscale_cmd.GenerateCmd)
+	MaxScaleCmd.AddCommand(maxscale_cmd.ReloadCmd)
+	MaxScaleCmd.AddCommand(maxscale_cmd.StatusCmd)
+}
@@ -0,0 +1,192 @@
+package maxscale_cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	core "sfDBTools/internal/core/maxscale"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/maxscale"
+	"sfDBTools/utils/system"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// ConfigureCmd renders maxscale.cnf for the detected servers and (re)starts MaxScale
+var ConfigureCmd = &cobra.Command{
+	Use:   "configure",
+	Short: "Detect the backend servers, write maxscale.cnf and start MaxScale",
+	Long: `Detect the database servers MaxScale should front, write maxscale.cnf and restart
+the MaxScale service.
+
+Servers are detected from a seed server (--seed, default the first entry of
+maxscale.servers), connecting with the monitor user:
+  - a Galera node lists the cluster (wsrep_incoming_addresses) and the
+    galeramon monitor is used
+  - a replica leads to its primary; the primary lists the replicas that set
+    report_host, and the mariadbmon monitor is used with automatic failover
+--servers skips the detection and uses the given list as is.
+
+The previous maxscale.cnf is kept next to the new one. When MaxScale does not
+come back with the new configuration, the previous one is restored.`,
+	Example: `  sudo sfDBTools maxscale configure --seed 10.0.0.1:3306 --dry-run
+  sudo sfDBTools maxscale configure --servers 10.0.0.1:3306,10.0.0.2:3306 --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeConfigure(cmd)
+	},
+}
+
+func init() {
+	ConfigureCmd.Flags().String("seed", "", "host:port of any server of the topology to detect the others from (default: first of maxscale.servers)")
+	ConfigureCmd.Flags().String("servers", "", "comma-separated host:port list to use instead of detecting the servers")
+	ConfigureCmd.Flags().String("monitor", "", "monitor module (default: galeramon for Galera, otherwise mariadbmon)")
+	addTopologyFlags(ConfigureCmd)
+	maxscale.AddAPIFlags(ConfigureCmd)
+	ConfigureCmd.Flags().Bool("dry-run", false, "print the configuration (passwords masked) without writing it (env SFDBTOOLS_DRY_RUN)")
+}
+
+func executeConfigure(cmd *cobra.Command) error {
+	dryRun := common.GetBoolFlagOrEnv(cmd, "dry-run", "SFDBTOOLS_DRY_RUN", false)
+	if !dryRun {
+		if err := system.CheckPrivileges(); err != nil {
+			return err
+		}
+	}
+	topology, err := resolveTopology(cmd)
+	if err != nil {
+		return err
+	}
+
+	terminal.Headers("MaxScale Configuration")
+	monitor := "mariadbmon"
+	if list := common.GetStringFlagOrEnv(cmd, "servers", "MAXSCALE_SERVERS", ""); list != "" {
+		if topology.Servers, err = maxscale.ParseServers(strings.Split(list, ",")); err != nil {
+			return common.WithExitCode(err, common.ExitUsage)
+		}
+	} else {
+		seed, err := resolveSeed(cmd, topology)
+		if err != nil {
+			return err
+		}
+		terminal.PrintInfo(fmt.Sprintf("Detecting servers from %s:%d", seed.Host, seed.Port))
+		backends, err := core.DiscoverBackends(seed)
+		if err != nil {
+			return err
+		}
+		topology.Servers = backends.Servers
+		monitor = backends.Monitor()
+	}
+	topology.Monitor = common.GetStringFlagOrEnv(cmd, "monitor", "MAXSCALE_MONITOR", monitor)
+	printServers(topology)
+
+	content, err := maxscale.GenerateConfig(topology)
+	if err != nil {
+		return err
+	}
+	output := resolveConfigFile(cmd)
+
+	if dryRun {
+		fmt.Println(strings.ReplaceAll(content, "password="+topology.MonitorPassword+"\n", "password=***\n"))
+		terminal.PrintSuccess("Dry run complete: " + output + " was not changed")
+		return nil
+	}
+	client, err := maxscale.ResolveClient(cmd)
+	if err != nil {
+		return err
+	}
+	skipConfirm := terminal.AssumeYes()
+	if !skipConfirm && !terminal.ConfirmPlan(buildConfigurePlan(topology, output), "Write the configuration and restart MaxScale?") {
+		return common.WithExitCode(errors.New("configure cancelled by user"), common.ExitCancelled)
+	}
+
+	result, err := core.Configure(core.ConfigureOptions{
+		ConfigFile: output,
+		Content:    content,
+		Client:     client,
+	})
+	if result != nil && result.Backup != "" {
+		terminal.PrintInfo(fmt.Sprintf("Previous configuration saved to %s", result.Backup))
+	}
+	if err != nil {
+		return err
+	}
+
+	terminal.PrintSuccess(fmt.Sprintf("MaxScale configured with %d server(s) and running", len(topology.Servers)))
+	rows := make([][]string, 0, len(result.Servers))
+	for _, s := range result.Servers {
+		rows = append(rows, []string{s.Name, fmt.Sprintf("%s:%d", s.Address, s.Port), s.State})
+	}
+	if len(rows) > 0 {
+		terminal.FormatTable([]string{"Server", "Address", "State"}, rows)
+	}
+	return nil
+}
+
+// resolveSeed returns the server detection starts from, connecting with
+// the monitor account
+func resolveSeed(cmd *cobra.Command, topology maxscale.Topology) (database.Config, error) {
+	seed := common.GetStringFlagOrEnv(cmd, "seed", "MAXSCALE_SEED", "")
+	if seed == "" && len(Cfg.MaxScale.Servers) > 0 {
+		seed = Cfg.MaxScale.Servers[0]
+	}
+	if seed == "" {
+		return database.Config{}, common.WithExitCode(errors.New("no seed server: use --seed, --servers or set maxscale.servers"), common.ExitUsage)
+	}
+	host, port := seed, 3306
+	if i := strings.LastIndex(seed, ":"); i > 0 {
+		n, err := strconv.Atoi(seed[i+1:])
+		if err != nil || n <= 0 || n > 65535 {
+			return database.Config{}, common.WithExitCode(fmt.Errorf("invalid seed %q: expected host:port", seed), common.ExitUsage)
+		}
+		host, port = seed[:i], n
+	}
+	return database.Config{Host: host, Port: port, User: topology.MonitorUser, Password: topology.MonitorPassword}, nil
+}
+
+func printServers(topology maxscale.Topology) {
+	rows := make([][]string, 0, len(topology.Servers))
+	for i, s := range topology.Servers {
+		role := "member"
+		if topology.Monitor != "galeramon" {
+			role = "replica"
+			if i == 0 {
+				role = "primary"
+			}
+		}
+		rows = append(rows, []string{s.Name, fmt.Sprintf("%s:%d", s.Address, s.Port), role})
+	}
+	terminal.FormatTable([]string{"Server", "Address", "Role"}, rows)
+}
+
+func buildConfigurePlan(topology maxscale.Topology, output string) terminal.OperationPlan {
+	router := topology.Router
+	if router == "" {
+		router = "readwritesplit"
+	}
+	servers := make([]string, len(topology.Servers))
+	for i, s := range topology.Servers {
+		servers[i] = fmt.Sprintf("%s:%d", s.Address, s.Port)
+	}
+	return terminal.OperationPlan{
+		Operation: "MaxScale Configure",
+		Source:    strings.Join(servers, ", "),
+		Target:    output,
+		Actions: []string{
+			fmt.Sprintf("Write %s (%s router, %s monitor, listener port %d)", output, router, topology.Monitor, listenPort(topology)),
+			"Enable and restart service " + maxscale.ServiceName,
+		},
+		Destructive: []string{"Restart " + maxscale.ServiceName + " (drops client connections routed through it)"},
+		Backup:      terminal.BackupSafety{Protected: true, Detail: "the previous maxscale.cnf is kept as a .bak copy and restored if MaxScale fails to start"},
+	}
+}
+
+func listenPort(topology maxscale.Topology) int {
+	if topology.ListenPort == 0 {
+		return 4006
+	}
+	return topology.ListenPort
+}
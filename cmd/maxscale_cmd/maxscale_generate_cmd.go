@@ -0,0 +1,130 @@
+package maxscale_cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/maxscale"
+	"sfDBTools/utils/secrets"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// GenerateCmd renders maxscale.cnf for the configured servers
+var GenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate maxscale.cnf for the configured database servers",
+	Long: `Generate a MaxScale configuration for a common topology.
+Servers are taken from --servers or from maxscale.servers in config.yaml.
+The default topology is a readwritesplit service with a mariadbmon monitor.
+Use --router readconnroute for a simple read-only load balancer.`,
+	Example: `sfDBTools maxscale generate --servers 10.0.0.1:3306,10.0.0.2:3306 --print
+sfDBTools maxscale generate --output /etc/maxscale.cnf --reload`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeGenerate(cmd)
+	},
+}
+
+func executeGenerate(cmd *cobra.Command) error {
+	entries := Cfg.MaxScale.Servers
+	if s := common.GetStringFlagOrEnv(cmd, "servers", "MAXSCALE_SERVERS", ""); s != "" {
+		entries = strings.Split(s, ",")
+	}
+	servers, err := maxscale.ParseServers(entries)
+	if err != nil {
+		return err
+	}
+
+	topology, err := resolveTopology(cmd)
+	if err != nil {
+		return err
+	}
+	topology.Servers = servers
+	topology.Monitor = common.GetStringFlagOrEnv(cmd, "monitor", "MAXSCALE_MONITOR", "mariadbmon")
+
+	content, err := maxscale.GenerateConfig(topology)
+	if err != nil {
+		return err
+	}
+
+	if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+		fmt.Println(content)
+		return nil
+	}
+
+	output := resolveConfigFile(cmd)
+	backup, err := maxscale.WriteConfig(output, content)
+	if err != nil {
+		return err
+	}
+	terminal.PrintSuccess(fmt.Sprintf("MaxScale configuration written to %s", output))
+	if backup != "" {
+		terminal.PrintInfo(fmt.Sprintf("Previous configuration saved to %s", backup))
+	}
+
+	if reload, _ := cmd.Flags().GetBool("reload"); reload {
+		if err := maxscale.Reload(); err != nil {
+			return err
+		}
+		terminal.PrintSuccess("MaxScale reloaded")
+	}
+	return nil
+}
+
+func init() {
+	GenerateCmd.Flags().String("servers", "", "comma-separated host:port list (default from config maxscale.servers)")
+	GenerateCmd.Flags().String("monitor", "mariadbmon", "monitor module (mariadbmon for replication, galeramon for Galera)")
+	addTopologyFlags(GenerateCmd)
+	GenerateCmd.Flags().Bool("print", false, "print the configuration instead of writing it")
+	GenerateCmd.Flags().Bool("reload", false, "reload MaxScale after writing the configuration")
+}
+
+// addTopologyFlags adds the flags read by resolveTopology and resolveConfigFile
+func addTopologyFlags(cmd *cobra.Command) {
+	cmd.Flags().String("router", "readwritesplit", "router module (readwritesplit, readconnroute)")
+	cmd.Flags().String("monitor-user", "", "monitor/service user (default from config maxscale.monitor_user)")
+	cmd.Flags().String("monitor-password", "", "monitor/service user password, or a secret reference (vault://, awssm://, envfile://)")
+	cmd.Flags().Int("listen-port", 0, "client listener port (default from config maxscale.listen_port)")
+	cmd.Flags().String("output", "", "output file (default from config maxscale.config_file)")
+}
+
+// resolveTopology reads the router, listener and monitor/service account of
+// the configuration to generate; the servers are left to the caller
+func resolveTopology(cmd *cobra.Command) (maxscale.Topology, error) {
+	monitorUser := common.GetStringFlagOrEnv(cmd, "monitor-user", "MAXSCALE_MONITOR_USER", Cfg.MaxScale.MonitorUser)
+	if monitorUser == "" {
+		monitorUser = "maxscale"
+	}
+	monitorPassword := common.GetStringFlagOrEnv(cmd, "monitor-password", "MAXSCALE_MONITOR_PASSWORD", Cfg.MaxScale.MonitorPassword)
+	if monitorPassword == "" {
+		monitorPassword = terminal.AskPassword("Password for MaxScale monitor/service user", "")
+	}
+	monitorUser, monitorPassword, err := secrets.ResolveCredentials(monitorUser, monitorPassword)
+	if err != nil {
+		return maxscale.Topology{}, err
+	}
+
+	router := common.GetStringFlagOrEnv(cmd, "router", "MAXSCALE_ROUTER", "readwritesplit")
+	if router != "readwritesplit" && router != "readconnroute" {
+		return maxscale.Topology{}, fmt.Errorf("unsupported router %q (use readwritesplit or readconnroute)", router)
+	}
+
+	return maxscale.Topology{
+		MonitorUser:     monitorUser,
+		MonitorPassword: monitorPassword,
+		ServiceUser:     monitorUser,
+		ServicePassword: monitorPassword,
+		ListenPort:      common.GetIntFlagOrEnv(cmd, "listen-port", "MAXSCALE_LISTEN_PORT", Cfg.MaxScale.ListenPort),
+		Router:          router,
+	}, nil
+}
+
+func resolveConfigFile(cmd *cobra.Command) string {
+	output := common.GetStringFlagOrEnv(cmd, "output", "MAXSCALE_CONFIG_FILE", Cfg.MaxScale.ConfigFile)
+	if output == "" {
+		output = "/etc/maxscale.cnf"
+	}
+	return output
+}
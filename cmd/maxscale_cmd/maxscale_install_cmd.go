@@ -0,0 +1,44 @@
+package maxscale_cmd
+
+import (
+	"sfDBTools/internal/core/maxscale"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/system"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// InstallCmd installs MaxScale from the MariaDB repository
+var InstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install MaxScale and enable its service",
+	Long: `Install MaxScale from the official MariaDB repository and enable its systemd service.
+
+The repository is set up with mariadb_repo_setup for MaxScale only, so an
+installed MariaDB server repository is left alone. Without --version the latest
+MaxScale release is installed.
+
+The service is started by 'maxscale configure', once maxscale.cnf describes the
+database servers. Installation requires root.`,
+	Example: `  sudo sfDBTools maxscale install
+  sudo sfDBTools maxscale install --version 24.02 && sudo sfDBTools maxscale configure`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := system.CheckPrivileges(); err != nil {
+			return err
+		}
+		version := common.GetStringFlagOrEnv(cmd, "version", "SFDBTOOLS_MAXSCALE_VERSION", Cfg.MaxScale.Version)
+
+		terminal.Headers("MaxScale Installation")
+		if err := maxscale.Install(maxscale.InstallOptions{Version: version}); err != nil {
+			return err
+		}
+		terminal.PrintSuccess("MaxScale " + maxscale.InstalledVersion() + " installed, service enabled")
+		terminal.PrintInfo("Run 'sfDBTools maxscale configure' to write maxscale.cnf and start it")
+		return nil
+	},
+}
+
+func init() {
+	InstallCmd.Flags().String("version", "", "MaxScale release series, e.g. 24.02 (env SFDBTOOLS_MAXSCALE_VERSION, default maxscale.version)")
+}
@@ -0,0 +1,21 @@
+package maxscale_cmd
+
+import (
+	"sfDBTools/utils/maxscale"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// ReloadCmd reloads the MaxScale service
+var ReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reload MaxScale so it picks up configuration changes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := maxscale.Reload(); err != nil {
+			return err
+		}
+		terminal.PrintSuccess("MaxScale reloaded")
+		return nil
+	},
+}
@@ -0,0 +1,65 @@
+package maxscale_cmd
+
+import (
+	"fmt"
+
+	"sfDBTools/utils/maxscale"
+	"sfDBTools/utils/system"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// ServiceCmd controls the MaxScale systemd service
+var ServiceCmd = &cobra.Command{
+	Use:   "service <start|stop|restart|enable|disable|status>",
+	Short: "Control the MaxScale systemd service",
+	Example: `  sfDBTools maxscale service status
+  sudo sfDBTools maxscale service restart`,
+	ValidArgs: []string{"start", "stop", "restart", "enable", "disable", "status"},
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sm := system.NewServiceManager()
+		name := maxscale.ServiceName
+
+		var err error
+		switch args[0] {
+		case "status":
+			status, err := sm.GetStatus(name)
+			if err != nil {
+				return err
+			}
+			terminal.FormatTable([]string{"Service", "Active", "Enabled"}, [][]string{
+				{name, yesNo(status.Active), yesNo(status.Enabled)},
+			})
+			return nil
+		case "start":
+			err = sm.Start(name)
+		case "stop":
+			err = sm.Stop(name)
+		case "restart":
+			err = sm.Restart(name)
+		case "enable":
+			err = sm.Enable(name)
+		case "disable":
+			err = sm.Disable(name)
+		}
+		if err != nil {
+			return err
+		}
+		if args[0] == "start" || args[0] == "restart" {
+			if !sm.IsActive(name) {
+				return fmt.Errorf("service %s is not active after %s; see journalctl -u %s", name, args[0], name)
+			}
+		}
+		terminal.PrintSuccess(fmt.Sprintf("Service %s: %s done", name, args[0]))
+		return nil
+	},
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
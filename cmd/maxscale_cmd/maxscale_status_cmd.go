@@ -0,0 +1,45 @@
+package maxscale_cmd
+
+import (
+	"fmt"
+
+	"sfDBTools/utils/maxscale"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// StatusCmd shows server states reported by the MaxScale REST API
+var StatusCmd = &cobra.Command{
+	Use:     "status",
+	Short:   "Show server states from the MaxScale REST API",
+	Example: `sfDBTools maxscale status --api-host 10.0.0.10 --api-user admin --api-password vault://secret/maxscale#password`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := maxscale.ResolveClient(cmd)
+		if err != nil {
+			return err
+		}
+		servers, err := client.ListServers()
+		if err != nil {
+			return fmt.Errorf("failed to query MaxScale servers: %w", err)
+		}
+
+		headers := []string{"Server", "Address", "State", "Connections", "GTID"}
+		rows := make([][]string, 0, len(servers))
+		for _, s := range servers {
+			rows = append(rows, []string{
+				s.Name,
+				fmt.Sprintf("%s:%d", s.Address, s.Port),
+				s.State,
+				fmt.Sprintf("%d", s.Connections),
+				s.GTID,
+			})
+		}
+		terminal.FormatTable(headers, rows)
+		return nil
+	},
+}
+
+func init() {
+	maxscale.AddAPIFlags(StatusCmd)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/i18n"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+// reportedError marks an error the middleware has already logged and printed
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string { return e.err.Error() }
+
+func (e *reportedError) Unwrap() error { return e.err }
+
+// applyMiddleware wraps the run function of c and every subcommand with
+// withMiddleware. Commands keep either Run or RunE; Run is adapted.
+func applyMiddleware(c *cobra.Command) {
+	for _, child := range c.Commands() {
+		applyMiddleware(child)
+	}
+	run := c.RunE
+	if run == nil && c.Run != nil {
+		legacy := c.Run
+		run = func(cmd *cobra.Command, args []string) error {
+			legacy(cmd, args)
+			return nil
+		}
+	}
+	if run == nil {
+		return
+	}
+	c.Run = nil
+	c.RunE = withMiddleware(run)
+}
+
+// withMiddleware checks that the configuration is loaded, times the command,
+// logs its result with the exit code, recovers panics, classifies failures
+// after SIGINT/SIGTERM as interrupted, prints the warning summary and prints
+// errors once, so commands only need to return an error.
+func withMiddleware(run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) (err error) {
+		lg, _ := logger.Get()
+		name := cmd.CommandPath()
+		start := time.Now()
+
+		defer func() {
+			if r := recover(); r != nil {
+				if abort, ok := r.(terminal.PromptAbort); ok {
+					err = abort.Err
+				} else {
+					lg.Error("Command panicked",
+						logger.String("command", name),
+						logger.String("panic", fmt.Sprint(r)),
+						logger.String("stack", string(debug.Stack())))
+					err = common.WithExitCode(errors.New(i18n.T("error.internal", r)), common.ExitInternal)
+				}
+			}
+			// A prompt that timed out with prompt on_timeout abort cancels the command
+			if errors.Is(err, terminal.ErrPromptTimeout) && common.ExitCode(err) == common.ExitFailure {
+				err = common.WithExitCode(err, common.ExitCancelled)
+			}
+			// Whatever failed after a stop signal failed because of it
+			if err != nil && cmd.Context().Err() != nil {
+				err = common.WithExitCode(fmt.Errorf("interrupted: %w", err), common.ExitInterrupted)
+			}
+
+			warnings.PrintSummary()
+			code := common.ExitCode(err)
+			fields := []logger.Field{
+				logger.String("command", name),
+				logger.String("duration", time.Since(start).Round(time.Millisecond).String()),
+				logger.Int("exit_code", code),
+			}
+			if err == nil {
+				lg.Info("Command completed", fields...)
+				return
+			}
+			lg.Error("Command failed", append(fields, logger.Error(err))...)
+			terminal.PrintError(err.Error())
+			err = &reportedError{err: err}
+		}()
+
+		// Commands annotated config=optional report a broken config themselves
+		if _, cfgErr := config.Get(); cfgErr != nil && cmd.Annotations["config"] != "optional" {
+			return common.WithExitCode(fmt.Errorf("%s: %w", i18n.T("error.config_unavailable"), cfgErr), common.ExitConfig)
+		}
+		return run(cmd, args)
+	}
+}
+
+// finishExecute prints errors cobra returned before any command ran, such as
+// unknown flags or subcommands, and classifies them as usage errors
+func finishExecute(err error) error {
+	if err == nil {
+		return nil
+	}
+	var reported *reportedError
+	if errors.As(err, &reported) {
+		return reported.err
+	}
+	terminal.PrintError(err.Error())
+	fmt.Println(i18n.T("error.usage_hint"))
+	var exitErr *common.ExitError
+	if errors.As(err, &exitErr) {
+		return err
+	}
+	return common.WithExitCode(err, common.ExitUsage)
+}
@@ -29,4 +29,5 @@ var MigrateCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(MigrateCmd)
 	MigrateCmd.AddCommand(migrate_cmd.SelectionMigrateCmd)
+	MigrateCmd.AddCommand(migrate_cmd.CutoverMigrateCmd)
 }
@@ -0,0 +1,212 @@
+package migrate_cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/core/migrate/cutover"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/hooks"
+	migrate_utils "sfDBTools/utils/migrate"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+var CutoverMigrateCmd = &cobra.Command{
+	Use:   "cutover",
+	Short: "Switch application traffic from the source server to the migrated target",
+	Long: `This command performs the last mile of a migration after data has been synced.
+
+The cutover process includes:
+1. Set the source server read-only
+2. Wait until the target has caught up (replication lag or row count verification)
+3. Repoint ProxySQL/MaxScale to the target, or print DNS/connection-string instructions
+4. Verify that new writes land on the target
+
+If a step fails and --rollback is enabled, the proxy is switched back to the source
+and writes are re-enabled on it.
+When a proxy is switched, --verify-writes needs --traffic-host and --traffic-port so the
+marker row goes through the application path; without a proxy it is written to the target.
+With --source-protect the source is only read, which requires --skip-read-only.`,
+	Example: `sfDBTools migrate cutover --source-config ./config/source.cnf.enc --target-config ./config/target.cnf.enc --databases app_db
+sfDBTools migrate cutover --source-host 10.0.0.1 --target-host 10.0.0.2 --databases app_db --proxy proxysql --proxy-host 10.0.0.10 --proxy-port 6032 --proxy-user admin --proxy-hostgroup 10 --traffic-host 10.0.0.10 --traffic-port 6033
+sfDBTools migrate cutover --databases app_db --proxy maxscale --proxy-host 10.0.0.10 --proxy-port 8989 --maxscale-source server1 --maxscale-target server2 --traffic-host 10.0.0.10 --traffic-port 4006`,
+	Annotations: map[string]string{
+		"command":  "migrate",
+		"category": "migration",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		run, err := hooks.Before(cmd, "migrate-cutover")
+		if err != nil {
+			return err
+		}
+		return run.After(executeCutover(cmd))
+	},
+}
+
+// executeCutover handles the main cutover execution logic
+func executeCutover(cmd *cobra.Command) error {
+	lg, err := logger.Get()
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	sourceConfig, targetConfig, err := resolveMigrationConfigurations(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve migration configurations: %w", err)
+	}
+
+	databasesFlag := common.GetStringFlagOrEnv(cmd, "databases", "CUTOVER_DATABASES", "")
+	var databases []string
+	for _, name := range strings.Split(databasesFlag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			databases = append(databases, name)
+		}
+	}
+	if len(databases) == 0 {
+		return fmt.Errorf("at least one database must be given with --databases")
+	}
+
+	proxyType := cutover.ProxyType(strings.ToLower(common.GetStringFlagOrEnv(cmd, "proxy", "CUTOVER_PROXY", string(cutover.ProxyNone))))
+	switch proxyType {
+	case cutover.ProxyNone, cutover.ProxyProxySQL, cutover.ProxyMaxScale:
+	default:
+		return fmt.Errorf("unsupported proxy type: %s (use none, proxysql or maxscale)", proxyType)
+	}
+
+	timeoutSec := common.GetIntFlagOrEnv(cmd, "wait-timeout", "CUTOVER_WAIT_TIMEOUT", 300)
+	hostGroup, _ := cmd.Flags().GetInt("proxy-hostgroup")
+	trafficPort, _ := cmd.Flags().GetInt("traffic-port")
+
+	opts := cutover.Options{
+		Source: database.Config{
+			Host: sourceConfig.SourceHost, Port: sourceConfig.SourcePort,
+			User: sourceConfig.SourceUser, Password: sourceConfig.SourcePassword,
+		},
+		Target: database.Config{
+			Host: targetConfig.TargetHost, Port: targetConfig.TargetPort,
+			User: targetConfig.TargetUser, Password: targetConfig.TargetPassword,
+		},
+		Databases: databases,
+		Proxy: cutover.ProxyOptions{
+			Type:         proxyType,
+			Host:         common.GetStringFlagOrEnv(cmd, "proxy-host", "CUTOVER_PROXY_HOST", "127.0.0.1"),
+			Port:         common.GetIntFlagOrEnv(cmd, "proxy-port", "CUTOVER_PROXY_PORT", 0),
+			User:         common.GetStringFlagOrEnv(cmd, "proxy-user", "CUTOVER_PROXY_USER", "admin"),
+			Password:     common.GetStringFlagOrEnv(cmd, "proxy-password", "CUTOVER_PROXY_PASSWORD", ""),
+			HostGroup:    hostGroup,
+			SourceServer: common.GetStringFlagOrEnv(cmd, "maxscale-source", "CUTOVER_MAXSCALE_SOURCE", ""),
+			TargetServer: common.GetStringFlagOrEnv(cmd, "maxscale-target", "CUTOVER_MAXSCALE_TARGET", ""),
+			TrafficHost:  common.GetStringFlagOrEnv(cmd, "traffic-host", "CUTOVER_TRAFFIC_HOST", ""),
+			TrafficPort:  trafficPort,
+		},
+		WaitTimeout:   time.Duration(timeoutSec) * time.Second,
+		SkipReadOnly:  common.GetBoolFlagOrEnv(cmd, "skip-read-only", "CUTOVER_SKIP_READ_ONLY", false),
+		VerifyWrites:  common.GetBoolFlagOrEnv(cmd, "verify-writes", "CUTOVER_VERIFY_WRITES", true),
+		RollbackOnErr: common.GetBoolFlagOrEnv(cmd, "rollback", "CUTOVER_ROLLBACK", true),
+	}
+	if backup_utils.SourceProtectEnabled(cmd) && !opts.SkipReadOnly {
+		return common.WithExitCode(fmt.Errorf("--source-protect refuses to set the source read-only; pass --skip-read-only and block writes another way"), common.ExitUsage)
+	}
+	if opts.Proxy.Port == 0 {
+		opts.Proxy.Port = defaultProxyPort(proxyType)
+	}
+	if opts.VerifyWrites && proxyType != cutover.ProxyNone && (opts.Proxy.TrafficHost == "" || opts.Proxy.TrafficPort == 0) {
+		return common.WithExitCode(fmt.Errorf("--verify-writes through %s needs --traffic-host and --traffic-port, or pass --verify-writes=false", proxyType), common.ExitUsage)
+	}
+
+	plan := terminal.OperationPlan{
+		Operation: "Migration Cutover",
+		Source:    fmt.Sprintf("%s:%d", opts.Source.Host, opts.Source.Port),
+		Target:    fmt.Sprintf("%s:%d", opts.Target.Host, opts.Target.Port),
+		Databases: databases,
+		Actions: []string{
+			"Wait for target to catch up with source",
+			"Switch " + string(proxyType) + " traffic to target",
+		},
+		Destructive: []string{"Redirect application traffic to target"},
+		Backup:      terminal.BackupSafety{Detail: "no rollback on failure"},
+	}
+	if !opts.SkipReadOnly {
+		plan.Actions = append([]string{"Set source read-only"}, plan.Actions...)
+		plan.Destructive = append(plan.Destructive, "Block writes on source")
+	}
+	if opts.VerifyWrites && opts.Proxy.TrafficHost == "" {
+		plan.Actions = append(plan.Actions, "Verify a direct write on target")
+	} else if opts.VerifyWrites {
+		plan.Actions = append(plan.Actions, "Verify writes through "+opts.Proxy.TrafficHost+" reach target")
+	}
+	if opts.RollbackOnErr {
+		plan.Backup = terminal.BackupSafety{Protected: true, Detail: "source and proxy are rolled back on failure"}
+	}
+	if !terminal.ConfirmPlan(plan, "Proceed with cutover?") {
+		lg.Info("Cutover cancelled by user")
+		return fmt.Errorf("cutover cancelled by user")
+	}
+
+	result, err := cutover.Run(cmd.Context(), opts)
+	if result != nil {
+		displayCutoverResult(result)
+	}
+	return err
+}
+
+// defaultProxyPort returns the conventional admin port for the proxy type
+func defaultProxyPort(proxyType cutover.ProxyType) int {
+	switch proxyType {
+	case cutover.ProxyProxySQL:
+		return 6032
+	case cutover.ProxyMaxScale:
+		return 8989
+	}
+	return 0
+}
+
+// displayCutoverResult prints a table with the outcome of each cutover step
+func displayCutoverResult(result *cutover.Result) {
+	headers := []string{"Step", "Status", "Duration", "Message"}
+	rows := make([][]string, 0, len(result.Steps))
+	for _, step := range result.Steps {
+		status := "✅ OK"
+		if !step.Success {
+			status = "❌ FAILED"
+		}
+		rows = append(rows, []string{step.Name, status, common.HumanizeDuration(step.Duration), step.Message})
+	}
+	fmt.Println()
+	terminal.FormatTable(headers, rows)
+
+	if result.Success {
+		warnings.PrintSummary()
+		terminal.PrintSuccess(fmt.Sprintf("Cutover completed in %s", common.HumanizeDuration(result.Duration)))
+	}
+}
+
+func init() {
+	migrate_utils.AddMigrationConnectionFlags(CutoverMigrateCmd)
+	backup_utils.AddSourceProtectFlags(CutoverMigrateCmd)
+
+	CutoverMigrateCmd.Flags().String("databases", "", "comma-separated list of migrated databases to verify")
+	CutoverMigrateCmd.Flags().Int("wait-timeout", 300, "seconds to wait for the target to catch up")
+	CutoverMigrateCmd.Flags().Bool("skip-read-only", false, "do not set the source server read-only")
+	CutoverMigrateCmd.Flags().Bool("verify-writes", true, "verify that new writes land on the target")
+	CutoverMigrateCmd.Flags().Bool("rollback", true, "switch the proxy back and re-enable writes on the source if cutover fails")
+
+	// Proxy options
+	CutoverMigrateCmd.Flags().String("proxy", "none", "proxy in front of the databases (none, proxysql, maxscale)")
+	CutoverMigrateCmd.Flags().String("proxy-host", "", "proxy admin interface host")
+	CutoverMigrateCmd.Flags().Int("proxy-port", 0, "proxy admin interface port (default 6032 for ProxySQL, 8989 for MaxScale)")
+	CutoverMigrateCmd.Flags().String("proxy-user", "", "proxy admin user")
+	CutoverMigrateCmd.Flags().String("proxy-password", "", "proxy admin password")
+	CutoverMigrateCmd.Flags().Int("proxy-hostgroup", 10, "ProxySQL writer hostgroup")
+	CutoverMigrateCmd.Flags().String("maxscale-source", "", "MaxScale server name of the source")
+	CutoverMigrateCmd.Flags().String("maxscale-target", "", "MaxScale server name of the target")
+	CutoverMigrateCmd.Flags().String("traffic-host", "", "application-facing proxy host used to verify writes")
+	CutoverMigrateCmd.Flags().Int("traffic-port", 0, "application-facing proxy port used to verify writes")
+}
@@ -1,16 +1,19 @@
 package migrate_cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
 	"sfDBTools/internal/core/restore/single"
 	restoreUtils "sfDBTools/internal/core/restore/utils"
 	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/database"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/hooks"
 	migrate_utils "sfDBTools/utils/migrate"
 
 	"github.com/spf13/cobra"
@@ -33,29 +36,30 @@ The migration process for each database includes:
 The migration uses existing backup and restore functionality for reliability.`,
 	Example: `sfDBTools migrate selection --source-config ./config/source.cnf.enc --target-config ./config/target.cnf.enc --db_list ./db_list.txt
 sfDBTools migrate selection --source-host localhost --source-user root --target-host remote.server.com --target-user admin
+sfDBTools migrate selection --check-collation  # Abort if target lacks source charsets/collations
 sfDBTools migrate selection  # Fully interactive - will prompt for everything`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get the value of the db_list flag
 		dbListPath, err := cmd.Flags().GetString("db_list")
 		if err != nil {
-			lg, _ := logger.Get()
-			lg.Error("Failed to get db_list flag", logger.Error(err))
-			os.Exit(1)
+			return fmt.Errorf("failed to get db_list flag: %w", err)
 		}
 
+		run, err := hooks.Before(cmd, "migrate-selection")
+		if err != nil {
+			return err
+		}
+		// Errors are returned so pending hooks and notifications finish before exit
 		if dbListPath == "" {
-			if err := executeSelectionMigration(cmd); err != nil {
-				lg, _ := logger.Get()
-				lg.Error("Selection migration failed", logger.Error(err))
-				os.Exit(1)
-			}
-		} else {
-			if err := executeListMigration(cmd); err != nil {
-				lg, _ := logger.Get()
-				lg.Error("List migration failed", logger.Error(err))
-				os.Exit(1)
+			if err := run.After(executeSelectionMigration(cmd)); err != nil {
+				return fmt.Errorf("selection migration failed: %w", err)
 			}
+			return nil
 		}
+		if err := run.After(executeListMigration(cmd)); err != nil {
+			return fmt.Errorf("list migration failed: %w", err)
+		}
+		return nil
 	},
 }
 
@@ -81,7 +85,7 @@ func executeSelectionMigration(cmd *cobra.Command) error {
 		User:     sourceConfig.SourceUser,
 		Password: sourceConfig.SourcePassword,
 	}
-	selectedDatabases, err := info.SelectMultipleDatabasesInteractive(sourceDBConfig)
+	selectedDatabases, err := info.SelectMultipleDatabasesInteractive(cmd.Context(), sourceDBConfig)
 	if err != nil {
 		return fmt.Errorf("failed to select databases: %w", err)
 	}
@@ -94,14 +98,19 @@ func executeSelectionMigration(cmd *cobra.Command) error {
 		logger.Int("count", len(selectedDatabases)),
 		logger.Strings("databases", selectedDatabases))
 
+	// Optional pre-flight gate: collation/charset compatibility between servers
+	if err := runCompatibilityGate(cmd, sourceConfig, targetConfig, selectedDatabases); err != nil {
+		return err
+	}
+
 	// 3. Prompt for confirmation before proceeding with bulk migration
-	if err := migrate_utils.PromptBulkMigrationConfirmation(sourceConfig, targetConfig, selectedDatabases); err != nil {
+	if err := migrate_utils.PromptBulkMigrationConfirmation(cmd.Context(), sourceConfig, targetConfig, selectedDatabases); err != nil {
 		lg.Info("Selection migration cancelled", logger.String("reason", err.Error()))
 		return err
 	}
 
 	// 4. Execute migration for selected databases
-	return executeBulkMigration(sourceConfig, targetConfig, selectedDatabases, lg)
+	return executeBulkMigration(cmd.Context(), sourceConfig, targetConfig, selectedDatabases, lg)
 }
 
 // executeListMigration handles the main list migration execution logic
@@ -137,14 +146,19 @@ func executeListMigration(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to resolve migration configurations: %w", err)
 	}
 
+	// Optional pre-flight gate: collation/charset compatibility between servers
+	if err := runCompatibilityGate(cmd, sourceConfig, targetConfig, selectedDatabases); err != nil {
+		return err
+	}
+
 	// 4. Prompt for confirmation before proceeding with bulk migration
-	if err := migrate_utils.PromptBulkMigrationConfirmation(sourceConfig, targetConfig, selectedDatabases); err != nil {
+	if err := migrate_utils.PromptBulkMigrationConfirmation(cmd.Context(), sourceConfig, targetConfig, selectedDatabases); err != nil {
 		lg.Info("List migration cancelled", logger.String("reason", err.Error()))
 		return err
 	}
 
 	// 5. Execute migration for databases from list
-	return executeBulkMigration(sourceConfig, targetConfig, selectedDatabases, lg)
+	return executeBulkMigration(cmd.Context(), sourceConfig, targetConfig, selectedDatabases, lg)
 }
 
 // resolveMigrationConfigurations resolves source and target configurations without specific database
@@ -161,6 +175,15 @@ func resolveMigrationConfigurations(cmd *cobra.Command) (*migrate_utils.Migratio
 		return nil, nil, fmt.Errorf("failed to resolve target database connection: %w", err)
 	}
 
+	// Guard the source; a migration into the same server would write to it
+	if backup_utils.SourceProtectEnabled(cmd) && strings.EqualFold(sourceHost, targetHost) && sourcePort == targetPort {
+		return nil, nil, common.WithExitCode(fmt.Errorf("--source-protect: target %s:%d is the source server", targetHost, targetPort), common.ExitUsage)
+	}
+	sourceDB := database.Config{Host: sourceHost, Port: sourcePort, User: sourceUser, Password: sourcePassword}
+	if err := backup_utils.ApplySourceProtect(cmd, sourceDB); err != nil {
+		return nil, nil, err
+	}
+
 	// Create source configuration template
 	sourceConfig := &migrate_utils.MigrationConfig{
 		SourceHost:       sourceHost,
@@ -205,8 +228,40 @@ func resolveMigrationConfigurations(cmd *cobra.Command) (*migrate_utils.Migratio
 	return sourceConfig, targetConfig, nil
 }
 
+// runCompatibilityGate runs the collation compatibility check when --check-collation is set
+// and blocks the migration if incompatible objects are found
+func runCompatibilityGate(cmd *cobra.Command, sourceConfig, targetConfig *migrate_utils.MigrationConfig, databases []string) error {
+	if !common.GetBoolFlagOrEnv(cmd, "check-collation", "MIGRATE_CHECK_COLLATION", false) {
+		return nil
+	}
+
+	sourceDBConfig := database.Config{
+		Host:     sourceConfig.SourceHost,
+		Port:     sourceConfig.SourcePort,
+		User:     sourceConfig.SourceUser,
+		Password: sourceConfig.SourcePassword,
+	}
+	targetDBConfig := database.Config{
+		Host:     targetConfig.TargetHost,
+		Port:     targetConfig.TargetPort,
+		User:     targetConfig.TargetUser,
+		Password: targetConfig.TargetPassword,
+	}
+
+	report, err := migrate_utils.CheckCollationCompatibility(sourceDBConfig, targetDBConfig, databases)
+	if err != nil {
+		return fmt.Errorf("collation compatibility check failed: %w", err)
+	}
+	migrate_utils.DisplayCompatibilityReport(report)
+
+	if report.HasBlockingIssues() {
+		return fmt.Errorf("migration blocked: %d collation compatibility issue(s) found", len(report.Issues))
+	}
+	return nil
+}
+
 // executeBulkMigration executes migration for multiple databases
-func executeBulkMigration(sourceConfig, targetConfig *migrate_utils.MigrationConfig, databases []string, lg *logger.Logger) error {
+func executeBulkMigration(ctx context.Context, sourceConfig, targetConfig *migrate_utils.MigrationConfig, databases []string, lg *logger.Logger) error {
 	startTime := time.Now()
 	successCount := 0
 	errorCount := 0
@@ -249,7 +304,7 @@ func executeBulkMigration(sourceConfig, targetConfig *migrate_utils.MigrationCon
 		}
 
 		// Execute migration for this database
-		err := executeSingleDatabaseMigration(migrationConfig, lg)
+		err := executeSingleDatabaseMigration(ctx, migrationConfig, lg)
 		if err != nil {
 			errorCount++
 			errMsg := fmt.Sprintf("Database %s: %v", dbName, err)
@@ -287,7 +342,7 @@ func executeBulkMigration(sourceConfig, targetConfig *migrate_utils.MigrationCon
 }
 
 // executeSingleDatabaseMigration executes migration for a single database
-func executeSingleDatabaseMigration(config *migrate_utils.MigrationConfig, lg *logger.Logger) error {
+func executeSingleDatabaseMigration(ctx context.Context, config *migrate_utils.MigrationConfig, lg *logger.Logger) error {
 	// Display migration information for this database
 	lg.Info("Preparing database migration",
 		logger.String("source_database", config.SourceDBName),
@@ -298,7 +353,7 @@ func executeSingleDatabaseMigration(config *migrate_utils.MigrationConfig, lg *l
 	// Step 1: Backup target database (if exists)
 	if config.BackupTarget {
 		lg.Info("Starting target database backup", logger.String("database", config.TargetDBName))
-		targetBackupFile, err := migrate_utils.BackupDatabaseForMigration(config, false, lg)
+		targetBackupFile, err := migrate_utils.BackupDatabaseForMigration(ctx, config, false, lg)
 
 		if err != nil {
 			lg.Warn("Failed to backup target database (may not exist)",
@@ -313,7 +368,7 @@ func executeSingleDatabaseMigration(config *migrate_utils.MigrationConfig, lg *l
 
 	// Step 2: Backup source database
 	lg.Info("Starting source database backup", logger.String("database", config.SourceDBName))
-	sourceBackupFile, err := migrate_utils.BackupDatabaseForMigration(config, true, lg)
+	sourceBackupFile, err := migrate_utils.BackupDatabaseForMigration(ctx, config, true, lg)
 	if err != nil {
 		return fmt.Errorf("failed to backup source database: %w", err)
 	}
@@ -325,7 +380,7 @@ func executeSingleDatabaseMigration(config *migrate_utils.MigrationConfig, lg *l
 	lg.Info("Starting restore to target database",
 		logger.String("source_file", sourceBackupFile),
 		logger.String("target_database", config.TargetDBName))
-	err = restoreSelectionToTarget(config, sourceBackupFile, lg)
+	err = restoreSelectionToTarget(ctx, config, sourceBackupFile, lg)
 	if err != nil {
 		return fmt.Errorf("failed to restore to target: %w", err)
 	}
@@ -336,7 +391,7 @@ func executeSingleDatabaseMigration(config *migrate_utils.MigrationConfig, lg *l
 }
 
 // restoreToTarget restores the source backup to the target database
-func restoreSelectionToTarget(config *migrate_utils.MigrationConfig, sourceBackupFile string, lg *logger.Logger) error {
+func restoreSelectionToTarget(ctx context.Context, config *migrate_utils.MigrationConfig, sourceBackupFile string, lg *logger.Logger) error {
 	// Create restore options for target database
 	restoreOptions := restoreUtils.RestoreOptions{
 		Host:           config.TargetHost,
@@ -349,7 +404,7 @@ func restoreSelectionToTarget(config *migrate_utils.MigrationConfig, sourceBacku
 	}
 
 	// Perform restore using existing restore functionality
-	err := single.RestoreSingle(restoreOptions)
+	err := single.RestoreSingle(ctx, restoreOptions)
 	if err != nil {
 		return err
 	}
@@ -359,7 +414,9 @@ func restoreSelectionToTarget(config *migrate_utils.MigrationConfig, sourceBacku
 
 func init() {
 	migrate_utils.AddCommonMigrationFlags(SelectionMigrateCmd)
+	backup_utils.AddSourceProtectFlags(SelectionMigrateCmd)
 
 	// Add specific flag for database list
+	SelectionMigrateCmd.Flags().Bool("check-collation", false, "check charset/collation and index length compatibility with the target before migrating")
 	SelectionMigrateCmd.Flags().String("db_list", "", "path to text file containing list of database names (optional, will show selection if not provided)")
 }
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	postgres_cmd "sfDBTools/cmd/postgres_cmd"
+	"sfDBTools/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var PostgresCmd = &cobra.Command{
+	Use:     "postgres",
+	Aliases: []string{"pg"},
+	Short:   "PostgreSQL server management commands",
+	Long:    "Install, configure, back up and restore PostgreSQL servers with the PostgreSQL client tools (psql, pg_dump, pg_restore).",
+	Run: func(cmd *cobra.Command, args []string) {
+		lg, _ := logger.Get()
+		lg.Info("Postgres command executed")
+		cmd.Help()
+	},
+	Annotations: map[string]string{
+		"command":  "postgres",
+		"category": "administration",
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(PostgresCmd)
+	PostgresCmd.AddCommand(postgres_cmd.InstallCmd)
+	PostgresCmd.AddCommand(postgres_cmd.ConfigureCmd)
+	PostgresCmd.AddCommand(postgres_cmd.BackupCmd)
+	PostgresCmd.AddCommand(postgres_cmd.RestoreCmd)
+}
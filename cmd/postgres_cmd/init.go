@@ -0,0 +1,44 @@
+package postgres_cmd
+
+import (
+	"sfDBTools/internal/config/model"
+	"sfDBTools/internal/core/postgres"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+
+	"github.com/spf13/cobra"
+)
+
+// Cfg and Lg are package-level variables that child commands in this
+// package can use. Call Init from the application entrypoint to set them.
+var Cfg *model.Config
+var Lg *logger.Logger
+
+// Init sets the package-level config and logger for the postgres_cmd package.
+func Init(cfg *model.Config, lg *logger.Logger) {
+	Cfg = cfg
+	Lg = lg
+}
+
+// addConnFlags adds the PostgreSQL connection flags shared by the subcommands
+func addConnFlags(cmd *cobra.Command) {
+	cmd.Flags().String("host", "", "PostgreSQL host (env PGHOST, default postgres.host or the local socket)")
+	cmd.Flags().Int("port", 0, "PostgreSQL port (env PGPORT, default postgres.port)")
+	cmd.Flags().String("user", "", "PostgreSQL user (env PGUSER, default postgres.user)")
+	cmd.Flags().String("password", "", "PostgreSQL password (env PGPASSWORD, or ~/.pgpass)")
+}
+
+// resolveConn resolves the connection: flags, then the libpq environment,
+// then the postgres section of config.yaml
+func resolveConn(cmd *cobra.Command) postgres.Conn {
+	var pg model.PostgresConfig
+	if Cfg != nil {
+		pg = Cfg.Postgres
+	}
+	return postgres.Conn{
+		Host:     common.GetStringFlagOrEnv(cmd, "host", "PGHOST", pg.Host),
+		Port:     common.GetIntFlagOrEnv(cmd, "port", "PGPORT", pg.Port),
+		User:     common.GetStringFlagOrEnv(cmd, "user", "PGUSER", pg.User),
+		Password: common.GetStringFlagOrEnv(cmd, "password", "PGPASSWORD", ""),
+	}
+}
@@ -0,0 +1,93 @@
+package postgres_cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"sfDBTools/internal/core/postgres"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// BackupCmd dumps PostgreSQL databases with pg_dump
+var BackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up PostgreSQL databases with pg_dump",
+	Long: `Back up PostgreSQL databases with pg_dump in the custom format, one
+<db>_<timestamp>.dump per database with a .sha256 checksum file next to it.
+
+Without --db every database that accepts connections is backed up. --globals
+also dumps roles and tablespaces with pg_dumpall --globals-only, which pg_dump
+does not include.`,
+	Example: `  sfDBTools postgres backup --globals
+  sfDBTools postgres backup --db sales --db billing --output-dir /backup/pg`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeBackup(cmd)
+	},
+}
+
+func init() {
+	addConnFlags(BackupCmd)
+	BackupCmd.Flags().StringArray("db", nil, "Database to back up (repeatable, default all)")
+	BackupCmd.Flags().String("output-dir", "", "Output directory (env SFDBTOOLS_POSTGRES_BACKUP_DIR, default postgres.backup_dir or <backup base>/postgres)")
+	BackupCmd.Flags().Bool("globals", false, "Also dump roles and tablespaces")
+}
+
+func executeBackup(cmd *cobra.Command) error {
+	defaultDir := Cfg.Postgres.BackupDir
+	if defaultDir == "" {
+		defaultDir = filepath.Join(Cfg.Backup.Storage.BaseDirectory, "postgres")
+	}
+	databases, _ := cmd.Flags().GetStringArray("db")
+	globals, _ := cmd.Flags().GetBool("globals")
+	opts := postgres.BackupOptions{
+		Conn:      resolveConn(cmd),
+		Databases: databases,
+		OutputDir: common.GetStringFlagOrEnv(cmd, "output-dir", "SFDBTOOLS_POSTGRES_BACKUP_DIR", defaultDir),
+		Globals:   globals,
+	}
+
+	terminal.Headers("PostgreSQL Backup")
+	version, err := opts.Conn.ServerVersion()
+	if err != nil {
+		return err
+	}
+	terminal.PrintInfo(fmt.Sprintf("Connected to PostgreSQL %s at %s", version, opts.Conn))
+
+	result, err := postgres.Backup(opts)
+	if result != nil {
+		printBackupResult(result)
+	}
+	if err != nil {
+		return err
+	}
+	terminal.PrintSuccess(fmt.Sprintf("Backup completed: %d file(s) in %s", len(result.Files), opts.OutputDir))
+	return nil
+}
+
+func printBackupResult(result *postgres.BackupResult) {
+	terminal.PrintSubHeader("Backup files")
+	var rows [][]string
+	for _, f := range result.Files {
+		db := f.Database
+		if db == "" {
+			db = "(globals)"
+		}
+		rows = append(rows, []string{db, f.Path, common.FormatSize(f.Size)})
+	}
+	if len(rows) > 0 {
+		terminal.FormatTable([]string{"Database", "File", "Size"}, rows)
+	}
+
+	failed := make([]string, 0, len(result.Failed))
+	for db := range result.Failed {
+		failed = append(failed, db)
+	}
+	sort.Strings(failed)
+	for _, db := range failed {
+		terminal.PrintError(fmt.Sprintf("%s: %v", db, result.Failed[db]))
+	}
+}
@@ -0,0 +1,166 @@
+package postgres_cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sfDBTools/internal/core/postgres"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// ConfigureCmd applies server settings with ALTER SYSTEM
+var ConfigureCmd = &cobra.Command{
+	Use:   "configure",
+	Short: "Apply PostgreSQL server settings",
+	Long: `Apply PostgreSQL server settings with ALTER SYSTEM, so postgresql.conf itself is
+left untouched and the changes live in postgresql.auto.conf.
+
+Only settings that differ from the running server are changed. The server is
+reloaded afterwards, or restarted when a changed setting needs a restart
+(e.g. shared_buffers, listen_addresses).
+
+--auto-tune sizes the memory settings from the host's RAM; explicit --set
+values take precedence.`,
+	Example: `  sfDBTools postgres configure --auto-tune --dry-run
+  sfDBTools postgres configure --listen-addresses '*' --max-connections 300
+  sfDBTools postgres configure --set work_mem=64MB --set log_min_duration_statement=500 --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeConfigure(cmd)
+	},
+}
+
+func init() {
+	addConnFlags(ConfigureCmd)
+	ConfigureCmd.Flags().StringArray("set", nil, "Setting to apply as name=value (repeatable)")
+	ConfigureCmd.Flags().String("listen-addresses", "", "Shorthand for --set listen_addresses=<value>")
+	ConfigureCmd.Flags().Int("max-connections", 0, "Shorthand for --set max_connections=<value>")
+	ConfigureCmd.Flags().Bool("auto-tune", false, "Size memory settings from the host's RAM")
+	ConfigureCmd.Flags().String("service", "", "Service to restart when needed (env SFDBTOOLS_POSTGRES_SERVICE, default postgres.service_name)")
+	ConfigureCmd.Flags().Bool("dry-run", false, "Show the changes without applying them (env SFDBTOOLS_DRY_RUN)")
+}
+
+func executeConfigure(cmd *cobra.Command) error {
+	settings, err := resolveSettings(cmd)
+	if err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	if len(settings) == 0 {
+		return common.WithExitCode(errors.New("nothing to configure: use --set, --listen-addresses, --max-connections or --auto-tune"), common.ExitUsage)
+	}
+	opts := postgres.ConfigureOptions{
+		Conn:        resolveConn(cmd),
+		Settings:    settings,
+		ServiceName: common.GetStringFlagOrEnv(cmd, "service", "SFDBTOOLS_POSTGRES_SERVICE", Cfg.Postgres.ServiceName),
+		DryRun:      common.GetBoolFlagOrEnv(cmd, "dry-run", "SFDBTOOLS_DRY_RUN", false),
+	}
+	skipConfirm := terminal.AssumeYes()
+
+	terminal.Headers("PostgreSQL Configuration")
+	version, err := opts.Conn.ServerVersion()
+	if err != nil {
+		return err
+	}
+	terminal.PrintInfo(fmt.Sprintf("Connected to PostgreSQL %s at %s", version, opts.Conn))
+
+	changes, err := postgres.PlanSettings(opts.Conn, settings)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		terminal.PrintSuccess("All settings already have the requested values")
+		return nil
+	}
+	printChanges(changes)
+
+	if opts.DryRun {
+		terminal.PrintSuccess("Dry run complete: no changes were made")
+		return nil
+	}
+	if !skipConfirm && !terminal.ConfirmPlan(buildConfigurePlan(opts, changes), "Apply these settings?") {
+		return common.WithExitCode(errors.New("configure cancelled by user"), common.ExitCancelled)
+	}
+
+	result, err := postgres.Configure(opts)
+	if err != nil {
+		return err
+	}
+	switch {
+	case result.Restarted:
+		terminal.PrintSuccess(fmt.Sprintf("%d setting(s) applied, service %s restarted", len(result.Changes), opts.ServiceName))
+	case result.RestartPending:
+		terminal.PrintSuccess(fmt.Sprintf("%d setting(s) applied", len(result.Changes)))
+		terminal.PrintWarning("Some settings take effect only after a server restart; use --service or set postgres.service_name to restart automatically")
+	default:
+		terminal.PrintSuccess(fmt.Sprintf("%d setting(s) applied, configuration reloaded", len(result.Changes)))
+	}
+	return nil
+}
+
+// resolveSettings merges --auto-tune, the shorthand flags and --set, in
+// increasing precedence
+func resolveSettings(cmd *cobra.Command) (map[string]string, error) {
+	settings := make(map[string]string)
+	maxConnections, _ := cmd.Flags().GetInt("max-connections")
+
+	if autoTune, _ := cmd.Flags().GetBool("auto-tune"); autoTune {
+		recommended, err := postgres.RecommendSettings(maxConnections)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hardware info: %w", err)
+		}
+		for k, v := range recommended {
+			settings[k] = v
+		}
+	}
+	if listen, _ := cmd.Flags().GetString("listen-addresses"); listen != "" {
+		settings["listen_addresses"] = listen
+	}
+	if maxConnections > 0 {
+		settings["max_connections"] = strconv.Itoa(maxConnections)
+	}
+
+	sets, _ := cmd.Flags().GetStringArray("set")
+	for _, s := range sets {
+		name, value, ok := strings.Cut(s, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --set %q, expected name=value", s)
+		}
+		settings[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	return settings, nil
+}
+
+func printChanges(changes []postgres.SettingChange) {
+	terminal.PrintSubHeader("Setting changes")
+	rows := make([][]string, len(changes))
+	for i, c := range changes {
+		restart := "no"
+		if c.Restart {
+			restart = "yes"
+		}
+		rows[i] = []string{c.Name, c.Current, c.New, restart}
+	}
+	terminal.FormatTable([]string{"Setting", "Current", "New", "Restart"}, rows)
+}
+
+func buildConfigurePlan(opts postgres.ConfigureOptions, changes []postgres.SettingChange) terminal.OperationPlan {
+	plan := terminal.OperationPlan{
+		Operation: "PostgreSQL Configure",
+		Target:    opts.Conn.String(),
+		Backup:    terminal.BackupSafety{Protected: true, Detail: "ALTER SYSTEM RESET <name> reverts a setting"},
+	}
+	restart := false
+	for _, c := range changes {
+		plan.Actions = append(plan.Actions, fmt.Sprintf("%s: %s -> %s", c.Name, c.Current, c.New))
+		restart = restart || c.Restart
+	}
+	if restart && opts.ServiceName != "" {
+		plan.Destructive = append(plan.Destructive, "Restart service "+opts.ServiceName+" (drops all connections)")
+	}
+	return plan
+}
@@ -0,0 +1,40 @@
+package postgres_cmd
+
+import (
+	"sfDBTools/internal/core/postgres"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// InstallCmd installs the PostgreSQL server
+var InstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the PostgreSQL server and start its service",
+	Long: `Install the PostgreSQL server and client packages with the OS package manager,
+initialize the cluster where the packages do not, and enable and start the service.
+
+Without --version the distribution's PostgreSQL is installed. A major version
+(e.g. 16) installs the PGDG packages; their repository must already be configured.`,
+	Example: `  sfDBTools postgres install
+  sfDBTools postgres install --version 16`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version := common.GetStringFlagOrEnv(cmd, "version", "SFDBTOOLS_POSTGRES_VERSION", Cfg.Postgres.Version)
+
+		terminal.Headers("PostgreSQL Installation")
+		layout, err := postgres.Install(postgres.InstallOptions{Version: version})
+		if err != nil {
+			return err
+		}
+		terminal.PrintSuccess("PostgreSQL installed, service " + layout.ServiceName + " is running")
+		if Cfg.Postgres.ServiceName == "" {
+			terminal.PrintInfo("Set postgres.service_name: " + layout.ServiceName + " in config.yaml so configure can restart it")
+		}
+		return nil
+	},
+}
+
+func init() {
+	InstallCmd.Flags().String("version", "", "PostgreSQL major version (env SFDBTOOLS_POSTGRES_VERSION, default postgres.version)")
+}
@@ -0,0 +1,106 @@
+package postgres_cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"sfDBTools/internal/core/postgres"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// RestoreCmd restores a PostgreSQL dump
+var RestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a PostgreSQL dump with pg_restore",
+	Long: `Restore a dump written by 'postgres backup'. The dump is verified against its
+.sha256 file first when one exists.
+
+Custom-format dumps are restored with pg_restore, in parallel with --jobs;
+.sql files (e.g. the globals dump) are replayed with psql. The target database
+defaults to the name in the dump file and is created when missing. --create
+instead lets pg_restore create the database recorded in the dump.`,
+	Example: `  sfDBTools postgres restore --file /backup/pg/sales_20250101_020000.dump
+  sfDBTools postgres restore --file sales_20250101_020000.dump --db sales_copy --jobs 4
+  sfDBTools postgres restore --file sales_20250101_020000.dump --clean --yes
+  sfDBTools postgres restore --file globals_20250101_020000.sql`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeRestore(cmd)
+	},
+}
+
+func init() {
+	addConnFlags(RestoreCmd)
+	RestoreCmd.Flags().String("file", "", "Dump file to restore (required)")
+	RestoreCmd.Flags().String("db", "", "Target database (default: from the dump file name)")
+	RestoreCmd.Flags().Bool("create", false, "Let pg_restore create the database recorded in the dump")
+	RestoreCmd.Flags().Bool("clean", false, "Drop existing objects before restoring them")
+	RestoreCmd.Flags().Int("jobs", 0, "Parallel restore jobs (env SFDBTOOLS_POSTGRES_JOBS, default postgres.jobs)")
+}
+
+func executeRestore(cmd *cobra.Command) error {
+	file, _ := cmd.Flags().GetString("file")
+	if file == "" {
+		return common.WithExitCode(errors.New("--file is required"), common.ExitUsage)
+	}
+	database, _ := cmd.Flags().GetString("db")
+	create, _ := cmd.Flags().GetBool("create")
+	clean, _ := cmd.Flags().GetBool("clean")
+	skipConfirm := terminal.AssumeYes()
+	if create && database != "" {
+		return common.WithExitCode(errors.New("--create and --db cannot be used together"), common.ExitUsage)
+	}
+	opts := postgres.RestoreOptions{
+		Conn:     resolveConn(cmd),
+		File:     file,
+		Database: database,
+		Create:   create,
+		Clean:    clean,
+		Jobs:     common.GetIntFlagOrEnv(cmd, "jobs", "SFDBTOOLS_POSTGRES_JOBS", Cfg.Postgres.Jobs),
+	}
+	opts.Database = opts.TargetDatabase()
+
+	terminal.Headers("PostgreSQL Restore")
+	version, err := opts.Conn.ServerVersion()
+	if err != nil {
+		return err
+	}
+	terminal.PrintInfo(fmt.Sprintf("Connected to PostgreSQL %s at %s", version, opts.Conn))
+
+	if !skipConfirm && !terminal.ConfirmPlan(buildRestorePlan(opts), "Proceed with restore?") {
+		return common.WithExitCode(errors.New("restore cancelled by user"), common.ExitCancelled)
+	}
+	if err := postgres.Restore(opts); err != nil {
+		return err
+	}
+	terminal.PrintSuccess("Restore completed from " + file)
+	return nil
+}
+
+func buildRestorePlan(opts postgres.RestoreOptions) terminal.OperationPlan {
+	target := opts.Database
+	if opts.Create {
+		target = "(database recorded in the dump)"
+	}
+	plan := terminal.OperationPlan{
+		Operation: "PostgreSQL Restore",
+		Source:    opts.File,
+		Target:    opts.Conn.String(),
+		Databases: []string{target},
+		Backup:    terminal.BackupSafety{Detail: "the target database is not backed up first"},
+	}
+	if opts.Jobs > 1 {
+		plan.Actions = append(plan.Actions, fmt.Sprintf("pg_restore with %d parallel jobs", opts.Jobs))
+	}
+	if opts.Create {
+		plan.Actions = append(plan.Actions, "Create the database recorded in the dump")
+	}
+	if opts.Clean {
+		plan.Destructive = append(plan.Destructive, "Drop existing objects in "+target+" before restoring")
+	} else {
+		plan.Destructive = append(plan.Destructive, "Restore into "+target+"; existing objects with the same names make the restore fail")
+	}
+	return plan
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	profile_cmd "sfDBTools/cmd/profile_cmd"
+
+	"github.com/spf13/cobra"
+)
+
+var ProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage encrypted connection profiles",
+	Long: `Create, list, show, edit, delete and rekey named connection profiles.
+
+A profile holds the host, port, user and password of a server (and optionally
+further hosts of the same topology), encrypted with an encryption password as
+<name>.cnf.enc in the database config directory. Backup, restore and the other
+commands read it with --config <file>.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+	Annotations: map[string]string{
+		"command":  "profile",
+		"category": "system",
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ProfileCmd)
+	ProfileCmd.AddCommand(profile_cmd.CreateCmd)
+	ProfileCmd.AddCommand(profile_cmd.ListCmd)
+	ProfileCmd.AddCommand(profile_cmd.ShowCmd)
+	ProfileCmd.AddCommand(profile_cmd.EditCmd)
+	ProfileCmd.AddCommand(profile_cmd.DeleteCmd)
+	ProfileCmd.AddCommand(profile_cmd.RekeyCmd)
+}
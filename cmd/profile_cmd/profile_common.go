@@ -0,0 +1,145 @@
+package profile_cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database/connection"
+	"sfDBTools/utils/dbconfig"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/secrets"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// addConnectionFlags adds the connection settings shared by create and edit;
+// the socket and auth plugin come from the global --socket and --auth-plugin
+func addConnectionFlags(cmd *cobra.Command) {
+	cmd.Flags().String("host", "", "database host (or set SFDB_HOST)")
+	cmd.Flags().Int("port", 0, "database port (or set SFDB_PORT)")
+	cmd.Flags().String("user", "", "database user (or set SFDB_USER)")
+	cmd.Flags().String("hosts", "", "further hosts of the same topology, comma-separated host[:port]")
+	cmd.Flags().String("policy", "", "host policy for multi-host profiles: first-available or prefer-replica-for-backup")
+	cmd.Flags().Bool("test", false, "test the connection first and do not save when no host is reachable")
+}
+
+// applyHostFlags sets --socket, --auth-plugin, --hosts and --policy on
+// dbConfig when given; dbConfig.Port must already be set
+func applyHostFlags(cmd *cobra.Command, dbConfig *config.EncryptedDatabaseConfig) error {
+	if cmd.Flags().Changed("socket") {
+		dbConfig.Socket, _ = cmd.Flags().GetString("socket")
+	}
+	if cmd.Flags().Changed("auth-plugin") {
+		dbConfig.AuthPlugin, _ = cmd.Flags().GetString("auth-plugin")
+	}
+	if err := connection.ValidateAuthPlugin(dbConfig.AuthPlugin); err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	if cmd.Flags().Changed("hosts") {
+		list, _ := cmd.Flags().GetString("hosts")
+		hosts, err := config.ParseDatabaseEndpoints(list, dbConfig.Port)
+		if err != nil {
+			return common.WithExitCode(fmt.Errorf("invalid --hosts: %w", err), common.ExitUsage)
+		}
+		dbConfig.Hosts = hosts
+	}
+	if cmd.Flags().Changed("policy") {
+		dbConfig.Policy, _ = cmd.Flags().GetString("policy")
+	}
+	if err := connection.ValidatePolicy(dbConfig.Policy); err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	if dbConfig.Policy != "" && len(dbConfig.Hosts) == 0 {
+		return common.WithExitCode(fmt.Errorf("--policy needs further hosts given with --hosts"), common.ExitUsage)
+	}
+	return nil
+}
+
+// validPort rejects ports outside 1-65535
+func validPort(port int) error {
+	if port < 1 || port > 65535 {
+		return common.WithExitCode(fmt.Errorf("invalid port %d: must be between 1 and 65535", port), common.ExitUsage)
+	}
+	return nil
+}
+
+// profileFile resolves a profile name or path and checks that the file exists
+func profileFile(ref string) (string, error) {
+	path, err := dbconfig.ProfilePath(ref)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", common.WithExitCode(fmt.Errorf("profile %s not found (%s)", ref, path), common.ExitUsage)
+	}
+	return path, nil
+}
+
+// loadProfile decrypts the profile at path, explaining a wrong password
+func loadProfile(path, encryptionPassword string) (*config.EncryptedDatabaseConfig, error) {
+	spinner := terminal.NewProgressSpinner("Decrypting profile...")
+	spinner.Start()
+	dbConfig, err := dbconfig.LoadProfile(path, encryptionPassword)
+	spinner.Stop()
+	if err != nil {
+		return nil, common.HandleDecryptionError(err, path)
+	}
+	return dbConfig, nil
+}
+
+// hostState is the outcome of testing one host of a profile
+type hostState struct {
+	Host      string `json:"host"`
+	Reachable bool   `json:"reachable"`
+	State     string `json:"state"`
+}
+
+// testConnection probes every host of dbConfig and prints their state. It
+// fails when no host is reachable.
+func testConnection(dbConfig *config.EncryptedDatabaseConfig) ([]hostState, error) {
+	spinner := terminal.NewProgressSpinner("Testing connection...")
+	spinner.Start()
+	health, err := dbconfig.TestProfile(dbConfig)
+	spinner.Stop()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]hostState, len(health))
+	rows := make([][]string, len(health))
+	var firstErr error
+	reachable := 0
+	for i, h := range health {
+		states[i] = hostState{Host: h.Endpoint.String(), Reachable: h.Reachable, State: h.State()}
+		rows[i] = []string{states[i].Host, states[i].State}
+		if h.Reachable {
+			reachable++
+		} else if firstErr == nil {
+			firstErr = h.Err
+		}
+	}
+	if !output.JSON() {
+		terminal.FormatTable([]string{"Host", "State"}, rows)
+	}
+	if reachable == 0 {
+		return states, fmt.Errorf("connection test failed: %w", firstErr)
+	}
+	terminal.PrintSuccess(fmt.Sprintf("Connection test passed (%d of %d hosts reachable)", reachable, len(health)))
+	return states, nil
+}
+
+// maskPassword hides a password for display
+func maskPassword(password string) string {
+	if password == "" {
+		return "[empty]"
+	}
+	if secrets.IsReference(password) {
+		// A reference such as vault://... is no secret itself
+		return password
+	}
+	return strings.Repeat("*", 8)
+}
@@ -0,0 +1,119 @@
+package profile_cmd
+
+import (
+	"fmt"
+	"os"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/dbconfig"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// CreateCmd creates a new encrypted connection profile
+var CreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create an encrypted connection profile",
+	Long: `Create a named connection profile, stored encrypted as <name>.cnf.enc in the
+database config directory (config_dir.database_config). Backup, restore and
+the other commands read it with --config <file>.
+
+Host, port and user come from the flags, SFDB_HOST, SFDB_PORT and SFDB_USER,
+or a prompt. The database password comes from SFDB_PASSWORD or a masked
+prompt; it may also be a secret reference such as vault://... which is
+resolved whenever the profile is used.
+
+The profile is encrypted with the encryption (master) password from
+SFDB_ENCRYPTION_PASSWORD, or one prompted twice. With --test every host is
+connected to first and nothing is saved when none is reachable.`,
+	Example: `sfDBTools profile create prod --host db1.example.com --user backup --test
+sfDBTools profile create prod --host db1 --hosts db2,db3:3307 --policy prefer-replica-for-backup
+SFDB_PASSWORD=vault://database/creds/backup sfDBTools profile create vault-prod --host db1`,
+	Args: cobra.ExactArgs(1),
+	Annotations: map[string]string{
+		"command":  "profile create",
+		"category": "system",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeCreate(cmd, args[0])
+	},
+}
+
+func init() {
+	addConnectionFlags(CreateCmd)
+	CreateCmd.Flags().Bool("force", false, "overwrite an existing profile of the same name")
+}
+
+func executeCreate(cmd *cobra.Command, name string) error {
+	lg, _ := logger.Get()
+	if err := dbconfig.ValidateProfileName(name); err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	path, err := dbconfig.ProfilePath(name)
+	if err != nil {
+		return err
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	if _, err := os.Stat(path); err == nil && !force {
+		return common.WithExitCode(fmt.Errorf("profile %s already exists (%s); use --force to overwrite it or 'profile edit' to change it", name, path), common.ExitUsage)
+	}
+
+	terminal.Headers("Create Connection Profile")
+
+	dbConfig := &config.EncryptedDatabaseConfig{
+		Host: common.GetStringFlagOrEnv(cmd, "host", crypto.ENV_DB_HOST, ""),
+		Port: common.GetIntFlagOrEnv(cmd, "port", crypto.ENV_DB_PORT, 0),
+		User: common.GetStringFlagOrEnv(cmd, "user", crypto.ENV_DB_USER, ""),
+		// --socket and --auth-plugin are global flags, also read from the environment
+		Socket:     common.GetStringFlagOrEnv(cmd, "socket", "SFDB_DB_SOCKET", ""),
+		AuthPlugin: common.GetStringFlagOrEnv(cmd, "auth-plugin", "SFDB_AUTH_PLUGIN", ""),
+	}
+	if dbConfig.Host == "" {
+		dbConfig.Host = terminal.AskString("Host", "localhost")
+	}
+	if dbConfig.Port == 0 {
+		dbConfig.Port = terminal.AskInt("Port", 3306)
+	}
+	if err := validPort(dbConfig.Port); err != nil {
+		return err
+	}
+	if dbConfig.User == "" {
+		dbConfig.User = terminal.AskString("User", "root")
+	}
+	if err := applyHostFlags(cmd, dbConfig); err != nil {
+		return err
+	}
+
+	dbConfig.Password = os.Getenv(crypto.ENV_DB_PASSWORD)
+	if dbConfig.Password != "" {
+		terminal.PrintInfo(fmt.Sprintf("Using database password from %s (hidden)", crypto.ENV_DB_PASSWORD))
+	} else {
+		dbConfig.Password = terminal.AskPassword(fmt.Sprintf("Database password for %s@%s", dbConfig.User, dbConfig.Host), "")
+		if dbConfig.Password == "" && dbConfig.AuthPlugin == "" {
+			terminal.PrintWarning("The profile has no database password")
+		}
+	}
+
+	if test, _ := cmd.Flags().GetBool("test"); test {
+		if _, err := testConnection(dbConfig); err != nil {
+			return fmt.Errorf("profile %s not saved: %w", name, err)
+		}
+	}
+
+	encryptionPassword, err := crypto.ConfirmEncryptionPassword("🔑 Encryption password: ")
+	if err != nil {
+		return fmt.Errorf("failed to get encryption password: %w", err)
+	}
+	if err := dbconfig.SaveProfile(path, dbConfig, encryptionPassword); err != nil {
+		return err
+	}
+
+	lg.Info("Connection profile created", logger.String("profile", name), logger.String("file", path))
+	terminal.PrintSuccess(fmt.Sprintf("Profile %s saved to %s", name, path))
+	terminal.PrintInfo(fmt.Sprintf("Use it with --config %s", path))
+	return nil
+}
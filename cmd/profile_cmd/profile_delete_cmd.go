@@ -0,0 +1,66 @@
+package profile_cmd
+
+import (
+	"fmt"
+	"os"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/dbconfig"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// DeleteCmd removes connection profiles
+var DeleteCmd = &cobra.Command{
+	Use:   "delete <name|file>...",
+	Short: "Delete encrypted connection profiles",
+	Long: `Delete connection profiles. The files are removed after confirmation (or
+with --yes) and cannot be recovered; commands and schedules that pass them
+with --config fail afterwards.`,
+	Example: `sfDBTools profile delete staging
+sfDBTools profile delete old-prod old-staging --yes`,
+	Args: cobra.MinimumNArgs(1),
+	Annotations: map[string]string{
+		"command":  "profile delete",
+		"category": "system",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeDelete(args)
+	},
+}
+
+func executeDelete(refs []string) error {
+	lg, _ := logger.Get()
+	paths := make([]string, 0, len(refs))
+	rows := make([][]string, 0, len(refs))
+	for _, ref := range refs {
+		path, err := profileFile(ref)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+		rows = append(rows, []string{dbconfig.ProfileNameFromPath(path), path})
+	}
+
+	terminal.FormatTable([]string{"Profile", "File"}, rows)
+	if !terminal.Confirm(fmt.Sprintf("Delete %d profiles? This cannot be undone", len(paths)), false) {
+		terminal.PrintWarning("Nothing deleted.")
+		return nil
+	}
+
+	var failed int
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			failed++
+			terminal.PrintError(fmt.Sprintf("Failed to delete %s: %v", path, err))
+			continue
+		}
+		lg.Info("Connection profile deleted", logger.String("file", path))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d profiles could not be deleted", failed, len(paths))
+	}
+	terminal.PrintSuccess(fmt.Sprintf("%d profiles deleted", len(paths)))
+	return nil
+}
@@ -0,0 +1,187 @@
+package profile_cmd
+
+import (
+	"fmt"
+	"os"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/dbconfig"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// EditCmd changes the settings of a connection profile
+var EditCmd = &cobra.Command{
+	Use:   "edit <name|file>",
+	Short: "Change the settings of an encrypted connection profile",
+	Long: `Decrypt a connection profile, change its settings and encrypt it again with
+the same encryption password.
+
+Settings given as flags are changed and everything else is kept; without
+flags every setting is prompted for with its current value as default.
+--password asks for a new database password (or takes SFDB_PASSWORD),
+--rename moves the profile to a new name. With --test the changed profile is
+connected to first and not saved when no host is reachable.
+
+Use 'profile rekey' to change the encryption password itself.`,
+	Example: `sfDBTools profile edit prod
+sfDBTools profile edit prod --host db2.example.com --test
+sfDBTools profile edit prod --password
+sfDBTools profile edit prod --rename prod-eu`,
+	Args: cobra.ExactArgs(1),
+	Annotations: map[string]string{
+		"command":  "profile edit",
+		"category": "system",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeEdit(cmd, args[0])
+	},
+}
+
+func init() {
+	addConnectionFlags(EditCmd)
+	EditCmd.Flags().Bool("password", false, "set a new database password (from SFDB_PASSWORD or a prompt)")
+	EditCmd.Flags().String("rename", "", "new name of the profile")
+}
+
+// editFlags are the flags that change a profile without prompting
+var editFlags = []string{"host", "port", "user", "socket", "auth-plugin", "hosts", "policy", "password", "rename"}
+
+func executeEdit(cmd *cobra.Command, ref string) error {
+	lg, _ := logger.Get()
+	path, err := profileFile(ref)
+	if err != nil {
+		return err
+	}
+	name := dbconfig.ProfileNameFromPath(path)
+	newName, _ := cmd.Flags().GetString("rename")
+	newPath := path
+	if newName != "" && newName != name {
+		if err := dbconfig.ValidateProfileName(newName); err != nil {
+			return common.WithExitCode(err, common.ExitUsage)
+		}
+		if newPath, err = dbconfig.ProfilePath(newName); err != nil {
+			return err
+		}
+		if _, err := os.Stat(newPath); err == nil {
+			return common.WithExitCode(fmt.Errorf("profile %s already exists (%s)", newName, newPath), common.ExitUsage)
+		}
+	} else {
+		newName = name
+	}
+
+	terminal.Headers("Edit Connection Profile")
+
+	encryptionPassword, err := crypto.GetEncryptionPassword("🔑 Encryption password: ")
+	if err != nil {
+		return fmt.Errorf("failed to get encryption password: %w", err)
+	}
+	current, err := loadProfile(path, encryptionPassword)
+	if err != nil {
+		return err
+	}
+	updated := *current
+
+	interactive := true
+	for _, flag := range editFlags {
+		if cmd.Flags().Changed(flag) {
+			interactive = false
+		}
+	}
+	if interactive {
+		promptSettings(&updated)
+	} else {
+		if cmd.Flags().Changed("host") {
+			updated.Host, _ = cmd.Flags().GetString("host")
+		}
+		if cmd.Flags().Changed("port") {
+			updated.Port, _ = cmd.Flags().GetInt("port")
+		}
+		if cmd.Flags().Changed("user") {
+			updated.User, _ = cmd.Flags().GetString("user")
+		}
+		if changePassword, _ := cmd.Flags().GetBool("password"); changePassword {
+			if updated.Password = os.Getenv(crypto.ENV_DB_PASSWORD); updated.Password != "" {
+				terminal.PrintInfo(fmt.Sprintf("Using database password from %s (hidden)", crypto.ENV_DB_PASSWORD))
+			} else {
+				updated.Password = terminal.AskPassword(fmt.Sprintf("New database password for %s@%s", updated.User, updated.Host), "")
+			}
+		}
+	}
+	if err := validPort(updated.Port); err != nil {
+		return err
+	}
+	if err := applyHostFlags(cmd, &updated); err != nil {
+		return err
+	}
+
+	if !printChanges(current, &updated, name, newName) {
+		terminal.PrintInfo("No changes made.")
+		return nil
+	}
+	if test, _ := cmd.Flags().GetBool("test"); test {
+		if _, err := testConnection(&updated); err != nil {
+			return fmt.Errorf("profile %s not changed: %w", name, err)
+		}
+	}
+	if interactive && !terminal.Confirm("Save these changes?", true) {
+		terminal.PrintWarning("Changes cancelled.")
+		return nil
+	}
+
+	if err := dbconfig.SaveProfile(newPath, &updated, encryptionPassword); err != nil {
+		return err
+	}
+	if newPath != path {
+		if err := os.Remove(path); err != nil {
+			terminal.PrintWarning(fmt.Sprintf("Could not remove the old profile %s: %v", path, err))
+		}
+	}
+
+	lg.Info("Connection profile changed", logger.String("profile", newName), logger.String("file", newPath))
+	terminal.PrintSuccess(fmt.Sprintf("Profile %s saved to %s", newName, newPath))
+	return nil
+}
+
+// promptSettings asks for host, port, user and password with the current
+// values as defaults
+func promptSettings(dbConfig *config.EncryptedDatabaseConfig) {
+	terminal.PrintInfo("Press Enter to keep the current value.")
+	dbConfig.Host = terminal.AskString("Host", dbConfig.Host)
+	dbConfig.Port = terminal.AskInt("Port", dbConfig.Port)
+	dbConfig.User = terminal.AskString("User", dbConfig.User)
+	dbConfig.Password = terminal.AskPassword("Database password", dbConfig.Password)
+}
+
+// printChanges lists what differs between the old and the new profile and
+// reports whether anything does
+func printChanges(old, new *config.EncryptedDatabaseConfig, oldName, newName string) bool {
+	var rows [][]string
+	change := func(setting, from, to string) {
+		if from != to {
+			rows = append(rows, []string{setting, from, to})
+		}
+	}
+	change("Name", oldName, newName)
+	change("Host", old.Host, new.Host)
+	change("Port", fmt.Sprintf("%d", old.Port), fmt.Sprintf("%d", new.Port))
+	change("User", old.User, new.User)
+	if old.Password != new.Password {
+		rows = append(rows, []string{"Password", maskPassword(old.Password), maskPassword(new.Password) + " (changed)"})
+	}
+	change("Socket", old.Socket, new.Socket)
+	change("Auth plugin", old.AuthPlugin, new.AuthPlugin)
+	change("Other hosts", old.ExtraHosts(), new.ExtraHosts())
+	change("Policy", old.Policy, new.Policy)
+
+	if len(rows) == 0 {
+		return false
+	}
+	terminal.PrintSubHeader("Changes")
+	terminal.FormatTable([]string{"Setting", "Current", "New"}, rows)
+	return true
+}
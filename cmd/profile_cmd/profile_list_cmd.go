@@ -0,0 +1,62 @@
+package profile_cmd
+
+import (
+	"time"
+
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/dbconfig"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// ListCmd lists the encrypted connection profiles
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List encrypted connection profiles",
+	Long: `List the connection profiles in the database config directory. Profiles are
+not decrypted, so no password is needed; use 'profile show' for the contents.`,
+	Annotations: map[string]string{
+		"command":  "profile list",
+		"category": "system",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeList()
+	},
+}
+
+// profileEntry is one profile in the output of list
+type profileEntry struct {
+	Name     string    `json:"name"`
+	File     string    `json:"file"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+func executeList() error {
+	fm := dbconfig.NewFileManager()
+	files, err := fm.ListConfigFiles()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]profileEntry, len(files))
+	rows := make([][]string, len(files))
+	for i, f := range files {
+		entries[i] = profileEntry{Name: f.Name, File: f.Path, Size: f.Size, Modified: f.ModTime}
+		rows[i] = []string{f.Name, common.HumanizeSize(f.Size), f.ModTime.Format("2006-01-02 15:04"), f.Path}
+	}
+	output.SetResult(entries)
+	if output.JSON() {
+		return nil
+	}
+
+	if len(files) == 0 {
+		terminal.PrintWarning("No connection profiles in " + fm.GetConfigDir())
+		terminal.PrintInfo("Create one with: sfDBTools profile create <name>")
+		return nil
+	}
+	terminal.FormatTable([]string{"Name", "Size", "Modified", "File"}, rows)
+	return nil
+}
@@ -0,0 +1,114 @@
+package profile_cmd
+
+import (
+	"fmt"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/dbconfig"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// RekeyCmd encrypts connection profiles again with a new encryption password
+var RekeyCmd = &cobra.Command{
+	Use:   "rekey [name|file]...",
+	Short: "Encrypt connection profiles with a new encryption password",
+	Long: `Make connection profiles readable with a new encryption (master) password.
+
+The current password comes from SFDB_ENCRYPTION_PASSWORD or a prompt, the new
+one from SFDB_NEW_ENCRYPTION_PASSWORD or a prompt asked twice. Every profile
+is decrypted before the first one is written, so a wrong current password
+changes nothing. Each file is replaced atomically.
+
+Remember to update SFDB_ENCRYPTION_PASSWORD wherever backups and restores run
+unattended with these profiles.`,
+	Example: `sfDBTools profile rekey prod staging
+sfDBTools profile rekey --all`,
+	Annotations: map[string]string{
+		"command":  "profile rekey",
+		"category": "system",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeRekey(cmd, args)
+	},
+}
+
+func init() {
+	RekeyCmd.Flags().Bool("all", false, "rekey every profile in the database config directory")
+}
+
+func executeRekey(cmd *cobra.Command, refs []string) error {
+	lg, _ := logger.Get()
+	all, _ := cmd.Flags().GetBool("all")
+	if all == (len(refs) > 0) {
+		return common.WithExitCode(fmt.Errorf("name the profiles to rekey or use --all"), common.ExitUsage)
+	}
+
+	var paths []string
+	if all {
+		files, err := dbconfig.NewFileManager().ListConfigFiles()
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			paths = append(paths, f.Path)
+		}
+		if len(paths) == 0 {
+			terminal.PrintWarning("No connection profiles to rekey")
+			return nil
+		}
+	}
+	for _, ref := range refs {
+		path, err := profileFile(ref)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+	}
+
+	terminal.Headers("Rekey Connection Profiles")
+
+	oldPassword, err := crypto.GetEncryptionPassword("Enter current encryption password: ")
+	if err != nil {
+		return fmt.Errorf("failed to get encryption password: %w", err)
+	}
+	profiles := make([]*config.EncryptedDatabaseConfig, len(paths))
+	for i, path := range paths {
+		if profiles[i], err = dbconfig.LoadProfile(path, oldPassword); err != nil {
+			return fmt.Errorf("failed to decrypt %s, nothing was changed: %w", path, common.HandleDecryptionError(err, path))
+		}
+	}
+
+	newPassword, err := crypto.GetNewEncryptionPassword("Enter new encryption password: ")
+	if err != nil {
+		return fmt.Errorf("failed to get new encryption password: %w", err)
+	}
+	if newPassword == oldPassword {
+		return common.WithExitCode(fmt.Errorf("the new encryption password must differ from the current one"), common.ExitUsage)
+	}
+
+	rows := make([][]string, 0, len(paths))
+	var failed int
+	for i, path := range paths {
+		status := "rekeyed"
+		if err := dbconfig.SaveProfile(path, profiles[i], newPassword); err != nil {
+			failed++
+			status = "failed: " + err.Error()
+			lg.Error("Profile rekey failed", logger.String("file", path), logger.Error(err))
+		} else {
+			lg.Info("Connection profile rekeyed", logger.String("file", path))
+		}
+		rows = append(rows, []string{dbconfig.ProfileNameFromPath(path), path, status})
+	}
+
+	terminal.FormatTable([]string{"Profile", "File", "Status"}, rows)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d profiles could not be rekeyed; they still use the current password", failed, len(paths))
+	}
+	terminal.PrintSuccess(fmt.Sprintf("%d profiles now use the new encryption password", len(paths)))
+	return nil
+}
@@ -0,0 +1,115 @@
+package profile_cmd
+
+import (
+	"fmt"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/dbconfig"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+// ShowCmd decrypts and prints a connection profile
+var ShowCmd = &cobra.Command{
+	Use:   "show <name|file>",
+	Short: "Show the contents of an encrypted connection profile",
+	Long: `Decrypt a connection profile and print its settings. The database password
+is masked unless --reveal is given; secret references are always shown since
+they hold no secret themselves. With --test every host of the profile is
+connected to, the same way backup and restore would.`,
+	Example: `sfDBTools profile show prod
+sfDBTools profile show prod --test
+sfDBTools profile show /etc/sfDBTools/config/db/prod.cnf.enc --reveal`,
+	Args: cobra.ExactArgs(1),
+	Annotations: map[string]string{
+		"command":  "profile show",
+		"category": "system",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeShow(cmd, args[0])
+	},
+}
+
+func init() {
+	ShowCmd.Flags().Bool("reveal", false, "print the database password in plain text")
+	ShowCmd.Flags().Bool("test", false, "test the connection to every host of the profile")
+}
+
+// profileDetails is the output of show
+type profileDetails struct {
+	Name       string                    `json:"name"`
+	File       string                    `json:"file"`
+	Host       string                    `json:"host"`
+	Port       int                       `json:"port"`
+	User       string                    `json:"user"`
+	Password   string                    `json:"password,omitempty"`
+	Socket     string                    `json:"socket,omitempty"`
+	AuthPlugin string                    `json:"auth_plugin,omitempty"`
+	Hosts      []config.DatabaseEndpoint `json:"hosts,omitempty"`
+	Policy     string                    `json:"policy,omitempty"`
+	Connection []hostState               `json:"connection,omitempty"`
+}
+
+func executeShow(cmd *cobra.Command, ref string) error {
+	path, err := profileFile(ref)
+	if err != nil {
+		return err
+	}
+	encryptionPassword, err := crypto.GetEncryptionPassword("🔑 Encryption password: ")
+	if err != nil {
+		return fmt.Errorf("failed to get encryption password: %w", err)
+	}
+	dbConfig, err := loadProfile(path, encryptionPassword)
+	if err != nil {
+		return err
+	}
+
+	reveal, _ := cmd.Flags().GetBool("reveal")
+	password := maskPassword(dbConfig.Password)
+	if reveal {
+		password = dbConfig.Password
+	}
+	details := profileDetails{
+		Name:       dbconfig.ProfileNameFromPath(path),
+		File:       path,
+		Host:       dbConfig.Host,
+		Port:       dbConfig.Port,
+		User:       dbConfig.User,
+		Password:   password,
+		Socket:     dbConfig.Socket,
+		AuthPlugin: dbConfig.AuthPlugin,
+		Hosts:      dbConfig.Hosts,
+		Policy:     dbConfig.Policy,
+	}
+	output.SetResult(&details)
+
+	if !output.JSON() {
+		rows := [][]string{
+			{"Name", details.Name},
+			{"File", details.File},
+			{"Host", details.Host},
+			{"Port", fmt.Sprintf("%d", details.Port)},
+			{"User", details.User},
+			{"Password", details.Password},
+		}
+		if dbConfig.Socket != "" {
+			rows = append(rows, []string{"Socket", dbConfig.Socket})
+		}
+		if dbConfig.AuthPlugin != "" {
+			rows = append(rows, []string{"Auth plugin", dbConfig.AuthPlugin})
+		}
+		if len(dbConfig.Hosts) > 0 {
+			rows = append(rows, []string{"Other hosts", dbConfig.ExtraHosts()}, []string{"Policy", dbConfig.Policy})
+		}
+		terminal.FormatTable([]string{"Setting", "Value"}, rows)
+	}
+
+	if test, _ := cmd.Flags().GetBool("test"); test {
+		details.Connection, err = testConnection(dbConfig)
+		return err
+	}
+	return nil
+}
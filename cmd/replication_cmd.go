@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	replication_cmd "sfDBTools/cmd/replication_cmd"
+	"sfDBTools/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var ReplicationCmd = &cobra.Command{
+	Use:   "replication",
+	Short: "Replication setup",
+	Long:  "Set up replicas from the backups in the catalog.",
+	Run: func(cmd *cobra.Command, args []string) {
+		lg, _ := logger.Get()
+		lg.Info("Replication command executed")
+		cmd.Help()
+	},
+	Example: `sfDBTools replication seed --from-backup all_databases_20250101_020000 --replica-config ./config/replica.cnf.enc`,
+	Annotations: map[string]string{
+		"command":  "replication",
+		"category": "restore",
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ReplicationCmd)
+	ReplicationCmd.AddCommand(replication_cmd.SeedCmd)
+}
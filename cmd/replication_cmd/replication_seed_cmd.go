@@ -0,0 +1,166 @@
+package replication_cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/replication"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	restore_utils "sfDBTools/utils/restore"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+var SeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Restore a backup on a replica and start replication from the backup's position",
+	Long: `Seed a replica from a backup in the catalog: restore the backup on the replica,
+then point it at the source with CHANGE MASTER TO using the binlog position the
+backup is consistent with.
+
+All-databases backups taken while binary logging is enabled on the source record
+that position (and its GTID) in their manifest. --from-backup takes the manifest
+name without .meta.json, as listed by 'catalog', or a path to the manifest.
+
+The source defaults to the host and port the backup was taken from. Replication
+starts from the binlog file and position unless --use-gtid is given, which sets
+gtid_slave_pos and uses MASTER_USE_GTID = slave_pos. A replica that already has
+replication configured is refused unless --force, which resets it first.`,
+	Example: `sfDBTools replication seed --from-backup all_databases_20250101_020000 --replica-config ./config/replica.cnf.enc --replication-user repl
+SFDB_REPLICATION_PASSWORD=secret sfDBTools replication seed --from-backup all_databases_20250101_020000 --target_host db2 --target_user root --use-gtid --start-replica
+sfDBTools replication seed --from-backup /var/backup/db1/all_databases_20250101_020000.meta.json --replica-config ./config/replica.cnf.enc --skip-restore --dry-run`,
+	Annotations: map[string]string{
+		"command":  "replication",
+		"category": "restore",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeSeed(cmd)
+	},
+}
+
+func executeSeed(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	id := common.GetStringFlagOrEnv(cmd, "from-backup", "SEED_FROM_BACKUP", "")
+	if id == "" {
+		return common.WithExitCode(fmt.Errorf("--from-backup is required"), common.ExitUsage)
+	}
+	baseDir := common.GetStringFlagOrEnv(cmd, "backup-dir", "BACKUP_DIR", cfg.Backup.Storage.BaseDirectory)
+	manifest, meta, err := replication.FindBackup(baseDir, id)
+	if err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	bookmark, err := replication.Bookmark(meta)
+	if err != nil {
+		return common.WithExitCode(fmt.Errorf("%s: %w", filepath.Base(manifest), err), common.ExitUsage)
+	}
+
+	opts := replication.SeedOptions{
+		Manifest:            manifest,
+		Backup:              meta,
+		SourceHost:          common.GetStringFlagOrEnv(cmd, "source-host", "SEED_SOURCE_HOST", meta.Host),
+		SourcePort:          common.GetIntFlagOrEnv(cmd, "source-port", "SEED_SOURCE_PORT", meta.Port),
+		ReplicationUser:     common.GetStringFlagOrEnv(cmd, "replication-user", "SFDB_REPLICATION_USER", "repl"),
+		ReplicationPassword: common.GetStringFlagOrEnv(cmd, "replication-password", "SFDB_REPLICATION_PASSWORD", ""),
+		UseGTID:             common.GetBoolFlagOrEnv(cmd, "use-gtid", "SEED_USE_GTID", false),
+		SkipRestore:         common.GetBoolFlagOrEnv(cmd, "skip-restore", "SEED_SKIP_RESTORE", false),
+		StartReplica:        common.GetBoolFlagOrEnv(cmd, "start-replica", "SEED_START_REPLICA", false),
+		Force:               common.GetBoolFlagOrEnv(cmd, "force", "SEED_FORCE", false),
+	}
+	if opts.UseGTID && bookmark.GTIDPosition == "" {
+		return common.WithExitCode(fmt.Errorf("%s has no GTID position recorded; seed by binlog position instead", filepath.Base(manifest)), common.ExitUsage)
+	}
+
+	if replicaConfig := common.GetStringFlagOrEnv(cmd, "replica-config", "SEED_REPLICA_CONFIG", ""); replicaConfig != "" {
+		if err := common.ValidateConfigFile(replicaConfig); err != nil {
+			return fmt.Errorf("invalid replica config file: %w", err)
+		}
+		host, port, user, password, err := common.GetDatabaseConfigFromEncrypted(replicaConfig)
+		if err != nil {
+			return fmt.Errorf("failed to load replica config: %w", err)
+		}
+		opts.Replica = database.Config{Host: host, Port: port, User: user, Password: password}
+	} else {
+		host, port, user, password, _, err := restore_utils.ResolveDatabaseConnection(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to resolve replica connection: %w", err)
+		}
+		opts.Replica = database.Config{Host: host, Port: port, User: user, Password: password}
+	}
+	if opts.Replica.Host == opts.SourceHost && opts.Replica.Port == opts.SourcePort {
+		return common.WithExitCode(fmt.Errorf("the replica %s:%d is the source of the backup", opts.Replica.Host, opts.Replica.Port), common.ExitUsage)
+	}
+
+	terminal.Headers("Replication - Seed Replica")
+	plan := buildSeedPlan(opts)
+	if common.GetBoolFlagOrEnv(cmd, "dry-run", "SEED_DRY_RUN", false) {
+		terminal.RenderPlanSummary(plan)
+		terminal.PrintInfo("Dry run: nothing was changed")
+		return nil
+	}
+	skipConfirm := terminal.AssumeYes()
+	if !skipConfirm && !terminal.ConfirmPlan(plan, "Seed the replica?") {
+		return common.WithExitCode(fmt.Errorf("seeding cancelled by user"), common.ExitUsage)
+	}
+
+	if err := replication.Seed(cmd.Context(), opts); err != nil {
+		return err
+	}
+	warnings.PrintSummary()
+	position := fmt.Sprintf("%s:%d", bookmark.LogFile, bookmark.LogPosition)
+	if opts.UseGTID {
+		position = "GTID " + bookmark.GTIDPosition
+	}
+	terminal.PrintSuccess(fmt.Sprintf("%s:%d replicates from %s:%d starting at %s", opts.Replica.Host, opts.Replica.Port, opts.SourceHost, opts.SourcePort, position))
+	if !opts.StartReplica {
+		terminal.PrintInfo("Replication is configured but not started; run START SLAVE on the replica")
+	}
+	return nil
+}
+
+func buildSeedPlan(opts replication.SeedOptions) terminal.OperationPlan {
+	plan := terminal.OperationPlan{
+		Operation: "Seed Replica",
+		Source:    fmt.Sprintf("%s (%s:%d, %s)", filepath.Base(opts.Manifest), opts.Backup.Host, opts.Backup.Port, opts.Backup.BackupDate.Local().Format("2006-01-02 15:04")),
+		Target:    fmt.Sprintf("%s:%d", opts.Replica.Host, opts.Replica.Port),
+		Backup: terminal.BackupSafety{
+			Protected: true,
+			Detail:    "the replica is expected to be empty; databases in the backup are overwritten",
+		},
+	}
+	if !opts.SkipRestore {
+		plan.Destructive = append(plan.Destructive, "restore "+filepath.Base(opts.Backup.OutputFile)+" on the replica")
+	}
+	if opts.Force {
+		plan.Destructive = append(plan.Destructive, "STOP SLAVE and RESET SLAVE ALL on the replica")
+	}
+	for _, stmt := range replication.ChangeMasterStatements(opts, opts.Backup.ReplicationInfo.Bookmark) {
+		plan.Actions = append(plan.Actions, replication.MaskPassword(stmt, opts.ReplicationPassword))
+	}
+	return plan
+}
+
+func init() {
+	SeedCmd.Flags().String("from-backup", "", "backup to seed from: manifest name without .meta.json, or a manifest path")
+	SeedCmd.Flags().String("replica-config", "", "encrypted configuration file (.cnf.enc) of the replica")
+	SeedCmd.Flags().String("target_host", "", "replica host (without --replica-config)")
+	SeedCmd.Flags().Int("target_port", 0, "replica port")
+	SeedCmd.Flags().String("target_user", "", "replica user")
+	SeedCmd.Flags().String("target_password", "", "replica password")
+	SeedCmd.Flags().String("backup-dir", "", "backup directory holding the catalog (default backup.storage.base_directory)")
+	SeedCmd.Flags().String("source-host", "", "source to replicate from (default: the host the backup was taken from)")
+	SeedCmd.Flags().Int("source-port", 0, "source port (default: the port the backup was taken from)")
+	SeedCmd.Flags().String("replication-user", "repl", "replication user on the source (or SFDB_REPLICATION_USER)")
+	SeedCmd.Flags().String("replication-password", "", "password of the replication user (or SFDB_REPLICATION_PASSWORD)")
+	SeedCmd.Flags().Bool("use-gtid", false, "start from the recorded GTID position instead of the binlog file and position")
+	SeedCmd.Flags().Bool("skip-restore", false, "only configure replication; the backup was restored already")
+	SeedCmd.Flags().Bool("start-replica", false, "start replication after configuring it")
+	SeedCmd.Flags().Bool("force", false, "replace replication the replica already has configured")
+	SeedCmd.Flags().Bool("dry-run", false, "show the plan and the CHANGE MASTER statement without changing anything")
+}
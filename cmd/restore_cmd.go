@@ -30,4 +30,8 @@ func init() {
 	rootCmd.AddCommand(RestoreCmd)
 	RestoreCmd.AddCommand(restore_cmd.AllRestoreCMD)
 	RestoreCmd.AddCommand(restore_cmd.SingleRestoreCmd)
+	RestoreCmd.AddCommand(restore_cmd.TableRestoreCmd)
+	RestoreCmd.AddCommand(restore_cmd.DrillRestoreCmd)
+	RestoreCmd.AddCommand(restore_cmd.ServerStateRestoreCmd)
+	RestoreCmd.AddCommand(restore_cmd.PhysicalRestoreCmd)
 }
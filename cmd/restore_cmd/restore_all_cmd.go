@@ -2,11 +2,11 @@ package restore_cmd
 
 import (
 	"fmt"
-	"os"
 
 	restore "sfDBTools/internal/core/restore/all"
 	restoreUtils "sfDBTools/internal/core/restore/utils"
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/maintenance"
 	restore_utils "sfDBTools/utils/restore"
 
 	"github.com/spf13/cobra"
@@ -32,13 +32,12 @@ sfDBTools restore all  # Fully interactive - will prompt for everything
 sfDBTools restore all --create-new-db --file ./backup/database_backup.sql.gz  # Create new database with manual name input
 sfDBTools restore all --create-new-db --db-from-filename --file ./backup/database_backup.sql.gz  # Create new database using name from filename
 sfDBTools restore all --target_host localhost --target_user root --create-new-db  # Interactive mode with new database option`,
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := executeRestoreAll(cmd); err != nil {
-			lg, _ := logger.Get()
-			lg.Error("Restore failed", logger.Error(err))
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, err := maintenance.Enter(cmd, "restore-all")
+		if err != nil {
+			return err
 		}
+		return session.Close(executeRestoreAll(cmd))
 	},
 }
 
@@ -63,6 +62,11 @@ func executeRestoreAll(cmd *cobra.Command) error {
 	// Display parameters before execution
 	restore_utils.DisplayRestoreParameters(options)
 
+	// Warn about the binlog volume replicas have to replay
+	if err := restore_utils.CheckReplicaImpact(cmd, &options); err != nil {
+		return err
+	}
+
 	// Prompt for confirmation before proceeding
 	if err := restore_utils.PromptRestoreConfirmation(options); err != nil {
 		lg.Info("Restore operation cancelled", logger.String("reason", err.Error()))
@@ -71,16 +75,20 @@ func executeRestoreAll(cmd *cobra.Command) error {
 
 	// Convert to internal RestoreOptions for backward compatibility
 	internalOptions := restoreUtils.RestoreOptions{
-		Host:           options.Host,
-		Port:           options.Port,
-		User:           options.User,
-		Password:       options.Password,
-		File:           options.File,
-		VerifyChecksum: options.VerifyChecksum,
+		Host:              options.Host,
+		Port:              options.Port,
+		User:              options.User,
+		Password:          options.Password,
+		File:              options.File,
+		VerifyChecksum:    options.VerifyChecksum,
+		SessionVars:       options.SessionVars,
+		GlobalVars:        options.GlobalVars,
+		ExtraArgs:         options.ExtraArgs,
+		EncryptionKeyFile: options.EncryptionKeyFile,
 	}
 
 	// Perform the restore
-	if err := restore.RestoreAll(internalOptions); err != nil {
+	if err := restore.RestoreAll(cmd.Context(), internalOptions); err != nil {
 		lg.Error("Restore operation failed", logger.Error(err))
 		return fmt.Errorf("restore failed: %w", err)
 	}
@@ -0,0 +1,165 @@
+package restore_cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/restore/drill"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+var DrillRestoreCmd = &cobra.Command{
+	Use:   "drill",
+	Short: "Run restore drills: restore a random recent backup per database into a scratch schema and verify it",
+	Long: `Restore drills prove that backups can actually be restored.
+
+For each database with single-database backups in the backup directory, the drill:
+  1. picks a random backup newer than --max-age
+  2. restores it into a scratch schema (--scratch-prefix + name + timestamp), rewriting
+     schema-qualified references to the scratch name
+  3. verifies the checksum and compares table/view/routine/trigger counts with the backup metadata
+  4. drops the scratch schema (unless --keep-scratch)
+  5. appends the result to the drill catalog
+
+The command exits non-zero when any drill fails or when a database has had no successful
+drill within --window, so schedulers and monitoring can alert on it. To run drills
+periodically, add a schedule with "command: drill" under backup.schedules and run
+'sfDBTools backup schedule install-systemd'.`,
+	Example: `sfDBTools restore drill
+sfDBTools restore drill --databases sales,hr --max-age 3d --window 7d
+sfDBTools restore drill --target_host 10.0.0.20 --target_user drill --keep-scratch`,
+	Annotations: map[string]string{
+		"command":  "restore",
+		"category": "restore",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeRestoreDrill(cmd)
+	},
+}
+
+func executeRestoreDrill(cmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	host, port, user, password, err := config.GetDatabaseCredentials()
+	if err != nil {
+		return fmt.Errorf("failed to resolve database credentials: %w", err)
+	}
+
+	baseDir := common.GetStringFlagOrEnv(cmd, "backup-dir", "DRILL_BACKUP_DIR", cfg.Backup.Storage.BaseDirectory)
+	if baseDir == "" {
+		return fmt.Errorf("backup directory is not set; use --backup-dir or backup.storage.base_directory")
+	}
+	catalog := common.GetStringFlagOrEnv(cmd, "catalog", "DRILL_CATALOG", cfg.Backup.Drill.Catalog)
+	if catalog == "" {
+		catalog = filepath.Join(baseDir, "restore_drills.json")
+	}
+	maxAge, err := common.ParseDurationWithDays(common.GetStringFlagOrEnv(cmd, "max-age", "DRILL_MAX_AGE", defaultString(cfg.Backup.Drill.MaxAge, "7d")))
+	if err != nil {
+		return err
+	}
+	window, err := common.ParseDurationWithDays(common.GetStringFlagOrEnv(cmd, "window", "DRILL_WINDOW", defaultString(cfg.Backup.Drill.Window, "14d")))
+	if err != nil {
+		return err
+	}
+
+	var databases []string
+	if list := common.GetStringFlagOrEnv(cmd, "databases", "DRILL_DATABASES", ""); list != "" {
+		for _, db := range strings.Split(list, ",") {
+			if db = strings.TrimSpace(db); db != "" {
+				databases = append(databases, db)
+			}
+		}
+	}
+	keepScratch, _ := cmd.Flags().GetBool("keep-scratch")
+
+	terminal.Headers("Restore Tools - Restore Drill")
+	result, err := drill.Run(cmd.Context(), drill.Options{
+		Target: database.Config{
+			Host:     common.GetStringFlagOrEnv(cmd, "target_host", "TARGET_HOST", host),
+			Port:     common.GetIntFlagOrEnv(cmd, "target_port", "TARGET_PORT", port),
+			User:     common.GetStringFlagOrEnv(cmd, "target_user", "TARGET_USER", user),
+			Password: common.GetStringFlagOrEnv(cmd, "target_password", "TARGET_PASSWORD", password),
+		},
+		BaseDir:       baseDir,
+		CatalogPath:   catalog,
+		Databases:     databases,
+		MaxAge:        maxAge,
+		Window:        window,
+		ScratchPrefix: common.GetStringFlagOrEnv(cmd, "scratch-prefix", "DRILL_SCRATCH_PREFIX", defaultString(cfg.Backup.Drill.ScratchPrefix, "_drill_")),
+		KeepScratch:   keepScratch,
+	})
+	if err != nil {
+		return err
+	}
+
+	return displayDrillResult(result, catalog)
+}
+
+func displayDrillResult(result *drill.Result, catalog string) error {
+	failed := 0
+	if len(result.Records) == 0 {
+		terminal.PrintWarning("No recent backups found to drill")
+	} else {
+		rows := make([][]string, 0, len(result.Records))
+		for _, r := range result.Records {
+			status := "PASS"
+			detail := ""
+			if !r.Success {
+				status = "FAIL"
+				detail = r.Error
+				if len(r.Mismatches) > 0 {
+					detail = strings.Join(r.Mismatches, "; ")
+				}
+				failed++
+			}
+			rows = append(rows, []string{r.Database, filepath.Base(r.BackupFile), r.Duration, status, detail})
+		}
+		terminal.FormatTable([]string{"Database", "Backup", "Duration", "Result", "Detail"}, rows)
+	}
+	terminal.PrintInfo(fmt.Sprintf("Results recorded in %s", catalog))
+
+	for _, a := range result.Alerts {
+		last := "never"
+		if !a.LastSuccess.IsZero() {
+			last = common.HumanizeTime(a.LastSuccess)
+		}
+		terminal.PrintWarning(fmt.Sprintf("%s: no successful restore drill within window (last success: %s)", a.Database, last))
+	}
+
+	if failed > 0 || len(result.Alerts) > 0 {
+		return fmt.Errorf("%d drill(s) failed, %d database(s) without a recent successful drill", failed, len(result.Alerts))
+	}
+	warnings.PrintSummary()
+	terminal.PrintSuccess("All restore drills passed")
+	return nil
+}
+
+func defaultString(val, fallback string) string {
+	if val != "" {
+		return val
+	}
+	return fallback
+}
+
+func init() {
+	DrillRestoreCmd.Flags().String("target_host", "", "server to restore scratch schemas into (default from config)")
+	DrillRestoreCmd.Flags().Int("target_port", 0, "target database port")
+	DrillRestoreCmd.Flags().String("target_user", "", "target database user")
+	DrillRestoreCmd.Flags().String("target_password", "", "target database password")
+	DrillRestoreCmd.Flags().String("backup-dir", "", "directory to pick backups from (default backup.storage.base_directory)")
+	DrillRestoreCmd.Flags().String("catalog", "", "drill catalog file (default backup.drill.catalog)")
+	DrillRestoreCmd.Flags().String("databases", "", "comma-separated databases to drill (default: all with backups)")
+	DrillRestoreCmd.Flags().String("max-age", "", "only drill backups newer than this, e.g. 7d (default backup.drill.max_age)")
+	DrillRestoreCmd.Flags().String("window", "", "alert when no successful drill within this window, e.g. 14d (default backup.drill.window)")
+	DrillRestoreCmd.Flags().String("scratch-prefix", "", "prefix for scratch schema names (default backup.drill.scratch_prefix)")
+	DrillRestoreCmd.Flags().Bool("keep-scratch", false, "keep scratch schemas after the drill for inspection")
+}
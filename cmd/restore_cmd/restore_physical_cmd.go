@@ -0,0 +1,116 @@
+package restore_cmd
+
+import (
+	"fmt"
+
+	"sfDBTools/internal/core/backup/physical"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/maintenance"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+var PhysicalRestoreCmd = &cobra.Command{
+	Use:   "physical <id|file|manifest>",
+	Short: "Restore a physical backup into the datadir of the local server",
+	Long: `Restore a backup taken with 'backup physical' into the datadir of the MariaDB
+server on this host.
+
+The chain ending at the given backup (the full backup and its incrementals) is
+extracted and prepared in --work-dir, next to the datadir by default, so the
+space of a full copy is needed there. Then the server is stopped, the current
+datadir is renamed to <datadir>.pre-restore-<time>, the prepared files are
+copied (or with --move-back moved) into a new datadir owned by mysql, and the
+server is started.
+
+When copying or starting fails, the previous datadir is put back and the server
+started on it. After a successful restore the previous datadir is kept; remove
+it once the restored server has been checked. Must run as root.`,
+	Example: `sudo sfDBTools restore physical 42
+sudo sfDBTools restore physical /backup/2025_01_01/physical/physical_20250101_020000_incr.meta.json --move-back
+sudo sfDBTools restore physical 42 --datadir /var/lib/mysql --service mariadb --work-dir /srv/restore --yes`,
+	Args: cobra.ExactArgs(1),
+	Annotations: map[string]string{
+		"command":  "restore",
+		"category": "restore",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		terminal.Headers("Restore Tools - Physical Restore")
+		session, err := maintenance.Enter(cmd, "restore-physical")
+		if err != nil {
+			return err
+		}
+		return session.Close(executePhysicalRestore(cmd, args[0]))
+	},
+}
+
+func executePhysicalRestore(cmd *cobra.Command, ref string) error {
+	m, err := physical.ResolveManifest(ref)
+	if err != nil {
+		return err
+	}
+	chain, err := physical.Chain(m)
+	if err != nil {
+		return err
+	}
+	moveBack, _ := cmd.Flags().GetBool("move-back")
+	skipConfirm := terminal.AssumeYes()
+	opts := physical.RestoreOptions{
+		Manifest:    m,
+		DataDir:     common.GetStringFlagOrEnv(cmd, "datadir", "RESTORE_PHYSICAL_DATADIR", ""),
+		ServiceName: common.GetStringFlagOrEnv(cmd, "service", "RESTORE_PHYSICAL_SERVICE", ""),
+		WorkDir:     common.GetStringFlagOrEnv(cmd, "work-dir", "RESTORE_PHYSICAL_WORK_DIR", ""),
+		MoveBack:    moveBack,
+	}
+	physical.ResolveRestoreTarget(&opts)
+	if opts.DataDir == "" {
+		return common.WithExitCode(fmt.Errorf("datadir could not be detected; pass --datadir"), common.ExitUsage)
+	}
+
+	plan := terminal.OperationPlan{
+		Operation: "Physical Restore",
+		Source:    fmt.Sprintf("%s (%d backup(s) in chain, LSN %d)", m.OutputFile, len(chain), m.Physical.ToLSN),
+		Target:    opts.DataDir,
+		Actions: []string{
+			"Prepare the backup chain",
+			"Stop " + opts.ServiceName,
+			"Restore into a new datadir owned by mysql",
+			"Start " + opts.ServiceName,
+		},
+		Destructive: []string{fmt.Sprintf("Replace the datadir %s; the server is down during the restore", opts.DataDir)},
+		Backup:      terminal.BackupSafety{Protected: true, Detail: "the current datadir is renamed aside, not deleted"},
+	}
+	if !skipConfirm && !terminal.ConfirmPlan(plan, "Replace the datadir?") {
+		return common.WithExitCode(fmt.Errorf("physical restore cancelled by user"), common.ExitCancelled)
+	}
+
+	result, err := physical.Restore(cmd.Context(), opts)
+	if err != nil {
+		return fmt.Errorf("physical restore failed: %w", err)
+	}
+
+	warnings.PrintSummary()
+	terminal.PrintSuccess(fmt.Sprintf("Datadir %s restored and %s started", result.DataDir, result.ServiceName))
+	rows := [][]string{
+		{"Backups applied", fmt.Sprintf("%d", len(result.Chain))},
+		{"Datadir", result.DataDir},
+		{"Duration", common.HumanizeDuration(result.Duration)},
+	}
+	if result.PreviousDataDir != "" {
+		rows = append(rows, []string{"Previous datadir", result.PreviousDataDir})
+	}
+	terminal.FormatTable([]string{"Item", "Value"}, rows)
+	if result.PreviousDataDir != "" {
+		terminal.PrintInfo("Remove the previous datadir once the restored server has been checked")
+	}
+	return nil
+}
+
+func init() {
+	PhysicalRestoreCmd.Flags().String("datadir", "", "datadir to replace (default: detected from the server configuration)")
+	PhysicalRestoreCmd.Flags().String("service", "", "systemd unit of the server (default: detected, mariadb)")
+	PhysicalRestoreCmd.Flags().String("work-dir", "", "where the chain is prepared (default: next to the datadir)")
+	PhysicalRestoreCmd.Flags().Bool("move-back", false, "move the prepared files instead of copying them")
+}
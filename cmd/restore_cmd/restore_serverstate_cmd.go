@@ -0,0 +1,147 @@
+package restore_cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"sfDBTools/internal/core/serverstate"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	restore_utils "sfDBTools/utils/restore"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+var ServerStateRestoreCmd = &cobra.Command{
+	Use:   "serverstate",
+	Short: "Reapply a server state bundle on a rebuilt host",
+	Long: `Reapply a bundle written by 'sfDBTools backup serverstate': install plugins
+and UDFs, SET GLOBAL the changed variables, recreate events and point the
+server at the replication source. Restore the databases first, since events
+are created in their schema.
+
+Objects already present are left alone, and some cannot be applied at runtime;
+these are listed with the reason and what to do instead:
+- plugins loaded through the configuration and read-only variables belong in my.cnf
+- host-specific variables (server_id, datadir, read_only, gtid_*, ...) are never copied
+- replication is configured only for GTID replicas and needs --replication-password;
+  a position-based replica is seeded with 'replication seed' instead
+
+SET GLOBAL does not survive a restart: persist the variables in my.cnf too.
+Use --dry-run to print the plan without changing anything. Exits non-zero when
+any statement fails.`,
+	Example: `sfDBTools restore serverstate --config ./config/newhost.cnf.enc --file ./backups/2026_01_10/serverstate/serverstate_20260110_020000.json --dry-run
+sfDBTools restore serverstate --config ./config/newhost.cnf.enc --file serverstate.json --only events,variables
+SFDB_REPLICATION_PASSWORD=secret sfDBTools restore serverstate --file serverstate.json --only replication --start-replica`,
+	Annotations: map[string]string{
+		"command":  "restore",
+		"category": "restore",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeServerStateRestore(cmd)
+	},
+}
+
+func executeServerStateRestore(cmd *cobra.Command) error {
+	file := common.GetStringFlagOrEnv(cmd, "file", "RESTORE_SERVERSTATE_FILE", "")
+	if file == "" {
+		return common.WithExitCode(fmt.Errorf("--file is required"), common.ExitUsage)
+	}
+	opts := serverstate.ApplyOptions{
+		Sections:            map[string]bool{},
+		ReplaceEvents:       common.GetBoolFlagOrEnv(cmd, "replace-events", "RESTORE_SERVERSTATE_REPLACE_EVENTS", false),
+		ReplicationPassword: common.GetStringFlagOrEnv(cmd, "replication-password", "SFDB_REPLICATION_PASSWORD", ""),
+		StartReplica:        common.GetBoolFlagOrEnv(cmd, "start-replica", "RESTORE_SERVERSTATE_START_REPLICA", false),
+	}
+	for _, section := range strings.Split(common.GetStringFlagOrEnv(cmd, "only", "RESTORE_SERVERSTATE_ONLY", ""), ",") {
+		if section = strings.ToLower(strings.TrimSpace(section)); section == "" {
+			continue
+		}
+		if !slices.Contains(serverstate.Sections, section) {
+			return common.WithExitCode(fmt.Errorf("invalid --only section %q: use %s", section, strings.Join(serverstate.Sections, ", ")), common.ExitUsage)
+		}
+		opts.Sections[section] = true
+	}
+	dryRun := common.GetBoolFlagOrEnv(cmd, "dry-run", "RESTORE_SERVERSTATE_DRY_RUN", false)
+
+	bundle, err := serverstate.Load(file)
+	if err != nil {
+		return err
+	}
+
+	terminal.Headers("Restore Tools - Server State Restore")
+	host, port, user, password, _, err := restore_utils.ResolveDatabaseConnection(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database connection: %w", err)
+	}
+	db, err := database.GetWithoutDB(database.Config{Host: host, Port: port, User: user, Password: password})
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s:%d: %w", host, port, err)
+	}
+	defer db.Close()
+
+	terminal.PrintInfo(fmt.Sprintf("Applying server state of %s:%d (%s, captured %s) to %s:%d",
+		bundle.Host, bundle.Port, bundle.ServerVersion, bundle.CreatedAt.Local().Format("2006-01-02 15:04:05"), host, port))
+	actions, err := serverstate.Plan(db, bundle, opts)
+	if err != nil {
+		return err
+	}
+	if len(actions) == 0 {
+		terminal.PrintWarning("Nothing to apply: the bundle has no objects in the selected sections")
+		return nil
+	}
+
+	if dryRun {
+		displayServerStatePlan(actions, "PLANNED")
+		for _, a := range actions {
+			if a.Skip == "" {
+				fmt.Printf("-- %s %s\n%s\n\n", a.Section, a.Name, a.Display())
+			}
+		}
+		terminal.PrintInfo("Dry run: no changes were made")
+		return nil
+	}
+
+	applied, failed := serverstate.Apply(db, actions)
+	displayServerStatePlan(actions, "APPLIED")
+	if failed > 0 {
+		return fmt.Errorf("%d of %d server state object(s) failed to apply", failed, applied+failed)
+	}
+	warnings.PrintSummary()
+	terminal.PrintSuccess(fmt.Sprintf("Server state restored: %d applied, %d skipped", applied, len(actions)-applied))
+	return nil
+}
+
+// displayServerStatePlan lists every action with its outcome; status is shown
+// for the actions that were not skipped
+func displayServerStatePlan(actions []*serverstate.Action, status string) {
+	rows := make([][]string, 0, len(actions))
+	for _, a := range actions {
+		result, detail := status, ""
+		switch {
+		case a.Skip != "":
+			result, detail = "SKIPPED", a.Skip
+		case a.Err != nil:
+			result, detail = "FAILED", a.Err.Error()
+		}
+		rows = append(rows, []string{a.Section, a.Name, result, detail})
+	}
+	terminal.FormatTable([]string{"Section", "Object", "Result", "Detail"}, rows)
+}
+
+func init() {
+	ServerStateRestoreCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc)")
+	ServerStateRestoreCmd.Flags().String("target_host", "", "target database host")
+	ServerStateRestoreCmd.Flags().Int("target_port", 0, "target database port")
+	ServerStateRestoreCmd.Flags().String("target_user", "", "target database user")
+	ServerStateRestoreCmd.Flags().String("target_password", "", "target database password")
+	ServerStateRestoreCmd.Flags().String("file", "", "server state bundle written by 'backup serverstate'")
+	ServerStateRestoreCmd.Flags().String("only", "", "comma-separated sections to apply: plugins, udfs, variables, events, replication (default: all)")
+	ServerStateRestoreCmd.Flags().Bool("dry-run", false, "print the plan and statements without applying them")
+	ServerStateRestoreCmd.Flags().Bool("replace-events", false, "drop and recreate events that already exist")
+	ServerStateRestoreCmd.Flags().String("replication-password", "", "password of the replication user (or SFDB_REPLICATION_PASSWORD)")
+	ServerStateRestoreCmd.Flags().Bool("start-replica", false, "start replication after configuring it")
+}
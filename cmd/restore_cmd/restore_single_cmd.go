@@ -2,12 +2,13 @@ package restore_cmd
 
 import (
 	"fmt"
-	"os"
 
 	restore "sfDBTools/internal/core/restore/single"
 	restoreUtils "sfDBTools/internal/core/restore/utils"
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/maintenance"
 	restore_utils "sfDBTools/utils/restore"
 	"sfDBTools/utils/terminal"
 
@@ -17,7 +18,71 @@ import (
 var SingleRestoreCmd = &cobra.Command{
 	Use:   "single",
 	Short: "Restore a single backup file",
-	Long:  `This command allows you to restore a single backup file with various options for database connection and validation.`,
+	Long: `This command allows you to restore a single backup file with various options for database connection and validation.
+
+Session settings (--set name=value, repeatable):
+  Session variables such as sql_mode, foreign_key_checks, unique_checks or
+  innodb_lock_wait_timeout are applied to the restore connection only, and again
+  after the header of a mysqldump file, which sets sql_mode, foreign_key_checks and
+  unique_checks itself. They are test-applied before the restore starts, so unknown
+  or invalid settings fail early.
+
+  max_allowed_packet and net_buffer_length are passed to the mysql client. The server
+  still enforces its own global values, which --set cannot change. If a dump needs more,
+  raise these on the server first (SET GLOBAL or the [mysqld] section of the config):
+    max_allowed_packet        largest statement/row the server accepts
+    innodb_log_file_size      large transactions in a single INSERT batch
+    wait_timeout / net_read_timeout  very slow or very large restores
+
+Loading on MySQL or older servers (--sanitize):
+  Rewrites MariaDB-specific syntax while the dump streams in: the sandbox-mode
+  directive, sequences, nextval() defaults, engines such as Aria (converted to
+  --sanitize-engine), page compression and encryption table options, system
+  versioning, uca1400 collations and the utf8mb3 charset name. Row data is never
+  changed. The transformations are listed after the restore. To produce a
+  converted file instead, use 'sfDBTools backup convert'.
+
+Failures and resuming:
+  The restore stops at the first failing statement and reports its number, table,
+  line and (truncated) text together with the number of statements applied. A
+  resume point is saved next to the backup as <file>.resume.json. After fixing the
+  problem, continue with --resume-from <statement> (or the resume file) instead of
+  reloading everything; the failed statement is run again and session settings
+  from the dump header are replayed.
+  Tables whose section of the dump (structure, data and triggers) completed before
+  the failure are recorded in <file>.loaded.json. Restoring the same file again
+  with --skip-loaded leaves those tables alone and reloads the others from their
+  DROP/CREATE TABLE, so a retry does not reload data that is already present.
+  --force keeps going past failing statements like 'mysql --force' and only
+  reports them at the end.
+
+Replica impact:
+  When the target writes a binary log, the restore estimates how much binlog it
+  will produce and how long replicas need to replay it, and warns above
+  --replica-impact-threshold (default 1G) before the confirmation. It then offers
+  to restore with sql_log_bin=0 (also --skip-binlog), which keeps the restore off
+  the replicas entirely: they diverge and must be restored separately. This has to
+  be acknowledged by typing DIVERGE, or with --ack-replica-divergence.
+
+Structural impact (--schema-diff):
+  Captures the tables, columns and indexes of the target database before the
+  restore and lists what was added, removed or changed once it finishes (also
+  after a failure). --schema-diff-file saves the list as JSON, e.g. as a record
+  for change management.
+
+Point-in-time recovery (--until):
+  After the backup is loaded, the binary logs written since the backup are
+  replayed up to --until, e.g. to recover to just before an accidental DROP.
+  Events at or after that time are not applied. Only events of the restored
+  database are replayed; when it is restored under another name they are
+  rewritten to it. The binlogs are read with mysqlbinlog from the restore
+  target, or from --binlog-host when the backup came from another server; the
+  account needs REPLICATION SLAVE. Before anything is restored, the binlogs are
+  checked to still reach back to the backup.
+  Replay starts at the binlog position recorded in the backup manifest. Without
+  one it starts at the time the dump began, which may apply the changes of that
+  second twice or miss them; --binlog-start file:position sets the position
+  explicitly.`,
 	Example: `sfDBTools restore single --config ./config/mydb.cnf.enc --file ./backup/database_backup.sql.gz
 sfDBTools restore single --target_db my_database --target_host localhost --target_port 3306 --target_user root --target_password my_password --file ./backup/database_backup.sql.gz
 sfDBTools restore single --target_host localhost --target_user root --file ./backup/database_backup.sql.gz  # Will prompt for database selection
@@ -27,14 +92,38 @@ sfDBTools restore single  # Fully interactive - will prompt for everything
 # Create new database options:
 sfDBTools restore single --create-new-db --file ./backup/database_backup.sql.gz  # Create new database with manual name input
 sfDBTools restore single --create-new-db --db-from-filename --file ./backup/database_backup.sql.gz  # Create new database using name from filename
-sfDBTools restore single --target_host localhost --target_user root --create-new-db  # Interactive mode with new database option`,
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := executeRestore(cmd); err != nil {
-			lg, _ := logger.Get()
-			lg.Error("Restore failed", logger.Error(err))
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+sfDBTools restore single --target_host localhost --target_user root --create-new-db  # Interactive mode with new database option
+
+# Restore a dump of "sales" into "sales_copy", rewriting sales.table references in views/routines/triggers:
+sfDBTools restore single --target_db sales_copy --rewrite-references --file ./backup/sales_backup.sql.gz
+
+# Restore with session settings required by the dump:
+sfDBTools restore single --file ./backup/app.sql.gz --set sql_mode=NO_AUTO_VALUE_ON_ZERO --set max_allowed_packet=1G
+
+# Restore a MariaDB dump on a MySQL server:
+sfDBTools restore single --target_db app --file ./backup/app.sql.gz --sanitize
+
+# Continue a restore that stopped at statement 18234:
+sfDBTools restore single --target_db app --file ./backup/app.sql.gz --resume-from 18234
+sfDBTools restore single --target_db app --file ./backup/app.sql.gz --resume-from ./backup/app.sql.gz.resume.json
+
+# Restore over an existing schema and keep a record of the structural changes:
+sfDBTools restore single --target_db app --file ./backup/app.sql.gz --schema-diff-file ./changes/app_restore_diff.json
+
+# Restore a large dump on a primary without replicating it (replicas are restored separately):
+sfDBTools restore single --target_db app --file ./backup/app.sql.gz --skip-binlog --ack-replica-divergence
+
+# Retry a failed restore from the start, skipping tables that were loaded completely:
+sfDBTools restore single --target_db app --file ./backup/app.sql.gz --skip-loaded
+
+# Recover app to just before a table was dropped at 13:00:
+sfDBTools restore single --target_db app --file ./backup/app.sql.gz --until "2024-01-02 12:59:59"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, err := maintenance.Enter(cmd, "restore-single")
+		if err != nil {
+			return err
 		}
+		return session.Close(executeRestore(cmd))
 	},
 }
 
@@ -65,6 +154,15 @@ func executeRestore(cmd *cobra.Command) error {
 
 	lg.Info("Starting restore process")
 
+	resumeFrom, resumeFile, err := restore.ParseResumeFrom(common.GetStringFlagOrEnv(cmd, "resume-from", "RESTORE_RESUME_FROM", ""))
+	if err != nil {
+		return err
+	}
+	force := common.GetBoolFlagOrEnv(cmd, "force", "RESTORE_FORCE", false)
+	if force && resumeFrom > 0 {
+		return fmt.Errorf("--force cannot be combined with --resume-from")
+	}
+
 	// Resolve restore configuration from various sources
 	restoreConfig, err := restore_utils.ResolveRestoreConfig(cmd)
 	if err != nil {
@@ -73,9 +171,23 @@ func executeRestore(cmd *cobra.Command) error {
 
 	// Convert to RestoreOptions for backward compatibility
 	options := restoreConfig.ToRestoreOptions()
+	if err := restore_utils.ResolvePointInTime(cmd, &options); err != nil {
+		return err
+	}
 
 	// Display parameters before execution
 	restore_utils.DisplayRestoreParameters(options)
+	if resumeFile != "" && resumeFile != options.File {
+		terminal.PrintWarning(fmt.Sprintf("Resume point was recorded for %s, not %s", resumeFile, options.File))
+	}
+	if resumeFrom > 0 {
+		terminal.PrintInfo(fmt.Sprintf("Resuming from statement %d", resumeFrom))
+	}
+
+	// Warn about the binlog volume replicas have to replay
+	if err := restore_utils.CheckReplicaImpact(cmd, &options); err != nil {
+		return err
+	}
 
 	// Prompt for confirmation before proceeding
 	if err := restore_utils.PromptRestoreConfirmation(options); err != nil {
@@ -85,17 +197,33 @@ func executeRestore(cmd *cobra.Command) error {
 
 	// Convert to internal RestoreOptions for backward compatibility
 	internalOptions := restoreUtils.RestoreOptions{
-		Host:           options.Host,
-		Port:           options.Port,
-		User:           options.User,
-		Password:       options.Password,
-		DBName:         options.DBName,
-		File:           options.File,
-		VerifyChecksum: options.VerifyChecksum,
+		Host:              options.Host,
+		Port:              options.Port,
+		User:              options.User,
+		Password:          options.Password,
+		DBName:            options.DBName,
+		File:              options.File,
+		VerifyChecksum:    options.VerifyChecksum,
+		RewriteReferences: options.RewriteReferences,
+		RewriteFrom:       options.RewriteFrom,
+		SessionVars:       options.SessionVars,
+		GlobalVars:        options.GlobalVars,
+		ExtraArgs:         options.ExtraArgs,
+		EncryptionKeyFile: options.EncryptionKeyFile,
+		StopOnError:       !force,
+		ResumeFrom:        resumeFrom,
+		SkipLoaded:        common.GetBoolFlagOrEnv(cmd, "skip-loaded", "RESTORE_SKIP_LOADED", false),
+		Sanitize:          common.GetBoolFlagOrEnv(cmd, "sanitize", "RESTORE_SANITIZE", false),
+		SanitizeEngine:    common.GetStringFlagOrEnv(cmd, "sanitize-engine", "RESTORE_SANITIZE_ENGINE", "InnoDB"),
+		SchemaDiffFile:    common.GetStringFlagOrEnv(cmd, "schema-diff-file", "RESTORE_SCHEMA_DIFF_FILE", ""),
+		Until:             options.Until,
+		BinlogStart:       options.BinlogStart,
+		BinlogSource:      options.BinlogSource,
 	}
+	internalOptions.SchemaDiff = internalOptions.SchemaDiffFile != "" || common.GetBoolFlagOrEnv(cmd, "schema-diff", "RESTORE_SCHEMA_DIFF", false)
 
 	// Perform the restore
-	if err := restore.RestoreSingle(internalOptions); err != nil {
+	if err := restore.RestoreSingle(cmd.Context(), internalOptions); err != nil {
 		lg.Error("Restore operation failed", logger.Error(err))
 		return fmt.Errorf("restore failed: %w", err)
 	}
@@ -107,4 +235,13 @@ func executeRestore(cmd *cobra.Command) error {
 
 func init() {
 	restore_utils.AddCommonRestoreFlags(SingleRestoreCmd)
+	restore_utils.AddPointInTimeFlags(SingleRestoreCmd)
+
+	SingleRestoreCmd.Flags().String("resume-from", "", "continue a failed restore from a statement number or a saved .resume.json file")
+	SingleRestoreCmd.Flags().Bool("skip-loaded", false, "skip tables a failed earlier restore of the same file loaded completely")
+	SingleRestoreCmd.Flags().Bool("sanitize", false, "rewrite MariaDB-specific syntax so the dump loads on MySQL or older servers")
+	SingleRestoreCmd.Flags().String("sanitize-engine", "InnoDB", "engine replacing non-portable storage engines (with --sanitize)")
+	SingleRestoreCmd.Flags().Bool("schema-diff", false, "capture the target schema before the restore and list structural changes afterwards")
+	SingleRestoreCmd.Flags().String("schema-diff-file", "", "also save the schema changes as JSON to this file (implies --schema-diff)")
+	SingleRestoreCmd.Flags().Bool("force", false, "continue past failing statements instead of stopping at the first one")
 }
@@ -0,0 +1,159 @@
+package restore_cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	restore_table "sfDBTools/internal/core/restore/table"
+	restoreUtils "sfDBTools/internal/core/restore/utils"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/maintenance"
+	restore_utils "sfDBTools/utils/restore"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+var TableRestoreCmd = &cobra.Command{
+	Use:   "table",
+	Short: "Restore selected tables from a database backup",
+	Long: `Restore only some tables of a backup instead of the whole database.
+
+For mysqldump backups the dump is streamed through a filter that keeps the
+sections mysqldump marks for each table (structure, data and triggers) and
+view, so nothing is unpacked to disk. Dumps written with --skip-comments have
+no section markers and cannot be filtered. For mydumper backups only the files
+of the selected tables are extracted and loaded with myloader; --file may also
+be a mydumper dump directory.
+
+Tables are given as db.table, or as table to match it in any database of the
+backup. They are restored into the database they were dumped from, or into
+--target_db. Existing tables with the same name are dropped and recreated.`,
+	Example: `sfDBTools restore table --file ./backup/appdb_20250101_020000.sql.gz --tables appdb.orders,appdb.order_items
+sfDBTools restore table --file ./backup/appdb_20250101_020000.sql.gz.enc --tables orders --target_db appdb_restore
+sfDBTools restore table --file ./backup/appdb_20250101_020000.mydumper.tar.zst --tables appdb.orders --config ./config/mydb.cnf.enc
+sfDBTools restore table --file /var/dumps/appdb --tables appdb.orders --target_host 10.0.0.5 --target_user admin`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, err := maintenance.Enter(cmd, "restore-table")
+		if err != nil {
+			return err
+		}
+		return session.Close(executeRestoreTable(cmd))
+	},
+}
+
+func executeRestoreTable(cmd *cobra.Command) error {
+	lg, err := logger.Get()
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	list := common.GetStringFlagOrEnv(cmd, "tables", "RESTORE_TABLES", "")
+	if list == "" {
+		return common.WithExitCode(fmt.Errorf("--tables is required"), common.ExitUsage)
+	}
+	tables, err := restore_table.ParseTables(list)
+	if err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	extraArgs := common.GetStringArrayFlagOrEnv(cmd, "restore-arg", "RESTORE_MYSQL_ARGS")
+	if err := common.ValidateRestoreArgs(extraArgs); err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+
+	// A mydumper dump directory is taken as it is; files go through the usual checks
+	file := common.GetStringFlagOrEnv(cmd, "file", "RESTORE_FILE", "")
+	if info, err := os.Stat(file); file == "" || err != nil || !info.IsDir() {
+		if file, err = restore_utils.ResolveBackupFile(cmd); err != nil {
+			return fmt.Errorf("failed to resolve backup file: %w", err)
+		}
+	}
+	host, port, user, password, _, err := restore_utils.ResolveDatabaseConnection(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database connection: %w", err)
+	}
+
+	opts := restore_table.Options{
+		RestoreOptions: restoreUtils.RestoreOptions{
+			Host:              host,
+			Port:              port,
+			User:              user,
+			Password:          password,
+			DBName:            common.GetStringFlagOrEnv(cmd, "target_db", "TARGET_DB", ""),
+			File:              file,
+			SessionVars:       common.GetStringArrayFlagOrEnv(cmd, "set", "RESTORE_SESSION_VARS"),
+			ExtraArgs:         extraArgs,
+			EncryptionKeyFile: common.GetStringFlagOrEnv(cmd, "encryption-key-file", "SFDB_ENCRYPTION_KEY_FILE", ""),
+		},
+		Tables: tables,
+	}
+
+	terminal.Headers("Restore Tables")
+	if !terminal.ConfirmPlan(buildTableRestorePlan(opts), "Restore these tables?") {
+		lg.Info("Table restore cancelled by user")
+		return fmt.Errorf("restore cancelled by user")
+	}
+
+	result, err := restore_table.RestoreTables(cmd.Context(), opts)
+	if result != nil {
+		displayTableRestoreResult(result)
+	}
+	if err != nil {
+		lg.Error("Table restore failed", logger.Error(err))
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	terminal.PrintSuccess(fmt.Sprintf("%d table(s) restored", len(result.Restored)))
+	return nil
+}
+
+// buildTableRestorePlan describes the restore for the confirmation prompt
+func buildTableRestorePlan(opts restore_table.Options) terminal.OperationPlan {
+	names := make([]string, len(opts.Tables))
+	for i, t := range opts.Tables {
+		names[i] = t.String()
+	}
+	target := fmt.Sprintf("%s:%d", opts.Host, opts.Port)
+	if opts.DBName != "" {
+		target += "/" + opts.DBName
+	} else {
+		target += " (databases from the backup)"
+	}
+	return terminal.OperationPlan{
+		Operation:   "Table Restore",
+		Source:      opts.File,
+		Target:      target,
+		Actions:     []string{"Restore tables: " + strings.Join(names, ", ")},
+		Destructive: []string{"Drop and recreate the selected tables in the target"},
+		Backup:      terminal.BackupSafety{Detail: "other tables are not touched"},
+	}
+}
+
+func displayTableRestoreResult(r *restore_table.Result) {
+	terminal.PrintSubHeader("Table Restore Result")
+	rows := make([][]string, 0, len(r.Restored)+len(r.Missing))
+	for _, name := range r.Restored {
+		rows = append(rows, []string{name, "restored"})
+	}
+	for _, name := range r.Missing {
+		rows = append(rows, []string{name, "not in backup"})
+	}
+	terminal.FormatTable([]string{"Table", "Status"}, rows)
+	fmt.Printf("Engine:   %s\n", r.Engine)
+	fmt.Printf("Duration: %s\n", common.HumanizeDuration(r.Duration))
+}
+
+func init() {
+	TableRestoreCmd.Flags().String("tables", "", "comma-separated tables to restore, as db.table or table (required)")
+	TableRestoreCmd.Flags().String("file", "", "backup file, or mydumper dump directory, to restore from")
+	TableRestoreCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc)")
+	TableRestoreCmd.Flags().String("target_db", "", "database to restore the tables into (default: the database they were dumped from)")
+	TableRestoreCmd.Flags().String("target_host", "", "target database host")
+	TableRestoreCmd.Flags().Int("target_port", 0, "target database port")
+	TableRestoreCmd.Flags().String("target_user", "", "target database user")
+	TableRestoreCmd.Flags().String("target_password", "", "target database password, or a secret reference (vault://, awssm://, envfile://)")
+	TableRestoreCmd.Flags().String("encryption-key-file", "", "key file an encrypted backup was written with (default: prompt for the password)")
+	TableRestoreCmd.Flags().StringArray("set", nil, "session setting for the restore connection, repeatable (e.g. --set foreign_key_checks=0)")
+	TableRestoreCmd.Flags().StringArray("restore-arg", nil, "extra mysql client option from the allowlist, e.g. --restore-arg=--binary-mode (repeatable)")
+}
@@ -1,11 +1,32 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	backup_cmd "sfDBTools/cmd/backup_cmd"
 	"sfDBTools/cmd/dbconfig_cmd"
 	mariadb_cmd "sfDBTools/cmd/mariadb_cmd"
+	maxscale_cmd "sfDBTools/cmd/maxscale_cmd"
+	postgres_cmd "sfDBTools/cmd/postgres_cmd"
 	"sfDBTools/internal/config/model"
 	"sfDBTools/internal/core/menu"
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/htmlreport"
+	"sfDBTools/utils/i18n"
+	"sfDBTools/utils/notify"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/progress"
+	"sfDBTools/utils/secrets"
+	"sfDBTools/utils/shutdown"
+	"sfDBTools/utils/tempdir"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
 
 	"github.com/spf13/cobra"
 )
@@ -13,14 +34,302 @@ import (
 var cfg *model.Config
 var lg *logger.Logger
 
+// report collects progress events when --report-html is set
+var report *htmlreport.Collector
+var reportPath string
+
+// stopNotifications ends the watch for operation results to notify and waits
+// for deliveries in flight
+var stopNotifications func()
+
 var rootCmd = &cobra.Command{
 	Use:   "sfDBTools",
 	Short: "sfDBTools CLI",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// First, so nothing meant for humans reaches stdout in JSON mode
+		if err := configureOutput(cmd); err != nil {
+			return err
+		}
+		if err := configureLanguage(cmd); err != nil {
+			return err
+		}
+		common.SetRawUnits(common.GetBoolFlagOrEnv(cmd, "raw-units", "SFDB_RAW_UNITS", false))
+		if err := configureAuthHint(cmd); err != nil {
+			return err
+		}
+		if err := configureTempDir(cmd); err != nil {
+			return err
+		}
+		if err := configureSecrets(); err != nil {
+			return err
+		}
+		if err := configureTraceSQL(cmd); err != nil {
+			return err
+		}
+		if err := configureTranscript(cmd); err != nil {
+			return err
+		}
+		if err := configureEvents(cmd); err != nil {
+			return err
+		}
+		configureReport(cmd)
+		stopNotifications = notify.WatchOperations()
+		return configurePrompts(cmd)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		menu.MenuUtama(lg, cfg)
 	},
 }
 
+func init() {
+	rootCmd.PersistentFlags().String("prompt-timeout", "", "give up on interactive prompts after this duration, e.g. 30s (0 waits forever)")
+	rootCmd.PersistentFlags().Bool("raw-units", false, "print exact numbers (bytes, seconds, bytes/s) instead of humanized sizes and durations")
+	rootCmd.PersistentFlags().BoolP("yes", "y", false, "answer yes to confirmations and take the default answer of other prompts")
+	rootCmd.PersistentFlags().String("prompt-on-timeout", "", "action when a prompt times out: default (use the default answer) or abort")
+	rootCmd.PersistentFlags().String("socket", "", "unix socket for connections to a local server (used instead of TCP)")
+	rootCmd.PersistentFlags().Bool("ignore-window", false, "run heavy operations (backups, restores, clones) outside the configured maintenance windows")
+	rootCmd.PersistentFlags().String("temp-dir", "", "directory for temporary files such as downloads and intermediate dumps (default: general.temp_dir, then TMPDIR or /tmp)")
+	rootCmd.PersistentFlags().String("trace-sql", "", "append every SQL statement run against servers, with timing and affected rows, to this file (passwords redacted, long statements cut)")
+	rootCmd.PersistentFlags().String("transcript", "", "also write the terminal output of this run, without colors, to this file (appends)")
+	rootCmd.PersistentFlags().String("events", "", "also write progress events (steps, bytes, warnings) of long operations to stderr; the only format is json (one object per line)")
+	rootCmd.PersistentFlags().String("output", "", "output format: text (default) or json (progress events and a final result record as JSON lines on stdout, everything else on stderr)")
+	rootCmd.PersistentFlags().String("output-format", "", "same as --output, for commands whose own --output flag names a file")
+	rootCmd.PersistentFlags().String("report-html", "", "write a self-contained HTML report of the run (steps, durations, sizes, warnings) to this file")
+	rootCmd.PersistentFlags().String("lang", "", "language of prompts and messages: en or id (default: general.locale.language, then LC_ALL, LC_MESSAGES or LANG)")
+	rootCmd.PersistentFlags().String("auth-plugin", "", "auth plugin of the database account: mysql_native_password, caching_sha2_password, sha256_password, mysql_clear_password, unix_socket or ed25519")
+}
+
+// configureAuthHint applies --socket and --auth-plugin to connections whose
+// encrypted config does not set them
+func configureAuthHint(cmd *cobra.Command) error {
+	hint := database.AuthHint{
+		Socket:     common.GetStringFlagOrEnv(cmd, "socket", "SFDB_DB_SOCKET", ""),
+		AuthPlugin: common.GetStringFlagOrEnv(cmd, "auth-plugin", "SFDB_AUTH_PLUGIN", ""),
+	}
+	if err := database.SetDefaultAuthHint(hint); err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	return nil
+}
+
+// configureOutput applies --output (--output-format, SFDB_OUTPUT). Commands
+// with their own --output flag shadow the global one; they take
+// --output-format or the environment.
+func configureOutput(cmd *cobra.Command) error {
+	format := common.GetStringFlagOrEnv(cmd, "output-format", "SFDB_OUTPUT", "")
+	if flag := cmd.Flags().Lookup("output"); flag != nil && flag == cmd.Root().PersistentFlags().Lookup("output") && flag.Changed {
+		format = flag.Value.String()
+	}
+	if err := output.Configure(format); err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	if output.JSON() {
+		// The console log was bound to stdout when the logger was built
+		lg.SetOutput(os.Stderr)
+	}
+	return nil
+}
+
+// PrepareOutput switches to JSON output before the command line is parsed
+// when --output json, --output-format json or SFDB_OUTPUT=json is given, so
+// the log lines written at startup already stay off stdout
+func PrepareOutput(logger *logger.Logger) {
+	format := os.Getenv("SFDB_OUTPUT")
+	args := os.Args[1:]
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--output" && name != "--output-format" {
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			value = args[i+1]
+		}
+		format = value
+	}
+	if format == output.FormatJSON && output.Configure(format) == nil {
+		logger.SetOutput(os.Stderr)
+	}
+}
+
+// configureLanguage selects the message language from --lang (SFDB_LANG),
+// general.locale.language or the locale environment
+func configureLanguage(cmd *cobra.Command) error {
+	langDefault := ""
+	if cfg != nil {
+		langDefault = cfg.General.Locale.Language
+	}
+	if err := i18n.Configure(common.GetStringFlagOrEnv(cmd, "lang", "SFDB_LANG", langDefault)); err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	return nil
+}
+
+// configureTempDir applies --temp-dir (SFDB_TEMP_DIR), general.temp_dir and
+// general.temp_min_free. The directory is only validated by the operations
+// that need it.
+func configureTempDir(cmd *cobra.Command) error {
+	dirDefault, minFree := "", ""
+	if cfg != nil {
+		dirDefault = cfg.General.TempDir
+		minFree = cfg.General.TempMinFree
+	}
+	var minFreeBytes int64
+	if minFree != "" {
+		if minFreeBytes = common.ParseSize(minFree); minFreeBytes <= 0 {
+			return common.WithExitCode(fmt.Errorf("invalid general.temp_min_free %q", minFree), common.ExitConfig)
+		}
+	}
+	dir := common.GetStringFlagOrEnv(cmd, "temp-dir", "SFDB_TEMP_DIR", dirDefault)
+	if err := tempdir.Configure(dir, minFreeBytes); err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	return nil
+}
+
+// configureSecrets registers the secret stores of the secrets section, so
+// vault://, awssm:// and envfile:// references resolve wherever credentials are read
+func configureSecrets() error {
+	var secretsCfg model.SecretsConfig
+	if cfg != nil {
+		secretsCfg = cfg.Secrets
+	}
+	if err := secrets.Configure(secretsCfg); err != nil {
+		return common.WithExitCode(err, common.ExitConfig)
+	}
+	return nil
+}
+
+// configureTraceSQL starts the SQL statement trace of --trace-sql (SFDB_TRACE_SQL)
+func configureTraceSQL(cmd *cobra.Command) error {
+	path := common.GetStringFlagOrEnv(cmd, "trace-sql", "SFDB_TRACE_SQL", "")
+	if path == "" {
+		return nil
+	}
+	if err := database.EnableTrace(path); err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	lg.Info("Tracing SQL statements", logger.String("path", path))
+	return nil
+}
+
+// configureTranscript starts copying terminal output to --transcript
+// (SFDB_TRANSCRIPT), or to a new file in general.transcript_dir
+func configureTranscript(cmd *cobra.Command) error {
+	path := common.GetStringFlagOrEnv(cmd, "transcript", "SFDB_TRANSCRIPT", "")
+	if path == "" && cfg != nil && cfg.General.TranscriptDir != "" {
+		name := strings.ReplaceAll(cmd.CommandPath(), " ", "_")
+		path = filepath.Join(cfg.General.TranscriptDir, fmt.Sprintf("%s_%s.txt", name, time.Now().Format("20060102_150405")))
+	}
+	if path == "" {
+		return nil
+	}
+	host, _ := os.Hostname()
+	header := fmt.Sprintf("=== %s | %s | host %s ===", redactedCommandLine(), time.Now().Format(time.RFC3339), host)
+	if err := terminal.StartTranscript(path, header); err != nil {
+		return common.WithExitCode(err, common.ExitUsage)
+	}
+	lg.Info("Writing terminal transcript", logger.String("path", path))
+	return nil
+}
+
+// configureEvents subscribes the terminal to progress events from the core
+// engines and, with --events json (SFDB_EVENTS), streams them to stderr
+func configureEvents(cmd *cobra.Command) error {
+	progress.Subscribe(progress.Terminal)
+	if output.JSON() {
+		progress.Subscribe(progress.JSONLines(output.Stdout()))
+	}
+	switch format := common.GetStringFlagOrEnv(cmd, "events", "SFDB_EVENTS", ""); format {
+	case "":
+	case "json":
+		progress.Subscribe(progress.JSONLines(os.Stderr))
+	default:
+		return common.WithExitCode(fmt.Errorf("invalid --events %q: only json is supported", format), common.ExitUsage)
+	}
+	return nil
+}
+
+// configureReport starts collecting progress events for --report-html
+// (SFDB_REPORT_HTML); Execute writes the file when the command returns
+func configureReport(cmd *cobra.Command) {
+	reportPath = common.GetStringFlagOrEnv(cmd, "report-html", "SFDB_REPORT_HTML", "")
+	if reportPath != "" {
+		report = htmlreport.Collect("sfDBTools "+strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "), redactedCommandLine())
+	}
+}
+
+// writeReport writes the --report-html file for the command's result
+func writeReport(runErr error) {
+	if report == nil {
+		return
+	}
+	if err := report.Write(reportPath, runErr); err != nil {
+		lg.Warn("Failed to write HTML report", logger.String("path", reportPath), logger.Error(err))
+		return
+	}
+	terminal.PrintInfo(fmt.Sprintf("HTML report written to %s", reportPath))
+}
+
+// writeResult writes the final JSON record of --output json
+func writeResult(executed *cobra.Command, runErr error) {
+	if !output.JSON() {
+		return
+	}
+	command := ""
+	if executed != nil {
+		command = strings.TrimPrefix(executed.CommandPath(), executed.Root().Name()+" ")
+	}
+	var collected any
+	if items := warnings.Items(); len(items) > 0 {
+		collected = items
+	}
+	output.Finish(command, runErr, common.ExitCode(runErr), collected)
+}
+
+// redactedCommandLine returns the command line with password flag values masked
+func redactedCommandLine() string {
+	args := make([]string, len(os.Args))
+	copy(args, os.Args)
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") || !strings.Contains(strings.ToLower(arg), "password") {
+			continue
+		}
+		if name, _, ok := strings.Cut(arg, "="); ok {
+			args[i] = name + "=***"
+		} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			args[i+1] = "***"
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// configurePrompts applies --yes and the prompt timeout settings from flags, environment and config in that order
+func configurePrompts(cmd *cobra.Command) error {
+	terminal.SetAssumeYes(common.GetBoolFlagOrEnv(cmd, "yes", "SFDB_YES", false))
+
+	timeoutDefault, actionDefault := "", ""
+	if cfg != nil {
+		timeoutDefault = cfg.General.Prompt.Timeout
+		actionDefault = cfg.General.Prompt.OnTimeout
+	}
+
+	rawTimeout := common.GetStringFlagOrEnv(cmd, "prompt-timeout", "SFDB_PROMPT_TIMEOUT", timeoutDefault)
+	action := common.GetStringFlagOrEnv(cmd, "prompt-on-timeout", "SFDB_PROMPT_ON_TIMEOUT", actionDefault)
+
+	var timeout time.Duration
+	if rawTimeout != "" && rawTimeout != "0" {
+		d, err := time.ParseDuration(rawTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid prompt timeout %q: %w", rawTimeout, err)
+		}
+		timeout = d
+	}
+	return terminal.ConfigurePrompts(timeout, action)
+}
+
 func Execute(config *model.Config, logger *logger.Logger) error {
 	// store provided config for use by commands
 	cfg = config
@@ -31,6 +340,26 @@ func Execute(config *model.Config, logger *logger.Logger) error {
 	dbconfig_cmd.Init(cfg, lg)
 	// ensure mariadb subpackage has access to cfg/lg as well
 	mariadb_cmd.Init(cfg, lg)
+	// maxscale subpackage reads proxy settings from cfg
+	maxscale_cmd.Init(cfg, lg)
+	backup_cmd.Init(cfg, lg)
+	postgres_cmd.Init(cfg, lg)
+
+	// Errors are printed and logged by the command middleware
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+	applyMiddleware(rootCmd)
+	warnings.Start()
 
-	return rootCmd.Execute()
+	// Commands reach the signal context through cmd.Context()
+	executed, err := rootCmd.ExecuteContextC(shutdown.Start())
+	err = finishExecute(err)
+	writeReport(err)
+	if stopNotifications != nil {
+		stopNotifications()
+	}
+	writeResult(executed, err)
+	database.CloseTrace()
+	terminal.StopTranscript(fmt.Sprintf("=== finished %s, exit code %d ===\n", time.Now().Format(time.RFC3339), common.ExitCode(err)))
+	return err
 }
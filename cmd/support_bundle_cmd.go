@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/support"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+var SupportBundleCmd = &cobra.Command{
+	Use:   "support-bundle",
+	Short: "Collect config, logs, catalog excerpts and server details into a tar.gz for support tickets",
+	Long: `Collect what support usually asks for into a single tar.gz:
+
+- versions of sfDBTools and the MariaDB client binaries
+- OS information (platform, kernel, disks, memory)
+- the sfDBTools YAML config, with passwords, tokens and keys masked
+- the end of the newest sfDBTools log files
+- the newest backup manifests and the restore drill catalog
+- with a connection (--config or --source_host): SHOW GLOBAL VARIABLES and the
+  end of the MariaDB error log, or of its systemd journal
+
+Secrets in logs and configs are masked as ***; encrypted connection files
+(.cnf.enc) are never included. Before packaging, the collected files are listed
+for review and any of them can be left out. README.txt in the bundle lists every
+file with its source and what could not be collected.`,
+	Example: `sfDBTools support-bundle
+sfDBTools support-bundle --config ./config/mydb.cnf.enc --output /tmp/ticket-1234.tar.gz
+sfDBTools support-bundle --source_host localhost --source_user root --yes`,
+	Annotations: map[string]string{
+		"command":  "support-bundle",
+		"category": "system",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeSupportBundle(cmd)
+	},
+}
+
+func executeSupportBundle(cmd *cobra.Command) error {
+	appCfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	terminal.Headers("Support Bundle")
+
+	opts := support.Options{
+		ConfigDir:      config.Dir(),
+		LogFiles:       common.GetIntFlagOrEnv(cmd, "log-files", "SUPPORT_LOG_FILES", 3),
+		LogLines:       common.GetIntFlagOrEnv(cmd, "log-lines", "SUPPORT_LOG_LINES", 2000),
+		BackupDir:      appCfg.Backup.Storage.BaseDirectory,
+		CatalogEntries: common.GetIntFlagOrEnv(cmd, "catalog-entries", "SUPPORT_CATALOG_ENTRIES", 20),
+		DrillCatalog:   appCfg.Backup.Drill.Catalog,
+		ErrorLogLines:  common.GetIntFlagOrEnv(cmd, "error-log-lines", "SUPPORT_ERROR_LOG_LINES", 500),
+	}
+	if appCfg.Log.Output.File.Enabled {
+		opts.LogDir = appCfg.Log.Output.File.Dir
+	}
+	if _, err := os.Stat(opts.DrillCatalog); err != nil {
+		opts.DrillCatalog = ""
+	}
+
+	// The server sections need a connection; without one they are skipped
+	// instead of prompting for a config file
+	if common.GetStringFlagOrEnv(cmd, "config", "BACKUP_CONFIG", "") != "" || cmd.Flags().Changed("source_host") {
+		host, port, user, password, _, err := backup_utils.ResolveDatabaseConnection(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to resolve database connection: %w", err)
+		}
+		opts.Server = &database.Config{Host: host, Port: port, User: user, Password: password}
+	}
+
+	bundle := support.Collect(opts)
+	if len(bundle.Items) == 0 {
+		return fmt.Errorf("nothing could be collected")
+	}
+
+	skipConfirm := terminal.AssumeYes()
+	if !skipConfirm {
+		if !reviewSupportBundle(bundle) {
+			return common.WithExitCode(fmt.Errorf("support bundle cancelled by user"), common.ExitUsage)
+		}
+	}
+
+	host, _ := os.Hostname()
+	output := common.GetStringFlagOrEnv(cmd, "output", "SUPPORT_BUNDLE_OUTPUT",
+		fmt.Sprintf("sfDBTools-support_%s_%s.tar.gz", host, common.LocalNow().Format("20060102_150405")))
+	if err := bundle.Write(output); err != nil {
+		return err
+	}
+
+	var size int64
+	for _, item := range bundle.Items {
+		size += int64(len(item.Data))
+	}
+	warnings.PrintSummary()
+	terminal.PrintSuccess(fmt.Sprintf("Support bundle written to %s (%d files, %s before compression)", output, len(bundle.Items), common.FormatSize(size)))
+	terminal.PrintInfo("Secrets were masked automatically; check the bundle before attaching it to a ticket")
+	return nil
+}
+
+// reviewSupportBundle lists the collected files and lets the operator leave
+// some out; false cancels the bundle
+func reviewSupportBundle(bundle *support.Bundle) bool {
+	for {
+		rows := make([][]string, len(bundle.Items))
+		for i, item := range bundle.Items {
+			rows[i] = []string{strconv.Itoa(i + 1), item.Name, common.FormatSize(int64(len(item.Data))), item.Source}
+		}
+		terminal.FormatTable([]string{"#", "File", "Size", "Source"}, rows)
+		for _, s := range bundle.Skipped {
+			terminal.PrintWarning("Not collected: " + s)
+		}
+
+		answer := strings.TrimSpace(terminal.AskString("Numbers of files to leave out (comma-separated, empty to keep all)", ""))
+		if answer == "" {
+			return terminal.Confirm("Create the support bundle?", true)
+		}
+		drop := make(map[int]bool)
+		valid := true
+		for _, part := range strings.Split(answer, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n < 1 || n > len(bundle.Items) {
+				terminal.PrintError(fmt.Sprintf("Invalid file number %q", strings.TrimSpace(part)))
+				valid = false
+				break
+			}
+			drop[n-1] = true
+		}
+		if !valid {
+			continue
+		}
+		kept := bundle.Items[:0]
+		for i, item := range bundle.Items {
+			if drop[i] {
+				bundle.Skipped = append(bundle.Skipped, item.Name+": left out during review")
+				continue
+			}
+			kept = append(kept, item)
+		}
+		bundle.Items = kept
+		if len(bundle.Items) == 0 {
+			return false
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(SupportBundleCmd)
+	SupportBundleCmd.Flags().String("config", "", "encrypted configuration file (.cnf.enc) of the server to include")
+	SupportBundleCmd.Flags().String("source_host", "", "database host to include")
+	SupportBundleCmd.Flags().Int("source_port", 0, "database port")
+	SupportBundleCmd.Flags().String("source_user", "", "database user")
+	SupportBundleCmd.Flags().String("source_password", "", "database password")
+	SupportBundleCmd.Flags().String("output", "", "bundle file (default ./sfDBTools-support_<host>_<timestamp>.tar.gz)")
+	SupportBundleCmd.Flags().Int("log-files", 3, "newest sfDBTools log files to include")
+	SupportBundleCmd.Flags().Int("log-lines", 2000, "lines kept from the end of each log file")
+	SupportBundleCmd.Flags().Int("error-log-lines", 500, "lines kept from the end of the MariaDB error log")
+	SupportBundleCmd.Flags().Int("catalog-entries", 20, "newest backup manifests to include")
+}
@@ -28,4 +28,5 @@ func init() {
 	SystemCmd.AddCommand(system_cmd.SystemDiskMonitorCmd)
 	SystemCmd.AddCommand(system_cmd.SystemDiskListCmd)
 	SystemCmd.AddCommand(system_cmd.SystemStorageMonitorCmd)
+	SystemCmd.AddCommand(system_cmd.SystemJobsCmd)
 }
@@ -3,14 +3,12 @@ package system_cmd
 import (
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
-	"sfDBTools/internal/logger"
-	"sfDBTools/utils/common/format"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/disk"
 	"sfDBTools/utils/fs"
+	"sfDBTools/utils/system"
 
 	"github.com/spf13/cobra"
 )
@@ -21,9 +19,7 @@ var SystemDiskCmd = &cobra.Command{
 	Short:   "Periksa ruang disk pada path tertentu",
 	Long:    "Periksa apakah tersedia ruang disk minimum (dalam MB) pada path yang diberikan.",
 	Example: `sfDBTools system disk-check --path /var/backups --min-mb 1024`,
-	Run: func(cmd *cobra.Command, args []string) {
-		lg, _ := logger.Get()
-
+	RunE: func(cmd *cobra.Command, args []string) error {
 		path, _ := cmd.Flags().GetString("path")
 		minMB, _ := cmd.Flags().GetInt64("min-mb")
 		showDetails, _ := cmd.Flags().GetBool("details")
@@ -33,21 +29,20 @@ var SystemDiskCmd = &cobra.Command{
 		}
 
 		if err := disk.CheckDiskSpace(path, minMB); err != nil {
-			lg.Error("Disk check failed", logger.Error(err))
-			fmt.Printf("Disk check failed: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("disk check failed: %w", err)
 		}
 
 		if showDetails {
 			stats, _ := disk.GetUsageStatistics(path)
 			fmt.Printf("Path: %s\nMount: %s (%s)\nFree: %s\nTotal: %s\nUsed: %s (%.1f%%)\n",
 				stats.Path, stats.Mountpoint, stats.Fstype,
-				format.FormatSizeWithPrecision(stats.Free, 2),
-				format.FormatSizeWithPrecision(stats.Total, 2),
-				format.FormatSizeWithPrecision(stats.Used, 2), stats.UsedPercent)
+				common.HumanizeSize(stats.Free),
+				common.HumanizeSize(stats.Total),
+				common.HumanizeSize(stats.Used), stats.UsedPercent)
 		} else {
 			fmt.Printf("Disk check passed for %s (required %d MB)\n", path, minMB)
 		}
+		return nil
 	},
 }
 
@@ -61,7 +56,11 @@ func init() {
 var SystemDiskMonitorCmd = &cobra.Command{
 	Use:   "disk-monitor",
 	Short: "Monitor ruang disk dan beri peringatan jika melampaui threshold",
-	Long:  "Monitor ruang disk secara periodik dan jalankan callback (stdout) jika persentase penggunaan melewati threshold.",
+	Long: `Monitor ruang disk secara periodik dan jalankan callback (stdout) jika persentase penggunaan melewati threshold.
+
+Dapat dijalankan sebagai service systemd Type=notify: READY dikirim setelah monitor
+berjalan, watchdog diberi ping bila WatchdogSec diset, dan SIGTERM menghentikan
+monitor dengan rapi.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		path, _ := cmd.Flags().GetString("path")
 		threshold, _ := cmd.Flags().GetFloat64("threshold")
@@ -71,16 +70,32 @@ var SystemDiskMonitorCmd = &cobra.Command{
 			path = string(os.PathSeparator)
 		}
 
+		daemon := system.NewDaemon("disk-monitor")
+		defer daemon.Stop()
+
 		stop := disk.MonitorDisk(path, time.Duration(intervalSec)*time.Second, threshold, func(u *fs.DiskUsage) {
-			fmt.Printf("[WARN] disk %s used %.1f%% (free %s)\n", u.Path, u.UsedPercent, format.FormatSizeWithPrecision(u.Free, 2))
+			fmt.Printf("[WARN] disk %s used %.1f%% (free %s)\n", u.Path, u.UsedPercent, common.HumanizeSize(u.Free))
 		})
+		defer stop()
 
 		fmt.Printf("Monitoring disk %s every %d seconds. Press CTRL+C to stop.\n", path, intervalSec)
-		// Wait until interrupted
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-		<-c
-		stop()
+		daemon.Ready(fmt.Sprintf("monitoring %s (threshold %.1f%%)", path, threshold))
+
+		// Wait until interrupted or stopped by systemd
+		var heartbeat <-chan time.Time
+		if tick := daemon.WatchdogTick(); tick > 0 {
+			t := time.NewTicker(tick)
+			defer t.Stop()
+			heartbeat = t.C
+		}
+		for {
+			select {
+			case <-daemon.Context().Done():
+				return
+			case <-heartbeat:
+				daemon.Alive()
+			}
+		}
 	},
 }
 
@@ -3,7 +3,7 @@ package system_cmd
 import (
 	"fmt"
 
-	"sfDBTools/utils/common/format"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/disk"
 
 	"github.com/spf13/cobra"
@@ -18,15 +18,20 @@ var SystemDiskListCmd = &cobra.Command{
 			fmt.Printf("Failed to list disk usages: %v\n", err)
 			return
 		}
-		fmt.Printf("%-30s %-8s %-8s %-8s %-6s %-6s\n", "Filesystem", "Size", "Used", "Avail", "Use%", "Type")
+		fmt.Printf("%-30s %-8s %-8s %-8s %-6s %-6s %-7s\n", "Filesystem", "Size", "Used", "Avail", "Use%", "Type", "Media")
 		for _, u := range partitions {
-			fmt.Printf("%-30s %-8s %-8s %-8s %-6.1f %-6s\n",
+			media := disk.MediaUnknown
+			if m, err := disk.DetectMedia(u.Mountpoint); err == nil {
+				media = m.Media
+			}
+			fmt.Printf("%-30s %-8s %-8s %-8s %-6.1f %-6s %-7s\n",
 				u.Mountpoint,
-				format.FormatSizeWithPrecision(u.Total, 1),
-				format.FormatSizeWithPrecision(u.Used, 1),
-				format.FormatSizeWithPrecision(u.Free, 1),
+				common.HumanizeSize(u.Total),
+				common.HumanizeSize(u.Used),
+				common.HumanizeSize(u.Free),
 				u.UsedPercent,
 				u.Fstype,
+				media,
 			)
 		}
 	},
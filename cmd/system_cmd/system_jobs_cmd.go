@@ -0,0 +1,107 @@
+package system_cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/terminal"
+	"sfDBTools/utils/warnings"
+
+	"github.com/spf13/cobra"
+)
+
+// SystemJobsCmd lists long-running operations from their job status files
+var SystemJobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Show progress of running and recent jobs",
+	Long: `Show progress of backup, restore and migrate operations started from any terminal.
+
+Each running operation writes a JSON status file to the job status directory
+(general.status_dir, overridable with SFDB_STATUS_DIR). External tools can read
+those files directly; this command lists them.`,
+	Example: `sfDBTools system jobs
+sfDBTools system jobs --json
+sfDBTools system jobs --clean`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		clean, _ := cmd.Flags().GetBool("clean")
+
+		if clean {
+			removed, err := jobstatus.Clean()
+			if err != nil {
+				return fmt.Errorf("failed to clean job status files: %w", err)
+			}
+			terminal.PrintSuccess(fmt.Sprintf("Removed %d finished job status file(s)", removed))
+			return nil
+		}
+
+		jobs, err := jobstatus.List()
+		if err != nil {
+			return fmt.Errorf("failed to read job status files: %w", err)
+		}
+
+		if output.JSON() {
+			output.SetResult(jobs)
+			return nil
+		}
+		if asJSON {
+			// Keep stdout valid JSON; each job carries its own warnings
+			warnings.Take()
+			data, _ := json.MarshalIndent(jobs, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(jobs) == 0 {
+			terminal.PrintInfo(fmt.Sprintf("No jobs found in %s", jobstatus.Dir()))
+			return nil
+		}
+
+		headers := []string{"Operation", "Target", "State", "Step", "Progress", "ETA", "Updated", "PID"}
+		rows := make([][]string, 0, len(jobs))
+		for _, j := range jobs {
+			rows = append(rows, []string{
+				j.Operation,
+				j.Target,
+				j.State,
+				j.Step,
+				jobProgress(j),
+				jobETA(j),
+				common.HumanizeDuration(time.Since(j.LastUpdate)) + " ago",
+				fmt.Sprintf("%d", j.PID),
+			})
+		}
+		terminal.FormatTable(headers, rows)
+		return nil
+	},
+}
+
+// jobProgress renders bytes and percentage for a job
+func jobProgress(j jobstatus.Job) string {
+	if j.BytesTotal > 0 {
+		return fmt.Sprintf("%.1f%% (%s / %s)", j.Percent,
+			common.HumanizeSize(j.BytesDone),
+			common.HumanizeSize(j.BytesTotal))
+	}
+	if j.BytesDone > 0 {
+		return common.HumanizeSize(j.BytesDone)
+	}
+	return "-"
+}
+
+// jobETA renders the remaining time estimate for running jobs
+func jobETA(j jobstatus.Job) string {
+	if j.State != jobstatus.StateRunning || j.ETASeconds <= 0 {
+		return "-"
+	}
+	return common.HumanizeDuration(time.Duration(j.ETASeconds) * time.Second)
+}
+
+func init() {
+	SystemJobsCmd.Flags().Bool("json", false, "print job status as JSON")
+	SystemJobsCmd.Flags().Bool("clean", false, "remove status files of finished, failed and stale jobs")
+}
@@ -1,17 +1,16 @@
 package system_cmd
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/signal"
 	"path/filepath"
 	"sync"
-	"syscall"
 	"time"
 
 	"sfDBTools/internal/logger"
-	"sfDBTools/utils/common/format"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/system"
 	"sort"
 
 	"github.com/spf13/cobra"
@@ -22,7 +21,7 @@ var SystemStorageMonitorCmd = &cobra.Command{
 	Use:   "storage-monitor",
 	Short: "Monitor storage usage per-database under configured data_dir",
 	Long:  "Load data_dir from configuration and monitor size of each immediate subdirectory (databases) every interval seconds.",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		lg, _ := logger.Get()
 
 		// load config
@@ -34,18 +33,14 @@ var SystemStorageMonitorCmd = &cobra.Command{
 
 		dataDir := ""
 		if dataDir == "" {
-			fmt.Println("data_dir not configured in config.yaml")
-			os.Exit(1)
+			return common.WithExitCode(errors.New("data_dir not configured in config.yaml"), common.ExitConfig)
 		}
 
 		interval, _ := cmd.Flags().GetInt("interval")
 		topN, _ := cmd.Flags().GetInt("top")
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-
-		sig := make(chan os.Signal, 1)
-		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		daemon := system.NewDaemon("storage-monitor")
+		defer daemon.Stop()
 
 		prev := map[string]int64{}
 
@@ -54,14 +49,23 @@ var SystemStorageMonitorCmd = &cobra.Command{
 		ticker := time.NewTicker(time.Duration(interval) * time.Second)
 		defer ticker.Stop()
 
+		var heartbeat <-chan time.Time
+		if tick := daemon.WatchdogTick(); tick > 0 {
+			t := time.NewTicker(tick)
+			defer t.Stop()
+			heartbeat = t.C
+		}
+		daemon.Ready(fmt.Sprintf("monitoring %s", dataDir))
+
 		for {
 			select {
-			case <-ctx.Done():
-				return
-			case <-sig:
+			case <-daemon.Context().Done():
 				fmt.Println("stopping storage monitor")
-				return
+				return nil
+			case <-heartbeat:
+				daemon.Alive()
 			case <-ticker.C:
+				daemon.Alive()
 				sizes, err := computeImmediateSubdirSizes(dataDir)
 				if err != nil {
 					fmt.Printf("error computing sizes: %v\n", err)
@@ -91,14 +95,14 @@ var SystemStorageMonitorCmd = &cobra.Command{
 					displayList = list[:topN]
 				}
 
-				ts := time.Now().Format("2006-01-02 15:04:05")
+				ts := common.LocalNow().Format("2006-01-02 15:04:05")
 				fmt.Printf("\n[%s] Top %d databases by size under %s:\n", ts, len(list), dataDir)
 				fmt.Printf("%-30s %-12s %-12s\n", "Database", "Size", "Growth/s")
 				for _, e := range displayList {
-					growthPerSec := e.delta / int64(interval)
+					growthPerSec := float64(e.delta) / float64(interval)
 					fmt.Printf("%-30s %-12s %-12s\n", e.name,
-						format.FormatSizeWithPrecision(e.size, 2),
-						format.FormatSizeWithPrecision(growthPerSec, 2))
+						common.HumanizeSize(e.size),
+						common.HumanizeRate(growthPerSec))
 				}
 
 				// update prev for all seen directories (not only displayed)
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+
 	"sfDBTools/utils/crypto"
 )
 
@@ -14,6 +17,77 @@ type EncryptedDatabaseConfig struct {
 	Port     int    `json:"port"`
 	User     string `json:"user"`
 	Password string `json:"password"`
+	// Socket and AuthPlugin are optional; see connection.Config
+	Socket     string `json:"socket,omitempty"`
+	AuthPlugin string `json:"auth_plugin,omitempty"`
+	// Hosts lists further servers of the same topology (replicas, a standby
+	// primary), probed after Host; Policy chooses among them, see
+	// connection.SelectEndpoint. Both are optional.
+	Hosts  []DatabaseEndpoint `json:"hosts,omitempty"`
+	Policy string             `json:"policy,omitempty"`
+}
+
+// DatabaseEndpoint is one more server of a multi-host database configuration
+type DatabaseEndpoint struct {
+	Host string `json:"host"`
+	Port int    `json:"port,omitempty"` // defaults to the port of the configuration
+}
+
+// ExtraHosts lists Hosts as host:port, for display
+func (c *EncryptedDatabaseConfig) ExtraHosts() string {
+	hosts := make([]string, len(c.Hosts))
+	for i, h := range c.Hosts {
+		port := h.Port
+		if port == 0 {
+			port = c.Port
+		}
+		hosts[i] = fmt.Sprintf("%s:%d", h.Host, port)
+	}
+	return strings.Join(hosts, ", ")
+}
+
+// ParseDatabaseEndpoints parses a comma-separated host[:port] list; hosts
+// without a port use defaultPort
+func ParseDatabaseEndpoints(list string, defaultPort int) ([]DatabaseEndpoint, error) {
+	var endpoints []DatabaseEndpoint
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		endpoint := DatabaseEndpoint{Host: item, Port: defaultPort}
+		if host, port, ok := strings.Cut(item, ":"); ok {
+			n, err := strconv.Atoi(port)
+			if err != nil || n < 1 || n > 65535 || host == "" {
+				return nil, fmt.Errorf("invalid host %q: expected host or host:port", item)
+			}
+			endpoint = DatabaseEndpoint{Host: host, Port: n}
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+// credentialResolver replaces secret references (vault://, awssm://, ...) in
+// users and passwords. The secrets package registers it at startup; this
+// package cannot import it without an import cycle through the logger.
+var credentialResolver func(user, password string) (string, string, error)
+
+// SetCredentialResolver registers the function resolving secret references
+func SetCredentialResolver(resolve func(user, password string) (string, string, error)) {
+	credentialResolver = resolve
+}
+
+// withResolvedCredentials returns the connection values with secret
+// references in user and password resolved
+func withResolvedCredentials(host string, port int, user, password string) (string, int, string, string, error) {
+	if credentialResolver != nil {
+		var err error
+		if user, password, err = credentialResolver(user, password); err != nil {
+			return "", 0, "", "", fmt.Errorf("failed to resolve database credentials: %w", err)
+		}
+	}
+	return host, port, user, password, nil
 }
 
 // LoadEncryptedDatabaseConfig loads and decrypts the database configuration
@@ -51,7 +125,9 @@ func LoadEncryptedDatabaseConfig(encryptionPassword string) (*EncryptedDatabaseC
 	}
 
 	return &dbConfig, nil
-} // GetDatabaseConfigWithEncryption returns database configuration, preferring encrypted config if available
+}
+
+// GetDatabaseConfigWithEncryption returns database configuration, preferring encrypted config if available
 func GetDatabaseConfigWithEncryption() (host string, port int, user, password string, err error) {
 	// Load main config
 	cfg, err := Get()
@@ -63,7 +139,7 @@ func GetDatabaseConfigWithEncryption() (host string, port int, user, password st
 	encryptedConfigPath := filepath.Join("./config", "database.encrypted")
 	if _, statErr := os.Stat(encryptedConfigPath); os.IsNotExist(statErr) {
 		// If encrypted config is not available, fallback to plain config
-		return cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, nil
+		return withResolvedCredentials(cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password)
 	}
 
 	// Try to load encrypted database config
@@ -78,7 +154,7 @@ func GetDatabaseConfigWithEncryption() (host string, port int, user, password st
 	}
 
 	// Return encrypted configuration
-	return encryptedDB.Host, encryptedDB.Port, encryptedDB.User, encryptedDB.Password, nil
+	return withResolvedCredentials(encryptedDB.Host, encryptedDB.Port, encryptedDB.User, encryptedDB.Password)
 }
 
 // GetDatabaseConfigWithPassword returns database configuration using provided encryption password
@@ -93,7 +169,7 @@ func GetDatabaseConfigWithPassword(encryptionPassword string) (host string, port
 	encryptedConfigPath := filepath.Join("./config", "database.encrypted")
 	if _, statErr := os.Stat(encryptedConfigPath); os.IsNotExist(statErr) {
 		// If encrypted config is not available, fallback to plain config
-		return cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, nil
+		return withResolvedCredentials(cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password)
 	}
 
 	// Load encrypted database config with provided password
@@ -103,7 +179,7 @@ func GetDatabaseConfigWithPassword(encryptionPassword string) (host string, port
 	}
 
 	// Return encrypted configuration
-	return encryptedDB.Host, encryptedDB.Port, encryptedDB.User, encryptedDB.Password, nil
+	return withResolvedCredentials(encryptedDB.Host, encryptedDB.Port, encryptedDB.User, encryptedDB.Password)
 }
 
 // ValidateEncryptedDatabaseConfig validates that the encrypted database configuration can be decrypted
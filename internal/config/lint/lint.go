@@ -0,0 +1,469 @@
+// Package lint memeriksa config.yaml sebelum dipakai dan melaporkan setiap
+// masalah dengan nomor barisnya, sehingga kesalahan konfigurasi ketahuan saat
+// 'config validate' dan bukan di tengah backup atau restore.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config/model"
+	"sfDBTools/internal/config/validate"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/compression"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity membedakan masalah yang membuat config ditolak atau operasi gagal
+// (error) dari yang patut diperiksa (warning)
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue adalah satu temuan lint. Line adalah baris key di config.yaml, 0 bila
+// key tidak ditulis di file (nilai default).
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Key      string   `json:"key,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Message  string   `json:"message"`
+	Hint     string   `json:"hint,omitempty"`
+}
+
+// Report berisi temuan lint satu file, urut per baris
+type Report struct {
+	File   string  `json:"file"`
+	Issues []Issue `json:"issues"`
+
+	lines map[string]int
+}
+
+// Count mengembalikan jumlah temuan dengan severity tersebut
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Report) add(severity Severity, key, hint, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{
+		Severity: severity,
+		Key:      key,
+		Line:     r.line(key),
+		Message:  fmt.Sprintf(format, args...),
+		Hint:     hint,
+	})
+}
+
+// line mencari baris key; key yang tidak ada di file memakai baris parent terdekat
+func (r *Report) line(key string) int {
+	for key != "" {
+		if n, ok := r.lines[key]; ok {
+			return n
+		}
+		i := strings.LastIndexAny(key, ".[")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return 0
+}
+
+// yamlErrorLine mengambil nomor baris dari pesan error yaml.v3
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// decodeErrorKey mengambil key dari pesan error decode, mis. 'backup.retention.days'
+var decodeErrorKey = regexp.MustCompile(`'([a-z0-9_.\[\]]+)'`)
+
+// File memeriksa config.yaml di path tanpa mengubah apa pun: sintaks YAML,
+// key yang tidak dikenal, field wajib, path dan permission, rotasi log dan
+// default backup. Error hanya dikembalikan bila file tidak bisa dibaca.
+func File(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{File: path, lines: make(map[string]int)}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		line := 0
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		r.Issues = append(r.Issues, Issue{Severity: SeverityError, Line: line,
+			Message: fmt.Sprintf("YAML tidak valid: %v", err), Hint: "Perbaiki indentasi atau tanda kutip pada baris tersebut"})
+		return r, nil
+	}
+	if len(root.Content) > 0 {
+		indexLines(root.Content[0], "", r.lines)
+	}
+	lintUnknownKeys(r)
+
+	// Dibaca dengan viper seperti saat startup, tanpa override environment
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		r.add(SeverityError, "", "", "gagal membaca config: %v", err)
+		return r, nil
+	}
+	var c model.Config
+	if err := v.Unmarshal(&c); err != nil {
+		for _, e := range unwrapAll(err) {
+			key := ""
+			if m := decodeErrorKey.FindStringSubmatch(e.Error()); m != nil {
+				key = m[1]
+			}
+			r.add(SeverityError, key, "Periksa tipe nilai (angka, boolean, teks atau list)", "%v", e)
+		}
+		// Field lain tetap terisi, jadi pemeriksaan berikutnya tetap berguna
+	}
+
+	lintGeneral(r, &c)
+	lintLog(r, &c)
+	lintPaths(r, &c, path)
+	lintBackup(r, &c)
+	sortIssues(r)
+	return r, nil
+}
+
+// indexLines mencatat baris setiap key dengan path bertitik; elemen list
+// ditulis sebagai key[i]
+func indexLines(n *yaml.Node, prefix string, lines map[string]int) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := strings.ToLower(n.Content[i].Value)
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			lines[key] = n.Content[i].Line
+			indexLines(n.Content[i+1], key, lines)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			key := fmt.Sprintf("%s[%d]", prefix, i)
+			lines[key] = item.Line
+			indexLines(item, key, lines)
+		}
+	}
+}
+
+// listIndex menormalkan key[3] menjadi key[] untuk dicocokkan dengan model
+var listIndex = regexp.MustCompile(`\[\d+\]`)
+
+// lintUnknownKeys menandai key yang tidak ada di model.Config; nilainya
+// diabaikan saat startup, biasanya karena salah ketik atau salah indentasi
+func lintUnknownKeys(r *Report) {
+	known, open := map[string]bool{}, map[string]bool{}
+	modelKeys(reflect.TypeOf(model.Config{}), "", known, open)
+
+	var keys []string
+	for key := range r.lines {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	reported := map[string]bool{}
+	for _, key := range keys {
+		norm := listIndex.ReplaceAllString(key, "[]")
+		if known[norm] || underOpen(norm, open) || underReported(key, reported) {
+			continue
+		}
+		reported[key] = true
+		r.add(SeverityWarning, key, "Periksa ejaan dan indentasi key; lihat config/config.yaml untuk struktur yang benar",
+			"key tidak dikenal, nilainya diabaikan")
+	}
+}
+
+func underOpen(key string, open map[string]bool) bool {
+	for prefix := range open {
+		if strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func underReported(key string, reported map[string]bool) bool {
+	for prefix := range reported {
+		if strings.HasPrefix(key, prefix+".") || strings.HasPrefix(key, prefix+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// modelKeys mengumpulkan key mapstructure dari t; key bertipe map menerima
+// sub-key apa pun
+func modelKeys(t reflect.Type, prefix string, known, open map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := strings.Split(f.Tag.Get("mapstructure"), ",")[0]
+			if tag == "" || tag == "-" {
+				continue
+			}
+			key := tag
+			if prefix != "" {
+				key = prefix + "." + tag
+			}
+			known[key] = true
+			modelKeys(f.Type, key, known, open)
+		}
+	case reflect.Slice, reflect.Array:
+		known[prefix+"[]"] = true
+		modelKeys(t.Elem(), prefix+"[]", known, open)
+	case reflect.Map:
+		open[prefix] = true
+	}
+}
+
+func unwrapAll(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var out []error
+		for _, e := range joined.Unwrap() {
+			out = append(out, unwrapAll(e)...)
+		}
+		return out
+	}
+	if inner := errors.Unwrap(err); inner != nil && strings.HasPrefix(err.Error(), "decoding failed") {
+		return unwrapAll(inner)
+	}
+	return []error{err}
+}
+
+func sortIssues(r *Report) {
+	sort.SliceStable(r.Issues, func(i, j int) bool { return r.Issues[i].Line < r.Issues[j].Line })
+}
+
+func lintGeneral(r *Report, c *model.Config) {
+	if err := validate.General(c.General); err != nil {
+		r.add(SeverityError, "general", "Nilai ini diperiksa saat startup; samakan dengan config/config.yaml bawaan", "%v", err)
+	}
+	g := c.General
+	lintTimezone(r, "general.locale.timezone", g.Locale.Timezone)
+	if !validate.InSlice(g.Locale.Language, []string{"", "en", "id"}) {
+		r.add(SeverityError, "general.locale.language", "Gunakan en, id atau kosongkan", "bahasa tidak didukung: %q", g.Locale.Language)
+	}
+	if g.Prompt.Timeout != "" && g.Prompt.Timeout != "0" {
+		if _, err := time.ParseDuration(g.Prompt.Timeout); err != nil {
+			r.add(SeverityError, "general.prompt.timeout", "Gunakan durasi Go seperti 30s atau 5m, atau 0", "durasi tidak valid: %q", g.Prompt.Timeout)
+		}
+	}
+	if !validate.InSlice(g.Prompt.OnTimeout, []string{"", "default", "abort"}) {
+		r.add(SeverityError, "general.prompt.on_timeout", "Gunakan default atau abort", "nilai tidak valid: %q", g.Prompt.OnTimeout)
+	}
+	if g.TempMinFree != "" && common.ParseSize(g.TempMinFree) <= 0 {
+		r.add(SeverityError, "general.temp_min_free", "Gunakan ukuran seperti 512MB atau 2GB", "ukuran tidak valid: %q", g.TempMinFree)
+	}
+	if g.Download.Retries < 0 {
+		r.add(SeverityError, "general.download.retries", "Gunakan 0 atau lebih", "jumlah retry negatif: %d", g.Download.Retries)
+	}
+	if g.Download.RetryBackoff != "" {
+		if d, err := time.ParseDuration(g.Download.RetryBackoff); err != nil || d <= 0 {
+			r.add(SeverityError, "general.download.retry_backoff", "Gunakan durasi Go seperti 2s", "durasi tidak valid: %q", g.Download.RetryBackoff)
+		}
+	}
+	lintTimezone(r, "maintenance.timezone", c.Maintenance.Timezone)
+}
+
+func lintLog(r *Report, c *model.Config) {
+	l := c.Log
+	if !validate.InSlice(l.Level, validate.LogLevels) {
+		r.add(SeverityError, "log.level", "Gunakan salah satu dari "+strings.Join(validate.LogLevels, ", "), "log.level tidak valid: %q", l.Level)
+	}
+	if !validate.InSlice(l.Format, validate.LogFormats) {
+		r.add(SeverityError, "log.format", "Gunakan text atau json", "log.format tidak valid: %q", l.Format)
+	}
+	lintTimezone(r, "log.timezone", l.Timezone)
+
+	f := l.Output.File
+	if !f.Enabled {
+		return
+	}
+	if f.Dir == "" {
+		r.add(SeverityError, "log.output.file.dir", "Isi direktori log atau set log.output.file.enabled: false", "wajib diisi saat log.output.file.enabled = true")
+	} else {
+		lintDir(r, "log.output.file.dir", f.Dir, true, "Buat direktori dan beri user sfDBTools akses tulis")
+	}
+	if f.FilenamePattern == "" {
+		r.add(SeverityWarning, "log.output.file.filename_pattern", "Contoh: sfDBTools_{date}.log", "pola nama file log kosong")
+	}
+	if f.Rotation.RetentionDays < 1 {
+		r.add(SeverityError, "log.output.file.rotation.retention_days", "Gunakan 1 atau lebih", "harus >= 1, sekarang: %d", f.Rotation.RetentionDays)
+	}
+	if f.Rotation.MaxSize != "" {
+		switch size := common.ParseSize(f.Rotation.MaxSize); {
+		case size <= 0:
+			r.add(SeverityError, "log.output.file.rotation.max_size", "Gunakan ukuran seperti 100MB", "ukuran tidak valid: %q", f.Rotation.MaxSize)
+		case size < 1<<20:
+			r.add(SeverityWarning, "log.output.file.rotation.max_size", "Ukuran di bawah 1MB membuat file log berganti terus-menerus", "ukuran rotasi sangat kecil: %s", f.Rotation.MaxSize)
+		}
+	}
+}
+
+// lintPaths memeriksa file config sendiri, direktori konfigurasi database,
+// direktori backup dan direktori temporary
+func lintPaths(r *Report, c *model.Config, path string) {
+	if info, err := os.Stat(path); err == nil {
+		mode := info.Mode().Perm()
+		switch {
+		case mode&0o002 != 0:
+			r.add(SeverityError, "", fmt.Sprintf("chmod o-w %s", path), "%s bisa ditulis semua user (%04o); hooks dan perintah di dalamnya bisa diganti siapa saja", filepath.Base(path), mode)
+		case mode&0o020 != 0:
+			r.add(SeverityWarning, "", fmt.Sprintf("chmod g-w %s", path), "%s bisa ditulis group (%04o)", filepath.Base(path), mode)
+		}
+		if c.Database.Password != "" && mode&0o004 != 0 {
+			r.add(SeverityWarning, "database.password", fmt.Sprintf("chmod o-r %s, atau simpan password di konfigurasi terenkripsi (dbconfig generate)", path), "password database tersimpan di file yang bisa dibaca semua user (%04o)", mode)
+		}
+	}
+
+	switch dir := c.ConfigDir.DatabaseConfig; {
+	case dir == "":
+		r.add(SeverityError, "config_dir.database_config", "Isi dengan direktori file .cnf.enc, mis. /etc/sfDBTools/config/db_config", "wajib diisi; command database tidak bisa menemukan konfigurasi terenkripsi")
+	default:
+		if lintDir(r, "config_dir.database_config", dir, false, "Buat direktori atau jalankan: sfDBTools dbconfig generate") {
+			if info, err := os.Stat(dir); err == nil && info.Mode().Perm()&0o007 != 0 {
+				r.add(SeverityWarning, "config_dir.database_config", fmt.Sprintf("chmod o-rwx %s", dir), "direktori konfigurasi database bisa diakses semua user (%04o)", info.Mode().Perm())
+			}
+		}
+	}
+
+	if dir := c.Backup.Storage.BaseDirectory; dir == "" {
+		r.add(SeverityWarning, "backup.storage.base_directory", "Isi dengan direktori backup, mis. /mnt/nfs/backup", "tidak diisi; backup ditulis ke ./backup di direktori kerja")
+	} else {
+		lintDir(r, "backup.storage.base_directory", dir, true, "Mount storage backup dan beri user sfDBTools akses tulis")
+	}
+	if dir := c.General.TempDir; dir != "" {
+		lintDir(r, "general.temp_dir", dir, true, "Buat direktori atau kosongkan untuk memakai TMPDIR atau /tmp")
+	}
+}
+
+// lintDir memeriksa bahwa dir adalah direktori (dan bisa ditulis bila
+// writable); direktori yang belum ada hanya warning karena dibuat saat dipakai.
+// Mengembalikan true bila direktori ada.
+func lintDir(r *Report, key, dir string, writable bool, hint string) bool {
+	info, err := os.Stat(dir)
+	switch {
+	case os.IsNotExist(err):
+		r.add(SeverityWarning, key, hint, "direktori %s belum ada", dir)
+		return false
+	case err != nil:
+		r.add(SeverityError, key, hint, "tidak bisa diakses: %v", err)
+		return false
+	case !info.IsDir():
+		r.add(SeverityError, key, hint, "%s bukan direktori", dir)
+		return false
+	}
+	if writable {
+		probe, err := os.CreateTemp(dir, ".sfdb-lint-*")
+		if err != nil {
+			r.add(SeverityError, key, hint, "direktori %s tidak bisa ditulis: %v", dir, err)
+			return true
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+	}
+	return true
+}
+
+func lintBackup(r *Report, c *model.Config) {
+	b := c.Backup
+	if !validate.InSlice(b.Engine, []string{"", "mysqldump", "mydumper"}) {
+		r.add(SeverityError, "backup.engine", "Gunakan mysqldump atau mydumper", "engine tidak didukung: %q", b.Engine)
+	}
+	if b.Mydumper.Threads < 0 || b.Mydumper.Rows < 0 {
+		r.add(SeverityError, "backup.mydumper", "Gunakan 0 untuk default", "threads dan rows tidak boleh negatif")
+	}
+	if b.Compression.Algorithm != "" {
+		if _, err := compression.ValidateCompressionType(b.Compression.Algorithm); err != nil {
+			r.add(SeverityError, "backup.compression.algorithm", "Gunakan none, gzip, pgzip, pigz, zlib atau zstd", "%v", err)
+		}
+	}
+	if b.Compression.Level != "" {
+		if _, err := compression.ValidateCompressionLevel(b.Compression.Level); err != nil {
+			r.add(SeverityError, "backup.compression.level", "Gunakan best_speed, fast, default, better, best atau angka 1-9 (1-22 untuk zstd)", "%v", err)
+		}
+	}
+	if a := b.Compression.Auto; a.Enabled {
+		if a.TightHeadroom < 1 {
+			r.add(SeverityError, "backup.compression.auto.tight_headroom", "Gunakan nilai >= 1, mis. 1.5", "harus >= 1, sekarang: %g", a.TightHeadroom)
+		}
+		if a.AbundantHeadroom <= a.TightHeadroom {
+			r.add(SeverityError, "backup.compression.auto.abundant_headroom", "Gunakan nilai lebih besar dari tight_headroom, mis. 5", "harus lebih besar dari tight_headroom (%g), sekarang: %g", a.TightHeadroom, a.AbundantHeadroom)
+		}
+	}
+	if b.Retention.Days < 0 {
+		r.add(SeverityError, "backup.retention.days", "Gunakan 0 atau lebih", "tidak boleh negatif: %d", b.Retention.Days)
+	}
+	if b.Compression.Required && b.Compression.Algorithm == "none" {
+		r.add(SeverityWarning, "backup.compression.required", "Pilih algoritma selain none atau set required: false", "kompresi diwajibkan tetapi algoritmanya none")
+	}
+	if s := b.Verification.MinimumFreeSpace; s != "" && common.ParseSize(s) <= 0 {
+		r.add(SeverityError, "backup.verification.minimum_free_space", "Gunakan ukuran seperti 10GB", "ukuran tidak valid: %q", s)
+	}
+	for key, value := range map[string]string{"backup.drill.max_age": b.Drill.MaxAge, "backup.drill.window": b.Drill.Window} {
+		if _, err := common.ParseDurationWithDays(value); err != nil {
+			r.add(SeverityError, key, "Gunakan durasi seperti 7d atau 36h", "%v", err)
+		}
+	}
+	if p := b.Remote.Provider; p != "" && p != "s3" {
+		r.add(SeverityError, "backup.remote.provider", "Hanya s3 yang didukung", "provider tidak didukung: %q", p)
+	}
+
+	names := map[string]bool{}
+	commands := []string{"all", "selection", "group", "user", "snapshot", "physical", "drill"}
+	for i, s := range b.Schedules {
+		key := fmt.Sprintf("backup.schedules[%d]", i)
+		if s.Name == "" {
+			r.add(SeverityError, key+".name", "Beri nama unik, mis. nightly-all", "nama jadwal kosong")
+		} else if names[s.Name] {
+			r.add(SeverityError, key+".name", "Nama jadwal dipakai sebagai nama unit systemd dan harus unik", "nama jadwal %q dipakai lebih dari sekali", s.Name)
+		}
+		names[s.Name] = true
+		if !validate.InSlice(s.Command, commands) {
+			r.add(SeverityError, key+".command", "Gunakan salah satu dari "+strings.Join(commands, ", "), "command tidak dikenal: %q", s.Command)
+		}
+		if s.OnCalendar == "" {
+			r.add(SeverityError, key+".on_calendar", "Gunakan format systemd, mis. daily atau '*-*-* 02:00:00'", "on_calendar kosong")
+		}
+		if s.RandomizedDelay != "" {
+			if _, err := common.ParseDurationWithDays(s.RandomizedDelay); err != nil {
+				r.add(SeverityError, key+".randomized_delay", "Gunakan durasi seperti 10m", "%v", err)
+			}
+		}
+		lintTimezone(r, key+".timezone", s.Timezone)
+	}
+}
+
+func lintTimezone(r *Report, key, tz string) {
+	if tz == "" {
+		return
+	}
+	if err := validate.IsValidTimezone(tz); err != nil {
+		r.add(SeverityError, key, "Gunakan nama IANA seperti Asia/Jakarta dan pastikan tzdata terinstall", "timezone tidak dikenal: %q", tz)
+	}
+}
@@ -8,6 +8,15 @@ import (
 	"github.com/spf13/viper"
 )
 
+// configDir holds the directory config.yaml was loaded from
+var configDir string
+
+// Dir returns the directory the active config.yaml was loaded from,
+// or an empty string when no configuration has been loaded yet.
+func Dir() string {
+	return configDir
+}
+
 // fileExists checks if a file exists
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
@@ -44,6 +53,8 @@ func loadViper() (*viper.Viper, error) {
 		return nil, fmt.Errorf("file konfigurasi tidak ditemukan di %s, %s atau %s", cwdConfigDir, appConfigDir, systemConfigDir)
 	}
 
+	configDir = chosenConfigDir
+
 	v := viper.New()
 
 	v.SetConfigName("config")
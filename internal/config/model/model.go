@@ -1,14 +1,174 @@
 package model
 
 type Config struct {
-	General     GeneralConfig   `mapstructure:"general"`
-	Log         LogConfig       `mapstructure:"log"`
-	Mysqldump   MysqldumpConfig `mapstructure:"mysqldump"`
-	Database    DatabaseConfig  `mapstructure:"database"`
-	Backup      BackupConfig    `mapstructure:"backup"`
-	SystemUsers SystemUsers     `mapstructure:"system_users"`
-	ConfigDir   ConfigDirConfig `mapstructure:"config_dir"`
-	MariaDB     MariaDBConfig   `mapstructure:"mariadb"`
+	General     GeneralConfig     `mapstructure:"general"`
+	Log         LogConfig         `mapstructure:"log"`
+	Mysqldump   MysqldumpConfig   `mapstructure:"mysqldump"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Backup      BackupConfig      `mapstructure:"backup"`
+	SystemUsers SystemUsers       `mapstructure:"system_users"`
+	ConfigDir   ConfigDirConfig   `mapstructure:"config_dir"`
+	MariaDB     MariaDBConfig     `mapstructure:"mariadb"`
+	MaxScale    MaxScaleConfig    `mapstructure:"maxscale"`
+	FilePolicy  FilePolicyConfig  `mapstructure:"file_policy"`
+	Maintenance MaintenanceConfig `mapstructure:"maintenance"`
+	Hooks       HooksConfig       `mapstructure:"hooks"`
+	Notify      NotifyConfig      `mapstructure:"notification"`
+	Quota       QuotaConfig       `mapstructure:"quota"`
+	Postgres    PostgresConfig    `mapstructure:"postgres"`
+	Secrets     SecretsConfig     `mapstructure:"secrets"`
+}
+
+// SecretsConfig configures the stores behind vault://, awssm:// and
+// envfile:// credential references. CacheTTL bounds how long a secret without
+// lease is reused (Go duration, default 5m); EnvFile is read by envfile://#KEY.
+type SecretsConfig struct {
+	CacheTTL string           `mapstructure:"cache_ttl"`
+	EnvFile  string           `mapstructure:"env_file"`
+	Vault    VaultConfig      `mapstructure:"vault"`
+	AWS      AWSSecretsConfig `mapstructure:"aws"`
+}
+
+// VaultConfig locates HashiCorp Vault. VAULT_ADDR, VAULT_NAMESPACE and
+// VAULT_TOKEN take precedence; without a token, TokenFile (default
+// ~/.vault-token) is read. RenewIncrement is requested when renewing leases.
+type VaultConfig struct {
+	Address        string `mapstructure:"address"`
+	Namespace      string `mapstructure:"namespace"`
+	TokenFile      string `mapstructure:"token_file"`
+	RenewIncrement string `mapstructure:"renew_increment"`
+}
+
+// AWSSecretsConfig selects the region and profile for AWS Secrets Manager;
+// empty values leave them to the aws CLI configuration
+type AWSSecretsConfig struct {
+	Region  string `mapstructure:"region"`
+	Profile string `mapstructure:"profile"`
+}
+
+// HooksConfig runs commands or HTTP calls before (Pre) and after (Post)
+// backups, restores, clones and migrations, e.g. to switch an application to
+// maintenance mode or to page someone. Timeout bounds each hook that sets no
+// timeout of its own (Go duration, default 5m).
+type HooksConfig struct {
+	Timeout string       `mapstructure:"timeout"`
+	Pre     []HookConfig `mapstructure:"pre"`
+	Post    []HookConfig `mapstructure:"post"`
+}
+
+// HookConfig is one hook. Operations limits it to operations by name or
+// prefix ("restore" matches restore-single and restore-all); empty means
+// every operation. Command is run through sh with the operation in
+// SFDB_HOOK_* and SFDB_OPERATION_* environment variables; URL instead
+// receives the same data as a JSON request with Method (default POST) and
+// Headers. OnFailure is "abort" or "warn": a failed pre hook aborts the
+// operation by default, a failed post hook only warns by default and fails
+// an otherwise successful operation with "abort". When limits a post hook to
+// "success" or "failure"; empty means always.
+type HookConfig struct {
+	Name       string            `mapstructure:"name"`
+	Operations []string          `mapstructure:"operations"`
+	Command    string            `mapstructure:"command"`
+	URL        string            `mapstructure:"url"`
+	Method     string            `mapstructure:"method"`
+	Headers    map[string]string `mapstructure:"headers"`
+	OnFailure  string            `mapstructure:"on_failure"`
+	When       string            `mapstructure:"when"`
+	Timeout    string            `mapstructure:"timeout"`
+}
+
+// NotifyConfig routes alerts raised by monitors and, with Operations, the
+// outcome of backups, restores and migrations. Alerts are always written to
+// the log; WebhookURL additionally receives them as a JSON POST, Slack and
+// Email as a message, and Command is run through sh with the alert in
+// SFDB_ALERT_* environment variables. Timeout bounds each delivery (Go
+// duration, default 10s).
+type NotifyConfig struct {
+	WebhookURL string                 `mapstructure:"webhook_url"`
+	Command    string                 `mapstructure:"command"`
+	Timeout    string                 `mapstructure:"timeout"`
+	Slack      SlackNotifyConfig      `mapstructure:"slack"`
+	Email      EmailNotifyConfig      `mapstructure:"email"`
+	Operations OperationsNotifyConfig `mapstructure:"operations"`
+}
+
+// SlackNotifyConfig posts alerts to a Slack incoming webhook. Channel and
+// Username override the webhook's defaults when set.
+type SlackNotifyConfig struct {
+	WebhookURL string `mapstructure:"webhook_url"`
+	Channel    string `mapstructure:"channel"`
+	Username   string `mapstructure:"username"`
+}
+
+// EmailNotifyConfig mails alerts through an SMTP server. TLS is "starttls"
+// (default: upgrade when the server offers it), "tls" (implicit TLS, usually
+// port 465) or "none". Password accepts a secret reference (vault://,
+// awssm://, envfile://); without Username no authentication is attempted.
+type EmailNotifyConfig struct {
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+	TLS      string   `mapstructure:"tls"`
+}
+
+// OperationsNotifyConfig selects which operation results are notified. On is
+// "never" (default), "failure" or "always". Operations lists operation names
+// or prefixes ("backup" matches backup-single and backup-all); empty means
+// backups, restores, clones and migrations.
+type OperationsNotifyConfig struct {
+	On         string   `mapstructure:"on"`
+	Operations []string `mapstructure:"operations"`
+}
+
+// QuotaConfig sets size limits per database for 'database quota'. WarnAt are
+// percentages of MaxSize that raise a warning. With Enforce, INSERT is revoked
+// from the AppUsers of a database above its hard limit and granted back once
+// it is below again. Alerts and enforcement actions are appended to AuditLog;
+// StateFile remembers alert levels and revoked grants between runs.
+type QuotaConfig struct {
+	Interval  string          `mapstructure:"interval"`
+	WarnAt    []float64       `mapstructure:"warn_at"`
+	Enforce   bool            `mapstructure:"enforce"`
+	AuditLog  string          `mapstructure:"audit_log"`
+	StateFile string          `mapstructure:"state_file"`
+	Databases []DatabaseQuota `mapstructure:"databases"`
+}
+
+// DatabaseQuota limits the databases matching Name, a glob such as
+// "dbsf_*_temp". Sizes accept units ("50GB"); HardLimit defaults to MaxSize.
+// AppUsers are accounts in 'user'@'host' form.
+type DatabaseQuota struct {
+	Name      string   `mapstructure:"name"`
+	MaxSize   string   `mapstructure:"max_size"`
+	HardLimit string   `mapstructure:"hard_limit"`
+	AppUsers  []string `mapstructure:"app_users"`
+}
+
+// MaintenanceConfig limits heavy operations (backups, restores, clones) to
+// maintenance windows; without windows they may run at any time. Times are
+// evaluated in Timezone, or general.locale.timezone when Timezone is empty.
+// OutsideWindow is "refuse" (default) or "defer" (wait up to MaxDefer for the
+// next window). OnWindowEnd is "continue" (default), "pause" (suspend the dump
+// or restore tools until the next window) or "abort"; EndWarning is how long
+// before the window closes a running job is warned.
+type MaintenanceConfig struct {
+	Timezone      string              `mapstructure:"timezone"`
+	Windows       []MaintenanceWindow `mapstructure:"windows"`
+	OutsideWindow string              `mapstructure:"outside_window"`
+	MaxDefer      string              `mapstructure:"max_defer"`
+	OnWindowEnd   string              `mapstructure:"on_window_end"`
+	EndWarning    string              `mapstructure:"end_warning"`
+}
+
+// MaintenanceWindow is a daily time range on the given weekdays ("mon",
+// "sat-sun"; empty means every day). End at or before Start spans midnight.
+type MaintenanceWindow struct {
+	Days  []string `mapstructure:"days"`
+	Start string   `mapstructure:"start"`
+	End   string   `mapstructure:"end"`
 }
 
 type GeneralConfig struct {
@@ -17,12 +177,52 @@ type GeneralConfig struct {
 	Version    string       `mapstructure:"version"`
 	Author     string       `mapstructure:"author"`
 	Locale     LocaleConfig `mapstructure:"locale"`
+	Prompt     PromptConfig `mapstructure:"prompt"`
+	StatusDir  string       `mapstructure:"status_dir"`
+	// TranscriptDir, when set, receives a plain-text copy of the terminal
+	// output of every command (see --transcript)
+	TranscriptDir string `mapstructure:"transcript_dir"`
+	// TempDir replaces the system temp dir (often a small tmpfs) for
+	// downloads, intermediate dump files and restore state (see --temp-dir).
+	// TempMinFree is the free space heavy operations require there, e.g. "2GB".
+	TempDir     string `mapstructure:"temp_dir"`
+	TempMinFree string `mapstructure:"temp_min_free"`
+	// Download controls retries of the repository setup script and of
+	// backups fetched from remote storage
+	Download DownloadConfig `mapstructure:"download"`
+}
+
+// DownloadConfig sets how often a failed download is retried and the wait
+// before the first retry (Go duration, default 2s), which doubles up to a
+// minute. Every retry resumes where the previous attempt stopped.
+type DownloadConfig struct {
+	Retries      int    `mapstructure:"retries"`
+	RetryBackoff string `mapstructure:"retry_backoff"`
+}
+
+// PromptConfig controls interactive prompts. Timeout is a Go duration ("30s", "5m");
+// empty or "0" waits forever. OnTimeout is "default" (use the prompt's default) or "abort".
+type PromptConfig struct {
+	Timeout   string `mapstructure:"timeout"`
+	OnTimeout string `mapstructure:"on_timeout"`
+}
+
+// FilePolicyConfig sets permissions and ownership for files sfDBTools creates
+// (backups, manifests, logs, temp files). Modes are octal strings such as "0640";
+// owner and group accept names or numeric IDs and are left unchanged when empty.
+type FilePolicyConfig struct {
+	FileMode string `mapstructure:"file_mode"`
+	DirMode  string `mapstructure:"dir_mode"`
+	Owner    string `mapstructure:"owner"`
+	Group    string `mapstructure:"group"`
 }
 
 type LocaleConfig struct {
 	Timezone   string `mapstructure:"timezone"`
 	DateFormat string `mapstructure:"date_format"`
 	TimeFormat string `mapstructure:"time_format"`
+	// Language of prompts and messages (en or id); empty follows LANG
+	Language string `mapstructure:"language"`
 }
 
 type LogConfig struct {
@@ -76,11 +276,61 @@ type DatabaseConfig struct {
 
 type BackupConfig struct {
 	MysqldumpArgs string             `mapstructure:"mysqldump_args"`
+	Engine        string             `mapstructure:"engine"`
+	Mydumper      BackupMydumper     `mapstructure:"mydumper"`
 	Retention     BackupRetention    `mapstructure:"retention"`
 	Compression   BackupCompression  `mapstructure:"compression"`
 	Security      BackupSecurity     `mapstructure:"security"`
 	Storage       BackupStorage      `mapstructure:"storage"`
 	Verification  BackupVerification `mapstructure:"verification"`
+	Schedules     []BackupSchedule   `mapstructure:"schedules"`
+	Drill         BackupDrill        `mapstructure:"drill"`
+	Remote        BackupRemote       `mapstructure:"remote"`
+	Catalog       BackupCatalog      `mapstructure:"catalog"`
+	// ConsistencyGroups name sets of databases that must be backed up in one snapshot
+	ConsistencyGroups []BackupConsistencyGroup `mapstructure:"consistency_groups"`
+}
+
+// BackupConsistencyGroup is a set of databases of one application (e.g. main,
+// dmart and temp) that is dumped from a single snapshot
+type BackupConsistencyGroup struct {
+	Name      string   `mapstructure:"name"`
+	Databases []string `mapstructure:"databases"`
+}
+
+// BackupDrill configures restore drills. Durations accept a day suffix (e.g. "7d").
+// BackupMydumper tunes the mydumper engine (backup.engine: mydumper). Threads
+// defaults to the number of CPUs; Rows splits tables into chunks of that many
+// rows so large tables are dumped by several threads.
+type BackupMydumper struct {
+	Threads int `mapstructure:"threads"`
+	Rows    int `mapstructure:"rows"`
+}
+
+type BackupDrill struct {
+	MaxAge        string `mapstructure:"max_age"`
+	Window        string `mapstructure:"window"`
+	Catalog       string `mapstructure:"catalog"`
+	ScratchPrefix string `mapstructure:"scratch_prefix"`
+}
+
+// BackupRemote describes the object storage backups are copied to. Objects are
+// keyed by Prefix plus the path of the artifact below the backup directory.
+// Access goes through the aws CLI, so credentials come from Profile or the
+// usual AWS environment; EndpointURL selects an S3-compatible service.
+type BackupRemote struct {
+	Provider    string `mapstructure:"provider"`
+	Bucket      string `mapstructure:"bucket"`
+	Prefix      string `mapstructure:"prefix"`
+	Region      string `mapstructure:"region"`
+	EndpointURL string `mapstructure:"endpoint_url"`
+	Profile     string `mapstructure:"profile"`
+}
+
+// BackupCatalog locates the backup catalog, the inventory every backup is
+// recorded in. Path defaults to catalog.db in backup.storage.base_directory.
+type BackupCatalog struct {
+	Path string `mapstructure:"path"`
 }
 
 type BackupRetention struct {
@@ -90,9 +340,20 @@ type BackupRetention struct {
 }
 
 type BackupCompression struct {
-	Required  bool   `mapstructure:"required"`
-	Algorithm string `mapstructure:"algorithm"`
-	Level     string `mapstructure:"level"`
+	Required  bool                  `mapstructure:"required"`
+	Algorithm string                `mapstructure:"algorithm"`
+	Level     string                `mapstructure:"level"`
+	Auto      BackupCompressionAuto `mapstructure:"auto"`
+}
+
+// BackupCompressionAuto lets the backup pick the codec and level from the
+// estimated output size and the free space of the output directory. Free
+// space below TightHeadroom times the estimate escalates compression; above
+// AbundantHeadroom times the estimate the fastest setting is used instead.
+type BackupCompressionAuto struct {
+	Enabled          bool    `mapstructure:"enabled"`
+	TightHeadroom    float64 `mapstructure:"tight_headroom"`
+	AbundantHeadroom float64 `mapstructure:"abundant_headroom"`
 }
 
 type BackupSecurity struct {
@@ -125,6 +386,34 @@ type BackupVerification struct {
 	MinimumFreeSpace string `mapstructure:"minimum_free_space"`
 	VerifyAfterWrite bool   `mapstructure:"verify_after_write"`
 	CompareChecksums bool   `mapstructure:"compare_checksums"`
+	// Sandbox is the server 'backup verify --deep' restores into. When Host is
+	// empty a throwaway mariadbd instance is started instead.
+	Sandbox BackupVerifySandbox `mapstructure:"sandbox"`
+}
+
+// BackupVerifySandbox is a disposable server for test restores. WorkDir holds
+// the data directory of throwaway instances (default: the temp directory).
+type BackupVerifySandbox struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
+	WorkDir  string `mapstructure:"work_dir"`
+}
+
+// BackupSchedule describes a recurring backup run. Command is a backup mode
+// (all, selection, group, user, snapshot, physical) or "drill" for a restore drill. OnCalendar uses the
+// systemd calendar event syntax (e.g. "daily", "*-*-* 02:00:00") and is
+// evaluated in Timezone, or general.locale.timezone when Timezone is empty.
+type BackupSchedule struct {
+	Name            string   `mapstructure:"name"`
+	Enabled         bool     `mapstructure:"enabled"`
+	Command         string   `mapstructure:"command"`
+	Args            []string `mapstructure:"args"`
+	OnCalendar      string   `mapstructure:"on_calendar"`
+	RandomizedDelay string   `mapstructure:"randomized_delay"`
+	Persistent      bool     `mapstructure:"persistent"`
+	Timezone        string   `mapstructure:"timezone"`
 }
 
 type SystemUsers struct {
@@ -134,6 +423,7 @@ type SystemUsers struct {
 type ConfigDirConfig struct {
 	DatabaseConfig        string `mapstructure:"database_config"`
 	MariaDBConfigTemplate string `mapstructure:"mariadb_config_templates"`
+	MariaDBProvisioning   string `mapstructure:"mariadb_provisioning"`
 	MariaDBKey            string `mapstructure:"mariadb_key"`
 	DatabaseList          string `mapstructure:"database_list"`
 }
@@ -149,3 +439,32 @@ type MariaDBConfig struct {
 	ConfigDir           string `mapstructure:"config_dir"`
 	ServerID            int    `mapstructure:"server_id"`
 }
+
+// PostgresConfig holds defaults for the postgres commands. Empty connection
+// fields fall back to the libpq environment (PGHOST, PGPORT, PGUSER,
+// PGPASSWORD) and its defaults. BackupDir defaults to
+// <backup.storage.base_directory>/postgres.
+type PostgresConfig struct {
+	Version     string `mapstructure:"version"`
+	Host        string `mapstructure:"host"`
+	Port        int    `mapstructure:"port"`
+	User        string `mapstructure:"user"`
+	ServiceName string `mapstructure:"service_name"`
+	BackupDir   string `mapstructure:"backup_dir"`
+	Jobs        int    `mapstructure:"jobs"`
+}
+
+type MaxScaleConfig struct {
+	ConfigFile      string   `mapstructure:"config_file"`
+	APIHost         string   `mapstructure:"api_host"`
+	APIPort         int      `mapstructure:"api_port"`
+	APIUser         string   `mapstructure:"api_user"`
+	APIPassword     string   `mapstructure:"api_password"`
+	MonitorUser     string   `mapstructure:"monitor_user"`
+	MonitorPassword string   `mapstructure:"monitor_password"`
+	ListenPort      int      `mapstructure:"listen_port"`
+	Servers         []string `mapstructure:"servers"`
+	// Version is the MaxScale release series installed by 'maxscale install'
+	// (e.g. "24.02"); empty installs the latest
+	Version string `mapstructure:"version"`
+}
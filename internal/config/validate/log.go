@@ -6,15 +6,16 @@ import (
 	"sfDBTools/internal/config/model"
 )
 
-var validLogLevels = []string{"trace", "debug", "info", "warn", "error", "fatal"}
-var validLogFormats = []string{"text", "json"}
+// LogLevels dan LogFormats adalah nilai log.level dan log.format yang diterima
+var LogLevels = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+var LogFormats = []string{"text", "json"}
 
 func Log(l model.LogConfig) error {
-	if !InSlice(l.Level, validLogLevels) {
+	if !InSlice(l.Level, LogLevels) {
 		return fmt.Errorf("log.level tidak valid: '%s'", l.Level)
 	}
 
-	if !InSlice(l.Format, validLogFormats) {
+	if !InSlice(l.Format, LogFormats) {
 		return fmt.Errorf("log.format tidak valid: '%s'", l.Format)
 	}
 
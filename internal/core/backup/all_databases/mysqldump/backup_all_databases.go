@@ -1,9 +1,10 @@
 package mysqldump
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"sfDBTools/internal/config"
@@ -11,10 +12,22 @@ import (
 	backup_utils "sfDBTools/utils/backup"
 	"sfDBTools/utils/database"
 	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
 )
 
 // BackupAllDatabases performs a backup of all databases into a single file
-func BackupAllDatabases(options backup_utils.AllDatabasesBackupOptions, availableDatabases []string) (*backup_utils.AllDatabasesBackupResult, error) {
+func BackupAllDatabases(ctx context.Context, options backup_utils.AllDatabasesBackupOptions, availableDatabases []string) (*backup_utils.AllDatabasesBackupResult, error) {
+	op, target := "backup-all", options.Host
+	if options.GroupName != "" {
+		op, target = "backup-group", options.GroupName
+	}
+	job := jobstatus.Start(op, target)
+	result, err := backupAllDatabases(ctx, options, availableDatabases, job)
+	job.Finish(err)
+	return result, err
+}
+
+func backupAllDatabases(ctx context.Context, options backup_utils.AllDatabasesBackupOptions, availableDatabases []string, job *jobstatus.Tracker) (*backup_utils.AllDatabasesBackupResult, error) {
 	lg, err := logger.Get()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get logger: %w", err)
@@ -29,7 +42,7 @@ func BackupAllDatabases(options backup_utils.AllDatabasesBackupOptions, availabl
 	// Validate output directory
 	manager := fs.NewManager()
 	if !manager.Dir().Exists(options.OutputDir) {
-		if err := manager.Dir().Create(options.OutputDir); err != nil {
+		if err := manager.Dir().CreateArtifactDir(options.OutputDir); err != nil {
 			lg.Error("Failed to create output directory", logger.Error(err))
 			return nil, fmt.Errorf("failed to create output directory: %w", err)
 		}
@@ -87,10 +100,11 @@ func BackupAllDatabases(options backup_utils.AllDatabasesBackupOptions, availabl
 		defer database.CleanupMaxStatementTimeManager(timeManager)
 	}
 
+	job.SetStep("collecting replication info")
 	// Collect replication information ONCE (no duplicate database list call)
 	replicationInfo, err := backup_utils.GetReplicationInfoForBackup(dbConfig)
 	if err != nil {
-		lg.Warn("Failed to collect replication information", logger.Error(err))
+		job.Warn(fmt.Sprintf("Failed to collect replication information: %v", err))
 	} else if replicationInfo != nil {
 		lg.Info("Replication information collected successfully")
 		// Extract GTID position if available
@@ -108,30 +122,57 @@ func BackupAllDatabases(options backup_utils.AllDatabasesBackupOptions, availabl
 
 	result.TotalDatabases = len(databases)
 
+	// A consistency group must come from one snapshot; --single-transaction
+	// only covers transactional tables, so fall back to a global read lock
+	result.SnapshotMode = "single_transaction"
+	if options.GroupName != "" {
+		tables, err := backup_utils.NonTransactionalTables(dbConfig, databases)
+		if err != nil {
+			lg.Warn("Failed to check storage engines of group members", logger.Error(err))
+		} else if len(tables) > 0 {
+			job.Warn(fmt.Sprintf("Consistency group %s contains non-transactional tables (%s), dumping under a global read lock",
+				options.GroupName, strings.Join(tables, ", ")))
+			options.LockAllTables = true
+			result.SnapshotMode = "lock_all_tables"
+		}
+	}
+
 	// Generate output paths
 	outputFile, metaFile := backup_utils.GenerateAllDatabasesOutputPaths(options)
 	result.BackupResult.OutputFile = outputFile
 	result.BackupResult.BackupMetaFile = metaFile
 
 	// Perform the backup
-	processedDatabases, skippedDatabases, err := performAllDatabasesBackup(options, outputFile, databases)
+	job.SetStep("dumping")
+	bookmark := backup_utils.NewBookmarkScanner()
+	processedDatabases, skippedDatabases, err := performAllDatabasesBackup(ctx, options, outputFile, databases, bookmark, job)
 	if err != nil {
+		backup_utils.RemovePartialOutput(outputFile)
 		result.BackupResult.Error = err
 		return result, err
 	}
+	if result.Bookmark = bookmark.Bookmark(); result.Bookmark != nil {
+		backup_utils.ResolveBookmarkGTID(dbConfig, result.Bookmark)
+	}
 
 	result.ProcessedDatabases = processedDatabases
 	result.SkippedDatabases = skippedDatabases
 
 	// Finalize backup result
+	job.SetStep("finalizing")
 	if err := backup_utils.FinalizeBackupResult(&result.BackupResult, outputFile, startTime, options.BackupOptions); err != nil {
 		lg.Warn("Failed to finalize backup result", logger.Error(err))
 	}
+	backup_utils.ReportResultDetails(job, &result.BackupResult)
+	job.Detail("Databases", fmt.Sprintf("%d backed up, %d skipped", len(processedDatabases), len(skippedDatabases)))
 
 	// Create metadata ONCE using already collected replication info
 	metadata := backup_utils.CreateAllDatabasesMetadata(options, result, dbConfig, replicationInfo)
 	if err := saveAllDatabasesMetadata(metaFile, metadata); err != nil {
 		lg.Warn("Failed to save all databases metadata", logger.Error(err))
+	} else {
+		backup_utils.RecordInCatalog(metaFile, metadata, options.RetentionDays)
+		backup_utils.PruneAfterBackup()
 	}
 
 	result.BackupResult.Success = true
@@ -146,16 +187,16 @@ func BackupAllDatabases(options backup_utils.AllDatabasesBackupOptions, availabl
 }
 
 // performAllDatabasesBackup performs the actual backup operation for all databases
-func performAllDatabasesBackup(options backup_utils.AllDatabasesBackupOptions, outputFile string, databases []string) ([]string, []string, error) {
+func performAllDatabasesBackup(ctx context.Context, options backup_utils.AllDatabasesBackupOptions, outputFile string, databases []string, bookmark *backup_utils.BookmarkScanner, job *jobstatus.Tracker) ([]string, []string, error) {
 	lg, _ := logger.Get()
 
 	// Create output directory
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+	if err := fs.NewManager().File().EnsureArtifactDir(filepath.Dir(outputFile)); err != nil {
 		return nil, nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Execute mysqldump for all databases
-	processedDatabases, skippedDatabases, err := executeAllDatabasesMysqldump(options, outputFile, databases)
+	processedDatabases, skippedDatabases, err := executeAllDatabasesMysqldump(ctx, options, outputFile, databases, bookmark, job)
 	if err != nil {
 		lg.Error("mysqldump execution failed", logger.Error(err))
 		return processedDatabases, skippedDatabases, fmt.Errorf("mysqldump failed: %w", err)
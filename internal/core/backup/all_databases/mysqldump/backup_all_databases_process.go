@@ -1,11 +1,10 @@
 package mysqldump
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"time"
 
@@ -15,10 +14,13 @@ import (
 	backup_utils "sfDBTools/utils/backup"
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/database"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/shutdown"
 )
 
 // executeAllDatabasesMysqldump executes mysqldump for all databases and writes to a single file
-func executeAllDatabasesMysqldump(options backup_utils.AllDatabasesBackupOptions, outputFile string, databases []string) ([]string, []string, error) {
+func executeAllDatabasesMysqldump(ctx context.Context, options backup_utils.AllDatabasesBackupOptions, outputFile string, databases []string, bookmark *backup_utils.BookmarkScanner, job *jobstatus.Tracker) ([]string, []string, error) {
 	lg, _ := logger.Get()
 
 	// Validate backup options
@@ -28,16 +30,17 @@ func executeAllDatabasesMysqldump(options backup_utils.AllDatabasesBackupOptions
 	}
 
 	// Create output directory
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+	fsMgr := fs.NewManager()
+	if err := fsMgr.File().EnsureArtifactDir(filepath.Dir(outputFile)); err != nil {
 		return nil, nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Always use single mysqldump command for replication consistency
-	return executeAllDatabasesWithSingleCommand(options, outputFile, databases)
+	return executeAllDatabasesWithSingleCommand(ctx, options, outputFile, databases, bookmark, job)
 }
 
 // executeAllDatabasesWithSingleCommand executes a single mysqldump command for all databases (for replication consistency)
-func executeAllDatabasesWithSingleCommand(options backup_utils.AllDatabasesBackupOptions, outputFile string, databases []string) ([]string, []string, error) {
+func executeAllDatabasesWithSingleCommand(ctx context.Context, options backup_utils.AllDatabasesBackupOptions, outputFile string, databases []string, bookmark *backup_utils.BookmarkScanner, job *jobstatus.Tracker) ([]string, []string, error) {
 	lg, _ := logger.Get()
 
 	lg.Info("Using single mysqldump command for replication consistency",
@@ -45,27 +48,20 @@ func executeAllDatabasesWithSingleCommand(options backup_utils.AllDatabasesBacku
 		logger.Bool("capture_gtid", options.CaptureGTID))
 
 	// Create output file
-	outFile, err := os.Create(outputFile)
+	outFile, err := fs.NewManager().File().CreateFile(outputFile)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create output file: %w", err)
 	}
 	defer outFile.Close()
 
-	// Set up writer chain: compression -> encryption -> file
-	var writer io.WriteCloser
-	var closers []io.Closer
-
-	writer, closers, err = backup_utils.BuildWriterChain(outFile, options.BackupOptions, lg)
+	// Set up the write path: dump -> bookmark scan -> compression -> encryption -> file
+	stream, err := backup_utils.OpenBackupStream(outFile, options.BackupOptions, lg, bookmark.Stage())
 	if err != nil {
-		lg.Error("Failed to set up writer chain", logger.Error(err))
 		return nil, nil, err
 	}
 	defer func() {
-		// Close writers in reverse order (inner to outer)
-		for i := len(closers) - 1; i >= 0; i-- {
-			if err := closers[i].Close(); err != nil {
-				lg.Warn("Failed to close writer", logger.Error(err))
-			}
+		if err := stream.Close(); err != nil {
+			lg.Warn("Failed to close writer", logger.Error(err))
 		}
 	}()
 
@@ -78,8 +74,8 @@ func executeAllDatabasesWithSingleCommand(options backup_utils.AllDatabasesBacku
 		logger.Int("port", options.Port))
 
 	// Execute mysqldump command
-	cmd := exec.Command("mysqldump", args...)
-	cmd.Stdout = writer
+	cmd := shutdown.Command(ctx, "mysqldump", args...)
+	cmd.Stdout = job.Writer(stream, 0)
 	cmd.Stderr = os.Stderr
 
 	// Set environment variable for password
@@ -102,7 +98,7 @@ func executeAllDatabasesWithSingleCommand(options backup_utils.AllDatabasesBacku
 
 	// Handle user grants backup if requested - save to separate file
 	if options.IncludeUser {
-		if err := createSeparateUserGrantsBackup(options); err != nil {
+		if err := createSeparateUserGrantsBackup(ctx, options); err != nil {
 			lg.Error("Failed to create separate user grants backup", logger.Error(err))
 			// Don't fail the entire backup, just log the error
 		}
@@ -112,7 +108,7 @@ func executeAllDatabasesWithSingleCommand(options backup_utils.AllDatabasesBacku
 }
 
 // createSeparateUserGrantsBackup creates user grants backup in separate file
-func createSeparateUserGrantsBackup(options backup_utils.AllDatabasesBackupOptions) error {
+func createSeparateUserGrantsBackup(ctx context.Context, options backup_utils.AllDatabasesBackupOptions) error {
 	lg, _ := logger.Get()
 
 	// Convert AllDatabasesBackupOptions to BackupOptions
@@ -132,7 +128,7 @@ func createSeparateUserGrantsBackup(options backup_utils.AllDatabasesBackupOptio
 	}
 
 	// Call the BackupUserGrants function from the separate package
-	result, err := user_grants_backup.BackupUserGrants(backupOptions)
+	result, err := user_grants_backup.BackupUserGrants(ctx, backupOptions)
 	if err != nil {
 		return fmt.Errorf("failed to create separate user grants backup: %w", err)
 	}
@@ -161,7 +157,12 @@ func getReplicationMysqldumpArgs(options backup_utils.AllDatabasesBackupOptions,
 	}
 
 	// Essential replication flags
-	args = append(args, "--single-transaction") // Ensures consistency
+	if options.LockAllTables {
+		// Non-transactional tables are only consistent under a global read lock
+		args = append(args, "--lock-all-tables")
+	} else {
+		args = append(args, "--single-transaction") // Ensures consistency
+	}
 
 	// Check if binary logging is enabled before adding --master-data flags
 	dbConfig := database.Config{
@@ -194,13 +195,16 @@ func getReplicationMysqldumpArgs(options backup_utils.AllDatabasesBackupOptions,
 		configArgs := common.ParseArgsString(cfg.Mysqldump.Args)
 		for _, arg := range configArgs {
 			// Skip flags that we're handling explicitly or that conflict
-			if arg != "--master-data" && arg != "--single-transaction" &&
+			if arg != "--master-data" && arg != "--single-transaction" && arg != "--lock-all-tables" && arg != "-x" &&
 				arg != "--databases" && arg != "-B" && arg != "--all-databases" && arg != "-A" {
 				args = append(args, arg)
 			}
 		}
 	}
 
+	// --dump-arg options were validated against the allowlist when resolving the config
+	args = append(args, options.DumpArgs...)
+
 	// Handle data inclusion
 	if !options.IncludeData {
 		args = append(common.RemoveDataFlags(args), "--no-data")
@@ -222,19 +226,14 @@ func getReplicationMysqldumpArgs(options backup_utils.AllDatabasesBackupOptions,
 
 // saveAllDatabasesMetadata saves metadata for all databases backup
 func saveAllDatabasesMetadata(metaFile string, metadata *backup_utils.BackupMetadata) error {
-	// Create metadata directory
-	if err := os.MkdirAll(filepath.Dir(metaFile), 0755); err != nil {
-		return fmt.Errorf("failed to create metadata directory: %w", err)
-	}
-
 	// Convert metadata to JSON
 	data, err := json.MarshalIndent(metadata, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(metaFile, data, 0644); err != nil {
+	// Write to file (creates the metadata directory when missing)
+	if err := fs.NewManager().File().WriteFile(metaFile, data); err != nil {
 		return fmt.Errorf("failed to write metadata file: %w", err)
 	}
 
@@ -0,0 +1,66 @@
+// Package engine selects the dump engine of single-database backups. Commands
+// pass BackupSingle as their backup function; it dispatches on
+// BackupOptions.Engine, so adding an engine only needs an entry in engines.
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	backup_single_mydumper "sfDBTools/internal/core/backup/single/mydumper"
+	backup_single_mysqldump "sfDBTools/internal/core/backup/single/mysqldump"
+	backup_utils "sfDBTools/utils/backup"
+)
+
+// Engine backs up one database into a single backup file
+type Engine interface {
+	Name() string
+	BackupSingle(ctx context.Context, options backup_utils.BackupOptions) (*backup_utils.BackupResult, error)
+}
+
+type funcEngine struct {
+	name   string
+	backup backup_utils.BackupFunc
+}
+
+func (e funcEngine) Name() string { return e.name }
+
+func (e funcEngine) BackupSingle(ctx context.Context, options backup_utils.BackupOptions) (*backup_utils.BackupResult, error) {
+	return e.backup(ctx, options)
+}
+
+var engines = map[string]Engine{
+	backup_utils.EngineMysqldump: funcEngine{backup_utils.EngineMysqldump, backup_single_mysqldump.BackupSingle},
+	backup_utils.EngineMydumper:  funcEngine{backup_utils.EngineMydumper, backup_single_mydumper.BackupSingle},
+}
+
+// Get returns the engine called name; mysqldump when name is empty
+func Get(name string) (Engine, error) {
+	if name == "" {
+		name = backup_utils.EngineMysqldump
+	}
+	if e, ok := engines[name]; ok {
+		return e, nil
+	}
+	return nil, fmt.Errorf("unknown dump engine %q (available: %v)", name, Names())
+}
+
+// Names lists the available engines
+func Names() []string {
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BackupSingle backs up options.DBName with the engine options.Engine names
+func BackupSingle(ctx context.Context, options backup_utils.BackupOptions) (*backup_utils.BackupResult, error) {
+	e, err := Get(options.Engine)
+	if err != nil {
+		return nil, err
+	}
+	return e.BackupSingle(ctx, options)
+}
@@ -0,0 +1,179 @@
+package physical
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sfDBTools/internal/config"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/backup/catalog"
+	"sfDBTools/utils/schema"
+)
+
+// Manifest is a loaded physical backup manifest
+type Manifest struct {
+	Path string
+	backup_utils.BackupMetadata
+}
+
+// File returns the backup file of the manifest
+func (m *Manifest) File() string {
+	return backup_utils.ArtifactPath(m.Path, &m.BackupMetadata)
+}
+
+// LoadManifest reads the physical backup manifest at path
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := schema.ReadManifest(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	m := &Manifest{Path: path}
+	if err := json.Unmarshal(data, &m.BackupMetadata); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if m.BackupType != BackupType || m.Physical == nil {
+		return nil, fmt.Errorf("%s is not a physical backup manifest (backup type %q)", path, m.BackupType)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		m.Path = abs
+	}
+	return m, nil
+}
+
+// ResolveManifest loads the manifest ref refers to: a manifest file, or a
+// catalog ID or backup file name
+func ResolveManifest(ref string) (*Manifest, error) {
+	if strings.HasSuffix(ref, ".meta.json") {
+		if _, err := os.Stat(ref); err == nil {
+			return LoadManifest(ref)
+		}
+	}
+	cat, err := openCatalog()
+	if err != nil {
+		return nil, err
+	}
+	e, err := cat.Find(ref)
+	cat.Close()
+	if err != nil {
+		return nil, err
+	}
+	return LoadManifest(e.Manifest)
+}
+
+// Chain returns the backups needed to restore m: the full backup first,
+// followed by the incremental backups up to m
+func Chain(m *Manifest) ([]*Manifest, error) {
+	chain := []*Manifest{m}
+	seen := map[string]bool{m.Path: true}
+	for cur := m; cur.Physical.Incremental; {
+		if cur.Physical.BaseManifest == "" {
+			return nil, fmt.Errorf("incremental backup %s does not record its base backup", cur.Path)
+		}
+		if seen[cur.Physical.BaseManifest] {
+			return nil, fmt.Errorf("backup chain of %s loops at %s", m.Path, cur.Physical.BaseManifest)
+		}
+		base, err := LoadManifest(cur.Physical.BaseManifest)
+		if err != nil {
+			return nil, fmt.Errorf("base of %s is missing: %w", cur.Path, err)
+		}
+		if base.Physical.ToLSN != cur.Physical.FromLSN {
+			return nil, fmt.Errorf("%s starts at LSN %d but its base %s ends at LSN %d",
+				cur.Path, cur.Physical.FromLSN, base.Path, base.Physical.ToLSN)
+		}
+		seen[base.Path] = true
+		chain = append([]*Manifest{base}, chain...)
+		cur = base
+	}
+	for _, b := range chain {
+		if _, err := os.Stat(b.File()); err != nil {
+			return nil, fmt.Errorf("backup file of %s is missing: %w", b.Path, err)
+		}
+	}
+	return chain, nil
+}
+
+// resolveBase returns the backup an incremental backup builds on: --base, or
+// the newest physical backup of the same server in the catalog
+func resolveBase(options Options) (*Manifest, error) {
+	if options.BaseManifest != "" {
+		return ResolveManifest(options.BaseManifest)
+	}
+	cat, err := openCatalog()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := cat.List(catalog.Filter{Type: BackupType})
+	cat.Close()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Host != options.Host || e.Port != options.Port {
+			continue
+		}
+		if m, err := LoadManifest(e.Manifest); err == nil {
+			if _, err := os.Stat(m.File()); err == nil {
+				return m, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no physical backup of %s:%d found to base an incremental backup on; take a full backup first or pass --base",
+		options.Host, options.Port)
+}
+
+func openCatalog() (*catalog.Catalog, error) {
+	cfg, err := config.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	path := backup_utils.CatalogPath(cfg)
+	if path == "" {
+		return nil, fmt.Errorf("no backup catalog configured (backup.catalog.path or backup.storage.base_directory)")
+	}
+	return catalog.Open(path)
+}
+
+// checkpoints is the LSN range a backup tool recorded in xtrabackup_checkpoints
+type checkpoints struct {
+	BackupType string
+	FromLSN    uint64
+	ToLSN      uint64
+}
+
+func readCheckpoints(dir string) (*checkpoints, error) {
+	f, err := os.Open(filepath.Join(dir, "xtrabackup_checkpoints"))
+	if err != nil {
+		return nil, fmt.Errorf("backup did not write xtrabackup_checkpoints: %w", err)
+	}
+	defer f.Close()
+
+	cp := &checkpoints{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "backup_type":
+			cp.BackupType = value
+		case "from_lsn":
+			cp.FromLSN, _ = strconv.ParseUint(value, 10, 64)
+		case "to_lsn":
+			cp.ToLSN, _ = strconv.ParseUint(value, 10, 64)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read xtrabackup_checkpoints: %w", err)
+	}
+	if cp.ToLSN == 0 {
+		return nil, fmt.Errorf("xtrabackup_checkpoints in %s has no to_lsn", dir)
+	}
+	return cp, nil
+}
@@ -0,0 +1,292 @@
+// Package physical takes hot physical backups with mariabackup (or Percona
+// xtrabackup), prepares full and incremental chains and restores a prepared
+// backup into the datadir of the local server.
+package physical
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/compression"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/schema"
+	"sfDBTools/utils/shutdown"
+)
+
+// BackupType is the backup_type of physical backup manifests
+const BackupType = "physical"
+
+// archiveRoot is the directory the target directory is stored under in the archive
+const archiveRoot = "physical"
+
+// Options configures a physical backup
+type Options struct {
+	backup_utils.BackupOptions
+	Incremental  bool   // back up only the pages changed since the base backup
+	BaseManifest string // base of an incremental backup; the newest physical backup of the host when empty
+	Parallel     int    // files copied in parallel; the tool default when zero
+}
+
+// Result describes a finished physical backup
+type Result struct {
+	backup_utils.BackupResult
+	Physical backup_utils.PhysicalMeta `json:"physical"`
+}
+
+// Backup copies the datadir of the server with mariabackup while it keeps
+// serving writes and archives the copy through the usual compression and
+// encryption. It must run on the database server.
+func Backup(ctx context.Context, options Options) (*Result, error) {
+	job := jobstatus.Start("backup-physical", options.Host)
+	result, err := backup(ctx, options, job)
+	job.Finish(err)
+	return result, err
+}
+
+func backup(ctx context.Context, options Options, job *jobstatus.Tracker) (*Result, error) {
+	lg, err := logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logger: %w", err)
+	}
+	tool, err := FindTool("")
+	if err != nil {
+		return nil, err
+	}
+
+	dbConfig := database.Config{
+		Host:     options.Host,
+		Port:     options.Port,
+		User:     options.User,
+		Password: options.Password,
+	}
+	db, err := database.GetWithoutDB(dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	var version, dataDir string
+	err = db.QueryRow("SELECT VERSION(), @@datadir").Scan(&version, &dataDir)
+	db.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server version and datadir: %w", err)
+	}
+	if info, err := os.Stat(dataDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("datadir %s is not a directory on this host; physical backups must run on the database server", dataDir)
+	}
+
+	meta := backup_utils.PhysicalMeta{Tool: tool, Incremental: options.Incremental, DataDir: filepath.Clean(dataDir)}
+	if options.Incremental {
+		job.SetStep("locating base backup")
+		base, err := resolveBase(options)
+		if err != nil {
+			return nil, err
+		}
+		if base.Physical.Tool != tool {
+			return nil, fmt.Errorf("base backup %s was taken with %s, not %s", base.Path, base.Physical.Tool, tool)
+		}
+		meta.BaseManifest = base.Path
+		meta.FromLSN = base.Physical.ToLSN
+		lg.Info("Incremental physical backup",
+			logger.String("base", base.Path),
+			logger.String("from_lsn", strconv.FormatUint(meta.FromLSN, 10)))
+	}
+
+	if removed, err := backup_utils.CleanupOldBackups(options.OutputDir, options.RetentionDays); err != nil {
+		lg.Warn("Failed to cleanup old backups", logger.Error(err))
+	} else if len(removed) > 0 {
+		lg.Info("Old backup directories removed", logger.Strings("dirs", removed), logger.Int("count", len(removed)))
+	}
+
+	startTime := time.Now()
+	result := &Result{BackupResult: *backup_utils.InitializeBackupResult(options.BackupOptions)}
+	result.IncludedData = true
+	outputFile, metaFile := outputPaths(options.BackupOptions, options.Incremental)
+	result.OutputFile, result.BackupMetaFile = outputFile, metaFile
+	if err := fs.NewManager().File().EnsureArtifactDir(filepath.Dir(outputFile)); err != nil {
+		return result, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Staging on the same filesystem keeps the space check of the backup meaningful
+	staging, err := os.MkdirTemp(filepath.Dir(outputFile), ".physical-")
+	if err != nil {
+		return result, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	defaultsFile, err := database.WriteDefaultsFile(staging, tool, options.User, options.Password)
+	if err != nil {
+		return result, err
+	}
+	targetDir := filepath.Join(staging, archiveRoot)
+
+	args := []string{
+		// --defaults-extra-file must come first; the server's own option files
+		// are still read, which is where the tool finds the datadir
+		"--defaults-extra-file=" + defaultsFile,
+		"--backup",
+		"--target-dir=" + targetDir,
+		"--host=" + options.Host,
+		"--port=" + strconv.Itoa(options.Port),
+	}
+	if options.Incremental {
+		args = append(args, "--incremental-lsn="+strconv.FormatUint(meta.FromLSN, 10))
+	}
+	if options.Parallel > 0 {
+		args = append(args, "--parallel="+strconv.Itoa(options.Parallel))
+	}
+
+	job.SetStep("copying datadir")
+	lg.Info("Executing "+tool, logger.String("output", outputFile), logger.Strings("args", args[1:]))
+	if err := run(ctx, tool, args...); err != nil {
+		return result, fmt.Errorf("%s backup failed: %w", tool, err)
+	}
+	checkpoints, err := readCheckpoints(targetDir)
+	if err != nil {
+		return result, err
+	}
+	meta.ToLSN = checkpoints.ToLSN
+	if !options.Incremental {
+		meta.FromLSN = checkpoints.FromLSN
+	}
+
+	job.SetStep("archiving")
+	if err := archiveDir(targetDir, outputFile, options.BackupOptions, job); err != nil {
+		backup_utils.RemovePartialOutput(outputFile)
+		return result, err
+	}
+
+	job.SetStep("finalizing")
+	if err := backup_utils.FinalizeBackupResult(&result.BackupResult, outputFile, startTime, options.BackupOptions); err != nil {
+		lg.Warn("Failed to finalize backup result", logger.Error(err))
+	}
+	result.Physical = meta
+	backup_utils.ReportResultDetails(job, &result.BackupResult)
+	job.Detail("LSN range", fmt.Sprintf("%d - %d", meta.FromLSN, meta.ToLSN))
+
+	metadata := &backup_utils.BackupMetadata{
+		SchemaVersion:     schema.ManifestVersion,
+		DatabaseName:      BackupType,
+		BackupDate:        time.Now().UTC(),
+		BackupType:        BackupType,
+		OutputFile:        outputFile,
+		FileSize:          result.OutputSize,
+		Compressed:        options.Compress,
+		CompressionType:   result.CompressionUsed,
+		Encrypted:         result.Encrypted,
+		IncludesData:      true,
+		Duration:          result.Duration.String(),
+		Checksum:          result.Checksum,
+		ChecksumAlgorithm: result.ChecksumAlgorithm,
+		Host:              options.Host,
+		Port:              options.Port,
+		User:              options.User,
+		MySQLVersion:      version,
+		Physical:          &result.Physical,
+	}
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err == nil {
+		err = fs.NewManager().File().WriteFile(metaFile, data)
+	}
+	if err != nil {
+		// Without the manifest the backup cannot serve as a base
+		return result, fmt.Errorf("failed to save physical backup metadata: %w", err)
+	}
+	backup_utils.RecordInCatalog(metaFile, metadata, options.RetentionDays)
+	backup_utils.PruneAfterBackup()
+
+	result.Success = true
+	return result, nil
+}
+
+// FindTool returns name when it is on PATH, or mariabackup, falling back to
+// xtrabackup, when name is empty
+func FindTool(name string) (string, error) {
+	candidates := []string{"mariabackup", "xtrabackup"}
+	if name != "" {
+		candidates = []string{name}
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c); err == nil {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("%v not found in PATH; install the MariaDB backup package (mariadb-backup)", candidates)
+}
+
+// run executes the backup tool with its output on the terminal
+func run(ctx context.Context, tool string, args ...string) error {
+	cmd := shutdown.Command(ctx, tool, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// archiveDir writes the backup target directory as a tar stream through the
+// backup writer chain into outputFile
+func archiveDir(root, outputFile string, options backup_utils.BackupOptions, job *jobstatus.Tracker) error {
+	lg, _ := logger.Get()
+
+	total, err := backup_utils.DirSize(root)
+	if err != nil {
+		return fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	outFile, err := fs.NewManager().File().CreateFile(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outFile.Close()
+
+	stream, err := backup_utils.OpenBackupStream(outFile, options, lg)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	tw := tar.NewWriter(stream)
+
+	err = backup_utils.TarDir(tw, job.Writer(tw, total), root, archiveRoot)
+	if closeErr := tw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := stream.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to archive physical backup: %w", err)
+	}
+	return nil
+}
+
+// outputPaths returns outputDir/YYYY_MM_DD/physical/physical_<time>_{full|incr}.tar[.gz|.zst][.enc]
+// and its metadata file
+func outputPaths(options backup_utils.BackupOptions, incremental bool) (string, string) {
+	now := common.LocalNow()
+	outputDir := filepath.Join(options.OutputDir, now.Format("2006_01_02"), BackupType)
+	base := "physical_" + now.Format("20060102_150405") + "_full"
+	if incremental {
+		base = "physical_" + now.Format("20060102_150405") + "_incr"
+	}
+
+	filename := base + ".tar"
+	if options.Compress {
+		if compressionType, err := compression.ValidateCompressionType(options.Compression); err == nil {
+			filename += compression.GetFileExtension(compressionType)
+		} else {
+			filename += compression.GetFileExtension(compression.CompressionGzip)
+		}
+	}
+	if options.Encrypt {
+		filename += ".enc"
+	}
+	return filepath.Join(outputDir, filename), filepath.Join(outputDir, base+".meta.json")
+}
@@ -0,0 +1,135 @@
+package physical
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/compression"
+	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/jobstatus"
+)
+
+// Prepare extracts the chain ending at m into targetDir and applies the redo
+// log and every incremental delta, leaving a consistent copy of the datadir
+// that the server can start from
+func Prepare(ctx context.Context, m *Manifest, targetDir string) ([]*Manifest, error) {
+	job := jobstatus.Start("backup-physical-prepare", filepath.Base(m.Path))
+	chain, err := prepare(ctx, m, targetDir, job)
+	job.Finish(err)
+	return chain, err
+}
+
+func prepare(ctx context.Context, m *Manifest, targetDir string, job *jobstatus.Tracker) ([]*Manifest, error) {
+	lg, _ := logger.Get()
+
+	chain, err := Chain(m)
+	if err != nil {
+		return nil, err
+	}
+	tool, err := FindTool(chain[0].Physical.Tool)
+	if err != nil {
+		return nil, err
+	}
+	if entries, err := os.ReadDir(targetDir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("target directory %s is not empty", targetDir)
+	}
+	targetDir = filepath.Clean(targetDir)
+	if err := os.MkdirAll(filepath.Dir(targetDir), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(targetDir), err)
+	}
+	// The full backup is renamed into place, which needs the target to be absent
+	os.Remove(targetDir)
+
+	// The key file comes from SFDB_ENCRYPTION_KEY_FILE, else a password is asked once
+	var secret crypto.Secret
+	for _, b := range chain {
+		if b.Encrypted {
+			if secret, err = crypto.GetEncryptionSecret("Enter encryption password to decrypt backup: ", ""); err != nil {
+				return nil, fmt.Errorf("failed to get encryption password: %w", err)
+			}
+			break
+		}
+	}
+
+	// Deltas are unpacked next to the target so applying them stays on one filesystem
+	work, err := os.MkdirTemp(filepath.Dir(targetDir), ".physical-prepare-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(work)
+
+	for i, b := range chain {
+		last := i == len(chain)-1
+		dir := filepath.Join(work, fmt.Sprint(i))
+		job.SetStep(fmt.Sprintf("extracting %d/%d", i+1, len(chain)))
+		lg.Info("Extracting physical backup", logger.String("file", b.File()))
+		if err := extractBackup(b.File(), dir, secret, job); err != nil {
+			return nil, err
+		}
+
+		args := []string{"--prepare", "--target-dir=" + targetDir}
+		if i == 0 {
+			if err := os.Rename(filepath.Join(dir, archiveRoot), targetDir); err != nil {
+				return nil, fmt.Errorf("failed to move full backup into %s: %w", targetDir, err)
+			}
+		} else {
+			args = append(args, "--incremental-dir="+filepath.Join(dir, archiveRoot))
+		}
+		// xtrabackup must not roll back uncommitted transactions while deltas
+		// remain; mariabackup handles this on its own
+		if tool == "xtrabackup" && !last {
+			args = append(args, "--apply-log-only")
+		}
+
+		job.SetStep(fmt.Sprintf("preparing %d/%d", i+1, len(chain)))
+		lg.Info("Executing "+tool, logger.Strings("args", args))
+		if err := run(ctx, tool, args...); err != nil {
+			return nil, fmt.Errorf("%s --prepare of %s failed: %w", tool, b.Path, err)
+		}
+		os.RemoveAll(dir)
+	}
+	return chain, nil
+}
+
+// extractBackup decrypts and decompresses the archive at path and unpacks it into dest
+func extractBackup(path, dest string, secret crypto.Secret, job *jobstatus.Tracker) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer file.Close()
+
+	var size int64
+	if fi, err := file.Stat(); err == nil {
+		size = fi.Size()
+	}
+	reader := job.Reader(file, size)
+
+	pathNoEnc := path
+	if strings.HasSuffix(strings.ToLower(path), ".enc") {
+		dr, err := crypto.NewDecryptingReader(reader, secret)
+		if err != nil {
+			return fmt.Errorf("failed to create decrypting reader: %w", err)
+		}
+		reader = dr
+		pathNoEnc = strings.TrimSuffix(pathNoEnc, ".enc")
+	}
+	decompressed, _, err := compression.NewAutoDecompressingReader(reader, compression.DetectCompressionTypeFromFile(pathNoEnc))
+	if err != nil {
+		return fmt.Errorf("failed to create decompressing reader: %w", err)
+	}
+	defer decompressed.Close()
+
+	if err := backup_utils.ExtractTar(decompressed, dest); err != nil {
+		return fmt.Errorf("failed to extract %s: %w", path, err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, archiveRoot)); err != nil {
+		return fmt.Errorf("%s does not contain a physical backup", path)
+	}
+	return nil
+}
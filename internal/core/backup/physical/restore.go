@@ -0,0 +1,201 @@
+package physical
+
+import (
+	"context"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/mariadb/discovery"
+	"sfDBTools/utils/system"
+)
+
+// defaultService is the systemd unit stopped and started around a restore
+// when none is given and none is running
+const defaultService = "mariadb"
+
+// RestoreOptions configures restoring a physical backup into the datadir
+type RestoreOptions struct {
+	Manifest    *Manifest
+	DataDir     string // datadir to replace; detected from the server configuration when empty
+	ServiceName string // systemd unit of the server; detected when empty
+	WorkDir     string // where the chain is prepared; next to the datadir when empty
+	MoveBack    bool   // move the prepared files instead of copying them (needs no extra space)
+}
+
+// RestoreResult describes a finished restore into the datadir
+type RestoreResult struct {
+	Chain           []*Manifest
+	DataDir         string
+	PreviousDataDir string // the replaced datadir, kept for the operator to remove
+	ServiceName     string
+	Duration        time.Duration
+}
+
+// ResolveRestoreTarget fills in the datadir and service of opts from the
+// local MariaDB installation, falling back to the datadir of the backup
+func ResolveRestoreTarget(opts *RestoreOptions) {
+	if opts.DataDir != "" && opts.ServiceName != "" {
+		return
+	}
+	installation, _ := discovery.DiscoverMariaDBInstallation()
+	if opts.DataDir == "" && installation != nil {
+		opts.DataDir = installation.DataDir
+	}
+	if opts.DataDir == "" && opts.Manifest != nil {
+		opts.DataDir = opts.Manifest.Physical.DataDir
+	}
+	if opts.ServiceName == "" && installation != nil {
+		opts.ServiceName = installation.ServiceName
+	}
+	if opts.ServiceName == "" {
+		opts.ServiceName = defaultService
+	}
+}
+
+// Restore prepares the chain ending at opts.Manifest, stops the server,
+// replaces its datadir with the prepared backup and starts it again. The old
+// datadir is renamed aside and put back when the restore fails.
+func Restore(ctx context.Context, opts RestoreOptions) (*RestoreResult, error) {
+	job := jobstatus.Start("restore-physical", opts.DataDir)
+	result, err := restore(ctx, opts, job)
+	job.Finish(err)
+	return result, err
+}
+
+func restore(ctx context.Context, opts RestoreOptions, job *jobstatus.Tracker) (*RestoreResult, error) {
+	lg, _ := logger.Get()
+	if os.Geteuid() != 0 {
+		return nil, fmt.Errorf("restoring into the datadir stops the server and changes file ownership; run as root")
+	}
+	ResolveRestoreTarget(&opts)
+	if opts.DataDir == "" {
+		return nil, fmt.Errorf("datadir could not be detected; pass --datadir")
+	}
+	dataDir := filepath.Clean(opts.DataDir)
+	workDir := opts.WorkDir
+	if workDir == "" {
+		workDir = filepath.Dir(dataDir)
+	}
+	start := time.Now()
+	stamp := time.Now().Format("20060102_150405")
+	result := &RestoreResult{DataDir: dataDir, ServiceName: opts.ServiceName}
+
+	prepared := filepath.Join(workDir, ".physical-restore-"+stamp)
+	defer os.RemoveAll(prepared)
+	chain, err := prepare(ctx, opts.Manifest, prepared, job)
+	if err != nil {
+		return nil, err
+	}
+	result.Chain = chain
+	tool, err := FindTool(chain[0].Physical.Tool)
+	if err != nil {
+		return nil, err
+	}
+
+	services := system.NewServiceManager()
+	job.SetStep("stopping server")
+	if services.IsActive(opts.ServiceName) {
+		lg.Info("Stopping server", logger.String("service", opts.ServiceName))
+		if err := services.Stop(opts.ServiceName); err != nil {
+			return result, fmt.Errorf("failed to stop %s: %w", opts.ServiceName, err)
+		}
+	}
+
+	if _, err := os.Stat(dataDir); err == nil {
+		result.PreviousDataDir = dataDir + ".pre-restore-" + stamp
+		if err := os.Rename(dataDir, result.PreviousDataDir); err != nil {
+			startErr := services.Start(opts.ServiceName)
+			return result, fmt.Errorf("failed to move the current datadir aside: %w (server start: %v)", err, startErr)
+		}
+		lg.Info("Current datadir moved aside", logger.String("path", result.PreviousDataDir))
+	}
+
+	if err := replaceDataDir(ctx, tool, prepared, dataDir, opts.MoveBack, job); err != nil {
+		return result, rollback(services, opts.ServiceName, dataDir, result.PreviousDataDir, err)
+	}
+
+	job.SetStep("starting server")
+	lg.Info("Starting server", logger.String("service", opts.ServiceName))
+	if err := services.Start(opts.ServiceName); err != nil || !services.IsActive(opts.ServiceName) {
+		if err == nil {
+			err = fmt.Errorf("%s is not active after start", opts.ServiceName)
+		}
+		return result, rollback(services, opts.ServiceName, dataDir, result.PreviousDataDir,
+			fmt.Errorf("server did not start on the restored datadir: %w", err))
+	}
+
+	result.Duration = time.Since(start)
+	if result.PreviousDataDir != "" {
+		job.Detail("Previous datadir", result.PreviousDataDir)
+	}
+	return result, nil
+}
+
+// replaceDataDir copies (or moves) the prepared backup into an empty datadir
+// and hands it to the mysql user
+func replaceDataDir(ctx context.Context, tool, prepared, dataDir string, moveBack bool, job *jobstatus.Tracker) error {
+	lg, _ := logger.Get()
+	manager := fs.NewManager()
+
+	if err := manager.Dir().CreateWithPerms(dataDir, 0750, "mysql", "mysql"); err != nil {
+		return fmt.Errorf("failed to create datadir: %w", err)
+	}
+	mode := "--copy-back"
+	if moveBack {
+		mode = "--move-back"
+	}
+	job.SetStep("restoring datadir")
+	args := []string{mode, "--target-dir=" + prepared, "--datadir=" + dataDir}
+	lg.Info("Executing "+tool, logger.Strings("args", args))
+	if err := run(ctx, tool, args...); err != nil {
+		return fmt.Errorf("%s %s failed: %w", tool, mode, err)
+	}
+
+	job.SetStep("setting ownership")
+	perm := manager.Perm()
+	return filepath.WalkDir(dataDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return perm.SetDirPerms(path, info.Mode().Perm(), "mysql", "mysql")
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return perm.SetFilePerms(path, info.Mode().Perm(), "mysql", "mysql")
+	})
+}
+
+// rollback puts the previous datadir back after a failed restore and starts
+// the server on it
+func rollback(services system.ServiceManager, service, dataDir, previous string, cause error) error {
+	lg, _ := logger.Get()
+	if previous == "" {
+		return cause
+	}
+	failed := dataDir + ".failed-restore"
+	os.RemoveAll(failed)
+	if err := os.Rename(dataDir, failed); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%w; the previous datadir is kept at %s but could not be put back: %v", cause, previous, err)
+	}
+	if err := os.Rename(previous, dataDir); err != nil {
+		return fmt.Errorf("%w; the previous datadir is kept at %s but could not be put back: %v", cause, previous, err)
+	}
+	os.RemoveAll(failed)
+	lg.Warn("Restore failed, previous datadir put back", logger.String("datadir", dataDir), logger.Error(cause))
+	if err := services.Start(service); err != nil {
+		return fmt.Errorf("%w; the previous datadir was put back but %s did not start: %v", cause, service, err)
+	}
+	return fmt.Errorf("%w; the previous datadir was put back and the server restarted", cause)
+}
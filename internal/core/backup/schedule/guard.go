@@ -0,0 +1,52 @@
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config/model"
+)
+
+// GuardStateDir holds the last-run markers used by the DST guard
+const GuardStateDir = "/var/lib/sfDBTools/schedule"
+
+// guardStateDir resolves the marker directory, using the temp dir when
+// GuardStateDir is not writable (e.g. when testing as a regular user)
+func guardStateDir() string {
+	if err := os.MkdirAll(GuardStateDir, 0755); err == nil {
+		return GuardStateDir
+	}
+	return filepath.Join(os.TempDir(), "sfDBTools", "schedule")
+}
+
+// Guard decides whether a guarded schedule may run now. A fixed-time schedule
+// runs at most once per local calendar day in its timezone, so the extra
+// trigger added for a skipped DST hour, or a wall-clock time that occurs twice
+// when clocks go back, does not start a second backup. The run is recorded
+// when it is allowed.
+func Guard(s model.BackupSchedule, timezone string, now time.Time) (bool, error) {
+	loc := time.Local
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return false, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+	}
+	today := now.In(loc).Format("2006-01-02")
+
+	dir := guardStateDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	marker := filepath.Join(dir, UnitBaseName(s)+".last")
+	if data, err := os.ReadFile(marker); err == nil && strings.TrimSpace(string(data)) == today {
+		return false, nil
+	}
+	if err := os.WriteFile(marker, []byte(today+"\n"), 0644); err != nil {
+		return false, fmt.Errorf("failed to record run in %s: %w", marker, err)
+	}
+	return true, nil
+}
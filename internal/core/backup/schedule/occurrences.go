@@ -0,0 +1,125 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config/model"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Occurrences lists the times a fixed-time schedule was due between from and
+// to (inclusive) in the given timezone. Weekday lists and ranges (Mon..Fri)
+// and year-month-day patterns made of *, numbers and lists are understood;
+// other expressions, such as repeating clocks, return an error.
+func Occurrences(s model.BackupSchedule, timezone string, from, to time.Time) ([]time.Time, error) {
+	loc := time.Local
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+	}
+
+	spec := parseCalendar(s.OnCalendar)
+	clock, ok := spec.clockSeconds()
+	if !ok {
+		return nil, fmt.Errorf("schedule %s: %q has no fixed time of day", s.Name, s.OnCalendar)
+	}
+	match, err := dayMatcher(spec.fields)
+	if err != nil {
+		return nil, fmt.Errorf("schedule %s: %w", s.Name, err)
+	}
+
+	var times []time.Time
+	start := from.In(loc)
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !match(day) {
+			continue
+		}
+		t := time.Date(day.Year(), day.Month(), day.Day(), clock/3600, clock%3600/60, clock%60, 0, loc)
+		if !t.Before(from) && !t.After(to) {
+			times = append(times, t)
+		}
+	}
+	return times, nil
+}
+
+// dayMatcher builds the day filter of the date and weekday fields of a calendar
+func dayMatcher(fields []string) (func(time.Time) bool, error) {
+	var days map[time.Weekday]bool
+	var year, month, mday func(int) bool
+	for _, field := range fields {
+		if strings.Count(field, "-") == 2 {
+			parts := strings.Split(field, "-")
+			var err error
+			if year, err = numberMatcher(parts[0]); err != nil {
+				return nil, err
+			}
+			if month, err = numberMatcher(parts[1]); err != nil {
+				return nil, err
+			}
+			if mday, err = numberMatcher(parts[2]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var err error
+		if days, err = weekdaySet(field); err != nil {
+			return nil, err
+		}
+	}
+	return func(t time.Time) bool {
+		return (days == nil || days[t.Weekday()]) &&
+			(year == nil || year(t.Year())) &&
+			(month == nil || month(int(t.Month()))) &&
+			(mday == nil || mday(t.Day()))
+	}, nil
+}
+
+// weekdaySet parses Mon, Mon..Fri and comma-separated combinations
+func weekdaySet(field string) (map[time.Weekday]bool, error) {
+	set := make(map[time.Weekday]bool)
+	for _, part := range strings.Split(field, ",") {
+		first, last, isRange := strings.Cut(part, "..")
+		from, ok := weekdays[strings.ToLower(first)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported calendar field %q", field)
+		}
+		to := from
+		if isRange {
+			if to, ok = weekdays[strings.ToLower(last)]; !ok {
+				return nil, fmt.Errorf("unsupported calendar field %q", field)
+			}
+		}
+		for d := from; ; d = (d + 1) % 7 {
+			set[d] = true
+			if d == to {
+				break
+			}
+		}
+	}
+	return set, nil
+}
+
+// numberMatcher parses *, N and N,M,... of a date component
+func numberMatcher(part string) (func(int) bool, error) {
+	if part == "*" {
+		return nil, nil
+	}
+	values := make(map[int]bool)
+	for _, v := range strings.Split(part, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported calendar date %q", part)
+		}
+		values[n] = true
+	}
+	return func(n int) bool { return values[n] }, nil
+}
@@ -0,0 +1,277 @@
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config/model"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/system"
+)
+
+const (
+	// UnitDir is where generated unit files are installed
+	UnitDir = "/etc/systemd/system"
+	// UnitPrefix marks units managed by sfDBTools so uninstall only touches our own files
+	UnitPrefix = "sfdbtools-backup-"
+	// EnvironmentFile is loaded by every generated service when present, for secrets like SFDB_ENCRYPTION_PASSWORD
+	EnvironmentFile = "/etc/sfDBTools/schedule.env"
+)
+
+// commandArgs maps a schedule command to the sfDBTools subcommand it runs
+var commandArgs = map[string][]string{
+	"all":       {"backup", "all"},
+	"selection": {"backup", "selection"},
+	"user":      {"backup", "user"},
+	"group":     {"backup", "group"},
+	"snapshot":  {"backup", "snapshot"},
+	"physical":  {"backup", "physical"},
+	"drill":     {"restore", "drill"},
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// UnitOptions controls how the service units invoke sfDBTools
+type UnitOptions struct {
+	BinaryPath string // absolute path of the sfDBTools binary
+	WorkDir    string // working directory; its ./config/config.yaml is picked up by the loader
+	Timezone   string // default timezone for schedules that do not set one
+}
+
+// Units holds the rendered unit files for a single schedule
+type Units struct {
+	Schedule    model.BackupSchedule
+	ServiceName string
+	TimerName   string
+	Service     string
+	Timer       string
+	Timezone    string     // timezone the calendar is evaluated in, empty for the server's zone
+	OnCalendar  []string   // calendar expressions written to the timer
+	DSTIssues   []DSTIssue // upcoming DST changes that affect the schedule
+}
+
+// UnitBaseName returns the unit name (without suffix) for a schedule
+func UnitBaseName(s model.BackupSchedule) string {
+	name := strings.Trim(unsafeNameChars.ReplaceAllString(s.Name, "-"), "-")
+	return UnitPrefix + name
+}
+
+// Render builds the service and timer units for one schedule
+func Render(s model.BackupSchedule, opts UnitOptions) (*Units, error) {
+	if strings.TrimSpace(s.Name) == "" {
+		return nil, fmt.Errorf("schedule name is required")
+	}
+	subcommand, ok := commandArgs[s.Command]
+	if !ok {
+		return nil, fmt.Errorf("schedule %s: unsupported command %q (use all, selection, group, user, snapshot, physical or drill)", s.Name, s.Command)
+	}
+	if strings.TrimSpace(s.OnCalendar) == "" {
+		return nil, fmt.Errorf("schedule %s: on_calendar is required", s.Name)
+	}
+	if !filepath.IsAbs(opts.BinaryPath) {
+		return nil, fmt.Errorf("binary path must be absolute: %s", opts.BinaryPath)
+	}
+
+	timezone, err := ScheduleTimezone(s, opts.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := DSTIssues(s.OnCalendar, timezone, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Pin the calendar to the timezone. When the time falls into a skipped
+	// DST hour, also trigger at the end of the gap; the guard keeps either
+	// that or a repeated hour from causing a second run on the same day.
+	spec := parseCalendar(s.OnCalendar)
+	calendars := []string{spec.expression(spec.clock, timezone)}
+	for _, issue := range issues {
+		if issue.Repeated {
+			continue
+		}
+		extra := spec.expression(issue.WindowEnd, timezone)
+		if !slices.Contains(calendars, extra) {
+			calendars = append(calendars, extra)
+		}
+	}
+
+	base := UnitBaseName(s)
+	execArgs := append(append([]string{opts.BinaryPath}, subcommand...), s.Args...)
+
+	var svc strings.Builder
+	svc.WriteString("# Generated by sfDBTools - do not edit, run 'sfDBTools backup schedule install-systemd' instead\n")
+	svc.WriteString("[Unit]\n")
+	fmt.Fprintf(&svc, "Description=sfDBTools backup (%s)\n", s.Name)
+	svc.WriteString("Wants=network-online.target\n")
+	svc.WriteString("After=network-online.target\n\n")
+	svc.WriteString("[Service]\n")
+	svc.WriteString("Type=oneshot\n")
+	if opts.WorkDir != "" {
+		fmt.Fprintf(&svc, "WorkingDirectory=%s\n", opts.WorkDir)
+	}
+	fmt.Fprintf(&svc, "EnvironmentFile=-%s\n", EnvironmentFile)
+	if len(issues) > 0 {
+		guardArgs := []string{opts.BinaryPath, "backup", "schedule", "guard", "--name", s.Name}
+		if timezone != "" {
+			guardArgs = append(guardArgs, "--timezone", timezone)
+		}
+		fmt.Fprintf(&svc, "ExecCondition=%s\n", quoteArgs(guardArgs))
+	}
+	fmt.Fprintf(&svc, "ExecStart=%s\n", quoteArgs(execArgs))
+	svc.WriteString("Nice=10\n")
+	svc.WriteString("IOSchedulingClass=best-effort\n")
+	svc.WriteString("IOSchedulingPriority=7\n")
+
+	var tmr strings.Builder
+	tmr.WriteString("# Generated by sfDBTools - do not edit, run 'sfDBTools backup schedule install-systemd' instead\n")
+	tmr.WriteString("[Unit]\n")
+	fmt.Fprintf(&tmr, "Description=sfDBTools backup timer (%s)\n\n", s.Name)
+	tmr.WriteString("[Timer]\n")
+	for _, c := range calendars {
+		fmt.Fprintf(&tmr, "OnCalendar=%s\n", c)
+	}
+	if s.RandomizedDelay != "" {
+		fmt.Fprintf(&tmr, "RandomizedDelaySec=%s\n", s.RandomizedDelay)
+	}
+	fmt.Fprintf(&tmr, "Persistent=%t\n", s.Persistent)
+	fmt.Fprintf(&tmr, "Unit=%s.service\n\n", base)
+	tmr.WriteString("[Install]\n")
+	tmr.WriteString("WantedBy=timers.target\n")
+
+	return &Units{
+		Schedule:    s,
+		ServiceName: base + ".service",
+		TimerName:   base + ".timer",
+		Service:     svc.String(),
+		Timer:       tmr.String(),
+		Timezone:    timezone,
+		OnCalendar:  calendars,
+		DSTIssues:   issues,
+	}, nil
+}
+
+// RenderAll renders units for every enabled schedule
+func RenderAll(schedules []model.BackupSchedule, opts UnitOptions) ([]*Units, error) {
+	var result []*Units
+	seen := make(map[string]string)
+	for _, s := range schedules {
+		if !s.Enabled {
+			continue
+		}
+		units, err := Render(s, opts)
+		if err != nil {
+			return nil, err
+		}
+		if other, dup := seen[units.TimerName]; dup {
+			return nil, fmt.Errorf("schedules %s and %s map to the same unit name %s", other, s.Name, units.TimerName)
+		}
+		seen[units.TimerName] = s.Name
+		result = append(result, units)
+	}
+	return result, nil
+}
+
+// InstallSystemd writes the units, reloads systemd and enables/starts each timer
+func InstallSystemd(units []*Units) error {
+	lg, _ := logger.Get()
+	sm := system.NewServiceManager()
+
+	for _, u := range units {
+		if err := os.WriteFile(filepath.Join(UnitDir, u.ServiceName), []byte(u.Service), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", u.ServiceName, err)
+		}
+		if err := os.WriteFile(filepath.Join(UnitDir, u.TimerName), []byte(u.Timer), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", u.TimerName, err)
+		}
+		lg.Info("Systemd units written",
+			logger.String("schedule", u.Schedule.Name),
+			logger.String("service", u.ServiceName),
+			logger.String("timer", u.TimerName))
+	}
+
+	if err := sm.DaemonReload(); err != nil {
+		return err
+	}
+
+	for _, u := range units {
+		if err := sm.Enable(u.TimerName); err != nil {
+			return err
+		}
+		if err := sm.Start(u.TimerName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InstalledUnits lists the sfDBTools backup units currently present in UnitDir
+func InstalledUnits() ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(UnitDir, UnitPrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, filepath.Base(m))
+	}
+	return names, nil
+}
+
+// UninstallSystemd stops and disables every managed timer and removes all managed unit files.
+// It returns the names of the removed units.
+func UninstallSystemd() ([]string, error) {
+	lg, _ := logger.Get()
+	sm := system.NewServiceManager()
+
+	names, err := InstalledUnits()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed units: %w", err)
+	}
+
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".timer") {
+			continue
+		}
+		// A unit that is already stopped or disabled is fine; keep going so files still get removed
+		if err := sm.Stop(name); err != nil {
+			lg.Warn("Failed to stop timer", logger.String("timer", name), logger.Error(err))
+		}
+		if err := sm.Disable(name); err != nil {
+			lg.Warn("Failed to disable timer", logger.String("timer", name), logger.Error(err))
+		}
+	}
+
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(UnitDir, name)); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove %s: %w", name, err)
+		}
+		lg.Info("Systemd unit removed", logger.String("unit", name))
+	}
+
+	if len(names) > 0 {
+		if err := sm.DaemonReload(); err != nil {
+			return names, err
+		}
+	}
+	return names, nil
+}
+
+// quoteArgs joins arguments for ExecStart, quoting the ones that contain whitespace or quotes.
+// Percent signs are doubled so systemd does not treat them as specifiers.
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		a = strings.ReplaceAll(a, "%", "%%")
+		if a == "" || strings.ContainsAny(a, " \t\"'\\") {
+			a = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(a) + `"`
+		}
+		quoted[i] = a
+	}
+	return strings.Join(quoted, " ")
+}
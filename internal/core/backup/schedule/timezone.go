@@ -0,0 +1,195 @@
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config/model"
+)
+
+// dstHorizon is how far ahead DST transitions are checked when rendering timers
+const dstHorizon = 366 * 24 * time.Hour
+
+var timeOfDayPattern = regexp.MustCompile(`^(\d{1,2}):(\d{2})(?::(\d{2}))?$`)
+
+// calendarShorthands expands the systemd shorthands that fire at a fixed time of day
+var calendarShorthands = map[string]string{
+	"daily":    "*-*-* 00:00:00",
+	"weekly":   "Mon *-*-* 00:00:00",
+	"monthly":  "*-*-01 00:00:00",
+	"yearly":   "*-01-01 00:00:00",
+	"annually": "*-01-01 00:00:00",
+}
+
+// DSTIssue is a day on which a schedule's wall-clock time is skipped or repeated
+type DSTIssue struct {
+	Date      time.Time // the local date of the transition
+	Repeated  bool      // true when clocks go back and the time occurs twice
+	WindowEnd string    // first wall-clock time (HH:MM:SS) after the affected window
+}
+
+func (i DSTIssue) String() string {
+	if i.Repeated {
+		return fmt.Sprintf("%s: time occurs twice (clocks go back)", i.Date.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("%s: time does not exist (clocks go forward)", i.Date.Format("2006-01-02"))
+}
+
+// calendarSpec is an OnCalendar expression split into its parts
+type calendarSpec struct {
+	fields   []string // date/weekday fields before the time
+	clock    string   // HH:MM[:SS], empty when the expression has no fixed time
+	timezone string   // trailing timezone, empty when not given
+}
+
+// CalendarClock returns the fixed time of day (HH:MM[:SS]) of an OnCalendar
+// expression, or "" when it has none
+func CalendarClock(expr string) string {
+	return parseCalendar(expr).clock
+}
+
+// parseCalendar splits an OnCalendar expression, expanding shorthands and
+// detecting a trailing timezone
+func parseCalendar(expr string) calendarSpec {
+	expr = strings.TrimSpace(expr)
+	if expanded, ok := calendarShorthands[strings.ToLower(expr)]; ok {
+		expr = expanded
+	}
+	parts := strings.Fields(expr)
+
+	var spec calendarSpec
+	if n := len(parts); n > 1 {
+		if _, err := time.LoadLocation(parts[n-1]); err == nil && !strings.Contains(parts[n-1], ":") {
+			spec.timezone = parts[n-1]
+			parts = parts[:n-1]
+		}
+	}
+	if n := len(parts); n > 0 && timeOfDayPattern.MatchString(parts[n-1]) {
+		spec.clock = parts[n-1]
+		parts = parts[:n-1]
+	}
+	spec.fields = parts
+	return spec
+}
+
+// expression rebuilds the OnCalendar value with the given clock and timezone
+func (c calendarSpec) expression(clock, timezone string) string {
+	parts := append([]string{}, c.fields...)
+	if clock != "" {
+		parts = append(parts, clock)
+	}
+	if timezone != "" {
+		parts = append(parts, timezone)
+	}
+	return strings.Join(parts, " ")
+}
+
+// clockSeconds returns the time of day of the spec as seconds after midnight
+func (c calendarSpec) clockSeconds() (int, bool) {
+	m := timeOfDayPattern.FindStringSubmatch(c.clock)
+	if m == nil {
+		return 0, false
+	}
+	h, _ := strconv.Atoi(m[1])
+	min, _ := strconv.Atoi(m[2])
+	sec := 0
+	if m[3] != "" {
+		sec, _ = strconv.Atoi(m[3])
+	}
+	if h > 23 || min > 59 || sec > 59 {
+		return 0, false
+	}
+	return h*3600 + min*60 + sec, true
+}
+
+// ScheduleTimezone returns the timezone a schedule runs in: the timezone in its
+// on_calendar expression, its timezone setting, or the given default
+func ScheduleTimezone(s model.BackupSchedule, fallback string) (string, error) {
+	tz := parseCalendar(s.OnCalendar).timezone
+	if tz == "" {
+		tz = s.Timezone
+	}
+	if tz == "" {
+		tz = fallback
+	}
+	if tz == "" {
+		return "", nil
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return "", fmt.Errorf("schedule %s: invalid timezone %q: %w", s.Name, tz, err)
+	}
+	return tz, nil
+}
+
+// transition is an instant at which a zone's UTC offset changes
+type transition struct {
+	at            time.Time
+	before, after int // UTC offsets in seconds
+}
+
+// transitions lists offset changes of loc between from and from+horizon
+func transitions(loc *time.Location, from time.Time, horizon time.Duration) []transition {
+	var result []transition
+	prev := from.In(loc)
+	_, prevOff := prev.Zone()
+	for t := from.Add(time.Hour); t.Before(from.Add(horizon)); t = t.Add(time.Hour) {
+		_, off := t.In(loc).Zone()
+		if off == prevOff {
+			prev = t
+			continue
+		}
+		// Narrow the change down to the second
+		lo, hi := prev, t
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, o := mid.In(loc).Zone(); o == prevOff {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		result = append(result, transition{at: hi, before: prevOff, after: off})
+		prev, prevOff = t, off
+	}
+	return result
+}
+
+// DSTIssues reports upcoming days on which a fixed-time schedule would be
+// skipped or run twice because of a DST change in the given timezone.
+// Schedules without a single fixed time of day are not checked, and weekday or
+// date restrictions are ignored, so the report errs on the side of caution.
+func DSTIssues(onCalendar, timezone string, from time.Time) ([]DSTIssue, error) {
+	loc := time.Local
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, err
+		}
+	}
+	clock, ok := parseCalendar(onCalendar).clockSeconds()
+	if !ok {
+		return nil, nil
+	}
+
+	var issues []DSTIssue
+	for _, tr := range transitions(loc, from, dstHorizon) {
+		shift := tr.after - tr.before
+		// Wall-clock window affected by the change, as seen from the side that has it
+		start := tr.at.In(time.FixedZone("", tr.before))
+		repeated := shift < 0
+		if repeated {
+			start = tr.at.In(time.FixedZone("", tr.after))
+			shift = -shift
+		}
+		midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		offset := int(start.Sub(midnight).Seconds())
+		if clock >= offset && clock < offset+shift {
+			end := start.Add(time.Duration(shift) * time.Second)
+			issues = append(issues, DSTIssue{Date: midnight, Repeated: repeated, WindowEnd: end.Format("15:04:05")})
+		}
+	}
+	return issues, nil
+}
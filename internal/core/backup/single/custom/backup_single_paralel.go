@@ -1,8 +1,8 @@
 package backup_single_custom
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"sfDBTools/internal/logger"
@@ -13,7 +13,7 @@ import (
 )
 
 // BackupSingle performs a backup of a single database
-func BackupCustom(options backup_utils.BackupOptions) (*backup_utils.BackupResult, error) {
+func BackupCustom(ctx context.Context, options backup_utils.BackupOptions) (*backup_utils.BackupResult, error) {
 	lg, err := logger.Get()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get logger: %w", err)
@@ -21,16 +21,14 @@ func BackupCustom(options backup_utils.BackupOptions) (*backup_utils.BackupResul
 
 	manager := fs.NewManager()
 	if !manager.Dir().Exists(options.OutputDir) {
-		if err := manager.Dir().Create(options.OutputDir); err != nil {
+		if err := manager.Dir().CreateArtifactDir(options.OutputDir); err != nil {
 			lg.Error("Failed to create output directory", logger.Error(err))
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
 		}
 	}
 	if err := manager.Dir().IsWritable(options.OutputDir); err != nil {
 		lg.Error("Output directory validation failed", logger.Error(err))
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("output directory validation failed: %w", err)
 	}
 
 	// Clean up old backups based on retention policy
@@ -64,7 +62,7 @@ func BackupCustom(options backup_utils.BackupOptions) (*backup_utils.BackupResul
 		defer database.CleanupMaxStatementTimeManager(timeManager)
 	}
 
-	dbInfo := info.CollectDatabaseInfo(config, lg)
+	dbInfo := info.CollectDatabaseInfo(ctx, config, lg)
 
 	outputFile, metaFile, err := backup_utils.SetupBackupPaths(options)
 	if err != nil {
@@ -74,6 +72,7 @@ func BackupCustom(options backup_utils.BackupOptions) (*backup_utils.BackupResul
 	result.OutputFile, result.BackupMetaFile = outputFile, metaFile
 
 	if err := performBackup(options, outputFile); err != nil {
+		backup_utils.RemovePartialOutput(outputFile)
 		result.Error = err
 		return result, err
 	}
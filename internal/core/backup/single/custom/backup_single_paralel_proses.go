@@ -2,12 +2,12 @@ package backup_single_custom
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"time"
 
 	"sfDBTools/internal/logger"
 	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/fs"
 )
 
 // performBackup performs the actual database backup using mysqldump
@@ -18,7 +18,7 @@ func performBackup(options backup_utils.BackupOptions, outputFile string) error
 		lg.Error("Invalid backup options", logger.Error(err))
 		return fmt.Errorf("validation failed: %w", err)
 	}
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+	if err := fs.NewManager().File().EnsureArtifactDir(filepath.Dir(outputFile)); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -0,0 +1,86 @@
+package backup_single_mydumper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+)
+
+// BackupSingle backs up a single database with mydumper. The dump directory
+// is archived into one backup file that goes through the usual compression
+// and encryption, so the result is handled like a mysqldump backup.
+func BackupSingle(ctx context.Context, options backup_utils.BackupOptions) (*backup_utils.BackupResult, error) {
+	job := jobstatus.Start("backup-single", options.DBName)
+	result, err := backupSingle(ctx, options, job)
+	job.Finish(err)
+	return result, err
+}
+
+func backupSingle(ctx context.Context, options backup_utils.BackupOptions, job *jobstatus.Tracker) (*backup_utils.BackupResult, error) {
+	lg, err := logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logger: %w", err)
+	}
+
+	manager := fs.NewManager()
+	if err := manager.Dir().CreateArtifactDir(options.OutputDir); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := manager.Dir().IsWritable(options.OutputDir); err != nil {
+		return nil, fmt.Errorf("output directory validation failed: %w", err)
+	}
+
+	if removed, err := backup_utils.CleanupOldBackups(options.OutputDir, options.RetentionDays); err != nil {
+		lg.Warn("Failed to cleanup old backups", logger.Error(err))
+	} else if len(removed) > 0 {
+		lg.Info("Old backup directories removed", logger.Strings("dirs", removed), logger.Int("count", len(removed)))
+	}
+
+	startTime := time.Now()
+	result := backup_utils.InitializeBackupResult(options)
+
+	if err := backup_utils.ValidateAndPrepareBackup(options); err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	config := database.Config{
+		Host: options.Host, Port: options.Port, User: options.User,
+		Password: options.Password, DBName: options.DBName,
+	}
+
+	job.SetStep("collecting database info")
+	dbInfo := info.CollectDatabaseInfo(ctx, config, lg)
+
+	outputFile, metaFile, err := backup_utils.SetupBackupPaths(options)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+	result.OutputFile, result.BackupMetaFile = outputFile, metaFile
+
+	job.SetStep("dumping")
+	if err := performBackup(ctx, options, outputFile, job); err != nil {
+		backup_utils.RemovePartialOutput(outputFile)
+		result.Error = err
+		return result, err
+	}
+
+	job.SetStep("finalizing")
+	if err := backup_utils.FinalizeBackupResult(result, outputFile, startTime, options); err != nil {
+		lg.Warn("Failed to finalize backup result", logger.Error(err))
+	}
+	backup_utils.ReportResultDetails(job, result)
+
+	if err := backup_utils.CreateMetadataFile(options, result, config, dbInfo); err != nil {
+		lg.Warn("Failed to create metadata file", logger.Error(err))
+	}
+	return result, nil
+}
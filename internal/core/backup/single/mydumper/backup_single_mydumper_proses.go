@@ -0,0 +1,145 @@
+package backup_single_mydumper
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/shutdown"
+)
+
+// archiveRoot is the directory the mydumper output is stored under in the archive
+const archiveRoot = "mydumper"
+
+// defaultRows is the chunk size when backup.mydumper.rows is not set
+const defaultRows = 500000
+
+// performBackup runs mydumper into a staging directory next to outputFile and
+// archives the result through the backup writer chain into outputFile
+func performBackup(ctx context.Context, options backup_utils.BackupOptions, outputFile string, job *jobstatus.Tracker) error {
+	lg, _ := logger.Get()
+
+	if err := backup_utils.ValidateBackupOptions(options); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	fsMgr := fs.NewManager()
+	if err := fsMgr.File().EnsureArtifactDir(filepath.Dir(outputFile)); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Staging on the same filesystem keeps the space check of the backup meaningful
+	staging, err := os.MkdirTemp(filepath.Dir(outputFile), ".mydumper-")
+	if err != nil {
+		return fmt.Errorf("failed to create mydumper staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	dumpDir := filepath.Join(staging, archiveRoot)
+	defaultsFile, err := database.WriteDefaultsFile(staging, "mydumper", options.User, options.Password)
+	if err != nil {
+		return err
+	}
+
+	args := mydumperArgs(options, defaultsFile, dumpDir)
+	lg.Info("Executing mydumper",
+		logger.String("database", options.DBName),
+		logger.String("output", outputFile),
+		logger.Strings("args", args[1:]))
+
+	startTime := time.Now()
+	cmd := shutdown.Command(ctx, "mydumper", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		lg.Error("mydumper command failed",
+			logger.Error(err),
+			logger.String("database", options.DBName),
+			logger.String("host", options.Host),
+			logger.Int("port", options.Port))
+		return fmt.Errorf("mydumper failed: %w", err)
+	}
+	lg.Info("mydumper completed successfully", logger.String("duration", time.Since(startTime).String()))
+
+	job.SetStep("archiving")
+	return archiveDump(dumpDir, outputFile, options, job)
+}
+
+func mydumperArgs(options backup_utils.BackupOptions, defaultsFile, dumpDir string) []string {
+	threads, rows := options.EngineThreads, defaultRows
+	if cfg, err := config.Get(); err == nil {
+		if threads == 0 {
+			threads = cfg.Backup.Mydumper.Threads
+		}
+		if cfg.Backup.Mydumper.Rows > 0 {
+			rows = cfg.Backup.Mydumper.Rows
+		}
+	}
+	if threads <= 0 {
+		threads = runtime.NumCPU()
+	}
+
+	// --defaults-file must come first
+	args := []string{
+		"--defaults-file=" + defaultsFile,
+		"--host=" + options.Host,
+		"--port=" + strconv.Itoa(options.Port),
+		"--database=" + options.DBName,
+		"--outputdir=" + dumpDir,
+		"--threads=" + strconv.Itoa(threads),
+		"--rows=" + strconv.Itoa(rows),
+		"--triggers",
+		"--events",
+		"--routines",
+	}
+	if !options.IncludeData {
+		args = append(args, "--no-data")
+	}
+	return args
+}
+
+// archiveDump writes the dump directory as a tar stream through compression
+// and encryption into outputFile
+func archiveDump(dumpDir, outputFile string, options backup_utils.BackupOptions, job *jobstatus.Tracker) error {
+	lg, _ := logger.Get()
+
+	total, err := backup_utils.DirSize(dumpDir)
+	if err != nil {
+		return fmt.Errorf("failed to read mydumper output: %w", err)
+	}
+
+	outFile, err := fs.NewManager().File().CreateFile(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outFile.Close()
+
+	stream, err := backup_utils.OpenBackupStream(outFile, options, lg)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	tw := tar.NewWriter(stream)
+
+	err = backup_utils.TarDir(tw, job.Writer(tw, total), dumpDir, archiveRoot)
+	if closeErr := tw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := stream.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to archive mydumper output: %w", err)
+	}
+	return nil
+}
@@ -1,20 +1,30 @@
 package backup_single_mysqldump
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/partition"
 	"sfDBTools/internal/logger"
 	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/database"
 	"sfDBTools/utils/database/info"
 	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
 )
 
 // BackupSingle performs a backup of a single database
-func BackupSingle(options backup_utils.BackupOptions) (*backup_utils.BackupResult, error) {
+func BackupSingle(ctx context.Context, options backup_utils.BackupOptions) (*backup_utils.BackupResult, error) {
+	job := jobstatus.Start("backup-single", options.DBName)
+	result, err := backupSingle(ctx, options, job)
+	job.Finish(err)
+	return result, err
+}
+
+func backupSingle(ctx context.Context, options backup_utils.BackupOptions, job *jobstatus.Tracker) (*backup_utils.BackupResult, error) {
 	lg, err := logger.Get()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get logger: %w", err)
@@ -28,15 +38,14 @@ func BackupSingle(options backup_utils.BackupOptions) (*backup_utils.BackupResul
 
 	manager := fs.NewManager()
 	if !manager.Dir().Exists(options.OutputDir) {
-		if err := manager.Dir().Create(options.OutputDir); err != nil {
+		if err := manager.Dir().CreateArtifactDir(options.OutputDir); err != nil {
 			lg.Error("Failed to create output directory", logger.Error(err))
 			return nil, fmt.Errorf("failed to create output directory: %w", err)
 		}
 	}
 	if err := manager.Dir().IsWritable(options.OutputDir); err != nil {
 		lg.Error("Output directory validation failed", logger.Error(err))
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("output directory validation failed: %w", err)
 	}
 
 	// Clean up old backups based on retention policy
@@ -73,7 +82,8 @@ func BackupSingle(options backup_utils.BackupOptions) (*backup_utils.BackupResul
 	// 	lg.Info("Replication information collected successfully before backup")
 	// }
 
-	dbInfo := info.CollectDatabaseInfo(config, lg)
+	job.SetStep("collecting database info")
+	dbInfo := info.CollectDatabaseInfo(ctx, config, lg)
 
 	outputFile, metaFile, err := backup_utils.SetupBackupPaths(options)
 	if err != nil {
@@ -82,19 +92,80 @@ func BackupSingle(options backup_utils.BackupOptions) (*backup_utils.BackupResul
 	}
 	result.OutputFile, result.BackupMetaFile = outputFile, metaFile
 
-	if err := performBackup(options, outputFile, dbInfo); err != nil {
+	partial, err := recentPartitionFilters(ctx, options, config)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+	result.PartialTables = partial
+
+	job.SetStep("dumping")
+	remote, err := performBackup(ctx, options, outputFile, dbInfo, partial, job)
+	if err != nil {
+		if options.Output == "" {
+			backup_utils.RemovePartialOutput(outputFile)
+		}
 		result.Error = err
 		return result, err
 	}
+	if remote != nil {
+		remote.Apply(result)
+	}
 
+	job.SetStep("finalizing")
 	if err := backup_utils.FinalizeBackupResult(result, outputFile, startTime, options); err != nil {
 		lg.Warn("Failed to finalize backup result", logger.Error(err))
 	}
+	backup_utils.ReportResultDetails(job, result)
 
 	if err := backup_utils.CreateMetadataFile(options, result, config, dbInfo); err != nil {
 		lg.Warn("Failed to create metadata file", logger.Error(err))
+	} else if remote != nil {
+		if err := remote.UploadManifest(options, metaFile); err != nil {
+			lg.Warn("Failed to upload metadata file", logger.Error(err))
+		}
 	}
 
 	// backup_utils.LogBackupCompletion(options, result, lg)
 	return result, nil
 }
+
+// recentPartitionFilters resolves the WHERE clause for each table that should
+// only have its most recent partitions dumped. Tables with no more partitions
+// than requested are left out and dumped whole.
+func recentPartitionFilters(ctx context.Context, options backup_utils.BackupOptions, config database.Config) (map[string]string, error) {
+	if len(options.RecentPartitions) == 0 || !options.IncludeData {
+		return nil, nil
+	}
+	lg, _ := logger.Get()
+
+	db, err := database.GetWithoutDB(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect for partition lookup: %w", err)
+	}
+	defer db.Close()
+
+	filters := make(map[string]string)
+	for table, keep := range options.RecentPartitions {
+		tables, err := partition.List(ctx, db, options.DBName, table)
+		if err != nil {
+			return nil, err
+		}
+		if len(tables) == 0 {
+			return nil, fmt.Errorf("--recent-partitions: table %s.%s is not partitioned", options.DBName, table)
+		}
+		where, err := partition.RecentFilter(tables[0], keep, common.LocalNow())
+		if err != nil {
+			return nil, fmt.Errorf("--recent-partitions: %w", err)
+		}
+		if where == "" {
+			continue
+		}
+		filters[table] = where
+		lg.Info("Dumping only recent partitions",
+			logger.String("table", table),
+			logger.Int("partitions", keep),
+			logger.String("where", where))
+	}
+	return filters, nil
+}
@@ -1,11 +1,12 @@
 package backup_single_mysqldump
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"sfDBTools/internal/config"
@@ -13,82 +14,115 @@ import (
 	backup_utils "sfDBTools/utils/backup"
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/shutdown"
 )
 
 // performBackup performs the actual database backup using mysqldump
-func performBackup(options backup_utils.BackupOptions, outputFile string, dbinfo *info.DatabaseInfo) error {
+// Tables listed in partial are dumped in a second pass limited by their WHERE clause.
+// With options.Output the dump is streamed to object storage; the returned
+// RemoteOutput is then the completed upload.
+func performBackup(ctx context.Context, options backup_utils.BackupOptions, outputFile string, dbinfo *info.DatabaseInfo, partial map[string]string, job *jobstatus.Tracker) (*backup_utils.RemoteOutput, error) {
 	lg, _ := logger.Get()
 
 	if err := backup_utils.ValidateBackupOptions(options); err != nil {
 		lg.Error("Invalid backup options", logger.Error(err))
-		return fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
 	}
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	fsMgr := fs.NewManager()
+	if err := fsMgr.File().EnsureArtifactDir(filepath.Dir(outputFile)); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Build mysqldump command with optimizations
-	args := getOptimizedMysqldumpArgs(options)
+	// Build mysqldump commands with optimizations
+	runs := dumpPasses(getOptimizedMysqldumpArgs(options), options.DBName, partial)
 
 	lg.Info("Executing mysqldump",
 		logger.String("output", outputFile),
 		logger.Bool("is_remote", common.IsRemoteConnection(options.Host)))
 
-	// Create output file
-	outFile, err := os.Create(outputFile)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+	// Create the output file, or start the upload when streaming to object storage
+	var storage io.Writer
+	var remote *backup_utils.RemoteOutput
+	if options.Output != "" {
+		var err error
+		if remote, err = backup_utils.OpenRemoteOutput(options, outputFile); err != nil {
+			return nil, err
+		}
+		storage = remote
+	} else {
+		outFile, err := fsMgr.File().CreateFile(outputFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer outFile.Close()
+		storage = outFile
 	}
-	defer outFile.Close()
 
-	// Set up writer chain: compression -> encryption -> file
-	var writer io.WriteCloser
-	var closers []io.Closer
-
-	writer, closers, err = backup_utils.BuildWriterChain(outFile, options, lg)
+	// Set up the write path: dump -> compression -> encryption -> storage
+	stream, err := backup_utils.OpenBackupStream(storage, options, lg)
 	if err != nil {
-		lg.Error("Failed to set up writer chain", logger.Error(err))
-		return err
+		if remote != nil {
+			remote.Abort()
+		}
+		return nil, err
 	}
+	defer stream.Close()
 
-	// Execute mysqldump command
-	cmd := exec.Command("mysqldump", args...)
-	cmd.Stdout = writer
-	cmd.Stderr = os.Stderr // Capture stderr for error diagnostics
-
-	// Set environment variable for password
-	if options.Password != "" {
-		cmd.Env = append(os.Environ(), fmt.Sprintf("MYSQL_PWD=%s", options.Password))
+	// Job progress is measured against the database size, so the percentage is an estimate
+	var estimated int64
+	if dbinfo != nil {
+		estimated = dbinfo.SizeBytes
 	}
+	out := job.Writer(stream, estimated)
 
 	// Start the command execution
 	startTime := time.Now()
 
-	err = cmd.Run()
+	for _, args := range runs {
+		cmd := shutdown.Command(ctx, "mysqldump", args...)
+		cmd.Stdout = out
+		cmd.Stderr = os.Stderr // Capture stderr for error diagnostics
 
-	if err != nil {
-		lg.Error("mysqldump command failed",
-			logger.Error(err),
-			logger.String("database", options.DBName),
-			logger.String("host", options.Host),
-			logger.Int("port", options.Port),
-			logger.String("user", options.User))
-		return fmt.Errorf("mysqldump failed: %w", err)
+		// Set environment variable for password
+		if options.Password != "" {
+			cmd.Env = append(os.Environ(), fmt.Sprintf("MYSQL_PWD=%s", options.Password))
+		}
+
+		if err := cmd.Run(); err != nil {
+			lg.Error("mysqldump command failed",
+				logger.Error(err),
+				logger.String("database", options.DBName),
+				logger.String("host", options.Host),
+				logger.Int("port", options.Port),
+				logger.String("user", options.User))
+			if remote != nil {
+				remote.Abort()
+			}
+			return nil, fmt.Errorf("mysqldump failed: %w", err)
+		}
 	}
 
 	duration := time.Since(startTime)
 	lg.Info("mysqldump completed successfully",
 		logger.String("duration", duration.String()))
 
-	// Close writers in reverse order (inner to outer)
-	for i := len(closers) - 1; i >= 0; i-- {
-		if err := closers[i].Close(); err != nil {
-			lg.Warn("Failed to close writer", logger.Error(err))
-			return fmt.Errorf("failed to close writer: %w", err)
+	if err := stream.Close(); err != nil {
+		lg.Warn("Failed to close writer", logger.Error(err))
+		if remote != nil {
+			remote.Abort()
 		}
+		return nil, fmt.Errorf("failed to close writer: %w", err)
+	}
+	if remote != nil {
+		if err := remote.Close(); err != nil {
+			return nil, err
+		}
+		lg.Info("Backup uploaded", logger.String("url", remote.URL()))
 	}
 
-	return nil
+	return remote, nil
 }
 
 func getOptimizedMysqldumpArgs(options backup_utils.BackupOptions) []string {
@@ -105,9 +139,38 @@ func getOptimizedMysqldumpArgs(options backup_utils.BackupOptions) []string {
 		fmt.Sprintf("--user=%s", options.User),
 	}
 	args = append(args, common.ParseArgsString(cfg.Mysqldump.Args)...)
+	args = append(args, options.DumpArgs...)
 	if !options.IncludeData {
 		args = append(common.RemoveDataFlags(args), "--no-data")
 	}
 	args = append(args, options.DBName)
 	return args
 }
+
+// dumpPasses splits a dump into a pass for the whole database without the
+// partially dumped tables, followed by one filtered pass per such table.
+// Routines and events are only written by the first pass.
+func dumpPasses(args []string, dbName string, partial map[string]string) [][]string {
+	if len(partial) == 0 {
+		return [][]string{args}
+	}
+	base := args[:len(args)-1] // drop the trailing database name
+
+	tables := make([]string, 0, len(partial))
+	for table := range partial {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	first := append([]string{}, base...)
+	for _, table := range tables {
+		first = append(first, fmt.Sprintf("--ignore-table=%s.%s", dbName, table))
+	}
+	runs := [][]string{append(first, dbName)}
+	for _, table := range tables {
+		pass := append([]string{}, base...)
+		pass = append(pass, "--skip-routines", "--skip-events", "--where="+partial[table], dbName, table)
+		runs = append(runs, pass)
+	}
+	return runs
+}
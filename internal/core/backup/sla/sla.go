@@ -0,0 +1,348 @@
+package sla
+
+import (
+	"fmt"
+	iofs "io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config/model"
+	"sfDBTools/internal/core/backup/schedule"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/jobstatus"
+)
+
+// AllDatabases is the row of backups taken with 'backup all'
+const AllDatabases = "all_databases"
+
+// Snapshot is the row of physical backups taken with 'backup snapshot'
+const Snapshot = "snapshot"
+
+// Physical is the row of backups taken with 'backup physical'
+const Physical = "physical"
+
+// earlyTolerance lets a run that started slightly before its scheduled time
+// (clock skew, a manual run ahead of the timer) count for that slot
+const earlyTolerance = 5 * time.Minute
+
+// Options controls which backups are evaluated and against which schedules
+type Options struct {
+	BaseDir   string // backup directory holding the manifests
+	Window    time.Duration
+	Grace     time.Duration // how long after its scheduled time a backup may finish and still be on time
+	Schedules []model.BackupSchedule
+	Timezone  string // default timezone of schedules that do not set one
+	Now       time.Time
+}
+
+// Row is the SLA of one database, consistency group or all-databases backup
+type Row struct {
+	Database  string   `json:"database"`
+	Schedules []string `json:"schedules,omitempty"`
+	Expected  int      `json:"expected"`
+	OnTime    int      `json:"on_time"`
+	Late      int      `json:"late"`
+	Missed    int      `json:"missed"`
+	Failed    int      `json:"failed"`
+	Runs      int      `json:"successful_runs"`
+	// SuccessPercent is OnTime/Expected; nil when no schedule covers the database
+	SuccessPercent *float64 `json:"success_percent"`
+	MeanDuration   float64  `json:"mean_duration_seconds"`
+	// DurationTrend is the change of the mean duration in the second half of
+	// the window against the first half, in percent
+	DurationTrend *float64 `json:"duration_trend_percent,omitempty"`
+	// LargestDeviation is the run duration furthest from the mean, in seconds
+	// (negative when faster), and when that backup was taken
+	LargestDeviation   float64    `json:"largest_deviation_seconds"`
+	LargestDeviationAt *time.Time `json:"largest_deviation_at,omitempty"`
+
+	kind  string // single, all_databases or consistency_group
+	runs  []run
+	slots []slot
+}
+
+// Report is the result of Compute
+type Report struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Window   string    `json:"window"`
+	Grace    string    `json:"grace"`
+	Rows     []*Row    `json:"databases"`
+	Warnings []string  `json:"warnings,omitempty"`
+}
+
+// run is a successful backup found in the catalog
+type run struct {
+	start, end time.Time
+	duration   time.Duration
+}
+
+// slot is a time a backup was due, with the grace of its schedule
+type slot struct {
+	at    time.Time
+	grace time.Duration
+}
+
+// Compute evaluates the backups of the last Window: successful runs come from
+// the manifests under BaseDir, failures from the job status files, and the
+// expected runs from the configured schedules.
+func Compute(opts Options) (*Report, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	from := opts.Now.Add(-opts.Window)
+	report := &Report{
+		From:   from,
+		To:     opts.Now,
+		Window: opts.Window.String(),
+		Grace:  opts.Grace.String(),
+	}
+	rows := make(map[string]*Row)
+	row := func(name, kind string) *Row {
+		r, ok := rows[name]
+		if !ok {
+			r = &Row{Database: name, kind: kind}
+			rows[name] = r
+		}
+		return r
+	}
+
+	err := filepath.WalkDir(opts.BaseDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		meta, ok := backup_utils.ReadBackupManifest(path)
+		if !ok || meta.BackupDate.Before(from) || meta.BackupDate.After(opts.Now) {
+			return nil
+		}
+		name := meta.DatabaseName
+		if meta.BackupType == AllDatabases {
+			name = AllDatabases
+		}
+		duration, _ := time.ParseDuration(meta.Duration)
+		r := row(name, meta.BackupType)
+		r.runs = append(r.runs, run{start: meta.BackupDate.Add(-duration), end: meta.BackupDate, duration: duration})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup catalog %s: %w", opts.BaseDir, err)
+	}
+
+	jobs, err := jobstatus.List()
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed runs not counted: %v", err))
+	}
+	for _, job := range jobs {
+		if job.State != jobstatus.StateFailed && job.State != jobstatus.StateStale || job.StartedAt.Before(from) {
+			continue
+		}
+		switch job.Operation {
+		case "backup-single":
+			row(job.Target, "single").Failed++
+		case "backup-all":
+			row(AllDatabases, AllDatabases).Failed++
+		case "backup-group":
+			row(job.Target, "consistency_group").Failed++
+		case "backup-snapshot":
+			row(Snapshot, Snapshot).Failed++
+		}
+	}
+
+	for _, s := range opts.Schedules {
+		if !s.Enabled {
+			continue
+		}
+		target, ok := scheduleTarget(s)
+		if !ok {
+			continue
+		}
+		timezone, err := schedule.ScheduleTimezone(s, opts.Timezone)
+		if err != nil {
+			report.Warnings = append(report.Warnings, err.Error())
+			continue
+		}
+		times, err := schedule.Occurrences(s, timezone, from, opts.Now)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%v; schedule not evaluated", err))
+			continue
+		}
+		grace := opts.Grace
+		if delay, err := common.ParseDurationWithDays(s.RandomizedDelay); err == nil {
+			grace += delay
+		}
+
+		var covered []*Row
+		if target == "" {
+			// selection without --source_db backs up a list: it covers every single-database backup
+			for _, r := range rows {
+				if r.kind == "single" {
+					covered = append(covered, r)
+				}
+			}
+		} else {
+			covered = append(covered, row(target, targetKind(s.Command)))
+		}
+		for _, r := range covered {
+			r.Schedules = append(r.Schedules, s.Name)
+			for _, t := range times {
+				r.slots = append(r.slots, slot{at: t, grace: grace})
+			}
+		}
+	}
+
+	for _, r := range rows {
+		r.evaluate(opts.Now)
+		r.durationStats(from.Add(opts.Window / 2))
+		report.Rows = append(report.Rows, r)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool { return report.Rows[i].Database < report.Rows[j].Database })
+	return report, nil
+}
+
+// evaluate matches each due slot with the first successful run that started
+// in it: on time when it finished within the grace, late otherwise, missed
+// when there was none. Slots whose grace has not passed yet are not counted.
+func (r *Row) evaluate(now time.Time) {
+	sort.Slice(r.slots, func(i, j int) bool { return r.slots[i].at.Before(r.slots[j].at) })
+	sort.Slice(r.runs, func(i, j int) bool { return r.runs[i].start.Before(r.runs[j].start) })
+	r.Runs = len(r.runs)
+
+	used := make([]bool, len(r.runs))
+	for i, s := range r.slots {
+		if i > 0 && s.at.Equal(r.slots[i-1].at) {
+			continue // two schedules due at the same time expect one backup
+		}
+		next := now
+		for _, later := range r.slots[i+1:] {
+			if later.at.After(s.at) {
+				next = later.at
+				break
+			}
+		}
+
+		matched := -1
+		for j, rn := range r.runs {
+			if !used[j] && !rn.start.Before(s.at.Add(-earlyTolerance)) && rn.start.Before(next.Add(-earlyTolerance)) {
+				matched = j
+				break
+			}
+		}
+		switch {
+		case matched >= 0:
+			used[matched] = true
+			r.Expected++
+			if r.runs[matched].end.After(s.at.Add(s.grace)) {
+				r.Late++
+			} else {
+				r.OnTime++
+			}
+		case s.at.Add(s.grace).After(now):
+			// still running or about to start
+		default:
+			r.Expected++
+			r.Missed++
+		}
+	}
+	if len(r.Schedules) > 0 && r.Expected > 0 {
+		pct := float64(r.OnTime) / float64(r.Expected) * 100
+		r.SuccessPercent = &pct
+	}
+}
+
+// durationStats computes the mean duration, its trend between the halves of
+// the window split at mid, and the run that deviated most from the mean
+func (r *Row) durationStats(mid time.Time) {
+	if len(r.runs) == 0 {
+		return
+	}
+	var total, firstTotal, secondTotal time.Duration
+	var first, second int
+	for _, rn := range r.runs {
+		total += rn.duration
+		if rn.start.Before(mid) {
+			firstTotal += rn.duration
+			first++
+		} else {
+			secondTotal += rn.duration
+			second++
+		}
+	}
+	mean := total.Seconds() / float64(len(r.runs))
+	r.MeanDuration = mean
+
+	if first > 0 && second > 0 && firstTotal > 0 {
+		firstMean := firstTotal.Seconds() / float64(first)
+		trend := (secondTotal.Seconds()/float64(second) - firstMean) / firstMean * 100
+		r.DurationTrend = &trend
+	}
+
+	for _, rn := range r.runs {
+		deviation := rn.duration.Seconds() - mean
+		if r.LargestDeviationAt == nil || abs(deviation) > abs(r.LargestDeviation) {
+			at := rn.end
+			r.LargestDeviation = deviation
+			r.LargestDeviationAt = &at
+		}
+	}
+}
+
+// scheduleTarget returns the row a schedule backs up: the database of a
+// selection with --source_db, the group of a group backup, all_databases for
+// 'all', snapshot for 'snapshot', physical for 'physical', and "" for a selection from a list. ok is false for schedules that
+// do not produce database backups or whose target is unknown.
+func scheduleTarget(s model.BackupSchedule) (string, bool) {
+	switch s.Command {
+	case "all":
+		return AllDatabases, true
+	case "snapshot":
+		return Snapshot, true
+	case "physical":
+		return Physical, true
+	case "group":
+		group := argValue(s.Args, "--group")
+		return group, group != ""
+	case "selection":
+		return argValue(s.Args, "--source_db"), true
+	}
+	return "", false
+}
+
+func targetKind(command string) string {
+	switch command {
+	case "all":
+		return AllDatabases
+	case "group":
+		return "consistency_group"
+	case "snapshot":
+		return Snapshot
+	case "physical":
+		return Physical
+	}
+	return "single"
+}
+
+// argValue returns the value of --name given as "--name value" or "--name=value"
+func argValue(args []string, name string) string {
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, name+"="); ok {
+			return value
+		}
+		if arg == name && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
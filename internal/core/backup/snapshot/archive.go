@@ -0,0 +1,51 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"fmt"
+
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+)
+
+// archiveRoot is the directory the datadir is stored under inside the archive
+const archiveRoot = "datadir"
+
+// archiveDir writes the files under root as a tar stream through the backup
+// writer chain (compression, encryption) into outputFile. Sockets, pipes and
+// devices are skipped.
+func archiveDir(root, outputFile string, options backup_utils.BackupOptions, job *jobstatus.Tracker) error {
+	lg, _ := logger.Get()
+
+	total, err := backup_utils.DirSize(root)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	outFile, err := fs.NewManager().File().CreateFile(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outFile.Close()
+
+	stream, err := backup_utils.OpenBackupStream(outFile, options, lg)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	tw := tar.NewWriter(stream)
+
+	err = backup_utils.TarDir(tw, job.Writer(tw, total), root, archiveRoot)
+	if closeErr := tw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := stream.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to archive snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sfDBTools/utils/system"
+)
+
+// Filesystems supported for snapshot backups
+const (
+	FilesystemLVM = "lvm"
+	FilesystemZFS = "zfs"
+)
+
+// volume is the filesystem a path lives on, as reported by findmnt
+type volume struct {
+	Source     string // block device or ZFS dataset
+	FSType     string
+	MountPoint string
+}
+
+// provider takes, exposes and removes a snapshot of one volume
+type provider interface {
+	// Filesystem returns FilesystemLVM or FilesystemZFS
+	Filesystem() string
+	// Volume names the logical volume or dataset being snapshotted
+	Volume() string
+	// Create takes the snapshot; it runs while the server is locked, so it
+	// must not do anything slow
+	Create(name string) error
+	// Mount makes the snapshot readable and returns where the volume's root is
+	Mount() (string, error)
+	// Verify reports whether the snapshot is still intact after archiving
+	Verify() error
+	// Remove unmounts and deletes the snapshot
+	Remove() error
+}
+
+// findVolume returns the mounted filesystem holding path
+func findVolume(path string) (volume, error) {
+	out, err := system.NewProcessManager().ExecuteWithOutput("findmnt", []string{"-n", "-o", "SOURCE,FSTYPE,TARGET", "-T", path})
+	if err != nil {
+		return volume{}, fmt.Errorf("failed to find the filesystem of %s: %w", path, err)
+	}
+	fields := strings.Fields(strings.TrimSpace(out))
+	if len(fields) < 3 {
+		return volume{}, fmt.Errorf("unexpected findmnt output for %s: %q", path, strings.TrimSpace(out))
+	}
+	return volume{Source: fields[0], FSType: fields[1], MountPoint: fields[2]}, nil
+}
+
+// detectProvider picks LVM or ZFS for the volume, or explains why neither applies
+func detectProvider(v volume, lvmSize string) (provider, error) {
+	if v.FSType == "zfs" {
+		return &zfsProvider{dataset: v.Source, mountPoint: v.MountPoint}, nil
+	}
+
+	out, err := system.NewProcessManager().ExecuteWithOutput("lvs", []string{"--noheadings", "-o", "vg_name,lv_name", v.Source})
+	if err != nil {
+		return nil, fmt.Errorf("%s (%s on %s) is neither an LVM logical volume nor a ZFS dataset", v.MountPoint, v.FSType, v.Source)
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("unexpected lvs output for %s: %q", v.Source, strings.TrimSpace(out))
+	}
+	return &lvmProvider{vg: fields[0], lv: fields[1], fsType: v.FSType, size: lvmSize}, nil
+}
+
+// lvmProvider snapshots an LVM logical volume and mounts it read-only
+type lvmProvider struct {
+	vg, lv   string
+	fsType   string
+	size     string // copy-on-write space reserved for the snapshot
+	name     string
+	mountDir string
+}
+
+func (p *lvmProvider) Filesystem() string { return FilesystemLVM }
+
+func (p *lvmProvider) Volume() string { return p.vg + "/" + p.lv }
+
+func (p *lvmProvider) device() string { return "/dev/" + p.vg + "/" + p.name }
+
+func (p *lvmProvider) Create(name string) error {
+	p.name = name
+	return system.NewProcessManager().Execute("lvcreate", []string{
+		"--snapshot", "--size", p.size, "--name", name, "/dev/" + p.vg + "/" + p.lv,
+	})
+}
+
+func (p *lvmProvider) Mount() (string, error) {
+	dir, err := os.MkdirTemp("", "sfdbtools-snapshot-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot mount point: %w", err)
+	}
+	options := "ro"
+	if p.fsType == "xfs" {
+		// The snapshot carries the UUID of the mounted origin
+		options += ",nouuid"
+	}
+	if err := system.NewProcessManager().Execute("mount", []string{"-o", options, p.device(), dir}); err != nil {
+		os.Remove(dir)
+		return "", err
+	}
+	p.mountDir = dir
+	return dir, nil
+}
+
+// Verify fails when the snapshot ran out of copy-on-write space while it was
+// being archived; its contents are unusable then
+func (p *lvmProvider) Verify() error {
+	out, err := system.NewProcessManager().ExecuteWithOutput("lvs", []string{"--noheadings", "-o", "lv_attr", p.device()})
+	if err != nil {
+		return err
+	}
+	if attr := strings.TrimSpace(out); len(attr) > 4 && attr[4] == 'I' {
+		return fmt.Errorf("LVM snapshot %s overflowed its %s copy-on-write space; retry with a larger --snapshot-size", p.device(), p.size)
+	}
+	return nil
+}
+
+func (p *lvmProvider) Remove() error {
+	pm := system.NewProcessManager()
+	if p.mountDir != "" {
+		if err := pm.Execute("umount", []string{p.mountDir}); err != nil {
+			return err
+		}
+		os.Remove(p.mountDir)
+		p.mountDir = ""
+	}
+	if p.name == "" {
+		return nil
+	}
+	return pm.Execute("lvremove", []string{"-f", p.device()})
+}
+
+// zfsProvider snapshots a ZFS dataset, read through its .zfs directory
+type zfsProvider struct {
+	dataset    string
+	mountPoint string
+	name       string
+}
+
+func (p *zfsProvider) Filesystem() string { return FilesystemZFS }
+
+func (p *zfsProvider) Volume() string { return p.dataset }
+
+func (p *zfsProvider) Create(name string) error {
+	p.name = name
+	return system.NewProcessManager().Execute("zfs", []string{"snapshot", p.dataset + "@" + name})
+}
+
+func (p *zfsProvider) Mount() (string, error) {
+	dir := filepath.Join(p.mountPoint, ".zfs", "snapshot", p.name)
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("snapshot %s@%s is not readable: %w", p.dataset, p.name, err)
+	}
+	return dir, nil
+}
+
+func (p *zfsProvider) Verify() error { return nil }
+
+func (p *zfsProvider) Remove() error {
+	if p.name == "" {
+		return nil
+	}
+	return system.NewProcessManager().Execute("zfs", []string{"destroy", p.dataset + "@" + p.name})
+}
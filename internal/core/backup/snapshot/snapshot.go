@@ -0,0 +1,286 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/compression"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/schema"
+)
+
+// Options configures a snapshot backup
+type Options struct {
+	backup_utils.BackupOptions
+	DataDir      string        // datadir on this host; queried from the server when empty
+	LockMode     string        // auto, backup-stage or ftwrl
+	LockTimeout  time.Duration // how long acquiring the lock may wait for running statements
+	SnapshotSize string        // copy-on-write space of an LVM snapshot, e.g. 10G
+}
+
+// Result describes a finished snapshot backup
+type Result struct {
+	backup_utils.BackupResult
+	Snapshot     backup_utils.SnapshotMeta `json:"snapshot"`
+	LockDuration time.Duration             `json:"lock_duration"`
+}
+
+// Backup snapshots the volume holding the datadir under a short server lock
+// and archives the snapshot after the lock has been released
+func Backup(options Options) (*Result, error) {
+	job := jobstatus.Start("backup-snapshot", options.Host)
+	result, err := backup(options, job)
+	job.Finish(err)
+	return result, err
+}
+
+func backup(options Options, job *jobstatus.Tracker) (*Result, error) {
+	lg, err := logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logger: %w", err)
+	}
+	if os.Geteuid() != 0 {
+		return nil, fmt.Errorf("snapshot backups manage LVM/ZFS snapshots and must run as root")
+	}
+	if err := ValidateLockMode(options.LockMode); err != nil {
+		return nil, err
+	}
+
+	dbConfig := database.Config{
+		Host:     options.Host,
+		Port:     options.Port,
+		User:     options.User,
+		Password: options.Password,
+	}
+	db, err := database.GetWithoutDB(dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	job.SetStep("locating datadir")
+	var version string
+	if err := db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		return nil, fmt.Errorf("failed to read server version: %w", err)
+	}
+	dataDir, err := resolveDataDir(db, options.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	vol, err := findVolume(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSameVolume(db, dataDir, vol); err != nil {
+		return nil, err
+	}
+	prov, err := detectProvider(vol, options.SnapshotSize)
+	if err != nil {
+		return nil, err
+	}
+	lockMode := resolveLockMode(options.LockMode, version)
+	lg.Info("Snapshot backup plan",
+		logger.String("datadir", dataDir),
+		logger.String("filesystem", prov.Filesystem()),
+		logger.String("volume", prov.Volume()),
+		logger.String("lock_mode", lockMode))
+
+	if removed, err := backup_utils.CleanupOldBackups(options.OutputDir, options.RetentionDays); err != nil {
+		lg.Warn("Failed to cleanup old backups", logger.Error(err))
+	} else if len(removed) > 0 {
+		lg.Info("Old backup directories removed", logger.Strings("dirs", removed), logger.Int("count", len(removed)))
+	}
+
+	startTime := time.Now()
+	result := &Result{BackupResult: *backup_utils.InitializeBackupResult(options.BackupOptions)}
+	result.IncludedData = true
+	outputFile, metaFile := outputPaths(options.BackupOptions)
+	result.OutputFile, result.BackupMetaFile = outputFile, metaFile
+	if err := fs.NewManager().File().EnsureArtifactDir(filepath.Dir(outputFile)); err != nil {
+		return result, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	name := "sfdbtools_" + common.LocalNow().Format("20060102_150405")
+	job.SetStep("snapshotting")
+	replicationInfo, lockDuration, err := takeSnapshot(db, dbConfig, prov, name, lockMode, options.LockTimeout)
+	if err != nil {
+		return result, err
+	}
+	result.LockDuration = lockDuration
+	job.Detail("Lock held", lockDuration.Round(time.Millisecond).String())
+	lg.Info("Snapshot taken, server unlocked",
+		logger.String("snapshot", name),
+		logger.String("lock_duration", lockDuration.String()))
+	defer func() {
+		if err := prov.Remove(); err != nil {
+			job.Warn(fmt.Sprintf("Failed to remove snapshot %s of %s: %v", name, prov.Volume(), err))
+		}
+	}()
+
+	// The server is serving writes again from here on; archiving reads the
+	// frozen snapshot for as long as it takes
+	job.SetStep("archiving")
+	root, err := prov.Mount()
+	if err != nil {
+		return result, fmt.Errorf("failed to mount snapshot: %w", err)
+	}
+	rel, err := filepath.Rel(vol.MountPoint, dataDir)
+	if err != nil {
+		return result, err
+	}
+	if err := archiveDir(filepath.Join(root, rel), outputFile, options.BackupOptions, job); err != nil {
+		backup_utils.RemovePartialOutput(outputFile)
+		return result, err
+	}
+	if err := prov.Verify(); err != nil {
+		return result, err
+	}
+
+	job.SetStep("finalizing")
+	if err := backup_utils.FinalizeBackupResult(&result.BackupResult, outputFile, startTime, options.BackupOptions); err != nil {
+		lg.Warn("Failed to finalize backup result", logger.Error(err))
+	}
+	result.Snapshot = backup_utils.SnapshotMeta{
+		Filesystem:   prov.Filesystem(),
+		Volume:       prov.Volume(),
+		Name:         name,
+		DataDir:      dataDir,
+		LockMode:     lockMode,
+		LockDuration: lockDuration.String(),
+	}
+	backup_utils.ReportResultDetails(job, &result.BackupResult)
+	job.Detail("Snapshot", fmt.Sprintf("%s %s@%s", prov.Filesystem(), prov.Volume(), name))
+
+	metadata := &backup_utils.BackupMetadata{
+		SchemaVersion:     schema.ManifestVersion,
+		DatabaseName:      "snapshot",
+		BackupDate:        time.Now().UTC(),
+		BackupType:        "snapshot",
+		OutputFile:        outputFile,
+		FileSize:          result.OutputSize,
+		Compressed:        options.Compress,
+		CompressionType:   result.CompressionUsed,
+		Encrypted:         result.Encrypted,
+		IncludesData:      true,
+		Duration:          result.Duration.String(),
+		Checksum:          result.Checksum,
+		ChecksumAlgorithm: result.ChecksumAlgorithm,
+		Host:              options.Host,
+		Port:              options.Port,
+		User:              options.User,
+		MySQLVersion:      version,
+		ReplicationInfo:   backup_utils.CreateReplicationMetadata(replicationInfo),
+		Snapshot:          &result.Snapshot,
+	}
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err == nil {
+		err = fs.NewManager().File().WriteFile(metaFile, data)
+	}
+	if err != nil {
+		lg.Warn("Failed to save snapshot backup metadata", logger.Error(err))
+	} else {
+		backup_utils.RecordInCatalog(metaFile, metadata, options.RetentionDays)
+		backup_utils.PruneAfterBackup()
+	}
+
+	result.Success = true
+	return result, nil
+}
+
+// takeSnapshot locks the server, records the binlog position the snapshot
+// corresponds to, takes the snapshot and unlocks. It returns how long the
+// server was locked.
+func takeSnapshot(db *sql.DB, dbConfig database.Config, prov provider, name, lockMode string, lockTimeout time.Duration) (*database.ReplicationInfo, time.Duration, error) {
+	lg, _ := logger.Get()
+	ctx := context.Background()
+
+	lock, err := acquireLock(ctx, db, lockMode, lockTimeout)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to lock the server: %w", err)
+	}
+	locked := time.Now()
+
+	// Writes are blocked, so the position read now is the snapshot's
+	replicationInfo, err := backup_utils.GetReplicationInfoForBackup(dbConfig)
+	if err != nil {
+		lg.Warn("Snapshot taken without replication position", logger.Error(err))
+	}
+	createErr := prov.Create(name)
+
+	releaseErr := lock.release()
+	held := time.Since(locked)
+	if createErr != nil {
+		return nil, held, fmt.Errorf("failed to create %s snapshot of %s: %w", prov.Filesystem(), prov.Volume(), createErr)
+	}
+	if releaseErr != nil {
+		// The session is closed either way, which releases the lock
+		lg.Warn("Failed to release server lock cleanly", logger.Error(releaseErr))
+	}
+	return replicationInfo, held, nil
+}
+
+// resolveDataDir returns the datadir from --datadir or the server, and checks
+// that it exists on this host: the snapshot is taken locally
+func resolveDataDir(db *sql.DB, dataDir string) (string, error) {
+	if dataDir == "" {
+		if err := db.QueryRow("SELECT @@datadir").Scan(&dataDir); err != nil {
+			return "", fmt.Errorf("failed to read datadir: %w", err)
+		}
+	}
+	dataDir = filepath.Clean(dataDir)
+	if info, err := os.Stat(dataDir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("datadir %s is not a directory on this host; snapshot backups must run on the database server", dataDir)
+	}
+	return dataDir, nil
+}
+
+// checkSameVolume fails when InnoDB keeps its redo log or system tablespace
+// on another volume: a snapshot of the datadir alone would not be consistent
+func checkSameVolume(db *sql.DB, dataDir string, vol volume) error {
+	for _, variable := range []string{"innodb_log_group_home_dir", "innodb_data_home_dir"} {
+		var dir string
+		if err := db.QueryRow("SELECT COALESCE(@@" + variable + ", '')").Scan(&dir); err != nil || dir == "" || !filepath.IsAbs(dir) {
+			continue
+		}
+		other, err := findVolume(dir)
+		if err != nil {
+			return err
+		}
+		if other.Source != vol.Source {
+			return fmt.Errorf("%s (%s) is on %s, not on %s with the datadir %s; a single snapshot would not be consistent",
+				variable, dir, other.Source, vol.Source, dataDir)
+		}
+	}
+	return nil
+}
+
+// outputPaths returns outputDir/YYYY_MM_DD/snapshot/snapshot_<time>.tar[.gz|.zst][.enc]
+// and its metadata file
+func outputPaths(options backup_utils.BackupOptions) (string, string) {
+	now := common.LocalNow()
+	outputDir := filepath.Join(options.OutputDir, now.Format("2006_01_02"), "snapshot")
+	base := "snapshot_" + now.Format("20060102_150405")
+
+	filename := base + ".tar"
+	if options.Compress {
+		if compressionType, err := compression.ValidateCompressionType(options.Compression); err == nil {
+			filename += compression.GetFileExtension(compressionType)
+		} else {
+			filename += compression.GetFileExtension(compression.CompressionGzip)
+		}
+	}
+	if options.Encrypt {
+		filename += ".enc"
+	}
+	return filepath.Join(outputDir, filename), filepath.Join(outputDir, base+".meta.json")
+}
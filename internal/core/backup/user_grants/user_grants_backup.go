@@ -1,16 +1,20 @@
 package user_grants_backup
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"sfDBTools/internal/logger"
 	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/shutdown"
 )
 
 // UserGrantsBackupResult contains the result of user grants backup
@@ -25,7 +29,7 @@ type UserGrantsBackupResult struct {
 }
 
 // BackupUserGrants backs up all user grants to a separate file using SHOW GRANTS method
-func BackupUserGrants(options backup_utils.BackupOptions) (*UserGrantsBackupResult, error) {
+func BackupUserGrants(ctx context.Context, options backup_utils.BackupOptions) (*UserGrantsBackupResult, error) {
 	lg, _ := logger.Get()
 
 	startTime := time.Now()
@@ -36,13 +40,13 @@ func BackupUserGrants(options backup_utils.BackupOptions) (*UserGrantsBackupResu
 		logger.String("output_dir", options.OutputDir))
 
 	// Generate output filename for user grants
-	timestamp := time.Now().Format("20060102_150405")
+	timestamp := common.LocalNow().Format("20060102_150405")
 	baseFilename := fmt.Sprintf("user_grants_%s_%d_%s", options.Host, options.Port, timestamp)
 
 	// Add compression extension if needed
 	if options.Compress {
 		switch options.Compression {
-		case "gzip", "pgzip":
+		case "gzip", "pgzip", "pigz":
 			baseFilename += ".sql.gz"
 		case "zlib":
 			baseFilename += ".sql.zlib"
@@ -63,34 +67,24 @@ func BackupUserGrants(options backup_utils.BackupOptions) (*UserGrantsBackupResu
 	outputFile := filepath.Join(options.OutputDir, "user_grants", baseFilename)
 
 	// Create output directory
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+	fsMgr := fs.NewManager()
+	if err := fsMgr.File().EnsureArtifactDir(filepath.Dir(outputFile)); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Create output file
-	outFile, err := os.Create(outputFile)
+	outFile, err := fsMgr.File().CreateFile(outputFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create output file: %w", err)
 	}
 	defer outFile.Close()
 
-	// Set up writer chain: compression -> encryption -> file
-	var writer io.WriteCloser
-	var closers []io.Closer
-
-	writer, closers, err = backup_utils.BuildWriterChain(outFile, options, lg)
+	// Set up the write path: grants -> compression -> encryption -> file
+	writer, err := backup_utils.OpenBackupStream(outFile, options, lg)
 	if err != nil {
-		lg.Error("Failed to set up writer chain", logger.Error(err))
 		return nil, err
 	}
-	defer func() {
-		// Close writers in reverse order (inner to outer)
-		for i := len(closers) - 1; i >= 0; i-- {
-			if err := closers[i].Close(); err != nil {
-				lg.Warn("Failed to close writer", logger.Error(err))
-			}
-		}
-	}()
+	defer writer.Close()
 
 	// Write backup header
 	header := fmt.Sprintf(`-- ================================
@@ -109,16 +103,14 @@ func BackupUserGrants(options backup_utils.BackupOptions) (*UserGrantsBackupResu
 	}
 
 	// Execute user grants backup
-	totalUsers, err := executeUserGrantsBackup(options, writer)
+	totalUsers, err := executeUserGrantsBackup(ctx, options, writer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to backup user grants: %w", err)
 	}
 
-	// Close all writers to ensure data is flushed
-	for i := len(closers) - 1; i >= 0; i-- {
-		if err := closers[i].Close(); err != nil {
-			lg.Warn("Failed to close writer", logger.Error(err))
-		}
+	// Close the stream to ensure data is flushed
+	if err := writer.Close(); err != nil {
+		lg.Warn("Failed to close writer", logger.Error(err))
 	}
 
 	// Get file size
@@ -149,7 +141,7 @@ func BackupUserGrants(options backup_utils.BackupOptions) (*UserGrantsBackupResu
 }
 
 // executeUserGrantsBackup executes the actual user grants backup using SHOW GRANTS method
-func executeUserGrantsBackup(options backup_utils.BackupOptions, writer io.Writer) (int, error) {
+func executeUserGrantsBackup(ctx context.Context, options backup_utils.BackupOptions, writer io.Writer) (int, error) {
 	lg, _ := logger.Get()
 
 	lg.Info("Executing SHOW GRANTS method for user backup")
@@ -158,7 +150,7 @@ func executeUserGrantsBackup(options backup_utils.BackupOptions, writer io.Write
 	getUsersQuery := "SELECT CONCAT('SHOW GRANTS FOR ''',user,'''@''',host,''';') FROM mysql.user WHERE user<>''"
 
 	// Build mysql command for getting users
-	getUsersCmd := exec.Command("mysql",
+	getUsersCmd := shutdown.Command(ctx, "mysql",
 		fmt.Sprintf("--host=%s", options.Host),
 		fmt.Sprintf("--port=%d", options.Port),
 		fmt.Sprintf("--user=%s", options.User),
@@ -172,7 +164,10 @@ func executeUserGrantsBackup(options backup_utils.BackupOptions, writer io.Write
 	}
 
 	// Get the output of the first command
+	endpoint := fmt.Sprintf("mysql-client(%s:%d)", options.Host, options.Port)
+	start := time.Now()
 	showGrantsStatements, err := getUsersCmd.Output()
+	database.TraceStatement(endpoint, getUsersQuery, time.Since(start), -1, err)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get users list: %w", err)
 	}
@@ -186,7 +181,7 @@ func executeUserGrantsBackup(options backup_utils.BackupOptions, writer io.Write
 	lg.Info("Found users to backup", logger.Int("user_count", userCount))
 
 	// Second command to execute all SHOW GRANTS statements
-	executeGrantsCmd := exec.Command("mysql",
+	executeGrantsCmd := shutdown.Command(ctx, "mysql",
 		fmt.Sprintf("--host=%s", options.Host),
 		fmt.Sprintf("--port=%d", options.Port),
 		fmt.Sprintf("--user=%s", options.User),
@@ -202,7 +197,9 @@ func executeUserGrantsBackup(options backup_utils.BackupOptions, writer io.Write
 	executeGrantsCmd.Stdin = strings.NewReader(string(showGrantsStatements))
 
 	// Get grants output
+	start = time.Now()
 	grantsOutput, err := executeGrantsCmd.Output()
+	database.TraceStatement(endpoint, string(showGrantsStatements), time.Since(start), -1, err)
 	if err != nil {
 		return 0, fmt.Errorf("failed to execute SHOW GRANTS: %w", err)
 	}
@@ -0,0 +1,226 @@
+package verify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/shutdown"
+	"sfDBTools/utils/tempdir"
+)
+
+// sandboxUser is the account created in a throwaway instance for the restore
+const sandboxUser = "sfdb_verify"
+
+// instance is a throwaway mariadbd started for one verification. It listens
+// on 127.0.0.1 only and its data directory is removed by Stop.
+type instance struct {
+	Config database.Config
+	dir    string
+	cmd    *exec.Cmd
+	exited chan error
+}
+
+func (i *instance) String() string {
+	return fmt.Sprintf("throwaway instance on 127.0.0.1:%d (%s)", i.Config.Port, i.dir)
+}
+
+// startInstance initializes a fresh data directory under workDir and starts
+// mariadbd on it, waiting up to timeout for it to accept connections
+func startInstance(ctx context.Context, workDir string, timeout time.Duration) (*instance, error) {
+	lg, _ := logger.Get()
+	server, err := findBinary("mariadbd", "mysqld")
+	if err != nil {
+		return nil, err
+	}
+	installDB, err := findBinary("mariadb-install-db", "mysql_install_db")
+	if err != nil {
+		return nil, err
+	}
+	if workDir == "" {
+		workDir = tempdir.Dir()
+	}
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create sandbox work directory: %w", err)
+	}
+	dir, err := os.MkdirTemp(workDir, "sfdb-verify-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	inst := &instance{dir: dir}
+	ok := false
+	defer func() {
+		if !ok {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+	inst.Config = database.Config{Host: "127.0.0.1", Port: port, User: sandboxUser, Password: password}
+
+	dataDir := filepath.Join(dir, "data")
+	initFile := filepath.Join(dir, "init.sql")
+	initSQL := fmt.Sprintf("CREATE USER '%[1]s'@'127.0.0.1' IDENTIFIED BY '%[2]s';\n"+
+		"GRANT ALL PRIVILEGES ON *.* TO '%[1]s'@'127.0.0.1' WITH GRANT OPTION;\n", sandboxUser, password)
+	if err := os.WriteFile(initFile, []byte(initSQL), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write sandbox init file: %w", err)
+	}
+
+	// mariadbd refuses to run as root unless told to; prefer the mysql account
+	var userArgs []string
+	if os.Geteuid() == 0 {
+		runAs := "root"
+		if u, err := user.Lookup("mysql"); err == nil {
+			runAs = "mysql"
+			if err := chownTree(dir, u); err != nil {
+				return nil, err
+			}
+		}
+		userArgs = []string{"--user=" + runAs}
+	}
+
+	lg.Info("Initializing throwaway instance", logger.String("dir", dir), logger.Int("port", port))
+	install := shutdown.Command(ctx, installDB, append([]string{
+		"--no-defaults",
+		"--datadir=" + dataDir,
+		"--auth-root-authentication-method=normal",
+		"--skip-test-db",
+	}, userArgs...)...)
+	if out, err := install.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", filepath.Base(installDB), err, tail(string(out)))
+	}
+
+	errorLog := filepath.Join(dir, "error.log")
+	inst.cmd = shutdown.Command(ctx, server, append([]string{
+		"--no-defaults",
+		"--datadir=" + dataDir,
+		"--socket=" + filepath.Join(dir, "mysqld.sock"),
+		"--port=" + strconv.Itoa(port),
+		"--bind-address=127.0.0.1",
+		"--init-file=" + initFile,
+		"--log-error=" + errorLog,
+		"--pid-file=" + filepath.Join(dir, "mysqld.pid"),
+		"--skip-log-bin",
+	}, userArgs...)...)
+	if err := inst.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", filepath.Base(server), err)
+	}
+	inst.exited = make(chan error, 1)
+	go func() { inst.exited <- inst.cmd.Wait() }()
+
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		select {
+		case err := <-inst.exited:
+			log, _ := os.ReadFile(errorLog)
+			return nil, fmt.Errorf("throwaway instance exited during startup (%v): %s", err, tail(string(log)))
+		case <-time.After(time.Second):
+		}
+		if database.ValidateConnection(inst.Config) == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			inst.Stop()
+			return nil, fmt.Errorf("throwaway instance did not accept connections within %s", timeout)
+		}
+	}
+	ok = true
+	return inst, nil
+}
+
+// Stop shuts the instance down and removes its directory
+func (i *instance) Stop() {
+	lg, _ := logger.Get()
+	if i.cmd != nil && i.cmd.Process != nil {
+		_ = i.cmd.Process.Signal(syscall.SIGTERM)
+		select {
+		case <-i.exited:
+		case <-time.After(time.Minute):
+			lg.Warn("Throwaway instance did not stop; killing it", logger.Int("pid", i.cmd.Process.Pid))
+			_ = i.cmd.Process.Kill()
+			<-i.exited
+		}
+	}
+	if err := os.RemoveAll(i.dir); err != nil {
+		lg.Warn("Failed to remove throwaway instance directory", logger.String("dir", i.dir), logger.Error(err))
+	}
+}
+
+func findBinary(names ...string) (string, error) {
+	for _, name := range names {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+		// mariadbd is usually installed in sbin, which is not always on PATH
+		for _, dir := range []string{"/usr/sbin", "/usr/libexec", "/usr/local/mysql/bin"} {
+			if path := filepath.Join(dir, name); isExecutable(path) {
+				return path, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s not found; install the MariaDB server or configure backup.verification.sandbox", strings.Join(names, "/"))
+}
+
+func isExecutable(path string) bool {
+	st, err := os.Stat(path)
+	return err == nil && !st.IsDir() && st.Mode()&0111 != 0
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port for the sandbox: %w", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate sandbox password: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func chownTree(dir string, u *user.User) error {
+	uid, _ := strconv.Atoi(u.Uid)
+	gid, _ := strconv.Atoi(u.Gid)
+	return filepath.Walk(dir, func(path string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Chown(path, uid, gid)
+	})
+}
+
+// tail returns the last lines of a log, enough to show why a start failed
+func tail(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > 10 {
+		lines = lines[len(lines)-10:]
+	}
+	return strings.Join(lines, "\n")
+}
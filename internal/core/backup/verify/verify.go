@@ -0,0 +1,336 @@
+// Package verify checks that a backup is usable. A plain verification
+// compares the backup file with the checksum in its manifest; a deep
+// verification also restores it into a sandbox server and checks every
+// restored table with CHECKSUM TABLE and a row count, proving the backup can
+// actually be restored.
+package verify
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	restore_all "sfDBTools/internal/core/restore/all"
+	restore "sfDBTools/internal/core/restore/single"
+	restoreUtils "sfDBTools/internal/core/restore/utils"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/backup/catalog"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/fs"
+)
+
+// Options controls one verification
+type Options struct {
+	Entry catalog.Entry
+	// Deep restores the backup into a sandbox and checks the restored tables
+	Deep bool
+	// Sandbox is an existing server to restore into. Single-database backups
+	// are restored into a scratch schema that is dropped afterwards. When nil,
+	// a throwaway mariadbd instance is started and removed afterwards.
+	Sandbox *database.Config
+	// KeepSandbox keeps the scratch schema or throwaway instance for inspection
+	KeepSandbox   bool
+	ScratchPrefix string
+	// WorkDir holds the data directory of a throwaway instance
+	WorkDir      string
+	StartTimeout time.Duration
+}
+
+// Result is the outcome of a verification
+type Result struct {
+	File       string          `json:"file"`
+	Checksum   string          `json:"checksum"` // ok, mismatch or skipped
+	Deep       bool            `json:"deep"`
+	Restorable bool            `json:"restorable"`
+	Sandbox    string          `json:"sandbox,omitempty"`
+	Databases  []DatabaseCheck `json:"databases,omitempty"`
+	Mismatches []string        `json:"mismatches,omitempty"`
+	Duration   time.Duration   `json:"duration"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// DatabaseCheck is what was found in one restored database
+type DatabaseCheck struct {
+	Name   string       `json:"name"`
+	Schema string       `json:"schema"` // name in the sandbox; differs from Name in a scratch schema
+	Tables []TableCheck `json:"tables"`
+	Rows   int64        `json:"rows"`
+}
+
+// TableCheck is the result of checking one restored table
+type TableCheck struct {
+	Name     string `json:"name"`
+	Rows     int64  `json:"rows"`
+	Checksum string `json:"checksum,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Success reports whether every check passed
+func (r *Result) Success() bool {
+	if r.Error != "" || r.Checksum == "mismatch" {
+		return false
+	}
+	return !r.Deep || r.Restorable
+}
+
+// Summary is a one-line description of the outcome
+func (r *Result) Summary() string {
+	switch {
+	case r.Error != "":
+		return r.Error
+	case r.Checksum == "mismatch":
+		return "checksum mismatch"
+	case !r.Deep:
+		return "checksum " + r.Checksum
+	case !r.Restorable:
+		return "restored data failed checks"
+	}
+	tables := 0
+	var rows int64
+	for _, d := range r.Databases {
+		tables += len(d.Tables)
+		rows += d.Rows
+	}
+	return fmt.Sprintf("restored %d database(s), %d table(s), %d row(s)", len(r.Databases), tables, rows)
+}
+
+// Run verifies the backup of opts.Entry. Verification failures are reported
+// in the Result; an error is only returned when the backup cannot be examined.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	lg, _ := logger.Get()
+	start := time.Now()
+	e := opts.Entry
+	result := &Result{File: e.File, Deep: opts.Deep}
+	defer func() { result.Duration = time.Since(start).Round(time.Second) }()
+
+	if _, err := os.Stat(e.File); err != nil {
+		return nil, fmt.Errorf("backup file not accessible: %w", err)
+	}
+	if opts.Deep && e.Type == "snapshot" {
+		return nil, fmt.Errorf("deep verification of snapshot backups is not supported; restore them with 'sfDBTools restore snapshot'")
+	}
+	if opts.Deep && e.Type == "physical" {
+		return nil, fmt.Errorf("deep verification of physical backups is not supported; check them with 'sfDBTools backup physical --prepare'")
+	}
+
+	result.Checksum = "skipped"
+	if e.Checksum != "" {
+		lg.Info("Verifying backup checksum", logger.String("file", e.File))
+		err := backup_utils.VerifyChecksum(e.File, e.Checksum, e.ChecksumAlgorithm)
+		switch {
+		case errors.Is(err, fs.ErrChecksumMismatch):
+			result.Checksum = "mismatch"
+			return result, nil
+		case err != nil:
+			return nil, err
+		}
+		result.Checksum = "ok"
+	}
+	if !opts.Deep {
+		return result, nil
+	}
+
+	target := opts.Sandbox
+	if target == nil {
+		inst, err := startInstance(ctx, opts.WorkDir, opts.StartTimeout)
+		if err != nil {
+			result.Error = err.Error()
+			return result, nil
+		}
+		result.Sandbox = inst.String()
+		if opts.KeepSandbox {
+			lg.Info("Keeping throwaway instance", logger.String("instance", inst.String()))
+		} else {
+			defer inst.Stop()
+		}
+		target = &inst.Config
+	} else {
+		result.Sandbox = fmt.Sprintf("%s:%d", target.Host, target.Port)
+	}
+
+	if err := restoreInto(ctx, opts, *target, result); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Restorable = len(result.Mismatches) == 0
+	for _, d := range result.Databases {
+		for _, t := range d.Tables {
+			if t.Error != "" {
+				result.Restorable = false
+			}
+		}
+	}
+	return result, nil
+}
+
+// restoreInto restores the backup into target and checks what was restored
+func restoreInto(ctx context.Context, opts Options, target database.Config, result *Result) error {
+	e := opts.Entry
+	restoreOpts := restoreUtils.RestoreOptions{
+		Host:     target.Host,
+		Port:     target.Port,
+		User:     target.User,
+		Password: target.Password,
+		File:     e.File,
+	}
+
+	if e.Type != "single" {
+		if opts.Sandbox != nil {
+			return fmt.Errorf("%s backups restore every database they contain and are only verified in a throwaway instance; omit the sandbox server", e.Type)
+		}
+		if err := restore_all.RestoreAll(ctx, restoreOpts); err != nil {
+			return fmt.Errorf("restore failed: %w", err)
+		}
+		names := e.Databases
+		if len(names) == 0 {
+			var err error
+			if names, err = info.ListDatabases(target); err != nil {
+				return err
+			}
+		}
+		for _, name := range names {
+			check, err := checkDatabase(target, name, name)
+			if err != nil {
+				return err
+			}
+			result.Databases = append(result.Databases, *check)
+		}
+		return nil
+	}
+
+	schema := e.Database
+	if opts.Sandbox != nil {
+		// A shared sandbox may already hold the database; restore beside it
+		schema = scratchName(opts.ScratchPrefix, e.Database, time.Now())
+		restoreOpts.RewriteReferences = true
+		restoreOpts.RewriteFrom = e.Database
+		if !opts.KeepSandbox {
+			defer dropSchema(target, schema)
+		}
+	}
+	restoreOpts.DBName = schema
+	if err := restore.RestoreSingle(ctx, restoreOpts); err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	check, err := checkDatabase(target, e.Database, schema)
+	if err != nil {
+		return err
+	}
+	result.Databases = append(result.Databases, *check)
+	result.Mismatches = compareCounts(ctx, target, schema, e.Manifest)
+	return nil
+}
+
+// checkDatabase runs CHECKSUM TABLE and a row count on every table of schema
+func checkDatabase(target database.Config, name, schema string) (*DatabaseCheck, error) {
+	target.DBName = ""
+	db, err := database.GetWithoutDB(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to sandbox: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT table_name FROM information_schema.tables
+		WHERE table_schema = ? AND table_type = 'BASE TABLE' ORDER BY table_name`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list restored tables of %s: %w", schema, err)
+	}
+	var tables []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	rows.Close()
+
+	check := &DatabaseCheck{Name: name, Schema: schema, Tables: []TableCheck{}}
+	for _, t := range tables {
+		tc := checkTable(db, schema, t)
+		check.Rows += tc.Rows
+		check.Tables = append(check.Tables, tc)
+	}
+	return check, nil
+}
+
+func checkTable(db *sql.DB, schema, table string) TableCheck {
+	tc := TableCheck{Name: table}
+	ident := database.QuoteIdent(schema) + "." + database.QuoteIdent(table)
+
+	var name string
+	var sum sql.NullString
+	if err := db.QueryRow("CHECKSUM TABLE "+ident).Scan(&name, &sum); err != nil {
+		tc.Error = fmt.Sprintf("CHECKSUM TABLE failed: %v", err)
+		return tc
+	}
+	if !sum.Valid {
+		// CHECKSUM TABLE returns NULL for a table it cannot read
+		tc.Error = "table is unreadable"
+		return tc
+	}
+	tc.Checksum = sum.String
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + ident).Scan(&tc.Rows); err != nil {
+		tc.Error = fmt.Sprintf("row count failed: %v", err)
+	}
+	return tc
+}
+
+// compareCounts compares the restored schema with the object counts recorded
+// in the manifest at backup time
+func compareCounts(ctx context.Context, target database.Config, schema, manifest string) []string {
+	meta, ok := backup_utils.ReadBackupManifest(manifest)
+	target.DBName = schema
+	info.InvalidateDatabaseInfo(target)
+	restored, err := info.GetDatabaseInfo(ctx, target)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to inspect restored schema: %v", err)}
+	}
+	if restored.TableCount == 0 && restored.ViewCount == 0 {
+		return []string{"restored schema is empty"}
+	}
+	if !ok || meta.DatabaseInfo == nil {
+		return nil
+	}
+
+	var mismatches []string
+	check := func(what string, want, got int) {
+		if want != got {
+			mismatches = append(mismatches, fmt.Sprintf("%s: backup %d, restored %d", what, want, got))
+		}
+	}
+	check("tables", meta.DatabaseInfo.TableCount, restored.TableCount)
+	check("views", meta.DatabaseInfo.ViewCount, restored.ViewCount)
+	check("routines", meta.DatabaseInfo.RoutineCount, restored.RoutineCount)
+	check("triggers", meta.DatabaseInfo.TriggerCount, restored.TriggerCount)
+	return mismatches
+}
+
+func dropSchema(target database.Config, schema string) {
+	target.DBName = ""
+	db, err := database.GetWithoutDB(target)
+	if err == nil {
+		defer db.Close()
+		_, err = db.Exec("DROP DATABASE IF EXISTS " + database.QuoteIdent(schema))
+	}
+	if err != nil {
+		lg, _ := logger.Get()
+		lg.Warn("Failed to drop verification schema", logger.String("schema", schema), logger.Error(err))
+	}
+}
+
+// scratchName builds a scratch schema name within the 64 character identifier limit
+func scratchName(prefix, db string, t time.Time) string {
+	suffix := "_" + t.Format("20060102150405")
+	name := prefix + db
+	if max := 64 - len(suffix); len(name) > max {
+		name = name[:max]
+	}
+	return name + suffix
+}
@@ -0,0 +1,338 @@
+package clone
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/fs/policy"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/shutdown"
+	"sfDBTools/utils/tempdir"
+)
+
+// Transfer modes
+const (
+	ModeStream = "stream"
+	ModeFile   = "file"
+)
+
+// stderrLimit caps how much client stderr is kept for error messages
+const stderrLimit = 4096
+
+// Options controls a database clone
+type Options struct {
+	Source   database.Config // DBName is the database to copy
+	Target   database.Config // DBName is the database to load into
+	Compress bool            // use client/server protocol compression on both connections
+	Fallback bool            // retry through a temporary dump file when the stream breaks
+	TempDir  string          // directory for the fallback dump file (default: tempdir.Dir)
+}
+
+// Result describes a finished clone
+type Result struct {
+	Mode           string
+	Bytes          int64
+	Duration       time.Duration
+	FallbackReason string // why the stream was abandoned, empty when streaming succeeded
+}
+
+// Run copies Source.DBName into Target.DBName by piping mysqldump straight
+// into the target's mysql client, without an intermediate file. If the
+// stream breaks and Fallback is set, the copy is retried by dumping to a
+// compressed temporary file first and loading that.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	lg, _ := logger.Get()
+	start := time.Now()
+
+	job := jobstatus.Start("clone", fmt.Sprintf("%s -> %s:%d/%s", opts.Source.DBName, opts.Target.Host, opts.Target.Port, opts.Target.DBName))
+	result, err := run(ctx, opts, job)
+	if result != nil {
+		result.Duration = time.Since(start)
+		job.Detail("Mode", result.Mode)
+		job.Detail("Data transferred", common.FormatSize(result.Bytes))
+		if result.FallbackReason != "" {
+			job.Detail("Fallback reason", result.FallbackReason)
+		}
+	}
+	job.Finish(err)
+
+	if err == nil {
+		lg.Info("Database clone completed",
+			logger.String("source_db", opts.Source.DBName),
+			logger.String("target_db", opts.Target.DBName),
+			logger.String("mode", result.Mode),
+			logger.Int64("bytes", result.Bytes))
+	}
+	return result, err
+}
+
+func run(ctx context.Context, opts Options, job *jobstatus.Tracker) (*Result, error) {
+	job.SetStep("preparing")
+	if err := ensureTargetDatabase(opts.Source, opts.Target); err != nil {
+		return nil, err
+	}
+	var estimate int64
+	if srcInfo, err := info.GetDatabaseInfo(ctx, opts.Source); err == nil {
+		estimate = srcInfo.SizeBytes
+	}
+
+	job.SetStep("streaming")
+	written, err := stream(ctx, opts, job, estimate)
+	if err == nil {
+		return &Result{Mode: ModeStream, Bytes: written}, nil
+	}
+	if !opts.Fallback {
+		return &Result{Mode: ModeStream, Bytes: written}, fmt.Errorf("stream clone failed: %w", err)
+	}
+
+	job.Warn(fmt.Sprintf("Stream clone broke after %s, falling back to file mode: %v", common.FormatSize(written), err))
+	result := &Result{Mode: ModeFile, FallbackReason: err.Error()}
+	result.Bytes, err = viaFile(ctx, opts, job, estimate)
+	if err != nil {
+		return result, fmt.Errorf("file mode clone failed after stream failure: %w", err)
+	}
+	return result, nil
+}
+
+// stream pipes mysqldump into mysql and returns the number of bytes transferred
+func stream(ctx context.Context, opts Options, job *jobstatus.Tracker, estimate int64) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	dump, dumpErr := dumpCommand(ctx, opts)
+	load, loadErr := loadCommand(ctx, opts)
+
+	out, err := dump.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+	counter := &countingReader{r: out}
+	load.Stdin = job.Reader(counter, estimate)
+
+	if err := load.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start mysql: %w", err)
+	}
+	if err := dump.Start(); err != nil {
+		cancel()
+		load.Wait()
+		return 0, fmt.Errorf("failed to start mysqldump: %w", err)
+	}
+
+	// load.Wait returns once stdin is fully consumed; a failed load leaves
+	// mysqldump blocked on a full pipe, so it is cancelled before waiting
+	lerr := load.Wait()
+	if lerr != nil {
+		cancel()
+	}
+	derr := dump.Wait()
+
+	switch {
+	case derr != nil && lerr == nil:
+		// mysql saw a clean EOF, but the dump was incomplete
+		return counter.n.Load(), fmt.Errorf("mysqldump failed: %w%s", derr, dumpErr.detail())
+	case lerr != nil:
+		return counter.n.Load(), fmt.Errorf("mysql failed: %w%s", lerr, loadErr.detail())
+	}
+	return counter.n.Load(), nil
+}
+
+// viaFile dumps to a gzip-compressed temp file, then loads it into the target
+func viaFile(ctx context.Context, opts Options, job *jobstatus.Tracker, estimate int64) (int64, error) {
+	dir := opts.TempDir
+	if dir == "" {
+		dir = tempdir.Dir()
+	}
+	// gzip shrinks a SQL dump to well under half its size
+	if err := tempdir.CheckDir("clone", dir, estimate/2); err != nil {
+		return 0, err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("clone_%s_%s.sql.gz", opts.Source.DBName, time.Now().Format("20060102_150405")))
+	defer os.Remove(path)
+
+	job.SetStep("dumping to file")
+	file, err := policy.CreateFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	gz := gzip.NewWriter(file)
+
+	dump, dumpErr := dumpCommand(ctx, opts)
+	counter := &countingWriter{w: gz}
+	dump.Stdout = job.Writer(counter, estimate)
+	runErr := dump.Run()
+	if err := gz.Close(); err != nil && runErr == nil {
+		runErr = err
+	}
+	if err := file.Close(); err != nil && runErr == nil {
+		runErr = err
+	}
+	if runErr != nil {
+		return 0, fmt.Errorf("mysqldump failed: %w%s", runErr, dumpErr.detail())
+	}
+
+	job.SetStep("loading file")
+	in, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	gr, err := gzip.NewReader(in)
+	if err != nil {
+		return 0, err
+	}
+	defer gr.Close()
+
+	load, loadErr := loadCommand(ctx, opts)
+	load.Stdin = job.Reader(gr, counter.n)
+	if err := load.Run(); err != nil {
+		return counter.n, fmt.Errorf("mysql failed: %w%s", err, loadErr.detail())
+	}
+	return counter.n, nil
+}
+
+// dumpCommand builds mysqldump for the source database using the configured arguments
+func dumpCommand(ctx context.Context, opts Options) (*exec.Cmd, *limitedBuffer) {
+	var args []string
+	if cfg, err := config.Get(); err == nil && cfg != nil {
+		args = common.ParseArgsString(cfg.Mysqldump.Args)
+	}
+	args = append(args,
+		fmt.Sprintf("--host=%s", opts.Source.Host),
+		fmt.Sprintf("--port=%d", opts.Source.Port),
+		fmt.Sprintf("--user=%s", opts.Source.User))
+	if opts.Compress {
+		args = append(args, "--compress")
+	}
+	args = append(args, opts.Source.DBName)
+
+	cmd := shutdown.Command(ctx, "mysqldump", args...)
+	return withCredentials(cmd, opts.Source.Password)
+}
+
+// loadCommand builds the mysql client that replays the dump into the target database
+func loadCommand(ctx context.Context, opts Options) (*exec.Cmd, *limitedBuffer) {
+	args := []string{
+		fmt.Sprintf("--host=%s", opts.Target.Host),
+		fmt.Sprintf("--port=%d", opts.Target.Port),
+		fmt.Sprintf("--user=%s", opts.Target.User),
+		"--max-allowed-packet=1G",
+	}
+	if opts.Compress {
+		args = append(args, "--compress")
+	}
+	args = append(args, opts.Target.DBName)
+
+	cmd := shutdown.Command(ctx, "mysql", args...)
+	return withCredentials(cmd, opts.Target.Password)
+}
+
+// withCredentials passes the password through the environment and captures stderr
+func withCredentials(cmd *exec.Cmd, password string) (*exec.Cmd, *limitedBuffer) {
+	cmd.Env = os.Environ()
+	if password != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("MYSQL_PWD=%s", password))
+	}
+	stderr := &limitedBuffer{}
+	cmd.Stderr = stderr
+	return cmd, stderr
+}
+
+// ensureTargetDatabase creates the target database with the source's default
+// character set and collation when it does not exist yet
+func ensureTargetDatabase(source, target database.Config) error {
+	charset, collation := "utf8mb4", "utf8mb4_general_ci"
+	if src, err := database.GetWithoutDB(source); err == nil {
+		_ = src.QueryRow("SELECT DEFAULT_CHARACTER_SET_NAME, DEFAULT_COLLATION_NAME FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?",
+			source.DBName).Scan(&charset, &collation)
+		src.Close()
+	}
+
+	db, err := database.GetWithoutDB(target)
+	if err != nil {
+		return fmt.Errorf("failed to connect to target: %w", err)
+	}
+	defer db.Close()
+
+	stmt := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s CHARACTER SET %s COLLATE %s",
+		database.QuoteIdent(target.DBName), charset, collation)
+	if _, err := db.Exec(stmt); err != nil {
+		return fmt.Errorf("failed to create target database %s: %w", target.DBName, err)
+	}
+	return nil
+}
+
+// TargetTableCount returns the number of tables already in the target database
+func TargetTableCount(target database.Config) (int, error) {
+	db, err := database.GetWithoutDB(target)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = ?", target.DBName).Scan(&count)
+	return count, err
+}
+
+// countingReader counts bytes read
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// countingWriter counts bytes written
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// limitedBuffer keeps the first stderrLimit bytes of client stderr
+type limitedBuffer struct {
+	buf bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := stderrLimit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// detail formats captured stderr for appending to an error message
+func (b *limitedBuffer) detail() string {
+	msg := strings.TrimSpace(b.buf.String())
+	if msg == "" {
+		return ""
+	}
+	return ": " + msg
+}
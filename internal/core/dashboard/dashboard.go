@@ -0,0 +1,304 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/core/mariadb/health"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/terminal"
+)
+
+// Panels in focus order
+const (
+	panelService = iota
+	panelDatabases
+	panelBackups
+	panelReplication
+	panelCount
+)
+
+var panelTitles = [panelCount]string{"Service", "Databases", "Recent backups", "Replication"}
+
+// view is the state of the screen between two key presses
+type view struct {
+	opts       Options
+	snapshot   *Snapshot
+	refreshing bool
+	focus      int
+	scroll     [panelCount]int
+	pageSize   [panelCount]int
+}
+
+// Run shows the dashboard until q, Esc or Ctrl+C is pressed or ctx is done.
+// The snapshot is collected in the background every opts.Interval and on 'r'.
+func Run(ctx context.Context, opts Options) error {
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+	screen, err := terminal.OpenScreen()
+	if err != nil {
+		return err
+	}
+	defer screen.Close()
+
+	v := &view{opts: opts, refreshing: true}
+	keys := screen.Keys()
+	snapshots := make(chan *Snapshot, 1)
+	collect := func() {
+		go func() { snapshots <- Collect(ctx, opts) }()
+	}
+	collect()
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	// Redraw at least every second so the terminal size and the age of the
+	// data stay current
+	redraw := time.NewTicker(time.Second)
+	defer redraw.Stop()
+
+	for {
+		screen.Draw(v.render(screen.Size()))
+		select {
+		case <-ctx.Done():
+			return nil
+		case s := <-snapshots:
+			v.snapshot, v.refreshing = s, false
+		case <-ticker.C:
+			if !v.refreshing {
+				v.refreshing = true
+				collect()
+			}
+		case <-redraw.C:
+		case key, ok := <-keys:
+			if !ok || v.handleKey(key) {
+				return nil
+			}
+			if key.Rune == 'r' && !v.refreshing {
+				v.refreshing = true
+				collect()
+				ticker.Reset(opts.Interval)
+			}
+		}
+	}
+}
+
+// handleKey moves the focus or scrolls the focused panel. It reports whether
+// the dashboard should close.
+func (v *view) handleKey(key terminal.Key) bool {
+	switch {
+	case key.Name == terminal.KeyCtrlC || key.Name == terminal.KeyEscape || key.Rune == 'q':
+		return true
+	case key.Name == terminal.KeyTab || key.Name == terminal.KeyRight:
+		v.focus = (v.focus + 1) % panelCount
+	case key.Name == terminal.KeyBackTab || key.Name == terminal.KeyLeft:
+		v.focus = (v.focus + panelCount - 1) % panelCount
+	case key.Rune >= '1' && key.Rune < '1'+panelCount:
+		v.focus = int(key.Rune - '1')
+	case key.Name == terminal.KeyDown || key.Rune == 'j':
+		v.scroll[v.focus]++
+	case key.Name == terminal.KeyUp || key.Rune == 'k':
+		v.scroll[v.focus]--
+	case key.Name == terminal.KeyPageDown:
+		v.scroll[v.focus] += v.pageSize[v.focus]
+	case key.Name == terminal.KeyPageUp:
+		v.scroll[v.focus] -= v.pageSize[v.focus]
+	case key.Name == terminal.KeyHome:
+		v.scroll[v.focus] = 0
+	case key.Name == terminal.KeyEnd:
+		v.scroll[v.focus] = 1 << 30
+	}
+	return false
+}
+
+// render lays out the header, the four panels and the key help for a screen
+// of width x height. Service and replication keep their natural height; the
+// databases and backups share the rest.
+func (v *view) render(width, height int) []string {
+	content := [panelCount][]string{
+		panelService:     v.serviceLines(),
+		panelDatabases:   v.databaseLines(),
+		panelBackups:     v.backupLines(),
+		panelReplication: v.replicationLines(),
+	}
+
+	// Each panel has a title line; header and footer take one line each
+	rest := height - 2 - (len(content[panelService]) + 1) - (len(content[panelReplication]) + 1)
+	heights := [panelCount]int{
+		panelService:     len(content[panelService]),
+		panelDatabases:   max(rest/2-1, 1),
+		panelBackups:     max(rest-rest/2-1, 1),
+		panelReplication: len(content[panelReplication]),
+	}
+
+	lines := []string{v.header(width)}
+	for p := 0; p < panelCount; p++ {
+		lines = append(lines, v.panel(p, content[p], heights[p], width)...)
+	}
+	if len(lines) > height-1 {
+		lines = lines[:max(height-1, 0)]
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	footer := "Tab/1-4 focus  ↑↓ PgUp/PgDn scroll  r refresh  q quit"
+	return append(lines, terminal.ColorText(terminal.Fit(footer, width), terminal.ColorCyan))
+}
+
+func (v *view) header(width int) string {
+	server := fmt.Sprintf("%s:%d", v.opts.Server.Host, v.opts.Server.Port)
+	state := "loading..."
+	if v.snapshot != nil {
+		state = "updated " + v.snapshot.At.Format("15:04:05")
+		if v.refreshing {
+			state += ", refreshing..."
+		}
+	}
+	title := terminal.Fit(fmt.Sprintf("sfDBTools dashboard  %s  every %s", server, v.opts.Interval), max(width-len(state)-1, 0))
+	return terminal.ColorBold + title + " " + state
+}
+
+// panel renders the title line and the visible window of one panel. The
+// scroll offset is clamped here, where the panel height is known.
+func (v *view) panel(p int, content []string, height, width int) []string {
+	v.pageSize[p] = height
+	maxScroll := max(len(content)-height, 0)
+	v.scroll[p] = min(max(v.scroll[p], 0), maxScroll)
+
+	title := fmt.Sprintf("─ %d %s ", p+1, panelTitles[p])
+	if maxScroll > 0 {
+		title += fmt.Sprintf("[%d-%d/%d] ", v.scroll[p]+1, min(v.scroll[p]+height, len(content)), len(content))
+	}
+	title += strings.Repeat("─", max(width-len([]rune(title)), 0))
+	title = terminal.Fit(title, width)
+	if p == v.focus {
+		title = terminal.ColorText(title, terminal.ColorBold+terminal.ColorCyan)
+	}
+
+	lines := []string{title}
+	for i := v.scroll[p]; i < v.scroll[p]+height; i++ {
+		if i < len(content) {
+			lines = append(lines, terminal.Fit(content[i], width))
+		} else {
+			lines = append(lines, "")
+		}
+	}
+	return lines
+}
+
+func (v *view) serviceLines() []string {
+	s := v.snapshot
+	if s == nil {
+		return []string{"", "", ""}
+	}
+	service := "not detected on this host"
+	if svc := s.Service; svc != nil && svc.ServiceName != "" {
+		state := "stopped"
+		if svc.IsRunning {
+			state = "running"
+		}
+		service = fmt.Sprintf("%s (%s)", svc.ServiceName, state)
+	}
+	lines := []string{"Service      " + service}
+	if svc := s.Service; svc != nil && svc.BinaryPath != "" {
+		lines = append(lines, fmt.Sprintf("Installed    MariaDB %s, datadir %s", svc.Version, svc.DataDir))
+	} else {
+		lines = append(lines, "Installed    -")
+	}
+	if s.HealthErr != nil {
+		return append(lines, "Server       unreachable: "+s.HealthErr.Error())
+	}
+	r := s.Health
+	return append(lines, fmt.Sprintf("Server       %s, MariaDB %s, up %s, %s, connections %d/%d (%.1f%%)",
+		statusLabel(r.Status), r.Version,
+		common.HumanizeDuration(time.Duration(r.UptimeSeconds)*time.Second),
+		r.Server, r.Connections.Connected, r.Connections.Max, r.Connections.UsagePercent))
+}
+
+func (v *view) databaseLines() []string {
+	s := v.snapshot
+	switch {
+	case s == nil:
+		return nil
+	case s.DatabasesErr != nil:
+		return []string{"unavailable: " + s.DatabasesErr.Error()}
+	case len(s.Databases) == 0:
+		return []string{"no user databases"}
+	}
+	var total int64
+	lines := []string{fmt.Sprintf("%-40s %12s %8s", "Database", "Size", "Tables")}
+	for _, d := range s.Databases {
+		total += d.SizeBytes
+		lines = append(lines, fmt.Sprintf("%-40s %12s %8d", d.Name, common.FormatSize(d.SizeBytes), d.TableCount))
+	}
+	return append(lines, fmt.Sprintf("%-40s %12s", fmt.Sprintf("%d databases", len(s.Databases)), common.FormatSize(total)))
+}
+
+func (v *view) backupLines() []string {
+	s := v.snapshot
+	switch {
+	case s == nil:
+		return nil
+	case v.opts.CatalogPath == "":
+		return []string{"no backup catalog configured"}
+	case s.BackupsErr != nil:
+		return []string{"unavailable: " + s.BackupsErr.Error()}
+	case len(s.Backups) == 0:
+		return []string{"no backups in " + v.opts.CatalogPath}
+	}
+	lines := []string{fmt.Sprintf("%-6s %-19s %-28s %-10s %10s %s", "ID", "Date", "Database", "Type", "Size", "Verified")}
+	for _, e := range s.Backups {
+		verified := "-"
+		if e.Verification != nil {
+			verified = "failed"
+			if e.Verification.Success {
+				verified = "ok"
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%-6d %-19s %-28s %-10s %10s %s",
+			e.ID, e.BackupDate.Local().Format("2006-01-02 15:04:05"), e.Database, e.Type,
+			common.FormatSize(e.Size), verified))
+	}
+	return lines
+}
+
+func (v *view) replicationLines() []string {
+	s := v.snapshot
+	switch {
+	case s == nil:
+		return []string{""}
+	case s.HealthErr != nil:
+		return []string{"unavailable: server unreachable"}
+	}
+	rep := s.Health.Replication
+	switch rep.Role {
+	case "replica":
+		lag := "unknown"
+		if rep.LagSeconds != nil {
+			lag = fmt.Sprintf("%ds", *rep.LagSeconds)
+		}
+		lines := []string{
+			"Role         replica",
+			fmt.Sprintf("Threads      IO %s, SQL %s", rep.IORunning, rep.SQLRunning),
+			"Lag          " + lag,
+		}
+		if rep.LastError != "" {
+			lines = append(lines, "Last error   "+rep.LastError)
+		}
+		return lines
+	case "primary":
+		return []string{fmt.Sprintf("Role         primary, %d replicas connected", rep.Replicas)}
+	}
+	return []string{"Role         " + rep.Role}
+}
+
+func statusLabel(status health.Status) string {
+	switch status {
+	case health.StatusOK:
+		return "status OK"
+	case health.StatusWarn:
+		return "status WARN"
+	}
+	return "status CRIT"
+}
@@ -0,0 +1,65 @@
+// Package dashboard shows a full-screen overview of a server on the terminal:
+// the local MariaDB service, the databases with their sizes, the recent
+// backups of the catalog and the replication state, refreshed periodically.
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"sfDBTools/internal/core/mariadb/health"
+	"sfDBTools/utils/backup/catalog"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/mariadb/discovery"
+)
+
+// Options configures the dashboard
+type Options struct {
+	Server      database.Config
+	CatalogPath string        // backup catalog; the backups panel is empty when unset
+	Backups     int           // number of recent backups shown
+	Interval    time.Duration // time between two refreshes
+}
+
+// Snapshot is the state of the server at one refresh. Every section is
+// collected on its own so one failing source does not blank the others.
+type Snapshot struct {
+	At           time.Time
+	Service      *discovery.MariaDBInstallation
+	Health       *health.Report
+	HealthErr    error
+	Databases    []info.DatabaseSize
+	DatabasesErr error
+	Backups      []catalog.Entry
+	BackupsErr   error
+}
+
+// Collect gathers a snapshot for opts
+func Collect(ctx context.Context, opts Options) *Snapshot {
+	s := &Snapshot{At: time.Now()}
+	s.Service, _ = discovery.DiscoverMariaDBInstallation()
+	s.Health, s.HealthErr = health.Check(opts.Server)
+	s.Databases, s.DatabasesErr = info.ListDatabaseSizes(ctx, opts.Server)
+	if opts.CatalogPath != "" {
+		s.Backups, s.BackupsErr = recentBackups(opts.CatalogPath, opts.Backups)
+	}
+	return s
+}
+
+// recentBackups opens the catalog only for the read, so backups running next
+// to the dashboard are not blocked on its lock
+func recentBackups(path string, limit int) ([]catalog.Entry, error) {
+	// Opening creates the catalog; a host without backups keeps none
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	c, err := catalog.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.List(catalog.Filter{Limit: limit})
+}
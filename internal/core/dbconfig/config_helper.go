@@ -3,7 +3,6 @@ package dbconfig
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
 
 	"sfDBTools/internal/config"
 	"sfDBTools/utils/common"
@@ -36,7 +35,7 @@ func (ch *ConfigHelper) LoadDecryptedConfig(filePath, encryptionPassword string)
 	spinner := terminal.NewProgressSpinner("Decrypting configuration...")
 	spinner.Start()
 
-	dbConfig, err := common.LoadEncryptedConfigFromFile(filePath, encryptionPassword)
+	dbConfig, err := dbconfig.LoadProfile(filePath, encryptionPassword)
 	spinner.Stop()
 
 	if err != nil {
@@ -48,8 +47,7 @@ func (ch *ConfigHelper) LoadDecryptedConfig(filePath, encryptionPassword string)
 
 // GetConfigNameFromPath extracts config name from file path
 func (ch *ConfigHelper) GetConfigNameFromPath(filePath string) string {
-	filename := filepath.Base(filePath)
-	return strings.TrimSuffix(filename, ".cnf.enc")
+	return dbconfig.ProfileNameFromPath(filePath)
 }
 
 // DisplayConfigDetails shows configuration details in a formatted way
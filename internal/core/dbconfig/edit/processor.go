@@ -1,17 +1,14 @@
 package edit
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
 	"sfDBTools/internal/config"
 	coredbconfig "sfDBTools/internal/core/dbconfig"
 	"sfDBTools/internal/logger"
-	"sfDBTools/utils/crypto"
 	"sfDBTools/utils/dbconfig"
 	"sfDBTools/utils/terminal"
 )
@@ -89,7 +86,7 @@ func (p *Processor) editSpecificConfig(filePath string) error {
 	}
 
 	// Confirm changes
-	if !terminal.AskYesNo("Save these changes?", true) {
+	if !terminal.Confirm("Save these changes?", true) {
 		terminal.PrintWarning("Changes cancelled.")
 		return nil
 	}
@@ -106,16 +103,14 @@ func (p *Processor) displayCurrentConfig(filePath string, dbConfig *config.Encry
 	terminal.PrintInfo(fmt.Sprintf("   Port: %d", dbConfig.Port))
 	terminal.PrintInfo(fmt.Sprintf("   User: %s", dbConfig.User))
 	terminal.PrintInfo(fmt.Sprintf("   Password: %s", dbConfig.Password))
+	if len(dbConfig.Hosts) > 0 {
+		terminal.PrintInfo(fmt.Sprintf("   Other hosts: %s (kept)", dbConfig.ExtraHosts()))
+	}
 }
 
 // extractConfigName extracts configuration name from file path
 func (p *Processor) extractConfigName(filePath string) string {
-	currentName := strings.TrimSuffix(strings.TrimSuffix(filePath, ".cnf.enc"), "config/")
-	if strings.Contains(currentName, "/") {
-		parts := strings.Split(currentName, "/")
-		currentName = parts[len(parts)-1]
-	}
-	return currentName
+	return dbconfig.ProfileNameFromPath(filePath)
 }
 
 // promptForUpdates prompts user for configuration updates
@@ -140,10 +135,14 @@ func (p *Processor) promptForUpdates(dbConfig *config.EncryptedDatabaseConfig, c
 
 	// Create updated configuration
 	updatedConfig := &config.EncryptedDatabaseConfig{
-		Host:     newHost,
-		Port:     newPort,
-		User:     newUser,
-		Password: newPassword,
+		Host:       newHost,
+		Port:       newPort,
+		User:       newUser,
+		Password:   newPassword,
+		Socket:     dbConfig.Socket,
+		AuthPlugin: dbConfig.AuthPlugin,
+		Hosts:      dbConfig.Hosts,
+		Policy:     dbConfig.Policy,
 	}
 
 	terminal.Headers("Edit Database Configuration")
@@ -213,62 +212,30 @@ func (p *Processor) maskPassword(password string) string {
 	return strings.Repeat("*", len(password))
 }
 
-// saveUpdatedConfig saves the updated configuration to file
+// saveUpdatedConfig saves the updated configuration through
+// dbconfig.SaveProfile, the same writer 'profile edit' uses. A renamed
+// configuration is written under the new name before the old file is removed.
 func (p *Processor) saveUpdatedConfig(originalPath, currentName, newName string, dbConfig *config.EncryptedDatabaseConfig, encryptionPassword string) error {
-	// Generate encryption key from user password only
-	key, err := crypto.DeriveKeyWithPassword(encryptionPassword)
-	if err != nil {
-		return fmt.Errorf("failed to derive encryption key: %v", err)
+	if newName == currentName {
+		if err := dbconfig.SaveProfile(originalPath, dbConfig, encryptionPassword); err != nil {
+			return fmt.Errorf("failed to write encrypted config file: %w", err)
+		}
+		terminal.PrintSuccess(fmt.Sprintf("Configuration updated: %s", originalPath))
+		return nil
 	}
 
-	// Convert to JSON
-	jsonData, err := p.marshalConfig(dbConfig)
-	if err != nil {
+	if err := dbconfig.ValidateProfileName(newName); err != nil {
 		return err
 	}
-
-	// Encrypt the configuration
-	encryptedData, err := crypto.EncryptData(jsonData, key, crypto.AES_GCM)
-	if err != nil {
-		return fmt.Errorf("failed to encrypt database configuration: %v", err)
-	}
-
-	// Determine new file path
-	configDir, err := config.GetDatabaseConfigDirectory()
+	newFilePath, err := dbconfig.ProfilePath(newName)
 	if err != nil {
-		return fmt.Errorf("failed to get database config directory: %v", err)
-	}
-
-	newFileName := newName + ".cnf.enc"
-	newFilePath := filepath.Join(configDir, newFileName)
-
-	// Handle file rename/save
-	if newName != currentName {
-		return p.saveWithRename(originalPath, newFilePath, encryptedData, newName)
-	} else {
-		return p.saveInPlace(originalPath, encryptedData)
-	}
-}
-
-// marshalConfig converts config to JSON
-func (p *Processor) marshalConfig(dbConfig *config.EncryptedDatabaseConfig) ([]byte, error) {
-	jsonData, err := json.Marshal(dbConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal database config to JSON: %v", err)
+		return err
 	}
-	return jsonData, nil
-}
-
-// saveWithRename saves to new file and removes old one
-func (p *Processor) saveWithRename(originalPath, newFilePath string, encryptedData []byte, newName string) error {
-	// Check if new file already exists
 	if _, err := os.Stat(newFilePath); err == nil {
 		return fmt.Errorf("configuration file with name '%s' already exists", newName)
 	}
-
-	// Write to new file
-	if err := os.WriteFile(newFilePath, encryptedData, 0600); err != nil {
-		return fmt.Errorf("failed to write new encrypted config file: %v", err)
+	if err := dbconfig.SaveProfile(newFilePath, dbConfig, encryptionPassword); err != nil {
+		return fmt.Errorf("failed to write new encrypted config file: %w", err)
 	}
 
 	// Remove old file
@@ -282,13 +249,3 @@ func (p *Processor) saveWithRename(originalPath, newFilePath string, encryptedDa
 	terminal.PrintSuccess(fmt.Sprintf("✅ Configuration saved to: %s", newFilePath))
 	return nil
 }
-
-// saveInPlace overwrites the existing file
-func (p *Processor) saveInPlace(originalPath string, encryptedData []byte) error {
-	if err := os.WriteFile(originalPath, encryptedData, 0600); err != nil {
-		return fmt.Errorf("failed to write encrypted config file: %v", err)
-	}
-
-	terminal.PrintSuccess(fmt.Sprintf("Configuration updated: %s", originalPath))
-	return nil
-}
@@ -15,10 +15,15 @@ func (p *Processor) processAutoMode(cfg *structs.DBConfig) error {
 
 	// Create configuration from provided parameters
 	dbConfig := &config.EncryptedDatabaseConfig{
-		Host:     cfg.ConnectionOptions.Host,
-		Port:     cfg.ConnectionOptions.Port,
-		User:     cfg.ConnectionOptions.User,
-		Password: cfg.ConnectionOptions.Password,
+		Host:       cfg.ConnectionOptions.Host,
+		Port:       cfg.ConnectionOptions.Port,
+		User:       cfg.ConnectionOptions.User,
+		Password:   cfg.ConnectionOptions.Password,
+		Socket:     cfg.ConnectionOptions.Socket,
+		AuthPlugin: cfg.ConnectionOptions.AuthPlugin,
+	}
+	if err := applyHosts(dbConfig, cfg.ConnectionOptions); err != nil {
+		return err
 	}
 
 	// Final name provided by caller
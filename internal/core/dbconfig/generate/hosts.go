@@ -0,0 +1,27 @@
+package generate
+
+import (
+	"fmt"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/utils/common/structs"
+	"sfDBTools/utils/database/connection"
+)
+
+// applyHosts adds the further hosts and policy given with --hosts/--policy
+// to a configuration whose Port is already set
+func applyHosts(dbConfig *config.EncryptedDatabaseConfig, opts structs.ConnectionOptions) error {
+	hosts, err := config.ParseDatabaseEndpoints(opts.Hosts, dbConfig.Port)
+	if err != nil {
+		return fmt.Errorf("invalid --hosts: %w", err)
+	}
+	if err := connection.ValidatePolicy(opts.Policy); err != nil {
+		return err
+	}
+	if opts.Policy != "" && len(hosts) == 0 {
+		return fmt.Errorf("--policy needs further hosts given with --hosts")
+	}
+	dbConfig.Hosts = hosts
+	dbConfig.Policy = opts.Policy
+	return nil
+}
@@ -51,10 +51,15 @@ func (p *Processor) processInteractiveMode(dbcfg *structs.DBConfig, lg *logger.L
 
 	// Create final configuration
 	dbConfig := &config.EncryptedDatabaseConfig{
-		Host:     inputConfig.Host,
-		Port:     inputConfig.Port,
-		User:     inputConfig.User,
-		Password: password,
+		Host:       inputConfig.Host,
+		Port:       inputConfig.Port,
+		User:       inputConfig.User,
+		Password:   password,
+		Socket:     dbcfg.ConnectionOptions.Socket,
+		AuthPlugin: dbcfg.ConnectionOptions.AuthPlugin,
+	}
+	if err := applyHosts(dbConfig, dbcfg.ConnectionOptions); err != nil {
+		return err
 	}
 
 	// Display summary
@@ -72,7 +77,7 @@ func (p *Processor) processInteractiveMode(dbcfg *structs.DBConfig, lg *logger.L
 	dbconfig.DisplayConfigSummary([]*dbconfig.ConfigInfo{configInfo})
 
 	// Confirm save
-	if !terminal.AskYesNo("Save this configuration?", true) {
+	if !terminal.Confirm("Save this configuration?", true) {
 		terminal.PrintWarning("Configuration not saved.")
 		return nil
 	}
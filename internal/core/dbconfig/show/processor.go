@@ -5,6 +5,7 @@ import (
 
 	"sfDBTools/internal/config"
 	coredbconfig "sfDBTools/internal/core/dbconfig"
+	"sfDBTools/utils/database/connection"
 	"sfDBTools/utils/dbconfig"
 	"sfDBTools/utils/terminal"
 )
@@ -113,4 +114,16 @@ func (p *Processor) displayDatabaseDetails(dbConfig *config.EncryptedDatabaseCon
 	terminal.PrintInfo(fmt.Sprintf("📁 Host: %s", dbConfig.Host))
 	terminal.PrintInfo(fmt.Sprintf("🔌 Port: %d", dbConfig.Port))
 	terminal.PrintInfo(fmt.Sprintf("👤 User: %s", dbConfig.User))
+	if len(dbConfig.Hosts) > 0 {
+		terminal.PrintInfo(fmt.Sprintf("🔀 Other hosts: %s", dbConfig.ExtraHosts()))
+		terminal.PrintInfo(fmt.Sprintf("🧭 Policy: %s", hostPolicy(dbConfig.Policy)))
+	}
+}
+
+// hostPolicy names the policy of a multi-host config, which defaults to first-available
+func hostPolicy(policy string) string {
+	if policy == "" {
+		return connection.PolicyFirstAvailable
+	}
+	return policy
 }
@@ -2,12 +2,12 @@ package dbconfig
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"time"
 
 	"sfDBTools/internal/config"
 	"sfDBTools/utils/common"
+	"sfDBTools/utils/database/connection"
 	"sfDBTools/utils/dbconfig"
 	"sfDBTools/utils/terminal"
 
@@ -83,6 +83,9 @@ func (vh *ValidationHelper) displayConfigInfo(dbConfig *config.EncryptedDatabase
 		{"🔌 Port", fmt.Sprintf("%d", dbConfig.Port)},
 		{"👤 Username", dbConfig.User},
 	}
+	if len(dbConfig.Hosts) > 0 {
+		rows = append(rows, []string{"🔀 Other hosts", dbConfig.ExtraHosts()}, []string{"🧭 Policy", dbConfig.Policy})
+	}
 	terminal.FormatTable(headers, rows)
 }
 
@@ -90,8 +93,21 @@ func (vh *ValidationHelper) displayConfigInfo(dbConfig *config.EncryptedDatabase
 func (vh *ValidationHelper) testDatabaseConnection(dbConfig *config.EncryptedDatabaseConfig, result *dbconfig.ValidationResult) error {
 	terminal.PrintSubHeader(" Database Connection Test")
 
-	// Build DSN for MySQL connection
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/", dbConfig.User, dbConfig.Password, dbConfig.Host, dbConfig.Port)
+	// Build DSN for MySQL connection, honouring the socket and auth plugin of the config
+	connConfig := connection.Config{
+		Host:       dbConfig.Host,
+		Port:       dbConfig.Port,
+		User:       dbConfig.User,
+		Password:   dbConfig.Password,
+		Socket:     dbConfig.Socket,
+		AuthPlugin: dbConfig.AuthPlugin,
+	}
+	dsn, err := connection.ServerDSN(connConfig)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("Connection not possible: %v", err))
+		terminal.PrintError("Database account cannot be used by this client")
+		return err
+	}
 
 	// Connection attempt with progress
 	spinner := terminal.NewProgressSpinner("Connecting to database...")
@@ -101,7 +117,7 @@ func (vh *ValidationHelper) testDatabaseConnection(dbConfig *config.EncryptedDat
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	db, err := sql.Open("mysql", dsn)
+	db, err := connection.Open(fmt.Sprintf("%s:%d", connConfig.Host, connConfig.Port), dsn)
 	if err != nil {
 		spinner.Stop()
 		result.Errors = append(result.Errors, fmt.Sprintf("Connection failed: %v", err))
@@ -114,6 +130,7 @@ func (vh *ValidationHelper) testDatabaseConnection(dbConfig *config.EncryptedDat
 	err = db.PingContext(ctx)
 	if err != nil {
 		spinner.Stop()
+		err = connection.DiagnoseAuthError(connConfig, err)
 		result.Errors = append(result.Errors, fmt.Sprintf("Connection test failed: %v", err))
 		terminal.PrintError("Database connection test failed")
 		return fmt.Errorf("database connection test failed: %w", err)
@@ -133,6 +150,52 @@ func (vh *ValidationHelper) testDatabaseConnection(dbConfig *config.EncryptedDat
 		terminal.PrintInfo(fmt.Sprintf("Server Version: %s", serverVersion))
 	}
 
+	if len(dbConfig.Hosts) > 0 {
+		return vh.testHosts(dbConfig, connConfig, result)
+	}
+	return nil
+}
+
+// testHosts probes every host of a multi-host config and checks that the
+// policy is known and a primary can be found
+func (vh *ValidationHelper) testHosts(dbConfig *config.EncryptedDatabaseConfig, connConfig connection.Config, result *dbconfig.ValidationResult) error {
+	terminal.PrintSubHeader(" Host Probe")
+	if err := connection.ValidatePolicy(dbConfig.Policy); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		terminal.PrintError(err.Error())
+		return err
+	}
+
+	candidates := []connection.Endpoint{{Host: dbConfig.Host, Port: dbConfig.Port}}
+	for _, h := range dbConfig.Hosts {
+		port := h.Port
+		if port == 0 {
+			port = dbConfig.Port
+		}
+		candidates = append(candidates, connection.Endpoint{Host: h.Host, Port: port})
+	}
+
+	spinner := terminal.NewProgressSpinner("Probing hosts...")
+	spinner.Start()
+	_, health, err := connection.SelectEndpoint(connConfig, candidates, dbConfig.Policy, connection.PurposePrimary)
+	spinner.Stop()
+
+	rows := make([][]string, 0, len(health))
+	for _, h := range health {
+		if !h.Reachable {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Host %s is unreachable", h.Endpoint))
+		}
+		rows = append(rows, []string{h.Endpoint.String(), h.State()})
+	}
+	terminal.FormatTable([]string{"Host", "State"}, rows)
+
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		terminal.PrintError("No primary found among the configured hosts")
+		return err
+	}
+	result.TestResults["host_probe"] = true
+	terminal.PrintSuccess("Primary found among the configured hosts")
 	return nil
 }
 
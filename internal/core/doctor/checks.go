@@ -0,0 +1,462 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	iofs "io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/config/model"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/schema"
+	"sfDBTools/utils/tempdir"
+)
+
+// namedServer is a database server together with where it was configured
+type namedServer struct {
+	Name   string
+	Config database.Config
+}
+
+func checkConfig(r *Report, opts Options) {
+	if opts.Config == nil {
+		detail := "config.yaml could not be loaded"
+		if opts.ConfigErr != nil {
+			detail = opts.ConfigErr.Error()
+		}
+		r.add(CategoryConfig, "config.yaml", StatusFail, detail,
+			"Place a valid config.yaml in ./config, <binary dir>/config or /etc/sfDBTools/config")
+		return
+	}
+	r.add(CategoryConfig, "config.yaml", StatusPass, filepath.Join(opts.ConfigDir, "config.yaml"), "")
+
+	dir := opts.Config.ConfigDir.DatabaseConfig
+	switch entries, err := os.ReadDir(dir); {
+	case dir == "":
+		r.add(CategoryConfig, "Database config directory", StatusWarn, "config_dir.database_config is not set",
+			"Set config_dir.database_config in config.yaml")
+	case err != nil:
+		r.add(CategoryConfig, "Database config directory", StatusWarn, err.Error(),
+			"Create the directory or generate a config with: sfDBTools dbconfig generate")
+	default:
+		n := 0
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), ".cnf.enc") {
+				n++
+			}
+		}
+		r.add(CategoryConfig, "Database config directory", StatusPass, fmt.Sprintf("%s (%d encrypted configs)", dir, n), "")
+	}
+
+	for _, tz := range []struct{ name, value string }{
+		{"general.locale.timezone", opts.Config.General.Locale.Timezone},
+		{"log.timezone", opts.Config.Log.Timezone},
+		{"maintenance.timezone", opts.Config.Maintenance.Timezone},
+	} {
+		if tz.value == "" {
+			continue
+		}
+		if _, err := time.LoadLocation(tz.value); err != nil {
+			r.add(CategoryConfig, tz.name, StatusFail, fmt.Sprintf("unknown timezone %q", tz.value),
+				"Use an IANA name such as Asia/Jakarta and make sure tzdata is installed")
+		}
+	}
+}
+
+func checkFilesystem(r *Report, cfg *model.Config) {
+	if cfg.Log.Output.File.Enabled {
+		checkWritable(r, "Log directory", cfg.Log.Output.File.Dir, StatusFail,
+			"Create the directory and give the sfDBTools user write access, or disable log.output.file")
+	}
+	checkWritable(r, "Temp directory", tempdir.Dir(), StatusFail,
+		"Point general.temp_dir (or --temp-dir) to a writable directory with enough free space")
+	checkWritable(r, "Job status directory", jobstatus.Dir(), StatusWarn,
+		"Set general.status_dir or SFDB_STATUS_DIR to a writable directory")
+	if cfg.Backup.Storage.BaseDirectory != "" {
+		checkWritable(r, "Backup directory", cfg.Backup.Storage.BaseDirectory, StatusFail,
+			"Mount the backup storage and give the sfDBTools user write access")
+	}
+}
+
+// checkWritable creates and removes a probe file in dir
+func checkWritable(r *Report, name, dir string, failStatus Status, hint string) {
+	if dir == "" {
+		r.add(CategoryFilesystem, name, StatusWarn, "not configured", hint)
+		return
+	}
+	if info, err := os.Stat(dir); err != nil {
+		r.add(CategoryFilesystem, name, failStatus, err.Error(), hint)
+		return
+	} else if !info.IsDir() {
+		r.add(CategoryFilesystem, name, failStatus, dir+" is not a directory", hint)
+		return
+	}
+	f, err := os.CreateTemp(dir, ".sfdb-doctor-*")
+	if err != nil {
+		r.add(CategoryFilesystem, name, failStatus, fmt.Sprintf("%s is not writable: %v", dir, err), hint)
+		return
+	}
+	f.Close()
+	os.Remove(f.Name())
+	r.add(CategoryFilesystem, name, StatusPass, dir, "")
+}
+
+func checkBinaries(r *Report, cfg *model.Config) {
+	const clientHint = "Install the MariaDB client package and make sure its binaries are in PATH"
+	binaries := []struct {
+		name    string
+		missing Status
+		purpose string
+		hint    string
+	}{
+		{"mysql", StatusFail, "restore and administration", clientHint},
+		{"mysqldump", StatusFail, "backups", clientHint},
+		{"mysqlbinlog", StatusWarn, "point-in-time recovery", clientHint},
+		{"rsync", StatusWarn, "data directory migration", "Install rsync"},
+		{"aws", StatusWarn, "remote backup storage", "Install the AWS CLI v2"},
+		{"mydumper", StatusWarn, "--engine mydumper backups", "Install mydumper, which also provides myloader"},
+		{"myloader", StatusWarn, "restoring mydumper backups", "Install mydumper, which also provides myloader"},
+		{"mariabackup", StatusWarn, "physical backups", "Install the MariaDB backup package (mariadb-backup)"},
+	}
+	for _, b := range binaries {
+		missing := b.missing
+		if b.name == "aws" && cfg.Backup.Remote.Bucket != "" {
+			missing = StatusFail
+		}
+		if b.name == "mydumper" && cfg.Backup.Engine == "mydumper" {
+			missing = StatusFail
+		}
+		path, err := exec.LookPath(b.name)
+		if err != nil {
+			r.add(CategoryBinaries, b.name, missing, "not found in PATH (needed for "+b.purpose+")", b.hint)
+			continue
+		}
+		r.add(CategoryBinaries, b.name, StatusPass, path, "")
+	}
+}
+
+// checkEncryption decrypts every encrypted database config with the password
+// from the environment and returns the servers they describe
+func checkEncryption(r *Report, cfg *model.Config) []namedServer {
+	password := os.Getenv(crypto.ENV_ENCRYPTION_PASSWORD)
+	if password == "" {
+		r.add(CategoryEncryption, "Encryption password", StatusWarn,
+			crypto.ENV_ENCRYPTION_PASSWORD+" is not set; commands will prompt for it",
+			"Export "+crypto.ENV_ENCRYPTION_PASSWORD+" or put it in a .env file next to the binary")
+		return nil
+	}
+
+	files, _ := filepath.Glob(filepath.Join(cfg.ConfigDir.DatabaseConfig, "*.cnf.enc"))
+	if cfg.ConfigDir.DatabaseConfig == "" || len(files) == 0 {
+		r.add(CategoryEncryption, "Encryption password", StatusPass,
+			"set in "+crypto.ENV_ENCRYPTION_PASSWORD+" (no encrypted configs to test it on)", "")
+		return nil
+	}
+
+	var servers []namedServer
+	var failed []string
+	for _, file := range files {
+		dbCfg, err := config.LoadEncryptedDatabaseConfigFromFile(file, password)
+		if err != nil {
+			failed = append(failed, filepath.Base(file))
+			continue
+		}
+		name := filepath.Base(file)
+		servers = append(servers, namedServer{Name: name, Config: database.Config{
+			Host: dbCfg.Host, Port: dbCfg.Port, User: dbCfg.User, Password: dbCfg.Password,
+		}})
+		for _, h := range dbCfg.Hosts {
+			port := h.Port
+			if port == 0 {
+				port = dbCfg.Port
+			}
+			servers = append(servers, namedServer{Name: name, Config: database.Config{
+				Host: h.Host, Port: port, User: dbCfg.User, Password: dbCfg.Password,
+			}})
+		}
+	}
+	if len(failed) > 0 {
+		r.add(CategoryEncryption, "Encryption password", StatusFail,
+			fmt.Sprintf("cannot decrypt %d of %d configs: %s", len(failed), len(files), strings.Join(failed, ", ")),
+			"Check "+crypto.ENV_ENCRYPTION_PASSWORD+", or encrypt the configs under one password with: sfDBTools profile rekey <name>")
+		return servers
+	}
+	r.add(CategoryEncryption, "Encryption password", StatusPass,
+		fmt.Sprintf("decrypts all %d configs", len(files)), "")
+	return servers
+}
+
+// checkNetwork dials every configured target and returns the database
+// servers that answered
+func checkNetwork(r *Report, cfg *model.Config, servers []namedServer, timeout time.Duration) []namedServer {
+	type target struct {
+		name, addr string
+		server     *namedServer
+		err        error
+	}
+	var targets []*target
+	for i := range servers {
+		s := &servers[i]
+		targets = append(targets, &target{
+			name:   "Database " + s.Name,
+			addr:   net.JoinHostPort(s.Config.Host, strconv.Itoa(s.Config.Port)),
+			server: s,
+		})
+	}
+	for _, s := range cfg.MaxScale.Servers {
+		targets = append(targets, &target{name: "MaxScale backend", addr: s})
+	}
+	if cfg.MaxScale.APIHost != "" && cfg.MaxScale.APIPort > 0 {
+		targets = append(targets, &target{name: "MaxScale REST API",
+			addr: net.JoinHostPort(cfg.MaxScale.APIHost, strconv.Itoa(cfg.MaxScale.APIPort))})
+	}
+	if addr := urlAddress(cfg.Notify.WebhookURL); addr != "" {
+		targets = append(targets, &target{name: "Notification webhook", addr: addr})
+	}
+	if addr := urlAddress(cfg.Notify.Slack.WebhookURL); addr != "" {
+		targets = append(targets, &target{name: "Slack webhook", addr: addr})
+	}
+	if email := cfg.Notify.Email; email.Host != "" {
+		port := email.Port
+		if port == 0 {
+			port = 587
+			if strings.EqualFold(email.TLS, "tls") {
+				port = 465
+			}
+		}
+		targets = append(targets, &target{name: "SMTP server", addr: net.JoinHostPort(email.Host, strconv.Itoa(port))})
+	}
+	if addr := urlAddress(cfg.Backup.Remote.EndpointURL); addr != "" {
+		targets = append(targets, &target{name: "Remote storage endpoint", addr: addr})
+	}
+	if len(targets) == 0 {
+		r.add(CategoryNetwork, "Targets", StatusWarn, "no network targets configured",
+			"Set "+crypto.ENV_ENCRYPTION_PASSWORD+" so the database configs can be read")
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Add(1)
+		go func(t *target) {
+			defer wg.Done()
+			conn, err := net.DialTimeout("tcp", t.addr, timeout)
+			if err == nil {
+				conn.Close()
+			}
+			t.err = err
+		}(t)
+	}
+	wg.Wait()
+
+	var reachable []namedServer
+	for _, t := range targets {
+		if t.err != nil {
+			r.add(CategoryNetwork, t.name, StatusFail, fmt.Sprintf("%s: %v", t.addr, t.err),
+				"Check that the service is running and that firewalls allow this host to reach it")
+			continue
+		}
+		r.add(CategoryNetwork, t.name, StatusPass, t.addr, "")
+		if t.server != nil {
+			reachable = append(reachable, *t.server)
+		}
+	}
+	return reachable
+}
+
+// urlAddress returns host:port of a URL, with the port defaulted from its scheme
+func urlAddress(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// checkCatalog verifies that every backup manifest can be read and still
+// points at its artifact, and that the drill catalog parses
+func checkCatalog(r *Report, cfg *model.Config) {
+	baseDir := cfg.Backup.Storage.BaseDirectory
+	if baseDir != "" {
+		if _, err := os.Stat(baseDir); err == nil {
+			checkManifests(r, baseDir)
+		}
+	}
+
+	path := cfg.Backup.Drill.Catalog
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		r.add(CategoryCatalog, "Restore drill catalog", StatusPass, path+" (no drills recorded yet)", "")
+		return
+	}
+	if err != nil {
+		r.add(CategoryCatalog, "Restore drill catalog", StatusFail, err.Error(),
+			"Give the sfDBTools user read access to "+path)
+		return
+	}
+	// Parsed here instead of with drill.LoadCatalog, which upgrades old
+	// catalogs in place; the doctor only reads
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		r.add(CategoryCatalog, "Restore drill catalog", StatusFail, fmt.Sprintf("%s: %v", path, err),
+			"Restore the catalog from a backup or move it aside to start a new one")
+		return
+	}
+	records := 0
+	switch v := doc.(type) {
+	case []any:
+		records = len(v)
+	case map[string]any:
+		if version, _ := v["schema_version"].(float64); int(version) > schema.CatalogVersion {
+			r.add(CategoryCatalog, "Restore drill catalog", StatusFail,
+				fmt.Sprintf("%s has schema version %d, this build reads up to %d", path, int(version), schema.CatalogVersion),
+				"Upgrade sfDBTools to the version that wrote the catalog")
+			return
+		}
+		list, _ := v["records"].([]any)
+		records = len(list)
+	default:
+		r.add(CategoryCatalog, "Restore drill catalog", StatusFail, path+": expected an object or array",
+			"Restore the catalog from a backup or move it aside to start a new one")
+		return
+	}
+	r.add(CategoryCatalog, "Restore drill catalog", StatusPass, fmt.Sprintf("%s (%d drills)", path, records), "")
+}
+
+func checkManifests(r *Report, baseDir string) {
+	var manifests int
+	var unreadable, missing, mismatched []string
+	filepath.WalkDir(baseDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		meta, ok := backup_utils.ReadBackupManifest(path)
+		if !ok {
+			if strings.HasSuffix(d.Name(), ".meta.json") {
+				unreadable = append(unreadable, path)
+			}
+			return nil
+		}
+		manifests++
+		info, err := os.Stat(backup_utils.ArtifactPath(path, meta))
+		switch {
+		case err != nil:
+			// Kept only remotely is fine as long as the last reconcile found it there
+			if meta.Remote == nil || (meta.Remote.Status != backup_utils.RemoteStatusPresent &&
+				meta.Remote.Status != backup_utils.RemoteStatusUploaded) {
+				missing = append(missing, path)
+			}
+		case meta.FileSize > 0 && info.Size() != meta.FileSize:
+			mismatched = append(mismatched, path)
+		}
+		return nil
+	})
+
+	if len(unreadable) > 0 {
+		r.add(CategoryCatalog, "Unreadable manifests", StatusFail, summarize(unreadable),
+			"Run sfDBTools catalog upgrade --dry-run to see which manifests cannot be read")
+	}
+	if len(missing) > 0 {
+		r.add(CategoryCatalog, "Missing artifacts", StatusFail, summarize(missing),
+			"Run sfDBTools catalog reconcile, or remove manifests of backups that were deleted by hand")
+	}
+	if len(mismatched) > 0 {
+		r.add(CategoryCatalog, "Artifact size mismatch", StatusFail, summarize(mismatched),
+			"The backup file changed after it was written; verify it before relying on it")
+	}
+	if len(unreadable)+len(missing)+len(mismatched) == 0 {
+		r.add(CategoryCatalog, "Backup manifests", StatusPass,
+			fmt.Sprintf("%d manifests in %s, all artifacts present", manifests, baseDir), "")
+	}
+}
+
+// summarize lists up to three paths and the number of the rest
+func summarize(paths []string) string {
+	const shown = 3
+	if len(paths) <= shown {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:shown], ", "), len(paths)-shown)
+}
+
+// checkClock compares the clock of each server with the local clock. Backup
+// names, retention and drill ages all mix local and server time, so skew
+// shows up as backups in the wrong window or pruned too early.
+func checkClock(r *Report, cfg *model.Config, servers []namedServer, maxSkew time.Duration) {
+	if len(servers) == 0 {
+		r.add(CategoryClock, "Clock skew", StatusWarn, "no reachable server to compare with",
+			"Pass --config or --source_host, or set "+crypto.ENV_ENCRYPTION_PASSWORD+" so configured servers are checked")
+		return
+	}
+	for _, s := range servers {
+		name := fmt.Sprintf("Clock skew %s (%s:%d)", s.Name, s.Config.Host, s.Config.Port)
+		skew, err := serverSkew(s.Config)
+		if err != nil {
+			r.add(CategoryClock, name, StatusWarn, err.Error(), "Check the credentials of the database config")
+			continue
+		}
+		detail := fmt.Sprintf("server clock is %s %s", skew.Abs().Round(time.Millisecond), direction(skew))
+		switch abs := skew.Abs(); {
+		case abs > maxSkew:
+			r.add(CategoryClock, name, StatusFail, detail, "Enable NTP (chronyd or systemd-timesyncd) on both hosts")
+		case abs > time.Second:
+			r.add(CategoryClock, name, StatusWarn, detail, "Enable NTP (chronyd or systemd-timesyncd) on both hosts")
+		default:
+			r.add(CategoryClock, name, StatusPass, detail, "")
+		}
+	}
+}
+
+// serverSkew returns how far the server clock is ahead of the local clock,
+// measured against the midpoint of the round trip
+func serverSkew(cfg database.Config) (time.Duration, error) {
+	db, err := database.GetWithoutDB(cfg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		return 0, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	before := time.Now()
+	var micros int64
+	if err := db.QueryRow("SELECT CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED)").Scan(&micros); err != nil {
+		return 0, fmt.Errorf("failed to read server time: %w", err)
+	}
+	after := time.Now()
+	local := before.Add(after.Sub(before) / 2)
+	return time.UnixMicro(micros).Sub(local), nil
+}
+
+func direction(skew time.Duration) string {
+	if skew < 0 {
+		return "behind"
+	}
+	return "ahead"
+}
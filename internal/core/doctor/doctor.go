@@ -0,0 +1,110 @@
+package doctor
+
+import (
+	"time"
+
+	"sfDBTools/internal/config/model"
+	"sfDBTools/utils/database"
+)
+
+// Status is the outcome of a single check
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Categories in report order
+const (
+	CategoryConfig     = "Configuration"
+	CategoryFilesystem = "Filesystem"
+	CategoryBinaries   = "Client binaries"
+	CategoryEncryption = "Encryption"
+	CategoryNetwork    = "Network"
+	CategoryCatalog    = "Catalog"
+	CategoryClock      = "Clock"
+)
+
+// Check is one diagnostic; Hint says how to fix a warning or failure
+type Check struct {
+	Category string
+	Name     string
+	Status   Status
+	Detail   string
+	Hint     string
+}
+
+// Report holds the checks in the order they ran
+type Report struct {
+	Checks []Check
+}
+
+// Count returns how many checks ended in status
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Categories returns the categories that have checks, in report order
+func (r *Report) Categories() []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, c := range r.Checks {
+		if !seen[c.Category] {
+			seen[c.Category] = true
+			out = append(out, c.Category)
+		}
+	}
+	return out
+}
+
+func (r *Report) add(category, name string, status Status, detail, hint string) {
+	r.Checks = append(r.Checks, Check{Category: category, Name: name, Status: status, Detail: detail, Hint: hint})
+}
+
+// Options controls Run
+type Options struct {
+	Config    *model.Config // nil when config.yaml could not be loaded
+	ConfigErr error         // why config.yaml could not be loaded
+	ConfigDir string        // directory config.yaml was loaded from
+	// Server to measure clock skew against; nil uses the servers of the
+	// encrypted database configs that are reachable
+	Server      *database.Config
+	DialTimeout time.Duration // per network target
+	MaxSkew     time.Duration // clock skew above this fails
+}
+
+// Run checks the environment sfDBTools runs in. It never prompts: the
+// encryption password is only taken from the environment. Checks that need
+// config.yaml are skipped when it could not be loaded.
+func Run(opts Options) *Report {
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 3 * time.Second
+	}
+	if opts.MaxSkew <= 0 {
+		opts.MaxSkew = 5 * time.Second
+	}
+
+	r := &Report{}
+	checkConfig(r, opts)
+	if opts.Config == nil {
+		return r
+	}
+	checkFilesystem(r, opts.Config)
+	checkBinaries(r, opts.Config)
+	servers := checkEncryption(r, opts.Config)
+	reachable := checkNetwork(r, opts.Config, servers, opts.DialTimeout)
+	checkCatalog(r, opts.Config)
+	if opts.Server != nil {
+		reachable = []namedServer{{Name: "--config / --source_host", Config: *opts.Server}}
+	}
+	checkClock(r, opts.Config, reachable, opts.MaxSkew)
+	return r
+}
@@ -0,0 +1,257 @@
+package dr
+
+import (
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sfDBTools/internal/core/serverstate"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	mariadb_versions "sfDBTools/utils/mariadb/versions"
+)
+
+// allDatabases is the backup type of 'backup all'
+const allDatabases = "all_databases"
+
+// Prepare returns the state of the rebuild: the saved one when the state file
+// exists for the same host, otherwise a new plan built from the catalog. With
+// a remote store, manifests missing locally are downloaded first.
+func Prepare(opts Options) (*State, error) {
+	lg, _ := logger.Get()
+
+	if !opts.Restart {
+		state, ok, err := LoadState(opts.StatePath)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if state.SourceHost != opts.SourceHost {
+				return nil, fmt.Errorf("state file %s belongs to the rebuild of %s; use another --state-file or --restart", opts.StatePath, state.SourceHost)
+			}
+			lg.Info("Resuming host rebuild", logger.String("state_file", opts.StatePath), logger.String("source_host", state.SourceHost))
+			return state, nil
+		}
+	}
+
+	if opts.Remote != nil {
+		if err := syncManifests(opts.Remote, opts.BaseDir); err != nil {
+			return nil, err
+		}
+	}
+
+	backups, versions, err := findBackups(opts.BaseDir, opts.SourceHost)
+	if err != nil {
+		return nil, err
+	}
+	plan := Plan{
+		ServerConfig: opts.ServerConfig,
+		ConfigPath:   opts.ConfigPath,
+		UsersFile:    opts.UsersFile,
+		Backups:      backups,
+	}
+	if plan.ConfigPath == "" {
+		plan.ConfigPath = defaultConfigPath
+	}
+
+	var bundle *serverstate.Bundle
+	if plan.ServerState = opts.ServerStateFile; plan.ServerState != "" {
+		if bundle, err = serverstate.Load(plan.ServerState); err != nil {
+			return nil, err
+		}
+	} else if plan.ServerState, bundle = findServerState(opts.BaseDir, opts.SourceHost); bundle != nil {
+		lg.Info("Using newest server state bundle of the host", logger.String("file", plan.ServerState))
+	}
+
+	switch {
+	case opts.Version != "":
+		plan.MariaDBVersion, plan.VersionSource = opts.Version, "--version"
+	case bundle != nil && mariadb_versions.Extract(bundle.ServerVersion) != "":
+		plan.MariaDBVersion, plan.VersionSource = mariadb_versions.Extract(bundle.ServerVersion), "server state bundle"
+	case len(versions) > 0:
+		plan.MariaDBVersion, plan.VersionSource = versions[0], "backup manifest"
+	default:
+		return nil, fmt.Errorf("no MariaDB version is recorded for %s; use --version", opts.SourceHost)
+	}
+	if len(plan.Backups) == 0 {
+		return nil, fmt.Errorf("no full backup of %s found in %s", opts.SourceHost, opts.BaseDir)
+	}
+
+	state := newState(opts, plan)
+	if err := state.Save(opts.StatePath); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// SourceHosts lists the hosts that have backups in the catalog
+func SourceHosts(baseDir string) ([]string, error) {
+	seen := make(map[string]bool)
+	err := walkManifests(baseDir, func(_ string, meta *backup_utils.BackupMetadata) {
+		seen[meta.Host] = true
+	})
+	hosts := make([]string, 0, len(seen))
+	for h := range seen {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	return hosts, err
+}
+
+// findBackups picks the newest all-databases backup of host and, for every
+// database, a single-database backup taken after it. Without an
+// all-databases backup the newest single backup of each database is used.
+// versions lists the recorded server versions, newest first.
+func findBackups(baseDir, host string) (backups []Backup, versions []string, err error) {
+	var all *Backup
+	singles := make(map[string]Backup)
+	type dated struct {
+		version string
+		backup  Backup
+	}
+	var recorded []dated
+
+	err = walkManifests(baseDir, func(path string, meta *backup_utils.BackupMetadata) {
+		if meta.Host != host {
+			return
+		}
+		b := Backup{
+			Manifest: path,
+			File:     backup_utils.ArtifactPath(path, meta),
+			Type:     meta.BackupType,
+			Date:     meta.BackupDate,
+		}
+		if meta.Remote != nil {
+			b.RemoteKey = meta.Remote.Key
+		}
+		if meta.MySQLVersion != "" {
+			recorded = append(recorded, dated{version: mariadb_versions.Extract(meta.MySQLVersion), backup: b})
+		}
+		switch meta.BackupType {
+		case allDatabases:
+			if all == nil || b.Date.After(all.Date) {
+				all = &b
+			}
+		case "single":
+			b.Database = meta.DatabaseName
+			if meta.DatabaseInfo != nil {
+				b.Tables = meta.DatabaseInfo.TableCount
+			}
+			if prev, ok := singles[b.Database]; !ok || b.Date.After(prev.Date) {
+				singles[b.Database] = b
+			}
+		}
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read backup catalog %s: %w", baseDir, err)
+	}
+
+	if all != nil {
+		backups = append(backups, *all)
+	}
+	names := make([]string, 0, len(singles))
+	for db := range singles {
+		names = append(names, db)
+	}
+	sort.Strings(names)
+	for _, db := range names {
+		if b := singles[db]; all == nil || b.Date.After(all.Date) {
+			backups = append(backups, b)
+		}
+	}
+
+	sort.SliceStable(recorded, func(i, j int) bool { return recorded[i].backup.Date.After(recorded[j].backup.Date) })
+	for _, r := range recorded {
+		if r.version != "" {
+			versions = append(versions, r.version)
+		}
+	}
+	return backups, versions, nil
+}
+
+// findServerState returns the newest bundle of host below baseDir
+func findServerState(baseDir, host string) (string, *serverstate.Bundle) {
+	var newestPath string
+	var newest *serverstate.Bundle
+	_ = filepath.WalkDir(baseDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Base(filepath.Dir(path)) != "serverstate" || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		b, err := serverstate.Load(path)
+		if err != nil || b.Host != host {
+			return nil
+		}
+		if newest == nil || b.CreatedAt.After(newest.CreatedAt) {
+			newestPath, newest = path, b
+		}
+		return nil
+	})
+	return newestPath, newest
+}
+
+func walkManifests(baseDir string, fn func(path string, meta *backup_utils.BackupMetadata)) error {
+	if _, err := os.Stat(baseDir); err != nil {
+		return fmt.Errorf("backup directory not accessible: %w", err)
+	}
+	return filepath.WalkDir(baseDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		if meta, ok := backup_utils.ReadBackupManifest(path); ok {
+			fn(path, meta)
+		}
+		return nil
+	})
+}
+
+// syncManifests downloads the JSON objects (manifests and server state
+// bundles) of the bucket that are missing below baseDir, so a host whose
+// local backup directory was lost can still be planned from remote storage
+func syncManifests(store *backup_utils.RemoteStore, baseDir string) error {
+	lg, _ := logger.Get()
+	objects, err := store.List()
+	if err != nil {
+		return err
+	}
+	fetched := 0
+	for key := range objects {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		local := filepath.Join(baseDir, filepath.FromSlash(store.RelPath(key)))
+		if _, err := os.Stat(local); err == nil {
+			continue
+		}
+		if err := store.Download(key, local); err != nil {
+			return err
+		}
+		fetched++
+	}
+	lg.Info("Synchronized backup catalog from remote storage", logger.Int("objects", len(objects)), logger.Int("downloaded", fetched))
+	return nil
+}
+
+// fetchArtifact downloads the file of b when it is missing locally
+func fetchArtifact(opts Options, b Backup) error {
+	if _, err := os.Stat(b.File); err == nil {
+		return nil
+	}
+	if opts.Remote == nil {
+		return fmt.Errorf("backup file %s is missing and no remote storage is configured", b.File)
+	}
+	key := b.RemoteKey
+	if key == "" {
+		rel, err := filepath.Rel(opts.BaseDir, b.File)
+		if err != nil {
+			return fmt.Errorf("backup file %s is outside %s: %w", b.File, opts.BaseDir, err)
+		}
+		key = opts.Remote.Key(rel)
+	}
+	return opts.Remote.Download(key, b.File)
+}
@@ -0,0 +1,142 @@
+package dr
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"sfDBTools/internal/core/mariadb/users"
+	"sfDBTools/internal/core/serverstate"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/database/info"
+	mariadb_versions "sfDBTools/utils/mariadb/versions"
+	"sfDBTools/utils/system"
+)
+
+// HealthChecks verifies the rebuilt server: the service runs, the version
+// matches the recorded one, the restored databases hold the tables recorded at
+// backup time, the imported accounts exist and a configured replica runs
+func HealthChecks(ctx context.Context, opts Options, state *State) []Check {
+	var checks []Check
+	checks = append(checks, Check{
+		Name:   "service",
+		OK:     system.NewServiceManager().IsActive(serviceName),
+		Detail: serviceName + " service active",
+	})
+
+	db, err := database.GetWithoutDB(opts.Target)
+	if err != nil {
+		return append(checks, Check{Name: "connection", Detail: err.Error()})
+	}
+	defer db.Close()
+	checks = append(checks, Check{Name: "connection", OK: true, Detail: fmt.Sprintf("%s:%d", opts.Target.Host, opts.Target.Port)})
+
+	if version, err := (mariadb_versions.QueryFetcher{DB: db}).Fetch(); err != nil {
+		checks = append(checks, Check{Name: "version", Detail: err.Error()})
+	} else {
+		checks = append(checks, Check{
+			Name:   "version",
+			OK:     mariadb_versions.SameSeries(version, state.Plan.MariaDBVersion),
+			Detail: fmt.Sprintf("running %s, recorded %s", version, state.Plan.MariaDBVersion),
+		})
+	}
+
+	for _, b := range state.Plan.Backups {
+		if b.Database == "" {
+			checks = append(checks, userSchemasCheck(db))
+			continue
+		}
+		checks = append(checks, databaseCheck(ctx, opts.Target, b))
+	}
+
+	if state.Plan.UsersFile != "" {
+		checks = append(checks, accountsCheck(db, state.Plan.UsersFile))
+	}
+	if state.Plan.ServerState != "" {
+		if bundle, err := serverstate.Load(state.Plan.ServerState); err == nil && bundle.Replication != nil {
+			checks = append(checks, replicaCheck(db))
+		}
+	}
+	return checks
+}
+
+func userSchemasCheck(db *sql.DB) Check {
+	var n int
+	err := db.QueryRow("SELECT COUNT(*) FROM information_schema.SCHEMATA WHERE SCHEMA_NAME NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')").Scan(&n)
+	if err != nil {
+		return Check{Name: "databases", Detail: err.Error()}
+	}
+	return Check{Name: "databases", OK: n > 0, Detail: fmt.Sprintf("%d user database(s) after the all-databases restore", n)}
+}
+
+func databaseCheck(ctx context.Context, target database.Config, b Backup) Check {
+	name := "database " + b.Database
+	target.DBName = b.Database
+	info.InvalidateDatabaseInfo(target)
+	restored, err := info.GetDatabaseInfo(ctx, target)
+	if err != nil {
+		return Check{Name: name, Detail: err.Error()}
+	}
+	if b.Tables == 0 {
+		return Check{Name: name, OK: restored.TableCount > 0, Detail: fmt.Sprintf("%d table(s); none recorded at backup time", restored.TableCount)}
+	}
+	return Check{
+		Name:   name,
+		OK:     restored.TableCount == b.Tables,
+		Detail: fmt.Sprintf("%d table(s), backup recorded %d", restored.TableCount, b.Tables),
+	}
+}
+
+func accountsCheck(db *sql.DB, file string) Check {
+	doc, err := users.Load(file)
+	if err != nil {
+		return Check{Name: "accounts", Detail: err.Error()}
+	}
+	missing := []string{}
+	for _, acc := range doc.Users {
+		var n int
+		if err := db.QueryRow("SELECT COUNT(*) FROM mysql.user WHERE User = ? AND Host = ?", acc.User, acc.Host).Scan(&n); err != nil {
+			return Check{Name: "accounts", Detail: err.Error()}
+		}
+		if n == 0 && !acc.IsRole {
+			missing = append(missing, acc.Name())
+		}
+	}
+	if len(missing) > 0 {
+		return Check{Name: "accounts", Detail: "missing: " + strings.Join(missing, ", ")}
+	}
+	return Check{Name: "accounts", OK: true, Detail: fmt.Sprintf("%d account(s) present", len(doc.Users))}
+}
+
+// replicaCheck reports the replication threads; a replica that was configured
+// but not started (no --start-replica) fails so the operator notices
+func replicaCheck(db *sql.DB) Check {
+	rows, err := db.Query("SHOW SLAVE STATUS")
+	if err != nil {
+		return Check{Name: "replication", Detail: err.Error()}
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return Check{Name: "replication", Detail: "replication recorded for the host but not configured"}
+	}
+	cols, _ := rows.Columns()
+	values := make([]sql.RawBytes, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return Check{Name: "replication", Detail: err.Error()}
+	}
+	status := make(map[string]string, len(cols))
+	for i, c := range cols {
+		status[c] = string(values[i])
+	}
+	io, sqlThread := status["Slave_IO_Running"], status["Slave_SQL_Running"]
+	return Check{
+		Name:   "replication",
+		OK:     io == "Yes" && sqlThread == "Yes",
+		Detail: fmt.Sprintf("IO %s, SQL %s, %s seconds behind", io, sqlThread, status["Seconds_Behind_Master"]),
+	}
+}
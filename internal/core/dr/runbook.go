@@ -0,0 +1,322 @@
+package dr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"sfDBTools/internal/core/mariadb/install"
+	"sfDBTools/internal/core/mariadb/users"
+	restoreAll "sfDBTools/internal/core/restore/all"
+	restoreSingle "sfDBTools/internal/core/restore/single"
+	restoreUtils "sfDBTools/internal/core/restore/utils"
+	"sfDBTools/internal/core/serverstate"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+	mariadb_config "sfDBTools/utils/mariadb/config"
+	"sfDBTools/utils/system"
+	"sfDBTools/utils/terminal"
+)
+
+// errSkipped marks a step that has nothing to do; its message is the reason
+type errSkipped struct{ reason string }
+
+func (e errSkipped) Error() string { return e.reason }
+
+func skip(format string, args ...interface{}) error {
+	return errSkipped{reason: fmt.Sprintf(format, args...)}
+}
+
+// step runs one runbook step and returns a detail for the record
+type step func(ctx context.Context, opts Options, state *State, record *StepRecord) (string, error)
+
+var steps = map[string]step{
+	StepDetectOS:     detectOS,
+	StepInstall:      installMariaDB,
+	StepServerConfig: applyServerConfig,
+	StepServerState:  applyServerState,
+	StepRestore:      restoreBackups,
+	StepUsers:        recreateUsers,
+	StepEvents:       applyEventsAndReplication,
+	StepHealthCheck:  runHealthChecks,
+}
+
+// Run executes the steps that are not done yet, saving the state after each
+// one. It stops at the first failing step; running again retries it.
+func Run(ctx context.Context, opts Options, state *State) error {
+	job := jobstatus.Start("dr-restore-host", opts.SourceHost)
+	err := run(ctx, opts, state, job)
+	job.Finish(err)
+	return err
+}
+
+func run(ctx context.Context, opts Options, state *State, job *jobstatus.Tracker) error {
+	lg, _ := logger.Get()
+
+	for i, name := range Steps {
+		record := state.Step(name)
+		if record.Status == StatusDone || record.Status == StatusSkipped {
+			lg.Info("Step already completed, skipping", logger.String("step", name), logger.String("status", record.Status))
+			continue
+		}
+
+		terminal.Headers(fmt.Sprintf("Step %d/%d: %s", i+1, len(Steps), name))
+		job.SetStep(name)
+		started := time.Now().UTC()
+		record.StartedAt, record.FinishedAt, record.Error = &started, nil, ""
+		record.Attempts++
+		state.record(name, "started (attempt %d)", record.Attempts)
+		if err := state.Save(opts.StatePath); err != nil {
+			return err
+		}
+		lg.Info("Rebuild step started", logger.String("step", name), logger.Int("attempt", record.Attempts))
+
+		detail, err := steps[name](ctx, opts, state, record)
+
+		finished := time.Now().UTC()
+		record.FinishedAt = &finished
+		record.Duration = finished.Sub(started).Round(time.Second).String()
+		var skipped errSkipped
+		switch {
+		case errors.As(err, &skipped):
+			record.Status, record.Detail = StatusSkipped, skipped.reason
+			state.record(name, "skipped: %s", skipped.reason)
+			lg.Info("Rebuild step skipped", logger.String("step", name), logger.String("reason", skipped.reason))
+			terminal.PrintInfo("Skipped: " + skipped.reason)
+		case err != nil:
+			record.Status, record.Detail, record.Error = StatusFailed, detail, err.Error()
+			state.record(name, "failed: %v", err)
+			lg.Error("Rebuild step failed", logger.String("step", name), logger.Error(err))
+		default:
+			record.Status, record.Detail = StatusDone, detail
+			state.record(name, "done in %s: %s", record.Duration, detail)
+			lg.Info("Rebuild step done", logger.String("step", name), logger.String("duration", record.Duration), logger.String("detail", detail))
+			terminal.PrintSuccess(detail)
+		}
+		if saveErr := state.Save(opts.StatePath); saveErr != nil && err == nil {
+			err = saveErr
+		}
+		if err != nil && record.Status == StatusFailed {
+			return fmt.Errorf("step %s failed: %w", name, err)
+		}
+	}
+	state.record("", "rebuild of %s completed", state.SourceHost)
+	return state.Save(opts.StatePath)
+}
+
+func detectOS(ctx context.Context, opts Options, state *State, record *StepRecord) (string, error) {
+	info, err := system.DetectOS()
+	if err != nil {
+		return "", err
+	}
+	if err := system.ValidateOperatingSystem(); err != nil {
+		return fmt.Sprintf("%s %s", info.Name, info.Version), err
+	}
+	return fmt.Sprintf("%s %s (%s packages)", info.Name, info.Version, info.PackageType), nil
+}
+
+func installMariaDB(ctx context.Context, opts Options, state *State, record *StepRecord) (string, error) {
+	if system.NewServiceManager().IsActive(serviceName) {
+		return "", skip("MariaDB is already installed and running; check that it is version %s", state.Plan.MariaDBVersion)
+	}
+	if err := system.CheckPrivileges(); err != nil {
+		return "", err
+	}
+	cfg := &mariadb_config.MariaDBInstallConfig{Version: state.Plan.MariaDBVersion, NonInteractive: true}
+	if err := install.RunMariaDBInstall(ctx, cfg, opts.Configure); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("MariaDB %s installed (version from %s)", state.Plan.MariaDBVersion, state.Plan.VersionSource), nil
+}
+
+// applyServerConfig installs the saved my.cnf in place of the configuration
+// written by the installer, keeping the latter, and restarts the server
+func applyServerConfig(ctx context.Context, opts Options, state *State, record *StepRecord) (string, error) {
+	src, dst := state.Plan.ServerConfig, state.Plan.ConfigPath
+	if src == "" {
+		return "", skip("no saved server configuration given (--server-config)")
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", fmt.Errorf("saved server configuration not accessible: %w", err)
+	}
+	kept := ""
+	if current, err := os.Stat(dst); err == nil {
+		kept = fmt.Sprintf("%s.dr-%s.bak", dst, time.Now().Format("20060102_150405"))
+		if err := fs.CopyFile(dst, kept, current); err != nil {
+			return "", fmt.Errorf("failed to keep the current configuration: %w", err)
+		}
+	}
+	if err := fs.CopyFile(src, dst, info); err != nil {
+		return "", fmt.Errorf("failed to install %s as %s: %w", src, dst, err)
+	}
+	services := system.NewServiceManager()
+	if err := services.Restart(serviceName); err != nil {
+		return "", fmt.Errorf("server did not restart with the saved configuration (previous one kept as %s): %w", kept, err)
+	}
+	if !services.IsActive(serviceName) {
+		return "", fmt.Errorf("server is not running with the saved configuration (previous one kept as %s)", kept)
+	}
+	detail := fmt.Sprintf("%s installed as %s and server restarted", src, dst)
+	if kept != "" {
+		detail += "; previous configuration kept as " + kept
+	}
+	return detail, nil
+}
+
+// applyServerState applies plugins, UDFs and variables before the data is
+// restored; events and replication wait until the schemas exist
+func applyServerState(ctx context.Context, opts Options, state *State, record *StepRecord) (string, error) {
+	return applyBundle(opts, state, serverstate.SectionPlugins, serverstate.SectionUDFs, serverstate.SectionVariables)
+}
+
+func applyEventsAndReplication(ctx context.Context, opts Options, state *State, record *StepRecord) (string, error) {
+	return applyBundle(opts, state, serverstate.SectionEvents, serverstate.SectionReplication)
+}
+
+func applyBundle(opts Options, state *State, sections ...string) (string, error) {
+	if state.Plan.ServerState == "" {
+		return "", skip("no server state bundle of %s found (take one with 'backup serverstate')", state.SourceHost)
+	}
+	bundle, err := serverstate.Load(state.Plan.ServerState)
+	if err != nil {
+		return "", err
+	}
+	db, err := database.GetWithoutDB(opts.Target)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s:%d: %w", opts.Target.Host, opts.Target.Port, err)
+	}
+	defer db.Close()
+
+	applyOpts := serverstate.ApplyOptions{
+		Sections:            map[string]bool{},
+		ReplicationPassword: opts.ReplicationPassword,
+		StartReplica:        opts.StartReplica,
+	}
+	for _, s := range sections {
+		applyOpts.Sections[s] = true
+	}
+	actions, err := serverstate.Plan(db, bundle, applyOpts)
+	if err != nil {
+		return "", err
+	}
+	if len(actions) == 0 {
+		return "", skip("the bundle has nothing to apply for %v", sections)
+	}
+	applied, failed := serverstate.Apply(db, actions)
+	for _, a := range actions {
+		switch {
+		case a.Err != nil:
+			terminal.PrintError(fmt.Sprintf("%s %s: %v", a.Section, a.Name, a.Err))
+		case a.Skip != "":
+			terminal.PrintWarning(fmt.Sprintf("%s %s not applied: %s", a.Section, a.Name, a.Skip))
+		}
+	}
+	detail := fmt.Sprintf("%d applied, %d not applied (see log)", applied, len(actions)-applied-failed)
+	if failed > 0 {
+		return detail, fmt.Errorf("%d of %d server state object(s) failed to apply", failed, applied+failed)
+	}
+	return detail, nil
+}
+
+// restoreBackups restores the planned backups in order, remembering the ones
+// already restored; single-database restores stop at the first failing
+// statement and skip the tables a failed earlier attempt loaded completely
+func restoreBackups(ctx context.Context, opts Options, state *State, record *StepRecord) (string, error) {
+	lg, _ := logger.Get()
+	for _, b := range state.Plan.Backups {
+		if slices.Contains(record.Completed, b.Manifest) {
+			lg.Info("Backup already restored, skipping", logger.String("file", b.File))
+			continue
+		}
+		if err := fetchArtifact(opts, b); err != nil {
+			return restoredDetail(record, state), err
+		}
+		options := restoreUtils.RestoreOptions{
+			Host:           opts.Target.Host,
+			Port:           opts.Target.Port,
+			User:           opts.Target.User,
+			Password:       opts.Target.Password,
+			File:           b.File,
+			VerifyChecksum: true,
+		}
+		var err error
+		if b.Database == "" {
+			err = restoreAll.RestoreAll(ctx, options)
+		} else {
+			options.DBName = b.Database
+			options.StopOnError = true
+			options.SkipLoaded = true
+			err = restoreSingle.RestoreSingle(ctx, options)
+		}
+		if err != nil {
+			return restoredDetail(record, state), fmt.Errorf("restore of %s (%s) failed: %w", b.Label(), b.File, err)
+		}
+		record.Completed = append(record.Completed, b.Manifest)
+		state.record(StepRestore, "restored %s from %s", b.Label(), b.File)
+		if err := state.Save(opts.StatePath); err != nil {
+			return restoredDetail(record, state), err
+		}
+	}
+	return restoredDetail(record, state), nil
+}
+
+func restoredDetail(record *StepRecord, state *State) string {
+	return fmt.Sprintf("%d of %d backup(s) restored", len(record.Completed), len(state.Plan.Backups))
+}
+
+func recreateUsers(ctx context.Context, opts Options, state *State, record *StepRecord) (string, error) {
+	if state.Plan.UsersFile == "" {
+		return "", skip("no account export given (--users-file); recreate accounts with 'mariadb user import'")
+	}
+	doc, err := users.Load(state.Plan.UsersFile)
+	if err != nil {
+		return "", err
+	}
+	db, err := database.GetWithoutDB(opts.Target)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s:%d: %w", opts.Target.Host, opts.Target.Port, err)
+	}
+	defer db.Close()
+
+	result, err := users.Import(db, doc, users.ImportOptions{Replace: opts.ReplaceUsers})
+	if err != nil {
+		return "", err
+	}
+	for name, reason := range result.Skipped {
+		terminal.PrintWarning(fmt.Sprintf("%s skipped: %s", name, reason))
+	}
+	for name, err := range result.Failed {
+		terminal.PrintError(fmt.Sprintf("%s failed: %v", name, err))
+	}
+	detail := fmt.Sprintf("%d created, %d replaced, %d skipped", len(result.Created), len(result.Replaced), len(result.Skipped))
+	if len(result.Failed) > 0 {
+		return detail, fmt.Errorf("%d account(s) failed to import", len(result.Failed))
+	}
+	return detail, nil
+}
+
+func runHealthChecks(ctx context.Context, opts Options, state *State, record *StepRecord) (string, error) {
+	checks := HealthChecks(ctx, opts, state)
+	rows := make([][]string, 0, len(checks))
+	failed := 0
+	for _, c := range checks {
+		result := "OK"
+		if !c.OK {
+			result = "FAILED"
+			failed++
+		}
+		rows = append(rows, []string{c.Name, result, c.Detail})
+	}
+	terminal.FormatTable([]string{"Check", "Result", "Detail"}, rows)
+	detail := fmt.Sprintf("%d of %d check(s) passed", len(checks)-failed, len(checks))
+	if failed > 0 {
+		return detail, fmt.Errorf("%d health check(s) failed", failed)
+	}
+	return detail, nil
+}
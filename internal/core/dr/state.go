@@ -0,0 +1,84 @@
+package dr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"sfDBTools/utils/fs"
+)
+
+// LoadState reads the state file; ok is false when it does not exist
+func LoadState(path string) (*State, bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read rebuild state: %w", err)
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, false, fmt.Errorf("%s is not a rebuild state file: %w", path, err)
+	}
+	if s.Version == 0 || s.Version > StateVersion {
+		return nil, false, fmt.Errorf("%s has unsupported rebuild state version %d", path, s.Version)
+	}
+	return &s, true, nil
+}
+
+// Save writes the state file
+func (s *State) Save(path string) error {
+	s.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode rebuild state: %w", err)
+	}
+	if err := fs.NewManager().File().WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write rebuild state: %w", err)
+	}
+	return nil
+}
+
+// Step returns the record of a step
+func (s *State) Step(name string) *StepRecord {
+	for _, r := range s.Steps {
+		if r.Name == name {
+			return r
+		}
+	}
+	r := &StepRecord{Name: name, Status: StatusPending}
+	s.Steps = append(s.Steps, r)
+	return r
+}
+
+// Finished reports whether every step is done or skipped
+func (s *State) Finished() bool {
+	for _, name := range Steps {
+		if r := s.Step(name); r.Status != StatusDone && r.Status != StatusSkipped {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *State) record(step, format string, args ...interface{}) {
+	s.Events = append(s.Events, Event{Time: time.Now().UTC(), Step: step, Message: fmt.Sprintf(format, args...)})
+}
+
+func newState(opts Options, plan Plan) *State {
+	now := time.Now().UTC()
+	s := &State{
+		Version:    StateVersion,
+		SourceHost: opts.SourceHost,
+		Target:     fmt.Sprintf("%s:%d", opts.Target.Host, opts.Target.Port),
+		CreatedAt:  now,
+		Plan:       plan,
+	}
+	for _, name := range Steps {
+		s.Step(name)
+	}
+	s.record("", "rebuild of %s planned", opts.SourceHost)
+	return s
+}
@@ -0,0 +1,138 @@
+// Package dr rebuilds a dead database server on a fresh host from what
+// sfDBTools recorded about it: the MariaDB version, a saved server
+// configuration, the server state bundle, the newest full backups in the
+// catalog and the exported accounts. Every step is recorded in a state file,
+// so an interrupted run resumes where it stopped and the file doubles as the
+// timeline of the incident.
+package dr
+
+import (
+	"time"
+
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/database"
+	mariadb_config "sfDBTools/utils/mariadb/config"
+)
+
+// StateVersion is the format version of the state file
+const StateVersion = 1
+
+// Steps of the runbook, in the order they run
+const (
+	StepDetectOS      = "detect-os"
+	StepInstall       = "install-mariadb"
+	StepServerConfig  = "server-config"
+	StepServerState   = "serverstate"
+	StepRestore       = "restore-data"
+	StepUsers         = "users"
+	StepEvents        = "events-replication"
+	StepHealthCheck   = "health-check"
+	serviceName       = "mariadb"
+	defaultConfigPath = "/etc/my.cnf.d/server.cnf"
+)
+
+// Steps lists every step in the order they run
+var Steps = []string{StepDetectOS, StepInstall, StepServerConfig, StepServerState, StepRestore, StepUsers, StepEvents, StepHealthCheck}
+
+// Step states
+const (
+	StatusPending = "pending"
+	StatusDone    = "done"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// Options controls a host rebuild
+type Options struct {
+	BaseDir    string                    // backup directory holding the catalog
+	SourceHost string                    // host of the dead server as recorded in the manifests
+	Target     database.Config           // the rebuilt server
+	Remote     *backup_utils.RemoteStore // fetches manifests and artifacts missing locally; nil when not configured
+	StatePath  string
+	Restart    bool // ignore an existing state file and plan again
+
+	Version         string // MariaDB version to install instead of the recorded one
+	ServerConfig    string // saved my.cnf of the dead server
+	ConfigPath      string // where ServerConfig is installed (mariadb.config_dir)
+	ServerStateFile string // bundle to apply instead of the newest one in the catalog
+	UsersFile       string // accounts exported with 'mariadb user export'
+	ReplaceUsers    bool
+
+	ReplicationPassword string
+	StartReplica        bool
+
+	// Configure holds the post-installation settings of 'mariadb install'
+	Configure *mariadb_config.MariaDBConfigureConfig
+}
+
+// State is the plan and progress of a rebuild, saved after every step
+type State struct {
+	Version    int           `json:"version"`
+	SourceHost string        `json:"source_host"`
+	Target     string        `json:"target"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	Plan       Plan          `json:"plan"`
+	Steps      []*StepRecord `json:"steps"`
+	Events     []Event       `json:"events"`
+}
+
+// Plan is what the rebuild restores, fixed when the state file is created so
+// a resumed run does not switch to backups taken in the meantime
+type Plan struct {
+	MariaDBVersion string   `json:"mariadb_version"`
+	VersionSource  string   `json:"version_source"`
+	ServerConfig   string   `json:"server_config,omitempty"`
+	ConfigPath     string   `json:"config_path,omitempty"`
+	ServerState    string   `json:"serverstate,omitempty"`
+	UsersFile      string   `json:"users_file,omitempty"`
+	Backups        []Backup `json:"backups"`
+}
+
+// Backup is a full backup chosen for the rebuild
+type Backup struct {
+	Manifest  string    `json:"manifest"`
+	File      string    `json:"file"`
+	Type      string    `json:"type"`               // all_databases or single
+	Database  string    `json:"database,omitempty"` // empty for all-databases backups
+	Date      time.Time `json:"date"`
+	RemoteKey string    `json:"remote_key,omitempty"`
+	Tables    int       `json:"tables,omitempty"` // table count recorded at backup time; 0 when unknown
+}
+
+// Label names the backup for display
+func (b Backup) Label() string {
+	if b.Database == "" {
+		return "all databases"
+	}
+	return b.Database
+}
+
+// StepRecord is the outcome of the last attempt of one step
+type StepRecord struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Attempts   int        `json:"attempts"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Duration   string     `json:"duration,omitempty"`
+	Detail     string     `json:"detail,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	// Completed lists the backups already restored, so a resumed restore
+	// continues with the next one
+	Completed []string `json:"completed,omitempty"`
+}
+
+// Event is one timestamped entry of the incident timeline
+type Event struct {
+	Time    time.Time `json:"time"`
+	Step    string    `json:"step"`
+	Message string    `json:"message"`
+}
+
+// Check is one health check of the rebuilt server
+type Check struct {
+	Name   string
+	OK     bool
+	Detail string
+}
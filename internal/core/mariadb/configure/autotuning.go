@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/disk"
 	mariadb_config "sfDBTools/utils/mariadb/config"
 	"sfDBTools/utils/system"
 )
@@ -117,9 +118,11 @@ func autoTunePerformanceSettings(config *mariadb_config.MariaDBConfigureConfig,
 		return fmt.Errorf("failed to auto-tune buffer pool instances: %w", err)
 	}
 
+	// Auto-tune IO settings berdasarkan jenis disk data dir
+	autoTuneDiskSettings(config)
+
 	// TODO: Bisa ditambahkan auto-tuning untuk setting lain seperti:
 	// - innodb_log_file_size
-	// - innodb_io_capacity
 	// - max_connections
 	// - query_cache_size (jika digunakan)
 	// - table_open_cache
@@ -129,4 +132,33 @@ func autoTunePerformanceSettings(config *mariadb_config.MariaDBConfigureConfig,
 	return nil
 }
 
+// autoTuneDiskSettings menyesuaikan innodb_flush_neighbors dan innodb_io_capacity
+// dengan jenis disk data dir: flushing halaman bertetangga hanya menguntungkan
+// disk berputar, sedangkan SSD sanggup menerima IO jauh lebih banyak
+func autoTuneDiskSettings(config *mariadb_config.MariaDBConfigureConfig) {
+	lg, _ := logger.Get()
+
+	media, err := disk.DetectMedia(config.DataDir)
+	if err != nil {
+		lg.Warn("Failed to detect data dir disk type, keeping IO defaults", logger.String("data_dir", config.DataDir), logger.Error(err))
+		return
+	}
+	config.DataDirMedia = media.String()
+	switch media.Media {
+	case disk.MediaSSD:
+		config.InnodbFlushNeighbors, config.InnodbIOCapacity = "0", "2000"
+	case disk.MediaHDD:
+		config.InnodbFlushNeighbors, config.InnodbIOCapacity = "1", "200"
+	default:
+		lg.Info("Data dir disk type unknown, keeping IO defaults", logger.String("data_dir", config.DataDir), logger.String("fstype", media.Fstype))
+		return
+	}
+
+	lg.Info("Auto-tuned IO settings for disk type",
+		logger.String("media", media.Media),
+		logger.String("device", media.Device),
+		logger.String("innodb_flush_neighbors", config.InnodbFlushNeighbors),
+		logger.String("innodb_io_capacity", config.InnodbIOCapacity))
+}
+
 // Helper functions
@@ -80,7 +80,10 @@ func RunMariaDBConfigure(ctx context.Context, config *mariadb_config.MariaDBConf
 		CurrentConfig: template.CurrentConfig,
 		CurrentPath:   template.CurrentPath,
 	}
-	if err := interactive.GatherInteractiveInput(ctx, config, interactiveTemplate, mariadbInstallation); err != nil {
+	if config.NonInteractive {
+		// Nilai sudah lengkap dan tervalidasi dari flags/env/answers file/config.yaml
+		lg.Info("Non-interactive mode: using resolved configuration values without prompting")
+	} else if err := interactive.GatherInteractiveInput(ctx, config, interactiveTemplate, mariadbInstallation); err != nil {
 		return fmt.Errorf("failed to gather interactive input: %w", err)
 	}
 
@@ -115,10 +118,31 @@ func RunMariaDBConfigure(ctx context.Context, config *mariadb_config.MariaDBConf
 	}
 	terminal.FormatTable(headersNew, rowsNew)
 
-	// Step 11: Konfirmasi user
-	lg.Info("Requesting user confirmation for configuration changes")
-	if err := interactive.RequestUserConfirmationForConfig(ctx, config); err != nil {
-		return fmt.Errorf("user confirmation failed: %w", err)
+	// Render server.cnf sekarang agar bisa ditinjau sebelum konfirmasi
+	newConfig, err := migration.GenerateConfiguration(config, template)
+	if err != nil {
+		return err
+	}
+	if config.ReviewConfig {
+		lg.Info("Reviewing generated configuration")
+		if newConfig, err = interactive.ReviewGeneratedConfig(ctx, template.CurrentPath, template.CurrentConfig, newConfig); err != nil {
+			return fmt.Errorf("configuration review failed: %w", err)
+		}
+	}
+
+	// Dry run berhenti di sini: laporkan perubahan tanpa menerapkannya
+	if config.DryRun {
+		return reportDryRun(config, template, mariadbInstallation, newConfig)
+	}
+
+	// Step 11: Konfirmasi user (dilewati pada mode non-interactive)
+	if config.NonInteractive {
+		lg.Info("Non-interactive mode: applying configuration without confirmation")
+	} else {
+		lg.Info("Requesting user confirmation for configuration changes")
+		if err := interactive.RequestUserConfirmationForConfig(ctx, config); err != nil {
+			return fmt.Errorf("user confirmation failed: %w", err)
+		}
 	}
 
 	// Step 19: Data Migration (jika diperlukan)
@@ -130,7 +154,7 @@ func RunMariaDBConfigure(ctx context.Context, config *mariadb_config.MariaDBConf
 
 	// Step 15-18: Backup dan konfigurasi
 	lg.Info("Backing up current configuration and applying new settings")
-	if err := migration.ApplyConfiguration(ctx, config, template); err != nil {
+	if err := migration.ApplyConfigurationContent(ctx, config, template, newConfig); err != nil {
 		return fmt.Errorf("failed to apply configuration: %w", err)
 	}
 
@@ -0,0 +1,86 @@
+package configure
+
+import (
+	"fmt"
+
+	"sfDBTools/internal/core/mariadb/configure/interactive"
+	"sfDBTools/internal/core/mariadb/configure/migration"
+	"sfDBTools/internal/core/mariadb/configure/template"
+	validation "sfDBTools/internal/core/mariadb/configure/validation"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	mariadb_config "sfDBTools/utils/mariadb/config"
+	"sfDBTools/utils/mariadb/discovery"
+	"sfDBTools/utils/terminal"
+)
+
+// reportDryRun menampilkan semua yang akan dilakukan configure: diff
+// server.cnf, direktori baru, rencana migrasi data dan langkah service.
+// Tidak ada yang diubah.
+func reportDryRun(config *mariadb_config.MariaDBConfigureConfig, tpl *template.MariaDBConfigTemplate, installation *discovery.MariaDBInstallation, newConfig string) error {
+	lg, _ := logger.Get()
+	terminal.PrintSubHeader("Dry Run: Planned Changes")
+
+	// 1. Perubahan server.cnf
+	interactive.ShowConfigChanges(tpl.CurrentPath, tpl.CurrentConfig, newConfig)
+
+	// 2. Direktori yang akan dibuat
+	terminal.PrintSubHeader("Directories to be created")
+	if dirs := validation.PlanDirectories(config); len(dirs) == 0 {
+		terminal.PrintInfo("All directories already exist")
+	} else {
+		rows := make([][]string, len(dirs))
+		for i, d := range dirs {
+			rows[i] = []string{d}
+		}
+		terminal.FormatTable([]string{"Directory"}, rows)
+	}
+
+	// 3. Rencana migrasi data
+	terminal.PrintSubHeader("Data migration plan")
+	migrations := migration.PlanDataMigration(config, installation)
+	if len(migrations) == 0 {
+		terminal.PrintInfo("No data migration required")
+	} else {
+		var rows [][]string
+		for _, m := range migrations {
+			p := migration.PreviewMigration(m)
+			files, size := "-", "-"
+			if p.SourceExists {
+				files, size = fmt.Sprintf("%d", p.Files), common.FormatSize(p.Bytes)
+			}
+			rows = append(rows, []string{m.Type, m.Source, m.Destination, files, size, p.Method})
+			lg.Info("Dry run: planned migration",
+				logger.String("type", m.Type),
+				logger.String("source", m.Source),
+				logger.String("destination", m.Destination),
+				logger.String("method", p.Method),
+				logger.Int64("bytes", p.Bytes))
+		}
+		terminal.FormatTable([]string{"Type", "Source", "Destination", "Files", "Size", "Method"}, rows)
+	}
+
+	// 4. Langkah yang mengubah sistem
+	service := installation.ServiceName
+	if service == "" {
+		service = "mariadb (auto-detected at run time)"
+	}
+	var steps []string
+	if len(migrations) > 0 {
+		steps = append(steps, "Stop service "+service+" for the data migration, start it again afterwards")
+	}
+	steps = append(steps,
+		fmt.Sprintf("Back up %s to %s/mariadb-config-backup-<timestamp>.cnf", tpl.CurrentPath, config.BackupDir),
+		"Write the new configuration to "+tpl.CurrentPath,
+		"Restart service "+service,
+		"Update the mariadb section of the sfDBTools config.yaml")
+	terminal.PrintSubHeader("Steps that would run")
+	for i, step := range steps {
+		fmt.Printf("  %d. %s\n", i+1, step)
+	}
+	fmt.Println()
+
+	terminal.PrintSuccess("Dry run complete: no changes were made")
+	lg.Info("MariaDB configure dry run completed", logger.Int("migrations", len(migrations)))
+	return nil
+}
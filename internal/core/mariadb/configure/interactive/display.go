@@ -12,7 +12,7 @@ func RequestUserConfirmation() error {
 	terminal.PrintWarning("This may require stopping the service and migrating data.")
 
 	question := "Do you want to proceed?"
-	confirmed := terminal.AskYesNo(question, false)
+	confirmed := terminal.Confirm(question, false)
 
 	if !confirmed {
 		return fmt.Errorf("configuration cancelled by user")
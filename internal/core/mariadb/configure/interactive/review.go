@@ -0,0 +1,229 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"sfDBTools/internal/core/mariadb/configure/template"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/terminal"
+)
+
+// settingKey mengidentifikasi satu opsi dalam section tertentu
+type settingKey struct {
+	Section string
+	Key     string
+}
+
+// ReviewGeneratedConfig menampilkan perubahan konfigurasi hasil template
+// terhadap konfigurasi yang sedang dipakai, lalu memberi operator pilihan
+// untuk melihat file lengkap, mengubahnya lewat $EDITOR, menerapkan, atau
+// membatalkan. Konten yang dikembalikan selalu lolos validasi INI.
+func ReviewGeneratedConfig(ctx context.Context, currentPath, current, generated string) (string, error) {
+	lg, _ := logger.Get()
+	content := generated
+	for {
+		if ctx != nil {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			default:
+			}
+		}
+
+		changed := ShowConfigChanges(currentPath, current, content)
+		choice := strings.ToLower(strings.TrimSpace(terminal.AskString("[a]pply, [v]iew full file, [e]dit in $EDITOR, [r]eset to generated, [c]ancel", "a")))
+		switch choice {
+		case "a", "apply":
+			if err := template.ValidateINI(content); err != nil {
+				terminal.PrintError("Configuration is invalid: " + err.Error())
+				continue
+			}
+			if content != generated {
+				lg.Info("Generated configuration edited during review", logger.String("config_path", currentPath))
+			}
+			return content, nil
+		case "v", "view":
+			pageConfig(content, changed)
+		case "e", "edit":
+			if edited, ok := editUntilValid(content); ok {
+				content = edited
+			}
+		case "r", "reset":
+			content = generated
+			terminal.PrintInfo("Edits discarded; back to the generated configuration")
+		case "c", "cancel":
+			return "", fmt.Errorf("configuration cancelled by user")
+		default:
+			terminal.PrintWarning("Unknown choice " + choice)
+		}
+	}
+}
+
+// editUntilValid membuka editor sampai hasilnya valid; edit yang salah dibuka
+// lagi agar perubahan operator tidak hilang. false jika edit dibuang.
+func editUntilValid(content string) (string, bool) {
+	for {
+		edited, err := editConfig(content)
+		if err != nil {
+			terminal.PrintError(err.Error())
+			return "", false
+		}
+		err = template.ValidateINI(edited)
+		if err == nil {
+			return edited, true
+		}
+		terminal.PrintError("Edited configuration is invalid: " + err.Error())
+		if !terminal.AskYesNo("Edit again to fix it?", true) {
+			terminal.PrintInfo("Edit discarded")
+			return "", false
+		}
+		content = edited
+	}
+}
+
+// ShowConfigChanges menampilkan opsi yang ditambah, diubah dan dihapus, lalu
+// mengembalikan opsi yang ditambah atau diubah untuk di-highlight
+func ShowConfigChanges(currentPath, current, content string) map[settingKey]bool {
+	terminal.PrintSubHeader("Review of " + currentPath)
+	before := settingsOf(current)
+	if before == nil && strings.TrimSpace(current) != "" {
+		terminal.PrintWarning("The current configuration could not be parsed; every option is shown as new")
+	}
+	after := settingsOf(content)
+
+	changed := make(map[settingKey]bool)
+	var rows [][]string
+	for _, k := range sortedKeys(after) {
+		old, existed := before[k]
+		switch {
+		case !existed:
+			changed[k] = true
+			rows = append(rows, []string{terminal.ColorText("+", terminal.ColorGreen), k.Section, k.Key, "", after[k]})
+		case old != after[k]:
+			changed[k] = true
+			rows = append(rows, []string{terminal.ColorText("~", terminal.ColorYellow), k.Section, k.Key, old, after[k]})
+		}
+	}
+	for _, k := range sortedKeys(before) {
+		if _, kept := after[k]; !kept {
+			rows = append(rows, []string{terminal.ColorText("-", terminal.ColorRed), k.Section, k.Key, before[k], ""})
+		}
+	}
+	if len(rows) == 0 {
+		terminal.PrintInfo("No changes to the current configuration")
+	} else {
+		terminal.FormatTable([]string{"", "Section", "Option", "Current", "New"}, rows)
+	}
+	return changed
+}
+
+// settingsOf memetakan opsi konten; nil jika konten tidak bisa di-parse.
+// Opsi yang muncul lebih dari sekali memakai nilai terakhir, seperti server.
+func settingsOf(content string) map[settingKey]string {
+	settings, err := template.ParseINI(content)
+	if err != nil {
+		return nil
+	}
+	m := make(map[settingKey]string, len(settings))
+	for _, s := range settings {
+		m[settingKey{s.Section, s.Key}] = s.Value
+	}
+	return m
+}
+
+func sortedKeys(m map[settingKey]string) []settingKey {
+	keys := make([]settingKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Section != keys[j].Section {
+			return keys[i].Section < keys[j].Section
+		}
+		return keys[i].Key < keys[j].Key
+	})
+	return keys
+}
+
+// pageConfig menampilkan file lengkap lewat $PAGER (default less -R) dengan
+// baris yang berubah di-highlight
+func pageConfig(content string, changed map[settingKey]bool) {
+	highlight := make(map[int]bool)
+	if settings, err := template.ParseINI(content); err == nil {
+		for _, s := range settings {
+			if changed[settingKey{s.Section, s.Key}] {
+				highlight[s.Line] = true
+			}
+		}
+	}
+	var sb strings.Builder
+	for i, line := range strings.Split(content, "\n") {
+		if highlight[i+1] {
+			sb.WriteString(terminal.ColorText(line, terminal.ColorGreen))
+		} else {
+			sb.WriteString(line)
+		}
+		sb.WriteString("\n")
+	}
+
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less", "-R"}
+	}
+	if _, err := exec.LookPath(pager[0]); err != nil {
+		fmt.Print(sb.String())
+		return
+	}
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin = strings.NewReader(sb.String())
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Print(sb.String())
+	}
+}
+
+// editConfig membuka konten di $VISUAL atau $EDITOR (default vi) dan
+// mengembalikan hasil editannya
+func editConfig(content string) (string, error) {
+	editor := strings.Fields(os.Getenv("VISUAL"))
+	if len(editor) == 0 {
+		editor = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	if _, err := exec.LookPath(editor[0]); err != nil {
+		return "", fmt.Errorf("editor %s not found; set $EDITOR", editor[0])
+	}
+
+	f, err := os.CreateTemp("", "server-*.cnf")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file for editing: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write temporary file for editing: %w", err)
+	}
+	f.Close()
+
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited configuration: %w", err)
+	}
+	return string(edited), nil
+}
@@ -13,6 +13,30 @@ import (
 )
 
 func ApplyConfiguration(ctx context.Context, config *mariadb_config.MariaDBConfigureConfig, tpl *template.MariaDBConfigTemplate) error {
+	newConfig, err := GenerateConfiguration(config, tpl)
+	if err != nil {
+		return err
+	}
+	return ApplyConfigurationContent(ctx, config, tpl, newConfig)
+}
+
+// GenerateConfiguration renders the template with the configure values and
+// checks that the result parses as an option file
+func GenerateConfiguration(config *mariadb_config.MariaDBConfigureConfig, tpl *template.MariaDBConfigTemplate) (string, error) {
+	configValues := buildConfigValues(config)
+	newConfig, err := tpl.GenerateConfigFromTemplate(configValues)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate config from template: %w", err)
+	}
+	if err := template.ValidateINI(newConfig); err != nil {
+		return "", fmt.Errorf("generated configuration is invalid: %w", err)
+	}
+	return newConfig, nil
+}
+
+// ApplyConfigurationContent backs up the current configuration and writes
+// content, e.g. a generated configuration edited during review, in its place
+func ApplyConfigurationContent(ctx context.Context, config *mariadb_config.MariaDBConfigureConfig, tpl *template.MariaDBConfigTemplate, content string) error {
 	lg, err := logger.Get()
 	if err != nil {
 		return fmt.Errorf("failed to get logger: %w", err)
@@ -20,19 +44,18 @@ func ApplyConfiguration(ctx context.Context, config *mariadb_config.MariaDBConfi
 
 	lg.Info("Applying MariaDB configuration")
 
+	// Validated again: the content may have been edited since it was generated
+	if err := template.ValidateINI(content); err != nil {
+		return fmt.Errorf("configuration is invalid: %w", err)
+	}
+
 	backupPath, err := tpl.BackupCurrentConfig(config.BackupDir)
 	if err != nil {
 		return fmt.Errorf("failed to backup current config: %w", err)
 	}
 	lg.Info("Current configuration backed up", logger.String("backup_path", backupPath))
 
-	configValues := buildConfigValues(config)
-	newConfig, err := tpl.GenerateConfigFromTemplate(configValues)
-	if err != nil {
-		return fmt.Errorf("failed to generate config from template: %w", err)
-	}
-
-	if err := writeConfiguration(tpl.CurrentPath, newConfig); err != nil {
+	if err := writeConfiguration(tpl.CurrentPath, content); err != nil {
 		return fmt.Errorf("failed to write new configuration: %w", err)
 	}
 
@@ -54,6 +77,12 @@ func buildConfigValues(config *mariadb_config.MariaDBConfigureConfig) map[string
 	values["innodb_log_group_home_dir"] = config.DataDir
 	values["innodb_buffer_pool_size"] = config.InnodbBufferPoolSize
 	values["innodb_buffer_pool_instances"] = fmt.Sprintf("%d", config.InnodbBufferPoolInstances)
+	if config.InnodbFlushNeighbors != "" {
+		values["innodb_flush_neighbors"] = config.InnodbFlushNeighbors
+	}
+	if config.InnodbIOCapacity != "" {
+		values["innodb_io_capacity"] = config.InnodbIOCapacity
+	}
 
 	if config.InnodbEncryptTables {
 		values["innodb_encrypt_tables"] = "ON"
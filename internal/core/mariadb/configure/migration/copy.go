@@ -1,13 +1,17 @@
 package migration
 
 import (
+	"context"
 	"fmt"
+	"os/exec"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common/format"
+	"sfDBTools/utils/disk"
 )
 
 // PerformSingleMigration performs a single data migration using the migration manager
-func PerformSingleMigration(migration DataMigration) error {
+func PerformSingleMigration(ctx context.Context, migration DataMigration) error {
 	lg, _ := logger.Get()
 	lg.Info("Performing migration", logger.String("type", migration.Type))
 
@@ -33,10 +37,8 @@ func PerformSingleMigration(migration DataMigration) error {
 		if err := mgr.CopyLogFilesOnly(migration.Source, migration.Destination); err != nil {
 			return fmt.Errorf("failed to copy log files: %w", err)
 		}
-	} else {
-		if err := mgr.CopyDirectory(migration.Source, migration.Destination); err != nil {
-			return fmt.Errorf("failed to copy data: %w", err)
-		}
+	} else if err := copyWithProgress(ctx, mgr, migration); err != nil {
+		return err
 	}
 
 	if migration.Critical && migration.Type == "data" {
@@ -64,3 +66,63 @@ func PerformSingleMigration(migration DataMigration) error {
 	lg.Info("Migration completed successfully")
 	return nil
 }
+
+// copyWithProgress copies the migration source tree, through rsync when a
+// spinning disk is involved, reporting the bytes copied, rate and ETA
+func copyWithProgress(ctx context.Context, mgr *MigrationManager, migration DataMigration) error {
+	lg, _ := logger.Get()
+	total, err := sourceSize(migration.Source)
+	if err != nil {
+		lg.Warn("Failed to measure migration source, copying without progress",
+			logger.String("source", migration.Source), logger.Error(err))
+	}
+	label := fmt.Sprintf("Migrating %s (%s)", migration.Type, format.FormatSizeWithPrecision(total, 1))
+
+	if rsyncPreferred(migration.Source, migration.Destination) {
+		lg.Info("Spinning disk involved, copying with rsync", logger.String("source", migration.Source))
+		var progress *transferProgress
+		if err == nil && rsyncHasProgress2(ctx) {
+			progress = newTransferProgress(label, total)
+		}
+		copyErr := rsyncCopy(ctx, migration.Source, migration.Destination, progress)
+		if progress != nil {
+			progress.Finish()
+		}
+		if copyErr != nil {
+			return fmt.Errorf("failed to copy data with rsync: %w", copyErr)
+		}
+		return nil
+	}
+
+	if err != nil {
+		if err := mgr.CopyDirectory(migration.Source, migration.Destination); err != nil {
+			return fmt.Errorf("failed to copy data: %w", err)
+		}
+		return nil
+	}
+	progress := newTransferProgress(label, total)
+	copyErr := mgr.copyTree(migration.Source, migration.Destination, progress)
+	progress.Finish()
+	if copyErr != nil {
+		return fmt.Errorf("failed to copy data: %w", copyErr)
+	}
+	return nil
+}
+
+// rsyncPreferred reports whether a directory copy should go through rsync:
+// when either side is a spinning disk its single sequential stream beats the
+// file-by-file native copy. Without rsync installed the native copy, which
+// keeps ownership, permissions, xattrs and sparse files too, is used.
+func rsyncPreferred(source, destination string) bool {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		lg, _ := logger.Get()
+		lg.Info("rsync not installed, using the native copy", logger.String("source", source))
+		return false
+	}
+	for _, path := range []string{source, destination} {
+		if media, err := disk.DetectMedia(path); err == nil && media.Rotational() {
+			return true
+		}
+	}
+	return false
+}
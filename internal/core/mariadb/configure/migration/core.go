@@ -11,43 +11,44 @@ import (
 	"sfDBTools/utils/system"
 )
 
-// PerformDataMigrationWithInstallation performs migration using an already-discovered installation
-// This avoids re-running discovery when the caller already has the installation info.
-func PerformDataMigrationWithInstallation(ctx context.Context, config *mariadb_config.MariaDBConfigureConfig, installation *discovery.MariaDBInstallation) error {
-	lg, err := logger.Get()
-	if err != nil {
-		return fmt.Errorf("failed to get logger: %w", err)
-	}
-
-	lg.Info("Starting data migration process (using provided installation)")
-
-	// Build migration plan
-	needsMigration := false
-	migrations := []DataMigration{}
+// PlanDataMigration returns the directory moves needed to go from the
+// installation's directories to the configured ones
+func PlanDataMigration(config *mariadb_config.MariaDBConfigureConfig, installation *discovery.MariaDBInstallation) []DataMigration {
+	var migrations []DataMigration
 
+	// Clean paths to avoid false positives due to trailing slashes or relative segments
 	currentLogDir := filepath.Clean(installation.LogDir)
 	targetLogDir := filepath.Clean(config.LogDir)
 	if currentLogDir != targetLogDir {
 		migrations = append(migrations, DataMigration{Type: "logs", Source: currentLogDir, Destination: targetLogDir, Critical: false})
-		needsMigration = true
 	}
 
-	// Clean paths to avoid false positives due to trailing slashes or relative segments
 	currentDataDir := filepath.Clean(installation.DataDir)
 	targetDataDir := filepath.Clean(config.DataDir)
 	if currentDataDir != targetDataDir {
 		migrations = append(migrations, DataMigration{Type: "data", Source: currentDataDir, Destination: targetDataDir, Critical: true})
-		needsMigration = true
 	}
 
 	currentBinlogDir := filepath.Clean(installation.BinlogDir)
 	targetBinlogDir := filepath.Clean(config.BinlogDir)
 	if currentBinlogDir != targetBinlogDir {
 		migrations = append(migrations, DataMigration{Type: "binlogs", Source: currentBinlogDir, Destination: targetBinlogDir, Critical: false})
-		needsMigration = true
 	}
+	return migrations
+}
+
+// PerformDataMigrationWithInstallation performs migration using an already-discovered installation
+// This avoids re-running discovery when the caller already has the installation info.
+func PerformDataMigrationWithInstallation(ctx context.Context, config *mariadb_config.MariaDBConfigureConfig, installation *discovery.MariaDBInstallation) error {
+	lg, err := logger.Get()
+	if err != nil {
+		return fmt.Errorf("failed to get logger: %w", err)
+	}
+
+	lg.Info("Starting data migration process (using provided installation)")
 
-	if !needsMigration {
+	migrations := PlanDataMigration(config, installation)
+	if len(migrations) == 0 {
 		lg.Info("No data migration required")
 		return nil
 	}
@@ -79,7 +80,7 @@ func PerformDataMigrationWithInstallation(ctx context.Context, config *mariadb_c
 	}
 
 	for _, m := range migrations {
-		if err := PerformSingleMigration(m); err != nil {
+		if err := PerformSingleMigration(ctx, m); err != nil {
 			if m.Critical {
 				return fmt.Errorf("critical migration failed: %w", err)
 			}
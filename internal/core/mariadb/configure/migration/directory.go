@@ -2,7 +2,6 @@ package migration
 
 import (
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -10,46 +9,32 @@ import (
 	fsutil "sfDBTools/utils/fs"
 )
 
-// CopyDirectory copies entire directory tree from source to destination
+// CopyDirectory copies entire directory tree from source to destination,
+// verifying the checksum of every copied file
 func (m *MigrationManager) CopyDirectory(source, destination string) error {
-	return filepath.WalkDir(source, func(path string, d fs.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return fmt.Errorf("error walking source %s: %w", path, walkErr)
-		}
-
-		rel, err := filepath.Rel(source, path)
-		if err != nil {
-			return fmt.Errorf("failed to compute relative path: %w", err)
-		}
-		destPath := filepath.Join(destination, rel)
-
-		info, err := d.Info()
-		if err != nil {
-			return fmt.Errorf("failed to stat source %s: %w", path, err)
-		}
-
-		// Skip special files (sockets, devices, pipes)
-		if fsutil.IsSpecialFile(info.Mode()) {
-			m.logger.Warn("Skipping unsupported special file during migration", logger.String("path", path))
-			return nil
-		}
-
-		// Handle directories
-		if info.IsDir() {
-			return m.copyDir(destPath, info)
-		}
-
-		// Handle symlinks
-		if info.Mode()&os.ModeSymlink != 0 {
-			return m.copySymlink(path, destPath, info)
-		}
+	return m.copyTree(source, destination, nil)
+}
 
-		// Handle regular files
-		if err := m.fsMgr.File().CopyWithInfo(path, destPath, info); err != nil {
-			return fmt.Errorf("failed to copy file %s to %s: %w", path, destPath, err)
-		}
-		return nil
-	})
+// copyTree copies the directory tree natively, counting the bytes of regular
+// files in progress when it is set
+func (m *MigrationManager) copyTree(source, destination string, progress *transferProgress) error {
+	opts := fsutil.TreeCopyOptions{Verify: true}
+	if progress != nil {
+		opts.Progress = progress
+	}
+	result, err := fsutil.CopyTree(source, destination, opts)
+	if err != nil {
+		return err
+	}
+	m.logger.Info("Directory copied and verified",
+		logger.String("source", source),
+		logger.Int("files", result.Files),
+		logger.Int("directories", result.Dirs),
+		logger.Int("symlinks", result.Symlinks))
+	if len(result.Skipped) > 0 {
+		m.logger.Warn("Special files were not copied", logger.Strings("paths", result.Skipped))
+	}
+	return nil
 }
 
 // copyDir creates a directory with proper permissions and ownership
@@ -0,0 +1,70 @@
+package migration
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	fsutil "sfDBTools/utils/fs"
+)
+
+// MigrationPreview describes what PerformSingleMigration would do for one
+// migration, without touching the filesystem
+type MigrationPreview struct {
+	DataMigration
+	SourceExists bool
+	Method       string // how the files would be copied
+	Files        int    // files that would be copied
+	Bytes        int64  // their total size
+}
+
+// PreviewMigration walks the source of m the same way the copy would and
+// reports the method, file count and size. Nothing is created or removed.
+func PreviewMigration(m DataMigration) MigrationPreview {
+	mgr := NewMigrationManager()
+	p := MigrationPreview{DataMigration: m}
+	if !mgr.FileSystem().Dir().Exists(m.Source) {
+		p.Method = "skipped: source directory does not exist"
+		if m.Critical {
+			p.Method = "fails: source directory does not exist"
+		}
+		return p
+	}
+	p.SourceExists = true
+
+	logsOnly := m.Type == "logs"
+	switch {
+	case logsOnly:
+		p.Method = "copy log files only"
+	case rsyncPreferred(m.Source, m.Destination):
+		p.Method = "rsync -a --whole-file " + m.Source + "/ " + m.Destination + "/"
+	default:
+		p.Method = "native copy"
+	}
+	if m.Critical && m.Type == "data" {
+		p.Method += ", verify"
+	}
+	p.Method += ", remove source"
+
+	filepath.WalkDir(m.Source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || fsutil.IsSpecialFile(info.Mode()) {
+			return nil
+		}
+		if info.IsDir() {
+			if logsOnly && mgr.IsDataDirectory(path, m.Source) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if logsOnly && !mgr.IsLogFile(path) {
+			return nil
+		}
+		p.Files++
+		p.Bytes += info.Size()
+		return nil
+	})
+	return p
+}
@@ -0,0 +1,222 @@
+package migration
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common/format"
+	"sfDBTools/utils/shutdown"
+	"sfDBTools/utils/terminal"
+)
+
+// progressRedraw limits how often the progress bar is redrawn
+const progressRedraw = 200 * time.Millisecond
+
+// progressLogStep is the percentage between two progress log lines when
+// stdout is not a terminal
+const progressLogStep = 10
+
+// transferProgress reports the bytes copied by a data migration: a progress
+// bar with rate and ETA on a terminal, a log line every progressLogStep
+// percent otherwise
+type transferProgress struct {
+	mu       sync.Mutex
+	label    string
+	total    int64
+	copied   int64
+	started  time.Time
+	bar      *terminal.ProgressBar
+	lastDraw time.Time
+	logged   int64
+}
+
+func newTransferProgress(label string, total int64) *transferProgress {
+	p := &transferProgress{label: label, total: total, started: time.Now(), logged: -1}
+	if total > 0 && terminal.StdoutIsTerminal() {
+		p.bar = terminal.NewTransferProgressBar(total, label)
+		p.bar.Update(0)
+	}
+	return p
+}
+
+// Write counts the bytes passing through a native copy
+func (p *transferProgress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	p.advance(p.copied + int64(len(b)))
+	p.mu.Unlock()
+	return len(b), nil
+}
+
+// Set records the total bytes copied so far, as reported by rsync
+func (p *transferProgress) Set(copied int64) {
+	p.mu.Lock()
+	p.advance(copied)
+	p.mu.Unlock()
+}
+
+func (p *transferProgress) advance(copied int64) {
+	if copied > p.total {
+		copied = p.total
+	}
+	p.copied = copied
+	if p.total <= 0 {
+		return
+	}
+	if p.bar != nil {
+		if time.Since(p.lastDraw) >= progressRedraw {
+			p.bar.Update(int(copied))
+			p.lastDraw = time.Now()
+		}
+		return
+	}
+	if step := copied * 100 / p.total / progressLogStep; step > p.logged {
+		p.logged = step
+		lg, _ := logger.Get()
+		lg.Info("Migration progress",
+			logger.String("migration", p.label),
+			logger.Int64("percent", step*progressLogStep),
+			logger.String("copied", format.FormatSizeWithPrecision(copied, 1)),
+			logger.String("total", format.FormatSizeWithPrecision(p.total, 1)),
+			logger.String("rate", p.rate()))
+	}
+}
+
+// rate formats the average transfer rate so far
+func (p *transferProgress) rate() string {
+	elapsed := time.Since(p.started).Seconds()
+	if elapsed <= 0 {
+		return "-"
+	}
+	return format.FormatSizeWithPrecision(int64(float64(p.copied)/elapsed), 1) + "/s"
+}
+
+// Finish draws the final state of the bar and logs the size and rate of the
+// transfer
+func (p *transferProgress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.bar != nil {
+		p.bar.Update(int(p.copied))
+		fmt.Println()
+	}
+	lg, _ := logger.Get()
+	lg.Info("Migration copy finished",
+		logger.String("migration", p.label),
+		logger.String("copied", format.FormatSizeWithPrecision(p.copied, 1)),
+		logger.String("duration", time.Since(p.started).Round(time.Second).String()),
+		logger.String("rate", p.rate()))
+}
+
+// sourceSize sums the sizes of the regular files under root, the total the
+// progress of a migration is measured against
+func sourceSize(root string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, err
+}
+
+// rsyncProgressLine matches a --info=progress2 line:
+// "  1,234,567,890  45%   98.76MB/s    0:01:02 (xfr#12, to-chk=3/40)"
+var rsyncProgressLine = regexp.MustCompile(`^\s*([\d,]+)\s+\d+%`)
+
+// rsyncVersion matches the first line of "rsync --version"
+var rsyncVersion = regexp.MustCompile(`version (\d+)\.(\d+)`)
+
+// rsyncHasProgress2 reports whether the installed rsync knows
+// --info=progress2, which was added in 3.1
+func rsyncHasProgress2(ctx context.Context) bool {
+	out, err := shutdown.Command(ctx, "rsync", "--version").Output()
+	if err != nil {
+		return false
+	}
+	m := rsyncVersion.FindSubmatch(out)
+	if m == nil {
+		return false
+	}
+	major, _ := strconv.Atoi(string(m[1]))
+	minor, _ := strconv.Atoi(string(m[2]))
+	return major > 3 || (major == 3 && minor >= 1)
+}
+
+// rsyncCopy copies source into destination with rsync. With a progress and an
+// rsync that supports it, the whole-transfer progress of rsync drives it.
+func rsyncCopy(ctx context.Context, source, destination string, progress *transferProgress) error {
+	args := []string{"-a", "--whole-file"}
+	if progress != nil {
+		// --no-inc-recursive scans the whole tree first so the reported
+		// byte count does not restart per directory
+		args = append(args, "--info=progress2", "--no-inc-recursive")
+	}
+	args = append(args, source+"/", destination+"/")
+
+	cmd := shutdown.Command(ctx, "rsync", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if progress == nil {
+		if out, err := cmd.Output(); err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)+stderr.String()))
+		}
+		return nil
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Split(scanProgressLines)
+	for scanner.Scan() {
+		m := rsyncProgressLine.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		if copied, err := strconv.ParseInt(strings.ReplaceAll(m[1], ",", ""), 10, 64); err == nil {
+			progress.Set(copied)
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// scanProgressLines splits rsync output on both newlines and the carriage
+// returns it redraws its progress line with
+func scanProgressLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
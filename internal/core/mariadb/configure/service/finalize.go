@@ -89,6 +89,10 @@ func ShowSuccessSummary(config *mariadb_config.MariaDBConfigureConfig) {
 	fmt.Printf("✓ Table Encryption: %t\n", config.InnodbEncryptTables)
 	fmt.Printf("✓ Buffer Pool Size: %s\n", config.InnodbBufferPoolSize)
 	fmt.Printf("✓ Buffer Pool Instances: %d\n", config.InnodbBufferPoolInstances)
+	if config.DataDirMedia != "" {
+		fmt.Printf("✓ Data Directory Disk: %s\n", config.DataDirMedia)
+		fmt.Printf("✓ Flush Neighbors / IO Capacity: %s / %s\n", config.InnodbFlushNeighbors, config.InnodbIOCapacity)
+	}
 	println()
 	terminal.PrintInfo("MariaDB service is running and ready to accept connections.")
 	terminal.PrintInfo("You can now connect to MariaDB using the new configuration.")
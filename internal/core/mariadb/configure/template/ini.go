@@ -0,0 +1,127 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// INISetting adalah satu opsi dalam file konfigurasi MariaDB
+type INISetting struct {
+	Section string
+	Key     string
+	Value   string
+	Line    int
+}
+
+// ParseINI mem-parsing konten option file MariaDB (server.cnf) dan menolak
+// konten yang tidak akan bisa dibaca server: header section rusak, opsi di
+// luar section, nama opsi tidak valid, kutipan tidak tertutup, atau
+// placeholder template yang belum terisi.
+func ParseINI(content string) ([]INISetting, error) {
+	var settings []INISetting
+	section := ""
+	for i, raw := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.Contains(line, "{{") {
+			return nil, fmt.Errorf("line %d: unresolved template placeholder: %s", lineNo, line)
+		}
+		// !include dan !includedir boleh muncul di mana saja
+		if strings.HasPrefix(line, "!") {
+			directive, arg, _ := strings.Cut(line, " ")
+			if directive != "!include" && directive != "!includedir" {
+				return nil, fmt.Errorf("line %d: unknown directive %s", lineNo, directive)
+			}
+			if strings.TrimSpace(arg) == "" {
+				return nil, fmt.Errorf("line %d: %s needs a path", lineNo, directive)
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: section header is not closed: %s", lineNo, line)
+			}
+			rest := strings.TrimSpace(line[end+1:])
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected text after section header: %s", lineNo, line)
+			}
+			section = strings.TrimSpace(line[1:end])
+			if section == "" {
+				return nil, fmt.Errorf("line %d: empty section name", lineNo)
+			}
+			continue
+		}
+		if section == "" {
+			return nil, fmt.Errorf("line %d: option outside of any section: %s", lineNo, line)
+		}
+
+		key, value, hasValue := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !validOptionName(key) {
+			return nil, fmt.Errorf("line %d: invalid option name %q", lineNo, key)
+		}
+		if hasValue {
+			var err error
+			if value, err = parseOptionValue(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", lineNo, key, err)
+			}
+		}
+		settings = append(settings, INISetting{Section: section, Key: normalizeOptionName(key), Value: value, Line: lineNo})
+	}
+	if len(settings) == 0 {
+		return nil, fmt.Errorf("configuration has no options")
+	}
+	return settings, nil
+}
+
+// ValidateINI memastikan konten bisa dibaca oleh ParseINI
+func ValidateINI(content string) error {
+	_, err := ParseINI(content)
+	return err
+}
+
+// validOptionName: huruf, angka, '_', '-' dan '.' (mis. loose-plugin.option)
+func validOptionName(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeOptionName menyamakan '-' dan '_' seperti yang dilakukan server
+func normalizeOptionName(key string) string {
+	return strings.ReplaceAll(key, "-", "_")
+}
+
+// parseOptionValue melepas kutipan dan komentar di akhir nilai
+func parseOptionValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if q := value[0]; q == '"' || q == '\'' {
+		end := strings.IndexByte(value[1:], q)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quote in value %s", value)
+		}
+		rest := strings.TrimSpace(value[end+2:])
+		if rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected text after quoted value: %s", rest)
+		}
+		return value[1 : end+1], nil
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value, nil
+}
@@ -44,6 +44,8 @@ func SetDefaultValues(template *MariaDBConfigTemplate) {
 		"log_error":                                "/var/lib/mysql/mysql_error.log",
 		"slow_query_log_file":                      "/var/lib/mysql/mysql_slow.log",
 		"innodb_buffer_pool_instances":             "8",
+		"innodb_flush_neighbors":                   "1",
+		"innodb_io_capacity":                       "200",
 	}
 	for key, value := range defaults {
 		if _, exists := template.DefaultValues[key]; !exists {
@@ -0,0 +1,90 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sfDBTools/utils/fs"
+	mariadb_config "sfDBTools/utils/mariadb/config"
+)
+
+// PlanDirectories mengembalikan direktori yang akan dibuat oleh configure
+// (data, binlog, log, direktori kunci enkripsi dan direktori backup config)
+func PlanDirectories(config *mariadb_config.MariaDBConfigureConfig) []string {
+	candidates := []string{config.DataDir, config.BinlogDir, config.LogDir}
+	if config.InnodbEncryptTables && config.EncryptionKeyFile != "" {
+		candidates = append(candidates, filepath.Dir(config.EncryptionKeyFile))
+	}
+	candidates = append(candidates, config.BackupDir)
+
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, d := range candidates {
+		if d == "" {
+			continue
+		}
+		d = filepath.Clean(d)
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		if _, err := os.Stat(d); os.IsNotExist(err) {
+			dirs = append(dirs, d)
+		}
+	}
+	return dirs
+}
+
+// validateDirectoriesDryRun memeriksa direktori tanpa membuatnya: direktori
+// yang sudah ada harus bisa ditulis, yang belum ada harus bisa dibuat di
+// parent terdekat yang sudah ada
+func validateDirectoriesDryRun(config *mariadb_config.MariaDBConfigureConfig) error {
+	if config.DataDir == config.BinlogDir {
+		return fmt.Errorf("data-dir and binlog-dir cannot be the same: %s", config.DataDir)
+	}
+
+	manager := fs.NewManager()
+	for _, d := range []string{config.DataDir, config.BinlogDir} {
+		existing := nearestExistingDir(d)
+		if existing == "" {
+			return fmt.Errorf("no existing parent directory for %s", d)
+		}
+		if err := manager.Dir().IsWritable(existing); err != nil {
+			if existing == filepath.Clean(d) {
+				return fmt.Errorf("directory %s is not writable: %w", d, err)
+			}
+			return fmt.Errorf("%s cannot be created: %w", d, err)
+		}
+	}
+	return nil
+}
+
+// validateEncryptionKeyFileDryRun memeriksa path kunci enkripsi tanpa membuat
+// direktori atau file uji
+func validateEncryptionKeyFileDryRun(keyFile string) error {
+	if keyFile == "" {
+		return fmt.Errorf("encryption key file path is required when encryption is enabled")
+	}
+	if !filepath.IsAbs(keyFile) {
+		return fmt.Errorf("encryption key file must be absolute path: %s", keyFile)
+	}
+	if _, err := os.Stat(keyFile); err == nil {
+		if _, err := os.ReadFile(keyFile); err != nil {
+			return fmt.Errorf("encryption key file is not readable: %w", err)
+		}
+	}
+	return nil
+}
+
+// nearestExistingDir mengembalikan path itu sendiri atau parent terdekat yang ada
+func nearestExistingDir(path string) string {
+	for p := filepath.Clean(path); ; p = filepath.Dir(p) {
+		if info, err := os.Stat(p); err == nil && info.IsDir() {
+			return p
+		}
+		if p == filepath.Dir(p) {
+			return ""
+		}
+	}
+}
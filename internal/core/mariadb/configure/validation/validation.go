@@ -26,7 +26,12 @@ func ValidateSystemRequirements(ctx context.Context, config *mariadb_config.Mari
 
 	lg.Info("Starting system requirements validation")
 
-	if err := validateDirectories(config); err != nil {
+	// Dry run hanya memeriksa; direktori dan file uji tidak dibuat
+	if config.DryRun {
+		if err := validateDirectoriesDryRun(config); err != nil {
+			return fmt.Errorf("directory validation failed: %w", err)
+		}
+	} else if err := validateDirectories(config); err != nil {
 		return fmt.Errorf("directory validation failed: %w", err)
 	}
 
@@ -35,7 +40,11 @@ func ValidateSystemRequirements(ctx context.Context, config *mariadb_config.Mari
 	}
 
 	if config.InnodbEncryptTables {
-		if err := validateEncryptionKeyFile(config.EncryptionKeyFile); err != nil {
+		validateKeyFile := validateEncryptionKeyFile
+		if config.DryRun {
+			validateKeyFile = validateEncryptionKeyFileDryRun
+		}
+		if err := validateKeyFile(config.EncryptionKeyFile); err != nil {
 			return fmt.Errorf("encryption key file validation failed: %w", err)
 		}
 	}
@@ -44,8 +53,11 @@ func ValidateSystemRequirements(ctx context.Context, config *mariadb_config.Mari
 		return fmt.Errorf("disk space validation failed: %w", err)
 	}
 
-	if err := validateDirectoryPermissions(config); err != nil {
-		return fmt.Errorf("directory permissions validation failed: %w", err)
+	// Memperbaiki permission berarti mengubah direktori; dilewati saat dry run
+	if !config.DryRun {
+		if err := validateDirectoryPermissions(config); err != nil {
+			return fmt.Errorf("directory permissions validation failed: %w", err)
+		}
 	}
 
 	lg.Info("All system requirements validation passed")
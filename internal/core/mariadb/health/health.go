@@ -0,0 +1,347 @@
+// Package health collects the figures an operator looks at first on a
+// MariaDB server: uptime, connections, buffer pool, replication, the disk of
+// the data directory and slow queries. Each section is graded so the report
+// can feed monitoring scripts as well as a terminal.
+package health
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/fs"
+)
+
+// Status grades a report or a finding
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusCrit Status = "crit"
+)
+
+// Thresholds at which a figure becomes a warning or critical
+const (
+	ConnectionsWarnPercent = 80
+	ConnectionsCritPercent = 95
+	LagWarnSeconds         = 60
+	LagCritSeconds         = 300
+	DiskWarnPercent        = 85
+	DiskCritPercent        = 95
+	HitRatioWarnPercent    = 95
+)
+
+// Report is the health of one server
+type Report struct {
+	Server        string      `json:"server"`
+	Version       string      `json:"version"`
+	CheckedAt     time.Time   `json:"checked_at"`
+	UptimeSeconds int64       `json:"uptime_seconds"`
+	Status        Status      `json:"status"`
+	Connections   Connections `json:"connections"`
+	BufferPool    BufferPool  `json:"buffer_pool"`
+	Replication   Replication `json:"replication"`
+	Disk          *Disk       `json:"disk,omitempty"`
+	SlowQueries   SlowQueries `json:"slow_queries"`
+	Findings      []Finding   `json:"findings,omitempty"`
+}
+
+// Connections are the client threads against max_connections
+type Connections struct {
+	Connected      int64   `json:"connected"`
+	Running        int64   `json:"running"`
+	MaxUsed        int64   `json:"max_used"`
+	Max            int64   `json:"max"`
+	UsagePercent   float64 `json:"usage_percent"`
+	AbortedClients int64   `json:"aborted_clients"`
+	AbortedConnect int64   `json:"aborted_connects"`
+}
+
+// BufferPool is the InnoDB buffer pool. HitRatioPercent is the share of
+// page reads served from memory since startup.
+type BufferPool struct {
+	SizeBytes       int64   `json:"size_bytes"`
+	PagesTotal      int64   `json:"pages_total"`
+	PagesData       int64   `json:"pages_data"`
+	PagesDirty      int64   `json:"pages_dirty"`
+	PagesFree       int64   `json:"pages_free"`
+	UsagePercent    float64 `json:"usage_percent"`
+	HitRatioPercent float64 `json:"hit_ratio_percent"`
+}
+
+// Replication describes the role of the server. LagSeconds is nil when the
+// server is no replica or Seconds_Behind_Master is NULL.
+type Replication struct {
+	Role       string `json:"role"`
+	Replicas   int    `json:"replicas,omitempty"`
+	IORunning  string `json:"io_running,omitempty"`
+	SQLRunning string `json:"sql_running,omitempty"`
+	LagSeconds *int64 `json:"lag_seconds,omitempty"`
+	LastError  string `json:"last_error,omitempty"`
+}
+
+// Disk is the file system of the data directory. It is only measured when the
+// server runs on this host.
+type Disk struct {
+	Datadir     string  `json:"datadir"`
+	Total       int64   `json:"total_bytes"`
+	Used        int64   `json:"used_bytes"`
+	Free        int64   `json:"free_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// SlowQueries counts queries slower than long_query_time since startup
+type SlowQueries struct {
+	Count         int64   `json:"count"`
+	PerHour       float64 `json:"per_hour"`
+	LogEnabled    bool    `json:"log_enabled"`
+	LongQueryTime float64 `json:"long_query_time"`
+}
+
+// Finding is a figure outside its threshold
+type Finding struct {
+	Status  Status `json:"status"`
+	Section string `json:"section"`
+	Message string `json:"message"`
+}
+
+// Check connects to the server of cfg and builds its report
+func Check(cfg database.Config) (*Report, error) {
+	lg, _ := logger.Get()
+	db, err := database.GetWithoutDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	status, err := globalStatus(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read global status: %w", err)
+	}
+	var (
+		version, datadir string
+		maxConnections   int64
+		bufferPoolSize   int64
+		slowLog          int
+		longQueryTime    float64
+		logBin           int
+	)
+	err = db.QueryRow("SELECT @@version, @@datadir, @@max_connections, @@innodb_buffer_pool_size, @@slow_query_log, @@long_query_time, @@log_bin").
+		Scan(&version, &datadir, &maxConnections, &bufferPoolSize, &slowLog, &longQueryTime, &logBin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server variables: %w", err)
+	}
+
+	r := &Report{
+		Server:        fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Version:       version,
+		CheckedAt:     time.Now(),
+		UptimeSeconds: status["Uptime"],
+	}
+	r.Connections = Connections{
+		Connected:      status["Threads_connected"],
+		Running:        status["Threads_running"],
+		MaxUsed:        status["Max_used_connections"],
+		Max:            maxConnections,
+		UsagePercent:   percent(status["Threads_connected"], maxConnections),
+		AbortedClients: status["Aborted_clients"],
+		AbortedConnect: status["Aborted_connects"],
+	}
+	r.BufferPool = BufferPool{
+		SizeBytes:    bufferPoolSize,
+		PagesTotal:   status["Innodb_buffer_pool_pages_total"],
+		PagesData:    status["Innodb_buffer_pool_pages_data"],
+		PagesDirty:   status["Innodb_buffer_pool_pages_dirty"],
+		PagesFree:    status["Innodb_buffer_pool_pages_free"],
+		UsagePercent: percent(status["Innodb_buffer_pool_pages_total"]-status["Innodb_buffer_pool_pages_free"], status["Innodb_buffer_pool_pages_total"]),
+	}
+	if requests := status["Innodb_buffer_pool_read_requests"]; requests > 0 {
+		r.BufferPool.HitRatioPercent = 100 - percent(status["Innodb_buffer_pool_reads"], requests)
+	}
+	r.SlowQueries = SlowQueries{
+		Count:         status["Slow_queries"],
+		LogEnabled:    slowLog == 1,
+		LongQueryTime: longQueryTime,
+	}
+	if r.UptimeSeconds > 0 {
+		r.SlowQueries.PerHour = float64(r.SlowQueries.Count) * 3600 / float64(r.UptimeSeconds)
+	}
+
+	if r.Replication, err = replication(db, logBin == 1); err != nil {
+		return nil, fmt.Errorf("failed to read replication status: %w", err)
+	}
+	if isLocal(cfg) {
+		if usage, err := fs.NewManager().Dir().GetDiskUsage(datadir); err == nil {
+			r.Disk = &Disk{Datadir: datadir, Total: usage.Total, Used: usage.Used, Free: usage.Free, UsedPercent: usage.UsedPercent}
+		} else {
+			lg.Warn("Failed to measure the data directory", logger.String("datadir", datadir), logger.Error(err))
+		}
+	}
+
+	r.grade()
+	lg.Debug("Health check done", logger.String("server", r.Server), logger.String("status", string(r.Status)), logger.Int("findings", len(r.Findings)))
+	return r, nil
+}
+
+// grade compares the figures with the thresholds and sets the overall status
+// to the worst finding
+func (r *Report) grade() {
+	level := func(value, warn, crit float64) Status {
+		switch {
+		case value >= crit:
+			return StatusCrit
+		case value >= warn:
+			return StatusWarn
+		}
+		return StatusOK
+	}
+	add := func(s Status, section, format string, args ...any) {
+		if s != StatusOK {
+			r.Findings = append(r.Findings, Finding{Status: s, Section: section, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	add(level(r.Connections.UsagePercent, ConnectionsWarnPercent, ConnectionsCritPercent), "connections",
+		"%d of %d connections in use (%.1f%%)", r.Connections.Connected, r.Connections.Max, r.Connections.UsagePercent)
+	if r.BufferPool.HitRatioPercent > 0 && r.BufferPool.HitRatioPercent < HitRatioWarnPercent {
+		add(StatusWarn, "buffer_pool", "buffer pool hit ratio is %.1f%%; innodb_buffer_pool_size may be too small", r.BufferPool.HitRatioPercent)
+	}
+	if rep := r.Replication; rep.Role == "replica" {
+		if rep.IORunning != "Yes" || rep.SQLRunning != "Yes" {
+			msg := fmt.Sprintf("replication threads stopped (IO %s, SQL %s)", rep.IORunning, rep.SQLRunning)
+			if rep.LastError != "" {
+				msg += ": " + rep.LastError
+			}
+			add(StatusCrit, "replication", "%s", msg)
+		} else if rep.LagSeconds == nil {
+			add(StatusWarn, "replication", "replication lag is unknown")
+		} else {
+			add(level(float64(*rep.LagSeconds), LagWarnSeconds, LagCritSeconds), "replication", "replica is %ds behind the primary", *rep.LagSeconds)
+		}
+	}
+	if r.Disk != nil {
+		add(level(r.Disk.UsedPercent, DiskWarnPercent, DiskCritPercent), "disk",
+			"file system of %s is %.1f%% full", r.Disk.Datadir, r.Disk.UsedPercent)
+	}
+
+	r.Status = StatusOK
+	for _, f := range r.Findings {
+		if f.Status == StatusCrit || r.Status == StatusOK {
+			r.Status = f.Status
+		}
+	}
+}
+
+// globalStatus returns the numeric SHOW GLOBAL STATUS counters
+func globalStatus(db *sql.DB) (map[string]int64, error) {
+	rows, err := db.Query("SHOW GLOBAL STATUS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	status := make(map[string]int64)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			status[name] = n
+		}
+	}
+	return status, rows.Err()
+}
+
+// replication reads SHOW SLAVE STATUS. A server without it is a primary when
+// it writes a binary log that replicas are connected to, standalone otherwise.
+func replication(db *sql.DB, logBin bool) (Replication, error) {
+	rows, err := db.Query("SHOW SLAVE STATUS")
+	if err != nil {
+		return Replication{}, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return Replication{}, err
+	}
+	if rows.Next() {
+		values := make([]sql.NullString, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return Replication{}, err
+		}
+		status := make(map[string]string, len(cols))
+		for i, c := range cols {
+			status[c] = values[i].String
+		}
+		rep := Replication{
+			Role:       "replica",
+			IORunning:  status["Slave_IO_Running"],
+			SQLRunning: status["Slave_SQL_Running"],
+			LastError:  firstNonEmpty(status["Last_SQL_Error"], status["Last_IO_Error"]),
+		}
+		if lag, err := strconv.ParseInt(status["Seconds_Behind_Master"], 10, 64); err == nil {
+			rep.LagSeconds = &lag
+		}
+		return rep, nil
+	}
+	if err := rows.Err(); err != nil {
+		return Replication{}, err
+	}
+	if !logBin {
+		return Replication{Role: "standalone"}, nil
+	}
+
+	hosts, err := db.Query("SHOW SLAVE HOSTS")
+	if err != nil {
+		return Replication{}, err
+	}
+	defer hosts.Close()
+	replicas := 0
+	for hosts.Next() {
+		replicas++
+	}
+	if replicas == 0 {
+		return Replication{Role: "standalone"}, hosts.Err()
+	}
+	return Replication{Role: "primary", Replicas: replicas}, hosts.Err()
+}
+
+// isLocal reports whether the server runs on this host, so its datadir can
+// be measured here
+func isLocal(cfg database.Config) bool {
+	if cfg.Socket != "" {
+		return true
+	}
+	switch strings.ToLower(cfg.Host) {
+	case "", "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	name, err := os.Hostname()
+	return err == nil && strings.EqualFold(name, cfg.Host)
+}
+
+func percent(part, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	mariadb_config "sfDBTools/utils/mariadb/config"
 	defaultsetup "sfDBTools/utils/mariadb/defaultSetup"
 	"sfDBTools/utils/system"
@@ -20,19 +21,19 @@ func RunMariaDBInstall(ctx context.Context, cfg *mariadb_config.MariaDBInstallCo
 
 	// Inisialisasi dependencies
 	deps := &defaultsetup.Dependencies{
-		PackageManager: system.NewPackageManager(),
+		PackageManager: system.NewPackageManagerContext(ctx),
 		ProcessManager: system.NewProcessManager(),
 		ServiceManager: system.NewServiceManager(),
 	}
 
 	// Langkah 1: Pre-installation checks (termasuk OS dan hak akses)
-	terminal.Headers("MariaDB Pre-Installation Checks")
+	terminal.Headers(i18n.T("mariadb.install.precheck_title"))
 	installation, err := preInstallationChecks(cfg, deps)
 	if err != nil {
 		return fmt.Errorf("pre-installation checks gagal: %w", err)
 	}
 
-	terminal.Headers("MariaDB Installation Process")
+	terminal.Headers(i18n.T("mariadb.install.title"))
 	// Langkah 2: Validasi konfigurasi (tidak ada lagi interactive input)
 	if err := validateFinalConfig(cfg); err != nil {
 		return fmt.Errorf("validasi konfigurasi gagal: %w", err)
@@ -68,9 +69,12 @@ func RunMariaDBInstall(ctx context.Context, cfg *mariadb_config.MariaDBInstallCo
 		return fmt.Errorf("verifikasi instalasi gagal: %w", err)
 	}
 
-	// Langkah 8: Post-installation
-	terminal.Headers("MariaDB Post-Installation Setup")
-	if err := postInstallationSetup(deps, mariadb_config, installation); err != nil {
+	// Langkah 8: Post-installation; dibatalkan bila Ctrl+C ditekan selama langkah sebelumnya
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("instalasi dibatalkan: %w", err)
+	}
+	terminal.Headers(i18n.T("mariadb.install.post_title"))
+	if err := postInstallationSetup(ctx, deps, mariadb_config, installation); err != nil {
 		return fmt.Errorf("post-installation setup gagal: %w", err)
 	}
 
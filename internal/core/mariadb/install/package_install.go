@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	defaultsetup "sfDBTools/utils/mariadb/defaultSetup"
 	"sfDBTools/utils/system"
 	"sfDBTools/utils/terminal"
@@ -13,20 +14,20 @@ import (
 func updatePackageCache(deps *defaultsetup.Dependencies) error {
 	lg, _ := logger.Get()
 
-	terminal.PrintSubHeader("[Package Manager] Update Cache")
+	terminal.PrintSubHeader(i18n.T("mariadb.install.update_cache"))
 
 	// Show spinner while updating package cache so user sees progress
-	spinner := terminal.NewInstallSpinner("Mengupdate cache package manager...")
+	spinner := terminal.NewInstallSpinner(i18n.T("mariadb.install.updating_cache"))
 	spinner.Start()
 
 	lg.Info("[Package Manager] Mengupdate cache")
 
 	if err := deps.PackageManager.UpdateCache(); err != nil {
-		spinner.StopWithError("Gagal mengupdate cache package manager")
+		spinner.StopWithError(i18n.T("mariadb.install.update_cache_failed"))
 		return fmt.Errorf("gagal mengupdate cache package manager: %w", err)
 	}
 
-	spinner.StopWithSuccess("Cache package manager berhasil diupdate")
+	spinner.StopWithSuccess(i18n.T("mariadb.install.cache_updated"))
 	lg.Info("[Package Manager] Cache berhasil diupdate")
 
 	return nil
@@ -37,20 +38,20 @@ func updatePackageCache(deps *defaultsetup.Dependencies) error {
 func updateSystemPackages(deps *defaultsetup.Dependencies) error {
 	lg, _ := logger.Get()
 
-	terminal.PrintSubHeader("[Package Manager] Upgrade Paket Sistem")
+	terminal.PrintSubHeader(i18n.T("mariadb.install.upgrade_packages"))
 	// Show spinner while upgrading packages so user sees progress
-	spinner := terminal.NewInstallSpinner("Mengupgrade paket sistem...")
+	spinner := terminal.NewInstallSpinner(i18n.T("mariadb.install.upgrading_packages"))
 	spinner.Start()
 
 	lg.Info("[Package Manager] Mengupgrade paket sistem")
 
 	if err := deps.PackageManager.Upgrade(); err != nil {
-		spinner.StopWithError("Gagal mengupgrade paket sistem")
+		spinner.StopWithError(i18n.T("mariadb.install.upgrade_failed"))
 		lg.Error("upgrade paket sistem gagal", logger.Error(err))
 		return fmt.Errorf("gagal mengupgrade paket sistem: %w", err)
 	}
 
-	spinner.StopWithSuccess("Paket sistem berhasil diupgrade")
+	spinner.StopWithSuccess(i18n.T("mariadb.install.packages_upgraded"))
 	lg.Info("[Package Manager] Paket sistem berhasil diupgrade")
 	return nil
 }
@@ -58,42 +59,42 @@ func updateSystemPackages(deps *defaultsetup.Dependencies) error {
 // installMariaDBPackages menginstall paket MariaDB server dan client satu per satu dengan progress
 func installMariaDBPackages(deps *defaultsetup.Dependencies) error {
 	lg, _ := logger.Get()
-	terminal.PrintSubHeader("[Package Manager] Install Paket MariaDB")
-	spinner := terminal.NewInstallSpinner("Menentukan dan menginstall paket MariaDB...")
+	terminal.PrintSubHeader(i18n.T("mariadb.install.install_packages"))
+	spinner := terminal.NewInstallSpinner(i18n.T("mariadb.install.resolving_packages"))
 	spinner.Start()
 
 	osInfo, err := system.DetectOS()
 	if err != nil {
-		spinner.StopWithError("Gagal mendeteksi OS untuk penentuan paket")
+		spinner.StopWithError(i18n.T("mariadb.install.detect_os_failed"))
 		return fmt.Errorf("gagal deteksi OS untuk penentuan paket MariaDB: %w", err)
 	}
 
 	packages, err := getMariaDBPackageNames(osInfo)
 	if err != nil {
-		spinner.StopWithError("Gagal menentukan nama paket MariaDB")
+		spinner.StopWithError(i18n.T("mariadb.install.package_names_failed"))
 		return fmt.Errorf("gagal menentukan nama paket MariaDB: %w", err)
 	}
 
-	spinner.StopWithSuccess("Daftar paket MariaDB berhasil didapatkan")
+	spinner.StopWithSuccess(i18n.T("mariadb.install.packages_resolved"))
 
 	total := len(packages)
 	for i, pkg := range packages {
 		// Use spinner per package to avoid flooding logs with many lines
-		pkgSpinner := terminal.NewInstallSpinner(fmt.Sprintf("[%d/%d] Menginstall paket: %s", i+1, total, pkg))
+		pkgSpinner := terminal.NewInstallSpinner(i18n.T("mariadb.install.installing_package", i+1, total, pkg))
 		pkgSpinner.Start()
 
 		lg.Info("installing package", logger.Int("index", i+1), logger.Int("total", total), logger.String("package", pkg))
 
 		// Install satu package
 		if err := deps.PackageManager.Install([]string{pkg}); err != nil {
-			pkgSpinner.StopWithError("Gagal menginstall paket")
+			pkgSpinner.StopWithError(i18n.T("mariadb.install.package_failed"))
 			errorMsg := fmt.Sprintf("Gagal menginstall paket %s", pkg)
 			// Log error with stack-like message but avoid printing full output to stdout
 			lg.Error(errorMsg, logger.Error(err))
 			return fmt.Errorf("gagal menginstall paket %s: %w", pkg, err)
 		}
 
-		pkgSpinner.StopWithSuccess("Berhasil")
+		pkgSpinner.StopWithSuccess(i18n.T("common.done"))
 		lg.Info("package installed", logger.String("package", pkg))
 	}
 
@@ -140,6 +141,29 @@ func getMariaDBPackageNames(osInfo *system.OSInfo) ([]string, error) {
 		}
 
 	case "rpm":
+		if osInfo.IsSUSE() || osInfo.ID == "amzn" {
+			// SLES/openSUSE dan Amazon Linux tidak memakai EPEL; hanya
+			// utilitas yang tersedia di repository bawaan
+			packages = []string{
+				// Core MariaDB
+				"MariaDB-server",
+				"MariaDB-client",
+				"MariaDB-backup",
+				"MariaDB-common",
+				"MariaDB-shared",
+
+				// System utilities & monitoring
+				"sysstat",
+				"rsync",
+				"lsof",
+				"strace",
+
+				// Compression utilities for backups
+				"pigz",
+			}
+			break
+		}
+
 		// CentOS/RHEL/Rocky packages
 		packages = []string{
 			// EPEL repository (harus diinstall pertama)
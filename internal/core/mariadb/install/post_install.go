@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	mariadb_config "sfDBTools/utils/mariadb/config"
 	defaultsetup "sfDBTools/utils/mariadb/defaultSetup"
 	"sfDBTools/utils/mariadb/discovery"
@@ -11,27 +12,20 @@ import (
 )
 
 // Post-installation setup seperti konfigurasi awal
-func postInstallationSetup(deps *defaultsetup.Dependencies, mariadb_config *mariadb_config.MariaDBConfigureConfig, installation *discovery.MariaDBInstallation) error {
+func postInstallationSetup(ctx context.Context, deps *defaultsetup.Dependencies, mariadb_config *mariadb_config.MariaDBConfigureConfig, installation *discovery.MariaDBInstallation) error {
 	lg, _ := logger.Get()
 	terminal.Clear()
 	lg.Info("Memulai post-installation setup")
 
 	// Langkah 4 : Konfigurasi standart perusahaan
-	ctx := context.Background()
 	if err := defaultsetup.RunStandardConfiguration(ctx, mariadb_config, installation); err != nil {
 		return fmt.Errorf("gagal menjalankan konfigurasi standart perusahaan: %w", err)
 	}
 
-	// Langkah 3 : Buat database default (hardcoded)
-	terminal.PrintSubHeader("Creating Default Database")
-	if err := defaultsetup.CreateDefaultDatabase(); err != nil {
-		return fmt.Errorf("gagal membuat default database: %w", err)
-	}
-
-	// Langkah 2 : Buat user & grants default (hardcoded)
-	terminal.PrintSubHeader("Creating Default Users and Grants")
-	if err := defaultsetup.CreateDefaultMariaDBUser(); err != nil {
-		return fmt.Errorf("gagal membuat default users/grants: %w", err)
+	// Langkah 2 : Buat database, user & grants default dari template provisioning
+	terminal.PrintSubHeader(i18n.T("mariadb.install.provision"))
+	if err := defaultsetup.ApplyDefaultProvisioning(ctx); err != nil {
+		return fmt.Errorf("gagal membuat default database/users/grants: %w", err)
 	}
 
 	// Selesai
@@ -3,13 +3,13 @@ package install
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"sfDBTools/internal/logger"
 	mariadb_config "sfDBTools/utils/mariadb/config"
 	defaultsetup "sfDBTools/utils/mariadb/defaultSetup"
 	"sfDBTools/utils/mariadb/discovery"
 	"sfDBTools/utils/system"
+	"sfDBTools/utils/tempdir"
 )
 
 // preInstallationChecks melakukan pemeriksaan sebelum instalasi
@@ -55,6 +55,16 @@ func preInstallationChecks(cfg *mariadb_config.MariaDBInstallConfig, deps *defau
 		return nil, fmt.Errorf("instalasi MariaDB memerlukan hak akses root. Jalankan dengan sudo")
 	}
 
+	// Cek direktori temporary untuk unduhan script repository dan paket
+	if err := tempdir.Check("mariadb install", 0); err != nil {
+		return nil, err
+	}
+
+	// Template provisioning harus lengkap sebelum paket diinstall
+	if err := defaultsetup.CheckDefaultProvisioning(); err != nil {
+		return nil, err
+	}
+
 	return installation, nil
 }
 
@@ -92,32 +102,6 @@ func isMariaDBInstalled(deps *defaultsetup.Dependencies) bool {
 	return false
 }
 
-func getInstalledMariaDBVersion(deps *defaultsetup.Dependencies) string {
-	// Coba jalankan mysql --version untuk mendapatkan versi
-	output, err := deps.ProcessManager.ExecuteWithOutput("mysql", []string{"--version"})
-	if err != nil {
-		return ""
-	}
-
-	// Parse output untuk mendapatkan versi
-	lines := strings.Split(output, "\n")
-	if len(lines) > 0 {
-		// Contoh output: mysql  Ver 15.1 Distrib 10.6.23-MariaDB, for Linux (x86_64)
-		if strings.Contains(lines[0], "MariaDB") {
-			parts := strings.Split(lines[0], " ")
-			for _, part := range parts {
-				if strings.Contains(part, "MariaDB") {
-					// Ekstrak versi dari format "10.6.23-MariaDB,"
-					version := strings.Split(part, "-")[0]
-					return version
-				}
-			}
-		}
-	}
-
-	return ""
-}
-
 func isRunningAsRoot() bool {
 	return os.Geteuid() == 0
 }
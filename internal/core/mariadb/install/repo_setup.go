@@ -4,57 +4,68 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/download"
+	"sfDBTools/utils/i18n"
 	mariadb_config "sfDBTools/utils/mariadb/config"
 	defaultsetup "sfDBTools/utils/mariadb/defaultSetup"
+	"sfDBTools/utils/mariadb/versions"
+	"sfDBTools/utils/system"
+	"sfDBTools/utils/tempdir"
 	"sfDBTools/utils/terminal"
 )
 
 // setupMariaDBRepository mengunduh dan menjalankan script setup repository
 func setupMariaDBRepository(ctx context.Context, cfg *mariadb_config.MariaDBInstallConfig, deps *defaultsetup.Dependencies) error {
 	lg, _ := logger.Get()
-	terminal.PrintSubHeader("[Repository] Setup")
+	terminal.PrintSubHeader(i18n.T("mariadb.install.repo_setup"))
 	lg.Info("[Repository] Setup Start")
 
 	// 1) Detect existing repo files
-	checkSpinner := terminal.NewInstallSpinner("Mengecek repository MariaDB yang ada...")
+	checkSpinner := terminal.NewInstallSpinner(i18n.T("mariadb.install.checking_repo"))
 	checkSpinner.Start()
 	found, err := detectExistingMariaDBRepo()
 	if err != nil {
-		checkSpinner.StopWithError("Gagal mengecek repository yang ada")
+		checkSpinner.StopWithError(i18n.T("mariadb.install.check_repo_failed"))
 		lg.Debug("gagal mengecek repo yang ada", logger.Error(err))
 		return fmt.Errorf("gagal mengecek repository yang ada: %w", err)
 	}
 
 	// If repo files exist, check whether they already match desired version
-	normalized := normalizeVersionForRepo(cfg.Version)
+	normalized := versions.Series(cfg.Version)
+	osInfo, err := system.DetectOS()
+	if err != nil {
+		checkSpinner.StopWithError(i18n.T("mariadb.install.check_repo_failed"))
+		return fmt.Errorf("gagal deteksi OS untuk repository: %w", err)
+	}
 	if len(found) > 0 {
-		if repoFilesContainVersion(found, normalized) {
-			checkSpinner.StopWithSuccess("Repository MariaDB sudah sesuai, tidak perlu setup")
+		// Repo dari image/mesin lain bisa menunjuk ke arsitektur yang berbeda (mis. x86_64 di server aarch64)
+		if repoFilesContainVersion(found, normalized) && repoFilesMatchArch(found, osInfo) {
+			checkSpinner.StopWithSuccess(i18n.T("mariadb.install.repo_ok"))
 			lg.Info("[Repository] Repo sudah sesuai versi yang akan diinstall, melewatkan setup")
 			return nil
 		}
 
 		// Need to backup/cleanup existing repo files before proceeding
-		checkSpinner.StopWithSuccess("Repository lama ditemukan — akan dibackup sebelum setup")
-		backupSpinner := terminal.NewInstallSpinner("Membackup repository MariaDB yang lama...")
+		checkSpinner.StopWithSuccess(i18n.T("mariadb.install.old_repo_found"))
+		backupSpinner := terminal.NewInstallSpinner(i18n.T("mariadb.install.backing_up_repo"))
 		backupSpinner.Start()
 		backupDir, berr := backupRepoFiles(found)
 		if berr != nil {
-			backupSpinner.StopWithError("Gagal membackup repository lama")
+			backupSpinner.StopWithError(i18n.T("mariadb.install.backup_repo_failed"))
 			lg.Debug("gagal membackup repo", logger.Error(berr))
 			return fmt.Errorf("gagal membackup repository lama: %w", berr)
 		}
-		backupSpinner.StopWithSuccess("Repository lama dibackup: " + backupDir)
+		backupSpinner.StopWithSuccess(i18n.T("mariadb.install.repo_backed_up", backupDir))
 		lg.Info("[Repository] Repo lama dibackup", logger.String("backup_dir", backupDir))
 	} else {
-		checkSpinner.StopWithSuccess("Tidak ditemukan repository MariaDB yang lama")
+		checkSpinner.StopWithSuccess(i18n.T("mariadb.install.no_old_repo"))
 	}
 
 	// 2) Check if mariadb_repo_setup script already exists on the system
@@ -63,15 +74,15 @@ func setupMariaDBRepository(ctx context.Context, cfg *mariadb_config.MariaDBInst
 		lg.Info("Menemukan script mariadb_repo_setup yang sudah ada", logger.String("path", scriptPath))
 	} else {
 		// Download mariadb_repo_setup script (show spinner for the download)
-		dlSpinner := terminal.NewDownloadSpinner("Mengunduh script setup repository...")
+		dlSpinner := terminal.NewDownloadSpinner(i18n.T("mariadb.install.downloading_script"))
 		dlSpinner.Start()
 		scriptPath, err = downloadRepoSetupScript(ctx)
 		if err != nil {
-			dlSpinner.StopWithError("Gagal mengunduh script setup repository")
+			dlSpinner.StopWithError(i18n.T("mariadb.install.download_failed"))
 			lg.Debug("gagal mengunduh script setup repository", logger.Error(err))
 			return fmt.Errorf("gagal mengunduh script setup repository: %w", err)
 		}
-		dlSpinner.StopWithSuccess("Script setup repository berhasil diunduh")
+		dlSpinner.StopWithSuccess(i18n.T("mariadb.install.downloaded"))
 		defer os.Remove(scriptPath)
 
 		// Buat permission executable
@@ -83,15 +94,20 @@ func setupMariaDBRepository(ctx context.Context, cfg *mariadb_config.MariaDBInst
 	// 3) Jalankan script dengan parameter yang sesuai
 	args := buildRepoSetupArgs(cfg)
 
-	runSpinner := terminal.NewInstallSpinner("Menjalankan script setup repository...")
+	runSpinner := terminal.NewInstallSpinner(i18n.T("mariadb.install.running_script"))
 	runSpinner.Start()
 	if err := deps.ProcessManager.ExecuteWithTimeout("bash", append([]string{scriptPath}, args...), 5*time.Minute); err != nil {
-		runSpinner.StopWithError("Gagal menjalankan script setup repository")
+		runSpinner.StopWithError(i18n.T("mariadb.install.script_failed"))
 		lg.Debug("[Repository] gagal menjalankan script setup", logger.Error(err))
 		return fmt.Errorf("gagal menjalankan script setup repository: %w", err)
 	}
-	runSpinner.StopWithSuccess("Script setup repository berhasil dijalankan")
-	lg.Info("[Repository] Setup selesai")
+	runSpinner.StopWithSuccess(i18n.T("mariadb.install.script_done"))
+
+	// Pastikan repository yang ditulis script menyediakan paket untuk arsitektur server ini
+	if written, err := detectExistingMariaDBRepo(); err == nil && !repoFilesMatchArch(written, osInfo) {
+		return fmt.Errorf("repository MariaDB %s tidak menyediakan paket untuk arsitektur %s", normalized, osInfo.Arch)
+	}
+	lg.Info("[Repository] Setup selesai", logger.String("arch", osInfo.Arch))
 
 	return nil
 }
@@ -101,6 +117,8 @@ func detectExistingMariaDBRepo() ([]string, error) {
 	patterns := []string{
 		"/etc/yum.repos.d/MariaDB.repo*",
 		"/etc/yum.repos.d/mariadb.repo*",
+		"/etc/zypp/repos.d/MariaDB.repo*",
+		"/etc/zypp/repos.d/mariadb.repo*",
 		"/etc/apt/sources.list.d/mariadb.list*",
 		"/etc/apt/sources.list.d/MariaDB.list*",
 		"/etc/apt/trusted.gpg.d/mariadb*",
@@ -128,8 +146,8 @@ func backupRepoFiles(files []string) (string, error) {
 
 	base := "backups/repo_backups"
 	if err := os.MkdirAll(base, 0755); err != nil {
-		// fallback ke /tmp jika tidak bisa membuat di /var/backups
-		base = "/tmp/sfdbtools_backups"
+		// fallback ke direktori temporary jika tidak bisa membuat di /var/backups
+		base = filepath.Join(tempdir.Dir(), "sfdbtools_backups")
 		if err := os.MkdirAll(base, 0755); err != nil {
 			return "", fmt.Errorf("gagal membuat direktori backup: %w", err)
 		}
@@ -188,6 +206,56 @@ func repoFilesContainVersion(files []string, normalizedVersion string) bool {
 	return false
 }
 
+// repoFilesMatchArch memeriksa bahwa file repo tidak terkunci ke arsitektur lain:
+// baseurl rpm tidak boleh berisi path arsitektur lain (kecuali memakai $basearch)
+// dan opsi [arch=...] di sumber apt harus memuat arsitektur server
+func repoFilesMatchArch(files []string, osInfo *system.OSInfo) bool {
+	if osInfo == nil || osInfo.Arch == "" {
+		return true
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(strings.ToLower(line), "baseurl"):
+				if strings.Contains(line, "$basearch") {
+					continue
+				}
+				for _, arch := range system.MariaDBArchitectures {
+					if arch != osInfo.Arch && (strings.Contains(line, "/"+arch+"/") || strings.HasSuffix(line, "/"+arch)) {
+						return false
+					}
+				}
+			case strings.HasPrefix(line, "deb ") || strings.HasPrefix(line, "deb-src "):
+				if !debLineAllowsArch(line, system.DebianArch(osInfo.Arch)) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// debLineAllowsArch memeriksa opsi [arch=a,b] pada baris sumber apt; baris tanpa
+// opsi arch berlaku untuk semua arsitektur
+func debLineAllowsArch(line, debArch string) bool {
+	start := strings.Index(line, "[")
+	end := strings.Index(line, "]")
+	if start < 0 || end < start {
+		return true
+	}
+	for _, opt := range strings.Fields(line[start+1 : end]) {
+		if value, ok := strings.CutPrefix(opt, "arch="); ok {
+			return slices.Contains(strings.Split(value, ","), debArch)
+		}
+	}
+	return true
+}
+
 // findExistingRepoSetupScript mencari apakah ada binary/script mariadb_repo_setup yang bisa dipakai
 func findExistingRepoSetupScript() string {
 	candidates := []string{"/usr/local/bin/mariadb_repo_setup", "/usr/bin/mariadb_repo_setup", "/bin/mariadb_repo_setup"}
@@ -199,40 +267,19 @@ func findExistingRepoSetupScript() string {
 	return ""
 }
 
-// downloadRepoSetupScript mengunduh script setup repository ke file temporary
+// downloadRepoSetupScript mengunduh script setup repository ke direktori
+// temporary. Unduhan yang terputus dilanjutkan dari byte terakhir dan diulang
+// sesuai general.download; path-nya tetap sehingga file .part dari run
+// sebelumnya juga dilanjutkan.
 func downloadRepoSetupScript(ctx context.Context) (string, error) {
 	url := "https://downloads.mariadb.com/MariaDB/mariadb_repo_setup"
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", fmt.Errorf("gagal membuat request: %w", err)
-	}
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
+	script := filepath.Join(tempdir.Dir(), "mariadb_repo_setup.sh")
+	if err := download.File(ctx, url, script, download.Options{Label: "mariadb_repo_setup"}); err != nil {
 		return "", fmt.Errorf("gagal mengunduh script: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("gagal mengunduh script, status code: %d", resp.StatusCode)
-	}
-
-	// Simpan ke file temporary
-	tmpFile, err := os.CreateTemp("", "mariadb_repo_setup_*.sh")
-	if err != nil {
-		return "", fmt.Errorf("gagal membuat file temporary: %w", err)
-	}
-	defer tmpFile.Close()
 
-	_, err = io.Copy(tmpFile, resp.Body)
-	if err != nil {
-		os.Remove(tmpFile.Name())
-		return "", fmt.Errorf("gagal menyimpan script: %w", err)
-	}
-
-	return tmpFile.Name(), nil
+	return script, nil
 }
 
 // buildRepoSetupArgs membangun argumen untuk script setup repository
@@ -241,28 +288,34 @@ func buildRepoSetupArgs(cfg *mariadb_config.MariaDBInstallConfig) []string {
 
 	// Tambahkan versi MariaDB (normalisasi ke major.minor karena skrip repo tidak selalu
 	// menerima patch version seperti 10.6.23 — skrip biasanya menerima 10.6 atau 11.4)
-	normalized := normalizeVersionForRepo(cfg.Version)
+	normalized := versions.Series(cfg.Version)
 	args = append(args, "--mariadb-server-version="+normalized)
 
 	// Skip MaxScale (tidak diperlukan untuk instalasi dasar)
 	args = append(args, "--skip-maxscale")
 
+	// OS yang tidak dikenali script dipetakan ke OS kompatibel
+	if osInfo, err := system.DetectOS(); err == nil {
+		args = append(args, repoSetupOSArgs(osInfo)...)
+	}
+
 	return args
 }
 
-// normalizeVersionForRepo mengubah versi lengkap (mis. 10.6.23) menjadi major.minor (10.6)
-// Skrip resmi `mariadb_repo_setup` terkadang menolak patch-level releases; gunakan format
-// mayor.minor saat memanggil script.
-func normalizeVersionForRepo(version string) string {
-	if version == "" {
-		return version
-	}
-
-	// Split into at most 3 parts: major, minor, patch
-	parts := strings.SplitN(version, ".", 3)
-	if len(parts) >= 2 {
-		return parts[0] + "." + parts[1]
+// repoSetupOSArgs mengembalikan --os-type/--os-version untuk OS yang tidak
+// dikenali mariadb_repo_setup: Amazon Linux memakai repository RHEL yang
+// setara dan openSUSE memakai repository SLES 15
+func repoSetupOSArgs(osInfo *system.OSInfo) []string {
+	switch osInfo.ID {
+	case "amzn":
+		// Amazon Linux 2 setara RHEL 7, Amazon Linux 2023 setara RHEL 9
+		rhel := "9"
+		if strings.HasPrefix(osInfo.Version, "2") && !strings.HasPrefix(osInfo.Version, "2023") {
+			rhel = "7"
+		}
+		return []string{"--os-type=rhel", "--os-version=" + rhel}
+	case "opensuse":
+		return []string{"--os-type=sles", "--os-version=15"}
 	}
-	// if version doesn't contain a minor part, return as-is
-	return version
+	return nil
 }
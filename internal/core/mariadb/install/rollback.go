@@ -0,0 +1,143 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
+	defaultsetup "sfDBTools/utils/mariadb/defaultSetup"
+	"sfDBTools/utils/mariadb/versions"
+	"sfDBTools/utils/system"
+	"sfDBTools/utils/terminal"
+)
+
+// RollbackMariaDBUpgrade mengembalikan MariaDB ke keadaan di snapshot:
+// repository lama dipasang kembali, paket diinstall ulang dengan versi persis
+// dari snapshot dan konfigurasi dikembalikan dari salinannya. Data directory
+// tidak disentuh.
+func RollbackMariaDBUpgrade(snap *UpgradeSnapshot) error {
+	lg, _ := logger.Get()
+	if !isRunningAsRoot() {
+		return fmt.Errorf("rollback MariaDB memerlukan hak akses root. Jalankan dengan sudo")
+	}
+
+	deps := &defaultsetup.Dependencies{
+		PackageManager: system.NewPackageManager(),
+		ProcessManager: system.NewProcessManager(),
+		ServiceManager: system.NewServiceManager(),
+	}
+
+	terminal.Headers(i18n.T("mariadb.upgrade.rollback_title"))
+	lg.Info("Memulai rollback upgrade MariaDB",
+		logger.String("snapshot", snap.Dir),
+		logger.String("version", snap.Version))
+
+	// Server dihentikan agar paket dan konfigurasi tidak berganti di bawahnya
+	if err := deps.ServiceManager.Stop(snap.ServiceName); err != nil {
+		lg.Warn("Gagal menghentikan service sebelum rollback", logger.String("service", snap.ServiceName), logger.Error(err))
+	}
+
+	// Langkah 1: Repository versi sebelumnya
+	spinner := terminal.NewInstallSpinner(i18n.T("mariadb.upgrade.restoring_repo"))
+	spinner.Start()
+	if err := restoreRepoFiles(snap); err != nil {
+		spinner.StopWithError(i18n.T("mariadb.upgrade.restore_repo_failed"))
+		return fmt.Errorf("gagal mengembalikan repository: %w", err)
+	}
+	spinner.StopWithSuccess(i18n.T("mariadb.upgrade.repo_restored"))
+
+	if err := updatePackageCache(deps); err != nil {
+		return fmt.Errorf("update package cache gagal: %w", err)
+	}
+
+	// Langkah 2: Paket dengan versi persis dari snapshot
+	if current, err := deps.PackageManager.GetInstalledVersions(); err == nil {
+		var added []string
+		for pkg := range current {
+			if _, ok := snap.Packages[pkg]; !ok {
+				added = append(added, pkg)
+			}
+		}
+		if len(added) > 0 {
+			sort.Strings(added)
+			lg.Warn("Paket yang ditambahkan oleh upgrade tidak dihapus saat rollback", logger.Strings("packages", added))
+		}
+	}
+	spinner = terminal.NewInstallSpinner(i18n.T("mariadb.upgrade.reinstalling"))
+	spinner.Start()
+	if err := deps.PackageManager.InstallVersions(snap.Packages); err != nil {
+		spinner.StopWithError(i18n.T("mariadb.upgrade.reinstall_failed"))
+		return fmt.Errorf("gagal menginstall ulang paket versi sebelumnya: %w", err)
+	}
+	spinner.StopWithSuccess(i18n.T("mariadb.upgrade.reinstalled"))
+
+	// Langkah 3: Konfigurasi; setelah paket agar file konfigurasi paket tertimpa
+	spinner = terminal.NewInstallSpinner(i18n.T("mariadb.upgrade.restoring_config"))
+	spinner.Start()
+	if err := restoreConfigPaths(snap); err != nil {
+		spinner.StopWithError(i18n.T("mariadb.upgrade.restore_config_failed"))
+		return fmt.Errorf("gagal mengembalikan konfigurasi: %w", err)
+	}
+	spinner.StopWithSuccess(i18n.T("mariadb.upgrade.config_restored"))
+
+	// Langkah 4: Start dan verifikasi versi
+	spinner = terminal.NewInstallSpinner(i18n.T("mariadb.upgrade.rollback_checking"))
+	spinner.Start()
+	if err := deps.ServiceManager.Restart(snap.ServiceName); err != nil {
+		spinner.StopWithError(i18n.T("mariadb.upgrade.rollback_check_failed"))
+		return fmt.Errorf("gagal menjalankan service %s: %w", snap.ServiceName, err)
+	}
+	if err := waitForServer(deps, snap.ServiceName, serverReadyTimeout); err != nil {
+		spinner.StopWithError(i18n.T("mariadb.upgrade.rollback_check_failed"))
+		return err
+	}
+	version := versions.Installed(deps.ProcessManager.ExecuteWithOutput)
+	if snap.Version != "" && version != snap.Version {
+		spinner.StopWithError(i18n.T("mariadb.upgrade.rollback_check_failed"))
+		return fmt.Errorf("versi setelah rollback %s, seharusnya %s", version, snap.Version)
+	}
+	spinner.StopWithSuccess(i18n.T("mariadb.upgrade.rollback_ok", version))
+
+	lg.Info("Rollback upgrade MariaDB selesai", logger.String("version", version))
+	return nil
+}
+
+// restoreRepoFiles mengganti file repository MariaDB yang ada dengan salinan
+// di snapshot. Tanpa salinan, repository yang dipasang upgrade dihapus.
+func restoreRepoFiles(snap *UpgradeSnapshot) error {
+	current, err := detectExistingMariaDBRepo()
+	if err != nil {
+		return err
+	}
+	for _, path := range current {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("gagal menghapus %s: %w", path, err)
+		}
+	}
+	for _, path := range snap.RepoFiles {
+		if err := snapshotCopy(filepath.Join(snap.Dir, "repo", path), path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreConfigPaths mengganti file dan direktori konfigurasi dengan salinan di snapshot
+func restoreConfigPaths(snap *UpgradeSnapshot) error {
+	for _, path := range snap.ConfigPaths {
+		src := filepath.Join(snap.Dir, "config", path)
+		if _, err := os.Lstat(src); err != nil {
+			return fmt.Errorf("salinan %s tidak ada di snapshot: %w", path, err)
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("gagal menghapus %s: %w", path, err)
+		}
+		if err := snapshotCopy(src, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"sfDBTools/internal/logger"
 	mariadb_config "sfDBTools/utils/mariadb/config"
 	defaultsetup "sfDBTools/utils/mariadb/defaultSetup"
+	"sfDBTools/utils/mariadb/versions"
 )
 
 // startMariaDBService memulai dan mengaktifkan service MariaDB
@@ -38,7 +39,7 @@ func verifyInstallation(cfg *mariadb_config.MariaDBInstallConfig, deps *defaults
 	}
 
 	// Cek versi yang terinstall
-	installedVersion := getInstalledMariaDBVersion(deps)
+	installedVersion := versions.Installed(deps.ProcessManager.ExecuteWithOutput)
 	if installedVersion == "" {
 		return fmt.Errorf("tidak dapat mendeteksi versi MariaDB yang terinstall")
 	}
@@ -0,0 +1,159 @@
+package install
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/fs"
+	defaultsetup "sfDBTools/utils/mariadb/defaultSetup"
+	"sfDBTools/utils/mariadb/discovery"
+)
+
+// UpgradeStateDir menyimpan snapshot yang dibuat sebelum setiap upgrade
+const UpgradeStateDir = "/var/lib/sfDBTools/upgrade"
+
+// snapshotFile adalah nama file metadata di dalam direktori snapshot
+const snapshotFile = "snapshot.json"
+
+// upgradeConfigPaths adalah file dan direktori konfigurasi MariaDB yang ikut
+// disimpan di snapshot; path yang tidak ada dilewati
+var upgradeConfigPaths = []string{
+	"/etc/my.cnf",
+	"/etc/my.cnf.d",
+	"/etc/mysql",
+	"/etc/sfDBTools/server.cnf",
+}
+
+// UpgradeSnapshot adalah keadaan MariaDB sebelum upgrade: versi paket yang
+// terinstall, salinan konfigurasi dan file repository. File disalin ke
+// <Dir>/config dan <Dir>/repo dengan path absolut aslinya.
+type UpgradeSnapshot struct {
+	Dir           string            `json:"-"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Version       string            `json:"version"`
+	TargetVersion string            `json:"target_version"`
+	ServiceName   string            `json:"service_name"`
+	Packages      map[string]string `json:"packages"`
+	ConfigPaths   []string          `json:"config_paths"`
+	RepoFiles     []string          `json:"repo_files"`
+}
+
+// takeUpgradeSnapshot mencatat versi paket MariaDB yang terinstall dan
+// menyalin konfigurasi serta file repository sebelum upgrade dimulai
+func takeUpgradeSnapshot(deps *defaultsetup.Dependencies, installation *discovery.MariaDBInstallation, targetVersion string) (*UpgradeSnapshot, error) {
+	lg, _ := logger.Get()
+
+	packages, err := deps.PackageManager.GetInstalledVersions()
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca versi paket yang terinstall: %w", err)
+	}
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("tidak ditemukan paket MariaDB yang terinstall")
+	}
+
+	snap := &UpgradeSnapshot{
+		CreatedAt:     time.Now(),
+		Version:       installation.Version,
+		TargetVersion: targetVersion,
+		ServiceName:   installation.ServiceName,
+		Packages:      packages,
+	}
+	if snap.ServiceName == "" {
+		snap.ServiceName = "mariadb"
+	}
+	snap.Dir = filepath.Join(UpgradeStateDir, snap.CreatedAt.Format("20060102-150405"))
+	if err := os.MkdirAll(snap.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("gagal membuat direktori snapshot: %w", err)
+	}
+
+	for _, path := range upgradeConfigPaths {
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		if err := snapshotCopy(path, filepath.Join(snap.Dir, "config", path)); err != nil {
+			return nil, err
+		}
+		snap.ConfigPaths = append(snap.ConfigPaths, path)
+	}
+
+	repoFiles, err := detectExistingMariaDBRepo()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range repoFiles {
+		if err := snapshotCopy(path, filepath.Join(snap.Dir, "repo", path)); err != nil {
+			return nil, err
+		}
+		snap.RepoFiles = append(snap.RepoFiles, path)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyusun metadata snapshot: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(snap.Dir, snapshotFile), data, 0600); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan metadata snapshot: %w", err)
+	}
+
+	lg.Info("Snapshot sebelum upgrade dibuat",
+		logger.String("dir", snap.Dir),
+		logger.String("version", snap.Version),
+		logger.Int("packages", len(snap.Packages)),
+		logger.Strings("config_paths", snap.ConfigPaths))
+	return snap, nil
+}
+
+// LoadUpgradeSnapshot membaca snapshot dari direktorinya
+func LoadUpgradeSnapshot(dir string) (*UpgradeSnapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, snapshotFile))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca snapshot %s: %w", dir, err)
+	}
+	snap := &UpgradeSnapshot{}
+	if err := json.Unmarshal(data, snap); err != nil {
+		return nil, fmt.Errorf("snapshot %s tidak valid: %w", dir, err)
+	}
+	if len(snap.Packages) == 0 {
+		return nil, fmt.Errorf("snapshot %s tidak berisi versi paket", dir)
+	}
+	snap.Dir = dir
+	return snap, nil
+}
+
+// LatestUpgradeSnapshot mengembalikan direktori snapshot terbaru di UpgradeStateDir
+func LatestUpgradeSnapshot() (string, error) {
+	entries, err := os.ReadDir(UpgradeStateDir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("gagal membaca %s: %w", UpgradeStateDir, err)
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			if _, err := os.Stat(filepath.Join(UpgradeStateDir, e.Name(), snapshotFile)); err == nil {
+				dirs = append(dirs, e.Name())
+			}
+		}
+	}
+	if len(dirs) == 0 {
+		return "", fmt.Errorf("tidak ada snapshot upgrade di %s", UpgradeStateDir)
+	}
+	// Nama direktori berupa timestamp sehingga urutan nama = urutan waktu
+	sort.Strings(dirs)
+	return filepath.Join(UpgradeStateDir, dirs[len(dirs)-1]), nil
+}
+
+// snapshotCopy menyalin file atau direktori beserta ownership dan permission-nya
+func snapshotCopy(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return fmt.Errorf("gagal membuat direktori %s: %w", filepath.Dir(dst), err)
+	}
+	if _, err := fs.CopyTree(src, dst, fs.TreeCopyOptions{}); err != nil {
+		return fmt.Errorf("gagal menyalin %s: %w", src, err)
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
+	mariadb_config "sfDBTools/utils/mariadb/config"
+	defaultsetup "sfDBTools/utils/mariadb/defaultSetup"
+	"sfDBTools/utils/mariadb/discovery"
+	"sfDBTools/utils/mariadb/versions"
+	"sfDBTools/utils/system"
+	"sfDBTools/utils/tempdir"
+	"sfDBTools/utils/terminal"
+)
+
+// serverReadyTimeout adalah batas waktu menunggu server menerima koneksi setelah restart
+const serverReadyTimeout = 2 * time.Minute
+
+// UpgradeOptions mengatur RunMariaDBUpgrade
+type UpgradeOptions struct {
+	// Rollback mengembalikan paket dan konfigurasi dari snapshot bila upgrade
+	// atau pemeriksaan setelahnya gagal
+	Rollback bool
+}
+
+// UpgradeResult merangkum hasil upgrade
+type UpgradeResult struct {
+	PreviousVersion string `json:"previous_version"`
+	Version         string `json:"version"`
+	SnapshotDir     string `json:"snapshot_dir"`
+	RolledBack      bool   `json:"rolled_back"`
+	RollbackError   string `json:"rollback_error,omitempty"`
+}
+
+// RunMariaDBUpgrade mengupgrade MariaDB yang terinstall ke cfg.Version. Versi
+// paket, konfigurasi dan repository disimpan di snapshot sebelum upgrade; bila
+// upgrade atau pemeriksaan setelahnya gagal dan opts.Rollback aktif, versi
+// sebelumnya diinstall ulang dan konfigurasinya dikembalikan.
+func RunMariaDBUpgrade(ctx context.Context, cfg *mariadb_config.MariaDBInstallConfig, opts UpgradeOptions) (*UpgradeResult, error) {
+	lg, _ := logger.Get()
+
+	deps := &defaultsetup.Dependencies{
+		PackageManager: system.NewPackageManagerContext(ctx),
+		ProcessManager: system.NewProcessManager(),
+		ServiceManager: system.NewServiceManager(),
+	}
+
+	// Langkah 1: Pre-upgrade checks
+	terminal.Headers(i18n.T("mariadb.upgrade.precheck_title"))
+	installation, err := preUpgradeChecks(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("pre-upgrade checks gagal: %w", err)
+	}
+	result := &UpgradeResult{PreviousVersion: installation.Version}
+
+	// Langkah 2: Snapshot paket, konfigurasi dan repository
+	terminal.Headers(i18n.T("mariadb.upgrade.title"))
+	terminal.PrintSubHeader(i18n.T("mariadb.upgrade.snapshot"))
+	spinner := terminal.NewInstallSpinner(i18n.T("mariadb.upgrade.taking_snapshot"))
+	spinner.Start()
+	snap, err := takeUpgradeSnapshot(deps, installation, cfg.Version)
+	if err != nil {
+		spinner.StopWithError(i18n.T("mariadb.upgrade.snapshot_failed"))
+		return nil, fmt.Errorf("gagal membuat snapshot sebelum upgrade: %w", err)
+	}
+	spinner.StopWithSuccess(i18n.T("mariadb.upgrade.snapshot_saved", snap.Dir))
+	result.SnapshotDir = snap.Dir
+
+	// Langkah 3-5: Repository, paket dan pemeriksaan setelah upgrade
+	version, err := upgradePackages(ctx, cfg, snap, deps)
+	if err == nil {
+		result.Version = version
+		lg.Info("Upgrade MariaDB berhasil diselesaikan",
+			logger.String("previous_version", result.PreviousVersion),
+			logger.String("version", version))
+		return result, nil
+	}
+
+	lg.Error("Upgrade MariaDB gagal", logger.Error(err), logger.String("snapshot", snap.Dir))
+	if !opts.Rollback {
+		return result, fmt.Errorf("upgrade gagal (snapshot untuk rollback: %s): %w", snap.Dir, err)
+	}
+	if rbErr := RollbackMariaDBUpgrade(snap); rbErr != nil {
+		result.RollbackError = rbErr.Error()
+		return result, fmt.Errorf("upgrade gagal: %w; rollback juga gagal: %v", err, rbErr)
+	}
+	result.RolledBack = true
+	result.Version = snap.Version
+	return result, fmt.Errorf("upgrade gagal, versi %s sudah dikembalikan: %w", snap.Version, err)
+}
+
+// upgradePackages memasang repository versi tujuan, mengupgrade paket MariaDB
+// dari snapshot dan memeriksa server setelahnya. Mengembalikan versi yang berjalan.
+func upgradePackages(ctx context.Context, cfg *mariadb_config.MariaDBInstallConfig, snap *UpgradeSnapshot, deps *defaultsetup.Dependencies) (string, error) {
+	if err := setupMariaDBRepository(ctx, cfg, deps); err != nil {
+		return "", fmt.Errorf("setup repository gagal: %w", err)
+	}
+	if err := updatePackageCache(deps); err != nil {
+		return "", fmt.Errorf("update package cache gagal: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("upgrade dibatalkan: %w", err)
+	}
+
+	packages := make([]string, 0, len(snap.Packages))
+	for pkg := range snap.Packages {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+
+	terminal.PrintSubHeader(i18n.T("mariadb.upgrade.upgrade_packages"))
+	spinner := terminal.NewInstallSpinner(i18n.T("mariadb.upgrade.upgrading_packages"))
+	spinner.Start()
+	if err := deps.PackageManager.UpgradePackages(packages); err != nil {
+		spinner.StopWithError(i18n.T("mariadb.upgrade.upgrade_failed"))
+		return "", fmt.Errorf("gagal mengupgrade paket MariaDB: %w", err)
+	}
+	spinner.StopWithSuccess(i18n.T("mariadb.upgrade.packages_upgraded"))
+
+	terminal.PrintSubHeader(i18n.T("mariadb.upgrade.postcheck"))
+	spinner = terminal.NewInstallSpinner(i18n.T("mariadb.upgrade.checking"))
+	spinner.Start()
+	version, err := postUpgradeChecks(cfg, snap, deps)
+	if err != nil {
+		spinner.StopWithError(i18n.T("mariadb.upgrade.check_failed"))
+		return "", err
+	}
+	spinner.StopWithSuccess(i18n.T("mariadb.upgrade.check_ok", version))
+	return version, nil
+}
+
+// preUpgradeChecks memastikan MariaDB terinstall dengan versi yang diketahui
+// dan versi tujuan tidak lebih rendah dari versi tersebut
+func preUpgradeChecks(cfg *mariadb_config.MariaDBInstallConfig) (*discovery.MariaDBInstallation, error) {
+	lg, _ := logger.Get()
+
+	if err := system.ValidateOperatingSystem(); err != nil {
+		return nil, fmt.Errorf("sistem operasi tidak didukung: %w", err)
+	}
+	if !isRunningAsRoot() {
+		return nil, fmt.Errorf("upgrade MariaDB memerlukan hak akses root. Jalankan dengan sudo")
+	}
+
+	installation, err := discovery.DiscoverMariaDBInstallation()
+	if err != nil || installation == nil || !installation.IsInstalled {
+		return nil, fmt.Errorf("MariaDB belum terinstall. Gunakan 'mariadb install'")
+	}
+	if installation.Version == "" {
+		return nil, fmt.Errorf("versi MariaDB yang terinstall tidak dapat dideteksi")
+	}
+	lg.Debug("MariaDB terinstall",
+		logger.String("installed_version", installation.Version),
+		logger.String("requested_version", cfg.Version))
+
+	if installation.Version == cfg.Version {
+		return nil, fmt.Errorf("MariaDB %s sudah terinstall", installation.Version)
+	}
+	if versions.Compare(versions.Series(cfg.Version), versions.Series(installation.Version)) < 0 {
+		return nil, fmt.Errorf("versi tujuan %s lebih rendah dari versi terinstall %s; downgrade hanya didukung melalui rollback snapshot", cfg.Version, installation.Version)
+	}
+
+	// Cek direktori temporary untuk unduhan script repository
+	if err := tempdir.Check("mariadb upgrade", 0); err != nil {
+		return nil, err
+	}
+	return installation, nil
+}
+
+// postUpgradeChecks me-restart service, menunggu server siap dan memastikan
+// versi yang berjalan berasal dari seri versi tujuan
+func postUpgradeChecks(cfg *mariadb_config.MariaDBInstallConfig, snap *UpgradeSnapshot, deps *defaultsetup.Dependencies) (string, error) {
+	lg, _ := logger.Get()
+
+	// Paket tidak selalu me-restart service; pastikan binary baru yang berjalan
+	if err := deps.ServiceManager.Restart(snap.ServiceName); err != nil {
+		return "", fmt.Errorf("gagal me-restart service %s: %w", snap.ServiceName, err)
+	}
+	if err := waitForServer(deps, snap.ServiceName, serverReadyTimeout); err != nil {
+		return "", err
+	}
+
+	version := versions.Installed(deps.ProcessManager.ExecuteWithOutput)
+	if version == "" {
+		return "", fmt.Errorf("tidak dapat mendeteksi versi MariaDB setelah upgrade")
+	}
+	if want := versions.Series(cfg.Version); versions.Series(version) != want {
+		return version, fmt.Errorf("versi yang terinstall %s bukan seri %s yang diminta", version, want)
+	}
+
+	lg.Info("Pemeriksaan setelah upgrade berhasil",
+		logger.String("previous_version", snap.Version),
+		logger.String("version", version))
+	return version, nil
+}
+
+// waitForServer menunggu service aktif dan server menjawab ping. mariadb-admin
+// ping berhasil selama server berjalan, juga ketika login root ditolak.
+func waitForServer(deps *defaultsetup.Dependencies, serviceName string, timeout time.Duration) error {
+	admin := "mariadb-admin"
+	if _, err := deps.ProcessManager.ExecuteWithOutput("which", []string{admin}); err != nil {
+		admin = "mysqladmin"
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if deps.ServiceManager.IsActive(serviceName) {
+			if _, err := deps.ProcessManager.ExecuteWithOutput(admin, []string{"ping"}); err == nil {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("server MariaDB tidak siap dalam %s", timeout)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
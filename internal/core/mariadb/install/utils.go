@@ -4,7 +4,8 @@ package install
 // File ini mengikuti prinsip DRY (Don't Repeat Yourself) dan single responsibility
 
 // File ini sengaja kosong karena helper functions sudah dipindahkan ke file-file yang relevan:
-// - isMariaDBInstalled, getInstalledMariaDBVersion, isRunningAsRoot -> precheck.go
+// - isMariaDBInstalled, isRunningAsRoot -> precheck.go
+// - Parsing dan perbandingan versi -> utils/mariadb/versions
 // - Helper untuk repo setup -> repo_setup.go
 // - Helper untuk package management -> package_install.go
 // - Helper untuk service management -> service.go
@@ -0,0 +1,13 @@
+package keys
+
+import (
+	"os"
+	"syscall"
+)
+
+// chownLike gives path the same owner and group as the reference file, so mysqld can still read it
+func chownLike(path string, ref os.FileInfo) {
+	if st, ok := ref.Sys().(*syscall.Stat_t); ok {
+		_ = os.Chown(path, int(st.Uid), int(st.Gid))
+	}
+}
@@ -0,0 +1,122 @@
+package keys
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyFile is the parsed content of a file_key_management key file ("<id>;<hex key>" per line)
+type KeyFile struct {
+	Path string
+	Keys map[int]string
+}
+
+// LoadKeyFile reads and parses a plain-text file_key_management key file
+func LoadKeyFile(path string) (*KeyFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open key file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	kf := &KeyFile{Path: path, Keys: make(map[int]string)}
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ";", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("key file %s line %d is not in '<id>;<hex key>' format (encrypted key files are not supported)", path, lineNo)
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("key file %s line %d has an invalid key id %q", path, lineNo, parts[0])
+		}
+		if _, dup := kf.Keys[id]; dup {
+			return nil, fmt.Errorf("key file %s has duplicate key id %d", path, id)
+		}
+		kf.Keys[id] = strings.TrimSpace(parts[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+	if len(kf.Keys) == 0 {
+		return nil, fmt.Errorf("key file %s contains no keys", path)
+	}
+	return kf, nil
+}
+
+// IDs returns the key ids in ascending order
+func (kf *KeyFile) IDs() []int {
+	ids := make([]int, 0, len(kf.Keys))
+	for id := range kf.Keys {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// AddKey appends a new random 256-bit key and returns its id
+func (kf *KeyFile) AddKey() (int, error) {
+	ids := kf.IDs()
+	next := ids[len(ids)-1] + 1
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return 0, fmt.Errorf("failed to generate key: %w", err)
+	}
+	kf.Keys[next] = hex.EncodeToString(buf)
+	return next, nil
+}
+
+// Remove drops the given key ids from the file content
+func (kf *KeyFile) Remove(ids []int) {
+	for _, id := range ids {
+		delete(kf.Keys, id)
+	}
+}
+
+// Save backs up the current file next to it and writes the new content with 0600 permissions,
+// preserving the original owner. It returns the backup path.
+func (kf *KeyFile) Save() (string, error) {
+	info, err := os.Stat(kf.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat key file: %w", err)
+	}
+
+	backupPath := fmt.Sprintf("%s.bak-%s", kf.Path, time.Now().Format("20060102-150405"))
+	original, err := os.ReadFile(kf.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key file for backup: %w", err)
+	}
+	if err := os.WriteFile(backupPath, original, 0600); err != nil {
+		return "", fmt.Errorf("failed to back up key file: %w", err)
+	}
+
+	var b strings.Builder
+	for _, id := range kf.IDs() {
+		fmt.Fprintf(&b, "%d;%s\n", id, kf.Keys[id])
+	}
+
+	tmp := kf.Path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0600); err != nil {
+		return backupPath, fmt.Errorf("failed to write key file: %w", err)
+	}
+	chownLike(tmp, info)
+	chownLike(backupPath, info)
+	if err := os.Rename(tmp, kf.Path); err != nil {
+		return backupPath, fmt.Errorf("failed to replace key file: %w", err)
+	}
+	return backupPath, nil
+}
@@ -0,0 +1,258 @@
+package keys
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/mariadb/discovery"
+	"sfDBTools/utils/system"
+	"sfDBTools/utils/terminal"
+)
+
+// RotateOptions controls a key rotation run
+type RotateOptions struct {
+	DB          database.Config
+	KeyFile     string // defaults to @@file_key_management_filename
+	ConfigFile  string // server config holding the encryption settings; discovered when empty
+	ServiceName string // discovered when empty
+	SkipRestart bool   // only append the key and update config; re-encryption happens on a later run
+	RetireOld   bool   // remove keys no tablespace uses after re-encryption
+}
+
+// RotateResult reports what the rotation did
+type RotateResult struct {
+	OldKeyID      int
+	NewKeyID      int
+	KeyFileBackup string
+	ConfigFile    string
+	Reencrypted   []string
+	Failed        map[string]error
+	Skipped       []string
+	Retired       []int
+	Pending       bool // true when re-encryption was deferred because the restart was skipped
+}
+
+// Rotate appends a new key to the key file, makes it the default for InnoDB, re-encrypts user
+// tables with it and optionally retires keys that are no longer referenced.
+func Rotate(opts RotateOptions) (*RotateResult, error) {
+	lg, _ := logger.Get()
+	result := &RotateResult{Failed: make(map[string]error)}
+
+	db, err := database.GetWithoutDB(opts.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MariaDB: %w", err)
+	}
+	defer func() { db.Close() }()
+
+	if opts.KeyFile == "" {
+		if err := db.QueryRow("SELECT @@GLOBAL.file_key_management_filename").Scan(&opts.KeyFile); err != nil {
+			return nil, fmt.Errorf("file_key_management plugin is not active: %w", err)
+		}
+	}
+	if err := db.QueryRow("SELECT @@GLOBAL.innodb_default_encryption_key_id").Scan(&result.OldKeyID); err != nil {
+		return nil, fmt.Errorf("failed to read innodb_default_encryption_key_id: %w", err)
+	}
+
+	installation, _ := discovery.DiscoverMariaDBInstallation()
+	if opts.ConfigFile == "" {
+		opts.ConfigFile = findEncryptionConfigFile(installation)
+	}
+	if opts.ConfigFile == "" {
+		return nil, fmt.Errorf("could not find the server config file containing file_key_management_filename; pass it with --config-file")
+	}
+	result.ConfigFile = opts.ConfigFile
+
+	kf, err := LoadKeyFile(opts.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if result.NewKeyID, err = kf.AddKey(); err != nil {
+		return nil, err
+	}
+	if result.KeyFileBackup, err = kf.Save(); err != nil {
+		return nil, err
+	}
+	lg.Info("New encryption key appended",
+		logger.String("key_file", opts.KeyFile),
+		logger.Int("key_id", result.NewKeyID),
+		logger.String("backup", result.KeyFileBackup))
+
+	if err := setConfigOption(opts.ConfigFile, "innodb_default_encryption_key_id", fmt.Sprintf("%d", result.NewKeyID)); err != nil {
+		return result, err
+	}
+	lg.Info("Default encryption key id updated", logger.String("config_file", opts.ConfigFile), logger.Int("key_id", result.NewKeyID))
+
+	if opts.SkipRestart {
+		result.Pending = true
+		return result, nil
+	}
+
+	// file_key_management only reads the key file at startup
+	serviceName := opts.ServiceName
+	if serviceName == "" && installation != nil {
+		serviceName = installation.ServiceName
+	}
+	if serviceName == "" {
+		serviceName = "mariadb"
+	}
+	db.Close()
+	terminal.PrintInfo(fmt.Sprintf("Restarting %s to load the new key...", serviceName))
+	if err := system.NewServiceManager().Restart(serviceName); err != nil {
+		return result, fmt.Errorf("failed to restart %s: %w", serviceName, err)
+	}
+	if db, err = waitForServer(opts.DB, 2*time.Minute); err != nil {
+		return result, err
+	}
+
+	var active int
+	if err := db.QueryRow("SELECT @@GLOBAL.innodb_default_encryption_key_id").Scan(&active); err != nil || active != result.NewKeyID {
+		return result, fmt.Errorf("server did not pick up key id %d after restart (active: %d)", result.NewKeyID, active)
+	}
+
+	if err := reencryptTables(db, result); err != nil {
+		return result, err
+	}
+
+	if opts.RetireOld {
+		if len(result.Failed) > 0 {
+			lg.Warn("Skipping key retirement because some tables failed to re-encrypt")
+			return result, nil
+		}
+		tablespaces, err := ListTablespaces(db)
+		if err != nil {
+			return result, err
+		}
+		retirable := RetirableKeys(kf, tablespaces, result.NewKeyID)
+		if len(retirable) > 0 {
+			kf.Remove(retirable)
+			if _, err := kf.Save(); err != nil {
+				return result, err
+			}
+			result.Retired = retirable
+			lg.Info("Retired unused encryption keys", logger.Strings("key_ids", intsToStrings(retirable)))
+		}
+	}
+
+	return result, nil
+}
+
+// reencryptTables rebuilds every user table that is not yet encrypted with the new key
+func reencryptTables(db *sql.DB, result *RotateResult) error {
+	lg, _ := logger.Get()
+
+	tablespaces, err := ListTablespaces(db)
+	if err != nil {
+		return err
+	}
+	tables, skipped := tablesUsingOtherKeys(tablespaces, result.NewKeyID)
+	result.Skipped = skipped
+	if len(tables) == 0 {
+		return nil
+	}
+
+	bar := progressbar.NewOptions(len(tables),
+		progressbar.OptionSetDescription("Re-encrypting tables"),
+		progressbar.OptionSetWidth(40),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetElapsedTime(true),
+	)
+	for _, ts := range tables {
+		name := ts.Schema + "." + ts.Table
+		bar.Describe(fmt.Sprintf("Re-encrypting %s", name))
+		query := fmt.Sprintf("ALTER TABLE %s.%s ENCRYPTION_KEY_ID=%d", database.QuoteIdent(ts.Schema), database.QuoteIdent(ts.Table), result.NewKeyID)
+		if _, err := db.Exec(query); err != nil {
+			result.Failed[name] = err
+			lg.Error("Failed to re-encrypt table", logger.String("table", name), logger.Error(err))
+		} else {
+			result.Reencrypted = append(result.Reencrypted, name)
+		}
+		_ = bar.Add(1)
+	}
+	_ = bar.Finish()
+	fmt.Println()
+	return nil
+}
+
+// waitForServer reconnects until the server answers or the timeout expires
+func waitForServer(cfg database.Config, timeout time.Duration) (*sql.DB, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		db, err := database.GetWithoutDB(cfg)
+		if err == nil {
+			if err = db.Ping(); err == nil {
+				return db, nil
+			}
+			db.Close()
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("server did not come back within %s: %w", timeout, err)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// findEncryptionConfigFile returns the first discovered config file that configures file_key_management
+func findEncryptionConfigFile(installation *discovery.MariaDBInstallation) string {
+	if installation == nil {
+		return ""
+	}
+	for _, path := range installation.ConfigPaths {
+		data, err := os.ReadFile(path)
+		if err == nil && strings.Contains(string(data), "file_key_management_filename") {
+			return path
+		}
+	}
+	return ""
+}
+
+// setConfigOption sets key=value in an option file, replacing an existing line or adding it
+// right after file_key_management_filename so it lands in the same section
+func setConfigOption(path, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	content := string(data)
+	line := fmt.Sprintf("%s = %s", key, value)
+
+	existing := regexp.MustCompile(`(?m)^\s*` + regexp.QuoteMeta(key) + `\s*=.*$`)
+	anchor := regexp.MustCompile(`(?m)^\s*file_key_management_filename\s*=.*$`)
+	switch {
+	case existing.MatchString(content):
+		content = existing.ReplaceAllLiteralString(content, line)
+	case anchor.MatchString(content):
+		loc := anchor.FindStringIndex(content)
+		content = content[:loc[1]] + "\n" + line + content[loc[1]:]
+	default:
+		return fmt.Errorf("%s does not contain file_key_management_filename", path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	backupPath := fmt.Sprintf("%s.bak-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backupPath, data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func intsToStrings(ids []int) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = fmt.Sprintf("%d", id)
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+package keys
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// systemSchemas are never re-encrypted by rotate; their key ids are therefore never retired
+var systemSchemas = map[string]bool{
+	"mysql":              true,
+	"information_schema": true,
+	"performance_schema": true,
+	"sys":                true,
+}
+
+// Tablespace is one row of INFORMATION_SCHEMA.INNODB_TABLESPACES_ENCRYPTION
+type Tablespace struct {
+	Name        string // "db/table" as reported by InnoDB
+	Schema      string
+	Table       string
+	KeyID       int
+	Rotating    bool
+	PagesDone   int64
+	PagesTotal  int64
+	UserTable   bool // false for the system tablespace, undo logs and system schemas
+	EncodedName bool // true when the name uses InnoDB's @xxxx filename encoding
+}
+
+// KeyUsage summarizes how many tablespaces are encrypted with each key id
+type KeyUsage struct {
+	KeyID       int
+	Tablespaces int
+	Rotating    int
+}
+
+// ListTablespaces returns encryption state for every encrypted InnoDB tablespace
+func ListTablespaces(db *sql.DB) ([]Tablespace, error) {
+	rows, err := db.Query(`SELECT NAME, CURRENT_KEY_ID, ROTATING_OR_FLUSHING,
+		COALESCE(KEY_ROTATION_PAGE_NUMBER, 0), COALESCE(KEY_ROTATION_MAX_PAGE_NUMBER, 0)
+		FROM information_schema.INNODB_TABLESPACES_ENCRYPTION
+		WHERE ENCRYPTION_SCHEME <> 0`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tablespace encryption status: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Tablespace
+	for rows.Next() {
+		var ts Tablespace
+		var rotating int
+		if err := rows.Scan(&ts.Name, &ts.KeyID, &rotating, &ts.PagesDone, &ts.PagesTotal); err != nil {
+			return nil, err
+		}
+		ts.Rotating = rotating != 0
+		if schema, table, ok := strings.Cut(ts.Name, "/"); ok {
+			// Partitions are reported as table#P#part; the ALTER applies to the whole table
+			if i := strings.Index(table, "#"); i >= 0 {
+				table = table[:i]
+			}
+			ts.Schema, ts.Table = schema, table
+			ts.UserTable = !systemSchemas[schema]
+			ts.EncodedName = strings.Contains(ts.Name, "@")
+		}
+		result = append(result, ts)
+	}
+	return result, rows.Err()
+}
+
+// SummarizeUsage groups tablespaces by key id
+func SummarizeUsage(tablespaces []Tablespace) []KeyUsage {
+	byKey := make(map[int]*KeyUsage)
+	for _, ts := range tablespaces {
+		u, ok := byKey[ts.KeyID]
+		if !ok {
+			u = &KeyUsage{KeyID: ts.KeyID}
+			byKey[ts.KeyID] = u
+		}
+		u.Tablespaces++
+		if ts.Rotating {
+			u.Rotating++
+		}
+	}
+	result := make([]KeyUsage, 0, len(byKey))
+	for _, u := range byKey {
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].KeyID < result[j].KeyID })
+	return result
+}
+
+// tablesUsingOtherKeys returns the distinct user tables not yet encrypted with keyID
+func tablesUsingOtherKeys(tablespaces []Tablespace, keyID int) (tables []Tablespace, skipped []string) {
+	seen := make(map[string]bool)
+	for _, ts := range tablespaces {
+		if !ts.UserTable || ts.KeyID == keyID {
+			continue
+		}
+		if ts.EncodedName {
+			skipped = append(skipped, ts.Name)
+			continue
+		}
+		key := ts.Schema + "." + ts.Table
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		tables = append(tables, ts)
+	}
+	return tables, skipped
+}
+
+// RetirableKeys returns key ids from the key file that no tablespace uses anymore.
+// Key 1 is always kept because InnoDB uses it for the system tablespace, redo log and temporary
+// tables, and the current default key is kept as well.
+func RetirableKeys(kf *KeyFile, tablespaces []Tablespace, defaultKeyID int) []int {
+	inUse := map[int]bool{1: true, defaultKeyID: true}
+	for _, ts := range tablespaces {
+		inUse[ts.KeyID] = true
+	}
+	var retirable []int
+	for _, id := range kf.IDs() {
+		if !inUse[id] {
+			retirable = append(retirable, id)
+		}
+	}
+	return retirable
+}
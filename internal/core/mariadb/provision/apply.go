@@ -0,0 +1,222 @@
+package provision
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/shutdown"
+)
+
+// Runner executes provisioning statements on a server. ExecAll runs the
+// whole plan in one session, stopping at the first failure, so a password
+// change of the account the runner logs in with cannot lock out the
+// statements after it.
+type Runner interface {
+	ExecAll(statements []Statement) error
+	AccountExists(user, host string) (bool, error)
+}
+
+// NewDBRunner runs statements over an existing connection, cancelled with ctx
+func NewDBRunner(ctx context.Context, db *sql.DB) Runner {
+	return dbRunner{ctx: ctx, db: db}
+}
+
+// NewClientRunner runs statements with the local mysql client as the OS user,
+// which right after an install is the only way in (root over the socket). The
+// client is killed when ctx is cancelled.
+func NewClientRunner(ctx context.Context) Runner {
+	return clientRunner{ctx: ctx}
+}
+
+// Statement is a statement with its display form, in which passwords are masked
+type Statement struct {
+	SQL     string
+	Display string
+}
+
+// Result reports what Apply did
+type Result struct {
+	Statements []Statement
+	Created    []string // users created
+	Reset      []string // existing users whose password was set again
+	Skipped    []string // existing users left with their password
+}
+
+// Plan returns the statements that bring the server in line with t. It fails
+// before anything changes when a user that does not exist has no password.
+func Plan(t *Template, r Runner) (*Result, error) {
+	result := &Result{}
+	add := func(sql, display string) {
+		result.Statements = append(result.Statements, Statement{SQL: sql, Display: display})
+	}
+
+	for _, db := range t.Databases {
+		stmt := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s CHARACTER SET %s COLLATE %s", database.QuoteIdent(db.Name), db.Charset, db.Collation)
+		add(stmt, stmt)
+	}
+	for _, name := range t.DropDatabases {
+		stmt := "DROP DATABASE IF EXISTS " + database.QuoteIdent(name)
+		add(stmt, stmt)
+	}
+	if t.DropAnonymousUsers {
+		stmt := "DELETE FROM mysql.user WHERE User = ''"
+		add(stmt, stmt)
+	}
+
+	var missing []string
+	for _, u := range t.Users {
+		exists, err := r.AccountExists(u.User, u.Host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check user %s: %w", u.Name(), err)
+		}
+		account := database.QuoteString(u.User) + "@" + database.QuoteString(u.Host)
+		switch {
+		case !exists && u.Password == "":
+			missing = append(missing, u.Name())
+			continue
+		case !exists:
+			add(fmt.Sprintf("CREATE USER IF NOT EXISTS %s IDENTIFIED BY %s", account, database.QuoteString(u.Password)),
+				fmt.Sprintf("CREATE USER IF NOT EXISTS %s IDENTIFIED BY '***'", account))
+			result.Created = append(result.Created, u.Name())
+		case u.ResetPassword && u.Password != "":
+			add(fmt.Sprintf("ALTER USER %s IDENTIFIED BY %s", account, database.QuoteString(u.Password)),
+				fmt.Sprintf("ALTER USER %s IDENTIFIED BY '***'", account))
+			result.Reset = append(result.Reset, u.Name())
+		default:
+			result.Skipped = append(result.Skipped, u.Name())
+		}
+		for _, g := range u.Grants {
+			stmt := fmt.Sprintf("GRANT %s ON %s TO %s", strings.ToUpper(g.Privileges), grantTarget(g.On), account)
+			if g.GrantOption {
+				stmt += " WITH GRANT OPTION"
+			}
+			add(stmt, stmt)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no password given for new user(s) %s; set them in the template, e.g. through the environment variables it reads",
+			strings.Join(missing, ", "))
+	}
+	add("FLUSH PRIVILEGES", "FLUSH PRIVILEGES")
+	return result, nil
+}
+
+// Apply plans t and runs the statements, stopping at the first failure
+func Apply(t *Template, r Runner) (*Result, error) {
+	lg, _ := logger.Get()
+	result, err := Plan(t, r)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.ExecAll(result.Statements); err != nil {
+		return result, err
+	}
+	for _, stmt := range result.Statements {
+		lg.Debug("Provisioning statement applied", logger.String("statement", stmt.Display))
+	}
+	lg.Info("Provisioning applied",
+		logger.Int("databases", len(t.Databases)),
+		logger.Strings("created", result.Created),
+		logger.Strings("password_reset", result.Reset))
+	return result, nil
+}
+
+type dbRunner struct {
+	ctx context.Context
+	db  *sql.DB
+}
+
+func (r dbRunner) ExecAll(statements []Statement) error {
+	conn, err := r.db.Conn(r.ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for _, stmt := range statements {
+		if _, err := conn.ExecContext(r.ctx, stmt.SQL); err != nil {
+			return fmt.Errorf("failed to run %s: %w", stmt.Display, err)
+		}
+	}
+	return nil
+}
+
+func (r dbRunner) AccountExists(user, host string) (bool, error) {
+	var n int
+	err := r.db.QueryRowContext(r.ctx, "SELECT COUNT(*) FROM mysql.user WHERE User = ? AND Host = ?", user, host).Scan(&n)
+	return n > 0, err
+}
+
+type clientRunner struct {
+	ctx context.Context
+}
+
+// failedLine finds the script line in a mysql client error such as
+// "ERROR 1396 (HY000) at line 12: Operation CREATE USER failed"
+var failedLine = regexp.MustCompile(`at line (\d+)`)
+
+// ExecAll feeds the statements to one mysql process on stdin, one per line,
+// so they share a session and passwords never appear on a command line
+func (r clientRunner) ExecAll(statements []Statement) error {
+	start := time.Now()
+	var script strings.Builder
+	for _, stmt := range statements {
+		script.WriteString(stmt.SQL)
+		script.WriteString(";\n")
+	}
+	_, err := r.run(script.String())
+	// The script holds passwords, so only its size is traced
+	database.TraceStatement("mysql-client(local)", fmt.Sprintf("-- %d provisioning statements", len(statements)), time.Since(start), -1, err)
+	if err != nil {
+		if m := failedLine.FindStringSubmatch(err.Error()); m != nil {
+			if n, _ := strconv.Atoi(m[1]); n >= 1 && n <= len(statements) {
+				return fmt.Errorf("failed to run %s: %w", statements[n-1].Display, err)
+			}
+		}
+		return fmt.Errorf("failed to run provisioning statements: %w", err)
+	}
+	return nil
+}
+
+func (r clientRunner) AccountExists(user, host string) (bool, error) {
+	start := time.Now()
+	stmt := fmt.Sprintf("SELECT COUNT(*) FROM mysql.user WHERE User = %s AND Host = %s", database.QuoteString(user), database.QuoteString(host))
+	out, err := r.run(stmt + ";\n")
+	database.TraceStatement("mysql-client(local)", stmt, time.Since(start), -1, err)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "0", nil
+}
+
+// run executes script with the local mysql client, reading it from stdin
+func (r clientRunner) run(script string) (string, error) {
+	cmd := shutdown.Command(r.ctx, "mysql", "-N", "-B")
+	cmd.Stdin = strings.NewReader(script)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), err
+}
+
+// grantTarget quotes the database and table of db.table, leaving * as is
+func grantTarget(on string) string {
+	db, table, _ := strings.Cut(on, ".")
+	if db != "*" {
+		db = database.QuoteIdent(db)
+	}
+	if table != "*" {
+		table = database.QuoteIdent(table)
+	}
+	return db + "." + table
+}
@@ -0,0 +1,197 @@
+// Package provision applies a template of databases, users and grants to a
+// server. Applying a template is idempotent: objects are only created when
+// missing, so the same template can be applied after every install or
+// configuration change.
+package provision
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormatVersion is the version of the provisioning template format
+const FormatVersion = 1
+
+// Template describes what a server should contain
+type Template struct {
+	Version            int        `yaml:"version"`
+	Databases          []Database `yaml:"databases"`
+	DropDatabases      []string   `yaml:"drop_databases"`
+	DropAnonymousUsers bool       `yaml:"drop_anonymous_users"`
+	Users              []User     `yaml:"users"`
+}
+
+// Database is created with IF NOT EXISTS; an existing database keeps its
+// character set
+type Database struct {
+	Name      string `yaml:"name"`
+	Charset   string `yaml:"charset"`
+	Collation string `yaml:"collation"`
+}
+
+// User is an account and its grants. Password is required when the account
+// does not exist yet; ResetPassword also sets it on an existing account.
+type User struct {
+	User          string  `yaml:"user"`
+	Host          string  `yaml:"host"`
+	Password      string  `yaml:"password"`
+	ResetPassword bool    `yaml:"reset_password"`
+	Grants        []Grant `yaml:"grants"`
+}
+
+// Grant is one GRANT statement: Privileges ON On
+type Grant struct {
+	Privileges  string `yaml:"privileges"`
+	On          string `yaml:"on"`
+	GrantOption bool   `yaml:"grant_option"`
+}
+
+// Name returns user@host for display
+func (u User) Name() string {
+	return u.User + "@" + u.Host
+}
+
+// WithoutPassword returns the users the template gives no password
+func (t *Template) WithoutPassword() []User {
+	var users []User
+	for _, u := range t.Users {
+		if u.Password == "" {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+// Values are the variables available to a template
+type Values struct {
+	ClientCode string
+}
+
+var (
+	validPrivileges = regexp.MustCompile(`^[A-Za-z][A-Za-z _,]*$`)
+	validObject     = regexp.MustCompile("^(\\*|[A-Za-z0-9_$]+)\\.(\\*|[A-Za-z0-9_$]+)$")
+	validName       = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+)
+
+// Load reads the template at path and renders its values. Rendering happens
+// per value after parsing, so a password containing quotes or other YAML
+// syntax cannot change the structure of the template.
+func Load(path string, values Values) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provisioning template %s: %w", path, err)
+	}
+	var t Template
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse provisioning template %s: %w", path, err)
+	}
+	if t.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported provisioning template version %d in %s (expected %d)", t.Version, path, FormatVersion)
+	}
+	if err := t.render(values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &t, nil
+}
+
+// Validate checks the names and grants of a rendered template
+func (t *Template) Validate() error {
+	for i := range t.Databases {
+		db := &t.Databases[i]
+		if db.Name == "" {
+			return fmt.Errorf("database %d has no name", i+1)
+		}
+		if db.Charset == "" {
+			db.Charset = "utf8mb4"
+		}
+		if db.Collation == "" {
+			db.Collation = "utf8mb4_general_ci"
+		}
+		if !validName.MatchString(db.Charset) || !validName.MatchString(db.Collation) {
+			return fmt.Errorf("database %s: invalid charset or collation", db.Name)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, u := range t.Users {
+		if u.User == "" || u.Host == "" {
+			return fmt.Errorf("user %q: user and host are required", u.Name())
+		}
+		if seen[u.Name()] {
+			return fmt.Errorf("user %s is listed twice", u.Name())
+		}
+		seen[u.Name()] = true
+		for _, g := range u.Grants {
+			if !validPrivileges.MatchString(g.Privileges) {
+				return fmt.Errorf("user %s: invalid privileges %q", u.Name(), g.Privileges)
+			}
+			if !validObject.MatchString(g.On) {
+				return fmt.Errorf("user %s: invalid grant target %q (expected db.table, db.* or *.*)", u.Name(), g.On)
+			}
+		}
+	}
+	return nil
+}
+
+// render expands the template expressions of every value
+func (t *Template) render(values Values) error {
+	r := renderer{values: values, funcs: template.FuncMap{
+		"env": os.Getenv,
+		"file": func(path string) (string, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimRight(string(data), "\r\n"), nil
+		},
+	}}
+	for i := range t.Databases {
+		r.expand(&t.Databases[i].Name)
+	}
+	for i := range t.DropDatabases {
+		r.expand(&t.DropDatabases[i])
+	}
+	for i := range t.Users {
+		u := &t.Users[i]
+		r.expand(&u.User)
+		r.expand(&u.Host)
+		r.expand(&u.Password)
+		// Anchored grant lists are shared between users, so copy before expanding
+		u.Grants = append([]Grant(nil), u.Grants...)
+		for j := range u.Grants {
+			r.expand(&u.Grants[j].On)
+		}
+	}
+	return r.err
+}
+
+type renderer struct {
+	values Values
+	funcs  template.FuncMap
+	err    error
+}
+
+func (r *renderer) expand(s *string) {
+	if r.err != nil || !strings.Contains(*s, "{{") {
+		return
+	}
+	tmpl, err := template.New("value").Option("missingkey=error").Funcs(r.funcs).Parse(*s)
+	if err != nil {
+		r.err = fmt.Errorf("invalid template expression %q: %w", *s, err)
+		return
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r.values); err != nil {
+		r.err = fmt.Errorf("failed to render %q: %w", *s, err)
+		return
+	}
+	*s = buf.String()
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	mariadb_config "sfDBTools/utils/mariadb/config"
 	"sfDBTools/utils/terminal"
 )
@@ -209,7 +210,7 @@ func removeDefaultDataDirectories() error {
 
 // removeCustomDataDirectories menghapus direktori berdasarkan konfigurasi yang terdeteksi
 func removeCustomDataDirectories(config *MariaDBConfig) error {
-	terminal.PrintSubHeader("Menghapus data directory MariaDB (custom)...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.removing_datadir"))
 
 	// Dapatkan semua direktori yang perlu dihapus
 	customDirs := getAllCustomDirectories(config)
@@ -257,7 +258,7 @@ func removeCustomDataDirectories(config *MariaDBConfig) error {
 
 // removeConfigFiles menghapus file-file konfigurasi MariaDB
 func removeConfigFiles() error {
-	terminal.PrintSubHeader("Menghapus file konfigurasi MariaDB...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.removing_config"))
 
 	configPaths := []string{
 		"/etc/mysql",
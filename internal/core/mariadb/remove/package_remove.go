@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	"sfDBTools/utils/system"
 	"sfDBTools/utils/terminal"
 )
@@ -12,7 +13,7 @@ import (
 func removeMariaDBPackages(deps *Dependencies) error {
 	lg, _ := logger.Get()
 
-	terminal.PrintSubHeader("Menghapus paket MariaDB...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.removing_packages"))
 
 	// Tentukan nama paket berdasarkan OS
 	packages, err := getMariaDBPackageList()
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"time"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/i18n"
 	mariadb_config "sfDBTools/utils/mariadb/config"
+	"sfDBTools/utils/mariadb/versions"
 	"sfDBTools/utils/terminal"
 )
 
@@ -15,7 +18,7 @@ import (
 func preRemovalChecks(cfg *mariadb_config.MariaDBRemoveConfig, deps *Dependencies) error {
 	lg, _ := logger.Get()
 
-	terminal.PrintSubHeader("Melakukan pemeriksaan sistem untuk penghapusan...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.prechecking"))
 
 	// Cek hak akses root
 	if !isRunningAsRoot() {
@@ -28,7 +31,7 @@ func preRemovalChecks(cfg *mariadb_config.MariaDBRemoveConfig, deps *Dependencie
 	}
 
 	// Deteksi versi yang terinstall untuk informasi
-	installedVersion := getInstalledMariaDBVersion(deps)
+	installedVersion := versions.Installed(deps.ProcessManager.ExecuteWithOutput)
 	if installedVersion != "" {
 		listHeader("📋 MariaDB terdeteksi:")
 		infof("Versi: %s", installedVersion)
@@ -46,88 +49,123 @@ func preRemovalChecks(cfg *mariadb_config.MariaDBRemoveConfig, deps *Dependencie
 
 // confirmRemoval meminta konfirmasi user untuk penghapusan
 func confirmRemoval(cfg *mariadb_config.MariaDBRemoveConfig, deps *Dependencies) error {
-	// Skip konfirmasi jika force mode atau non-interactive
-	if cfg.Force || cfg.NonInteractive {
+	// Skip konfirmasi jika force mode, non-interactive atau --yes
+	if cfg.Force || cfg.NonInteractive || terminal.AssumeYes() {
 		return nil
 	}
 
-	// Show concise confirmation block
-	terminal.PrintSubHeader("⚠️  Konfirmasi Penghapusan ⚠️")
-	listHeader("Yang akan dihapus:")
-	info("✓ Paket MariaDB server dan client")
+	terminal.RenderPlanSummary(buildRemovalPlan(cfg, deps))
 
+	warn("PERHATIAN: Proses ini TIDAK DAPAT DIBATALKAN. Ketik 'HAPUS' untuk melanjutkan.")
+
+	fmt.Println()
+	response, err := terminal.Input("Konfirmasi", "", nil)
+	if err != nil {
+		return fmt.Errorf("gagal membaca konfirmasi: %w", err)
+	}
+
+	if response != "HAPUS" {
+		return fmt.Errorf("penghapusan dibatalkan oleh user")
+	}
+
+	return nil
+}
+
+// removalBaseDuration adalah perkiraan waktu stop service, hapus paket dan cleanup
+const removalBaseDuration = 3 * time.Minute
+
+// removalCopyThroughput adalah perkiraan kecepatan copy saat backup data directory
+const removalCopyThroughput = 100 << 20 // bytes per detik
+
+// buildRemovalPlan menyusun ringkasan rencana penghapusan dari konfigurasi remove
+func buildRemovalPlan(cfg *mariadb_config.MariaDBRemoveConfig, deps *Dependencies) terminal.OperationPlan {
+	plan := terminal.OperationPlan{
+		Operation: "MariaDB Removal",
+		Target:    "localhost",
+		Actions: []string{
+			"Stop dan disable service MariaDB",
+			"Hapus paket MariaDB server dan client",
+		},
+		Destructive:       []string{"Paket MariaDB server dan client"},
+		EstimatedDuration: removalBaseDuration,
+	}
+	if version := versions.Installed(deps.ProcessManager.ExecuteWithOutput); version != "" {
+		plan.Target = "localhost (MariaDB " + version + ")"
+	}
+
+	var dataDirs []string
 	if cfg.RemoveData {
-		info("✓ Data directory - SEMUA DATABASE AKAN HILANG!")
-
-		// Deteksi dan tampilkan direktori custom yang akan dihapus
-		if mariadbConfig := getDetectedConfig(deps); mariadbConfig != nil {
-			customDirs := getAllCustomDirectories(mariadbConfig)
-			if len(customDirs) > 0 {
-				info("📂 Direktori yang akan dihapus:")
-
-				// Default placeholder directories that we shouldn't show if they don't exist
-				defaults := map[string]bool{
-					"/var/lib/mysql": true,
-					"/var/log/mysql": true,
-				}
-
-				for _, dir := range customDirs {
-					// Normalize to absolute path for reliable comparisons
-					absDir := dir
-					if p, err := filepath.Abs(dir); err == nil {
-						absDir = p
-					}
-
-					// If this dir is a default placeholder and is absent, skip it to reduce noise
-					if defaults[absDir] {
-						if _, err := os.Stat(absDir); os.IsNotExist(err) {
-							// skip showing default placeholder that doesn't exist
-							continue
-						}
-					}
-
-					// Show directory; if missing, annotate
-					if _, err := os.Stat(absDir); err == nil {
-						info("- " + absDir)
-					} else {
-						info("- " + absDir + " (tidak ditemukan)")
-					}
-				}
-			}
-		} else {
-			info("📂 Direktori default: /var/lib/mysql")
+		dataDirs = removalDataDirectories(deps)
+		plan.Actions = append(plan.Actions, "Hapus data directory")
+		plan.Destructive = append(plan.Destructive, "Data directory - SEMUA DATABASE AKAN HILANG!")
+		for _, dir := range dataDirs {
+			plan.Destructive = append(plan.Destructive, "  - "+dir)
 		}
 	}
-
 	if cfg.RemoveConfig {
-		info("✓ File konfigurasi (/etc/mysql, /etc/my.cnf)")
+		plan.Actions = append(plan.Actions, "Hapus file konfigurasi")
+		plan.Destructive = append(plan.Destructive, "File konfigurasi (/etc/mysql, /etc/my.cnf)")
 	}
-
 	if cfg.RemoveRepository {
-		info("✓ Repository MariaDB")
+		plan.Actions = append(plan.Actions, "Hapus repository MariaDB")
+		plan.Destructive = append(plan.Destructive, "Repository MariaDB")
 	}
-
 	if cfg.RemoveUser {
-		info("✓ User sistem 'mysql'")
+		plan.Actions = append(plan.Actions, "Hapus user sistem dan bersihkan logs/temp")
+		plan.Destructive = append(plan.Destructive, "User sistem 'mysql'")
+	}
+
+	switch {
+	case cfg.BackupData:
+		plan.Actions = append([]string{"Backup data ke " + cfg.BackupPath}, plan.Actions...)
+		plan.Backup = terminal.BackupSafety{Protected: true, Detail: "data dibackup ke " + cfg.BackupPath}
+		manager := fs.NewManager()
+		for _, dir := range dataDirs {
+			if size, err := manager.Dir().GetSize(dir); err == nil {
+				plan.EstimatedDuration += time.Duration(size/removalCopyThroughput) * time.Second
+			}
+		}
+	case cfg.RemoveData:
+		plan.Backup = terminal.BackupSafety{Detail: "data directory dihapus tanpa backup"}
+	default:
+		plan.Backup = terminal.BackupSafety{Protected: true, Detail: "data directory tidak dihapus"}
 	}
 
-	if cfg.BackupData {
-		infof("Backup data akan dibuat di: %s", cfg.BackupPath)
-	}
+	return plan
+}
 
-	warn("PERHATIAN: Proses ini TIDAK DAPAT DIBATALKAN. Ketik 'HAPUS' untuk melanjutkan.")
+// removalDataDirectories mengembalikan direktori data yang akan dihapus. Direktori
+// default yang tidak ada disembunyikan dan direktori yang hilang diberi keterangan.
+func removalDataDirectories(deps *Dependencies) []string {
+	mariadbConfig := getDetectedConfig(deps)
+	if mariadbConfig == nil {
+		return []string{"/var/lib/mysql"}
+	}
 
-	fmt.Print("\nKonfirmasi: ")
+	// Default placeholder directories that we shouldn't show if they don't exist
+	defaults := map[string]bool{
+		"/var/lib/mysql": true,
+		"/var/log/mysql": true,
+	}
 
-	var response string
-	fmt.Scanln(&response)
-	response = strings.TrimSpace(response)
+	var dirs []string
+	for _, dir := range getAllCustomDirectories(mariadbConfig) {
+		// Normalize to absolute path for reliable comparisons
+		absDir := dir
+		if p, err := filepath.Abs(dir); err == nil {
+			absDir = p
+		}
 
-	if response != "HAPUS" {
-		return fmt.Errorf("penghapusan dibatalkan oleh user")
+		_, statErr := os.Stat(absDir)
+		if defaults[absDir] && os.IsNotExist(statErr) {
+			continue
+		}
+		if statErr != nil {
+			absDir += " (tidak ditemukan)"
+		}
+		dirs = append(dirs, absDir)
 	}
-
-	return nil
+	return dirs
 }
 
 // Helper functions
@@ -148,32 +186,6 @@ func isMariaDBInstalled(deps *Dependencies) bool {
 	return false
 }
 
-func getInstalledMariaDBVersion(deps *Dependencies) string {
-	// Coba jalankan mysql --version untuk mendapatkan versi
-	output, err := deps.ProcessManager.ExecuteWithOutput("mysql", []string{"--version"})
-	if err != nil {
-		return ""
-	}
-
-	// Parse output untuk mendapatkan versi
-	lines := strings.Split(output, "\n")
-	if len(lines) > 0 {
-		// Contoh output: mysql  Ver 15.1 Distrib 10.6.23-MariaDB, for Linux (x86_64)
-		if strings.Contains(lines[0], "MariaDB") {
-			parts := strings.Split(lines[0], " ")
-			for _, part := range parts {
-				if strings.Contains(part, "MariaDB") {
-					// Ekstrak versi dari format "10.6.23-MariaDB,"
-					version := strings.Split(part, "-")[0]
-					return version
-				}
-			}
-		}
-	}
-
-	return ""
-}
-
 func isRunningAsRoot() bool {
 	return os.Geteuid() == 0
 }
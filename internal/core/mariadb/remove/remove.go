@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	mariadb_config "sfDBTools/utils/mariadb/config"
 	"sfDBTools/utils/system"
 	"sfDBTools/utils/terminal"
@@ -22,7 +23,7 @@ type Dependencies struct {
 func RunMariaDBRemove(ctx context.Context, cfg *mariadb_config.MariaDBRemoveConfig) error {
 	lg, _ := logger.Get()
 	terminal.ClearScreen()
-	terminal.Headers("MariaDB Removal Process")
+	terminal.Headers(i18n.T("mariadb.remove.title"))
 
 	// Inisialisasi dependencies
 	deps := &Dependencies{
@@ -48,7 +49,7 @@ func RunMariaDBRemove(ctx context.Context, cfg *mariadb_config.MariaDBRemoveConf
 	}
 
 	// Langkah 3: Stop dan disable service MariaDB
-	if err := stepWithSpinner("Menghentikan & mendisable service MariaDB", func() error {
+	if err := stepWithSpinner(i18n.T("mariadb.remove.step_stop_service"), func() error {
 		return stopMariaDBService(deps)
 	}); err != nil {
 		return fmt.Errorf("stop service MariaDB gagal: %w", err)
@@ -56,7 +57,7 @@ func RunMariaDBRemove(ctx context.Context, cfg *mariadb_config.MariaDBRemoveConf
 
 	// Langkah 4: Backup data sebelum dihapus (jika diminta)
 	if cfg.BackupData {
-		if err := stepWithSpinner("Membuat backup data MariaDB", func() error {
+		if err := stepWithSpinner(i18n.T("mariadb.remove.step_backup"), func() error {
 			return handleDataBackup(cfg, deps)
 		}); err != nil {
 			return fmt.Errorf("backup data gagal: %w", err)
@@ -64,7 +65,7 @@ func RunMariaDBRemove(ctx context.Context, cfg *mariadb_config.MariaDBRemoveConf
 	}
 
 	// Langkah 5: Hapus paket MariaDB
-	if err := stepWithSpinner("Menghapus paket MariaDB", func() error {
+	if err := stepWithSpinner(i18n.T("mariadb.remove.step_packages"), func() error {
 		return removeMariaDBPackages(deps)
 	}); err != nil {
 		return fmt.Errorf("penghapusan paket MariaDB gagal: %w", err)
@@ -72,7 +73,7 @@ func RunMariaDBRemove(ctx context.Context, cfg *mariadb_config.MariaDBRemoveConf
 
 	// Langkah 6: Hapus data dan konfigurasi (jika diminta)
 	if cfg.RemoveData || cfg.RemoveConfig {
-		if err := stepWithSpinner("Menghapus data dan/atau konfigurasi MariaDB", func() error {
+		if err := stepWithSpinner(i18n.T("mariadb.remove.step_data"), func() error {
 			return removeDataAndConfig(cfg, deps)
 		}); err != nil {
 			return fmt.Errorf("penghapusan data/config gagal: %w", err)
@@ -81,7 +82,7 @@ func RunMariaDBRemove(ctx context.Context, cfg *mariadb_config.MariaDBRemoveConf
 
 	// Langkah 7: Hapus repository MariaDB (jika diminta)
 	if cfg.RemoveRepository {
-		if err := stepWithSpinner("Menghapus repository MariaDB", func() error {
+		if err := stepWithSpinner(i18n.T("mariadb.remove.step_repo"), func() error {
 			return removeMariaDBRepository(cfg, deps)
 		}); err != nil {
 			return fmt.Errorf("penghapusan repository gagal: %w", err)
@@ -90,7 +91,7 @@ func RunMariaDBRemove(ctx context.Context, cfg *mariadb_config.MariaDBRemoveConf
 
 	// Langkah 8: Cleanup sistem dan user
 	if cfg.RemoveUser {
-		if err := stepWithSpinner("Membersihkan sistem (user, logs, temp)", func() error {
+		if err := stepWithSpinner(i18n.T("mariadb.remove.step_system"), func() error {
 			return cleanupSystem(cfg, deps)
 		}); err != nil {
 			return fmt.Errorf("cleanup sistem gagal: %w", err)
@@ -98,7 +99,7 @@ func RunMariaDBRemove(ctx context.Context, cfg *mariadb_config.MariaDBRemoveConf
 	}
 
 	// Langkah 9: Verifikasi penghapusan
-	if err := stepWithSpinner("Memverifikasi penghapusan", func() error {
+	if err := stepWithSpinner(i18n.T("mariadb.remove.step_verify"), func() error {
 		return verifyRemoval(deps)
 	}); err != nil {
 		return fmt.Errorf("verifikasi penghapusan gagal: %w", err)
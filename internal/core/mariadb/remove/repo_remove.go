@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	mariadb_config "sfDBTools/utils/mariadb/config"
 	"sfDBTools/utils/system"
 	"sfDBTools/utils/terminal"
@@ -17,7 +18,7 @@ func removeMariaDBRepository(cfg *mariadb_config.MariaDBRemoveConfig, deps *Depe
 	}
 
 	lg, _ := logger.Get()
-	terminal.PrintSubHeader("Menghapus repository MariaDB...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.removing_repo"))
 
 	osInfo, err := system.DetectOS()
 	if err != nil {
@@ -78,11 +79,14 @@ func removeDebianRepository(deps *Dependencies) error {
 	return nil
 }
 
-// removeRPMRepository menghapus repository MariaDB untuk CentOS/RHEL/Rocky
+// removeRPMRepository menghapus repository MariaDB untuk CentOS/RHEL/Rocky,
+// Amazon Linux dan SLES/openSUSE
 func removeRPMRepository(deps *Dependencies) error {
 	repoFiles := []string{
 		"/etc/yum.repos.d/MariaDB.repo",
 		"/etc/yum.repos.d/mariadb.repo",
+		"/etc/zypp/repos.d/MariaDB.repo",
+		"/etc/zypp/repos.d/mariadb.repo",
 	}
 
 	for _, file := range repoFiles {
@@ -101,12 +105,16 @@ func removeRPMRepository(deps *Dependencies) error {
 	}
 
 	// Clean package cache
-	terminal.PrintSubHeader("Membersihkan package cache...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.cleaning_cache"))
 	if err := deps.ProcessManager.Execute("yum", []string{"clean", "all"}); err != nil {
-		// Try dnf if yum fails
+		// Try dnf, lalu zypper (SLES/openSUSE), jika yum gagal
 		if err := deps.ProcessManager.Execute("dnf", []string{"clean", "all"}); err != nil {
-			// Log warning tapi tidak return error
-			warn("Gagal membersihkan package cache")
+			if err := deps.ProcessManager.Execute("zypper", []string{"--non-interactive", "clean", "--all"}); err != nil {
+				// Log warning tapi tidak return error
+				warn("Gagal membersihkan package cache")
+			} else {
+				success("Package cache dibersihkan")
+			}
 		} else {
 			success("Package cache dibersihkan")
 		}
@@ -2,6 +2,7 @@ package remove
 
 import (
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	"sfDBTools/utils/terminal"
 )
 
@@ -9,7 +10,7 @@ import (
 func stopMariaDBService(deps *Dependencies) error {
 	lg, _ := logger.Get()
 
-	terminal.PrintSubHeader("Menghentikan & mendisable service MariaDB...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.stopping_service"))
 
 	serviceName := "mariadb"
 
@@ -46,7 +47,7 @@ func stopMariaDBService(deps *Dependencies) error {
 // killMariaDBProcesses membunuh proses MariaDB yang mungkin masih berjalan
 func killMariaDBProcesses(deps *Dependencies) error {
 
-	terminal.PrintSubHeader("Menghentikan proses MariaDB yang masih berjalan...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.stopping_processes"))
 
 	// Cari proses mysqld
 	processes := []string{"mysqld", "mariadbd", "mysql"}
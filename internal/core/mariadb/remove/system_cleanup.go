@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	mariadb_config "sfDBTools/utils/mariadb/config"
 	"sfDBTools/utils/terminal"
 )
@@ -38,7 +39,7 @@ func cleanupSystem(cfg *mariadb_config.MariaDBRemoveConfig, deps *Dependencies)
 
 // removeMySQLUser menghapus user mysql dari sistem
 func removeMySQLUser(deps *Dependencies) error {
-	terminal.PrintSubHeader("Menghapus user mysql dari sistem...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.removing_user"))
 
 	// Cek apakah user mysql ada
 	_, err := deps.ProcessManager.ExecuteWithOutput("id", []string{"mysql"})
@@ -61,7 +62,7 @@ func removeMySQLUser(deps *Dependencies) error {
 
 // cleanupLogFiles menghapus file-file log MariaDB
 func cleanupLogFiles() error {
-	terminal.PrintSubHeader("Membersihkan log files...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.cleaning_logs"))
 
 	logPaths := []string{
 		"/var/log/mysql",
@@ -94,7 +95,7 @@ func cleanupLogFiles() error {
 
 // cleanupTempFiles menghapus file-file temporary MariaDB
 func cleanupTempFiles() error {
-	terminal.PrintSubHeader("Membersihkan temp files...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.cleaning_temp"))
 
 	tempPaths := []string{
 		"/tmp/mysql.sock",
@@ -127,7 +128,7 @@ func cleanupTempFiles() error {
 
 // verifyRemoval memverifikasi bahwa penghapusan berhasil
 func verifyRemoval(deps *Dependencies) error {
-	terminal.PrintSubHeader("Memverifikasi penghapusan...")
+	terminal.PrintSubHeader(i18n.T("mariadb.remove.verifying"))
 
 	// Cek apakah masih ada paket yang terinstall
 	packages, err := getMariaDBPackageList()
@@ -0,0 +1,116 @@
+package users
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Authentication plugins with a known hash format
+const (
+	pluginNative  = "mysql_native_password"
+	pluginOld     = "mysql_old_password"
+	pluginEd25519 = "ed25519"
+)
+
+var (
+	nativeHash  = regexp.MustCompile(`^\*[0-9A-Fa-f]{40}$`)
+	oldHash     = regexp.MustCompile(`^[0-9A-Fa-f]{16}$`)
+	ed25519Hash = regexp.MustCompile(`^[A-Za-z0-9+/]{43}$`)
+)
+
+// externalPlugins authenticate against the OS or a directory service, so the
+// account only works if the same identities exist on the target
+var externalPlugins = map[string]bool{
+	"unix_socket": true,
+	"gssapi":      true,
+	"pam":         true,
+	"named_pipe":  true,
+}
+
+// CheckCompatibility verifies that every account in doc can be recreated on
+// the target: the authentication plugin must be active there and the stored
+// hash must be in the format that plugin expects
+func CheckCompatibility(db *sql.DB, doc *Document) ([]Issue, error) {
+	plugins, err := activeAuthPlugins(db)
+	if err != nil {
+		return nil, err
+	}
+	var secureAuth bool
+	_ = db.QueryRow("SELECT @@GLOBAL.secure_auth").Scan(&secureAuth)
+
+	var issues []Issue
+	for _, acc := range doc.Users {
+		if acc.IsRole {
+			continue
+		}
+		name := acc.Name()
+
+		if !plugins[acc.Plugin] {
+			issues = append(issues, Issue{Account: name, Fatal: true,
+				Message: fmt.Sprintf("authentication plugin %s is not active on the target (INSTALL SONAME or use another plugin)", acc.Plugin)})
+			continue
+		}
+		if msg := checkHashFormat(acc.Plugin, acc.AuthString); msg != "" {
+			issues = append(issues, Issue{Account: name, Fatal: true, Message: msg})
+			continue
+		}
+		if acc.Plugin == pluginOld && secureAuth {
+			issues = append(issues, Issue{Account: name,
+				Message: "pre-4.1 password hash: logins are refused while secure_auth is ON, reset the password after import"})
+		}
+		if externalPlugins[acc.Plugin] {
+			issues = append(issues, Issue{Account: name,
+				Message: fmt.Sprintf("%s authenticates externally: the matching OS/directory identity must exist on the target", acc.Plugin)})
+		}
+	}
+	return issues, nil
+}
+
+// checkHashFormat returns a problem description when hash does not match the
+// format of plugin, or "" when it matches or the plugin has no known format
+func checkHashFormat(plugin, hash string) string {
+	if hash == "" {
+		return ""
+	}
+	switch plugin {
+	case pluginNative:
+		if !nativeHash.MatchString(hash) {
+			if oldHash.MatchString(hash) {
+				return "pre-4.1 (16 character) hash stored for mysql_native_password; switch the account to mysql_old_password or reset its password"
+			}
+			return "mysql_native_password hash is not in '*' + 40 hex format"
+		}
+	case pluginOld:
+		if !oldHash.MatchString(hash) {
+			return "mysql_old_password hash is not 16 hex characters"
+		}
+	case pluginEd25519:
+		if !ed25519Hash.MatchString(hash) {
+			return "ed25519 hash is not a 43 character base64 string"
+		}
+	}
+	return ""
+}
+
+// activeAuthPlugins returns the authentication plugins the server can use.
+// The native and old password plugins are built in and always available.
+func activeAuthPlugins(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT PLUGIN_NAME FROM information_schema.PLUGINS
+		WHERE PLUGIN_TYPE = 'AUTHENTICATION' AND PLUGIN_STATUS = 'ACTIVE'`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list authentication plugins: %w", err)
+	}
+	defer rows.Close()
+
+	plugins := map[string]bool{pluginNative: true, pluginOld: true}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		plugins[strings.ToLower(name)] = true
+	}
+	return plugins, rows.Err()
+}
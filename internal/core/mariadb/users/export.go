@@ -0,0 +1,158 @@
+package users
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+)
+
+// builtinAccounts are created by the server itself and never exported
+var builtinAccounts = map[string]bool{
+	"root":        true,
+	"mysql":       true,
+	"mariadb.sys": true,
+	"mysql.sys":   true,
+	"PUBLIC":      true,
+}
+
+// ExportOptions controls which accounts are exported
+type ExportOptions struct {
+	SourceHost    string
+	Users         []string // user or user@host names; empty exports all
+	IncludeSystem bool     // include configured system users and built-in accounts
+}
+
+// Export reads accounts, their authentication, TLS requirements, resource
+// limits and grants from the server into a portable document
+func Export(db *sql.DB, opts ExportOptions) (*Document, error) {
+	lg, _ := logger.Get()
+
+	var version string
+	if err := db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		return nil, fmt.Errorf("failed to read server version: %w", err)
+	}
+
+	accounts, err := listAccounts(db)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{
+		Version:       FormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		SourceHost:    opts.SourceHost,
+		ServerVersion: version,
+	}
+	for _, acc := range accounts {
+		if !opts.IncludeSystem && (builtinAccounts[acc.User] || database.IsSystemUser(acc.User)) {
+			continue
+		}
+		if !matchesFilter(acc, opts.Users) {
+			continue
+		}
+
+		grants, err := accountGrants(db, acc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read grants for %s: %w", acc.Name(), err)
+		}
+		acc.Grants = grants
+		doc.Users = append(doc.Users, acc)
+		lg.Debug("Exported account", logger.String("account", acc.Name()), logger.Int("grants", len(grants)))
+	}
+	return doc, nil
+}
+
+// listAccounts reads account attributes from mysql.user. On MariaDB 10.4+ this
+// is a view over mysql.global_priv exposing the same columns.
+func listAccounts(db *sql.DB) ([]Account, error) {
+	rows, err := db.Query(`
+		SELECT User, Host, is_role, plugin, authentication_string, Password,
+			ssl_type, ssl_cipher, x509_issuer, x509_subject,
+			max_questions, max_updates, max_connections, max_user_connections
+		FROM mysql.user
+		WHERE User != ''
+		ORDER BY is_role DESC, User, Host`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mysql.user: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []Account
+	for rows.Next() {
+		var (
+			acc                     Account
+			isRole, password        string
+			cipher, issuer, subject []byte
+		)
+		if err := rows.Scan(&acc.User, &acc.Host, &isRole, &acc.Plugin, &acc.AuthString, &password,
+			&acc.TLS.Type, &cipher, &issuer, &subject,
+			&acc.Limits.MaxQueriesPerHour, &acc.Limits.MaxUpdatesPerHour,
+			&acc.Limits.MaxConnectionsPerHour, &acc.Limits.MaxUserConnections); err != nil {
+			return nil, fmt.Errorf("failed to scan mysql.user row: %w", err)
+		}
+
+		acc.IsRole = isRole == "Y"
+		if acc.AuthString == "" && password != "" {
+			// Before 10.4 native hashes live in the Password column
+			acc.AuthString = password
+			if acc.Plugin == "" {
+				acc.Plugin = pluginNative
+			}
+		}
+		if acc.Plugin == "" && !acc.IsRole {
+			acc.Plugin = pluginNative
+		}
+		acc.TLS.Cipher, acc.TLS.Issuer, acc.TLS.Subject = string(cipher), string(issuer), string(subject)
+		if acc.IsRole {
+			acc.Host, acc.Plugin, acc.AuthString = "", "", ""
+		}
+		accounts = append(accounts, acc)
+	}
+	return accounts, rows.Err()
+}
+
+// accountGrants returns the GRANT statements of an account, leaving out the
+// USAGE line that only carries what CREATE USER already sets
+func accountGrants(db *sql.DB, acc Account) ([]string, error) {
+	rows, err := db.Query("SHOW GRANTS FOR " + acc.SQLName())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []string
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return nil, err
+		}
+		if isBareUsage(grant) {
+			continue
+		}
+		grants = append(grants, grant)
+	}
+	return grants, rows.Err()
+}
+
+// isBareUsage reports whether grant is "GRANT USAGE ON *.*" without grant option
+func isBareUsage(grant string) bool {
+	upper := strings.ToUpper(grant)
+	return strings.HasPrefix(upper, "GRANT USAGE ON *.* TO") && !strings.Contains(upper, "WITH GRANT OPTION")
+}
+
+// matchesFilter reports whether acc is selected by a list of user or user@host names
+func matchesFilter(acc Account, filter []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if f == acc.User || f == acc.User+"@"+acc.Host {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package users
+
+import (
+	"fmt"
+	"os"
+
+	"sfDBTools/utils/fs/policy"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Save writes doc as YAML. The file holds password hashes, so it is created
+// with the configured file policy rather than world-readable.
+func Save(path string, doc *Document) error {
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode users: %w", err)
+	}
+	if err := policy.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Load reads a document written by Save
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if doc.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported user export version %d (expected %d)", doc.Version, FormatVersion)
+	}
+	return &doc, nil
+}
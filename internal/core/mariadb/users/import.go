@@ -0,0 +1,179 @@
+package users
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+)
+
+// Import recreates the accounts of doc on the target server. Roles are created
+// before users and grants are applied last so role grants resolve. Accounts
+// with fatal compatibility issues are skipped, as are accounts that already
+// exist unless opts.Replace is set.
+func Import(db *sql.DB, doc *Document, opts ImportOptions) (*ImportResult, error) {
+	lg, _ := logger.Get()
+
+	issues, err := CheckCompatibility(db, doc)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := existingAccounts(db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Skipped: make(map[string]string), Failed: make(map[string]error)}
+	fatal := make(map[string]string)
+	for _, issue := range issues {
+		if issue.Fatal {
+			fatal[issue.Account] = issue.Message
+		}
+	}
+
+	var applied []Account
+	for _, acc := range doc.Users {
+		name := acc.Name()
+		if msg, ok := fatal[name]; ok {
+			result.Skipped[name] = msg
+			continue
+		}
+		exists := existing[acc.User+"@"+acc.Host]
+		if exists && !opts.Replace {
+			result.Skipped[name] = "already exists on target (use --replace to recreate)"
+			continue
+		}
+
+		if opts.DryRun {
+			result.Statements = append(result.Statements, createStatement(acc, exists, true))
+			result.Statements = append(result.Statements, acc.Grants...)
+			continue
+		}
+
+		if _, err := db.Exec(createStatement(acc, exists, false)); err != nil {
+			result.Failed[name] = fmt.Errorf("create failed: %w", err)
+			lg.Error("Failed to create account", logger.String("account", name), logger.Error(err))
+			continue
+		}
+		if exists {
+			result.Replaced = append(result.Replaced, name)
+		} else {
+			result.Created = append(result.Created, name)
+		}
+		applied = append(applied, acc)
+		lg.Info("Account created", logger.String("account", name), logger.Bool("replaced", exists))
+	}
+
+	for _, acc := range applied {
+		for _, grant := range acc.Grants {
+			if _, err := db.Exec(grant); err != nil {
+				result.Failed[acc.Name()] = fmt.Errorf("grant failed (%s): %w", grant, err)
+				lg.Error("Failed to apply grant", logger.String("account", acc.Name()), logger.String("grant", grant), logger.Error(err))
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// existingAccounts returns the user@host accounts present on the server;
+// roles are keyed with an empty host
+func existingAccounts(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query("SELECT User, IF(is_role = 'Y', '', Host) FROM mysql.user")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list target accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := make(map[string]bool)
+	for rows.Next() {
+		var user, host string
+		if err := rows.Scan(&user, &host); err != nil {
+			return nil, err
+		}
+		accounts[user+"@"+host] = true
+	}
+	return accounts, rows.Err()
+}
+
+// createStatement builds the CREATE USER or CREATE ROLE statement for acc.
+// With redact the stored hash is masked so dry-run output can be shared.
+func createStatement(acc Account, replace, redact bool) string {
+	verb := "CREATE"
+	if replace {
+		verb = "CREATE OR REPLACE"
+	}
+	if acc.IsRole {
+		return fmt.Sprintf("%s ROLE %s", verb, acc.SQLName())
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s USER %s", verb, acc.SQLName())
+
+	auth := acc.AuthString
+	if redact && auth != "" {
+		auth = "<redacted>"
+	}
+	switch {
+	case acc.Plugin == pluginNative || acc.Plugin == "":
+		if auth != "" {
+			sb.WriteString(" IDENTIFIED BY PASSWORD " + database.QuoteString(auth))
+		}
+	default:
+		sb.WriteString(" IDENTIFIED VIA " + acc.Plugin)
+		if auth != "" {
+			sb.WriteString(" USING " + database.QuoteString(auth))
+		}
+	}
+
+	if req := requireClause(acc.TLS); req != "" {
+		sb.WriteString(" REQUIRE " + req)
+	}
+	if with := limitsClause(acc.Limits); with != "" {
+		sb.WriteString(" WITH " + with)
+	}
+	return sb.String()
+}
+
+// requireClause maps mysql.user ssl_type and x509 columns to a REQUIRE clause
+func requireClause(t TLS) string {
+	switch strings.ToUpper(t.Type) {
+	case "ANY":
+		return "SSL"
+	case "X509":
+		return "X509"
+	case "SPECIFIED":
+		var parts []string
+		if t.Cipher != "" {
+			parts = append(parts, "CIPHER "+database.QuoteString(t.Cipher))
+		}
+		if t.Issuer != "" {
+			parts = append(parts, "ISSUER "+database.QuoteString(t.Issuer))
+		}
+		if t.Subject != "" {
+			parts = append(parts, "SUBJECT "+database.QuoteString(t.Subject))
+		}
+		return strings.Join(parts, " AND ")
+	}
+	return ""
+}
+
+// limitsClause builds the WITH clause for non-zero resource limits
+func limitsClause(l Limits) string {
+	var parts []string
+	if l.MaxQueriesPerHour > 0 {
+		parts = append(parts, fmt.Sprintf("MAX_QUERIES_PER_HOUR %d", l.MaxQueriesPerHour))
+	}
+	if l.MaxUpdatesPerHour > 0 {
+		parts = append(parts, fmt.Sprintf("MAX_UPDATES_PER_HOUR %d", l.MaxUpdatesPerHour))
+	}
+	if l.MaxConnectionsPerHour > 0 {
+		parts = append(parts, fmt.Sprintf("MAX_CONNECTIONS_PER_HOUR %d", l.MaxConnectionsPerHour))
+	}
+	if l.MaxUserConnections > 0 {
+		parts = append(parts, fmt.Sprintf("MAX_USER_CONNECTIONS %d", l.MaxUserConnections))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,100 @@
+package users
+
+import (
+	"time"
+
+	"sfDBTools/utils/database"
+)
+
+// FormatVersion is the version of the portable user export document
+const FormatVersion = 1
+
+// Document is the portable YAML representation of exported accounts
+type Document struct {
+	Version       int       `yaml:"version"`
+	ExportedAt    time.Time `yaml:"exported_at"`
+	SourceHost    string    `yaml:"source_host"`
+	ServerVersion string    `yaml:"server_version"`
+	Users         []Account `yaml:"users"`
+}
+
+// Account is a single user or role with everything needed to recreate it
+type Account struct {
+	User       string   `yaml:"user"`
+	Host       string   `yaml:"host"`
+	IsRole     bool     `yaml:"is_role,omitempty"`
+	Plugin     string   `yaml:"plugin,omitempty"`
+	AuthString string   `yaml:"auth_string,omitempty"`
+	TLS        TLS      `yaml:"tls,omitempty"`
+	Limits     Limits   `yaml:"limits,omitempty"`
+	Grants     []string `yaml:"grants"`
+}
+
+// TLS holds the REQUIRE clause of an account
+type TLS struct {
+	Type    string `yaml:"type,omitempty"` // "", ANY, X509 or SPECIFIED
+	Cipher  string `yaml:"cipher,omitempty"`
+	Issuer  string `yaml:"issuer,omitempty"`
+	Subject string `yaml:"subject,omitempty"`
+}
+
+// Limits holds per-account resource limits; zero means unlimited
+type Limits struct {
+	MaxQueriesPerHour     int64 `yaml:"max_queries_per_hour,omitempty"`
+	MaxUpdatesPerHour     int64 `yaml:"max_updates_per_hour,omitempty"`
+	MaxConnectionsPerHour int64 `yaml:"max_connections_per_hour,omitempty"`
+	MaxUserConnections    int64 `yaml:"max_user_connections,omitempty"`
+}
+
+// SQLName returns the quoted 'user'@'host' account name used in statements
+func (a Account) SQLName() string {
+	if a.IsRole {
+		return database.QuoteString(a.User)
+	}
+	return database.QuoteString(a.User) + "@" + database.QuoteString(a.Host)
+}
+
+// Name returns user@host for display
+func (a Account) Name() string {
+	if a.IsRole {
+		return a.User + " (role)"
+	}
+	return a.User + "@" + a.Host
+}
+
+// Select keeps only the accounts matching user or user@host names; an empty
+// list keeps all accounts
+func (d *Document) Select(names []string) {
+	if len(names) == 0 {
+		return
+	}
+	var selected []Account
+	for _, acc := range d.Users {
+		if matchesFilter(acc, names) {
+			selected = append(selected, acc)
+		}
+	}
+	d.Users = selected
+}
+
+// Issue is a compatibility problem found before import
+type Issue struct {
+	Account string
+	Message string
+	Fatal   bool // the account cannot be created on the target
+}
+
+// ImportOptions controls how a document is applied to a server
+type ImportOptions struct {
+	Replace bool // recreate accounts that already exist
+	DryRun  bool // only return the statements
+}
+
+// ImportResult reports the outcome per account
+type ImportResult struct {
+	Created    []string
+	Replaced   []string
+	Skipped    map[string]string
+	Failed     map[string]error
+	Statements []string // populated for dry runs
+}
@@ -0,0 +1,92 @@
+package maxscale
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/maxscale"
+	"sfDBTools/utils/system"
+)
+
+// startTimeout bounds waiting for the REST API after a restart
+const startTimeout = 30 * time.Second
+
+// ConfigureOptions is what Configure deploys
+type ConfigureOptions struct {
+	ConfigFile string
+	Content    string
+	// Client queries the REST API to confirm MaxScale sees the servers;
+	// nil skips the check
+	Client *maxscale.Client
+}
+
+// ConfigureResult reports what Configure did
+type ConfigureResult struct {
+	Backup  string
+	Servers []maxscale.ServerState
+}
+
+// Configure writes maxscale.cnf, restarts MaxScale and waits until its REST
+// API answers. When MaxScale does not come back, the previous configuration
+// is put back and MaxScale restarted with it.
+func Configure(opts ConfigureOptions) (*ConfigureResult, error) {
+	lg, _ := logger.Get()
+	backup, err := maxscale.WriteConfig(opts.ConfigFile, opts.Content)
+	if err != nil {
+		return nil, err
+	}
+	result := &ConfigureResult{Backup: backup}
+
+	sm := system.NewServiceManager()
+	if err := sm.Enable(maxscale.ServiceName); err != nil {
+		return result, err
+	}
+	startErr := sm.Restart(maxscale.ServiceName)
+	if startErr == nil {
+		result.Servers, startErr = waitForAPI(sm, opts.Client)
+	}
+	if startErr == nil {
+		lg.Info("MaxScale configured", logger.String("config", opts.ConfigFile), logger.Int("servers", len(result.Servers)))
+		return result, nil
+	}
+
+	if backup == "" {
+		return result, fmt.Errorf("MaxScale did not start with the new configuration: %w", startErr)
+	}
+	lg.Warn("MaxScale did not start, restoring the previous configuration", logger.String("backup", backup), logger.Error(startErr))
+	data, err := os.ReadFile(backup)
+	if err == nil {
+		err = os.WriteFile(opts.ConfigFile, data, 0640)
+	}
+	if err == nil {
+		err = sm.Restart(maxscale.ServiceName)
+	}
+	if err != nil {
+		return result, fmt.Errorf("MaxScale did not start with the new configuration (%v), and restoring %s failed: %w", startErr, backup, err)
+	}
+	return result, fmt.Errorf("MaxScale did not start with the new configuration, the previous one was restored: %w", startErr)
+}
+
+// waitForAPI polls the service and REST API until MaxScale reports its servers
+func waitForAPI(sm system.ServiceManager, client *maxscale.Client) ([]maxscale.ServerState, error) {
+	deadline := time.Now().Add(startTimeout)
+	var lastErr error
+	for time.Now().Before(deadline) {
+		time.Sleep(2 * time.Second)
+		if !sm.IsActive(maxscale.ServiceName) {
+			lastErr = fmt.Errorf("service %s is not active", maxscale.ServiceName)
+			continue
+		}
+		if client == nil {
+			return nil, nil
+		}
+		servers, err := client.ListServers()
+		if err == nil {
+			return servers, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("not ready after %s: %w", startTimeout, lastErr)
+}
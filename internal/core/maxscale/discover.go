@@ -0,0 +1,181 @@
+package maxscale
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/maxscale"
+)
+
+// Backends is the topology found from a seed server
+type Backends struct {
+	Servers []maxscale.Server
+	// Galera is set when the seed is a Galera node; the servers are then the
+	// cluster members, otherwise the primary followed by its replicas
+	Galera bool
+}
+
+// Monitor returns the MaxScale monitor module for the topology
+func (b *Backends) Monitor() string {
+	if b.Galera {
+		return "galeramon"
+	}
+	return "mariadbmon"
+}
+
+// DiscoverBackends finds the servers MaxScale should route to, starting from
+// any server of the topology. A Galera node lists the cluster through
+// wsrep_incoming_addresses. For replication, a replica leads to its primary
+// and the primary lists the replicas that set report_host.
+func DiscoverBackends(seed database.Config) (*Backends, error) {
+	lg, _ := logger.Get()
+	seed.DBName = ""
+
+	db, err := database.GetWithoutDB(seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s:%d: %w", seed.Host, seed.Port, err)
+	}
+	defer db.Close()
+
+	if addresses := statusValue(db, "wsrep_incoming_addresses"); addresses != "" && addresses != "AUTO" {
+		var entries []string
+		for _, a := range strings.Split(addresses, ",") {
+			if a = strings.TrimSpace(a); a != "" && !strings.HasPrefix(a, "AUTO") {
+				entries = append(entries, a)
+			}
+		}
+		servers, err := maxscale.ParseServers(entries)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected wsrep_incoming_addresses %q: %w", addresses, err)
+		}
+		lg.Info("Galera cluster discovered", logger.Int("nodes", len(servers)))
+		return &Backends{Servers: servers, Galera: true}, nil
+	}
+
+	entries := []string{endpoint(seed.Host, seed.Port)}
+	primary := seed
+	status, err := rowMap(db, "SHOW SLAVE STATUS")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replication status of %s: %w", entries[0], err)
+	}
+	if host := status["Master_Host"]; host != "" {
+		// The seed is a replica: its primary goes first and lists the others
+		port, _ := strconv.Atoi(status["Master_Port"])
+		primary.Host, primary.Port, primary.Socket = host, port, ""
+		entries = []string{endpoint(host, port), entries[0]}
+		pdb, err := database.GetWithoutDB(primary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to primary %s: %w", entries[0], err)
+		}
+		defer pdb.Close()
+		db = pdb
+	}
+
+	replicas, err := replicaHosts(db)
+	if err != nil {
+		lg.Warn("Failed to list replicas of the primary", logger.String("primary", entries[0]), logger.Error(err))
+	}
+	entries = append(entries, replicas...)
+
+	servers, err := maxscale.ParseServers(dedupe(entries))
+	if err != nil {
+		return nil, err
+	}
+	lg.Info("Replication topology discovered",
+		logger.String("primary", entries[0]),
+		logger.Int("servers", len(servers)))
+	return &Backends{Servers: servers}, nil
+}
+
+// replicaHosts lists the replicas connected to a primary. Replicas without
+// report_host show an empty host and cannot be addressed, so they are skipped.
+func replicaHosts(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SHOW SLAVE HOSTS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	maps, err := scanMaps(rows)
+	if err != nil {
+		return nil, err
+	}
+	var hosts []string
+	for _, m := range maps {
+		if m["Host"] == "" {
+			continue
+		}
+		port, _ := strconv.Atoi(m["Port"])
+		hosts = append(hosts, endpoint(m["Host"], port))
+	}
+	return hosts, nil
+}
+
+// statusValue returns a global status variable, empty when it does not exist
+func statusValue(db *sql.DB, name string) string {
+	var n, value string
+	if err := db.QueryRow("SHOW GLOBAL STATUS LIKE ?", name).Scan(&n, &value); err != nil {
+		return ""
+	}
+	return value
+}
+
+// rowMap returns the first row of query by column name, nil when it is empty
+func rowMap(db *sql.DB, query string) (map[string]string, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	maps, err := scanMaps(rows)
+	if err != nil || len(maps) == 0 {
+		return nil, err
+	}
+	return maps[0], nil
+}
+
+func scanMaps(rows *sql.Rows) ([]map[string]string, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result []map[string]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		m := make(map[string]string, len(cols))
+		for i, c := range cols {
+			m[c] = values[i].String
+		}
+		result = append(result, m)
+	}
+	return result, rows.Err()
+}
+
+func endpoint(host string, port int) string {
+	if port == 0 {
+		port = 3306
+	}
+	return fmt.Sprintf("%s:%d", host, port)
+}
+
+func dedupe(entries []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, e := range entries {
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+	return out
+}
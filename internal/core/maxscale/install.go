@@ -0,0 +1,107 @@
+// Package maxscale installs MaxScale and deploys its configuration for the
+// database servers it fronts.
+package maxscale
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/download"
+	"sfDBTools/utils/maxscale"
+	"sfDBTools/utils/shutdown"
+	"sfDBTools/utils/system"
+	"sfDBTools/utils/tempdir"
+	"sfDBTools/utils/terminal"
+)
+
+// repoSetupURL is the MariaDB repository setup script, which also sets up the
+// MaxScale repository
+const repoSetupURL = "https://downloads.mariadb.com/MariaDB/mariadb_repo_setup"
+
+// PackageName is the MaxScale package in the MariaDB repository
+const PackageName = "maxscale"
+
+// InstallOptions selects what Install sets up. An empty Version uses the
+// latest MaxScale release of the repository.
+type InstallOptions struct {
+	Version string
+}
+
+// Install adds the MaxScale repository, installs the package and enables the
+// service. The service is started by Configure, once maxscale.cnf describes
+// the servers; the packaged sample configuration routes nowhere.
+func Install(opts InstallOptions) error {
+	lg, _ := logger.Get()
+	pm := system.NewPackageManager()
+
+	if pm.IsInstalled(PackageName) {
+		terminal.PrintInfo("MaxScale is already installed")
+	} else {
+		terminal.PrintSubHeader("Setting up the MaxScale repository")
+		if err := setupRepository(opts.Version); err != nil {
+			return err
+		}
+		terminal.PrintSubHeader("Installing packages")
+		if err := pm.Install([]string{PackageName}); err != nil {
+			return fmt.Errorf("failed to install %s: %w", PackageName, err)
+		}
+	}
+
+	sm := system.NewServiceManager()
+	if err := sm.Enable(maxscale.ServiceName); err != nil {
+		return err
+	}
+	lg.Info("MaxScale installed", logger.String("version", InstalledVersion()))
+	return nil
+}
+
+// InstalledVersion returns the version reported by the maxscale binary,
+// empty when it is not installed
+func InstalledVersion() string {
+	out, err := exec.Command("maxscale", "--version").Output()
+	if err != nil {
+		return ""
+	}
+	// "MaxScale 24.02.1"
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// setupRepository runs mariadb_repo_setup for MaxScale only
+func setupRepository(version string) error {
+	lg, _ := logger.Get()
+	script, err := downloadRepoSetup()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(script)
+
+	args := []string{script, "--skip-server", "--skip-tools"}
+	if version != "" {
+		args = append(args, "--mariadb-maxscale-version="+version)
+	}
+	if err := system.NewProcessManager().ExecuteWithTimeout("bash", args, 5*time.Minute); err != nil {
+		return fmt.Errorf("failed to set up the MaxScale repository: %w", err)
+	}
+	lg.Info("MaxScale repository configured", logger.String("version", version))
+	return nil
+}
+
+// downloadRepoSetup fetches the setup script into the temp directory,
+// resuming and retrying as configured in general.download. The path is fixed
+// so a download interrupted in an earlier run resumes from its .part file.
+func downloadRepoSetup() (string, error) {
+	script := filepath.Join(tempdir.Dir(), "mariadb_repo_setup.sh")
+	if err := download.File(shutdown.Context(), repoSetupURL, script, download.Options{Label: "mariadb_repo_setup"}); err != nil {
+		return "", err
+	}
+	return script, nil
+}
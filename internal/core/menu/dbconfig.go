@@ -4,19 +4,20 @@ import (
 	"sfDBTools/cmd/dbconfig_cmd"
 	"sfDBTools/internal/config/model"
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	"sfDBTools/utils/terminal"
 )
 
 func DBConfigMenu(lg *logger.Logger, cfg *model.Config) {
-	terminal.Headers("Manajemen Konfigurasi DB")
-	choice, err := terminal.ShowMenuAndClear("Pilih Menu : ", []string{
-		"Buat Konfigurasi DB",
-		"Edit Konfigurasi DB",
-		"Hapus Konfigurasi DB",
-		"Validasi Koneksi DB",
-		"Lihat Konfigurasi DB",
-		"Menu utama",
-		"Keluar",
+	terminal.Headers(i18n.T("menu.dbconfig.title"))
+	choice, err := terminal.ShowMenuAndClear(i18n.T("menu.choose"), []string{
+		i18n.T("menu.dbconfig.create"),
+		i18n.T("menu.dbconfig.edit"),
+		i18n.T("menu.dbconfig.delete"),
+		i18n.T("menu.dbconfig.validate"),
+		i18n.T("menu.dbconfig.show"),
+		i18n.T("menu.back_to_main"),
+		i18n.T("menu.exit"),
 	})
 	if err != nil {
 		lg.Error("Menu error", logger.Error(err))
@@ -4,19 +4,20 @@ import (
 	"sfDBTools/cmd/mariadb_cmd"
 	"sfDBTools/internal/config/model"
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	"sfDBTools/utils/terminal"
 )
 
 func MariaDBMenu(lg *logger.Logger, cfg *model.Config) {
-	terminal.Headers("Menu Instalasi MariaDB")
-	choice, err := terminal.ShowMenuAndClear("Pilih Menu : ", []string{
-		"Install MariaDB",
-		"Hapus MariaDB",
-		"Modifikasi Konfigurasi MariaDB",
-		"Check Status MariaDB",
-		"Check Versi (Online)",
-		"Menu utama",
-		"Keluar",
+	terminal.Headers(i18n.T("menu.mariadb.title"))
+	choice, err := terminal.ShowMenuAndClear(i18n.T("menu.choose"), []string{
+		i18n.T("menu.mariadb.install"),
+		i18n.T("menu.mariadb.remove"),
+		i18n.T("menu.mariadb.configure"),
+		i18n.T("menu.mariadb.status"),
+		i18n.T("menu.mariadb.versions"),
+		i18n.T("menu.back_to_main"),
+		i18n.T("menu.exit"),
 	})
 	if err != nil {
 		lg.Error("Menu error", logger.Error(err))
@@ -4,18 +4,19 @@ import (
 	"fmt"
 	"sfDBTools/internal/config/model"
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 	"sfDBTools/utils/terminal"
 )
 
 func MenuUtama(lg *logger.Logger, cfg *model.Config) {
-	terminal.Headers("Menu Utama")
-	choice, err := terminal.ShowMenuAndClear("Pilih Menu : ", []string{
-		"Menu Konfigurasi DB",
-		"Menu Instalasi MariDB",
-		"Menu Backup",
-		"Menu Restore",
-		"Menu Backup & Restore",
-		"Keluar",
+	terminal.Headers(i18n.T("menu.main.title"))
+	choice, err := terminal.ShowMenuAndClear(i18n.T("menu.choose"), []string{
+		i18n.T("menu.main.dbconfig"),
+		i18n.T("menu.main.mariadb"),
+		i18n.T("menu.main.backup"),
+		i18n.T("menu.main.restore"),
+		i18n.T("menu.main.backup_restore"),
+		i18n.T("menu.exit"),
 	})
 	if err != nil {
 		lg.Error("Menu error", logger.Error(err))
@@ -31,20 +32,20 @@ func MenuUtama(lg *logger.Logger, cfg *model.Config) {
 		return
 	case 3:
 		lg.Info("Selected: Menu Backup")
-		fmt.Println("Fungsi Backup Database belum diimplementasikan.")
-		terminal.WaitForEnterWithMessage("Tekan Enter untuk kembali ke menu utama...")
+		fmt.Println(i18n.T("menu.main.backup_missing"))
+		terminal.WaitForEnterWithMessage(i18n.T("menu.press_enter_main"))
 		MenuUtama(lg, cfg)
 		return
 	case 4:
 		lg.Info("Selected: Menu Restore")
-		fmt.Println("Fungsi Restore Database belum diimplementasikan.")
-		terminal.WaitForEnterWithMessage("Tekan Enter untuk kembali ke menu utama...")
+		fmt.Println(i18n.T("menu.main.restore_missing"))
+		terminal.WaitForEnterWithMessage(i18n.T("menu.press_enter_main"))
 		MenuUtama(lg, cfg)
 		return
 	case 5:
 		lg.Info("Selected: Menu Backup & Restore")
-		fmt.Println("Fungsi Backup & Restore Database belum diimplementasikan.")
-		terminal.WaitForEnterWithMessage("Tekan Enter untuk kembali ke menu utama...")
+		fmt.Println(i18n.T("menu.main.backup_restore_missing"))
+		terminal.WaitForEnterWithMessage(i18n.T("menu.press_enter_main"))
 		MenuUtama(lg, cfg)
 		return
 	case 6:
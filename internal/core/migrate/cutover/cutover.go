@@ -0,0 +1,139 @@
+package cutover
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/jobstatus"
+)
+
+// Run executes the cutover sequence: freeze the source, wait for the target to catch up,
+// switch the proxy (or print instructions) and verify new writes land on the target.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	job := jobstatus.Start("migrate-cutover", fmt.Sprintf("%s:%d -> %s:%d", opts.Source.Host, opts.Source.Port, opts.Target.Host, opts.Target.Port))
+	result, err := run(ctx, opts, job)
+	if result != nil {
+		job.Detail("Proxy", string(opts.Proxy.Type))
+		job.Detail("Databases", strings.Join(opts.Databases, ", "))
+		for _, step := range result.Steps {
+			job.Detail(step.Name, step.Message)
+		}
+	}
+	job.Finish(err)
+	return result, err
+}
+
+func run(ctx context.Context, opts Options, job *jobstatus.Tracker) (*Result, error) {
+	lg, err := logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logger: %w", err)
+	}
+
+	result := &Result{StartTime: time.Now()}
+
+	lg.Info("Starting migration cutover",
+		logger.String("source", fmt.Sprintf("%s:%d", opts.Source.Host, opts.Source.Port)),
+		logger.String("target", fmt.Sprintf("%s:%d", opts.Target.Host, opts.Target.Port)),
+		logger.String("proxy", string(opts.Proxy.Type)),
+		logger.Strings("databases", opts.Databases))
+
+	sourceFrozen := false
+
+	// Step 1: set source read-only
+	if !opts.SkipReadOnly {
+		err := runStep(result, job, "Set source read-only", func() error {
+			return database.SetGlobalReadOnly(opts.Source, true)
+		})
+		if err != nil {
+			return finish(result), err
+		}
+		sourceFrozen = true
+	}
+
+	var restoreProxy func() error
+	rollback := func() {
+		if !opts.RollbackOnErr {
+			return
+		}
+		// Undo in reverse order: traffic back to the source, then writes
+		if restoreProxy != nil {
+			lg.Warn("Rolling back: switching traffic back to source")
+			if err := restoreProxy(); err != nil {
+				lg.Error("Failed to restore the proxy", logger.Error(err))
+			}
+		}
+		if sourceFrozen {
+			lg.Warn("Rolling back: re-enabling writes on source")
+			if err := database.SetGlobalReadOnly(opts.Source, false); err != nil {
+				lg.Error("Failed to re-enable writes on source", logger.Error(err))
+			}
+		}
+	}
+
+	// Step 2: wait for replication / verification
+	if err := runStep(result, job, "Wait for target sync", func() error {
+		return waitForSync(ctx, opts, lg)
+	}); err != nil {
+		rollback()
+		return finish(result), err
+	}
+
+	// Step 3: switch proxy or print instructions
+	if err := runStep(result, job, "Switch application traffic", func() error {
+		var err error
+		restoreProxy, err = switchProxy(ctx, opts, lg)
+		return err
+	}); err != nil {
+		rollback()
+		return finish(result), err
+	}
+
+	// Step 4: verify writes land on target. Without a traffic endpoint the
+	// marker is written to the target itself, which does not prove routing.
+	if opts.VerifyWrites {
+		name := "Verify writes on target"
+		if opts.Proxy.TrafficHost == "" {
+			name = "Verify direct target write"
+		}
+		if err := runStep(result, job, name, func() error {
+			return verifyWritesOnTarget(ctx, opts, lg)
+		}); err != nil {
+			rollback()
+			return finish(result), err
+		}
+	}
+
+	result.Success = true
+	finish(result)
+	lg.Info("Migration cutover completed", logger.String("duration", result.Duration.String()))
+	return result, nil
+}
+
+// runStep executes a single step and records its outcome
+func runStep(result *Result, job *jobstatus.Tracker, name string, fn func() error) error {
+	lg, _ := logger.Get()
+	start := time.Now()
+	job.SetStep(name)
+	lg.Info("Cutover step started", logger.String("step", name))
+
+	err := fn()
+	step := StepResult{Name: name, Success: err == nil, Duration: time.Since(start)}
+	if err != nil {
+		step.Message = err.Error()
+		lg.Error("Cutover step failed", logger.String("step", name), logger.Error(err))
+	} else {
+		step.Message = "ok"
+		lg.Info("Cutover step completed", logger.String("step", name), logger.String("duration", step.Duration.String()))
+	}
+	result.Steps = append(result.Steps, step)
+	return err
+}
+
+func finish(result *Result) *Result {
+	result.Duration = time.Since(result.StartTime)
+	return result
+}
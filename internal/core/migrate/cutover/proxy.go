@@ -0,0 +1,196 @@
+package cutover
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database/connection"
+	"sfDBTools/utils/maxscale"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// switchProxy repoints the proxy in front of the databases from source to target.
+// When no proxy is configured, manual DNS/connection-string instructions are printed.
+// The returned function puts the proxy back the way it was found; it is nil when
+// nothing was changed. The rollback does not use ctx, as it has to run after a
+// cancelled cutover too.
+func switchProxy(ctx context.Context, opts Options, lg *logger.Logger) (func() error, error) {
+	switch opts.Proxy.Type {
+	case ProxyProxySQL:
+		return switchProxySQL(ctx, opts, lg)
+	case ProxyMaxScale:
+		return switchMaxScale(opts, lg)
+	default:
+		printManualInstructions(opts)
+		return nil, nil
+	}
+}
+
+// proxySQLStatement is a ProxySQL admin statement with its arguments
+type proxySQLStatement struct {
+	query string
+	args  []interface{}
+}
+
+// openProxySQLAdmin connects to the ProxySQL admin interface
+func openProxySQLAdmin(ctx context.Context, p ProxyOptions) (*sql.DB, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = p.User
+	cfg.Passwd = p.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+	db, err := connection.Open(fmt.Sprintf("proxysql-admin(%s:%d)", p.Host, p.Port), cfg.FormatDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ProxySQL admin connection: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to ProxySQL admin at %s:%d: %w", p.Host, p.Port, err)
+	}
+	return db, nil
+}
+
+// proxySQLStatus returns the status of host:port in the hostgroup, or "" when
+// the server is not in it
+func proxySQLStatus(ctx context.Context, db *sql.DB, hostGroup int, host string, port int) (string, error) {
+	var status string
+	err := db.QueryRowContext(ctx, "SELECT status FROM mysql_servers WHERE hostgroup_id = ? AND hostname = ? AND port = ?",
+		hostGroup, host, port).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return status, err
+}
+
+// execProxySQL runs admin statements and loads the result to runtime and disk
+func execProxySQL(ctx context.Context, db *sql.DB, statements []proxySQLStatement) error {
+	statements = append(statements,
+		proxySQLStatement{"LOAD MYSQL SERVERS TO RUNTIME", nil},
+		proxySQLStatement{"SAVE MYSQL SERVERS TO DISK", nil})
+	for _, stmt := range statements {
+		if _, err := db.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return fmt.Errorf("ProxySQL admin statement failed (%s): %w", stmt.query, err)
+		}
+	}
+	return nil
+}
+
+// switchProxySQL updates the writer hostgroup through the ProxySQL admin interface
+func switchProxySQL(ctx context.Context, opts Options, lg *logger.Logger) (func() error, error) {
+	p := opts.Proxy
+	db, err := openProxySQLAdmin(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	sourceStatus, err := proxySQLStatus(ctx, db, p.HostGroup, opts.Source.Host, opts.Source.Port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ProxySQL state of the source: %w", err)
+	}
+	targetStatus, err := proxySQLStatus(ctx, db, p.HostGroup, opts.Target.Host, opts.Target.Port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ProxySQL state of the target: %w", err)
+	}
+
+	restore := func() error {
+		db, err := openProxySQLAdmin(context.Background(), p)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		var statements []proxySQLStatement
+		if sourceStatus != "" {
+			statements = append(statements, proxySQLStatement{"UPDATE mysql_servers SET status = ? WHERE hostgroup_id = ? AND hostname = ? AND port = ?",
+				[]interface{}{sourceStatus, p.HostGroup, opts.Source.Host, opts.Source.Port}})
+		}
+		if targetStatus == "" {
+			statements = append(statements, proxySQLStatement{"DELETE FROM mysql_servers WHERE hostgroup_id = ? AND hostname = ? AND port = ?",
+				[]interface{}{p.HostGroup, opts.Target.Host, opts.Target.Port}})
+		} else {
+			statements = append(statements, proxySQLStatement{"UPDATE mysql_servers SET status = ? WHERE hostgroup_id = ? AND hostname = ? AND port = ?",
+				[]interface{}{targetStatus, p.HostGroup, opts.Target.Host, opts.Target.Port}})
+		}
+		return execProxySQL(context.Background(), db, statements)
+	}
+
+	err = execProxySQL(ctx, db, []proxySQLStatement{
+		{"UPDATE mysql_servers SET status = 'OFFLINE_SOFT' WHERE hostgroup_id = ? AND hostname = ? AND port = ?",
+			[]interface{}{p.HostGroup, opts.Source.Host, opts.Source.Port}},
+		{"REPLACE INTO mysql_servers (hostgroup_id, hostname, port, status) VALUES (?, ?, ?, 'ONLINE')",
+			[]interface{}{p.HostGroup, opts.Target.Host, opts.Target.Port}},
+	})
+	if err != nil {
+		// Part of the change may already be in the admin tables
+		return restore, err
+	}
+
+	lg.Info("ProxySQL writer hostgroup switched to target",
+		logger.Int("hostgroup", p.HostGroup),
+		logger.String("source", fmt.Sprintf("%s:%d", opts.Source.Host, opts.Source.Port)),
+		logger.String("target", fmt.Sprintf("%s:%d", opts.Target.Host, opts.Target.Port)))
+	return restore, nil
+}
+
+// switchMaxScale puts the source server in maintenance and releases the target via the REST API
+func switchMaxScale(opts Options, lg *logger.Logger) (func() error, error) {
+	p := opts.Proxy
+	if p.SourceServer == "" || p.TargetServer == "" {
+		return nil, fmt.Errorf("MaxScale cutover requires both source and target server names")
+	}
+
+	client := maxscale.NewClient(p.Host, p.Port, p.User, p.Password)
+	servers, err := client.ListServers()
+	if err != nil {
+		return nil, err
+	}
+	inMaintenance := make(map[string]bool)
+	for _, s := range servers {
+		inMaintenance[s.Name] = strings.Contains(strings.ToLower(s.State), "maintenance")
+	}
+
+	restore := func() error {
+		if !inMaintenance[p.SourceServer] {
+			if err := client.ClearServerState(p.SourceServer, "maintenance"); err != nil {
+				return err
+			}
+		}
+		if inMaintenance[p.TargetServer] {
+			return client.SetServerState(p.TargetServer, "maintenance")
+		}
+		return nil
+	}
+
+	if err := client.ClearServerState(p.TargetServer, "maintenance"); err != nil {
+		return nil, err
+	}
+	if err := client.SetServerState(p.SourceServer, "maintenance"); err != nil {
+		return restore, err
+	}
+
+	lg.Info("MaxScale servers switched",
+		logger.String("maintenance", p.SourceServer),
+		logger.String("active", p.TargetServer))
+	return restore, nil
+}
+
+// printManualInstructions shows what must be changed when no proxy is managed by sfDBTools
+func printManualInstructions(opts Options) {
+	fmt.Println("\n📋 Manual cutover instructions")
+	fmt.Println("==============================")
+	fmt.Printf("No proxy configured. Point application traffic to the target server:\n")
+	fmt.Printf("  Old endpoint: %s:%d\n", opts.Source.Host, opts.Source.Port)
+	fmt.Printf("  New endpoint: %s:%d\n", opts.Target.Host, opts.Target.Port)
+	fmt.Println("\nOptions:")
+	fmt.Printf("  • Update the DNS record currently resolving to %s so it points to %s (lower TTL first)\n", opts.Source.Host, opts.Target.Host)
+	fmt.Printf("  • Or update application connection strings, e.g. jdbc:mariadb://%s:%d/<database>\n", opts.Target.Host, opts.Target.Port)
+	fmt.Println("  • Restart or reload application connection pools so stale connections are dropped")
+	fmt.Println()
+}
@@ -0,0 +1,61 @@
+package cutover
+
+import (
+	"time"
+
+	"sfDBTools/utils/database"
+)
+
+// ProxyType identifies how application traffic is switched to the target
+type ProxyType string
+
+const (
+	ProxyNone     ProxyType = "none"
+	ProxyProxySQL ProxyType = "proxysql"
+	ProxyMaxScale ProxyType = "maxscale"
+)
+
+// ProxyOptions holds admin interface details for the proxy in front of the databases
+type ProxyOptions struct {
+	Type     ProxyType
+	Host     string
+	Port     int
+	User     string
+	Password string
+	// HostGroup is the ProxySQL writer hostgroup to repoint
+	HostGroup int
+	// SourceServer and TargetServer are the MaxScale server object names
+	SourceServer string
+	TargetServer string
+	// TrafficHost/TrafficPort is the application-facing listener used to verify writes
+	TrafficHost string
+	TrafficPort int
+}
+
+// Options represents the configuration for a migration cutover
+type Options struct {
+	Source        database.Config
+	Target        database.Config
+	Databases     []string
+	Proxy         ProxyOptions
+	WaitTimeout   time.Duration
+	SkipReadOnly  bool
+	VerifyWrites  bool
+	RollbackOnErr bool
+}
+
+// StepResult records the outcome of an individual cutover step
+type StepResult struct {
+	Name     string
+	Success  bool
+	Message  string
+	Duration time.Duration
+}
+
+// Result represents the result of a cutover operation
+type Result struct {
+	Steps     []StepResult
+	StartTime time.Time
+	Duration  time.Duration
+	Success   bool
+}
@@ -0,0 +1,203 @@
+package cutover
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+)
+
+// waitForSync waits until the target has caught up with the source. When the target
+// replicates from the source, replication lag is polled; otherwise per-table row counts
+// are compared until they match, the timeout expires or ctx is cancelled.
+func waitForSync(ctx context.Context, opts Options, lg *logger.Logger) error {
+	tgtDB, err := database.GetWithoutDB(opts.Target)
+	if err != nil {
+		return fmt.Errorf("failed to connect to target: %w", err)
+	}
+	defer tgtDB.Close()
+
+	srcDB, err := database.GetWithoutDB(opts.Source)
+	if err != nil {
+		return fmt.Errorf("failed to connect to source: %w", err)
+	}
+	defer srcDB.Close()
+
+	deadline := time.Now().Add(opts.WaitTimeout)
+	isReplica := hasReplicaStatus(ctx, tgtDB)
+
+	for {
+		var synced bool
+		var detail string
+		if isReplica {
+			synced, detail = replicaCaughtUp(ctx, tgtDB)
+		} else {
+			synced, detail = rowCountsMatch(ctx, srcDB, tgtDB, opts.Databases)
+		}
+
+		if synced {
+			lg.Info("Target is in sync with source", logger.Bool("replica", isReplica))
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("target did not catch up within %s: %s", opts.WaitTimeout, detail)
+		}
+
+		lg.Info("Waiting for target to catch up", logger.String("detail", detail))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// hasReplicaStatus returns true when the server reports a replication channel
+func hasReplicaStatus(ctx context.Context, db *sql.DB) bool {
+	rows, err := db.QueryContext(ctx, "SHOW SLAVE STATUS")
+	if err != nil {
+		return false
+	}
+	defer rows.Close()
+	return rows.Next()
+}
+
+// replicaCaughtUp checks Seconds_Behind_Master and the SQL thread state
+func replicaCaughtUp(ctx context.Context, db *sql.DB) (bool, string) {
+	rows, err := db.QueryContext(ctx, "SHOW SLAVE STATUS")
+	if err != nil {
+		return false, err.Error()
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil || !rows.Next() {
+		return false, "replica status unavailable"
+	}
+
+	values := make([]sql.NullString, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return false, err.Error()
+	}
+
+	status := make(map[string]string, len(cols))
+	for i, c := range cols {
+		status[c] = values[i].String
+	}
+
+	if status["Slave_SQL_Running"] != "Yes" {
+		return false, "replica SQL thread is not running"
+	}
+	lag := status["Seconds_Behind_Master"]
+	if lag != "0" {
+		return false, fmt.Sprintf("replica lag %ss", lag)
+	}
+	if status["Read_Master_Log_Pos"] != status["Exec_Master_Log_Pos"] {
+		return false, "replica has unapplied relay log events"
+	}
+	return true, "replica caught up"
+}
+
+// rowCountsMatch compares exact row counts of every base table in the given databases
+func rowCountsMatch(ctx context.Context, srcDB, tgtDB *sql.DB, databases []string) (bool, string) {
+	for _, dbName := range databases {
+		rows, err := srcDB.QueryContext(ctx, `SELECT TABLE_NAME FROM information_schema.TABLES
+			WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'`, dbName)
+		if err != nil {
+			return false, err.Error()
+		}
+
+		var tables []string
+		for rows.Next() {
+			var t string
+			if err := rows.Scan(&t); err == nil {
+				tables = append(tables, t)
+			}
+		}
+		rows.Close()
+
+		for _, table := range tables {
+			query := "SELECT COUNT(*) FROM " + database.QuoteIdent(dbName) + "." + database.QuoteIdent(table)
+			var srcCount, tgtCount int64
+			if err := srcDB.QueryRowContext(ctx, query).Scan(&srcCount); err != nil {
+				return false, err.Error()
+			}
+			if err := tgtDB.QueryRowContext(ctx, query).Scan(&tgtCount); err != nil {
+				return false, fmt.Sprintf("%s.%s missing on target", dbName, table)
+			}
+			if srcCount != tgtCount {
+				return false, fmt.Sprintf("%s.%s source=%d target=%d", dbName, table, srcCount, tgtCount)
+			}
+		}
+	}
+	return true, "row counts match"
+}
+
+// verifyWritesOnTarget writes a marker row through the application path (the traffic
+// endpoint when one is given, otherwise the target itself) and confirms it is readable
+// on the target server
+func verifyWritesOnTarget(ctx context.Context, opts Options, lg *logger.Logger) error {
+	if len(opts.Databases) == 0 {
+		return fmt.Errorf("no database available for write verification")
+	}
+	dbName := opts.Databases[0]
+
+	targetCfg := opts.Target
+	targetCfg.DBName = dbName
+
+	writeCfg := targetCfg
+	if opts.Proxy.TrafficHost != "" {
+		writeCfg.Host = opts.Proxy.TrafficHost
+		writeCfg.Port = opts.Proxy.TrafficPort
+	}
+
+	marker := fmt.Sprintf("cutover-%d", time.Now().UnixNano())
+
+	targetDB, err := database.GetDatabaseConnection(targetCfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to target for write verification: %w", err)
+	}
+	defer targetDB.Close()
+
+	if _, err := targetDB.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS `_sfdbtools_cutover` (marker VARCHAR(64) PRIMARY KEY, created_at DATETIME NOT NULL)"); err != nil {
+		return fmt.Errorf("failed to create cutover marker table: %w", err)
+	}
+	// Dropped without ctx so the marker table goes away after a cancel too
+	defer targetDB.Exec("DROP TABLE IF EXISTS `_sfdbtools_cutover`")
+
+	writeDB, err := database.GetDatabaseConnection(writeCfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s:%d for write verification: %w", writeCfg.Host, writeCfg.Port, err)
+	}
+	defer writeDB.Close()
+
+	if _, err := writeDB.ExecContext(ctx, "INSERT INTO `_sfdbtools_cutover` (marker, created_at) VALUES (?, NOW())", marker); err != nil {
+		return fmt.Errorf("write rejected via %s:%d: %w", writeCfg.Host, writeCfg.Port, err)
+	}
+
+	// Read back directly from the target server to prove the write landed there
+	var found string
+	if err := targetDB.QueryRowContext(ctx, "SELECT marker FROM `_sfdbtools_cutover` WHERE marker = ?", marker).Scan(&found); err != nil {
+		return fmt.Errorf("marker not visible on target: %w", err)
+	}
+
+	// Source should reject writes now that it is read-only
+	if !opts.SkipReadOnly {
+		if ro, err := database.IsGlobalReadOnly(opts.Source); err == nil && !ro {
+			return fmt.Errorf("source %s:%d is still writable", opts.Source.Host, opts.Source.Port)
+		}
+	}
+
+	lg.Info("Write verification succeeded on target",
+		logger.String("database", dbName),
+		logger.String("marker", marker))
+	return nil
+}
@@ -0,0 +1,224 @@
+package partition
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sfDBTools/utils/database"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxValue is the description information_schema reports for a catch-all range partition
+const MaxValue = "MAXVALUE"
+
+// Partition is one partition of a partitioned table
+type Partition struct {
+	Name        string
+	Ordinal     int
+	Description string // upper bound for RANGE, value list for LIST, empty for HASH/KEY
+	Rows        int64
+	SizeBytes   int64
+}
+
+// Table is a partitioned table with its partitions in ordinal order
+type Table struct {
+	Schema     string
+	Name       string
+	Method     string // RANGE, RANGE COLUMNS, LIST, HASH, KEY, ...
+	Expression string
+	Partitions []Partition
+}
+
+// List returns the partitioned tables of schema, or only table when it is not empty
+func List(ctx context.Context, db *sql.DB, schema, table string) ([]Table, error) {
+	query := `SELECT TABLE_NAME, PARTITION_NAME, MIN(PARTITION_ORDINAL_POSITION),
+		MIN(PARTITION_METHOD), MIN(PARTITION_EXPRESSION), MIN(PARTITION_DESCRIPTION),
+		COALESCE(SUM(TABLE_ROWS), 0), COALESCE(SUM(DATA_LENGTH + INDEX_LENGTH), 0)
+		FROM information_schema.PARTITIONS
+		WHERE TABLE_SCHEMA = ? AND PARTITION_NAME IS NOT NULL`
+	args := []any{schema}
+	if table != "" {
+		query += " AND TABLE_NAME = ?"
+		args = append(args, table)
+	}
+	query += " GROUP BY TABLE_NAME, PARTITION_NAME ORDER BY TABLE_NAME, 3"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []Table
+	for rows.Next() {
+		var name string
+		var p Partition
+		var method, expr, desc sql.NullString
+		if err := rows.Scan(&name, &p.Name, &p.Ordinal, &method, &expr, &desc, &p.Rows, &p.SizeBytes); err != nil {
+			return nil, err
+		}
+		p.Description = desc.String
+		if n := len(tables); n == 0 || tables[n-1].Name != name {
+			tables = append(tables, Table{Schema: schema, Name: name, Method: method.String, Expression: expr.String})
+		}
+		t := &tables[len(tables)-1]
+		t.Partitions = append(t.Partitions, p)
+	}
+	return tables, rows.Err()
+}
+
+// boundKind says how the upper bounds of a range-partitioned table map to dates
+type boundKind int
+
+const (
+	boundDays boundKind = iota + 1 // RANGE (TO_DAYS(col))
+	boundUnix                      // RANGE (UNIX_TIMESTAMP(col))
+	boundYear                      // RANGE (YEAR(col))
+	boundDate                      // RANGE COLUMNS(col) on a DATE/DATETIME column
+)
+
+var (
+	funcExpr   = regexp.MustCompile("(?i)^\\s*(to_days|unix_timestamp|year)\\s*\\(\\s*`?(\\w+)`?\\s*\\)\\s*$")
+	columnExpr = regexp.MustCompile("^\\s*`?(\\w+)`?\\s*$")
+)
+
+// toDaysEpoch is TO_DAYS('1970-01-01')
+const toDaysEpoch = 719528
+
+// timeRange describes a table partitioned by date ranges
+type timeRange struct {
+	kind   boundKind
+	column string
+}
+
+// rangeOf reports how t is partitioned by time, or an error when rotation is not supported
+func rangeOf(t Table) (timeRange, error) {
+	switch strings.ToUpper(t.Method) {
+	case "RANGE":
+		m := funcExpr.FindStringSubmatch(t.Expression)
+		if m == nil {
+			break
+		}
+		kinds := map[string]boundKind{"to_days": boundDays, "unix_timestamp": boundUnix, "year": boundYear}
+		return timeRange{kind: kinds[strings.ToLower(m[1])], column: m[2]}, nil
+	case "RANGE COLUMNS":
+		if m := columnExpr.FindStringSubmatch(t.Expression); m != nil {
+			return timeRange{kind: boundDate, column: m[1]}, nil
+		}
+	}
+	return timeRange{}, fmt.Errorf("table %s is partitioned by %s(%s); only RANGE on TO_DAYS, UNIX_TIMESTAMP or YEAR and RANGE COLUMNS on a date column are supported",
+		t.Name, t.Method, t.Expression)
+}
+
+// boundTime converts an upper bound description to the wall-clock time it represents
+func (r timeRange) boundTime(desc string, loc *time.Location) (time.Time, error) {
+	if r.kind == boundDate {
+		value := strings.Trim(desc, "'")
+		for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("unsupported partition bound %s", desc)
+	}
+	n, err := strconv.ParseInt(desc, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unsupported partition bound %s", desc)
+	}
+	switch r.kind {
+	case boundDays:
+		return time.Date(1970, 1, 1, 0, 0, 0, 0, loc).AddDate(0, 0, int(n-toDaysEpoch)), nil
+	case boundUnix:
+		return time.Unix(n, 0).In(loc), nil
+	default:
+		return time.Date(int(n), 1, 1, 0, 0, 0, 0, loc), nil
+	}
+}
+
+// boundValue renders t as the VALUES LESS THAN expression for a new partition
+func (r timeRange) boundValue(t time.Time) string {
+	switch r.kind {
+	case boundDays:
+		return fmt.Sprintf("TO_DAYS('%s')", t.Format("2006-01-02"))
+	case boundUnix:
+		return fmt.Sprintf("UNIX_TIMESTAMP('%s')", t.Format("2006-01-02 15:04:05"))
+	case boundYear:
+		return strconv.Itoa(t.Year())
+	default:
+		return fmt.Sprintf("'%s'", t.Format("2006-01-02"))
+	}
+}
+
+// lowerBoundFilter renders a WHERE clause selecting rows at or above an existing bound
+func (r timeRange) lowerBoundFilter(desc string) string {
+	col := database.QuoteIdent(r.column)
+	switch r.kind {
+	case boundDays:
+		return fmt.Sprintf("%s >= FROM_DAYS(%s)", col, desc)
+	case boundUnix:
+		return fmt.Sprintf("%s >= FROM_UNIXTIME(%s)", col, desc)
+	case boundYear:
+		return fmt.Sprintf("%s >= MAKEDATE(%s, 1)", col, desc)
+	default:
+		return fmt.Sprintf("%s >= %s", col, desc)
+	}
+}
+
+// ranged is a data partition with its upper bound resolved to a time
+type ranged struct {
+	Partition
+	upper time.Time
+}
+
+// splitRanges separates the dated partitions from a trailing MAXVALUE partition
+func splitRanges(t Table, r timeRange, loc *time.Location) ([]ranged, *Partition, error) {
+	var data []ranged
+	var catchAll *Partition
+	for i, p := range t.Partitions {
+		if strings.EqualFold(p.Description, MaxValue) {
+			catchAll = &t.Partitions[i]
+			continue
+		}
+		upper, err := r.boundTime(p.Description, loc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("table %s partition %s: %w", t.Name, p.Name, err)
+		}
+		data = append(data, ranged{Partition: p, upper: upper})
+	}
+	return data, catchAll, nil
+}
+
+// currentIndex returns the index of the partition that receives rows for now,
+// or len(data) when now is past every dated partition
+func currentIndex(data []ranged, now time.Time) int {
+	for i, p := range data {
+		if now.Before(p.upper) {
+			return i
+		}
+	}
+	return len(data)
+}
+
+// RecentFilter returns a WHERE clause selecting the rows of the most recent
+// keep partitions of t (counting the one receiving rows now), for dumping
+// only recent data of large append-only tables. An empty clause means the
+// table has no more than keep partitions and should be dumped whole.
+func RecentFilter(t Table, keep int, now time.Time) (string, error) {
+	r, err := rangeOf(t)
+	if err != nil {
+		return "", err
+	}
+	data, _, err := splitRanges(t, r, now.Location())
+	if err != nil {
+		return "", err
+	}
+	// The lower bound of a partition is the upper bound of the one before it
+	cutoff := currentIndex(data, now) - keep
+	if keep <= 0 || cutoff < 0 {
+		return "", nil
+	}
+	return r.lowerBoundFilter(data[cutoff].Description), nil
+}
@@ -0,0 +1,204 @@
+package partition
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+)
+
+// Interval is the span covered by one range partition
+type Interval struct {
+	Years, Months, Days int
+}
+
+// Intervals accepted by ParseInterval
+var namedIntervals = map[string]Interval{
+	"day":   {Days: 1},
+	"week":  {Days: 7},
+	"month": {Months: 1},
+	"year":  {Years: 1},
+}
+
+// ParseInterval parses day, week, month or year; an empty string is the zero Interval
+func ParseInterval(s string) (Interval, error) {
+	if s == "" {
+		return Interval{}, nil
+	}
+	iv, ok := namedIntervals[strings.ToLower(s)]
+	if !ok {
+		return Interval{}, fmt.Errorf("invalid interval %q (use day, week, month or year)", s)
+	}
+	return iv, nil
+}
+
+func (iv Interval) isZero() bool { return iv == Interval{} }
+
+func (iv Interval) after(t time.Time) time.Time { return t.AddDate(iv.Years, iv.Months, iv.Days) }
+
+// nameLayout picks the partition name format for the interval
+func (iv Interval) nameLayout() string {
+	switch {
+	case iv.Days > 0:
+		return "p20060102"
+	case iv.Months > 0:
+		return "p200601"
+	default:
+		return "p2006"
+	}
+}
+
+// inferInterval derives the partition span from the last two dated partitions
+func inferInterval(data []ranged) (Interval, error) {
+	if len(data) < 2 {
+		return Interval{}, fmt.Errorf("cannot infer the partition interval from fewer than two range partitions; set --interval")
+	}
+	a, b := data[len(data)-2].upper, data[len(data)-1].upper
+	if a.Day() == 1 && b.Day() == 1 && a.Hour() == 0 && b.Hour() == 0 {
+		months := (b.Year()-a.Year())*12 + int(b.Month()-a.Month())
+		if months%12 == 0 && a.Month() == time.January {
+			return Interval{Years: months / 12}, nil
+		}
+		if months > 0 {
+			return Interval{Months: months}, nil
+		}
+	}
+	if days := int(b.Sub(a).Hours() / 24); days > 0 && a.AddDate(0, 0, days).Equal(b) {
+		return Interval{Days: days}, nil
+	}
+	return Interval{}, fmt.Errorf("cannot infer the partition interval from %s and %s; set --interval",
+		data[len(data)-2].Name, data[len(data)-1].Name)
+}
+
+// RotateOptions is the retention policy applied by PlanRotation
+type RotateOptions struct {
+	Keep     int      // partitions to keep up to and including the current one; 0 never drops
+	Ahead    int      // empty partitions to keep ready after the current one
+	Interval Interval // span of a new partition; inferred from existing bounds when zero
+}
+
+// NewPartition is a range partition to be created
+type NewPartition struct {
+	Name  string
+	Upper time.Time
+	Value string // VALUES LESS THAN expression
+}
+
+// Plan lists the changes that bring a table in line with a rotation policy
+type Plan struct {
+	Table      Table
+	Current    string // partition receiving rows now, empty when it is the MAXVALUE partition
+	Add        []NewPartition
+	Drop       []Partition
+	Statements []string
+}
+
+// PlanRotation computes which partitions of t to create and drop at now
+func PlanRotation(t Table, opts RotateOptions, now time.Time) (*Plan, error) {
+	r, err := rangeOf(t)
+	if err != nil {
+		return nil, err
+	}
+	data, catchAll, err := splitRanges(t, r, now.Location())
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("table %s has no dated range partitions", t.Name)
+	}
+
+	plan := &Plan{Table: t}
+	current := currentIndex(data, now)
+	if current < len(data) {
+		plan.Current = data[current].Name
+	}
+
+	if opts.Ahead > 0 {
+		iv := opts.Interval
+		if iv.isZero() {
+			if iv, err = inferInterval(data); err != nil {
+				return nil, err
+			}
+		}
+		existing := make(map[string]bool, len(t.Partitions))
+		for _, p := range t.Partitions {
+			existing[strings.ToLower(p.Name)] = true
+		}
+		// The newest partition must end after now plus Ahead spans
+		horizon := now
+		for i := 0; i < opts.Ahead; i++ {
+			horizon = iv.after(horizon)
+		}
+		lower := data[len(data)-1].upper
+		for !horizon.Before(lower) {
+			upper := iv.after(lower)
+			name := lower.Format(iv.nameLayout())
+			if existing[strings.ToLower(name)] {
+				return nil, fmt.Errorf("table %s already has a partition named %s", t.Name, name)
+			}
+			plan.Add = append(plan.Add, NewPartition{Name: name, Upper: upper, Value: r.boundValue(upper)})
+			lower = upper
+		}
+	}
+
+	// Partitions before the current one are expired once more than Keep-1 of them exist
+	if opts.Keep > 0 {
+		if expired := current - (opts.Keep - 1); expired > 0 {
+			for _, p := range data[:expired] {
+				plan.Drop = append(plan.Drop, p.Partition)
+			}
+		}
+		if len(plan.Drop) == len(t.Partitions) {
+			return nil, fmt.Errorf("refusing to drop every partition of %s", t.Name)
+		}
+	}
+
+	plan.Statements = statements(t, plan, catchAll)
+	return plan, nil
+}
+
+// statements renders the ALTER TABLE statements for a plan
+func statements(t Table, plan *Plan, catchAll *Partition) []string {
+	table := database.QuoteIdent(t.Schema) + "." + database.QuoteIdent(t.Name)
+	var stmts []string
+	if len(plan.Drop) > 0 {
+		names := make([]string, len(plan.Drop))
+		for i, p := range plan.Drop {
+			names[i] = database.QuoteIdent(p.Name)
+		}
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s DROP PARTITION %s", table, strings.Join(names, ", ")))
+	}
+	if len(plan.Add) > 0 {
+		defs := make([]string, 0, len(plan.Add)+1)
+		for _, p := range plan.Add {
+			defs = append(defs, fmt.Sprintf("PARTITION %s VALUES LESS THAN (%s)", database.QuoteIdent(p.Name), p.Value))
+		}
+		if catchAll != nil {
+			// New ranges must be split off the MAXVALUE partition
+			defs = append(defs, fmt.Sprintf("PARTITION %s VALUES LESS THAN (MAXVALUE)", database.QuoteIdent(catchAll.Name)))
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s REORGANIZE PARTITION %s INTO (%s)",
+				table, database.QuoteIdent(catchAll.Name), strings.Join(defs, ", ")))
+		} else {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD PARTITION (%s)", table, strings.Join(defs, ", ")))
+		}
+	}
+	return stmts
+}
+
+// Apply runs the statements of a plan in order
+func Apply(ctx context.Context, db *sql.DB, plan *Plan) error {
+	lg, _ := logger.Get()
+	for _, stmt := range plan.Statements {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to rotate partitions of %s: %w", plan.Table.Name, err)
+		}
+		lg.Info("Partition rotation statement applied",
+			logger.String("table", plan.Table.Name),
+			logger.String("statement", stmt))
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/fs/policy"
+	"sfDBTools/utils/jobstatus"
+)
+
+// BackupOptions selects the databases to dump. Each database is written with
+// pg_dump in the custom format (compressed, restorable in parallel with
+// pg_restore -j); roles and tablespaces go to a separate globals file.
+type BackupOptions struct {
+	Conn      Conn
+	Databases []string // empty backs up every database
+	OutputDir string
+	Globals   bool // also dump roles and tablespaces with pg_dumpall --globals-only
+}
+
+// BackupFile is one written dump
+type BackupFile struct {
+	Database string // empty for the globals file
+	Path     string
+	Size     int64
+	Checksum string // sha256, also written next to the dump as <file>.sha256
+}
+
+// BackupResult lists the written dumps and the databases that failed
+type BackupResult struct {
+	Files  []BackupFile
+	Failed map[string]error
+}
+
+// dumpSuffix matches the timestamp Backup appends to a dump name
+var dumpSuffix = regexp.MustCompile(`_\d{8}_\d{6}$`)
+
+// DatabaseFromDump derives the database name from a dump written by Backup,
+// e.g. sales_20250101_020000.dump is sales
+func DatabaseFromDump(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return dumpSuffix.ReplaceAllString(name, "")
+}
+
+// Backup dumps the selected databases. A failing database does not stop the
+// others; it is reported in Failed and makes Backup return an error.
+func Backup(opts BackupOptions) (result *BackupResult, err error) {
+	lg, _ := logger.Get()
+	job := jobstatus.Start("postgres-backup", opts.Conn.String())
+	defer func() { job.Finish(err) }()
+
+	databases := opts.Databases
+	if len(databases) == 0 {
+		job.SetStep("listing databases")
+		if databases, err = opts.Conn.ListDatabases(); err != nil {
+			return nil, fmt.Errorf("failed to list databases: %w", err)
+		}
+	}
+	if len(databases) == 0 {
+		return nil, fmt.Errorf("no databases to back up on %s", opts.Conn)
+	}
+	if err := policy.MkdirAll(opts.OutputDir); err != nil {
+		return nil, fmt.Errorf("failed to create output directory %s: %w", opts.OutputDir, err)
+	}
+
+	stamp := common.LocalNow().Format("20060102_150405")
+	result = &BackupResult{Failed: make(map[string]error)}
+
+	if opts.Globals {
+		job.SetStep("dumping globals")
+		path := filepath.Join(opts.OutputDir, "globals_"+stamp+".sql")
+		file, err := write(path, func(tmp string) error {
+			return opts.Conn.run("pg_dumpall", "--globals-only", "-f", tmp)
+		})
+		if err != nil {
+			result.Failed["(globals)"] = err
+		} else {
+			result.Files = append(result.Files, *file)
+		}
+	}
+
+	for i, db := range databases {
+		job.SetStep(fmt.Sprintf("dumping %s (%d/%d)", db, i+1, len(databases)))
+		start := time.Now()
+		path := filepath.Join(opts.OutputDir, db+"_"+stamp+".dump")
+		file, err := write(path, func(tmp string) error {
+			return opts.Conn.run("pg_dump", "-Fc", "-d", db, "-f", tmp)
+		})
+		if err != nil {
+			lg.Error("PostgreSQL database backup failed", logger.String("database", db), logger.Error(err))
+			result.Failed[db] = err
+			continue
+		}
+		file.Database = db
+		result.Files = append(result.Files, *file)
+		lg.Info("PostgreSQL database backed up",
+			logger.String("database", db),
+			logger.String("file", path),
+			logger.Int64("size", file.Size),
+			logger.String("duration", time.Since(start).Round(time.Millisecond).String()))
+	}
+
+	if len(result.Failed) > 0 {
+		return result, fmt.Errorf("%d of %d backups failed", len(result.Failed), len(databases))
+	}
+	job.Detail("Files", fmt.Sprintf("%d", len(result.Files)))
+	return result, nil
+}
+
+// write runs dump into a temporary file next to path, then renames it into
+// place and writes the checksum file, so an interrupted dump never looks
+// complete
+func write(path string, dump func(tmp string) error) (*BackupFile, error) {
+	tmp := path + ".partial"
+	if err := dump(tmp); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to finalize %s: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := fs.FileChecksum(path, "sha256")
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum %s: %w", path, err)
+	}
+	if err := policy.WriteFile(path+".sha256", []byte(sum+"  "+filepath.Base(path)+"\n")); err != nil {
+		return nil, fmt.Errorf("failed to write checksum of %s: %w", path, err)
+	}
+	return &BackupFile{Path: path, Size: info.Size(), Checksum: sum}, nil
+}
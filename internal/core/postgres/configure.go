@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/system"
+)
+
+// ConfigureOptions lists the server settings to apply. Settings are written
+// with ALTER SYSTEM (postgresql.auto.conf), so postgresql.conf itself is
+// never edited.
+type ConfigureOptions struct {
+	Conn     Conn
+	Settings map[string]string
+	// ServiceName is restarted when a setting only takes effect at server
+	// start; empty skips the restart and reports it instead
+	ServiceName string
+	DryRun      bool
+}
+
+// SettingChange is one setting whose value differs from the requested one
+type SettingChange struct {
+	Name    string
+	Current string // as shown by SHOW, with unit
+	New     string
+	Restart bool // only applied by a server restart
+}
+
+// ConfigureResult reports what Configure changed
+type ConfigureResult struct {
+	Changes   []SettingChange
+	Restarted bool
+	// RestartPending is set when a restart is needed but no service was given
+	RestartPending bool
+}
+
+// RecommendSettings returns memory settings sized from the host's RAM, the
+// usual starting point for a dedicated server: a quarter of RAM for
+// shared_buffers and three quarters as the planner's cache estimate
+func RecommendSettings(maxConnections int) (map[string]string, error) {
+	hw, err := system.GetHardwareInfo()
+	if err != nil {
+		return nil, err
+	}
+	if maxConnections <= 0 {
+		maxConnections = 100
+	}
+	ramMB := hw.TotalRAMMB
+	workMem := ramMB / 4 / maxConnections
+	if workMem < 4 {
+		workMem = 4
+	}
+	maintenance := ramMB / 16
+	if maintenance > 2048 {
+		maintenance = 2048
+	}
+	return map[string]string{
+		"shared_buffers":       fmt.Sprintf("%dMB", ramMB/4),
+		"effective_cache_size": fmt.Sprintf("%dMB", ramMB*3/4),
+		"maintenance_work_mem": fmt.Sprintf("%dMB", maintenance),
+		"work_mem":             fmt.Sprintf("%dMB", workMem),
+		"max_worker_processes": fmt.Sprintf("%d", hw.CPUCores),
+		"max_parallel_workers": fmt.Sprintf("%d", hw.CPUCores),
+	}, nil
+}
+
+// PlanSettings compares the requested settings with the server and returns
+// those that would change. Unknown setting names are an error.
+func PlanSettings(conn Conn, settings map[string]string) ([]SettingChange, error) {
+	names := make([]string, 0, len(settings))
+	quoted := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+		quoted = append(quoted, QuoteLiteral(name))
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	rows, err := conn.Query("postgres", "SELECT name, setting, COALESCE(unit, ''), context FROM pg_settings WHERE name IN ("+strings.Join(quoted, ", ")+")")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server settings: %w", err)
+	}
+	type current struct{ value, context string }
+	known := make(map[string]current, len(rows))
+	for _, row := range rows {
+		f := strings.SplitN(row, "|", 4)
+		if len(f) != 4 {
+			continue
+		}
+		known[f[0]] = current{value: withUnit(f[1], f[2]), context: f[3]}
+	}
+
+	var changes []SettingChange
+	for _, name := range names {
+		cur, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown PostgreSQL setting %q", name)
+		}
+		want := settings[name]
+		if strings.EqualFold(normalizeMemory(cur.value), normalizeMemory(want)) {
+			continue
+		}
+		changes = append(changes, SettingChange{Name: name, Current: cur.value, New: want, Restart: cur.context == "postmaster"})
+	}
+	return changes, nil
+}
+
+// Configure applies the settings that differ, then reloads the server, or
+// restarts it when a changed setting needs a restart
+func Configure(opts ConfigureOptions) (*ConfigureResult, error) {
+	lg, _ := logger.Get()
+	changes, err := PlanSettings(opts.Conn, opts.Settings)
+	if err != nil {
+		return nil, err
+	}
+	result := &ConfigureResult{Changes: changes}
+	if len(changes) == 0 || opts.DryRun {
+		return result, nil
+	}
+
+	restart := false
+	for _, c := range changes {
+		// ALTER SYSTEM cannot run inside a transaction block, so one statement per call
+		if err := opts.Conn.Exec("postgres", fmt.Sprintf("ALTER SYSTEM SET %s = %s", QuoteIdent(c.Name), QuoteLiteral(c.New))); err != nil {
+			return result, fmt.Errorf("failed to set %s: %w", c.Name, err)
+		}
+		lg.Info("PostgreSQL setting changed",
+			logger.String("setting", c.Name),
+			logger.String("from", c.Current),
+			logger.String("to", c.New))
+		restart = restart || c.Restart
+	}
+
+	if !restart {
+		if err := opts.Conn.Exec("postgres", "SELECT pg_reload_conf()"); err != nil {
+			return result, fmt.Errorf("failed to reload configuration: %w", err)
+		}
+		return result, nil
+	}
+	if opts.ServiceName == "" {
+		result.RestartPending = true
+		return result, nil
+	}
+	if err := system.NewServiceManager().Restart(opts.ServiceName); err != nil {
+		return result, err
+	}
+	result.Restarted = true
+	return result, nil
+}
+
+// withUnit renders a pg_settings value like SHOW does for the common memory
+// units, so "16384" with unit "8kB" compares equal to "128MB"
+func withUnit(setting, unit string) string {
+	var n int64
+	if _, err := fmt.Sscan(setting, &n); err != nil || unit == "" {
+		return setting
+	}
+	var kb int64
+	switch unit {
+	case "8kB":
+		kb = n * 8
+	case "kB":
+		kb = n
+	case "MB":
+		kb = n * 1024
+	default:
+		return setting + unit
+	}
+	return formatKB(kb)
+}
+
+// normalizeMemory rewrites a memory value such as 4096MB as formatKB does
+// (4GB); other values are returned unchanged
+func normalizeMemory(value string) string {
+	var n int64
+	var unit string
+	if _, err := fmt.Sscanf(value, "%d%s", &n, &unit); err != nil {
+		return value
+	}
+	switch strings.ToUpper(unit) {
+	case "KB":
+		return formatKB(n)
+	case "MB":
+		return formatKB(n * 1024)
+	case "GB":
+		return formatKB(n * 1024 * 1024)
+	}
+	return value
+}
+
+// formatKB renders kilobytes in the largest whole unit
+func formatKB(kb int64) string {
+	switch {
+	case kb%(1024*1024) == 0 && kb > 0:
+		return fmt.Sprintf("%dGB", kb/(1024*1024))
+	case kb%1024 == 0 && kb > 0:
+		return fmt.Sprintf("%dMB", kb/1024)
+	}
+	return fmt.Sprintf("%dkB", kb)
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/system"
+	"sfDBTools/utils/terminal"
+)
+
+// InstallOptions selects what Install sets up. An empty Version installs the
+// distribution's PostgreSQL; a major version (e.g. "16") installs the PGDG
+// packages, whose repository must already be configured.
+type InstallOptions struct {
+	Version string
+}
+
+// Layout is where a PostgreSQL installation lives on this OS
+type Layout struct {
+	Packages    []string
+	ServiceName string
+	DataDir     string
+	// InitDB initializes the cluster; empty when the package does it
+	InitDB []string
+}
+
+// LayoutFor returns the packages, service and data directory for the OS
+// package type (rpm or deb) and major version
+func LayoutFor(packageType, version string) (*Layout, error) {
+	major := strings.SplitN(version, ".", 2)[0]
+	switch packageType {
+	case "deb":
+		// Debian packages create and start the "main" cluster themselves
+		if major == "" {
+			return &Layout{Packages: []string{"postgresql", "postgresql-client"}, ServiceName: "postgresql"}, nil
+		}
+		return &Layout{
+			Packages:    []string{"postgresql-" + major, "postgresql-client-" + major},
+			ServiceName: "postgresql",
+			DataDir:     filepath.Join("/var/lib/postgresql", major, "main"),
+		}, nil
+	case "rpm":
+		if major == "" {
+			return &Layout{
+				Packages:    []string{"postgresql-server", "postgresql"},
+				ServiceName: "postgresql",
+				DataDir:     "/var/lib/pgsql/data",
+				InitDB:      []string{"postgresql-setup", "--initdb"},
+			}, nil
+		}
+		return &Layout{
+			Packages:    []string{"postgresql" + major + "-server", "postgresql" + major},
+			ServiceName: "postgresql-" + major,
+			DataDir:     filepath.Join("/var/lib/pgsql", major, "data"),
+			InitDB:      []string{filepath.Join("/usr/pgsql-"+major, "bin", "postgresql-"+major+"-setup"), "initdb"},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported package type %q", packageType)
+}
+
+// Install installs the PostgreSQL server and client packages, initializes
+// the cluster when the packages do not, and enables and starts the service
+func Install(opts InstallOptions) (*Layout, error) {
+	lg, _ := logger.Get()
+
+	osInfo, err := system.DetectOS()
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect OS: %w", err)
+	}
+	layout, err := LayoutFor(osInfo.PackageType, opts.Version)
+	if err != nil {
+		return nil, err
+	}
+	lg.Info("Installing PostgreSQL",
+		logger.String("os", osInfo.ID),
+		logger.String("version", opts.Version),
+		logger.String("packages", strings.Join(layout.Packages, ",")))
+
+	pm := system.NewPackageManager()
+	terminal.PrintSubHeader("Installing packages")
+	var missing []string
+	for _, pkg := range layout.Packages {
+		if pm.IsInstalled(pkg) {
+			terminal.PrintInfo(pkg + " is already installed")
+			continue
+		}
+		missing = append(missing, pkg)
+	}
+	if len(missing) > 0 {
+		if err := pm.Install(missing); err != nil {
+			hint := ""
+			if opts.Version != "" {
+				hint = " (versioned packages need the PGDG repository, see https://www.postgresql.org/download/)"
+			}
+			return nil, fmt.Errorf("failed to install %s%s: %w", strings.Join(missing, ", "), hint, err)
+		}
+	}
+
+	if len(layout.InitDB) > 0 && !clusterInitialized(layout.DataDir) {
+		terminal.PrintSubHeader("Initializing cluster")
+		out, err := exec.Command(layout.InitDB[0], layout.InitDB[1:]...).CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize cluster in %s: %w: %s", layout.DataDir, err, strings.TrimSpace(string(out)))
+		}
+		lg.Info("PostgreSQL cluster initialized", logger.String("data_dir", layout.DataDir))
+	}
+
+	terminal.PrintSubHeader("Starting service")
+	sm := system.NewServiceManager()
+	if err := sm.Enable(layout.ServiceName); err != nil {
+		return nil, err
+	}
+	if err := sm.Start(layout.ServiceName); err != nil {
+		return nil, err
+	}
+	lg.Info("PostgreSQL installed", logger.String("service", layout.ServiceName))
+	return layout, nil
+}
+
+// clusterInitialized reports whether dataDir holds a cluster
+func clusterInitialized(dataDir string) bool {
+	_, err := os.Stat(filepath.Join(dataDir, "PG_VERSION"))
+	return err == nil
+}
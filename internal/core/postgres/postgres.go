@@ -0,0 +1,147 @@
+// Package postgres manages PostgreSQL servers next to the MariaDB tooling:
+// install, configure, backup and restore. All server access goes through the
+// PostgreSQL client binaries (psql, pg_dump, pg_dumpall, pg_restore), the
+// same way the MariaDB side uses mysql and mysqldump.
+package postgres
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"sfDBTools/utils/shutdown"
+)
+
+// Conn is a PostgreSQL connection. Empty fields are left to libpq, which
+// falls back to PGHOST, PGPORT, PGUSER, PGPASSWORD, ~/.pgpass and the local
+// Unix socket.
+type Conn struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+}
+
+// String returns host:port for messages and job status
+func (c Conn) String() string {
+	host := c.Host
+	if host == "" {
+		host = "local socket"
+	}
+	if c.Port == 0 {
+		return host
+	}
+	return host + ":" + strconv.Itoa(c.Port)
+}
+
+// Env returns the environment for client binaries. The password is passed
+// as PGPASSWORD so it never shows up in the process list.
+func (c Conn) Env() []string {
+	env := os.Environ()
+	if c.Host != "" {
+		env = append(env, "PGHOST="+c.Host)
+	}
+	if c.Port != 0 {
+		env = append(env, "PGPORT="+strconv.Itoa(c.Port))
+	}
+	if c.User != "" {
+		env = append(env, "PGUSER="+c.User)
+	}
+	if c.Password != "" {
+		env = append(env, "PGPASSWORD="+c.Password)
+	}
+	return env
+}
+
+// command prepares a client binary with the connection environment and
+// stderr captured for error messages
+func (c Conn) command(stderr *bytes.Buffer, name string, args ...string) *exec.Cmd {
+	cmd := shutdown.Command(shutdown.Context(), name, args...)
+	cmd.Env = c.Env()
+	cmd.Stderr = stderr
+	return cmd
+}
+
+// run runs a client binary and includes the end of its stderr in the error
+func (c Conn) run(name string, args ...string) error {
+	var stderr bytes.Buffer
+	if err := c.command(&stderr, name, args...).Run(); err != nil {
+		return clientError(name, err, &stderr)
+	}
+	return nil
+}
+
+// Query runs a statement with psql in database and returns the result rows,
+// columns separated by '|'
+func (c Conn) Query(database, query string) ([]string, error) {
+	var stderr bytes.Buffer
+	cmd := c.command(&stderr, "psql", "-X", "-A", "-t", "-q", "-v", "ON_ERROR_STOP=1", "-d", database, "-c", query)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, clientError("psql", err, &stderr)
+	}
+	var rows []string
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		if line != "" {
+			rows = append(rows, line)
+		}
+	}
+	return rows, nil
+}
+
+// Exec runs a statement with psql in database
+func (c Conn) Exec(database, statement string) error {
+	return c.run("psql", "-X", "-q", "-v", "ON_ERROR_STOP=1", "-d", database, "-c", statement)
+}
+
+// ServerVersion returns server_version, e.g. "16.2"
+func (c Conn) ServerVersion() (string, error) {
+	rows, err := c.Query("postgres", "SHOW server_version")
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to PostgreSQL at %s: %w", c, err)
+	}
+	if len(rows) == 0 {
+		return "", fmt.Errorf("no server version returned by %s", c)
+	}
+	return strings.Fields(rows[0])[0], nil
+}
+
+// ListDatabases returns the databases that accept connections, without the
+// templates
+func (c Conn) ListDatabases() ([]string, error) {
+	return c.Query("postgres", "SELECT datname FROM pg_database WHERE NOT datistemplate AND datallowconn ORDER BY datname")
+}
+
+// DatabaseExists reports whether name exists on the server
+func (c Conn) DatabaseExists(name string) (bool, error) {
+	rows, err := c.Query("postgres", "SELECT 1 FROM pg_database WHERE datname = "+QuoteLiteral(name))
+	if err != nil {
+		return false, err
+	}
+	return len(rows) > 0, nil
+}
+
+// QuoteLiteral quotes s as an SQL string literal
+func QuoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// QuoteIdent quotes s as an SQL identifier
+func QuoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// clientError wraps a client binary failure with the last line of its stderr
+func clientError(name string, err error, stderr *bytes.Buffer) error {
+	msg := strings.TrimSpace(stderr.String())
+	if lines := strings.Split(msg, "\n"); len(lines) > 3 {
+		msg = strings.Join(lines[len(lines)-3:], "\n")
+	}
+	if msg == "" {
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return fmt.Errorf("%s failed: %w: %s", name, err, msg)
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+)
+
+// RestoreOptions selects the dump to restore and how
+type RestoreOptions struct {
+	Conn     Conn
+	File     string
+	Database string // target database; defaults to the name in the dump file name
+	// Create lets pg_restore create the database recorded in the dump (-C),
+	// ignoring Database
+	Create bool
+	Clean  bool // drop objects before recreating them
+	Jobs   int  // parallel pg_restore jobs; custom-format dumps only
+}
+
+// Restore verifies the dump against its .sha256 file, when present, and
+// restores it. Plain SQL dumps (globals, pg_dump -Fp) go through psql,
+// everything else through pg_restore.
+func Restore(opts RestoreOptions) (err error) {
+	lg, _ := logger.Get()
+	opts.Database = opts.TargetDatabase()
+	if database.IsProtected(opts.Conn.Host, opts.Conn.Port) {
+		return fmt.Errorf("refusing to restore into protected endpoint %s", opts.Conn)
+	}
+
+	job := jobstatus.Start("postgres-restore", opts.Conn.String())
+	defer func() { job.Finish(err) }()
+	job.Detail("File", opts.File)
+
+	job.SetStep("verifying checksum")
+	if err := verifyDump(opts.File); err != nil {
+		return err
+	}
+
+	plain := isPlainDump(opts.File)
+	if !opts.Create {
+		exists, err := opts.Conn.DatabaseExists(opts.Database)
+		if err != nil {
+			return fmt.Errorf("failed to check database %s: %w", opts.Database, err)
+		}
+		if !exists {
+			job.SetStep("creating database " + opts.Database)
+			if err := opts.Conn.Exec("postgres", "CREATE DATABASE "+QuoteIdent(opts.Database)); err != nil {
+				return fmt.Errorf("failed to create database %s: %w", opts.Database, err)
+			}
+		}
+	}
+
+	job.SetStep("restoring")
+	if plain {
+		err = opts.Conn.run("psql", "-X", "-q", "-v", "ON_ERROR_STOP=1", "-d", opts.Database, "-f", opts.File)
+	} else {
+		err = opts.Conn.run("pg_restore", restoreArgs(opts)...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", opts.File, err)
+	}
+	lg.Info("PostgreSQL restore completed",
+		logger.String("file", opts.File),
+		logger.String("database", opts.Database),
+		logger.String("server", opts.Conn.String()))
+	return nil
+}
+
+// TargetDatabase returns the database the dump is restored into: Database,
+// else the name in a dump file written by Backup. Plain SQL dumps such as
+// the globals file default to the maintenance database. Empty with Create.
+func (o RestoreOptions) TargetDatabase() string {
+	switch {
+	case o.Database != "" || o.Create:
+		return o.Database
+	case isPlainDump(o.File):
+		return "postgres"
+	}
+	return DatabaseFromDump(o.File)
+}
+
+// isPlainDump reports whether file is replayed with psql rather than pg_restore
+func isPlainDump(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), ".sql")
+}
+
+// restoreArgs builds the pg_restore command line. With Create, pg_restore
+// connects to the maintenance database and creates the target itself.
+func restoreArgs(opts RestoreOptions) []string {
+	args := []string{"--no-owner", "--exit-on-error"}
+	if opts.Create {
+		args = append(args, "-C", "-d", "postgres")
+	} else {
+		args = append(args, "-d", opts.Database)
+	}
+	if opts.Clean {
+		args = append(args, "--clean", "--if-exists")
+	}
+	if opts.Jobs > 1 {
+		args = append(args, "-j", strconv.Itoa(opts.Jobs))
+	}
+	return append(args, opts.File)
+}
+
+// verifyDump checks file against the <file>.sha256 written by Backup. Dumps
+// without one, e.g. made by hand, are restored unverified.
+func verifyDump(file string) error {
+	if _, err := os.Stat(file); err != nil {
+		return fmt.Errorf("dump file not found: %w", err)
+	}
+	f, err := os.Open(file + ".sha256")
+	if errors.Is(err, os.ErrNotExist) {
+		lg, _ := logger.Get()
+		lg.Warn("No checksum file found, restoring without verification", logger.String("file", file))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty checksum file %s.sha256", file)
+	}
+	return fs.VerifyFileChecksum(file, fields[0], "sha256")
+}
@@ -0,0 +1,224 @@
+package quota
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"sfDBTools/internal/config/model"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/notify"
+)
+
+// Options controls one quota check
+type Options struct {
+	Config  model.QuotaConfig
+	Enforce bool // revoke INSERT above the hard limit
+	// DryRun only rates the sizes: no alerts, enforcement, audit or state
+	DryRun bool
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Statuses []Status
+	Alerts   int
+	Actions  []string // enforcement changes made, for display
+}
+
+// Check measures the databases, alerts on every change of level since the
+// previous check, and enforces the hard limits. INSERT revoked earlier is
+// granted back once the database is below its hard limit again, also when
+// enforcement has since been turned off.
+func Check(db *sql.DB, limits []Limit, opts Options) (*Result, error) {
+	sizes, err := Measure(db)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Statuses: Evaluate(sizes, limits, opts.Config.WarnAt)}
+	if opts.DryRun {
+		return res, nil
+	}
+
+	state, err := LoadState(opts.Config.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	var audit []AuditEntry
+	current := make(map[string]Status, len(res.Statuses))
+	for _, s := range res.Statuses {
+		current[s.Database] = s
+		prev, seen := state.Levels[s.Database]
+		label := s.Label()
+		state.Levels[s.Database] = label
+		if prev == label || (!seen && s.Level == LevelOK) {
+			continue
+		}
+		action := "alert"
+		if s.Level == LevelOK {
+			action = "recover"
+		}
+		audit = append(audit, AuditEntry{Action: action, Database: s.Database, Level: label, Size: s.Size, MaxSize: s.Limit.MaxSize})
+		res.Alerts++
+		sendAlert(s, prev)
+	}
+
+	// Enforcement runs on every check so accounts added to app_users, or
+	// enforcement turned on, take effect while a database stays above its limit
+	for _, s := range res.Statuses {
+		if s.Level != LevelHard {
+			delete(state.Unenforceable, s.Database)
+			continue
+		}
+		if opts.Enforce {
+			audit = append(audit, enforce(db, s, state, res)...)
+		}
+	}
+
+	for database, accounts := range state.Revoked {
+		if s, ok := current[database]; ok && s.Level == LevelHard {
+			continue
+		}
+		audit = append(audit, restore(db, database, accounts, current[database], state, res)...)
+	}
+
+	if err := appendAudit(opts.Config.AuditLog, audit...); err != nil {
+		return res, err
+	}
+	return res, state.Save(opts.Config.StateFile)
+}
+
+func sendAlert(s Status, prev string) {
+	a := notify.Alert{
+		Source:   "quota",
+		Severity: notify.SeverityWarning,
+		Subject:  fmt.Sprintf("database %s at %.0f%% of quota", s.Database, s.Percent),
+		Message: fmt.Sprintf("Database %s uses %s of its %s quota (%.1f%%)",
+			s.Database, common.FormatSize(s.Size), common.FormatSize(s.Limit.MaxSize), s.Percent),
+		Fields: map[string]string{
+			"database":   s.Database,
+			"level":      s.Label(),
+			"previous":   prev,
+			"size_bytes": fmt.Sprint(s.Size),
+			"max_bytes":  fmt.Sprint(s.Limit.MaxSize),
+			"hard_bytes": fmt.Sprint(s.Limit.HardLimit),
+		},
+	}
+	switch s.Level {
+	case LevelOK:
+		a.Severity = notify.SeverityInfo
+		a.Subject = fmt.Sprintf("database %s back under quota", s.Database)
+	case LevelHard:
+		a.Severity = notify.SeverityCritical
+		a.Subject = fmt.Sprintf("database %s over its hard limit", s.Database)
+	}
+	notify.Send(a)
+}
+
+// enforce revokes INSERT on the database from its application accounts
+func enforce(db *sql.DB, s Status, state *State, res *Result) []AuditEntry {
+	lg, _ := logger.Get()
+	var audit []AuditEntry
+	for _, account := range s.Limit.AppUsers {
+		if containsAccount(state.Revoked[s.Database], account) || containsAccount(state.Unenforceable[s.Database], account) {
+			continue
+		}
+		user, host, _ := splitAccount(account)
+		entry := AuditEntry{Database: s.Database, Level: s.Label(), Size: s.Size, MaxSize: s.Limit.MaxSize, Account: account}
+		grantedOn, err := insertGrantedOn(db, user, host, s.Database)
+		if err != nil || grantedOn == "" {
+			entry.Action = "enforce-skipped"
+			entry.Detail = fmt.Sprintf("INSERT is not granted on %s.* (global or wildcard grant)", s.Database)
+			if err != nil {
+				entry.Detail = err.Error()
+			}
+			lg.Warn("Quota enforcement skipped", logger.String("database", s.Database), logger.String("account", account), logger.String("reason", entry.Detail))
+			audit = append(audit, entry)
+			state.Unenforceable[s.Database] = append(state.Unenforceable[s.Database], account)
+			continue
+		}
+		stmt := fmt.Sprintf("REVOKE INSERT ON %s.* FROM %s", database.QuoteIdent(grantedOn), quoteAccount(user, host))
+		if _, err := db.Exec(stmt); err != nil {
+			entry.Action, entry.Detail = "enforce-skipped", err.Error()
+			lg.Error("Failed to revoke INSERT for quota", logger.String("database", s.Database), logger.String("account", account), logger.Error(err))
+			audit = append(audit, entry)
+			continue
+		}
+		entry.Action, entry.Detail = "revoke", stmt
+		audit = append(audit, entry)
+		state.Revoked[s.Database] = append(state.Revoked[s.Database], account)
+		res.Actions = append(res.Actions, stmt)
+		notify.Send(notify.Alert{
+			Source:   "quota",
+			Severity: notify.SeverityCritical,
+			Subject:  fmt.Sprintf("INSERT revoked on %s", s.Database),
+			Message:  fmt.Sprintf("INSERT on %s revoked from %s: %s is over its hard limit of %s", s.Database, account, common.FormatSize(s.Size), common.FormatSize(s.Limit.HardLimit)),
+			Fields:   map[string]string{"database": s.Database, "account": account},
+		})
+	}
+	return audit
+}
+
+// restore grants INSERT back to the accounts it was revoked from
+func restore(db *sql.DB, dbName string, accounts []string, s Status, state *State, res *Result) []AuditEntry {
+	lg, _ := logger.Get()
+	var audit []AuditEntry
+	var kept []string
+	for _, account := range accounts {
+		user, host, _ := splitAccount(account)
+		stmt := fmt.Sprintf("GRANT INSERT ON %s.* TO %s", database.QuoteIdent(dbName), quoteAccount(user, host))
+		entry := AuditEntry{Action: "grant", Database: dbName, Level: s.Label(), Size: s.Size, MaxSize: s.Limit.MaxSize, Account: account, Detail: stmt}
+		if _, err := db.Exec(stmt); err != nil {
+			lg.Error("Failed to grant INSERT back after quota", logger.String("database", dbName), logger.String("account", account), logger.Error(err))
+			kept = append(kept, account)
+			continue
+		}
+		audit = append(audit, entry)
+		res.Actions = append(res.Actions, stmt)
+		notify.Send(notify.Alert{
+			Source:   "quota",
+			Severity: notify.SeverityInfo,
+			Subject:  fmt.Sprintf("INSERT granted back on %s", dbName),
+			Message:  fmt.Sprintf("INSERT on %s granted back to %s: the database is below its hard limit", dbName, account),
+			Fields:   map[string]string{"database": dbName, "account": account},
+		})
+	}
+	if len(kept) == 0 {
+		delete(state.Revoked, dbName)
+	} else {
+		state.Revoked[dbName] = kept
+	}
+	return audit
+}
+
+// insertGrantedOn returns the schema name INSERT is granted on for the
+// account: the database name itself or its escaped form (dbsf\_x), or empty
+func insertGrantedOn(db *sql.DB, user, host, database string) (string, error) {
+	escaped := strings.NewReplacer(`_`, `\_`, `%`, `\%`).Replace(database)
+	var schema string
+	err := db.QueryRow(`SELECT table_schema FROM information_schema.schema_privileges
+		WHERE grantee = ? AND table_schema IN (?, ?) AND privilege_type = 'INSERT' LIMIT 1`,
+		quoteAccount(user, host), database, escaped).Scan(&schema)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read grants of %s: %w", quoteAccount(user, host), err)
+	}
+	return schema, nil
+}
+
+func containsAccount(accounts []string, account string) bool {
+	for _, a := range accounts {
+		if a == account {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteAccount(user, host string) string {
+	esc := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + esc.Replace(user) + "'@'" + esc.Replace(host) + "'"
+}
@@ -0,0 +1,145 @@
+// Package quota checks database sizes against the limits in the quota
+// section of the config, raises alerts when a database crosses a threshold
+// and, when enforcement is on, revokes INSERT from its application accounts
+// while it is above its hard limit.
+package quota
+
+import (
+	"database/sql"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"sfDBTools/internal/config/model"
+	"sfDBTools/utils/common"
+)
+
+// Levels of a database relative to its quota, from low to high
+const (
+	LevelOK   = "ok"
+	LevelWarn = "warn" // above a warn_at threshold
+	LevelOver = "over" // above max_size
+	LevelHard = "hard" // above the hard limit; enforced when enabled
+)
+
+// Limit is a parsed quota entry
+type Limit struct {
+	Pattern   string
+	MaxSize   int64
+	HardLimit int64
+	AppUsers  []string
+}
+
+// Status is the size of one database against its limit
+type Status struct {
+	Database  string
+	Size      int64
+	Limit     Limit
+	Percent   float64
+	Level     string
+	Threshold float64 // warn_at threshold crossed, for LevelWarn
+}
+
+// Label describes the level, e.g. "warn 80%"
+func (s Status) Label() string {
+	if s.Level == LevelWarn {
+		return fmt.Sprintf("%s %.0f%%", s.Level, s.Threshold)
+	}
+	return s.Level
+}
+
+// ParseLimits validates the quota entries of the config
+func ParseLimits(cfg model.QuotaConfig) ([]Limit, error) {
+	limits := make([]Limit, 0, len(cfg.Databases))
+	for _, q := range cfg.Databases {
+		if q.Name == "" {
+			return nil, fmt.Errorf("quota entry without name")
+		}
+		if _, err := path.Match(q.Name, ""); err != nil {
+			return nil, fmt.Errorf("quota %s: invalid pattern: %w", q.Name, err)
+		}
+		l := Limit{Pattern: q.Name, MaxSize: common.ParseSize(q.MaxSize), AppUsers: q.AppUsers}
+		if l.MaxSize <= 0 {
+			return nil, fmt.Errorf("quota %s: invalid max_size %q", q.Name, q.MaxSize)
+		}
+		l.HardLimit = l.MaxSize
+		if q.HardLimit != "" {
+			if l.HardLimit = common.ParseSize(q.HardLimit); l.HardLimit < l.MaxSize {
+				return nil, fmt.Errorf("quota %s: hard_limit %q must be at least max_size", q.Name, q.HardLimit)
+			}
+		}
+		for _, u := range q.AppUsers {
+			if _, _, ok := splitAccount(u); !ok {
+				return nil, fmt.Errorf("quota %s: app user %q is not in 'user'@'host' form", q.Name, u)
+			}
+		}
+		limits = append(limits, l)
+	}
+	return limits, nil
+}
+
+// Measure returns the size of every database (data and indexes) in bytes
+func Measure(db *sql.DB) (map[string]int64, error) {
+	rows, err := db.Query(`SELECT table_schema, COALESCE(SUM(data_length + index_length), 0)
+		FROM information_schema.tables GROUP BY table_schema`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database sizes: %w", err)
+	}
+	defer rows.Close()
+	sizes := make(map[string]int64)
+	for rows.Next() {
+		var name string
+		var size int64
+		if err := rows.Scan(&name, &size); err != nil {
+			return nil, fmt.Errorf("failed to read database sizes: %w", err)
+		}
+		sizes[name] = size
+	}
+	return sizes, rows.Err()
+}
+
+// Evaluate matches databases to the first limit whose pattern fits and rates
+// their size. Databases without a limit are left out.
+func Evaluate(sizes map[string]int64, limits []Limit, warnAt []float64) []Status {
+	thresholds := append([]float64(nil), warnAt...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(thresholds)))
+
+	var statuses []Status
+	for name, size := range sizes {
+		for _, l := range limits {
+			if ok, _ := path.Match(l.Pattern, name); !ok {
+				continue
+			}
+			s := Status{Database: name, Size: size, Limit: l, Level: LevelOK}
+			s.Percent = float64(size) * 100 / float64(l.MaxSize)
+			switch {
+			case size >= l.HardLimit:
+				s.Level = LevelHard
+			case size >= l.MaxSize:
+				s.Level = LevelOver
+			default:
+				for _, t := range thresholds {
+					if s.Percent >= t {
+						s.Level, s.Threshold = LevelWarn, t
+						break
+					}
+				}
+			}
+			statuses = append(statuses, s)
+			break
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Percent > statuses[j].Percent })
+	return statuses
+}
+
+// splitAccount splits 'user'@'host'
+func splitAccount(account string) (user, host string, ok bool) {
+	user, host, ok = strings.Cut(account, "@")
+	if !ok {
+		return "", "", false
+	}
+	user, host = strings.Trim(user, "'`\""), strings.Trim(host, "'`\"")
+	return user, host, user != "" && host != ""
+}
@@ -0,0 +1,98 @@
+package quota
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sfDBTools/utils/fs"
+)
+
+// State is what the monitor remembers between checks: the level each
+// database was last alerted at, so an alert is raised once per change, and
+// the accounts INSERT was revoked from, so it can be granted back.
+// Unenforceable lists accounts enforcement was skipped for while the
+// database stays above its hard limit, so the skip is audited once.
+type State struct {
+	Levels        map[string]string   `json:"levels"`
+	Revoked       map[string][]string `json:"revoked,omitempty"`
+	Unenforceable map[string][]string `json:"unenforceable,omitempty"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// LoadState reads the state file; a missing file is an empty state
+func LoadState(path string) (*State, error) {
+	s := &State{Levels: map[string]string{}, Revoked: map[string][]string{}, Unenforceable: map[string][]string{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota state: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("%s is not a quota state file: %w", path, err)
+	}
+	if s.Levels == nil {
+		s.Levels = map[string]string{}
+	}
+	if s.Revoked == nil {
+		s.Revoked = map[string][]string{}
+	}
+	if s.Unenforceable == nil {
+		s.Unenforceable = map[string][]string{}
+	}
+	return s, nil
+}
+
+// Save writes the state file
+func (s *State) Save(path string) error {
+	s.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode quota state: %w", err)
+	}
+	if err := fs.NewManager().File().WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write quota state: %w", err)
+	}
+	return nil
+}
+
+// AuditEntry is one line of the audit log
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Action   string    `json:"action"` // alert, recover, revoke, grant, enforce-skipped
+	Database string    `json:"database"`
+	Level    string    `json:"level,omitempty"`
+	Size     int64     `json:"size_bytes"`
+	MaxSize  int64     `json:"max_size_bytes"`
+	Account  string    `json:"account,omitempty"`
+	Detail   string    `json:"detail,omitempty"`
+}
+
+// appendAudit appends entries to the JSON lines audit log
+func appendAudit(path string, entries ...AuditEntry) error {
+	if path == "" || len(entries) == 0 {
+		return nil
+	}
+	if err := fs.NewManager().File().EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open quota audit log: %w", err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, e := range entries {
+		if e.Time.IsZero() {
+			e.Time = time.Now().UTC()
+		}
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to write quota audit log: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package replication
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	restoreAll "sfDBTools/internal/core/restore/all"
+	restoreUtils "sfDBTools/internal/core/restore/utils"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/jobstatus"
+)
+
+// SeedOptions describes seeding a replica from a bookmarked backup
+type SeedOptions struct {
+	Manifest string                       // manifest of the backup
+	Backup   *backup_utils.BackupMetadata // decoded manifest
+	Replica  database.Config
+	// Source the replica replicates from; defaults to the backup's host
+	SourceHost string
+	SourcePort int
+	// Replication account on the source
+	ReplicationUser     string
+	ReplicationPassword string
+	// UseGTID starts from the GTID position of the bookmark instead of the
+	// binlog file and position
+	UseGTID      bool
+	SkipRestore  bool // the backup was restored already
+	StartReplica bool
+	Force        bool // replace a replication setup the replica already has
+}
+
+// FindBackup resolves a backup id to its manifest. The id is the manifest file
+// name without .meta.json (e.g. all_databases_20250101_020000), or a path to
+// the manifest.
+func FindBackup(baseDir, id string) (string, *backup_utils.BackupMetadata, error) {
+	if meta, ok := backup_utils.ReadBackupManifest(id); ok {
+		return id, meta, nil
+	}
+	var found string
+	var meta *backup_utils.BackupMetadata
+	err := filepath.WalkDir(baseDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || found != "" {
+			return nil
+		}
+		name := d.Name()
+		if strings.TrimSuffix(strings.TrimSuffix(name, ".json"), ".meta") != id {
+			return nil
+		}
+		if m, ok := backup_utils.ReadBackupManifest(path); ok {
+			found, meta = path, m
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read backup catalog %s: %w", baseDir, err)
+	}
+	if found == "" {
+		return "", nil, fmt.Errorf("backup %s not found in %s", id, baseDir)
+	}
+	return found, meta, nil
+}
+
+// Bookmark returns the source position recorded for the backup
+func Bookmark(meta *backup_utils.BackupMetadata) (*backup_utils.BinlogBookmark, error) {
+	if meta.ReplicationInfo == nil || meta.ReplicationInfo.Bookmark == nil {
+		return nil, fmt.Errorf("backup has no recorded binlog position; take an all-databases backup with binary logging enabled on the source")
+	}
+	return meta.ReplicationInfo.Bookmark, nil
+}
+
+// ChangeMasterStatements returns the statements that point the replica at the
+// bookmark. The password is passed separately so it can be masked in output.
+func ChangeMasterStatements(opts SeedOptions, bookmark *backup_utils.BinlogBookmark) []string {
+	clauses := []string{
+		"MASTER_HOST = " + database.QuoteString(opts.SourceHost),
+		fmt.Sprintf("MASTER_PORT = %d", opts.SourcePort),
+		"MASTER_USER = " + database.QuoteString(opts.ReplicationUser),
+		"MASTER_PASSWORD = " + database.QuoteString(opts.ReplicationPassword),
+	}
+	var stmts []string
+	if opts.UseGTID {
+		stmts = append(stmts, "SET GLOBAL gtid_slave_pos = "+database.QuoteString(bookmark.GTIDPosition))
+		clauses = append(clauses, "MASTER_USE_GTID = slave_pos")
+	} else {
+		clauses = append(clauses,
+			"MASTER_LOG_FILE = "+database.QuoteString(bookmark.LogFile),
+			fmt.Sprintf("MASTER_LOG_POS = %d", bookmark.LogPosition))
+	}
+	stmts = append(stmts, "CHANGE MASTER TO "+strings.Join(clauses, ", "))
+	if opts.StartReplica {
+		stmts = append(stmts, "START SLAVE")
+	}
+	return stmts
+}
+
+// MaskPassword masks the replication password in a statement for display
+func MaskPassword(stmt, password string) string {
+	if password == "" {
+		return stmt
+	}
+	return strings.ReplaceAll(stmt, database.QuoteString(password), "'***'")
+}
+
+// Seed restores the backup on the replica and configures replication from the
+// position the backup is consistent with
+func Seed(ctx context.Context, opts SeedOptions) (err error) {
+	lg, _ := logger.Get()
+	job := jobstatus.Start("replication-seed", fmt.Sprintf("%s:%d", opts.Replica.Host, opts.Replica.Port))
+	defer func() { job.Finish(err) }()
+
+	bookmark, err := Bookmark(opts.Backup)
+	if err != nil {
+		return err
+	}
+	if opts.UseGTID && bookmark.GTIDPosition == "" {
+		return fmt.Errorf("backup has no GTID position recorded; seed by binlog position instead")
+	}
+	if database.IsProtected(opts.Replica.Host, opts.Replica.Port) {
+		return fmt.Errorf("%s:%d is a protected source and cannot be seeded", opts.Replica.Host, opts.Replica.Port)
+	}
+
+	job.SetStep("checking replica")
+	db, err := database.GetWithoutDB(opts.Replica)
+	if err != nil {
+		return fmt.Errorf("failed to connect to replica %s:%d: %w", opts.Replica.Host, opts.Replica.Port, err)
+	}
+	defer db.Close()
+	if configured, err := hasReplication(db); err != nil {
+		return err
+	} else if configured && !opts.Force {
+		return fmt.Errorf("replica already has replication configured; use --force to replace it")
+	}
+
+	if !opts.SkipRestore {
+		job.SetStep("restoring backup")
+		file := backup_utils.ArtifactPath(opts.Manifest, opts.Backup)
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("backup file not accessible: %w", err)
+		}
+		// A running replica thread would apply events on top of the restore
+		if _, err := db.Exec("STOP SLAVE"); err != nil {
+			lg.Debug("STOP SLAVE before restore failed", logger.Error(err))
+		}
+		err = restoreAll.RestoreAll(ctx, restoreUtils.RestoreOptions{
+			Host:           opts.Replica.Host,
+			Port:           opts.Replica.Port,
+			User:           opts.Replica.User,
+			Password:       opts.Replica.Password,
+			File:           file,
+			VerifyChecksum: true,
+		})
+		if err != nil {
+			return fmt.Errorf("restore on replica failed: %w", err)
+		}
+	}
+
+	job.SetStep("configuring replication")
+	if _, err := db.Exec("STOP SLAVE"); err != nil {
+		lg.Debug("STOP SLAVE failed", logger.Error(err))
+	}
+	if opts.Force {
+		if _, err := db.Exec("RESET SLAVE ALL"); err != nil {
+			return fmt.Errorf("failed to reset existing replication: %w", err)
+		}
+	}
+	for _, stmt := range ChangeMasterStatements(opts, bookmark) {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("%s: %w", MaskPassword(stmt, opts.ReplicationPassword), err)
+		}
+	}
+	lg.Info("Replica seeded from backup",
+		logger.String("backup", opts.Manifest),
+		logger.String("source", fmt.Sprintf("%s:%d", opts.SourceHost, opts.SourcePort)),
+		logger.String("log_file", bookmark.LogFile),
+		logger.Int64("log_position", bookmark.LogPosition),
+		logger.String("gtid_position", bookmark.GTIDPosition),
+		logger.Bool("use_gtid", opts.UseGTID))
+	job.Detail("Start position", fmt.Sprintf("%s:%d", bookmark.LogFile, bookmark.LogPosition))
+	return nil
+}
+
+// hasReplication reports whether SHOW SLAVE STATUS returns a connection
+func hasReplication(db *sql.DB) (bool, error) {
+	rows, err := db.Query("SHOW SLAVE STATUS")
+	if err != nil {
+		return false, fmt.Errorf("failed to read replica status: %w", err)
+	}
+	defer rows.Close()
+	return rows.Next(), rows.Err()
+}
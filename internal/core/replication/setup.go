@@ -0,0 +1,446 @@
+package replication
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/shutdown"
+)
+
+// SetupOptions describes building a replica from a running primary
+type SetupOptions struct {
+	Primary database.Config
+	Replica database.Config
+	// PrimaryAddress is the host the replica reaches the primary at; defaults
+	// to Primary.Host, which may be an address only this machine can use
+	PrimaryAddress string
+	// Replication account created on the primary; ReplicationHost is its
+	// host part (default '%')
+	ReplicationUser     string
+	ReplicationPassword string
+	ReplicationHost     string
+	// ReplicaServerID is the server_id given to the replica; 0 keeps its own
+	// unless it clashes with the primary or another replica
+	ReplicaServerID int
+	UseGTID         bool
+	Force           bool // replace a replication setup the replica already has
+	// MaxLag is the Seconds_Behind_Master the replica must reach within
+	// VerifyTimeout for the setup to count as caught up
+	MaxLag        time.Duration
+	VerifyTimeout time.Duration
+}
+
+// SetupPlan is what Setup will change, worked out before anything is changed
+type SetupPlan struct {
+	SetupOptions
+	PrimaryVersion string
+	Databases      []string // user databases copied from the primary
+	// Overwritten are replica databases the copy replaces; Extra are replica
+	// databases the primary does not have, left as they are
+	Overwritten []string
+	Extra       []string
+	// PrimaryStatements and ReplicaStatements run before the snapshot
+	PrimaryStatements []string
+	ReplicaStatements []string
+	ReplicaServerID   int
+	ResetReplication  bool // the replica has replication configured (requires Force)
+	SnapshotBytes     int64
+}
+
+// SetupResult reports what Setup did
+type SetupResult struct {
+	Bookmark *backup_utils.BinlogBookmark
+	Bytes    int64
+	// Lag is the last Seconds_Behind_Master seen; CaughtUp tells whether it
+	// reached MaxLag within VerifyTimeout
+	Lag      time.Duration
+	CaughtUp bool
+}
+
+// PrepareSetup checks both servers and plans the setup. It fails before
+// anything is changed when the primary has no binary log, both are the same
+// server or the replica already replicates and Force is not set.
+func PrepareSetup(opts SetupOptions) (*SetupPlan, error) {
+	if opts.PrimaryAddress == "" {
+		opts.PrimaryAddress = opts.Primary.Host
+	}
+	if opts.ReplicationHost == "" {
+		opts.ReplicationHost = "%"
+	}
+	if opts.Primary.Host == opts.Replica.Host && opts.Primary.Port == opts.Replica.Port {
+		return nil, fmt.Errorf("primary and replica are the same server (%s:%d)", opts.Replica.Host, opts.Replica.Port)
+	}
+	if database.IsProtected(opts.Replica.Host, opts.Replica.Port) {
+		return nil, fmt.Errorf("%s:%d is a protected source and cannot be used as a replica", opts.Replica.Host, opts.Replica.Port)
+	}
+	plan := &SetupPlan{SetupOptions: opts}
+
+	primary, err := database.GetWithoutDB(opts.Primary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to primary %s:%d: %w", opts.Primary.Host, opts.Primary.Port, err)
+	}
+	defer primary.Close()
+	var logBin, primaryID int
+	var binlogFormat string
+	err = primary.QueryRow("SELECT @@global.log_bin, @@global.server_id, @@global.binlog_format, @@version").
+		Scan(&logBin, &primaryID, &binlogFormat, &plan.PrimaryVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read primary settings: %w", err)
+	}
+	if logBin != 1 {
+		return nil, fmt.Errorf("binary logging is off on the primary; set log_bin in its server configuration (e.g. with 'sfDBTools mariadb configure') and restart it")
+	}
+	// server_id 0 refuses replicas; it is dynamic, so no restart is needed
+	if primaryID == 0 {
+		primaryID = 1
+		plan.PrimaryStatements = append(plan.PrimaryStatements, "SET GLOBAL server_id = 1")
+	}
+	if strings.EqualFold(binlogFormat, "STATEMENT") {
+		plan.PrimaryStatements = append(plan.PrimaryStatements, "SET GLOBAL binlog_format = 'MIXED'")
+	}
+	account := database.QuoteString(opts.ReplicationUser) + "@" + database.QuoteString(opts.ReplicationHost)
+	plan.PrimaryStatements = append(plan.PrimaryStatements,
+		"CREATE USER IF NOT EXISTS "+account+" IDENTIFIED BY "+database.QuoteString(opts.ReplicationPassword),
+		"ALTER USER "+account+" IDENTIFIED BY "+database.QuoteString(opts.ReplicationPassword),
+		"GRANT REPLICATION SLAVE ON *.* TO "+account)
+
+	if plan.Databases, err = info.ListDatabases(opts.Primary); err != nil {
+		return nil, fmt.Errorf("failed to list databases on the primary: %w", err)
+	}
+	plan.SnapshotBytes = databasesSize(primary, plan.Databases)
+	usedIDs := replicaServerIDs(primary)
+
+	replica, err := database.GetWithoutDB(opts.Replica)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to replica %s:%d: %w", opts.Replica.Host, opts.Replica.Port, err)
+	}
+	defer replica.Close()
+	if plan.ResetReplication, err = hasReplication(replica); err != nil {
+		return nil, err
+	}
+	if plan.ResetReplication && !opts.Force {
+		return nil, fmt.Errorf("replica already has replication configured; use --force to replace it")
+	}
+	var replicaID int
+	if err := replica.QueryRow("SELECT @@global.server_id").Scan(&replicaID); err != nil {
+		return nil, fmt.Errorf("failed to read replica server_id: %w", err)
+	}
+	plan.ReplicaServerID = opts.ReplicaServerID
+	if plan.ReplicaServerID == 0 {
+		plan.ReplicaServerID = replicaID
+		if replicaID == 0 || replicaID == primaryID || slices.Contains(usedIDs, replicaID) {
+			plan.ReplicaServerID = slices.Max(append(usedIDs, primaryID)) + 1
+		}
+	}
+	if plan.ReplicaServerID == primaryID {
+		return nil, fmt.Errorf("replica server_id %d is the primary's", primaryID)
+	}
+	if plan.ReplicaServerID != replicaID {
+		plan.ReplicaStatements = append(plan.ReplicaStatements, fmt.Sprintf("SET GLOBAL server_id = %d", plan.ReplicaServerID))
+	}
+	plan.ReplicaStatements = append(plan.ReplicaStatements, "STOP SLAVE")
+	if plan.ResetReplication {
+		plan.ReplicaStatements = append(plan.ReplicaStatements, "RESET SLAVE ALL")
+	}
+
+	existing, err := info.ListDatabases(opts.Replica)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases on the replica: %w", err)
+	}
+	for _, name := range existing {
+		if slices.Contains(plan.Databases, name) {
+			plan.Overwritten = append(plan.Overwritten, name)
+		} else {
+			plan.Extra = append(plan.Extra, name)
+		}
+	}
+	return plan, nil
+}
+
+// seedOptions returns the options ChangeMasterStatements needs
+func (p *SetupPlan) seedOptions() SeedOptions {
+	return SeedOptions{
+		SourceHost:          p.PrimaryAddress,
+		SourcePort:          p.Primary.Port,
+		ReplicationUser:     p.ReplicationUser,
+		ReplicationPassword: p.ReplicationPassword,
+		UseGTID:             p.UseGTID,
+		StartReplica:        true,
+	}
+}
+
+// ChangeMasterPreview returns the statements run on the replica after the
+// snapshot, with the position still unknown
+func (p *SetupPlan) ChangeMasterPreview() []string {
+	placeholder := &backup_utils.BinlogBookmark{LogFile: "<snapshot file>", GTIDPosition: "<snapshot GTID>"}
+	return ChangeMasterStatements(p.seedOptions(), placeholder)
+}
+
+// Setup configures the primary, copies a consistent snapshot of its user
+// databases to the replica, starts replication from the snapshot's position
+// and waits for the replica to catch up
+func Setup(plan *SetupPlan) (result *SetupResult, err error) {
+	lg, _ := logger.Get()
+	job := jobstatus.Start("replication-setup", fmt.Sprintf("%s:%d", plan.Replica.Host, plan.Replica.Port))
+	defer func() { job.Finish(err) }()
+
+	job.SetStep("configuring primary")
+	if err := execAll(plan.Primary, plan.PrimaryStatements, plan.ReplicationPassword); err != nil {
+		return nil, fmt.Errorf("primary: %w", err)
+	}
+	lg.Info("Replication account ready on primary",
+		logger.String("user", plan.ReplicationUser),
+		logger.String("host", plan.ReplicationHost))
+	for _, stmt := range plan.PrimaryStatements {
+		if strings.HasPrefix(stmt, "SET GLOBAL") {
+			lg.Warn("Primary setting changed at runtime only; add it to the server configuration so it survives a restart", logger.String("statement", stmt))
+		}
+	}
+
+	job.SetStep("preparing replica")
+	replica, err := database.GetWithoutDB(plan.Replica)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to replica: %w", err)
+	}
+	defer replica.Close()
+	for _, stmt := range plan.ReplicaStatements {
+		if _, err := replica.Exec(stmt); err != nil && stmt != "STOP SLAVE" {
+			return nil, fmt.Errorf("replica: %s: %w", stmt, err)
+		}
+	}
+	if len(plan.ReplicaStatements) > 0 && strings.HasPrefix(plan.ReplicaStatements[0], "SET GLOBAL server_id") {
+		lg.Warn("Replica server_id changed at runtime only; set server_id in its server configuration so it survives a restart",
+			logger.Int("server_id", plan.ReplicaServerID))
+	}
+
+	job.SetStep("copying snapshot")
+	result = &SetupResult{}
+	result.Bookmark, result.Bytes, err = copySnapshot(plan, job)
+	if err != nil {
+		return result, err
+	}
+	if plan.UseGTID {
+		backup_utils.ResolveBookmarkGTID(plan.Primary, result.Bookmark)
+		if result.Bookmark.GTIDPosition == "" {
+			return result, fmt.Errorf("could not resolve the GTID position of %s:%d on the primary; set up without --use-gtid", result.Bookmark.LogFile, result.Bookmark.LogPosition)
+		}
+	}
+	job.Detail("Start position", fmt.Sprintf("%s:%d", result.Bookmark.LogFile, result.Bookmark.LogPosition))
+
+	job.SetStep("starting replication")
+	for _, stmt := range ChangeMasterStatements(plan.seedOptions(), result.Bookmark) {
+		if _, err := replica.Exec(stmt); err != nil {
+			return result, fmt.Errorf("replica: %s: %w", MaskPassword(stmt, plan.ReplicationPassword), err)
+		}
+	}
+
+	job.SetStep("verifying replication")
+	result.Lag, result.CaughtUp, err = waitForReplica(replica, plan.MaxLag, plan.VerifyTimeout)
+	if err != nil {
+		return result, err
+	}
+	if !result.CaughtUp {
+		lg.Warn("Replica is replicating but still behind the primary",
+			logger.String("lag", result.Lag.String()),
+			logger.String("max_lag", plan.MaxLag.String()))
+	}
+	lg.Info("Replication set up",
+		logger.String("primary", fmt.Sprintf("%s:%d", plan.PrimaryAddress, plan.Primary.Port)),
+		logger.String("replica", fmt.Sprintf("%s:%d", plan.Replica.Host, plan.Replica.Port)),
+		logger.String("log_file", result.Bookmark.LogFile),
+		logger.Int64("log_position", result.Bookmark.LogPosition),
+		logger.String("lag", result.Lag.String()))
+	return result, nil
+}
+
+// copySnapshot pipes mysqldump of the primary into the replica. The dump is
+// taken in one transaction with --master-data=2, whose header names the
+// binlog position the snapshot is consistent with.
+func copySnapshot(plan *SetupPlan, job *jobstatus.Tracker) (*backup_utils.BinlogBookmark, int64, error) {
+	if len(plan.Databases) == 0 {
+		return nil, 0, fmt.Errorf("the primary has no user databases to copy")
+	}
+	var args []string
+	if cfg, err := config.Get(); err == nil && cfg != nil {
+		args = common.ParseArgsString(cfg.Mysqldump.Args)
+	}
+	args = append(args,
+		fmt.Sprintf("--host=%s", plan.Primary.Host),
+		fmt.Sprintf("--port=%d", plan.Primary.Port),
+		fmt.Sprintf("--user=%s", plan.Primary.User),
+		"--single-transaction", "--master-data=2",
+		"--routines", "--triggers", "--events",
+		"--databases")
+	args = append(args, plan.Databases...)
+	dump := shutdown.Command(shutdown.Context(), "mysqldump", args...)
+	dump.Env = clientEnv(plan.Primary.Password)
+	var dumpErr strings.Builder
+	dump.Stderr = &dumpErr
+
+	load := shutdown.Command(shutdown.Context(), "mysql",
+		fmt.Sprintf("--host=%s", plan.Replica.Host),
+		fmt.Sprintf("--port=%d", plan.Replica.Port),
+		fmt.Sprintf("--user=%s", plan.Replica.User),
+		"--max-allowed-packet=1G")
+	load.Env = clientEnv(plan.Replica.Password)
+	var loadErr strings.Builder
+	load.Stderr = &loadErr
+	stdin, err := load.StdinPipe()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	scanner := backup_utils.NewBookmarkScanner()
+	tap, _ := scanner.Stage().Wrap(stdin)
+	counter := &byteCounter{w: job.Writer(tap, plan.SnapshotBytes)}
+	dump.Stdout = counter
+
+	if err := load.Start(); err != nil {
+		return nil, 0, fmt.Errorf("failed to start mysql: %w", err)
+	}
+	dumpRunErr := dump.Run()
+	stdin.Close()
+	loadRunErr := load.Wait()
+	switch {
+	case dumpRunErr != nil:
+		return nil, counter.n, fmt.Errorf("mysqldump failed after %d bytes: %w: %s", counter.n, dumpRunErr, strings.TrimSpace(dumpErr.String()))
+	case loadRunErr != nil:
+		return nil, counter.n, fmt.Errorf("loading the snapshot failed after %d bytes: %w: %s", counter.n, loadRunErr, strings.TrimSpace(loadErr.String()))
+	}
+	bookmark := scanner.Bookmark()
+	if bookmark == nil {
+		return nil, counter.n, errors.New("the dump has no binlog position; check that the primary user has RELOAD and REPLICATION CLIENT")
+	}
+	return bookmark, counter.n, nil
+}
+
+// waitForReplica polls SHOW SLAVE STATUS until both threads run and the lag
+// is at most maxLag. A replication error fails at once; a lag still above
+// maxLag at the timeout is reported, not failed, as the replica catches up.
+func waitForReplica(db *sql.DB, maxLag, timeout time.Duration) (time.Duration, bool, error) {
+	deadline := time.Now().Add(timeout)
+	lag := time.Duration(-1)
+	for {
+		status, err := database.GetReplicaStatus(shutdown.Context(), db)
+		if err != nil {
+			return lag, false, fmt.Errorf("failed to read replica status: %w", err)
+		}
+		if status == nil {
+			return lag, false, errors.New("replica has no replication configured after CHANGE MASTER")
+		}
+		if msg := status["Last_IO_Error"]; msg != "" {
+			return lag, false, fmt.Errorf("replica IO thread error: %s", msg)
+		}
+		if msg := status["Last_SQL_Error"]; msg != "" {
+			return lag, false, fmt.Errorf("replica SQL thread error: %s", msg)
+		}
+		running := status.Value("Slave_IO_Running", "Replica_IO_Running") == "Yes" &&
+			status.Value("Slave_SQL_Running", "Replica_SQL_Running") == "Yes"
+		if seconds, err := strconv.Atoi(status.Value("Seconds_Behind_Master", "Seconds_Behind_Source")); err == nil {
+			lag = time.Duration(seconds) * time.Second
+		}
+		if running && lag >= 0 && lag <= maxLag {
+			return lag, true, nil
+		}
+		if time.Now().After(deadline) {
+			if !running {
+				return lag, false, fmt.Errorf("replication threads not running after %s (IO: %s, SQL: %s)",
+					timeout, status["Slave_IO_Running"], status["Slave_SQL_Running"])
+			}
+			return lag, false, nil
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// execAll runs statements in order, masking password in errors
+func execAll(cfg database.Config, statements []string, password string) error {
+	if len(statements) == 0 {
+		return nil
+	}
+	db, err := database.GetWithoutDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("%s: %w", MaskPassword(stmt, password), err)
+		}
+	}
+	return nil
+}
+
+// replicaServerIDs returns the server_id of the replicas connected to the primary
+func replicaServerIDs(db *sql.DB) []int {
+	rows, err := db.Query("SHOW SLAVE HOSTS")
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	cols, _ := rows.Columns()
+	var ids []int
+	for rows.Next() {
+		values := make([]sql.NullString, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if rows.Scan(ptrs...) != nil {
+			continue
+		}
+		if id, err := strconv.Atoi(values[0].String); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// databasesSize estimates the dump size from the data and index sizes
+func databasesSize(db *sql.DB, databases []string) int64 {
+	if len(databases) == 0 {
+		return 0
+	}
+	args := make([]any, len(databases))
+	for i, name := range databases {
+		args[i] = name
+	}
+	var size sql.NullInt64
+	query := "SELECT SUM(DATA_LENGTH + INDEX_LENGTH) FROM information_schema.TABLES WHERE TABLE_SCHEMA IN (?" + strings.Repeat(",?", len(databases)-1) + ")"
+	_ = db.QueryRow(query, args...).Scan(&size)
+	return size.Int64
+}
+
+func clientEnv(password string) []string {
+	env := os.Environ()
+	if password != "" {
+		env = append(env, fmt.Sprintf("MYSQL_PWD=%s", password))
+	}
+	return env
+}
+
+// byteCounter counts the bytes written through it
+type byteCounter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -1,13 +1,10 @@
 package restore_all
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -17,15 +14,24 @@ import (
 	backup_utils "sfDBTools/utils/backup"
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/common/format"
-	"sfDBTools/utils/compression"
-	"sfDBTools/utils/crypto"
 	"sfDBTools/utils/database"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+	restore_utils "sfDBTools/utils/restore"
+	"sfDBTools/utils/schema"
 )
 
 // RestoreAll restores all databases from a single backup file produced by the
 // "all databases" backup flow (contains dumps for multiple databases).
-func RestoreAll(options restoreUtils.RestoreOptions) error {
+func RestoreAll(ctx context.Context, options restoreUtils.RestoreOptions) error {
+	job := jobstatus.Start("restore-all", options.Host)
+	err := restoreAll(ctx, options, job)
+	job.Finish(err)
+	return err
+}
+
+func restoreAll(ctx context.Context, options restoreUtils.RestoreOptions, job *jobstatus.Tracker) error {
 	lg, err := logger.Get()
 	if err != nil {
 		return fmt.Errorf("failed to get logger: %w", err)
@@ -46,57 +52,33 @@ func RestoreAll(options restoreUtils.RestoreOptions) error {
 		defer database.CleanupMaxStatementTimeManager(timeManager)
 	}
 
+	job.SetStep("validating connection")
 	cfg := database.Config{Host: options.Host, Port: options.Port, User: options.User, Password: options.Password, DBName: options.DBName}
 	if err := database.ValidateConnection(cfg); err != nil {
 		return err
 	}
-
-	if options.VerifyChecksum {
-		verifyChecksumIfPossible(options.File, lg)
+	session, err := restoreUtils.ApplySessionVars(ctx, cfg, options.SessionVars)
+	if err != nil {
+		return err
 	}
-
-	file, err := os.Open(options.File)
+	globals, err := restoreUtils.ApplyGlobalVars(ctx, cfg, options.GlobalVars)
 	if err != nil {
-		return fmt.Errorf("failed to open backup file: %w", err)
+		return err
 	}
-	defer file.Close()
-
-	var reader io.ReadCloser = file
-	var closers []io.Closer
+	defer globals.Revert()
 
-	pathNoEnc := options.File
-	if strings.HasSuffix(strings.ToLower(pathNoEnc), ".enc") {
-		// Get encryption password from user (same method as config generate and backup)
-		encryptionPassword, err := crypto.GetEncryptionPassword("Enter encryption password to decrypt backup: ")
-		if err != nil {
-			return fmt.Errorf("failed to get encryption password: %w", err)
-		}
-
-		// Use the same key derivation method as config generate and backup
-		key, err := crypto.DeriveKeyWithPassword(encryptionPassword)
-		if err != nil {
-			return fmt.Errorf("failed to derive decryption key: %w", err)
-		}
-
-		lg.Debug("Derived decryption key", logger.Int("key_length", len(key)))
-
-		dr, err := crypto.NewGCMDecryptingReader(reader, key)
-		if err != nil {
-			return fmt.Errorf("failed to create decrypting reader: failed to decrypt data (incorrect password or data corruption): %w", err)
-		}
-		reader = io.NopCloser(dr)
-		pathNoEnc = strings.TrimSuffix(pathNoEnc, ".enc")
+	if options.VerifyChecksum {
+		verifyChecksumIfPossible(options.File, lg)
 	}
 
-	ctype := compression.DetectCompressionTypeFromFile(pathNoEnc)
-	if ctype != compression.CompressionNone {
-		dr, err := compression.NewDecompressingReader(reader, ctype)
-		if err != nil {
-			return fmt.Errorf("failed to create decompressing reader: %w", err)
-		}
-		reader = dr
-		closers = append(closers, dr)
+	stream, err := restore_utils.OpenBackupStream(options.File, restore_utils.StreamOptions{
+		EncryptionKeyFile: options.EncryptionKeyFile,
+		Raw:               job.Reader,
+	})
+	if err != nil {
+		return err
 	}
+	defer stream.Close()
 
 	args := []string{
 		fmt.Sprintf("--host=%s", options.Host),
@@ -104,32 +86,27 @@ func RestoreAll(options restoreUtils.RestoreOptions) error {
 		fmt.Sprintf("--user=%s", options.User),
 		"--force",
 	}
-
-	cmd := exec.Command("mysql", args...)
-	cmd.Stdin = reader
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if options.Password != "" {
-		cmd.Env = append(os.Environ(), fmt.Sprintf("MYSQL_PWD=%s", options.Password))
+	args = append(args, session.MysqlArgs()...)
+	if len(options.ExtraArgs) > 0 {
+		args = append(args, options.ExtraArgs...)
+		lg.Info("Passing extra mysql client options", logger.Strings("args", options.ExtraArgs))
 	}
 
 	lg.Info("Starting all databases restore", logger.String("file", options.File))
-	if err := cmd.Run(); err != nil {
+	job.SetStep("restoring")
+	if err := restore_utils.PipeToMySQL(ctx, session.DumpReader(stream), restore_utils.MySQLCommand{Args: args, Password: options.Password}); err != nil {
 		lg.Error("mysql restore failed", logger.Error(err))
 		return fmt.Errorf("mysql restore failed: %w", err)
 	}
 
-	for i := len(closers) - 1; i >= 0; i-- {
-		closers[i].Close()
-	}
-
 	lg.Info("All databases restore completed", logger.String("file", options.File))
+	job.SetStep("verifying")
 	DisplayRestoreSummary(options, startTime, lg, &configDB)
 
 	// Display backup metadata if available (skip database comparison for all-databases restore)
 	meta := metadataPath(options.File)
 	if meta != "" {
-		data, err := os.ReadFile(meta)
+		data, err := schema.ReadManifest(meta)
 		if err == nil {
 			var metaInfo backup_utils.BackupMetadata
 			if json.Unmarshal(data, &metaInfo) == nil {
@@ -153,7 +130,7 @@ func verifyChecksumIfPossible(filePath string, lg *logger.Logger) {
 		return
 	}
 
-	data, err := os.ReadFile(meta)
+	data, err := schema.ReadManifest(meta)
 	if err != nil {
 		lg.Warn("Failed to read metadata file", logger.String("metadata", meta), logger.Error(err))
 		return
@@ -170,16 +147,14 @@ func verifyChecksumIfPossible(filePath string, lg *logger.Logger) {
 		return
 	}
 
-	sum, err := calculateChecksum(filePath)
-	if err != nil {
-		lg.Warn("Checksum calculation failed", logger.String("file", filePath), logger.Error(err))
-		return
-	}
-
-	if strings.EqualFold(sum, metaInfo.Checksum) {
+	err = backup_utils.VerifyChecksum(filePath, metaInfo.Checksum, metaInfo.ChecksumAlgorithm)
+	switch {
+	case err == nil:
 		lg.Info("Checksum verified successfully", logger.String("file", filePath))
-	} else {
-		lg.Error("Checksum mismatch", logger.String("file", filePath), logger.String("expected", metaInfo.Checksum), logger.String("got", sum))
+	case errors.Is(err, fs.ErrChecksumMismatch):
+		lg.Error("Checksum mismatch", logger.String("file", filePath), logger.Error(err))
+	default:
+		lg.Warn("Checksum calculation failed", logger.String("file", filePath), logger.Error(err))
 	}
 }
 
@@ -200,19 +175,6 @@ func metadataPath(filePath string) string {
 	return base + ".meta.json"
 }
 
-func calculateChecksum(filename string) (string, error) {
-	f, err := os.Open(filename)
-	if err != nil {
-		return "", err
-	}
-	defer f.Close()
-	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return "", err
-	}
-	return hex.EncodeToString(h.Sum(nil)), nil
-}
-
 // DisplayRestoreOverview shows restore parameters before execution
 func DisplayRestoreOverview(options restoreUtils.RestoreOptions, startTime time.Time, filePath string, lg *logger.Logger) {
 
@@ -228,7 +190,7 @@ func DisplayRestoreOverview(options restoreUtils.RestoreOptions, startTime time.
 		lg.Warn("Metadata file not found, skipping display backup metadata", logger.String("file", filePath))
 		return
 	} else {
-		data, err := os.ReadFile(meta)
+		data, err := schema.ReadManifest(meta)
 		if err != nil {
 			lg.Warn("Failed to read metadata file", logger.String("metadata", meta), logger.Error(err))
 			return
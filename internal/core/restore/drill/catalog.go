@@ -0,0 +1,55 @@
+package drill
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"sfDBTools/utils/fs/policy"
+	"sfDBTools/utils/schema"
+)
+
+// LoadCatalog reads all drill records; a missing file yields an empty catalog
+func LoadCatalog(path string) ([]Record, error) {
+	data, err := schema.ReadCatalog(path)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, nil
+	}
+	var records []Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("invalid drill catalog %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// appendCatalog adds records to the catalog file, writing the current schema version
+func appendCatalog(path string, records []Record) error {
+	existing, err := LoadCatalog(path)
+	if err != nil {
+		return err
+	}
+	existing = append(existing, records...)
+
+	if err := policy.MkdirAll(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create catalog directory: %w", err)
+	}
+	if err := schema.WriteCatalog(path, existing); err != nil {
+		return fmt.Errorf("failed to write drill catalog: %w", err)
+	}
+	return nil
+}
+
+// LastSuccess returns the time of the newest successful drill per database
+func LastSuccess(records []Record) map[string]time.Time {
+	result := make(map[string]time.Time)
+	for _, r := range records {
+		if r.Success && r.DrillTime.After(result[r.Database]) {
+			result[r.Database] = r.DrillTime
+		}
+	}
+	return result
+}
@@ -0,0 +1,271 @@
+package drill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	restore "sfDBTools/internal/core/restore/single"
+	restoreUtils "sfDBTools/internal/core/restore/utils"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/schema"
+)
+
+// Run performs one restore drill per database: it picks a random recent backup, restores it
+// into a scratch schema, compares object counts with the backup metadata, drops the scratch
+// schema and records the outcome in the catalog. Databases without a successful drill inside
+// the configured window are returned as alerts.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	lg, _ := logger.Get()
+
+	candidates, err := findCandidates(opts.BaseDir, opts.MaxAge)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Databases) > 0 {
+		wanted := make(map[string]bool, len(opts.Databases))
+		for _, db := range opts.Databases {
+			wanted[db] = true
+		}
+		for db := range candidates {
+			if !wanted[db] {
+				delete(candidates, db)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(candidates))
+	for db := range candidates {
+		names = append(names, db)
+	}
+	sort.Strings(names)
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	result := &Result{}
+	for _, db := range names {
+		pool := candidates[db]
+		picked := pool[rng.Intn(len(pool))]
+		lg.Info("Starting restore drill",
+			logger.String("database", db),
+			logger.String("backup_file", picked.File),
+			logger.Int("candidates", len(pool)))
+		record := drillOne(ctx, opts, picked)
+		if record.Success {
+			lg.Info("Restore drill passed", logger.String("database", db), logger.String("duration", record.Duration))
+		} else {
+			lg.Error("Restore drill failed", logger.String("database", db), logger.String("error", record.Error))
+		}
+		result.Records = append(result.Records, record)
+	}
+
+	if len(result.Records) > 0 {
+		if err := appendCatalog(opts.CatalogPath, result.Records); err != nil {
+			return result, err
+		}
+	}
+
+	records, err := LoadCatalog(opts.CatalogPath)
+	if err != nil {
+		return result, err
+	}
+	result.Alerts = staleDatabases(names, opts.Databases, records, opts.Window)
+	for _, a := range result.Alerts {
+		lg.Error("No successful restore drill within window",
+			logger.String("database", a.Database),
+			logger.String("window", opts.Window.String()),
+			logger.String("last_success", lastSuccessString(a.LastSuccess)))
+	}
+	return result, nil
+}
+
+func drillOne(ctx context.Context, opts Options, c candidate) Record {
+	start := time.Now()
+	scratch := scratchName(opts.ScratchPrefix, c.Database, start)
+	record := Record{
+		Database:      c.Database,
+		BackupFile:    c.File,
+		BackupDate:    c.BackupDate.UTC(),
+		ScratchSchema: scratch,
+		DrillTime:     start.UTC(),
+	}
+
+	target := opts.Target
+	target.DBName = scratch
+	defer func() {
+		record.Duration = time.Since(start).Round(time.Second).String()
+	}()
+
+	err := restore.RestoreSingle(ctx, restoreUtils.RestoreOptions{
+		Host:              target.Host,
+		Port:              target.Port,
+		User:              target.User,
+		Password:          target.Password,
+		DBName:            scratch,
+		File:              c.File,
+		VerifyChecksum:    true,
+		RewriteReferences: true,
+		RewriteFrom:       c.Database,
+	})
+	if err == nil {
+		record.Mismatches, err = verify(ctx, target, c.Meta)
+	}
+	if !opts.KeepScratch {
+		if dropErr := dropScratch(opts.Target, scratch); dropErr != nil && err == nil {
+			err = dropErr
+		}
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+	record.Success = len(record.Mismatches) == 0
+	if !record.Success {
+		record.Error = "restored object counts differ from backup metadata"
+	}
+	return record
+}
+
+// verify compares the restored schema with the counts recorded at backup time
+func verify(ctx context.Context, target database.Config, meta backupMeta) ([]string, error) {
+	info.InvalidateDatabaseInfo(target)
+	restored, err := info.GetDatabaseInfo(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect restored schema: %w", err)
+	}
+	if restored.TableCount == 0 && restored.ViewCount == 0 {
+		return []string{"restored schema is empty"}, nil
+	}
+	if !meta.HasCounts {
+		return nil, nil
+	}
+
+	var mismatches []string
+	check := func(what string, want, got int) {
+		if want != got {
+			mismatches = append(mismatches, fmt.Sprintf("%s: backup %d, restored %d", what, want, got))
+		}
+	}
+	check("tables", meta.TableCount, restored.TableCount)
+	check("views", meta.ViewCount, restored.ViewCount)
+	check("routines", meta.RoutineCount, restored.RoutineCount)
+	check("triggers", meta.TriggerCount, restored.TriggerCount)
+	return mismatches, nil
+}
+
+func dropScratch(target database.Config, scratch string) error {
+	target.DBName = ""
+	db, err := database.GetWithoutDB(target)
+	if err != nil {
+		return fmt.Errorf("failed to connect to drop scratch schema: %w", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("DROP DATABASE IF EXISTS `" + scratch + "`"); err != nil {
+		return fmt.Errorf("failed to drop scratch schema %s: %w", scratch, err)
+	}
+	return nil
+}
+
+// findCandidates collects single-database backups newer than maxAge, grouped by database
+func findCandidates(baseDir string, maxAge time.Duration) (map[string][]candidate, error) {
+	if _, err := os.Stat(baseDir); err != nil {
+		return nil, fmt.Errorf("backup directory not accessible: %w", err)
+	}
+	cutoff := time.Now().Add(-maxAge)
+	result := make(map[string][]candidate)
+
+	err := filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		data, err := schema.ReadManifest(path)
+		if err != nil {
+			lg, _ := logger.Get()
+			lg.Warn("Skipping unreadable backup manifest", logger.String("path", path), logger.Error(err))
+			return nil
+		}
+		var meta backup_utils.BackupMetadata
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil
+		}
+		if meta.BackupType != "single" || meta.DatabaseName == "" || meta.OutputFile == "" {
+			return nil
+		}
+		if maxAge > 0 && meta.BackupDate.Before(cutoff) {
+			return nil
+		}
+		file := backup_utils.ArtifactPath(path, &meta)
+		if _, err := os.Stat(file); err != nil {
+			return nil
+		}
+
+		c := candidate{Database: meta.DatabaseName, File: file, BackupDate: meta.BackupDate}
+		if meta.DatabaseInfo != nil {
+			c.Meta = backupMeta{
+				TableCount:   meta.DatabaseInfo.TableCount,
+				ViewCount:    meta.DatabaseInfo.ViewCount,
+				RoutineCount: meta.DatabaseInfo.RoutineCount,
+				TriggerCount: meta.DatabaseInfo.TriggerCount,
+				HasCounts:    true,
+			}
+		}
+		result[meta.DatabaseName] = append(result[meta.DatabaseName], c)
+		return nil
+	})
+	return result, err
+}
+
+// staleDatabases lists databases whose newest successful drill is older than the window
+func staleDatabases(drilled, requested []string, records []Record, window time.Duration) []Alert {
+	if window <= 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, list := range [][]string{drilled, requested} {
+		for _, db := range list {
+			if !seen[db] {
+				seen[db] = true
+				names = append(names, db)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	last := LastSuccess(records)
+	cutoff := time.Now().Add(-window)
+	var alerts []Alert
+	for _, db := range names {
+		if t := last[db]; t.Before(cutoff) {
+			alerts = append(alerts, Alert{Database: db, LastSuccess: t})
+		}
+	}
+	return alerts
+}
+
+// scratchName builds a scratch schema name that stays within the 64 character identifier limit
+func scratchName(prefix, db string, t time.Time) string {
+	suffix := "_" + t.Format("20060102150405")
+	name := prefix + db
+	if max := 64 - len(suffix); len(name) > max {
+		name = name[:max]
+	}
+	return name + suffix
+}
+
+func lastSuccessString(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,61 @@
+package drill
+
+import (
+	"time"
+
+	"sfDBTools/utils/database"
+)
+
+// Options controls a restore drill run
+type Options struct {
+	Target        database.Config // server the scratch schemas are restored into
+	BaseDir       string          // backup storage directory to pick backups from
+	CatalogPath   string          // JSON file drill results are appended to
+	Databases     []string        // limit drills to these databases; all with backups when empty
+	MaxAge        time.Duration   // only backups newer than this are drill candidates
+	Window        time.Duration   // alert when a database has no successful drill within this window
+	ScratchPrefix string          // prefix for scratch schema names
+	KeepScratch   bool            // keep scratch schemas after the drill for inspection
+}
+
+// Record is one drill outcome stored in the catalog
+type Record struct {
+	Database      string    `json:"database"`
+	BackupFile    string    `json:"backup_file"`
+	BackupDate    time.Time `json:"backup_date"`
+	ScratchSchema string    `json:"scratch_schema"`
+	DrillTime     time.Time `json:"drill_time"`
+	Duration      string    `json:"duration"`
+	Success       bool      `json:"success"`
+	Error         string    `json:"error,omitempty"`
+	Mismatches    []string  `json:"mismatches,omitempty"`
+}
+
+// Alert flags a database whose backups have not been proven restorable recently
+type Alert struct {
+	Database    string
+	LastSuccess time.Time // zero when no successful drill was ever recorded
+}
+
+// Result is the outcome of a drill run
+type Result struct {
+	Records []Record
+	Alerts  []Alert
+}
+
+// candidate is a backup file that can be drilled
+type candidate struct {
+	Database   string
+	File       string
+	BackupDate time.Time
+	Meta       backupMeta
+}
+
+// backupMeta holds the object counts recorded at backup time
+type backupMeta struct {
+	TableCount   int
+	ViewCount    int
+	RoutineCount int
+	TriggerCount int
+	HasCounts    bool
+}
@@ -0,0 +1,285 @@
+// Package pitr recovers a database to a point in time by replaying the
+// binary logs of its server on top of a restored full backup.
+package pitr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/shutdown"
+)
+
+// StartPoint is where replay begins: the binlog position the backup is
+// consistent with or, when none was recorded, the time the dump started
+type StartPoint struct {
+	LogFile     string
+	LogPosition int64
+	Time        time.Time
+	// Exact is set when the position is the one the dump is consistent with;
+	// any other start may replay or skip the events of a few moments
+	Exact  bool
+	Origin string // where the start point came from, for reports
+}
+
+func (s StartPoint) String() string {
+	if s.LogFile != "" {
+		return fmt.Sprintf("%s:%d (%s)", s.LogFile, s.LogPosition, s.Origin)
+	}
+	return fmt.Sprintf("%s (%s)", s.Time.Local().Format(database.BinlogTime), s.Origin)
+}
+
+// ParseStart parses a --binlog-start value of the form file:position
+func ParseStart(s string) (StartPoint, error) {
+	file, pos, ok := strings.Cut(strings.TrimSpace(s), ":")
+	n, err := strconv.ParseInt(pos, 10, 64)
+	if !ok || file == "" || err != nil || n < 4 {
+		return StartPoint{}, fmt.Errorf("invalid binlog start %q (expected file:position, e.g. mysql-bin.000042:1234)", s)
+	}
+	return StartPoint{LogFile: file, LogPosition: n, Exact: true, Origin: "--binlog-start"}, nil
+}
+
+// StartFromManifest returns the start point recorded in a backup manifest.
+// The bookmark written into the dump by --master-data is exact; the position
+// read before the dump started and the dump's start time are approximations.
+func StartFromManifest(meta *backup_utils.BackupMetadata) (StartPoint, error) {
+	if r := meta.ReplicationInfo; r != nil {
+		if r.Bookmark != nil && r.Bookmark.LogFile != "" {
+			return StartPoint{LogFile: r.Bookmark.LogFile, LogPosition: r.Bookmark.LogPosition, Exact: true, Origin: "backup bookmark"}, nil
+		}
+		if r.LogFile != "" {
+			return StartPoint{LogFile: r.LogFile, LogPosition: r.LogPosition, Origin: "position before the dump"}, nil
+		}
+	}
+	if meta.BackupDate.IsZero() {
+		return StartPoint{}, fmt.Errorf("backup manifest has neither a binlog position nor a backup date; set the start with --binlog-start")
+	}
+	// The manifest is written when the dump ends; the snapshot was taken when it began
+	start := meta.BackupDate
+	if d, err := time.ParseDuration(meta.Duration); err == nil {
+		start = start.Add(-d)
+	}
+	return StartPoint{Time: start.Truncate(time.Second), Origin: "backup start time"}, nil
+}
+
+// Options describes one recovery
+type Options struct {
+	// Source is the server whose binary logs hold the changes after the backup
+	Source database.Config
+	// Target is the server and database the changes are applied to
+	Target database.Config
+	// SourceDatabase is the database name in the binlogs; when it differs from
+	// Target.DBName the events are rewritten to the target database
+	SourceDatabase string
+	Start          StartPoint
+	Until          time.Time
+}
+
+// Plan is a recovery whose binary logs have been checked to cover the window
+type Plan struct {
+	Options
+	Binlogs []string
+	// SkipGTIDs strips the GTIDs of MySQL events, which the source would
+	// otherwise skip as already applied when it is also the target
+	SkipGTIDs bool
+}
+
+// Result reports a finished replay
+type Result struct {
+	Binlogs  []string
+	Bytes    int64 // decoded SQL applied to the target
+	Duration time.Duration
+}
+
+// Prepare checks that binary logging is enabled on the source and that its
+// binlogs still reach back to the start point, before anything is restored
+func Prepare(ctx context.Context, opts Options) (*Plan, error) {
+	lg, _ := logger.Get()
+	if _, err := exec.LookPath("mysqlbinlog"); err != nil {
+		return nil, fmt.Errorf("mysqlbinlog not found in PATH: %w", err)
+	}
+	if !opts.Start.Time.IsZero() && !opts.Until.After(opts.Start.Time) {
+		return nil, fmt.Errorf("recovery time %s is before the backup was taken (%s)",
+			opts.Until.Local().Format(database.BinlogTime), opts.Start.Time.Local().Format(database.BinlogTime))
+	}
+
+	cfg := opts.Source
+	cfg.DBName = ""
+	db, err := database.GetWithoutDB(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to binlog source %s:%d: %w", cfg.Host, cfg.Port, err)
+	}
+	defer db.Close()
+
+	var logBin, version string
+	if err := db.QueryRowContext(ctx, "SELECT @@GLOBAL.log_bin, VERSION()").Scan(&logBin, &version); err != nil {
+		return nil, fmt.Errorf("failed to read binlog settings: %w", err)
+	}
+	if logBin != "1" && !strings.EqualFold(logBin, "ON") {
+		return nil, fmt.Errorf("binary logging is disabled on %s:%d; changes after the backup cannot be replayed", cfg.Host, cfg.Port)
+	}
+
+	rows, err := db.QueryContext(ctx, "SHOW BINARY LOGS")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list binary logs: %w", err)
+	}
+	cols, _ := rows.Columns()
+	var binlogs []string
+	for rows.Next() {
+		// Log_name, File_size and, depending on the version, Encrypted
+		values := make([]any, len(cols))
+		var name string
+		values[0] = &name
+		for i := 1; i < len(values); i++ {
+			values[i] = new(any)
+		}
+		if err := rows.Scan(values...); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		binlogs = append(binlogs, name)
+	}
+	rows.Close()
+	if len(binlogs) == 0 {
+		return nil, fmt.Errorf("server reports no binary logs")
+	}
+
+	plan := &Plan{Options: opts, SkipGTIDs: !strings.Contains(strings.ToLower(version), "mariadb")}
+	if opts.Start.LogFile != "" {
+		for i, name := range binlogs {
+			if name == opts.Start.LogFile {
+				plan.Binlogs = binlogs[i:]
+				break
+			}
+		}
+		if plan.Binlogs == nil {
+			return nil, fmt.Errorf("binlog %s is no longer on %s:%d (oldest is %s); the changes since the backup are lost",
+				opts.Start.LogFile, cfg.Host, cfg.Port, binlogs[0])
+		}
+	} else {
+		oldest, err := database.OldestBinlogEvent(cfg, binlogs[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the start of the oldest binlog %s: %w", binlogs[0], err)
+		}
+		if oldest.After(opts.Start.Time) {
+			return nil, fmt.Errorf("the oldest binlog on %s:%d starts at %s, after the backup was taken; the changes since the backup are lost",
+				cfg.Host, cfg.Port, oldest.Format(database.BinlogTime))
+		}
+		plan.Binlogs = binlogs
+	}
+
+	lg.Info("Point-in-time recovery planned",
+		logger.String("start", opts.Start.String()),
+		logger.String("until", opts.Until.Format(time.RFC3339)),
+		logger.Strings("binlogs", plan.Binlogs))
+	return plan, nil
+}
+
+// Args returns the mysqlbinlog arguments that decode the window of the plan
+func (p *Plan) Args() []string {
+	args := []string{
+		"--read-from-remote-server",
+		fmt.Sprintf("--host=%s", p.Source.Host),
+		fmt.Sprintf("--port=%d", p.Source.Port),
+		fmt.Sprintf("--user=%s", p.Source.User),
+	}
+	if p.Start.LogFile != "" {
+		// Applies to the first file only, which is the one holding the position
+		args = append(args, fmt.Sprintf("--start-position=%d", p.Start.LogPosition))
+	} else {
+		args = append(args, "--start-datetime="+p.Start.Time.Local().Format(database.BinlogTime))
+	}
+	// Events at or after the stop time are not replayed
+	args = append(args, "--stop-datetime="+p.Until.Local().Format(database.BinlogTime))
+	if p.SourceDatabase != "" && p.SourceDatabase != p.Target.DBName {
+		args = append(args, fmt.Sprintf("--rewrite-db=%s->%s", p.SourceDatabase, p.Target.DBName))
+	}
+	// Filtering happens after rewriting, so it names the target database
+	args = append(args, "--database="+p.Target.DBName)
+	if p.SkipGTIDs {
+		args = append(args, "--skip-gtids")
+	}
+	return append(args, p.Binlogs...)
+}
+
+// Replay decodes the binlogs with mysqlbinlog and applies them to the target
+// with the mysql client. The first failing event stops the replay.
+func Replay(ctx context.Context, plan *Plan) (*Result, error) {
+	lg, _ := logger.Get()
+	start := time.Now()
+
+	decode := shutdown.Command(ctx, "mysqlbinlog", plan.Args()...)
+	decode.Env = clientEnv(plan.Source)
+	var decodeErr bytes.Buffer
+	decode.Stderr = &decodeErr
+	events, err := decode.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	apply := shutdown.Command(ctx, "mysql",
+		fmt.Sprintf("--host=%s", plan.Target.Host),
+		fmt.Sprintf("--port=%d", plan.Target.Port),
+		fmt.Sprintf("--user=%s", plan.Target.User),
+		"--binary-mode")
+	apply.Env = clientEnv(plan.Target)
+	counted := &countingReader{r: events}
+	apply.Stdin = counted
+	var applyErr bytes.Buffer
+	apply.Stdout = os.Stdout
+	apply.Stderr = io.MultiWriter(os.Stderr, &applyErr)
+
+	lg.Info("Replaying binary logs",
+		logger.String("source", fmt.Sprintf("%s:%d", plan.Source.Host, plan.Source.Port)),
+		logger.String("database", plan.Target.DBName),
+		logger.String("start", plan.Start.String()),
+		logger.String("until", plan.Until.Format(time.RFC3339)))
+
+	if err := decode.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start mysqlbinlog: %w", err)
+	}
+	if err := apply.Run(); err != nil {
+		decode.Process.Kill()
+		decode.Wait()
+		return nil, fmt.Errorf("failed to apply binlog events after %d bytes: %w: %s", counted.n, err, strings.TrimSpace(applyErr.String()))
+	}
+	if err := decode.Wait(); err != nil {
+		return nil, fmt.Errorf("mysqlbinlog failed: %w: %s", err, strings.TrimSpace(decodeErr.String()))
+	}
+
+	result := &Result{Binlogs: plan.Binlogs, Bytes: counted.n, Duration: time.Since(start)}
+	lg.Info("Binary logs replayed",
+		logger.Int("binlogs", len(result.Binlogs)),
+		logger.Int64("bytes", result.Bytes),
+		logger.String("duration", result.Duration.Round(time.Millisecond).String()))
+	return result, nil
+}
+
+func clientEnv(cfg database.Config) []string {
+	env := os.Environ()
+	if cfg.Password != "" {
+		env = append(env, fmt.Sprintf("MYSQL_PWD=%s", cfg.Password))
+	}
+	return env
+}
+
+// countingReader counts the bytes passed to the mysql client
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -1,8 +1,9 @@
 package single
 
 import (
+	"context"
 	"encoding/json"
-	"os"
+	"fmt"
 	"time"
 
 	restoreUtils "sfDBTools/internal/core/restore/utils"
@@ -12,6 +13,8 @@ import (
 	"sfDBTools/utils/common/format"
 	"sfDBTools/utils/database"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/schema"
+	"sfDBTools/utils/terminal"
 )
 
 // DisplayRestoreOverview shows restore parameters before execution
@@ -30,7 +33,7 @@ func DisplayRestoreOverview(options restoreUtils.RestoreOptions, startTime time.
 		return
 	}
 
-	data, err := os.ReadFile(meta)
+	data, err := schema.ReadManifest(meta)
 	if err != nil {
 		lg.Warn("Failed to read metadata file", logger.String("metadata", meta), logger.Error(err))
 		return
@@ -55,6 +58,10 @@ func DisplayRestoreOverview(options restoreUtils.RestoreOptions, startTime time.
 		logger.String("size", common.FormatSize(metaInfo.FileSize)),
 	)
 
+	for table, where := range metaInfo.PartialTables {
+		terminal.PrintWarning(fmt.Sprintf("Backup holds only recent partitions of %s (%s)", table, where))
+	}
+
 	lg.Info("DB source metadata",
 		logger.String("db_name", metaInfo.DatabaseName),
 		logger.String("db_size", common.FormatSize(metaInfo.DatabaseInfo.SizeBytes)),
@@ -66,7 +73,7 @@ func DisplayRestoreOverview(options restoreUtils.RestoreOptions, startTime time.
 }
 
 // DisplayRestoreSummary shows restore summary after completion and returns collected db info
-func DisplayRestoreSummary(options restoreUtils.RestoreOptions, startTime time.Time, lg *logger.Logger, config *database.Config) (*info.DatabaseInfo, error) {
+func DisplayRestoreSummary(ctx context.Context, options restoreUtils.RestoreOptions, startTime time.Time, lg *logger.Logger, config *database.Config) (*info.DatabaseInfo, error) {
 	endTime := time.Now()
 	duration := endTime.Sub(startTime)
 
@@ -84,7 +91,8 @@ func DisplayRestoreSummary(options restoreUtils.RestoreOptions, startTime time.T
 
 	// Spinner := terminal.NewLoadingSpinner("Collecting database information...")
 	// Spinner.Start()
-	dbInfo, err := info.GetDatabaseInfo(*config)
+	info.InvalidateDatabaseInfo(*config)
+	dbInfo, err := info.GetDatabaseInfo(ctx, *config)
 	if err != nil {
 		lg.Warn("Failed to collect database information", logger.Error(err))
 		return nil, err
@@ -143,3 +151,31 @@ func compareValues(sourceValue, restoredValue interface{}) string {
 	}
 	return "MISMATCHED"
 }
+
+// DisplayRewriteReport logs the outcome of reference rewriting and lists references left untouched
+func DisplayRewriteReport(report *RewriteReport, lg *logger.Logger) {
+	lg.Info("Reference rewrite summary",
+		logger.String("from", report.From),
+		logger.String("to", report.To),
+		logger.Int("rewritten", report.Rewritten),
+		logger.Int("skipped_in_data", report.SkippedInData),
+		logger.Int("unsafe", report.UnsafeCount))
+
+	if report.SkippedInData > 0 {
+		terminal.PrintInfo(fmt.Sprintf("%d reference(s) to `%s` inside row data were left unchanged", report.SkippedInData, report.From))
+	}
+	if report.UnsafeCount == 0 {
+		terminal.PrintSuccess(fmt.Sprintf("Rewrote %d reference(s) from `%s` to `%s`", report.Rewritten, report.From, report.To))
+		return
+	}
+
+	terminal.PrintWarning(fmt.Sprintf("Rewrote %d reference(s); %d unquoted reference(s) to %s could not be rewritten safely, review these objects:",
+		report.Rewritten, report.UnsafeCount, report.From))
+	for _, s := range report.Unsafe {
+		lg.Warn("Reference not rewritten", logger.String("statement", s))
+		fmt.Printf("   - %s\n", s)
+	}
+	if report.UnsafeCount > len(report.Unsafe) {
+		fmt.Printf("   ... and %d more\n", report.UnsafeCount-len(report.Unsafe))
+	}
+}
@@ -1,17 +1,16 @@
 package single
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
-	"io"
-	"os"
+	"errors"
 	"path/filepath"
 	"strings"
 
 	"sfDBTools/internal/logger"
 	backup_utils "sfDBTools/utils/backup"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/schema"
 )
 
 // metadataPath returns the metadata JSON path for a backup file or empty string
@@ -31,55 +30,43 @@ func metadataPath(filePath string) string {
 	return base + ".json"
 }
 
-func calculateChecksum(filename string) (string, error) {
-	f, err := os.Open(filename)
-	if err != nil {
-		return "", err
-	}
-	defer f.Close()
-	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return "", err
-	}
-	return hex.EncodeToString(h.Sum(nil)), nil
-}
-
-// verifyChecksumIfPossible reads metadata and compares checksum if available
-func verifyChecksumIfPossible(filePath string, lg *logger.Logger) {
+// verifyChecksumIfPossible reads metadata and compares checksum if available,
+// returning the outcome for reports
+func verifyChecksumIfPossible(filePath string, lg *logger.Logger) string {
 	meta := metadataPath(filePath)
 	if meta == "" {
 		lg.Warn("Metadata file not found, skipping checksum verification", logger.String("file", filePath))
-		return
+		return "skipped, no metadata file"
 	}
 
-	data, err := os.ReadFile(meta)
+	data, err := schema.ReadManifest(meta)
 	if err != nil {
 		lg.Warn("Failed to read metadata file", logger.String("metadata", meta), logger.Error(err))
-		return
+		return "skipped, metadata unreadable"
 	}
 
 	var metaInfo backup_utils.BackupMetadata
 	if err := json.Unmarshal(data, &metaInfo); err != nil {
 		lg.Warn("Invalid metadata format", logger.String("metadata", meta), logger.Error(err))
-		return
+		return "skipped, metadata unreadable"
 	}
 
 	if metaInfo.Checksum == "" {
 		lg.Warn("Checksum not found in metadata, skipping verification", logger.String("metadata", meta))
-		return
+		return "skipped, no checksum recorded"
 	}
 
-	sum, err := calculateChecksum(filePath)
-	if err != nil {
-		lg.Warn("Checksum calculation failed", logger.String("file", filePath), logger.Error(err))
-		return
-	}
-
-	if strings.EqualFold(sum, metaInfo.Checksum) {
+	err = backup_utils.VerifyChecksum(filePath, metaInfo.Checksum, metaInfo.ChecksumAlgorithm)
+	switch {
+	case err == nil:
 		lg.Info("Checksum verified successfully", logger.String("file", filePath))
-	} else {
-		lg.Error("Checksum mismatch", logger.String("file", filePath), logger.String("expected", metaInfo.Checksum), logger.String("got", sum))
+		return "verified"
+	case errors.Is(err, fs.ErrChecksumMismatch):
+		lg.Error("Checksum mismatch", logger.String("file", filePath), logger.Error(err))
+		return "MISMATCH"
 	}
+	lg.Warn("Checksum calculation failed", logger.String("file", filePath), logger.Error(err))
+	return "failed to calculate: " + err.Error()
 }
 
 // ProcessMetadataAfterRestore reads metadata.json (if present) and compares with dbInfo
@@ -90,7 +77,7 @@ func ProcessMetadataAfterRestore(filePath string, dbInfo *info.DatabaseInfo, lg
 		return
 	}
 
-	data, err := os.ReadFile(meta)
+	data, err := schema.ReadManifest(meta)
 	if err != nil {
 		lg.Warn("Failed to read metadata file", logger.String("metadata", meta), logger.Error(err))
 		return
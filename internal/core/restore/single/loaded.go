@@ -0,0 +1,91 @@
+package single
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// LoadedTables records which table sections of a dump were completely loaded
+// by a failed restore, so a retry with --skip-loaded can leave them alone
+type LoadedTables struct {
+	File        string        `json:"file"`
+	Database    string        `json:"database"`
+	FileSize    int64         `json:"file_size"`
+	FileModTime time.Time     `json:"file_mod_time"`
+	Tables      []LoadedTable `json:"tables"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// LoadedTable is one table section of the dump. Index is the position of the
+// section in the dump: a view appears twice (placeholder table and view), so
+// the name alone does not identify a section.
+type LoadedTable struct {
+	Index int    `json:"index"`
+	Table string `json:"table"`
+}
+
+// tableSegment is the run of statements belonging to one table: its DROP and
+// CREATE, the data between LOCK and UNLOCK TABLES, and its triggers
+type tableSegment struct {
+	index   int
+	table   string
+	first   int // statement numbers
+	last    int
+	skipped bool // loaded by an earlier run, not sent to mysql
+}
+
+func loadedPath(file string) string {
+	return file + ".loaded.json"
+}
+
+// readLoadedTables returns the sections to skip for file, keyed by index. A
+// record written for a different version of the file is an error: section
+// numbers would no longer match.
+func readLoadedTables(file, dbName string) (map[int]string, error) {
+	data, err := readStateFile(loadedPath(file))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lt LoadedTables
+	if err := json.Unmarshal(data, &lt); err != nil {
+		return nil, fmt.Errorf("invalid loaded tables file %s: %w", loadedPath(file), err)
+	}
+	fi, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() != lt.FileSize || !fi.ModTime().Equal(lt.FileModTime) {
+		return nil, fmt.Errorf("%s changed since the loaded tables were recorded; restore without --skip-loaded", file)
+	}
+	if lt.Database != dbName {
+		return nil, fmt.Errorf("loaded tables were recorded for database %s, not %s", lt.Database, dbName)
+	}
+	skip := make(map[int]string, len(lt.Tables))
+	for _, t := range lt.Tables {
+		skip[t.Index] = t.Table
+	}
+	return skip, nil
+}
+
+// writeLoadedTables records tables for file and returns the path used
+func writeLoadedTables(file, dbName string, tables []LoadedTable) (string, error) {
+	lt := LoadedTables{File: file, Database: dbName, Tables: tables, UpdatedAt: time.Now().UTC()}
+	if fi, err := os.Stat(file); err == nil {
+		lt.FileSize = fi.Size()
+		lt.FileModTime = fi.ModTime()
+	}
+	data, err := json.MarshalIndent(lt, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return writeStateFile(loadedPath(file), data)
+}
+
+func clearLoadedTables(file string) {
+	removeStateFile(loadedPath(file))
+}
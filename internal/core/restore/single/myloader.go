@@ -0,0 +1,93 @@
+package single
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"sfDBTools/internal/config"
+	restoreUtils "sfDBTools/internal/core/restore/utils"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/shutdown"
+	"sfDBTools/utils/tempdir"
+)
+
+// restoreWithMyloader unpacks a backup of the mydumper engine from the
+// decrypted and decompressed reader into the temp directory and loads it
+// into options.DBName with myloader
+func restoreWithMyloader(ctx context.Context, options restoreUtils.RestoreOptions, reader io.Reader, job *jobstatus.Tracker, lg *logger.Logger) error {
+	switch {
+	case options.ResumeFrom > 0, options.SkipLoaded:
+		return fmt.Errorf("resuming a restore is not supported for mydumper backups")
+	case options.Sanitize:
+		return fmt.Errorf("--sanitize is not supported for mydumper backups")
+	case len(options.ExtraArgs) > 0:
+		return fmt.Errorf("--restore-arg passes options to the mysql client and cannot be used with mydumper backups")
+	}
+	if _, err := exec.LookPath("myloader"); err != nil {
+		return fmt.Errorf("restoring a mydumper backup needs the myloader binary on PATH: %w", err)
+	}
+
+	staging, err := os.MkdirTemp(tempdir.Dir(), "sfdb-myloader-")
+	if err != nil {
+		return fmt.Errorf("failed to create extract directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	job.SetStep("extracting")
+	lg.Info("Extracting mydumper backup", logger.String("dir", staging))
+	if err := backup_utils.ExtractTar(reader, staging); err != nil {
+		return err
+	}
+	dumpDir := filepath.Join(staging, "mydumper")
+	if _, err := os.Stat(dumpDir); err != nil {
+		return fmt.Errorf("backup does not contain a mydumper dump directory")
+	}
+
+	job.SetStep("restoring")
+	return RunMyloader(ctx, options, dumpDir, staging, lg)
+}
+
+// RunMyloader loads the mydumper dump in dumpDir with myloader. The client
+// option file is written to workDir. Tables go to options.DBName, or to the
+// databases they were dumped from when it is empty.
+func RunMyloader(ctx context.Context, options restoreUtils.RestoreOptions, dumpDir, workDir string, lg *logger.Logger) error {
+	defaultsFile, err := database.WriteDefaultsFile(workDir, "myloader", options.User, options.Password)
+	if err != nil {
+		return err
+	}
+
+	threads := runtime.NumCPU()
+	if cfg, err := config.Get(); err == nil && cfg.Backup.Mydumper.Threads > 0 {
+		threads = cfg.Backup.Mydumper.Threads
+	}
+	// --defaults-file must come first
+	args := []string{
+		"--defaults-file=" + defaultsFile,
+		"--host=" + options.Host,
+		"--port=" + strconv.Itoa(options.Port),
+		"--directory=" + dumpDir,
+	}
+	if options.DBName != "" {
+		args = append(args, "--database="+options.DBName)
+	}
+	args = append(args, "--threads="+strconv.Itoa(threads), "--overwrite-tables")
+
+	lg.Info("Executing myloader", logger.String("db", options.DBName), logger.Int("threads", threads))
+	cmd := shutdown.Command(ctx, "myloader", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		lg.Error("myloader restore failed", logger.Error(err))
+		return fmt.Errorf("myloader restore failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package single
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sfDBTools/internal/core/restore/pitr"
+	restoreUtils "sfDBTools/internal/core/restore/utils"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/schema"
+	"sfDBTools/utils/terminal"
+)
+
+// preparePointInTime resolves where binlog replay starts and checks that the
+// binlogs still cover the window, so a recovery that cannot complete fails
+// before the backup is loaded
+func preparePointInTime(ctx context.Context, options restoreUtils.RestoreOptions, target database.Config, job *jobstatus.Tracker, lg *logger.Logger) (*pitr.Plan, error) {
+	var meta backup_utils.BackupMetadata
+	haveMeta := false
+	if path := metadataPath(options.File); path != "" {
+		if data, err := schema.ReadManifest(path); err == nil && json.Unmarshal(data, &meta) == nil {
+			haveMeta = true
+		}
+	}
+
+	var start pitr.StartPoint
+	var err error
+	switch {
+	case options.BinlogStart != "":
+		start, err = pitr.ParseStart(options.BinlogStart)
+	case haveMeta:
+		start, err = pitr.StartFromManifest(&meta)
+	default:
+		err = fmt.Errorf("no backup manifest found for %s; set the binlog start position with --binlog-start", options.File)
+	}
+	if err != nil {
+		return nil, common.WithExitCode(err, common.ExitUsage)
+	}
+	if !start.Exact {
+		job.Warn(fmt.Sprintf("Replay starts at the %s, not at the exact position of the backup; changes made while the dump started may be applied twice or missed", start.Origin))
+	}
+
+	source := options.BinlogSource
+	if haveMeta && meta.Host != "" && (meta.Host != source.Host || meta.Port != source.Port) {
+		job.Warn(fmt.Sprintf("Backup was taken from %s:%d but binlogs are read from %s:%d", meta.Host, meta.Port, source.Host, source.Port))
+	}
+	sourceDB := options.DBName
+	if haveMeta && meta.DatabaseName != "" {
+		sourceDB = meta.DatabaseName
+	}
+
+	plan, err := pitr.Prepare(ctx, pitr.Options{
+		Source:         source,
+		Target:         target,
+		SourceDatabase: sourceDB,
+		Start:          start,
+		Until:          options.Until,
+	})
+	if err != nil {
+		return nil, err
+	}
+	lg.Info("Point-in-time recovery enabled",
+		logger.String("start", start.String()),
+		logger.String("until", options.Until.Format(time.RFC3339)))
+	return plan, nil
+}
+
+// replayBinlogs applies the binlog window of plan on top of the restored backup
+func replayBinlogs(ctx context.Context, plan *pitr.Plan, job *jobstatus.Tracker) error {
+	job.SetStep("replaying binlogs")
+	terminal.PrintSubHeader(fmt.Sprintf("Replaying binary logs until %s", plan.Until.Local().Format("2006-01-02 15:04:05")))
+	result, err := pitr.Replay(ctx, plan)
+	if err != nil {
+		return fmt.Errorf("backup restored, but point-in-time recovery failed: %w", err)
+	}
+	job.Detail("Recovered until", plan.Until.Local().Format("2006-01-02 15:04:05"))
+	job.Detail("Binlog start", plan.Start.String())
+	job.Detail("Binlogs replayed", fmt.Sprintf("%d (%s of events)", len(result.Binlogs), common.FormatSize(result.Bytes)))
+	terminal.PrintSuccess(fmt.Sprintf("Replayed %d binlog(s) in %s", len(result.Binlogs), result.Duration.Round(time.Second)))
+	return nil
+}
@@ -1,41 +1,63 @@
 package single
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"strings"
-	"sync/atomic"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
 
+	"sfDBTools/internal/core/restore/pitr"
 	restoreUtils "sfDBTools/internal/core/restore/utils"
 	"sfDBTools/internal/logger"
-	"sfDBTools/utils/compression"
-	"sfDBTools/utils/crypto"
+	backup_utils "sfDBTools/utils/backup"
 	"sfDBTools/utils/database"
+	"sfDBTools/utils/jobstatus"
+	restore_utils "sfDBTools/utils/restore"
+	"sfDBTools/utils/sanitize"
+	"sfDBTools/utils/terminal"
 )
 
-// countingReader counts bytes read through it in an atomic counter
-type countingReader struct {
-	r     io.Reader
-	count int64
-}
+// rawProgressInterval is how often the progress bar follows the backup file
+const rawProgressInterval = 200 * time.Millisecond
 
-func (c *countingReader) Read(p []byte) (int, error) {
-	n, err := c.r.Read(p)
-	if n > 0 {
-		atomic.AddInt64(&c.count, int64(n))
+// trackRawProgress moves bar with the bytes read from the backup file until
+// the returned function is called, which also finishes the bar
+func trackRawProgress(stream *restore_utils.BackupStream, bar *progressbar.ProgressBar) func() {
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(rawProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				_ = bar.Set64(stream.RawBytes())
+				return
+			case <-ticker.C:
+				_ = bar.Set64(stream.RawBytes())
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+		_ = bar.Finish()
 	}
-	return n, err
 }
 
-func (c *countingReader) Count() int64 { return atomic.LoadInt64(&c.count) }
-
 // RestoreSingle restores a single database from backup file
-func RestoreSingle(options restoreUtils.RestoreOptions) error {
+func RestoreSingle(ctx context.Context, options restoreUtils.RestoreOptions) error {
+	job := jobstatus.Start("restore-single", options.DBName)
+	err := restoreSingle(ctx, options, job)
+	job.Finish(err)
+	return err
+}
+
+func restoreSingle(ctx context.Context, options restoreUtils.RestoreOptions, job *jobstatus.Tracker) error {
 	lg, err := logger.Get()
 	if err != nil {
 		return fmt.Errorf("failed to get logger: %w", err)
@@ -60,6 +82,7 @@ func RestoreSingle(options restoreUtils.RestoreOptions) error {
 		defer database.CleanupMaxStatementTimeManager(timeManager)
 	}
 
+	job.SetStep("validating connection")
 	cfg := database.Config{Host: options.Host, Port: options.Port, User: options.User, Password: options.Password, DBName: options.DBName}
 	if err := database.ValidateConnection(cfg); err != nil {
 		return err
@@ -67,131 +90,167 @@ func RestoreSingle(options restoreUtils.RestoreOptions) error {
 	if err := database.EnsureDatabase(cfg); err != nil {
 		return err
 	}
+	var recovery *pitr.Plan
+	if !options.Until.IsZero() {
+		job.SetStep("checking binlogs")
+		if recovery, err = preparePointInTime(ctx, options, cfg, job, lg); err != nil {
+			return err
+		}
+	}
+	if options.SchemaDiff {
+		before, err := captureSchema(ctx, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to capture schema before restore: %w", err)
+		}
+		defer reportSchemaDiff(ctx, options, cfg, before, job, lg)
+	}
+	session, err := restoreUtils.ApplySessionVars(ctx, cfg, options.SessionVars)
+	if err != nil {
+		return err
+	}
+	globals, err := restoreUtils.ApplyGlobalVars(ctx, cfg, options.GlobalVars)
+	if err != nil {
+		return err
+	}
+	defer globals.Revert()
 
 	if options.VerifyChecksum {
-		verifyChecksumIfPossible(options.File, lg)
+		job.Detail("Checksum verification", verifyChecksumIfPossible(options.File, lg))
 	}
 
-	file, err := os.Open(options.File)
+	// Decrypt and decompress on the way to the client; progress is tracked on
+	// the raw file so the percentage is accurate for any backup
+	stream, err := restore_utils.OpenBackupStream(options.File, restore_utils.StreamOptions{
+		EncryptionKeyFile: options.EncryptionKeyFile,
+		Raw:               job.Reader,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to open backup file: %w", err)
+		return err
 	}
-	defer file.Close()
-
-	var reader io.ReadCloser = file
-	var closers []io.Closer
+	defer stream.Close()
+	var reader io.Reader = stream
 
-	pathNoEnc := options.File
-	if strings.HasSuffix(strings.ToLower(pathNoEnc), ".enc") {
-		// Get encryption password from user (same method as config generate and backup)
-		encryptionPassword, err := crypto.GetEncryptionPassword("Enter encryption password to decrypt backup: ")
-		if err != nil {
-			return fmt.Errorf("failed to get encryption password: %w", err)
+	if backup_utils.IsMydumperArchive(stream.Plain) {
+		if err := restoreWithMyloader(ctx, options, reader, job, lg); err != nil {
+			return err
 		}
-
-		// Use the same key derivation method as config generate and backup
-		key, err := crypto.DeriveKeyWithPassword(encryptionPassword)
-		if err != nil {
-			return fmt.Errorf("failed to derive decryption key: %w", err)
-		}
-
-		lg.Debug("Derived decryption key", logger.Int("key_length", len(key)))
-
-		dr, err := crypto.NewGCMDecryptingReader(reader, key)
-		if err != nil {
-			return fmt.Errorf("failed to create decrypting reader: failed to decrypt data (incorrect password or data corruption): %w", err)
+		lg.Info("Restore completed", logger.String("db", options.DBName), logger.String("engine", backup_utils.EngineMydumper))
+		job.Detail("Backup file", options.File)
+		if recovery != nil {
+			if err := replayBinlogs(ctx, recovery, job); err != nil {
+				return err
+			}
 		}
-		reader = io.NopCloser(dr)
-		pathNoEnc = strings.TrimSuffix(pathNoEnc, ".enc")
-	}
-
-	ctype := compression.DetectCompressionTypeFromFile(pathNoEnc)
-	if ctype != compression.CompressionNone {
-		dr, err := compression.NewDecompressingReader(reader, ctype)
-		if err != nil {
-			return fmt.Errorf("failed to create decompressing reader: %w", err)
-		}
-		reader = dr
-		closers = append(closers, dr)
+		job.SetStep("verifying")
+		dbInfo, _ := DisplayRestoreSummary(ctx, options, startTime, lg, &configDB)
+		ProcessMetadataAfterRestore(options.File, dbInfo, lg)
+		return nil
 	}
 
 	args := []string{
 		fmt.Sprintf("--host=%s", options.Host),
 		fmt.Sprintf("--port=%d", options.Port),
 		fmt.Sprintf("--user=%s", options.User),
-		"--force",
-		options.DBName,
 	}
+	if !options.StopOnError {
+		args = append(args, "--force")
+	}
+	args = append(args, session.MysqlArgs()...)
+	if len(options.ExtraArgs) > 0 {
+		args = append(args, options.ExtraArgs...)
+		job.Detail("Client options", strings.Join(options.ExtraArgs, " "))
+		lg.Info("Passing extra mysql client options", logger.Strings("args", options.ExtraArgs))
+	}
+	args = append(args, options.DBName)
 
-	// Wrap the final reader with a counting reader so we can display progress
-	counting := &countingReader{r: reader}
+	var rewriter *referenceRewriter
+	if options.RewriteReferences {
+		from := options.RewriteFrom
+		if from == "" {
+			from = sourceDatabaseFromMetadata(options.File)
+		}
+		if from == "" {
+			return fmt.Errorf("cannot determine source database name for reference rewriting; set it with --rewrite-from")
+		}
+		if from != options.DBName {
+			lg.Info("Rewriting schema-qualified references",
+				logger.String("from", from),
+				logger.String("to", options.DBName))
+			rewriter = newReferenceRewriter(reader, from, options.DBName)
+			reader = rewriter
+		}
+	}
 
-	cmd := exec.Command("mysql", args...)
-	cmd.Stdin = counting
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if options.Password != "" {
-		cmd.Env = append(os.Environ(), fmt.Sprintf("MYSQL_PWD=%s", options.Password))
+	var sanitizer *sanitize.Reader
+	if options.Sanitize {
+		sanitizer = sanitize.NewReader(reader, options.SanitizeEngine)
+		reader = sanitizer
 	}
 
-	// Determine whether we can compute an accurate total for percentage.
-	// Accurate if file is not encrypted and not compressed (we can use raw file size).
-	wasEncrypted := strings.HasSuffix(strings.ToLower(options.File), ".enc")
-	accuratePercentage := !wasEncrypted && (compression.DetectCompressionTypeFromFile(options.File) == compression.CompressionNone)
-	var totalBytes int64 = 0
-	if fi, err := os.Stat(options.File); err == nil {
-		totalBytes = fi.Size()
+	var skipTables map[int]string
+	if options.SkipLoaded {
+		if skipTables, err = readLoadedTables(options.File, options.DBName); err != nil {
+			return err
+		}
+		if len(skipTables) == 0 {
+			job.Warn("No loaded tables recorded for this backup; restoring everything")
+		} else {
+			lg.Info("Skipping tables loaded by an earlier restore", logger.Int("tables", len(skipTables)))
+			terminal.PrintInfo(fmt.Sprintf("Skipping %d table(s) already loaded by an earlier restore", len(skipTables)))
+		}
 	}
 
+	// Track statements so a failing one can be reported and resumed from
+	tracker := newStatementTracker(reader, options.ResumeFrom, skipTables)
+	if options.ResumeFrom > 1 {
+		lg.Info("Resuming restore", logger.Int("from_statement", options.ResumeFrom))
+	}
+
+	stderr := &cappedBuffer{}
 	lg.Info("Starting restore", logger.String("db", options.DBName))
+	job.SetStep("restoring")
 
-	// Setup progressbar (use accurate total only when not compressed and not encrypted)
-	var readerForCmd io.Reader = counting
-	var bar *progressbar.ProgressBar
-	if accuratePercentage && totalBytes > 0 {
-		bar = progressbar.NewOptions64(totalBytes,
-			progressbar.OptionSetDescription("Restoring"),
-			progressbar.OptionSetWidth(40),
-			progressbar.OptionShowBytes(true),
-			progressbar.OptionSetElapsedTime(true),
-			progressbar.OptionSetPredictTime(false),
-		)
-		// wrap counting so bar is updated as bytes flow
-		readerForCmd = io.TeeReader(counting, bar)
-	} else {
-		// Unknown total: show bytes and elapsed only
-		bar = progressbar.NewOptions(-1,
-			progressbar.OptionSetDescription("Restoring"),
-			progressbar.OptionSetWidth(40),
-			progressbar.OptionShowBytes(true),
-			progressbar.OptionSetElapsedTime(true),
-			progressbar.OptionSpinnerType(14),
-		)
-		readerForCmd = io.TeeReader(counting, bar)
-	}
-
-	cmd.Stdin = io.NopCloser(readerForCmd)
-
-	if err := cmd.Run(); err != nil {
-		// ensure bar finished/cleared
-		if bar != nil {
-			_ = bar.Finish()
-		}
+	bar := progressbar.NewOptions64(stream.Size,
+		progressbar.OptionSetDescription("Restoring"),
+		progressbar.OptionSetWidth(40),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionSetElapsedTime(true),
+		progressbar.OptionSetPredictTime(false),
+	)
+	stopBar := trackRawProgress(stream, bar)
+	err = restore_utils.PipeToMySQL(ctx, session.DumpReader(tracker), restore_utils.MySQLCommand{
+		Args:     args,
+		Password: options.Password,
+		Stderr:   io.MultiWriter(os.Stderr, stderr),
+	})
+	stopBar()
+	if err != nil {
 		lg.Error("mysql restore failed", logger.Error(err))
-		return err
-	}
-	if bar != nil {
-		_ = bar.Finish()
+		return reportFailedStatements(options, tracker, stderr.String(), err)
 	}
 
-	for i := len(closers) - 1; i >= 0; i-- {
-		// Best-effort close any readers (decompressor/decrypters)
-		_ = closers[i].Close()
+	lg.Info("Restore completed", logger.String("db", options.DBName), logger.Int("statements", tracker.Statements()))
+	job.Detail("Backup file", options.File)
+	job.Detail("Statements applied", fmt.Sprintf("%d", tracker.Statements()))
+	if options.ResumeFrom > 0 {
+		clearResumePoint(options.File)
+	}
+	clearLoadedTables(options.File)
+	if recovery != nil {
+		if err := replayBinlogs(ctx, recovery, job); err != nil {
+			return err
+		}
+	}
+	job.SetStep("verifying")
+	if rewriter != nil {
+		DisplayRewriteReport(rewriter.report, lg)
+	}
+	if sanitizer != nil {
+		sanitize.Display(sanitizer.Report(), lg)
+		job.Detail("Sanitized syntax", fmt.Sprintf("%d change(s)", sanitizer.Report().Total()))
 	}
-
-	lg.Info("Restore completed", logger.String("db", options.DBName))
 	// Display summary and collect DB info (single-db restore only)
-	dbInfo, _ := DisplayRestoreSummary(options, startTime, lg, &configDB)
+	dbInfo, _ := DisplayRestoreSummary(ctx, options, startTime, lg, &configDB)
 
 	// Process metadata (read metadata file and compare with collected db info)
 	ProcessMetadataAfterRestore(options.File, dbInfo, lg)
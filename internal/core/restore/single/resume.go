@@ -0,0 +1,106 @@
+package single
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/utils/fs/policy"
+	"sfDBTools/utils/tempdir"
+)
+
+// ResumePoint records where a restore stopped so it can be continued with --resume-from
+type ResumePoint struct {
+	File      string    `json:"file"`
+	Database  string    `json:"database"`
+	Statement int       `json:"statement"` // failed statement; the resume starts by re-running it
+	Applied   int       `json:"statements_applied"`
+	Line      int       `json:"line"`
+	Table     string    `json:"table,omitempty"`
+	Text      string    `json:"statement_text"`
+	Error     string    `json:"error"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// resumePath is where the resume point of a backup file is stored by default
+func resumePath(file string) string {
+	return file + ".resume.json"
+}
+
+// writeResumePoint saves rp next to the backup file, or in the temp dir when the
+// backup directory is read-only, and returns the path used
+func writeResumePoint(rp ResumePoint) (string, error) {
+	data, err := json.MarshalIndent(rp, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return writeStateFile(resumePath(rp.File), data)
+}
+
+// clearResumePoint removes a saved resume point once the restore has completed
+func clearResumePoint(file string) {
+	removeStateFile(resumePath(file))
+}
+
+// stateFallbackPath is where a state file goes when the backup directory is read-only
+func stateFallbackPath(path string) string {
+	return filepath.Join(tempdir.Dir(), "sfDBTools", "restore", filepath.Base(path))
+}
+
+// writeStateFile writes restore state next to the backup file, falling back to
+// the temp dir, and returns the path used. The temp dir is shared, so the
+// fallback directory is private to the user and the file follows the policy.
+func writeStateFile(path string, data []byte) (string, error) {
+	if err := policy.WriteFile(path, data); err == nil {
+		return path, nil
+	}
+	fallback := stateFallbackPath(path)
+	if err := os.MkdirAll(filepath.Dir(fallback), 0700); err != nil {
+		return "", err
+	}
+	return fallback, policy.WriteFile(fallback, data)
+}
+
+// readStateFile reads restore state from next to the backup file or the temp dir
+func readStateFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil || !os.IsNotExist(err) {
+		return data, err
+	}
+	return os.ReadFile(stateFallbackPath(path))
+}
+
+func removeStateFile(path string) {
+	os.Remove(path)
+	os.Remove(stateFallbackPath(path))
+}
+
+// ParseResumeFrom interprets a --resume-from value: a statement number, or the
+// path of a resume point file written by a failed restore. The backup file
+// recorded in the resume point is returned when a file was given.
+func ParseResumeFrom(value string) (statement int, file string, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, "", nil
+	}
+	if n, convErr := strconv.Atoi(value); convErr == nil {
+		if n < 1 {
+			return 0, "", fmt.Errorf("--resume-from must be a statement number of at least 1")
+		}
+		return n, "", nil
+	}
+
+	data, err := os.ReadFile(value)
+	if err != nil {
+		return 0, "", fmt.Errorf("--resume-from is neither a statement number nor a readable resume file: %w", err)
+	}
+	var rp ResumePoint
+	if err := json.Unmarshal(data, &rp); err != nil || rp.Statement < 1 {
+		return 0, "", fmt.Errorf("invalid resume file %s", value)
+	}
+	return rp.Statement, rp.File, nil
+}
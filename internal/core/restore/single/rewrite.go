@@ -0,0 +1,137 @@
+package single
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sfDBTools/utils/schema"
+
+	backup_utils "sfDBTools/utils/backup"
+)
+
+// maxUnsafeSamples caps how many unrewritten references are kept for the report
+const maxUnsafeSamples = 20
+
+// RewriteReport summarizes what the reference rewriter changed and what it left alone
+type RewriteReport struct {
+	From          string
+	To            string
+	Rewritten     int      // quoted `from`. references rewritten in DDL statements
+	SkippedInData int      // quoted references found inside INSERT data, left untouched
+	Unsafe        []string // unquoted references that were not rewritten (first maxUnsafeSamples)
+	UnsafeCount   int
+}
+
+// referenceRewriter rewrites schema-qualified references (`from`.obj -> `to`.obj) in a
+// mysqldump stream. INSERT/REPLACE lines are copied verbatim so row data is never altered;
+// all other lines (DDL, views, routines, triggers) are rewritten. Unquoted references are
+// only reported because they cannot be told apart from aliases or string content.
+type referenceRewriter struct {
+	src       *bufio.Reader
+	fromQuote []byte
+	toQuote   []byte
+	useFrom   []byte
+	useTo     []byte
+	unquoted  *regexp.Regexp
+	pending   []byte
+	inData    bool
+	report    *RewriteReport
+}
+
+func newReferenceRewriter(r io.Reader, from, to string) *referenceRewriter {
+	return &referenceRewriter{
+		src:       bufio.NewReaderSize(r, 1<<20),
+		fromQuote: []byte("`" + from + "`."),
+		toQuote:   []byte("`" + to + "`."),
+		useFrom:   []byte("USE `" + from + "`"),
+		useTo:     []byte("USE `" + to + "`"),
+		unquoted:  regexp.MustCompile(`(?i)(^|[^\w` + "`" + `.$])` + regexp.QuoteMeta(from) + `\.[\w` + "`" + `]`),
+		report:    &RewriteReport{From: from, To: to},
+	}
+}
+
+func (rw *referenceRewriter) Read(p []byte) (int, error) {
+	for len(rw.pending) == 0 {
+		if err := rw.fill(); err != nil {
+			if len(rw.pending) > 0 {
+				break
+			}
+			return 0, err
+		}
+	}
+	n := copy(p, rw.pending)
+	rw.pending = rw.pending[n:]
+	return n, nil
+}
+
+// fill loads the next chunk into pending. Data lines are streamed in buffer-sized pieces,
+// other lines are read whole so references spanning a buffer boundary are still found.
+func (rw *referenceRewriter) fill() error {
+	if rw.inData {
+		chunk, err := rw.src.ReadSlice('\n')
+		rw.report.SkippedInData += bytes.Count(chunk, rw.fromQuote)
+		rw.pending = append(rw.pending[:0], chunk...)
+		if err == bufio.ErrBufferFull {
+			return nil
+		}
+		rw.inData = false
+		return err
+	}
+
+	if head, _ := rw.src.Peek(7); bytes.HasPrefix(head, []byte("INSERT ")) || bytes.HasPrefix(head, []byte("REPLACE")) {
+		rw.inData = true
+		return rw.fill()
+	}
+
+	var line []byte
+	for {
+		chunk, err := rw.src.ReadSlice('\n')
+		line = append(line, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		rw.pending = rw.rewriteLine(line)
+		return err
+	}
+}
+
+func (rw *referenceRewriter) rewriteLine(line []byte) []byte {
+	if n := bytes.Count(line, rw.fromQuote); n > 0 {
+		rw.report.Rewritten += n
+		line = bytes.ReplaceAll(line, rw.fromQuote, rw.toQuote)
+	}
+	if bytes.HasPrefix(line, rw.useFrom) {
+		rw.report.Rewritten++
+		line = append(append([]byte{}, rw.useTo...), line[len(rw.useFrom):]...)
+	}
+	if matches := rw.unquoted.FindAll(line, -1); len(matches) > 0 {
+		rw.report.UnsafeCount += len(matches)
+		if len(rw.report.Unsafe) < maxUnsafeSamples {
+			snippet := bytes.TrimSpace(line)
+			if len(snippet) > 120 {
+				snippet = append(snippet[:117:117], "..."...)
+			}
+			rw.report.Unsafe = append(rw.report.Unsafe, string(snippet))
+		}
+	}
+	return line
+}
+
+// sourceDatabaseFromMetadata returns the database name recorded in the backup's metadata file
+func sourceDatabaseFromMetadata(filePath string) string {
+	meta := metadataPath(filePath)
+	if meta == "" {
+		return ""
+	}
+	data, err := schema.ReadManifest(meta)
+	if err != nil {
+		return ""
+	}
+	var metaInfo backup_utils.BackupMetadata
+	if err := json.Unmarshal(data, &metaInfo); err != nil {
+		return ""
+	}
+	return metaInfo.DatabaseName
+}
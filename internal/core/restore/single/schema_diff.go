@@ -0,0 +1,47 @@
+package single
+
+import (
+	"context"
+	"fmt"
+
+	restoreUtils "sfDBTools/internal/core/restore/utils"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/jobstatus"
+	"sfDBTools/utils/schemadiff"
+	"sfDBTools/utils/terminal"
+)
+
+func captureSchema(ctx context.Context, cfg database.Config) (*schemadiff.Snapshot, error) {
+	db, err := database.GetDatabaseConnection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return schemadiff.Capture(ctx, db, cfg.DBName)
+}
+
+// reportSchemaDiff compares the schema with the one captured before the
+// restore. It also runs after a failed restore, whose partial changes matter
+// just as much.
+func reportSchemaDiff(ctx context.Context, options restoreUtils.RestoreOptions, cfg database.Config, before *schemadiff.Snapshot, job *jobstatus.Tracker, lg *logger.Logger) {
+	after, err := captureSchema(ctx, cfg)
+	if err != nil {
+		lg.Warn("Failed to capture schema after restore", logger.Error(err))
+		terminal.PrintWarning(fmt.Sprintf("Schema diff unavailable: %v", err))
+		return
+	}
+	report := schemadiff.Diff(before, after)
+	schemadiff.Display(report, lg)
+	job.Detail("Schema changes", fmt.Sprintf("%d added, %d removed, %d changed",
+		report.Count(schemadiff.Added), report.Count(schemadiff.Removed), report.Count(schemadiff.Changed)))
+	if options.SchemaDiffFile == "" {
+		return
+	}
+	if err := report.Save(options.SchemaDiffFile); err != nil {
+		lg.Warn("Failed to save schema diff", logger.String("path", options.SchemaDiffFile), logger.Error(err))
+		terminal.PrintWarning(fmt.Sprintf("Failed to save schema diff to %s: %v", options.SchemaDiffFile, err))
+		return
+	}
+	terminal.PrintInfo(fmt.Sprintf("Schema diff saved to %s", options.SchemaDiffFile))
+}
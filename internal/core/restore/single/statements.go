@@ -0,0 +1,338 @@
+package single
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	restoreUtils "sfDBTools/internal/core/restore/utils"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/terminal"
+)
+
+const (
+	// maxReportedErrors caps how many failed statements are reported when mysql continues past errors
+	maxReportedErrors = 10
+	// stderrCapture caps how much mysql client stderr is kept for error mapping
+	stderrCapture = 256 * 1024
+	// statementHistory is how many recent statements are kept for mapping mysql errors;
+	// mysql lags the reader by no more than a pipe buffer, so a small window suffices
+	statementHistory = 4096
+	// statementPreview caps the statement text kept for error reports
+	statementPreview = 200
+)
+
+var (
+	delimiterLine  = regexp.MustCompile(`(?i)^DELIMITER\s+(\S+)`)
+	statementTable = regexp.MustCompile("(?i)^(?:/\\*!\\d+\\s*)?(?:INSERT\\s+(?:IGNORE\\s+)?INTO|REPLACE\\s+INTO|CREATE\\s+TABLE(?:\\s+IF\\s+NOT\\s+EXISTS)?|DROP\\s+TABLE(?:\\s+IF\\s+EXISTS)?|ALTER\\s+TABLE|LOCK\\s+TABLES|CREATE\\s+(?:ALGORITHM=\\w+\\s+)?(?:DEFINER=\\S+\\s+)?(?:SQL\\s+SECURITY\\s+\\w+\\s+)?VIEW)\\s+`?([^`\\s(]+)`?")
+	// sessionStatement matches setup statements that are replayed even when skipped by a resume
+	sessionStatement = regexp.MustCompile(`(?i)^(?:/\*!\d+\s*)?(?:SET|USE)\s`)
+	mysqlErrorLine   = regexp.MustCompile(`ERROR (\d+) \(([0-9A-Z]+)\) at line (\d+)[^:]*: (.*)`)
+)
+
+// Statement describes one statement of the dump as it was sent to mysql
+type Statement struct {
+	Number    int    // position in the dump, counting from 1; stable across resumes
+	StartLine int    // first and last line as numbered by the mysql client
+	EndLine   int    //
+	Table     string // table the statement works on, when recognizable
+	Text      string // statement text, truncated to statementPreview
+}
+
+// statementTracker splits the dump stream into statements while passing it on to
+// mysql unchanged, so an "at line N" error can be traced to its statement. With
+// skipBefore set, statements before that number are dropped except for session
+// setup (SET, USE), which lets a restore resume after a failure. Table sections
+// listed in skipTables are dropped the same way.
+type statementTracker struct {
+	src        *bufio.Reader
+	skipBefore int
+	skipTables map[int]string
+	segments   []tableSegment
+
+	delimiter string
+	number    int // statements seen in the dump
+	line      int // lines sent to mysql
+	current   *Statement
+	building  strings.Builder
+	emit      bool
+	recent    []Statement // ring buffer of the last statementHistory statements
+	next      int
+	pending   []byte
+}
+
+func newStatementTracker(r io.Reader, skipBefore int, skipTables map[int]string) *statementTracker {
+	return &statementTracker{
+		src:        bufio.NewReaderSize(r, 1<<20),
+		skipBefore: skipBefore,
+		skipTables: skipTables,
+		delimiter:  ";",
+	}
+}
+
+func (st *statementTracker) Read(p []byte) (int, error) {
+	for len(st.pending) == 0 {
+		if err := st.fill(); err != nil {
+			if len(st.pending) > 0 {
+				break
+			}
+			return 0, err
+		}
+	}
+	n := copy(p, st.pending)
+	st.pending = st.pending[n:]
+	return n, nil
+}
+
+// fill reads the next line, updates statement bookkeeping and queues the line
+// for mysql unless it belongs to a skipped statement
+func (st *statementTracker) fill() error {
+	var line []byte
+	var err error
+	for {
+		var chunk []byte
+		chunk, err = st.src.ReadSlice('\n')
+		line = append(line, chunk...)
+		if err != bufio.ErrBufferFull {
+			break
+		}
+	}
+	if len(line) == 0 {
+		return err
+	}
+
+	trimmed := bytes.TrimSpace(line)
+	if st.current == nil {
+		switch {
+		case len(trimmed) == 0 || bytes.HasPrefix(trimmed, []byte("--")):
+			st.send(line, true)
+			return err
+		case delimiterLine.Match(trimmed):
+			st.delimiter = string(delimiterLine.FindSubmatch(trimmed)[1])
+			st.send(line, true)
+			return err
+		}
+		st.number++
+		st.building.Reset()
+		st.current = &Statement{Number: st.number}
+		if m := statementTable.FindSubmatch(trimmed); m != nil {
+			st.current.Table = string(m[1])
+		}
+		skipped := st.track(st.current)
+		st.emit = (st.number >= st.skipBefore && !skipped) || sessionStatement.Match(trimmed)
+		if st.emit {
+			st.current.StartLine = st.line + 1
+		}
+	}
+
+	if room := statementPreview + 1 - st.building.Len(); room > 0 {
+		preview := trimmed
+		if len(preview) > room {
+			preview = preview[:room]
+		}
+		st.building.Write(preview)
+		st.building.WriteByte(' ')
+	}
+	st.send(line, st.emit)
+
+	if bytes.HasSuffix(trimmed, []byte(st.delimiter)) || err != nil {
+		if st.emit {
+			st.current.EndLine = st.line
+			st.current.Text = truncateStatement(st.building.String())
+			st.remember(*st.current)
+		}
+		st.current = nil
+	}
+	return err
+}
+
+func (st *statementTracker) send(line []byte, emit bool) {
+	if !emit {
+		return
+	}
+	st.line += bytes.Count(line, []byte("\n"))
+	if !bytes.HasSuffix(line, []byte("\n")) {
+		st.line++
+	}
+	st.pending = append(st.pending[:0], line...)
+}
+
+func (st *statementTracker) remember(s Statement) {
+	if len(st.recent) < statementHistory {
+		st.recent = append(st.recent, s)
+		return
+	}
+	st.recent[st.next] = s
+	st.next = (st.next + 1) % statementHistory
+}
+
+// track assigns s to its table section, opening a new one when s names another
+// table, and reports whether the section is skipped
+func (st *statementTracker) track(s *Statement) bool {
+	n := len(st.segments)
+	if s.Table != "" && (n == 0 || st.segments[n-1].table != s.Table) {
+		seg := tableSegment{index: n, table: s.Table, first: s.Number}
+		if name, ok := st.skipTables[n]; ok && name == s.Table {
+			seg.skipped = true
+		}
+		st.segments = append(st.segments, seg)
+		n++
+	}
+	if n == 0 {
+		return false
+	}
+	st.segments[n-1].last = s.Number
+	return st.segments[n-1].skipped
+}
+
+// loadedTables returns the table sections known to be completely loaded when
+// statement failed was the first to fail: those skipped as loaded earlier and
+// those that ended before it. The section still open when reading stopped is
+// never complete, since later statements may still belong to it.
+func (st *statementTracker) loadedTables(failed int) []LoadedTable {
+	var loaded []LoadedTable
+	for i, seg := range st.segments {
+		closed := i < len(st.segments)-1
+		if seg.skipped || (closed && seg.last < failed) {
+			loaded = append(loaded, LoadedTable{Index: seg.index, Table: seg.table})
+		}
+	}
+	return loaded
+}
+
+// Statements returns the number of statements read from the dump so far
+func (st *statementTracker) Statements() int { return st.number }
+
+// AtLine returns the statement mysql was executing at line, if still known
+func (st *statementTracker) AtLine(line int) (Statement, bool) {
+	for _, s := range st.recent {
+		if line >= s.StartLine && line <= s.EndLine {
+			return s, true
+		}
+	}
+	return Statement{}, false
+}
+
+func truncateStatement(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > statementPreview {
+		return s[:statementPreview-3] + "..."
+	}
+	return s
+}
+
+// mysqlError is one error reported by the mysql client
+type mysqlError struct {
+	Code    int
+	State   string
+	Line    int
+	Message string
+}
+
+// parseMysqlErrors extracts "ERROR n (state) at line N: message" entries from client stderr
+func parseMysqlErrors(stderr string) []mysqlError {
+	var errs []mysqlError
+	for _, m := range mysqlErrorLine.FindAllStringSubmatch(stderr, -1) {
+		code, _ := strconv.Atoi(m[1])
+		line, _ := strconv.Atoi(m[3])
+		errs = append(errs, mysqlError{Code: code, State: m[2], Line: line, Message: strings.TrimSpace(m[4])})
+	}
+	return errs
+}
+
+// cappedBuffer keeps the first stderrCapture bytes written to it
+type cappedBuffer struct {
+	buf bytes.Buffer
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := stderrCapture - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string { return b.buf.String() }
+
+// reportFailedStatements maps mysql errors back to dump statements, logs and
+// prints them, and saves a resume point when the restore stopped at the error
+func reportFailedStatements(options restoreUtils.RestoreOptions, tracker *statementTracker, stderr string, runErr error) error {
+	lg, _ := logger.Get()
+	errs := parseMysqlErrors(stderr)
+	if len(errs) == 0 {
+		return runErr
+	}
+
+	terminal.PrintSubHeader("Failed Statements")
+	var first Statement
+	for i, e := range errs {
+		if i == maxReportedErrors {
+			terminal.PrintWarning(fmt.Sprintf("... %d more failed statement(s), see the log", len(errs)-maxReportedErrors))
+			break
+		}
+		stmt, ok := tracker.AtLine(e.Line)
+		if i == 0 {
+			first = stmt
+		}
+		lg.Error("Restore statement failed",
+			logger.Int("statement", stmt.Number),
+			logger.Int("line", e.Line),
+			logger.String("table", stmt.Table),
+			logger.Int("error_code", e.Code),
+			logger.String("error", e.Message),
+			logger.String("sql", stmt.Text))
+		if !ok {
+			fmt.Printf("  line %d: ERROR %d: %s (statement no longer buffered)\n", e.Line, e.Code, e.Message)
+			continue
+		}
+		table := stmt.Table
+		if table == "" {
+			table = "-"
+		}
+		fmt.Printf("  #%d  line %d  table %s\n", stmt.Number, e.Line, table)
+		fmt.Printf("      ERROR %d (%s): %s\n", e.Code, e.State, e.Message)
+		fmt.Printf("      %s\n", stmt.Text)
+	}
+
+	if !options.StopOnError || first.Number == 0 {
+		return fmt.Errorf("%d statement(s) failed during restore: %w", len(errs), runErr)
+	}
+
+	applied := first.Number - 1
+	rp := ResumePoint{
+		File:      options.File,
+		Database:  options.DBName,
+		Statement: first.Number,
+		Applied:   applied,
+		Line:      errs[0].Line,
+		Table:     first.Table,
+		Text:      first.Text,
+		Error:     errs[0].Message,
+		FailedAt:  time.Now().UTC(),
+	}
+	fmt.Printf("Statements applied before the failure: %d\n", applied)
+	if path, err := writeResumePoint(rp); err != nil {
+		lg.Warn("Failed to save resume point", logger.Error(err))
+	} else {
+		terminal.PrintInfo(fmt.Sprintf("Resume point saved to %s", path))
+	}
+	if loaded := tracker.loadedTables(first.Number); len(loaded) > 0 {
+		if path, err := writeLoadedTables(options.File, options.DBName, loaded); err != nil {
+			lg.Warn("Failed to save loaded tables", logger.Error(err))
+		} else {
+			lg.Info("Loaded tables recorded", logger.Int("tables", len(loaded)), logger.String("path", path))
+			terminal.PrintInfo(fmt.Sprintf("%d table(s) were loaded completely; a full retry can skip them with --skip-loaded", len(loaded)))
+		}
+	}
+	terminal.PrintInfo(fmt.Sprintf("After fixing the problem, continue with: --resume-from %d", first.Number))
+	return fmt.Errorf("statement %d failed (table %s): %s", first.Number, first.Table, errs[0].Message)
+}
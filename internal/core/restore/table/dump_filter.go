@@ -0,0 +1,141 @@
+package restore_table
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+var (
+	// sectionMarker matches the comments mysqldump writes before the parts of a table or view
+	sectionMarker = regexp.MustCompile("^-- (?:Table structure for table|Dumping data for table|Temporary table structure for view|Temporary view structure for view|Final view structure for view) `(.+)`\\s*$")
+	// databaseMarker starts the statements of a database in a multi-database dump
+	databaseMarker = regexp.MustCompile("^-- Current Database: `(.+)`\\s*$")
+	// closingMarker starts a part of the dump that belongs to no table
+	closingMarker = regexp.MustCompile(`^-- (?:Dumping (?:routines|events) for database|Dump completed)`)
+	useStatement  = regexp.MustCompile("(?i)^USE\\s+`?([^`;\\s]+)`?\\s*;")
+	// sessionLine matches single-line setup statements, which are kept from skipped parts
+	sessionLine   = regexp.MustCompile(`(?i)^(?:/\*!\d+\s*)?SET\s.*;\s*$`)
+	delimiterLine = regexp.MustCompile(`(?i)^DELIMITER\s+(\S+)`)
+)
+
+// dumpFilter passes on the parts of a mysqldump stream that belong to the
+// selected tables: the header, then for each table its structure, data and
+// triggers. Views are selected by name like tables. Other parts are dropped
+// except for session setup (SET) so the trailer still restores the session.
+// Each kept part is preceded by a USE of its database, or of target when set,
+// which is created when missing.
+type dumpFilter struct {
+	src     *bufio.Reader
+	tables  []Table
+	target  string
+	buf     bytes.Buffer
+	current string // database the dump is in
+	used    string // database of the last USE sent
+	keep    bool   // the current part is sent
+	header  bool   // before the first part
+	delim   string
+	found   map[Table]bool
+	err     error
+}
+
+// newDumpFilter filters r for tables. source is the database of a dump
+// without USE statements (a single-database backup).
+func newDumpFilter(r io.Reader, tables []Table, source, target string) *dumpFilter {
+	return &dumpFilter{
+		src:     bufio.NewReaderSize(r, 1<<20),
+		tables:  tables,
+		target:  target,
+		current: source,
+		header:  true,
+		delim:   ";",
+		found:   make(map[Table]bool),
+	}
+}
+
+func (f *dumpFilter) Read(p []byte) (int, error) {
+	for f.buf.Len() == 0 && f.err == nil {
+		f.err = f.fill()
+	}
+	if f.buf.Len() > 0 {
+		return f.buf.Read(p)
+	}
+	return 0, f.err
+}
+
+// fill processes the next line of the dump
+func (f *dumpFilter) fill() error {
+	line, err := f.src.ReadBytes('\n')
+	if len(line) > 0 {
+		if perr := f.process(line); perr != nil {
+			return perr
+		}
+	}
+	return err
+}
+
+func (f *dumpFilter) process(line []byte) error {
+	text := bytes.TrimRight(line, "\r\n")
+	if m := delimiterLine.FindSubmatch(text); m != nil {
+		f.delim = string(m[1])
+	}
+	// The filter sends its own USE before each part it keeps
+	if m := useStatement.FindSubmatch(text); m != nil {
+		f.current = string(m[1])
+		return nil
+	}
+
+	switch {
+	case databaseMarker.Match(text):
+		f.current = string(databaseMarker.FindSubmatch(text)[1])
+		f.header, f.keep = false, false
+		return nil
+	case sectionMarker.Match(text):
+		name := string(sectionMarker.FindSubmatch(text)[1])
+		f.header, f.keep = false, f.selected(name)
+		if f.keep {
+			if err := f.use(name); err != nil {
+				return err
+			}
+			f.found[Table{DB: f.current, Name: name}] = true
+		}
+	case closingMarker.Match(text):
+		f.header, f.keep = false, false
+	}
+
+	if f.header || f.keep {
+		f.buf.Write(line)
+	} else if f.delim == ";" && sessionLine.Match(text) {
+		// Session setup of skipped parts, but not statements inside a DELIMITER block
+		f.buf.Write(line)
+	}
+	return nil
+}
+
+// selected reports whether the table or view name of the current database was asked for
+func (f *dumpFilter) selected(name string) bool {
+	for _, t := range f.tables {
+		if t.matches(f.current, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// use switches the client to the database the part of table name is restored into
+func (f *dumpFilter) use(name string) error {
+	db := f.target
+	if db == "" {
+		db = f.current
+	}
+	if db == "" {
+		return fmt.Errorf("the dump does not say which database %s belongs to; set the target database", name)
+	}
+	if db != f.used {
+		fmt.Fprintf(&f.buf, "CREATE DATABASE IF NOT EXISTS `%s`;\nUSE `%s`;\n", db, db)
+		f.used = db
+	}
+	return nil
+}
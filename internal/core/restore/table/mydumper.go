@@ -0,0 +1,123 @@
+package restore_table
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// mydumperChunk matches the row chunk numbers mydumper appends to data files
+var mydumperChunk = regexp.MustCompile(`(\.\d+)+$`)
+
+// mydumperFile tells what a file of a mydumper dump holds: the database
+// schema (table empty), a table's schema, triggers, view or rows, or nothing
+// a table restore needs (ok false), like the global metadata file
+func mydumperFile(name string) (db, table string, ok bool) {
+	name = path.Base(name)
+	for _, ext := range []string{".gz", ".zst"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	name = strings.TrimSuffix(name, ".sql")
+	if db, ok := strings.CutSuffix(name, "-schema-create"); ok {
+		return db, "", true
+	}
+	for _, suffix := range []string{"-schema-triggers", "-schema-view", "-schema-post", "-schema", "-metadata"} {
+		if base, ok := strings.CutSuffix(name, suffix); ok {
+			name = base
+			break
+		}
+	}
+	name = mydumperChunk.ReplaceAllString(name, "")
+	db, table, ok = strings.Cut(name, ".")
+	if !ok || db == "" || table == "" {
+		return "", "", false
+	}
+	return db, table, true
+}
+
+// mydumperSelection picks the files of a mydumper dump that a restore of
+// tables needs and records which tables it found
+type mydumperSelection struct {
+	tables []Table
+	found  map[Table]bool
+	dbs    map[string]bool
+}
+
+func newMydumperSelection(tables []Table) *mydumperSelection {
+	return &mydumperSelection{tables: tables, found: make(map[Table]bool), dbs: make(map[string]bool)}
+}
+
+// keep reports whether file name goes into the restore. Database schemas are
+// kept for every database and pruned by prune once the tables are known.
+func (s *mydumperSelection) keep(name string) bool {
+	if path.Base(name) == "metadata" {
+		return true
+	}
+	db, table, ok := mydumperFile(name)
+	if !ok {
+		return false
+	}
+	if table == "" {
+		return true
+	}
+	for _, t := range s.tables {
+		if t.matches(db, table) {
+			s.found[Table{DB: db, Name: table}] = true
+			s.dbs[db] = true
+			return true
+		}
+	}
+	return false
+}
+
+// prune removes the schema files of databases without a selected table from dir
+func (s *mydumperSelection) prune(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if db, table, ok := mydumperFile(e.Name()); ok && table == "" && !s.dbs[db] {
+			if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// linkDir fills staging with links to the files of the dump directory src
+// that the restore needs
+func (s *mydumperSelection) linkDir(src, staging string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("failed to read dump directory: %w", err)
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !s.keep(e.Name()) {
+			continue
+		}
+		target, err := filepath.Abs(filepath.Join(src, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err := os.Symlink(target, filepath.Join(staging, e.Name())); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", e.Name(), err)
+		}
+	}
+	return s.prune(staging)
+}
+
+// mydumperDir returns the mydumper dump directory in dir: dir itself or the
+// mydumper directory of an unpacked backup archive
+func mydumperDir(dir string) (string, bool) {
+	for _, d := range []string{dir, filepath.Join(dir, "mydumper")} {
+		if info, err := os.Stat(filepath.Join(d, "metadata")); err == nil && info.Mode().IsRegular() {
+			return d, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,221 @@
+package restore_table
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/core/restore/single"
+	restoreUtils "sfDBTools/internal/core/restore/utils"
+	"sfDBTools/internal/logger"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/jobstatus"
+	restore_utils "sfDBTools/utils/restore"
+	"sfDBTools/utils/schema"
+	"sfDBTools/utils/tempdir"
+)
+
+// RestoreTables restores only the selected tables of a backup: sections of a
+// mysqldump file, or the files of a mydumper backup or dump directory. The
+// rest of the backup is read but not restored.
+func RestoreTables(ctx context.Context, opts Options) (*Result, error) {
+	job := jobstatus.Start("restore-table", tableNames(opts.Tables))
+	result, err := restoreTables(ctx, opts, job)
+	job.Finish(err)
+	return result, err
+}
+
+func restoreTables(ctx context.Context, opts Options, job *jobstatus.Tracker) (*Result, error) {
+	lg, err := logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logger: %w", err)
+	}
+	startTime := time.Now()
+	lg.Info("Starting table restore",
+		logger.String("file", opts.File),
+		logger.Strings("tables", strings.Split(tableNames(opts.Tables), ",")),
+		logger.String("target_db", opts.DBName))
+
+	job.SetStep("validating connection")
+	cfg := database.Config{Host: opts.Host, Port: opts.Port, User: opts.User, Password: opts.Password, DBName: opts.DBName}
+	if err := database.ValidateConnection(cfg); err != nil {
+		return nil, err
+	}
+	if opts.DBName != "" {
+		if err := database.EnsureDatabase(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	var result *Result
+	if info, err := os.Stat(opts.File); err == nil && info.IsDir() {
+		dumpDir, ok := mydumperDir(opts.File)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a mydumper dump directory (no metadata file)", opts.File)
+		}
+		result, err = restoreFromMydumper(ctx, opts, job, lg, func(sel *mydumperSelection, staging string) error {
+			return sel.linkDir(dumpDir, staging)
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if backup_utils.IsMydumperArchive(strings.TrimSuffix(opts.File, ".enc")) {
+		if result, err = restoreFromMydumperArchive(ctx, opts, job, lg); err != nil {
+			return nil, err
+		}
+	} else if result, err = restoreFromDump(ctx, opts, cfg, job, lg); err != nil {
+		return nil, err
+	}
+
+	result.Duration = time.Since(startTime)
+	job.Detail("Engine", result.Engine)
+	job.Detail("Tables restored", fmt.Sprintf("%d", len(result.Restored)))
+	if len(result.Missing) > 0 {
+		job.Warn(fmt.Sprintf("Not in the backup: %s", strings.Join(result.Missing, ", ")))
+	}
+	if len(result.Restored) == 0 {
+		return result, fmt.Errorf("none of the tables were found in %s", opts.File)
+	}
+	lg.Info("Table restore completed",
+		logger.Strings("restored", result.Restored),
+		logger.Strings("missing", result.Missing),
+		logger.String("duration", result.Duration.String()))
+	return result, nil
+}
+
+// restoreFromDump streams the sections of the selected tables from a
+// mysqldump backup into the mysql client
+func restoreFromDump(ctx context.Context, opts Options, cfg database.Config, job *jobstatus.Tracker, lg *logger.Logger) (*Result, error) {
+	session, err := restoreUtils.ApplySessionVars(ctx, cfg, opts.SessionVars)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := restore_utils.OpenBackupStream(opts.File, restore_utils.StreamOptions{
+		EncryptionKeyFile: opts.EncryptionKeyFile,
+		Raw:               job.Reader,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	filter := newDumpFilter(stream, opts.Tables, sourceDatabase(opts.File), opts.DBName)
+	args := []string{
+		fmt.Sprintf("--host=%s", opts.Host),
+		fmt.Sprintf("--port=%d", opts.Port),
+		fmt.Sprintf("--user=%s", opts.User),
+	}
+	args = append(args, session.MysqlArgs()...)
+	if len(opts.ExtraArgs) > 0 {
+		args = append(args, opts.ExtraArgs...)
+		lg.Info("Passing extra mysql client options", logger.Strings("args", opts.ExtraArgs))
+	}
+
+	job.SetStep("restoring")
+	if err := restore_utils.PipeToMySQL(ctx, session.DumpReader(filter), restore_utils.MySQLCommand{Args: args, Password: opts.Password}); err != nil {
+		lg.Error("mysql restore failed", logger.Error(err))
+		return nil, fmt.Errorf("mysql restore failed: %w", err)
+	}
+	result := &Result{Engine: backup_utils.EngineMysqldump, Restored: foundNames(filter.found), Missing: missingTables(filter.tables, filter.found)}
+	if len(result.Restored) == 0 {
+		lg.Warn("No table sections matched; dumps written with --skip-comments have no section markers and cannot be filtered")
+	}
+	return result, nil
+}
+
+// restoreFromMydumperArchive extracts the files of the selected tables from
+// a mydumper backup archive and loads them with myloader
+func restoreFromMydumperArchive(ctx context.Context, opts Options, job *jobstatus.Tracker, lg *logger.Logger) (*Result, error) {
+	stream, err := restore_utils.OpenBackupStream(opts.File, restore_utils.StreamOptions{
+		EncryptionKeyFile: opts.EncryptionKeyFile,
+		Raw:               job.Reader,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	return restoreFromMydumper(ctx, opts, job, lg, func(sel *mydumperSelection, staging string) error {
+		lg.Info("Extracting selected tables", logger.String("dir", staging))
+		err := backup_utils.ExtractTarMatching(stream, staging, func(name string) bool {
+			dir, _ := filepath.Split(filepath.FromSlash(name))
+			return filepath.Clean(dir) == "mydumper" && sel.keep(name)
+		})
+		if err != nil {
+			return err
+		}
+		dumpDir := filepath.Join(staging, "mydumper")
+		if _, err := os.Stat(filepath.Join(dumpDir, "metadata")); err != nil {
+			return fmt.Errorf("backup does not contain a mydumper dump directory")
+		}
+		return sel.prune(dumpDir)
+	})
+}
+
+// restoreFromMydumper stages the files of the selected tables with stage and
+// loads them with myloader
+func restoreFromMydumper(ctx context.Context, opts Options, job *jobstatus.Tracker, lg *logger.Logger, stage func(sel *mydumperSelection, staging string) error) (*Result, error) {
+	if len(opts.ExtraArgs) > 0 {
+		return nil, fmt.Errorf("--restore-arg passes options to the mysql client and cannot be used with mydumper backups")
+	}
+	if _, err := exec.LookPath("myloader"); err != nil {
+		return nil, fmt.Errorf("restoring a mydumper backup needs the myloader binary on PATH: %w", err)
+	}
+	staging, err := os.MkdirTemp(tempdir.Dir(), "sfdb-myloader-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extract directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	job.SetStep("extracting")
+	sel := newMydumperSelection(opts.Tables)
+	if err := stage(sel, staging); err != nil {
+		return nil, err
+	}
+	result := &Result{Engine: backup_utils.EngineMydumper, Restored: foundNames(sel.found), Missing: missingTables(sel.tables, sel.found)}
+	if len(result.Restored) == 0 {
+		return result, nil
+	}
+
+	dumpDir := staging
+	if _, err := os.Stat(filepath.Join(staging, "mydumper")); err == nil {
+		dumpDir = filepath.Join(staging, "mydumper")
+	}
+	job.SetStep("restoring")
+	if err := single.RunMyloader(ctx, opts.RestoreOptions, dumpDir, staging, lg); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sourceDatabase returns the database name recorded in the manifest of a
+// backup file, which single-database dumps do not name themselves
+func sourceDatabase(file string) string {
+	manifest := strings.TrimSuffix(file, ".enc")
+	for _, ext := range []string{".gz", ".zst", ".zlib", ".sql"} {
+		manifest = strings.TrimSuffix(manifest, ext)
+	}
+	data, err := schema.ReadManifest(manifest + ".json")
+	if err != nil {
+		return ""
+	}
+	var meta backup_utils.BackupMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return ""
+	}
+	return meta.DatabaseName
+}
+
+func tableNames(tables []Table) string {
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.String()
+	}
+	return strings.Join(names, ",")
+}
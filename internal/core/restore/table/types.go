@@ -0,0 +1,108 @@
+package restore_table
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	restoreUtils "sfDBTools/internal/core/restore/utils"
+)
+
+// Table names a table (or view) to restore. An empty DB matches the table in
+// every database of the backup.
+type Table struct {
+	DB   string
+	Name string
+}
+
+func (t Table) String() string {
+	if t.DB == "" {
+		return t.Name
+	}
+	return t.DB + "." + t.Name
+}
+
+// matches reports whether the table named name in database db was asked for
+func (t Table) matches(db, name string) bool {
+	return t.Name == name && (t.DB == "" || t.DB == db)
+}
+
+// ParseTables parses a comma-separated list of table or db.table names;
+// names may be quoted with backticks
+func ParseTables(list string) ([]Table, error) {
+	var tables []Table
+	seen := make(map[Table]bool)
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		var t Table
+		if db, name, ok := strings.Cut(item, "."); ok {
+			t = Table{DB: unquote(db), Name: unquote(name)}
+		} else {
+			t = Table{Name: unquote(item)}
+		}
+		if t.Name == "" || strings.ContainsAny(t.DB+t.Name, "`/") {
+			return nil, fmt.Errorf("invalid table name %q, expected table or db.table", item)
+		}
+		if !seen[t] {
+			seen[t] = true
+			tables = append(tables, t)
+		}
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no tables given")
+	}
+	return tables, nil
+}
+
+// foundNames returns the db.table names of found, sorted
+func foundNames(found map[Table]bool) []string {
+	names := make([]string, 0, len(found))
+	for t := range found {
+		names = append(names, t.String())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// missingTables returns the tables that match nothing in found
+func missingTables(tables []Table, found map[Table]bool) []string {
+	var missing []string
+	for _, t := range tables {
+		hit := false
+		for f := range found {
+			hit = hit || t.matches(f.DB, f.Name)
+		}
+		if !hit {
+			missing = append(missing, t.String())
+		}
+	}
+	return missing
+}
+
+func unquote(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) >= 2 && name[0] == '`' && name[len(name)-1] == '`' {
+		return name[1 : len(name)-1]
+	}
+	return name
+}
+
+// Options configures a table restore. RestoreOptions.File is a backup file or
+// a mydumper dump directory; RestoreOptions.DBName, when set, is the database
+// every table is restored into instead of the one it was dumped from.
+type Options struct {
+	restoreUtils.RestoreOptions
+	Tables []Table
+}
+
+// Result describes a finished table restore
+type Result struct {
+	Engine   string   // mysqldump or mydumper
+	Restored []string // db.table names found in the backup and restored
+	Missing  []string // requested tables the backup does not contain
+	Duration time.Duration
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+)
+
+// GlobalSettings holds global variables changed for the duration of a restore
+// together with their original values
+type GlobalSettings struct {
+	db       *sql.DB
+	original []SessionSetting
+	once     sync.Once
+	stop     func() bool // unregisters the revert on interrupt
+}
+
+// ApplyGlobalVars sets the "name=value" entries with SET GLOBAL on the target
+// and returns the settings to revert afterwards. The original values are
+// logged before anything changes and are put back by Revert, which also runs
+// when ctx is cancelled. Protected hosts are left unchanged.
+func ApplyGlobalVars(ctx context.Context, config database.Config, entries []string) (*GlobalSettings, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	lg, _ := logger.Get()
+	if database.IsProtected(config.Host, config.Port) {
+		lg.Info("Target is protected, leaving global settings unchanged")
+		return nil, nil
+	}
+	settings, err := ParseSessionSettings(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	config.DBName = ""
+	db, err := database.GetWithoutDB(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to apply global settings: %w", err)
+	}
+	g := &GlobalSettings{db: db}
+	for _, s := range settings {
+		var value string
+		if err := db.QueryRowContext(ctx, "SELECT @@GLOBAL."+s.Name).Scan(&value); err != nil {
+			g.Revert()
+			return nil, fmt.Errorf("failed to read global %s: %w", s.Name, err)
+		}
+		lg.Info("Changing global setting for the restore",
+			logger.String("name", s.Name),
+			logger.String("original", value),
+			logger.String("value", s.Value))
+		if _, err := db.ExecContext(ctx, "SET GLOBAL "+s.Name+" = "+sqlValue(s.Value)); err != nil {
+			g.Revert()
+			return nil, fmt.Errorf("global setting %s=%s rejected by server: %w", s.Name, s.Value, err)
+		}
+		g.original = append(g.original, SessionSetting{Name: s.Name, Value: value})
+	}
+
+	// Revert as soon as a stop signal arrives instead of when the restore has
+	// unwound, so a second Ctrl+C cannot leave the server changed
+	g.stop = context.AfterFunc(ctx, g.Revert)
+	return g, nil
+}
+
+// Revert puts the original global values back and closes the connection. It
+// is safe to call more than once and on a nil receiver. It usually runs after
+// the restore's context is cancelled, so it does not take one.
+func (g *GlobalSettings) Revert() {
+	if g == nil {
+		return
+	}
+	g.once.Do(func() {
+		lg, _ := logger.Get()
+		if g.stop != nil {
+			g.stop()
+		}
+		for i := len(g.original) - 1; i >= 0; i-- {
+			s := g.original[i]
+			if _, err := g.db.Exec("SET GLOBAL " + s.Name + " = " + sqlValue(s.Value)); err != nil {
+				lg.Error("Failed to revert global setting", logger.String("name", s.Name), logger.String("original", s.Value), logger.Error(err))
+				terminal.PrintWarning(fmt.Sprintf("Could not revert %s; run SET GLOBAL %s = %s on the target", s.Name, s.Name, sqlValue(s.Value)))
+				continue
+			}
+			lg.Info("Reverted global setting", logger.String("name", s.Name), logger.String("value", s.Value))
+		}
+		g.db.Close()
+	})
+}
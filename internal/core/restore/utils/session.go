@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+)
+
+// clientSideVars are read-only at session level on the server and must be passed to the
+// mysql client instead; the server's global value still caps what is accepted.
+var clientSideVars = map[string]string{
+	"max_allowed_packet": "--max-allowed-packet",
+	"net_buffer_length":  "--net-buffer-length",
+}
+
+var varNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SessionSetting is one name=value pair requested for the restore session
+type SessionSetting struct {
+	Name  string
+	Value string
+}
+
+// SessionSettings holds validated session settings ready to be passed to the mysql client
+type SessionSettings struct {
+	Session  []SessionSetting // applied with SET SESSION through --init-command and DumpReader
+	Client   []SessionSetting // passed as mysql client options
+	Warnings []string         // server-side variables that may need changing
+}
+
+// ParseSessionSettings parses "name=value" entries as given to --set
+func ParseSessionSettings(entries []string) ([]SessionSetting, error) {
+	var settings []SessionSetting
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if !ok || !varNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid session setting %q (expected name=value)", entry)
+		}
+		settings = append(settings, SessionSetting{Name: name, Value: value})
+	}
+	return settings, nil
+}
+
+// PrepareSessionSettings validates the requested settings against the target server. Session
+// variables are test-applied on a separate connection so unknown names, global-only variables
+// and invalid values fail before the restore starts. Client-side sizes are compared with the
+// server's global limits and reported as warnings when the server would reject them.
+func PrepareSessionSettings(ctx context.Context, config database.Config, settings []SessionSetting) (*SessionSettings, error) {
+	result := &SessionSettings{}
+	if len(settings) == 0 {
+		return result, nil
+	}
+	lg, _ := logger.Get()
+
+	db, err := database.GetWithoutDB(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to validate session settings: %w", err)
+	}
+	defer db.Close()
+
+	for _, s := range settings {
+		if _, ok := clientSideVars[s.Name]; ok {
+			requested := common.ParseSize(s.Value)
+			if requested <= 0 {
+				return nil, fmt.Errorf("invalid size for %s: %q", s.Name, s.Value)
+			}
+			var serverValue int64
+			if err := db.QueryRowContext(ctx, "SELECT @@GLOBAL."+s.Name).Scan(&serverValue); err != nil {
+				return nil, fmt.Errorf("failed to read server %s: %w", s.Name, err)
+			}
+			if requested > serverValue {
+				result.Warnings = append(result.Warnings, fmt.Sprintf(
+					"%s=%s exceeds the server limit (%s); raise it with SET GLOBAL %s=%d or in the [mysqld] section of the server config, otherwise large statements are rejected",
+					s.Name, s.Value, common.FormatSize(serverValue), s.Name, requested))
+			}
+			result.Client = append(result.Client, SessionSetting{Name: s.Name, Value: strconv.FormatInt(requested, 10)})
+			continue
+		}
+
+		if _, err := db.ExecContext(ctx, "SET SESSION "+s.Name+" = "+sqlValue(s.Value)); err != nil {
+			return nil, fmt.Errorf("session setting %s=%s rejected by server: %w", s.Name, s.Value, err)
+		}
+		result.Session = append(result.Session, s)
+	}
+
+	lg.Info("Restore session settings validated",
+		logger.Int("session", len(result.Session)),
+		logger.Int("client", len(result.Client)),
+		logger.Int("warnings", len(result.Warnings)))
+	return result, nil
+}
+
+// ApplySessionVars parses and validates --set entries for a restore and prints any
+// server-side limits that need attention. The result's MysqlArgs feed the mysql client.
+func ApplySessionVars(ctx context.Context, config database.Config, entries []string) (*SessionSettings, error) {
+	settings, err := ParseSessionSettings(entries)
+	if err != nil {
+		return nil, err
+	}
+	config.DBName = ""
+	prepared, err := PrepareSessionSettings(ctx, config, settings)
+	if err != nil {
+		return nil, err
+	}
+	lg, _ := logger.Get()
+	for _, w := range prepared.Warnings {
+		lg.Warn("Restore session setting exceeds server limit", logger.String("detail", w))
+		terminal.PrintWarning(w)
+	}
+	return prepared, nil
+}
+
+// MysqlArgs returns the mysql client arguments that apply these settings. The
+// session variables set here only hold until the dump changes them: mysqldump
+// headers set sql_mode, foreign_key_checks and unique_checks themselves, so
+// dumps should also be read through DumpReader.
+func (s *SessionSettings) MysqlArgs() []string {
+	if s == nil {
+		return nil
+	}
+	var args []string
+	for _, c := range s.Client {
+		args = append(args, fmt.Sprintf("%s=%s", clientSideVars[c.Name], c.Value))
+	}
+	if len(s.Session) > 0 {
+		args = append(args, "--init-command="+s.setStatement())
+	}
+	return args
+}
+
+// setStatement returns the SET statement applying the session variables
+func (s *SessionSettings) setStatement() string {
+	parts := make([]string, len(s.Session))
+	for i, v := range s.Session {
+		parts[i] = fmt.Sprintf("SESSION %s = %s", v.Name, sqlValue(v.Value))
+	}
+	return "SET " + strings.Join(parts, ", ")
+}
+
+// DumpReader re-applies the session variables right after the header of a
+// mysqldump stream, which would otherwise override --init-command for
+// variables such as sql_mode or foreign_key_checks. The statement is appended
+// to the last header line, so the line numbers mysql reports in errors still
+// match the dump. Streams without a header are returned unchanged.
+func (s *SessionSettings) DumpReader(r io.Reader) io.Reader {
+	if s == nil || len(s.Session) == 0 {
+		return r
+	}
+	return &sessionInjector{src: bufio.NewReader(r), set: []byte(" " + s.setStatement() + ";")}
+}
+
+// sessionInjector holds back the header of a dump until its end is known
+type sessionInjector struct {
+	src     *bufio.Reader
+	set     []byte
+	pending []byte
+	done    bool
+}
+
+func (si *sessionInjector) Read(p []byte) (int, error) {
+	if !si.done {
+		if err := si.readHeader(); err != nil {
+			return 0, err
+		}
+	}
+	if len(si.pending) > 0 {
+		n := copy(p, si.pending)
+		si.pending = si.pending[n:]
+		return n, nil
+	}
+	return si.src.Read(p)
+}
+
+// readHeader reads up to the first statement after the header, the block of
+// /*!...*/; lines with blank and comment lines between them, and queues it
+// with the SET statement behind the last header line
+func (si *sessionInjector) readHeader() error {
+	headerEnd := -1
+	for {
+		line, err := si.src.ReadSlice('\n')
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return err
+		}
+		trimmed := bytes.TrimSpace(line)
+		complete := err == nil || (err == io.EOF && len(line) > 0)
+		switch {
+		case !complete && err == bufio.ErrBufferFull:
+			// Header lines are short; a line this long is the first statement
+		case len(trimmed) == 0 && err == nil, bytes.HasPrefix(trimmed, []byte("--")) && complete:
+			si.pending = append(si.pending, line...)
+			continue
+		case bytes.HasPrefix(trimmed, []byte("/*!")) && bytes.HasSuffix(trimmed, []byte("*/;")) && complete:
+			si.pending = append(si.pending, line...)
+			headerEnd = len(si.pending) - len(line) + len(bytes.TrimRight(line, "\r\n"))
+			if err == nil {
+				continue
+			}
+			line = nil
+		}
+		if headerEnd >= 0 {
+			si.pending = slices.Insert(si.pending, headerEnd, si.set...)
+		}
+		si.pending = append(si.pending, line...)
+		si.done = true
+		return nil
+	}
+}
+
+// sqlValue renders a setting value as a SQL literal: numbers and keywords stay bare, anything else is quoted
+func sqlValue(v string) string {
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return v
+	}
+	switch strings.ToUpper(v) {
+	case "ON", "OFF", "DEFAULT", "TRUE", "FALSE":
+		return strings.ToUpper(v)
+	}
+	v = strings.Trim(v, `'"`)
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
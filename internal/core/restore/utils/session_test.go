@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDumpReader(t *testing.T) {
+	settings := &SessionSettings{Session: []SessionSetting{{Name: "foreign_key_checks", Value: "1"}}}
+	set := " SET SESSION foreign_key_checks = 1;"
+
+	tests := []struct {
+		name string
+		dump string
+		want string
+	}{
+		{
+			name: "after the mysqldump header",
+			dump: "-- MySQL dump\n\n/*!40101 SET NAMES utf8mb4 */;\n/*!40014 SET FOREIGN_KEY_CHECKS=0 */;\n\n--\n-- Table structure\n--\nDROP TABLE IF EXISTS `t`;\n",
+			want: "-- MySQL dump\n\n/*!40101 SET NAMES utf8mb4 */;\n/*!40014 SET FOREIGN_KEY_CHECKS=0 */;" + set + "\n\n--\n-- Table structure\n--\nDROP TABLE IF EXISTS `t`;\n",
+		},
+		{
+			name: "CRLF line endings",
+			dump: "/*!40014 SET FOREIGN_KEY_CHECKS=0 */;\r\nINSERT INTO t VALUES (1);\r\n",
+			want: "/*!40014 SET FOREIGN_KEY_CHECKS=0 */;" + set + "\r\nINSERT INTO t VALUES (1);\r\n",
+		},
+		{
+			name: "header only",
+			dump: "/*!40014 SET FOREIGN_KEY_CHECKS=0 */;",
+			want: "/*!40014 SET FOREIGN_KEY_CHECKS=0 */;" + set,
+		},
+		{
+			name: "no header",
+			dump: "CREATE TABLE t (id int);\n/*!40014 SET FOREIGN_KEY_CHECKS=0 */;\n",
+			want: "CREATE TABLE t (id int);\n/*!40014 SET FOREIGN_KEY_CHECKS=0 */;\n",
+		},
+		{
+			name: "long first statement",
+			dump: "/*!40014 SET FOREIGN_KEY_CHECKS=0 */;\nINSERT INTO t VALUES " + strings.Repeat("(1),", 5000) + "(1);\n",
+			want: "/*!40014 SET FOREIGN_KEY_CHECKS=0 */;" + set + "\nINSERT INTO t VALUES " + strings.Repeat("(1),", 5000) + "(1);\n",
+		},
+		{name: "empty", dump: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(settings.DumpReader(strings.NewReader(tt.dump)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDumpReaderWithoutSessionSettings(t *testing.T) {
+	r := strings.NewReader("/*!40014 SET FOREIGN_KEY_CHECKS=0 */;\n")
+	client := &SessionSettings{Client: []SessionSetting{{Name: "max_allowed_packet", Value: "1073741824"}}}
+	if got := client.DumpReader(r); got != io.Reader(r) {
+		t.Error("stream wrapped although there are no session variables")
+	}
+	var none *SessionSettings
+	if got := none.DumpReader(r); got != io.Reader(r) {
+		t.Error("nil settings wrapped the stream")
+	}
+}
@@ -1,5 +1,11 @@
 package utils
 
+import (
+	"time"
+
+	"sfDBTools/utils/database"
+)
+
 // RestoreOptions represents the configuration for a single database Restore
 type RestoreOptions struct {
 	Host           string
@@ -9,4 +15,40 @@ type RestoreOptions struct {
 	DBName         string
 	File           string
 	VerifyChecksum bool
+	// RewriteReferences rewrites `RewriteFrom`.obj references to `DBName`.obj while restoring.
+	// When RewriteFrom is empty the database name from the backup metadata is used.
+	RewriteReferences bool
+	RewriteFrom       string
+	// SessionVars are name=value settings applied to the restore connection
+	SessionVars []string
+	// GlobalVars are name=value settings applied with SET GLOBAL for the
+	// duration of the restore and reverted afterwards
+	GlobalVars []string
+	// ExtraArgs are --restore-arg options appended to the mysql client command
+	ExtraArgs []string
+	// StopOnError stops at the first failing statement and saves a resume point
+	// instead of letting mysql continue past errors
+	StopOnError bool
+	// ResumeFrom skips statements before this number (except session setup)
+	ResumeFrom int
+	// SkipLoaded skips the table sections a failed earlier restore of the same
+	// file recorded as completely loaded
+	SkipLoaded bool
+	// Sanitize rewrites MariaDB-specific syntax so the dump loads on MySQL or
+	// older servers; SanitizeEngine replaces non-portable storage engines
+	Sanitize       bool
+	SanitizeEngine string
+	// SchemaDiff captures the target schema before the restore and reports
+	// the structural changes afterwards; SchemaDiffFile also saves them as JSON
+	SchemaDiff     bool
+	SchemaDiffFile string
+	// EncryptionKeyFile decrypts an encrypted backup with a key file instead
+	// of a password; SFDB_ENCRYPTION_KEY_FILE is used when empty
+	EncryptionKeyFile string
+	// Until recovers the database to this point in time by replaying the
+	// binary logs of BinlogSource after the backup; zero restores the backup
+	// as it is. BinlogStart overrides the start position from the manifest.
+	Until        time.Time
+	BinlogStart  string
+	BinlogSource database.Config
 }
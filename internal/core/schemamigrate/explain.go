@@ -0,0 +1,109 @@
+package schemamigrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sfDBTools/utils/common"
+)
+
+// ddlTarget finds the table a DDL statement works on
+var ddlTarget = regexp.MustCompile("(?is)^(?:(?:ALTER|DROP|TRUNCATE|RENAME)\\s+(?:ONLINE\\s+|IGNORE\\s+)?TABLE\\s+(?:IF\\s+(?:NOT\\s+)?EXISTS\\s+)?|CREATE\\s+(?:OR\\s+REPLACE\\s+)?(?:TEMPORARY\\s+)?TABLE\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?|CREATE\\s+(?:UNIQUE\\s+|FULLTEXT\\s+|SPATIAL\\s+)?INDEX\\s+\\S+\\s+ON\\s+|DROP\\s+INDEX\\s+\\S+\\s+ON\\s+)((?:`[^`]+`|[^`\\s(;.]+)(?:\\.(?:`[^`]+`|[^`\\s(;.]+))?)")
+
+// PlanStep is one statement of a dry run with what is known about its cost
+type PlanStep struct {
+	SQL    string
+	Kind   string
+	Detail string // EXPLAIN summary for DML, size of the affected table for DDL
+}
+
+// explain describes stmts without changing anything. MySQL cannot EXPLAIN
+// DDL, so for DDL the size of the affected table is reported instead: that is
+// what decides how long an ALTER copying the table runs.
+func explain(conn *sql.Conn, stmts []string) []PlanStep {
+	steps := make([]PlanStep, 0, len(stmts))
+	for _, s := range stmts {
+		step := PlanStep{SQL: s, Kind: statementKind(s)}
+		switch step.Kind {
+		case KindDML:
+			step.Detail = explainDML(conn, s)
+		case KindDDL:
+			step.Detail = describeDDLTarget(conn, s)
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// explainDML summarises EXPLAIN as "table: type, ~rows" per plan row
+func explainDML(conn *sql.Conn, stmt string) string {
+	rows, err := conn.QueryContext(context.Background(), "EXPLAIN "+stripComments(stmt))
+	if err != nil {
+		return fmt.Sprintf("EXPLAIN failed: %v", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return fmt.Sprintf("EXPLAIN failed: %v", err)
+	}
+	var parts []string
+	for rows.Next() {
+		values := make([]sql.NullString, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return fmt.Sprintf("EXPLAIN failed: %v", err)
+		}
+		row := make(map[string]string, len(cols))
+		for i, c := range cols {
+			row[strings.ToLower(c)] = values[i].String
+		}
+		part := fmt.Sprintf("%s: %s, ~%s rows", row["table"], row["type"], row["rows"])
+		if row["key"] != "" {
+			part += ", key " + row["key"]
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return "no plan"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describeDDLTarget reports whether the table of a DDL statement exists and how big it is
+func describeDDLTarget(conn *sql.Conn, stmt string) string {
+	m := ddlTarget.FindStringSubmatch(stripComments(stmt))
+	if m == nil {
+		return ""
+	}
+	schema, table := "", strings.Trim(m[1], "`")
+	if db, name, ok := strings.Cut(m[1], "."); ok {
+		schema, table = strings.Trim(db, "`"), strings.Trim(name, "`")
+	}
+
+	query := "SELECT engine, table_rows, data_length + index_length FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	args := []any{table}
+	if schema != "" {
+		query = "SELECT engine, table_rows, data_length + index_length FROM information_schema.tables WHERE table_schema = ? AND table_name = ?"
+		args = []any{schema, table}
+	}
+	var (
+		engine sql.NullString
+		rows   sql.NullInt64
+		size   sql.NullInt64
+	)
+	err := conn.QueryRowContext(context.Background(), query, args...).Scan(&engine, &rows, &size)
+	if err == sql.ErrNoRows {
+		return fmt.Sprintf("table %s does not exist", table)
+	}
+	if err != nil {
+		return fmt.Sprintf("table lookup failed: %v", err)
+	}
+	return fmt.Sprintf("table %s: %s, ~%d rows, %s", table, engine.String, rows.Int64, common.FormatSize(size.Int64))
+}
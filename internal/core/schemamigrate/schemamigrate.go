@@ -0,0 +1,410 @@
+// Package schemamigrate applies versioned schema changes of an application
+// database. Migrations are pairs of files in one directory,
+// <version>_<name>.up.sql and <version>_<name>.down.sql, applied in version
+// order and recorded in a tracking table inside the database.
+package schemamigrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+)
+
+// TrackingTable records applied migrations in the target database
+const TrackingTable = "_sfdbtools_schema_migrations"
+
+// lockPrefix names the GET_LOCK lock that keeps two runs on one database apart
+const lockPrefix = "sfdbtools_migrate_schema."
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Directions of a run
+const (
+	Up   = "up"
+	Down = "down"
+)
+
+// Migration states reported by Status
+const (
+	StatusPending = "pending"
+	StatusApplied = "applied"
+	StatusDirty   = "dirty"   // failed midway, the schema needs manual repair
+	StatusChanged = "changed" // applied, but the up file was edited afterwards
+	StatusMissing = "missing" // recorded in the database, no file in the directory
+)
+
+// Options controls a run
+type Options struct {
+	DB  database.Config // DBName is the database to migrate
+	Dir string
+	// Steps limits how many migrations are applied (up, 0 = all pending) or
+	// rolled back (down, 0 = 1)
+	Steps int
+	// DryRun prints the statements and their EXPLAIN plans instead of running them
+	DryRun bool
+	// Force retries a migration left dirty by a failed run
+	Force       bool
+	LockTimeout time.Duration
+}
+
+// Migration is one version of the migration directory
+type Migration struct {
+	Version  int64
+	Name     string
+	UpPath   string
+	DownPath string
+	Checksum string // of the up file
+
+	Status      string
+	AppliedAt   time.Time
+	ExecutionMs int64
+}
+
+// Result describes one migration applied, rolled back or planned by a run
+type Result struct {
+	Migration     *Migration
+	Direction     string
+	Statements    int
+	Transactional bool
+	Duration      time.Duration
+	Plan          []PlanStep // dry run only
+}
+
+// Load reads the migration directory. Every version needs an up file; the
+// down file is optional but required to roll the version back.
+func Load(dir string) ([]*Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration directory: %w", err)
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		m := migrationFile.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", e.Name(), err)
+		}
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2], Status: StatusPending}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("version %d is used by both %q and %q", version, mig.Name, m[2])
+		}
+		path := filepath.Join(dir, e.Name())
+		if m[3] == Up {
+			mig.UpPath = path
+		} else {
+			mig.DownPath = path
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.UpPath == "" {
+			return nil, fmt.Errorf("migration %d_%s has no .up.sql file", mig.Version, mig.Name)
+		}
+		raw, err := os.ReadFile(mig.UpPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", mig.UpPath, err)
+		}
+		sum := sha256.Sum256(raw)
+		mig.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Status loads the directory and merges it with the tracking table. Versions
+// recorded in the database without a file are included as missing.
+func Status(opts Options) ([]*Migration, error) {
+	migrations, err := Load(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	db, err := database.GetDatabaseConnection(opts.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return merge(conn, migrations)
+}
+
+// Run applies pending migrations (Up) or rolls back applied ones (Down). A
+// database lock keeps concurrent runs out; a migration without DDL runs in a
+// transaction together with its tracking row, anything else is applied
+// statement by statement and marked dirty until it completes.
+func Run(direction string, opts Options) ([]Result, error) {
+	lg, _ := logger.Get()
+	migrations, err := Load(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := database.GetDatabaseConnection(opts.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	// GET_LOCK and transactions belong to a session, so everything runs on one connection
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if !opts.DryRun {
+		release, err := acquireLock(conn, opts.DB.DBName, opts.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		if err := ensureTrackingTable(conn); err != nil {
+			return nil, err
+		}
+	}
+
+	if migrations, err = merge(conn, migrations); err != nil {
+		return nil, err
+	}
+	for _, m := range migrations {
+		if m.Status == StatusDirty && !(opts.Force && direction == Up) {
+			return nil, fmt.Errorf("migration %d_%s failed midway in an earlier run; repair the schema by hand, then retry it with 'up --force'", m.Version, m.Name)
+		}
+	}
+
+	plan := selectMigrations(direction, migrations, opts.Steps)
+	var results []Result
+	for _, m := range plan {
+		path := m.UpPath
+		if direction == Down {
+			if m.DownPath == "" {
+				return results, fmt.Errorf("migration %d_%s has no .down.sql file and cannot be rolled back", m.Version, m.Name)
+			}
+			path = m.DownPath
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return results, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		stmts, err := splitStatements(string(raw))
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+
+		res := Result{Migration: m, Direction: direction, Statements: len(stmts), Transactional: transactional(stmts)}
+		if opts.DryRun {
+			res.Plan = explain(conn, stmts)
+			results = append(results, res)
+			continue
+		}
+
+		lg.Info("Applying schema migration",
+			logger.String("database", opts.DB.DBName),
+			logger.String("direction", direction),
+			logger.String("migration", fmt.Sprintf("%d_%s", m.Version, m.Name)),
+			logger.Bool("transactional", res.Transactional))
+		start := time.Now()
+		if res.Transactional {
+			err = applyInTransaction(conn, direction, m, stmts, start)
+		} else {
+			err = applyStepwise(conn, direction, m, stmts, start)
+		}
+		res.Duration = time.Since(start)
+		if err != nil {
+			return results, fmt.Errorf("migration %d_%s (%s): %w", m.Version, m.Name, direction, err)
+		}
+		if direction == Up {
+			m.Status = StatusApplied
+		} else {
+			m.Status = StatusPending
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// selectMigrations returns the migrations a run works on, in execution order
+func selectMigrations(direction string, migrations []*Migration, steps int) []*Migration {
+	var plan []*Migration
+	if direction == Up {
+		for _, m := range migrations {
+			if m.Status == StatusPending || m.Status == StatusDirty {
+				plan = append(plan, m)
+			}
+		}
+		if steps > 0 && len(plan) > steps {
+			plan = plan[:steps]
+		}
+		return plan
+	}
+
+	if steps <= 0 {
+		steps = 1
+	}
+	for i := len(migrations) - 1; i >= 0 && len(plan) < steps; i-- {
+		if m := migrations[i]; m.Status == StatusApplied || m.Status == StatusChanged {
+			plan = append(plan, m)
+		}
+	}
+	return plan
+}
+
+// applyInTransaction runs stmts and updates the tracking table atomically
+func applyInTransaction(conn *sql.Conn, direction string, m *Migration, stmts []string, start time.Time) error {
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	for i, s := range stmts {
+		if _, err := tx.Exec(s); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("statement %d: %w (rolled back)", i+1, err)
+		}
+	}
+	if direction == Up {
+		_, err = tx.Exec("REPLACE INTO `"+TrackingTable+"` (version, name, checksum, applied_at, execution_ms, dirty) VALUES (?, ?, ?, NOW(), ?, 0)",
+			m.Version, m.Name, m.Checksum, time.Since(start).Milliseconds())
+	} else {
+		_, err = tx.Exec("DELETE FROM `"+TrackingTable+"` WHERE version = ?", m.Version)
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to update %s: %w (rolled back)", TrackingTable, err)
+	}
+	return tx.Commit()
+}
+
+// applyStepwise runs stmts one by one; DDL commits implicitly, so the version
+// is marked dirty while it runs and a failure leaves it dirty
+func applyStepwise(conn *sql.Conn, direction string, m *Migration, stmts []string, start time.Time) error {
+	if _, err := conn.ExecContext(context.Background(),
+		"REPLACE INTO `"+TrackingTable+"` (version, name, checksum, applied_at, execution_ms, dirty) VALUES (?, ?, ?, NOW(), 0, 1)",
+		m.Version, m.Name, m.Checksum); err != nil {
+		return fmt.Errorf("failed to mark migration as running: %w", err)
+	}
+	for i, s := range stmts {
+		if _, err := conn.ExecContext(context.Background(), s); err != nil {
+			return fmt.Errorf("statement %d: %w (migration left dirty, statements before it were applied)", i+1, err)
+		}
+	}
+
+	var err error
+	if direction == Up {
+		_, err = conn.ExecContext(context.Background(), "UPDATE `"+TrackingTable+"` SET dirty = 0, applied_at = NOW(), execution_ms = ? WHERE version = ?",
+			time.Since(start).Milliseconds(), m.Version)
+	} else {
+		_, err = conn.ExecContext(context.Background(), "DELETE FROM `"+TrackingTable+"` WHERE version = ?", m.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("migration was applied but %s could not be updated: %w", TrackingTable, err)
+	}
+	return nil
+}
+
+// acquireLock takes the per-database migration lock and returns its release
+func acquireLock(conn *sql.Conn, dbName string, timeout time.Duration) (func(), error) {
+	name := lockPrefix + dbName
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(context.Background(), "SELECT GET_LOCK(?, ?)", name, int(timeout.Seconds())).Scan(&got); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	if !got.Valid || got.Int64 != 1 {
+		var holder sql.NullInt64
+		conn.QueryRowContext(context.Background(), "SELECT IS_USED_LOCK(?)", name).Scan(&holder)
+		return nil, fmt.Errorf("another schema migration is running on %s (connection id %d); try again later", dbName, holder.Int64)
+	}
+	return func() {
+		conn.ExecContext(context.Background(), "DO RELEASE_LOCK(?)", name)
+	}, nil
+}
+
+func ensureTrackingTable(conn *sql.Conn) error {
+	if _, err := conn.ExecContext(context.Background(), "CREATE TABLE IF NOT EXISTS `"+TrackingTable+"` ("+
+		"version BIGINT NOT NULL PRIMARY KEY, "+
+		"name VARCHAR(255) NOT NULL, "+
+		"checksum CHAR(64) NOT NULL, "+
+		"applied_at DATETIME NOT NULL, "+
+		"execution_ms BIGINT NOT NULL DEFAULT 0, "+
+		"dirty TINYINT(1) NOT NULL DEFAULT 0) ENGINE=InnoDB"); err != nil {
+		return fmt.Errorf("failed to create %s: %w", TrackingTable, err)
+	}
+	return nil
+}
+
+// merge sets the status of migrations from the tracking table, which may not
+// exist yet
+func merge(conn *sql.Conn, migrations []*Migration) ([]*Migration, error) {
+	ctx := context.Background()
+	var exists int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", TrackingTable).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check %s: %w", TrackingTable, err)
+	}
+	if exists == 0 {
+		return migrations, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, UNIX_TIMESTAMP(applied_at), execution_ms, dirty FROM `"+TrackingTable+"` ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", TrackingTable, err)
+	}
+	defer rows.Close()
+
+	byVersion := make(map[int64]*Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+	for rows.Next() {
+		var (
+			rec     Migration
+			applied int64
+			dirty   bool
+		)
+		if err := rows.Scan(&rec.Version, &rec.Name, &rec.Checksum, &applied, &rec.ExecutionMs, &dirty); err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[rec.Version]
+		switch {
+		case !ok:
+			rec.Status = StatusMissing
+			m = &rec
+			migrations = append(migrations, m)
+		case dirty:
+			m.Status = StatusDirty
+		case rec.Checksum != m.Checksum:
+			m.Status = StatusChanged
+		default:
+			m.Status = StatusApplied
+		}
+		m.ExecutionMs = rec.ExecutionMs
+		m.AppliedAt = time.Unix(applied, 0)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
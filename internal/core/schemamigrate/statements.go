@@ -0,0 +1,132 @@
+package schemamigrate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	delimiterLine = regexp.MustCompile(`(?i)^\s*DELIMITER\s+(\S+)\s*$`)
+	leadingWord   = regexp.MustCompile(`^[A-Za-z]+`)
+)
+
+// Statement kinds, deciding whether a migration can run inside a transaction
+const (
+	KindDDL   = "ddl"   // causes an implicit commit in MySQL/MariaDB
+	KindDML   = "dml"   // can be EXPLAINed and rolled back
+	KindOther = "other" // SET, DO, CALL, ...
+	KindTx    = "tx"    // explicit transaction control written by the author
+)
+
+// splitStatements splits a migration file into statements. It honours quotes,
+// comments and DELIMITER lines the way the mysql client does, since
+// database/sql runs one statement per call.
+func splitStatements(src string) ([]string, error) {
+	var (
+		stmts     []string
+		current   strings.Builder
+		delimiter = ";"
+		quote     byte
+		block     bool // inside /* */
+	)
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" && stripComments(s) != "" {
+			stmts = append(stmts, s)
+		}
+		current.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(src, "\n") {
+		if quote == 0 && !block && stripComments(current.String()) == "" {
+			if m := delimiterLine.FindStringSubmatch(strings.TrimRight(line, "\r\n")); m != nil {
+				delimiter = m[1]
+				current.Reset()
+				continue
+			}
+		}
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case block:
+				current.WriteByte(c)
+				if c == '*' && i+1 < len(line) && line[i+1] == '/' {
+					current.WriteByte('/')
+					i++
+					block = false
+				}
+			case quote != 0:
+				current.WriteByte(c)
+				if c == '\\' && quote != '`' && i+1 < len(line) {
+					current.WriteByte(line[i+1])
+					i++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '\'' || c == '"' || c == '`':
+				quote = c
+				current.WriteByte(c)
+			case c == '/' && i+1 < len(line) && line[i+1] == '*':
+				block = true
+				current.WriteString("/*")
+				i++
+			case c == '#' || (c == '-' && strings.HasPrefix(line[i:], "-- ")) || (c == '-' && strings.TrimRight(line[i:], "\r\n") == "--"):
+				// line comment: keep the newline so statements stay readable
+				current.WriteByte('\n')
+				i = len(line)
+			case strings.HasPrefix(line[i:], delimiter):
+				flush()
+				i += len(delimiter) - 1
+			default:
+				current.WriteByte(c)
+			}
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if block {
+		return nil, fmt.Errorf("unterminated /* comment")
+	}
+	flush()
+	return stmts, nil
+}
+
+// stripComments removes leading block comments, except MySQL conditional
+// comments (/*! ... */) which are executed
+func stripComments(s string) string {
+	s = strings.TrimSpace(s)
+	for strings.HasPrefix(s, "/*") && !strings.HasPrefix(s, "/*!") {
+		end := strings.Index(s, "*/")
+		if end < 0 {
+			return ""
+		}
+		s = strings.TrimSpace(s[end+2:])
+	}
+	return s
+}
+
+// statementKind classifies a statement by its leading keyword
+func statementKind(stmt string) string {
+	word := strings.ToUpper(leadingWord.FindString(stripComments(stmt)))
+	switch word {
+	case "CREATE", "ALTER", "DROP", "RENAME", "TRUNCATE":
+		return KindDDL
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "REPLACE":
+		return KindDML
+	case "BEGIN", "START", "COMMIT", "ROLLBACK", "LOCK", "UNLOCK":
+		return KindTx
+	}
+	return KindOther
+}
+
+// transactional reports whether stmts can be applied atomically in one
+// transaction: DDL and explicit transaction control commit implicitly
+func transactional(stmts []string) bool {
+	for _, s := range stmts {
+		if k := statementKind(s); k == KindDDL || k == KindTx {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,264 @@
+// Package seed applies ordered SQL fixture files to a database, e.g. to
+// prepare training and demo databases. Applied fixtures are recorded in a
+// table inside the target database so running the same directory again only
+// applies new files.
+package seed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/shutdown"
+)
+
+// TrackingTable records applied fixtures in the target database
+const TrackingTable = "_sfdbtools_seeds"
+
+// placeholder matches {{name}} template variables in fixtures
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// Options controls Apply
+type Options struct {
+	DB  database.Config // DBName is the database to seed
+	Dir string
+	// Vars are substituted for {{name}} in fixtures; client_code, app_name and
+	// database are always available
+	Vars map[string]string
+	// ReapplyChanged applies fixtures again whose content changed since they
+	// were applied; otherwise a changed fixture is an error
+	ReapplyChanged bool
+	DryRun         bool
+}
+
+// Fixture is one SQL file of a seed directory
+type Fixture struct {
+	Name     string // path relative to the seed directory, the tracking key
+	Path     string
+	Checksum string // of the rendered SQL
+	SQL      []byte
+	Status   string // pending, applied, skipped or changed
+}
+
+// Fixture states reported by Apply
+const (
+	StatusPending = "pending"
+	StatusApplied = "applied"
+	StatusSkipped = "skipped" // already applied with the same content
+	StatusChanged = "changed" // already applied with other content
+)
+
+// Load reads every .sql file below dir in lexical order of its relative path
+// (name them 001_schema.sql, 002_users.sql, ...) and renders its variables
+func Load(dir string, vars map[string]string) ([]*Fixture, error) {
+	var fixtures []*Fixture
+	err := filepath.WalkDir(dir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(d.Name()), ".sql") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read fixture %s: %w", rel, err)
+		}
+		rendered, err := render(raw, vars)
+		if err != nil {
+			return fmt.Errorf("fixture %s: %w", rel, err)
+		}
+		sum := sha256.Sum256(rendered)
+		fixtures = append(fixtures, &Fixture{
+			Name:     filepath.ToSlash(rel),
+			Path:     path,
+			Checksum: hex.EncodeToString(sum[:]),
+			SQL:      rendered,
+			Status:   StatusPending,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].Name < fixtures[j].Name })
+	return fixtures, nil
+}
+
+// render replaces {{name}} placeholders; unknown names are an error so a typo
+// does not end up in the seeded data
+func render(raw []byte, vars map[string]string) ([]byte, error) {
+	var missing []string
+	out := placeholder.ReplaceAllFunc(raw, func(m []byte) []byte {
+		name := string(placeholder.FindSubmatch(m)[1])
+		if v, ok := vars[name]; ok {
+			return []byte(v)
+		}
+		missing = append(missing, name)
+		return m
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("undefined template variable(s): %s (set them with --var name=value)", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// DefaultVars returns the variables every fixture can use
+func DefaultVars(dbName string) map[string]string {
+	vars := map[string]string{"database": dbName}
+	if cfg, err := config.Get(); err == nil {
+		vars["client_code"] = cfg.General.ClientCode
+		vars["app_name"] = cfg.General.AppName
+	}
+	return vars
+}
+
+// ParseVars parses name=value pairs given with --var
+func ParseVars(raw []string) (map[string]string, error) {
+	vars := make(map[string]string, len(raw))
+	for _, kv := range raw {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid --var %q, expected name=value", kv)
+		}
+		vars[strings.TrimSpace(name)] = value
+	}
+	return vars, nil
+}
+
+// Apply loads the fixtures of opts.Dir and applies those not yet recorded in
+// the tracking table, stopping at the first failure
+func Apply(opts Options) ([]*Fixture, error) {
+	lg, _ := logger.Get()
+
+	vars := DefaultVars(opts.DB.DBName)
+	for k, v := range opts.Vars {
+		vars[k] = v
+	}
+	fixtures, err := Load(opts.Dir, vars)
+	if err != nil {
+		return nil, err
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no .sql fixtures found in %s", opts.Dir)
+	}
+
+	db, err := database.GetDatabaseConnection(opts.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	applied, err := appliedFixtures(db, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fixtures {
+		sum, ok := applied[f.Name]
+		switch {
+		case !ok:
+		case sum == f.Checksum:
+			f.Status = StatusSkipped
+		default:
+			f.Status = StatusChanged
+		}
+	}
+	for _, f := range fixtures {
+		if f.Status == StatusChanged && !opts.ReapplyChanged {
+			return fixtures, fmt.Errorf("fixture %s changed since it was applied to %s; use --reapply-changed to apply it again", f.Name, opts.DB.DBName)
+		}
+	}
+	if opts.DryRun {
+		return fixtures, nil
+	}
+
+	for _, f := range fixtures {
+		if f.Status == StatusSkipped {
+			continue
+		}
+		start := time.Now()
+		lg.Info("Applying seed fixture", logger.String("database", opts.DB.DBName), logger.String("fixture", f.Name))
+		if err := runFixture(opts.DB, f); err != nil {
+			return fixtures, err
+		}
+		if _, err := db.Exec("REPLACE INTO `"+TrackingTable+"` (name, checksum, applied_at, duration_ms) VALUES (?, ?, NOW(), ?)",
+			f.Name, f.Checksum, time.Since(start).Milliseconds()); err != nil {
+			return fixtures, fmt.Errorf("fixture %s was applied but could not be recorded: %w", f.Name, err)
+		}
+		f.Status = StatusApplied
+	}
+	return fixtures, nil
+}
+
+// appliedFixtures returns the checksums recorded in the tracking table,
+// creating it unless this is a dry run
+func appliedFixtures(db *sql.DB, dryRun bool) (map[string]string, error) {
+	applied := make(map[string]string)
+	if !dryRun {
+		if _, err := db.Exec("CREATE TABLE IF NOT EXISTS `" + TrackingTable + "` (" +
+			"name VARCHAR(255) NOT NULL PRIMARY KEY, " +
+			"checksum CHAR(64) NOT NULL, " +
+			"applied_at DATETIME NOT NULL, " +
+			"duration_ms BIGINT NOT NULL DEFAULT 0)"); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", TrackingTable, err)
+		}
+	}
+
+	var exists int
+	if err := db.QueryRow("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", TrackingTable).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check %s: %w", TrackingTable, err)
+	}
+	if exists == 0 {
+		return applied, nil
+	}
+	rows, err := db.Query("SELECT name, checksum FROM `" + TrackingTable + "`")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", TrackingTable, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, sum string
+		if err := rows.Scan(&name, &sum); err != nil {
+			return nil, err
+		}
+		applied[name] = sum
+	}
+	return applied, rows.Err()
+}
+
+// runFixture feeds the rendered SQL to the mysql client, which handles
+// DELIMITER blocks and multi-statement files like a restore does
+func runFixture(cfg database.Config, f *Fixture) error {
+	args := []string{
+		fmt.Sprintf("--host=%s", cfg.Host),
+		fmt.Sprintf("--port=%d", cfg.Port),
+		fmt.Sprintf("--user=%s", cfg.User),
+		cfg.DBName,
+	}
+	cmd := shutdown.Command(shutdown.Context(), "mysql", args...)
+	cmd.Stdin = bytes.NewReader(f.SQL)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if cfg.Password != "" {
+		cmd.Env = append(os.Environ(), fmt.Sprintf("MYSQL_PWD=%s", cfg.Password))
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("fixture %s failed: %w: %s", f.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package serverstate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sfDBTools/utils/database"
+)
+
+// hostSpecificVariables belong to the host or its role rather than to the
+// server configuration and are never copied to another host
+var hostSpecificVariables = map[string]bool{
+	"server_id": true, "server_uuid": true, "hostname": true, "port": true, "socket": true,
+	"bind_address": true, "report_host": true, "report_port": true, "datadir": true, "pid_file": true,
+	"log_error": true, "general_log_file": true, "slow_query_log_file": true, "log_bin_basename": true,
+	"log_bin_index": true, "relay_log": true, "relay_log_basename": true, "relay_log_index": true,
+	"read_only": true, "super_read_only": true, "innodb_read_only": true,
+	"gtid_slave_pos": true, "gtid_binlog_pos": true, "gtid_binlog_state": true, "gtid_current_pos": true,
+	"gtid_executed": true, "gtid_purged": true, "wsrep_node_address": true, "wsrep_node_name": true,
+	"wsrep_node_incoming_address": true, "wsrep_sst_receive_address": true,
+}
+
+// ApplyOptions controls which parts of a bundle are reapplied and how
+type ApplyOptions struct {
+	Sections            map[string]bool // sections to apply; all when empty
+	ReplaceEvents       bool            // drop and recreate events that already exist
+	ReplicationPassword string          // needed to configure replication
+	StartReplica        bool            // start replication after configuring it
+}
+
+// Action is one object of the bundle and the statements reapplying it. Skip
+// explains why an action is not applied; Err is set when applying failed.
+type Action struct {
+	Section    string
+	Name       string
+	Statements []string
+	Skip       string
+	Err        error
+	secret     string // redacted from Display
+}
+
+// Display returns the statements with the replication password masked
+func (a *Action) Display() string {
+	text := strings.Join(a.Statements, ";\n") + ";"
+	if a.secret != "" {
+		text = strings.ReplaceAll(text, database.QuoteString(a.secret), "'***'")
+	}
+	return text
+}
+
+// Plan compares the bundle with the server and returns an action per object
+// of the selected sections, in the order they must be applied
+func Plan(db *sql.DB, b *Bundle, opts ApplyOptions) ([]*Action, error) {
+	selected := func(section string) bool { return len(opts.Sections) == 0 || opts.Sections[section] }
+	var actions []*Action
+
+	if selected(SectionPlugins) {
+		active, err := stringSet(db, "SELECT PLUGIN_NAME FROM information_schema.PLUGINS WHERE PLUGIN_STATUS = 'ACTIVE'")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read installed plugins: %w", err)
+		}
+		for _, p := range b.Plugins {
+			a := &Action{Section: SectionPlugins, Name: p.Name,
+				Statements: []string{fmt.Sprintf("INSTALL PLUGIN %s SONAME %s", database.QuoteIdent(p.Name), database.QuoteString(p.Library))}}
+			switch {
+			case active[strings.ToLower(p.Name)]:
+				a.Skip = "already active"
+			case !p.Installed:
+				a.Skip = fmt.Sprintf("loaded by the server configuration; add plugin_load_add = %s to my.cnf", p.Library)
+			}
+			actions = append(actions, a)
+		}
+	}
+
+	if selected(SectionUDFs) {
+		existing, err := stringSet(db, "SELECT name FROM mysql.func")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read UDFs: %w", err)
+		}
+		for _, u := range b.UDFs {
+			aggregate := ""
+			if u.Aggregate {
+				aggregate = "AGGREGATE "
+			}
+			a := &Action{Section: SectionUDFs, Name: u.Name, Statements: []string{
+				fmt.Sprintf("CREATE %sFUNCTION %s RETURNS %s SONAME %s", aggregate, database.QuoteIdent(u.Name), u.Returns, database.QuoteString(u.Library))}}
+			if existing[strings.ToLower(u.Name)] {
+				a.Skip = "already exists"
+			}
+			actions = append(actions, a)
+		}
+	}
+
+	if selected(SectionVariables) {
+		current, err := globalVariables(db)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read global variables: %w", err)
+		}
+		for _, v := range b.Variables {
+			a := &Action{Section: SectionVariables, Name: v.Name,
+				Statements: []string{fmt.Sprintf("SET GLOBAL %s = %s", v.Name, variableLiteral(v.Value))}}
+			value, known := current[v.Name]
+			switch {
+			case hostSpecificVariables[v.Name]:
+				a.Skip = "host-specific"
+			case !known:
+				a.Skip = "not supported by this server"
+			case value == v.Value:
+				a.Skip = "already set"
+			case v.ReadOnly:
+				a.Skip = fmt.Sprintf("read-only; set %s = %s in my.cnf and restart", v.Name, v.Value)
+			}
+			actions = append(actions, a)
+		}
+	}
+
+	if selected(SectionEvents) {
+		schemas, err := stringSet(db, "SELECT SCHEMA_NAME FROM information_schema.SCHEMATA")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read databases: %w", err)
+		}
+		events, err := stringSet(db, "SELECT CONCAT(EVENT_SCHEMA, '.', EVENT_NAME) FROM information_schema.EVENTS")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read events: %w", err)
+		}
+		for _, e := range b.Events {
+			name := e.Schema + "." + e.Name
+			a := &Action{Section: SectionEvents, Name: name, Statements: []string{
+				"SET SESSION sql_mode = " + database.QuoteString(e.SQLMode),
+				"SET SESSION time_zone = " + database.QuoteString(e.TimeZone),
+				"SET SESSION character_set_client = " + database.QuoteString(e.CharacterSetClient),
+				"SET SESSION collation_connection = " + database.QuoteString(e.CollationConnection),
+				"USE " + database.QuoteIdent(e.Schema),
+			}}
+			exists := events[strings.ToLower(name)]
+			if exists && opts.ReplaceEvents {
+				a.Statements = append(a.Statements, fmt.Sprintf("DROP EVENT IF EXISTS %s.%s", database.QuoteIdent(e.Schema), database.QuoteIdent(e.Name)))
+			}
+			a.Statements = append(a.Statements, e.Create)
+			switch {
+			case !schemas[strings.ToLower(e.Schema)]:
+				a.Skip = fmt.Sprintf("database %s does not exist; restore it first", e.Schema)
+			case exists && !opts.ReplaceEvents:
+				a.Skip = "already exists (use --replace-events to recreate)"
+			}
+			actions = append(actions, a)
+		}
+	}
+
+	if selected(SectionReplication) && b.Replication != nil {
+		actions = append(actions, planReplication(db, b.Replication, opts))
+	}
+	return actions, nil
+}
+
+// planReplication points the server at the bundle's replication source. Only
+// GTID replication is configured: a binlog position would have to come from
+// the data backup the host is rebuilt from.
+func planReplication(db *sql.DB, r *Replication, opts ApplyOptions) *Action {
+	source := fmt.Sprintf("%s:%d", r.SourceHost, r.SourcePort)
+	clauses := []string{
+		"MASTER_HOST = " + database.QuoteString(r.SourceHost),
+		fmt.Sprintf("MASTER_PORT = %d", r.SourcePort),
+		"MASTER_USER = " + database.QuoteString(r.SourceUser),
+		"MASTER_PASSWORD = " + database.QuoteString(opts.ReplicationPassword),
+	}
+	if r.ConnectRetry > 0 {
+		clauses = append(clauses, fmt.Sprintf("MASTER_CONNECT_RETRY = %d", r.ConnectRetry))
+	}
+	if r.SSL {
+		clauses = append(clauses, "MASTER_SSL = 1")
+	}
+	switch {
+	case r.UsingGTID != "":
+		clauses = append(clauses, "MASTER_USE_GTID = "+strings.ToLower(r.UsingGTID))
+	case r.AutoPosition:
+		clauses = append(clauses, "MASTER_AUTO_POSITION = 1")
+	}
+
+	a := &Action{Section: SectionReplication, Name: "source " + source, secret: opts.ReplicationPassword,
+		Statements: []string{"CHANGE MASTER TO " + strings.Join(clauses, ", ")}}
+	if opts.StartReplica {
+		a.Statements = append(a.Statements, "START SLAVE")
+	}
+
+	if status, err := database.GetReplicaStatus(context.Background(), db); err == nil && status != nil {
+		a.Skip = fmt.Sprintf("already replicating from %s:%s", status.Value("Master_Host", "Source_Host"), status.Value("Master_Port", "Source_Port"))
+	} else if r.UsingGTID == "" && !r.AutoPosition {
+		a.Skip = fmt.Sprintf("position-based replication; seed it with 'sfDBTools replication seed --from-backup <id>' (was %s:%d)", r.SourceLogFile, r.SourceLogPos)
+	} else if opts.ReplicationPassword == "" {
+		a.Skip = "no replication password given (--replication-password)"
+	}
+	return a
+}
+
+// Apply runs the actions that are not skipped, continuing past failures, and
+// returns how many were applied and how many failed
+func Apply(db *sql.DB, actions []*Action) (applied, failed int) {
+	ctx := context.Background()
+	for _, a := range actions {
+		if a.Skip != "" {
+			continue
+		}
+		// Session settings and USE must reach the statements on the same connection
+		conn, err := db.Conn(ctx)
+		if err == nil {
+			for _, stmt := range a.Statements {
+				if _, err = conn.ExecContext(ctx, stmt); err != nil {
+					break
+				}
+			}
+			conn.Close()
+		}
+		if err != nil {
+			a.Err = err
+			failed++
+			continue
+		}
+		applied++
+	}
+	return applied, failed
+}
+
+// variableLiteral renders a variable value for SET GLOBAL: numbers and
+// ON/OFF unquoted, everything else as a string
+func variableLiteral(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	switch strings.ToUpper(value) {
+	case "ON", "OFF":
+		return strings.ToUpper(value)
+	}
+	return database.QuoteString(value)
+}
+
+func globalVariables(db *sql.DB) (map[string]string, error) {
+	rows, err := db.Query("SHOW GLOBAL VARIABLES")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	vars := make(map[string]string)
+	for rows.Next() {
+		var name string
+		var value sql.NullString
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		vars[strings.ToLower(name)] = value.String
+	}
+	return vars, rows.Err()
+}
+
+// stringSet returns the lowercased values of the first column of query
+func stringSet(db *sql.DB, query string) (map[string]bool, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	set := make(map[string]bool)
+	for rows.Next() {
+		var v sql.NullString
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		set[strings.ToLower(v.String)] = true
+	}
+	return set, rows.Err()
+}
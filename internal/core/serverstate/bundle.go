@@ -0,0 +1,121 @@
+// Package serverstate captures the server-level objects a full-server
+// recovery needs besides schemas and grants — events, plugins, UDFs, global
+// variables changed from their defaults and the replication source — into a
+// JSON bundle, and reapplies them on a rebuilt host.
+package serverstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"sfDBTools/utils/fs"
+)
+
+// BundleVersion is the format version of the bundle file
+const BundleVersion = 1
+
+// Sections of a bundle, as accepted by --only
+const (
+	SectionEvents      = "events"
+	SectionPlugins     = "plugins"
+	SectionUDFs        = "udfs"
+	SectionVariables   = "variables"
+	SectionReplication = "replication"
+)
+
+// Sections lists every section in the order they are applied
+var Sections = []string{SectionPlugins, SectionUDFs, SectionVariables, SectionEvents, SectionReplication}
+
+// Bundle is the server state of one host
+type Bundle struct {
+	Version       int          `json:"version"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Host          string       `json:"host"`
+	Port          int          `json:"port"`
+	ServerVersion string       `json:"server_version"`
+	Events        []Event      `json:"events"`
+	Plugins       []Plugin     `json:"plugins"`
+	UDFs          []UDF        `json:"udfs"`
+	Variables     []Variable   `json:"variables"`
+	Replication   *Replication `json:"replication,omitempty"`
+}
+
+// Event is a scheduled event with the session settings it was created under
+type Event struct {
+	Schema              string `json:"schema"`
+	Name                string `json:"name"`
+	Create              string `json:"create"`
+	SQLMode             string `json:"sql_mode"`
+	TimeZone            string `json:"time_zone"`
+	CharacterSetClient  string `json:"character_set_client"`
+	CollationConnection string `json:"collation_connection"`
+}
+
+// Plugin is a plugin loaded from a shared library. Installed plugins came
+// from INSTALL PLUGIN; the others are loaded by the server configuration.
+type Plugin struct {
+	Name      string `json:"name"`
+	Library   string `json:"library"`
+	Installed bool   `json:"installed"`
+}
+
+// UDF is a loadable function registered in mysql.func
+type UDF struct {
+	Name      string `json:"name"`
+	Returns   string `json:"returns"` // STRING, REAL, INTEGER or DECIMAL
+	Library   string `json:"library"`
+	Aggregate bool   `json:"aggregate"`
+}
+
+// Variable is a global variable whose value does not come from the built-in default
+type Variable struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Default  string `json:"default,omitempty"`
+	Origin   string `json:"origin,omitempty"` // CONFIG, COMMAND-LINE, SQL, ...
+	ReadOnly bool   `json:"read_only"`
+}
+
+// Replication is the replication source of a replica; the password is never
+// captured and must be given on restore
+type Replication struct {
+	SourceHost    string `json:"source_host"`
+	SourcePort    int    `json:"source_port"`
+	SourceUser    string `json:"source_user"`
+	UsingGTID     string `json:"using_gtid,omitempty"` // MariaDB: Current_Pos or Slave_Pos
+	AutoPosition  bool   `json:"auto_position,omitempty"`
+	ConnectRetry  int    `json:"connect_retry,omitempty"`
+	SSL           bool   `json:"ssl,omitempty"`
+	SourceLogFile string `json:"source_log_file,omitempty"` // position executed when captured, for reference
+	SourceLogPos  int64  `json:"source_log_pos,omitempty"`
+}
+
+// Save writes the bundle as JSON to path
+func (b *Bundle) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode server state: %w", err)
+	}
+	if err := fs.NewManager().File().WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write server state bundle: %w", err)
+	}
+	return nil
+}
+
+// Load reads a bundle written by Save
+func Load(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server state bundle: %w", err)
+	}
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s is not a server state bundle: %w", path, err)
+	}
+	if b.Version == 0 || b.Version > BundleVersion {
+		return nil, fmt.Errorf("%s has unsupported server state bundle version %d", path, b.Version)
+	}
+	return &b, nil
+}
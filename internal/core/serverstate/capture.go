@@ -0,0 +1,211 @@
+package serverstate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/utils/database"
+)
+
+// udfReturnTypes maps mysql.func.ret to the RETURNS clause
+var udfReturnTypes = map[int]string{0: "STRING", 1: "REAL", 2: "INTEGER", 4: "DECIMAL"}
+
+// Capture reads the server state of db. Sections the account cannot read are
+// left empty and reported in the returned warnings.
+func Capture(db *sql.DB, host string, port int) (*Bundle, []string, error) {
+	b := &Bundle{Version: BundleVersion, CreatedAt: time.Now().UTC(), Host: host, Port: port}
+	if err := db.QueryRow("SELECT VERSION()").Scan(&b.ServerVersion); err != nil {
+		return nil, nil, fmt.Errorf("failed to read server version: %w", err)
+	}
+
+	var warnings []string
+	var err error
+	if b.Events, err = captureEvents(db); err != nil {
+		warnings = append(warnings, fmt.Sprintf("events not captured: %v", err))
+	}
+	if b.Plugins, err = capturePlugins(db); err != nil {
+		warnings = append(warnings, fmt.Sprintf("plugins not captured: %v", err))
+	}
+	if b.UDFs, err = captureUDFs(db); err != nil {
+		warnings = append(warnings, fmt.Sprintf("UDFs not captured: %v", err))
+	}
+	if b.Variables, err = captureVariables(db); err != nil {
+		warnings = append(warnings, fmt.Sprintf("global variables not captured: %v", err))
+	}
+	if b.Replication, err = captureReplication(db); err != nil {
+		warnings = append(warnings, fmt.Sprintf("replication configuration not captured: %v", err))
+	}
+	return b, warnings, nil
+}
+
+func captureEvents(db *sql.DB) ([]Event, error) {
+	rows, err := db.Query("SELECT EVENT_SCHEMA, EVENT_NAME FROM information_schema.EVENTS ORDER BY EVENT_SCHEMA, EVENT_NAME")
+	if err != nil {
+		return nil, err
+	}
+	var names [][2]string
+	for rows.Next() {
+		var schema, name string
+		if err := rows.Scan(&schema, &name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, [2]string{schema, name})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(names))
+	for _, n := range names {
+		// Event, sql_mode, time_zone, Create Event, character_set_client,
+		// collation_connection, Database Collation
+		var cols [7]sql.NullString
+		if err := db.QueryRow(fmt.Sprintf("SHOW CREATE EVENT %s.%s", database.QuoteIdent(n[0]), database.QuoteIdent(n[1]))).
+			Scan(&cols[0], &cols[1], &cols[2], &cols[3], &cols[4], &cols[5], &cols[6]); err != nil {
+			return nil, fmt.Errorf("SHOW CREATE EVENT %s.%s: %w", n[0], n[1], err)
+		}
+		events = append(events, Event{
+			Schema:              n[0],
+			Name:                n[1],
+			SQLMode:             cols[1].String,
+			TimeZone:            cols[2].String,
+			Create:              cols[3].String,
+			CharacterSetClient:  cols[4].String,
+			CollationConnection: cols[5].String,
+		})
+	}
+	return events, nil
+}
+
+func capturePlugins(db *sql.DB) ([]Plugin, error) {
+	installed := make(map[string]bool)
+	if rows, err := db.Query("SELECT name FROM mysql.plugin"); err == nil {
+		for rows.Next() {
+			var name string
+			if rows.Scan(&name) == nil {
+				installed[strings.ToLower(name)] = true
+			}
+		}
+		rows.Close()
+	}
+
+	rows, err := db.Query(`SELECT PLUGIN_NAME, PLUGIN_LIBRARY FROM information_schema.PLUGINS
+		WHERE PLUGIN_LIBRARY IS NOT NULL AND PLUGIN_STATUS = 'ACTIVE' ORDER BY PLUGIN_NAME`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var plugins []Plugin
+	for rows.Next() {
+		var p Plugin
+		if err := rows.Scan(&p.Name, &p.Library); err != nil {
+			return nil, err
+		}
+		p.Installed = installed[strings.ToLower(p.Name)]
+		plugins = append(plugins, p)
+	}
+	return plugins, rows.Err()
+}
+
+func captureUDFs(db *sql.DB) ([]UDF, error) {
+	rows, err := db.Query("SELECT name, ret, dl, type FROM mysql.func ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var udfs []UDF
+	for rows.Next() {
+		var u UDF
+		var ret int
+		var kind string
+		if err := rows.Scan(&u.Name, &ret, &u.Library, &kind); err != nil {
+			return nil, err
+		}
+		u.Returns = udfReturnTypes[ret]
+		if u.Returns == "" {
+			u.Returns = "STRING"
+		}
+		u.Aggregate = strings.EqualFold(kind, "aggregate")
+		udfs = append(udfs, u)
+	}
+	return udfs, rows.Err()
+}
+
+// captureVariables reads the global variables set by the configuration, the
+// command line or SET GLOBAL, from information_schema.SYSTEM_VARIABLES on
+// MariaDB or performance_schema.variables_info on MySQL 8
+func captureVariables(db *sql.DB) ([]Variable, error) {
+	rows, err := db.Query(`SELECT VARIABLE_NAME, COALESCE(GLOBAL_VALUE, ''), COALESCE(DEFAULT_VALUE, ''), GLOBAL_VALUE_ORIGIN, READ_ONLY
+		FROM information_schema.SYSTEM_VARIABLES
+		WHERE GLOBAL_VALUE_ORIGIN IN ('CONFIG', 'COMMAND-LINE', 'SQL')
+		AND NOT (GLOBAL_VALUE <=> DEFAULT_VALUE)
+		ORDER BY VARIABLE_NAME`)
+	if err != nil {
+		return captureVariablesMySQL(db)
+	}
+	defer rows.Close()
+	var vars []Variable
+	for rows.Next() {
+		var v Variable
+		var readOnly string
+		if err := rows.Scan(&v.Name, &v.Value, &v.Default, &v.Origin, &readOnly); err != nil {
+			return nil, err
+		}
+		v.Name = strings.ToLower(v.Name)
+		v.ReadOnly = readOnly == "YES"
+		vars = append(vars, v)
+	}
+	return vars, rows.Err()
+}
+
+func captureVariablesMySQL(db *sql.DB) ([]Variable, error) {
+	rows, err := db.Query(`SELECT vi.VARIABLE_NAME, gv.VARIABLE_VALUE, vi.VARIABLE_SOURCE
+		FROM performance_schema.variables_info vi
+		JOIN performance_schema.global_variables gv ON gv.VARIABLE_NAME = vi.VARIABLE_NAME
+		WHERE vi.VARIABLE_SOURCE <> 'COMPILED'
+		ORDER BY vi.VARIABLE_NAME`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var vars []Variable
+	for rows.Next() {
+		var v Variable
+		if err := rows.Scan(&v.Name, &v.Value, &v.Origin); err != nil {
+			return nil, err
+		}
+		v.Name = strings.ToLower(v.Name)
+		vars = append(vars, v)
+	}
+	return vars, rows.Err()
+}
+
+// captureReplication reads the replication source of a replica; nil when the
+// server does not replicate
+func captureReplication(db *sql.DB) (*Replication, error) {
+	status, err := database.GetReplicaStatus(context.Background(), db)
+	if err != nil || status == nil {
+		return nil, err
+	}
+	r := &Replication{
+		SourceHost:    status.Value("Master_Host", "Source_Host"),
+		SourceUser:    status.Value("Master_User", "Source_User"),
+		UsingGTID:     status["Using_Gtid"],
+		AutoPosition:  status["Auto_Position"] == "1",
+		SSL:           status.Value("Master_SSL_Allowed", "Source_SSL_Allowed") == "Yes",
+		SourceLogFile: status.Value("Relay_Master_Log_File", "Relay_Source_Log_File"),
+	}
+	if strings.EqualFold(r.UsingGTID, "No") {
+		r.UsingGTID = ""
+	}
+	r.SourcePort, _ = strconv.Atoi(status.Value("Master_Port", "Source_Port"))
+	r.ConnectRetry, _ = strconv.Atoi(status["Connect_Retry"])
+	r.SourceLogPos, _ = strconv.ParseInt(status.Value("Exec_Master_Log_Pos", "Exec_Source_Log_Pos"), 10, 64)
+	return r, nil
+}
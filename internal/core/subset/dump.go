@@ -0,0 +1,109 @@
+package subset
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/shutdown"
+)
+
+// whereLimit keeps each --where argument well below the kernel's 128 KiB
+// limit for a single command line argument
+const whereLimit = 64 << 10
+
+// Dump writes the schema of the whole database followed by the selected rows
+// of every table to out, using mysqldump. Rows are dumped in chunks filtered
+// by their keys, so the dump reflects the rows as they are when each chunk
+// is read.
+func Dump(source database.Config, sel *Selection, out io.Writer) error {
+	var base []string
+	if cfg, err := config.Get(); err == nil && cfg != nil {
+		base = common.RemoveDataFlags(common.ParseArgsString(cfg.Mysqldump.Args))
+	}
+	base = append(base,
+		fmt.Sprintf("--host=%s", source.Host),
+		fmt.Sprintf("--port=%d", source.Port),
+		fmt.Sprintf("--user=%s", source.User))
+
+	fmt.Fprintf(out, "-- sfDBTools subset of %s: %d row(s) from %d table(s)\n", sel.Graph.Database, sel.Total(), len(sel.Tables()))
+	for _, fk := range sel.Dangling {
+		fmt.Fprintf(out, "-- not followed: %s\n", fk)
+	}
+
+	schema := append(append([]string{}, base...), "--no-data", sel.Graph.Database)
+	if err := runDump(schema, source.Password, out); err != nil {
+		return fmt.Errorf("failed to dump schema of %s: %w", sel.Graph.Database, err)
+	}
+
+	for _, name := range sel.Tables() {
+		table := sel.Graph.Tables[name]
+		for _, where := range whereChunks(table.Key, sel.Rows[name].keys) {
+			args := append(append([]string{}, base...),
+				"--no-create-info", "--skip-routines", "--skip-events", "--skip-triggers",
+				"--where="+where, sel.Graph.Database, name)
+			if err := runDump(args, source.Password, out); err != nil {
+				return fmt.Errorf("failed to dump rows of %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func runDump(args []string, password string, out io.Writer) error {
+	cmd := shutdown.Command(shutdown.Context(), "mysqldump", args...)
+	cmd.Stdout = out
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+	if password != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("MYSQL_PWD=%s", password))
+	}
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// whereChunks splits the keys into "(a, b) IN (...)" conditions of at most
+// whereLimit bytes each
+func whereChunks(cols []string, keys [][]string) []string {
+	lhs := columnList(cols)
+	if len(cols) > 1 {
+		lhs = "(" + lhs + ")"
+	}
+	var chunks []string
+	var sb strings.Builder
+	flush := func() {
+		if sb.Len() > 0 {
+			chunks = append(chunks, lhs+" IN ("+sb.String()+")")
+			sb.Reset()
+		}
+	}
+	for _, k := range keys {
+		lit := make([]string, len(k))
+		for i, v := range k {
+			lit[i] = database.QuoteString(v)
+		}
+		tuple := strings.Join(lit, ", ")
+		if len(cols) > 1 {
+			tuple = "(" + tuple + ")"
+		}
+		if sb.Len()+len(tuple) > whereLimit {
+			flush()
+		}
+		if sb.Len() > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(tuple)
+	}
+	flush()
+	return chunks
+}
@@ -0,0 +1,336 @@
+package subset
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+)
+
+// batchSize bounds the number of key tuples per IN list
+const batchSize = 500
+
+// Seed selects the starting rows of a table
+type Seed struct {
+	Table string
+	Where string // empty selects every row
+}
+
+// rowSet holds the keys of the rows selected from one table, in the order
+// they were found
+type rowSet struct {
+	keys  [][]string
+	index map[string]bool
+	down  map[string]bool // keys whose children were already followed
+}
+
+func newRowSet() *rowSet {
+	return &rowSet{index: map[string]bool{}, down: map[string]bool{}}
+}
+
+func tupleID(t []string) string { return strings.Join(t, "\x00") }
+
+// add returns the tuples not selected before, and, when down is set, the
+// tuples whose children have not been followed yet
+func (r *rowSet) add(tuples [][]string, down bool) (added, expandDown [][]string) {
+	for _, t := range tuples {
+		id := tupleID(t)
+		if !r.index[id] {
+			r.index[id] = true
+			r.keys = append(r.keys, t)
+			added = append(added, t)
+		}
+		if down && !r.down[id] {
+			r.down[id] = true
+			expandDown = append(expandDown, t)
+		}
+	}
+	return added, expandDown
+}
+
+// Selection is the subset: the keys of the selected rows per table
+type Selection struct {
+	Graph *Graph
+	Rows  map[string]*rowSet
+	// Dangling lists foreign keys of selected rows whose parent table is not
+	// followed, so the subset is not referentially complete
+	Dangling []string
+}
+
+// Count returns the number of rows selected from table
+func (s *Selection) Count(table string) int {
+	if r, ok := s.Rows[table]; ok {
+		return len(r.keys)
+	}
+	return 0
+}
+
+// Tables lists the tables with selected rows in name order
+func (s *Selection) Tables() []string {
+	names := make([]string, 0, len(s.Rows))
+	for name, r := range s.Rows {
+		if len(r.keys) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Total returns the number of selected rows
+func (s *Selection) Total() int {
+	n := 0
+	for _, r := range s.Rows {
+		n += len(r.keys)
+	}
+	return n
+}
+
+// EstimatedBytes estimates the size of the selected rows from the average
+// row length of each table
+func (s *Selection) EstimatedBytes(table string) int64 {
+	if t, ok := s.Graph.Tables[table]; ok {
+		return int64(s.Count(table)) * t.AvgRowLength
+	}
+	return 0
+}
+
+// TotalEstimatedBytes estimates the size of the whole subset
+func (s *Selection) TotalEstimatedBytes() int64 {
+	var n int64
+	for table := range s.Rows {
+		n += s.EstimatedBytes(table)
+	}
+	return n
+}
+
+// ExtractOptions controls which rows are selected
+type ExtractOptions struct {
+	Seeds      []Seed
+	FollowFKs  bool            // pull in referenced parents and referencing children
+	ParentOnly bool            // with FollowFKs, only pull in referenced parents
+	Exclude    map[string]bool // tables never pulled in
+	MaxRows    int             // stop when the subset grows beyond this; 0 is unlimited
+}
+
+type pending struct {
+	table      string
+	added      [][]string // newly selected: follow references to parents
+	expandDown [][]string // follow references from children
+}
+
+// Extract selects the seed rows and, with FollowFKs, every row they depend
+// on: the parents referenced by any selected row, and the children
+// referencing seed rows or rows reached through children. Parents pulled in
+// only as dependencies do not pull in their other children, which would
+// otherwise drag in most of the database through shared lookup tables.
+func Extract(db *sql.DB, g *Graph, opts ExtractOptions) (*Selection, error) {
+	lg, _ := logger.Get()
+	sel := &Selection{Graph: g, Rows: map[string]*rowSet{}}
+	var queue []pending
+
+	push := func(table string, tuples [][]string, down bool) error {
+		r, ok := sel.Rows[table]
+		if !ok {
+			r = newRowSet()
+			sel.Rows[table] = r
+		}
+		added, expandDown := r.add(tuples, down)
+		if opts.MaxRows > 0 && sel.Total() > opts.MaxRows {
+			return fmt.Errorf("subset grew beyond %d rows at table %s; narrow --where, exclude tables or raise --max-rows", opts.MaxRows, table)
+		}
+		if len(added) > 0 || len(expandDown) > 0 {
+			queue = append(queue, pending{table: table, added: added, expandDown: expandDown})
+		}
+		return nil
+	}
+
+	for _, seed := range opts.Seeds {
+		t, ok := g.Tables[seed.Table]
+		if !ok {
+			return nil, fmt.Errorf("table %s not found in %s", seed.Table, g.Database)
+		}
+		if len(t.Key) == 0 {
+			return nil, fmt.Errorf("table %s has no primary key or NOT NULL unique key to identify rows", t.Name)
+		}
+		query := fmt.Sprintf("SELECT %s FROM %s", columnList(t.Key), database.QuoteIdent(t.Name))
+		if seed.Where != "" {
+			query += " WHERE " + seed.Where
+		}
+		tuples, err := queryTuples(db, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select seed rows of %s: %w", t.Name, err)
+		}
+		lg.Debug("Subset seed rows selected", logger.String("table", t.Name), logger.Int("rows", len(tuples)))
+		if err := push(t.Name, tuples, true); err != nil {
+			return nil, err
+		}
+	}
+
+	dangling := map[string]bool{}
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+		table := g.Tables[item.table]
+
+		for _, fk := range g.FKs {
+			// Parents referenced by the new rows
+			if fk.Child == item.table && len(item.added) > 0 {
+				parent, ok := g.Tables[fk.Parent]
+				if !opts.FollowFKs || opts.Exclude[fk.Parent] || !ok || len(parent.Key) == 0 {
+					dangling[fk.String()] = true
+					continue
+				}
+				refs, err := lookup(db, table, item.added, fk.ChildCols)
+				if err != nil {
+					return nil, err
+				}
+				keys, err := lookupBy(db, parent, fk.ParentCols, refs)
+				if err != nil {
+					return nil, err
+				}
+				if err := push(parent.Name, keys, false); err != nil {
+					return nil, err
+				}
+			}
+			// Children referencing rows reached downwards
+			if fk.Parent == item.table && len(item.expandDown) > 0 && opts.FollowFKs && !opts.ParentOnly {
+				child, ok := g.Tables[fk.Child]
+				if opts.Exclude[fk.Child] || !ok {
+					continue
+				}
+				if len(child.Key) == 0 {
+					lg.Warn("Child table without row key not followed", logger.String("table", fk.Child))
+					continue
+				}
+				refs, err := lookup(db, table, item.expandDown, fk.ParentCols)
+				if err != nil {
+					return nil, err
+				}
+				keys, err := lookupBy(db, child, fk.ChildCols, refs)
+				if err != nil {
+					return nil, err
+				}
+				if err := push(child.Name, keys, true); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for fk := range dangling {
+		sel.Dangling = append(sel.Dangling, fk)
+	}
+	sort.Strings(sel.Dangling)
+	return sel, nil
+}
+
+// lookup returns the distinct non-NULL values of cols for the rows of table
+// with the given keys
+func lookup(db *sql.DB, table *Table, keys [][]string, cols []string) ([][]string, error) {
+	if sameColumns(table.Key, cols) {
+		return keys, nil
+	}
+	return lookupBy(db, &Table{Name: table.Name, Key: cols}, table.Key, keys)
+}
+
+// lookupBy returns the distinct key tuples of table's rows whose cols match
+// one of values; tuples with a NULL are dropped
+func lookupBy(db *sql.DB, table *Table, cols []string, values [][]string) ([][]string, error) {
+	var out [][]string
+	seen := map[string]bool{}
+	for start := 0; start < len(values); start += batchSize {
+		batch := values[start:min(start+batchSize, len(values))]
+		cond, args := inCondition(cols, batch)
+		query := fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s", columnList(table.Key), database.QuoteIdent(table.Name), cond)
+		tuples, err := queryTuples(db, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to follow rows of %s: %w", table.Name, err)
+		}
+		for _, t := range tuples {
+			if id := tupleID(t); !seen[id] {
+				seen[id] = true
+				out = append(out, t)
+			}
+		}
+	}
+	return out, nil
+}
+
+// queryTuples runs query and returns its rows as strings, skipping rows with a NULL
+func queryTuples(db *sql.DB, query string, args ...interface{}) ([][]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var out [][]string
+	raw := make([]sql.NullString, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+next:
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		t := make([]string, len(cols))
+		for i, v := range raw {
+			if !v.Valid {
+				continue next
+			}
+			t[i] = v.String
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// inCondition builds "(a, b) IN ((?, ?), ...)" with its arguments
+func inCondition(cols []string, tuples [][]string) (string, []interface{}) {
+	args := make([]interface{}, 0, len(cols)*len(tuples))
+	one := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ") + ")"
+	if len(cols) == 1 {
+		one = "?"
+	}
+	parts := make([]string, len(tuples))
+	for i, t := range tuples {
+		parts[i] = one
+		for _, v := range t {
+			args = append(args, v)
+		}
+	}
+	lhs := columnList(cols)
+	if len(cols) > 1 {
+		lhs = "(" + lhs + ")"
+	}
+	return lhs + " IN (" + strings.Join(parts, ", ") + ")", args
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func columnList(cols []string) string {
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = database.QuoteIdent(c)
+	}
+	return strings.Join(quoted, ", ")
+}
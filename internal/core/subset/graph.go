@@ -0,0 +1,230 @@
+package subset
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Table is a base table of the database with the columns rows are identified by
+type Table struct {
+	Name         string
+	Key          []string // primary key, or the first unique key without nullable columns
+	Rows         int64    // estimated row count from information_schema
+	AvgRowLength int64
+}
+
+// ForeignKey is a reference from Child.ChildCols to Parent.ParentCols
+type ForeignKey struct {
+	Name       string
+	Child      string
+	ChildCols  []string
+	Parent     string
+	ParentCols []string
+}
+
+func (fk ForeignKey) String() string {
+	return fmt.Sprintf("%s(%s) -> %s(%s)", fk.Child, strings.Join(fk.ChildCols, ","), fk.Parent, strings.Join(fk.ParentCols, ","))
+}
+
+// Graph is the foreign key graph of one database
+type Graph struct {
+	Database string
+	Tables   map[string]*Table
+	FKs      []ForeignKey
+	// CrossDatabase lists references to other databases, which are not followed
+	CrossDatabase []string
+}
+
+// LoadGraph reads the tables, row keys and foreign keys of dbName
+func LoadGraph(db *sql.DB, dbName string) (*Graph, error) {
+	g := &Graph{Database: dbName, Tables: map[string]*Table{}}
+
+	rows, err := db.Query(`SELECT table_name, COALESCE(table_rows, 0), COALESCE(avg_row_length, 0)
+		FROM information_schema.tables WHERE table_schema = ? AND table_type = 'BASE TABLE'`, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tables of %s: %w", dbName, err)
+	}
+	for rows.Next() {
+		t := &Table{}
+		if err := rows.Scan(&t.Name, &t.Rows, &t.AvgRowLength); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read tables of %s: %w", dbName, err)
+		}
+		g.Tables[t.Name] = t
+	}
+	rows.Close()
+	if len(g.Tables) == 0 {
+		return nil, fmt.Errorf("database %s has no tables or does not exist", dbName)
+	}
+
+	if err := g.loadKeys(db); err != nil {
+		return nil, err
+	}
+	if err := g.loadForeignKeys(db); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// loadKeys picks the primary key of every table, or its first unique key
+// whose columns are all NOT NULL
+func (g *Graph) loadKeys(db *sql.DB) error {
+	rows, err := db.Query(`SELECT s.table_name, s.index_name, s.column_name, c.is_nullable
+		FROM information_schema.statistics s
+		JOIN information_schema.columns c
+		  ON c.table_schema = s.table_schema AND c.table_name = s.table_name AND c.column_name = s.column_name
+		WHERE s.table_schema = ? AND s.non_unique = 0
+		ORDER BY s.table_name, s.index_name = 'PRIMARY' DESC, s.index_name, s.seq_in_index`, g.Database)
+	if err != nil {
+		return fmt.Errorf("failed to read keys of %s: %w", g.Database, err)
+	}
+	defer rows.Close()
+
+	type index struct {
+		cols     []string
+		nullable bool
+	}
+	indexes := map[string][]string{} // table -> index names in order
+	cols := map[string]*index{}
+	for rows.Next() {
+		var table, name, column, nullable string
+		if err := rows.Scan(&table, &name, &column, &nullable); err != nil {
+			return fmt.Errorf("failed to read keys of %s: %w", g.Database, err)
+		}
+		id := table + "\x00" + name
+		idx, ok := cols[id]
+		if !ok {
+			idx = &index{}
+			cols[id] = idx
+			indexes[table] = append(indexes[table], name)
+		}
+		idx.cols = append(idx.cols, column)
+		idx.nullable = idx.nullable || nullable == "YES"
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read keys of %s: %w", g.Database, err)
+	}
+	for table, names := range indexes {
+		t, ok := g.Tables[table]
+		if !ok {
+			continue
+		}
+		for _, name := range names {
+			if idx := cols[table+"\x00"+name]; !idx.nullable {
+				t.Key = idx.cols
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Graph) loadForeignKeys(db *sql.DB) error {
+	rows, err := db.Query(`SELECT constraint_name, table_name, column_name, referenced_table_schema, referenced_table_name, referenced_column_name
+		FROM information_schema.key_column_usage
+		WHERE table_schema = ? AND referenced_table_name IS NOT NULL
+		ORDER BY table_name, constraint_name, ordinal_position`, g.Database)
+	if err != nil {
+		return fmt.Errorf("failed to read foreign keys of %s: %w", g.Database, err)
+	}
+	defer rows.Close()
+
+	byName := map[string]*ForeignKey{}
+	var order []string
+	for rows.Next() {
+		var name, table, column, refSchema, refTable, refColumn string
+		if err := rows.Scan(&name, &table, &column, &refSchema, &refTable, &refColumn); err != nil {
+			return fmt.Errorf("failed to read foreign keys of %s: %w", g.Database, err)
+		}
+		if refSchema != g.Database {
+			g.CrossDatabase = append(g.CrossDatabase, fmt.Sprintf("%s.%s -> %s.%s", table, name, refSchema, refTable))
+			continue
+		}
+		id := table + "\x00" + name
+		fk, ok := byName[id]
+		if !ok {
+			fk = &ForeignKey{Name: name, Child: table, Parent: refTable}
+			byName[id] = fk
+			order = append(order, id)
+		}
+		fk.ChildCols = append(fk.ChildCols, column)
+		fk.ParentCols = append(fk.ParentCols, refColumn)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read foreign keys of %s: %w", g.Database, err)
+	}
+	for _, id := range order {
+		g.FKs = append(g.FKs, *byName[id])
+	}
+	sort.Strings(g.CrossDatabase)
+	return nil
+}
+
+// Cycles returns the groups of tables that reference each other in a loop,
+// including tables referencing themselves, found with Tarjan's algorithm.
+// Following foreign keys terminates on cycles because rows already in the
+// subset are never expanded again; the cycles are reported because they can
+// pull in far more rows than expected.
+func (g *Graph) Cycles() [][]string {
+	edges := map[string][]string{}
+	self := map[string]bool{}
+	for _, fk := range g.FKs {
+		edges[fk.Child] = append(edges[fk.Child], fk.Parent)
+		if fk.Child == fk.Parent {
+			self[fk.Child] = true
+		}
+	}
+	names := make([]string, 0, len(g.Tables))
+	for name := range g.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var cycles [][]string
+	next := 0
+
+	var visit func(v string)
+	visit = func(v string) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range edges[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var component []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		if len(component) > 1 || self[v] {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, name := range names {
+		if _, seen := index[name]; !seen {
+			visit(name)
+		}
+	}
+	return cycles
+}
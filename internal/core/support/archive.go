@@ -0,0 +1,67 @@
+package support
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sfDBTools/utils/fs"
+)
+
+// Write packages the items into a tar.gz at path. The archive has a single
+// top-level directory named after the file and a README listing every file
+// with its source and what was skipped.
+func (b *Bundle) Write(path string) error {
+	out, err := fs.NewManager().File().CreateFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to create support bundle: %w", err)
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	root := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".gz"), ".tar")
+	now := time.Now()
+
+	items := append([]Item{{Name: "README.txt", Source: "sfDBTools", Data: []byte(b.readme(now))}}, b.Items...)
+	for _, item := range items {
+		hdr := &tar.Header{
+			Name:    root + "/" + item.Name,
+			Mode:    0o600,
+			Size:    int64(len(item.Data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to write %s to support bundle: %w", item.Name, err)
+		}
+		if _, err := tw.Write(item.Data); err != nil {
+			return fmt.Errorf("failed to write %s to support bundle: %w", item.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish support bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish support bundle: %w", err)
+	}
+	return nil
+}
+
+func (b *Bundle) readme(now time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "sfDBTools support bundle created %s\n", now.Format(time.RFC3339))
+	sb.WriteString("Passwords, tokens and keys are masked as ***; encrypted connection files are not included.\n\nFiles:\n")
+	for _, item := range b.Items {
+		fmt.Fprintf(&sb, "  %-40s %s\n", item.Name, item.Source)
+	}
+	if len(b.Skipped) > 0 {
+		sb.WriteString("\nNot collected:\n")
+		for _, s := range b.Skipped {
+			fmt.Fprintf(&sb, "  %s\n", s)
+		}
+	}
+	return sb.String()
+}
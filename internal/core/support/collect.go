@@ -0,0 +1,288 @@
+package support
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/internal/version"
+	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/database"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// Collect gathers the bundle content. Nothing fails the collection as a
+// whole: a source that cannot be read is recorded in Skipped.
+func Collect(opts Options) *Bundle {
+	b := &Bundle{}
+	b.collectVersions()
+	b.collectOS()
+	b.collectConfig(opts.ConfigDir)
+	b.collectLogs(opts.LogDir, opts.LogFiles, opts.LogLines)
+	b.collectCatalog(opts.BackupDir, opts.CatalogEntries)
+	if opts.DrillCatalog != "" {
+		b.addFile("catalog/"+filepath.Base(opts.DrillCatalog), opts.DrillCatalog, 0, RedactText)
+	}
+	if opts.Server == nil {
+		b.skip("server variables and MariaDB error log: no connection given (--config or --source_host)")
+		b.collectJournal(opts.ErrorLogLines)
+	} else {
+		b.collectServer(*opts.Server, opts.ErrorLogLines)
+	}
+	return b
+}
+
+func (b *Bundle) add(name, source, content string) {
+	b.Items = append(b.Items, Item{Name: name, Source: source, Data: []byte(content)})
+}
+
+func (b *Bundle) skip(format string, args ...interface{}) {
+	lg, _ := logger.Get()
+	reason := fmt.Sprintf(format, args...)
+	lg.Warn("Support bundle source skipped", logger.String("reason", reason))
+	b.Skipped = append(b.Skipped, reason)
+}
+
+// addFile adds path, keeping its last tailLines lines when tailLines > 0
+func (b *Bundle) addFile(name, path string, tailLines int, redact func(string) string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		b.skip("%s: %v", path, err)
+		return
+	}
+	content := string(data)
+	if tailLines > 0 {
+		content = tail(content, tailLines)
+	}
+	b.add(name, path, redact(content))
+}
+
+func (b *Bundle) collectVersions() {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "sfDBTools %s (commit %s, built %s)\n", version.Version, version.Commit, version.Date)
+	fmt.Fprintf(&sb, "go %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	for _, bin := range []string{"mariadb", "mysql", "mariadb-dump", "mysqldump", "maxctrl"} {
+		if out, err := exec.Command(bin, "--version").CombinedOutput(); err == nil {
+			fmt.Fprintf(&sb, "%s: %s\n", bin, strings.TrimSpace(string(out)))
+		}
+	}
+	b.add("versions.txt", "sfDBTools build and client binaries", sb.String())
+}
+
+func (b *Bundle) collectOS() {
+	var sb strings.Builder
+	if info, err := host.Info(); err == nil {
+		data, _ := json.MarshalIndent(info, "", "  ")
+		sb.Write(data)
+		sb.WriteString("\n\n")
+	}
+	for _, c := range [][]string{{"uname", "-a"}, {"df", "-hP"}, {"free", "-m"}, {"uptime"}} {
+		out, err := exec.Command(c[0], c[1:]...).CombinedOutput()
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&sb, "$ %s\n%s\n", strings.Join(c, " "), out)
+	}
+	b.add("os/system.txt", "host information", sb.String())
+	if _, err := os.Stat("/etc/os-release"); err == nil {
+		b.addFile("os/os-release", "/etc/os-release", 0, func(s string) string { return s })
+	}
+}
+
+// collectConfig adds the YAML files of the config directory. Encrypted
+// connection files (.cnf.enc) are never included.
+func (b *Bundle) collectConfig(dir string) {
+	if dir == "" {
+		b.skip("sfDBTools config: no config.yaml loaded")
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		b.skip("sfDBTools config %s: %v", dir, err)
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !(strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")) {
+			continue
+		}
+		b.addFile("config/"+name, filepath.Join(dir, name), 0, RedactYAML)
+	}
+}
+
+// collectLogs adds the end of the newest sfDBTools log files
+func (b *Bundle) collectLogs(dir string, files, lines int) {
+	if dir == "" {
+		b.skip("sfDBTools logs: file logging is not configured")
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		b.skip("sfDBTools logs %s: %v", dir, err)
+		return
+	}
+	type logFile struct {
+		name string
+		mod  time.Time
+	}
+	var logs []logFile
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".log") {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			logs = append(logs, logFile{e.Name(), info.ModTime()})
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].mod.After(logs[j].mod) })
+	if len(logs) > files {
+		logs = logs[:files]
+	}
+	for _, l := range logs {
+		b.addFile("logs/"+l.name, filepath.Join(dir, l.name), lines, RedactText)
+	}
+}
+
+// collectCatalog adds the newest backup manifests of the catalog
+func (b *Bundle) collectCatalog(dir string, entries int) {
+	if dir == "" || entries <= 0 {
+		return
+	}
+	type manifest struct {
+		path string
+		date time.Time
+	}
+	var found []manifest
+	err := filepath.WalkDir(dir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		if meta, ok := backup_utils.ReadBackupManifest(path); ok {
+			found = append(found, manifest{path, meta.BackupDate})
+		}
+		return nil
+	})
+	if err != nil || len(found) == 0 {
+		b.skip("backup catalog %s: no manifests found", dir)
+		return
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].date.After(found[j].date) })
+	if len(found) > entries {
+		found = found[:entries]
+	}
+	for _, m := range found {
+		rel, err := filepath.Rel(dir, m.path)
+		if err != nil {
+			rel = filepath.Base(m.path)
+		}
+		b.addFile("catalog/"+filepath.ToSlash(rel), m.path, 0, RedactText)
+	}
+}
+
+// collectServer adds the version, global variables and error log of the
+// server. Variables naming a secret are masked.
+func (b *Bundle) collectServer(cfg database.Config, errorLogLines int) {
+	cfg.DBName = ""
+	db, err := database.GetWithoutDB(cfg)
+	if err != nil {
+		b.skip("server %s:%d: %v", cfg.Host, cfg.Port, err)
+		b.collectJournal(errorLogLines)
+		return
+	}
+	defer db.Close()
+	source := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+
+	vars, err := globalVariables(db)
+	if err != nil {
+		b.skip("server variables: %v", err)
+	} else {
+		var sb strings.Builder
+		for _, v := range vars {
+			if secretKey.MatchString(v[0]) && v[1] != "" {
+				v[1] = redacted
+			}
+			fmt.Fprintf(&sb, "%s\t%s\n", v[0], v[1])
+		}
+		b.add("server/variables.txt", source+" SHOW GLOBAL VARIABLES", sb.String())
+	}
+
+	var logError, dataDir string
+	if err := db.QueryRow("SELECT @@GLOBAL.log_error, @@GLOBAL.datadir").Scan(&logError, &dataDir); err != nil {
+		b.skip("MariaDB error log location: %v", err)
+		return
+	}
+	if logError == "" || logError == "stderr" {
+		b.collectJournal(errorLogLines)
+		return
+	}
+	if !filepath.IsAbs(logError) {
+		logError = filepath.Join(dataDir, logError)
+	}
+	if !isLocal(cfg.Host) {
+		b.skip("MariaDB error log %s: server %s is not on this host", logError, cfg.Host)
+		return
+	}
+	b.addFile("server/error.log", logError, errorLogLines, RedactText)
+}
+
+// collectJournal falls back to the systemd journal of the MariaDB service
+func (b *Bundle) collectJournal(lines int) {
+	for _, unit := range []string{"mariadb", "mysql", "mysqld"} {
+		out, err := exec.Command("journalctl", "-u", unit, "-n", fmt.Sprint(lines), "--no-pager", "-q").Output()
+		if err != nil || len(bytes.TrimSpace(out)) == 0 {
+			continue
+		}
+		b.add("server/journal-"+unit+".log", "journalctl -u "+unit, RedactText(string(out)))
+		return
+	}
+	b.skip("MariaDB error log: not found in the systemd journal")
+}
+
+func globalVariables(db *sql.DB) ([][2]string, error) {
+	rows, err := db.Query("SHOW GLOBAL VARIABLES")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var vars [][2]string
+	for rows.Next() {
+		var name string
+		var value sql.NullString
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		vars = append(vars, [2]string{name, value.String})
+	}
+	return vars, rows.Err()
+}
+
+func isLocal(h string) bool {
+	if h == "" || h == "localhost" || h == "127.0.0.1" || h == "::1" {
+		return true
+	}
+	name, err := os.Hostname()
+	return err == nil && strings.EqualFold(name, h)
+}
+
+// tail returns the last n lines of s
+func tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
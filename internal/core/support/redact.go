@@ -0,0 +1,50 @@
+package support
+
+import (
+	"regexp"
+	"strings"
+)
+
+const redacted = "***"
+
+// secretKey matches configuration keys and variable names holding secrets
+var secretKey = regexp.MustCompile(`(?i)(password|passwd|secret|token|access_key|private_key|api_key|credential|webhook)`)
+
+// secretPatterns mask secrets embedded in free text such as log lines
+var secretPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?i)(identified\s+(?:by|with\s+\S+\s+(?:by|as))\s+)('[^']*'|"[^"]*")`), "${1}'" + redacted + "'"},
+	{regexp.MustCompile(`(?i)(password\s*\(\s*)('[^']*'|"[^"]*")`), "${1}'" + redacted + "'"},
+	{regexp.MustCompile(`(?i)("[^"]*(?:password|secret|token)[^"]*"\s*:\s*)"[^"]*"`), `${1}"` + redacted + `"`},
+	{regexp.MustCompile(`(?i)((?:password|passwd|secret|token|access_key)[a-z_]*\s*[=:]\s*)("[^"]*"|'[^']*'|\S+)`), "${1}" + redacted},
+	{regexp.MustCompile(`(--password=)(\S+)`), "${1}" + redacted},
+	{regexp.MustCompile(`(?i)(mysql|mariadb)://([^:/\s]+):[^@\s]+@`), "${1}://${2}:" + redacted + "@"},
+}
+
+// RedactText masks passwords, tokens and keys in free text
+func RedactText(s string) string {
+	for _, p := range secretPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// RedactYAML masks the values of keys that name a secret, keeping the
+// structure of the file readable; other lines go through RedactText
+func RedactYAML(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+		if ok && secretKey.MatchString(key) {
+			// Empty values stay visible: an unset password is useful to know
+			if v := strings.TrimSpace(value); v != "" && v != `""` && v != "''" {
+				lines[i] = key + ": " + redacted
+			}
+			continue
+		}
+		lines[i] = RedactText(line)
+	}
+	return strings.Join(lines, "\n")
+}
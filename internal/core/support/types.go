@@ -0,0 +1,30 @@
+package support
+
+import "sfDBTools/utils/database"
+
+// Options selects what goes into a support bundle
+type Options struct {
+	ConfigDir      string           // directory of config.yaml
+	LogDir         string           // sfDBTools log directory
+	LogFiles       int              // newest log files to include
+	LogLines       int              // lines kept from the end of each log file
+	BackupDir      string           // backup catalog to take manifests from
+	CatalogEntries int              // newest manifests to include
+	DrillCatalog   string           // restore drill catalog, when configured
+	Server         *database.Config // server to read variables and the error log of; nil skips them
+	ErrorLogLines  int              // lines kept from the end of the MariaDB error log
+}
+
+// Item is one file of the bundle
+type Item struct {
+	Name   string // path inside the archive
+	Source string // where the content came from
+	Data   []byte
+}
+
+// Bundle is the collected content before packaging. Skipped lists what could
+// not be collected and why; it is written to the bundle as well.
+type Bundle struct {
+	Items   []Item
+	Skipped []string
+}
@@ -17,6 +17,7 @@ import (
 
 	"sfDBTools/internal/config"
 	"sfDBTools/internal/config/model"
+	"sfDBTools/utils/fs/policy"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -505,8 +506,12 @@ func buildLogger(cfg *model.Config) (*logrus.Logger, error) {
 
 // setupFileOutput configures file-based logging with rotation
 func setupFileOutput(cfg *model.Config) (io.Writer, error) {
+	// Log files follow the file policy. The process-wide policy is installed
+	// after the logger is built, so derive it from the config here.
+	filePolicy, _ := policy.FromConfig(cfg.FilePolicy)
+
 	// Create log directory if it doesn't exist
-	if err := os.MkdirAll(cfg.Log.Output.File.Dir, 0755); err != nil {
+	if err := filePolicy.MkdirAll(cfg.Log.Output.File.Dir); err != nil {
 		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
@@ -517,6 +522,16 @@ func setupFileOutput(cfg *model.Config) (io.Writer, error) {
 	filename := generateFilename(cfg.Log.Output.File.FilenamePattern)
 	fullPath := filepath.Join(cfg.Log.Output.File.Dir, filename)
 
+	// Pre-create the log file with the policy mode and owner; lumberjack keeps
+	// the mode and ownership of an existing file, including across rotations.
+	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		if f, err := filePolicy.CreateFile(fullPath); err == nil {
+			f.Close()
+		}
+	} else {
+		_ = filePolicy.Apply(fullPath, false)
+	}
+
 	// Setup lumberjack for file rotation
 	lumberjackLogger := &lumberjack.Logger{
 		Filename:   fullPath,
@@ -9,6 +9,8 @@ import (
 	"sfDBTools/cmd"
 	"sfDBTools/internal/config"
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/fs/policy"
 )
 
 func main() {
@@ -24,19 +26,26 @@ func main() {
 	// 	os.Exit(1)
 	// }
 
-	cfg, err := config.Get()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	// A missing or invalid config is reported by the command middleware, so
+	// --help keeps working without one
+	cfg, cfgErr := config.Get()
 	lg, err := logger.Get()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Logger initialization error: %v\n", err)
-		os.Exit(1)
+		os.Exit(common.ExitInternal)
 	}
-	lg.Info("Starting "+cfg.General.AppName, logger.String("version", cfg.General.Version))
 
-	if err := cmd.Execute(cfg, lg); err != nil {
-		os.Exit(1)
+	cmd.PrepareOutput(lg)
+	if cfgErr == nil {
+		lg.Info("Starting "+cfg.General.AppName, logger.String("version", cfg.General.Version))
+
+		// Apply the permission/ownership policy for created files and warn once
+		// when it cannot be honored by this process
+		for _, warning := range policy.Configure(cfg.FilePolicy) {
+			lg.Warn("File policy cannot be fully applied", logger.String("reason", warning))
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
 	}
+
+	os.Exit(common.ExitCode(cmd.Execute(cfg, lg)))
 }
@@ -3,10 +3,10 @@ package backup_utils
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"sfDBTools/internal/logger"
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/common/format"
+	"sfDBTools/utils/schema"
 )
 
 // DisplayBackupParameters logs backup parameters before execution (simplified)
@@ -36,7 +36,7 @@ func DisplayBackupResults(result *BackupResult, options BackupOptions, title str
 	// Read database metadata if available
 	var dbInfo map[string]interface{}
 	if result.BackupMetaFile != "" {
-		if data, err := os.ReadFile(result.BackupMetaFile); err == nil {
+		if data, err := schema.ReadManifest(result.BackupMetaFile); err == nil {
 			var metadata BackupMetadata
 			if json.Unmarshal(data, &metadata) == nil && metadata.DatabaseInfo != nil {
 				dbInfo = map[string]interface{}{
@@ -64,9 +64,10 @@ func DisplayBackupResults(result *BackupResult, options BackupOptions, title str
 		logger.String("compression_type", result.CompressionUsed),
 		logger.Bool("encrypted", result.Encrypted),
 		logger.Bool("include_data", result.IncludedData),
-		logger.String("duration", format.FormatDuration(result.Duration, "shorts")),
-		logger.String("average_speed", common.FormatSize(int64(result.AverageSpeed))+"/s"),
-		logger.String("checksum_sha256", result.Checksum),
+		logger.String("duration", common.HumanizeDuration(result.Duration)),
+		logger.String("average_speed", common.HumanizeRate(result.AverageSpeed)),
+		logger.String("checksum", result.Checksum),
+		logger.String("checksum_algorithm", checksumLabel(result.ChecksumAlgorithm)),
 	}
 
 	// Add database info if available
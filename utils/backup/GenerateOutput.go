@@ -3,13 +3,14 @@ package backup_utils
 import (
 	"fmt"
 	"path/filepath"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/compression"
-	"time"
+	"strings"
 )
 
 // GenerateOutputPaths generates the output file path and metadata file path
 func GenerateOutputPaths(options BackupOptions) (string, string) {
-	timestamp := time.Now().Format("2006_01_02")
+	timestamp := common.LocalNow().Format("2006_01_02")
 
 	// Create subdirectory for the database
 	dbDir := filepath.Join(options.OutputDir, timestamp, options.DBName)
@@ -30,6 +31,9 @@ func GenerateOutputPaths(options BackupOptions) (string, string) {
 	} else {
 		extension = ".sql"
 	}
+	if options.Engine == EngineMydumper {
+		extension = MydumperExtension + strings.TrimPrefix(extension, ".sql")
+	}
 
 	// Add .enc extension if encryption is enabled
 	if options.Encrypt {
@@ -1,14 +1,18 @@
 package backup_utils
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/database"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/output"
+	"sfDBTools/utils/schema"
 
 	"github.com/spf13/cobra"
 )
@@ -17,24 +21,31 @@ import (
 type AllDatabasesBackupOptions struct {
 	BackupOptions
 	ExcludeSystemDatabases bool
-	IncludeUser            bool // Include user grants for replication using SHOW GRANTS method
-	CaptureGTID            bool // Capture GTID information including BINLOG_GTID_POS
-	IncludeDatabaseName    bool // Include database name as comments in the output
+	IncludeUser            bool   // Include user grants for replication using SHOW GRANTS method
+	CaptureGTID            bool   // Capture GTID information including BINLOG_GTID_POS
+	IncludeDatabaseName    bool   // Include database name as comments in the output
+	GroupName              string // Consistency group being backed up; empty for an all databases backup
+	LockAllTables          bool   // Hold a global read lock instead of relying on --single-transaction
 }
 
+// AllDatabasesBackupFunc backs up the given databases into one file; ctx stops it
+type AllDatabasesBackupFunc func(ctx context.Context, options AllDatabasesBackupOptions, databases []string) (*AllDatabasesBackupResult, error)
+
 // AllDatabasesBackupResult represents the result of all databases backup
 type AllDatabasesBackupResult struct {
 	BackupResult
-	ProcessedDatabases []string
-	SkippedDatabases   []string
-	TotalDatabases     int
-	GTIDPosition       string // GTID position from BINLOG_GTID_POS
+	ProcessedDatabases []string        `json:"processed_databases"`
+	SkippedDatabases   []string        `json:"skipped_databases,omitempty"`
+	TotalDatabases     int             `json:"total_databases"`
+	GTIDPosition       string          `json:"gtid_position,omitempty"` // GTID position from BINLOG_GTID_POS
+	Bookmark           *BinlogBookmark `json:"bookmark,omitempty"`      // source position written into the dump by --master-data
+	SnapshotMode       string          `json:"snapshot_mode,omitempty"` // how the consistent snapshot was taken: single_transaction or lock_all_tables
 }
 
 // ExecuteAllDatabasesBackup executes backup for all databases into a single file
 func ExecuteAllDatabasesBackup(
 	cmd *cobra.Command,
-	backupFunc func(AllDatabasesBackupOptions, []string) (*AllDatabasesBackupResult, error),
+	backupFunc AllDatabasesBackupFunc,
 ) error {
 	lg, err := logger.Get()
 	if err != nil {
@@ -54,6 +65,9 @@ func ExecuteAllDatabasesBackup(
 	if err := TestDatabaseConnection(dbConfig); err != nil {
 		return err
 	}
+	if err := PreflightBackupPrivileges(dbConfig, nil); err != nil {
+		return err
+	}
 
 	// 3. Get databases ONCE based on system database inclusion preference
 	includeSystemDatabases, _ := cmd.Flags().GetBool("include-system-databases")
@@ -90,6 +104,8 @@ func ExecuteAllDatabasesBackup(
 			VerifyDisk:        backupConfig.VerifyDisk,
 			RetentionDays:     backupConfig.RetentionDays,
 			CalculateChecksum: backupConfig.CalculateChecksum,
+			ChecksumAlgorithm: backupConfig.ChecksumAlgorithm,
+			DumpArgs:          backupConfig.DumpArgs,
 		},
 		ExcludeSystemDatabases: !includeSystemDatabases,
 		IncludeUser:            includeUser,
@@ -101,13 +117,14 @@ func ExecuteAllDatabasesBackup(
 	options.DBName = "all_databases"
 
 	// 5. Execute backup with pre-loaded database list
-	result, err := backupFunc(options, availableDatabases)
+	result, err := backupFunc(cmd.Context(), options, availableDatabases)
 	if err != nil {
 		return fmt.Errorf("all databases backup failed: %w", err)
 	}
 
 	// 6. Display results
 	DisplayAllDatabasesBackupResults(result, options)
+	output.SetResult(result)
 
 	return nil
 }
@@ -145,19 +162,25 @@ func GetAllDatabasesList(dbConfig database.Config, excludeSystem bool) ([]string
 
 // GenerateAllDatabasesOutputPaths generates output paths for all databases backup
 func GenerateAllDatabasesOutputPaths(options AllDatabasesBackupOptions) (string, string) {
-	timestamp := time.Now().Format("2006_01_02")
-	timeDetail := time.Now().Format("20060102_150405")
+	timestamp := common.LocalNow().Format("2006_01_02")
+	timeDetail := common.LocalNow().Format("20060102_150405")
+
+	// Consistency groups are stored as group_<name> next to all_databases
+	label := "all_databases"
+	if options.GroupName != "" {
+		label = "group_" + options.GroupName
+	}
 
-	// Create output directory structure: outputDir/YYYY_MM_DD/all_databases/
-	outputDir := filepath.Join(options.OutputDir, timestamp, "all_databases")
+	// Create output directory structure: outputDir/YYYY_MM_DD/<label>/
+	outputDir := filepath.Join(options.OutputDir, timestamp, label)
 
-	// Generate filename: all_databases_YYYYMMDD_HHMMSS.sql[.compression][.enc]
-	filename := fmt.Sprintf("all_databases_%s.sql", timeDetail)
+	// Generate filename: <label>_YYYYMMDD_HHMMSS.sql[.compression][.enc]
+	filename := fmt.Sprintf("%s_%s.sql", label, timeDetail)
 
 	// Add compression extension
 	if options.Compress && options.Compression != "" {
 		switch strings.ToLower(options.Compression) {
-		case "gzip", "pgzip":
+		case "gzip", "pgzip", "pigz":
 			filename += ".gz"
 		case "zstd":
 			filename += ".zst"
@@ -170,7 +193,7 @@ func GenerateAllDatabasesOutputPaths(options AllDatabasesBackupOptions) (string,
 	}
 
 	outputFile := filepath.Join(outputDir, filename)
-	metaFile := filepath.Join(outputDir, fmt.Sprintf("all_databases_%s.meta.json", timeDetail))
+	metaFile := filepath.Join(outputDir, fmt.Sprintf("%s_%s.meta.json", label, timeDetail))
 
 	return outputFile, metaFile
 }
@@ -223,22 +246,25 @@ func CreateAllDatabasesMetadata(
 	mysqlVersion, _ := database.GetMySQLVersion(dbConfig)
 
 	metadata := &BackupMetadata{
-		DatabaseName:    "all_databases",
-		BackupDate:      time.Now(),
-		BackupType:      "all_databases",
-		OutputFile:      result.OutputFile,
-		FileSize:        result.OutputSize,
-		Compressed:      options.Compress,
-		CompressionType: result.CompressionUsed,
-		Encrypted:       result.Encrypted,
-		IncludesData:    result.IncludedData,
-		Duration:        result.Duration.String(),
-		Checksum:        result.Checksum,
-		Host:            options.Host,
-		Port:            options.Port,
-		User:            options.User,
-		MySQLVersion:    mysqlVersion,
-		ReplicationInfo: CreateReplicationMetadata(replicationInfo),
+		SchemaVersion:     schema.ManifestVersion,
+		DatabaseName:      "all_databases",
+		BackupDate:        time.Now().UTC(),
+		BackupType:        "all_databases",
+		OutputFile:        result.OutputFile,
+		FileSize:          result.OutputSize,
+		Compressed:        options.Compress,
+		CompressionType:   result.CompressionUsed,
+		Encrypted:         result.Encrypted,
+		IncludesData:      result.IncludedData,
+		Duration:          result.Duration.String(),
+		Checksum:          result.Checksum,
+		ChecksumAlgorithm: result.ChecksumAlgorithm,
+		Host:              options.Host,
+		Port:              options.Port,
+		User:              options.User,
+		MySQLVersion:      mysqlVersion,
+		ReplicationInfo:   CreateReplicationMetadata(replicationInfo),
+		DumpArgs:          options.DumpArgs,
 		DatabaseInfo: &DatabaseInfoMeta{
 			SizeBytes:    result.OutputSize,
 			TableCount:   result.TotalDatabases, // Use total databases count
@@ -249,6 +275,20 @@ func CreateAllDatabasesMetadata(
 		},
 	}
 
+	if result.Bookmark != nil {
+		if metadata.ReplicationInfo == nil {
+			metadata.ReplicationInfo = &ReplicationMeta{}
+		}
+		metadata.ReplicationInfo.Bookmark = result.Bookmark
+	}
+
+	if options.GroupName != "" {
+		metadata.DatabaseName = options.GroupName
+		metadata.BackupType = "consistency_group"
+		metadata.Databases = result.ProcessedDatabases
+		metadata.SnapshotMode = result.SnapshotMode
+	}
+
 	// Add custom metadata for all databases backup
 	if metadata.DatabaseInfo != nil {
 		// Store processed databases count in TableCount field for reference
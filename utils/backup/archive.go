@@ -0,0 +1,134 @@
+package backup_utils
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sfDBTools/internal/logger"
+)
+
+// DirSize is the total size of the regular files under root
+func DirSize(root string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(root, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// TarDir adds the files under root to tw below name. File contents are
+// written through content, which must end in tw (e.g. a progress writer
+// around it). Sockets, pipes and devices are skipped.
+func TarDir(tw *tar.Writer, content io.Writer, root, name string) error {
+	lg, _ := logger.Get()
+	return filepath.WalkDir(root, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		mode := d.Type()
+		if !mode.IsRegular() && !mode.IsDir() && mode&iofs.ModeSymlink == 0 {
+			lg.Debug("Skipping special file in archive", logger.String("path", path))
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if mode&iofs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(name, rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !mode.IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(content, f)
+		return err
+	})
+}
+
+// ExtractTar unpacks the regular files and directories of a tar stream into
+// dest. Entries that would land outside dest are refused.
+func ExtractTar(r io.Reader, dest string) error {
+	return ExtractTarMatching(r, dest, nil)
+}
+
+// ExtractTarMatching is ExtractTar for the regular files whose archive name
+// keep accepts; a nil keep extracts everything. The rest of the stream is
+// still read, so r can be fed from a pipe.
+func ExtractTarMatching(r io.Reader, dest string, keep func(name string) bool) error {
+	dest = filepath.Clean(dest)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(header.Name))
+		if target != dest && !strings.HasPrefix(target, dest+string(os.PathSeparator)) {
+			return fmt.Errorf("archive entry %s escapes the extract directory", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if keep != nil && !keep(header.Name) {
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package backup_utils
+
+import (
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/disk"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultTightHeadroom    = 1.5
+	defaultAbundantHeadroom = 5.0
+)
+
+// compressionStep is one codec/level choice with the typical size of its
+// output relative to the on-disk size of the dumped tables
+type compressionStep struct {
+	codec string
+	level string
+	ratio float64
+}
+
+// compressionLadder is ordered from the fastest to the smallest output. pgzip
+// and zstd use every core, so each step costs more CPU than the one before.
+var compressionLadder = []compressionStep{
+	{"pgzip", "fast", 0.32},
+	{"zstd", "fast", 0.25},
+	{"zstd", "default", 0.20},
+	{"zstd", "better", 0.17},
+	{"zstd", "best", 0.15},
+}
+
+// typicalRatio approximates the output ratio of a codec and level that is not
+// on the ladder, e.g. single-threaded gzip at best
+func typicalRatio(codec, level string) float64 {
+	for _, s := range compressionLadder {
+		if s.codec == codec && s.level == level {
+			return s.ratio
+		}
+	}
+	switch codec {
+	case "", "none":
+		return 1
+	case "zstd":
+		return 0.20
+	}
+	switch level {
+	case "best_speed", "fast":
+		return 0.32
+	case "better", "best":
+		return 0.24
+	}
+	return 0.27
+}
+
+// ApplyAutoCompression adjusts the codec and level of backupConfig to the free
+// space of the output directory when backup.compression.auto is enabled.
+// Tight space escalates to the fastest setting whose estimated output fits
+// with the configured headroom; abundant space switches to the fastest
+// setting so the dump is not held back by compression. Codec and level given
+// explicitly (flag, environment or --profile) are never changed.
+func ApplyAutoCompression(cmd *cobra.Command, backupConfig *BackupConfig) {
+	cfg, err := config.Get()
+	if err != nil || !cfg.Backup.Compression.Auto.Enabled || !backupConfig.Compress {
+		return
+	}
+	if explicitCompression(cmd) {
+		return
+	}
+	lg, _ := logger.Get()
+	tight, abundant := cfg.Backup.Compression.Auto.TightHeadroom, cfg.Backup.Compression.Auto.AbundantHeadroom
+	if tight <= 0 {
+		tight = defaultTightHeadroom
+	}
+	if abundant <= tight {
+		abundant = defaultAbundantHeadroom
+	}
+
+	source, err := sourceBytes(database.Config{
+		Host: backupConfig.Host, Port: backupConfig.Port,
+		User: backupConfig.User, Password: backupConfig.Password,
+	}, backupConfig.DBName)
+	if err != nil || source <= 0 {
+		lg.Warn("Automatic compression skipped: source size unknown", logger.Error(err))
+		return
+	}
+	free, err := disk.GetFreeBytes(existingParent(backupConfig.OutputDir))
+	if err != nil {
+		lg.Warn("Automatic compression skipped: free space unknown", logger.Error(err))
+		return
+	}
+
+	scale := historyScale(backupConfig.OutputDir, backupConfig.DBName)
+	estimate := func(codec, level string) int64 {
+		return int64(float64(source) * typicalRatio(codec, level) * scale)
+	}
+	current := estimate(backupConfig.Compression, backupConfig.CompressionLevel)
+
+	var chosen *compressionStep
+	reason := ""
+	switch {
+	case float64(free) < float64(current)*tight:
+		reason = "tight"
+		for i := range compressionLadder {
+			s := &compressionLadder[i]
+			if float64(estimate(s.codec, s.level))*tight <= float64(free) {
+				chosen = s
+				break
+			}
+		}
+		if chosen == nil {
+			chosen = &compressionLadder[len(compressionLadder)-1]
+		}
+		if estimate(chosen.codec, chosen.level) >= current {
+			chosen = nil
+		}
+	case float64(free) > float64(current)*abundant:
+		reason = "abundant"
+		fastest := &compressionLadder[0]
+		if fastest.ratio > typicalRatio(backupConfig.Compression, backupConfig.CompressionLevel) &&
+			float64(estimate(fastest.codec, fastest.level))*abundant <= float64(free) {
+			chosen = fastest
+		}
+	}
+
+	fields := []logger.Field{
+		logger.String("source_size", common.FormatSize(source)),
+		logger.String("estimate", common.FormatSize(current)),
+		logger.String("free", common.FormatSize(free)),
+		logger.String("configured", backupConfig.Compression+"/"+backupConfig.CompressionLevel),
+	}
+	if chosen == nil {
+		if reason == "tight" {
+			terminal.PrintWarning(fmt.Sprintf("Backup estimated at %s with %s free; no compression setting leaves %.1fx headroom",
+				common.FormatSize(current), common.FormatSize(free), tight))
+		}
+		lg.Info("Automatic compression kept configured setting", append(fields, logger.String("space", reason))...)
+		return
+	}
+
+	backupConfig.Compression, backupConfig.CompressionLevel = chosen.codec, chosen.level
+	lg.Info("Automatic compression changed setting", append(fields,
+		logger.String("space", reason),
+		logger.String("chosen", chosen.codec+"/"+chosen.level),
+		logger.String("new_estimate", common.FormatSize(estimate(chosen.codec, chosen.level))))...)
+	terminal.PrintInfo(fmt.Sprintf("Disk space is %s (%s free, backup estimated at %s): using %s compression at level %s",
+		reason, common.FormatSize(free), common.FormatSize(current), chosen.codec, chosen.level))
+}
+
+// explicitCompression reports whether the operator chose the codec or level
+func explicitCompression(cmd *cobra.Command) bool {
+	for flag, env := range map[string]string{"compression": "COMPRESSION", "compression-level": "COMPRESSION_LEVEL"} {
+		if f := cmd.Flags().Lookup(flag); (f != nil && f.Changed) || os.Getenv(env) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceBytes returns the data and index size of dbName, or of every user
+// database when dbName is empty. For backups of a subset of databases this
+// overestimates, which errs on the side of more compression.
+func sourceBytes(cfg database.Config, dbName string) (int64, error) {
+	db, err := database.GetWithoutDB(cfg)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	var size int64
+	if dbName != "" {
+		err = db.QueryRow("SELECT COALESCE(SUM(DATA_LENGTH + INDEX_LENGTH), 0) FROM information_schema.TABLES WHERE TABLE_SCHEMA = ?", dbName).Scan(&size)
+	} else {
+		err = db.QueryRow("SELECT COALESCE(SUM(DATA_LENGTH + INDEX_LENGTH), 0) FROM information_schema.TABLES WHERE TABLE_SCHEMA NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')").Scan(&size)
+	}
+	return size, err
+}
+
+// historyScale corrects the typical ratios with the newest manifest of the
+// same database: the measured output ratio divided by the typical one for its
+// codec. It is 1 when no earlier backup recorded both sizes.
+func historyScale(outputDir, dbName string) float64 {
+	var newest *BackupMetadata
+	_ = filepath.WalkDir(outputDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		meta, ok := ReadBackupManifest(path)
+		if !ok || meta.DatabaseName != dbName || !meta.Compressed || meta.FileSize <= 0 ||
+			meta.DatabaseInfo == nil || meta.DatabaseInfo.SizeBytes <= 0 {
+			return nil
+		}
+		if newest == nil || meta.BackupDate.After(newest.BackupDate) {
+			newest = meta
+		}
+		return nil
+	})
+	if newest == nil {
+		return 1
+	}
+	measured := float64(newest.FileSize) / float64(newest.DatabaseInfo.SizeBytes)
+	scale := measured / typicalRatio(newest.CompressionType, "default")
+	// Keep one odd backup from skewing the estimate beyond reason
+	return max(0.5, min(scale, 2))
+}
+
+// existingParent returns the nearest existing directory of path, since the
+// output directory of a first backup may not exist yet
+func existingParent(path string) string {
+	for p := filepath.Clean(path); ; p = filepath.Dir(p) {
+		if _, err := os.Stat(p); err == nil || p == filepath.Dir(p) {
+			return p
+		}
+	}
+}
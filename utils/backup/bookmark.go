@@ -0,0 +1,103 @@
+package backup_utils
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+)
+
+// BinlogBookmark is the position on the source a backup is consistent with,
+// as written by mysqldump --master-data into the dump itself. Replicas seeded
+// from the backup start replicating exactly there.
+type BinlogBookmark struct {
+	LogFile      string `json:"log_file"`
+	LogPosition  int64  `json:"log_position"`
+	GTIDPosition string `json:"gtid_position,omitempty"` // BINLOG_GTID_POS of the coordinates
+}
+
+// bookmarkScanLimit bounds how much of the dump is searched: mysqldump writes
+// the CHANGE MASTER line in the header, before any table data
+const bookmarkScanLimit = 1 << 20
+
+var changeMasterLine = regexp.MustCompile(`CHANGE MASTER TO MASTER_LOG_FILE='([^']+)', MASTER_LOG_POS=(\d+)`)
+
+// BookmarkScanner finds the binlog coordinates in the plain dump as it
+// streams through the backup pipeline
+type BookmarkScanner struct {
+	head     bytes.Buffer
+	bookmark *BinlogBookmark
+}
+
+// NewBookmarkScanner returns a scanner; add its Stage to the pipeline
+func NewBookmarkScanner() *BookmarkScanner {
+	return &BookmarkScanner{}
+}
+
+// Stage is a pass-through filter stage feeding the scanner
+func (s *BookmarkScanner) Stage() Stage {
+	return Stage{
+		Name: "bookmark",
+		Wrap: func(next io.Writer) (io.WriteCloser, error) {
+			return &bookmarkWriter{scanner: s, next: next}, nil
+		},
+	}
+}
+
+// Bookmark returns the coordinates found in the dump, or nil
+func (s *BookmarkScanner) Bookmark() *BinlogBookmark {
+	return s.bookmark
+}
+
+func (s *BookmarkScanner) scan(p []byte) {
+	if s.bookmark != nil || s.head.Len() >= bookmarkScanLimit {
+		return
+	}
+	s.head.Write(p[:min(len(p), bookmarkScanLimit-s.head.Len())])
+	if m := changeMasterLine.FindSubmatch(s.head.Bytes()); m != nil {
+		pos, _ := strconv.ParseInt(string(m[2]), 10, 64)
+		s.bookmark = &BinlogBookmark{LogFile: string(m[1]), LogPosition: pos}
+		s.head = bytes.Buffer{}
+	}
+}
+
+type bookmarkWriter struct {
+	scanner *BookmarkScanner
+	next    io.Writer
+}
+
+func (w *bookmarkWriter) Write(p []byte) (int, error) {
+	w.scanner.scan(p)
+	return w.next.Write(p)
+}
+
+func (w *bookmarkWriter) Close() error { return nil }
+
+// ResolveBookmarkGTID fills in the GTID position of the coordinates from the
+// source's binary logs; it stays empty on servers without GTIDs
+func ResolveBookmarkGTID(config database.Config, bookmark *BinlogBookmark) {
+	if bookmark == nil {
+		return
+	}
+	lg, _ := logger.Get()
+	config.DBName = ""
+	db, err := database.GetWithoutDB(config)
+	if err != nil {
+		lg.Warn("Failed to resolve GTID position of backup bookmark", logger.Error(err))
+		return
+	}
+	defer db.Close()
+	var gtid []byte
+	if err := db.QueryRow("SELECT BINLOG_GTID_POS(?, ?)", bookmark.LogFile, bookmark.LogPosition).Scan(&gtid); err != nil {
+		lg.Warn("Failed to resolve GTID position of backup bookmark", logger.Error(err))
+		return
+	}
+	bookmark.GTIDPosition = string(gtid)
+	lg.Info("Backup bookmarked at source position",
+		logger.String("log_file", bookmark.LogFile),
+		logger.Int64("log_position", bookmark.LogPosition),
+		logger.String("gtid_position", bookmark.GTIDPosition))
+}
@@ -0,0 +1,186 @@
+package backup_utils
+
+import (
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/config/model"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/backup/catalog"
+	"sfDBTools/utils/schema"
+)
+
+// CatalogPath returns the backup catalog file: backup.catalog.path, or
+// catalog.db in the backup directory
+func CatalogPath(cfg *model.Config) string {
+	if cfg.Backup.Catalog.Path != "" {
+		return cfg.Backup.Catalog.Path
+	}
+	if cfg.Backup.Storage.BaseDirectory == "" {
+		return ""
+	}
+	return filepath.Join(cfg.Backup.Storage.BaseDirectory, catalog.FileName)
+}
+
+// CatalogEntry describes the backup of the manifest at manifestPath. The
+// retention expiry is the backup date plus retentionDays; zero days means the
+// backup does not expire.
+func CatalogEntry(manifestPath string, meta *BackupMetadata, retentionDays int) *catalog.Entry {
+	if abs, err := filepath.Abs(manifestPath); err == nil {
+		manifestPath = abs
+	}
+	duration, _ := time.ParseDuration(meta.Duration)
+	entry := &catalog.Entry{
+		Database:          meta.DatabaseName,
+		Databases:         meta.Databases,
+		Type:              meta.BackupType,
+		BackupDate:        meta.BackupDate,
+		Host:              meta.Host,
+		Port:              meta.Port,
+		File:              ArtifactPath(manifestPath, meta),
+		Manifest:          manifestPath,
+		Size:              meta.FileSize,
+		Duration:          duration,
+		Checksum:          meta.Checksum,
+		ChecksumAlgorithm: meta.ChecksumAlgorithm,
+		Compressed:        meta.Compressed,
+		CompressionType:   meta.CompressionType,
+		Encrypted:         meta.Encrypted,
+		Pinned:            meta.Pinned,
+		PinReason:         meta.PinReason,
+	}
+	if meta.Remote != nil && meta.Remote.Status != RemoteStatusVanished {
+		entry.RemoteKey = meta.Remote.Key
+	}
+	if entry.Checksum != "" && entry.ChecksumAlgorithm == "" {
+		entry.ChecksumAlgorithm = "sha256"
+	}
+	if retentionDays > 0 {
+		entry.ExpiresAt = meta.BackupDate.AddDate(0, 0, retentionDays)
+	}
+	return entry
+}
+
+// catalogMu serializes the catalog writes of parallel backups; bbolt locks
+// the file per open handle, so a second handle in this process would wait
+var catalogMu sync.Mutex
+
+// RecordInCatalog adds the backup of the manifest at manifestPath to the
+// catalog. The manifest is already written, so a failure only costs the
+// catalog entry: it is logged as a warning and 'catalog index' recovers it.
+func RecordInCatalog(manifestPath string, meta *BackupMetadata, retentionDays int) {
+	lg, _ := logger.Get()
+	cfg, err := config.Get()
+	if err != nil {
+		lg.Warn("Backup not recorded in catalog", logger.String("manifest", manifestPath), logger.Error(err))
+		return
+	}
+	path := CatalogPath(cfg)
+	if path == "" {
+		lg.Debug("No backup catalog configured", logger.String("manifest", manifestPath))
+		return
+	}
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	cat, err := catalog.Open(path)
+	if err != nil {
+		lg.Warn("Backup not recorded in catalog", logger.String("manifest", manifestPath), logger.Error(err))
+		return
+	}
+	defer cat.Close()
+
+	entry := CatalogEntry(manifestPath, meta, retentionDays)
+	if err := cat.Record(entry); err != nil {
+		lg.Warn("Backup not recorded in catalog", logger.String("manifest", manifestPath), logger.Error(err))
+		return
+	}
+	lg.Info("Backup recorded in catalog", logger.String("catalog", path), logger.Int("id", int(entry.ID)))
+}
+
+// IndexResult reports what IndexCatalog did
+type IndexResult struct {
+	Recorded int
+	Pruned   []string // manifests that no longer exist
+}
+
+// IndexCatalog records every manifest under baseDir in cat, so backups taken
+// before the catalog existed, or while it could not be opened, are listed too.
+// With prune, entries whose manifest is gone are removed.
+func IndexCatalog(cat *catalog.Catalog, baseDir string, retentionDays int, prune bool) (*IndexResult, error) {
+	lg, _ := logger.Get()
+	result := &IndexResult{}
+	err := filepath.WalkDir(baseDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		meta, ok := ReadBackupManifest(path)
+		if !ok {
+			return nil
+		}
+		if err := cat.Record(CatalogEntry(path, meta, retentionDays)); err != nil {
+			return fmt.Errorf("failed to record %s: %w", path, err)
+		}
+		result.Recorded++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if prune {
+		entries, err := cat.List(catalog.Filter{})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if _, err := os.Stat(e.Manifest); !os.IsNotExist(err) {
+				continue
+			}
+			if err := cat.Remove(e.ID); err != nil {
+				return nil, err
+			}
+			result.Pruned = append(result.Pruned, e.Manifest)
+		}
+	}
+	lg.Info("Backup catalog indexed", logger.String("dir", baseDir),
+		logger.Int("recorded", result.Recorded), logger.Int("pruned", len(result.Pruned)))
+	return result, nil
+}
+
+// RecordRewrite updates the size and checksum of a backup whose file was
+// rewritten in place, e.g. by 'backup rekey', in its manifest and catalog
+// entry. The checksum is only recomputed when one was recorded.
+func RecordRewrite(cat *catalog.Catalog, e *catalog.Entry) error {
+	info, err := os.Stat(e.File)
+	if err != nil {
+		return err
+	}
+	checksum := e.Checksum
+	if checksum != "" {
+		if checksum, err = FileChecksum(e.File, e.ChecksumAlgorithm); err != nil {
+			return fmt.Errorf("failed to checksum %s: %w", e.File, err)
+		}
+	}
+	err = schema.PatchManifest(e.Manifest, func(doc map[string]any) {
+		doc["file_size"] = info.Size()
+		if checksum != "" {
+			doc["checksum"] = checksum
+		}
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to update manifest %s: %w", e.Manifest, err)
+	}
+	return cat.Update(e.ID, func(entry *catalog.Entry) {
+		entry.Size, entry.Checksum = info.Size(), checksum
+	})
+}
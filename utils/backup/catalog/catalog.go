@@ -0,0 +1,286 @@
+// Package catalog keeps an inventory of backups in an embedded bbolt
+// database. Every backup that writes a manifest is recorded with its
+// database, size, duration, checksum, encryption and retention expiry, so
+// backups can be listed and looked up without walking the backup directory.
+// The manifests stay the source of truth; the catalog can be rebuilt from them.
+package catalog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"sfDBTools/utils/fs"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// FileName is the catalog file created in the backup directory by default
+const FileName = "catalog.db"
+
+var (
+	entriesBucket   = []byte("entries")
+	manifestsBucket = []byte("manifests")
+)
+
+// ErrNotFound is returned when no entry matches a reference
+var ErrNotFound = errors.New("backup not found in catalog")
+
+// Entry is one backup
+type Entry struct {
+	ID                uint64        `json:"id"`
+	Database          string        `json:"database"`
+	Databases         []string      `json:"databases,omitempty"`
+	Type              string        `json:"type"`
+	BackupDate        time.Time     `json:"backup_date"`
+	Host              string        `json:"host"`
+	Port              int           `json:"port"`
+	File              string        `json:"file"`
+	Manifest          string        `json:"manifest"`
+	Size              int64         `json:"size"`
+	Duration          time.Duration `json:"duration"`
+	Checksum          string        `json:"checksum,omitempty"`
+	ChecksumAlgorithm string        `json:"checksum_algorithm,omitempty"`
+	Compressed        bool          `json:"compressed"`
+	CompressionType   string        `json:"compression_type,omitempty"`
+	Encrypted         bool          `json:"encrypted"`
+	// RemoteKey is the object key of the backup in remote storage, if any
+	RemoteKey string `json:"remote_key,omitempty"`
+	// Pinned backups are never pruned, whatever their expiry
+	Pinned    bool   `json:"pinned,omitempty"`
+	PinReason string `json:"pin_reason,omitempty"`
+	// ExpiresAt is when retention allows the backup to be removed; zero when
+	// backups are kept forever
+	ExpiresAt  time.Time `json:"expires_at,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+	// Verification is the outcome of the last 'backup verify'
+	Verification *Verification `json:"verification,omitempty"`
+}
+
+// Verification records whether a backup was found intact and, for a deep
+// verification, restorable
+type Verification struct {
+	At      time.Time `json:"at"`
+	Deep    bool      `json:"deep"`
+	Success bool      `json:"success"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+// Expired reports whether the retention of the backup has passed at now
+func (e *Entry) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+// Filter selects entries in List. Zero fields match everything.
+type Filter struct {
+	Database string
+	Type     string
+	Since    time.Time
+	Limit    int
+}
+
+func (f Filter) match(e *Entry) bool {
+	if f.Database != "" && e.Database != f.Database {
+		return false
+	}
+	if f.Type != "" && e.Type != f.Type {
+		return false
+	}
+	return f.Since.IsZero() || !e.BackupDate.Before(f.Since)
+}
+
+// Catalog is an open catalog file
+type Catalog struct {
+	db *bolt.DB
+}
+
+// Open opens the catalog at path, creating it when missing. bbolt allows a
+// single writer, so Open waits a few seconds for another process to close it.
+func Open(path string) (*Catalog, error) {
+	if err := fs.NewManager().File().EnsureArtifactDir(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("failed to create catalog directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup catalog %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{entriesBucket, manifestsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize backup catalog %s: %w", path, err)
+	}
+	return &Catalog{db: db}, nil
+}
+
+// Close releases the catalog file
+func (c *Catalog) Close() error {
+	return c.db.Close()
+}
+
+// Record stores e. An entry for the same manifest is replaced and keeps its
+// ID and last verification, so recording a backup again (e.g. when
+// rebuilding) does not duplicate it.
+func (c *Catalog) Record(e *Entry) error {
+	if e.Manifest == "" {
+		return fmt.Errorf("catalog entry for %s has no manifest", e.File)
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		entries, manifests := tx.Bucket(entriesBucket), tx.Bucket(manifestsBucket)
+		if id := manifests.Get([]byte(e.Manifest)); id != nil {
+			e.ID = binary.BigEndian.Uint64(id)
+			var old Entry
+			if e.Verification == nil && json.Unmarshal(entries.Get(id), &old) == nil {
+				e.Verification = old.Verification
+			}
+		} else {
+			seq, err := entries.NextSequence()
+			if err != nil {
+				return err
+			}
+			e.ID = seq
+		}
+		if e.RecordedAt.IsZero() {
+			e.RecordedAt = time.Now().UTC()
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		key := itob(e.ID)
+		if err := entries.Put(key, data); err != nil {
+			return err
+		}
+		return manifests.Put([]byte(e.Manifest), key)
+	})
+}
+
+// Update applies change to the entry with id
+func (c *Catalog) Update(id uint64, change func(e *Entry)) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		data := entries.Get(itob(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return err
+		}
+		change(&e)
+		e.ID = id
+		updated, err := json.Marshal(&e)
+		if err != nil {
+			return err
+		}
+		return entries.Put(itob(id), updated)
+	})
+}
+
+// SetPinned pins or unpins the entry with id
+func (c *Catalog) SetPinned(id uint64, pinned bool, reason string) error {
+	return c.Update(id, func(e *Entry) {
+		e.Pinned, e.PinReason = pinned, reason
+		if !pinned {
+			e.PinReason = ""
+		}
+	})
+}
+
+// Remove deletes the entry with id
+func (c *Catalog) Remove(id uint64) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		data := entries.Get(itob(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err == nil {
+			if err := tx.Bucket(manifestsBucket).Delete([]byte(e.Manifest)); err != nil {
+				return err
+			}
+		}
+		return entries.Delete(itob(id))
+	})
+}
+
+// Find returns the entry ref refers to: its ID, its manifest path or its
+// backup file (full path or file name)
+func (c *Catalog) Find(ref string) (*Entry, error) {
+	var found *Entry
+	err := c.db.View(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+			if data := entries.Get(itob(id)); data != nil {
+				found = &Entry{}
+				return json.Unmarshal(data, found)
+			}
+		}
+		if abs, err := filepath.Abs(ref); err == nil {
+			if id := tx.Bucket(manifestsBucket).Get([]byte(abs)); id != nil {
+				found = &Entry{}
+				return json.Unmarshal(entries.Get(id), found)
+			}
+		}
+		return entries.ForEach(func(_, data []byte) error {
+			var e Entry
+			if err := json.Unmarshal(data, &e); err != nil {
+				return nil
+			}
+			if e.File == ref || filepath.Base(e.File) == ref {
+				found = &e
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	}
+	return found, nil
+}
+
+// List returns the entries matching f, newest first
+func (c *Catalog) List(f Filter) ([]Entry, error) {
+	var list []Entry
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(entriesBucket).ForEach(func(_, data []byte) error {
+			var e Entry
+			if err := json.Unmarshal(data, &e); err != nil {
+				return fmt.Errorf("corrupt catalog entry: %w", err)
+			}
+			if f.match(&e) {
+				list = append(list, e)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].BackupDate.After(list[j].BackupDate) })
+	if f.Limit > 0 && len(list) > f.Limit {
+		list = list[:f.Limit]
+	}
+	return list, nil
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
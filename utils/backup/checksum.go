@@ -0,0 +1,57 @@
+package backup_utils
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/terminal"
+)
+
+// checksumProgressMin is the file size from which hashing shows a progress bar
+const checksumProgressMin = 256 << 20
+
+// checksumRedraw limits how often the checksum progress bar is redrawn
+const checksumRedraw = 200 * time.Millisecond
+
+// FileChecksum computes the checksum of a backup file, with a progress bar
+// on a terminal for large files
+func FileChecksum(file, algorithm string) (string, error) {
+	progress, finish := checksumProgress(file, "Computing checksum")
+	defer finish()
+	return fs.FileChecksumWithProgress(file, algorithm, progress)
+}
+
+// VerifyChecksum checks a backup file against its recorded checksum, with a
+// progress bar on a terminal for large files. The error wraps
+// fs.ErrChecksumMismatch when the file does not match.
+func VerifyChecksum(file, expected, algorithm string) error {
+	progress, finish := checksumProgress(file, "Verifying checksum")
+	defer finish()
+	return fs.VerifyFileChecksumWithProgress(file, expected, algorithm, progress)
+}
+
+// checksumProgress returns the progress callback drawing a transfer bar for
+// hashing file, or nil when the file is small or stdout is no terminal
+func checksumProgress(file, label string) (fs.ChecksumProgress, func()) {
+	info, err := os.Stat(file)
+	if err != nil || info.Size() < checksumProgressMin || !terminal.StdoutIsTerminal() {
+		return nil, func() {}
+	}
+	bar := terminal.NewTransferProgressBar(info.Size(), label)
+	bar.Update(0)
+	var hashed int64
+	var lastDraw time.Time
+	progress := func(done, total int64) {
+		hashed = done
+		if time.Since(lastDraw) >= checksumRedraw {
+			bar.Update(int(done))
+			lastDraw = time.Now()
+		}
+	}
+	return progress, func() {
+		bar.Update(int(hashed))
+		fmt.Println()
+	}
+}
@@ -1,13 +1,18 @@
 package backup_utils
 
 import (
+	iofs "io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"sfDBTools/internal/logger"
 )
 
 // cleanupOldBackups removes dated subdirectories older than retentionDays from outputDir.
 // It returns a slice of removed directory names. Directories must be named in YYYY_MM_DD format to be considered.
+// Directories holding a pinned backup are kept.
 func CleanupOldBackups(outputDir string, retentionDays int) ([]string, error) {
 	if retentionDays <= 0 {
 		return nil, nil
@@ -34,6 +39,9 @@ func CleanupOldBackups(outputDir string, retentionDays int) ([]string, error) {
 		}
 
 		if date.Before(threshold) {
+			if hasPinnedBackup(filepath.Join(outputDir, entry.Name())) {
+				continue
+			}
 			os.RemoveAll(filepath.Join(outputDir, entry.Name()))
 			removed = append(removed, entry.Name())
 		}
@@ -41,3 +49,31 @@ func CleanupOldBackups(outputDir string, retentionDays int) ([]string, error) {
 
 	return removed, nil
 }
+
+// hasPinnedBackup reports whether a manifest below dir is pinned
+func hasPinnedBackup(dir string) bool {
+	pinned := false
+	filepath.WalkDir(dir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		if meta, ok := ReadBackupManifest(path); ok && meta.Pinned {
+			pinned = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return pinned
+}
+
+// RemovePartialOutput deletes the backup file of a failed or interrupted run,
+// so an incomplete dump is never mistaken for a backup
+func RemovePartialOutput(outputFile string) {
+	if outputFile == "" {
+		return
+	}
+	if err := os.Remove(outputFile); err == nil {
+		lg, _ := logger.Get()
+		lg.Info("Removed incomplete backup file", logger.String("file", outputFile))
+	}
+}
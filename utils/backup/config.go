@@ -2,29 +2,42 @@ package backup_utils
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"sfDBTools/internal/config"
 	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/fs"
 
 	"github.com/spf13/cobra"
 )
 
 // BackupConfig represents the resolved backup configuration
 type BackupConfig struct {
-	Host              string
-	Port              int
-	User              string
-	Password          string
-	DBName            string
-	OutputDir         string
-	Compress          bool
-	Compression       string
-	CompressionLevel  string
-	IncludeData       bool
-	Encrypt           bool
-	VerifyDisk        bool
-	RetentionDays     int
-	CalculateChecksum bool
+	Host               string
+	Port               int
+	User               string
+	Password           string
+	DBName             string
+	OutputDir          string
+	Compress           bool
+	Compression        string
+	CompressionLevel   string
+	IncludeData        bool
+	Encrypt            bool
+	VerifyDisk         bool
+	RetentionDays      int
+	CalculateChecksum  bool
+	ChecksumAlgorithm  string
+	RecentPartitions   map[string]int
+	DumpArgs           []string
+	Parallel           int    // databases backed up at the same time by multi-database backups
+	EncryptionPassword string // resolved once up front when databases are backed up in parallel
+	EncryptionKeyFile  string // key file to encrypt with instead of a password
+	Output             string // s3://bucket/prefix to stream backups to object storage
+	Engine             string // EngineMysqldump or EngineMydumper
+	EngineThreads      int    // mydumper threads; 0 for the configured default
 }
 
 // ResolveBackupConfig resolves backup configuration from various sources with proper priority
@@ -36,8 +49,13 @@ func ResolveBackupConfig(cmd *cobra.Command) (*BackupConfig, error) {
 
 	backupConfig := &BackupConfig{}
 
+	// Preset the flags of --profile before they are read
+	if err := ApplyBackupProfile(cmd); err != nil {
+		return nil, err
+	}
+
 	// Resolve database connection
-	host, port, user, password, source, err := ResolveDatabaseConnection(cmd)
+	host, port, user, password, source, err := ResolveBackupConnection(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve database connection: %w", err)
 	}
@@ -47,6 +65,11 @@ func ResolveBackupConfig(cmd *cobra.Command) (*BackupConfig, error) {
 	backupConfig.User = user
 	backupConfig.Password = password
 
+	// Guard the source before anything else connects to it
+	if err := ApplySourceProtect(cmd, database.Config{Host: host, Port: port, User: user, Password: password}); err != nil {
+		return nil, err
+	}
+
 	// Display configuration source
 	switch source {
 	case SourceConfigFile:
@@ -75,35 +98,125 @@ func ResolveBackupConfig(cmd *cobra.Command) (*BackupConfig, error) {
 	backupConfig.Compress = common.GetBoolFlagOrEnv(cmd, "compress", "COMPRESS", defaultCompress)
 	backupConfig.IncludeData = common.GetBoolFlagOrEnv(cmd, "data", "INCLUDE_DATA", defaultIncludeData)
 	backupConfig.Encrypt = common.GetBoolFlagOrEnv(cmd, "encrypt", "ENCRYPT", defaultEncrypt)
+	backupConfig.EncryptionKeyFile = common.GetStringFlagOrEnv(cmd, "encryption-key-file", "SFDB_ENCRYPTION_KEY_FILE", "")
+	if cmd.Flags().Changed("encryption-key-file") {
+		backupConfig.Encrypt = true
+	}
 	backupConfig.Compression = common.GetStringFlagOrEnv(cmd, "compression", "COMPRESSION", defaultCompression)
 	backupConfig.CompressionLevel = common.GetStringFlagOrEnv(cmd, "compression-level", "COMPRESSION_LEVEL", defaultCompressionLevel)
 	backupConfig.VerifyDisk = common.GetBoolFlagOrEnv(cmd, "verify-disk", "VERIFY_DISK", defaultVerifyDisk)
 	backupConfig.RetentionDays = common.GetIntFlagOrEnv(cmd, "retention-days", "RETENTION_DAYS", defaultRetentionDays)
 	backupConfig.CalculateChecksum = common.GetBoolFlagOrEnv(cmd, "calculate-checksum", "CALCULATE_CHECKSUM", defaultCalculateChecksum)
+	backupConfig.ChecksumAlgorithm = common.GetStringFlagOrEnv(cmd, "checksum-algorithm", "CHECKSUM_ALGORITHM", fs.ChecksumSHA256)
+	if err := fs.ValidateChecksumAlgorithm(backupConfig.ChecksumAlgorithm); err != nil {
+		return nil, common.WithExitCode(err, common.ExitUsage)
+	}
 
 	if backupConfig.Compression == "" && backupConfig.Compress {
 		backupConfig.Compression = "gzip"
 	}
+	ApplyAutoCompression(cmd, backupConfig)
+	if backupConfig.RecentPartitions, err = resolveRecentPartitions(cmd); err != nil {
+		return nil, err
+	}
+	if backupConfig.DumpArgs, err = resolveDumpArgs(cmd); err != nil {
+		return nil, err
+	}
+	if err := resolveEngine(cmd, backupConfig); err != nil {
+		return nil, err
+	}
 
 	return backupConfig, nil
 }
 
+// resolveDumpArgs reads the validated --dump-arg values on commands that define it
+func resolveDumpArgs(cmd *cobra.Command) ([]string, error) {
+	if cmd.Flags().Lookup("dump-arg") == nil {
+		return nil, nil
+	}
+	args := common.GetStringArrayFlagOrEnv(cmd, "dump-arg", "BACKUP_DUMP_ARGS")
+	if err := common.ValidateDumpArgs(args); err != nil {
+		return nil, common.WithExitCode(err, common.ExitUsage)
+	}
+	return args, nil
+}
+
+// resolveParallel reads --parallel on commands that define it; 1 elsewhere
+func resolveParallel(cmd *cobra.Command) (int, error) {
+	if cmd.Flags().Lookup("parallel") == nil {
+		return 1, nil
+	}
+	n := common.GetIntFlagOrEnv(cmd, "parallel", "BACKUP_PARALLEL", 1)
+	if n < 1 {
+		return 0, common.WithExitCode(fmt.Errorf("invalid --parallel %d (expected 1 or more)", n), common.ExitUsage)
+	}
+	return n, nil
+}
+
+// resolveOutput reads --output on commands that define it and checks that
+// the object storage target can be used before any database is dumped
+func resolveOutput(cmd *cobra.Command) (string, error) {
+	if cmd.Flags().Lookup("output") == nil {
+		return "", nil
+	}
+	output := common.GetStringFlagOrEnv(cmd, "output", "BACKUP_OUTPUT", "")
+	if output == "" {
+		return "", nil
+	}
+	if _, _, err := ParseS3URL(output); err != nil {
+		return "", common.WithExitCode(err, common.ExitUsage)
+	}
+	if _, err := NewOutputStore(output); err != nil {
+		return "", err
+	}
+	return output, nil
+}
+
+// resolveRecentPartitions parses --recent-partitions TABLE=N values on commands that define it
+func resolveRecentPartitions(cmd *cobra.Command) (map[string]int, error) {
+	if cmd.Flags().Lookup("recent-partitions") == nil {
+		return nil, nil
+	}
+	values, _ := cmd.Flags().GetStringSlice("recent-partitions")
+	if len(values) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]int, len(values))
+	for _, v := range values {
+		table, count, ok := strings.Cut(v, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if !ok || strings.TrimSpace(table) == "" || err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid --recent-partitions value %q (expected TABLE=N with N >= 1)", v)
+		}
+		result[strings.TrimSpace(table)] = n
+	}
+	return result, nil
+}
+
 // ConvertToBackupOptions converts BackupConfig to BackupOptions for backward compatibility
 func (bc *BackupConfig) ToBackupOptions() BackupOptions {
 	return BackupOptions{
-		Host:              bc.Host,
-		Port:              bc.Port,
-		User:              bc.User,
-		Password:          bc.Password,
-		DBName:            bc.DBName,
-		OutputDir:         bc.OutputDir,
-		Compress:          bc.Compress,
-		Compression:       bc.Compression,
-		CompressionLevel:  bc.CompressionLevel,
-		IncludeData:       bc.IncludeData,
-		Encrypt:           bc.Encrypt,
-		VerifyDisk:        bc.VerifyDisk,
-		RetentionDays:     bc.RetentionDays,
-		CalculateChecksum: bc.CalculateChecksum,
+		Host:               bc.Host,
+		Port:               bc.Port,
+		User:               bc.User,
+		Password:           bc.Password,
+		DBName:             bc.DBName,
+		OutputDir:          bc.OutputDir,
+		Compress:           bc.Compress,
+		Compression:        bc.Compression,
+		CompressionLevel:   bc.CompressionLevel,
+		IncludeData:        bc.IncludeData,
+		Encrypt:            bc.Encrypt,
+		VerifyDisk:         bc.VerifyDisk,
+		RetentionDays:      bc.RetentionDays,
+		CalculateChecksum:  bc.CalculateChecksum,
+		ChecksumAlgorithm:  bc.ChecksumAlgorithm,
+		RecentPartitions:   bc.RecentPartitions,
+		DumpArgs:           bc.DumpArgs,
+		EncryptionPassword: bc.EncryptionPassword,
+		EncryptionKeyFile:  bc.EncryptionKeyFile,
+		Output:             bc.Output,
+		Engine:             bc.Engine,
+		EngineThreads:      bc.EngineThreads,
 	}
 }
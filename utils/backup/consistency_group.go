@@ -0,0 +1,186 @@
+package backup_utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+
+	"github.com/spf13/cobra"
+)
+
+// groupName restricts group names to characters that are safe in file names
+var groupName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ResolveConsistencyGroup returns the group name and member databases from
+// --group (looked up in backup.consistency_groups, or named ad hoc when
+// --databases is also given) and --databases
+func ResolveConsistencyGroup(cmd *cobra.Command) (string, []string, error) {
+	name := common.GetStringFlagOrEnv(cmd, "group", "BACKUP_GROUP", "")
+	var members []string
+	for _, db := range strings.Split(common.GetStringFlagOrEnv(cmd, "databases", "BACKUP_GROUP_DATABASES", ""), ",") {
+		if db = strings.TrimSpace(db); db != "" {
+			members = append(members, db)
+		}
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("--group is required")
+	}
+	if !groupName.MatchString(name) {
+		return "", nil, fmt.Errorf("invalid group name %q: use letters, digits, '-' and '_'", name)
+	}
+
+	if len(members) == 0 {
+		cfg, err := config.Get()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+		for _, g := range cfg.Backup.ConsistencyGroups {
+			if g.Name == name {
+				members = g.Databases
+				break
+			}
+		}
+		if len(members) == 0 {
+			return "", nil, fmt.Errorf("consistency group %q is not defined in backup.consistency_groups; pass its members with --databases", name)
+		}
+	}
+
+	seen := make(map[string]bool, len(members))
+	for _, db := range members {
+		if seen[db] {
+			return "", nil, fmt.Errorf("database %s is listed twice in group %s", db, name)
+		}
+		seen[db] = true
+	}
+	if len(members) < 2 {
+		return "", nil, fmt.Errorf("consistency group %s needs at least two databases", name)
+	}
+	return name, members, nil
+}
+
+// NonTransactionalTables lists tables of databases whose storage engine is not
+// covered by a --single-transaction snapshot, as db.table
+func NonTransactionalTables(dbConfig database.Config, databases []string) ([]string, error) {
+	db, err := database.GetWithoutDB(dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(databases)), ",")
+	args := make([]any, len(databases))
+	for i, name := range databases {
+		args[i] = name
+	}
+	rows, err := db.Query(`SELECT TABLE_SCHEMA, TABLE_NAME FROM information_schema.TABLES
+		WHERE TABLE_TYPE = 'BASE TABLE' AND ENGINE NOT IN ('InnoDB', 'ROCKSDB', 'TokuDB')
+		AND TABLE_SCHEMA IN (`+placeholders+`) ORDER BY TABLE_SCHEMA, TABLE_NAME`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var schema, table string
+		if err := rows.Scan(&schema, &table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, schema+"."+table)
+	}
+	return tables, rows.Err()
+}
+
+// ExecuteConsistencyGroupBackup backs up the members of a consistency group
+// with one mysqldump run, so the set restores to a single point in time
+func ExecuteConsistencyGroupBackup(
+	cmd *cobra.Command,
+	backupFunc AllDatabasesBackupFunc,
+) error {
+	lg, err := logger.Get()
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	group, members, err := ResolveConsistencyGroup(cmd)
+	if err != nil {
+		return err
+	}
+	lg.Info("Starting consistency group backup", logger.String("group", group), logger.Strings("databases", members))
+
+	backupConfig, err := ResolveBackupConfigWithoutDB(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to resolve backup configuration: %w", err)
+	}
+
+	dbConfig := CreateDatabaseConfig(backupConfig)
+	if err := TestDatabaseConnection(dbConfig); err != nil {
+		return err
+	}
+
+	available, err := GetAllDatabasesList(dbConfig, false)
+	if err != nil {
+		return fmt.Errorf("failed to get available databases: %w", err)
+	}
+	exists := make(map[string]bool, len(available))
+	for _, db := range available {
+		exists[db] = true
+	}
+	var missing []string
+	for _, db := range members {
+		if !exists[db] {
+			missing = append(missing, db)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("group %s references databases that do not exist: %s", group, strings.Join(missing, ", "))
+	}
+
+	if err := PreflightBackupPrivileges(dbConfig, members); err != nil {
+		return err
+	}
+
+	captureGTID, _ := cmd.Flags().GetBool("capture-gtid")
+	options := AllDatabasesBackupOptions{
+		BackupOptions: BackupOptions{
+			Host:              backupConfig.Host,
+			Port:              backupConfig.Port,
+			User:              backupConfig.User,
+			Password:          backupConfig.Password,
+			DBName:            group,
+			OutputDir:         backupConfig.OutputDir,
+			Compress:          backupConfig.Compress,
+			Compression:       backupConfig.Compression,
+			CompressionLevel:  backupConfig.CompressionLevel,
+			IncludeData:       backupConfig.IncludeData,
+			Encrypt:           backupConfig.Encrypt,
+			VerifyDisk:        backupConfig.VerifyDisk,
+			RetentionDays:     backupConfig.RetentionDays,
+			CalculateChecksum: backupConfig.CalculateChecksum,
+			ChecksumAlgorithm: backupConfig.ChecksumAlgorithm,
+			DumpArgs:          backupConfig.DumpArgs,
+		},
+		ExcludeSystemDatabases: true, // dump exactly the members with --databases
+		CaptureGTID:            captureGTID,
+		IncludeDatabaseName:    true,
+		GroupName:              group,
+	}
+
+	result, err := backupFunc(cmd.Context(), options, members)
+	if err != nil {
+		return fmt.Errorf("consistency group backup failed: %w", err)
+	}
+
+	DisplayAllDatabasesBackupResults(result, options)
+	lg.Info("Consistency group backup completed",
+		logger.String("group", group),
+		logger.Strings("databases", result.ProcessedDatabases),
+		logger.String("snapshot_mode", result.SnapshotMode),
+		logger.String("output_file", result.OutputFile))
+	return nil
+}
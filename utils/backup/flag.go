@@ -20,15 +20,18 @@ func AddCommonBackupFlags(cmd *cobra.Command) {
 	cmd.Flags().String("source_host", "", "source database host")
 	cmd.Flags().Int("source_port", 0, "source database port")
 	cmd.Flags().String("source_user", "", "source database user")
-	cmd.Flags().String("source_password", "", "source database password")
+	cmd.Flags().String("source_password", "", "source database password, or a secret reference (vault://, awssm://, envfile://)")
 
 	// Backup options
 	cmd.Flags().Bool("compress", defaultCompress, "compress output")
-	cmd.Flags().String("compression", defaultCompression, "compression format (gzip, pgzip, zlib, zstd)")
-	cmd.Flags().String("compression-level", defaultCompressionLevel, "compression level (best_speed, fast, default, better, best)")
+	cmd.Flags().String("compression", defaultCompression, "compression format (gzip, pgzip, pigz, zlib, zstd)")
+	cmd.Flags().String("compression-level", defaultCompressionLevel, "compression level: best_speed, fast, default, better, best, or a number (1-9, 1-22 for zstd)")
 	cmd.Flags().String("output-dir", defaultOutputDir, "output directory")
 	cmd.Flags().Bool("data", defaultIncludeData, "include data in backup")
 	cmd.Flags().Bool("encrypt", defaultEncrypt, "encrypt output (will prompt for encryption password)")
+	cmd.Flags().String("encryption-key-file", "", "encrypt with a key file (at least 32 bytes) instead of a password")
+	cmd.Flags().String("checksum-algorithm", "sha256", "checksum algorithm with --calculate-checksum: sha256, sha512, md5, or xxh64 (faster for large files)")
+	cmd.Flags().String("profile", "", "preset for compression, parallelism and verification: fast, balanced or safe (explicit flags win)")
 }
 
 // ParseBackupOptionsFromFlags parses backup options from command flags.
@@ -42,3 +45,19 @@ func ParseBackupOptionsFromFlags(cmd *cobra.Command) (BackupOptions, error) {
 
 	return backupConfig.ToBackupOptions(), nil
 }
+
+// AddParallelFlag adds --parallel to commands that back up several databases
+func AddParallelFlag(cmd *cobra.Command) {
+	cmd.Flags().Int("parallel", 0, "number of databases to back up at the same time, env BACKUP_PARALLEL (default 1)")
+}
+
+// AddRemoteOutputFlag adds --output to commands that can stream a dump
+// straight to object storage
+func AddRemoteOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().String("output", "", "stream backups to object storage instead of --output-dir, as s3://bucket/prefix (env BACKUP_OUTPUT)")
+}
+
+// AddDumpArgFlag adds the repeatable --dump-arg flag passing extra options to mysqldump
+func AddDumpArgFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArray("dump-arg", nil, "extra mysqldump option from the allowlist, e.g. --dump-arg=--hex-blob (repeatable)")
+}
@@ -3,20 +3,21 @@ package backup_utils
 import (
 	"database/sql"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/compression"
 	"sfDBTools/utils/database"
+	"sfDBTools/utils/fs"
 )
 
 // GenerateGrantOutputPaths generates output paths for grant backup files
 func GenerateGrantOutputPaths(options BackupOptions) (string, string) {
-	timestamp := time.Now().Format("2006_01_02_150405")
-	dateDir := time.Now().Format("2006_01_02")
+	timestamp := common.LocalNow().Format("2006_01_02_150405")
+	dateDir := common.LocalNow().Format("2006_01_02")
 
 	var targetName string
 	if options.SystemUsers {
@@ -76,7 +77,7 @@ func BackupDatabaseGrants(db *sql.DB, options BackupOptions) (*BackupResult, err
 	outputFile, metaFile := GenerateGrantOutputPaths(options)
 
 	// Create output directory
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+	if err := fs.NewManager().File().EnsureArtifactDir(filepath.Dir(outputFile)); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -198,7 +199,7 @@ func BackupSystemUserGrants(db *sql.DB, options BackupOptions) (*BackupResult, e
 	outputFile, metaFile := GenerateGrantOutputPaths(options)
 
 	// Create output directory
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+	if err := fs.NewManager().File().EnsureArtifactDir(filepath.Dir(outputFile)); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
@@ -278,17 +279,18 @@ func writeGrantsToFile(outputFile, content string, options BackupOptions) (*Back
 	lg, _ := logger.Get()
 
 	// Create file
-	file, err := os.Create(outputFile)
+	file, err := fs.NewManager().File().CreateFile(outputFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create output file: %w", err)
 	}
 	defer file.Close()
 
-	// Use the same BuildWriterChain as other backup operations
-	writer, closers, err := BuildWriterChain(file, options, lg)
+	// Use the same write path as other backup operations
+	writer, err := OpenBackupStream(file, options, lg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build writer chain: %w", err)
 	}
+	defer writer.Close()
 
 	// Write content
 	bytesWritten, err := writer.Write([]byte(content))
@@ -296,11 +298,8 @@ func writeGrantsToFile(outputFile, content string, options BackupOptions) (*Back
 		return nil, fmt.Errorf("failed to write content: %w", err)
 	}
 
-	// Close writers in reverse order (inner to outer)
-	for i := len(closers) - 1; i >= 0; i-- {
-		if err := closers[i].Close(); err != nil {
-			lg.Warn("Failed to close writer", logger.Error(err))
-		}
+	if err := writer.Close(); err != nil {
+		lg.Warn("Failed to close writer", logger.Error(err))
 	}
 
 	// Get file size
@@ -9,6 +9,7 @@ import (
 	"sfDBTools/internal/logger"
 	"sfDBTools/utils/database"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/terminal"
 
 	"github.com/spf13/cobra"
 )
@@ -22,10 +23,11 @@ type DatabaseListResult struct {
 
 // MultiBackupResult represents the result of backing up multiple databases
 type MultiBackupResult struct {
-	TotalProcessed   int
-	SuccessCount     int
-	FailedDatabases  []string
-	InvalidDatabases []string
+	TotalProcessed   int                      `json:"total_processed"`
+	SuccessCount     int                      `json:"success_count"`
+	FailedDatabases  []string                 `json:"failed_databases"`
+	InvalidDatabases []string                 `json:"invalid_databases,omitempty"`
+	Results          map[string]*BackupResult `json:"results"` // successful backups by database
 }
 
 // ResolveDBListFile resolves the database list file, either from flag or interactive selection
@@ -81,25 +83,13 @@ func ResolveDBListFile(cmd *cobra.Command) (string, error) {
 		return "", fmt.Errorf("no .txt files found in %s directory", dbListDir)
 	}
 
-	// Display available files
-	fmt.Printf("📁 Available Database List Files:\n")
-	fmt.Printf("==================================\n")
-	for i, file := range txtFiles {
-		fmt.Printf("   %d. %s\n", i+1, file)
-	}
-
 	// Get user selection
-	var choice int
-	for {
-		fmt.Printf("\nSelect database list file (1-%d): ", len(txtFiles))
-		if _, err := fmt.Scanf("%d", &choice); err != nil || choice < 1 || choice > len(txtFiles) {
-			fmt.Printf("Invalid selection. Please enter a number between 1 and %d.\n", len(txtFiles))
-			continue
-		}
-		break
+	choice, err := terminal.Select("📁 Available Database List Files:", txtFiles, -1)
+	if err != nil {
+		return "", fmt.Errorf("failed to read selection: %w", err)
 	}
 
-	selectedFile := filepath.Join(dbListDir, txtFiles[choice-1])
+	selectedFile := filepath.Join(dbListDir, txtFiles[choice])
 	lg, _ := logger.Get()
 	lg.Info("Selected db_list file", logger.String("file", selectedFile))
 
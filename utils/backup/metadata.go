@@ -3,11 +3,12 @@ package backup_utils
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"sfDBTools/internal/logger"
 	"sfDBTools/utils/database"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/schema"
 	"time"
 )
 
@@ -30,21 +31,27 @@ func CreateMetadataFile(
 	// }
 
 	metadata := BackupMetadata{
-		DatabaseName:    options.DBName,
-		BackupDate:      time.Now(),
-		BackupType:      "single",
-		OutputFile:      filepath.Base(result.OutputFile),
-		FileSize:        result.OutputSize,
-		Compressed:      options.Compress,
-		CompressionType: options.Compression,
-		Encrypted:       options.Encrypt,
-		IncludesData:    options.IncludeData,
-		Duration:        result.Duration.String(),
-		Checksum:        result.Checksum,
-		Host:            options.Host,
-		Port:            options.Port,
-		User:            options.User,
-		MySQLVersion:    mysqlVersion,
+		SchemaVersion:     schema.ManifestVersion,
+		DatabaseName:      options.DBName,
+		BackupDate:        time.Now().UTC(),
+		BackupType:        "single",
+		OutputFile:        filepath.Base(result.OutputFile),
+		FileSize:          result.OutputSize,
+		Compressed:        options.Compress,
+		CompressionType:   options.Compression,
+		Encrypted:         options.Encrypt,
+		IncludesData:      options.IncludeData,
+		Duration:          result.Duration.String(),
+		Checksum:          result.Checksum,
+		ChecksumAlgorithm: result.ChecksumAlgorithm,
+		PartialTables:     result.PartialTables,
+		DumpArgs:          options.DumpArgs,
+		Engine:            options.Engine,
+		Remote:            result.Remote,
+		Host:              options.Host,
+		Port:              options.Port,
+		User:              options.User,
+		MySQLVersion:      mysqlVersion,
 	}
 
 	// Helper to convert *info.DatabaseInfo to *utils.DatabaseInfoMeta
@@ -71,10 +78,12 @@ func CreateMetadataFile(
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
-	if err := os.WriteFile(result.BackupMetaFile, metaBytes, 0644); err != nil {
+	if err := fs.NewManager().File().WriteFile(result.BackupMetaFile, metaBytes); err != nil {
 		return fmt.Errorf("failed to write metadata file: %w", err)
 	}
 
 	lg.Info("Metadata file created", logger.String("file", result.BackupMetaFile))
+	RecordInCatalog(result.BackupMetaFile, &metadata, options.RetentionDays)
+	PruneAfterBackup()
 	return nil
 }
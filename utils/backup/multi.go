@@ -1,9 +1,11 @@
 package backup_utils
 
 import (
+	"context"
 	"fmt"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/output"
 	"sfDBTools/utils/terminal"
 
 	"github.com/spf13/cobra"
@@ -16,9 +18,10 @@ func ExecuteGrantBackup() {
 
 // ExecuteMultipleDatabaseBackup executes backup for multiple databases
 func ExecuteMultipleDatabaseBackup(
+	ctx context.Context,
 	backupConfig *BackupConfig,
 	databases []string,
-	backupFunc func(BackupOptions) (*BackupResult, error),
+	backupFunc BackupFunc,
 	operationType string,
 ) (*MultiBackupResult, error) {
 	lg, _ := logger.Get()
@@ -28,20 +31,33 @@ func ExecuteMultipleDatabaseBackup(
 		logger.Int("total_databases", len(databases)),
 		logger.Strings("databases", databases))
 
+	if backupConfig.Parallel > 1 && len(databases) > 1 {
+		result, err := executeParallelBackup(ctx, backupConfig, databases, backupFunc, operationType)
+		output.SetResult(result)
+		return result, err
+	}
+
 	result := &MultiBackupResult{
 		TotalProcessed:  len(databases),
 		SuccessCount:    0,
 		FailedDatabases: []string{},
+		Results:         map[string]*BackupResult{},
 	}
+	output.SetResult(result)
 
 	for i, dbName := range databases {
+		if ctx.Err() != nil {
+			// The remaining databases are not started after a stop signal
+			result.FailedDatabases = append(result.FailedDatabases, databases[i:]...)
+			break
+		}
 		terminal.PrintSubHeader(fmt.Sprintf("Processing Database (%d/%d): %s", i+1, len(databases), dbName))
 		lg.Debug("Processing database",
 			logger.String("database", dbName),
 			logger.Int("current", i+1),
 			logger.Int("total", len(databases)))
 
-		_, err := ExecuteSingleBackup(backupConfig, dbName, backupFunc)
+		backupResult, err := ExecuteSingleBackup(ctx, backupConfig, dbName, backupFunc)
 		if err != nil {
 			lg.Error("Database backup failed",
 				logger.String("database", dbName),
@@ -51,6 +67,7 @@ func ExecuteMultipleDatabaseBackup(
 		}
 
 		result.SuccessCount++
+		result.Results[dbName] = backupResult
 	}
 
 	// Final summary
@@ -72,7 +89,7 @@ func ExecuteMultipleDatabaseBackup(
 // ExecuteListBackupWorkflow executes the complete list backup workflow
 func ExecuteListBackupWorkflow(
 	cmd *cobra.Command,
-	backupFunc func(BackupOptions) (*BackupResult, error),
+	backupFunc BackupFunc,
 ) error {
 	lg, _ := logger.Get()
 	lg.Info("Starting list backup process")
@@ -105,6 +122,7 @@ func ExecuteListBackupWorkflow(
 
 	// 5. Execute backup for valid databases
 	multiResult, err := ExecuteMultipleDatabaseBackup(
+		cmd.Context(),
 		backupConfig,
 		dbListResult.ValidDatabases,
 		backupFunc,
@@ -0,0 +1,211 @@
+package backup_utils
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/progress"
+	"sfDBTools/utils/terminal"
+)
+
+// parallelOutcome is the result of one database in a parallel backup
+type parallelOutcome struct {
+	result   *BackupResult
+	err      error
+	duration time.Duration
+}
+
+// parallelProgress shows one live line per running database with its step
+// and bytes written, fed by the progress events of the backup engines, and a
+// line as each database finishes. Workers report concurrently, so the counters
+// are guarded by mu.
+type parallelProgress struct {
+	mu      sync.Mutex
+	total   int
+	running int
+	done    int
+	failed  int
+	bars    *terminal.MultiProgress
+	items   map[string]*terminal.ProgressItem
+}
+
+func newParallelProgress(total int) *parallelProgress {
+	return &parallelProgress{total: total, bars: terminal.NewMultiProgress(), items: map[string]*terminal.ProgressItem{}}
+}
+
+func (p *parallelProgress) started(dbName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running++
+	p.items[dbName] = p.bars.AddBytes(dbName, 0)
+}
+
+func (p *parallelProgress) finished(dbName string, o parallelOutcome) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running--
+	p.done++
+	item := p.items[dbName]
+	delete(p.items, dbName)
+	if o.err != nil {
+		p.failed++
+		item.Fail(fmt.Sprintf("[%d/%d] failed after %s: %v", p.done, p.total, o.duration.Round(time.Second), o.err))
+		return
+	}
+	item.Done(fmt.Sprintf("[%d/%d] done: %s in %s", p.done, p.total, common.FormatSize(o.result.OutputSize), o.duration.Round(time.Second)))
+}
+
+// event updates the line of the database a backup engine reports on
+func (p *parallelProgress) event(e progress.Event) {
+	p.mu.Lock()
+	item := p.items[e.Target]
+	p.mu.Unlock()
+	if item == nil {
+		return
+	}
+	switch e.Kind {
+	case progress.KindStepStarted:
+		item.SetStatus(e.Step)
+	case progress.KindBytes:
+		item.Update(e.BytesDone, e.BytesTotal)
+	}
+}
+
+// executeParallelBackup backs up databases with a pool of
+// backupConfig.Parallel workers. Every database gets its own copy of the
+// configuration, so workers never share DBName.
+func executeParallelBackup(
+	ctx context.Context,
+	backupConfig *BackupConfig,
+	databases []string,
+	backupFunc BackupFunc,
+	operationType string,
+) (*MultiBackupResult, error) {
+	lg, _ := logger.Get()
+
+	workers := min(backupConfig.Parallel, len(databases))
+
+	// Workers cannot share the terminal for a password prompt, so ask once
+	if backupConfig.Encrypt && backupConfig.EncryptionPassword == "" && backupConfig.EncryptionKeyFile == "" {
+		password, err := crypto.GetEncryptionPassword("Enter encryption password for backup: ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get encryption password: %w", err)
+		}
+		backupConfig.EncryptionPassword = password
+	}
+
+	terminal.PrintSubHeader(fmt.Sprintf("Backing up %d databases with %d workers", len(databases), workers))
+	lg.Info("Starting parallel multi-database backup",
+		logger.String("operation", operationType),
+		logger.Int("total_databases", len(databases)),
+		logger.Int("workers", workers))
+
+	start := time.Now()
+	outcomes := runParallelJobs(ctx, backupConfig, databases, backupFunc, workers)
+
+	result := &MultiBackupResult{
+		TotalProcessed:  len(databases),
+		FailedDatabases: []string{},
+		Results:         map[string]*BackupResult{},
+	}
+	rows := make([][]string, len(databases))
+	for i, dbName := range databases {
+		o := outcomes[i]
+		if o.err != nil {
+			result.FailedDatabases = append(result.FailedDatabases, dbName)
+			rows[i] = []string{dbName, terminal.ColorText("FAILED", terminal.ColorRed), "-", o.duration.Round(time.Second).String(), "-"}
+			continue
+		}
+		result.SuccessCount++
+		result.Results[dbName] = o.result
+		location := o.result.OutputFile
+		if o.result.Remote != nil {
+			location = o.result.Remote.Key
+		}
+		rows[i] = []string{dbName, terminal.ColorText("OK", terminal.ColorGreen), common.FormatSize(o.result.OutputSize), o.duration.Round(time.Second).String(), location}
+	}
+
+	terminal.PrintSubHeader("Backup Summary")
+	terminal.FormatTable([]string{"Database", "Status", "Size", "Duration", "File"}, rows)
+	lg.Info("Multi-database backup completed",
+		logger.String("operation", operationType),
+		logger.Int("total_processed", result.TotalProcessed),
+		logger.Int("successful", result.SuccessCount),
+		logger.Int("failed", len(result.FailedDatabases)),
+		logger.Int("workers", workers),
+		logger.String("duration", time.Since(start).Round(time.Second).String()),
+		logger.Strings("failed_databases", result.FailedDatabases))
+
+	if len(result.FailedDatabases) > 0 {
+		return result, fmt.Errorf("some databases failed to backup")
+	}
+
+	return result, nil
+}
+
+// runParallelJobs backs up databases with workers goroutines behind the live
+// progress lines. The log output and the terminal are restored even when a
+// worker panics; a panicking database is recorded as failed.
+func runParallelJobs(ctx context.Context, backupConfig *BackupConfig, databases []string, backupFunc BackupFunc, workers int) []parallelOutcome {
+	lg, _ := logger.Get()
+
+	tracker := newParallelProgress(len(databases))
+	tracker.bars.Start()
+	defer tracker.bars.Stop()
+	defer progress.Subscribe(tracker.event)()
+	// Log lines go above the live lines instead of through them
+	console := lg.Out
+	lg.SetOutput(tracker.bars)
+	defer lg.SetOutput(console)
+
+	outcomes := make([]parallelOutcome, len(databases))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if ctx.Err() != nil {
+					outcomes[i] = parallelOutcome{err: fmt.Errorf("not started: interrupted")}
+					continue
+				}
+				tracker.started(databases[i])
+				outcomes[i] = runParallelJob(ctx, *backupConfig, databases[i], backupFunc)
+				tracker.finished(databases[i], outcomes[i])
+			}
+		}()
+	}
+	for i := range databases {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return outcomes
+}
+
+// runParallelJob backs up one database, turning a panic into its error
+func runParallelJob(ctx context.Context, cfg BackupConfig, dbName string, backupFunc BackupFunc) (outcome parallelOutcome) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			if lg, _ := logger.Get(); lg != nil {
+				lg.Error("Backup worker panicked",
+					logger.String("database", dbName),
+					logger.String("panic", fmt.Sprint(r)),
+					logger.String("stack", string(debug.Stack())))
+			}
+			outcome = parallelOutcome{err: fmt.Errorf("backup of %s panicked: %v", dbName, r)}
+		}
+		outcome.duration = time.Since(start)
+	}()
+	result, err := ExecuteSingleBackup(ctx, &cfg, dbName, backupFunc)
+	return parallelOutcome{result: result, err: err}
+}
@@ -0,0 +1,197 @@
+package backup_utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/compression"
+	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/disk"
+)
+
+// Stage is one step of the backup write path. Wrap returns the writer the
+// previous stage writes into; Close of that writer flushes the stage into
+// next without closing next.
+type Stage struct {
+	Name string
+	Wrap func(next io.Writer) (io.WriteCloser, error)
+}
+
+// Pipeline builds the write path of a backup from the dump towards storage:
+//
+//	dump -> filters -> compressor -> encryptor -> buffer -> storage
+//
+// Stages are added in that order regardless of the order of the builder
+// calls, so every backup mode gets the same layering. Filters run in the
+// order they are added.
+type Pipeline struct {
+	filters  []Stage
+	compress *Stage
+	encrypt  *Stage
+	buffer   *Stage
+}
+
+// NewPipeline returns an empty pipeline that writes straight to storage
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Filter adds a stage that sees the plain dump, before compression
+func (p *Pipeline) Filter(s Stage) *Pipeline {
+	p.filters = append(p.filters, s)
+	return p
+}
+
+// Compress compresses the stream with cfg
+func (p *Pipeline) Compress(cfg compression.CompressionConfig) *Pipeline {
+	p.compress = &Stage{
+		Name: "compress:" + string(cfg.Type),
+		Wrap: func(next io.Writer) (io.WriteCloser, error) {
+			return compression.NewCompressingWriter(next, cfg)
+		},
+	}
+	return p
+}
+
+// Encrypt encrypts the compressed stream in chunks with AES-256-GCM under a
+// key derived from secret
+func (p *Pipeline) Encrypt(secret crypto.Secret) *Pipeline {
+	p.encrypt = &Stage{
+		Name: "encrypt:aes-256-gcm",
+		Wrap: func(next io.Writer) (io.WriteCloser, error) {
+			return crypto.NewEncryptingWriter(next, secret)
+		},
+	}
+	return p
+}
+
+// Buffer batches writes to storage in chunks of size bytes
+func (p *Pipeline) Buffer(size int) *Pipeline {
+	p.buffer = &Stage{
+		Name: fmt.Sprintf("buffer:%d", size),
+		Wrap: func(next io.Writer) (io.WriteCloser, error) {
+			return bufferedWriter{bufio.NewWriterSize(next, size)}, nil
+		},
+	}
+	return p
+}
+
+// Stages lists the stages from the dump side to the storage side
+func (p *Pipeline) Stages() []Stage {
+	stages := append([]Stage(nil), p.filters...)
+	for _, s := range []*Stage{p.compress, p.encrypt, p.buffer} {
+		if s != nil {
+			stages = append(stages, *s)
+		}
+	}
+	return stages
+}
+
+// To connects the pipeline to storage and returns the writer the dump goes
+// into. Storage is not closed by the stream.
+func (p *Pipeline) To(storage io.Writer) (*Stream, error) {
+	stages := p.Stages()
+	stream := &Stream{Writer: storage}
+	// Wrap from storage back to the dump; closers end up storage side first
+	for i := len(stages) - 1; i >= 0; i-- {
+		w, err := stages[i].Wrap(stream.Writer)
+		if err != nil {
+			stream.Close()
+			return nil, fmt.Errorf("failed to set up %s stage: %w", stages[i].Name, err)
+		}
+		stream.closers = append(stream.closers, w)
+		stream.Writer = w
+	}
+	return stream, nil
+}
+
+// Stream is the head of a connected pipeline
+type Stream struct {
+	io.Writer
+	closers []io.Closer // storage side first
+	closed  bool
+}
+
+// Close flushes every stage from the dump side towards storage and returns
+// the first error. Closing twice is a no-op.
+func (s *Stream) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	var first error
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		if err := s.closers[i].Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// bufferedWriter batches writes to the output file; Close flushes without
+// closing the file
+type bufferedWriter struct {
+	*bufio.Writer
+}
+
+func (b bufferedWriter) Close() error { return b.Flush() }
+
+// NewBackupPipeline configures compression, encryption and buffering from
+// the backup options. Encryption prompts for the backup password. Writes to
+// a file are buffered in chunks sized for its disk type.
+func NewBackupPipeline(storage io.Writer, options BackupOptions, lg *logger.Logger) (*Pipeline, error) {
+	p := NewPipeline()
+
+	if named, ok := storage.(interface{ Name() string }); ok {
+		size := disk.WriteBufferSize(named.Name())
+		lg.Debug("Buffering backup output", logger.String("file", named.Name()), logger.Int("buffer_bytes", size))
+		p.Buffer(size)
+	}
+
+	if options.Encrypt {
+		secret := crypto.Secret{Passphrase: options.EncryptionPassword, KeyFile: options.EncryptionKeyFile}
+		if secret.Passphrase == "" && secret.KeyFile == "" {
+			var err error
+			if secret, err = crypto.GetEncryptionSecret("Enter encryption password for backup: ", ""); err != nil {
+				return nil, fmt.Errorf("failed to get encryption password: %w", err)
+			}
+		}
+		p.Encrypt(secret)
+		lg.Info("Encryption configured", logger.String("method", crypto.ChunkedAlgorithm), logger.String("key", secret.String()))
+	}
+
+	if options.Compress {
+		compressionType, err := compression.ValidateCompressionType(options.Compression)
+		if err != nil {
+			lg.Warn("Invalid compression type, using gzip", logger.String("requested", options.Compression), logger.Error(err))
+			compressionType = compression.CompressionGzip
+		}
+		compressionLevel, err := compression.ValidateCompressionLevel(options.CompressionLevel)
+		if err != nil {
+			lg.Warn("Invalid compression level, using default", logger.String("requested", options.CompressionLevel), logger.Error(err))
+			compressionLevel = compression.LevelDefault
+		}
+		p.Compress(compression.CompressionConfig{Type: compressionType, Level: compressionLevel})
+	}
+	return p, nil
+}
+
+// OpenBackupStream connects the backup pipeline of options, with filters on
+// the plain dump, to storage. Close the stream before closing storage.
+func OpenBackupStream(storage io.Writer, options BackupOptions, lg *logger.Logger, filters ...Stage) (*Stream, error) {
+	p, err := NewBackupPipeline(storage, options, lg)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range filters {
+		p.Filter(f)
+	}
+	stream, err := p.To(storage)
+	if err != nil {
+		lg.Error("Failed to set up backup pipeline", logger.Error(err))
+		return nil, err
+	}
+	return stream, nil
+}
@@ -0,0 +1,352 @@
+package backup_utils
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sfDBTools/utils/compression"
+	"sfDBTools/utils/crypto"
+)
+
+const pipelineDump = "-- dump\nCREATE TABLE t (id INT);\nINSERT INTO t VALUES (1),(2),(3);\n"
+
+// upperFilter is a filter stage that upper-cases the dump
+func upperFilter() Stage {
+	return Stage{Name: "upper", Wrap: func(next io.Writer) (io.WriteCloser, error) {
+		return nopCloser{writerFunc(func(p []byte) (int, error) {
+			if _, err := next.Write(bytes.ToUpper(p)); err != nil {
+				return 0, err
+			}
+			return len(p), nil
+		})}, nil
+	}}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// recordingStage passes data through and records its close in log; closeErr
+// is returned from Close and writeErr from Write once failAfter bytes passed
+type recordingStage struct {
+	name      string
+	next      io.Writer
+	log       *[]string
+	closeErr  error
+	writeErr  error
+	failAfter int
+	written   int
+}
+
+func (r *recordingStage) Write(p []byte) (int, error) {
+	if r.writeErr != nil && r.written+len(p) > r.failAfter {
+		return 0, r.writeErr
+	}
+	r.written += len(p)
+	return r.next.Write(p)
+}
+
+func (r *recordingStage) Close() error {
+	*r.log = append(*r.log, r.name)
+	return r.closeErr
+}
+
+func recording(name string, log *[]string, configure func(*recordingStage)) Stage {
+	return Stage{Name: name, Wrap: func(next io.Writer) (io.WriteCloser, error) {
+		r := &recordingStage{name: name, next: next, log: log}
+		if configure != nil {
+			configure(r)
+		}
+		return r, nil
+	}}
+}
+
+// failingStorage accepts limit bytes and fails every write after that
+type failingStorage struct {
+	bytes.Buffer
+	limit int
+}
+
+var errStorageFull = errors.New("storage full")
+
+func (f *failingStorage) Write(p []byte) (int, error) {
+	if f.Len()+len(p) > f.limit {
+		return 0, errStorageFull
+	}
+	return f.Buffer.Write(p)
+}
+
+func writeDump(t *testing.T, p *Pipeline, storage io.Writer) {
+	t.Helper()
+	stream, err := p.To(storage)
+	if err != nil {
+		t.Fatalf("To: %v", err)
+	}
+	if _, err := io.Copy(stream, strings.NewReader(pipelineDump)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestPipelineStageOrder(t *testing.T) {
+	p := NewPipeline().
+		Buffer(16).
+		Encrypt(crypto.Secret{Passphrase: "secret"}).
+		Filter(upperFilter()).
+		Compress(compression.CompressionConfig{Type: compression.CompressionGzip, Level: compression.LevelDefault})
+
+	var names []string
+	for _, s := range p.Stages() {
+		names = append(names, s.Name)
+	}
+	want := []string{"upper", "compress:gzip", "encrypt:aes-256-gcm", "buffer:16"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("stages = %v, want %v", names, want)
+	}
+}
+
+func TestPipelineWithoutStagesWritesThrough(t *testing.T) {
+	var storage bytes.Buffer
+	writeDump(t, NewPipeline(), &storage)
+	if storage.String() != pipelineDump {
+		t.Fatalf("storage = %q, want the dump unchanged", storage.String())
+	}
+}
+
+func TestPipelineFilterStage(t *testing.T) {
+	var storage bytes.Buffer
+	writeDump(t, NewPipeline().Filter(upperFilter()), &storage)
+	if want := strings.ToUpper(pipelineDump); storage.String() != want {
+		t.Fatalf("storage = %q, want %q", storage.String(), want)
+	}
+}
+
+func TestPipelineCompressStageRoundTrip(t *testing.T) {
+	for _, ctype := range []compression.CompressionType{compression.CompressionGzip, compression.CompressionZlib, compression.CompressionZstd} {
+		t.Run(string(ctype), func(t *testing.T) {
+			var storage bytes.Buffer
+			writeDump(t, NewPipeline().Compress(compression.CompressionConfig{Type: ctype, Level: compression.LevelDefault}), &storage)
+
+			r, err := compression.NewDecompressingReader(&storage, ctype)
+			if err != nil {
+				t.Fatalf("NewDecompressingReader: %v", err)
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("decompress: %v", err)
+			}
+			if string(got) != pipelineDump {
+				t.Fatalf("round trip = %q, want %q", got, pipelineDump)
+			}
+		})
+	}
+}
+
+func TestPipelineEncryptStageRoundTrip(t *testing.T) {
+	secret := crypto.Secret{Passphrase: "secret"}
+	var storage bytes.Buffer
+	writeDump(t, NewPipeline().Encrypt(secret), &storage)
+	if bytes.Contains(storage.Bytes(), []byte("CREATE TABLE")) {
+		t.Fatal("storage holds the plain dump")
+	}
+
+	r, err := crypto.NewDecryptingReader(&storage, secret)
+	if err != nil {
+		t.Fatalf("NewDecryptingReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if string(got) != pipelineDump {
+		t.Fatalf("round trip = %q, want %q", got, pipelineDump)
+	}
+}
+
+func TestPipelineBufferStageFlushesOnClose(t *testing.T) {
+	var storage bytes.Buffer
+	stream, err := NewPipeline().Buffer(4096).To(&storage)
+	if err != nil {
+		t.Fatalf("To: %v", err)
+	}
+	if _, err := io.WriteString(stream, pipelineDump); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if storage.Len() != 0 {
+		t.Fatalf("storage got %d bytes before Close, want them buffered", storage.Len())
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if storage.String() != pipelineDump {
+		t.Fatalf("storage = %q after Close, want the dump", storage.String())
+	}
+}
+
+func TestPipelineFullRoundTrip(t *testing.T) {
+	secret := crypto.Secret{Passphrase: "secret"}
+	var storage bytes.Buffer
+	p := NewPipeline().
+		Filter(upperFilter()).
+		Compress(compression.CompressionConfig{Type: compression.CompressionGzip, Level: compression.LevelBest}).
+		Encrypt(secret).
+		Buffer(64)
+	writeDump(t, p, &storage)
+
+	decrypted, err := crypto.NewDecryptingReader(&storage, secret)
+	if err != nil {
+		t.Fatalf("NewDecryptingReader: %v", err)
+	}
+	r, err := compression.NewDecompressingReader(decrypted, compression.CompressionGzip)
+	if err != nil {
+		t.Fatalf("NewDecompressingReader: %v", err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read back: %v", err)
+	}
+	if want := strings.ToUpper(pipelineDump); string(got) != want {
+		t.Fatalf("round trip = %q, want %q", got, want)
+	}
+}
+
+func TestStreamClosesDumpSideFirst(t *testing.T) {
+	var log []string
+	p := NewPipeline().
+		Filter(recording("filter-1", &log, nil)).
+		Filter(recording("filter-2", &log, nil))
+	p.buffer = &Stage{Name: "buffer", Wrap: recording("buffer", &log, nil).Wrap}
+
+	var storage bytes.Buffer
+	writeDump(t, p, &storage)
+	if want := []string{"filter-1", "filter-2", "buffer"}; !reflect.DeepEqual(log, want) {
+		t.Fatalf("close order = %v, want %v", log, want)
+	}
+
+	// A second Close must not close the stages again
+	stream, err := NewPipeline().Filter(recording("once", &log, nil)).To(&storage)
+	if err != nil {
+		t.Fatalf("To: %v", err)
+	}
+	log = nil
+	stream.Close()
+	stream.Close()
+	if len(log) != 1 {
+		t.Fatalf("stage closed %d times, want once", len(log))
+	}
+}
+
+func TestStreamCompressedDataReachesStorageBeforeBufferFlush(t *testing.T) {
+	// The compressor writes its trailer on Close; the buffer after it must
+	// only be flushed afterwards or the trailer is lost
+	var storage bytes.Buffer
+	p := NewPipeline().
+		Compress(compression.CompressionConfig{Type: compression.CompressionGzip, Level: compression.LevelDefault}).
+		Buffer(1 << 20)
+	writeDump(t, p, &storage)
+
+	r, err := compression.NewDecompressingReader(&storage, compression.CompressionGzip)
+	if err != nil {
+		t.Fatalf("NewDecompressingReader: %v", err)
+	}
+	defer r.Close()
+	if got, err := io.ReadAll(r); err != nil || string(got) != pipelineDump {
+		t.Fatalf("read back = %q, %v; want the complete dump", got, err)
+	}
+}
+
+func TestStreamWriteErrorMidStream(t *testing.T) {
+	errFilter := errors.New("filter failed")
+	var log []string
+	stream, err := NewPipeline().
+		Filter(recording("filter", &log, func(r *recordingStage) { r.writeErr, r.failAfter = errFilter, 10 })).
+		To(&bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("To: %v", err)
+	}
+	if _, err := io.Copy(stream, strings.NewReader(pipelineDump)); !errors.Is(err, errFilter) {
+		t.Fatalf("write error = %v, want %v", err, errFilter)
+	}
+	stream.Close()
+	if len(log) != 1 {
+		t.Fatalf("failed stage not closed: %v", log)
+	}
+}
+
+func TestStreamStorageErrorSurfacesOnClose(t *testing.T) {
+	// Buffered data only reaches storage on Close, so that is where a full
+	// disk has to show up
+	storage := &failingStorage{limit: 10}
+	stream, err := NewPipeline().Buffer(4096).To(storage)
+	if err != nil {
+		t.Fatalf("To: %v", err)
+	}
+	if _, err := io.WriteString(stream, pipelineDump); err != nil {
+		t.Fatalf("buffered write failed early: %v", err)
+	}
+	if err := stream.Close(); !errors.Is(err, errStorageFull) {
+		t.Fatalf("Close error = %v, want %v", err, errStorageFull)
+	}
+}
+
+func TestStreamStorageErrorThroughCompressor(t *testing.T) {
+	storage := &failingStorage{limit: 16}
+	stream, err := NewPipeline().
+		Compress(compression.CompressionConfig{Type: compression.CompressionGzip, Level: compression.LevelDefault}).
+		To(storage)
+	if err != nil {
+		t.Fatalf("To: %v", err)
+	}
+	_, writeErr := io.Copy(stream, strings.NewReader(strings.Repeat(pipelineDump, 1000)))
+	closeErr := stream.Close()
+	if !errors.Is(writeErr, errStorageFull) && !errors.Is(closeErr, errStorageFull) {
+		t.Fatalf("write error %v, close error %v; want %v from one of them", writeErr, closeErr, errStorageFull)
+	}
+}
+
+func TestStreamCloseReturnsFirstErrorAndClosesAll(t *testing.T) {
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+	var log []string
+	stream, err := NewPipeline().
+		Filter(recording("a", &log, func(r *recordingStage) { r.closeErr = errFirst })).
+		Filter(recording("b", &log, func(r *recordingStage) { r.closeErr = errSecond })).
+		Filter(recording("c", &log, nil)).
+		To(&bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("To: %v", err)
+	}
+	if err := stream.Close(); !errors.Is(err, errFirst) {
+		t.Fatalf("Close error = %v, want %v", err, errFirst)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(log, want) {
+		t.Fatalf("closed %v, want %v", log, want)
+	}
+}
+
+func TestPipelineSetupErrorClosesBuiltStages(t *testing.T) {
+	errSetup := errors.New("no key")
+	var log []string
+	p := NewPipeline().Filter(Stage{Name: "broken", Wrap: func(io.Writer) (io.WriteCloser, error) {
+		return nil, errSetup
+	}})
+	p.buffer = &Stage{Name: "buffer", Wrap: recording("buffer", &log, nil).Wrap}
+
+	if _, err := p.To(&bytes.Buffer{}); !errors.Is(err, errSetup) || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("To error = %v, want the broken stage's error", err)
+	}
+	if want := []string{"buffer"}; !reflect.DeepEqual(log, want) {
+		t.Fatalf("closed %v after failed setup, want %v", log, want)
+	}
+}
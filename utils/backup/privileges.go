@@ -0,0 +1,309 @@
+package backup_utils
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+)
+
+// privilegeRequirement is a privilege the backup user needs, with the privileges that satisfy it
+type privilegeRequirement struct {
+	Name    string   // privilege named in the suggested GRANT
+	Accepts []string // privileges that satisfy the requirement
+	Global  bool     // only grantable ON *.*
+	Reason  string
+}
+
+// backupPrivileges lists what mysqldump needs for a complete, consistent dump
+var backupPrivileges = []privilegeRequirement{
+	{Name: "SELECT", Accepts: []string{"SELECT"}, Reason: "read table data"},
+	{Name: "LOCK TABLES", Accepts: []string{"LOCK TABLES", "BACKUP_ADMIN"}, Reason: "lock tables while dumping non-transactional tables"},
+	{Name: "SHOW VIEW", Accepts: []string{"SHOW VIEW"}, Reason: "dump view definitions"},
+	{Name: "TRIGGER", Accepts: []string{"TRIGGER"}, Reason: "dump triggers"},
+	{Name: "EVENT", Accepts: []string{"EVENT"}, Reason: "dump scheduled events"},
+	{Name: "RELOAD", Accepts: []string{"RELOAD"}, Global: true, Reason: "flush tables and logs for a consistent snapshot"},
+	// MariaDB 10.5 renamed REPLICATION CLIENT to BINLOG MONITOR
+	{Name: "REPLICATION CLIENT", Accepts: []string{"REPLICATION CLIENT", "BINLOG MONITOR", "SUPER"}, Global: true, Reason: "record the binary log position"},
+}
+
+// MissingPrivilege is a required privilege the backup user does not hold
+type MissingPrivilege struct {
+	Privilege string
+	Scope     string // *.* or `db`.*
+	Reason    string
+}
+
+// PrivilegeReport is the result of a backup privilege preflight
+type PrivilegeReport struct {
+	Account   string   // 'user'@'host' the server authenticated the connection as
+	Databases []string // databases checked; empty means all databases
+	Missing   []MissingPrivilege
+}
+
+// OK reports whether the backup user holds every required privilege
+func (r *PrivilegeReport) OK() bool { return len(r.Missing) == 0 }
+
+// Lacks reports whether privilege is missing on any scope
+func (r *PrivilegeReport) Lacks(privilege string) bool {
+	for _, m := range r.Missing {
+		if m.Privilege == privilege {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantStatements returns the GRANT statements that add the missing privileges, one per scope
+func (r *PrivilegeReport) GrantStatements() []string {
+	var scopes []string
+	byScope := make(map[string][]string)
+	for _, m := range r.Missing {
+		if _, ok := byScope[m.Scope]; !ok {
+			scopes = append(scopes, m.Scope)
+		}
+		byScope[m.Scope] = append(byScope[m.Scope], m.Privilege)
+	}
+	stmts := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		stmts = append(stmts, fmt.Sprintf("GRANT %s ON %s TO %s;", strings.Join(byScope[scope], ", "), scope, r.Account))
+	}
+	return stmts
+}
+
+// grantLine matches "GRANT <privileges> ON <scope> TO ..." rows of SHOW GRANTS
+var grantLine = regexp.MustCompile(`(?i)^GRANT\s+(.+?)\s+ON\s+(?:(?:TABLE|FUNCTION|PROCEDURE|PACKAGE(?:\s+BODY)?)\s+)?(\S+)\s+TO\s`)
+
+// heldPrivileges are the privileges granted on *.* and per database pattern
+type heldPrivileges struct {
+	global   map[string]bool
+	patterns []databaseGrant
+}
+
+type databaseGrant struct {
+	pattern *regexp.Regexp
+	privs   map[string]bool
+}
+
+// parseGrants collects global and database-level privileges from SHOW GRANTS
+// output; table and column grants cannot cover a whole database and are ignored
+func parseGrants(grants []string) heldPrivileges {
+	held := heldPrivileges{global: make(map[string]bool)}
+	for _, g := range grants {
+		m := grantLine.FindStringSubmatch(strings.TrimSpace(g))
+		if m == nil {
+			continue
+		}
+		privs := splitPrivileges(m[1])
+		scope := m[2]
+		if scope == "*.*" {
+			for p := range privs {
+				held.global[p] = true
+			}
+			continue
+		}
+		db, rest, ok := strings.Cut(scope, ".")
+		if !ok || rest != "*" {
+			continue
+		}
+		held.patterns = append(held.patterns, databaseGrant{pattern: likePattern(strings.Trim(db, "`'\"")), privs: privs})
+	}
+	return held
+}
+
+// splitPrivileges splits a privilege list, dropping column lists such as "SELECT (a, b)"
+func splitPrivileges(list string) map[string]bool {
+	privs := make(map[string]bool)
+	depth, start := 0, 0
+	add := func(p string) {
+		if i := strings.Index(p, "("); i >= 0 {
+			return // column privilege
+		}
+		p = strings.ToUpper(strings.Join(strings.Fields(p), " "))
+		if p == "ALL" {
+			p = "ALL PRIVILEGES"
+		}
+		if p != "" {
+			privs[p] = true
+		}
+	}
+	for i, c := range list {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				add(list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add(list[start:])
+	return privs
+}
+
+// likePattern turns a database name from a grant, which may use LIKE
+// wildcards such as app\_%, into an anchored regular expression
+func likePattern(name string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case c == '\\' && i+1 < len(name):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(name[i])))
+		case c == '%':
+			b.WriteString(".*")
+		case c == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// holds reports whether any of accepts is granted globally or, for a
+// non-empty dbName, on that database
+func (h heldPrivileges) holds(accepts []string, dbName string) bool {
+	if h.global["ALL PRIVILEGES"] {
+		return true
+	}
+	for _, a := range accepts {
+		if h.global[a] {
+			return true
+		}
+	}
+	if dbName == "" {
+		return false
+	}
+	for _, g := range h.patterns {
+		if !g.pattern.MatchString(dbName) {
+			continue
+		}
+		if g.privs["ALL PRIVILEGES"] {
+			return true
+		}
+		for _, a := range accepts {
+			if g.privs[a] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckBackupPrivileges compares the grants of the connected user with what a
+// backup of databases needs. An empty databases list checks for a backup of
+// every database, which needs the database-level privileges ON *.*.
+func CheckBackupPrivileges(db *sql.DB, databases []string) (*PrivilegeReport, error) {
+	var current string
+	if err := db.QueryRow("SELECT CURRENT_USER()").Scan(&current); err != nil {
+		return nil, fmt.Errorf("failed to determine current user: %w", err)
+	}
+	user, host := current, "%"
+	if i := strings.LastIndex(current, "@"); i >= 0 {
+		user, host = current[:i], current[i+1:]
+	}
+
+	rows, err := db.Query("SHOW GRANTS")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read grants of %s: %w", current, err)
+	}
+	defer rows.Close()
+	var grants []string
+	for rows.Next() {
+		var g string
+		if err := rows.Scan(&g); err != nil {
+			return nil, err
+		}
+		grants = append(grants, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	held := parseGrants(grants)
+	report := &PrivilegeReport{
+		Account:   fmt.Sprintf("'%s'@'%s'", strings.ReplaceAll(user, "'", "\\'"), strings.ReplaceAll(host, "'", "\\'")),
+		Databases: databases,
+	}
+	for _, req := range backupPrivileges {
+		if req.Global || len(databases) == 0 {
+			if !held.holds(req.Accepts, "") {
+				report.Missing = append(report.Missing, MissingPrivilege{Privilege: req.Name, Scope: "*.*", Reason: req.Reason})
+			}
+			continue
+		}
+		for _, name := range databases {
+			if !held.holds(req.Accepts, name) {
+				scope := database.QuoteIdent(name) + ".*"
+				report.Missing = append(report.Missing, MissingPrivilege{Privilege: req.Name, Scope: scope, Reason: req.Reason})
+			}
+		}
+	}
+	return report, nil
+}
+
+// DisplayPrivilegeReport prints the missing privileges and the GRANT statements that fix them
+func DisplayPrivilegeReport(report *PrivilegeReport) {
+	if report.OK() {
+		terminal.PrintSuccess(fmt.Sprintf("%s holds every privilege needed for the backup", report.Account))
+		return
+	}
+	terminal.PrintWarning(fmt.Sprintf("%s is missing %d privilege(s) needed for the backup", report.Account, len(report.Missing)))
+	rows := make([][]string, 0, len(report.Missing))
+	for _, m := range report.Missing {
+		rows = append(rows, []string{m.Privilege, m.Scope, m.Reason})
+	}
+	terminal.FormatTable([]string{"Privilege", "On", "Needed to"}, rows)
+	fmt.Println("Run as an administrator to fix:")
+	for _, stmt := range report.GrantStatements() {
+		fmt.Printf("  %s\n", stmt)
+	}
+}
+
+// PreflightBackupPrivileges checks the backup user before a dump starts. Missing
+// privileges are reported with the GRANT statements to fix them; the backup
+// is refused only without SELECT, since mysqldump cannot dump anything then.
+func PreflightBackupPrivileges(dbConfig database.Config, databases []string) error {
+	lg, _ := logger.Get()
+	db, err := database.GetWithoutDB(dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	report, err := CheckBackupPrivileges(db, databases)
+	if err != nil {
+		// The dump itself reports authoritative errors; do not block on the preflight
+		lg.Warn("Backup privilege preflight skipped", logger.Error(err))
+		return nil
+	}
+	if report.OK() {
+		lg.Debug("Backup privilege preflight passed", logger.String("account", report.Account))
+		return nil
+	}
+
+	missing := make([]string, 0, len(report.Missing))
+	for _, m := range report.Missing {
+		missing = append(missing, m.Privilege+" ON "+m.Scope)
+	}
+	lg.Warn("Backup user is missing privileges",
+		logger.String("account", report.Account),
+		logger.Strings("missing", missing),
+		logger.Strings("fix", report.GrantStatements()))
+	DisplayPrivilegeReport(report)
+
+	if report.Lacks("SELECT") {
+		return fmt.Errorf("backup user %s lacks SELECT; grant the privileges above and retry", report.Account)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package backup_utils
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+
+	"github.com/spf13/cobra"
+)
+
+// profileSetting is one flag a backup profile presets, with the environment
+// variable that overrides it like an explicit flag does
+type profileSetting struct {
+	flag  string
+	env   string
+	value string
+}
+
+// backupProfiles bundle codec, level, parallelism and verification. pgzip and
+// zstd compress on every core; gzip is single-threaded but the most portable.
+var backupProfiles = map[string][]profileSetting{
+	"fast": {
+		{"compress", "COMPRESS", "true"},
+		{"compression", "COMPRESSION", "zstd"},
+		{"compression-level", "COMPRESSION_LEVEL", "fast"},
+		{"checksum-algorithm", "CHECKSUM_ALGORITHM", "xxh64"},
+		{"verify-disk", "VERIFY_DISK", "false"},
+	},
+	"balanced": {
+		{"compress", "COMPRESS", "true"},
+		{"compression", "COMPRESSION", "pgzip"},
+		{"compression-level", "COMPRESSION_LEVEL", "default"},
+		{"calculate-checksum", "CALCULATE_CHECKSUM", "true"},
+		{"checksum-algorithm", "CHECKSUM_ALGORITHM", "xxh64"},
+		{"verify-disk", "VERIFY_DISK", "true"},
+	},
+	"safe": {
+		{"compress", "COMPRESS", "true"},
+		{"compression", "COMPRESSION", "gzip"},
+		{"compression-level", "COMPRESSION_LEVEL", "default"},
+		{"calculate-checksum", "CALCULATE_CHECKSUM", "true"},
+		{"checksum-algorithm", "CHECKSUM_ALGORITHM", "sha256"},
+		{"verify-disk", "VERIFY_DISK", "true"},
+	},
+}
+
+// BackupProfileNames lists the accepted --profile values
+func BackupProfileNames() []string {
+	names := make([]string, 0, len(backupProfiles))
+	for name := range backupProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyBackupProfile presets the flags of the --profile preset. Flags given on
+// the command line or through their environment variable keep their value, so
+// a profile only fills in what the operator did not choose.
+func ApplyBackupProfile(cmd *cobra.Command) error {
+	if cmd.Flags().Lookup("profile") == nil {
+		return nil
+	}
+	name := strings.ToLower(common.GetStringFlagOrEnv(cmd, "profile", "BACKUP_PROFILE", ""))
+	if name == "" {
+		return nil
+	}
+	settings, ok := backupProfiles[name]
+	if !ok {
+		return common.WithExitCode(fmt.Errorf("unknown backup profile %q (expected %s)", name, strings.Join(BackupProfileNames(), ", ")), common.ExitUsage)
+	}
+
+	lg, _ := logger.Get()
+	var applied []string
+	for _, s := range settings {
+		f := cmd.Flags().Lookup(s.flag)
+		if f == nil || f.Changed || os.Getenv(s.env) != "" {
+			continue
+		}
+		if err := cmd.Flags().Set(s.flag, s.value); err != nil {
+			return fmt.Errorf("failed to apply profile %s to --%s: %w", name, s.flag, err)
+		}
+		applied = append(applied, s.flag+"="+s.value)
+	}
+	lg.Info("Applied backup profile", logger.String("profile", name), logger.String("settings", strings.Join(applied, " ")))
+	return nil
+}
@@ -0,0 +1,234 @@
+package backup_utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/backup/catalog"
+	"sfDBTools/utils/schema"
+)
+
+// PruneOptions controls Prune
+type PruneOptions struct {
+	// RetentionDays replaces the expiry recorded with each backup when set
+	RetentionDays int
+	// Database limits pruning to the backups of one database or group
+	Database string
+	// BaseDir is the backup directory; remote manifest keys are relative to it
+	BaseDir string
+	// Remote is the storage remote copies are deleted from; nil prunes local
+	// files only and keeps the catalog entries of backups with a remote copy
+	Remote *RemoteStore
+	DryRun bool
+}
+
+// PruneItem is one expired backup and what happened to it
+type PruneItem struct {
+	Entry         catalog.Entry
+	ExpiredAt     time.Time
+	LocalRemoved  bool
+	RemoteRemoved bool
+	RemoteKept    bool // a remote copy exists but no remote storage was given
+	Err           error
+}
+
+// PruneResult reports what Prune did
+type PruneResult struct {
+	Pruned []PruneItem
+	Pinned []catalog.Entry // expired, but kept because they are pinned
+	Latest []catalog.Entry // expired, but kept as the newest backup of their database
+	Freed  int64
+}
+
+// Failed returns the items that could not be removed completely
+func (r *PruneResult) Failed() []PruneItem {
+	var failed []PruneItem
+	for _, item := range r.Pruned {
+		if item.Err != nil {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// Prune deletes the backups in cat whose retention has passed: the backup
+// file, its manifest and the remote copies, then the catalog entry. Pinned
+// backups are kept, and so is the newest backup of every database and type,
+// so a database whose backups stopped running still has one to restore.
+// Entries whose files could not all be removed stay in the catalog, so the
+// next prune retries them.
+func Prune(cat *catalog.Catalog, opts PruneOptions) (*PruneResult, error) {
+	lg, _ := logger.Get()
+	entries, err := cat.List(catalog.Filter{Database: opts.Database})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := &PruneResult{}
+	newest := make(map[string]bool)
+	for _, e := range entries {
+		// List returns newest first, so the first entry of a database is its latest
+		key := e.Database + "\x00" + e.Type
+		latest := !newest[key]
+		newest[key] = true
+
+		expires := e.ExpiresAt
+		if opts.RetentionDays > 0 {
+			expires = e.BackupDate.AddDate(0, 0, opts.RetentionDays)
+		}
+		switch {
+		case expires.IsZero() || !now.After(expires):
+			continue
+		case e.Pinned:
+			result.Pinned = append(result.Pinned, e)
+			continue
+		case latest:
+			result.Latest = append(result.Latest, e)
+			continue
+		}
+
+		item := PruneItem{Entry: e, ExpiredAt: expires}
+		if !opts.DryRun {
+			item.Err = pruneEntry(cat, &item, opts)
+			if item.Err != nil {
+				lg.Warn("Failed to prune expired backup", logger.String("file", e.File), logger.Error(item.Err))
+			} else {
+				lg.Info("Expired backup pruned", logger.String("file", e.File),
+					logger.Bool("local", item.LocalRemoved), logger.Bool("remote", item.RemoteRemoved))
+			}
+		}
+		if item.Err == nil {
+			result.Freed += e.Size
+		}
+		result.Pruned = append(result.Pruned, item)
+	}
+
+	lg.Info("Backup prune finished",
+		logger.Bool("dry_run", opts.DryRun),
+		logger.Int("pruned", len(result.Pruned)),
+		logger.Int("failed", len(result.Failed())),
+		logger.Int("pinned", len(result.Pinned)),
+		logger.Int64("freed_bytes", result.Freed))
+	return result, nil
+}
+
+// pruneEntry removes the files of one backup and, once nothing of it is
+// left, its catalog entry
+func pruneEntry(cat *catalog.Catalog, item *PruneItem, opts PruneOptions) error {
+	e := &item.Entry
+	var errs []error
+	for _, path := range []string{e.File, e.Manifest} {
+		err := os.Remove(path)
+		switch {
+		case err == nil:
+			item.LocalRemoved = true
+		case !os.IsNotExist(err):
+			errs = append(errs, err)
+		}
+	}
+	removeEmptyParents(filepath.Dir(e.Manifest), opts.BaseDir)
+
+	if e.RemoteKey != "" {
+		if opts.Remote == nil {
+			item.RemoteKept = true
+		} else {
+			keys := []string{e.RemoteKey}
+			if rel, err := filepath.Rel(opts.BaseDir, e.Manifest); opts.BaseDir != "" && err == nil && !strings.HasPrefix(rel, "..") {
+				keys = append(keys, opts.Remote.Key(rel))
+			}
+			for _, key := range keys {
+				if err := opts.Remote.Delete(key); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			item.RemoteRemoved = len(errs) == 0
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if item.RemoteKept {
+		return nil
+	}
+	if err := cat.Remove(e.ID); err != nil {
+		return fmt.Errorf("failed to remove catalog entry %d: %w", e.ID, err)
+	}
+	return nil
+}
+
+// removeEmptyParents removes dir and its parents up to, not including,
+// baseDir while they are empty, so pruning does not leave empty date
+// directories behind
+func removeEmptyParents(dir, baseDir string) {
+	if baseDir == "" {
+		return
+	}
+	baseDir = filepath.Clean(baseDir)
+	for dir = filepath.Clean(dir); dir != baseDir && strings.HasPrefix(dir, baseDir+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+// PruneAfterBackup prunes expired backups once a backup has finished, when
+// backup.retention.cleanup_enabled is set. Remote copies are pruned too when
+// backup.remote.bucket is configured. The backup itself succeeded, so
+// failures are only logged.
+func PruneAfterBackup() {
+	lg, _ := logger.Get()
+	cfg, err := config.Get()
+	if err != nil || !cfg.Backup.Retention.CleanupEnabled {
+		return
+	}
+	path := CatalogPath(cfg)
+	if path == "" {
+		return
+	}
+
+	opts := PruneOptions{BaseDir: cfg.Backup.Storage.BaseDirectory}
+	if cfg.Backup.Remote.Bucket != "" {
+		if opts.Remote, err = NewRemoteStore(cfg.Backup.Remote); err != nil {
+			lg.Warn("Pruning local backups only", logger.Error(err))
+		}
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	cat, err := catalog.Open(path)
+	if err != nil {
+		lg.Warn("Expired backups not pruned", logger.Error(err))
+		return
+	}
+	defer cat.Close()
+	if _, err := Prune(cat, opts); err != nil {
+		lg.Warn("Expired backups not pruned", logger.Error(err))
+	}
+}
+
+// SetPinned pins or unpins a backup. The pin is written to the manifest as
+// well as the catalog, so it survives rebuilding the catalog with
+// 'catalog index'.
+func SetPinned(cat *catalog.Catalog, e *catalog.Entry, pinned bool, reason string) error {
+	err := schema.PatchManifest(e.Manifest, func(doc map[string]any) {
+		if pinned {
+			doc["pinned"] = true
+			doc["pin_reason"] = reason
+		} else {
+			delete(doc, "pinned")
+			delete(doc, "pin_reason")
+		}
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to update manifest %s: %w", e.Manifest, err)
+	}
+	return cat.SetPinned(e.ID, pinned, reason)
+}
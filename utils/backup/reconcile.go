@@ -0,0 +1,188 @@
+package backup_utils
+
+import (
+	"encoding/json"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/schema"
+)
+
+// Remote states recorded in manifests by ReconcileRemote
+const (
+	RemoteStatusPresent      = "present"       // the object exists with the expected size
+	RemoteStatusSizeMismatch = "size_mismatch" // the object exists with another size
+	RemoteStatusMissing      = "missing"       // not in remote storage, local copy still exists
+	RemoteStatusUploaded     = "uploaded"      // was missing and has been uploaded again
+	RemoteStatusVanished     = "vanished"      // gone from remote storage and from disk
+)
+
+// ReconcileOptions controls ReconcileRemote
+type ReconcileOptions struct {
+	BaseDir string
+	// ReuploadWithin re-uploads missing backups younger than this; 0 disables it
+	ReuploadWithin time.Duration
+	DryRun         bool
+}
+
+// ReconcileEntry is the outcome for one manifest
+type ReconcileEntry struct {
+	Manifest   string
+	Database   string
+	BackupDate time.Time
+	Key        string
+	Status     string
+	Previous   string // status recorded by the previous reconcile, if any
+	Err        error  // upload or manifest update failure
+}
+
+// ReconcileResult compares the catalog (manifests below BaseDir) with remote storage
+type ReconcileResult struct {
+	Entries   []ReconcileEntry
+	Untracked []RemoteObject // remote objects no manifest refers to
+}
+
+// Count returns how many entries ended in status
+func (r *ReconcileResult) Count(status string) int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Diverged reports whether catalog and storage disagree
+func (r *ReconcileResult) Diverged() bool {
+	return len(r.Untracked) > 0 || r.Count(RemoteStatusPresent)+r.Count(RemoteStatusUploaded) < len(r.Entries)
+}
+
+// ReconcileRemote walks the backup manifests, checks each artifact against the
+// remote listing and records the result in the manifest. Buckets with lifecycle
+// rules expire objects on their own, so entries whose artifact is gone both
+// remotely and locally are marked vanished instead of being left stale.
+func ReconcileRemote(store *RemoteStore, opts ReconcileOptions) (*ReconcileResult, error) {
+	lg, _ := logger.Get()
+
+	objects, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	lg.Info("Listed remote backup objects", logger.Int("objects", len(objects)))
+
+	result := &ReconcileResult{}
+	referenced := make(map[string]bool)
+	now := time.Now().UTC()
+
+	err = filepath.WalkDir(opts.BaseDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		meta, ok := ReadBackupManifest(path)
+		if !ok {
+			return nil
+		}
+		if rel, relErr := filepath.Rel(opts.BaseDir, path); relErr == nil {
+			referenced[store.Key(rel)] = true
+		}
+
+		artifact := ArtifactPath(path, meta)
+		rel, err := filepath.Rel(opts.BaseDir, artifact)
+		if err != nil {
+			return nil
+		}
+		entry := ReconcileEntry{
+			Manifest:   path,
+			Database:   meta.DatabaseName,
+			BackupDate: meta.BackupDate,
+			Key:        store.Key(rel),
+		}
+		if meta.Remote != nil {
+			entry.Previous = meta.Remote.Status
+		}
+		referenced[entry.Key] = true
+
+		_, statErr := os.Stat(artifact)
+		localExists := statErr == nil
+		obj, remoteExists := objects[entry.Key]
+		switch {
+		case remoteExists && meta.FileSize > 0 && obj.Size != meta.FileSize:
+			entry.Status = RemoteStatusSizeMismatch
+		case remoteExists:
+			entry.Status = RemoteStatusPresent
+		case !localExists:
+			entry.Status = RemoteStatusVanished
+		default:
+			entry.Status = RemoteStatusMissing
+			if opts.ReuploadWithin > 0 && now.Sub(meta.BackupDate) <= opts.ReuploadWithin && !opts.DryRun {
+				if err := store.Upload(artifact, entry.Key); err != nil {
+					entry.Err = err
+					lg.Warn("Re-upload failed", logger.String("file", artifact), logger.Error(err))
+				} else {
+					entry.Status = RemoteStatusUploaded
+					lg.Info("Re-uploaded missing backup", logger.String("file", artifact), logger.String("key", entry.Key))
+				}
+			}
+		}
+
+		if !opts.DryRun {
+			state := map[string]any{"key": entry.Key, "status": entry.Status, "checked_at": now}
+			if err := schema.PatchManifest(path, func(doc map[string]any) { doc["remote"] = state }); err != nil && entry.Err == nil {
+				entry.Err = err
+			}
+		}
+		result.Entries = append(result.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for key, obj := range objects {
+		if !referenced[key] && !strings.HasSuffix(key, "/") {
+			result.Untracked = append(result.Untracked, obj)
+		}
+	}
+	sort.Slice(result.Untracked, func(i, j int) bool { return result.Untracked[i].Key < result.Untracked[j].Key })
+	sort.Slice(result.Entries, func(i, j int) bool { return result.Entries[i].BackupDate.After(result.Entries[j].BackupDate) })
+
+	lg.Info("Catalog reconcile finished",
+		logger.Int("entries", len(result.Entries)),
+		logger.Int("vanished", result.Count(RemoteStatusVanished)),
+		logger.Int("missing", result.Count(RemoteStatusMissing)),
+		logger.Int("uploaded", result.Count(RemoteStatusUploaded)),
+		logger.Int("untracked", len(result.Untracked)))
+	return result, nil
+}
+
+// ArtifactPath returns the backup file of the manifest at manifestPath.
+// Manifests record the file name next to them; older all-databases manifests
+// record an absolute path.
+func ArtifactPath(manifestPath string, meta *BackupMetadata) string {
+	if filepath.IsAbs(meta.OutputFile) {
+		return meta.OutputFile
+	}
+	return filepath.Join(filepath.Dir(manifestPath), meta.OutputFile)
+}
+
+// ReadBackupManifest decodes a backup manifest; ok is false for other JSON files
+func ReadBackupManifest(path string) (*BackupMetadata, bool) {
+	data, err := schema.ReadManifest(path)
+	if err != nil {
+		return nil, false
+	}
+	var meta BackupMetadata
+	if err := json.Unmarshal(data, &meta); err != nil || meta.OutputFile == "" || meta.BackupDate.IsZero() {
+		return nil, false
+	}
+	return &meta, true
+}
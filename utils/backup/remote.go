@@ -0,0 +1,256 @@
+package backup_utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config/model"
+	"sfDBTools/utils/download"
+	"sfDBTools/utils/fs/policy"
+	"sfDBTools/utils/shutdown"
+)
+
+// remoteFetchChunk is the size of the ranges Download fetches, and so the
+// most a failed attempt has to fetch again
+const remoteFetchChunk = 64 << 20
+
+// RemoteObject is one object found in remote storage
+type RemoteObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// RemoteStore lists and uploads backup artifacts in an S3 bucket through the
+// aws CLI, so bucket credentials and S3-compatible endpoints are configured
+// the same way as for any other aws command
+type RemoteStore struct {
+	cfg model.BackupRemote
+}
+
+// NewRemoteStore checks the remote settings and that the aws CLI is installed
+func NewRemoteStore(cfg model.BackupRemote) (*RemoteStore, error) {
+	if cfg.Provider != "" && cfg.Provider != "s3" {
+		return nil, fmt.Errorf("unsupported remote provider %q (only s3 is supported)", cfg.Provider)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("remote bucket is not set; use --bucket or backup.remote.bucket")
+	}
+	if _, err := exec.LookPath("aws"); err != nil {
+		return nil, fmt.Errorf("aws CLI not found in PATH; it is needed to access s3://%s", cfg.Bucket)
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	return &RemoteStore{cfg: cfg}, nil
+}
+
+// Key returns the object key for an artifact path relative to the backup directory
+func (s *RemoteStore) Key(relPath string) string {
+	return path.Join(s.cfg.Prefix, strings.ReplaceAll(relPath, "\\", "/"))
+}
+
+// RelPath returns the path relative to the backup directory of key, the
+// inverse of Key
+func (s *RemoteStore) RelPath(key string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(key, s.cfg.Prefix), "/")
+}
+
+// URL returns the s3:// URL of key
+func (s *RemoteStore) URL(key string) string {
+	return fmt.Sprintf("s3://%s/%s", s.cfg.Bucket, key)
+}
+
+// List returns every object below the prefix keyed by object key
+func (s *RemoteStore) List() (map[string]RemoteObject, error) {
+	args := []string{"s3api", "list-objects-v2", "--bucket", s.cfg.Bucket, "--output", "json"}
+	if s.cfg.Prefix != "" {
+		args = append(args, "--prefix", s.cfg.Prefix+"/")
+	}
+	out, err := s.run(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list s3://%s/%s: %w", s.cfg.Bucket, s.cfg.Prefix, err)
+	}
+
+	var listing struct {
+		Contents []struct {
+			Key          string    `json:"Key"`
+			Size         int64     `json:"Size"`
+			LastModified time.Time `json:"LastModified"`
+		} `json:"Contents"`
+	}
+	// An empty prefix makes the CLI print nothing at all
+	if len(bytes.TrimSpace(out)) > 0 {
+		if err := json.Unmarshal(out, &listing); err != nil {
+			return nil, fmt.Errorf("failed to parse object listing: %w", err)
+		}
+	}
+	objects := make(map[string]RemoteObject, len(listing.Contents))
+	for _, o := range listing.Contents {
+		objects[o.Key] = RemoteObject{Key: o.Key, Size: o.Size, LastModified: o.LastModified}
+	}
+	return objects, nil
+}
+
+// Upload copies a local file to key
+func (s *RemoteStore) Upload(localPath, key string) error {
+	if _, err := s.run("s3", "cp", "--only-show-errors", localPath, s.URL(key)); err != nil {
+		return fmt.Errorf("failed to upload %s to %s: %w", localPath, s.URL(key), err)
+	}
+	return nil
+}
+
+// Download copies key to a local file, creating its directory. The object
+// is fetched in ranges of remoteFetchChunk bytes into <localPath>.part; a
+// failed range is retried as configured in general.download, and a later
+// call for the same file resumes where the .part file ends. Large objects
+// show a progress bar on a terminal.
+func (s *RemoteStore) Download(key, localPath string) error {
+	if err := policy.MkdirAll(filepath.Dir(localPath)); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", localPath, err)
+	}
+	size, ok, err := s.Head(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("failed to download %s: object does not exist", s.URL(key))
+	}
+
+	part := localPath + download.PartSuffix
+	f, err := os.OpenFile(part, os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", part, err)
+	}
+	f.Close()
+
+	progress := download.NewProgress("Downloading " + filepath.Base(localPath))
+	progress.SetTotal(size)
+	defer progress.Finish()
+
+	retry := download.RetryFromConfig()
+	for {
+		info, err := os.Stat(part)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", part, err)
+		}
+		done := info.Size()
+		if done > size {
+			// Left over from an older version of the object
+			if err := os.Truncate(part, 0); err != nil {
+				return fmt.Errorf("failed to reset %s: %w", part, err)
+			}
+			done = 0
+		}
+		progress.Update(done)
+		if done == size {
+			break
+		}
+		end := min(done+remoteFetchChunk, size) - 1
+		if err := retry.Do(context.Background(), s.URL(key), func() error {
+			return s.fetchRange(key, part, done, end)
+		}); err != nil {
+			return fmt.Errorf("failed to download %s to %s: %w", s.URL(key), localPath, err)
+		}
+	}
+
+	if err := os.Rename(part, localPath); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", part, err)
+	}
+	// The .part file was private while incomplete
+	_ = policy.Current().Apply(localPath, false)
+	return nil
+}
+
+// fetchRange appends bytes start to end (inclusive) of key to part. The range
+// lands in a file of its own first, so a broken transfer never leaves a gap
+// or a torn range in part.
+func (s *RemoteStore) fetchRange(key, part string, start, end int64) error {
+	chunk := part + ".range"
+	defer os.Remove(chunk)
+	if _, err := s.run("s3api", "get-object", "--bucket", s.cfg.Bucket, "--key", key,
+		"--range", fmt.Sprintf("bytes=%d-%d", start, end), chunk, "--output", "json"); err != nil {
+		return err
+	}
+
+	in, err := os.Open(chunk)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if info, err := in.Stat(); err != nil {
+		return err
+	} else if info.Size() != end-start+1 {
+		return fmt.Errorf("range %d-%d returned %d bytes", start, end, info.Size())
+	}
+
+	out, err := os.OpenFile(part, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return download.Permanent(err)
+	}
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// The appended bytes are still correct; the next range starts after them
+		return fmt.Errorf("failed to write %s: %w", part, err)
+	}
+	return nil
+}
+
+// Delete removes key; removing an object that does not exist succeeds
+func (s *RemoteStore) Delete(key string) error {
+	if _, err := s.run("s3", "rm", "--only-show-errors", s.URL(key)); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", s.URL(key), err)
+	}
+	return nil
+}
+
+// Head returns the size of key; ok is false when the object does not exist
+func (s *RemoteStore) Head(key string) (size int64, ok bool, err error) {
+	out, err := s.run("s3api", "head-object", "--bucket", s.cfg.Bucket, "--key", key, "--output", "json")
+	if err != nil {
+		if strings.Contains(err.Error(), "Not Found") || strings.Contains(err.Error(), "404") {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to read %s: %w", s.URL(key), err)
+	}
+	var head struct {
+		ContentLength int64 `json:"ContentLength"`
+	}
+	if err := json.Unmarshal(out, &head); err != nil {
+		return 0, false, fmt.Errorf("failed to parse object metadata: %w", err)
+	}
+	return head.ContentLength, true, nil
+}
+
+func (s *RemoteStore) run(args ...string) ([]byte, error) {
+	if s.cfg.Region != "" {
+		args = append(args, "--region", s.cfg.Region)
+	}
+	if s.cfg.EndpointURL != "" {
+		args = append(args, "--endpoint-url", s.cfg.EndpointURL)
+	}
+	if s.cfg.Profile != "" {
+		args = append(args, "--profile", s.cfg.Profile)
+	}
+	var stderr bytes.Buffer
+	cmd := shutdown.Command(shutdown.Context(), "aws", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
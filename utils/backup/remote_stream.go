@@ -0,0 +1,351 @@
+package backup_utils
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/config/model"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/fs"
+	"sfDBTools/utils/tempdir"
+)
+
+const (
+	// remotePartSize is the size of the first multipart upload parts. It
+	// doubles every remotePartGrowth parts so the 10000 part limit of S3 is
+	// never reached, whatever the size of the dump.
+	remotePartSize   = 64 << 20
+	remotePartGrowth = 1000
+	// remotePartAttempts is how often a part is sent before the upload fails
+	remotePartAttempts = 5
+)
+
+// ParseS3URL splits s3://bucket/prefix into bucket and prefix
+func ParseS3URL(url string) (bucket, prefix string, err error) {
+	rest, ok := strings.CutPrefix(url, "s3://")
+	if !ok {
+		return "", "", fmt.Errorf("invalid output %q (expected s3://bucket/prefix)", url)
+	}
+	bucket, prefix, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("invalid output %q: bucket is missing", url)
+	}
+	return bucket, strings.Trim(prefix, "/"), nil
+}
+
+// NewOutputStore returns the store for an --output s3://bucket/prefix target.
+// Region, endpoint and profile come from backup.remote, so an S3-compatible
+// service such as MinIO is configured once for uploads and reconcile alike.
+func NewOutputStore(url string) (*RemoteStore, error) {
+	bucket, prefix, err := ParseS3URL(url)
+	if err != nil {
+		return nil, err
+	}
+	var remote model.BackupRemote
+	if cfg, err := config.Get(); err == nil {
+		remote = cfg.Backup.Remote
+	}
+	remote.Provider, remote.Bucket, remote.Prefix = "s3", bucket, prefix
+	return NewRemoteStore(remote)
+}
+
+// RemoteWriter writes an object with an S3 multipart upload. Only the part
+// being uploaded is spooled to the temp directory, so a dump of any size
+// needs at most one part of local space. Every part is sent with its
+// Content-MD5, which makes the service reject a part corrupted in transit;
+// failed parts are retried with backoff.
+type RemoteWriter struct {
+	store    *RemoteStore
+	key      string
+	uploadID string
+
+	part     *os.File
+	partHash hash.Hash
+	partLen  int64
+	partSize int64
+
+	parts []completedPart
+	size  int64
+	done  bool
+}
+
+type completedPart struct {
+	ETag       string `json:"ETag"`
+	PartNumber int    `json:"PartNumber"`
+}
+
+// NewWriter starts a multipart upload to key
+func (s *RemoteStore) NewWriter(key string) (*RemoteWriter, error) {
+	out, err := s.run("s3api", "create-multipart-upload", "--bucket", s.cfg.Bucket, "--key", key, "--output", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start upload to %s: %w", s.URL(key), err)
+	}
+	var created struct {
+		UploadID string `json:"UploadId"`
+	}
+	if err := json.Unmarshal(out, &created); err != nil || created.UploadID == "" {
+		return nil, fmt.Errorf("failed to start upload to %s: no upload id returned", s.URL(key))
+	}
+	return &RemoteWriter{store: s, key: key, uploadID: created.UploadID, partSize: remotePartSize}, nil
+}
+
+// Write spools p into the current part and uploads every part that fills up
+func (w *RemoteWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if w.part == nil {
+			if err := w.openPart(); err != nil {
+				return written, err
+			}
+		}
+		chunk := p
+		if room := w.partSize - w.partLen; int64(len(chunk)) > room {
+			chunk = chunk[:room]
+		}
+		n, err := w.part.Write(chunk)
+		w.partHash.Write(chunk[:n])
+		w.partLen += int64(n)
+		w.size += int64(n)
+		written += n
+		if err != nil {
+			return written, fmt.Errorf("failed to spool upload part: %w", err)
+		}
+		p = p[n:]
+		if w.partLen == w.partSize {
+			if err := w.uploadPart(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close uploads the last part, completes the upload and checks that the
+// object has the size that was written. On error the upload is aborted.
+func (w *RemoteWriter) Close() error {
+	if w.done {
+		return nil
+	}
+	// An empty dump still needs one (empty) part
+	if w.part != nil || len(w.parts) == 0 {
+		if w.part == nil {
+			if err := w.openPart(); err != nil {
+				w.Abort()
+				return err
+			}
+		}
+		if err := w.uploadPart(); err != nil {
+			w.Abort()
+			return err
+		}
+	}
+
+	manifest, err := os.CreateTemp(tempdir.Dir(), "sfdbtools-parts-*.json")
+	if err != nil {
+		w.Abort()
+		return fmt.Errorf("failed to write part list: %w", err)
+	}
+	defer os.Remove(manifest.Name())
+	err = json.NewEncoder(manifest).Encode(map[string][]completedPart{"Parts": w.parts})
+	if cerr := manifest.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		w.Abort()
+		return fmt.Errorf("failed to write part list: %w", err)
+	}
+
+	if _, err := w.store.run("s3api", "complete-multipart-upload", "--bucket", w.store.cfg.Bucket, "--key", w.key,
+		"--upload-id", w.uploadID, "--multipart-upload", "file://"+manifest.Name()); err != nil {
+		w.Abort()
+		return fmt.Errorf("failed to complete upload to %s: %w", w.store.URL(w.key), err)
+	}
+	w.done = true
+
+	size, ok, err := w.store.Head(w.key)
+	if err != nil {
+		return err
+	}
+	if !ok || size != w.size {
+		return fmt.Errorf("%w for %s: wrote %d bytes, object has %d", fs.ErrChecksumMismatch, w.store.URL(w.key), w.size, size)
+	}
+	return nil
+}
+
+// Abort cancels an unfinished upload so the service discards its parts
+func (w *RemoteWriter) Abort() {
+	if w.done {
+		return
+	}
+	w.done = true
+	w.discardPart()
+	if _, err := w.store.run("s3api", "abort-multipart-upload", "--bucket", w.store.cfg.Bucket, "--key", w.key, "--upload-id", w.uploadID); err != nil {
+		lg, _ := logger.Get()
+		lg.Warn("Failed to abort multipart upload; the bucket keeps its parts until a lifecycle rule removes them",
+			logger.String("key", w.key), logger.String("upload_id", w.uploadID), logger.Error(err))
+	}
+}
+
+// Size returns the number of bytes written
+func (w *RemoteWriter) Size() int64 {
+	return w.size
+}
+
+func (w *RemoteWriter) openPart() error {
+	f, err := os.CreateTemp(tempdir.Dir(), "sfdbtools-part-*")
+	if err != nil {
+		return fmt.Errorf("failed to create upload part: %w", err)
+	}
+	w.part, w.partHash, w.partLen = f, md5.New(), 0
+	return nil
+}
+
+func (w *RemoteWriter) discardPart() {
+	if w.part != nil {
+		w.part.Close()
+		os.Remove(w.part.Name())
+		w.part = nil
+	}
+}
+
+// uploadPart sends the spooled part, retrying with backoff
+func (w *RemoteWriter) uploadPart() error {
+	defer w.discardPart()
+	number := len(w.parts) + 1
+	contentMD5 := base64.StdEncoding.EncodeToString(w.partHash.Sum(nil))
+
+	lg, _ := logger.Get()
+	var err error
+	for attempt := 1; attempt <= remotePartAttempts; attempt++ {
+		var out []byte
+		out, err = w.store.run("s3api", "upload-part", "--bucket", w.store.cfg.Bucket, "--key", w.key,
+			"--upload-id", w.uploadID, "--part-number", strconv.Itoa(number),
+			"--body", w.part.Name(), "--content-md5", contentMD5, "--output", "json")
+		if err == nil {
+			var uploaded struct {
+				ETag string `json:"ETag"`
+			}
+			if err = json.Unmarshal(out, &uploaded); err == nil && uploaded.ETag == "" {
+				err = errors.New("no ETag returned")
+			}
+			if err == nil {
+				w.parts = append(w.parts, completedPart{ETag: uploaded.ETag, PartNumber: number})
+				break
+			}
+		}
+		if attempt == remotePartAttempts {
+			break
+		}
+		wait := time.Duration(1<<attempt) * time.Second
+		lg.Warn("Upload part failed, retrying",
+			logger.String("key", w.key),
+			logger.Int("part", number),
+			logger.Int("attempt", attempt),
+			logger.String("retry_in", wait.String()),
+			logger.Error(err))
+		time.Sleep(wait)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upload part %d of %s after %d attempts: %w", number, w.store.URL(w.key), remotePartAttempts, err)
+	}
+
+	lg.Debug("Uploaded part", logger.String("key", w.key), logger.Int("part", number), logger.Int64("bytes", w.partLen))
+	if number%remotePartGrowth == 0 {
+		w.partSize *= 2
+	}
+	return nil
+}
+
+// RemoteOutput is the storage side of a backup streamed to object storage
+// with --output s3://bucket/prefix. The object key mirrors the path the
+// backup would have below the output directory, so the local manifest and
+// catalog reconcile find it under the same key.
+type RemoteOutput struct {
+	store     *RemoteStore
+	key       string
+	writer    *RemoteWriter
+	checksum  hash.Hash
+	algorithm string
+	out       io.Writer
+}
+
+// OpenRemoteOutput starts the upload of the backup that would be written to
+// outputFile
+func OpenRemoteOutput(options BackupOptions, outputFile string) (*RemoteOutput, error) {
+	store, err := NewOutputStore(options.Output)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(options.OutputDir, outputFile)
+	if err != nil {
+		return nil, err
+	}
+	key := store.Key(rel)
+	writer, err := store.NewWriter(key)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &RemoteOutput{store: store, key: key, writer: writer, out: writer}
+	if options.CalculateChecksum {
+		// Nothing is left on disk to checksum afterwards, so hash the stream
+		if o.checksum, err = fs.NewChecksumHash(options.ChecksumAlgorithm); err != nil {
+			writer.Abort()
+			return nil, err
+		}
+		o.algorithm = options.ChecksumAlgorithm
+		o.out = io.MultiWriter(writer, o.checksum)
+	}
+	lg, _ := logger.Get()
+	lg.Info("Streaming backup to object storage", logger.String("url", store.URL(key)))
+	return o, nil
+}
+
+func (o *RemoteOutput) Write(p []byte) (int, error) {
+	return o.out.Write(p)
+}
+
+// Close completes the upload
+func (o *RemoteOutput) Close() error {
+	return o.writer.Close()
+}
+
+// Abort cancels the upload
+func (o *RemoteOutput) Abort() {
+	o.writer.Abort()
+}
+
+// URL returns the s3:// URL of the backup object
+func (o *RemoteOutput) URL() string {
+	return o.store.URL(o.key)
+}
+
+// Apply records size, checksum and location of the uploaded backup in result
+func (o *RemoteOutput) Apply(result *BackupResult) {
+	result.OutputSize = o.writer.Size()
+	if o.checksum != nil {
+		result.Checksum = fmt.Sprintf("%x", o.checksum.Sum(nil))
+		result.ChecksumAlgorithm = o.algorithm
+	}
+	result.Remote = &RemoteMeta{Key: o.key, Status: RemoteStatusPresent, CheckedAt: time.Now().UTC()}
+}
+
+// UploadManifest copies the manifest next to the backup object
+func (o *RemoteOutput) UploadManifest(options BackupOptions, metaFile string) error {
+	rel, err := filepath.Rel(options.OutputDir, metaFile)
+	if err != nil {
+		return err
+	}
+	return o.store.Upload(metaFile, o.store.Key(rel))
+}
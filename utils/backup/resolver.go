@@ -8,6 +8,7 @@ import (
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/database"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/secrets"
 	"sfDBTools/utils/terminal"
 
 	"github.com/spf13/cobra"
@@ -23,8 +24,19 @@ const (
 	SourceInteractive
 )
 
-// ResolveDatabaseConnection resolves database connection from various sources
+// ResolveDatabaseConnection resolves database connection from various sources;
+// a multi-host config file resolves to its current primary
 func ResolveDatabaseConnection(cmd *cobra.Command) (host string, port int, user, password string, source ConfigurationSource, err error) {
+	return resolveDatabaseConnection(cmd, database.PurposePrimary)
+}
+
+// ResolveBackupConnection resolves the server to back up; a multi-host config
+// file may resolve to a healthy replica, depending on its policy
+func ResolveBackupConnection(cmd *cobra.Command) (host string, port int, user, password string, source ConfigurationSource, err error) {
+	return resolveDatabaseConnection(cmd, database.PurposeBackup)
+}
+
+func resolveDatabaseConnection(cmd *cobra.Command, purpose database.Purpose) (host string, port int, user, password string, source ConfigurationSource, err error) {
 	// Check if --config flag is provided
 	configFile := common.GetStringFlagOrEnv(cmd, "config", "BACKUP_CONFIG", "")
 
@@ -34,7 +46,7 @@ func ResolveDatabaseConnection(cmd *cobra.Command) (host string, port int, user,
 			return "", 0, "", "", SourceConfigFile, fmt.Errorf("invalid config file: %w", err)
 		}
 
-		host, port, user, password, err := common.GetDatabaseConfigFromEncrypted(configFile)
+		host, port, user, password, err := common.GetDatabaseConfigFromEncryptedFor(configFile, purpose)
 		if err != nil {
 			return "", 0, "", "", SourceConfigFile, fmt.Errorf("failed to load config from file: %w", err)
 		}
@@ -54,6 +66,10 @@ func ResolveDatabaseConnection(cmd *cobra.Command) (host string, port int, user,
 		port := common.GetIntFlagOrEnv(cmd, "source_port", "SOURCE_PORT", 3306)
 		user := common.GetStringFlagOrEnv(cmd, "source_user", "SOURCE_USER", "root")
 		password := common.GetStringFlagOrEnv(cmd, "source_password", "SOURCE_PASSWORD", "")
+		user, password, err := secrets.ResolveCredentials(user, password)
+		if err != nil {
+			return "", 0, "", "", SourceFlags, err
+		}
 
 		return host, port, user, password, SourceFlags, nil
 	}
@@ -65,7 +81,7 @@ func ResolveDatabaseConnection(cmd *cobra.Command) (host string, port int, user,
 	}
 
 	if selectedFile != "" {
-		host, port, user, password, err := common.GetDatabaseConfigFromEncrypted(selectedFile)
+		host, port, user, password, err := common.GetDatabaseConfigFromEncryptedFor(selectedFile, purpose)
 		if err != nil {
 			return "", 0, "", "", SourceInteractive, fmt.Errorf("failed to load config from file: %w", err)
 		}
@@ -1,6 +1,7 @@
 package backup_utils
 
 import (
+	"context"
 	"fmt"
 
 	"sfDBTools/internal/logger"
@@ -51,7 +52,7 @@ func ExecuteSelectionBackupWorkflow(
 
 	if workflow.UseInteractiveSelection {
 		// Interactive multi-database selection
-		selectedDatabases, err = info.SelectMultipleDatabasesInteractive(dbConfig)
+		selectedDatabases, err = info.SelectMultipleDatabasesInteractive(cmd.Context(), dbConfig)
 		if err != nil {
 			return fmt.Errorf("failed to select databases interactively: %w", err)
 		}
@@ -89,10 +90,11 @@ func ExecuteSelectionBackupWorkflow(
 
 // ExecuteMultipleSelectionBackup executes backup for multiple databases using standard database backup function
 func ExecuteMultipleSelectionBackup(
+	ctx context.Context,
 	backupConfig *BackupConfig,
 	databases []string,
-	backupFunc func(BackupOptions) (*BackupResult, error),
+	backupFunc BackupFunc,
 	operationType string,
 ) (*MultiBackupResult, error) {
-	return ExecuteMultipleDatabaseBackup(backupConfig, databases, backupFunc, operationType)
+	return ExecuteMultipleDatabaseBackup(ctx, backupConfig, databases, backupFunc, operationType)
 }
@@ -1,12 +1,14 @@
 package backup_utils
 
 import (
+	"context"
 	"fmt"
 
 	"sfDBTools/internal/config"
 	"sfDBTools/internal/logger"
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/database"
+	"sfDBTools/utils/fs"
 
 	"github.com/spf13/cobra"
 )
@@ -20,8 +22,13 @@ func ResolveBackupConfigWithoutDB(cmd *cobra.Command) (*BackupConfig, error) {
 
 	backupConfig := &BackupConfig{}
 
+	// Preset the flags of --profile before they are read
+	if err := ApplyBackupProfile(cmd); err != nil {
+		return nil, err
+	}
+
 	// Resolve database connection using the same logic as backup single
-	host, port, user, password, source, err := ResolveDatabaseConnection(cmd)
+	host, port, user, password, source, err := ResolveBackupConnection(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve database connection: %w", err)
 	}
@@ -31,6 +38,11 @@ func ResolveBackupConfigWithoutDB(cmd *cobra.Command) (*BackupConfig, error) {
 	backupConfig.User = user
 	backupConfig.Password = password
 
+	// Guard the source before anything else connects to it
+	if err := ApplySourceProtect(cmd, database.Config{Host: host, Port: port, User: user, Password: password}); err != nil {
+		return nil, err
+	}
+
 	// Display configuration source using the standard display function
 	var details string
 	configFile := common.GetStringFlagOrEnv(cmd, "config", "BACKUP_CONFIG", "")
@@ -46,21 +58,45 @@ func ResolveBackupConfigWithoutDB(cmd *cobra.Command) (*BackupConfig, error) {
 	backupConfig.Compress = common.GetBoolFlagOrEnv(cmd, "compress", "COMPRESS", defaultCompress)
 	backupConfig.IncludeData = common.GetBoolFlagOrEnv(cmd, "data", "INCLUDE_DATA", defaultIncludeData)
 	backupConfig.Encrypt = common.GetBoolFlagOrEnv(cmd, "encrypt", "ENCRYPT", defaultEncrypt)
+	backupConfig.EncryptionKeyFile = common.GetStringFlagOrEnv(cmd, "encryption-key-file", "SFDB_ENCRYPTION_KEY_FILE", "")
+	if cmd.Flags().Changed("encryption-key-file") {
+		backupConfig.Encrypt = true
+	}
 	backupConfig.Compression = common.GetStringFlagOrEnv(cmd, "compression", "COMPRESSION", defaultCompression)
 	backupConfig.CompressionLevel = common.GetStringFlagOrEnv(cmd, "compression-level", "COMPRESSION_LEVEL", defaultCompressionLevel)
 	backupConfig.VerifyDisk = common.GetBoolFlagOrEnv(cmd, "verify-disk", "VERIFY_DISK", defaultVerifyDisk)
 	backupConfig.RetentionDays = common.GetIntFlagOrEnv(cmd, "retention-days", "RETENTION_DAYS", defaultRetentionDays)
 	backupConfig.CalculateChecksum = common.GetBoolFlagOrEnv(cmd, "calculate-checksum", "CALCULATE_CHECKSUM", defaultCalculateChecksum)
+	backupConfig.ChecksumAlgorithm = common.GetStringFlagOrEnv(cmd, "checksum-algorithm", "CHECKSUM_ALGORITHM", fs.ChecksumSHA256)
+	if err := fs.ValidateChecksumAlgorithm(backupConfig.ChecksumAlgorithm); err != nil {
+		return nil, common.WithExitCode(err, common.ExitUsage)
+	}
 
 	if backupConfig.Compression == "" && backupConfig.Compress {
 		backupConfig.Compression = "gzip"
 	}
+	ApplyAutoCompression(cmd, backupConfig)
+	if backupConfig.RecentPartitions, err = resolveRecentPartitions(cmd); err != nil {
+		return nil, err
+	}
+	if backupConfig.DumpArgs, err = resolveDumpArgs(cmd); err != nil {
+		return nil, err
+	}
+	if backupConfig.Parallel, err = resolveParallel(cmd); err != nil {
+		return nil, err
+	}
+	if backupConfig.Output, err = resolveOutput(cmd); err != nil {
+		return nil, err
+	}
+	if err := resolveEngine(cmd, backupConfig); err != nil {
+		return nil, err
+	}
 
 	return backupConfig, nil
 }
 
 // ExecuteSingleBackup executes backup for a single database with all standard operations
-func ExecuteSingleBackup(backupConfig *BackupConfig, databaseName string, backupFunc func(BackupOptions) (*BackupResult, error)) (*BackupResult, error) {
+func ExecuteSingleBackup(ctx context.Context, backupConfig *BackupConfig, databaseName string, backupFunc BackupFunc) (*BackupResult, error) {
 	lg, _ := logger.Get()
 
 	// Set database name for this backup
@@ -71,7 +107,7 @@ func ExecuteSingleBackup(backupConfig *BackupConfig, databaseName string, backup
 	DisplayBackupParameters(options)
 
 	// Perform the backup
-	result, err := backupFunc(options)
+	result, err := backupFunc(ctx, options)
 	if err != nil {
 		lg.Error("Backup operation failed for database", logger.String("database", databaseName), logger.Error(err))
 		return nil, fmt.Errorf("backup failed for %s: %w", databaseName, err)
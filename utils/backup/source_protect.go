@@ -0,0 +1,87 @@
+package backup_utils
+
+import (
+	"fmt"
+	"strings"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+
+	"github.com/spf13/cobra"
+)
+
+// sourceModifyingDumpArgs are mysqldump options that change the server being dumped
+var sourceModifyingDumpArgs = []string{
+	"--flush-logs",         // rotates the binary logs
+	"--delete-master-logs", // purges the binary logs
+	"--delete-source-logs",
+	"--dump-slave", // stops and restarts the replica SQL thread
+	"--dump-replica",
+}
+
+// AddSourceProtectFlags adds the flags of the read-only guard for the source server
+func AddSourceProtectFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("source-protect", false, "guarantee the source is never written: read-only sessions, source-modifying options refused")
+	cmd.Flags().Bool("source-protect-set", true, "with --source-protect, set SESSION TRANSACTION READ ONLY instead of requiring a read-only session")
+}
+
+// SourceProtectEnabled reports whether --source-protect (SOURCE_PROTECT) is on
+func SourceProtectEnabled(cmd *cobra.Command) bool {
+	if cmd.Flags().Lookup("source-protect") == nil {
+		return false
+	}
+	return common.GetBoolFlagOrEnv(cmd, "source-protect", "SOURCE_PROTECT", false)
+}
+
+// ApplySourceProtect guards the source when --source-protect is set: the
+// configured mysqldump options may not modify it, every connection opened to
+// it is read-only and refuses writing statements, and one such connection is
+// opened right away so an unprotectable source fails before any work starts
+func ApplySourceProtect(cmd *cobra.Command, source database.Config) error {
+	if !SourceProtectEnabled(cmd) {
+		return nil
+	}
+	lg, _ := logger.Get()
+
+	if cfg, err := config.Get(); err == nil && cfg != nil {
+		for _, args := range []string{cfg.Mysqldump.Args, cfg.Backup.MysqldumpArgs} {
+			if err := checkDumpArgs(args); err != nil {
+				return common.WithExitCode(err, common.ExitConfig)
+			}
+		}
+	}
+
+	opts := database.ProtectOptions{
+		SetReadOnly: common.GetBoolFlagOrEnv(cmd, "source-protect-set", "SOURCE_PROTECT_SET", true),
+	}
+	database.ProtectEndpoint(source.Host, source.Port, opts)
+	if err := database.VerifyProtected(source); err != nil {
+		return fmt.Errorf("source protection failed: %w", err)
+	}
+
+	lg.Info("Source protection enabled",
+		logger.String("host", source.Host),
+		logger.Int("port", source.Port),
+		logger.Bool("set_read_only", opts.SetReadOnly))
+	fmt.Printf("🔒 Source %s:%d is protected: read-only session, writes are refused\n", source.Host, source.Port)
+	return nil
+}
+
+// checkDumpArgs refuses mysqldump options that would modify the source
+func checkDumpArgs(args string) error {
+	for _, arg := range common.ParseArgsString(args) {
+		name := strings.SplitN(arg, "=", 2)[0]
+		for _, modifying := range sourceModifyingDumpArgs {
+			if name == modifying {
+				return fmt.Errorf("mysqldump option %s modifies the source and is refused with --source-protect; remove it from mysqldump.args or backup.mysqldump_args in config.yaml", arg)
+			}
+		}
+		// -F is --flush-logs, also inside a group of short options such as -CFq
+		if !strings.HasPrefix(arg, "--") && strings.HasPrefix(arg, "-") && strings.Contains(arg, "F") {
+			return fmt.Errorf("mysqldump option %s includes -F (--flush-logs), which modifies the source and is refused with --source-protect", arg)
+		}
+	}
+	return nil
+}
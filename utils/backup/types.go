@@ -1,62 +1,120 @@
 package backup_utils
 
-import "time"
+import (
+	"context"
+	"time"
+)
+
+// BackupFunc backs up the database options.DBName; ctx stops it
+type BackupFunc func(ctx context.Context, options BackupOptions) (*BackupResult, error)
 
 // BackupOptions represents the configuration for a single database backup
 type BackupOptions struct {
-	Host              string
-	Port              int
-	User              string
-	Password          string
-	DBName            string
-	OutputDir         string
-	Compress          bool
-	Compression       string
-	CompressionLevel  string
-	IncludeData       bool
-	Encrypt           bool
-	VerifyDisk        bool
-	RetentionDays     int
-	CalculateChecksum bool
-	IncludeSystem     bool
-	SystemUsers       bool
-	Background        bool
+	Host               string
+	Port               int
+	User               string
+	Password           string
+	DBName             string
+	OutputDir          string
+	Compress           bool
+	Compression        string
+	CompressionLevel   string
+	IncludeData        bool
+	Encrypt            bool
+	VerifyDisk         bool
+	RetentionDays      int
+	CalculateChecksum  bool
+	ChecksumAlgorithm  string // fs.ChecksumSHA256 (default), fs.ChecksumSHA512, fs.ChecksumMD5 or fs.ChecksumXXH64
+	IncludeSystem      bool
+	SystemUsers        bool
+	Background         bool
+	RecentPartitions   map[string]int // table -> number of recent partitions to dump; other rows are skipped
+	DumpArgs           []string       // validated --dump-arg options passed through to mysqldump
+	EncryptionPassword string         // used instead of prompting when set
+	EncryptionKeyFile  string         // key file to encrypt with instead of a password
+	Output             string         // s3://bucket/prefix streams the dump to object storage instead of OutputDir
+	Engine             string         // EngineMysqldump (default) or EngineMydumper
+	EngineThreads      int            // mydumper threads; 0 for backup.mydumper.threads or the number of CPUs
 }
 
 // BackupResult represents the result of a backup operation
 type BackupResult struct {
-	Success         bool
-	OutputFile      string
-	BackupMetaFile  string
-	OutputSize      int64
-	CompressionUsed string
-	Encrypted       bool
-	IncludedData    bool
-	Duration        time.Duration
-	AverageSpeed    float64
-	Checksum        string
-	Error           error
+	Success           bool              `json:"success"`
+	OutputFile        string            `json:"output_file"`
+	BackupMetaFile    string            `json:"meta_file,omitempty"`
+	OutputSize        int64             `json:"output_size"`
+	CompressionUsed   string            `json:"compression,omitempty"`
+	Encrypted         bool              `json:"encrypted"`
+	IncludedData      bool              `json:"included_data"`
+	Duration          time.Duration     `json:"duration"`
+	AverageSpeed      float64           `json:"average_speed"`
+	Checksum          string            `json:"checksum,omitempty"`
+	ChecksumAlgorithm string            `json:"checksum_algorithm,omitempty"`
+	PartialTables     map[string]string `json:"partial_tables,omitempty"` // table -> WHERE clause used when only recent partitions were dumped
+	Remote            *RemoteMeta       `json:"remote,omitempty"`         // set when the dump was streamed to object storage
+	Error             error             `json:"-"`
 }
 
 // BackupMetadata represents metadata about the backup
 type BackupMetadata struct {
-	DatabaseName    string            `json:"database_name"`
-	BackupDate      time.Time         `json:"backup_date"`
-	BackupType      string            `json:"backup_type"`
-	OutputFile      string            `json:"output_file"`
-	FileSize        int64             `json:"file_size"`
-	Compressed      bool              `json:"compressed"`
-	CompressionType string            `json:"compression_type,omitempty"`
-	Encrypted       bool              `json:"encrypted"`
-	IncludesData    bool              `json:"includes_data"`
-	Duration        string            `json:"duration"`
-	Checksum        string            `json:"checksum,omitempty"`
-	Host            string            `json:"host"`
-	Port            int               `json:"port"`
-	User            string            `json:"user"`
-	MySQLVersion    string            `json:"mariadb_version,omitempty"`
-	DatabaseInfo    *DatabaseInfoMeta `json:"database_info,omitempty"`
-	ReplicationInfo *ReplicationMeta  `json:"replication_info,omitempty"`
+	SchemaVersion     int               `json:"schema_version"`
+	DatabaseName      string            `json:"database_name"`
+	BackupDate        time.Time         `json:"backup_date"`
+	BackupType        string            `json:"backup_type"`
+	OutputFile        string            `json:"output_file"`
+	FileSize          int64             `json:"file_size"`
+	Compressed        bool              `json:"compressed"`
+	CompressionType   string            `json:"compression_type,omitempty"`
+	Encrypted         bool              `json:"encrypted"`
+	IncludesData      bool              `json:"includes_data"`
+	Duration          string            `json:"duration"`
+	Checksum          string            `json:"checksum,omitempty"`
+	ChecksumAlgorithm string            `json:"checksum_algorithm,omitempty"` // sha256 when empty
+	Host              string            `json:"host"`
+	Port              int               `json:"port"`
+	User              string            `json:"user"`
+	MySQLVersion      string            `json:"mariadb_version,omitempty"`
+	DatabaseInfo      *DatabaseInfoMeta `json:"database_info,omitempty"`
+	ReplicationInfo   *ReplicationMeta  `json:"replication_info,omitempty"`
+	PartialTables     map[string]string `json:"partial_tables,omitempty"`
+	Databases         []string          `json:"databases,omitempty"`     // members of a consistency group backup
+	SnapshotMode      string            `json:"snapshot_mode,omitempty"` // single_transaction or lock_all_tables
+	Remote            *RemoteMeta       `json:"remote,omitempty"`        // last catalog reconcile against remote storage
+	DumpArgs          []string          `json:"dump_args,omitempty"`     // --dump-arg options the dump was taken with
+	Snapshot          *SnapshotMeta     `json:"snapshot,omitempty"`      // filesystem snapshot a physical backup was archived from
+	Pinned            bool              `json:"pinned,omitempty"`        // excluded from retention pruning
+	PinReason         string            `json:"pin_reason,omitempty"`
+	Engine            string            `json:"engine,omitempty"` // dump engine; mysqldump when empty
+	Physical          *PhysicalMeta     `json:"physical,omitempty"`
+}
+
+// PhysicalMeta describes a mariabackup/xtrabackup backup and its place in an
+// incremental chain
+type PhysicalMeta struct {
+	Tool         string `json:"tool"`                    // mariabackup or xtrabackup
+	Incremental  bool   `json:"incremental"`             // holds only the changes since BaseManifest
+	BaseManifest string `json:"base_manifest,omitempty"` // manifest of the backup the deltas apply to
+	FromLSN      uint64 `json:"from_lsn"`
+	ToLSN        uint64 `json:"to_lsn"`
+	DataDir      string `json:"datadir,omitempty"`
+}
+
+// SnapshotMeta describes the filesystem snapshot of a physical backup
+type SnapshotMeta struct {
+	Filesystem   string `json:"filesystem"` // lvm or zfs
+	Volume       string `json:"volume"`     // logical volume or dataset holding the datadir
+	Name         string `json:"name"`
+	DataDir      string `json:"datadir"`
+	LockMode     string `json:"lock_mode"` // backup-stage or ftwrl
+	LockDuration string `json:"lock_duration"`
+}
+
+// RemoteMeta records where the backup lives in remote storage and what the
+// last reconcile found there
+type RemoteMeta struct {
+	Key       string    `json:"key"`
+	Status    string    `json:"status"`
+	CheckedAt time.Time `json:"checked_at"`
 }
 
 // ReplicationMeta represents replication information in metadata
@@ -69,6 +127,9 @@ type ReplicationMeta struct {
 	LogFile      string `json:"log_file,omitempty"`
 	LogPosition  int64  `json:"log_position,omitempty"`
 	GTIDPosition string `json:"gtid_position,omitempty"` // From BINLOG_GTID_POS function
+	// Bookmark is the exact position the dump is consistent with; the fields
+	// above are read before the dump starts
+	Bookmark *BinlogBookmark `json:"bookmark,omitempty"`
 }
 
 // DatabaseInfoMeta represents database information in metadata
@@ -1,32 +1,16 @@
 package backup_utils
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"io"
+	"fmt"
 	"os"
 	"sfDBTools/internal/logger"
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/fs"
+	"sfDBTools/utils/jobstatus"
+	"strings"
 	"time"
 )
 
-// CalculateChecksum calculates SHA256 checksum of the backup file
-func CalculateChecksum(filename string) (string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return "", err
-	}
-
-	return hex.EncodeToString(hash.Sum(nil)), nil
-}
-
 // initializeBackupResult creates and initializes a backup result structure
 func InitializeBackupResult(options BackupOptions) *BackupResult {
 	return &BackupResult{
@@ -39,7 +23,7 @@ func InitializeBackupResult(options BackupOptions) *BackupResult {
 // setupBackupPaths generates output file paths and creates directories
 func SetupBackupPaths(options BackupOptions) (string, string, error) {
 	// Create output directory
-	if err := fs.CreateDir(options.OutputDir); err != nil {
+	if err := fs.NewManager().Dir().CreateArtifactDir(options.OutputDir); err != nil {
 		return "", "", err
 	}
 
@@ -52,8 +36,12 @@ func SetupBackupPaths(options BackupOptions) (string, string, error) {
 func FinalizeBackupResult(result *BackupResult, outputFile string, startTime time.Time, options BackupOptions) error {
 	lg, _ := logger.Get()
 
+	// A streamed backup has no local file; its size and checksum were
+	// recorded while uploading
+	remote := result.Remote != nil
+
 	// Get output file size
-	if stat, err := os.Stat(outputFile); err == nil {
+	if stat, err := os.Stat(outputFile); err == nil && !remote {
 		result.OutputSize = stat.Size()
 	}
 
@@ -64,9 +52,10 @@ func FinalizeBackupResult(result *BackupResult, outputFile string, startTime tim
 	}
 
 	// Calculate checksum if requested
-	if options.CalculateChecksum {
-		if checksum, err := common.CalculateChecksum(outputFile); err == nil {
+	if options.CalculateChecksum && !remote {
+		if checksum, err := FileChecksum(outputFile, options.ChecksumAlgorithm); err == nil {
 			result.Checksum = checksum
+			result.ChecksumAlgorithm = options.ChecksumAlgorithm
 		} else {
 			lg.Warn("Failed to calculate checksum", logger.Error(err))
 		}
@@ -78,3 +67,32 @@ func FinalizeBackupResult(result *BackupResult, outputFile string, startTime tim
 	result.Success = true
 	return nil
 }
+
+// ReportResultDetails emits the facts of a finished backup on its job, for
+// reports built from progress events
+func ReportResultDetails(job *jobstatus.Tracker, result *BackupResult) {
+	if result.Remote != nil {
+		job.Detail("Remote object", result.Remote.Key)
+	} else {
+		job.Detail("Output file", result.OutputFile)
+	}
+	job.Detail("Size", common.FormatSize(result.OutputSize))
+	if result.CompressionUsed != "" {
+		job.Detail("Compression", result.CompressionUsed)
+	}
+	job.Detail("Encrypted", fmt.Sprintf("%t", result.Encrypted))
+	if result.Checksum != "" {
+		job.Detail("Checksum", checksumLabel(result.ChecksumAlgorithm)+" "+result.Checksum)
+	}
+	if len(result.PartialTables) > 0 {
+		job.Detail("Partial tables", fmt.Sprintf("%d (recent partitions only)", len(result.PartialTables)))
+	}
+}
+
+// checksumLabel names the algorithm of a recorded checksum; empty means SHA-256
+func checksumLabel(algorithm string) string {
+	if algorithm == "" {
+		algorithm = fs.ChecksumSHA256
+	}
+	return strings.ToUpper(algorithm)
+}
@@ -41,8 +41,13 @@ func ValidateAndPrepareBackup(options BackupOptions) error {
 		return err
 	}
 
-	// Check disk space if required (using default 1GB minimum)
-	if options.VerifyDisk {
+	if err := PreflightBackupPrivileges(config, []string{options.DBName}); err != nil {
+		return err
+	}
+
+	// Check disk space if required (using default 1GB minimum); a dump
+	// streamed to object storage does not land on the output disk
+	if options.VerifyDisk && options.Output == "" {
 		if err := disk.CheckDiskSpace(options.OutputDir, 1024); err != nil { // 1GB default
 			return err
 		}
@@ -1,13 +1,13 @@
 package backup_restore_utils
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"strings"
 
+	"sfDBTools/internal/core/seed"
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/terminal"
 
 	"github.com/spf13/cobra"
 )
@@ -28,6 +28,8 @@ type BackupRestoreConfig struct {
 	TargetDB          string
 	TargetDmartDB     string
 	Users             []string
+	SeedDir           string            // fixtures applied to TargetDB after the restore
+	SeedVars          map[string]string // extra template variables for the fixtures
 }
 
 // ResolveBackupRestoreConfig resolves backup restore configuration from various sources
@@ -50,7 +52,12 @@ func ResolveBackupRestoreConfig(cmd *cobra.Command) (*BackupRestoreConfig, error
 	// Get encryption flag
 	config.Encrypt, _ = cmd.Flags().GetBool("encrypt")
 	config.DryRun, _ = cmd.Flags().GetBool("dry-run")
-	config.SkipConfirmation, _ = cmd.Flags().GetBool("yes")
+	config.SkipConfirmation = terminal.AssumeYes()
+	config.SeedDir, _ = cmd.Flags().GetString("seed-dir")
+	rawVars, _ := cmd.Flags().GetStringArray("var")
+	if config.SeedVars, err = seed.ParseVars(rawVars); err != nil {
+		return nil, err
+	}
 
 	// Resolve database connection from config or flags
 	configFile, _ := cmd.Flags().GetString("config")
@@ -125,6 +132,9 @@ func DisplayBackupRestoreConfig(config *BackupRestoreConfig) {
 	fmt.Printf("Target DB:            %s\n", config.TargetDB)
 	fmt.Printf("Target Dmart DB:      %s\n", config.TargetDmartDB)
 	fmt.Printf("Users:                %s\n", strings.Join(config.Users, ", "))
+	if config.SeedDir != "" {
+		fmt.Printf("Seed Fixtures:        %s\n", config.SeedDir)
+	}
 	fmt.Printf("Encryption:           %t\n", config.Encrypt)
 	fmt.Printf("Dry Run:              %t\n", config.DryRun)
 	fmt.Printf("====================================\n\n")
@@ -150,16 +160,7 @@ func PromptBackupRestoreConfirmation(config *BackupRestoreConfig) error {
 		fmt.Printf("🔍 DRY RUN MODE: No actual changes will be made\n\n")
 	}
 
-	fmt.Printf("Do you want to continue with the backup restore operation? [y/N]: ")
-
-	reader := bufio.NewReader(os.Stdin)
-	response, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read confirmation: %w", err)
-	}
-
-	response = strings.ToLower(strings.TrimSpace(response))
-	if response != "y" && response != "yes" {
+	if !terminal.Confirm("Do you want to continue with the backup restore operation?", false) {
 		return fmt.Errorf("operation cancelled by user")
 	}
 
@@ -1,6 +1,7 @@
 package backup_restore_utils
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -10,15 +11,17 @@ import (
 	backup_single_mysqldump "sfDBTools/internal/core/backup/single/mysqldump"
 	restore_single "sfDBTools/internal/core/restore/single"
 	restoreUtils "sfDBTools/internal/core/restore/utils"
+	"sfDBTools/internal/core/seed"
 	"sfDBTools/internal/logger"
 	backup_utils "sfDBTools/utils/backup"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/database"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
 // ExecuteBackupRestoreProduction executes the complete backup restore production flow
-func ExecuteBackupRestoreProduction(options *BackupRestoreConfig) error {
+func ExecuteBackupRestoreProduction(ctx context.Context, options *BackupRestoreConfig) error {
 	lg, err := logger.Get()
 	if err != nil {
 		return fmt.Errorf("failed to get logger: %w", err)
@@ -76,10 +79,17 @@ func ExecuteBackupRestoreProduction(options *BackupRestoreConfig) error {
 	}
 
 	// Step 5: Backup and restore production databases
-	if err := backupAndRestoreDatabases(options, cfg, dbConfig); err != nil {
+	if err := backupAndRestoreDatabases(ctx, options, cfg, dbConfig); err != nil {
 		return fmt.Errorf("backup and restore failed: %w", err)
 	}
 
+	// Step 6: Seed training/demo fixtures into the restored target
+	if options.SeedDir != "" {
+		if err := seedTargetDatabase(options, dbConfig); err != nil {
+			return fmt.Errorf("seeding %s failed: %w", options.TargetDB, err)
+		}
+	}
+
 	duration := time.Since(startTime)
 	lg.Info("Backup restore production completed successfully",
 		logger.String("duration", duration.String()))
@@ -110,6 +120,40 @@ func executeDryRun(options *BackupRestoreConfig, dbConfig database.Config) error
 		logger.String("dmart_from", options.ProductionDmartDB),
 		logger.String("dmart_to", options.TargetDmartDB))
 
+	if options.SeedDir != "" {
+		lg.Info("DRY RUN: Would apply seed fixtures",
+			logger.String("dir", options.SeedDir),
+			logger.String("target_db", options.TargetDB))
+	}
+
+	return nil
+}
+
+// seedTargetDatabase applies the seed fixtures to the target database. The
+// restore replaced the tracking table with production's (normally none), so
+// every fixture is applied to the fresh copy.
+func seedTargetDatabase(options *BackupRestoreConfig, dbConfig database.Config) error {
+	lg, _ := logger.Get()
+
+	vars := map[string]string{"account": options.Account, "target": options.Target}
+	for k, v := range options.SeedVars {
+		vars[k] = v
+	}
+	dbConfig.DBName = options.TargetDB
+	fixtures, err := seed.Apply(seed.Options{DB: dbConfig, Dir: options.SeedDir, Vars: vars, ReapplyChanged: true})
+	if err != nil {
+		return err
+	}
+	applied := 0
+	for _, f := range fixtures {
+		if f.Status == seed.StatusApplied {
+			applied++
+		}
+	}
+	lg.Info("Seed fixtures applied",
+		logger.String("target_db", options.TargetDB),
+		logger.Int("applied", applied),
+		logger.Int("total", len(fixtures)))
 	return nil
 }
 
@@ -255,14 +299,14 @@ func checkUserAndGrantPrivileges(username string, options *BackupRestoreConfig,
 }
 
 // backupAndRestoreDatabases performs the backup and restore operations
-func backupAndRestoreDatabases(options *BackupRestoreConfig, cfg *model.Config, dbConfig database.Config) error {
+func backupAndRestoreDatabases(ctx context.Context, options *BackupRestoreConfig, cfg *model.Config, dbConfig database.Config) error {
 	// Backup and restore main database
-	if err := backupAndRestoreDatabase(options.ProductionDB, options.TargetDB, options, cfg, dbConfig); err != nil {
+	if err := backupAndRestoreDatabase(ctx, options.ProductionDB, options.TargetDB, options, cfg, dbConfig); err != nil {
 		return fmt.Errorf("failed to backup/restore main database: %w", err)
 	}
 
 	// Backup and restore dmart database
-	if err := backupAndRestoreDatabase(options.ProductionDmartDB, options.TargetDmartDB, options, cfg, dbConfig); err != nil {
+	if err := backupAndRestoreDatabase(ctx, options.ProductionDmartDB, options.TargetDmartDB, options, cfg, dbConfig); err != nil {
 		return fmt.Errorf("failed to backup/restore dmart database: %w", err)
 	}
 
@@ -270,7 +314,7 @@ func backupAndRestoreDatabases(options *BackupRestoreConfig, cfg *model.Config,
 }
 
 // backupAndRestoreDatabase performs backup and restore for a single database
-func backupAndRestoreDatabase(sourceDB, targetDB string, options *BackupRestoreConfig, cfg *model.Config, dbConfig database.Config) error {
+func backupAndRestoreDatabase(ctx context.Context, sourceDB, targetDB string, options *BackupRestoreConfig, cfg *model.Config, dbConfig database.Config) error {
 	lg, _ := logger.Get()
 
 	lg.Info("Starting backup and restore operation",
@@ -298,7 +342,7 @@ func backupAndRestoreDatabase(sourceDB, targetDB string, options *BackupRestoreC
 	}
 
 	lg.Info("Backing up source database", logger.String("database", sourceDB))
-	result, err := backup_single_mysqldump.BackupSingle(backupOptions)
+	result, err := backup_single_mysqldump.BackupSingle(ctx, backupOptions)
 	if err != nil {
 		return fmt.Errorf("backup failed for %s: %w", sourceDB, err)
 	}
@@ -310,7 +354,7 @@ func backupAndRestoreDatabase(sourceDB, targetDB string, options *BackupRestoreC
 	lg.Info("Backup completed successfully",
 		logger.String("source", sourceDB),
 		logger.String("file", result.OutputFile),
-		logger.String("size", fmt.Sprintf("%.2f MB", float64(result.OutputSize)/(1024*1024))))
+		logger.String("size", common.HumanizeSize(result.OutputSize)))
 
 	// Step 2: Restore to target database
 	restoreOptions := restoreUtils.RestoreOptions{
@@ -323,7 +367,7 @@ func backupAndRestoreDatabase(sourceDB, targetDB string, options *BackupRestoreC
 	}
 
 	lg.Info("Restoring to target database", logger.String("database", targetDB))
-	if err := restore_single.RestoreSingle(restoreOptions); err != nil {
+	if err := restore_single.RestoreSingle(ctx, restoreOptions); err != nil {
 		return fmt.Errorf("restore failed for %s: %w", targetDB, err)
 	}
 
@@ -0,0 +1,136 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// clientOption describes an option the user may pass through to a client tool
+type clientOption struct {
+	value bool // takes a value, given as --name=value
+}
+
+// dumpArgAllowlist lists the mysqldump/mariadb-dump options accepted by
+// --dump-arg. Connection, credential, output-file and source-modifying
+// options are managed by sfDBTools and are not passed through.
+var dumpArgAllowlist = map[string]clientOption{
+	"--add-drop-database":     {},
+	"--add-drop-table":        {},
+	"--skip-add-drop-table":   {},
+	"--add-drop-trigger":      {},
+	"--add-locks":             {},
+	"--skip-add-locks":        {},
+	"--allow-keywords":        {},
+	"--column-statistics":     {value: true},
+	"--comments":              {},
+	"--skip-comments":         {},
+	"--compact":               {},
+	"--complete-insert":       {},
+	"--compress":              {},
+	"--create-options":        {},
+	"--skip-create-options":   {},
+	"--default-character-set": {value: true},
+	"--disable-keys":          {},
+	"--skip-disable-keys":     {},
+	"--dump-date":             {},
+	"--skip-dump-date":        {},
+	"--events":                {},
+	"--skip-events":           {},
+	"--extended-insert":       {},
+	"--skip-extended-insert":  {},
+	"--hex-blob":              {},
+	"--ignore-table":          {value: true},
+	"--ignore-table-data":     {value: true},
+	"--insert-ignore":         {},
+	"--max-allowed-packet":    {value: true},
+	"--net-buffer-length":     {value: true},
+	"--no-create-db":          {},
+	"--no-create-info":        {},
+	"--no-tablespaces":        {},
+	"--order-by-primary":      {},
+	"--quick":                 {},
+	"--quote-names":           {},
+	"--skip-quote-names":      {},
+	"--replace":               {},
+	"--routines":              {},
+	"--skip-routines":         {},
+	"--set-charset":           {},
+	"--skip-set-charset":      {},
+	"--single-transaction":    {},
+	"--skip-lock-tables":      {},
+	"--triggers":              {},
+	"--skip-triggers":         {},
+	"--tz-utc":                {},
+	"--skip-tz-utc":           {},
+	"--where":                 {value: true},
+}
+
+// restoreArgAllowlist lists the mysql/mariadb client options accepted by
+// --restore-arg. Session settings go through --set instead of --init-command.
+var restoreArgAllowlist = map[string]clientOption{
+	"--binary-mode":            {},
+	"--comments":               {},
+	"--skip-comments":          {},
+	"--compress":               {},
+	"--connect-timeout":        {value: true},
+	"--default-character-set":  {value: true},
+	"--max-allowed-packet":     {value: true},
+	"--net-buffer-length":      {value: true},
+	"--show-warnings":          {},
+	"--sigint-ignore":          {},
+	"--ssl":                    {},
+	"--ssl-ca":                 {value: true},
+	"--ssl-cert":               {value: true},
+	"--ssl-key":                {value: true},
+	"--ssl-verify-server-cert": {},
+}
+
+// ValidateDumpArgs checks --dump-arg values against the mysqldump allowlist
+func ValidateDumpArgs(args []string) error {
+	return validateClientArgs("--dump-arg", "mysqldump", args, dumpArgAllowlist)
+}
+
+// ValidateRestoreArgs checks --restore-arg values against the mysql client allowlist
+func ValidateRestoreArgs(args []string) error {
+	return validateClientArgs("--restore-arg", "mysql", args, restoreArgAllowlist)
+}
+
+// validateClientArgs accepts only long options from allowed, with a value
+// when the option takes one; switches may be given as --name=true/false
+func validateClientArgs(flag, tool string, args []string, allowed map[string]clientOption) error {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			return fmt.Errorf("invalid %s %q: give %s options in long form, e.g. --max-allowed-packet=1G", flag, arg, tool)
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		opt, ok := allowed[name]
+		if !ok {
+			return fmt.Errorf("%s option %s is not allowed with %s (allowed: %s)", tool, name, flag, strings.Join(allowedNames(allowed), ", "))
+		}
+		if opt.value && !hasValue {
+			return fmt.Errorf("invalid %s %q: %s needs a value, e.g. %s=VALUE", flag, arg, name, name)
+		}
+		if !opt.value && hasValue && !isBoolOptionValue(value) {
+			return fmt.Errorf("invalid %s %q: %s only takes a boolean value", flag, arg, name)
+		}
+	}
+	return nil
+}
+
+func allowedNames(allowed map[string]clientOption) []string {
+	names := make([]string, 0, len(allowed))
+	for name := range allowed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isBoolOptionValue(v string) bool {
+	switch strings.ToLower(v) {
+	case "0", "1", "true", "false", "on", "off":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package common
+
+import "errors"
+
+// Process exit codes. Scripts and schedulers can tell a bad invocation or a
+// broken configuration apart from an operation that failed.
+const (
+	ExitOK          = 0
+	ExitFailure     = 1   // the operation failed
+	ExitUsage       = 2   // invalid flags, arguments or subcommand
+	ExitConfig      = 3   // config.yaml is missing or invalid
+	ExitCancelled   = 4   // the user declined a confirmation
+	ExitWindow      = 5   // refused or aborted by the maintenance window
+	ExitInternal    = 70  // unexpected panic; details are in the log
+	ExitInterrupted = 130 // stopped by SIGINT or SIGTERM (128+SIGINT, as shells report it)
+)
+
+// ExitError attaches an exit code to an error returned by a command
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// WithExitCode wraps err so the process exits with code; nil stays nil
+func WithExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode returns the exit code for err: ExitOK for nil, the attached code
+// for an ExitError, ExitFailure otherwise
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitFailure
+}
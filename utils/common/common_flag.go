@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"strings"
 
+	"sfDBTools/utils/terminal"
+
 	"github.com/spf13/cobra"
 )
 
@@ -48,3 +50,30 @@ func GetBoolFlagOrEnv(cmd *cobra.Command, flagName, envName string, defaultVal b
 	}
 	return defaultVal
 }
+
+// GetStringArrayFlagOrEnv returns the values of a repeatable flag, or the
+// semicolon-separated environment variable when the flag was not given
+func GetStringArrayFlagOrEnv(cmd *cobra.Command, flagName, envName string) []string {
+	if values, _ := cmd.Flags().GetStringArray(flagName); len(values) > 0 {
+		return values
+	}
+	var values []string
+	for _, v := range strings.Split(os.Getenv(envName), ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// GetTableOptions reads the flags added by flags.AddTableFlags
+func GetTableOptions(cmd *cobra.Command) terminal.TableOptions {
+	opts := terminal.TableOptions{}
+	opts.SortBy, _ = cmd.Flags().GetString("sort")
+	opts.Descending, _ = cmd.Flags().GetBool("desc")
+	opts.Page, _ = cmd.Flags().GetInt("page")
+	opts.PageSize, _ = cmd.Flags().GetInt("page-size")
+	opts.MaxWidth, _ = cmd.Flags().GetInt("max-width")
+	opts.Interactive, _ = cmd.Flags().GetBool("pager")
+	return opts
+}
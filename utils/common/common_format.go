@@ -3,20 +3,18 @@ package common
 import (
 	"fmt"
 	"math"
-	"sfDBTools/utils/common/format"
 	"strconv"
 	"strings"
 )
 
-// Size formatting
+// FormatSize formats bytes for reports; see HumanizeSize
 func FormatSize(bytes int64) string {
-	format := format.FormatSize(uint64(bytes), format.SizeBinary)
-	return format
+	return HumanizeSize(bytes)
 }
 
+// FormatSpeed formats a transfer rate for reports; see HumanizeRate
 func FormatSpeed(bytesPerSecond float64) string {
-	format := format.FormatTransferRate(uint64(bytesPerSecond), format.SizeBinary)
-	return format
+	return HumanizeRate(bytesPerSecond)
 }
 
 // parseMemorySizeToMB mengkonversi string memory size ke MB.
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"sfDBTools/utils/common/format"
+)
+
+// rawUnits switches the humanize helpers to exact numbers for scripts
+var rawUnits atomic.Bool
+
+// SetRawUnits enables or disables raw unit output (bytes, seconds, bytes/s)
+func SetRawUnits(enabled bool) {
+	rawUnits.Store(enabled)
+}
+
+// RawUnits reports whether raw unit output is enabled
+func RawUnits() bool {
+	return rawUnits.Load()
+}
+
+// HumanizeSize formats bytes with binary units ("1.5 GiB"), or as an exact
+// byte count when raw units are enabled
+func HumanizeSize(bytes int64) string {
+	if RawUnits() {
+		return strconv.FormatInt(bytes, 10)
+	}
+	if bytes < 0 {
+		return "-" + format.FormatSizeWithPrecision(-bytes, 1)
+	}
+	return format.FormatSizeWithPrecision(bytes, 1)
+}
+
+// HumanizeDuration formats d as "1h 5m 3s" (sub-second values as "350ms"),
+// or as exact seconds when raw units are enabled
+func HumanizeDuration(d time.Duration) string {
+	if RawUnits() {
+		return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+	}
+	if d < 0 {
+		return "-" + HumanizeDuration(-d)
+	}
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	switch {
+	case h > 0:
+		return fmt.Sprintf("%dh %dm %ds", h, m, s)
+	case m > 0:
+		return fmt.Sprintf("%dm %ds", m, s)
+	default:
+		return fmt.Sprintf("%ds", s)
+	}
+}
+
+// HumanizeRate formats a throughput as "12.3 MiB/s", or as exact bytes per
+// second when raw units are enabled
+func HumanizeRate(bytesPerSecond float64) string {
+	if RawUnits() {
+		return strconv.FormatFloat(bytesPerSecond, 'f', 0, 64)
+	}
+	return HumanizeSize(int64(bytesPerSecond)) + "/s"
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"time"
+
+	"sfDBTools/internal/config"
+)
+
+// displayLayout is used for timestamps shown in tables and summaries
+const displayLayout = "2006-01-02 15:04 MST"
+
+// Location returns the timezone configured in general.locale.timezone,
+// falling back to the server's local zone when unset or invalid
+func Location() *time.Location {
+	cfg, err := config.Get()
+	if err != nil || cfg == nil || cfg.General.Locale.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(cfg.General.Locale.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// LocalNow returns the current time in the configured timezone. Use it for
+// file and directory names so they do not depend on the server's TZ.
+func LocalNow() time.Time {
+	return time.Now().In(Location())
+}
+
+// HumanizeTime formats a stored timestamp for display in the configured
+// timezone, or as RFC 3339 UTC when raw units are enabled. Zero times are "-".
+func HumanizeTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if RawUnits() {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return t.In(Location()).Format(displayLayout)
+}
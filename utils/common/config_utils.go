@@ -1,15 +1,16 @@
 package common
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
 	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/database/connection"
+	"sfDBTools/utils/secrets"
 	"sfDBTools/utils/terminal"
 )
 
@@ -48,33 +49,21 @@ func SelectConfigFileInteractive() (string, error) {
 
 	if len(encFiles) == 0 {
 		fmt.Println("❌ No encrypted configuration files found.")
-		fmt.Println("   Use 'config generate' to create one.")
+		fmt.Println("   Use 'sfDBTools profile create <name>' to create one.")
 		return "", fmt.Errorf("no encrypted configuration files found")
 	}
 
-	// Display available files
-	terminal.PrintSubHeader("Available Encrypted Configuration Files:")
+	// Let user choose; only the filename is shown
+	options := make([]string, len(encFiles))
 	for i, file := range encFiles {
-		// Extract just the filename for display
-		filename := filepath.Base(file)
-		fmt.Printf("   %d. %s\n", i+1, filename)
+		options[i] = filepath.Base(file)
 	}
-
-	// Let user choose
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\nSelect configuration file (1-%d): ", len(encFiles))
-	choice, err := reader.ReadString('\n')
+	index, err := terminal.Select("Available Encrypted Configuration Files:", options, -1)
 	if err != nil {
 		return "", fmt.Errorf("failed to read selection: %w", err)
 	}
 
-	choice = strings.TrimSpace(choice)
-	index, err := strconv.Atoi(choice)
-	if err != nil || index < 1 || index > len(encFiles) {
-		return "", fmt.Errorf("invalid selection: %s", choice)
-	}
-
-	return encFiles[index-1], nil
+	return encFiles[index], nil
 }
 
 // LoadEncryptedConfigFromFile loads and decrypts config from a specific file
@@ -83,8 +72,15 @@ func LoadEncryptedConfigFromFile(filePath, encryptionPassword string) (*config.E
 	return config.LoadEncryptedDatabaseConfigFromFile(filePath, encryptionPassword)
 }
 
-// GetDatabaseConfigFromEncrypted gets database configuration from encrypted file
+// GetDatabaseConfigFromEncrypted gets database configuration from encrypted
+// file; with several hosts configured it returns the current primary
 func GetDatabaseConfigFromEncrypted(configFilePath string) (host string, port int, user, password string, err error) {
+	return GetDatabaseConfigFromEncryptedFor(configFilePath, connection.PurposePrimary)
+}
+
+// GetDatabaseConfigFromEncryptedFor gets database configuration from encrypted
+// file, choosing among its hosts for the given purpose
+func GetDatabaseConfigFromEncryptedFor(configFilePath string, purpose connection.Purpose) (host string, port int, user, password string, err error) {
 	// Get encryption password
 	encryptionPassword, err := crypto.GetEncryptionPassword("Enter encryption password: ")
 	if err != nil {
@@ -97,7 +93,74 @@ func GetDatabaseConfigFromEncrypted(configFilePath string) (host string, port in
 		return "", 0, "", "", fmt.Errorf("failed to load encrypted config: %w", err)
 	}
 
-	return dbConfig.Host, dbConfig.Port, dbConfig.User, dbConfig.Password, nil
+	// Callers only pass host/port/user/password on, so register the socket and
+	// auth plugin for this endpoint where the connection code can find them
+	if dbConfig.Socket != "" || dbConfig.AuthPlugin != "" {
+		if err := connection.ValidateAuthPlugin(dbConfig.AuthPlugin); err != nil {
+			return "", 0, "", "", fmt.Errorf("invalid auth plugin in %s: %w", configFilePath, err)
+		}
+		connection.SetEndpointAuthHint(dbConfig.Host, dbConfig.Port, connection.AuthHint{Socket: dbConfig.Socket, AuthPlugin: dbConfig.AuthPlugin})
+	}
+
+	if len(dbConfig.Hosts) > 0 {
+		if dbConfig.Host, dbConfig.Port, err = selectConfiguredHost(dbConfig, purpose); err != nil {
+			return "", 0, "", "", fmt.Errorf("%s: %w", configFilePath, err)
+		}
+	}
+
+	user, password, err = secrets.ResolveCredentials(dbConfig.User, dbConfig.Password)
+	if err != nil {
+		return "", 0, "", "", fmt.Errorf("failed to resolve credentials of %s: %w", configFilePath, err)
+	}
+	return dbConfig.Host, dbConfig.Port, user, password, nil
+}
+
+// selectConfiguredHost probes Host and Hosts of a multi-host configuration
+// and returns the one its policy picks for purpose. The socket only belongs
+// to Host; the auth plugin applies to every host.
+func selectConfiguredHost(dbConfig *config.EncryptedDatabaseConfig, purpose connection.Purpose) (string, int, error) {
+	candidates := []connection.Endpoint{{Host: dbConfig.Host, Port: dbConfig.Port}}
+	for _, h := range dbConfig.Hosts {
+		port := h.Port
+		if port == 0 {
+			port = dbConfig.Port
+		}
+		candidates = append(candidates, connection.Endpoint{Host: h.Host, Port: port})
+		if dbConfig.AuthPlugin != "" {
+			connection.SetEndpointAuthHint(h.Host, port, connection.AuthHint{AuthPlugin: dbConfig.AuthPlugin})
+		}
+	}
+
+	chosen, health, err := connection.SelectEndpoint(connection.Config{Host: dbConfig.Host, Port: dbConfig.Port, User: dbConfig.User, Password: dbConfig.Password, Socket: dbConfig.Socket}, candidates, dbConfig.Policy, purpose)
+	if err != nil {
+		return "", 0, err
+	}
+	states := make([]string, len(health))
+	for i, h := range health {
+		states[i] = h.String()
+	}
+	lg, _ := logger.Get()
+	lg.Info("Selected database host",
+		logger.String("host", chosen.String()),
+		logger.String("policy", policyName(dbConfig.Policy)),
+		logger.Strings("probed", states))
+	for _, h := range health {
+		if h.Endpoint == chosen {
+			role := "primary"
+			if h.Replica {
+				role = "replica"
+			}
+			fmt.Printf("🔀 Using %s %s (%d hosts probed, policy %s)\n", role, chosen, len(health), policyName(dbConfig.Policy))
+		}
+	}
+	return chosen.Host, chosen.Port, nil
+}
+
+func policyName(policy string) string {
+	if policy == "" {
+		return connection.PolicyFirstAvailable
+	}
+	return policy
 }
 
 // ValidateConfigFile checks if the file path is valid for encrypted config
@@ -5,6 +5,8 @@ import "github.com/spf13/cobra"
 // AddGenerateFlags adds flags specific to the generate command
 func AddGenerateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("encryption-password", "e", "", "Encryption password (or set SFDB_ENCRYPTION_PASSWORD env variable)")
+	cmd.Flags().String("hosts", "", "further hosts of the same topology, comma-separated host[:port] (or set SFDB_DB_HOSTS)")
+	cmd.Flags().String("policy", "", "host policy for multi-host configs: first-available or prefer-replica-for-backup (or set SFDB_DB_POLICY)")
 }
 
 // AddCommonDbConfigFlags adds shared flags used across dbconfig commands
@@ -0,0 +1,14 @@
+package flags
+
+import "github.com/spf13/cobra"
+
+// AddTableFlags adds the sorting, paging and truncation flags of commands
+// that print long tables; read them with common.GetTableOptions
+func AddTableFlags(cmd *cobra.Command) {
+	cmd.Flags().String("sort", "", "sort the table by this column, e.g. size or date")
+	cmd.Flags().Bool("desc", false, "sort in descending order")
+	cmd.Flags().Int("page", 1, "page of the table to print")
+	cmd.Flags().Int("page-size", 0, "rows per page (0 prints every row)")
+	cmd.Flags().Int("max-width", 0, "cut cells longer than this many characters (0 for no limit)")
+	cmd.Flags().Bool("pager", false, "scroll through a long table interactively when attached to a terminal")
+}
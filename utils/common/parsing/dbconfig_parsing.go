@@ -22,5 +22,9 @@ func ParseDBConfigFlags(cmd *cobra.Command) (DBConfig *structs.DBConfig, err err
 	DBConfig.ConnectionOptions.User = common.GetStringFlagOrEnv(cmd, "user", "SFDB_DB_USER", "")
 	DBConfig.EncryptionConfig.EncryptionPassword = common.GetStringFlagOrEnv(cmd, "encryption-password", "SFDB_ENCRYPTION_PASSWORD", "")
 	DBConfig.ConnectionOptions.Password = common.GetStringFlagOrEnv(cmd, "password", "SFDB_DB_PASSWORD", "")
+	DBConfig.ConnectionOptions.Socket = common.GetStringFlagOrEnv(cmd, "socket", "SFDB_DB_SOCKET", "")
+	DBConfig.ConnectionOptions.AuthPlugin = common.GetStringFlagOrEnv(cmd, "auth-plugin", "SFDB_AUTH_PLUGIN", "")
+	DBConfig.ConnectionOptions.Hosts = common.GetStringFlagOrEnv(cmd, "hosts", "SFDB_DB_HOSTS", "")
+	DBConfig.ConnectionOptions.Policy = common.GetStringFlagOrEnv(cmd, "policy", "SFDB_DB_POLICY", "")
 	return DBConfig, nil
 }
@@ -62,6 +62,7 @@ type BackupResult struct {
 
 // BackupMetadata represents metadata about the backup
 type BackupMetadata struct {
+	SchemaVersion   int               `json:"schema_version"`
 	DatabaseName    string            `json:"database_name"`
 	BackupDate      time.Time         `json:"backup_date"`
 	BackupType      string            `json:"backup_type"`
@@ -7,4 +7,10 @@ type ConnectionOptions struct {
 	User     string
 	Password string
 	DBName   string
+	// Socket and AuthPlugin are saved into encrypted configs when set
+	Socket     string
+	AuthPlugin string
+	// Hosts (comma-separated host[:port]) and Policy make a multi-host config
+	Hosts  string
+	Policy string
 }
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/klauspost/compress/zstd"
@@ -20,6 +21,7 @@ const (
 	CompressionNone  CompressionType = "none"
 	CompressionGzip  CompressionType = "gzip"
 	CompressionPgzip CompressionType = "pgzip" // Parallel gzip
+	CompressionPigz  CompressionType = "pigz"  // Parallel gzip through the pigz binary
 	CompressionZlib  CompressionType = "zlib"
 	CompressionZstd  CompressionType = "zstd" // Zstandard
 )
@@ -35,6 +37,21 @@ const (
 	LevelBest      CompressionLevel = "best"
 )
 
+// Numeric returns the level as a number when it was given as one
+// (--compression-level 6), and false for the named levels
+func (l CompressionLevel) Numeric() (int, bool) {
+	n, err := strconv.Atoi(string(l))
+	return n, err == nil
+}
+
+// levelRange is the range of numeric levels a codec accepts
+func levelRange(t CompressionType) (int, int) {
+	if t == CompressionZstd {
+		return 1, 22
+	}
+	return 1, 9
+}
+
 // CompressionConfig holds compression configuration
 type CompressionConfig struct {
 	Type  CompressionType
@@ -58,6 +75,12 @@ func NewCompressingWriter(baseWriter io.Writer, config CompressionConfig) (*Comp
 		}, nil
 	}
 
+	if n, ok := config.Level.Numeric(); ok {
+		if lo, hi := levelRange(config.Type); n < lo || n > hi {
+			return nil, fmt.Errorf("compression level %d is out of range for %s (%d-%d)", n, config.Type, lo, hi)
+		}
+	}
+
 	var compressor io.WriteCloser
 	var err error
 
@@ -66,6 +89,8 @@ func NewCompressingWriter(baseWriter io.Writer, config CompressionConfig) (*Comp
 		compressor, err = createGzipWriter(baseWriter, config.Level)
 	case CompressionPgzip:
 		compressor, err = createPgzipWriter(baseWriter, config.Level)
+	case CompressionPigz:
+		compressor, err = createPigzWriter(baseWriter, config.Level)
 	case CompressionZlib:
 		compressor, err = createZlibWriter(baseWriter, config.Level)
 	case CompressionZstd:
@@ -103,6 +128,9 @@ func (cw *CompressingWriter) Close() error {
 
 // createGzipWriter creates a gzip writer with specified level
 func createGzipWriter(w io.Writer, level CompressionLevel) (*gzip.Writer, error) {
+	if n, ok := level.Numeric(); ok {
+		return gzip.NewWriterLevel(w, n)
+	}
 	var gzipLevel int
 	switch level {
 	case LevelBestSpeed:
@@ -124,6 +152,9 @@ func createGzipWriter(w io.Writer, level CompressionLevel) (*gzip.Writer, error)
 
 // createPgzipWriter creates a parallel gzip writer with specified level
 func createPgzipWriter(w io.Writer, level CompressionLevel) (*pgzip.Writer, error) {
+	if n, ok := level.Numeric(); ok {
+		return pgzip.NewWriterLevel(w, n)
+	}
 	var gzipLevel int
 	switch level {
 	case LevelBestSpeed:
@@ -145,6 +176,9 @@ func createPgzipWriter(w io.Writer, level CompressionLevel) (*pgzip.Writer, erro
 
 // createZlibWriter creates a zlib writer with specified level
 func createZlibWriter(w io.Writer, level CompressionLevel) (*zlib.Writer, error) {
+	if n, ok := level.Numeric(); ok {
+		return zlib.NewWriterLevel(w, n)
+	}
 	var zlibLevel int
 	switch level {
 	case LevelBestSpeed:
@@ -166,6 +200,9 @@ func createZlibWriter(w io.Writer, level CompressionLevel) (*zlib.Writer, error)
 
 // createZstdWriter creates a zstandard writer with specified level
 func createZstdWriter(w io.Writer, level CompressionLevel) (*zstd.Encoder, error) {
+	if n, ok := level.Numeric(); ok {
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(n)))
+	}
 	var zstdLevel zstd.EncoderLevel
 	switch level {
 	case LevelBestSpeed:
@@ -188,7 +225,7 @@ func createZstdWriter(w io.Writer, level CompressionLevel) (*zstd.Encoder, error
 // GetFileExtension returns the appropriate file extension for the compression type
 func GetFileExtension(compressionType CompressionType) string {
 	switch compressionType {
-	case CompressionGzip, CompressionPgzip:
+	case CompressionGzip, CompressionPgzip, CompressionPigz:
 		return ".gz"
 	case CompressionZlib:
 		return ".zlib"
@@ -239,10 +276,10 @@ func CompressFile(inputPath, outputPath string, config CompressionConfig) error
 func ValidateCompressionType(compressionType string) (CompressionType, error) {
 	ct := CompressionType(strings.ToLower(compressionType))
 	switch ct {
-	case CompressionNone, CompressionGzip, CompressionPgzip, CompressionZlib, CompressionZstd:
+	case CompressionNone, CompressionGzip, CompressionPgzip, CompressionPigz, CompressionZlib, CompressionZstd:
 		return ct, nil
 	default:
-		return CompressionNone, fmt.Errorf("unsupported compression type: %s. Supported types: none, gzip, pgzip, zlib, zstd", compressionType)
+		return CompressionNone, fmt.Errorf("unsupported compression type: %s. Supported types: none, gzip, pgzip, pigz, zlib, zstd", compressionType)
 	}
 }
 
@@ -252,9 +289,12 @@ func ValidateCompressionLevel(compressionLevel string) (CompressionLevel, error)
 	switch cl {
 	case LevelBestSpeed, LevelFast, LevelDefault, LevelBetter, LevelBest:
 		return cl, nil
-	default:
-		return LevelDefault, fmt.Errorf("unsupported compression level: %s. Supported levels: best_speed, fast, default, better, best", compressionLevel)
 	}
+	// Numeric levels are checked against the codec by NewCompressingWriter
+	if n, ok := cl.Numeric(); ok && n >= 1 && n <= 22 {
+		return cl, nil
+	}
+	return LevelDefault, fmt.Errorf("unsupported compression level: %s. Supported levels: best_speed, fast, default, better, best, or 1-9 (1-22 for zstd)", compressionLevel)
 }
 
 // GetCompressionInfo returns information about available compression types
@@ -263,6 +303,7 @@ func GetCompressionInfo() map[CompressionType]string {
 		CompressionNone:  "No compression",
 		CompressionGzip:  "Standard gzip compression",
 		CompressionPgzip: "Parallel gzip compression (faster for large files)",
+		CompressionPigz:  "Parallel gzip through pigz, falling back to pgzip when pigz is not installed",
 		CompressionZlib:  "Zlib compression (good compression ratio)",
 		CompressionZstd:  "Zstandard compression (fast and good ratio)",
 	}
@@ -1,20 +1,23 @@
 package compression
 
 import (
-	"compress/gzip"
+	"bufio"
+	"bytes"
 	"compress/zlib"
 	"fmt"
 	"io"
 	"strings"
 
 	"github.com/klauspost/compress/zstd"
+	"github.com/klauspost/pgzip"
 )
 
 // NewDecompressingReader returns a reader that decompresses data from r using the specified compression type.
 func NewDecompressingReader(r io.Reader, ctype CompressionType) (io.ReadCloser, error) {
 	switch ctype {
-	case CompressionGzip, CompressionPgzip:
-		return gzip.NewReader(r)
+	case CompressionGzip, CompressionPgzip, CompressionPigz:
+		// pgzip decompresses ahead of the reader on another goroutine
+		return pgzip.NewReader(r)
 	case CompressionZlib:
 		return zlib.NewReader(r)
 	case CompressionZstd:
@@ -22,7 +25,7 @@ func NewDecompressingReader(r io.Reader, ctype CompressionType) (io.ReadCloser,
 		if err != nil {
 			return nil, err
 		}
-		return io.NopCloser(zr), nil
+		return zr.IOReadCloser(), nil
 	case CompressionNone:
 		return io.NopCloser(r), nil
 	default:
@@ -30,6 +33,42 @@ func NewDecompressingReader(r io.Reader, ctype CompressionType) (io.ReadCloser,
 	}
 }
 
+// NewAutoDecompressingReader detects the compression of r from its first
+// bytes and returns a reader of the decompressed data with the detected type.
+// When the data has no recognised header, fallback (usually the type
+// DetectCompressionTypeFromFile gives) is used, so renamed files and
+// extension-less uploads still restore.
+func NewAutoDecompressingReader(r io.Reader, fallback CompressionType) (io.ReadCloser, CompressionType, error) {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(4)
+	ctype := DetectCompressionType(header)
+	if ctype == CompressionNone {
+		ctype = fallback
+	}
+	dr, err := NewDecompressingReader(br, ctype)
+	return dr, ctype, err
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// DetectCompressionType detects the compression of data from its header
+func DetectCompressionType(header []byte) CompressionType {
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return CompressionGzip
+	case bytes.HasPrefix(header, zstdMagic):
+		return CompressionZstd
+	case len(header) >= 2 && header[0] == 0x78 && bytes.IndexByte([]byte{0x01, 0x5e, 0x9c, 0xda}, header[1]) >= 0:
+		// zlib: deflate with a 32K window and one of the standard levels
+		return CompressionZlib
+	default:
+		return CompressionNone
+	}
+}
+
 // DetectCompressionTypeFromFile detects compression type based on file extension.
 func DetectCompressionTypeFromFile(path string) CompressionType {
 	name := strings.ToLower(path)
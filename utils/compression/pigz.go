@@ -0,0 +1,67 @@
+package compression
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"sfDBTools/utils/shutdown"
+)
+
+// pigzWriter pipes data through an external pigz process. pigz compresses on
+// every core and is usually faster than the in-process pgzip at the same level.
+type pigzWriter struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr strings.Builder
+}
+
+// createPigzWriter starts pigz writing to w, or falls back to pgzip when
+// pigz is not installed; both produce standard .gz output
+func createPigzWriter(w io.Writer, level CompressionLevel) (io.WriteCloser, error) {
+	path, err := exec.LookPath("pigz")
+	if err != nil {
+		return createPgzipWriter(w, level)
+	}
+
+	args := []string{"-c", "-p", strconv.Itoa(runtime.NumCPU())}
+	if n, ok := level.Numeric(); ok {
+		args = append(args, "-"+strconv.Itoa(n))
+	} else {
+		switch level {
+		case LevelBestSpeed, LevelFast:
+			args = append(args, "-1")
+		case LevelBetter, LevelBest:
+			args = append(args, "-9")
+		default:
+			args = append(args, "-6")
+		}
+	}
+
+	pw := &pigzWriter{cmd: shutdown.Command(shutdown.Context(), path, args...)}
+	pw.cmd.Stdout = w
+	pw.cmd.Stderr = &pw.stderr
+	if pw.stdin, err = pw.cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	if err := pw.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start pigz: %w", err)
+	}
+	return pw, nil
+}
+
+func (pw *pigzWriter) Write(p []byte) (int, error) {
+	return pw.stdin.Write(p)
+}
+
+// Close flushes the remaining input and waits for pigz to finish writing
+func (pw *pigzWriter) Close() error {
+	closeErr := pw.stdin.Close()
+	if err := pw.cmd.Wait(); err != nil {
+		return fmt.Errorf("pigz failed: %w: %s", err, strings.TrimSpace(pw.stderr.String()))
+	}
+	return closeErr
+}
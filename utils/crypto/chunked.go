@@ -0,0 +1,491 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Encrypted backups are written in a chunked format so they can be
+// encrypted and decrypted as a stream:
+//
+//	"SFDBENC2" | uint32 header length | header (JSON) | records
+//
+// Each record is a flag byte (1 on the last record), a uint32 length and a
+// chunk of at most ChunkSize bytes sealed with AES-256-GCM. The nonce of a
+// chunk is the header's nonce prefix, the chunk number and the flag, so
+// reordered, dropped or truncated chunks fail to decrypt.
+//
+// The chunks are encrypted with a random data key. The header carries the
+// data key wrapped under a key derived from a passphrase (PBKDF2-SHA512) or
+// a key file (HKDF-SHA256), so rotating the passphrase or key file only
+// rewrites the header; see Rekey.
+const (
+	chunkedMagic      = "SFDBENC2"
+	chunkedVersion    = 2
+	ChunkedAlgorithm  = "AES-256-GCM"
+	DefaultChunkSize  = 1 << 20
+	maxHeaderSize     = 64 << 10
+	noncePrefixSize   = GCMNonceSize - 5
+	recordHeaderSize  = 5
+	keyFileMinSize    = 32
+	keyDerivationInfo = "sfDBTools backup key"
+)
+
+// Key derivation functions of the chunked format
+const (
+	KDFPBKDF2  = "pbkdf2-sha512"
+	KDFKeyFile = "hkdf-sha256"
+)
+
+// DefaultPBKDF2Iterations is the PBKDF2 work factor of new encrypted backups
+const DefaultPBKDF2Iterations = 210000
+
+// ErrLegacyFormat is returned by ReadHeader for files encrypted before the
+// chunked format, which have no header
+var ErrLegacyFormat = errors.New("legacy encryption format without header")
+
+// ErrWrongKey is returned when the passphrase or key file does not match the
+// key an encrypted backup was written with
+var ErrWrongKey = errors.New("wrong encryption passphrase or key file")
+
+// Secret is what the key of an encrypted backup is derived from: a
+// passphrase, or the contents of a key file when KeyFile is set
+type Secret struct {
+	Passphrase string
+	KeyFile    string
+}
+
+// String describes the secret without revealing it
+func (s Secret) String() string {
+	if s.KeyFile != "" {
+		return "key file " + s.KeyFile
+	}
+	return "passphrase"
+}
+
+// KDFParams says how the key-encryption key is derived from the secret
+type KDFParams struct {
+	Name       string `json:"name"`
+	Iterations int    `json:"iterations,omitempty"`
+	Salt       []byte `json:"salt"`
+}
+
+// Header is the metadata at the start of a file in the chunked format
+type Header struct {
+	Version     int       `json:"version"`
+	Algorithm   string    `json:"algorithm"`
+	ChunkSize   int       `json:"chunk_size"`
+	NoncePrefix []byte    `json:"nonce_prefix"`
+	KDF         KDFParams `json:"kdf"`
+	KeyID       string    `json:"key_id"`      // identifies the key-encryption key
+	WrappedKey  []byte    `json:"wrapped_key"` // data key sealed under the key-encryption key
+}
+
+// newKDFParams returns fresh derivation parameters for secret
+func newKDFParams(secret Secret) (KDFParams, error) {
+	salt, err := GenerateRandomSalt(16)
+	if err != nil {
+		return KDFParams{}, err
+	}
+	if secret.KeyFile != "" {
+		return KDFParams{Name: KDFKeyFile, Salt: salt}, nil
+	}
+	if secret.Passphrase == "" {
+		return KDFParams{}, errors.New("encryption passphrase cannot be empty")
+	}
+	return KDFParams{Name: KDFPBKDF2, Iterations: DefaultPBKDF2Iterations, Salt: salt}, nil
+}
+
+// deriveKey derives the key-encryption key of secret with kdf
+func deriveKey(secret Secret, kdf KDFParams) ([]byte, error) {
+	switch kdf.Name {
+	case KDFPBKDF2:
+		if secret.KeyFile != "" || secret.Passphrase == "" {
+			return nil, fmt.Errorf("%w: the backup is encrypted with a passphrase", ErrWrongKey)
+		}
+		if kdf.Iterations <= 0 {
+			return nil, fmt.Errorf("invalid PBKDF2 iteration count %d", kdf.Iterations)
+		}
+		return pbkdf2.Key([]byte(secret.Passphrase), kdf.Salt, kdf.Iterations, 32, sha512.New), nil
+	case KDFKeyFile:
+		if secret.KeyFile == "" {
+			return nil, fmt.Errorf("%w: the backup is encrypted with a key file", ErrWrongKey)
+		}
+		material, err := readKeyFile(secret.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return hkdf.Key(sha256.New, material, kdf.Salt, keyDerivationInfo, 32)
+	}
+	return nil, fmt.Errorf("unsupported key derivation %q", kdf.Name)
+}
+
+// readKeyFile reads the key material of a key file, which must hold at least
+// 32 bytes, e.g. from 'openssl rand -out backup.key 32'
+func readKeyFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("key file %s is accessible by other users (mode %04o); restrict it to its owner", path, info.Mode().Perm())
+	}
+	material, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	if len(material) < keyFileMinSize {
+		return nil, fmt.Errorf("key file %s holds %d bytes; at least %d are needed", path, len(material), keyFileMinSize)
+	}
+	return material, nil
+}
+
+// keyID returns a short fingerprint of a key-encryption key
+func keyID(kek []byte) string {
+	sum := sha256.Sum256(append([]byte(keyDerivationInfo+" id:"), kek...))
+	return hex.EncodeToString(sum[:8])
+}
+
+// wrapHeader derives a key-encryption key from secret with fresh parameters
+// and stores dataKey wrapped under it in h
+func wrapHeader(h *Header, secret Secret, dataKey []byte) error {
+	kdf, err := newKDFParams(secret)
+	if err != nil {
+		return err
+	}
+	kek, err := deriveKey(secret, kdf)
+	if err != nil {
+		return err
+	}
+	wrapped, err := encryptAES_GCM(dataKey, kek)
+	if err != nil {
+		return fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	h.KDF, h.KeyID, h.WrappedKey = kdf, keyID(kek), wrapped
+	return nil
+}
+
+// unwrapHeader returns the data key of h using secret
+func unwrapHeader(h *Header, secret Secret) ([]byte, error) {
+	kek, err := deriveKey(secret, h.KDF)
+	if err != nil {
+		return nil, err
+	}
+	if id := keyID(kek); id != h.KeyID {
+		return nil, fmt.Errorf("%w: the backup needs key %s, the %s gives key %s", ErrWrongKey, h.KeyID, secret, id)
+	}
+	dataKey, err := decryptAES_GCM(h.WrappedKey, kek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key (corrupted header?): %w", err)
+	}
+	return dataKey, nil
+}
+
+// writeHeader writes the magic, the header length and the header
+func writeHeader(w io.Writer, h *Header) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(chunkedMagic)
+	binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	buf.Write(data)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write encryption header: %w", err)
+	}
+	return nil
+}
+
+// HeaderMagicSize is how many bytes IsChunkedHeader needs
+const HeaderMagicSize = len(chunkedMagic)
+
+// IsChunkedHeader reports whether data starts like a file in the chunked
+// format
+func IsChunkedHeader(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(chunkedMagic))
+}
+
+// ReadHeader reads the header of a file in the chunked format and leaves r
+// at the first record. It returns ErrLegacyFormat when r does not start with
+// a header.
+func ReadHeader(r io.Reader) (*Header, error) {
+	prefix := make([]byte, len(chunkedMagic)+4)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return nil, ErrLegacyFormat
+		}
+		return nil, fmt.Errorf("failed to read encryption header: %w", err)
+	}
+	if string(prefix[:len(chunkedMagic)]) != chunkedMagic {
+		return nil, ErrLegacyFormat
+	}
+	size := binary.BigEndian.Uint32(prefix[len(chunkedMagic):])
+	if size > maxHeaderSize {
+		return nil, fmt.Errorf("encryption header of %d bytes is too large", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("failed to read encryption header: %w", err)
+	}
+	h := &Header{}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("invalid encryption header: %w", err)
+	}
+	if h.Version != chunkedVersion || h.Algorithm != ChunkedAlgorithm {
+		return nil, fmt.Errorf("unsupported encryption format version %d (%s)", h.Version, h.Algorithm)
+	}
+	if h.ChunkSize <= 0 || len(h.NoncePrefix) != noncePrefixSize {
+		return nil, fmt.Errorf("invalid encryption header")
+	}
+	return h, nil
+}
+
+// chunkNonce returns the nonce of chunk n
+func chunkNonce(prefix []byte, n uint32, last bool) []byte {
+	nonce := make([]byte, GCMNonceSize)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], n)
+	if last {
+		nonce[GCMNonceSize-1] = 1
+	}
+	return nonce
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptingWriter encrypts a stream in the chunked format. Close writes the
+// last record; without it the output is rejected as truncated.
+type EncryptingWriter struct {
+	w      io.Writer
+	gcm    cipher.AEAD
+	header *Header
+	buf    []byte
+	sealed []byte
+	chunk  uint32
+	closed bool
+}
+
+// NewEncryptingWriter writes the header for secret to w and returns a writer
+// encrypting into w
+func NewEncryptingWriter(w io.Writer, secret Secret) (*EncryptingWriter, error) {
+	dataKey, err := GenerateRandomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := GenerateRandomBytes(noncePrefixSize)
+	if err != nil {
+		return nil, err
+	}
+	h := &Header{Version: chunkedVersion, Algorithm: ChunkedAlgorithm, ChunkSize: DefaultChunkSize, NoncePrefix: prefix}
+	if err := wrapHeader(h, secret, dataKey); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeHeader(w, h); err != nil {
+		return nil, err
+	}
+	return &EncryptingWriter{w: w, gcm: gcm, header: h, buf: make([]byte, 0, h.ChunkSize)}, nil
+}
+
+// Header returns the header written for the stream
+func (e *EncryptingWriter) Header() *Header {
+	return e.header
+}
+
+// Write encrypts p, writing every full chunk
+func (e *EncryptingWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("writer is closed")
+	}
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), e.header.ChunkSize-len(e.buf))
+		e.buf = append(e.buf, p[:n]...)
+		p = p[n:]
+		written += n
+		// A full chunk is only sealed once more data follows, so the last
+		// record is never empty unless the whole stream is
+		if len(e.buf) == e.header.ChunkSize && len(p) > 0 {
+			if err := e.seal(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close writes the remaining data as the last record. It does not close the
+// underlying writer.
+func (e *EncryptingWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.seal(true)
+}
+
+func (e *EncryptingWriter) seal(last bool) error {
+	if e.chunk == math.MaxUint32 {
+		return errors.New("stream too long to encrypt")
+	}
+	e.sealed = e.gcm.Seal(e.sealed[:0], chunkNonce(e.header.NoncePrefix, e.chunk, last), e.buf, nil)
+	var record [recordHeaderSize]byte
+	if last {
+		record[0] = 1
+	}
+	binary.BigEndian.PutUint32(record[1:], uint32(len(e.sealed)))
+	if _, err := e.w.Write(record[:]); err != nil {
+		return fmt.Errorf("failed to write encrypted data: %w", err)
+	}
+	if _, err := e.w.Write(e.sealed); err != nil {
+		return fmt.Errorf("failed to write encrypted data: %w", err)
+	}
+	e.chunk++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// DecryptingReader decrypts a stream in the chunked format
+type DecryptingReader struct {
+	r      io.Reader
+	gcm    cipher.AEAD
+	header *Header
+	sealed []byte
+	plain  []byte
+	rest   []byte
+	chunk  uint32
+	done   bool
+}
+
+// Header returns the header of the stream
+func (d *DecryptingReader) Header() *Header {
+	return d.header
+}
+
+// Read returns decrypted data. It fails when a chunk does not authenticate
+// or the stream ends before its last record.
+func (d *DecryptingReader) Read(p []byte) (int, error) {
+	for len(d.rest) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.rest)
+	d.rest = d.rest[n:]
+	return n, nil
+}
+
+// next decrypts the next record
+func (d *DecryptingReader) next() error {
+	var record [recordHeaderSize]byte
+	if _, err := io.ReadFull(d.r, record[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return errors.New("encrypted data is truncated")
+		}
+		return fmt.Errorf("failed to read encrypted data: %w", err)
+	}
+	last := record[0] == 1
+	size := int(binary.BigEndian.Uint32(record[1:]))
+	if record[0] > 1 || size < GCMTagSize || size > d.header.ChunkSize+GCMTagSize {
+		return errors.New("encrypted data is corrupted")
+	}
+	if cap(d.sealed) < size {
+		d.sealed = make([]byte, size)
+	}
+	d.sealed = d.sealed[:size]
+	if _, err := io.ReadFull(d.r, d.sealed); err != nil {
+		return errors.New("encrypted data is truncated")
+	}
+	plain, err := d.gcm.Open(d.plain[:0], chunkNonce(d.header.NoncePrefix, d.chunk, last), d.sealed, nil)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt chunk %d (data corruption): %w", d.chunk, err)
+	}
+	d.plain, d.rest = plain, plain
+	d.chunk++
+	if last {
+		d.done = true
+		// Anything after the last record was not written by the encrypter
+		var extra [1]byte
+		if n, _ := d.r.Read(extra[:]); n > 0 {
+			return errors.New("encrypted data has trailing bytes after its last chunk")
+		}
+	}
+	return nil
+}
+
+// NewDecryptingReader returns a reader decrypting r with the key derived from
+// secret. Files in the chunked format are decrypted as a stream; files from
+// before it hold one GCM block under the passphrase key of
+// DeriveKeyWithPassword and are read whole.
+func NewDecryptingReader(r io.Reader, secret Secret) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(HeaderMagicSize); !IsChunkedHeader(magic) {
+		if secret.KeyFile != "" {
+			return nil, fmt.Errorf("%w: backups in the legacy format are encrypted with a passphrase", ErrWrongKey)
+		}
+		key, err := DeriveKeyWithPassword(secret.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return NewGCMDecryptingReader(br, key)
+	}
+	h, err := ReadHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := unwrapHeader(h, secret)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptingReader{r: br, gcm: gcm, header: h}, nil
+}
+
+// GetEncryptionSecret returns the secret of encrypted backups: the key file
+// keyFile, else the one named by SFDB_ENCRYPTION_KEY_FILE, else a passphrase
+// from SFDB_ENCRYPTION_PASSWORD or the prompt
+func GetEncryptionSecret(promptMessage, keyFile string) (Secret, error) {
+	if keyFile == "" {
+		keyFile = strings.TrimSpace(os.Getenv(ENV_ENCRYPTION_KEY_FILE))
+	}
+	if keyFile != "" {
+		return Secret{KeyFile: keyFile}, nil
+	}
+	password, err := GetEncryptionPassword(promptMessage)
+	if err != nil {
+		return Secret{}, err
+	}
+	return Secret{Passphrase: password}, nil
+}
@@ -13,6 +13,10 @@ import (
 const (
 	// Environment variable for encryption password
 	ENV_ENCRYPTION_PASSWORD = "SFDB_ENCRYPTION_PASSWORD"
+	// Environment variable for the key file of encrypted backups
+	ENV_ENCRYPTION_KEY_FILE = "SFDB_ENCRYPTION_KEY_FILE"
+	// Environment variable for the new passphrase of backup rekey
+	ENV_NEW_ENCRYPTION_PASSWORD = "SFDB_NEW_ENCRYPTION_PASSWORD"
 
 	// Environment variables for database credentials
 	ENV_DB_HOST     = "SFDB_HOST"
@@ -163,3 +167,23 @@ func PromptEncryptionPassword(promptMessage string) (string, error) {
 
 	return password, nil
 }
+
+// GetNewEncryptionPassword gets the new password of a key rotation from
+// SFDB_NEW_ENCRYPTION_PASSWORD, or prompts for it twice
+func GetNewEncryptionPassword(promptMessage string) (string, error) {
+	if password := os.Getenv(ENV_NEW_ENCRYPTION_PASSWORD); password != "" {
+		return password, nil
+	}
+	password1, err := PromptEncryptionPassword(promptMessage)
+	if err != nil {
+		return "", err
+	}
+	password2, err := PromptEncryptionPassword("Confirm new encryption password: ")
+	if err != nil {
+		return "", err
+	}
+	if password1 != password2 {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	return password2, nil
+}
@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LegacyKeyID stands for the key of files encrypted before the chunked
+// format, which record no key ID
+const LegacyKeyID = "legacy"
+
+// RekeyResult describes a file rotated by RekeyFile
+type RekeyResult struct {
+	OldKeyID    string
+	NewKeyID    string
+	Reencrypted bool // the file was in the legacy format and encrypted again
+}
+
+// RekeyFile makes the encrypted backup at path readable with newSecret
+// instead of oldSecret. For the chunked format only the header changes: the
+// data key is unwrapped with the old secret and wrapped with the new one, so
+// the data itself is copied as is. Legacy files are decrypted and encrypted
+// again in the chunked format. The file is replaced atomically through a
+// temporary file next to it.
+func RekeyFile(path string, oldSecret, newSecret Secret) (*RekeyResult, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open encrypted file: %w", err)
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file stats: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".rekey-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	result := &RekeyResult{}
+	h, err := ReadHeader(src)
+	switch {
+	case errors.Is(err, ErrLegacyFormat):
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		result.OldKeyID, result.Reencrypted = LegacyKeyID, true
+		result.NewKeyID, err = reencrypt(src, tmp, oldSecret, newSecret)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		dataKey, err := unwrapHeader(h, oldSecret)
+		if err != nil {
+			return nil, err
+		}
+		result.OldKeyID = h.KeyID
+		if err := wrapHeader(h, newSecret, dataKey); err != nil {
+			return nil, err
+		}
+		result.NewKeyID = h.KeyID
+		if err := writeHeader(tmp, h); err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(tmp, src); err != nil {
+			return nil, fmt.Errorf("failed to copy encrypted data: %w", err)
+		}
+	}
+
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	if err := tmp.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// reencrypt decrypts a legacy stream with oldSecret and writes it to w in the
+// chunked format under newSecret, returning the new key ID
+func reencrypt(r io.Reader, w io.Writer, oldSecret, newSecret Secret) (string, error) {
+	dec, err := NewDecryptingReader(r, oldSecret)
+	if err != nil {
+		return "", err
+	}
+	enc, err := NewEncryptingWriter(w, newSecret)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(enc, dec); err != nil {
+		return "", fmt.Errorf("failed to re-encrypt data (wrong passphrase?): %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return enc.Header().KeyID, nil
+}
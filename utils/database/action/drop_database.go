@@ -60,10 +60,6 @@ func isSystemDB(name string) bool {
 	return false
 }
 
-func sanitizeDBName(name string) string {
-	return strings.ReplaceAll(name, "`", "``")
-}
-
 func DropDatabases(opts DropDatabasesOptions) (*DropDatabasesResult, error) {
 	lg, _ := logger.Get()
 
@@ -172,7 +168,7 @@ func DropDatabases(opts DropDatabasesOptions) (*DropDatabasesResult, error) {
 }
 
 func dropOne(db *sql.DB, dbName string) error {
-	q := fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", sanitizeDBName(dbName))
+	q := "DROP DATABASE IF EXISTS " + database.QuoteIdent(dbName)
 	if _, err := db.Exec(q); err != nil {
 		return fmt.Errorf("DROP DATABASE failed: %w", err)
 	}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"sfDBTools/utils/shutdown"
+)
+
+// BinlogTime is the layout mysqlbinlog expects for --start-datetime/--stop-datetime
+const BinlogTime = "2006-01-02 15:04:05"
+
+// binlogEventHeader matches the comment mysqlbinlog writes above every event,
+// e.g. "#240131 12:00:00 server id 1  end_log_pos 256"
+var binlogEventHeader = regexp.MustCompile(`^#(\d{6})\s+(\d{1,2}:\d{2}:\d{2})\s+server id`)
+
+// BinlogEventTime returns the time of an event header line of mysqlbinlog
+// output; false when line is no event header
+func BinlogEventTime(line string) (time.Time, bool) {
+	m := binlogEventHeader.FindStringSubmatch(line)
+	if m == nil {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("060102 15:04:05", m[1]+" "+m[2], time.Local)
+	return t, err == nil
+}
+
+// OldestBinlogEvent reads the start of binlog from the server with mysqlbinlog
+// and returns the time of its first event
+func OldestBinlogEvent(cfg Config, binlog string) (time.Time, error) {
+	cmd := shutdown.Command(shutdown.Context(), "mysqlbinlog", "--read-from-remote-server",
+		fmt.Sprintf("--host=%s", cfg.Host),
+		fmt.Sprintf("--port=%d", cfg.Port),
+		fmt.Sprintf("--user=%s", cfg.User),
+		"--stop-position=1024", binlog)
+	cmd.Env = os.Environ()
+	if cfg.Password != "" {
+		cmd.Env = append(cmd.Env, "MYSQL_PWD="+cfg.Password)
+	}
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return time.Time{}, err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if t, ok := BinlogEventTime(line); ok {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no event found at the start of %s", binlog)
+}
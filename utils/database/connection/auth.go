@@ -0,0 +1,173 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"os/user"
+	"strings"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Authentication plugins an account can be identified with
+const (
+	AuthPluginNative        = "mysql_native_password"
+	AuthPluginCachingSHA2   = "caching_sha2_password"
+	AuthPluginSHA256        = "sha256_password"
+	AuthPluginClearPassword = "mysql_clear_password"
+	AuthPluginED25519       = "ed25519"
+	AuthPluginUnixSocket    = "unix_socket"
+)
+
+// clientAuthPlugins lists the plugins the bundled MySQL driver can answer.
+// unix_socket needs no client plugin but only works over a socket.
+var clientAuthPlugins = map[string]bool{
+	AuthPluginNative:        true,
+	AuthPluginCachingSHA2:   true,
+	AuthPluginSHA256:        true,
+	AuthPluginClearPassword: true,
+	AuthPluginUnixSocket:    true,
+	AuthPluginED25519:       false,
+}
+
+// AuthHint tells how to reach one server: over a socket and/or with a given plugin
+type AuthHint struct {
+	Socket     string
+	AuthPlugin string
+}
+
+// authHints holds hints registered for host:port endpoints (from encrypted
+// configs) and the process-wide default (from --socket/--auth-plugin)
+var authHints = struct {
+	sync.RWMutex
+	byEndpoint map[string]AuthHint
+	fallback   AuthHint
+}{byEndpoint: make(map[string]AuthHint)}
+
+func endpointKey(host string, port int) string {
+	return fmt.Sprintf("%s:%d", strings.ToLower(host), port)
+}
+
+// SetEndpointAuthHint records how to authenticate against host:port, so
+// connections built from just host, port, user and password pick it up
+func SetEndpointAuthHint(host string, port int, hint AuthHint) {
+	authHints.Lock()
+	defer authHints.Unlock()
+	if hint == (AuthHint{}) {
+		delete(authHints.byEndpoint, endpointKey(host, port))
+		return
+	}
+	authHints.byEndpoint[endpointKey(host, port)] = hint
+}
+
+// SetDefaultAuthHint sets the hint used when an endpoint has none of its own.
+// The socket only applies to local hosts.
+func SetDefaultAuthHint(hint AuthHint) error {
+	if err := ValidateAuthPlugin(hint.AuthPlugin); err != nil {
+		return err
+	}
+	authHints.Lock()
+	authHints.fallback = hint
+	authHints.Unlock()
+	return nil
+}
+
+// ValidateAuthPlugin rejects plugin names sfDBTools does not know about
+func ValidateAuthPlugin(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := clientAuthPlugins[name]; !ok {
+		return fmt.Errorf("unknown auth plugin %q (known: %s, %s, %s, %s, %s, %s)", name,
+			AuthPluginNative, AuthPluginCachingSHA2, AuthPluginSHA256, AuthPluginClearPassword, AuthPluginED25519, AuthPluginUnixSocket)
+	}
+	return nil
+}
+
+func isLocalHost(host string) bool {
+	switch strings.ToLower(host) {
+	case "", "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+// withAuthHints fills Socket and AuthPlugin from the registered hints when
+// the config does not set them itself
+func withAuthHints(config Config) Config {
+	authHints.RLock()
+	hint, ok := authHints.byEndpoint[endpointKey(config.Host, config.Port)]
+	fallback := authHints.fallback
+	authHints.RUnlock()
+	if !ok {
+		hint = fallback
+		if !isLocalHost(config.Host) {
+			hint.Socket = ""
+		}
+	}
+	if config.Socket == "" {
+		config.Socket = hint.Socket
+	}
+	if config.AuthPlugin == "" {
+		config.AuthPlugin = hint.AuthPlugin
+	}
+	return config
+}
+
+// checkAuthPlugin fails early, with advice, when the requested plugin cannot
+// work with this client or transport
+func checkAuthPlugin(config Config) error {
+	switch config.AuthPlugin {
+	case AuthPluginED25519:
+		return fmt.Errorf("account '%s' uses ed25519 authentication, which the MySQL driver built into sfDBTools does not support (no client_ed25519 plugin); "+
+			"connect through the unix socket as the matching OS user with unix_socket auth, or give the account a supported plugin: "+
+			"ALTER USER '%s'@'<host>' IDENTIFIED VIA mysql_native_password USING PASSWORD('<password>')", config.User, config.User)
+	case AuthPluginUnixSocket:
+		if config.Socket == "" {
+			return fmt.Errorf("unix_socket authentication only works over a local socket; set --socket (for example /var/lib/mysql/mysql.sock)")
+		}
+		if current, err := user.Current(); err == nil && current.Username != config.User {
+			return fmt.Errorf("unix_socket authentication logs in as the OS user: running as '%s' cannot authenticate as '%s' (run as %s, e.g. with sudo)",
+				current.Username, config.User, config.User)
+		}
+	}
+	return nil
+}
+
+// dsnParams returns the DSN query parameters the auth plugin needs
+func dsnParams(config Config) string {
+	if config.AuthPlugin == AuthPluginClearPassword {
+		// The password is sent as is; PAM/LDAP accounts need this
+		return "?allowCleartextPasswords=true"
+	}
+	return ""
+}
+
+// DiagnoseAuthError explains authentication plugin failures of a connection
+// opened with ServerDSN
+func DiagnoseAuthError(config Config, err error) error {
+	return diagnoseAuthError(withAuthHints(config), err)
+}
+
+// diagnoseAuthError turns driver authentication failures into advice about
+// the auth plugin of the account; other errors are returned unchanged
+func diagnoseAuthError(config Config, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, mysql.ErrUnknownPlugin) {
+		return fmt.Errorf("%w: the server asked for an authentication plugin the MySQL driver built into sfDBTools lacks "+
+			"(usually client_ed25519 for ed25519 accounts); supported: mysql_native_password, caching_sha2_password, sha256_password, "+
+			"mysql_clear_password (with --auth-plugin mysql_clear_password) and unix_socket over --socket", err)
+	}
+	if errors.Is(err, mysql.ErrCleartextPassword) {
+		return fmt.Errorf("%w: the account uses a cleartext plugin such as PAM; pass --auth-plugin mysql_clear_password, preferably over --socket or TLS", err)
+	}
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == 1698 && config.Socket == "" {
+		// ER_ACCESS_DENIED_NO_PASSWORD_ERROR: typical for root with unix_socket auth over TCP
+		return fmt.Errorf("%w: '%s' probably authenticates with unix_socket; connect through the local socket with --socket as the matching OS user", err, config.User)
+	}
+	return err
+}
@@ -13,9 +13,13 @@ func Get(config Config) (*sql.DB, error) {
 		return nil, err
 	}
 
+	config, err = prepareAuth(config)
+	if err != nil {
+		return nil, err
+	}
 	dsn := buildDSN(config, true) // Connect with database selected
 
-	db, err := createConnection(dsn)
+	db, err := createConnection(config, dsn)
 	if err != nil {
 		lg.Error("Failed to open database connection", logger.Error(err))
 		return nil, fmt.Errorf("failed to open connection to database '%s': %w", config.DBName, err)
@@ -27,7 +31,7 @@ func Get(config Config) (*sql.DB, error) {
 		lg.Error("Failed to connect to database",
 			logger.Error(err),
 			logger.String("database", config.DBName))
-		return nil, fmt.Errorf("failed to connect to database '%s': %w", config.DBName, err)
+		return nil, fmt.Errorf("failed to connect to database '%s': %w", config.DBName, diagnoseAuthError(config, err))
 	}
 
 	// success - connection established (no verbose debug log to avoid noisy output)
@@ -41,9 +45,13 @@ func GetWithoutDB(config Config) (*sql.DB, error) {
 		return nil, err
 	}
 
+	config, err = prepareAuth(config)
+	if err != nil {
+		return nil, err
+	}
 	dsn := buildDSN(config, false) // Connect without database selected
 
-	db, err := createConnection(dsn)
+	db, err := createConnection(config, dsn)
 	if err != nil {
 		lg.Error("Failed to open database connection", logger.Error(err))
 		return nil, fmt.Errorf("failed to open connection to database server: %w", err)
@@ -53,7 +61,7 @@ func GetWithoutDB(config Config) (*sql.DB, error) {
 	if err := db.Ping(); err != nil {
 		db.Close()
 		lg.Error("Failed to connect to database server", logger.Error(err))
-		return nil, fmt.Errorf("failed to connect to database server: %w", err)
+		return nil, fmt.Errorf("failed to connect to database server: %w", diagnoseAuthError(config, err))
 	}
 
 	// success - connection to server established (no verbose debug log to avoid noisy output)
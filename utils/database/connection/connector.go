@@ -11,9 +11,24 @@ import (
 )
 
 // createConnection creates a new database connection with the given DSN
-// It is a helper function used by other functions in this package
-func createConnection(dsn string) (*sql.DB, error) {
-	db, err := sql.Open("mysql", dsn)
+// It is a helper function used by other functions in this package.
+// Connections to an endpoint registered with ProtectEndpoint are guarded,
+// and all statements are traced while EnableTrace is on.
+func createConnection(config Config, dsn string) (*sql.DB, error) {
+	protected.RLock()
+	opts, guarded := protected.byEndpoint[endpointKey(config.Host, config.Port)]
+	protected.RUnlock()
+
+	var db *sql.DB
+	var err error
+	switch {
+	case guarded:
+		db, err = openWrapped(dsn, endpointKey(config.Host, config.Port), &opts)
+	case tracing():
+		db, err = openWrapped(dsn, endpointKey(config.Host, config.Port), nil)
+	default:
+		db, err = sql.Open("mysql", dsn)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -27,13 +42,45 @@ func createConnection(dsn string) (*sql.DB, error) {
 }
 
 // buildDSN creates a DSN string for MySQL connections
-// If dbName is empty, it will connect to the MySQL server without selecting a database
+// If dbName is empty, it will connect to the MySQL server without selecting a database.
+// A socket, given directly or through an auth hint, replaces host and port.
 func buildDSN(config Config, includeDBName bool) string {
+	config = withAuthHints(config)
 	dbPart := ""
 	if includeDBName && config.DBName != "" {
 		dbPart = config.DBName
 	}
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", config.User, config.Password, config.Host, config.Port, dbPart)
+	address := fmt.Sprintf("tcp(%s:%d)", config.Host, config.Port)
+	if config.Socket != "" {
+		address = fmt.Sprintf("unix(%s)", config.Socket)
+	}
+	return fmt.Sprintf("%s:%s@%s/%s%s", config.User, config.Password, address, dbPart, dsnParams(config))
+}
+
+// prepareAuth resolves the auth hints of config and fails early, with advice,
+// when the auth plugin cannot work with this client or transport
+func prepareAuth(config Config) (Config, error) {
+	config = withAuthHints(config)
+	return config, checkAuthPlugin(config)
+}
+
+// ServerDSN returns the DSN for config without a database selected, for
+// callers that open and configure the connection themselves
+func ServerDSN(config Config) (string, error) {
+	config, err := prepareAuth(config)
+	if err != nil {
+		return "", err
+	}
+	return buildDSN(config, false), nil
+}
+
+// Open opens a connection pool for a DSN built by the caller, like
+// sql.Open, with its statements traced while EnableTrace is on
+func Open(endpoint, dsn string) (*sql.DB, error) {
+	if tracing() {
+		return openWrapped(dsn, endpoint, nil)
+	}
+	return sql.Open("mysql", dsn)
 }
 
 // getLogger gets the logger or returns an error
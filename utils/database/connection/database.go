@@ -14,7 +14,7 @@ func EnsureDatabase(config Config) error {
 	}
 
 	dsn := buildDSN(config, false)
-	db, err := createConnection(dsn)
+	db, err := createConnection(config, dsn)
 	if err != nil {
 		lg.Error("Failed to open database connection", logger.Error(err))
 		return fmt.Errorf("failed to open database connection: %w", err)
@@ -0,0 +1,202 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+)
+
+// Policies for choosing among the hosts of a multi-host configuration
+const (
+	// PolicyFirstAvailable uses the first reachable host, in configured order
+	PolicyFirstAvailable = "first-available"
+	// PolicyPreferReplicaForBackup runs backups against a healthy replica and
+	// falls back to the primary when there is none
+	PolicyPreferReplicaForBackup = "prefer-replica-for-backup"
+)
+
+// Purpose tells SelectEndpoint what the connection is used for
+type Purpose int
+
+const (
+	// PurposePrimary needs the current primary: restores, migrations and
+	// anything else that may write
+	PurposePrimary Purpose = iota
+	// PurposeBackup only reads, so the policy may pick a replica
+	PurposeBackup
+)
+
+// probeTimeout bounds connecting to and querying one host while probing
+const probeTimeout = 5 * time.Second
+
+// maxBackupReplicaLag is the replication lag above which a replica is not
+// used for backups
+const maxBackupReplicaLag = 300 * time.Second
+
+// Endpoint is one host of a multi-host configuration
+type Endpoint struct {
+	Host string
+	Port int
+}
+
+func (e Endpoint) String() string {
+	return endpointKey(e.Host, e.Port)
+}
+
+// EndpointHealth is what probing found about one host
+type EndpointHealth struct {
+	Endpoint
+	Reachable bool
+	ReadOnly  bool
+	Replica   bool
+	// ReplicationRunning and Lag are only set for replicas; Lag is -1 when
+	// the server does not know it (e.g. the IO thread is down)
+	ReplicationRunning bool
+	Lag                time.Duration
+	Err                error
+}
+
+// Primary reports whether the host accepts writes and replicates from nowhere
+func (h EndpointHealth) Primary() bool {
+	return h.Reachable && !h.ReadOnly && !h.Replica
+}
+
+// healthyReplica reports whether a backup may run against the host
+func (h EndpointHealth) healthyReplica() bool {
+	return h.Reachable && h.Replica && h.ReplicationRunning && h.Lag >= 0 && h.Lag <= maxBackupReplicaLag
+}
+
+// State describes the role and health of the host
+func (h EndpointHealth) State() string {
+	switch {
+	case !h.Reachable:
+		return fmt.Sprintf("unreachable (%v)", h.Err)
+	case h.Replica && !h.ReplicationRunning:
+		return "replica, replication stopped"
+	case h.Replica && h.Lag < 0:
+		return "replica, lag unknown"
+	case h.Replica:
+		return fmt.Sprintf("replica, lag %s", h.Lag)
+	case h.ReadOnly:
+		return "read-only"
+	}
+	return "primary"
+}
+
+func (h EndpointHealth) String() string {
+	return h.Endpoint.String() + " " + h.State()
+}
+
+// ValidatePolicy rejects unknown multi-host policies; empty means first-available
+func ValidatePolicy(policy string) error {
+	switch policy {
+	case "", PolicyFirstAvailable, PolicyPreferReplicaForBackup:
+		return nil
+	}
+	return fmt.Errorf("unknown host policy %q (use %s or %s)", policy, PolicyFirstAvailable, PolicyPreferReplicaForBackup)
+}
+
+// SelectEndpoint probes the candidates in order and picks the one to use.
+// PurposePrimary always gets the current primary, whatever the policy. A
+// backup gets the first healthy replica under prefer-replica-for-backup,
+// otherwise the primary, and with first-available the first reachable host.
+// config supplies the credentials, and the socket of the host it names.
+func SelectEndpoint(config Config, candidates []Endpoint, policy string, purpose Purpose) (Endpoint, []EndpointHealth, error) {
+	lg, _ := getLogger()
+	if err := ValidatePolicy(policy); err != nil {
+		return Endpoint{}, nil, err
+	}
+
+	health := make([]EndpointHealth, 0, len(candidates))
+	for _, candidate := range candidates {
+		h := ProbeEndpoint(config, candidate)
+		health = append(health, h)
+		if lg != nil {
+			lg.Debug("Probed database host", logger.String("host", h.String()))
+		}
+	}
+
+	pick := func(ok func(EndpointHealth) bool) (Endpoint, bool) {
+		for _, h := range health {
+			if ok(h) {
+				return h.Endpoint, true
+			}
+		}
+		return Endpoint{}, false
+	}
+
+	if purpose == PurposeBackup {
+		if policy == PolicyPreferReplicaForBackup {
+			if e, ok := pick(EndpointHealth.healthyReplica); ok {
+				return e, health, nil
+			}
+			if lg != nil {
+				lg.Warn("No healthy replica for the backup, using the primary")
+			}
+		} else if e, ok := pick(func(h EndpointHealth) bool { return h.Reachable }); ok {
+			return e, health, nil
+		}
+	}
+	if e, ok := pick(EndpointHealth.Primary); ok {
+		return e, health, nil
+	}
+
+	states := make([]string, len(health))
+	for i, h := range health {
+		states[i] = h.String()
+	}
+	return Endpoint{}, health, fmt.Errorf("no usable primary among the configured hosts: %s", strings.Join(states, "; "))
+}
+
+// ProbeEndpoint connects to one host with the credentials of config and
+// reads whether it is read-only and replicating. The socket of config is only
+// used when endpoint is the host config names.
+func ProbeEndpoint(config Config, endpoint Endpoint) EndpointHealth {
+	h := EndpointHealth{Endpoint: endpoint, Lag: -1}
+	if !strings.EqualFold(endpoint.Host, config.Host) || endpoint.Port != config.Port {
+		config.Socket = ""
+	}
+	config.Host, config.Port, config.DBName = endpoint.Host, endpoint.Port, ""
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	config, err := prepareAuth(config)
+	if err != nil {
+		h.Err = err
+		return h
+	}
+	db, err := createConnection(config, buildDSN(config, false))
+	if err != nil {
+		h.Err = err
+		return h
+	}
+	defer db.Close()
+	if err := db.PingContext(ctx); err != nil {
+		h.Err = diagnoseAuthError(config, err)
+		return h
+	}
+	h.Reachable = true
+
+	var readOnly int
+	if err := db.QueryRowContext(ctx, "SELECT @@global.read_only").Scan(&readOnly); err == nil {
+		h.ReadOnly = readOnly == 1
+	}
+
+	status, err := GetReplicaStatus(ctx, db)
+	if err != nil || status == nil {
+		return h
+	}
+	h.Replica = true
+	io := status.Value("Slave_IO_Running", "Replica_IO_Running")
+	sql := status.Value("Slave_SQL_Running", "Replica_SQL_Running")
+	h.ReplicationRunning = io == "Yes" && sql == "Yes"
+	if lag, err := strconv.Atoi(status.Value("Seconds_Behind_Master", "Seconds_Behind_Source")); err == nil {
+		h.Lag = time.Duration(lag) * time.Second
+	}
+	return h
+}
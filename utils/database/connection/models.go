@@ -7,4 +7,9 @@ type Config struct {
 	User     string
 	Password string
 	DBName   string
+	// Socket is a unix socket path; when set it is used instead of Host:Port
+	Socket string
+	// AuthPlugin is the authentication plugin of the account (see AuthPlugin* constants);
+	// empty lets the server pick
+	AuthPlugin string
 }
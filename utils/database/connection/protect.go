@@ -0,0 +1,200 @@
+package connection
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"sfDBTools/internal/logger"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ErrSourceWrite is returned, before anything reaches the server, for a
+// statement that could write to a protected endpoint
+var ErrSourceWrite = errors.New("write refused on protected source")
+
+// ProtectOptions controls how connections to a protected endpoint are guarded
+type ProtectOptions struct {
+	// SetReadOnly issues SET SESSION TRANSACTION READ ONLY on every new
+	// connection; otherwise the session must already be read-only (a
+	// read-only account, init_connect or transaction_read_only on the server)
+	SetReadOnly bool
+}
+
+// protected holds the endpoints registered with ProtectEndpoint
+var protected = struct {
+	sync.RWMutex
+	byEndpoint map[string]ProtectOptions
+}{byEndpoint: make(map[string]ProtectOptions)}
+
+// ProtectEndpoint guards every connection opened to host:port from now on:
+// the session must be read-only and statements that could write are refused
+func ProtectEndpoint(host string, port int, opts ProtectOptions) {
+	protected.Lock()
+	defer protected.Unlock()
+	protected.byEndpoint[endpointKey(host, port)] = opts
+}
+
+// IsProtected reports whether host:port was registered with ProtectEndpoint
+func IsProtected(host string, port int) bool {
+	protected.RLock()
+	defer protected.RUnlock()
+	_, ok := protected.byEndpoint[endpointKey(host, port)]
+	return ok
+}
+
+// VerifyProtected opens a connection to the protected endpoint of config so
+// a session that cannot be made read-only fails before any work starts
+func VerifyProtected(config Config) error {
+	if !IsProtected(config.Host, config.Port) {
+		return fmt.Errorf("%s:%d is not a protected endpoint", config.Host, config.Port)
+	}
+	db, err := GetWithoutDB(config)
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// prepareProtected makes the session read-only, when asked to, and verifies it is
+func prepareProtected(ctx context.Context, conn driver.Conn, endpoint string, opts ProtectOptions) error {
+	if opts.SetReadOnly {
+		execer, ok := conn.(driver.ExecerContext)
+		if !ok {
+			return fmt.Errorf("driver cannot set the session read-only")
+		}
+		start := time.Now()
+		_, err := execer.ExecContext(ctx, "SET SESSION TRANSACTION READ ONLY", nil)
+		traceStatement(endpoint, "SET SESSION TRANSACTION READ ONLY", nil, time.Since(start), -1, err)
+		if err != nil {
+			return fmt.Errorf("failed to make the session on protected source %s read-only: %w", endpoint, err)
+		}
+	}
+	readOnly, err := sessionReadOnly(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("failed to check the session on protected source %s: %w", endpoint, err)
+	}
+	if !readOnly {
+		return fmt.Errorf("session on protected source %s is not read-only; use a read-only account or let sfDBTools set it (--source-protect-set)", endpoint)
+	}
+	return nil
+}
+
+// sessionReadOnly reads transaction_read_only, falling back to its older
+// name tx_read_only on servers that lack it
+func sessionReadOnly(ctx context.Context, conn driver.Conn) (bool, error) {
+	queryer, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return false, fmt.Errorf("driver cannot query the session")
+	}
+	var rows driver.Rows
+	var err error
+	for _, variable := range []string{"transaction_read_only", "tx_read_only"} {
+		rows, err = queryer.QueryContext(ctx, "SELECT @@session."+variable, nil)
+		var myErr *mysql.MySQLError
+		if err == nil || !errors.As(err, &myErr) || myErr.Number != 1193 { // ER_UNKNOWN_SYSTEM_VARIABLE
+			break
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	value := make([]driver.Value, 1)
+	if err := rows.Next(value); err != nil {
+		return false, err
+	}
+	switch v := value[0].(type) {
+	case int64:
+		return v == 1, nil
+	case []byte:
+		return string(v) == "1", nil
+	}
+	return false, fmt.Errorf("unexpected read-only value %v", value[0])
+}
+
+// refuseWrite logs and returns the error for a statement checkStatement refused
+func refuseWrite(endpoint string, refused error) error {
+	if lg, err := getLogger(); err == nil {
+		lg.Error("Refused statement on protected source",
+			logger.String("endpoint", endpoint),
+			logger.String("statement", refused.Error()))
+	}
+	return fmt.Errorf("%w %s: %v", ErrSourceWrite, endpoint, refused)
+}
+
+// checkStatement allows statements that only read or manage the session and
+// transactions; everything else, including unknown statements, is refused
+func checkStatement(query string) error {
+	stmt := leadingStatement(query)
+	fields := strings.Fields(stmt)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	allowed := false
+	switch verb := strings.TrimSuffix(fields[0], ";"); verb {
+	case "SELECT", "WITH":
+		allowed = !strings.Contains(stmt, " INTO OUTFILE") && !strings.Contains(stmt, " INTO DUMPFILE")
+	case "SHOW", "DESC", "DESCRIBE", "EXPLAIN", "USE", "HELP", "CHECKSUM", "COMMIT", "ROLLBACK", "SAVEPOINT", "RELEASE", "UNLOCK":
+		allowed = true
+	case "BEGIN", "START":
+		allowed = (verb == "BEGIN" || len(fields) > 1 && fields[1] == "TRANSACTION") && !strings.Contains(stmt, "READ WRITE")
+	case "SET":
+		allowed = true
+		for _, word := range []string{"GLOBAL", "PERSIST", "PASSWORD", "DEFAULT ROLE", "READ WRITE"} {
+			if strings.Contains(stmt, word) {
+				allowed = false
+			}
+		}
+	case "LOCK":
+		allowed = !strings.Contains(stmt, " WRITE")
+	case "FLUSH":
+		allowed = strings.HasSuffix(strings.TrimRight(stmt, "; "), "WITH READ LOCK")
+	}
+	if allowed {
+		return nil
+	}
+
+	summary := strings.Join(strings.Fields(query), " ")
+	if len(summary) > 80 {
+		summary = summary[:80] + "..."
+	}
+	return errors.New(summary)
+}
+
+// leadingStatement upper-cases query after skipping leading comments and
+// parentheses. Versioned comments (/*!50100 ... */) are executed by the
+// server, so their content counts as the statement.
+func leadingStatement(query string) string {
+	s := strings.TrimSpace(query)
+	for {
+		switch {
+		case strings.HasPrefix(s, "/*!"), strings.HasPrefix(s, "/*M!"):
+			s = strings.TrimLeft(s[strings.Index(s, "!")+1:], "0123456789")
+		case strings.HasPrefix(s, "/*"):
+			end := strings.Index(s, "*/")
+			if end < 0 {
+				return ""
+			}
+			s = s[end+2:]
+		case strings.HasPrefix(s, "--"), strings.HasPrefix(s, "#"):
+			end := strings.IndexByte(s, '\n')
+			if end < 0 {
+				return ""
+			}
+			s = s[end+1:]
+		case strings.HasPrefix(s, "("):
+			s = s[1:]
+		default:
+			return strings.ToUpper(s)
+		}
+		s = strings.TrimSpace(s)
+	}
+}
@@ -0,0 +1,50 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ReplicaStatus is the row of SHOW SLAVE STATUS by column name
+type ReplicaStatus map[string]string
+
+// Value returns the first of names the status has, so both the Master_/Slave_
+// columns and the Source_/Replica_ ones of newer servers can be read
+func (s ReplicaStatus) Value(names ...string) string {
+	for _, name := range names {
+		if v, ok := s[name]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// GetReplicaStatus returns the first row of SHOW SLAVE STATUS (SHOW REPLICA
+// STATUS on servers that dropped the old name), nil when not a replica
+func GetReplicaStatus(ctx context.Context, db *sql.DB) (ReplicaStatus, error) {
+	rows, err := db.QueryContext(ctx, "SHOW SLAVE STATUS")
+	if err != nil {
+		if rows, err = db.QueryContext(ctx, "SHOW REPLICA STATUS"); err != nil {
+			return nil, err
+		}
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil || !rows.Next() {
+		return nil, err
+	}
+	values := make([]sql.NullString, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	status := make(ReplicaStatus, len(cols))
+	for i, c := range cols {
+		status[c] = values[i].String
+	}
+	return status, nil
+}
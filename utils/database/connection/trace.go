@@ -0,0 +1,137 @@
+package connection
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTracedStatement is the length after which traced statements are cut
+const maxTracedStatement = 2000
+
+// maxTracedArg is the length after which traced string arguments are cut
+const maxTracedArg = 64
+
+// trace is the file of EnableTrace; nil when tracing is off
+var trace struct {
+	sync.Mutex
+	file *os.File
+}
+
+// secretLiteral matches the quoted literal following a password clause, e.g.
+// IDENTIFIED BY '...', PASSWORD('...'), SET PASSWORD = '...', MASTER_PASSWORD='...'
+var secretLiteral = regexp.MustCompile(`(?is)((?:IDENTIFIED\s+(?:BY|WITH\s+\S+\s+(?:BY|AS)|VIA\s+\S+\s+(?:USING|AS))|PASSWORD\s*(?:=|\()|(?:MASTER|SOURCE)_PASSWORD\s*=)\s*(?:PASSWORD\s*\(?\s*)?)('(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.)*")`)
+
+// EnableTrace appends every statement executed through this package, with
+// its duration and affected rows, to path
+func EnableTrace(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create SQL trace directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open SQL trace: %w", err)
+	}
+	trace.Lock()
+	defer trace.Unlock()
+	if trace.file != nil {
+		trace.file.Close()
+	}
+	trace.file = file
+	fmt.Fprintf(file, "# sfDBTools SQL trace started %s (pid %d)\n", time.Now().Format(time.RFC3339), os.Getpid())
+	return nil
+}
+
+// CloseTrace stops tracing and closes the trace file
+func CloseTrace() error {
+	trace.Lock()
+	defer trace.Unlock()
+	if trace.file == nil {
+		return nil
+	}
+	err := trace.file.Close()
+	trace.file = nil
+	return err
+}
+
+// TraceStatement records a statement sfDBTools ran outside a pooled
+// connection, e.g. a script passed to the mysql client. rows is -1 when unknown.
+func TraceStatement(endpoint, statement string, duration time.Duration, rows int64, err error) {
+	traceStatement(endpoint, statement, nil, duration, rows, err)
+}
+
+func tracing() bool {
+	trace.Lock()
+	defer trace.Unlock()
+	return trace.file != nil
+}
+
+// traceStatement writes one line: time, endpoint, duration, affected rows,
+// the redacted statement with its arguments and the error, if any
+func traceStatement(endpoint, query string, args []driver.NamedValue, duration time.Duration, rows int64, err error) {
+	trace.Lock()
+	defer trace.Unlock()
+	if trace.file == nil {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s", time.Now().Format("2006-01-02T15:04:05.000Z07:00"), endpoint, duration.Round(time.Microsecond))
+	if rows >= 0 {
+		fmt.Fprintf(&b, " rows=%d", rows)
+	}
+	b.WriteString(" | ")
+	b.WriteString(redactStatement(query))
+	if len(args) > 0 {
+		b.WriteString(" | args=")
+		b.WriteString(formatArgs(query, args))
+	}
+	if err != nil {
+		b.WriteString(" | error: ")
+		b.WriteString(err.Error())
+	}
+	b.WriteByte('\n')
+	trace.file.WriteString(b.String())
+}
+
+// redactStatement masks password literals, folds the statement onto one line
+// and cuts it at maxTracedStatement
+func redactStatement(query string) string {
+	s := secretLiteral.ReplaceAllString(query, "$1'***'")
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxTracedStatement {
+		s = fmt.Sprintf("%s... (%d bytes)", s[:maxTracedStatement], len(query))
+	}
+	return s
+}
+
+// formatArgs lists placeholder values; all of them are masked when the
+// statement deals with passwords
+func formatArgs(query string, args []driver.NamedValue) string {
+	upper := strings.ToUpper(query)
+	if strings.Contains(upper, "PASSWORD") || strings.Contains(upper, "IDENTIFIED") {
+		return "***"
+	}
+	values := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.Value.(type) {
+		case nil:
+			values[i] = "NULL"
+		case []byte:
+			values[i] = fmt.Sprintf("<%d bytes>", len(v))
+		case string:
+			if len(v) > maxTracedArg {
+				v = v[:maxTracedArg] + "..."
+			}
+			values[i] = fmt.Sprintf("%q", v)
+		default:
+			values[i] = fmt.Sprint(v)
+		}
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
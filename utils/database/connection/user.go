@@ -17,7 +17,7 @@ func ValidateUser(config Config) error {
 	lg.Debug("Validating database user",
 		logger.String("user", config.User))
 
-	db, err := createConnection(dsn)
+	db, err := createConnection(config, dsn)
 	if err != nil {
 		lg.Error("Failed to open database connection", logger.Error(err))
 		return fmt.Errorf("failed to open database connection: %w", err)
@@ -14,12 +14,16 @@ func ValidateConnection(config Config) error {
 		return err
 	}
 
+	config, err = prepareAuth(config)
+	if err != nil {
+		return err
+	}
 	dsn := buildDSN(config, false) // No need to specify database for connection validation
 	lg.Debug("Validating database connection",
 		logger.String("host", config.Host),
 		logger.Int("port", config.Port))
 
-	db, err := createConnection(dsn)
+	db, err := createConnection(config, dsn)
 	if err != nil {
 		lg.Error("Failed to open database connection", logger.Error(err))
 		return fmt.Errorf("failed to open database connection: %w", err)
@@ -32,7 +36,7 @@ func ValidateConnection(config Config) error {
 	// Try to connect
 	if err := db.Ping(); err != nil {
 		lg.Error("Failed to connect to database", logger.Error(err))
-		return fmt.Errorf("failed to connect to database server: %w", err)
+		return fmt.Errorf("failed to connect to database server: %w", diagnoseAuthError(config, err))
 	}
 
 	lg.Debug("Database connection is valid",
@@ -52,7 +56,7 @@ func ValidateDatabase(config Config) error {
 	lg.Debug("Validating database exists",
 		logger.String("database", config.DBName))
 
-	db, err := createConnection(dsn)
+	db, err := createConnection(config, dsn)
 	if err != nil {
 		lg.Error("Failed to open database connection", logger.Error(err))
 		return fmt.Errorf("failed to open database connection: %w", err)
@@ -0,0 +1,265 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// openWrapped opens dsn through a connector that guards statements for a
+// protected endpoint (when protect is set) and records them to the SQL trace
+func openWrapped(dsn, endpoint string, protect *ProtectOptions) (*sql.DB, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	base, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(&wrappedConnector{base: base, endpoint: endpoint, protect: protect}), nil
+}
+
+type wrappedConnector struct {
+	base     driver.Connector
+	endpoint string
+	protect  *ProtectOptions
+}
+
+func (c *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.protect != nil {
+		if err := prepareProtected(ctx, conn, c.endpoint, *c.protect); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return &wrappedConn{conn: conn, endpoint: c.endpoint, guarded: c.protect != nil}, nil
+}
+
+func (c *wrappedConnector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// wrappedConn checks and traces statements before passing them on
+type wrappedConn struct {
+	conn     driver.Conn
+	endpoint string
+	guarded  bool
+}
+
+func (c *wrappedConn) check(query string) error {
+	if !c.guarded {
+		return nil
+	}
+	if err := checkStatement(query); err != nil {
+		err = refuseWrite(c.endpoint, err)
+		traceStatement(c.endpoint, query, nil, 0, -1, err)
+		return err
+	}
+	return nil
+}
+
+func (c *wrappedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *wrappedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := c.check(query); err != nil {
+		return nil, err
+	}
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		traceStatement(c.endpoint, query, nil, 0, -1, err)
+		return nil, err
+	}
+	return &wrappedStmt{stmt: stmt, query: query, endpoint: c.endpoint}, nil
+}
+
+func (c *wrappedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.check(query); err != nil {
+		return nil, err
+	}
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		traceStatement(c.endpoint, query, args, time.Since(start), rowsAffected(result), err)
+	}
+	return result, err
+}
+
+func (c *wrappedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.check(query); err != nil {
+		return nil, err
+	}
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		traceStatement(c.endpoint, query, args, time.Since(start), -1, err)
+	}
+	return rows, err
+}
+
+func (c *wrappedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx starts a transaction, always a read-only one on a protected endpoint
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.guarded {
+		opts.ReadOnly = true
+	}
+	beginner, ok := c.conn.(driver.ConnBeginTx)
+	if !ok {
+		return nil, fmt.Errorf("driver cannot start a transaction with options")
+	}
+	start := time.Now()
+	tx, err := beginner.BeginTx(ctx, opts)
+	statement := "START TRANSACTION"
+	if opts.ReadOnly {
+		statement += " READ ONLY"
+	}
+	traceStatement(c.endpoint, statement, nil, time.Since(start), -1, err)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedTx{tx: tx, endpoint: c.endpoint}, nil
+}
+
+func (c *wrappedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *wrappedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *wrappedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// wrappedStmt traces executions of a prepared statement with their arguments
+type wrappedStmt struct {
+	stmt     driver.Stmt
+	query    string
+	endpoint string
+}
+
+func (s *wrappedStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *wrappedStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *wrappedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *wrappedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *wrappedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.stmt.(driver.StmtExecContext)
+	if !ok {
+		return nil, fmt.Errorf("driver statement cannot execute with a context")
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, args)
+	traceStatement(s.endpoint, s.query, args, time.Since(start), rowsAffected(result), err)
+	return result, err
+}
+
+func (s *wrappedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.stmt.(driver.StmtQueryContext)
+	if !ok {
+		return nil, fmt.Errorf("driver statement cannot query with a context")
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, args)
+	traceStatement(s.endpoint, s.query, args, time.Since(start), -1, err)
+	return rows, err
+}
+
+// wrappedTx traces the end of a transaction
+type wrappedTx struct {
+	tx       driver.Tx
+	endpoint string
+}
+
+func (t *wrappedTx) Commit() error {
+	start := time.Now()
+	err := t.tx.Commit()
+	traceStatement(t.endpoint, "COMMIT", nil, time.Since(start), -1, err)
+	return err
+}
+
+func (t *wrappedTx) Rollback() error {
+	start := time.Now()
+	err := t.tx.Rollback()
+	traceStatement(t.endpoint, "ROLLBACK", nil, time.Since(start), -1, err)
+	return err
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+// rowsAffected returns the affected rows of result, -1 when unknown
+func rowsAffected(result driver.Result) int64 {
+	if result == nil {
+		return -1
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"sfDBTools/utils/database/connection"
 )
@@ -38,3 +40,69 @@ func GetWithoutDB(config Config) (*sql.DB, error) {
 func EnsureDatabase(config Config) error {
 	return connection.EnsureDatabase(config)
 }
+
+// AuthHint tells how to reach a server: over a unix socket and/or with an auth plugin
+type AuthHint = connection.AuthHint
+
+// SetEndpointAuthHint records the socket and auth plugin to use for host:port
+func SetEndpointAuthHint(host string, port int, hint AuthHint) {
+	connection.SetEndpointAuthHint(host, port, hint)
+}
+
+// SetDefaultAuthHint sets the socket and auth plugin used when an endpoint has no hint
+func SetDefaultAuthHint(hint AuthHint) error {
+	return connection.SetDefaultAuthHint(hint)
+}
+
+// ProtectOptions controls how connections to a protected source are guarded
+type ProtectOptions = connection.ProtectOptions
+
+// ErrSourceWrite is returned for a statement refused on a protected source
+var ErrSourceWrite = connection.ErrSourceWrite
+
+// ProtectEndpoint makes every later connection to host:port read-only and
+// refuses statements that could write to it
+func ProtectEndpoint(host string, port int, opts ProtectOptions) {
+	connection.ProtectEndpoint(host, port, opts)
+}
+
+// IsProtected reports whether host:port is a protected source
+func IsProtected(host string, port int) bool {
+	return connection.IsProtected(host, port)
+}
+
+// VerifyProtected checks that a read-only session can be opened on the protected source
+func VerifyProtected(config Config) error {
+	return connection.VerifyProtected(config)
+}
+
+// EnableTrace appends every SQL statement sfDBTools executes to path
+func EnableTrace(path string) error {
+	return connection.EnableTrace(path)
+}
+
+// CloseTrace stops SQL tracing
+func CloseTrace() error {
+	return connection.CloseTrace()
+}
+
+// TraceStatement records a statement run outside a connection pool, e.g. by the mysql client
+func TraceStatement(endpoint, statement string, duration time.Duration, rows int64, err error) {
+	connection.TraceStatement(endpoint, statement, duration, rows, err)
+}
+
+// Purpose tells a multi-host configuration what a connection is used for
+type Purpose = connection.Purpose
+
+const (
+	PurposePrimary = connection.PurposePrimary
+	PurposeBackup  = connection.PurposeBackup
+)
+
+// ReplicaStatus is the row of SHOW SLAVE STATUS by column name
+type ReplicaStatus = connection.ReplicaStatus
+
+// GetReplicaStatus returns SHOW SLAVE STATUS (or SHOW REPLICA STATUS), nil when not a replica
+func GetReplicaStatus(ctx context.Context, db *sql.DB) (ReplicaStatus, error) {
+	return connection.GetReplicaStatus(ctx, db)
+}
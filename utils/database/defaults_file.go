@@ -0,0 +1,21 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteDefaultsFile writes client.cnf with mode 0600 to dir, holding user and
+// password for the [client] group and for the [tool] group, and returns its
+// path. Client tools read it with --defaults-file so the password does not
+// show up in the process list.
+func WriteDefaultsFile(dir, tool, user, password string) (string, error) {
+	path := filepath.Join(dir, "client.cnf")
+	content := fmt.Sprintf("[client]\nuser=%s\npassword=%s\n\n[%s]\nuser=%s\npassword=%s\n",
+		user, password, tool, user, password)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s option file: %w", tool, err)
+	}
+	return path, nil
+}
@@ -1,12 +1,9 @@
 package info
 
 import (
-	"bufio"
+	"context"
 	"database/sql"
 	"fmt"
-	"os"
-	"strconv"
-	"strings"
 
 	"sfDBTools/internal/logger"
 	"sfDBTools/utils/database"
@@ -168,31 +165,17 @@ func SelectDatabaseInteractive(config database.Config) (string, error) {
 		return "", fmt.Errorf("no databases found")
 	}
 
-	// Display available databases
-	terminal.PrintSubHeader("Available Databases:")
-	for i, db := range databases {
-		fmt.Printf("   %d. %s\n", i+1, db)
-	}
-
-	// Let user choose
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\nSelect database (1-%d): ", len(databases))
-	choice, err := reader.ReadString('\n')
+	// Let user choose; the picker filters the list while typing
+	index, err := terminal.PickOne("Available Databases:", databases)
 	if err != nil {
 		return "", fmt.Errorf("failed to read selection: %w", err)
 	}
 
-	choice = strings.TrimSpace(choice)
-	index, err := strconv.Atoi(choice)
-	if err != nil || index < 1 || index > len(databases) {
-		return "", fmt.Errorf("invalid selection: %s", choice)
-	}
-
-	return databases[index-1], nil
+	return databases[index], nil
 }
 
 // SelectMultipleDatabasesInteractive displays available databases and lets user choose multiple
-func SelectMultipleDatabasesInteractive(config database.Config) ([]string, error) {
+func SelectMultipleDatabasesInteractive(ctx context.Context, config database.Config) ([]string, error) {
 	databases, err := ListDatabases(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve database list: %w", err)
@@ -203,93 +186,33 @@ func SelectMultipleDatabasesInteractive(config database.Config) ([]string, error
 		return nil, fmt.Errorf("no databases found")
 	}
 
-	// Display available databases
-	terminal.PrintSubHeader("Available Databases:")
+	summaries := CollectDatabaseSummaries(ctx, config, databases)
+
+	// Let user choose multiple databases; each one shows its size, table
+	// count and last backup age
+	width := 0
+	for _, db := range databases {
+		if len(db) > width {
+			width = len(db)
+		}
+	}
+	options := make([]string, len(databases))
 	for i, db := range databases {
-		fmt.Printf("   %d. %s\n", i+1, db)
+		options[i] = fmt.Sprintf("%-*s  (%s)", width, db, summaries[db])
 	}
-
-	// Let user choose multiple databases
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\nSelect databases (comma-separated, e.g. 1,3,5 or ranges like 1-3,5): ")
-	choice, err := reader.ReadString('\n')
+	selectedIndexes, err := terminal.Pick(options, terminal.PickOptions{Title: "Available Databases:", Multi: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to read selection: %w", err)
 	}
-
-	choice = strings.TrimSpace(choice)
-	if choice == "" {
+	if len(selectedIndexes) == 0 {
 		return nil, fmt.Errorf("no databases selected")
 	}
 
-	// Parse selection
-	selectedIndexes, err := parseSelection(choice, len(databases))
-	if err != nil {
-		return nil, fmt.Errorf("invalid selection: %w", err)
-	}
-
 	// Get selected databases
 	var selectedDatabases []string
 	for _, index := range selectedIndexes {
-		selectedDatabases = append(selectedDatabases, databases[index-1])
+		selectedDatabases = append(selectedDatabases, databases[index])
 	}
 
 	return selectedDatabases, nil
 }
-
-// parseSelection parses user selection string (e.g., "1,3,5" or "1-3,5")
-func parseSelection(selection string, maxCount int) ([]int, error) {
-	var indexes []int
-	seen := make(map[int]bool)
-
-	parts := strings.Split(selection, ",")
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-
-		if strings.Contains(part, "-") {
-			// Handle range (e.g., "1-3")
-			rangeParts := strings.Split(part, "-")
-			if len(rangeParts) != 2 {
-				return nil, fmt.Errorf("invalid range format: %s", part)
-			}
-
-			start, err := strconv.Atoi(strings.TrimSpace(rangeParts[0]))
-			if err != nil {
-				return nil, fmt.Errorf("invalid start number in range: %s", rangeParts[0])
-			}
-
-			end, err := strconv.Atoi(strings.TrimSpace(rangeParts[1]))
-			if err != nil {
-				return nil, fmt.Errorf("invalid end number in range: %s", rangeParts[1])
-			}
-
-			if start < 1 || end > maxCount || start > end {
-				return nil, fmt.Errorf("invalid range: %d-%d (valid: 1-%d)", start, end, maxCount)
-			}
-
-			for i := start; i <= end; i++ {
-				if !seen[i] {
-					indexes = append(indexes, i)
-					seen[i] = true
-				}
-			}
-		} else {
-			// Handle single number
-			index, err := strconv.Atoi(part)
-			if err != nil {
-				return nil, fmt.Errorf("invalid number: %s", part)
-			}
-
-			if index < 1 || index > maxCount {
-				return nil, fmt.Errorf("invalid selection: %d (valid: 1-%d)", index, maxCount)
-			}
-
-			if !seen[index] {
-				indexes = append(indexes, index)
-				seen[index] = true
-			}
-		}
-	}
-
-	return indexes, nil
-}
@@ -0,0 +1,176 @@
+package info
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/common/structs"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/schema"
+)
+
+// summaryWorkers bounds the number of concurrent per-database queries
+const summaryWorkers = 8
+
+// maxMetadataFileSize skips unexpectedly large .json files while scanning the backup directory
+const maxMetadataFileSize = 1 << 20
+
+// DatabaseSummary is a lightweight view of a database used to annotate selection lists
+type DatabaseSummary struct {
+	Name       string
+	SizeBytes  int64
+	TableCount int
+	LastBackup time.Time // zero when no backup metadata was found
+	Err        error
+}
+
+// CollectDatabaseSummaries fetches size and table count for each database concurrently and
+// looks up the most recent backup of each one in the configured backup directory.
+func CollectDatabaseSummaries(ctx context.Context, config database.Config, databases []string) map[string]*DatabaseSummary {
+	lg, _ := logger.Get()
+	summaries := make(map[string]*DatabaseSummary, len(databases))
+	for _, name := range databases {
+		summaries[name] = &DatabaseSummary{Name: name}
+	}
+
+	db, err := database.GetWithoutDB(config)
+	if err != nil {
+		lg.Warn("Failed to connect for database summaries", logger.Error(err))
+		return summaries
+	}
+	defer db.Close()
+
+	var lastBackups map[string]time.Time
+	var backupWG sync.WaitGroup
+	backupWG.Add(1)
+	go func() {
+		defer backupWG.Done()
+		lastBackups = LastBackupTimes()
+	}()
+
+	var pending []*DatabaseSummary
+	for _, name := range databases {
+		s := summaries[name]
+		dbConfig := config
+		dbConfig.DBName = name
+		if cached, ok := cachedSummary(dbConfig); ok {
+			s.SizeBytes, s.TableCount = cached.SizeBytes, cached.TableCount
+			continue
+		}
+		pending = append(pending, s)
+	}
+
+	if len(pending) > 0 {
+		progress := newSyncProgress(len(pending), fmt.Sprintf("Collecting size information for %d databases", len(pending)))
+		tasks := make([]func(), len(pending))
+		for i, s := range pending {
+			tasks[i] = func() {
+				if size, err := getDatabaseSize(ctx, db, s.Name); err == nil {
+					s.SizeBytes = size
+				} else {
+					s.Err = err
+				}
+				if count, err := getTableCount(ctx, db, s.Name); err == nil {
+					s.TableCount = count
+				} else if s.Err == nil {
+					s.Err = err
+				}
+				if s.Err == nil {
+					dbConfig := config
+					dbConfig.DBName = s.Name
+					storeSummary(dbConfig, s)
+				}
+				progress.Increment()
+			}
+		}
+		runBounded(summaryWorkers, tasks)
+		progress.Finish()
+	}
+	backupWG.Wait()
+
+	for name, t := range lastBackups {
+		if s, ok := summaries[name]; ok {
+			s.LastBackup = t
+		}
+	}
+
+	return summaries
+}
+
+// LastBackupTimes scans backup metadata files under the configured base directory and
+// returns the newest backup date per database name.
+func LastBackupTimes() map[string]time.Time {
+	result := make(map[string]time.Time)
+
+	cfg, err := config.Get()
+	if err != nil || cfg == nil || cfg.Backup.Storage.BaseDirectory == "" {
+		return result
+	}
+	baseDir := cfg.Backup.Storage.BaseDirectory
+	if _, err := os.Stat(baseDir); err != nil {
+		return result
+	}
+
+	_ = filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		if fi, err := d.Info(); err != nil || fi.Size() > maxMetadataFileSize {
+			return nil
+		}
+		data, err := schema.ReadManifest(path)
+		if err != nil {
+			return nil
+		}
+		var meta structs.BackupMetadata
+		if err := json.Unmarshal(data, &meta); err != nil || meta.DatabaseName == "" || meta.BackupDate.IsZero() {
+			return nil
+		}
+		if meta.BackupDate.After(result[meta.DatabaseName]) {
+			result[meta.DatabaseName] = meta.BackupDate
+		}
+		return nil
+	})
+	return result
+}
+
+// String renders the summary as "1.2 GB, 45 tables, last backup 3d ago"
+func (s *DatabaseSummary) String() string {
+	if s == nil {
+		return ""
+	}
+	parts := []string{}
+	if s.Err != nil && s.SizeBytes == 0 && s.TableCount == 0 {
+		parts = append(parts, "size unavailable")
+	} else {
+		parts = append(parts, common.FormatSize(s.SizeBytes), fmt.Sprintf("%d tables", s.TableCount))
+	}
+	if s.LastBackup.IsZero() {
+		parts = append(parts, "never backed up")
+	} else {
+		parts = append(parts, "last backup "+FormatAge(time.Since(s.LastBackup))+" ago")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatAge renders a duration in its largest sensible unit
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,125 @@
+package info
+
+import (
+	"fmt"
+	"sync"
+
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+)
+
+// infoCache keeps database information for the lifetime of the process, so
+// screens that show the same database more than once query it only once
+var infoCache = struct {
+	sync.Mutex
+	entries map[string]*DatabaseInfo
+}{entries: make(map[string]*DatabaseInfo)}
+
+// summaryCache keeps the size and table count shown in selection lists
+var summaryCache = struct {
+	sync.Mutex
+	entries map[string]DatabaseSummary
+}{entries: make(map[string]DatabaseSummary)}
+
+// cacheKey identifies a database as seen by one user; visible objects depend on grants
+func cacheKey(config database.Config) string {
+	return fmt.Sprintf("%s@%s:%d/%s", config.User, config.Host, config.Port, config.DBName)
+}
+
+func cachedDatabaseInfo(config database.Config) (*DatabaseInfo, bool) {
+	infoCache.Lock()
+	defer infoCache.Unlock()
+	cached, ok := infoCache.entries[cacheKey(config)]
+	if !ok {
+		return nil, false
+	}
+	copied := *cached
+	return &copied, true
+}
+
+func storeDatabaseInfo(config database.Config, info *DatabaseInfo) {
+	copied := *info
+	infoCache.Lock()
+	infoCache.entries[cacheKey(config)] = &copied
+	infoCache.Unlock()
+}
+
+// cachedSummary returns the size and table count of a database from either cache
+func cachedSummary(config database.Config) (DatabaseSummary, bool) {
+	if info, ok := cachedDatabaseInfo(config); ok {
+		return DatabaseSummary{Name: config.DBName, SizeBytes: info.SizeBytes, TableCount: info.TableCount}, true
+	}
+	summaryCache.Lock()
+	defer summaryCache.Unlock()
+	s, ok := summaryCache.entries[cacheKey(config)]
+	return s, ok
+}
+
+func storeSummary(config database.Config, s *DatabaseSummary) {
+	summaryCache.Lock()
+	summaryCache.entries[cacheKey(config)] = DatabaseSummary{Name: s.Name, SizeBytes: s.SizeBytes, TableCount: s.TableCount}
+	summaryCache.Unlock()
+}
+
+// InvalidateDatabaseInfo drops cached information about a database after it was
+// restored, cloned into or dropped
+func InvalidateDatabaseInfo(config database.Config) {
+	key := cacheKey(config)
+	infoCache.Lock()
+	delete(infoCache.entries, key)
+	infoCache.Unlock()
+	summaryCache.Lock()
+	delete(summaryCache.entries, key)
+	summaryCache.Unlock()
+}
+
+// runBounded runs tasks with at most workers of them at a time and waits for all
+func runBounded(workers int, tasks []func()) {
+	if workers > len(tasks) {
+		workers = len(tasks)
+	}
+	queue := make(chan func())
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				task()
+			}
+		}()
+	}
+	for _, task := range tasks {
+		queue <- task
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// syncProgress is a progress bar that concurrent workers can advance
+type syncProgress struct {
+	mu   sync.Mutex
+	bar  *terminal.ProgressBar
+	done int
+}
+
+func newSyncProgress(total int, message string) *syncProgress {
+	p := &syncProgress{bar: terminal.NewProgressBar(total, message)}
+	p.bar.Update(0)
+	return p
+}
+
+// Increment marks one more unit of work as finished
+func (p *syncProgress) Increment() {
+	p.mu.Lock()
+	p.done++
+	p.bar.Update(p.done)
+	p.mu.Unlock()
+}
+
+// Finish completes the bar and moves to the next line
+func (p *syncProgress) Finish() {
+	p.mu.Lock()
+	p.bar.Finish()
+	p.mu.Unlock()
+}
@@ -1,9 +1,11 @@
 package info
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strconv"
+	"sync/atomic"
 
 	"sfDBTools/internal/logger"
 	"sfDBTools/utils/common"
@@ -24,8 +26,16 @@ type DatabaseInfo struct {
 	UserCount    int     `json:"user_count"`
 }
 
-// GetDatabaseInfo retrieves comprehensive information about a database
-func GetDatabaseInfo(config database.Config) (*DatabaseInfo, error) {
+// metadataWorkers bounds the concurrent metadata queries of one GetDatabaseInfo call
+const metadataWorkers = 4
+
+// GetDatabaseInfo retrieves comprehensive information about a database. The
+// metadata queries run concurrently and the result is cached for the rest of
+// the command; call InvalidateDatabaseInfo after changing the database.
+func GetDatabaseInfo(ctx context.Context, config database.Config) (*DatabaseInfo, error) {
+	if cached, ok := cachedDatabaseInfo(config); ok {
+		return cached, nil
+	}
 	lg, _ := logger.Get()
 
 	db, err := database.GetDatabaseConnection(config)
@@ -34,111 +44,113 @@ func GetDatabaseInfo(config database.Config) (*DatabaseInfo, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer db.Close()
+	db.SetMaxOpenConns(metadataWorkers)
 
 	info := &DatabaseInfo{
 		DatabaseName: config.DBName,
 	}
 
-	// Use a single shared spinner for the whole metadata collection and
-	// update its message between steps. Track if any step produced an
-	// error so we can show a final warning message when finished.
-	spinner := terminal.NewProgressSpinner("Collecting database metadata...")
-	spinner.Start()
-	defer func() {
-		// Ensure spinner is stopped; if there were warnings we show a warning
-		// message, otherwise show success.
-		if spinner == nil {
-			return
+	count := func(target *int, query func(context.Context, *sql.DB, string) (int, error)) func() error {
+		return func() error {
+			n, err := query(ctx, db, config.DBName)
+			if err == nil {
+				*target = n
+			}
+			return err
 		}
-	}()
-
-	hadWarning := false
-
-	// Get database size
-	spinner.UpdateMessage("Calculating database size...")
-	if size, err := getDatabaseSize(db, config.DBName); err == nil {
-		info.SizeBytes = size
-		info.SizeMB = float64(size) / (1024 * 1024)
-		info.SizeHuman = common.FormatSize(size)
-		spinner.UpdateMessage(fmt.Sprintf("Database size: %s", info.SizeHuman))
-	} else {
-		hadWarning = true
-		spinner.UpdateMessage("Failed to get database size")
-		lg.Warn("Failed to get database size", logger.Error(err))
 	}
-
-	// Get table count
-	spinner.UpdateMessage("Counting tables...")
-	if count, err := getTableCount(db, config.DBName); err == nil {
-		info.TableCount = count
-		spinner.UpdateMessage(fmt.Sprintf("Tables: %d", info.TableCount))
-	} else {
-		hadWarning = true
-		spinner.UpdateMessage("Failed to get table count")
-		lg.Warn("Failed to get table count", logger.Error(err))
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"database size", func() error {
+			size, err := getDatabaseSize(ctx, db, config.DBName)
+			if err == nil {
+				info.SizeBytes = size
+				info.SizeMB = float64(size) / (1024 * 1024)
+				info.SizeHuman = common.FormatSize(size)
+			}
+			return err
+		}},
+		{"table count", count(&info.TableCount, getTableCount)},
+		{"view count", count(&info.ViewCount, getViewCount)},
+		{"routine count", count(&info.RoutineCount, getRoutineCount)},
+		{"trigger count", count(&info.TriggerCount, getTriggerCount)},
+		{"user count", count(&info.UserCount, getUserCount)},
 	}
 
-	// Get view count
-	spinner.UpdateMessage("Counting views...")
-	if count, err := getViewCount(db, config.DBName); err == nil {
-		info.ViewCount = count
-		spinner.UpdateMessage(fmt.Sprintf("Views: %d", info.ViewCount))
-	} else {
-		hadWarning = true
-		spinner.UpdateMessage("Failed to get view count")
-		lg.Warn("Failed to get view count", logger.Error(err))
+	progress := newSyncProgress(len(steps), "Collecting database metadata")
+	var warnings int32
+	tasks := make([]func(), len(steps))
+	for i, step := range steps {
+		tasks[i] = func() {
+			if err := step.run(); err != nil {
+				atomic.AddInt32(&warnings, 1)
+				lg.Warn("Failed to get "+step.name, logger.String("database", config.DBName), logger.Error(err))
+			}
+			progress.Increment()
+		}
 	}
+	runBounded(metadataWorkers, tasks)
+	progress.Finish()
 
-	// Get routine count (stored procedures + functions)
-	spinner.UpdateMessage("Counting routines (procs & funcs)...")
-	if count, err := getRoutineCount(db, config.DBName); err == nil {
-		info.RoutineCount = count
-		spinner.UpdateMessage(fmt.Sprintf("Routines: %d", info.RoutineCount))
-	} else {
-		hadWarning = true
-		spinner.UpdateMessage("Failed to get routine count")
-		lg.Warn("Failed to get routine count", logger.Error(err))
+	if warnings > 0 {
+		terminal.PrintWarning(fmt.Sprintf("Database information collected with %d warning(s)", warnings))
 	}
+	storeDatabaseInfo(config, info)
+	return info, nil
+}
 
-	// Get trigger count
-	spinner.UpdateMessage("Counting triggers...")
-	if count, err := getTriggerCount(db, config.DBName); err == nil {
-		info.TriggerCount = count
-		spinner.UpdateMessage(fmt.Sprintf("Triggers: %d", info.TriggerCount))
-	} else {
-		hadWarning = true
-		spinner.UpdateMessage("Failed to get trigger count")
-		lg.Warn("Failed to get trigger count", logger.Error(err))
+// DatabaseSize is the size and base table count of one database
+type DatabaseSize struct {
+	Name       string `json:"name"`
+	SizeBytes  int64  `json:"size_bytes"`
+	TableCount int    `json:"table_count"`
+}
+
+// ListDatabaseSizes returns the size (data and indexes) and base table count
+// of every user database, largest first. It is one information_schema query,
+// cheap enough to repeat, and is not cached.
+func ListDatabaseSizes(ctx context.Context, config database.Config) ([]DatabaseSize, error) {
+	config.DBName = ""
+	db, err := database.GetWithoutDB(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database server: %w", err)
 	}
+	defer db.Close()
 
-	// Get user count with grants to this database
-	spinner.UpdateMessage("Counting users with grants...")
-	if count, err := getUserCount(db, config.DBName); err == nil {
-		info.UserCount = count
-		spinner.UpdateMessage(fmt.Sprintf("Users with grants: %d", info.UserCount))
-	} else {
-		hadWarning = true
-		spinner.UpdateMessage("Failed to get user count")
-		lg.Warn("Failed to get user count", logger.Error(err))
+	rows, err := db.QueryContext(ctx, `
+		SELECT s.schema_name,
+			COALESCE(SUM(t.data_length + t.index_length), 0),
+			COUNT(CASE WHEN t.table_type = 'BASE TABLE' THEN 1 END)
+		FROM information_schema.schemata s
+		LEFT JOIN information_schema.tables t ON t.table_schema = s.schema_name
+		WHERE s.schema_name NOT IN ('information_schema', 'performance_schema', 'mysql', 'sys')
+		GROUP BY s.schema_name
+		ORDER BY 2 DESC, s.schema_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database sizes: %w", err)
 	}
+	defer rows.Close()
 
-	// Finalize spinner with appropriate final status
-	if hadWarning {
-		spinner.StopWithWarning("Completed with warnings")
-	} else {
-		spinner.StopWithSuccess("Database information collected")
+	var sizes []DatabaseSize
+	for rows.Next() {
+		var s DatabaseSize
+		if err := rows.Scan(&s.Name, &s.SizeBytes, &s.TableCount); err != nil {
+			return nil, fmt.Errorf("failed to read database sizes: %w", err)
+		}
+		sizes = append(sizes, s)
 	}
-	spinner.Stop()
-	return info, nil
+	return sizes, rows.Err()
 }
 
 // getDatabaseSize calculates the total size of a database in bytes
 // getDatabaseSize calculates the total size of a database in bytes using SHOW TABLE STATUS
-func getDatabaseSize(db *sql.DB, dbName string) (int64, error) {
+func getDatabaseSize(ctx context.Context, db *sql.DB, dbName string) (int64, error) {
 	// Use SHOW TABLE STATUS which is much faster than information_schema
 	query := "SHOW TABLE STATUS FROM " + "`" + dbName + "`"
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return 0, err
 	}
@@ -206,28 +218,28 @@ func getDatabaseSize(db *sql.DB, dbName string) (int64, error) {
 
 // getTableCount returns the number of tables in a database
 // getTableCount returns the number of BASE TABLES only (excluding views)
-func getTableCount(db *sql.DB, dbName string) (int, error) {
+func getTableCount(ctx context.Context, db *sql.DB, dbName string) (int, error) {
 	// Use information_schema to distinguish BASE TABLE from VIEW
 	query := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = ? AND table_type = 'BASE TABLE'"
 
 	var count int
-	err := db.QueryRow(query, dbName).Scan(&count)
+	err := db.QueryRowContext(ctx, query, dbName).Scan(&count)
 	if err != nil {
 		// Fallback to SHOW TABLES if information_schema fails
-		return getTableCountFallback(db, dbName)
+		return getTableCountFallback(ctx, db, dbName)
 	}
 
 	return count, nil
 }
 
 // Fallback method using SHOW FULL TABLES
-func getTableCountFallback(db *sql.DB, dbName string) (int, error) {
+func getTableCountFallback(ctx context.Context, db *sql.DB, dbName string) (int, error) {
 	query := "SHOW FULL TABLES FROM " + "`" + dbName + "`" + " WHERE Table_type = 'BASE TABLE'"
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		// Final fallback - assume all are tables
-		return getTableCountSimple(db, dbName)
+		return getTableCountSimple(ctx, db, dbName)
 	}
 	defer rows.Close()
 
@@ -239,10 +251,10 @@ func getTableCountFallback(db *sql.DB, dbName string) (int, error) {
 	return count, nil
 }
 
-func getTableCountSimple(db *sql.DB, dbName string) (int, error) {
+func getTableCountSimple(ctx context.Context, db *sql.DB, dbName string) (int, error) {
 	query := "SHOW TABLES FROM " + "`" + dbName + "`"
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return 0, err
 	}
@@ -257,15 +269,15 @@ func getTableCountSimple(db *sql.DB, dbName string) (int, error) {
 }
 
 // getViewCount returns the number of views in a database
-func getViewCount(db *sql.DB, dbName string) (int, error) {
+func getViewCount(ctx context.Context, db *sql.DB, dbName string) (int, error) {
 	// Try to use SHOW FULL TABLES to get views (faster than information_schema)
 	query := "SHOW FULL TABLES FROM " + "`" + dbName + "`" + " WHERE Table_type = 'VIEW'"
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		// Fallback: try without FULL keyword for older MySQL versions
 		fallbackQuery := "SHOW TABLES FROM " + "`" + dbName + "`"
-		fallbackRows, fallbackErr := db.Query(fallbackQuery)
+		fallbackRows, fallbackErr := db.QueryContext(ctx, fallbackQuery)
 		if fallbackErr != nil {
 			return 0, fallbackErr
 		}
@@ -287,12 +299,12 @@ func getViewCount(db *sql.DB, dbName string) (int, error) {
 
 // getRoutineCount returns the number of stored procedures and functions in a database
 // It tries a fast query on mysql.proc first, then falls back to information_schema.
-func getRoutineCount(db *sql.DB, dbName string) (int, error) {
+func getRoutineCount(ctx context.Context, db *sql.DB, dbName string) (int, error) {
 	var count int
 
 	// 1. Try the fast method first (querying mysql.proc)
 	procQuery := "SELECT COUNT(*) FROM mysql.proc WHERE db = ?"
-	err := db.QueryRow(procQuery, dbName).Scan(&count)
+	err := db.QueryRowContext(ctx, procQuery, dbName).Scan(&count)
 	if err == nil {
 		return count, nil // Success! Return the count.
 	}
@@ -301,11 +313,11 @@ func getRoutineCount(db *sql.DB, dbName string) (int, error) {
 }
 
 // getTriggerCount returns the number of triggers in a database
-func getTriggerCount(db *sql.DB, dbName string) (int, error) {
+func getTriggerCount(ctx context.Context, db *sql.DB, dbName string) (int, error) {
 	// Use SHOW TRIGGERS which is much faster than information_schema
 	showQuery := "SHOW TRIGGERS FROM " + "`" + dbName + "`"
 
-	rows, err := db.Query(showQuery)
+	rows, err := db.QueryContext(ctx, showQuery)
 	if err != nil {
 		// If SHOW TRIGGERS fails, return 0 to avoid breaking backup
 		return 0, nil
@@ -322,7 +334,7 @@ func getTriggerCount(db *sql.DB, dbName string) (int, error) {
 }
 
 // getUserCount returns the number of users with grants to a specific database
-func getUserCount(db *sql.DB, dbName string) (int, error) {
+func getUserCount(ctx context.Context, db *sql.DB, dbName string) (int, error) {
 	// This query gets users with specific database privileges
 	// Note: This might not work perfectly in all MySQL/MariaDB versions
 	// as it depends on the mysql.db table structure
@@ -333,18 +345,18 @@ func getUserCount(db *sql.DB, dbName string) (int, error) {
 	`
 
 	var count int
-	err := db.QueryRow(query, dbName).Scan(&count)
+	err := db.QueryRowContext(ctx, query, dbName).Scan(&count)
 	if err != nil {
 		// Fallback: try to get global user count if database-specific fails
 		fallbackQuery := `SELECT COUNT(*) FROM mysql.user`
-		err = db.QueryRow(fallbackQuery).Scan(&count)
+		err = db.QueryRowContext(ctx, fallbackQuery).Scan(&count)
 	}
 
 	return count, err
 }
 
 // GetDetailedTableInfo returns detailed information about tables in the database
-func GetDetailedTableInfo(config database.Config) ([]TableInfo, error) {
+func GetDetailedTableInfo(ctx context.Context, config database.Config) ([]TableInfo, error) {
 	lg, _ := logger.Get()
 
 	db, err := database.GetDatabaseConnection(config)
@@ -356,11 +368,11 @@ func GetDetailedTableInfo(config database.Config) ([]TableInfo, error) {
 	// Use SHOW FULL TABLES to get both tables and views
 	query := "SHOW FULL TABLES FROM " + "`" + config.DBName + "`"
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		// Fallback to simple SHOW TABLES if SHOW FULL TABLES fails
 		query = "SHOW TABLES FROM " + "`" + config.DBName + "`"
-		rows, err = db.Query(query)
+		rows, err = db.QueryContext(ctx, query)
 		if err != nil {
 			lg.Error("Failed to get table information", logger.Error(err))
 			return nil, fmt.Errorf("failed to get table information: %w", err)
@@ -419,11 +431,11 @@ type TableInfo struct {
 	TableType string `json:"table_type"`
 }
 
-// collectDatabaseInfo retrieves database information and logs it
-func CollectDatabaseInfo(config database.Config, lg *logger.Logger) *DatabaseInfo {
+// CollectDatabaseInfo retrieves database information and logs it
+func CollectDatabaseInfo(ctx context.Context, config database.Config, lg *logger.Logger) *DatabaseInfo {
 	lg.Debug("Collecting database information", logger.String("database", config.DBName))
 
-	dbInfo, err := GetDatabaseInfo(config)
+	dbInfo, err := GetDatabaseInfo(ctx, config)
 	if err != nil {
 		lg.Warn("Failed to collect database information", logger.Error(err))
 		return nil
@@ -124,6 +124,12 @@ func (m *MaxStatementTimeManager) Close() error {
 
 // setupMaxStatementTimeManager creates and configures max_statement_time manager
 func SetupMaxStatementTimeManager(config Config, lg *logger.Logger) (*MaxStatementTimeManager, error) {
+	if IsProtected(config.Host, config.Port) {
+		// SET GLOBAL would be refused on a protected source
+		lg.Info("Source is protected, leaving max_statement_time unchanged")
+		return nil, nil
+	}
+
 	timeManager, err := NewMaxStatementTimeManager(config)
 	if err != nil {
 		lg.Warn("Failed to create max_statement_time manager", logger.Error(err))
@@ -0,0 +1,16 @@
+package database
+
+import "strings"
+
+// stringEscaper escapes a string literal the way mysql_real_escape_string does
+var stringEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`)
+
+// QuoteIdent returns name as a backquoted identifier, doubling any backquote in it
+func QuoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// QuoteString returns s as a single-quoted SQL string literal
+func QuoteString(s string) string {
+	return "'" + stringEscaper.Replace(s) + "'"
+}
@@ -0,0 +1,29 @@
+package database
+
+import "testing"
+
+func TestQuoteIdent(t *testing.T) {
+	for name, want := range map[string]string{
+		"orders":     "`orders`",
+		"my`table":   "`my``table`",
+		"``":         "``````",
+		"sp ace.dot": "`sp ace.dot`",
+	} {
+		if got := QuoteIdent(name); got != want {
+			t.Errorf("QuoteIdent(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	for s, want := range map[string]string{
+		"plain":          `'plain'`,
+		"it's":           `'it\'s'`,
+		`C:\dir`:         `'C:\\dir'`,
+		"a\nb\r\x00\x1a": `'a\nb\r\0\Z'`,
+	} {
+		if got := QuoteString(s); got != want {
+			t.Errorf("QuoteString(%q) = %s, want %s", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"fmt"
+
+	"sfDBTools/internal/logger"
+)
+
+// SetGlobalReadOnly toggles the server-wide read_only flag. Users with SUPER
+// (or READ_ONLY ADMIN) privileges can still write while read_only is enabled.
+func SetGlobalReadOnly(config Config, enabled bool) error {
+	lg, _ := logger.Get()
+
+	configWithoutDB := config
+	configWithoutDB.DBName = ""
+
+	db, err := GetWithoutDB(configWithoutDB)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database server: %w", err)
+	}
+	defer db.Close()
+
+	value := "OFF"
+	if enabled {
+		value = "ON"
+	}
+
+	if _, err := db.Exec("SET GLOBAL read_only = " + value); err != nil {
+		lg.Error("Failed to set read_only", logger.String("value", value), logger.Error(err))
+		return fmt.Errorf("failed to set read_only=%s: %w", value, err)
+	}
+
+	lg.Info("Global read_only updated",
+		logger.String("host", config.Host),
+		logger.Int("port", config.Port),
+		logger.String("read_only", value))
+	return nil
+}
+
+// IsGlobalReadOnly reports whether the server currently has read_only enabled
+func IsGlobalReadOnly(config Config) (bool, error) {
+	configWithoutDB := config
+	configWithoutDB.DBName = ""
+
+	db, err := GetWithoutDB(configWithoutDB)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to database server: %w", err)
+	}
+	defer db.Close()
+
+	var readOnly int
+	if err := db.QueryRow("SELECT @@global.read_only").Scan(&readOnly); err != nil {
+		return false, fmt.Errorf("failed to read read_only: %w", err)
+	}
+	return readOnly == 1, nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/mariadb/versions"
 )
 
 // GTIDInfo represents GTID information
@@ -103,7 +104,7 @@ func checkGTIDEnabled(db *sql.DB) (bool, error) {
 	lg, _ := logger.Get()
 
 	// First check if this is MySQL or MariaDB
-	version, err := getMySQLVersionString(db)
+	version, err := versions.QueryFetcher{DB: db}.Fetch()
 	if err != nil {
 		lg.Warn("Could not determine database version", logger.Error(err))
 	}
@@ -156,7 +157,7 @@ func checkGTIDEnabled(db *sql.DB) (bool, error) {
 
 // getGTIDExecuted retrieves the GTID_EXECUTED global variable (MySQL) or gtid_current_pos (MariaDB)
 func getGTIDExecuted(db *sql.DB) (string, error) {
-	version, err := getMySQLVersionString(db)
+	version, err := versions.QueryFetcher{DB: db}.Fetch()
 	if err != nil {
 		// If we can't get version, try MySQL format first
 		return getGTIDExecutedMySQL(db)
@@ -200,7 +201,7 @@ func getGTIDExecutedMySQL(db *sql.DB) (string, error) {
 
 // getGTIDPurged retrieves the GTID_PURGED global variable (MySQL) or gtid_binlog_pos (MariaDB)
 func getGTIDPurged(db *sql.DB) (string, error) {
-	version, err := getMySQLVersionString(db)
+	version, err := versions.QueryFetcher{DB: db}.Fetch()
 	if err != nil {
 		// If we can't get version, try MySQL format first
 		return getGTIDPurgedMySQL(db)
@@ -245,7 +246,7 @@ func getGTIDPurgedMySQL(db *sql.DB) (string, error) {
 // getBinlogGTIDPos gets GTID position for a specific binlog file and position
 // This function only works on MariaDB, returns empty for MySQL
 func getBinlogGTIDPos(db *sql.DB, binlogFile string, binlogPos int64) (string, error) {
-	version, err := getMySQLVersionString(db)
+	version, err := versions.QueryFetcher{DB: db}.Fetch()
 	if err != nil {
 		// Can't determine version, skip BINLOG_GTID_POS
 		return "", nil
@@ -438,13 +439,3 @@ func GetReplicationInfo(config Config) (*ReplicationInfo, error) {
 
 	return replicationInfo, nil
 }
-
-// getMySQLVersionString gets the version string from database
-func getMySQLVersionString(db *sql.DB) (string, error) {
-	var version string
-	err := db.QueryRow("SELECT VERSION()").Scan(&version)
-	if err != nil {
-		return "", err
-	}
-	return version, nil
-}
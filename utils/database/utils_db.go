@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/mariadb/versions"
 )
 
 // getMySQLVersion gets the MySQL server version
@@ -13,9 +14,7 @@ func GetMySQLVersion(config Config) (string, error) {
 	}
 	defer db.Close()
 
-	var version string
-	err = db.QueryRow("SELECT VERSION()").Scan(&version)
-	return version, err
+	return versions.QueryFetcher{DB: db}.Fetch()
 }
 
 // validateConnection validates the database connection and user privileges
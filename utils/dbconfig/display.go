@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/common/structs"
 	"sfDBTools/utils/terminal"
 )
@@ -192,12 +193,7 @@ func DisplayFileList(files []string) {
 
 // formatFileSize returns human-readable file size
 func formatFileSize(size int64) string {
-	if size < 1024 {
-		return fmt.Sprintf("%d B", size)
-	} else if size < 1024*1024 {
-		return fmt.Sprintf("%.1f KB", float64(size)/1024)
-	}
-	return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	return common.HumanizeSize(size)
 }
 
 // getStatusText returns colored status text
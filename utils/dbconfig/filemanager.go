@@ -140,7 +140,8 @@ func (fm *FileManager) GetConfigFilePath(name string) string {
 	return filepath.Join(fm.configDir, name)
 }
 
-// BackupConfigFile creates a backup of a configuration file
+// BackupConfigFile creates a backup of a configuration file, readable only
+// by its owner like the profile itself
 func (fm *FileManager) BackupConfigFile(filePath string) (string, error) {
 	backupPath := filePath + ".backup." + time.Now().Format("20060102-150405")
 
@@ -150,7 +151,7 @@ func (fm *FileManager) BackupConfigFile(filePath string) (string, error) {
 	}
 	defer sourceFile.Close()
 
-	destFile, err := os.Create(backupPath)
+	destFile, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
 	if err != nil {
 		return "", fmt.Errorf("failed to create backup file: %v", err)
 	}
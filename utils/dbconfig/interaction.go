@@ -32,7 +32,7 @@ func ConfirmDeletion(deletionType DeletionType, items []string) bool {
 		terminal.PrintError("This will permanently delete all database configurations!")
 	}
 
-	return terminal.AskYesNo("Confirm deletion", false)
+	return terminal.Confirm("Confirm deletion", false)
 }
 
 // SelectFilesForDeletion prompts user to select files for deletion
@@ -102,7 +102,7 @@ func PromptForConfigName(availableConfigs []*FileInfo, operation OperationType)
 	if len(availableConfigs) == 1 {
 		configName := availableConfigs[0].Name
 		message := fmt.Sprintf("Use configuration '%s' for %s?", configName, operation.String())
-		if terminal.AskYesNo(message, true) {
+		if terminal.Confirm(message, true) {
 			return configName, nil
 		}
 		return "", fmt.Errorf("operation cancelled")
@@ -157,7 +157,7 @@ func PromptForConfigName(availableConfigs []*FileInfo, operation OperationType)
 // ConfirmOverwrite prompts user to confirm file overwrite
 func ConfirmOverwrite(filename string) bool {
 	message := fmt.Sprintf("Configuration '%s' already exists. Overwrite?", filename)
-	return terminal.AskYesNo(message, false)
+	return terminal.Confirm(message, false)
 }
 
 // PromptForFileSelection prompts user to select configuration files
@@ -0,0 +1,147 @@
+package dbconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/database/connection"
+	"sfDBTools/utils/secrets"
+)
+
+// ProfileExtension is the extension of encrypted connection profiles, the
+// files --config of backup and restore reads
+const ProfileExtension = ".cnf.enc"
+
+// profileName allows names that are safe as a file name and on a command line
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateProfileName rejects names that cannot be used as a profile file name
+func ValidateProfileName(name string) error {
+	if !profileName.MatchString(name) || strings.HasSuffix(name, ProfileExtension) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// ProfilePath returns the file of a profile reference: a path when ref
+// contains a directory, otherwise the name of a profile in the database
+// config directory (with or without .cnf.enc)
+func ProfilePath(ref string) (string, error) {
+	if strings.ContainsRune(ref, filepath.Separator) {
+		return ref, nil
+	}
+	configDir, err := config.GetDatabaseConfigDirectory()
+	if err != nil {
+		return "", fmt.Errorf("failed to get database config directory: %w", err)
+	}
+	return filepath.Join(configDir, strings.TrimSuffix(ref, ProfileExtension)+ProfileExtension), nil
+}
+
+// ProfileNameFromPath returns the profile name of a profile file
+func ProfileNameFromPath(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), ProfileExtension)
+}
+
+// LoadProfile decrypts the profile at path
+func LoadProfile(path, encryptionPassword string) (*config.EncryptedDatabaseConfig, error) {
+	return config.LoadEncryptedDatabaseConfigFromFile(path, encryptionPassword)
+}
+
+// SaveProfile encrypts dbConfig with encryptionPassword and writes it to
+// path with mode 0600. The file is replaced atomically through a temporary
+// file next to it, so an interrupted save leaves the previous profile intact.
+func SaveProfile(path string, dbConfig *config.EncryptedDatabaseConfig, encryptionPassword string) error {
+	data, err := json.Marshal(dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	key, err := crypto.DeriveKeyWithPassword(encryptionPassword)
+	if err != nil {
+		return fmt.Errorf("failed to derive encryption key: %w", err)
+	}
+	encrypted, err := crypto.EncryptData(data, key, crypto.AES_GCM)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt configuration: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := tmp.Chmod(0600); err != nil {
+		return err
+	}
+	if _, err := tmp.Write(encrypted); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// RekeyProfile makes the profile at path readable with newPassword instead
+// of oldPassword. The contents are unchanged.
+func RekeyProfile(path, oldPassword, newPassword string) error {
+	dbConfig, err := LoadProfile(path, oldPassword)
+	if err != nil {
+		return err
+	}
+	return SaveProfile(path, dbConfig, newPassword)
+}
+
+// TestProfile connects to every host of a profile with its credentials, the
+// way backup and restore would, and returns what probing found per host.
+// Secret references in user and password are resolved first.
+func TestProfile(dbConfig *config.EncryptedDatabaseConfig) ([]connection.EndpointHealth, error) {
+	if err := connection.ValidateAuthPlugin(dbConfig.AuthPlugin); err != nil {
+		return nil, err
+	}
+	if err := connection.ValidatePolicy(dbConfig.Policy); err != nil {
+		return nil, err
+	}
+	user, password, err := secrets.ResolveCredentials(dbConfig.User, dbConfig.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+	connConfig := connection.Config{
+		Host:       dbConfig.Host,
+		Port:       dbConfig.Port,
+		User:       user,
+		Password:   password,
+		Socket:     dbConfig.Socket,
+		AuthPlugin: dbConfig.AuthPlugin,
+	}
+
+	endpoints := []connection.Endpoint{{Host: dbConfig.Host, Port: dbConfig.Port}}
+	for _, h := range dbConfig.Hosts {
+		port := h.Port
+		if port == 0 {
+			port = dbConfig.Port
+		}
+		endpoints = append(endpoints, connection.Endpoint{Host: h.Host, Port: port})
+	}
+	health := make([]connection.EndpointHealth, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		health = append(health, connection.ProbeEndpoint(connConfig, endpoint))
+	}
+	return health, nil
+}
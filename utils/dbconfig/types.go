@@ -2,6 +2,7 @@ package dbconfig
 
 import (
 	"fmt"
+	"sfDBTools/utils/common"
 	"time"
 )
 
@@ -152,12 +153,7 @@ func (f *FileInfo) GetDisplayName() string {
 
 // GetFormattedSize returns human-readable file size
 func (f *FileInfo) GetFormattedSize() string {
-	if f.Size < 1024 {
-		return fmt.Sprintf("%d B", f.Size)
-	} else if f.Size < 1024*1024 {
-		return fmt.Sprintf("%.1f KB", float64(f.Size)/1024)
-	}
-	return fmt.Sprintf("%.1f MB", float64(f.Size)/(1024*1024))
+	return common.HumanizeSize(f.Size)
 }
 
 // HasErrors returns true if validation has errors
@@ -9,6 +9,7 @@ import (
 	"math"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/common/format"
 	"sfDBTools/utils/fs"
 )
@@ -34,18 +35,18 @@ func CheckDiskSpace(path string, minFreeSpace int64) error {
 
 	if usage.Free < required {
 		lg.Error("Insufficient disk space",
-			logger.String("available", format.FormatSizeWithPrecision(usage.Free, 2)),
-			logger.String("required", format.FormatSizeWithPrecision(required, 2)),
+			logger.String("available", common.HumanizeSize(usage.Free)),
+			logger.String("required", common.HumanizeSize(required)),
 			logger.String("checked_path", usage.Path),
 			logger.String("percent_free", format.FormatPercent(percentFree, 1)))
 		return fmt.Errorf("insufficient disk space: available %s, required %s (free %s)",
-			format.FormatSizeWithPrecision(usage.Free, 2),
-			format.FormatSizeWithPrecision(required, 2),
+			common.HumanizeSize(usage.Free),
+			common.HumanizeSize(required),
 			format.FormatPercent(percentFree, 1))
 	}
 
 	lg.Debug("Disk space check passed",
-		logger.String("available", format.FormatSizeWithPrecision(usage.Free, 2)),
+		logger.String("available", common.HumanizeSize(usage.Free)),
 		logger.String("checked_path", usage.Path),
 		logger.String("percent_free", format.FormatPercent(percentFree, 1)))
 	return nil
@@ -66,17 +67,17 @@ func CheckDiskSpaceBytes(path string, minFreeBytes int64) error {
 
 	if usage.Free < minFreeBytes {
 		lg.Error("Insufficient disk space",
-			logger.String("available", format.FormatSizeWithPrecision(usage.Free, 2)),
-			logger.String("required", format.FormatSizeWithPrecision(minFreeBytes, 2)),
+			logger.String("available", common.HumanizeSize(usage.Free)),
+			logger.String("required", common.HumanizeSize(minFreeBytes)),
 			logger.String("path", usage.Path))
 		return fmt.Errorf("insufficient disk space: available %s, required %s",
-			format.FormatSizeWithPrecision(usage.Free, 2),
-			format.FormatSizeWithPrecision(minFreeBytes, 2))
+			common.HumanizeSize(usage.Free),
+			common.HumanizeSize(minFreeBytes))
 	}
 
 	lg.Debug("Disk space check passed",
-		logger.String("available", format.FormatSizeWithPrecision(usage.Free, 2)),
-		logger.String("required", format.FormatSizeWithPrecision(minFreeBytes, 2)),
+		logger.String("available", common.HumanizeSize(usage.Free)),
+		logger.String("required", common.HumanizeSize(minFreeBytes)),
 		logger.String("path", usage.Path))
 	return nil
 }
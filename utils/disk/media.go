@@ -0,0 +1,141 @@
+package disk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gopsutildisk "github.com/shirou/gopsutil/v3/disk"
+)
+
+// Media kinds reported by DetectMedia
+const (
+	MediaSSD     = "ssd"
+	MediaHDD     = "hdd"
+	MediaUnknown = "unknown" // network, virtual or memory filesystems, or no sysfs
+)
+
+// MediaInfo describes the block device and filesystem behind a path
+type MediaInfo struct {
+	Path       string   `json:"path"`
+	Mountpoint string   `json:"mountpoint"`
+	Device     string   `json:"device"`
+	Fstype     string   `json:"fstype"`
+	Options    []string `json:"options"`
+	Media      string   `json:"media"`
+}
+
+// Rotational reports whether the path is on a spinning disk
+func (m *MediaInfo) Rotational() bool {
+	return m != nil && m.Media == MediaHDD
+}
+
+// String returns e.g. "ssd (ext4 on /dev/nvme0n1p2, noatime)"
+func (m *MediaInfo) String() string {
+	if m == nil {
+		return MediaUnknown
+	}
+	s := fmt.Sprintf("%s (%s on %s", m.Media, m.Fstype, m.Device)
+	for _, opt := range m.Options {
+		if opt == "noatime" || opt == "relatime" || strings.HasPrefix(opt, "barrier") || opt == "nobarrier" {
+			s += ", " + opt
+		}
+	}
+	return s + ")"
+}
+
+// DetectMedia finds the mount holding path and reads the rotational flag of
+// its block device from sysfs. path need not exist yet; its nearest existing
+// parent is used. Device-mapper and md devices report the flag of the stack.
+func DetectMedia(path string) (*MediaInfo, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, err := os.Stat(abs); err == nil {
+			break
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			break
+		}
+		abs = parent
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+
+	partitions, err := gopsutildisk.Partitions(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions: %w", err)
+	}
+	var best *gopsutildisk.PartitionStat
+	for i, p := range partitions {
+		if !within(abs, p.Mountpoint) {
+			continue
+		}
+		if best == nil || len(p.Mountpoint) >= len(best.Mountpoint) {
+			best = &partitions[i]
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no mount found for %s", path)
+	}
+
+	info := &MediaInfo{
+		Path:       path,
+		Mountpoint: best.Mountpoint,
+		Device:     best.Device,
+		Fstype:     best.Fstype,
+		Options:    best.Opts,
+		Media:      MediaUnknown,
+	}
+	if rotational, ok := readRotational(best.Device); ok {
+		info.Media = MediaSSD
+		if rotational {
+			info.Media = MediaHDD
+		}
+	}
+	return info, nil
+}
+
+// within reports whether path is mountpoint or below it
+func within(path, mountpoint string) bool {
+	if mountpoint == "/" || path == mountpoint {
+		return true
+	}
+	return strings.HasPrefix(path, mountpoint+"/")
+}
+
+// readRotational reads queue/rotational of device, looking at the parent disk
+// for a partition. ok is false for devices without a sysfs entry.
+func readRotational(device string) (rotational, ok bool) {
+	if !strings.HasPrefix(device, "/dev/") {
+		return false, false
+	}
+	if resolved, err := filepath.EvalSymlinks(device); err == nil {
+		device = resolved
+	}
+	sys, err := filepath.EvalSymlinks(filepath.Join("/sys/class/block", filepath.Base(device)))
+	if err != nil {
+		return false, false
+	}
+	for _, dir := range []string{sys, filepath.Dir(sys)} {
+		data, err := os.ReadFile(filepath.Join(dir, "queue", "rotational"))
+		if err == nil {
+			return strings.TrimSpace(string(data)) == "1", true
+		}
+	}
+	return false, false
+}
+
+// WriteBufferSize returns the buffer size for large sequential writes to
+// path: spinning disks gain from fewer, larger writes
+func WriteBufferSize(path string) int {
+	if m, err := DetectMedia(path); err == nil && m.Rotational() {
+		return 8 << 20
+	}
+	return 1 << 20
+}
@@ -10,6 +10,7 @@ import (
 	gopsutildisk "github.com/shirou/gopsutil/v3/disk"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/common/format"
 	"sfDBTools/utils/fs"
 )
@@ -64,8 +65,8 @@ func GetUsageStatistics(path string) (*UsageStatistics, error) {
 		logger.String("path", path),
 		logger.String("mountpoint", stats.Mountpoint),
 		logger.String("fstype", stats.Fstype),
-		logger.String("total", format.FormatSizeWithPrecision(stats.Total, 2)),
-		logger.String("free", format.FormatSizeWithPrecision(stats.Free, 2)),
+		logger.String("total", common.HumanizeSize(stats.Total)),
+		logger.String("free", common.HumanizeSize(stats.Free)),
 		logger.Float64("used_percent", stats.UsedPercent))
 
 	return stats, nil
@@ -173,7 +174,7 @@ func FindBestStorageLocation(candidates []string) (*UsageStatistics, error) {
 	lg.Info("Best storage location selected",
 		logger.String("path", bestStats.Path),
 		logger.String("mountpoint", bestStats.Mountpoint),
-		logger.String("free_space", format.FormatSizeWithPrecision(bestStats.Free, 2)),
+		logger.String("free_space", common.HumanizeSize(bestStats.Free)),
 		logger.Float64("used_percent", bestStats.UsedPercent))
 
 	return bestStats, nil
@@ -186,10 +187,10 @@ func (s *UsageStatistics) FormatUsageReport() string {
 		s.Path,
 		s.Mountpoint,
 		s.Fstype,
-		format.FormatSizeWithPrecision(s.Total, 2),
-		format.FormatSizeWithPrecision(s.Used, 2),
+		common.HumanizeSize(s.Total),
+		common.HumanizeSize(s.Used),
 		format.FormatPercent(s.UsedPercent, 1),
-		format.FormatSizeWithPrecision(s.Free, 2),
+		common.HumanizeSize(s.Free),
 	)
 }
 
@@ -0,0 +1,292 @@
+// Package download fetches files that are too valuable to fetch twice: the
+// MariaDB repository setup script and backups in remote storage. Data is
+// written to <dest>.part and renamed once complete; a failed transfer is
+// retried with backoff and resumes where the .part file ends instead of
+// starting from zero, as long as the file on the server has not changed.
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+)
+
+const (
+	// DefaultRetries is how often a failed transfer is retried when
+	// general.download.retries is not set
+	DefaultRetries = 4
+	// DefaultBackoff is the wait before the first retry when
+	// general.download.retry_backoff is not set; it doubles on every retry
+	DefaultBackoff = 2 * time.Second
+	// maxBackoff caps the wait between two attempts
+	maxBackoff = time.Minute
+)
+
+// PartSuffix is appended to the destination while a download is incomplete
+const PartSuffix = ".part"
+
+// validatorSuffix names the file next to the .part file that keeps the ETag
+// or Last-Modified of the response the .part file was started from
+const validatorSuffix = ".validator"
+
+// Retry is how often and how patiently a failed transfer is attempted again
+type Retry struct {
+	Retries int
+	Backoff time.Duration
+}
+
+// RetryFromConfig returns general.download.retries and
+// general.download.retry_backoff, or the defaults when they are not set
+func RetryFromConfig() Retry {
+	r := Retry{Retries: DefaultRetries, Backoff: DefaultBackoff}
+	cfg, err := config.Get()
+	if err != nil {
+		return r
+	}
+	if cfg.General.Download.Retries > 0 {
+		r.Retries = cfg.General.Download.Retries
+	}
+	if cfg.General.Download.RetryBackoff != "" {
+		if d, err := time.ParseDuration(cfg.General.Download.RetryBackoff); err == nil && d > 0 {
+			r.Backoff = d
+		}
+	}
+	return r
+}
+
+// permanentError marks a failure that another attempt cannot fix
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so Do returns it without retrying
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Do calls fn until it succeeds, returns a Permanent error or the retries are
+// used up, waiting Backoff, 2*Backoff, ... (at most a minute) in between.
+// what names the transfer in the log.
+func (r Retry) Do(ctx context.Context, what string, fn func() error) error {
+	lg, _ := logger.Get()
+	wait := r.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if attempt > r.Retries {
+			return fmt.Errorf("%w (gave up after %d attempts)", err, attempt)
+		}
+		lg.Warn("Download failed, retrying",
+			logger.String("source", what),
+			logger.Int("attempt", attempt),
+			logger.String("retry_in", wait.String()),
+			logger.Error(err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		if wait *= 2; wait > maxBackoff {
+			wait = maxBackoff
+		}
+	}
+}
+
+// Options tunes File
+type Options struct {
+	// Label titles the progress bar; empty shows none
+	Label string
+	// Retry overrides RetryFromConfig when Retries or Backoff is set
+	Retry Retry
+}
+
+// File downloads url to dest. Bytes go to dest.part, which a failed attempt
+// leaves in place: the next attempt, or the next call for the same dest,
+// asks the server for the rest with a Range request guarded by If-Range.
+// When the file has changed since, or the server ignores the range, it sends
+// the whole file again, which replaces the .part file. A .part file without
+// a known ETag or Last-Modified is downloaded again from the start.
+func File(ctx context.Context, url, dest string, opts Options) error {
+	retry := opts.Retry
+	if retry.Retries == 0 && retry.Backoff == 0 {
+		retry = RetryFromConfig()
+	}
+	if retry.Backoff <= 0 {
+		retry.Backoff = DefaultBackoff
+	}
+
+	part := dest + PartSuffix
+	progress := NewProgress(opts.Label)
+	defer progress.Finish()
+
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   30 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	}}
+	err := retry.Do(ctx, url, func() error {
+		return fetch(ctx, client, url, part, progress)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	if err := os.Rename(part, dest); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", part, err)
+	}
+	os.Remove(part + validatorSuffix)
+	return nil
+}
+
+// fetch requests what part is still missing and appends it
+func fetch(ctx context.Context, client *http.Client, url, part string, progress *Progress) error {
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Permanent(err)
+	}
+	if offset > 0 {
+		// Without a validator the rest might belong to another version of the file
+		if validator := readValidator(part); validator != "" {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			req.Header.Set("If-Range", validator)
+		} else {
+			offset = 0
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Permanent(ctx.Err())
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			return fmt.Errorf("server answered range %d- with %q", offset, resp.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		// No range was asked for, the file has changed or the server ignored
+		// the range: start over
+		offset = 0
+		flags |= os.O_TRUNC
+		if err := saveValidator(part, resp.Header); err != nil {
+			return Permanent(err)
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The .part file already holds the whole file when its size is the
+		// one the server reports
+		if total, ok := contentRangeTotal(resp.Header.Get("Content-Range")); ok && total == offset {
+			return nil
+		}
+		os.Remove(part)
+		os.Remove(part + validatorSuffix)
+		return fmt.Errorf("partial download %s does not match the file on the server", part)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return Permanent(fmt.Errorf("status code %d", resp.StatusCode))
+	default:
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	if resp.ContentLength >= 0 {
+		progress.SetTotal(offset + resp.ContentLength)
+	}
+	progress.Update(offset)
+
+	f, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return Permanent(fmt.Errorf("failed to open %s: %w", part, err))
+	}
+	_, copyErr := io.Copy(f, progress.Reader(resp.Body, offset))
+	if err := f.Close(); copyErr == nil && err != nil {
+		return Permanent(fmt.Errorf("failed to write %s: %w", part, err))
+	}
+	if copyErr != nil {
+		if ctx.Err() != nil {
+			return Permanent(ctx.Err())
+		}
+		return copyErr
+	}
+	return nil
+}
+
+// readValidator returns the If-Range value saved for part, empty when none is
+func readValidator(part string) string {
+	data, err := os.ReadFile(part + validatorSuffix)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// saveValidator keeps the strong ETag of a full response, or its
+// Last-Modified, so a later resume of part can send it as If-Range. Weak
+// ETags are not allowed in If-Range.
+func saveValidator(part string, header http.Header) error {
+	validator := header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = header.Get("Last-Modified")
+	}
+	file := part + validatorSuffix
+	if validator == "" {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(file, []byte(validator+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+// contentRangeStart parses the first byte of "bytes 100-199/200"
+func contentRangeStart(header string) (int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	return n, err == nil
+}
+
+// contentRangeTotal parses the size of "bytes */200" or "bytes 100-199/200"
+func contentRangeTotal(header string) (int64, bool) {
+	_, total, ok := strings.Cut(header, "/")
+	if !ok || total == "*" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(total, 10, 64)
+	return n, err == nil
+}
@@ -0,0 +1,97 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serve answers with content, honouring Range and If-Range against etag, and
+// records the Range header of every request
+func serve(t *testing.T, content []byte, etag string, ranges *[]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFileResume(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 100))
+
+	tests := []struct {
+		name      string
+		part      []byte
+		validator string
+		wantRange string
+	}{
+		{name: "no partial download", wantRange: ""},
+		{name: "same file resumes", part: content[:300], validator: `"v1"`, wantRange: "bytes=300-"},
+		{name: "changed file starts over", part: []byte(strings.Repeat("x", 300)), validator: `"v0"`, wantRange: "bytes=300-"},
+		{name: "unknown version starts over", part: []byte(strings.Repeat("x", 300)), wantRange: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+			srv := serve(t, content, `"v1"`, &ranges)
+			dest := filepath.Join(t.TempDir(), "file")
+			if tt.part != nil {
+				os.WriteFile(dest+PartSuffix, tt.part, 0644)
+			}
+			if tt.validator != "" {
+				os.WriteFile(dest+PartSuffix+validatorSuffix, []byte(tt.validator), 0644)
+			}
+
+			if err := File(context.Background(), srv.URL, dest, Options{Retry: Retry{Retries: 1, Backoff: time.Millisecond}}); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile(dest)
+			if !bytes.Equal(got, content) {
+				t.Errorf("downloaded %d bytes that differ from the file on the server", len(got))
+			}
+			if len(ranges) != 1 || ranges[0] != tt.wantRange {
+				t.Errorf("requested ranges %q, want one request with %q", ranges, tt.wantRange)
+			}
+			for _, leftover := range []string{dest + PartSuffix, dest + PartSuffix + validatorSuffix} {
+				if _, err := os.Stat(leftover); err == nil {
+					t.Errorf("%s left behind", filepath.Base(leftover))
+				}
+			}
+		})
+	}
+}
+
+func TestSaveValidator(t *testing.T) {
+	part := filepath.Join(t.TempDir(), "file.part")
+	tests := []struct {
+		etag, lastModified, want string
+	}{
+		{`"abc"`, "Mon, 02 Jan 2006 15:04:05 GMT", `"abc"`},
+		{`W/"abc"`, "Mon, 02 Jan 2006 15:04:05 GMT", "Mon, 02 Jan 2006 15:04:05 GMT"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.etag != "" {
+			header.Set("ETag", tt.etag)
+		}
+		if tt.lastModified != "" {
+			header.Set("Last-Modified", tt.lastModified)
+		}
+		if err := saveValidator(part, header); err != nil {
+			t.Fatal(err)
+		}
+		if got := readValidator(part); got != tt.want {
+			t.Errorf("ETag %q, Last-Modified %q: validator %q, want %q", tt.etag, tt.lastModified, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package download
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"sfDBTools/utils/terminal"
+)
+
+// progressMin is the size from which a download shows a progress bar; the
+// setup script and manifests finish before a bar is worth drawing
+const progressMin = 1 << 20
+
+// progressRedraw limits how often the progress bar is redrawn
+const progressRedraw = 200 * time.Millisecond
+
+// Progress draws a transfer bar fed by the bytes downloaded so far. It draws
+// nothing without a label, a known total of at least progressMin, or a
+// terminal on stdout.
+type Progress struct {
+	label    string
+	bar      *terminal.ProgressBar
+	done     int64
+	lastDraw time.Time
+}
+
+// NewProgress returns the progress of a download titled label; the bar
+// appears once SetTotal reports the size
+func NewProgress(label string) *Progress {
+	return &Progress{label: label}
+}
+
+// SetTotal sets the size of the whole download
+func (p *Progress) SetTotal(total int64) {
+	if p.bar != nil || p.label == "" || total < progressMin || !terminal.StdoutIsTerminal() {
+		return
+	}
+	p.bar = terminal.NewTransferProgressBar(total, p.label)
+}
+
+// Update records that done bytes are downloaded
+func (p *Progress) Update(done int64) {
+	p.done = done
+	if p.bar != nil && time.Since(p.lastDraw) >= progressRedraw {
+		p.bar.Update(int(done))
+		p.lastDraw = time.Now()
+	}
+}
+
+// Reader returns r counting its bytes on top of offset bytes already downloaded
+func (p *Progress) Reader(r io.Reader, offset int64) io.Reader {
+	return &progressReader{r: r, p: p, done: offset}
+}
+
+// Finish draws the final state and ends the bar's line
+func (p *Progress) Finish() {
+	if p.bar != nil {
+		p.bar.Update(int(p.done))
+		fmt.Println()
+		p.bar = nil
+	}
+}
+
+type progressReader struct {
+	r    io.Reader
+	p    *Progress
+	done int64
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.done += int64(n)
+	r.p.Update(r.done)
+	return n, err
+}
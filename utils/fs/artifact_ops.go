@@ -0,0 +1,117 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/fs/policy"
+
+	"github.com/spf13/afero"
+)
+
+// Artefak adalah file yang dibuat sfDBTools sendiri (output backup, manifest,
+// file sementara). Permission dan ownership-nya mengikuti file_policy, berbeda
+// dengan direktori MariaDB yang punya aturan sendiri.
+
+// CreateFile membuat (atau mengosongkan) file artefak sesuai file policy
+func (f *fileOperations) CreateFile(path string) (afero.File, error) {
+	p := policy.Current()
+
+	file, err := f.fs.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, p.FileMode)
+	if err != nil {
+		return nil, fmt.Errorf("gagal buat file %s: %w", path, err)
+	}
+	applyPolicy(f.fs, f.logger, p, path, false)
+	return file, nil
+}
+
+// WriteFile menulis data ke file artefak sesuai file policy
+func (f *fileOperations) WriteFile(path string, data []byte) error {
+	if err := f.EnsureArtifactDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("gagal pastikan parent dir: %w", err)
+	}
+
+	file, err := f.CreateFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("gagal tulis file %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// EnsureArtifactDir memastikan direktori artefak ada; direktori yang baru
+// dibuat mendapat mode dan ownership dari file policy
+func (f *fileOperations) EnsureArtifactDir(path string) error {
+	if path == "" || path == "." {
+		return nil
+	}
+	return createArtifactDir(f.fs, f.logger, filepath.Clean(path))
+}
+
+// CreateArtifactDir membuat direktori artefak sesuai file policy lalu
+// memvalidasi bahwa direktori tersebut dapat ditulis
+func (d *directoryOperations) CreateArtifactDir(path string) error {
+	if path == "" {
+		return fmt.Errorf("path tidak boleh kosong")
+	}
+
+	normalizedPath := filepath.Clean(path)
+	if err := createArtifactDir(d.fs, d.logger, normalizedPath); err != nil {
+		return err
+	}
+	return d.validateDirectory(normalizedPath)
+}
+
+// createArtifactDir membuat path beserta parent yang belum ada dan menerapkan
+// file policy hanya pada direktori yang baru dibuat
+func createArtifactDir(fsys afero.Fs, lg *logger.Logger, path string) error {
+	p := policy.Current()
+
+	var missing []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if exists, _ := afero.Exists(fsys, dir); exists {
+			break
+		}
+		missing = append(missing, dir)
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if err := fsys.MkdirAll(path, p.DirMode); err != nil {
+		return fmt.Errorf("gagal buat dir %s: %w", path, err)
+	}
+	for _, dir := range missing {
+		applyPolicy(fsys, lg, p, dir, true)
+	}
+
+	lg.Debug("Direktori artefak dibuat",
+		logger.String("path", path),
+		logger.String("mode", p.DirMode.String()))
+	return nil
+}
+
+// applyPolicy menerapkan mode dan ownership policy. Kegagalan hanya dicatat
+// di level debug karena sudah diperingatkan sekali saat startup.
+func applyPolicy(fsys afero.Fs, lg *logger.Logger, p policy.Policy, path string, isDir bool) {
+	mode := p.FileMode
+	if isDir {
+		mode = p.DirMode
+	}
+	if err := fsys.Chmod(path, mode); err != nil {
+		lg.Debug("Gagal set permission sesuai policy", logger.String("path", path), logger.Error(err))
+	}
+	if uid, gid := p.IDs(); uid >= 0 || gid >= 0 {
+		if err := fsys.Chown(path, uid, gid); err != nil {
+			lg.Debug("Gagal set ownership sesuai policy", logger.String("path", path), logger.Error(err))
+		}
+	}
+}
@@ -3,13 +3,130 @@ package fs
 import (
 	"crypto/md5"
 	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"os"
+	"strings"
 
 	"sfDBTools/internal/logger"
 )
 
+// Checksum algorithms. SHA-256 is the default and the one assumed for
+// checksums recorded without an algorithm; XXH64 is a much faster
+// non-cryptographic alternative for large files.
+const (
+	ChecksumSHA256 = "sha256"
+	ChecksumSHA512 = "sha512"
+	ChecksumXXH64  = "xxh64"
+	ChecksumMD5    = "md5"
+)
+
+// checksumBlock is the read size while hashing, and so the granularity of
+// progress callbacks
+const checksumBlock = 1 << 20
+
+// ChecksumProgress receives the bytes hashed so far and the size of the file
+type ChecksumProgress func(done, total int64)
+
+// ErrChecksumMismatch is returned by VerifyFileChecksum when the file does not
+// match the expected checksum
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ValidateChecksumAlgorithm checks an algorithm given by the user
+func ValidateChecksumAlgorithm(algorithm string) error {
+	if _, err := NewChecksumHash(algorithm); err != nil {
+		return fmt.Errorf("%w (supported: %s, %s, %s, %s)", err, ChecksumSHA256, ChecksumSHA512, ChecksumXXH64, ChecksumMD5)
+	}
+	return nil
+}
+
+// NewChecksumHash returns the hash of algorithm; empty means SHA-256
+func NewChecksumHash(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case "", ChecksumSHA256:
+		return sha256.New(), nil
+	case ChecksumSHA512:
+		return sha512.New(), nil
+	case ChecksumXXH64:
+		return newXXH64(), nil
+	case ChecksumMD5:
+		return md5.New(), nil
+	}
+	return nil, fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
+}
+
+// FileChecksum streams filePath through algorithm and returns the hex digest
+func FileChecksum(filePath, algorithm string) (string, error) {
+	return FileChecksumWithProgress(filePath, algorithm, nil)
+}
+
+// FileChecksumWithProgress is FileChecksum reporting the bytes hashed to
+// progress after every block read; progress may be nil
+func FileChecksumWithProgress(filePath, algorithm string, progress ChecksumProgress) (string, error) {
+	h, err := NewChecksumHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	var w io.Writer = h
+	if progress != nil {
+		var total int64
+		if info, err := file.Stat(); err == nil {
+			total = info.Size()
+		}
+		w = &checksumProgressWriter{w: h, total: total, progress: progress}
+	}
+	// Hide os.File.WriteTo so the copy reads whole blocks
+	if _, err := io.CopyBuffer(w, struct{ io.Reader }{file}, make([]byte, checksumBlock)); err != nil {
+		return "", fmt.Errorf("failed to calculate checksum for %s: %w", filePath, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyFileChecksum checks filePath against an expected hex checksum, e.g.
+// one recorded in backup metadata. The error wraps ErrChecksumMismatch when
+// the file was read but does not match.
+func VerifyFileChecksum(filePath, expected, algorithm string) error {
+	return VerifyFileChecksumWithProgress(filePath, expected, algorithm, nil)
+}
+
+// VerifyFileChecksumWithProgress is VerifyFileChecksum reporting the bytes
+// hashed to progress; progress may be nil
+func VerifyFileChecksumWithProgress(filePath, expected, algorithm string, progress ChecksumProgress) error {
+	actual, err := FileChecksumWithProgress(filePath, algorithm, progress)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("%w for %s: expected %s, got %s", ErrChecksumMismatch, filePath, expected, actual)
+	}
+	return nil
+}
+
+// checksumProgressWriter passes writes to w and reports the running total
+type checksumProgressWriter struct {
+	w        io.Writer
+	done     int64
+	total    int64
+	progress ChecksumProgress
+}
+
+func (c *checksumProgressWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.done += int64(n)
+	c.progress(c.done, c.total)
+	return n, err
+}
+
 // ChecksumOperations provides file checksum calculation and comparison operations
 type ChecksumOperations interface {
 	CalculateMD5(filePath string) (string, error)
@@ -30,34 +147,12 @@ func newChecksumOperations(logger *logger.Logger) ChecksumOperations {
 
 // CalculateMD5 calculates MD5 checksum of a file
 func (c *checksumOperations) CalculateMD5(filePath string) (string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open file %s: %w", filePath, err)
-	}
-	defer file.Close()
-
-	hash := md5.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return "", fmt.Errorf("failed to calculate MD5 for %s: %w", filePath, err)
-	}
-
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+	return FileChecksum(filePath, ChecksumMD5)
 }
 
 // CalculateSHA256 calculates SHA256 checksum of a file
 func (c *checksumOperations) CalculateSHA256(filePath string) (string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open file %s: %w", filePath, err)
-	}
-	defer file.Close()
-
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return "", fmt.Errorf("failed to calculate SHA256 for %s: %w", filePath, err)
-	}
-
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+	return FileChecksum(filePath, ChecksumSHA256)
 }
 
 // CompareFiles compares two files by their MD5 checksums
@@ -77,21 +172,9 @@ func (c *checksumOperations) CompareFiles(file1, file2 string) (bool, error) {
 
 // VerifyChecksum verifies a file against an expected checksum using specified algorithm
 func (c *checksumOperations) VerifyChecksum(filePath, expectedChecksum, algorithm string) (bool, error) {
-	var actualChecksum string
-	var err error
-
-	switch algorithm {
-	case "md5":
-		actualChecksum, err = c.CalculateMD5(filePath)
-	case "sha256":
-		actualChecksum, err = c.CalculateSHA256(filePath)
-	default:
-		return false, fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
+	err := VerifyFileChecksum(filePath, expectedChecksum, algorithm)
+	if errors.Is(err, ErrChecksumMismatch) {
+		return false, nil
 	}
-
-	if err != nil {
-		return false, err
-	}
-
-	return actualChecksum == expectedChecksum, nil
+	return err == nil, err
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
 )
 
 // CleanupResult merepresentasikan hasil operasi cleanup
@@ -19,7 +20,7 @@ type CleanupResult struct {
 
 // GetFormattedSizeFreed mengembalikan ukuran yang dibebaskan dalam format yang mudah dibaca
 func (c *CleanupResult) GetFormattedSizeFreed() string {
-	return FormatSize(c.TotalSizeFreed)
+	return common.HumanizeSize(c.TotalSizeFreed)
 }
 
 // Cleaner handles cleanup operations
@@ -10,10 +10,11 @@ import (
 	"time"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/terminal"
 
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/afero"
-	"golang.org/x/term"
 )
 
 // fileOperations mengimplementasikan FileOperations interface
@@ -66,7 +67,7 @@ func (f *fileOperations) CopyWithInfo(src, dst string, info os.FileInfo) error {
 
 	useProgressBar := false
 	if total > 0 {
-		if term.IsTerminal(int(os.Stdout.Fd())) {
+		if terminal.StdoutIsTerminal() {
 			useProgressBar = true
 		}
 	}
@@ -75,7 +76,7 @@ func (f *fileOperations) CopyWithInfo(src, dst string, info os.FileInfo) error {
 		// Create a progress bar that writes to stdout. Throttle updates to
 		// avoid excessive redraws.
 		// Include human-readable size in the description so user sees "Copying foo (1.2 MiB)"
-		desc := fmt.Sprintf("Copying %s (%s)", filepath.Base(src), humanizeBytes(uint64(total)))
+		desc := fmt.Sprintf("Copying %s (%s)", filepath.Base(src), common.HumanizeSize(total))
 		bar := progressbar.NewOptions64(
 			total,
 			progressbar.OptionSetWriter(os.Stdout),
@@ -156,21 +157,6 @@ func (f *fileOperations) CopyWithInfo(src, dst string, info os.FileInfo) error {
 	return nil
 }
 
-// FormatSize mengubah ukuran byte menjadi string yang lebih mudah dibaca
-func humanizeBytes(bytes uint64) string {
-	sizes := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
-	f := float64(bytes)
-	i := 0
-	for f >= 1024 && i < len(sizes)-1 {
-		f /= 1024
-		i++
-	}
-	if i == 0 {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	return fmt.Sprintf("%.1f %s", f, sizes[i])
-}
-
 // Move memindahkan file dari src ke dst
 func (f *fileOperations) Move(src, dst string) error {
 	if err := f.Copy(src, dst); err != nil {
@@ -189,13 +175,13 @@ func (f *fileOperations) EnsureDir(path string) error {
 	return f.fs.MkdirAll(normalizedPath, 0755)
 }
 
-// WriteJSON menulis data sebagai JSON ke file
+// WriteJSON menulis data sebagai JSON ke file sesuai file policy
 func (f *fileOperations) WriteJSON(path string, data interface{}) error {
-	if err := f.EnsureDir(filepath.Dir(path)); err != nil {
+	if err := f.EnsureArtifactDir(filepath.Dir(path)); err != nil {
 		return fmt.Errorf("gagal pastikan parent dir: %w", err)
 	}
 
-	file, err := f.fs.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	file, err := f.CreateFile(path)
 	if err != nil {
 		return fmt.Errorf("gagal buat file JSON: %w", err)
 	}
@@ -4,6 +4,8 @@ package fs
 import (
 	"io/fs"
 	"os"
+
+	"github.com/spf13/afero"
 )
 
 // FileSystem menyediakan operasi filesystem dasar
@@ -32,12 +34,18 @@ type FileOperations interface {
 	Move(src, dst string) error
 	EnsureDir(path string) error
 	WriteJSON(path string, data interface{}) error
+
+	// Artefak (backup, manifest, file sementara) mengikuti file policy
+	CreateFile(path string) (afero.File, error)
+	WriteFile(path string, data []byte) error
+	EnsureArtifactDir(path string) error
 }
 
 // DirectoryOperations menyediakan operasi direktori tingkat tinggi
 type DirectoryOperations interface {
 	Create(path string) error
 	CreateWithPerms(path string, mode os.FileMode, owner, group string) error
+	CreateArtifactDir(path string) error
 	Exists(path string) bool
 	IsWritable(path string) error
 	GetSize(path string) (int64, error)
@@ -0,0 +1,261 @@
+// Package policy holds the permission and ownership policy applied to files
+// sfDBTools creates (backup outputs, manifests, logs, temp files). It has no
+// dependency on the logger so the logger itself can honor the policy.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"sfDBTools/internal/config/model"
+)
+
+// Policy describes the mode and ownership given to created artifacts.
+// A uid or gid of -1 leaves that part of the ownership unchanged.
+type Policy struct {
+	FileMode os.FileMode
+	DirMode  os.FileMode
+	Owner    string
+	Group    string
+	uid      int
+	gid      int
+}
+
+// Default matches the permissions used before a policy was configurable
+var Default = Policy{FileMode: 0644, DirMode: 0755, uid: -1, gid: -1}
+
+var (
+	mu      sync.RWMutex
+	current = Default
+)
+
+// FromConfig builds a policy from the file_policy config section. Empty fields
+// keep the default. Invalid values are dropped and reported as warnings.
+func FromConfig(cfg model.FilePolicyConfig) (Policy, []string) {
+	p := Default
+	var warnings []string
+
+	if cfg.FileMode != "" {
+		if mode, err := parseMode(cfg.FileMode); err != nil {
+			warnings = append(warnings, fmt.Sprintf("invalid file_policy.file_mode %q: %v", cfg.FileMode, err))
+		} else {
+			p.FileMode = mode
+		}
+	}
+	if cfg.DirMode != "" {
+		if mode, err := parseMode(cfg.DirMode); err != nil {
+			warnings = append(warnings, fmt.Sprintf("invalid file_policy.dir_mode %q: %v", cfg.DirMode, err))
+		} else {
+			p.DirMode = mode
+		}
+	}
+	if cfg.Owner != "" {
+		if uid, err := lookupUID(cfg.Owner); err != nil {
+			warnings = append(warnings, fmt.Sprintf("file_policy.owner: %v", err))
+		} else {
+			p.Owner, p.uid = cfg.Owner, uid
+		}
+	}
+	if cfg.Group != "" {
+		if gid, err := lookupGID(cfg.Group); err != nil {
+			warnings = append(warnings, fmt.Sprintf("file_policy.group: %v", err))
+		} else {
+			p.Group, p.gid = cfg.Group, gid
+		}
+	}
+	return p, warnings
+}
+
+// Configure installs the policy built from cfg as the process-wide policy and
+// returns warnings for settings that are invalid or cannot be honored.
+func Configure(cfg model.FilePolicyConfig) []string {
+	p, warnings := FromConfig(cfg)
+	warnings = append(warnings, p.Problems()...)
+	Set(p)
+	return warnings
+}
+
+// Set replaces the process-wide policy
+func Set(p Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = p
+}
+
+// Current returns the process-wide policy
+func Current() Policy {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Problems reports ownership settings the current process cannot apply
+func (p Policy) Problems() []string {
+	euid := os.Geteuid()
+	if euid == 0 || euid == -1 {
+		return nil
+	}
+
+	var problems []string
+	if p.uid >= 0 && p.uid != euid {
+		problems = append(problems, fmt.Sprintf("cannot give files to owner %s: changing ownership requires root", p.Owner))
+	}
+	if p.gid >= 0 && !inGroup(p.gid) {
+		problems = append(problems, fmt.Sprintf("cannot give files to group %s: process is not a member", p.Group))
+	}
+	return problems
+}
+
+// IDs returns the resolved owner and group IDs, -1 when not set
+func (p Policy) IDs() (uid, gid int) {
+	return p.uid, p.gid
+}
+
+// Apply sets the policy mode and ownership on an existing path
+func (p Policy) Apply(path string, isDir bool) error {
+	mode := p.FileMode
+	if isDir {
+		mode = p.DirMode
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		return err
+	}
+	if p.uid >= 0 || p.gid >= 0 {
+		if err := os.Chown(path, p.uid, p.gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateFile creates or truncates path for writing with the current policy.
+// Ownership failures are ignored here; they are reported once at startup.
+func CreateFile(path string) (*os.File, error) {
+	return Current().CreateFile(path)
+}
+
+// OpenAppend opens path for appending, creating it with the current policy
+func OpenAppend(path string) (*os.File, error) {
+	return Current().OpenAppend(path)
+}
+
+// WriteFile writes data to path with the current policy
+func WriteFile(path string, data []byte) error {
+	return Current().WriteFile(path, data)
+}
+
+// MkdirAll creates path and any missing parents with the current policy
+func MkdirAll(path string) error {
+	return Current().MkdirAll(path)
+}
+
+// CreateFile creates or truncates path for writing with this policy
+func (p Policy) CreateFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, p.FileMode)
+	if err != nil {
+		return nil, err
+	}
+	_ = p.Apply(path, false)
+	return f, nil
+}
+
+// OpenAppend opens path for appending, creating it with this policy
+func (p Policy) OpenAppend(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, p.FileMode)
+	if err != nil {
+		return nil, err
+	}
+	_ = p.Apply(path, false)
+	return f, nil
+}
+
+// WriteFile writes data to path with this policy
+func (p Policy) WriteFile(path string, data []byte) error {
+	f, err := p.CreateFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// MkdirAll creates path and any missing parents, applying this policy to the
+// directories it creates. Existing directories are left untouched.
+func (p Policy) MkdirAll(path string) error {
+	path = filepath.Clean(path)
+
+	var missing []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+
+	if err := os.MkdirAll(path, p.DirMode); err != nil {
+		return err
+	}
+	for _, dir := range missing {
+		_ = p.Apply(dir, true)
+	}
+	return nil
+}
+
+// parseMode parses an octal permission string such as "0640"
+func parseMode(s string) (os.FileMode, error) {
+	v, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("must be an octal mode such as 0640")
+	}
+	if v > 0777 {
+		return 0, fmt.Errorf("only permission bits (0000-0777) are allowed")
+	}
+	return os.FileMode(v), nil
+}
+
+func lookupUID(owner string) (int, error) {
+	if u, err := user.Lookup(owner); err == nil {
+		return strconv.Atoi(u.Uid)
+	}
+	if uid, err := strconv.Atoi(owner); err == nil {
+		return uid, nil
+	}
+	return -1, fmt.Errorf("user not found: %s", owner)
+}
+
+func lookupGID(group string) (int, error) {
+	if g, err := user.LookupGroup(group); err == nil {
+		return strconv.Atoi(g.Gid)
+	}
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+	return -1, fmt.Errorf("group not found: %s", group)
+}
+
+// inGroup reports whether the process has gid as its effective or a supplementary group
+func inGroup(gid int) bool {
+	if os.Getegid() == gid {
+		return true
+	}
+	groups, err := os.Getgroups()
+	if err != nil {
+		return false
+	}
+	for _, g := range groups {
+		if g == gid {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
 
 	"github.com/spf13/afero"
 )
@@ -27,7 +28,7 @@ func (e *Entry) GetFormattedSize() string {
 	if e.IsDir {
 		return "<DIR>"
 	}
-	return FormatSize(e.Size)
+	return common.HumanizeSize(e.Size)
 }
 
 // IsOlderThan mengecek apakah entry lebih tua dari durasi yang ditentukan
@@ -0,0 +1,218 @@
+package fs
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/shutdown"
+)
+
+// treeCopyBlock adalah ukuran blok salin; blok yang seluruhnya nol di file
+// sparse dilewati dengan seek sehingga hole tetap menjadi hole
+const treeCopyBlock = 1 << 20
+
+// TreeCopyOptions mengatur CopyTree
+type TreeCopyOptions struct {
+	Progress io.Writer // menerima setiap byte file yang disalin; boleh nil
+	Verify   bool      // baca ulang setiap file tujuan dan bandingkan checksum-nya dengan sumber
+}
+
+// TreeCopyResult merangkum hasil CopyTree
+type TreeCopyResult struct {
+	Files    int
+	Dirs     int
+	Symlinks int
+	Bytes    int64
+	Skipped  []string // special file (socket, device, pipe) yang tidak disalin
+}
+
+// CopyTree menyalin direktori source ke destination secara rekursif tanpa
+// bergantung pada rsync. Ownership, permission, waktu modifikasi dan extended
+// attribute (termasuk ACL dan label SELinux) ikut disalin, file sparse tetap
+// sparse, dan dengan Verify setiap file dicek dengan checksum XXH64.
+// Ownership dan xattr yang gagal disalin (bukan root, filesystem tanpa xattr)
+// hanya dicatat di log.
+func CopyTree(source, destination string, opts TreeCopyOptions) (*TreeCopyResult, error) {
+	lg, _ := logger.Get()
+	result := &TreeCopyResult{}
+	// Waktu direktori di-set setelah isinya selesai disalin, dari yang terdalam
+	var dirs []string
+
+	err := filepath.WalkDir(source, func(path string, d iofs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return fmt.Errorf("gagal membaca %s: %w", path, walkErr)
+		}
+		if shutdown.Context().Err() != nil {
+			return fmt.Errorf("penyalinan dibatalkan di %s", path)
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(destination, rel)
+		info, err := os.Lstat(path)
+		if err != nil {
+			return fmt.Errorf("gagal stat %s: %w", path, err)
+		}
+
+		switch mode := info.Mode(); {
+		case mode.IsDir():
+			if err := os.MkdirAll(dst, 0700); err != nil {
+				return fmt.Errorf("gagal membuat direktori %s: %w", dst, err)
+			}
+			copyMetadata(path, dst, info, lg)
+			dirs = append(dirs, path)
+			result.Dirs++
+		case mode&os.ModeSymlink != 0:
+			if err := copySymlink(path, dst, info, lg); err != nil {
+				return err
+			}
+			result.Symlinks++
+		case mode.IsRegular():
+			if err := copyRegularFile(path, dst, info, opts); err != nil {
+				return err
+			}
+			copyMetadata(path, dst, info, lg)
+			result.Files++
+			result.Bytes += info.Size()
+		default:
+			lg.Warn("Special file dilewati", logger.String("path", path))
+			result.Skipped = append(result.Skipped, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rel, _ := filepath.Rel(source, dirs[i])
+		if info, err := os.Stat(dirs[i]); err == nil {
+			_ = os.Chtimes(filepath.Join(destination, rel), info.ModTime(), info.ModTime())
+		}
+	}
+	return result, nil
+}
+
+// copyRegularFile menyalin isi file; file sumber yang sparse disalin dengan
+// melewati blok nol, dan dengan Verify checksum sumber yang dihitung saat
+// menyalin dibandingkan dengan checksum file tujuan
+func copyRegularFile(src, dst string, info os.FileInfo, opts TreeCopyOptions) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("gagal membuka %s: %w", src, err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("gagal membuat %s: %w", dst, err)
+	}
+
+	var reader io.Reader = in
+	sourceHash, _ := NewChecksumHash(ChecksumXXH64)
+	if opts.Verify {
+		reader = io.TeeReader(reader, sourceHash)
+	}
+	if opts.Progress != nil {
+		reader = io.TeeReader(reader, opts.Progress)
+	}
+
+	if isSparse(info) {
+		err = copySparse(out, reader, info.Size())
+	} else {
+		_, err = io.CopyBuffer(out, reader, make([]byte, treeCopyBlock))
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("gagal menyalin %s ke %s: %w", src, dst, err)
+	}
+
+	if opts.Verify {
+		expected := hex.EncodeToString(sourceHash.Sum(nil))
+		if err := VerifyFileChecksum(dst, expected, ChecksumXXH64); err != nil {
+			return fmt.Errorf("verifikasi salinan %s gagal: %w", src, err)
+		}
+	}
+	return nil
+}
+
+// isSparse melaporkan apakah file memakai lebih sedikit blok dari ukurannya,
+// heuristik yang sama dengan cp --sparse=auto
+func isSparse(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && st.Blocks*512 < info.Size()
+}
+
+// copySparse menulis isi reader ke out dan melompati blok yang seluruhnya
+// nol, lalu memotong file ke size agar hole di akhir file ikut terbentuk
+func copySparse(out *os.File, reader io.Reader, size int64) error {
+	buf := make([]byte, treeCopyBlock)
+	zero := make([]byte, treeCopyBlock)
+	for {
+		n, err := io.ReadFull(reader, buf)
+		if n > 0 {
+			if bytes.Equal(buf[:n], zero[:n]) {
+				if _, err := out.Seek(int64(n), io.SeekCurrent); err != nil {
+					return err
+				}
+			} else if _, err := out.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return out.Truncate(size)
+}
+
+// copySymlink membuat ulang symlink dengan target dan ownership yang sama
+func copySymlink(src, dst string, info os.FileInfo, lg *logger.Logger) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return fmt.Errorf("gagal membaca symlink %s: %w", src, err)
+	}
+	_ = os.Remove(dst)
+	if err := os.Symlink(target, dst); err != nil {
+		return fmt.Errorf("gagal membuat symlink %s -> %s: %w", dst, target, err)
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		if err := os.Lchown(dst, int(st.Uid), int(st.Gid)); err != nil {
+			lg.Debug("Gagal preserve ownership symlink", logger.String("path", dst), logger.Error(err))
+		}
+	}
+	return nil
+}
+
+// copyMetadata menyalin ownership, permission (termasuk setuid, setgid dan
+// sticky), xattr dan waktu modifikasi dari src ke dst
+func copyMetadata(src, dst string, info os.FileInfo, lg *logger.Logger) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		if err := os.Chown(dst, int(st.Uid), int(st.Gid)); err != nil {
+			lg.Debug("Gagal preserve ownership", logger.String("path", dst), logger.Error(err))
+		}
+	}
+	// Chmod setelah chown, karena chown menghapus bit setuid/setgid
+	if err := os.Chmod(dst, info.Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky)); err != nil {
+		lg.Warn("Gagal set permission", logger.String("path", dst), logger.Error(err))
+	}
+	if err := copyXattrs(src, dst); err != nil {
+		lg.Debug("Gagal menyalin xattr", logger.String("path", dst), logger.Error(err))
+	}
+	if !info.IsDir() {
+		_ = os.Chtimes(dst, info.ModTime(), info.ModTime())
+	}
+}
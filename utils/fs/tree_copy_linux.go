@@ -0,0 +1,50 @@
+//go:build linux
+
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+)
+
+// copyXattrs menyalin semua extended attribute yang bisa dibaca dari src
+func copyXattrs(src, dst string) error {
+	size, err := syscall.Listxattr(src, nil)
+	if err != nil || size == 0 {
+		if errors.Is(err, syscall.ENOTSUP) {
+			return nil
+		}
+		return err
+	}
+	buf := make([]byte, size)
+	size, err = syscall.Listxattr(src, buf)
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, name := range strings.Split(strings.TrimRight(string(buf[:size]), "\x00"), "\x00") {
+		if name == "" {
+			continue
+		}
+		n, err := syscall.Getxattr(src, name, nil)
+		if err != nil {
+			failed = append(failed, name)
+			continue
+		}
+		value := make([]byte, n)
+		if n, err = syscall.Getxattr(src, name, value); err != nil {
+			failed = append(failed, name)
+			continue
+		}
+		if err := syscall.Setxattr(dst, name, value[:n], 0); err != nil {
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("xattr tidak tersalin: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package fs
+
+// copyXattrs tidak menyalin apa pun di luar Linux; hanya ownership,
+// permission dan waktu modifikasi yang dipertahankan
+func copyXattrs(src, dst string) error {
+	return nil
+}
@@ -9,22 +9,6 @@ import (
 	"time"
 )
 
-// FormatSize memformat ukuran byte menjadi string yang mudah dibaca
-func FormatSize(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
-
 // IsHidden mengecek apakah file/direktori hidden berdasarkan platform
 func IsHidden(name string) bool {
 	if runtime.GOOS == "windows" {
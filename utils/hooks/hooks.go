@@ -0,0 +1,267 @@
+// Package hooks runs the commands and HTTP calls configured in the hooks
+// section of the config before and after heavy operations. Commands call
+// Before when the operation is about to start and Run.After with its
+// outcome; the maintenance session does this for the commands using it.
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/config/model"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Phases, failure actions and outcomes of model.HookConfig
+const (
+	PhasePre  = "pre"
+	PhasePost = "post"
+
+	FailAbort = "abort"
+	FailWarn  = "warn"
+
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// defaultTimeout bounds a hook when neither it nor hooks.timeout sets one
+const defaultTimeout = 5 * time.Minute
+
+// Hook is one parsed hook of the config
+type Hook struct {
+	Name       string
+	Phase      string
+	operations []string
+	command    string
+	url        string
+	method     string
+	headers    map[string]string
+	onFailure  string
+	when       string
+	timeout    time.Duration
+}
+
+// Event describes the operation a hook runs for. Commands get it in
+// environment variables, HTTP hooks as the JSON body.
+type Event struct {
+	Phase     string            `json:"phase"`
+	Hook      string            `json:"hook"`
+	Operation string            `json:"operation"`
+	Command   string            `json:"command"`
+	Flags     map[string]string `json:"flags,omitempty"` // set on the command line, secrets left out
+	Host      string            `json:"host"`
+	PID       int               `json:"pid"`
+	Started   time.Time         `json:"started"`
+	Status    string            `json:"status,omitempty"` // post hooks only
+	Error     string            `json:"error,omitempty"`
+	Duration  float64           `json:"duration_seconds,omitempty"`
+}
+
+// Run holds the post hooks of an operation that passed its pre hooks. A nil
+// Run is a no-op.
+type Run struct {
+	event Event
+	post  []Hook
+}
+
+// Load parses cfg into its pre and post hooks
+func Load(cfg model.HooksConfig) (pre, post []Hook, err error) {
+	timeout := defaultTimeout
+	if cfg.Timeout != "" {
+		if timeout, err = common.ParseDurationWithDays(cfg.Timeout); err != nil {
+			return nil, nil, fmt.Errorf("hooks: timeout: %w", err)
+		}
+	}
+	if pre, err = loadPhase(PhasePre, cfg.Pre, timeout); err != nil {
+		return nil, nil, err
+	}
+	if post, err = loadPhase(PhasePost, cfg.Post, timeout); err != nil {
+		return nil, nil, err
+	}
+	return pre, post, nil
+}
+
+func loadPhase(phase string, configs []model.HookConfig, timeout time.Duration) ([]Hook, error) {
+	hooks := make([]Hook, 0, len(configs))
+	for i, c := range configs {
+		h := Hook{
+			Name:       c.Name,
+			Phase:      phase,
+			operations: c.Operations,
+			command:    c.Command,
+			url:        c.URL,
+			method:     strings.ToUpper(c.Method),
+			headers:    c.Headers,
+			onFailure:  strings.ToLower(c.OnFailure),
+			when:       strings.ToLower(c.When),
+			timeout:    timeout,
+		}
+		if h.Name == "" {
+			h.Name = fmt.Sprintf("%s[%d]", phase, i)
+		}
+		where := fmt.Sprintf("hooks: %s hook %s", phase, h.Name)
+		switch {
+		case h.command == "" && h.url == "":
+			return nil, fmt.Errorf("%s: set command or url", where)
+		case h.command != "" && h.url != "":
+			return nil, fmt.Errorf("%s: set either command or url, not both", where)
+		}
+		if h.method == "" {
+			h.method = "POST"
+		}
+		switch h.onFailure {
+		case "":
+			h.onFailure = FailWarn
+			if phase == PhasePre {
+				h.onFailure = FailAbort
+			}
+		case FailAbort, FailWarn:
+		default:
+			return nil, fmt.Errorf("%s: on_failure must be %s or %s, got %q", where, FailAbort, FailWarn, c.OnFailure)
+		}
+		switch h.when {
+		case "", "always":
+			h.when = ""
+		case StatusSuccess, StatusFailure:
+			if phase == PhasePre {
+				return nil, fmt.Errorf("%s: when only applies to post hooks", where)
+			}
+		default:
+			return nil, fmt.Errorf("%s: when must be %s, %s or always, got %q", where, StatusSuccess, StatusFailure, c.When)
+		}
+		if c.Timeout != "" {
+			d, err := common.ParseDurationWithDays(c.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("%s: timeout: %w", where, err)
+			}
+			h.timeout = d
+		}
+		hooks = append(hooks, h)
+	}
+	return hooks, nil
+}
+
+// Applies reports whether the hook runs for operation
+func (h Hook) Applies(operation string) bool {
+	if len(h.operations) == 0 {
+		return true
+	}
+	for _, op := range h.operations {
+		op = strings.ToLower(strings.TrimSpace(op))
+		if op == "*" || operation == op || strings.HasPrefix(operation, op+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// Before runs the pre hooks configured for operation. A failing hook with
+// on_failure abort stops the operation before it starts. The returned Run
+// runs the post hooks; it is nil when no hook applies.
+func Before(cmd *cobra.Command, operation string) (*Run, error) {
+	lg, _ := logger.Get()
+	cfg, err := config.Get()
+	if err != nil {
+		return nil, nil
+	}
+	pre, post, err := Load(cfg.Hooks)
+	if err != nil {
+		return nil, common.WithExitCode(err, common.ExitConfig)
+	}
+	pre, post = applying(pre, operation), applying(post, operation)
+	if len(pre) == 0 && len(post) == 0 {
+		return nil, nil
+	}
+
+	r := &Run{event: newEvent(cmd, operation), post: post}
+	for _, h := range pre {
+		if err := h.run(r.event); err != nil {
+			if h.onFailure == FailAbort {
+				lg.Error("Pre hook failed, operation not started", logger.String("hook", h.Name), logger.String("operation", operation), logger.Error(err))
+				return nil, fmt.Errorf("pre hook %s failed, %s not started: %w", h.Name, operation, err)
+			}
+			lg.Warn("Pre hook failed", logger.String("hook", h.Name), logger.String("operation", operation), logger.Error(err))
+		}
+	}
+	return r, nil
+}
+
+// After runs the post hooks with the outcome of the operation and returns
+// opErr. When the operation succeeded, a failing hook with on_failure abort
+// is returned instead.
+func (r *Run) After(opErr error) error {
+	if r == nil {
+		return opErr
+	}
+	lg, _ := logger.Get()
+	event := r.event
+	event.Phase = PhasePost
+	event.Duration = time.Since(event.Started).Seconds()
+	event.Status = StatusSuccess
+	if opErr != nil {
+		event.Status, event.Error = StatusFailure, opErr.Error()
+	}
+
+	var hookErr error
+	for _, h := range r.post {
+		if h.when != "" && h.when != event.Status {
+			continue
+		}
+		if err := h.run(event); err != nil {
+			lg.Warn("Post hook failed", logger.String("hook", h.Name), logger.String("operation", event.Operation), logger.Error(err))
+			if h.onFailure == FailAbort && hookErr == nil {
+				hookErr = fmt.Errorf("post hook %s failed after %s: %w", h.Name, event.Operation, err)
+			}
+		}
+	}
+	if opErr != nil {
+		return opErr
+	}
+	return hookErr
+}
+
+func applying(hooks []Hook, operation string) []Hook {
+	var out []Hook
+	for _, h := range hooks {
+		if h.Applies(operation) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+func newEvent(cmd *cobra.Command, operation string) Event {
+	e := Event{Phase: PhasePre, Operation: operation, PID: os.Getpid(), Started: time.Now()}
+	e.Host, _ = os.Hostname()
+	if cmd != nil {
+		e.Command = cmd.CommandPath()
+		cmd.Flags().Visit(func(f *pflag.Flag) {
+			if secretFlag(f.Name) {
+				return
+			}
+			if e.Flags == nil {
+				e.Flags = make(map[string]string)
+			}
+			e.Flags[f.Name] = f.Value.String()
+		})
+	}
+	return e
+}
+
+// secretFlag reports whether a flag may carry a credential hooks must not see
+func secretFlag(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"password", "secret", "token"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
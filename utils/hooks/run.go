@@ -0,0 +1,125 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"sfDBTools/internal/logger"
+)
+
+// run executes the hook for event, bounded by the hook's timeout
+func (h Hook) run(event Event) error {
+	lg, _ := logger.Get()
+	event.Hook = h.Name
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if h.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+	}
+	defer cancel()
+
+	lg.Info("Running hook", logger.String("hook", h.Name), logger.String("phase", event.Phase), logger.String("operation", event.Operation))
+	start := time.Now()
+	var err error
+	if h.url != "" {
+		err = h.call(ctx, event)
+	} else {
+		err = h.exec(ctx, event)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", h.timeout)
+	}
+	if err == nil {
+		lg.Debug("Hook finished", logger.String("hook", h.Name), logger.String("duration", time.Since(start).Round(time.Millisecond).String()))
+	}
+	return err
+}
+
+// exec runs the hook command through sh with the event in the environment
+func (h Hook) exec(ctx context.Context, event Event) error {
+	lg, _ := logger.Get()
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.command)
+	cmd.Env = append(os.Environ(), eventEnv(event)...)
+	// On timeout stop what the shell started too, not only the shell
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error { return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) }
+	cmd.WaitDelay = time.Second
+	out, err := cmd.CombinedOutput()
+	if text := strings.TrimSpace(string(out)); text != "" {
+		lg.Debug("Hook output", logger.String("hook", h.Name), logger.String("output", text))
+		if err != nil {
+			return fmt.Errorf("command failed: %w: %s", err, text)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("command failed: %w", err)
+	}
+	return nil
+}
+
+// call sends the event as JSON to the hook URL
+func (h Hook) call(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, h.method, h.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid hook url: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The URL may carry a token; report the host only
+		return fmt.Errorf("%s %s failed: %w", h.method, req.URL.Host, errors.Unwrap(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s", h.method, req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+// eventEnv returns the event as SFDB_HOOK_* and SFDB_OPERATION_* variables;
+// each command line flag becomes SFDB_FLAG_<NAME>
+func eventEnv(e Event) []string {
+	env := []string{
+		"SFDB_HOOK_NAME=" + e.Hook,
+		"SFDB_HOOK_PHASE=" + e.Phase,
+		"SFDB_OPERATION=" + e.Operation,
+		"SFDB_OPERATION_COMMAND=" + e.Command,
+		"SFDB_OPERATION_HOST=" + e.Host,
+		"SFDB_OPERATION_PID=" + strconv.Itoa(e.PID),
+		"SFDB_OPERATION_STARTED=" + e.Started.Format(time.RFC3339),
+	}
+	if e.Phase == PhasePost {
+		env = append(env,
+			"SFDB_OPERATION_STATUS="+e.Status,
+			"SFDB_OPERATION_ERROR="+e.Error,
+			"SFDB_OPERATION_DURATION="+strconv.FormatFloat(e.Duration, 'f', 0, 64),
+		)
+	}
+	names := make([]string, 0, len(e.Flags))
+	for name := range e.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+		env = append(env, "SFDB_FLAG_"+key+"="+e.Flags[name])
+	}
+	return env
+}
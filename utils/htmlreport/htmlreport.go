@@ -0,0 +1,168 @@
+// Package htmlreport turns the progress events of a run (see package progress)
+// into a self-contained HTML file: the warnings of the run, then one section
+// per job with its steps, durations, transferred bytes, result details,
+// warnings and outcome. The file
+// has inline styles only, so it can be attached to an email as is.
+package htmlreport
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/fs/policy"
+	"sfDBTools/utils/i18n"
+	"sfDBTools/utils/progress"
+	"sfDBTools/utils/warnings"
+)
+
+// Report is everything shown in the HTML file
+type Report struct {
+	Title    string
+	Command  string
+	Host     string
+	Started  time.Time
+	Finished time.Time
+	Error    string // the command's error, empty on success
+	Warnings []warnings.Item
+	Jobs     []*Job
+}
+
+// Job is one operation reported through a jobstatus tracker
+type Job struct {
+	ID        string
+	Operation string
+	Target    string
+	Started   time.Time
+	Duration  time.Duration
+	Finished  bool
+	Error     string
+	Steps     []Step
+	Details   []Detail
+	Warnings  []string
+}
+
+// Step is one finished step of a job
+type Step struct {
+	Name     string
+	Duration time.Duration
+	Bytes    int64
+}
+
+// Detail is a labelled fact of a job's result
+type Detail struct {
+	Label string
+	Value string
+}
+
+// Collector builds a Report from progress events
+type Collector struct {
+	mu     sync.Mutex
+	report Report
+	byID   map[string]*Job
+	cancel func()
+}
+
+// Collect starts collecting events for a report titled title
+func Collect(title, command string) *Collector {
+	host, _ := os.Hostname()
+	c := &Collector{
+		report: Report{Title: title, Command: command, Host: host, Started: time.Now()},
+		byID:   make(map[string]*Job),
+	}
+	c.cancel = progress.Subscribe(c.handle)
+	return c
+}
+
+func (c *Collector) handle(e progress.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	job := c.byID[e.Job]
+	if job == nil {
+		job = &Job{ID: e.Job, Operation: e.Operation, Target: e.Target, Started: e.Time}
+		c.byID[e.Job] = job
+		c.report.Jobs = append(c.report.Jobs, job)
+	}
+	switch e.Kind {
+	case progress.KindStepFinished:
+		job.Steps = append(job.Steps, Step{Name: e.Step, Duration: seconds(e.Duration), Bytes: e.BytesDone})
+	case progress.KindWarning:
+		job.Warnings = append(job.Warnings, e.Message)
+	case progress.KindDetail:
+		job.Details = append(job.Details, Detail{Label: e.Label, Value: e.Message})
+	case progress.KindFinished:
+		job.Finished = true
+		job.Duration = seconds(e.Duration)
+		job.Error = e.Error
+	}
+}
+
+// Write stops collecting and writes the report to path. runErr is the
+// command's result.
+func (c *Collector) Write(path string, runErr error) error {
+	c.cancel()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.report.Finished = time.Now()
+	if runErr != nil {
+		c.report.Error = runErr.Error()
+	}
+	c.report.Warnings = warnings.Items()
+
+	var b strings.Builder
+	if err := page.Execute(&b, &c.report); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return policy.WriteFile(path, []byte(b.String()))
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Millisecond)
+}
+
+var page = template.Must(template.New("report").Funcs(template.FuncMap{
+	"size": func(n int64) string { return common.FormatSize(n) },
+	"when": func(t time.Time) string { return t.Format("2006-01-02 15:04:05 MST") },
+	"took": func(d time.Duration) string { return d.Round(time.Second).String() },
+	"t":    i18n.T,
+	"lang": i18n.Lang,
+}).Parse(`<!DOCTYPE html>
+<html lang="{{lang}}"><head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="font-family:Arial,Helvetica,sans-serif;font-size:14px;color:#222;margin:24px">
+<h2 style="margin:0 0 4px 0">{{.Title}}</h2>
+{{if .Error}}<div style="display:inline-block;padding:4px 10px;background:#c62828;color:#fff;border-radius:3px;font-weight:bold">{{t "report.badge_failed"}}</div>
+{{else}}<div style="display:inline-block;padding:4px 10px;background:#2e7d32;color:#fff;border-radius:3px;font-weight:bold">{{t "report.badge_succeeded"}}</div>{{end}}
+<table style="border-collapse:collapse;margin:12px 0">
+<tr><td style="padding:2px 12px 2px 0;color:#666">{{t "report.command"}}</td><td><code>{{.Command}}</code></td></tr>
+<tr><td style="padding:2px 12px 2px 0;color:#666">{{t "report.host"}}</td><td>{{.Host}}</td></tr>
+<tr><td style="padding:2px 12px 2px 0;color:#666">{{t "report.started"}}</td><td>{{when .Started}}</td></tr>
+<tr><td style="padding:2px 12px 2px 0;color:#666">{{t "report.finished"}}</td><td>{{when .Finished}} ({{took (.Finished.Sub .Started)}})</td></tr>
+{{if .Error}}<tr><td style="padding:2px 12px 2px 0;color:#666">{{t "report.error"}}</td><td style="color:#c62828">{{.Error}}</td></tr>{{end}}
+</table>
+{{if .Warnings}}<div style="background:#fff8e1;border-left:4px solid #ef6c00;padding:6px 10px;margin:8px 0"><b>{{t "report.warnings"}} ({{len .Warnings}})</b><ol style="margin:4px 0">
+{{range .Warnings}}<li>{{.Message}}{{if gt .Count 1}} (x{{.Count}}){{end}}</li>
+{{end}}</ol></div>{{end}}
+{{range .Jobs}}
+<h3 style="margin:20px 0 6px 0;border-bottom:1px solid #ddd;padding-bottom:4px">{{.Operation}}{{if .Target}} &ndash; {{.Target}}{{end}}
+{{if .Error}}<span style="color:#c62828">{{t "report.job_failed"}}</span>{{else if .Finished}}<span style="color:#2e7d32">{{t "report.job_completed"}}</span>{{else}}<span style="color:#ef6c00">{{t "report.job_unfinished"}}</span>{{end}}</h3>
+<div style="color:#666">{{t "report.started"}} {{when .Started}}{{if .Finished}}, {{t "report.took"}} {{took .Duration}}{{end}}</div>
+{{if .Error}}<p style="color:#c62828">{{.Error}}</p>{{end}}
+{{if .Steps}}<table style="border-collapse:collapse;margin:8px 0">
+<tr style="background:#f0f0f0"><th style="text-align:left;padding:4px 12px;border:1px solid #ddd">{{t "report.step"}}</th><th style="text-align:right;padding:4px 12px;border:1px solid #ddd">{{t "report.duration"}}</th><th style="text-align:right;padding:4px 12px;border:1px solid #ddd">{{t "report.data"}}</th></tr>
+{{range .Steps}}<tr><td style="padding:4px 12px;border:1px solid #ddd">{{.Name}}</td><td style="text-align:right;padding:4px 12px;border:1px solid #ddd">{{.Duration}}</td><td style="text-align:right;padding:4px 12px;border:1px solid #ddd">{{if .Bytes}}{{size .Bytes}}{{end}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Details}}<table style="border-collapse:collapse;margin:8px 0">
+{{range .Details}}<tr><td style="padding:2px 12px 2px 0;color:#666">{{.Label}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Warnings}}<div style="background:#fff8e1;border-left:4px solid #ef6c00;padding:6px 10px;margin:8px 0"><b>{{t "report.warnings"}}</b><ul style="margin:4px 0">
+{{range .Warnings}}<li>{{.}}</li>
+{{end}}</ul></div>{{end}}
+{{else}}<p style="color:#666">{{t "report.no_jobs"}}</p>
+{{end}}
+<p style="color:#999;font-size:12px;margin-top:24px">{{t "report.generated"}}</p>
+</body></html>
+`))
@@ -0,0 +1,189 @@
+package i18n
+
+// English messages. Every key used with T must be defined here; other catalogs
+// may omit keys and fall back to these.
+var catalogEN = map[string]string{
+	"mariadb.install.update_cache":         "[Package Manager] Update Cache",
+	"mariadb.install.updating_cache":       "Updating package manager cache...",
+	"mariadb.install.update_cache_failed":  "Failed to update package manager cache",
+	"mariadb.install.cache_updated":        "Package manager cache updated",
+	"mariadb.install.upgrade_packages":     "[Package Manager] Upgrade System Packages",
+	"mariadb.install.upgrading_packages":   "Upgrading system packages...",
+	"mariadb.install.upgrade_failed":       "Failed to upgrade system packages",
+	"mariadb.install.packages_upgraded":    "System packages upgraded",
+	"mariadb.install.install_packages":     "[Package Manager] Install MariaDB Packages",
+	"mariadb.install.resolving_packages":   "Resolving and installing MariaDB packages...",
+	"mariadb.install.detect_os_failed":     "Failed to detect the OS to choose packages",
+	"mariadb.install.package_names_failed": "Failed to determine MariaDB package names",
+	"mariadb.install.packages_resolved":    "MariaDB package list resolved",
+	"mariadb.install.installing_package":   "[%d/%d] Installing package: %s",
+	"mariadb.install.package_failed":       "Failed to install package",
+
+	"common.done": "Done",
+
+	"mariadb.install.repo_setup":         "[Repository] Setup",
+	"mariadb.install.checking_repo":      "Checking existing MariaDB repository...",
+	"mariadb.install.check_repo_failed":  "Failed to check the existing repository",
+	"mariadb.install.repo_ok":            "MariaDB repository is already configured, no setup needed",
+	"mariadb.install.old_repo_found":     "Existing repository found, it will be backed up before setup",
+	"mariadb.install.backing_up_repo":    "Backing up the old MariaDB repository...",
+	"mariadb.install.backup_repo_failed": "Failed to back up the old repository",
+	"mariadb.install.repo_backed_up":     "Old repository backed up to %s",
+	"mariadb.install.no_old_repo":        "No existing MariaDB repository found",
+	"mariadb.install.downloading_script": "Downloading repository setup script...",
+	"mariadb.install.download_failed":    "Failed to download the repository setup script",
+	"mariadb.install.downloaded":         "Repository setup script downloaded",
+	"mariadb.install.running_script":     "Running repository setup script...",
+	"mariadb.install.script_failed":      "Failed to run the repository setup script",
+	"mariadb.install.script_done":        "Repository setup script completed",
+	"mariadb.install.precheck_title":     "MariaDB Pre-Installation Checks",
+	"mariadb.install.title":              "MariaDB Installation Process",
+	"mariadb.install.post_title":         "MariaDB Post-Installation Setup",
+	"mariadb.install.provision":          "Provisioning Default Databases, Users and Grants",
+	"mariadb.remove.stopping_service":    "Stopping and disabling the MariaDB service...",
+	"mariadb.remove.stopping_processes":  "Stopping MariaDB processes that are still running...",
+	"mariadb.remove.removing_repo":       "Removing the MariaDB repository...",
+	"mariadb.remove.cleaning_cache":      "Cleaning the package cache...",
+	"mariadb.remove.title":               "MariaDB Removal Process",
+	"mariadb.remove.step_stop_service":   "Stopping and disabling the MariaDB service",
+	"mariadb.remove.step_backup":         "Backing up MariaDB data",
+	"mariadb.remove.step_packages":       "Removing MariaDB packages",
+	"mariadb.remove.step_data":           "Removing MariaDB data and/or configuration",
+	"mariadb.remove.step_repo":           "Removing the MariaDB repository",
+	"mariadb.remove.step_system":         "Cleaning up the system (user, logs, temp)",
+	"mariadb.remove.step_verify":         "Verifying removal",
+	"mariadb.remove.removing_packages":   "Removing MariaDB packages...",
+	"mariadb.remove.prechecking":         "Checking the system before removal...",
+	"mariadb.remove.removing_datadir":    "Removing the custom MariaDB data directory...",
+	"mariadb.remove.removing_config":     "Removing MariaDB configuration files...",
+	"mariadb.remove.removing_user":       "Removing the mysql system user...",
+	"mariadb.remove.cleaning_logs":       "Cleaning up log files...",
+	"mariadb.remove.cleaning_temp":       "Cleaning up temp files...",
+	"mariadb.remove.verifying":           "Verifying removal...",
+
+	"mariadb.upgrade.precheck_title":        "MariaDB Pre-Upgrade Checks",
+	"mariadb.upgrade.title":                 "MariaDB Upgrade Process",
+	"mariadb.upgrade.snapshot":              "[Snapshot] Packages and Configuration",
+	"mariadb.upgrade.taking_snapshot":       "Saving installed package versions and configuration...",
+	"mariadb.upgrade.snapshot_failed":       "Failed to create the pre-upgrade snapshot",
+	"mariadb.upgrade.snapshot_saved":        "Snapshot saved to %s",
+	"mariadb.upgrade.upgrade_packages":      "[Package Manager] Upgrade MariaDB Packages",
+	"mariadb.upgrade.upgrading_packages":    "Upgrading MariaDB packages...",
+	"mariadb.upgrade.upgrade_failed":        "Failed to upgrade MariaDB packages",
+	"mariadb.upgrade.packages_upgraded":     "MariaDB packages upgraded",
+	"mariadb.upgrade.postcheck":             "[Post-Check] Service and Version",
+	"mariadb.upgrade.checking":              "Restarting MariaDB and checking the new version...",
+	"mariadb.upgrade.check_failed":          "Post-upgrade checks failed",
+	"mariadb.upgrade.check_ok":              "MariaDB %s is running",
+	"mariadb.upgrade.rollback_title":        "MariaDB Upgrade Rollback",
+	"mariadb.upgrade.restoring_repo":        "Restoring the previous MariaDB repository...",
+	"mariadb.upgrade.restore_repo_failed":   "Failed to restore the previous repository",
+	"mariadb.upgrade.repo_restored":         "Previous repository restored",
+	"mariadb.upgrade.reinstalling":          "Reinstalling the previous MariaDB packages...",
+	"mariadb.upgrade.reinstall_failed":      "Failed to reinstall the previous packages",
+	"mariadb.upgrade.reinstalled":           "Previous MariaDB packages reinstalled",
+	"mariadb.upgrade.restoring_config":      "Restoring the configuration backup...",
+	"mariadb.upgrade.restore_config_failed": "Failed to restore the configuration backup",
+	"mariadb.upgrade.config_restored":       "Configuration restored",
+	"mariadb.upgrade.rollback_checking":     "Starting MariaDB and checking the previous version...",
+	"mariadb.upgrade.rollback_check_failed": "MariaDB did not come back at the previous version",
+	"mariadb.upgrade.rollback_ok":           "MariaDB %s is running again",
+
+	"menu.main.title":                  "Main Menu",
+	"menu.choose":                      "Choose menu: ",
+	"menu.exit":                        "Exit",
+	"menu.back_to_main":                "Main menu",
+	"menu.main.dbconfig":               "DB Configuration",
+	"menu.main.mariadb":                "MariaDB Installation",
+	"menu.main.backup":                 "Backup",
+	"menu.main.restore":                "Restore",
+	"menu.main.backup_restore":         "Backup & Restore",
+	"menu.main.backup_missing":         "Database backup is not available in the menu yet; use \"sfDBTools backup\".",
+	"menu.main.restore_missing":        "Database restore is not available in the menu yet; use \"sfDBTools restore\".",
+	"menu.main.backup_restore_missing": "Backup & restore is not available in the menu yet; use \"sfDBTools backup-restore\".",
+	"menu.press_enter_main":            "Press Enter to return to the main menu...",
+	"menu.mariadb.title":               "MariaDB Installation",
+	"menu.mariadb.install":             "Install MariaDB",
+	"menu.mariadb.remove":              "Remove MariaDB",
+	"menu.mariadb.configure":           "Modify MariaDB Configuration",
+	"menu.mariadb.status":              "Check MariaDB Status",
+	"menu.mariadb.versions":            "Check Versions (Online)",
+	"menu.dbconfig.title":              "DB Configuration Management",
+	"menu.dbconfig.create":             "Create DB Configuration",
+	"menu.dbconfig.edit":               "Edit DB Configuration",
+	"menu.dbconfig.delete":             "Delete DB Configuration",
+	"menu.dbconfig.validate":           "Validate DB Connection",
+	"menu.dbconfig.show":               "Show DB Configuration",
+
+	"prompt.yes_no.default_no":      "[y/N]",
+	"prompt.select_option":          "Select option (1-%d): ",
+	"prompt.invalid_selection":      "invalid selection: %s",
+	"prompt.selection_out_of_range": "selection out of range: %d",
+
+	"menu.error":        "Menu error: %v",
+	"menu.action_error": "Action error: %v",
+
+	"prompt.yes_no.default_yes": "[Y/n]",
+	"prompt.invalid_integer":    "Invalid integer, please try again.",
+	"prompt.press_enter":        "Press Enter to continue...",
+	"prompt.timeout_abort":      "No input received within %s, aborting",
+	"prompt.timeout_default":    "No input received within %s, using default answer",
+	"prompt.yes":                "yes",
+	"prompt.no":                 "no",
+	"prompt.assumed":            "%s %v (--yes)",
+	"prompt.answer_required":    "An answer is required.",
+	"prompt.no_options":         "nothing to choose from",
+	"prompt.select_hint":        "Select 1-%d or type to search",
+	"prompt.multi_select_hint":  "Select (e.g. 1,3,5-7, all, names or /regex to search; Enter for none)",
+	"prompt.no_match":           "Nothing matches %q",
+	"prompt.several_matches":    "%q matches several options:",
+	"prompt.selected":           "Selected %d: %s",
+	"prompt.use_selection":      "Use this selection?",
+	"prompt.invalid_regex":      "invalid regular expression %q: %v",
+
+	"plan.title":                 "%s - Confirmation Summary",
+	"plan.source":                "Source",
+	"plan.target":                "Target",
+	"plan.databases":             "Databases (%d)",
+	"plan.actions":               "Actions",
+	"plan.destructive":           "Destructive",
+	"plan.estimated_duration":    "Estimated duration",
+	"plan.backup_safety":         "Backup safety",
+	"plan.item":                  "Item",
+	"plan.details":               "Details",
+	"plan.destructive_warning":   "This operation is destructive and cannot be undone",
+	"plan.unprotected_warning":   "No backup protects the affected data",
+	"plan.more":                  "... and %d more",
+	"plan.duration_unknown":      "unknown",
+	"plan.duration_under_minute": "less than a minute",
+	"plan.not_protected":         "✗ NOT PROTECTED",
+	"plan.protected":             "✓ protected",
+
+	"warnings.summary": "Warnings (%d)",
+
+	"error.internal":           "unexpected internal error: %v (stack trace written to the log)",
+	"error.config_unavailable": "configuration not available",
+	"error.usage_hint":         "Run 'sfDBTools --help' for usage.",
+
+	"restore.confirm":    "Do you want to continue with the restore?",
+	"restore.cancelled":  "restore operation cancelled by user",
+	"restore.proceeding": "Proceeding with restore...",
+
+	"report.badge_failed":    "FAILED",
+	"report.badge_succeeded": "SUCCEEDED",
+	"report.command":         "Command",
+	"report.host":            "Host",
+	"report.started":         "Started",
+	"report.finished":        "Finished",
+	"report.error":           "Error",
+	"report.job_failed":      "failed",
+	"report.job_completed":   "completed",
+	"report.job_unfinished":  "did not finish",
+	"report.took":            "took",
+	"report.step":            "Step",
+	"report.duration":        "Duration",
+	"report.data":            "Data",
+	"report.warnings":        "Warnings",
+	"report.no_jobs":         "No tracked operations ran.",
+	"report.generated":       "Generated by sfDBTools",
+}
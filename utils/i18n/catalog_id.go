@@ -0,0 +1,188 @@
+package i18n
+
+// Indonesian messages
+var catalogID = map[string]string{
+	"mariadb.install.update_cache":         "[Package Manager] Update Cache",
+	"mariadb.install.updating_cache":       "Mengupdate cache package manager...",
+	"mariadb.install.update_cache_failed":  "Gagal mengupdate cache package manager",
+	"mariadb.install.cache_updated":        "Cache package manager berhasil diupdate",
+	"mariadb.install.upgrade_packages":     "[Package Manager] Upgrade Paket Sistem",
+	"mariadb.install.upgrading_packages":   "Mengupgrade paket sistem...",
+	"mariadb.install.upgrade_failed":       "Gagal mengupgrade paket sistem",
+	"mariadb.install.packages_upgraded":    "Paket sistem berhasil diupgrade",
+	"mariadb.install.install_packages":     "[Package Manager] Install Paket MariaDB",
+	"mariadb.install.resolving_packages":   "Menentukan dan menginstall paket MariaDB...",
+	"mariadb.install.detect_os_failed":     "Gagal mendeteksi OS untuk penentuan paket",
+	"mariadb.install.package_names_failed": "Gagal menentukan nama paket MariaDB",
+	"mariadb.install.packages_resolved":    "Daftar paket MariaDB berhasil didapatkan",
+	"mariadb.install.installing_package":   "[%d/%d] Menginstall paket: %s",
+	"mariadb.install.package_failed":       "Gagal menginstall paket",
+
+	"common.done": "Berhasil",
+
+	"mariadb.install.repo_setup":         "[Repository] Setup",
+	"mariadb.install.checking_repo":      "Mengecek repository MariaDB yang ada...",
+	"mariadb.install.check_repo_failed":  "Gagal mengecek repository yang ada",
+	"mariadb.install.repo_ok":            "Repository MariaDB sudah sesuai, tidak perlu setup",
+	"mariadb.install.old_repo_found":     "Repository lama ditemukan — akan dibackup sebelum setup",
+	"mariadb.install.backing_up_repo":    "Membackup repository MariaDB yang lama...",
+	"mariadb.install.backup_repo_failed": "Gagal membackup repository lama",
+	"mariadb.install.repo_backed_up":     "Repository lama dibackup: %s",
+	"mariadb.install.no_old_repo":        "Tidak ditemukan repository MariaDB yang lama",
+	"mariadb.install.downloading_script": "Mengunduh script setup repository...",
+	"mariadb.install.download_failed":    "Gagal mengunduh script setup repository",
+	"mariadb.install.downloaded":         "Script setup repository berhasil diunduh",
+	"mariadb.install.running_script":     "Menjalankan script setup repository...",
+	"mariadb.install.script_failed":      "Gagal menjalankan script setup repository",
+	"mariadb.install.script_done":        "Script setup repository berhasil dijalankan",
+	"mariadb.install.precheck_title":     "Pemeriksaan Pra-Instalasi MariaDB",
+	"mariadb.install.title":              "Proses Instalasi MariaDB",
+	"mariadb.install.post_title":         "Setup Pasca-Instalasi MariaDB",
+	"mariadb.install.provision":          "Membuat Database, User dan Grant Default",
+	"mariadb.remove.stopping_service":    "Menghentikan & mendisable service MariaDB...",
+	"mariadb.remove.stopping_processes":  "Menghentikan proses MariaDB yang masih berjalan...",
+	"mariadb.remove.removing_repo":       "Menghapus repository MariaDB...",
+	"mariadb.remove.cleaning_cache":      "Membersihkan package cache...",
+	"mariadb.remove.title":               "Proses Penghapusan MariaDB",
+	"mariadb.remove.step_stop_service":   "Menghentikan & mendisable service MariaDB",
+	"mariadb.remove.step_backup":         "Membuat backup data MariaDB",
+	"mariadb.remove.step_packages":       "Menghapus paket MariaDB",
+	"mariadb.remove.step_data":           "Menghapus data dan/atau konfigurasi MariaDB",
+	"mariadb.remove.step_repo":           "Menghapus repository MariaDB",
+	"mariadb.remove.step_system":         "Membersihkan sistem (user, logs, temp)",
+	"mariadb.remove.step_verify":         "Memverifikasi penghapusan",
+	"mariadb.remove.removing_packages":   "Menghapus paket MariaDB...",
+	"mariadb.remove.prechecking":         "Melakukan pemeriksaan sistem untuk penghapusan...",
+	"mariadb.remove.removing_datadir":    "Menghapus data directory MariaDB (custom)...",
+	"mariadb.remove.removing_config":     "Menghapus file konfigurasi MariaDB...",
+	"mariadb.remove.removing_user":       "Menghapus user mysql dari sistem...",
+	"mariadb.remove.cleaning_logs":       "Membersihkan log files...",
+	"mariadb.remove.cleaning_temp":       "Membersihkan temp files...",
+	"mariadb.remove.verifying":           "Memverifikasi penghapusan...",
+
+	"mariadb.upgrade.precheck_title":        "Pemeriksaan Pra-Upgrade MariaDB",
+	"mariadb.upgrade.title":                 "Proses Upgrade MariaDB",
+	"mariadb.upgrade.snapshot":              "[Snapshot] Paket dan Konfigurasi",
+	"mariadb.upgrade.taking_snapshot":       "Menyimpan versi paket terinstall dan konfigurasi...",
+	"mariadb.upgrade.snapshot_failed":       "Gagal membuat snapshot sebelum upgrade",
+	"mariadb.upgrade.snapshot_saved":        "Snapshot disimpan di %s",
+	"mariadb.upgrade.upgrade_packages":      "[Package Manager] Upgrade Paket MariaDB",
+	"mariadb.upgrade.upgrading_packages":    "Mengupgrade paket MariaDB...",
+	"mariadb.upgrade.upgrade_failed":        "Gagal mengupgrade paket MariaDB",
+	"mariadb.upgrade.packages_upgraded":     "Paket MariaDB berhasil diupgrade",
+	"mariadb.upgrade.postcheck":             "[Post-Check] Service dan Versi",
+	"mariadb.upgrade.checking":              "Me-restart MariaDB dan memeriksa versi baru...",
+	"mariadb.upgrade.check_failed":          "Pemeriksaan setelah upgrade gagal",
+	"mariadb.upgrade.check_ok":              "MariaDB %s berjalan",
+	"mariadb.upgrade.rollback_title":        "Rollback Upgrade MariaDB",
+	"mariadb.upgrade.restoring_repo":        "Mengembalikan repository MariaDB sebelumnya...",
+	"mariadb.upgrade.restore_repo_failed":   "Gagal mengembalikan repository sebelumnya",
+	"mariadb.upgrade.repo_restored":         "Repository sebelumnya berhasil dikembalikan",
+	"mariadb.upgrade.reinstalling":          "Menginstall ulang paket MariaDB versi sebelumnya...",
+	"mariadb.upgrade.reinstall_failed":      "Gagal menginstall ulang paket versi sebelumnya",
+	"mariadb.upgrade.reinstalled":           "Paket MariaDB versi sebelumnya berhasil diinstall ulang",
+	"mariadb.upgrade.restoring_config":      "Mengembalikan backup konfigurasi...",
+	"mariadb.upgrade.restore_config_failed": "Gagal mengembalikan backup konfigurasi",
+	"mariadb.upgrade.config_restored":       "Konfigurasi berhasil dikembalikan",
+	"mariadb.upgrade.rollback_checking":     "Menjalankan MariaDB dan memeriksa versi sebelumnya...",
+	"mariadb.upgrade.rollback_check_failed": "MariaDB tidak kembali berjalan dengan versi sebelumnya",
+	"mariadb.upgrade.rollback_ok":           "MariaDB %s kembali berjalan",
+
+	"menu.main.title":                  "Menu Utama",
+	"menu.choose":                      "Pilih Menu : ",
+	"menu.exit":                        "Keluar",
+	"menu.back_to_main":                "Menu utama",
+	"menu.main.dbconfig":               "Menu Konfigurasi DB",
+	"menu.main.mariadb":                "Menu Instalasi MariaDB",
+	"menu.main.backup":                 "Menu Backup",
+	"menu.main.restore":                "Menu Restore",
+	"menu.main.backup_restore":         "Menu Backup & Restore",
+	"menu.main.backup_missing":         "Fungsi Backup Database belum diimplementasikan di menu; gunakan \"sfDBTools backup\".",
+	"menu.main.restore_missing":        "Fungsi Restore Database belum diimplementasikan di menu; gunakan \"sfDBTools restore\".",
+	"menu.main.backup_restore_missing": "Fungsi Backup & Restore Database belum diimplementasikan di menu; gunakan \"sfDBTools backup-restore\".",
+	"menu.press_enter_main":            "Tekan Enter untuk kembali ke menu utama...",
+	"menu.mariadb.title":               "Menu Instalasi MariaDB",
+	"menu.mariadb.install":             "Install MariaDB",
+	"menu.mariadb.remove":              "Hapus MariaDB",
+	"menu.mariadb.configure":           "Modifikasi Konfigurasi MariaDB",
+	"menu.mariadb.status":              "Check Status MariaDB",
+	"menu.mariadb.versions":            "Check Versi (Online)",
+	"menu.dbconfig.title":              "Manajemen Konfigurasi DB",
+	"menu.dbconfig.create":             "Buat Konfigurasi DB",
+	"menu.dbconfig.edit":               "Edit Konfigurasi DB",
+	"menu.dbconfig.delete":             "Hapus Konfigurasi DB",
+	"menu.dbconfig.validate":           "Validasi Koneksi DB",
+	"menu.dbconfig.show":               "Lihat Konfigurasi DB",
+
+	"prompt.yes_no.default_no":      "[y/T]",
+	"prompt.select_option":          "Pilih opsi (1-%d): ",
+	"prompt.invalid_selection":      "pilihan tidak valid: %s",
+	"prompt.selection_out_of_range": "pilihan di luar rentang: %d",
+
+	"menu.error":        "Kesalahan menu: %v",
+	"menu.action_error": "Kesalahan aksi: %v",
+
+	"prompt.yes_no.default_yes": "[Y/t]",
+	"prompt.invalid_integer":    "Bilangan bulat tidak valid, silakan coba lagi.",
+	"prompt.press_enter":        "Tekan Enter untuk melanjutkan...",
+	"prompt.timeout_abort":      "Tidak ada input dalam %s, dibatalkan",
+	"prompt.timeout_default":    "Tidak ada input dalam %s, memakai jawaban default",
+	"prompt.yes":                "ya",
+	"prompt.no":                 "tidak",
+	"prompt.assumed":            "%s %v (--yes)",
+	"prompt.answer_required":    "Jawaban wajib diisi.",
+	"prompt.no_options":         "tidak ada yang bisa dipilih",
+	"prompt.select_hint":        "Pilih 1-%d atau ketik untuk mencari",
+	"prompt.multi_select_hint":  "Pilih (mis. 1,3,5-7, all, nama atau /regex untuk mencari; Enter untuk tidak ada)",
+	"prompt.no_match":           "Tidak ada yang cocok dengan %q",
+	"prompt.several_matches":    "%q cocok dengan beberapa opsi:",
+	"prompt.selected":           "Terpilih %d: %s",
+	"prompt.use_selection":      "Pakai pilihan ini?",
+	"prompt.invalid_regex":      "regular expression %q tidak valid: %v",
+
+	"plan.title":                 "%s - Ringkasan Konfirmasi",
+	"plan.source":                "Sumber",
+	"plan.target":                "Target",
+	"plan.databases":             "Database (%d)",
+	"plan.actions":               "Aksi",
+	"plan.destructive":           "Destruktif",
+	"plan.estimated_duration":    "Perkiraan durasi",
+	"plan.backup_safety":         "Keamanan backup",
+	"plan.item":                  "Item",
+	"plan.details":               "Rincian",
+	"plan.destructive_warning":   "Operasi ini destruktif dan tidak dapat dibatalkan",
+	"plan.unprotected_warning":   "Tidak ada backup yang melindungi data yang terdampak",
+	"plan.more":                  "... dan %d lainnya",
+	"plan.duration_unknown":      "tidak diketahui",
+	"plan.duration_under_minute": "kurang dari satu menit",
+	"plan.not_protected":         "✗ TIDAK TERLINDUNGI",
+	"plan.protected":             "✓ terlindungi",
+
+	"warnings.summary": "Peringatan (%d)",
+
+	"error.internal":           "kesalahan internal tak terduga: %v (stack trace ditulis ke log)",
+	"error.config_unavailable": "konfigurasi tidak tersedia",
+	"error.usage_hint":         "Jalankan 'sfDBTools --help' untuk melihat cara pemakaian.",
+
+	"restore.confirm":    "Lanjutkan restore?",
+	"restore.cancelled":  "restore dibatalkan oleh pengguna",
+	"restore.proceeding": "Melanjutkan restore...",
+
+	"report.badge_failed":    "GAGAL",
+	"report.badge_succeeded": "BERHASIL",
+	"report.command":         "Perintah",
+	"report.host":            "Host",
+	"report.started":         "Mulai",
+	"report.finished":        "Selesai",
+	"report.error":           "Kesalahan",
+	"report.job_failed":      "gagal",
+	"report.job_completed":   "selesai",
+	"report.job_unfinished":  "tidak selesai",
+	"report.took":            "durasi",
+	"report.step":            "Langkah",
+	"report.duration":        "Durasi",
+	"report.data":            "Data",
+	"report.warnings":        "Peringatan",
+	"report.no_jobs":         "Tidak ada operasi yang tercatat.",
+	"report.generated":       "Dibuat oleh sfDBTools",
+}
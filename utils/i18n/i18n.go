@@ -0,0 +1,112 @@
+// Package i18n translates user-facing messages. Messages are looked up by key
+// in the catalog of the configured language, falling back to English and then
+// to the key itself, so a missing translation never hides a message.
+//
+// The language comes from --lang (SFDB_LANG), general.locale.language, or the
+// LC_ALL, LC_MESSAGES and LANG environment variables, in that order.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Supported languages
+const (
+	English    = "en"
+	Indonesian = "id"
+)
+
+var catalogs = map[string]map[string]string{
+	English:    catalogEN,
+	Indonesian: catalogID,
+}
+
+var (
+	mu   sync.RWMutex
+	lang = English
+)
+
+// Configure selects the language. An empty lang is detected from the
+// environment; an explicitly requested unsupported language is an error.
+func Configure(requested string) error {
+	selected := English
+	if requested = strings.TrimSpace(requested); requested != "" {
+		l, ok := normalize(requested)
+		if !ok {
+			return fmt.Errorf("unsupported language %q (supported: %s, %s)", requested, English, Indonesian)
+		}
+		selected = l
+	} else {
+		selected = detect()
+	}
+	mu.Lock()
+	lang = selected
+	mu.Unlock()
+	return nil
+}
+
+// Lang returns the selected language
+func Lang() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return lang
+}
+
+// T returns the message for key in the selected language, formatted with
+// args like fmt.Sprintf when any are given
+func T(key string, args ...interface{}) string {
+	mu.RLock()
+	msg, ok := catalogs[lang][key]
+	mu.RUnlock()
+	if !ok {
+		if msg, ok = catalogEN[key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// IsYes reports whether an answer to a yes/no prompt means yes in any
+// supported language
+func IsYes(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "ya":
+		return true
+	}
+	return false
+}
+
+// detect reads the language of the POSIX locale, e.g. id_ID.UTF-8; the C and
+// POSIX locales and unsupported languages select English
+func detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			if l, ok := normalize(value); ok {
+				return l
+			}
+			return English
+		}
+	}
+	return English
+}
+
+// normalize maps "id", "id_ID.UTF-8", "in" or "en-US" to a supported language
+func normalize(value string) (string, bool) {
+	value = strings.ToLower(value)
+	if i := strings.IndexAny(value, "_-.@"); i >= 0 {
+		value = value[:i]
+	}
+	switch value {
+	case English:
+		return English, true
+	case Indonesian, "in", "ind":
+		return Indonesian, true
+	}
+	return "", false
+}
@@ -0,0 +1,313 @@
+package jobstatus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/fs/policy"
+	"sfDBTools/utils/progress"
+	"sfDBTools/utils/terminal"
+)
+
+// DefaultDir is the well-known directory for job status files
+const DefaultDir = "/var/run/sfDBTools/jobs"
+
+// writeInterval throttles status file writes during Progress updates
+const writeInterval = time.Second
+
+// emitInterval throttles bytes events during Progress updates
+const emitInterval = 500 * time.Millisecond
+
+// Job states
+const (
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateFailed    = "failed"
+	StateStale     = "stale"
+)
+
+// Job is the JSON document written for each running operation
+type Job struct {
+	ID         string    `json:"id"`
+	Operation  string    `json:"operation"`
+	Target     string    `json:"target"`
+	PID        int       `json:"pid"`
+	Host       string    `json:"host"`
+	State      string    `json:"state"`
+	Step       string    `json:"step"`
+	Percent    float64   `json:"percent"`
+	BytesDone  int64     `json:"bytes_done"`
+	BytesTotal int64     `json:"bytes_total"`
+	ETASeconds int64     `json:"eta_seconds"`
+	Error      string    `json:"error,omitempty"`
+	Warnings   []string  `json:"warnings,omitempty"` // distinct warnings raised by the job
+	StartedAt  time.Time `json:"started_at"`
+	LastUpdate time.Time `json:"last_update"`
+}
+
+// sequence keeps job IDs unique when one process starts several jobs
+var sequence int64
+
+// Tracker updates the status file of a single job and emits its progress
+// events (see package progress). All methods are safe for concurrent use and a
+// nil Tracker is a no-op, so callers never need to check whether status
+// reporting is available.
+type Tracker struct {
+	mu          sync.Mutex
+	path        string // empty when the status file is unavailable; events still flow
+	job         Job
+	stepStarted time.Time
+	lastWrite   time.Time
+	lastEmit    time.Time
+}
+
+// Dir resolves the status directory from SFDB_STATUS_DIR, the config and the
+// default, falling back to the system temp dir when it cannot be created.
+func Dir() string {
+	dir := os.Getenv("SFDB_STATUS_DIR")
+	if dir == "" {
+		if cfg, err := config.Get(); err == nil && cfg.General.StatusDir != "" {
+			dir = cfg.General.StatusDir
+		}
+	}
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if err := policy.MkdirAll(dir); err != nil {
+		return filepath.Join(os.TempDir(), "sfDBTools", "jobs")
+	}
+	return dir
+}
+
+// Start registers a new job, writes its initial status file and emits the
+// started event. Status file failures are logged and only disable the file, so
+// the operation itself and event consumers are unaffected.
+func Start(operation, target string) *Tracker {
+	lg, _ := logger.Get()
+
+	host, _ := os.Hostname()
+	now := time.Now().UTC()
+	id := fmt.Sprintf("%s-%s-%d-%d", operation, now.Format("20060102-150405"), os.Getpid(), atomic.AddInt64(&sequence, 1))
+	t := &Tracker{
+		stepStarted: now,
+		job: Job{
+			ID:         id,
+			Operation:  operation,
+			Target:     target,
+			PID:        os.Getpid(),
+			Host:       host,
+			State:      StateRunning,
+			Step:       "starting",
+			StartedAt:  now,
+			LastUpdate: now,
+		},
+	}
+	t.emit(progress.KindStarted)
+
+	dir := Dir()
+	if err := policy.MkdirAll(dir); err != nil {
+		lg.Warn("Job status directory unavailable", logger.String("dir", dir), logger.Error(err))
+		return t
+	}
+	t.path = filepath.Join(dir, sanitize(id)+".json")
+	if err := t.write(); err != nil {
+		lg.Warn("Failed to write job status file", logger.String("path", t.path), logger.Error(err))
+		t.path = ""
+		return t
+	}
+	lg.Debug("Job status tracking started", logger.String("path", t.path))
+	return t
+}
+
+// Path returns the status file location
+func (t *Tracker) Path() string {
+	if t == nil {
+		return ""
+	}
+	return t.path
+}
+
+// SetStep records the current step and resets byte progress
+func (t *Tracker) SetStep(step string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.finishStep()
+	t.job.Step = step
+	t.stepStarted = time.Now()
+	t.job.BytesDone, t.job.BytesTotal, t.job.Percent, t.job.ETASeconds = 0, 0, 0, 0
+	t.emit(progress.KindStepStarted)
+	t.flush(true)
+}
+
+// Progress records bytes processed for the current step. total may be 0 when
+// unknown, in which case no percentage or ETA is reported. Writes are throttled.
+func (t *Tracker) Progress(done, total int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.job.BytesDone, t.job.BytesTotal = done, total
+	t.job.Percent, t.job.ETASeconds = 0, 0
+	if total > 0 {
+		t.job.Percent = float64(done) * 100 / float64(total)
+		if t.job.Percent > 100 {
+			t.job.Percent = 100
+		}
+		elapsed := time.Since(t.stepStarted).Seconds()
+		if done > 0 && done < total && elapsed > 0 {
+			rate := float64(done) / elapsed
+			t.job.ETASeconds = int64(float64(total-done) / rate)
+		}
+	}
+	if time.Since(t.lastEmit) >= emitInterval || (total > 0 && done >= total) {
+		t.lastEmit = time.Now()
+		t.emit(progress.KindBytes)
+	}
+	t.flush(false)
+}
+
+// Warn logs a condition the user should know about and emits it as a warning
+// event, which the CLI prints
+func (t *Tracker) Warn(message string) {
+	lg, _ := logger.Get()
+	lg.Warn(message)
+	if t == nil {
+		terminal.PrintWarning(message)
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !slices.Contains(t.job.Warnings, message) {
+		t.job.Warnings = append(t.job.Warnings, message)
+		t.flush(false)
+	}
+	e := t.event(progress.KindWarning)
+	e.Message = message
+	progress.Emit(e)
+}
+
+// Detail emits a labelled fact about the job's result, such as the output
+// file, its size or a verification outcome, for reports
+func (t *Tracker) Detail(label, value string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e := t.event(progress.KindDetail)
+	e.Label, e.Message = label, value
+	progress.Emit(e)
+}
+
+// Finish marks the job completed or failed. The file is kept so other tools
+// can see the outcome; "system jobs --clean" removes finished entries.
+func (t *Tracker) Finish(err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.finishStep()
+	t.job.State = StateCompleted
+	t.job.Step = "done"
+	t.job.ETASeconds = 0
+	if t.job.BytesTotal > 0 {
+		t.job.Percent = 100
+	}
+	if err != nil {
+		t.job.State = StateFailed
+		t.job.Error = err.Error()
+	}
+	e := t.event(progress.KindFinished)
+	e.Step = ""
+	e.Duration = time.Since(t.job.StartedAt).Seconds()
+	e.Error = t.job.Error
+	progress.Emit(e)
+	t.flush(true)
+}
+
+// event builds an event from the current job state. Callers must hold t.mu.
+func (t *Tracker) event(kind string) progress.Event {
+	return progress.Event{
+		Job:        t.job.ID,
+		Operation:  t.job.Operation,
+		Target:     t.job.Target,
+		Kind:       kind,
+		Step:       t.job.Step,
+		BytesDone:  t.job.BytesDone,
+		BytesTotal: t.job.BytesTotal,
+	}
+}
+
+// emit sends a kind event for the current state. Callers must hold t.mu.
+func (t *Tracker) emit(kind string) {
+	progress.Emit(t.event(kind))
+}
+
+// finishStep emits step_finished for the running step, if one was set.
+// Callers must hold t.mu.
+func (t *Tracker) finishStep() {
+	if t.job.Step == "starting" || t.job.Step == "done" {
+		return
+	}
+	e := t.event(progress.KindStepFinished)
+	e.Duration = time.Since(t.stepStarted).Seconds()
+	progress.Emit(e)
+}
+
+// flush writes the status file if forced or the throttle interval has passed.
+// Callers must hold t.mu.
+func (t *Tracker) flush(force bool) {
+	if t.path == "" {
+		return
+	}
+	if !force && time.Since(t.lastWrite) < writeInterval {
+		return
+	}
+	if err := t.write(); err != nil {
+		lg, _ := logger.Get()
+		lg.Debug("Failed to update job status file", logger.String("path", t.path), logger.Error(err))
+	}
+}
+
+// write atomically replaces the status file with the current job state
+func (t *Tracker) write() error {
+	t.job.LastUpdate = time.Now().UTC()
+	data, err := json.MarshalIndent(t.job, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err := policy.WriteFile(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	t.lastWrite = t.job.LastUpdate
+	return nil
+}
+
+// sanitize keeps job IDs safe for use as file names
+func sanitize(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, id)
+}
@@ -0,0 +1,67 @@
+package jobstatus
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+)
+
+// List reads all job status files from the status directory, newest first.
+// Running jobs whose process no longer exists on this host are reported as stale.
+func List() ([]Job, error) {
+	files, err := filepath.Glob(filepath.Join(Dir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	host, _ := os.Hostname()
+	jobs := make([]Job, 0, len(files))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var job Job
+		if json.Unmarshal(data, &job) != nil {
+			continue
+		}
+		if job.State == StateRunning && job.Host == host && !processAlive(job.PID) {
+			job.State = StateStale
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].StartedAt.After(jobs[j].StartedAt) })
+	return jobs, nil
+}
+
+// Clean removes status files of jobs that are no longer running and returns
+// the number of files removed.
+func Clean() (int, error) {
+	jobs, err := List()
+	if err != nil {
+		return 0, err
+	}
+	dir := Dir()
+	removed := 0
+	for _, job := range jobs {
+		if job.State == StateRunning {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, sanitize(job.ID)+".json")); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,56 @@
+package jobstatus
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// progressReader reports bytes read to a Tracker
+type progressReader struct {
+	r     io.Reader
+	t     *Tracker
+	total int64
+	done  int64
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.t.Progress(atomic.AddInt64(&p.done, int64(n)), p.total)
+	}
+	return n, err
+}
+
+// progressWriter reports bytes written to a Tracker
+type progressWriter struct {
+	w     io.Writer
+	t     *Tracker
+	total int64
+	done  int64
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.t.Progress(atomic.AddInt64(&p.done, int64(n)), p.total)
+	}
+	return n, err
+}
+
+// Reader wraps r so every read updates the job's byte progress against total
+// (0 when unknown). On a nil Tracker r is returned unchanged.
+func (t *Tracker) Reader(r io.Reader, total int64) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &progressReader{r: r, t: t, total: total}
+}
+
+// Writer wraps w so every write updates the job's byte progress against total
+// (0 when unknown). On a nil Tracker w is returned unchanged.
+func (t *Tracker) Writer(w io.Writer, total int64) io.Writer {
+	if t == nil {
+		return w
+	}
+	return &progressWriter{w: w, t: t, total: total}
+}
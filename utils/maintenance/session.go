@@ -0,0 +1,262 @@
+package maintenance
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/hooks"
+	"sfDBTools/utils/tempdir"
+	"sfDBTools/utils/terminal"
+
+	gproc "github.com/shirou/gopsutil/v3/process"
+	"github.com/spf13/cobra"
+)
+
+// Session watches the window a heavy operation runs in and holds its post
+// hooks. A nil Session is a no-op, so callers never need to check whether
+// windows or hooks are configured.
+type Session struct {
+	schedule  *Schedule // nil without maintenance windows
+	operation string
+	hooks     *hooks.Run
+	stop      chan struct{}
+	done      chan struct{}
+
+	mu      sync.Mutex
+	aborted time.Time
+}
+
+// Enter checks the temp dir and the maintenance window before operation
+// starts, then runs the pre hooks of operation. Outside a window it refuses,
+// or with outside_window: defer waits for the next one. --ignore-window
+// (SFDB_IGNORE_WINDOW) skips the window check. Call Close, passing the
+// operation's error, when the work is done.
+func Enter(cmd *cobra.Command, operation string) (*Session, error) {
+	if err := tempdir.Check(operation, 0); err != nil {
+		return nil, common.WithExitCode(err, common.ExitConfig)
+	}
+	s, err := enterWindow(cmd, operation)
+	if err != nil {
+		return nil, err
+	}
+	run, err := hooks.Before(cmd, operation)
+	if err != nil {
+		return nil, s.Close(err)
+	}
+	if run != nil {
+		if s == nil {
+			s = &Session{operation: operation}
+		}
+		s.hooks = run
+	}
+	return s, nil
+}
+
+// enterWindow waits for or refuses outside the maintenance window and starts
+// watching it; the Session is nil without windows
+func enterWindow(cmd *cobra.Command, operation string) (*Session, error) {
+	lg, _ := logger.Get()
+	cfg, err := config.Get()
+	if err != nil {
+		return nil, nil
+	}
+	schedule, err := Load(cfg.Maintenance, cfg.General.Locale.Timezone)
+	if err != nil {
+		return nil, common.WithExitCode(err, common.ExitConfig)
+	}
+	if !schedule.Enabled() {
+		return nil, nil
+	}
+	if common.GetBoolFlagOrEnv(cmd, "ignore-window", "SFDB_IGNORE_WINDOW", false) {
+		lg.Warn("Maintenance window check skipped", logger.String("operation", operation), logger.String("windows", schedule.String()))
+		return nil, nil
+	}
+
+	now := time.Now()
+	end, open := schedule.Current(now)
+	if !open {
+		next, ok := schedule.Next(now)
+		if !ok {
+			return nil, common.WithExitCode(fmt.Errorf("%s is outside the maintenance window (%s) and no window opens within a week; use --ignore-window to run anyway", operation, schedule), common.ExitWindow)
+		}
+		wait := next.Sub(now)
+		if schedule.outside != OutsideDefer {
+			return nil, common.WithExitCode(fmt.Errorf("%s is outside the maintenance window (%s); the next window opens %s, use --ignore-window to run anyway",
+				operation, schedule, next.In(schedule.loc).Format("Mon 2006-01-02 15:04")), common.ExitWindow)
+		}
+		if schedule.maxDefer > 0 && wait > schedule.maxDefer {
+			return nil, common.WithExitCode(fmt.Errorf("%s is outside the maintenance window and the next one opens in %s, beyond max_defer %s",
+				operation, wait.Round(time.Minute), schedule.maxDefer), common.ExitWindow)
+		}
+
+		terminal.PrintInfo(fmt.Sprintf("Outside the maintenance window; %s deferred until %s", operation, next.In(schedule.loc).Format("Mon 2006-01-02 15:04")))
+		lg.Info("Deferring operation until maintenance window", logger.String("operation", operation), logger.String("until", next.Format(time.RFC3339)))
+		time.Sleep(wait)
+		if end, open = schedule.Current(time.Now()); !open {
+			return nil, common.WithExitCode(fmt.Errorf("maintenance window did not open as expected at %s", next.Format(time.RFC3339)), common.ExitWindow)
+		}
+	}
+
+	lg.Info("Running inside maintenance window",
+		logger.String("operation", operation),
+		logger.String("closes", end.Format(time.RFC3339)),
+		logger.String("on_window_end", schedule.onEnd))
+	s := &Session{
+		schedule:  schedule,
+		operation: operation,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go s.watch(end)
+	return s, nil
+}
+
+// Close stops watching the window, runs the post hooks and returns err,
+// replaced by a window error when the operation was aborted because the
+// window closed, or by the error of a post hook that aborts
+func (s *Session) Close(err error) error {
+	if s == nil {
+		return err
+	}
+	if s.schedule != nil {
+		err = s.closeWindow(err)
+	}
+	return s.hooks.After(err)
+}
+
+// closeWindow stops watching the window
+func (s *Session) closeWindow(err error) error {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	aborted := s.aborted
+	s.mu.Unlock()
+	if !aborted.IsZero() && err != nil {
+		return common.WithExitCode(fmt.Errorf("%s aborted when the maintenance window closed at %s: %w",
+			s.operation, aborted.In(s.schedule.loc).Format("15:04"), err), common.ExitWindow)
+	}
+	return err
+}
+
+// watch warns before the window closes and applies on_window_end when it does
+func (s *Session) watch(end time.Time) {
+	defer close(s.done)
+	lg, _ := logger.Get()
+
+	for {
+		if warnAt := end.Add(-s.schedule.endWarning); s.schedule.endWarning > 0 && time.Now().Before(warnAt) {
+			if !s.sleep(time.Until(warnAt)) {
+				return
+			}
+			// Windows can be extended by an adjacent one configured later
+			if extended, _ := s.schedule.Current(time.Now()); extended.After(end) {
+				end = extended
+				continue
+			}
+			msg := fmt.Sprintf("Maintenance window closes at %s; %s will %s", end.In(s.schedule.loc).Format("15:04"), s.operation, endVerb(s.schedule.onEnd))
+			terminal.PrintWarning(msg)
+			lg.Warn(msg)
+		}
+		if !s.sleep(time.Until(end)) {
+			return
+		}
+		if extended, open := s.schedule.Current(time.Now()); open && extended.After(end) {
+			end = extended
+			continue
+		}
+
+		switch s.schedule.onEnd {
+		case EndAbort:
+			s.mu.Lock()
+			s.aborted = end
+			s.mu.Unlock()
+			lg.Warn("Maintenance window closed, stopping operation", logger.String("operation", s.operation))
+			terminal.PrintWarning(fmt.Sprintf("Maintenance window closed; stopping %s", s.operation))
+			signalChildren(syscall.SIGTERM)
+			return
+		case EndPause:
+			next, ok := s.schedule.Next(time.Now())
+			if !ok {
+				return
+			}
+			lg.Warn("Maintenance window closed, pausing operation",
+				logger.String("operation", s.operation), logger.String("resume", next.Format(time.RFC3339)))
+			terminal.PrintWarning(fmt.Sprintf("Maintenance window closed; %s paused until %s", s.operation, next.In(s.schedule.loc).Format("Mon 15:04")))
+			signalChildren(syscall.SIGSTOP)
+			resumed := s.sleep(time.Until(next))
+			signalChildren(syscall.SIGCONT)
+			if !resumed {
+				return
+			}
+			lg.Info("Maintenance window opened, resuming operation", logger.String("operation", s.operation))
+			if end, ok = s.schedule.Current(time.Now()); !ok {
+				return
+			}
+		default:
+			lg.Warn("Maintenance window closed, operation continues", logger.String("operation", s.operation))
+			return
+		}
+	}
+}
+
+// sleep waits for d and reports false when the session was closed meanwhile
+func (s *Session) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.stop:
+		return false
+	}
+}
+
+func endVerb(action string) string {
+	switch action {
+	case EndAbort:
+		return "be stopped"
+	case EndPause:
+		return "be paused until the next window"
+	}
+	return "continue"
+}
+
+// signalChildren sends sig to every descendant of this process: the dump,
+// restore and compression tools doing the actual work. sfDBTools itself keeps
+// running so it can clean up and record the outcome.
+func signalChildren(sig syscall.Signal) {
+	lg, _ := logger.Get()
+	self, err := gproc.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		lg.Warn("Cannot inspect child processes", logger.Error(err))
+		return
+	}
+	for _, pid := range descendants(self) {
+		if err := syscall.Kill(int(pid), sig); err != nil {
+			lg.Debug("Failed to signal child process", logger.Int("pid", int(pid)), logger.Error(err))
+		}
+	}
+}
+
+func descendants(p *gproc.Process) []int32 {
+	children, err := p.Children()
+	if err != nil {
+		return nil
+	}
+	var pids []int32
+	for _, c := range children {
+		pids = append(pids, c.Pid)
+		pids = append(pids, descendants(c)...)
+	}
+	return pids
+}
@@ -0,0 +1,251 @@
+// Package maintenance restricts heavy operations to configured maintenance
+// windows. Commands call Enter before starting work; outside a window they are
+// refused or deferred, and a window that closes while they run can pause or
+// abort the external tools (mysqldump, mysql) doing the work. Enter and
+// Session.Close also run the pre and post hooks of the operation.
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config/model"
+	"sfDBTools/utils/common"
+)
+
+// Actions for model.MaintenanceConfig.OutsideWindow and OnWindowEnd
+const (
+	OutsideRefuse = "refuse"
+	OutsideDefer  = "defer"
+
+	EndContinue = "continue"
+	EndPause    = "pause"
+	EndAbort    = "abort"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// window is one parsed time range; start and end are offsets from midnight
+type window struct {
+	days       [7]bool
+	start, end time.Duration
+	label      string
+}
+
+// Schedule is the parsed maintenance configuration
+type Schedule struct {
+	windows     []window
+	loc         *time.Location
+	outside     string
+	maxDefer    time.Duration
+	onEnd       string
+	endWarning  time.Duration
+	description string
+}
+
+// Load parses cfg; fallbackTimezone is used when cfg.Timezone is empty
+func Load(cfg model.MaintenanceConfig, fallbackTimezone string) (*Schedule, error) {
+	s := &Schedule{loc: time.Local, outside: OutsideRefuse, onEnd: EndContinue}
+
+	tz := cfg.Timezone
+	if tz == "" {
+		tz = fallbackTimezone
+	}
+	if tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("maintenance: invalid timezone %q: %w", tz, err)
+		}
+		s.loc = loc
+	}
+
+	switch strings.ToLower(cfg.OutsideWindow) {
+	case "", OutsideRefuse:
+	case OutsideDefer:
+		s.outside = OutsideDefer
+	default:
+		return nil, fmt.Errorf("maintenance: outside_window must be %s or %s, got %q", OutsideRefuse, OutsideDefer, cfg.OutsideWindow)
+	}
+	switch strings.ToLower(cfg.OnWindowEnd) {
+	case "", EndContinue:
+	case EndPause, EndAbort:
+		s.onEnd = strings.ToLower(cfg.OnWindowEnd)
+	default:
+		return nil, fmt.Errorf("maintenance: on_window_end must be %s, %s or %s, got %q", EndContinue, EndPause, EndAbort, cfg.OnWindowEnd)
+	}
+
+	var err error
+	if s.maxDefer, err = common.ParseDurationWithDays(cfg.MaxDefer); err != nil {
+		return nil, fmt.Errorf("maintenance: max_defer: %w", err)
+	}
+	if s.endWarning, err = common.ParseDurationWithDays(cfg.EndWarning); err != nil {
+		return nil, fmt.Errorf("maintenance: end_warning: %w", err)
+	}
+
+	labels := make([]string, 0, len(cfg.Windows))
+	for i, w := range cfg.Windows {
+		parsed, err := parseWindow(w)
+		if err != nil {
+			return nil, fmt.Errorf("maintenance: window %d: %w", i+1, err)
+		}
+		s.windows = append(s.windows, parsed)
+		labels = append(labels, parsed.label)
+	}
+	s.description = strings.Join(labels, ", ")
+	return s, nil
+}
+
+func parseWindow(w model.MaintenanceWindow) (window, error) {
+	var parsed window
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return parsed, fmt.Errorf("start: %w", err)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return parsed, fmt.Errorf("end: %w", err)
+	}
+	parsed.start, parsed.end = start, end
+
+	if len(w.Days) == 0 {
+		for d := range parsed.days {
+			parsed.days[d] = true
+		}
+	}
+	for _, spec := range w.Days {
+		from, to, isRange := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), "-")
+		first, ok := weekdays[from]
+		if !ok {
+			return parsed, fmt.Errorf("unknown weekday %q (use mon, tue, ... or ranges like mon-fri)", spec)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[to]; !ok {
+				return parsed, fmt.Errorf("unknown weekday %q (use mon, tue, ... or ranges like mon-fri)", spec)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			parsed.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	days := "daily"
+	if len(w.Days) > 0 {
+		days = strings.ToLower(strings.Join(w.Days, ","))
+	}
+	parsed.label = fmt.Sprintf("%s %s-%s", days, w.Start, w.End)
+	return parsed, nil
+}
+
+// parseClock parses "HH:MM" into an offset from midnight
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (use HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Enabled reports whether any window is configured
+func (s *Schedule) Enabled() bool {
+	return s != nil && len(s.windows) > 0
+}
+
+// String describes the configured windows
+func (s *Schedule) String() string {
+	return s.description
+}
+
+// occurrence returns the start and end of w when it starts on the day of midnight
+func (w window) occurrence(midnight time.Time) (time.Time, time.Time) {
+	start := midnight.Add(w.start)
+	end := midnight.Add(w.end)
+	if w.end <= w.start {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+// midnight returns the start of the day of t in loc, shifted by offset days
+func (s *Schedule) midnight(t time.Time, offset int) time.Time {
+	local := t.In(s.loc)
+	return time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, s.loc)
+}
+
+// Current reports whether now is inside a window and when that window closes.
+// Overlapping windows extend each other.
+func (s *Schedule) Current(now time.Time) (time.Time, bool) {
+	var end time.Time
+	open := false
+	for changed := true; changed; {
+		changed = false
+		probe := now
+		if open {
+			probe = end
+		}
+		for _, w := range s.windows {
+			for offset := -1; offset <= 0; offset++ {
+				day := s.midnight(probe, offset)
+				if !w.days[day.Weekday()] {
+					continue
+				}
+				start, stop := w.occurrence(day)
+				if !probe.Before(start) && probe.Before(stop) && stop.After(end) {
+					end, open, changed = stop, true, true
+				}
+			}
+		}
+	}
+	return end, open
+}
+
+// Next returns when the next window opens after now
+func (s *Schedule) Next(now time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, w := range s.windows {
+		for offset := 0; offset <= 7; offset++ {
+			day := s.midnight(now, offset)
+			if !w.days[day.Weekday()] {
+				continue
+			}
+			start, _ := w.occurrence(day)
+			if start.After(now) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// CoversClock reports whether a daily run at clock ("HH:MM" or "HH:MM:SS")
+// falls inside a window on at least one weekday
+func (s *Schedule) CoversClock(clock string) bool {
+	if !s.Enabled() {
+		return true
+	}
+	parts := strings.SplitN(clock, ":", 3)
+	if len(parts) < 2 {
+		return true
+	}
+	at, err := parseClock(parts[0] + ":" + parts[1])
+	if err != nil {
+		return true
+	}
+	for _, w := range s.windows {
+		inside := at >= w.start && at < w.end
+		if w.end <= w.start {
+			inside = at >= w.start || at < w.end
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
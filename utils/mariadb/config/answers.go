@@ -0,0 +1,90 @@
+package mariadb
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigureAnswers berisi jawaban untuk mariadb configure yang dibaca dari
+// --answers-file (YAML). Key yang tidak diisi tetap memakai config.yaml.
+// Contoh:
+//
+//	server_id: 2
+//	port: 3306
+//	data_dir: /data/mysql
+//	log_dir: /data/logs
+//	binlog_dir: /data/binlogs
+//	innodb_encrypt_tables: true
+//	encryption_key_file: /data/keys/keyfile
+//	migrate_data: true
+type ConfigureAnswers struct {
+	ServerID                  *int    `yaml:"server_id"`
+	Port                      *int    `yaml:"port"`
+	DataDir                   *string `yaml:"data_dir"`
+	LogDir                    *string `yaml:"log_dir"`
+	BinlogDir                 *string `yaml:"binlog_dir"`
+	InnodbEncryptTables       *bool   `yaml:"innodb_encrypt_tables"`
+	EncryptionKeyFile         *string `yaml:"encryption_key_file"`
+	InnodbBufferPoolSize      *string `yaml:"innodb_buffer_pool_size"`
+	InnodbBufferPoolInstances *int    `yaml:"innodb_buffer_pool_instances"`
+	AutoTune                  *bool   `yaml:"auto_tune"`
+	BackupDir                 *string `yaml:"backup_dir"`
+	MigrateData               *bool   `yaml:"migrate_data"`
+}
+
+// LoadConfigureAnswers membaca answers file; key yang tidak dikenal ditolak
+// agar salah ketik tidak diam-diam diabaikan
+func LoadConfigureAnswers(path string) (*ConfigureAnswers, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca answers file: %w", err)
+	}
+	defer f.Close()
+
+	var answers ConfigureAnswers
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&answers); err != nil {
+		return nil, fmt.Errorf("answers file %s tidak valid: %w", path, err)
+	}
+	return &answers, nil
+}
+
+// apply menimpa nilai cfg dengan key yang diisi di answers file
+func (a *ConfigureAnswers) apply(cfg *MariaDBConfigureConfig) {
+	if a == nil {
+		return
+	}
+	setInt(&cfg.ServerID, a.ServerID)
+	setInt(&cfg.Port, a.Port)
+	setString(&cfg.DataDir, a.DataDir)
+	setString(&cfg.LogDir, a.LogDir)
+	setString(&cfg.BinlogDir, a.BinlogDir)
+	setBool(&cfg.InnodbEncryptTables, a.InnodbEncryptTables)
+	setString(&cfg.EncryptionKeyFile, a.EncryptionKeyFile)
+	setString(&cfg.InnodbBufferPoolSize, a.InnodbBufferPoolSize)
+	setInt(&cfg.InnodbBufferPoolInstances, a.InnodbBufferPoolInstances)
+	setBool(&cfg.AutoTune, a.AutoTune)
+	setString(&cfg.BackupDir, a.BackupDir)
+	setBool(&cfg.MigrateData, a.MigrateData)
+}
+
+func setInt(dst *int, v *int) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setString(dst *string, v *string) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setBool(dst *bool, v *bool) {
+	if v != nil {
+		*dst = *v
+	}
+}
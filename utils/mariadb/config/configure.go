@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"sfDBTools/internal/config"
+	"sfDBTools/utils/common"
 
 	"github.com/spf13/cobra"
 )
@@ -29,15 +30,22 @@ func AddMariaDBConfigureFlags(cmd *cobra.Command) {
 
 	// Mode configuration flags
 	cmd.Flags().Bool("auto-tune", false, "Aktifkan auto-tuning berdasarkan resource sistem")
+	cmd.Flags().Bool("review-config", false, "Tinjau dan edit server.cnf hasil template (pager/$EDITOR) sebelum diterapkan")
 
 	// Backup and safety flags
 	cmd.Flags().String("backup-dir", "", "Direktori untuk backup")
 
 	// Migration flags
 	cmd.Flags().Bool("migrate-data", false, "Migrasi data jika direktori berubah")
+
+	// Automation flags
+	cmd.Flags().Bool("non-interactive", false, "Jalankan tanpa prompt: nilai diambil dari flags, env, answers file dan config.yaml")
+	cmd.Flags().String("answers-file", "", "File YAML berisi jawaban konfigurasi (server_id, port, data_dir, ...)")
+	cmd.Flags().Bool("dry-run", false, "Tampilkan perubahan (diff config, direktori baru, rencana migrasi) tanpa menerapkannya")
 }
 
-// ResolveMariaDBConfigureConfig menggunakan pola priority: flags > env > config > defaults
+// ResolveMariaDBConfigureConfig menggunakan pola priority:
+// flags > env > answers file > config.yaml > defaults
 func ResolveMariaDBConfigureConfig(cmd *cobra.Command) (*MariaDBConfigureConfig, error) {
 	// Load config file untuk default values
 	appConfig, err := config.Get()
@@ -45,93 +53,55 @@ func ResolveMariaDBConfigureConfig(cmd *cobra.Command) (*MariaDBConfigureConfig,
 		return nil, fmt.Errorf("gagal memuat konfigurasi dari config.yaml: %w", err)
 	}
 
-	// Server ID: only from flag (no hardcoded default)
-	serverID := appConfig.MariaDB.ServerID
-	if val, err := cmd.Flags().GetInt("server-id"); err == nil && cmd.Flags().Changed("server-id") {
-		serverID = val
-	}
-
-	// Port: from config.yaml or flag (no hardcoded default)
-	port := appConfig.MariaDB.Port
-	if val, err := cmd.Flags().GetInt("port"); err == nil && cmd.Flags().Changed("port") {
-		port = val
-	}
-
-	// Directory configuration - ambil dari config.yaml atau flag
-	dataDir := appConfig.MariaDB.DataDir
-	if val, err := cmd.Flags().GetString("data-dir"); err == nil && cmd.Flags().Changed("data-dir") {
-		dataDir = val
+	// Nilai dasar dari config.yaml; performance, mode dan migrasi tidak ada di model
+	cfg := &MariaDBConfigureConfig{
+		ServerID:            appConfig.MariaDB.ServerID,
+		Port:                appConfig.MariaDB.Port,
+		DataDir:             appConfig.MariaDB.DataDir,
+		LogDir:              appConfig.MariaDB.LogDir,
+		BinlogDir:           appConfig.MariaDB.BinlogDir,
+		InnodbEncryptTables: appConfig.MariaDB.InnodbEncryptTables,
+		EncryptionKeyFile:   appConfig.MariaDB.EncryptionKeyFile,
+		AutoTune:            true,
+		BackupDir:           appConfig.Backup.Storage.BaseDirectory,
 	}
 
-	logDir := appConfig.MariaDB.LogDir
-	if val, err := cmd.Flags().GetString("log-dir"); err == nil && cmd.Flags().Changed("log-dir") {
-		logDir = val
-	}
-
-	binlogDir := appConfig.MariaDB.BinlogDir
-	if val, err := cmd.Flags().GetString("binlog-dir"); err == nil && cmd.Flags().Changed("binlog-dir") {
-		binlogDir = val
-	}
-
-	// Encryption configuration
-	innodbEncryptTables := appConfig.MariaDB.InnodbEncryptTables
-	if val, err := cmd.Flags().GetBool("innodb_encrypt_tables"); err == nil && cmd.Flags().Changed("innodb_encrypt_tables") {
-		innodbEncryptTables = val
-	}
-	// Gunakan encryption key dari config.yaml (no hardcoded fallback)
-	encryptionKeyFile := appConfig.MariaDB.EncryptionKeyFile
-	if val, err := cmd.Flags().GetString("encryption-key-file"); err == nil && cmd.Flags().Changed("encryption-key-file") {
-		encryptionKeyFile = val
+	// Answers file menimpa config.yaml, lalu env dan flags menimpa answers file
+	if path := common.GetStringFlagOrEnv(cmd, "answers-file", "SFDBTOOLS_CONFIGURE_ANSWERS", ""); path != "" {
+		answers, err := LoadConfigureAnswers(path)
+		if err != nil {
+			return nil, err
+		}
+		answers.apply(cfg)
 	}
 
-	// Performance configuration (not present in appConfig model) — use flags only
-	innodbBufferPoolSize := ""
-	if val, err := cmd.Flags().GetString("innodb-buffer-pool-size"); err == nil && cmd.Flags().Changed("innodb-buffer-pool-size") {
-		innodbBufferPoolSize = val
+	cfg.ServerID = common.GetIntFlagOrEnv(cmd, "server-id", "SFDBTOOLS_MARIADB_SERVER_ID", cfg.ServerID)
+	cfg.Port = common.GetIntFlagOrEnv(cmd, "port", "SFDBTOOLS_MARIADB_PORT", cfg.Port)
+	cfg.DataDir = common.GetStringFlagOrEnv(cmd, "data-dir", "SFDBTOOLS_MARIADB_DATA_DIR", cfg.DataDir)
+	cfg.LogDir = common.GetStringFlagOrEnv(cmd, "log-dir", "SFDBTOOLS_MARIADB_LOG_DIR", cfg.LogDir)
+	cfg.BinlogDir = common.GetStringFlagOrEnv(cmd, "binlog-dir", "SFDBTOOLS_MARIADB_BINLOG_DIR", cfg.BinlogDir)
+	cfg.InnodbEncryptTables = common.GetBoolFlagOrEnv(cmd, "innodb_encrypt_tables", "SFDBTOOLS_MARIADB_INNODB_ENCRYPT_TABLES", cfg.InnodbEncryptTables)
+	cfg.EncryptionKeyFile = common.GetStringFlagOrEnv(cmd, "encryption-key-file", "SFDBTOOLS_MARIADB_ENCRYPTION_KEY_FILE", cfg.EncryptionKeyFile)
+	cfg.InnodbBufferPoolSize = common.GetStringFlagOrEnv(cmd, "innodb-buffer-pool-size", "SFDBTOOLS_MARIADB_INNODB_BUFFER_POOL_SIZE", cfg.InnodbBufferPoolSize)
+	cfg.InnodbBufferPoolInstances = common.GetIntFlagOrEnv(cmd, "innodb-buffer-pool-instances", "SFDBTOOLS_MARIADB_INNODB_BUFFER_POOL_INSTANCES", cfg.InnodbBufferPoolInstances)
+	cfg.AutoTune = common.GetBoolFlagOrEnv(cmd, "auto-tune", "SFDBTOOLS_MARIADB_AUTO_TUNE", cfg.AutoTune)
+	cfg.BackupDir = common.GetStringFlagOrEnv(cmd, "backup-dir", "SFDBTOOLS_MARIADB_BACKUP_DIR", cfg.BackupDir)
+	cfg.MigrateData = common.GetBoolFlagOrEnv(cmd, "migrate-data", "SFDBTOOLS_MARIADB_MIGRATE_DATA", cfg.MigrateData)
+	cfg.NonInteractive = common.GetBoolFlagOrEnv(cmd, "non-interactive", "SFDBTOOLS_NON_INTERACTIVE", false)
+	cfg.DryRun = common.GetBoolFlagOrEnv(cmd, "dry-run", "SFDBTOOLS_DRY_RUN", false)
+
+	// Review server.cnf sebelum ditulis - only from flag
+	if val, err := cmd.Flags().GetBool("review-config"); err == nil && cmd.Flags().Changed("review-config") {
+		cfg.ReviewConfig = val
 	}
-
-	innodbBufferPoolInstances := 0
-	if val, err := cmd.Flags().GetInt("innodb-buffer-pool-instances"); err == nil && cmd.Flags().Changed("innodb-buffer-pool-instances") {
-		innodbBufferPoolInstances = val
-	}
-
-	// Mode configuration (auto-tune) - only from flag
-	autoTune := true
-	if val, err := cmd.Flags().GetBool("auto-tune"); err == nil && cmd.Flags().Changed("auto-tune") {
-		autoTune = val
-	}
-
-	// Backup and safety configuration - only from flag
-	backupDir := appConfig.Backup.Storage.BaseDirectory
-	if val, err := cmd.Flags().GetString("backup-dir"); err == nil && cmd.Flags().Changed("backup-dir") {
-		backupDir = val
-	}
-
-	// Migration configuration - only from flag
-	migrateData := false
-	if val, err := cmd.Flags().GetBool("migrate-data"); err == nil && cmd.Flags().Changed("migrate-data") {
-		migrateData = val
-	}
-
-	mariadbCfg := &MariaDBConfigureConfig{
-		ServerID:                  serverID,
-		Port:                      port,
-		DataDir:                   dataDir,
-		LogDir:                    logDir,
-		BinlogDir:                 binlogDir,
-		InnodbEncryptTables:       innodbEncryptTables,
-		EncryptionKeyFile:         encryptionKeyFile,
-		InnodbBufferPoolSize:      innodbBufferPoolSize,
-		InnodbBufferPoolInstances: innodbBufferPoolInstances,
-		AutoTune:                  autoTune,
-		BackupDir:                 backupDir,
-		MigrateData:               migrateData,
+	if cfg.NonInteractive && cfg.ReviewConfig {
+		return nil, fmt.Errorf("--review-config membutuhkan terminal dan tidak bisa dipakai bersama --non-interactive")
 	}
 
 	// Validasi input user (penting untuk konfigurasi sistem)
-	if err := validateConfigureInput(mariadbCfg); err != nil {
+	if err := validateConfigureInput(cfg); err != nil {
 		return nil, fmt.Errorf("validasi konfigurasi gagal: %w", err)
 	}
 
-	return mariadbCfg, nil
+	return cfg, nil
 }
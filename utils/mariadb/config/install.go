@@ -2,11 +2,14 @@ package mariadb
 
 import (
 	"fmt"
+	"os"
 
 	"sfDBTools/internal/config"
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/database"
+	"sfDBTools/utils/database/connection"
 	"sfDBTools/utils/mariadb/discovery"
+	"sfDBTools/utils/mariadb/versions"
 
 	"github.com/spf13/cobra"
 )
@@ -40,23 +43,30 @@ func ResolveMariaDBInstallConfig(cmd *cobra.Command) (*MariaDBInstallConfig, err
 	}
 
 	// Validasi konfigurasi basic (format saja)
-	if err := validateVersionFormat(cfg.Version); err != nil {
+	if err := versions.Validate(cfg.Version); err != nil {
 		return nil, fmt.Errorf("format versi tidak valid: %w", err)
 	}
 
 	return cfg, nil
 }
 
-// CreateDatabaseConfigFromInstallation creates a basic database.Config from installation info
+// CreateDatabaseConfigFromInstallation creates a basic database.Config from installation info.
+// Local root usually authenticates with unix_socket (MariaDB 10.4+), so the socket
+// is used when known; unix_socket only applies when running as OS root.
 func CreateDatabaseConfigFromInstallation(installation *discovery.MariaDBInstallation) *database.Config {
 	if installation == nil {
 		return nil
 	}
-	return &database.Config{
+	cfg := &database.Config{
 		Host:     "localhost",
 		Port:     installation.Port,
 		User:     "root",
 		Password: "",
 		DBName:   "",
+		Socket:   installation.SocketPath,
 	}
+	if cfg.Socket != "" && os.Geteuid() == 0 {
+		cfg.AuthPlugin = connection.AuthPluginUnixSocket
+	}
+	return cfg
 }
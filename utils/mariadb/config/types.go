@@ -26,9 +26,19 @@ type MariaDBConfigureConfig struct {
 	// Performance configuration
 	InnodbBufferPoolSize      string `json:"innodb_buffer_pool_size"`
 	InnodbBufferPoolInstances int    `json:"innodb_buffer_pool_instances"`
+	// Set by auto-tuning from the data dir's disk type; empty keeps the template default
+	InnodbFlushNeighbors string `json:"innodb_flush_neighbors"`
+	InnodbIOCapacity     string `json:"innodb_io_capacity"`
+	DataDirMedia         string `json:"data_dir_media"`
 
 	// Mode configuration
 	AutoTune bool `json:"auto_tune"`
+	// Tinjau (dan edit) server.cnf hasil template sebelum ditulis
+	ReviewConfig bool `json:"review_config"`
+	// Tanpa prompt sama sekali, untuk automation (Ansible, CI) tanpa TTY
+	NonInteractive bool `json:"non_interactive"`
+	// Jalankan semua langkah dan laporkan perubahan tanpa menerapkannya
+	DryRun bool `json:"dry_run"`
 
 	// Backup and safety configuration
 	BackupDir string `json:"backup_dir"`
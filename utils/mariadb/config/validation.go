@@ -3,31 +3,8 @@ package mariadb
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
 )
 
-// validateVersionFormat melakukan validasi sederhana format versi
-func validateVersionFormat(version string) error {
-	// Versi harus berupa angka dan titik, misalnya: 10.6, 10.6.23, 11.4
-	if len(version) == 0 {
-		return fmt.Errorf("versi tidak boleh kosong")
-	}
-
-	// Cek apakah mengandung karakter yang valid
-	for _, char := range version {
-		if char != '.' && (char < '0' || char > '9') {
-			return fmt.Errorf("karakter tidak valid dalam versi: %c", char)
-		}
-	}
-
-	// Minimal harus ada satu titik untuk major.minor
-	if !strings.Contains(version, ".") {
-		return fmt.Errorf("format versi harus berupa major.minor (contoh: 10.6)")
-	}
-
-	return nil
-}
-
 // validateConfigureInput melakukan validasi input untuk MariaDB configure
 func validateConfigureInput(cfg *MariaDBConfigureConfig) error {
 	// Server ID validation
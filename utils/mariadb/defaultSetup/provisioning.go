@@ -0,0 +1,82 @@
+package defaultsetup
+
+import (
+	"context"
+	"fmt"
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/core/mariadb/provision"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/terminal"
+	"strings"
+)
+
+// loadDefaultProvisioning membaca template provisioning dari
+// config_dir.mariadb_provisioning dengan client_code dari konfigurasi
+func loadDefaultProvisioning() (*provision.Template, string, error) {
+	lg, _ := logger.Get()
+
+	conf, confErr := config.Get()
+	values := provision.Values{ClientCode: "demo"}
+	path := ""
+	if confErr == nil && conf != nil {
+		if conf.General.ClientCode != "" {
+			values.ClientCode = conf.General.ClientCode
+		}
+		path = conf.ConfigDir.MariaDBProvisioning
+	} else {
+		lg.Debug("Gagal membaca konfigurasi, menggunakan client_code default 'demo'", logger.Error(confErr))
+	}
+	if path == "" {
+		return nil, "", fmt.Errorf("template provisioning belum diatur (config_dir.mariadb_provisioning)")
+	}
+
+	tmpl, err := provision.Load(path, values)
+	if err != nil {
+		return nil, "", err
+	}
+	return tmpl, values.ClientCode, nil
+}
+
+// CheckDefaultProvisioning memastikan template provisioning bisa dibaca dan
+// setiap user punya password sebelum instalasi dimulai, supaya instalasi
+// tidak gagal di tengah jalan. root@localhost dibuat oleh paket MariaDB.
+func CheckDefaultProvisioning() error {
+	tmpl, _, err := loadDefaultProvisioning()
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, u := range tmpl.WithoutPassword() {
+		if u.User == "root" && u.Host == "localhost" {
+			continue
+		}
+		missing = append(missing, u.Name())
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("template provisioning tidak memberi password untuk user %s; atur environment variable yang dibaca template", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ApplyDefaultProvisioning membuat database, user dan grant default dari
+// template provisioning. Password diambil dari template (env/file), bukan
+// dari source code.
+func ApplyDefaultProvisioning(ctx context.Context) error {
+	lg, _ := logger.Get()
+
+	tmpl, clientCode, err := loadDefaultProvisioning()
+	if err != nil {
+		return err
+	}
+
+	// Jalankan via mysql client lokal (root lewat socket setelah instalasi)
+	result, err := provision.Apply(tmpl, provision.NewClientRunner(ctx))
+	if err != nil {
+		lg.Debug("Gagal menerapkan template provisioning", logger.Error(err))
+		return fmt.Errorf("gagal menerapkan provisioning default: %w", err)
+	}
+
+	terminal.PrintSuccess(fmt.Sprintf("%d database, %d user baru, %d password diperbarui", len(tmpl.Databases), len(result.Created), len(result.Reset)))
+	lg.Info("Provisioning default berhasil diterapkan untuk client " + clientCode)
+	return nil
+}
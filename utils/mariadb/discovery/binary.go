@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"regexp"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/mariadb/versions"
 )
 
 // detectMariaDBBinary mendeteksi binary MariaDB/MySQL
@@ -34,29 +34,11 @@ func detectMariaDBBinary(installation *MariaDBInstallation) error {
 // detectMariaDBVersion mendeteksi versi MariaDB
 func detectMariaDBVersion(installation *MariaDBInstallation) error {
 	lg, _ := logger.Get()
-	cmd := exec.Command(installation.BinaryPath, "--version")
-	output, err := cmd.Output()
+	version, err := versions.CommandFetcher{Binaries: []string{installation.BinaryPath}}.Fetch()
 	if err != nil {
-		return fmt.Errorf("gagal menjalankan %s --version: %w", installation.BinaryPath, err)
+		return err
 	}
-	version := parseVersionFromOutput(string(output))
-	if version != "" {
-		installation.Version = version
-		lg.Info("Terdeteksi versi MariaDB", logger.String("version", version))
-		return nil
-	}
-	return fmt.Errorf("gagal parsing versi dari output: %s", string(output))
-}
-
-// parseVersionFromOutput parsing versi dari output command
-func parseVersionFromOutput(output string) string {
-	patterns := []string{`mariadb\s+Ver\s+(\d+\.\d+\.\d+)`, `MariaDB\s+(\d+\.\d+\.\d+)`, `mysql\s+Ver\s+(\d+\.\d+\.\d+).*MariaDB`, `(\d+\.\d+\.\d+)-MariaDB`}
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(`(?i)` + pattern)
-		matches := re.FindStringSubmatch(output)
-		if len(matches) >= 2 {
-			return matches[1]
-		}
-	}
-	return ""
+	installation.Version = version
+	lg.Info("Terdeteksi versi MariaDB", logger.String("version", version))
+	return nil
 }
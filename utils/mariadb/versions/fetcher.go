@@ -0,0 +1,96 @@
+package versions
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// Fetcher membaca versi MariaDB dari satu sumber: binary yang terinstall,
+// server yang berjalan, atau sumber lain seperti manifest backup
+type Fetcher interface {
+	Fetch() (string, error)
+}
+
+// RunFunc menjalankan command dan mengembalikan output-nya, mis.
+// system.ProcessManager.ExecuteWithOutput
+type RunFunc func(command string, args []string) (string, error)
+
+// CommandFetcher membaca versi dari output `<binary> --version` untuk binary
+// pertama yang menjawab dengan versi MariaDB
+type CommandFetcher struct {
+	// Run menjalankan binary; nil memakai exec.Command langsung
+	Run RunFunc
+	// Binaries dicoba berurutan; kosong berarti mariadb lalu mysql
+	Binaries []string
+}
+
+// Fetch menjalankan setiap binary dengan --version sampai ada versi yang terbaca
+func (f CommandFetcher) Fetch() (string, error) {
+	run := f.Run
+	if run == nil {
+		run = func(command string, args []string) (string, error) {
+			out, err := exec.Command(command, args...).Output()
+			return string(out), err
+		}
+	}
+	binaries := f.Binaries
+	if len(binaries) == 0 {
+		binaries = []string{"mariadb", "mysql"}
+	}
+
+	var errs []error
+	for _, binary := range binaries {
+		output, err := run(binary, []string{"--version"})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("gagal menjalankan %s --version: %w", binary, err))
+			continue
+		}
+		if version := FromOutput(output); version != "" {
+			return version, nil
+		}
+		errs = append(errs, fmt.Errorf("gagal parsing versi dari output %s: %s", binary, output))
+	}
+	return "", errors.Join(errs...)
+}
+
+// QueryFetcher membaca versi server yang berjalan dengan SELECT VERSION().
+// Hasilnya utuh, mis. "10.6.23-MariaDB-log"; gunakan Extract untuk angkanya.
+type QueryFetcher struct {
+	DB *sql.DB
+}
+
+// Fetch menjalankan SELECT VERSION()
+func (f QueryFetcher) Fetch() (string, error) {
+	var version string
+	if err := f.DB.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// First mengembalikan versi dari fetcher pertama yang berhasil
+func First(fetchers ...Fetcher) (string, error) {
+	var errs []error
+	for _, f := range fetchers {
+		version, err := f.Fetch()
+		if err == nil && version != "" {
+			return version, nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return "", fmt.Errorf("versi MariaDB tidak ditemukan")
+	}
+	return "", errors.Join(errs...)
+}
+
+// Installed mengembalikan versi MariaDB yang terinstall menurut mariadb atau
+// mysql --version; kosong bila tidak terdeteksi
+func Installed(run RunFunc) string {
+	version, _ := CommandFetcher{Run: run}.Fetch()
+	return version
+}
@@ -0,0 +1,150 @@
+// Package versions adalah satu-satunya tempat parsing, perbandingan dan
+// pembacaan versi MariaDB. Versi dari config, output `mariadb --version`,
+// SELECT VERSION() dan manifest backup dibaca dengan aturan yang sama, sehingga
+// install, upgrade, remove, discovery dan DR tidak lagi berbeda pendapat
+// tentang versi mana yang lebih baru.
+package versions
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version adalah versi MariaDB major.minor[.patch]. HasPatch false untuk versi
+// seri seperti 10.6; dalam perbandingan patch yang tidak disebut dianggap 0.
+type Version struct {
+	Major    int
+	Minor    int
+	Patch    int
+	HasPatch bool
+}
+
+// leadingVersion mengambil major.minor[.patch] di awal string, mis. dari
+// "10.6.23-MariaDB-log"
+var leadingVersion = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// Parse membaca versi di awal s; akhiran seperti "-MariaDB-log" diabaikan
+func Parse(s string) (Version, error) {
+	m := leadingVersion.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Version{}, fmt.Errorf("versi tidak valid: %q", s)
+	}
+	v := Version{}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+		v.HasPatch = true
+	}
+	return v, nil
+}
+
+// String mengembalikan versi dalam bentuk 10.6 atau 10.6.23
+func (v Version) String() string {
+	if v.HasPatch {
+		return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	}
+	return v.Series()
+}
+
+// Series mengembalikan seri major.minor, mis. 10.6 untuk 10.6.23
+func (v Version) Series() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// Compare membandingkan v dengan o secara numerik (10.11 lebih baru dari
+// 10.6); hasilnya -1, 0 atau 1
+func (v Version) Compare(o Version) int {
+	for _, d := range [][2]int{{v.Major, o.Major}, {v.Minor, o.Minor}, {v.Patch, o.Patch}} {
+		switch {
+		case d[0] < d[1]:
+			return -1
+		case d[0] > d[1]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Compare membandingkan dua string versi; hasilnya -1, 0 atau 1. Versi yang
+// tidak dapat dibaca dianggap lebih lama dari versi yang valid.
+func Compare(a, b string) int {
+	va, errA := Parse(a)
+	vb, errB := Parse(b)
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return va.Compare(vb)
+}
+
+// Series mengembalikan seri major.minor dari s (mis. 10.6 untuk 10.6.23).
+// Skrip mariadb_repo_setup dan repository paket hanya mengenal seri; s yang
+// tidak dapat dibaca dikembalikan apa adanya.
+func Series(s string) string {
+	v, err := Parse(s)
+	if err != nil {
+		return s
+	}
+	return v.Series()
+}
+
+// SameSeries membandingkan major.minor saja, karena repository paket bisa
+// hanya menyediakan patch release yang lebih baru dari versi yang dicatat
+func SameSeries(a, b string) bool {
+	return Series(a) == Series(b)
+}
+
+// Extract mengembalikan major.minor[.patch] di awal s, mis. 10.6.23 dari
+// "10.6.23-MariaDB-log"; kosong bila s tidak diawali versi
+func Extract(s string) string {
+	v, err := Parse(s)
+	if err != nil {
+		return ""
+	}
+	return v.String()
+}
+
+// Validate memastikan s adalah versi major.minor atau major.minor.patch
+// tanpa akhiran, seperti yang diterima --version
+func Validate(s string) error {
+	if len(s) == 0 {
+		return fmt.Errorf("versi tidak boleh kosong")
+	}
+	for _, char := range s {
+		if char != '.' && (char < '0' || char > '9') {
+			return fmt.Errorf("karakter tidak valid dalam versi: %c", char)
+		}
+	}
+	if _, err := Parse(s); err != nil || strings.Count(s, ".") > 2 {
+		return fmt.Errorf("format versi harus berupa major.minor (contoh: 10.6)")
+	}
+	return nil
+}
+
+// outputPatterns mengenali versi server di output `--version` client dan
+// server MariaDB, mis. "mysql  Ver 15.1 Distrib 10.6.23-MariaDB, for Linux"
+// atau "mariadb from 11.4.2-MariaDB, client 15.2"
+var outputPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(\d+\.\d+\.\d+)-MariaDB`),
+	regexp.MustCompile(`(?i)mariadb\s+Ver\s+(\d+\.\d+\.\d+)`),
+	regexp.MustCompile(`(?i)MariaDB\s+(\d+\.\d+\.\d+)`),
+	regexp.MustCompile(`(?i)mysql\s+Ver\s+(\d+\.\d+\.\d+).*MariaDB`),
+}
+
+// FromOutput mengambil versi MariaDB dari output `--version`; kosong bila
+// output bukan dari MariaDB
+func FromOutput(output string) string {
+	for _, re := range outputPatterns {
+		if m := re.FindStringSubmatch(output); len(m) >= 2 {
+			return m[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package maxscale
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Client talks to the MaxScale REST API (default port 8989)
+type Client struct {
+	http *resty.Client
+}
+
+// NewClient creates a REST API client for the MaxScale instance at host:port
+func NewClient(host string, port int, user, password string) *Client {
+	http := resty.New().
+		SetTimeout(10*time.Second).
+		SetBaseURL(fmt.Sprintf("http://%s:%d/v1", host, port)).
+		SetBasicAuth(user, password).
+		SetHeader("User-Agent", "sfDBTools")
+	return &Client{http: http}
+}
+
+// serversResponse mirrors the subset of /v1/servers we use
+type serversResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			State      string `json:"state"`
+			Parameters struct {
+				Address string `json:"address"`
+				Port    int    `json:"port"`
+			} `json:"parameters"`
+			Statistics struct {
+				Connections int `json:"connections"`
+			} `json:"statistics"`
+			GTIDCurrentPos string `json:"gtid_current_pos"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// ListServers returns the runtime state of every server known to MaxScale
+func (c *Client) ListServers() ([]ServerState, error) {
+	resp, err := c.http.R().Get("/servers")
+	if err != nil {
+		return nil, fmt.Errorf("MaxScale API request failed: %w", err)
+	}
+	if resp.StatusCode() >= 400 {
+		return nil, fmt.Errorf("MaxScale API returned %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	var parsed serversResponse
+	if err := json.Unmarshal(resp.Body(), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse MaxScale API response: %w", err)
+	}
+
+	states := make([]ServerState, 0, len(parsed.Data))
+	for _, d := range parsed.Data {
+		states = append(states, ServerState{
+			Name:        d.ID,
+			Address:     d.Attributes.Parameters.Address,
+			Port:        d.Attributes.Parameters.Port,
+			State:       d.Attributes.State,
+			Connections: d.Attributes.Statistics.Connections,
+			GTID:        d.Attributes.GTIDCurrentPos,
+		})
+	}
+	return states, nil
+}
+
+// SetServerState sets a state flag (e.g. "maintenance", "drain") on a server
+func (c *Client) SetServerState(server, state string) error {
+	return c.put(fmt.Sprintf("/servers/%s/set?state=%s", url.PathEscape(server), url.QueryEscape(state)))
+}
+
+// ClearServerState clears a state flag on a server
+func (c *Client) ClearServerState(server, state string) error {
+	return c.put(fmt.Sprintf("/servers/%s/clear?state=%s", url.PathEscape(server), url.QueryEscape(state)))
+}
+
+// Ping checks that the REST API is reachable and credentials are valid
+func (c *Client) Ping() error {
+	resp, err := c.http.R().Get("/maxscale")
+	if err != nil {
+		return fmt.Errorf("MaxScale API request failed: %w", err)
+	}
+	if resp.StatusCode() >= 400 {
+		return fmt.Errorf("MaxScale API returned %d: %s", resp.StatusCode(), resp.String())
+	}
+	return nil
+}
+
+func (c *Client) put(path string) error {
+	resp, err := c.http.R().Put(path)
+	if err != nil {
+		return fmt.Errorf("MaxScale request %s failed: %w", path, err)
+	}
+	if resp.StatusCode() >= 400 {
+		return fmt.Errorf("MaxScale request %s returned %d: %s", path, resp.StatusCode(), resp.String())
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package maxscale
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseServers converts "host:port" entries into named servers (server1, server2, ...)
+func ParseServers(entries []string) ([]Server, error) {
+	servers := make([]Server, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, portStr := entry, "3306"
+		if idx := strings.LastIndex(entry, ":"); idx > 0 {
+			host, portStr = entry[:idx], entry[idx+1:]
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port <= 0 || port > 65535 {
+			return nil, fmt.Errorf("invalid server entry %q: bad port", entry)
+		}
+		servers = append(servers, Server{
+			Name:    fmt.Sprintf("server%d", len(servers)+1),
+			Address: host,
+			Port:    port,
+		})
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no servers configured")
+	}
+	return servers, nil
+}
+
+// GenerateConfig renders a maxscale.cnf for the given topology
+func GenerateConfig(t Topology) (string, error) {
+	if len(t.Servers) == 0 {
+		return "", fmt.Errorf("topology has no servers")
+	}
+	router := t.Router
+	if router == "" {
+		router = "readwritesplit"
+	}
+	listenPort := t.ListenPort
+	if listenPort == 0 {
+		listenPort = 4006
+	}
+
+	names := make([]string, 0, len(t.Servers))
+	for _, s := range t.Servers {
+		names = append(names, s.Name)
+	}
+	serverList := strings.Join(names, ",")
+
+	var b strings.Builder
+	b.WriteString("# Generated by sfDBTools - manual changes will be overwritten\n\n")
+	b.WriteString("[maxscale]\nthreads=auto\nadmin_host=127.0.0.1\nadmin_port=8989\n\n")
+
+	for _, s := range t.Servers {
+		fmt.Fprintf(&b, "[%s]\ntype=server\naddress=%s\nport=%d\nprotocol=MariaDBBackend\n\n", s.Name, s.Address, s.Port)
+	}
+
+	monitor, monitorName := t.Monitor, "MariaDB-Monitor"
+	switch monitor {
+	case "", "mariadbmon":
+		monitor = "mariadbmon"
+	case "galeramon":
+		monitorName = "Galera-Monitor"
+	default:
+		return "", fmt.Errorf("unsupported monitor %q (use mariadbmon or galeramon)", monitor)
+	}
+	fmt.Fprintf(&b, "[%s]\ntype=monitor\nmodule=%s\nservers=%s\nuser=%s\npassword=%s\nmonitor_interval=2000ms\n", monitorName, monitor, serverList, t.MonitorUser, t.MonitorPassword)
+	// Galera nodes are all writable; failover is only needed for replication
+	if monitor == "mariadbmon" && len(t.Servers) > 1 {
+		b.WriteString("auto_failover=true\nauto_rejoin=true\n")
+	}
+	b.WriteString("\n")
+
+	serviceName := "RW-Split-Router"
+	if router != "readwritesplit" {
+		serviceName = "Read-Connection-Router"
+	}
+	fmt.Fprintf(&b, "[%s]\ntype=service\nrouter=%s\nservers=%s\nuser=%s\npassword=%s\n", serviceName, router, serverList, t.ServiceUser, t.ServicePassword)
+	if router == "readwritesplit" {
+		b.WriteString("master_reconnection=true\ntransaction_replay=true\n")
+	} else {
+		b.WriteString("router_options=running\n")
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "[%s-Listener]\ntype=listener\nservice=%s\nprotocol=MariaDBClient\nport=%d\n", serviceName, serviceName, listenPort)
+
+	return b.String(), nil
+}
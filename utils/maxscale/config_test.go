@@ -0,0 +1,23 @@
+package maxscale
+
+import "testing"
+
+func TestParseServersNamesEmittedServers(t *testing.T) {
+	servers, err := ParseServers([]string{"db1:3306", " ", "", "db2", "db3:3307"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Server{
+		{Name: "server1", Address: "db1", Port: 3306},
+		{Name: "server2", Address: "db2", Port: 3306},
+		{Name: "server3", Address: "db3", Port: 3307},
+	}
+	if len(servers) != len(want) {
+		t.Fatalf("got %d servers, want %d", len(servers), len(want))
+	}
+	for i := range want {
+		if servers[i] != want[i] {
+			t.Errorf("server %d = %+v, want %+v", i, servers[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package maxscale
+
+import (
+	"errors"
+	"fmt"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/secrets"
+
+	"github.com/spf13/cobra"
+)
+
+// AddAPIFlags adds REST API connection flags to the given command
+func AddAPIFlags(cmd *cobra.Command) {
+	cmd.Flags().String("api-host", "", "MaxScale REST API host (default from config maxscale.api_host)")
+	cmd.Flags().Int("api-port", 0, "MaxScale REST API port (default from config maxscale.api_port)")
+	cmd.Flags().String("api-user", "", "MaxScale REST API user (or set maxscale.api_user)")
+	cmd.Flags().String("api-password", "", "MaxScale REST API password or secret reference (or set maxscale.api_password)")
+}
+
+// ResolveClient builds a REST API client from flags, environment and config in
+// that order. The user and password have no default; either may be a secret
+// reference (vault://, awssm://, envfile://).
+func ResolveClient(cmd *cobra.Command) (*Client, error) {
+	host, port, user, password := "127.0.0.1", 8989, "", ""
+	if cfg, err := config.Get(); err == nil && cfg != nil {
+		if cfg.MaxScale.APIHost != "" {
+			host = cfg.MaxScale.APIHost
+		}
+		if cfg.MaxScale.APIPort != 0 {
+			port = cfg.MaxScale.APIPort
+		}
+		user = cfg.MaxScale.APIUser
+		password = cfg.MaxScale.APIPassword
+	}
+
+	host = common.GetStringFlagOrEnv(cmd, "api-host", "MAXSCALE_API_HOST", host)
+	port = common.GetIntFlagOrEnv(cmd, "api-port", "MAXSCALE_API_PORT", port)
+	user = common.GetStringFlagOrEnv(cmd, "api-user", "MAXSCALE_API_USER", user)
+	password = common.GetStringFlagOrEnv(cmd, "api-password", "MAXSCALE_API_PASSWORD", password)
+	if user == "" || password == "" {
+		return nil, common.WithExitCode(errors.New("MaxScale REST API credentials are not set: use --api-user and --api-password, MAXSCALE_API_USER and MAXSCALE_API_PASSWORD, or maxscale.api_user and maxscale.api_password"), common.ExitConfig)
+	}
+	user, password, err := secrets.ResolveCredentials(user, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve MaxScale REST API credentials: %w", err)
+	}
+
+	return NewClient(host, port, user, password), nil
+}
@@ -0,0 +1,102 @@
+package maxscale
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/system"
+)
+
+// ServiceName is the systemd unit name of MaxScale
+const ServiceName = "maxscale"
+
+// configGroup owns maxscale.cnf so the service can read the passwords in it
+// while other users cannot
+const configGroup = "maxscale"
+
+// WriteConfig writes the rendered configuration to path, keeping a timestamped
+// copy of the previous file next to it. Both files hold passwords, so they are
+// written with mode 0640 and group maxscale through a temporary file that is
+// renamed into place. The returned string is the backup path.
+func WriteConfig(path, content string) (string, error) {
+	lg, _ := logger.Get()
+
+	backupPath := ""
+	if _, err := os.Stat(path); err == nil {
+		backupPath = fmt.Sprintf("%s.bak-%s", path, time.Now().Format("20060102-150405"))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read existing config %s: %w", path, err)
+		}
+		if err := writeConfigFile(backupPath, data); err != nil {
+			return "", fmt.Errorf("failed to backup existing config to %s: %w", backupPath, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := writeConfigFile(path, []byte(content)); err != nil {
+		return "", fmt.Errorf("failed to write config %s: %w", path, err)
+	}
+
+	lg.Info("MaxScale configuration written", logger.String("path", path), logger.String("backup", backupPath))
+	return backupPath, nil
+}
+
+// writeConfigFile atomically replaces path with data, mode 0640 and group
+// maxscale when that group exists. Only root can hand the file to the group;
+// other users keep their own group, which is still private.
+func writeConfigFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := tmp.Chmod(0640); err != nil {
+		return err
+	}
+	if g, err := user.LookupGroup(configGroup); err == nil {
+		if gid, err := strconv.Atoi(g.Gid); err == nil {
+			if err := tmp.Chown(-1, gid); err != nil && os.Geteuid() == 0 {
+				return fmt.Errorf("failed to give %s to group %s: %w", path, configGroup, err)
+			}
+		}
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Reload asks systemd to reload MaxScale, restarting it when reload is not supported
+func Reload() error {
+	lg, _ := logger.Get()
+	sm := system.NewServiceManager()
+
+	if err := sm.Reload(ServiceName); err != nil {
+		lg.Warn("MaxScale reload failed, falling back to restart", logger.Error(err))
+		if err := sm.Restart(ServiceName); err != nil {
+			return fmt.Errorf("failed to restart MaxScale: %w", err)
+		}
+	}
+
+	if !sm.IsActive(ServiceName) {
+		return fmt.Errorf("MaxScale service is not active after reload")
+	}
+	lg.Info("MaxScale reloaded")
+	return nil
+}
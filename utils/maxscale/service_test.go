@@ -0,0 +1,40 @@
+package maxscale
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteConfigKeepsPasswordsPrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maxscale.cnf")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := WriteConfig(path, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for file, want := range map[string]string{path: "new", backup: "old"} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", file, data, want)
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0640 {
+			t.Errorf("%s mode = %o, want 640", file, mode)
+		}
+	}
+
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".maxscale.cnf.tmp-*"))
+	if len(leftovers) > 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+}
@@ -0,0 +1,33 @@
+package maxscale
+
+// Server represents a backend database server in a MaxScale topology
+type Server struct {
+	Name    string
+	Address string
+	Port    int
+}
+
+// Topology describes the MaxScale configuration to generate
+type Topology struct {
+	Servers         []Server
+	MonitorUser     string
+	MonitorPassword string
+	ServiceUser     string
+	ServicePassword string
+	ListenPort      int
+	// Router is the MaxScale router module, e.g. "readwritesplit" or "readconnroute"
+	Router string
+	// Monitor is the monitor module: "mariadbmon" for primary/replica
+	// replication (default) or "galeramon" for a Galera cluster
+	Monitor string
+}
+
+// ServerState is the runtime state of a server as reported by the REST API
+type ServerState struct {
+	Name        string
+	Address     string
+	Port        int
+	State       string
+	Connections int
+	GTID        string
+}
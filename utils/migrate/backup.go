@@ -1,6 +1,7 @@
 package migrate_utils
 
 import (
+	"context"
 	"fmt"
 
 	backup_single_mysqldump "sfDBTools/internal/core/backup/single/mysqldump"
@@ -9,7 +10,7 @@ import (
 )
 
 // BackupDatabaseForMigration creates a backup of a database (source or target) for migration
-func BackupDatabaseForMigration(config *MigrationConfig, isSource bool, lg *logger.Logger) (string, error) {
+func BackupDatabaseForMigration(ctx context.Context, config *MigrationConfig, isSource bool, lg *logger.Logger) (string, error) {
 	// Get database connection info
 	dbInfo := getDBInfo(config, isSource)
 	dbType := getDBType(isSource)
@@ -22,7 +23,7 @@ func BackupDatabaseForMigration(config *MigrationConfig, isSource bool, lg *logg
 	backupOptions := createBackupOptions(dbInfo, config.MigrateData)
 
 	// Perform backup
-	result, err := backup_single_mysqldump.BackupSingle(backupOptions)
+	result, err := backup_single_mysqldump.BackupSingle(ctx, backupOptions)
 	if err != nil {
 		return "", fmt.Errorf("failed to backup %s database: %w", dbType, err)
 	}
@@ -0,0 +1,440 @@
+package migrate_utils
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/database"
+)
+
+const (
+	// maxIndexKeyBytes is the InnoDB limit of a whole index key with 16K or
+	// larger pages; smaller pages lower it proportionally
+	maxIndexKeyBytes = 3072
+	// maxCompactColumnBytes is the InnoDB limit of one indexed column (or
+	// column prefix) in the REDUNDANT and COMPACT row formats
+	maxCompactColumnBytes = 767
+)
+
+// CompatibilityIssue describes a single schema object that may not migrate cleanly
+type CompatibilityIssue struct {
+	Database   string
+	Table      string
+	Column     string
+	Kind       string // "charset", "collation" or "index_length"
+	Value      string
+	Suggestion string
+	Blocking   bool
+}
+
+// CompatibilityReport aggregates the result of a cross-version compatibility scan
+type CompatibilityReport struct {
+	SourceVersion string
+	TargetVersion string
+	Databases     []string
+	Issues        []CompatibilityIssue
+}
+
+// HasBlockingIssues returns true when at least one issue would break the migration
+func (r *CompatibilityReport) HasBlockingIssues() bool {
+	for _, issue := range r.Issues {
+		if issue.Blocking {
+			return true
+		}
+	}
+	return false
+}
+
+// collationFallbacks maps collations that only exist on newer servers to a widely available equivalent
+var collationFallbacks = map[string]string{
+	"utf8mb4_0900_ai_ci":     "utf8mb4_unicode_520_ci",
+	"utf8mb4_0900_as_ci":     "utf8mb4_unicode_520_ci",
+	"utf8mb4_0900_as_cs":     "utf8mb4_bin",
+	"utf8mb4_0900_bin":       "utf8mb4_bin",
+	"utf8mb4_uca1400_ai_ci":  "utf8mb4_unicode_520_ci",
+	"utf8mb4_uca1400_as_ci":  "utf8mb4_unicode_520_ci",
+	"utf8mb4_uca1400_as_cs":  "utf8mb4_bin",
+	"utf8mb3_uca1400_ai_ci":  "utf8mb3_unicode_520_ci",
+	"utf8mb4_unicode_520_ci": "utf8mb4_unicode_ci",
+}
+
+// CheckCollationCompatibility scans the given databases on the source server for charsets and
+// collations that are unknown to the target server, and for indexes whose key length exceeds
+// the InnoDB limit. The returned report lists suggested conversions for each issue.
+func CheckCollationCompatibility(source, target database.Config, databases []string) (*CompatibilityReport, error) {
+	lg, _ := logger.Get()
+
+	srcDB, err := database.GetWithoutDB(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to source server: %w", err)
+	}
+	defer srcDB.Close()
+
+	tgtDB, err := database.GetWithoutDB(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to target server: %w", err)
+	}
+	defer tgtDB.Close()
+
+	report := &CompatibilityReport{Databases: databases}
+	_ = srcDB.QueryRow("SELECT VERSION()").Scan(&report.SourceVersion)
+	_ = tgtDB.QueryRow("SELECT VERSION()").Scan(&report.TargetVersion)
+
+	targetCollations, targetCharsets, err := loadTargetCollations(tgtDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target collations: %w", err)
+	}
+
+	maxLens, err := loadCharsetMaxLen(srcDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source charsets: %w", err)
+	}
+	limits := loadIndexLimits(tgtDB)
+	lg.Debug("Target index key limits",
+		logger.Int("key_bytes", int(limits.keyBytes)),
+		logger.String("default_row_format", limits.defaultRowFormat))
+
+	for _, dbName := range databases {
+		if err := scanSchemaDefaults(srcDB, dbName, targetCollations, targetCharsets, report); err != nil {
+			return nil, err
+		}
+		if err := scanTableCollations(srcDB, dbName, targetCollations, report); err != nil {
+			return nil, err
+		}
+		if err := scanColumnCollations(srcDB, dbName, targetCollations, targetCharsets, report); err != nil {
+			return nil, err
+		}
+		if err := scanIndexLengths(srcDB, dbName, maxLens, limits, report); err != nil {
+			return nil, err
+		}
+	}
+
+	lg.Info("Collation compatibility check completed",
+		logger.String("source_version", report.SourceVersion),
+		logger.String("target_version", report.TargetVersion),
+		logger.Int("databases", len(databases)),
+		logger.Int("issues", len(report.Issues)),
+		logger.Bool("blocking", report.HasBlockingIssues()))
+
+	return report, nil
+}
+
+// loadTargetCollations returns the sets of collations and charsets known to the target server
+func loadTargetCollations(db *sql.DB) (map[string]bool, map[string]bool, error) {
+	rows, err := db.Query("SELECT COLLATION_NAME, CHARACTER_SET_NAME FROM information_schema.COLLATIONS")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	collations := make(map[string]bool)
+	charsets := make(map[string]bool)
+	for rows.Next() {
+		var name, charset string
+		if err := rows.Scan(&name, &charset); err != nil {
+			continue
+		}
+		collations[strings.ToLower(name)] = true
+		charsets[strings.ToLower(charset)] = true
+	}
+	return collations, charsets, rows.Err()
+}
+
+// loadCharsetMaxLen returns the maximum bytes per character for each charset on the source
+func loadCharsetMaxLen(db *sql.DB) (map[string]int, error) {
+	rows, err := db.Query("SELECT CHARACTER_SET_NAME, MAXLEN FROM information_schema.CHARACTER_SETS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	maxLens := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var maxLen int
+		if err := rows.Scan(&name, &maxLen); err != nil {
+			continue
+		}
+		maxLens[strings.ToLower(name)] = maxLen
+	}
+	return maxLens, rows.Err()
+}
+
+func scanSchemaDefaults(db *sql.DB, dbName string, collations, charsets map[string]bool, report *CompatibilityReport) error {
+	var charset, collation string
+	err := db.QueryRow(`SELECT DEFAULT_CHARACTER_SET_NAME, DEFAULT_COLLATION_NAME
+		FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?`, dbName).Scan(&charset, &collation)
+	if err != nil {
+		return fmt.Errorf("failed to read schema defaults for %s: %w", dbName, err)
+	}
+
+	if !charsets[strings.ToLower(charset)] {
+		report.Issues = append(report.Issues, CompatibilityIssue{
+			Database: dbName, Kind: "charset", Value: charset,
+			Suggestion: "ALTER DATABASE `" + dbName + "` CHARACTER SET utf8mb4", Blocking: true,
+		})
+	}
+	if !collations[strings.ToLower(collation)] {
+		report.Issues = append(report.Issues, CompatibilityIssue{
+			Database: dbName, Kind: "collation", Value: collation,
+			Suggestion: "ALTER DATABASE `" + dbName + "` COLLATE " + suggestCollation(collation, collations), Blocking: true,
+		})
+	}
+	return nil
+}
+
+func scanTableCollations(db *sql.DB, dbName string, collations map[string]bool, report *CompatibilityReport) error {
+	rows, err := db.Query(`SELECT TABLE_NAME, TABLE_COLLATION FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE' AND TABLE_COLLATION IS NOT NULL`, dbName)
+	if err != nil {
+		return fmt.Errorf("failed to read table collations for %s: %w", dbName, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table, collation string
+		if err := rows.Scan(&table, &collation); err != nil {
+			continue
+		}
+		if collations[strings.ToLower(collation)] {
+			continue
+		}
+		suggested := suggestCollation(collation, collations)
+		report.Issues = append(report.Issues, CompatibilityIssue{
+			Database: dbName, Table: table, Kind: "collation", Value: collation,
+			Suggestion: fmt.Sprintf("ALTER TABLE `%s`.`%s` CONVERT TO CHARACTER SET %s COLLATE %s",
+				dbName, table, charsetOf(suggested), suggested),
+			Blocking: true,
+		})
+	}
+	return rows.Err()
+}
+
+func scanColumnCollations(db *sql.DB, dbName string, collations, charsets map[string]bool, report *CompatibilityReport) error {
+	rows, err := db.Query(`SELECT TABLE_NAME, COLUMN_NAME, CHARACTER_SET_NAME, COLLATION_NAME
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND COLLATION_NAME IS NOT NULL`, dbName)
+	if err != nil {
+		return fmt.Errorf("failed to read column collations for %s: %w", dbName, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table, column, charset, collation string
+		if err := rows.Scan(&table, &column, &charset, &collation); err != nil {
+			continue
+		}
+		if !charsets[strings.ToLower(charset)] {
+			report.Issues = append(report.Issues, CompatibilityIssue{
+				Database: dbName, Table: table, Column: column, Kind: "charset", Value: charset,
+				Suggestion: "convert column to utf8mb4", Blocking: true,
+			})
+			continue
+		}
+		if !collations[strings.ToLower(collation)] {
+			report.Issues = append(report.Issues, CompatibilityIssue{
+				Database: dbName, Table: table, Column: column, Kind: "collation", Value: collation,
+				Suggestion: "COLLATE " + suggestCollation(collation, collations), Blocking: true,
+			})
+		}
+	}
+	return rows.Err()
+}
+
+// indexLimits are the InnoDB index key limits of the target server
+type indexLimits struct {
+	// keyBytes is the limit of a whole key, derived from innodb_page_size
+	keyBytes int64
+	// defaultRowFormat is innodb_default_row_format, used by tables that do
+	// not name their row format
+	defaultRowFormat string
+}
+
+// loadIndexLimits reads the page size and default row format of the target.
+// Servers that do not report them get the MariaDB defaults (16K pages,
+// DYNAMIC).
+func loadIndexLimits(db *sql.DB) indexLimits {
+	limits := indexLimits{keyBytes: maxIndexKeyBytes, defaultRowFormat: "dynamic"}
+	var pageSize int64
+	if err := db.QueryRow("SELECT @@innodb_page_size").Scan(&pageSize); err == nil && pageSize > 0 {
+		limits.keyBytes = keyBytesForPageSize(pageSize)
+	}
+	var rowFormat string
+	if err := db.QueryRow("SELECT @@innodb_default_row_format").Scan(&rowFormat); err == nil && rowFormat != "" {
+		limits.defaultRowFormat = strings.ToLower(rowFormat)
+	}
+	return limits
+}
+
+// keyBytesForPageSize returns the key limit for an InnoDB page size: 3072
+// bytes from 16K pages up, 1536 for 8K and 768 for 4K pages
+func keyBytesForPageSize(pageSize int64) int64 {
+	return min(maxIndexKeyBytes, pageSize*3/16)
+}
+
+// columnBytes returns the limit of one indexed column in rowFormat
+func (l indexLimits) columnBytes(rowFormat string) int64 {
+	switch rowFormat {
+	case "redundant", "compact":
+		return min(maxCompactColumnBytes, l.keyBytes)
+	}
+	return l.keyBytes
+}
+
+// indexColumn is one column of a B-tree index
+type indexColumn struct {
+	// subPart is the prefix length in characters of a prefix index
+	subPart sql.NullInt64
+	// charLen is the length of the column in characters
+	charLen int64
+	// bytesPerChar is the maximum length of a character of the column's charset
+	bytesPerChar int64
+}
+
+// keyBytes returns the bytes the column takes in the key: the prefix for a
+// prefix index, the whole column otherwise
+func (c indexColumn) keyBytes() int64 {
+	chars := c.charLen
+	if c.subPart.Valid {
+		chars = c.subPart.Int64
+	}
+	return chars * c.bytesPerChar
+}
+
+// checkIndexLength returns the key length of an index and, when the target
+// would reject the index in rowFormat, why
+func checkIndexLength(columns []indexColumn, rowFormat string, limits indexLimits) (int64, string) {
+	var total int64
+	columnLimit := limits.columnBytes(rowFormat)
+	problem := ""
+	for _, c := range columns {
+		n := c.keyBytes()
+		total += n
+		if n > columnLimit && problem == "" {
+			problem = fmt.Sprintf("a column takes %d bytes, over the %d bytes allowed per column in %s row format", n, columnLimit, strings.ToUpper(rowFormat))
+		}
+	}
+	if total > limits.keyBytes {
+		problem = fmt.Sprintf("the key takes %d bytes, over the %d bytes allowed by the target", total, limits.keyBytes)
+	}
+	return total, problem
+}
+
+// loadTableRowFormats returns the row format each table of dbName would get
+// on the target: the one named in its CREATE TABLE, else the target default
+func loadTableRowFormats(db *sql.DB, dbName string, limits indexLimits) (map[string]string, error) {
+	rows, err := db.Query(`SELECT TABLE_NAME, COALESCE(CREATE_OPTIONS, '')
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'`, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read row formats for %s: %w", dbName, err)
+	}
+	defer rows.Close()
+
+	formats := make(map[string]string)
+	for rows.Next() {
+		var table, options string
+		if err := rows.Scan(&table, &options); err != nil {
+			continue
+		}
+		formats[table] = limits.defaultRowFormat
+		for _, opt := range strings.Fields(strings.ToLower(options)) {
+			if format, ok := strings.CutPrefix(opt, "row_format="); ok && format != "default" {
+				formats[table] = format
+			}
+		}
+	}
+	return formats, rows.Err()
+}
+
+// scanIndexLengths flags B-tree indexes whose key, or one of whose columns,
+// exceeds the InnoDB limits of the target. FULLTEXT, SPATIAL and long
+// UNIQUE (HASH) indexes have no such limit and are skipped.
+func scanIndexLengths(db *sql.DB, dbName string, maxLens map[string]int, limits indexLimits, report *CompatibilityReport) error {
+	rowFormats, err := loadTableRowFormats(db, dbName, limits)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query(`SELECT s.TABLE_NAME, s.INDEX_NAME, s.SUB_PART, c.CHARACTER_MAXIMUM_LENGTH, c.CHARACTER_SET_NAME
+		FROM information_schema.STATISTICS s
+		JOIN information_schema.COLUMNS c
+		  ON c.TABLE_SCHEMA = s.TABLE_SCHEMA AND c.TABLE_NAME = s.TABLE_NAME AND c.COLUMN_NAME = s.COLUMN_NAME
+		WHERE s.TABLE_SCHEMA = ? AND s.INDEX_TYPE = 'BTREE' AND c.CHARACTER_SET_NAME IS NOT NULL
+		ORDER BY s.TABLE_NAME, s.INDEX_NAME, s.SEQ_IN_INDEX`, dbName)
+	if err != nil {
+		return fmt.Errorf("failed to read index definitions for %s: %w", dbName, err)
+	}
+	defer rows.Close()
+
+	type indexKey struct{ table, index string }
+	columns := make(map[indexKey][]indexColumn)
+	var order []indexKey
+
+	for rows.Next() {
+		var table, index, charset string
+		var subPart, charLen sql.NullInt64
+		if err := rows.Scan(&table, &index, &subPart, &charLen, &charset); err != nil {
+			continue
+		}
+		bytesPerChar := int64(maxLens[strings.ToLower(charset)])
+		if bytesPerChar == 0 {
+			bytesPerChar = 4
+		}
+		key := indexKey{table, index}
+		if _, seen := columns[key]; !seen {
+			order = append(order, key)
+		}
+		columns[key] = append(columns[key], indexColumn{subPart: subPart, charLen: charLen.Int64, bytesPerChar: bytesPerChar})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, key := range order {
+		rowFormat := rowFormats[key.table]
+		if rowFormat == "" {
+			rowFormat = limits.defaultRowFormat
+		}
+		total, problem := checkIndexLength(columns[key], rowFormat, limits)
+		if problem == "" {
+			continue
+		}
+		report.Issues = append(report.Issues, CompatibilityIssue{
+			Database: dbName, Table: key.table, Column: key.index, Kind: "index_length",
+			Value:      fmt.Sprintf("%d bytes", total),
+			Suggestion: problem + "; use a prefix index, shorten the columns or use ROW_FORMAT=DYNAMIC",
+			Blocking:   true,
+		})
+	}
+	return nil
+}
+
+// suggestCollation walks the fallback chain until it finds a collation available on the target
+func suggestCollation(collation string, available map[string]bool) string {
+	current := strings.ToLower(collation)
+	for i := 0; i < 5; i++ {
+		next, ok := collationFallbacks[current]
+		if !ok {
+			break
+		}
+		if available[next] {
+			return next
+		}
+		current = next
+	}
+	if strings.HasPrefix(current, "utf8mb3") || strings.HasPrefix(current, "utf8_") {
+		return "utf8mb3_general_ci"
+	}
+	return "utf8mb4_general_ci"
+}
+
+// charsetOf returns the charset prefix of a collation name
+func charsetOf(collation string) string {
+	if idx := strings.Index(collation, "_"); idx > 0 {
+		return collation[:idx]
+	}
+	return collation
+}
@@ -0,0 +1,57 @@
+package migrate_utils
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func varchar(chars, bytesPerChar int64) indexColumn {
+	return indexColumn{charLen: chars, bytesPerChar: bytesPerChar}
+}
+
+func prefix(chars, prefixChars, bytesPerChar int64) indexColumn {
+	return indexColumn{charLen: chars, subPart: sql.NullInt64{Int64: prefixChars, Valid: true}, bytesPerChar: bytesPerChar}
+}
+
+func TestKeyBytesForPageSize(t *testing.T) {
+	for pageSize, want := range map[int64]int64{4096: 768, 8192: 1536, 16384: 3072, 32768: 3072, 65536: 3072} {
+		if got := keyBytesForPageSize(pageSize); got != want {
+			t.Errorf("keyBytesForPageSize(%d) = %d, want %d", pageSize, got, want)
+		}
+	}
+}
+
+func TestCheckIndexLength(t *testing.T) {
+	dynamic16K := indexLimits{keyBytes: 3072, defaultRowFormat: "dynamic"}
+	dynamic8K := indexLimits{keyBytes: 1536, defaultRowFormat: "dynamic"}
+
+	tests := []struct {
+		name      string
+		columns   []indexColumn
+		rowFormat string
+		limits    indexLimits
+		wantBytes int64
+		wantFail  bool
+	}{
+		{"fits", []indexColumn{varchar(255, 4)}, "dynamic", dynamic16K, 1020, false},
+		{"whole key too long", []indexColumn{varchar(500, 4), varchar(400, 4)}, "dynamic", dynamic16K, 3600, true},
+		{"exactly the limit", []indexColumn{varchar(768, 4)}, "dynamic", dynamic16K, 3072, false},
+		{"prefix index on a long column", []indexColumn{prefix(65535, 191, 4)}, "dynamic", dynamic16K, 764, false},
+		{"prefix index too long", []indexColumn{prefix(65535, 1000, 4)}, "dynamic", dynamic16K, 4000, true},
+		{"smaller page size", []indexColumn{varchar(500, 4)}, "dynamic", dynamic8K, 2000, true},
+		{"compact column limit", []indexColumn{varchar(255, 4)}, "compact", dynamic16K, 1020, true},
+		{"compact prefix fits", []indexColumn{prefix(255, 191, 4)}, "compact", dynamic16K, 764, false},
+		{"redundant with short utf8mb3", []indexColumn{varchar(255, 3)}, "redundant", dynamic16K, 765, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, problem := checkIndexLength(tt.columns, tt.rowFormat, tt.limits)
+			if total != tt.wantBytes {
+				t.Errorf("key bytes = %d, want %d", total, tt.wantBytes)
+			}
+			if (problem != "") != tt.wantFail {
+				t.Errorf("problem = %q, want failure %v", problem, tt.wantFail)
+			}
+		})
+	}
+}
@@ -96,26 +96,31 @@ func ResolveMigrationConfig(cmd *cobra.Command) (*MigrationConfig, error) {
 
 // AddCommonMigrationFlags adds common migration flags for single database migration
 func AddCommonMigrationFlags(cmd *cobra.Command) {
+	AddMigrationConnectionFlags(cmd)
+	cmd.Flags().String("source-db", "", "source database name")
+	cmd.Flags().String("target-db", "", "target database name (defaults to source database name)")
+
+	// Migration options
+	cmd.Flags().Bool("migrate-users", true, "migrate database users and grants")
+	cmd.Flags().Bool("migrate-data", true, "migrate database data")
+	cmd.Flags().Bool("migrate-structure", true, "migrate database structure")
+	cmd.Flags().Bool("verify-data", true, "verify data integrity after migration")
+	cmd.Flags().Bool("backup-target", true, "backup target database before migration")
+}
+
+// AddMigrationConnectionFlags adds only the source and target connection flags
+func AddMigrationConnectionFlags(cmd *cobra.Command) {
 	// Source configuration options
 	cmd.Flags().String("source-config", "", "source encrypted configuration file (.cnf.enc)")
 	cmd.Flags().String("source-host", "", "source database host")
 	cmd.Flags().Int("source-port", 0, "source database port")
 	cmd.Flags().String("source-user", "", "source database user")
-	cmd.Flags().String("source-password", "", "source database password")
-	cmd.Flags().String("source-db", "", "source database name")
+	cmd.Flags().String("source-password", "", "source database password, or a secret reference (vault://, awssm://, envfile://)")
 
 	// Target configuration options
 	cmd.Flags().String("target-config", "", "target encrypted configuration file (.cnf.enc)")
 	cmd.Flags().String("target-host", "", "target database host")
 	cmd.Flags().Int("target-port", 0, "target database port")
 	cmd.Flags().String("target-user", "", "target database user")
-	cmd.Flags().String("target-password", "", "target database password")
-	cmd.Flags().String("target-db", "", "target database name (defaults to source database name)")
-
-	// Migration options
-	cmd.Flags().Bool("migrate-users", true, "migrate database users and grants")
-	cmd.Flags().Bool("migrate-data", true, "migrate database data")
-	cmd.Flags().Bool("migrate-structure", true, "migrate database structure")
-	cmd.Flags().Bool("verify-data", true, "verify data integrity after migration")
-	cmd.Flags().Bool("backup-target", true, "backup target database before migration")
+	cmd.Flags().String("target-password", "", "target database password, or a secret reference (vault://, awssm://, envfile://)")
 }
@@ -1,60 +1,20 @@
 package migrate_utils
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
 	"sfDBTools/internal/logger"
-	"strings"
+	"sfDBTools/utils/terminal"
 )
 
 // PromptMigrationConfirmation prompts user for confirmation before performing migration
-func PromptMigrationConfirmation(config *MigrationConfig) error {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Println("\n⚠️  MIGRATION CONFIRMATION")
-	fmt.Println("==========================")
-	fmt.Printf("You are about to migrate database:\n")
-	fmt.Printf("  Source Database: %s\n", config.SourceDBName)
-	fmt.Printf("  Source Host:     %s:%d\n", config.SourceHost, config.SourcePort)
-	fmt.Printf("  Source User:     %s\n", config.SourceUser)
-	fmt.Printf("  Target Database: %s\n", config.TargetDBName)
-	fmt.Printf("  Target Host:     %s:%d\n", config.TargetHost, config.TargetPort)
-	fmt.Printf("  Target User:     %s\n", config.TargetUser)
-
-	fmt.Println("\n📋 Migration Options:")
-	if config.BackupTarget {
-		fmt.Println("  ✅ Backup target database (if exists)")
-	}
-	if config.DropTarget {
-		fmt.Println("  ✅ Drop target database")
-	}
-	if config.CreateTarget {
-		fmt.Println("  ✅ Create target database")
-	}
-	if config.MigrateStructure {
-		fmt.Println("  ✅ Migrate database structure")
+func PromptMigrationConfirmation(ctx context.Context, config *MigrationConfig) error {
+	plan := BuildMigrationPlan(ctx, config, config, []string{config.SourceDBName})
+	if config.TargetDBName != "" && config.TargetDBName != config.SourceDBName {
+		plan.Target = fmt.Sprintf("%s (database: %s)", plan.Target, config.TargetDBName)
 	}
-	if config.MigrateData {
-		fmt.Println("  ✅ Migrate database data")
-	}
-	if config.MigrateUsers {
-		fmt.Println("  ✅ Migrate database users and grants")
-	}
-	if config.VerifyData {
-		fmt.Println("  ✅ Verify data integrity after migration")
-	}
-
-	fmt.Println("\n🚨 WARNING: This will overwrite existing data in the target database!")
 
-	fmt.Print("\nDo you want to continue with the migration? [y/N]: ")
-	confirmInput, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read confirmation: %w", err)
-	}
-
-	confirm := strings.ToLower(strings.TrimSpace(confirmInput))
-	if confirm != "y" && confirm != "yes" {
+	if !terminal.ConfirmPlan(plan, "Do you want to continue with the migration?") {
 		return fmt.Errorf("migration operation cancelled by user")
 	}
 
@@ -63,59 +23,10 @@ func PromptMigrationConfirmation(config *MigrationConfig) error {
 }
 
 // PromptBulkMigrationConfirmation prompts user for confirmation before performing bulk migration
-func PromptBulkMigrationConfirmation(sourceConfig, targetConfig *MigrationConfig, databases []string) error {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Println("\n⚠️  BULK MIGRATION CONFIRMATION")
-	fmt.Println("===============================")
-	fmt.Printf("You are about to migrate %d database(s):\n", len(databases))
-
-	// Show first few databases and total count
-	for i, dbName := range databases {
-		if i < 5 { // Show first 5 databases
-			fmt.Printf("  %d. %s\n", i+1, dbName)
-		} else if i == 5 {
-			fmt.Printf("  ... and %d more databases\n", len(databases)-5)
-			break
-		}
-	}
-
-	fmt.Printf("\nSource Server: %s:%d (user: %s)\n", sourceConfig.SourceHost, sourceConfig.SourcePort, sourceConfig.SourceUser)
-	fmt.Printf("Target Server: %s:%d (user: %s)\n", targetConfig.TargetHost, targetConfig.TargetPort, targetConfig.TargetUser)
-
-	fmt.Println("\n📋 Migration Options for ALL databases:")
-	if sourceConfig.BackupTarget {
-		fmt.Println("  ✅ Backup target databases (if they exist)")
-	}
-	if sourceConfig.DropTarget {
-		fmt.Println("  ✅ Drop target databases")
-	}
-	if sourceConfig.CreateTarget {
-		fmt.Println("  ✅ Create target databases")
-	}
-	if sourceConfig.MigrateStructure {
-		fmt.Println("  ✅ Migrate database structures")
-	}
-	if sourceConfig.MigrateData {
-		fmt.Println("  ✅ Migrate database data")
-	}
-	if sourceConfig.MigrateUsers {
-		fmt.Println("  ✅ Migrate database users and grants")
-	}
-	if sourceConfig.VerifyData {
-		fmt.Println("  ✅ Verify data integrity after migration")
-	}
-
-	fmt.Println("\n🚨 WARNING: This will overwrite existing data in ALL target databases!")
-
-	fmt.Print("\nDo you want to continue with the bulk migration? [y/N]: ")
-	confirmInput, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read confirmation: %w", err)
-	}
+func PromptBulkMigrationConfirmation(ctx context.Context, sourceConfig, targetConfig *MigrationConfig, databases []string) error {
+	plan := BuildMigrationPlan(ctx, sourceConfig, targetConfig, databases)
 
-	confirm := strings.ToLower(strings.TrimSpace(confirmInput))
-	if confirm != "y" && confirm != "yes" {
+	if !terminal.ConfirmPlan(plan, "Do you want to continue with the bulk migration?") {
 		return fmt.Errorf("bulk migration operation cancelled by user")
 	}
 
@@ -140,3 +51,28 @@ func DisplayMigrationSummary(databases []string, successCount, errorCount int, d
 			logger.String("duration", duration))
 	}
 }
+
+// DisplayCompatibilityReport prints the result of a collation compatibility scan
+func DisplayCompatibilityReport(report *CompatibilityReport) {
+	fmt.Println("\n=== Collation Compatibility Report ===")
+	fmt.Printf("Source Version:     %s\n", report.SourceVersion)
+	fmt.Printf("Target Version:     %s\n", report.TargetVersion)
+	fmt.Printf("Databases Scanned:  %d\n", len(report.Databases))
+	fmt.Printf("Issues Found:       %d\n", len(report.Issues))
+
+	if len(report.Issues) == 0 {
+		terminal.PrintSuccess("No incompatible charsets, collations or index lengths found")
+		return
+	}
+
+	headers := []string{"Database", "Table", "Column/Index", "Kind", "Value", "Suggestion"}
+	rows := make([][]string, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		rows = append(rows, []string{issue.Database, issue.Table, issue.Column, issue.Kind, issue.Value, issue.Suggestion})
+	}
+	terminal.FormatTable(headers, rows)
+
+	if report.HasBlockingIssues() {
+		terminal.PrintError("Blocking compatibility issues found - convert the objects above before migrating")
+	}
+}
@@ -0,0 +1,71 @@
+package migrate_utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/terminal"
+)
+
+// migrationThroughput is a conservative dump-and-load rate used for duration estimates
+const migrationThroughput = 20 << 20 // bytes per second
+
+// BuildMigrationPlan describes a migration of the given databases for the
+// confirmation summary. Source sizes are collected to estimate the duration.
+func BuildMigrationPlan(ctx context.Context, source, target *MigrationConfig, databases []string) terminal.OperationPlan {
+	plan := terminal.OperationPlan{
+		Operation: "Database Migration",
+		Source:    fmt.Sprintf("%s:%d (user: %s)", source.SourceHost, source.SourcePort, source.SourceUser),
+		Target:    fmt.Sprintf("%s:%d (user: %s)", target.TargetHost, target.TargetPort, target.TargetUser),
+		Databases: databases,
+	}
+
+	if source.CreateTarget {
+		plan.Actions = append(plan.Actions, "Create target databases")
+	}
+	if source.MigrateStructure {
+		plan.Actions = append(plan.Actions, "Migrate structure")
+	}
+	if source.MigrateData {
+		plan.Actions = append(plan.Actions, "Migrate data")
+	}
+	if source.MigrateUsers {
+		plan.Actions = append(plan.Actions, "Migrate users and grants")
+	}
+	if source.VerifyData {
+		plan.Actions = append(plan.Actions, "Verify data after migration")
+	}
+
+	if source.DropTarget {
+		plan.Destructive = append(plan.Destructive, "DROP existing target databases")
+	}
+	plan.Destructive = append(plan.Destructive, "Overwrite existing tables in target databases")
+	if source.MigrateUsers {
+		plan.Destructive = append(plan.Destructive, "Modify user privileges on target server")
+	}
+
+	if source.BackupTarget {
+		plan.Backup = terminal.BackupSafety{Protected: true, Detail: "target databases are backed up before they are overwritten"}
+	} else {
+		plan.Backup = terminal.BackupSafety{Detail: "target databases are not backed up (enable backup target)"}
+	}
+
+	sourceDB := database.Config{
+		Host:     source.SourceHost,
+		Port:     source.SourcePort,
+		User:     source.SourceUser,
+		Password: source.SourcePassword,
+	}
+	var totalBytes int64
+	for _, s := range info.CollectDatabaseSummaries(ctx, sourceDB, databases) {
+		totalBytes += s.SizeBytes
+	}
+	if totalBytes > 0 {
+		plan.EstimatedDuration = time.Duration(totalBytes/migrationThroughput) * time.Second
+	}
+
+	return plan
+}
@@ -1,16 +1,14 @@
 package migrate_utils
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"strconv"
-	"strings"
 
 	"sfDBTools/internal/config"
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/database"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/secrets"
+	"sfDBTools/utils/terminal"
 
 	"github.com/spf13/cobra"
 )
@@ -46,6 +44,10 @@ func ResolveSourceDatabaseConnection(cmd *cobra.Command) (host string, port int,
 		port := common.GetIntFlagOrEnv(cmd, "source-port", "SOURCE_PORT", 3306)
 		user := common.GetStringFlagOrEnv(cmd, "source-user", "SOURCE_USER", "root")
 		password := common.GetStringFlagOrEnv(cmd, "source-password", "SOURCE_PASSWORD", "")
+		user, password, err := secrets.ResolveCredentials(user, password)
+		if err != nil {
+			return "", 0, "", "", SourceFlags, err
+		}
 
 		return host, port, user, password, SourceFlags, nil
 	}
@@ -102,6 +104,10 @@ func ResolveTargetDatabaseConnection(cmd *cobra.Command) (host string, port int,
 		port := common.GetIntFlagOrEnv(cmd, "target-port", "TARGET_PORT", 3306)
 		user := common.GetStringFlagOrEnv(cmd, "target-user", "TARGET_USER", "root")
 		password := common.GetStringFlagOrEnv(cmd, "target-password", "TARGET_PASSWORD", "")
+		user, password, err := secrets.ResolveCredentials(user, password)
+		if err != nil {
+			return "", 0, "", "", SourceFlags, err
+		}
 
 		return host, port, user, password, SourceFlags, nil
 	}
@@ -199,32 +205,13 @@ func SelectTargetDatabaseInteractive(config database.Config) (string, error) {
 		return "", fmt.Errorf("failed to retrieve database list: %w", err)
 	}
 
-	// Display available databases with new database option
-	fmt.Println("📁 Available Target Databases:")
-	fmt.Println("=============================")
-
-	// Option to create new database
-	fmt.Printf("   0. 🆕 Create new database\n")
-
-	// List existing databases
-	for i, db := range databases {
-		fmt.Printf("   %d. %s\n", i+1, db)
-	}
-
-	// Let user choose
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\nSelect target database (0-%d): ", len(databases))
-	choice, err := reader.ReadString('\n')
+	// The first option creates a new database
+	options := append([]string{"🆕 Create new database"}, databases...)
+	index, err := terminal.PickOne("📁 Available Target Databases", options)
 	if err != nil {
 		return "", fmt.Errorf("failed to read selection: %w", err)
 	}
 
-	choice = strings.TrimSpace(choice)
-	index, err := strconv.Atoi(choice)
-	if err != nil || index < 0 || index > len(databases) {
-		return "", fmt.Errorf("invalid selection: %s", choice)
-	}
-
 	if index == 0 {
 		// User chose to create new database
 		return promptForNewTargetDatabaseName()
@@ -236,18 +223,10 @@ func SelectTargetDatabaseInteractive(config database.Config) (string, error) {
 
 // promptForNewTargetDatabaseName prompts user for new target database name
 func promptForNewTargetDatabaseName() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Print("Enter new target database name: ")
-	dbName, err := reader.ReadString('\n')
+	dbName, err := terminal.Input("Enter new target database name", "", terminal.NotEmpty)
 	if err != nil {
 		return "", fmt.Errorf("failed to read database name: %w", err)
 	}
 
-	dbName = strings.TrimSpace(dbName)
-	if dbName == "" {
-		return "", fmt.Errorf("database name cannot be empty")
-	}
-
 	return dbName, nil
 }
@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config/model"
+	"sfDBTools/utils/secrets"
+)
+
+// TLS modes of model.EmailNotifyConfig
+const (
+	EmailStartTLS = "starttls"
+	EmailTLS      = "tls"
+	EmailNoTLS    = "none"
+)
+
+// Email mails the alert as plain text through an SMTP server
+type Email struct {
+	Config model.EmailNotifyConfig
+}
+
+func (e *Email) Name() string { return "email" }
+
+func (e *Email) Notify(ctx context.Context, a Alert) error {
+	cfg := e.Config
+	mode := strings.ToLower(cfg.TLS)
+	switch mode {
+	case "":
+		mode = EmailStartTLS
+	case EmailStartTLS, EmailTLS, EmailNoTLS:
+	default:
+		return fmt.Errorf("notification.email.tls must be %s, %s or %s, got %q", EmailStartTLS, EmailTLS, EmailNoTLS, cfg.TLS)
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+		if mode == EmailTLS {
+			port = 465
+		}
+	}
+	from := cfg.From
+	if from == "" {
+		from = cfg.Username
+	}
+	if from == "" {
+		return fmt.Errorf("notification.email.from is not set")
+	}
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+	if mode == EmailTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp %s: %w", addr, err)
+	}
+	defer c.Close()
+
+	if mode == EmailStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("smtp %s: STARTTLS: %w", addr, err)
+			}
+		}
+	}
+	if cfg.Username != "" {
+		password, err := secrets.Resolve(cfg.Password)
+		if err != nil {
+			return fmt.Errorf("failed to resolve notification.email.password: %w", err)
+		}
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, password, cfg.Host)); err != nil {
+			return fmt.Errorf("smtp %s: authentication failed: %w", addr, err)
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("smtp %s: MAIL FROM: %w", addr, err)
+	}
+	for _, to := range cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp %s: RCPT TO %s: %w", addr, to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp %s: DATA: %w", addr, err)
+	}
+	if _, err := w.Write(mailMessage(from, cfg.To, a)); err != nil {
+		return fmt.Errorf("smtp %s: %w", addr, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp %s: %w", addr, err)
+	}
+	return c.Quit()
+}
+
+// mailMessage builds the headers and plain text body of the alert mail
+func mailMessage(from string, to []string, a Alert) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", fmt.Sprintf("[%s] %s", strings.ToUpper(a.Severity), a.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", a.Time.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(plainText(a), "\n", "\r\n"))
+	return []byte(b.String())
+}
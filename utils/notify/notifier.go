@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config/model"
+)
+
+// Notifier is a destination for alerts
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, a Alert) error
+}
+
+// Notifiers returns the destinations configured in cfg
+func Notifiers(cfg model.NotifyConfig) []Notifier {
+	var notifiers []Notifier
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, &Webhook{URL: cfg.WebhookURL})
+	}
+	if cfg.Slack.WebhookURL != "" {
+		notifiers = append(notifiers, &Slack{WebhookURL: cfg.Slack.WebhookURL, Channel: cfg.Slack.Channel, Username: cfg.Slack.Username})
+	}
+	if cfg.Email.Host != "" && len(cfg.Email.To) > 0 {
+		notifiers = append(notifiers, &Email{Config: cfg.Email})
+	}
+	if cfg.Command != "" {
+		notifiers = append(notifiers, &Command{Command: cfg.Command})
+	}
+	return notifiers
+}
+
+// Webhook posts the alert as JSON to URL
+type Webhook struct {
+	URL string
+}
+
+func (w *Webhook) Name() string { return "webhook" }
+
+func (w *Webhook) Notify(ctx context.Context, a Alert) error {
+	return postJSON(ctx, w.URL, a)
+}
+
+// Slack posts the alert to a Slack incoming webhook, with the fields as an
+// attachment colored by severity
+type Slack struct {
+	WebhookURL string
+	Channel    string
+	Username   string
+}
+
+func (s *Slack) Name() string { return "slack" }
+
+func (s *Slack) Notify(ctx context.Context, a Alert) error {
+	type field struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+		Short bool   `json:"short"`
+	}
+	type attachment struct {
+		Color  string  `json:"color"`
+		Text   string  `json:"text,omitempty"`
+		Fields []field `json:"fields,omitempty"`
+		Footer string  `json:"footer,omitempty"`
+		Ts     int64   `json:"ts"`
+	}
+	att := attachment{Color: slackColor(a.Severity), Text: a.Message, Footer: a.Host + " · " + a.Source, Ts: a.Time.Unix()}
+	for _, k := range sortedFieldNames(a.Fields) {
+		v := a.Fields[k]
+		att.Fields = append(att.Fields, field{Title: k, Value: v, Short: len(v) <= 40})
+	}
+	payload := struct {
+		Text        string       `json:"text"`
+		Channel     string       `json:"channel,omitempty"`
+		Username    string       `json:"username,omitempty"`
+		Attachments []attachment `json:"attachments"`
+	}{
+		Text:        fmt.Sprintf("*[%s]* %s", strings.ToUpper(a.Severity), a.Subject),
+		Channel:     s.Channel,
+		Username:    s.Username,
+		Attachments: []attachment{att},
+	}
+	return postJSON(ctx, s.WebhookURL, payload)
+}
+
+func slackColor(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return "danger"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "good"
+}
+
+// Command runs Command through sh with the alert in SFDB_ALERT_* variables
+type Command struct {
+	Command string
+}
+
+func (c *Command) Name() string { return "command" }
+
+func (c *Command) Notify(ctx context.Context, a Alert) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.Command)
+	cmd.Env = append(os.Environ(),
+		"SFDB_ALERT_SOURCE="+a.Source,
+		"SFDB_ALERT_SEVERITY="+a.Severity,
+		"SFDB_ALERT_SUBJECT="+a.Subject,
+		"SFDB_ALERT_MESSAGE="+a.Message,
+		"SFDB_ALERT_HOST="+a.Host,
+		"SFDB_ALERT_TIME="+a.Time.Format(time.RFC3339),
+	)
+	for _, k := range sortedFieldNames(a.Fields) {
+		cmd.Env = append(cmd.Env, "SFDB_ALERT_"+strings.ToUpper(k)+"="+a.Fields[k])
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notification command failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func postJSON(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid notification webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The URL may carry a token; report the host only
+		return fmt.Errorf("notification webhook %s failed: %w", req.URL.Host, errors.Unwrap(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook %s returned %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+// plainText renders the alert for destinations without structure, e.g. mail
+func plainText(a Alert) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", a.Message)
+	width := 0
+	for k := range a.Fields {
+		width = max(width, len(k))
+	}
+	for _, k := range sortedFieldNames(a.Fields) {
+		fmt.Fprintf(&b, "%-*s  %s\n", width, k, a.Fields[k])
+	}
+	fmt.Fprintf(&b, "\nSeverity: %s\nSource:   %s\nHost:     %s\nTime:     %s\n", a.Severity, a.Source, a.Host, a.Time.Format(time.RFC3339))
+	return b.String()
+}
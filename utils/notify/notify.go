@@ -0,0 +1,91 @@
+// Package notify delivers alerts raised by monitors, and the results of
+// operations (see WatchOperations), to the destinations in the notification
+// section of the config: the log always, plus any configured Notifier.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+)
+
+// Alert severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Alert is one notification
+type Alert struct {
+	Source   string            `json:"source"` // monitor raising the alert, e.g. "quota"
+	Severity string            `json:"severity"`
+	Subject  string            `json:"subject"`
+	Message  string            `json:"message"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Host     string            `json:"host"`
+	Time     time.Time         `json:"time"`
+}
+
+// Send logs the alert and delivers it to the configured notifiers. Every
+// destination is tried; the errors of failed deliveries are joined.
+func Send(a Alert) error {
+	lg, _ := logger.Get()
+	if a.Time.IsZero() {
+		a.Time = time.Now()
+	}
+	if a.Host == "" {
+		a.Host, _ = os.Hostname()
+	}
+
+	fields := []logger.Field{logger.String("source", a.Source), logger.String("subject", a.Subject)}
+	for _, k := range sortedFieldNames(a.Fields) {
+		fields = append(fields, logger.String(k, a.Fields[k]))
+	}
+	switch a.Severity {
+	case SeverityCritical:
+		lg.Error(a.Message, fields...)
+	case SeverityWarning:
+		lg.Warn(a.Message, fields...)
+	default:
+		lg.Info(a.Message, fields...)
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load notification config: %w", err)
+	}
+	timeout := 10 * time.Second
+	if cfg.Notify.Timeout != "" {
+		if d, err := time.ParseDuration(cfg.Notify.Timeout); err == nil && d > 0 {
+			timeout = d
+		}
+	}
+
+	var errs []error
+	for _, n := range Notifiers(cfg.Notify) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := n.Notify(ctx, a)
+		cancel()
+		if err != nil {
+			lg.Warn("Failed to deliver alert", logger.String("notifier", n.Name()), logger.String("subject", a.Subject), logger.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sortedFieldNames(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
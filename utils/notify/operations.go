@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/progress"
+)
+
+// Values of model.OperationsNotifyConfig.On
+const (
+	OnNever   = "never"
+	OnFailure = "failure"
+	OnAlways  = "always"
+)
+
+// SourceOperation is the Alert.Source of operation results
+const SourceOperation = "operation"
+
+// defaultOperations are notified when notification.operations.operations is empty
+var defaultOperations = []string{"backup", "restore", "clone", "migrate", "postgres-backup", "postgres-restore", "dr-restore"}
+
+// operationJob is what a watcher knows about a running job
+type operationJob struct {
+	bytes    int64
+	size     string
+	details  map[string]string
+	warnings []string
+}
+
+// watcher turns the finished events of jobs into alerts
+type watcher struct {
+	on         string
+	operations []string
+
+	mu      sync.Mutex
+	jobs    map[string]*operationJob
+	pending sync.WaitGroup
+}
+
+// WatchOperations notifies the result of every job (see package jobstatus)
+// of the operations selected in notification.operations, with its duration,
+// size, details and error. Alerts are delivered in the background; the
+// returned function stops watching and waits for deliveries in flight.
+func WatchOperations() func() {
+	lg, _ := logger.Get()
+	cfg, err := config.Get()
+	if err != nil {
+		return func() {}
+	}
+	w := &watcher{on: strings.ToLower(cfg.Notify.Operations.On), operations: cfg.Notify.Operations.Operations, jobs: make(map[string]*operationJob)}
+	switch w.on {
+	case "", OnNever:
+		return func() {}
+	case OnFailure, OnAlways:
+	default:
+		lg.Warn("Operation notifications disabled: notification.operations.on must be never, failure or always", logger.String("on", cfg.Notify.Operations.On))
+		return func() {}
+	}
+	if len(Notifiers(cfg.Notify)) == 0 {
+		lg.Debug("Operation notifications enabled without a notifier; results are only logged")
+	}
+	if len(w.operations) == 0 {
+		w.operations = defaultOperations
+	}
+	cancel := progress.Subscribe(w.handle)
+	return func() {
+		cancel()
+		w.pending.Wait()
+	}
+}
+
+func (w *watcher) handle(e progress.Event) {
+	if !w.selected(e.Operation) {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	job := w.jobs[e.Job]
+	if job == nil {
+		job = &operationJob{details: make(map[string]string)}
+		w.jobs[e.Job] = job
+	}
+	switch e.Kind {
+	case progress.KindBytes, progress.KindStepFinished:
+		job.bytes = max(job.bytes, e.BytesDone)
+	case progress.KindDetail:
+		if e.Label == "Size" {
+			job.size = e.Message
+		} else {
+			job.details[fieldName(e.Label)] = e.Message
+		}
+	case progress.KindWarning:
+		job.warnings = append(job.warnings, e.Message)
+	case progress.KindFinished:
+		delete(w.jobs, e.Job)
+		if e.Error == "" && w.on == OnFailure {
+			return
+		}
+		a := operationAlert(e, job)
+		w.pending.Add(1)
+		go func() {
+			defer w.pending.Done()
+			Send(a)
+		}()
+	}
+}
+
+// selected reports whether operation is one whose results are notified
+func (w *watcher) selected(operation string) bool {
+	for _, op := range w.operations {
+		op = strings.ToLower(strings.TrimSpace(op))
+		if op == "*" || operation == op || strings.HasPrefix(operation, op+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// operationAlert describes the finished job of e
+func operationAlert(e progress.Event, job *operationJob) Alert {
+	duration := time.Duration(e.Duration * float64(time.Second)).Round(time.Second)
+	subject := e.Operation
+	if e.Target != "" {
+		subject += " " + e.Target
+	}
+
+	a := Alert{
+		Source:   SourceOperation,
+		Severity: SeverityInfo,
+		Fields:   job.details,
+		Time:     e.Time,
+	}
+	a.Fields["operation"] = e.Operation
+	a.Fields["target"] = e.Target
+	a.Fields["job"] = e.Job
+	a.Fields["duration"] = common.HumanizeDuration(duration)
+	switch {
+	case job.size != "":
+		a.Fields["size"] = job.size
+	case job.bytes > 0:
+		a.Fields["size"] = common.FormatSize(job.bytes)
+	}
+	if len(job.warnings) > 0 {
+		a.Fields["warnings"] = strings.Join(job.warnings, "; ")
+	}
+
+	if e.Error != "" {
+		a.Severity = SeverityCritical
+		a.Fields["status"] = "failed"
+		a.Fields["error"] = e.Error
+		a.Subject = subject + " failed"
+		a.Message = fmt.Sprintf("%s failed after %s: %s", subject, a.Fields["duration"], e.Error)
+		return a
+	}
+	if len(job.warnings) > 0 {
+		a.Severity = SeverityWarning
+	}
+	a.Fields["status"] = "succeeded"
+	a.Subject = subject + " succeeded"
+	a.Message = fmt.Sprintf("%s completed in %s", subject, a.Fields["duration"])
+	if size := a.Fields["size"]; size != "" {
+		a.Message += ", " + size
+	}
+	return a
+}
+
+// fieldName turns a detail label such as "Output file" into output_file
+func fieldName(label string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '_'
+	}, label), "_")
+}
@@ -0,0 +1,113 @@
+// Package output selects how commands present their results. The default is
+// the human terminal output. With --output json stdout carries only JSON, one
+// object per line: the progress events of the run (see package progress)
+// followed by a single "result" record with the outcome of the command and
+// the data it set with SetResult. Everything written for humans, including
+// the log console and the output of child processes, goes to stderr instead.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Formats accepted by Configure
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// KindResult is the kind of the final record, next to the event kinds of
+// package progress
+const KindResult = "result"
+
+// Record is the last line written in JSON mode
+type Record struct {
+	Kind     string `json:"kind"`
+	Command  string `json:"command"`
+	Success  bool   `json:"success"`
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+	Warnings any    `json:"warnings,omitempty"`
+	Data     any    `json:"data,omitempty"`
+}
+
+var (
+	mu     sync.Mutex
+	format           = FormatText
+	stdout io.Writer = os.Stdout
+	result any
+)
+
+// Configure selects the output format. For json the real stdout is kept for
+// the JSON records and os.Stdout is pointed at stderr, so every later write
+// meant for the terminal stays out of the JSON stream.
+func Configure(f string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	switch f {
+	case "", FormatText:
+		return nil
+	case FormatJSON:
+		if format == FormatJSON {
+			return nil
+		}
+		format = FormatJSON
+		stdout = os.Stdout
+		os.Stdout = os.Stderr
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q: expected %s or %s", f, FormatText, FormatJSON)
+}
+
+// JSON reports whether commands run in JSON mode
+func JSON() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return format == FormatJSON
+}
+
+// Stdout is where JSON records go: the real standard output
+func Stdout() io.Writer {
+	mu.Lock()
+	defer mu.Unlock()
+	return stdout
+}
+
+// SetResult sets the data of the result record, e.g. a backup result or a
+// health report. It is ignored in text mode, where commands print their own
+// results.
+func SetResult(v any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if format == FormatJSON {
+		result = v
+	}
+}
+
+// Finish writes the result record of command in JSON mode. warnings are the
+// distinct warnings of the run.
+func Finish(command string, err error, exitCode int, warnings any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if format != FormatJSON {
+		return
+	}
+	record := Record{
+		Kind:     KindResult,
+		Command:  command,
+		Success:  err == nil,
+		ExitCode: exitCode,
+		Warnings: warnings,
+		Data:     result,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if encodeErr := json.NewEncoder(stdout).Encode(record); encodeErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to write JSON result: %v\n", encodeErr)
+	}
+}
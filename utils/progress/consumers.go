@@ -0,0 +1,36 @@
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/terminal"
+)
+
+// Terminal is the default CLI consumer. Commands print their own results, so
+// it only shows warnings; steps go to the debug log.
+func Terminal(e Event) {
+	lg, _ := logger.Get()
+	switch e.Kind {
+	case KindWarning:
+		terminal.PrintWarning(e.Message)
+	case KindStepStarted:
+		lg.Debug("Job step started", logger.String("operation", e.Operation), logger.String("step", e.Step))
+	case KindStepFinished:
+		lg.Debug("Job step finished", logger.String("operation", e.Operation), logger.String("step", e.Step), logger.Float64("seconds", e.Duration))
+	}
+}
+
+// JSONLines returns a consumer writing every event to w as one JSON object
+// per line
+func JSONLines(w io.Writer) Handler {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		enc.Encode(e)
+	}
+}
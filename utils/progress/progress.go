@@ -0,0 +1,99 @@
+// Package progress carries progress events from the core engines to whoever
+// presents them: the terminal, JSON lines for other programs, an API server or
+// a dashboard. Engines report through a jobstatus.Tracker, which emits here;
+// consumers subscribe with a callback or a channel.
+package progress
+
+import (
+	"sync"
+	"time"
+)
+
+// Event kinds
+const (
+	KindStarted      = "started"
+	KindStepStarted  = "step_started"
+	KindStepFinished = "step_finished"
+	KindBytes        = "bytes"
+	KindWarning      = "warning"
+	KindDetail       = "detail" // a labelled fact of the result, e.g. output size
+	KindFinished     = "finished"
+)
+
+// Event is one progress notification of a job
+type Event struct {
+	Time       time.Time `json:"time"`
+	Job        string    `json:"job"`
+	Operation  string    `json:"operation"`
+	Target     string    `json:"target,omitempty"`
+	Kind       string    `json:"kind"`
+	Step       string    `json:"step,omitempty"`
+	BytesDone  int64     `json:"bytes_done,omitempty"`
+	BytesTotal int64     `json:"bytes_total,omitempty"`
+	Duration   float64   `json:"duration_seconds,omitempty"` // step_finished and finished
+	Label      string    `json:"label,omitempty"`            // detail label
+	Message    string    `json:"message,omitempty"`          // warning text or detail value
+	Error      string    `json:"error,omitempty"`            // finished with an error
+}
+
+// Handler receives events. It is called synchronously on the engine's
+// goroutine and must not block; use Channel to consume asynchronously.
+type Handler func(Event)
+
+var (
+	mu       sync.RWMutex
+	handlers = map[int]Handler{}
+	nextID   int
+)
+
+// Subscribe registers h for all events and returns a function removing it
+func Subscribe(h Handler) (cancel func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	id := nextID
+	nextID++
+	handlers[id] = h
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(handlers, id)
+	}
+}
+
+// Channel subscribes a buffered channel. Events are dropped, never waited
+// for, when the channel is full. cancel unsubscribes and closes the channel.
+func Channel(buffer int) (events <-chan Event, cancel func()) {
+	ch := make(chan Event, buffer)
+	var once sync.Once
+	unsubscribe := Subscribe(func(e Event) {
+		select {
+		case ch <- e:
+		default:
+		}
+	})
+	return ch, func() {
+		once.Do(func() {
+			unsubscribe()
+			close(ch)
+		})
+	}
+}
+
+// Active reports whether anyone is subscribed
+func Active() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(handlers) > 0
+}
+
+// Emit delivers e to all subscribers, stamping the time when unset
+func Emit(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, h := range handlers {
+		h(e)
+	}
+}
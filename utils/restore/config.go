@@ -62,8 +62,23 @@ func ResolveRestoreConfig(cmd *cobra.Command) (*RestoreConfig, error) {
 		restoreConfig.DBName = dbName
 	}
 
+	// Preset the flags of --profile before they are read
+	if restoreConfig.GlobalVars, err = applyRestoreProfile(cmd); err != nil {
+		return nil, err
+	}
+
 	// Resolve other restore options
 	restoreConfig.VerifyChecksum = common.GetBoolFlagOrEnv(cmd, "verify-checksum", "VERIFY_CHECKSUM", false)
+	restoreConfig.RewriteFrom = common.GetStringFlagOrEnv(cmd, "rewrite-from", "RESTORE_REWRITE_FROM", "")
+	restoreConfig.RewriteReferences = common.GetBoolFlagOrEnv(cmd, "rewrite-references", "RESTORE_REWRITE_REFERENCES", false) ||
+		restoreConfig.RewriteFrom != ""
+	// Semicolon-separated in the environment, as values such as sql_mode contain commas
+	restoreConfig.SessionVars = common.GetStringArrayFlagOrEnv(cmd, "set", "RESTORE_SESSION_VARS")
+	restoreConfig.ExtraArgs = common.GetStringArrayFlagOrEnv(cmd, "restore-arg", "RESTORE_MYSQL_ARGS")
+	restoreConfig.EncryptionKeyFile = common.GetStringFlagOrEnv(cmd, "encryption-key-file", "SFDB_ENCRYPTION_KEY_FILE", "")
+	if err := common.ValidateRestoreArgs(restoreConfig.ExtraArgs); err != nil {
+		return nil, common.WithExitCode(err, common.ExitUsage)
+	}
 
 	return restoreConfig, nil
 }
@@ -122,7 +137,7 @@ func AddCommonRestoreFlags(cmd *cobra.Command) {
 	cmd.Flags().String("target_host", "", "target database host")
 	cmd.Flags().Int("target_port", 0, "target database port")
 	cmd.Flags().String("target_user", "", "target database user")
-	cmd.Flags().String("target_password", "", "target database password")
+	cmd.Flags().String("target_password", "", "target database password, or a secret reference (vault://, awssm://, envfile://)")
 
 	// Database creation options
 	cmd.Flags().Bool("create-new-db", false, "create new database instead of selecting existing one")
@@ -130,7 +145,18 @@ func AddCommonRestoreFlags(cmd *cobra.Command) {
 
 	// Restore options
 	cmd.Flags().String("file", "", "backup file to restore")
+	cmd.Flags().String("encryption-key-file", "", "key file an encrypted backup was written with (default: prompt for the password)")
 	cmd.Flags().Bool("verify-checksum", false, "verify checksum after restore")
+	cmd.Flags().Bool("rewrite-references", false, "rewrite source_db.object references in views/routines/triggers to the target database name")
+	cmd.Flags().String("rewrite-from", "", "source database name used in the dump (default: from backup metadata; implies --rewrite-references)")
+	cmd.Flags().StringArray("set", nil, "session setting for the restore connection, repeatable (e.g. --set sql_mode=NO_AUTO_VALUE_ON_ZERO --set max_allowed_packet=1G)")
+	cmd.Flags().String("profile", "", "preset for checksum verification and durability on the target: fast, balanced or safe (fast and balanced relax innodb_flush_log_at_trx_commit until the restore ends)")
+	cmd.Flags().StringArray("restore-arg", nil, "extra mysql client option from the allowlist, e.g. --restore-arg=--binary-mode (repeatable)")
+
+	// Replica impact options
+	cmd.Flags().String("replica-impact-threshold", defaultReplicaImpactThreshold, "warn when the restore is expected to write more binary log than this (0 disables)")
+	cmd.Flags().Bool("skip-binlog", false, "restore with sql_log_bin=0: replicas do not receive the restored data")
+	cmd.Flags().Bool("ack-replica-divergence", false, "acknowledge --skip-binlog without the interactive confirmation")
 }
 
 // AddCommonRestoreUserFlags adds common restore user grants flags to the given command
@@ -142,7 +168,7 @@ func AddCommonRestoreUserFlags(cmd *cobra.Command) {
 	cmd.Flags().String("target_host", "", "target database host")
 	cmd.Flags().Int("target_port", 0, "target database port")
 	cmd.Flags().String("target_user", "", "target database user")
-	cmd.Flags().String("target_password", "", "target database password")
+	cmd.Flags().String("target_password", "", "target database password, or a secret reference (vault://, awssm://, envfile://)")
 
 	// Restore options
 	cmd.Flags().String("file", "", "grants backup file to restore")
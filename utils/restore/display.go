@@ -1,11 +1,11 @@
 package restore_utils
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"os"
 	"sfDBTools/internal/logger"
-	"sfDBTools/utils/common/format"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/i18n"
 	"sfDBTools/utils/terminal"
 	"strings"
 	"time"
@@ -20,6 +20,26 @@ func DisplayRestoreParameters(options RestoreOptions) {
 	fmt.Printf("Target User:      %s\n", options.User)
 	fmt.Printf("Backup File:      %s\n", options.File)
 	fmt.Printf("Verify Checksum:  %t\n", options.VerifyChecksum)
+	if options.RewriteReferences {
+		from := options.RewriteFrom
+		if from == "" {
+			from = "(from backup metadata)"
+		}
+		fmt.Printf("Rewrite Refs:     %s -> %s\n", from, options.DBName)
+	}
+	for _, v := range options.SessionVars {
+		fmt.Printf("Session Setting:  %s\n", v)
+	}
+	for _, v := range options.GlobalVars {
+		fmt.Printf("Global Setting:   %s (reverted after restore)\n", v)
+	}
+	if len(options.ExtraArgs) > 0 {
+		fmt.Printf("Client Options:   %s\n", strings.Join(options.ExtraArgs, " "))
+	}
+	if !options.Until.IsZero() {
+		fmt.Printf("Recover Until:    %s\n", options.Until.Format("2006-01-02 15:04:05"))
+		fmt.Printf("Binlog Source:    %s:%d\n", options.BinlogSource.Host, options.BinlogSource.Port)
+	}
 	terminal.PrintSeparator()
 }
 
@@ -34,7 +54,7 @@ func DisplayRestoreResults(success bool, duration time.Duration, title string) {
 	} else {
 		fmt.Println("❌ Restore failed!")
 	}
-	fmt.Printf("Duration: %s\n", format.FormatDuration(duration, "shorts"))
+	fmt.Printf("Duration: %s\n", common.HumanizeDuration(duration))
 	fmt.Printf("=== End %s ===\n", title)
 }
 
@@ -55,7 +75,7 @@ func DisplayRestoreSummary(options RestoreOptions, duration time.Duration, lg *l
 	fmt.Printf("Host:             %s:%d\n", options.Host, options.Port)
 	fmt.Printf("User:             %s\n", options.User)
 	fmt.Printf("Source File:      %s\n", options.File)
-	fmt.Printf("Duration:         %s\n", format.FormatDuration(duration, "shorts"))
+	fmt.Printf("Duration:         %s\n", common.HumanizeDuration(duration))
 	fmt.Printf("Verify Checksum:  %t\n", options.VerifyChecksum)
 	fmt.Println("=======================")
 
@@ -83,30 +103,11 @@ func LogRestoreCompletion(options RestoreOptions, duration time.Duration, lg *lo
 
 // PromptRestoreConfirmation prompts user for confirmation before performing restore
 func PromptRestoreConfirmation(options RestoreOptions) error {
-	reader := bufio.NewReader(os.Stdin)
-	if options.DBName == "" {
-		options.DBName = "All Database"
+	if !terminal.ConfirmPlan(BuildRestorePlan(options), i18n.T("restore.confirm")) {
+		return errors.New(i18n.T("restore.cancelled"))
 	}
-	terminal.PrintSubHeader("RESTORE CONFIRMATION")
-	fmt.Printf("You are about to restore backup to:\n")
-	fmt.Printf("  Database: %s\n", options.DBName)
-	fmt.Printf("  Host:     %s:%d\n", options.Host, options.Port)
-	fmt.Printf("  User:     %s\n", options.User)
-	fmt.Printf("  File:     %s\n", options.File)
-	fmt.Println("\n🚨 WARNING: This will overwrite existing data in the target database!")
 
-	fmt.Print("\nDo you want to continue with the restore? [y/N]: ")
-	confirmInput, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read confirmation: %w", err)
-	}
-
-	confirm := strings.ToLower(strings.TrimSpace(confirmInput))
-	if confirm != "y" && confirm != "yes" {
-		return fmt.Errorf("restore operation cancelled by user")
-	}
-
-	terminal.PrintSubHeader("Proceeding with restore...")
+	terminal.PrintSubHeader(i18n.T("restore.proceeding"))
 	return nil
 }
 
@@ -122,8 +123,6 @@ func DisplayRestoreUserParameters(options RestoreUserOptions) {
 
 // PromptRestoreUserConfirmation prompts user for confirmation before performing user grants restore
 func PromptRestoreUserConfirmation(options RestoreUserOptions) error {
-	reader := bufio.NewReader(os.Stdin)
-
 	fmt.Println("\n⚠️  USER GRANTS RESTORE CONFIRMATION")
 	fmt.Println("====================================")
 	fmt.Printf("You are about to restore user grants to:\n")
@@ -133,14 +132,8 @@ func PromptRestoreUserConfirmation(options RestoreUserOptions) error {
 	fmt.Println("\n🚨 WARNING: This will execute GRANT statements on the target database server!")
 	fmt.Println("🚨 WARNING: This may create new users or modify existing user privileges!")
 
-	fmt.Print("\nDo you want to continue with the user grants restore? [y/N]: ")
-	confirmInput, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read confirmation: %w", err)
-	}
-
-	confirm := strings.ToLower(strings.TrimSpace(confirmInput))
-	if confirm != "y" && confirm != "yes" {
+	fmt.Println()
+	if !terminal.Confirm("Do you want to continue with the user grants restore?", false) {
 		return fmt.Errorf("user grants restore operation cancelled by user")
 	}
 
@@ -1,12 +1,11 @@
 package restore_utils
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sfDBTools/utils/common"
 	"sfDBTools/utils/terminal"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -199,38 +198,18 @@ func SelectBackupFileInteractive(baseDir string) (string, error) {
 		}
 	}
 
-	// Display available files
-	terminal.PrintSubHeader("Available Backup Files")
+	// Let user choose, by number or by searching the file or database name
+	options := make([]string, len(allFiles))
 	for i, file := range allFiles {
-		relPath, err := filepath.Rel(".", file.Path)
-		if err != nil {
-			relPath = file.Path // fallback to absolute path if relative fails
-		}
-		sizeStr := formatFileSize(file.Size)
-		timeStr := file.ModTime.Format("2006-01-02 15:04")
-
-		fmt.Printf("   %d. %s\n", i+1, file.Name)
-		fmt.Printf("      Path: %s\n", relPath)
-		fmt.Printf("      Database: %s | Size: %s | Date: %s\n",
-			file.DatabaseName, sizeStr, timeStr)
-		fmt.Println()
+		options[i] = fmt.Sprintf("%s  (%s, %s, %s, in %s)", file.Name,
+			file.DatabaseName, common.HumanizeSize(file.Size), common.HumanizeTime(file.ModTime), relativeDir(file.Path))
 	}
-
-	// Let user choose
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\nSelect backup file (1-%d): ", len(allFiles))
-	choice, err := reader.ReadString('\n')
+	index, err := terminal.Select("Available Backup Files", options, -1)
 	if err != nil {
 		return "", fmt.Errorf("failed to read selection: %w", err)
 	}
 
-	choice = strings.TrimSpace(choice)
-	index, err := strconv.Atoi(choice)
-	if err != nil || index < 1 || index > len(allFiles) {
-		return "", fmt.Errorf("invalid selection: %s", choice)
-	}
-
-	return allFiles[index-1].Path, nil
+	return allFiles[index].Path, nil
 }
 
 // SelectGrantsFileInteractive shows available grants files and lets user choose one
@@ -252,39 +231,27 @@ func SelectGrantsFileInteractive(baseDir string) (string, error) {
 
 	fmt.Printf("Found %d grants backup files\n", len(allFiles))
 
-	// Display available files
-	fmt.Println("📁 Available Grants Backup Files:")
-	fmt.Println("==================================")
+	// Let user choose
+	options := make([]string, len(allFiles))
 	for i, file := range allFiles {
-		relPath, err := filepath.Rel(".", file.Path)
-		if err != nil {
-			relPath = file.Path // fallback to absolute path if relative fails
-		}
-		sizeStr := formatFileSize(file.Size)
-		timeStr := file.ModTime.Format("2006-01-02 15:04")
-
-		fmt.Printf("   %d. %s\n", i+1, file.Name)
-		fmt.Printf("      Path: %s\n", relPath)
-		fmt.Printf("      Type: %s | Size: %s | Date: %s\n",
-			file.DatabaseName, sizeStr, timeStr)
-		fmt.Println()
+		options[i] = fmt.Sprintf("%s  (%s, %s, %s, in %s)", file.Name,
+			file.DatabaseName, common.HumanizeSize(file.Size), common.HumanizeTime(file.ModTime), relativeDir(file.Path))
 	}
-
-	// Let user choose
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\nSelect grants backup file (1-%d): ", len(allFiles))
-	choice, err := reader.ReadString('\n')
+	index, err := terminal.Select("📁 Available Grants Backup Files", options, -1)
 	if err != nil {
 		return "", fmt.Errorf("failed to read selection: %w", err)
 	}
 
-	choice = strings.TrimSpace(choice)
-	index, err := strconv.Atoi(choice)
-	if err != nil || index < 1 || index > len(allFiles) {
-		return "", fmt.Errorf("invalid selection: %s", choice)
-	}
+	return allFiles[index].Path, nil
+}
 
-	return allFiles[index-1].Path, nil
+// relativeDir returns the directory of path relative to the working directory when possible
+func relativeDir(path string) string {
+	relPath, err := filepath.Rel(".", path)
+	if err != nil {
+		relPath = path // fallback to absolute path if relative fails
+	}
+	return filepath.Dir(relPath)
 }
 
 // FindGrantsFiles finds all grants backup files in the specified directory and subdirectories
@@ -370,20 +337,6 @@ func extractGrantsTypeFromFilename(filename string) string {
 	return "User Grants"
 }
 
-// formatFileSize formats file size in human readable format
-func formatFileSize(size int64) string {
-	const unit = 1024
-	if size < unit {
-		return fmt.Sprintf("%d B", size)
-	}
-	div, exp := int64(unit), 0
-	for n := size / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
-}
-
 // ValidateBackupFile checks if the backup file exists and is readable
 func ValidateBackupFile(filePath string) error {
 	if filePath == "" {
@@ -0,0 +1,66 @@
+package restore_utils
+
+import (
+	"fmt"
+	"time"
+
+	"sfDBTools/internal/core/mariadb/changes"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/secrets"
+
+	"github.com/spf13/cobra"
+)
+
+// AddPointInTimeFlags adds the point-in-time recovery flags to the given command
+func AddPointInTimeFlags(cmd *cobra.Command) {
+	cmd.Flags().String("until", "", "recover to this time by replaying binary logs after the backup (\"YYYY-MM-DD HH:MM:SS\", local time; events at or after it are not applied)")
+	cmd.Flags().String("binlog-start", "", "binlog position to start replaying from as file:position (default: from the backup manifest)")
+	cmd.Flags().String("binlog-host", "", "server whose binary logs are replayed (default: the restore target)")
+	cmd.Flags().Int("binlog-port", 0, "port of the binlog server (default: the restore target port)")
+	cmd.Flags().String("binlog-user", "", "user reading the binary logs, needs REPLICATION SLAVE (default: the restore target user)")
+	cmd.Flags().String("binlog-password", "", "password of the binlog user, or a secret reference (vault://, awssm://, envfile://)")
+}
+
+// ResolvePointInTime reads --until and the binlog source into options. The
+// binlog source defaults to the restore target, the usual case of recovering
+// a database on the server where it was dropped.
+func ResolvePointInTime(cmd *cobra.Command, options *RestoreOptions) error {
+	raw := common.GetStringFlagOrEnv(cmd, "until", "RESTORE_UNTIL", "")
+	start := common.GetStringFlagOrEnv(cmd, "binlog-start", "RESTORE_BINLOG_START", "")
+	if raw == "" {
+		if start != "" {
+			return common.WithExitCode(fmt.Errorf("--binlog-start requires --until"), common.ExitUsage)
+		}
+		return nil
+	}
+	until, err := changes.ParseTime(raw)
+	if err != nil {
+		return common.WithExitCode(fmt.Errorf("--until: %w", err), common.ExitUsage)
+	}
+	if until.After(time.Now()) {
+		return common.WithExitCode(fmt.Errorf("--until %s is in the future", until.Format("2006-01-02 15:04:05")), common.ExitUsage)
+	}
+	if options.DBName == "" {
+		return common.WithExitCode(fmt.Errorf("--until needs a target database"), common.ExitUsage)
+	}
+
+	options.Until = until
+	options.BinlogStart = start
+	source := database.Config{
+		Host: common.GetStringFlagOrEnv(cmd, "binlog-host", "RESTORE_BINLOG_HOST", options.Host),
+		Port: common.GetIntFlagOrEnv(cmd, "binlog-port", "RESTORE_BINLOG_PORT", options.Port),
+		User: common.GetStringFlagOrEnv(cmd, "binlog-user", "RESTORE_BINLOG_USER", options.User),
+	}
+	// The target password is only reused for the target's own account
+	password := ""
+	if source.Host == options.Host && source.User == options.User {
+		password = options.Password
+	}
+	source.Password = common.GetStringFlagOrEnv(cmd, "binlog-password", "RESTORE_BINLOG_PASSWORD", password)
+	if source.User, source.Password, err = secrets.ResolveCredentials(source.User, source.Password); err != nil {
+		return fmt.Errorf("binlog source: %w", err)
+	}
+	options.BinlogSource = source
+	return nil
+}
@@ -0,0 +1,92 @@
+package restore_utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/compression"
+	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/terminal"
+)
+
+const (
+	// restoreThroughput is a conservative rate at which mysql replays a dump
+	restoreThroughput = 15 << 20 // bytes per second
+	// compressedExpansion approximates how much a compressed dump grows when decompressed
+	compressedExpansion = 5
+)
+
+// BuildRestorePlan describes a restore for the confirmation summary. An empty
+// DBName means every database contained in the backup is restored.
+func BuildRestorePlan(options RestoreOptions) terminal.OperationPlan {
+	plan := terminal.OperationPlan{
+		Operation: "Database Restore",
+		Source:    options.File,
+		Target:    fmt.Sprintf("%s:%d (user: %s)", options.Host, options.Port, options.User),
+		Actions:   []string{"Replay backup file with mysql --force"},
+	}
+
+	if fi, err := os.Stat(options.File); err == nil {
+		plan.Source = fmt.Sprintf("%s (%s)", options.File, common.FormatSize(fi.Size()))
+		plan.EstimatedDuration = time.Duration(estimatedDumpSize(options.File)/restoreThroughput) * time.Second
+	}
+
+	if options.VerifyChecksum {
+		plan.Actions = append([]string{"Verify backup checksum"}, plan.Actions...)
+	}
+	if options.RewriteReferences {
+		plan.Actions = append(plan.Actions, "Rewrite schema-qualified references to "+options.DBName)
+	}
+	for _, v := range options.SessionVars {
+		plan.Actions = append(plan.Actions, "Session setting "+v)
+	}
+	for _, v := range options.GlobalVars {
+		plan.Actions = append(plan.Actions, "Global setting "+v+" until the restore ends")
+	}
+	if len(options.ExtraArgs) > 0 {
+		plan.Actions = append(plan.Actions, "mysql client options "+strings.Join(options.ExtraArgs, " "))
+	}
+
+	if !options.Until.IsZero() {
+		plan.Actions = append(plan.Actions, fmt.Sprintf("Replay binary logs of %s:%d until %s",
+			options.BinlogSource.Host, options.BinlogSource.Port, options.Until.Format("2006-01-02 15:04:05")))
+	}
+
+	if options.DBName == "" {
+		plan.Destructive = []string{
+			"Drop and recreate every database contained in the backup",
+			"Overwrite users and grants included in the backup",
+		}
+		plan.Backup = terminal.BackupSafety{Detail: "existing databases on the target are not backed up first"}
+		return plan
+	}
+
+	plan.Databases = []string{options.DBName}
+	plan.Destructive = []string{fmt.Sprintf("Drop and recreate tables in %s that exist in the backup", options.DBName)}
+	if last, ok := info.LastBackupTimes()[options.DBName]; ok {
+		plan.Backup = terminal.BackupSafety{
+			Protected: true,
+			Detail:    fmt.Sprintf("last backup of %s taken %s ago", options.DBName, info.FormatAge(time.Since(last))),
+		}
+	} else {
+		plan.Backup = terminal.BackupSafety{Detail: fmt.Sprintf("no backup of %s found in the backup directory", options.DBName)}
+	}
+	return plan
+}
+
+// estimatedDumpSize approximates the SQL a backup file replays, correcting
+// compressed files for their typical ratio; zero when the file is unreadable
+func estimatedDumpSize(file string) int64 {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return 0
+	}
+	size := fi.Size()
+	if compression.DetectCompressionTypeFromFile(file) != compression.CompressionNone {
+		size *= compressedExpansion
+	}
+	return size
+}
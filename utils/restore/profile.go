@@ -0,0 +1,75 @@
+package restore_utils
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+
+	"github.com/spf13/cobra"
+)
+
+// restoreProfile bundles checksum verification with durability settings
+// relaxed on the target while the restore runs. The global settings are
+// reverted when the restore ends or is interrupted.
+type restoreProfile struct {
+	verifyChecksum string
+	globals        []string
+}
+
+var restoreProfiles = map[string]restoreProfile{
+	// fast skips fsync at commit and binlog sync; a crash of the target
+	// during the restore may lose the last second of restored rows
+	"fast": {
+		verifyChecksum: "false",
+		globals:        []string{"innodb_flush_log_at_trx_commit=2", "sync_binlog=0"},
+	},
+	"balanced": {
+		verifyChecksum: "true",
+		globals:        []string{"innodb_flush_log_at_trx_commit=2"},
+	},
+	"safe": {
+		verifyChecksum: "true",
+	},
+}
+
+// RestoreProfileNames lists the accepted --profile values
+func RestoreProfileNames() []string {
+	names := make([]string, 0, len(restoreProfiles))
+	for name := range restoreProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyRestoreProfile presets --verify-checksum from the --profile preset
+// unless given explicitly, and returns the global settings of the profile
+func applyRestoreProfile(cmd *cobra.Command) ([]string, error) {
+	if cmd.Flags().Lookup("profile") == nil {
+		return nil, nil
+	}
+	name := strings.ToLower(common.GetStringFlagOrEnv(cmd, "profile", "RESTORE_PROFILE", ""))
+	if name == "" {
+		return nil, nil
+	}
+	profile, ok := restoreProfiles[name]
+	if !ok {
+		return nil, common.WithExitCode(fmt.Errorf("unknown restore profile %q (expected %s)", name, strings.Join(RestoreProfileNames(), ", ")), common.ExitUsage)
+	}
+
+	if f := cmd.Flags().Lookup("verify-checksum"); f != nil && !f.Changed && os.Getenv("VERIFY_CHECKSUM") == "" {
+		if err := cmd.Flags().Set("verify-checksum", profile.verifyChecksum); err != nil {
+			return nil, fmt.Errorf("failed to apply profile %s to --verify-checksum: %w", name, err)
+		}
+	}
+
+	lg, _ := logger.Get()
+	lg.Info("Applied restore profile",
+		logger.String("profile", name),
+		logger.String("globals", strings.Join(profile.globals, " ")))
+	return profile.globals, nil
+}
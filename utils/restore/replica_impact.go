@@ -0,0 +1,156 @@
+package restore_utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/database"
+	"sfDBTools/utils/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// replicaApplyThroughput is a conservative rate at which a replica's
+	// SQL thread applies a bulk load
+	replicaApplyThroughput = 10 << 20 // bytes per second
+	// rowBinlogOverhead approximates how much larger row events are than the
+	// INSERT statements they log
+	rowBinlogOverhead = 1.2
+	// defaultReplicaImpactThreshold is the binlog volume above which the
+	// operator is warned before restoring
+	defaultReplicaImpactThreshold = "1G"
+	// skipBinlogAcknowledgement must be typed to restore without binlog
+	skipBinlogAcknowledgement = "DIVERGE"
+)
+
+// ReplicaImpact estimates what a restore does to the replicas of its target
+type ReplicaImpact struct {
+	LogBin       bool
+	BinlogFormat string
+	Replicas     []string      // hosts connected with a binlog dump thread
+	BinlogBytes  int64         // binary log the restore is expected to write
+	ReplicaLag   time.Duration // time replicas need to apply it
+}
+
+// EstimateReplicaImpact reads the binary log settings and connected replicas
+// of the target and estimates the binlog volume and replica lag of restoring file
+func EstimateReplicaImpact(config database.Config, file string) (*ReplicaImpact, error) {
+	config.DBName = ""
+	db, err := database.GetWithoutDB(config)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	impact := &ReplicaImpact{}
+	var logBin int
+	if err := db.QueryRow("SELECT @@GLOBAL.log_bin, @@GLOBAL.binlog_format").Scan(&logBin, &impact.BinlogFormat); err != nil {
+		return nil, fmt.Errorf("failed to read binary log settings: %w", err)
+	}
+	impact.LogBin = logBin == 1
+	if !impact.LogBin {
+		return impact, nil
+	}
+
+	// Needs PROCESS to see the dump threads of other accounts; without it no
+	// replica is listed and the estimate still applies to the binlog itself
+	rows, err := db.Query("SELECT HOST FROM information_schema.PROCESSLIST WHERE COMMAND LIKE 'Binlog Dump%'")
+	if err == nil {
+		for rows.Next() {
+			var host string
+			if rows.Scan(&host) == nil {
+				impact.Replicas = append(impact.Replicas, host)
+			}
+		}
+		rows.Close()
+	}
+
+	impact.BinlogBytes = estimatedDumpSize(file)
+	if strings.EqualFold(impact.BinlogFormat, "ROW") {
+		impact.BinlogBytes = int64(float64(impact.BinlogBytes) * rowBinlogOverhead)
+	}
+	impact.ReplicaLag = time.Duration(impact.BinlogBytes/replicaApplyThroughput) * time.Second
+	return impact, nil
+}
+
+// CheckReplicaImpact warns before a restore that would write a large amount of
+// binary log on a server with replicas, and offers to restore with
+// sql_log_bin=0 instead. Skipping the binlog (--skip-binlog or the prompt) must
+// be acknowledged, interactively or with --ack-replica-divergence, because the
+// replicas then never receive the restored data. The setting is added to
+// options.SessionVars.
+func CheckReplicaImpact(cmd *cobra.Command, options *RestoreOptions) error {
+	lg, _ := logger.Get()
+	skipBinlog := common.GetBoolFlagOrEnv(cmd, "skip-binlog", "RESTORE_SKIP_BINLOG", false)
+	acknowledged := common.GetBoolFlagOrEnv(cmd, "ack-replica-divergence", "RESTORE_ACK_REPLICA_DIVERGENCE", false)
+	threshold := common.ParseSize(common.GetStringFlagOrEnv(cmd, "replica-impact-threshold", "RESTORE_REPLICA_IMPACT_THRESHOLD", defaultReplicaImpactThreshold))
+	for _, v := range options.SessionVars {
+		if name, _, _ := strings.Cut(v, "="); strings.EqualFold(strings.TrimSpace(name), "sql_log_bin") {
+			return nil // the operator chose the binlog behaviour with --set
+		}
+	}
+
+	impact, err := EstimateReplicaImpact(database.Config{Host: options.Host, Port: options.Port, User: options.User, Password: options.Password}, options.File)
+	if err != nil {
+		lg.Warn("Could not estimate the replica impact of the restore", logger.Error(err))
+		if skipBinlog {
+			return skipBinlogForRestore(options, acknowledged)
+		}
+		return nil
+	}
+	if !impact.LogBin {
+		return nil
+	}
+
+	significant := threshold > 0 && impact.BinlogBytes >= threshold
+	if significant {
+		displayReplicaImpact(impact)
+	}
+	if skipBinlog {
+		return skipBinlogForRestore(options, acknowledged)
+	}
+	if significant && terminal.AskYesNo("Restore without writing the binary log (sql_log_bin=0) instead?", false) {
+		return skipBinlogForRestore(options, acknowledged)
+	}
+	return nil
+}
+
+func displayReplicaImpact(impact *ReplicaImpact) {
+	replicas := "none connected (or not visible without the PROCESS privilege)"
+	if len(impact.Replicas) > 0 {
+		replicas = fmt.Sprintf("%d: %s", len(impact.Replicas), strings.Join(impact.Replicas, ", "))
+	}
+	terminal.PrintSubHeader("Replica Impact")
+	terminal.FormatTable([]string{"Item", "Estimate"}, [][]string{
+		{"Binary log", fmt.Sprintf("ON (%s)", impact.BinlogFormat)},
+		{"Binlog written by restore", "~" + common.FormatSize(impact.BinlogBytes)},
+		{"Replicas", replicas},
+		{"Replica apply time / lag", "up to ~" + common.HumanizeDuration(impact.ReplicaLag)},
+	})
+	terminal.PrintWarning("Every statement of the restore is written to the binary log and replayed on each replica; " +
+		"expect replication lag and binlog disk growth on this server and the replicas")
+}
+
+// skipBinlogForRestore adds sql_log_bin=0 to the restore session once the
+// consequences are acknowledged
+func skipBinlogForRestore(options *RestoreOptions, acknowledged bool) error {
+	lg, _ := logger.Get()
+	terminal.PrintWarning("With sql_log_bin=0 the restored data is NOT written to the binary log:")
+	fmt.Println("  - replicas never receive it and diverge from this server; restore them separately")
+	fmt.Println("  - point-in-time recovery from this server's binlogs does not cover the restore")
+	fmt.Println("  - later writes to the restored tables may break replication on the replicas")
+	if !acknowledged {
+		answer := terminal.AskString(fmt.Sprintf("Type %s to confirm", skipBinlogAcknowledgement), "")
+		if answer != skipBinlogAcknowledgement {
+			return common.WithExitCode(fmt.Errorf("restore without binary log not acknowledged; use --ack-replica-divergence for unattended runs"), common.ExitUsage)
+		}
+	}
+	options.SessionVars = append(options.SessionVars, "sql_log_bin=0")
+	lg.Warn("Restore is not written to the binary log; replicas will not receive it",
+		logger.String("host", fmt.Sprintf("%s:%d", options.Host, options.Port)))
+	return nil
+}
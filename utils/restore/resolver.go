@@ -1,17 +1,14 @@
 package restore_utils
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"path/filepath"
-	"strconv"
-	"strings"
 
 	"sfDBTools/internal/config"
 	"sfDBTools/utils/common"
 	"sfDBTools/utils/database"
 	"sfDBTools/utils/database/info"
+	"sfDBTools/utils/secrets"
 	"sfDBTools/utils/terminal"
 
 	"github.com/spf13/cobra"
@@ -48,6 +45,10 @@ func ResolveDatabaseConnection(cmd *cobra.Command) (host string, port int, user,
 		port := common.GetIntFlagOrEnv(cmd, "target_port", "TARGET_PORT", 3306)
 		user := common.GetStringFlagOrEnv(cmd, "target_user", "TARGET_USER", "root")
 		password := common.GetStringFlagOrEnv(cmd, "target_password", "TARGET_PASSWORD", "")
+		user, password, err := secrets.ResolveCredentials(user, password)
+		if err != nil {
+			return "", 0, "", "", SourceFlags, err
+		}
 
 		return host, port, user, password, SourceFlags, nil
 	}
@@ -258,19 +259,7 @@ func resolveDatabaseNameForNewDB(cmd *cobra.Command) (string, error) {
 	}
 
 	// Prompt user for manual input
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Enter new database name: ")
-	dbName, err := reader.ReadString('\n')
-	if err != nil {
-		return "", fmt.Errorf("failed to read database name: %w", err)
-	}
-
-	dbName = strings.TrimSpace(dbName)
-	if dbName == "" {
-		return "", fmt.Errorf("database name cannot be empty")
-	}
-
-	return dbName, nil
+	return promptDatabaseName()
 }
 
 // SelectDatabaseInteractiveWithNewOption displays available databases and option to create new one
@@ -280,31 +269,13 @@ func SelectDatabaseInteractiveWithNewOption(config database.Config) (string, err
 		return "", fmt.Errorf("failed to retrieve database list: %w", err)
 	}
 
-	// Display available databases with new database option
-	terminal.PrintSubHeader("Available Databases")
-
-	// Option to create new database
-	fmt.Printf("   0. 🆕 Create new database\n")
-
-	// List existing databases
-	for i, db := range databases {
-		fmt.Printf("   %d. %s\n", i+1, db)
-	}
-
-	// Let user choose
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\nSelect database (0-%d): ", len(databases))
-	choice, err := reader.ReadString('\n')
+	// The first option creates a new database
+	options := append([]string{"🆕 Create new database"}, databases...)
+	index, err := terminal.PickOne("Available Databases", options)
 	if err != nil {
 		return "", fmt.Errorf("failed to read selection: %w", err)
 	}
 
-	choice = strings.TrimSpace(choice)
-	index, err := strconv.Atoi(choice)
-	if err != nil || index < 0 || index > len(databases) {
-		return "", fmt.Errorf("invalid selection: %s", choice)
-	}
-
 	if index == 0 {
 		// User chose to create new database
 		return promptForNewDatabaseName()
@@ -316,40 +287,18 @@ func SelectDatabaseInteractiveWithNewOption(config database.Config) (string, err
 
 // promptForNewDatabaseName prompts user for new database name with options
 func promptForNewDatabaseName() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Println("\n🆕 Create New Database:")
-	fmt.Println("======================")
-	fmt.Println("   1. Use database name from backup filename")
-	fmt.Println("   2. Enter database name manually")
-
-	fmt.Print("\nSelect option (1-2): ")
-	choice, err := reader.ReadString('\n')
+	fmt.Println()
+	options := []string{"Use database name from backup filename", "Enter database name manually"}
+	choice, err := terminal.Select("🆕 Create New Database", options, -1)
 	if err != nil {
 		return "", fmt.Errorf("failed to read selection: %w", err)
 	}
 
-	choice = strings.TrimSpace(choice)
-	switch choice {
-	case "1":
+	if choice == 0 {
 		// This will be handled later when we have the file path
 		return "USE_FILENAME", nil
-	case "2":
-		fmt.Print("Enter new database name: ")
-		dbName, err := reader.ReadString('\n')
-		if err != nil {
-			return "", fmt.Errorf("failed to read database name: %w", err)
-		}
-
-		dbName = strings.TrimSpace(dbName)
-		if dbName == "" {
-			return "", fmt.Errorf("database name cannot be empty")
-		}
-
-		return dbName, nil
-	default:
-		return "", fmt.Errorf("invalid selection: %s", choice)
 	}
+	return promptDatabaseName()
 }
 
 // resolveDatabaseNameForNewDBWithFile handles database name resolution for new database creation with file path
@@ -369,19 +318,7 @@ func resolveDatabaseNameForNewDBWithFile(cmd *cobra.Command, filePath string) (s
 	}
 
 	// Prompt user for manual input
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Enter new database name: ")
-	dbName, err := reader.ReadString('\n')
-	if err != nil {
-		return "", fmt.Errorf("failed to read database name: %w", err)
-	}
-
-	dbName = strings.TrimSpace(dbName)
-	if dbName == "" {
-		return "", fmt.Errorf("database name cannot be empty")
-	}
-
-	return dbName, nil
+	return promptDatabaseName()
 }
 
 // SelectDatabaseInteractiveWithNewOptionAndFile displays available databases and option to create new one with file context
@@ -394,31 +331,13 @@ func SelectDatabaseInteractiveWithNewOptionAndFile(config database.Config, fileP
 	// Extract suggested database name from filename
 	suggestedName := extractDatabaseNameFromFilename(filepath.Base(filePath))
 
-	// Display available databases with new database option
-	terminal.PrintSubHeader("Available Databases")
-
-	// Option to create new database
-	fmt.Printf("   0. 🆕 Create new database\n")
-
-	// List existing databases
-	for i, db := range databases {
-		fmt.Printf("   %d. %s\n", i+1, db)
-	}
-
-	// Let user choose
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\nSelect database (0-%d): ", len(databases))
-	choice, err := reader.ReadString('\n')
+	// The first option creates a new database
+	options := append([]string{"🆕 Create new database"}, databases...)
+	index, err := terminal.PickOne("Available Databases", options)
 	if err != nil {
 		return "", fmt.Errorf("failed to read selection: %w", err)
 	}
 
-	choice = strings.TrimSpace(choice)
-	index, err := strconv.Atoi(choice)
-	if err != nil || index < 0 || index > len(databases) {
-		return "", fmt.Errorf("invalid selection: %s", choice)
-	}
-
 	if index == 0 {
 		// User chose to create new database
 		return promptForNewDatabaseNameWithFile(suggestedName)
@@ -430,42 +349,33 @@ func SelectDatabaseInteractiveWithNewOptionAndFile(config database.Config, fileP
 
 // promptForNewDatabaseNameWithFile prompts user for new database name with filename suggestion
 func promptForNewDatabaseNameWithFile(suggestedName string) (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Println("\n🆕 Create New Database:")
-	fmt.Println("======================")
-	fmt.Printf("   1. Use database name from backup filename (%s)\n", suggestedName)
-	fmt.Println("   2. Enter database name manually")
+	if suggestedName == "" {
+		return promptDatabaseName()
+	}
 
-	fmt.Print("\nSelect option (1-2): ")
-	choice, err := reader.ReadString('\n')
+	fmt.Println()
+	options := []string{
+		fmt.Sprintf("Use database name from backup filename (%s)", suggestedName),
+		"Enter database name manually",
+	}
+	choice, err := terminal.Select("🆕 Create New Database", options, 0)
 	if err != nil {
 		return "", fmt.Errorf("failed to read selection: %w", err)
 	}
 
-	choice = strings.TrimSpace(choice)
-	switch choice {
-	case "1":
-		if suggestedName == "" {
-			return "", fmt.Errorf("cannot extract database name from filename")
-		}
+	if choice == 0 {
 		return suggestedName, nil
-	case "2":
-		fmt.Print("Enter new database name: ")
-		dbName, err := reader.ReadString('\n')
-		if err != nil {
-			return "", fmt.Errorf("failed to read database name: %w", err)
-		}
-
-		dbName = strings.TrimSpace(dbName)
-		if dbName == "" {
-			return "", fmt.Errorf("database name cannot be empty")
-		}
+	}
+	return promptDatabaseName()
+}
 
-		return dbName, nil
-	default:
-		return "", fmt.Errorf("invalid selection: %s", choice)
+// promptDatabaseName asks for the name of the new database
+func promptDatabaseName() (string, error) {
+	dbName, err := terminal.Input("Enter new database name", "", terminal.NotEmpty)
+	if err != nil {
+		return "", fmt.Errorf("failed to read database name: %w", err)
 	}
+	return dbName, nil
 }
 
 // DisplayConfigurationSource shows which configuration source is being used
@@ -0,0 +1,156 @@
+package restore_utils
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/compression"
+	"sfDBTools/utils/crypto"
+	"sfDBTools/utils/shutdown"
+)
+
+// StreamOptions configures OpenBackupStream
+type StreamOptions struct {
+	// EncryptionKeyFile decrypts with a key file; otherwise the password comes
+	// from SFDB_ENCRYPTION_PASSWORD or a prompt
+	EncryptionKeyFile string
+	// Raw wraps the file before anything else reads it, e.g. to track progress
+	// on the bytes read from disk
+	Raw func(r io.Reader, size int64) io.Reader
+}
+
+// BackupStream reads the plain dump of a backup file: the file is decrypted
+// and decompressed on the fly, so nothing is written to disk on the way to
+// the mysql client
+type BackupStream struct {
+	io.Reader
+	Path        string // backup file
+	Plain       string // backup file name without the .enc extension
+	Size        int64  // size of the backup file
+	Encrypted   bool
+	Compression compression.CompressionType
+
+	file         *os.File
+	raw          *rawCounter
+	decompressor io.Closer
+}
+
+// rawCounter counts the bytes read from the backup file
+type rawCounter struct {
+	r     io.Reader
+	count int64
+}
+
+func (c *rawCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(&c.count, int64(n))
+	return n, err
+}
+
+// OpenBackupStream opens path and returns its plain dump. A backup is
+// decrypted when its name ends in .enc or it starts with the header of the
+// chunked encryption format; the compression is detected from the data,
+// falling back to the file extension.
+func OpenBackupStream(path string, opts StreamOptions) (*BackupStream, error) {
+	lg, _ := logger.Get()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup file: %w", err)
+	}
+	s := &BackupStream{Path: path, Plain: path, file: file}
+	if fi, err := file.Stat(); err == nil {
+		s.Size = fi.Size()
+	}
+
+	var reader io.Reader = file
+	if opts.Raw != nil {
+		reader = opts.Raw(reader, s.Size)
+	}
+	s.raw = &rawCounter{r: reader}
+	buffered := bufio.NewReader(s.raw)
+	reader = buffered
+
+	header, _ := buffered.Peek(crypto.HeaderMagicSize)
+	if strings.HasSuffix(strings.ToLower(path), ".enc") || crypto.IsChunkedHeader(header) {
+		secret, err := crypto.GetEncryptionSecret("Enter encryption password to decrypt backup: ", opts.EncryptionKeyFile)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to get encryption password: %w", err)
+		}
+		lg.Debug("Decrypting backup", logger.String("key", secret.String()))
+		dr, err := crypto.NewDecryptingReader(reader, secret)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to create decrypting reader: %w", err)
+		}
+		if _, streaming := dr.(*crypto.DecryptingReader); !streaming {
+			lg.Warn("Backup uses the legacy encryption format and is decrypted in memory; 'backup rekey' converts it to the streaming format",
+				logger.String("file", path))
+		}
+		reader = dr
+		s.Encrypted = true
+		if strings.HasSuffix(strings.ToLower(path), ".enc") {
+			s.Plain = path[:len(path)-len(".enc")]
+		}
+	}
+
+	// The header decides the codec; the extension only covers unrecognised data
+	dr, ctype, err := compression.NewAutoDecompressingReader(reader, compression.DetectCompressionTypeFromFile(s.Plain))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create decompressing reader: %w", err)
+	}
+	if ctype != compression.CompressionNone {
+		lg.Debug("Decompressing backup", logger.String("compression", string(ctype)))
+	}
+	s.Reader, s.Compression, s.decompressor = dr, ctype, dr
+	return s, nil
+}
+
+// RawBytes returns how much of the backup file has been read so far; with
+// Size it gives the progress of a restore whatever the compression
+func (s *BackupStream) RawBytes() int64 {
+	return atomic.LoadInt64(&s.raw.count)
+}
+
+// Close closes the decompressor and the backup file
+func (s *BackupStream) Close() error {
+	err := s.decompressor.Close()
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// MySQLCommand describes a run of the mysql client fed by a BackupStream
+type MySQLCommand struct {
+	Args     []string // client options and the database, without the password
+	Password string   // passed in MYSQL_PWD, never on the command line
+	Stdout   io.Writer
+	Stderr   io.Writer
+}
+
+// PipeToMySQL runs the mysql client with stdin read from r until r ends.
+// The client is stopped when ctx is cancelled.
+func PipeToMySQL(ctx context.Context, r io.Reader, c MySQLCommand) error {
+	cmd := shutdown.Command(ctx, "mysql", c.Args...)
+	cmd.Stdin = r
+	cmd.Stdout, cmd.Stderr = c.Stdout, c.Stderr
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	if c.Password != "" {
+		cmd.Env = append(os.Environ(), fmt.Sprintf("MYSQL_PWD=%s", c.Password))
+	}
+	return cmd.Run()
+}
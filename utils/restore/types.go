@@ -1,25 +1,48 @@
 package restore_utils
 
+import (
+	"time"
+
+	"sfDBTools/utils/database"
+)
+
 // RestoreConfig represents the resolved restore configuration
 type RestoreConfig struct {
-	Host           string
-	Port           int
-	User           string
-	Password       string
-	DBName         string
-	File           string
-	VerifyChecksum bool
+	Host              string
+	Port              int
+	User              string
+	Password          string
+	DBName            string
+	File              string
+	VerifyChecksum    bool
+	RewriteReferences bool
+	RewriteFrom       string
+	SessionVars       []string
+	GlobalVars        []string
+	ExtraArgs         []string
+	EncryptionKeyFile string
 }
 
 // RestoreOptions represents the configuration for restore operations (backward compatibility)
 type RestoreOptions struct {
-	Host           string
-	Port           int
-	User           string
-	Password       string
-	DBName         string
-	File           string
-	VerifyChecksum bool
+	Host              string
+	Port              int
+	User              string
+	Password          string
+	DBName            string
+	File              string
+	VerifyChecksum    bool
+	RewriteReferences bool
+	RewriteFrom       string
+	SessionVars       []string
+	GlobalVars        []string
+	ExtraArgs         []string
+	EncryptionKeyFile string // key file of encrypted backups; a password is asked for otherwise
+	// Until, BinlogStart and BinlogSource describe a point-in-time recovery,
+	// see ResolvePointInTime
+	Until        time.Time
+	BinlogStart  string
+	BinlogSource database.Config
 }
 
 // RestoreUserConfig represents the resolved restore user grants configuration
@@ -45,13 +68,19 @@ type RestoreUserOptions struct {
 // ToRestoreOptions converts RestoreConfig to RestoreOptions for backward compatibility
 func (rc *RestoreConfig) ToRestoreOptions() RestoreOptions {
 	return RestoreOptions{
-		Host:           rc.Host,
-		Port:           rc.Port,
-		User:           rc.User,
-		Password:       rc.Password,
-		DBName:         rc.DBName,
-		File:           rc.File,
-		VerifyChecksum: rc.VerifyChecksum,
+		Host:              rc.Host,
+		Port:              rc.Port,
+		User:              rc.User,
+		Password:          rc.Password,
+		DBName:            rc.DBName,
+		File:              rc.File,
+		VerifyChecksum:    rc.VerifyChecksum,
+		RewriteReferences: rc.RewriteReferences,
+		RewriteFrom:       rc.RewriteFrom,
+		SessionVars:       rc.SessionVars,
+		GlobalVars:        rc.GlobalVars,
+		ExtraArgs:         rc.ExtraArgs,
+		EncryptionKeyFile: rc.EncryptionKeyFile,
 	}
 }
 
@@ -0,0 +1,48 @@
+package sanitize
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/terminal"
+)
+
+// Display prints and logs the transformations in report
+func Display(report *Report, lg *logger.Logger) {
+	lg.Info("Dump sanitize summary", logger.Int("changes", report.Total()), logger.Int("warnings", len(report.Warnings)+report.Dropped))
+	if report.Total() == 0 {
+		terminal.PrintInfo("Sanitizer found no vendor-specific syntax to rewrite")
+		return
+	}
+
+	terminal.PrintSubHeader("Sanitized Syntax")
+	rows := make([][]string, 0, len(report.Applied))
+	for _, rule := range report.Rules() {
+		detail := ""
+		if rule == RuleEngine {
+			engines := make([]string, 0, len(report.Engines))
+			for engine, n := range report.Engines {
+				engines = append(engines, fmt.Sprintf("%s x%d", engine, n))
+			}
+			sort.Strings(engines)
+			detail = strings.Join(engines, ", ")
+		}
+		rows = append(rows, []string{rule, fmt.Sprintf("%d", report.Applied[rule]), detail})
+		lg.Info("Sanitize rule applied", logger.String("rule", rule), logger.Int("count", report.Applied[rule]))
+	}
+	terminal.FormatTable([]string{"Transformation", "Count", "Detail"}, rows)
+
+	if len(report.Warnings) == 0 {
+		return
+	}
+	terminal.PrintWarning("Some changes alter behaviour; review these objects:")
+	for _, w := range report.Warnings {
+		lg.Warn("Sanitize changed behaviour", logger.String("detail", w))
+		fmt.Printf("   - %s\n", w)
+	}
+	if report.Dropped > 0 {
+		fmt.Printf("   ... and %d more\n", report.Dropped)
+	}
+}
@@ -0,0 +1,213 @@
+// Package sanitize rewrites MariaDB-specific syntax in a mysqldump stream so
+// the dump loads on plain MySQL or older servers. Row data (INSERT and
+// REPLACE lines) is passed through untouched; only DDL and session lines are
+// rewritten, and every change is counted in a Report.
+package sanitize
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxSamples caps the statements kept per warning in the report
+const maxSamples = 10
+
+// Rule names used in Report.Applied
+const (
+	RuleSandbox     = "sandbox directive removed"
+	RuleSequence    = "sequence statement commented out"
+	RuleSequenceOpt = "SEQUENCE=1 table option removed"
+	RuleNextval     = "nextval() column default removed"
+	RuleEngine      = "storage engine replaced"
+	RuleTableOption = "MariaDB table option removed"
+	RuleVersioning  = "system versioning removed"
+	RuleCollation   = "collation replaced"
+	RuleCharset     = "utf8mb3 renamed to utf8"
+	RuleIntroducer  = "charset introducer renamed"
+)
+
+// rewrite is one substitution applied to DDL lines
+type rewrite struct {
+	rule    string
+	pattern *regexp.Regexp
+	replace string
+}
+
+var (
+	periodColumn = regexp.MustCompile(`(?i)GENERATED\s+ALWAYS\s+AS\s+ROW\s+(?:START|END)|PERIOD\s+FOR\s+SYSTEM_TIME`)
+	sandboxLine  = regexp.MustCompile(`^/\*M?!999999\\?- enable the sandbox mode \*/`)
+	sequenceLine = regexp.MustCompile("(?i)^(?:CREATE\\s+(?:OR\\s+REPLACE\\s+)?SEQUENCE|DROP\\s+SEQUENCE|(?:SELECT|DO)\\s+SETVAL\\s*\\()")
+	nextvalDflt  = regexp.MustCompile("(?i)\\s+DEFAULT\\s+(?:nextval|next\\s+value\\s+for)\\s*\\(?\\s*`?[\\w.`]+`?\\s*\\)?")
+	engineClause = regexp.MustCompile(`(?i)\bENGINE\s*=\s*(\w+)`)
+
+	rewrites = []rewrite{
+		{RuleSequenceOpt, regexp.MustCompile(`(?i)\s+SEQUENCE\s*=\s*1\b`), ""},
+		{RuleTableOption, regexp.MustCompile(`(?i)\s+(?:PAGE_CHECKSUM|TRANSACTIONAL|PAGE_COMPRESSED|PAGE_COMPRESSION_LEVEL|ENCRYPTED|ENCRYPTION_KEY_ID|IETF_QUOTES)\s*=\s*'?\w+'?`), ""},
+		{RuleVersioning, regexp.MustCompile(`(?i)\s+WITH(?:OUT)?\s+SYSTEM\s+VERSIONING\b`), ""},
+		{RuleCollation, regexp.MustCompile(`(?i)\butf8mb4_uca1400_(?:as_cs|as_ci|ai_cs|ai_ci|nopad_\w+)\b|\butf8mb4_0900_\w+\b`), "utf8mb4_unicode_ci"},
+		{RuleCollation, regexp.MustCompile(`(?i)\butf8mb3_uca1400_\w+\b`), "utf8_unicode_ci"},
+		{RuleIntroducer, regexp.MustCompile(`\b_utf8mb3'`), "_utf8'"},
+		{RuleCharset, regexp.MustCompile(`(?i)\butf8mb3(_\w+)?\b`), "utf8$1"},
+	}
+)
+
+// portableEngines load on every MySQL and MariaDB server
+var portableEngines = map[string]bool{"innodb": true, "myisam": true, "memory": true, "csv": true, "archive": true, "blackhole": true, "merge": true, "mrg_myisam": true}
+
+// Report lists what the sanitizer changed
+type Report struct {
+	Applied  map[string]int // per rule
+	Engines  map[string]int // replaced engine -> tables
+	Warnings []string       // changes that alter behaviour, first maxSamples
+	Dropped  int            // warnings beyond the samples
+}
+
+// Total returns the number of changes made
+func (r *Report) Total() int {
+	n := 0
+	for _, c := range r.Applied {
+		n += c
+	}
+	return n
+}
+
+// Rules returns the applied rule names in a stable order
+func (r *Report) Rules() []string {
+	rules := make([]string, 0, len(r.Applied))
+	for rule := range r.Applied {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+func (r *Report) warn(format string, args ...any) {
+	if len(r.Warnings) < maxSamples {
+		r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+		return
+	}
+	r.Dropped++
+}
+
+// Reader sanitizes a dump while it is read
+type Reader struct {
+	src          *bufio.Reader
+	targetEngine string
+	pending      []byte
+	inData       bool
+	report       *Report
+}
+
+// NewReader wraps r. Tables using engines other than the portable ones
+// (Aria, ColumnStore, ...) are converted to targetEngine, InnoDB when empty.
+func NewReader(r io.Reader, targetEngine string) *Reader {
+	if targetEngine == "" {
+		targetEngine = "InnoDB"
+	}
+	return &Reader{
+		src:          bufio.NewReaderSize(r, 1<<20),
+		targetEngine: targetEngine,
+		report:       &Report{Applied: make(map[string]int), Engines: make(map[string]int)},
+	}
+}
+
+// Report returns the changes made so far
+func (s *Reader) Report() *Report { return s.report }
+
+func (s *Reader) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if err := s.fill(); err != nil {
+			if len(s.pending) > 0 {
+				break
+			}
+			return 0, err
+		}
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// fill loads the next line into pending. Data lines are streamed in
+// buffer-sized pieces without inspection.
+func (s *Reader) fill() error {
+	if s.inData {
+		chunk, err := s.src.ReadSlice('\n')
+		s.pending = append(s.pending[:0], chunk...)
+		if err == bufio.ErrBufferFull {
+			return nil
+		}
+		s.inData = false
+		return err
+	}
+	if head, _ := s.src.Peek(7); bytes.HasPrefix(head, []byte("INSERT ")) || bytes.HasPrefix(head, []byte("REPLACE")) {
+		s.inData = true
+		return s.fill()
+	}
+
+	var line []byte
+	for {
+		chunk, err := s.src.ReadSlice('\n')
+		line = append(line, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		s.pending = s.sanitizeLine(line)
+		return err
+	}
+}
+
+func (s *Reader) sanitizeLine(line []byte) []byte {
+	text := string(line)
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+		return line
+	}
+
+	if sandboxLine.MatchString(trimmed) {
+		s.report.Applied[RuleSandbox]++
+		return nil
+	}
+	if sequenceLine.MatchString(trimmed) {
+		s.report.Applied[RuleSequence]++
+		s.report.warn("sequence not restored: %s", preview(trimmed))
+		return []byte("-- [sanitized] " + text)
+	}
+
+	if periodColumn.MatchString(text) {
+		s.report.warn("system-versioned table needs manual conversion: %s", preview(trimmed))
+	}
+	if m := nextvalDflt.FindAllString(text, -1); len(m) > 0 {
+		s.report.Applied[RuleNextval] += len(m)
+		s.report.warn("column default removed, the application must supply the value: %s", preview(trimmed))
+		text = nextvalDflt.ReplaceAllString(text, "")
+	}
+	text = engineClause.ReplaceAllStringFunc(text, func(clause string) string {
+		engine := engineClause.FindStringSubmatch(clause)[1]
+		if portableEngines[strings.ToLower(engine)] || strings.EqualFold(engine, s.targetEngine) {
+			return clause
+		}
+		s.report.Applied[RuleEngine]++
+		s.report.Engines[engine]++
+		return "ENGINE=" + s.targetEngine
+	})
+	for _, rw := range rewrites {
+		if n := len(rw.pattern.FindAllStringIndex(text, -1)); n > 0 {
+			s.report.Applied[rw.rule] += n
+			text = rw.pattern.ReplaceAllString(text, rw.replace)
+		}
+	}
+	return []byte(text)
+}
+
+func preview(s string) string {
+	if len(s) > 120 {
+		return s[:117] + "..."
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// CatalogVersion is the drill catalog version written by this build.
+//
+//	1: a bare JSON array of drill records
+//	2: an object with schema_version and a records array
+const CatalogVersion = 2
+
+var catalogKind = kind{
+	name:    "drill catalog",
+	current: CatalogVersion,
+	migrations: map[int]Migration{
+		1: func(doc map[string]any) error {
+			if _, ok := doc["records"]; !ok {
+				doc["records"] = []any{}
+			}
+			return nil
+		},
+	},
+}
+
+// catalogFile is the on-disk layout of a current drill catalog
+type catalogFile struct {
+	SchemaVersion int `json:"schema_version"`
+	Records       any `json:"records"`
+}
+
+// loadCatalog decodes a catalog of any supported version into a document
+func loadCatalog(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid %s %s: %w", catalogKind.name, path, err)
+	}
+	switch v := raw.(type) {
+	case []any:
+		return map[string]any{"records": v}, nil
+	case map[string]any:
+		return v, nil
+	}
+	return nil, fmt.Errorf("invalid %s %s: expected an object or array", catalogKind.name, path)
+}
+
+// ReadCatalog returns the records array of the drill catalog at path as JSON,
+// upgrading older catalogs in place on first read. A missing file yields nil.
+func ReadCatalog(path string) (json.RawMessage, error) {
+	doc, err := loadCatalog(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := catalogKind.upgrade(path, doc)
+	if err != nil {
+		return nil, err
+	}
+	if from != CatalogVersion {
+		rewrite(catalogKind, path, doc)
+	}
+	return json.Marshal(doc["records"])
+}
+
+// WriteCatalog writes records as a current-version drill catalog
+func WriteCatalog(path string, records any) error {
+	return writeAtomic(path, catalogFile{SchemaVersion: CatalogVersion, Records: records}, false)
+}
+
+// UpgradeCatalog migrates the drill catalog at path to the current version and
+// reports the version it had. With dryRun the file is left untouched.
+func UpgradeCatalog(path string, dryRun bool) (int, error) {
+	doc, err := loadCatalog(path)
+	if err != nil {
+		return 0, err
+	}
+	from, err := catalogKind.upgrade(path, doc)
+	if err != nil || from == CatalogVersion || dryRun {
+		return from, err
+	}
+	if err := writeAtomic(path, doc, true); err != nil {
+		return from, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return from, nil
+}
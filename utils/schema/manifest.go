@@ -0,0 +1,124 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ManifestVersion is the backup manifest (metadata JSON) version written by this build.
+//
+//	1: unversioned manifests from earlier releases
+//	2: adds schema_version, always sets backup_type and database_info.size_mb
+const ManifestVersion = 2
+
+var manifestKind = kind{
+	name:    "backup manifest",
+	current: ManifestVersion,
+	migrations: map[int]Migration{
+		1: migrateManifestV1,
+	},
+}
+
+// migrateManifestV1 normalizes fields that early releases left out or named differently
+func migrateManifestV1(doc map[string]any) error {
+	if v, ok := doc["mysql_version"]; ok {
+		if _, exists := doc["mariadb_version"]; !exists {
+			doc["mariadb_version"] = v
+		}
+		delete(doc, "mysql_version")
+	}
+	if t, _ := doc["backup_type"].(string); t == "" {
+		doc["backup_type"] = "single"
+		if doc["database_name"] == "all_databases" {
+			doc["backup_type"] = "all_databases"
+		}
+	}
+	if dbInfo, ok := doc["database_info"].(map[string]any); ok {
+		if _, has := dbInfo["size_mb"]; !has {
+			if size, ok := dbInfo["size_bytes"].(float64); ok {
+				dbInfo["size_mb"] = size / (1024 * 1024)
+			}
+		}
+	}
+	return nil
+}
+
+// IsManifest reports whether a decoded JSON object is a backup manifest
+func IsManifest(doc map[string]any) bool {
+	_, hasName := doc["database_name"]
+	_, hasDate := doc["backup_date"]
+	return hasName && hasDate
+}
+
+// ReadManifest reads a backup manifest and returns its JSON upgraded to the
+// current version. Older manifests are rewritten in place on first read.
+// Files that are not manifests are returned unchanged so callers can keep
+// their own filtering; a manifest with an unsupported version is an error.
+func ReadManifest(path string) ([]byte, error) {
+	doc, data, err := loadObject(path)
+	if err != nil {
+		if data != nil {
+			return data, nil
+		}
+		return nil, err
+	}
+	if !IsManifest(doc) {
+		return data, nil
+	}
+
+	from, err := manifestKind.upgrade(path, doc)
+	if err != nil {
+		return nil, err
+	}
+	if from == ManifestVersion {
+		return data, nil
+	}
+	rewrite(manifestKind, path, doc)
+	return json.Marshal(doc)
+}
+
+// UpgradeManifest migrates the manifest at path to the current version and
+// reports the version it had. With dryRun the file is left untouched.
+// ok is false when path is not a backup manifest.
+func UpgradeManifest(path string, dryRun bool) (from int, ok bool, err error) {
+	doc, _, err := loadObject(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, false, err
+		}
+		return 0, false, nil
+	}
+	if !IsManifest(doc) {
+		return 0, false, nil
+	}
+
+	from, err = manifestKind.upgrade(path, doc)
+	if err != nil || from == ManifestVersion || dryRun {
+		return from, true, err
+	}
+	if err := writeAtomic(path, doc, true); err != nil {
+		return from, true, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return from, true, nil
+}
+
+// PatchManifest applies patch to the manifest at path, upgrading it first, and
+// writes it back keeping the modification time so retention is unaffected
+func PatchManifest(path string, patch func(doc map[string]any)) error {
+	doc, _, err := loadObject(path)
+	if err != nil {
+		return err
+	}
+	if !IsManifest(doc) {
+		return fmt.Errorf("%s is not a backup manifest", path)
+	}
+	if _, err := manifestKind.upgrade(path, doc); err != nil {
+		return err
+	}
+	patch(doc)
+	if err := writeAtomic(path, doc, true); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package schema versions the JSON artifacts sfDBTools keeps on disk (backup
+// manifests and the drill catalog). Older formats are migrated step by step to
+// the current version when read, so entries written by earlier releases stay
+// readable, and versions newer than this build are rejected with an error
+// naming the artifact.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/fs/policy"
+)
+
+// VersionField is the JSON key holding an artifact's schema version.
+// Artifacts written before versioning have no such key and are version 1.
+const VersionField = "schema_version"
+
+// Migration upgrades a decoded document from version N to N+1 in place
+type Migration func(doc map[string]any) error
+
+// VersionError reports an artifact whose schema version this build cannot read
+type VersionError struct {
+	Kind      string
+	Path      string
+	Version   int
+	Supported int
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("%s %s has unsupported %s %d (this build supports 1-%d)",
+		e.Kind, e.Path, VersionField, e.Version, e.Supported)
+}
+
+// kind describes one versioned artifact type
+type kind struct {
+	name       string
+	current    int
+	migrations map[int]Migration // keyed by the version they upgrade from
+}
+
+// Version returns the schema version recorded in doc, 1 when absent
+func Version(doc map[string]any) (int, error) {
+	raw, ok := doc[VersionField]
+	if !ok {
+		return 1, nil
+	}
+	v, ok := raw.(float64)
+	if !ok || v != float64(int(v)) {
+		return 0, fmt.Errorf("%s must be an integer, got %v", VersionField, raw)
+	}
+	return int(v), nil
+}
+
+// upgrade migrates doc to the current version and returns the version it had
+func (k kind) upgrade(path string, doc map[string]any) (int, error) {
+	from, err := Version(doc)
+	if err != nil {
+		return 0, fmt.Errorf("%s %s: %w", k.name, path, err)
+	}
+	if from < 1 || from > k.current {
+		return from, &VersionError{Kind: k.name, Path: path, Version: from, Supported: k.current}
+	}
+	for v := from; v < k.current; v++ {
+		migrate, ok := k.migrations[v]
+		if !ok {
+			return from, fmt.Errorf("%s %s: no migration from %s %d", k.name, path, VersionField, v)
+		}
+		if err := migrate(doc); err != nil {
+			return from, fmt.Errorf("%s %s: migration from %s %d failed: %w", k.name, path, VersionField, v, err)
+		}
+	}
+	doc[VersionField] = k.current
+	return from, nil
+}
+
+// rewrite replaces path with the upgraded document through a temp file,
+// keeping the original modification time so age-based retention is unaffected.
+// Failures are only logged: the caller still has the upgraded content, and
+// read-only backup mounts must stay readable.
+func rewrite(k kind, path string, doc any) {
+	lg, _ := logger.Get()
+
+	if err := writeAtomic(path, doc, true); err != nil {
+		lg.Debug("Could not persist upgraded artifact", logger.String("kind", k.name), logger.String("path", path), logger.Error(err))
+		return
+	}
+	lg.Info("Upgraded artifact schema", logger.String("kind", k.name), logger.String("path", path), logger.Int("version", k.current))
+}
+
+// writeAtomic marshals doc and replaces path through a temp file created with
+// the file policy, optionally restoring the previous modification time
+func writeAtomic(path string, doc any, keepModTime bool) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	info, statErr := os.Stat(path)
+
+	tmp := path + ".tmp"
+	if err := policy.WriteFile(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if keepModTime && statErr == nil {
+		_ = os.Chtimes(path, info.ModTime(), info.ModTime())
+	}
+	return nil
+}
+
+// loadObject reads path and decodes it as a JSON object
+func loadObject(path string) (map[string]any, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, data, err
+	}
+	return doc, data, nil
+}
@@ -0,0 +1,46 @@
+package schemadiff
+
+import (
+	"fmt"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/terminal"
+)
+
+// maxDisplayed caps the changes printed; the log and a saved report hold all
+const maxDisplayed = 50
+
+// Display prints and logs the changes in report
+func Display(report *Report, lg *logger.Logger) {
+	lg.Info("Schema diff summary",
+		logger.String("database", report.Database),
+		logger.Int("added", report.Count(Added)),
+		logger.Int("removed", report.Count(Removed)),
+		logger.Int("changed", report.Count(Changed)))
+
+	terminal.PrintSubHeader("Schema Changes")
+	if len(report.Changes) == 0 {
+		terminal.PrintInfo("The restore left the schema structure unchanged")
+		return
+	}
+
+	rows := make([][]string, 0, len(report.Changes))
+	for i, c := range report.Changes {
+		lg.Info("Schema changed",
+			logger.String("kind", c.Kind),
+			logger.String("object", c.Object),
+			logger.String("table", c.Table),
+			logger.String("name", c.Name),
+			logger.String("before", c.Before),
+			logger.String("after", c.After))
+		if i < maxDisplayed {
+			rows = append(rows, []string{c.Kind, c.Object, c.Table, c.Name, c.Before, c.After})
+		}
+	}
+	terminal.FormatTable([]string{"Change", "Object", "Table", "Name", "Before", "After"}, rows)
+	if len(report.Changes) > maxDisplayed {
+		fmt.Printf("   ... and %d more, see the log\n", len(report.Changes)-maxDisplayed)
+	}
+	fmt.Printf("Tables/columns/indexes: %d added, %d removed, %d changed\n",
+		report.Count(Added), report.Count(Removed), report.Count(Changed))
+}
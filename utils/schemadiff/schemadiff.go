@@ -0,0 +1,269 @@
+// Package schemadiff captures the structure of a database (tables, columns,
+// indexes) from information_schema and compares two captures, e.g. before
+// and after a restore over an existing schema.
+package schemadiff
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sfDBTools/utils/fs/policy"
+)
+
+// Change kinds
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Object kinds
+const (
+	ObjectTable  = "table"
+	ObjectColumn = "column"
+	ObjectIndex  = "index"
+)
+
+// Snapshot is the structure of one database at a point in time
+type Snapshot struct {
+	Database string            `json:"database"`
+	TakenAt  time.Time         `json:"taken_at"`
+	Tables   map[string]*Table `json:"tables"`
+}
+
+// Table is a base table or view
+type Table struct {
+	Type      string            `json:"type"` // BASE TABLE or VIEW
+	Engine    string            `json:"engine,omitempty"`
+	Collation string            `json:"collation,omitempty"`
+	Columns   map[string]Column `json:"columns"`
+	Indexes   map[string]Index  `json:"indexes"`
+}
+
+// Column is one column definition
+type Column struct {
+	Position  int     `json:"position"`
+	Type      string  `json:"type"`
+	Nullable  bool    `json:"nullable"`
+	Default   *string `json:"default,omitempty"`
+	Extra     string  `json:"extra,omitempty"`
+	Collation string  `json:"collation,omitempty"`
+}
+
+// Index is one index with its columns in order
+type Index struct {
+	Unique  bool     `json:"unique"`
+	Type    string   `json:"type"`
+	Columns []string `json:"columns"`
+}
+
+// Change is one structural difference between two snapshots
+type Change struct {
+	Kind   string `json:"kind"`
+	Object string `json:"object"`
+	Table  string `json:"table"`
+	Name   string `json:"name,omitempty"` // column or index
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// Report is the result of Diff
+type Report struct {
+	Database string    `json:"database"`
+	Before   time.Time `json:"before"`
+	After    time.Time `json:"after"`
+	Changes  []Change  `json:"changes"`
+}
+
+// Capture reads the structure of dbName. A database that does not exist yet
+// yields an empty snapshot.
+func Capture(ctx context.Context, db *sql.DB, dbName string) (*Snapshot, error) {
+	snap := &Snapshot{Database: dbName, TakenAt: time.Now().UTC(), Tables: make(map[string]*Table)}
+
+	rows, err := db.QueryContext(ctx, `SELECT TABLE_NAME, TABLE_TYPE, COALESCE(ENGINE, ''), COALESCE(TABLE_COLLATION, '')
+		FROM information_schema.TABLES WHERE TABLE_SCHEMA = ?`, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tables: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		t := &Table{Columns: make(map[string]Column), Indexes: make(map[string]Index)}
+		if err := rows.Scan(&name, &t.Type, &t.Engine, &t.Collation); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		snap.Tables[name] = t
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `SELECT TABLE_NAME, COLUMN_NAME, ORDINAL_POSITION, COLUMN_TYPE, IS_NULLABLE,
+			COLUMN_DEFAULT, EXTRA, COALESCE(COLLATION_NAME, '')
+		FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ?`, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+	for rows.Next() {
+		var table, name, nullable string
+		var dflt sql.NullString
+		var c Column
+		if err := rows.Scan(&table, &name, &c.Position, &c.Type, &nullable, &dflt, &c.Extra, &c.Collation); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c.Nullable = nullable == "YES"
+		if dflt.Valid {
+			c.Default = &dflt.String
+		}
+		if t := snap.Tables[table]; t != nil {
+			t.Columns[name] = c
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `SELECT TABLE_NAME, INDEX_NAME, NON_UNIQUE, INDEX_TYPE, COLUMN_NAME
+		FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX`, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, name, indexType string
+		var column sql.NullString
+		var nonUnique int
+		if err := rows.Scan(&table, &name, &nonUnique, &indexType, &column); err != nil {
+			return nil, err
+		}
+		t := snap.Tables[table]
+		if t == nil {
+			continue
+		}
+		idx := t.Indexes[name]
+		idx.Unique, idx.Type = nonUnique == 0, indexType
+		idx.Columns = append(idx.Columns, column.String) // empty for functional key parts
+		t.Indexes[name] = idx
+	}
+	return snap, rows.Err()
+}
+
+// Diff lists what changed from before to after, sorted by table and name
+func Diff(before, after *Snapshot) *Report {
+	r := &Report{Database: after.Database, Before: before.TakenAt, After: after.TakenAt}
+	for _, name := range unionKeys(before.Tables, after.Tables) {
+		b, a := before.Tables[name], after.Tables[name]
+		switch {
+		case b == nil:
+			r.add(Change{Kind: Added, Object: ObjectTable, Table: name, After: a.describe()})
+			continue
+		case a == nil:
+			r.add(Change{Kind: Removed, Object: ObjectTable, Table: name, Before: b.describe()})
+			continue
+		}
+		if bd, ad := b.describe(), a.describe(); bd != ad {
+			r.add(Change{Kind: Changed, Object: ObjectTable, Table: name, Before: bd, After: ad})
+		}
+		for _, col := range unionKeys(b.Columns, a.Columns) {
+			bc, bok := b.Columns[col]
+			ac, aok := a.Columns[col]
+			r.compare(ObjectColumn, name, col, bok, aok, bc.describe(), ac.describe())
+		}
+		for _, idx := range unionKeys(b.Indexes, a.Indexes) {
+			bi, bok := b.Indexes[idx]
+			ai, aok := a.Indexes[idx]
+			r.compare(ObjectIndex, name, idx, bok, aok, bi.describe(), ai.describe())
+		}
+	}
+	return r
+}
+
+// Count returns the number of changes of kind
+func (r *Report) Count(kind string) int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Save writes the report as JSON to path
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return policy.WriteFile(path, data)
+}
+
+func (r *Report) add(c Change) {
+	r.Changes = append(r.Changes, c)
+}
+
+func (r *Report) compare(object, table, name string, inBefore, inAfter bool, before, after string) {
+	switch {
+	case !inBefore:
+		r.add(Change{Kind: Added, Object: object, Table: table, Name: name, After: after})
+	case !inAfter:
+		r.add(Change{Kind: Removed, Object: object, Table: table, Name: name, Before: before})
+	case before != after:
+		r.add(Change{Kind: Changed, Object: object, Table: table, Name: name, Before: before, After: after})
+	}
+}
+
+func (t *Table) describe() string {
+	if t.Type == "VIEW" {
+		return "VIEW"
+	}
+	return strings.TrimSpace(t.Engine + " " + t.Collation)
+}
+
+func (c Column) describe() string {
+	s := c.Type
+	if !c.Nullable {
+		s += " NOT NULL"
+	}
+	if c.Default != nil {
+		s += " DEFAULT " + *c.Default
+	}
+	if c.Extra != "" {
+		s += " " + c.Extra
+	}
+	if c.Collation != "" {
+		s += " COLLATE " + c.Collation
+	}
+	return s
+}
+
+func (i Index) describe() string {
+	s := i.Type + " (" + strings.Join(i.Columns, ", ") + ")"
+	if i.Unique {
+		s = "UNIQUE " + s
+	}
+	return s
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"sfDBTools/internal/config/model"
+)
+
+// awsProvider reads AWS Secrets Manager secrets with the aws CLI, which
+// brings the usual credential chain (instance profile, SSO, AWS_PROFILE)
+type awsProvider struct {
+	cfg model.AWSSecretsConfig
+}
+
+// Fetch returns the fields of a JSON secret string; any other secret string
+// is returned as the single field "value"
+func (p *awsProvider) Fetch(secretID string) (*Secret, error) {
+	if _, err := exec.LookPath("aws"); err != nil {
+		return nil, fmt.Errorf("aws CLI not found in PATH")
+	}
+	args := []string{"secretsmanager", "get-secret-value", "--secret-id", secretID,
+		"--query", "SecretString", "--output", "text"}
+	if p.cfg.Region != "" {
+		args = append(args, "--region", p.cfg.Region)
+	}
+	if p.cfg.Profile != "" {
+		args = append(args, "--profile", p.cfg.Profile)
+	}
+	cmd := exec.Command("aws", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	value := strings.TrimRight(string(out), "\r\n")
+	var values map[string]any
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return &Secret{Fields: map[string]string{"value": value}}, nil
+	}
+	return &Secret{Fields: stringFields(values)}, nil
+}
@@ -0,0 +1,33 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/subosito/gotenv"
+)
+
+// envFileProvider reads KEY=value files such as a mounted Kubernetes or
+// systemd credentials file. envfile://#KEY reads secrets.env_file.
+type envFileProvider struct {
+	defaultPath string
+}
+
+func (p *envFileProvider) Fetch(path string) (*Secret, error) {
+	if path == "" {
+		path = p.defaultPath
+	}
+	if path == "" {
+		return nil, fmt.Errorf("no env file given and secrets.env_file not set")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	values, err := gotenv.StrictParse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &Secret{Fields: values}, nil
+}
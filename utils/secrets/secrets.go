@@ -0,0 +1,223 @@
+// Package secrets resolves credentials from secret stores, so passwords do not
+// have to live in flags, environment variables or config.yaml. Wherever a user
+// or password is accepted, a reference can be given instead:
+//
+//	vault://database/creds/backup             username and password of a lease
+//	vault://secret/data/db/prod#password      one field of a KV secret
+//	awssm://prod/db#password                  AWS Secrets Manager
+//	envfile:///etc/sfDBTools/secrets.env#DB_PASSWORD
+//
+// Providers are registered per scheme. Secrets are cached for the run; leased
+// secrets (Vault dynamic credentials) are kept until their lease ends and
+// renewed in the background while the process runs.
+package secrets
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"sfDBTools/internal/config"
+	"sfDBTools/internal/config/model"
+	"sfDBTools/internal/logger"
+)
+
+// DefaultCacheTTL is how long a secret without lease is reused
+const DefaultCacheTTL = 5 * time.Minute
+
+// Secret is a set of named values read from a store
+type Secret struct {
+	Fields map[string]string
+	// LeaseID is set for secrets the store revokes after LeaseDuration,
+	// such as Vault dynamic database credentials
+	LeaseID       string
+	LeaseDuration time.Duration
+	Renewable     bool
+}
+
+// Provider reads secrets of one scheme
+type Provider interface {
+	Fetch(path string) (*Secret, error)
+}
+
+// Renewer is implemented by providers whose leases can be extended
+type Renewer interface {
+	// Renew extends the lease and returns its new duration
+	Renew(leaseID string) (time.Duration, error)
+}
+
+type cached struct {
+	secret  *Secret
+	expires time.Time
+}
+
+var (
+	mu        sync.Mutex
+	providers = make(map[string]Provider)
+	cache     = make(map[string]*cached)
+	cacheTTL  = DefaultCacheTTL
+)
+
+// Register makes a provider available for references with scheme
+func Register(scheme string, p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[scheme] = p
+}
+
+// Configure registers the built-in providers with the secrets section of
+// config.yaml. It runs once at startup.
+func Configure(cfg model.SecretsConfig) error {
+	ttl := DefaultCacheTTL
+	if cfg.CacheTTL != "" {
+		d, err := time.ParseDuration(cfg.CacheTTL)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid secrets.cache_ttl %q", cfg.CacheTTL)
+		}
+		ttl = d
+	}
+	vault, err := newVaultProvider(cfg.Vault)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	cacheTTL = ttl
+	mu.Unlock()
+	Register("vault", vault)
+	Register("awssm", &awsProvider{cfg: cfg.AWS})
+	Register("envfile", &envFileProvider{defaultPath: cfg.EnvFile})
+	config.SetCredentialResolver(ResolveCredentials)
+	return nil
+}
+
+// IsReference reports whether s refers to a secret of a registered scheme
+func IsReference(s string) bool {
+	scheme, _, ok := strings.Cut(s, "://")
+	if !ok {
+		return false
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	_, ok = providers[scheme]
+	return ok
+}
+
+// Resolve returns the value s refers to, or s itself when it is not a
+// reference. A reference without #field needs a secret with a single field.
+func Resolve(s string) (string, error) {
+	if !IsReference(s) {
+		return s, nil
+	}
+	ref, field, _ := strings.Cut(s, "#")
+	secret, err := fetch(ref)
+	if err != nil {
+		return "", err
+	}
+	if field == "" {
+		if len(secret.Fields) != 1 {
+			return "", fmt.Errorf("secret %s has %d fields; select one with #field", ref, len(secret.Fields))
+		}
+		for _, v := range secret.Fields {
+			return v, nil
+		}
+	}
+	v, ok := secret.Fields[field]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no field %q", ref, field)
+	}
+	return v, nil
+}
+
+// ResolveCredentials resolves user and password references. A password
+// reference without #field names a secret holding both: its username (or
+// user) replaces user, which is how dynamic credentials are used.
+func ResolveCredentials(user, password string) (string, string, error) {
+	if IsReference(password) && !strings.Contains(password, "#") {
+		secret, err := fetch(password)
+		if err != nil {
+			return "", "", err
+		}
+		pw, ok := secret.Fields["password"]
+		if !ok {
+			return "", "", fmt.Errorf("secret %s has no password field", password)
+		}
+		for _, key := range []string{"username", "user"} {
+			if u, ok := secret.Fields[key]; ok {
+				return u, pw, nil
+			}
+		}
+		password = pw
+	}
+	user, err := Resolve(user)
+	if err != nil {
+		return "", "", err
+	}
+	if password, err = Resolve(password); err != nil {
+		return "", "", err
+	}
+	return user, password, nil
+}
+
+// fetch returns the secret of ref (scheme://path) from the cache or its
+// provider. A leased secret is fetched once, so the username and password of
+// dynamic credentials always belong to the same lease.
+func fetch(ref string) (*Secret, error) {
+	scheme, path, _ := strings.Cut(ref, "://")
+	mu.Lock()
+	p := providers[scheme]
+	if c, ok := cache[ref]; ok && time.Now().Before(c.expires) {
+		mu.Unlock()
+		return c.secret, nil
+	}
+	ttl := cacheTTL
+	mu.Unlock()
+
+	secret, err := p.Fetch(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", ref, err)
+	}
+	expires := time.Now().Add(ttl)
+	if secret.LeaseID != "" {
+		expires = time.Now().Add(secret.LeaseDuration)
+	}
+	mu.Lock()
+	cache[ref] = &cached{secret: secret, expires: expires}
+	mu.Unlock()
+
+	lg, _ := logger.Get()
+	lg.Debug("Secret read", logger.String("ref", ref), logger.Bool("leased", secret.LeaseID != ""))
+	if r, ok := p.(Renewer); ok && secret.Renewable && secret.LeaseID != "" {
+		go keepRenewed(ref, r, secret)
+	}
+	return secret, nil
+}
+
+// keepRenewed extends a lease at two thirds of its duration for as long as
+// the process runs, so long backups do not lose their credentials midway.
+// It stops when the store refuses, e.g. because the lease reached its max TTL.
+func keepRenewed(ref string, r Renewer, secret *Secret) {
+	lg, _ := logger.Get()
+	duration := secret.LeaseDuration
+	for duration > 0 {
+		time.Sleep(duration * 2 / 3)
+		next, err := r.Renew(secret.LeaseID)
+		if err != nil {
+			lg.Warn("Failed to renew secret lease", logger.String("ref", ref), logger.Error(err))
+			return
+		}
+		mu.Lock()
+		if c, ok := cache[ref]; ok && c.secret == secret {
+			c.expires = time.Now().Add(next)
+		}
+		mu.Unlock()
+		lg.Debug("Secret lease renewed", logger.String("ref", ref), logger.String("duration", next.String()))
+		if next >= duration*2/3 {
+			duration = next
+			continue
+		}
+		// The lease is capped and about to end; renewing again gains nothing
+		duration = 0
+	}
+}
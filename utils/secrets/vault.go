@@ -0,0 +1,176 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sfDBTools/internal/config/model"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// vaultProvider reads HashiCorp Vault secrets over its HTTP API. KV v2
+// secrets (data/data), KV v1 secrets and dynamic secrets engines such as
+// database/creds/<role> are all read with a plain GET on the path.
+type vaultProvider struct {
+	cfg       model.VaultConfig
+	increment time.Duration
+	http      *resty.Client
+}
+
+// vaultResponse mirrors the subset of a Vault read we use
+type vaultResponse struct {
+	LeaseID       string          `json:"lease_id"`
+	LeaseDuration int             `json:"lease_duration"`
+	Renewable     bool            `json:"renewable"`
+	Data          json.RawMessage `json:"data"`
+	Errors        []string        `json:"errors"`
+}
+
+func newVaultProvider(cfg model.VaultConfig) (*vaultProvider, error) {
+	p := &vaultProvider{cfg: cfg}
+	if cfg.RenewIncrement != "" {
+		d, err := time.ParseDuration(cfg.RenewIncrement)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid secrets.vault.renew_increment %q", cfg.RenewIncrement)
+		}
+		p.increment = d
+	}
+	return p, nil
+}
+
+// client is created on first use, so the address and token are only required
+// when a vault:// reference is actually used
+func (p *vaultProvider) client() (*resty.Client, error) {
+	if p.http != nil {
+		return p.http, nil
+	}
+	address := os.Getenv("VAULT_ADDR")
+	if address == "" {
+		address = p.cfg.Address
+	}
+	if address == "" {
+		return nil, fmt.Errorf("vault address not set (secrets.vault.address or VAULT_ADDR)")
+	}
+	token, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+	namespace := os.Getenv("VAULT_NAMESPACE")
+	if namespace == "" {
+		namespace = p.cfg.Namespace
+	}
+
+	p.http = resty.New().
+		SetTimeout(10*time.Second).
+		SetBaseURL(strings.TrimRight(address, "/")+"/v1").
+		SetHeader("X-Vault-Token", token).
+		SetHeader("User-Agent", "sfDBTools")
+	if namespace != "" {
+		p.http.SetHeader("X-Vault-Namespace", namespace)
+	}
+	return p.http, nil
+}
+
+// token is taken from VAULT_TOKEN, secrets.vault.token_file or the file
+// written by 'vault login', in that order
+func (p *vaultProvider) token() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	path := p.cfg.TokenFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("vault token not set (VAULT_TOKEN or secrets.vault.token_file)")
+		}
+		path = filepath.Join(home, ".vault-token")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("vault token not set (VAULT_TOKEN or secrets.vault.token_file): %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (p *vaultProvider) Fetch(path string) (*Secret, error) {
+	http, err := p.client()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.R().Get("/" + strings.TrimLeft(path, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	var parsed vaultResponse
+	if err := json.Unmarshal(resp.Body(), &parsed); err != nil && resp.StatusCode() < 400 {
+		return nil, fmt.Errorf("failed to parse vault response: %w", err)
+	}
+	if resp.StatusCode() >= 400 {
+		return nil, fmt.Errorf("vault returned %d: %s", resp.StatusCode(), strings.Join(parsed.Errors, "; "))
+	}
+
+	// KV v2 nests the secret in data.data next to data.metadata
+	var kv2 struct {
+		Data     map[string]any `json:"data"`
+		Metadata map[string]any `json:"metadata"`
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(parsed.Data, &kv2); err == nil && kv2.Data != nil && kv2.Metadata != nil {
+		fields = kv2.Data
+	} else if err := json.Unmarshal(parsed.Data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse vault secret: %w", err)
+	}
+
+	return &Secret{
+		Fields:        stringFields(fields),
+		LeaseID:       parsed.LeaseID,
+		LeaseDuration: time.Duration(parsed.LeaseDuration) * time.Second,
+		Renewable:     parsed.Renewable,
+	}, nil
+}
+
+// Renew extends a lease by secrets.vault.renew_increment, or by the lease's
+// own default when no increment is configured
+func (p *vaultProvider) Renew(leaseID string) (time.Duration, error) {
+	http, err := p.client()
+	if err != nil {
+		return 0, err
+	}
+	body := map[string]any{"lease_id": leaseID}
+	if p.increment > 0 {
+		body["increment"] = int(p.increment.Seconds())
+	}
+	resp, err := http.R().SetBody(body).Put("/sys/leases/renew")
+	if err != nil {
+		return 0, fmt.Errorf("vault request failed: %w", err)
+	}
+	var parsed vaultResponse
+	_ = json.Unmarshal(resp.Body(), &parsed)
+	if resp.StatusCode() >= 400 {
+		return 0, fmt.Errorf("vault returned %d: %s", resp.StatusCode(), strings.Join(parsed.Errors, "; "))
+	}
+	return time.Duration(parsed.LeaseDuration) * time.Second, nil
+}
+
+// stringFields converts decoded JSON values to strings, keeping numbers and
+// booleans in their JSON form
+func stringFields(values map[string]any) map[string]string {
+	fields := make(map[string]string, len(values))
+	for k, v := range values {
+		switch v := v.(type) {
+		case string:
+			fields[k] = v
+		case nil:
+			fields[k] = ""
+		default:
+			b, _ := json.Marshal(v)
+			fields[k] = string(b)
+		}
+	}
+	return fields
+}
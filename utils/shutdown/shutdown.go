@@ -0,0 +1,90 @@
+// Package shutdown turns SIGINT and SIGTERM into a cancelled context. Long
+// operations start their external commands with Command, so Ctrl+C or a
+// systemd stop ends mysqldump, rsync or the package manager, the operation
+// returns an error through its usual cleanup path and the process exits with
+// common.ExitInterrupted instead of dying with partial files behind.
+//
+// The context returned by Start is the one the root command executes with,
+// so commands get it from cmd.Context() and hand it down: the backup, restore,
+// migrate and install entry points take it as their first parameter and use
+// it for their queries and external commands.
+//
+// Context holds the same context globally. It is a temporary bridge for code
+// that has no ctx handed to it yet, such as remote storage, binlog reading,
+// MariaDB removal and the MaxScale and PostgreSQL installs, and should not be
+// used in new code.
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"sfDBTools/internal/logger"
+)
+
+// WaitDelay is how long a cancelled command may take to exit after SIGTERM
+// before it is killed
+const WaitDelay = 10 * time.Second
+
+var (
+	mu      sync.Mutex
+	ctx     = context.Background()
+	started bool
+)
+
+// Start installs the signal handler and returns the context it cancels. The
+// first signal cancels the context; a second one exits immediately.
+func Start() context.Context {
+	mu.Lock()
+	defer mu.Unlock()
+	if started {
+		return ctx
+	}
+	started = true
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		lg, _ := logger.Get()
+		lg.Warn("Signal received, stopping", logger.String("signal", sig.String()))
+		fmt.Fprintf(os.Stderr, "\n%s received, stopping (press Ctrl+C again to exit immediately)\n", sig)
+		cancel()
+
+		sig = <-signals
+		lg.Warn("Second signal received, exiting immediately", logger.String("signal", sig.String()))
+		os.Exit(130)
+	}()
+	return ctx
+}
+
+// Context is cancelled when the process is asked to stop. It is never
+// cancelled before Start.
+//
+// Deprecated: temporary bridge for code that is not given a ctx yet. Take a
+// context.Context parameter and pass cmd.Context() down instead.
+func Context() context.Context {
+	mu.Lock()
+	defer mu.Unlock()
+	return ctx
+}
+
+// Command is exec.CommandContext with a gentler stop: when c is cancelled
+// the command gets SIGTERM, and is killed when it has not exited within
+// WaitDelay
+func Command(c context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(c, name, args...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = WaitDelay
+	return cmd
+}
@@ -0,0 +1,35 @@
+package system
+
+import "strings"
+
+// MariaDBArchitectures are the CPU architectures, in uname -m form, the
+// MariaDB repositories ship packages for
+var MariaDBArchitectures = []string{"x86_64", "aarch64", "ppc64le", "s390x"}
+
+// NormalizeArch maps a uname -m or GOARCH name to the uname -m form used in
+// rpm repository paths (x86_64, aarch64, ...)
+func NormalizeArch(arch string) string {
+	switch arch = strings.ToLower(strings.TrimSpace(arch)); arch {
+	case "amd64", "x64":
+		return "x86_64"
+	case "arm64", "armv8", "armv8l":
+		return "aarch64"
+	case "ppc64el":
+		return "ppc64le"
+	}
+	return arch
+}
+
+// DebianArch returns the Debian name of arch (amd64, arm64, ...) as used in
+// the [arch=...] option of apt sources
+func DebianArch(arch string) string {
+	switch NormalizeArch(arch) {
+	case "x86_64":
+		return "amd64"
+	case "aarch64":
+		return "arm64"
+	case "ppc64le":
+		return "ppc64el"
+	}
+	return arch
+}
@@ -2,6 +2,8 @@ package system
 
 import (
 	"fmt"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
 
@@ -16,6 +18,7 @@ type OSInfo struct {
 	Name        string `json:"name"`
 	Version     string `json:"version"`
 	PackageType string `json:"package_type"`
+	Arch        string `json:"arch"` // CPU architecture as uname -m reports it, e.g. x86_64 or aarch64
 }
 
 // DetectOS detects the current operating system and returns basic info
@@ -46,13 +49,18 @@ func detectOSOnce() (*OSInfo, error) {
 			Name:        info.Platform,
 			Version:     info.PlatformVersion,
 			PackageType: getPackageType(osID),
+			Arch:        NormalizeArch(info.KernelArch),
+		}
+		if cachedOSInfo.Arch == "" {
+			cachedOSInfo.Arch = NormalizeArch(runtime.GOARCH)
 		}
 
 		lg.Info("OS detected",
 			logger.String("id", cachedOSInfo.ID),
 			logger.String("name", cachedOSInfo.Name),
 			logger.String("version", cachedOSInfo.Version),
-			logger.String("package_type", cachedOSInfo.PackageType))
+			logger.String("package_type", cachedOSInfo.PackageType),
+			logger.String("arch", cachedOSInfo.Arch))
 	})
 
 	return cachedOSInfo, detectErr
@@ -69,17 +77,29 @@ func normalizeOSID(platform string) string {
 		return "rocky"
 	case "alma linux", "almalinux":
 		return "almalinux"
+	case "amazon", "amzn", "amazon linux":
+		return "amzn"
+	case "sles", "sled", "suse", "suse linux enterprise server":
+		return "sles"
+	case "opensuse", "opensuse-leap", "opensuse-tumbleweed":
+		return "opensuse"
 	default:
 		return osID
 	}
 }
 
+// IsSUSE reports whether the OS is SLES or openSUSE, which install rpm
+// packages with zypper
+func (o *OSInfo) IsSUSE() bool {
+	return o.ID == "sles" || o.ID == "opensuse"
+}
+
 // getPackageType returns package manager type based on OS
 func getPackageType(osID string) string {
 	switch osID {
 	case "ubuntu", "debian":
 		return "deb"
-	case "centos", "rhel", "rocky", "almalinux", "fedora":
+	case "centos", "rhel", "rocky", "almalinux", "fedora", "amzn", "sles", "opensuse":
 		return "rpm"
 	case "arch", "manjaro":
 		return "pacman"
@@ -106,6 +126,9 @@ func ValidateOperatingSystem() error {
 		"rocky":     true,
 		"almalinux": true,
 		"debian":    true,
+		"amzn":      true,
+		"sles":      true,
+		"opensuse":  true,
 	}
 
 	if !supportedOS[osInfo.ID] {
@@ -113,6 +136,11 @@ func ValidateOperatingSystem() error {
 		return fmt.Errorf("unsupported operating system: %s", osInfo.ID)
 	}
 
-	lg.Info("Operating system is supported", logger.String("os", osInfo.ID))
+	if !slices.Contains(MariaDBArchitectures, osInfo.Arch) {
+		lg.Error("Unsupported CPU architecture", logger.String("detected_arch", osInfo.Arch))
+		return fmt.Errorf("unsupported CPU architecture: %s (MariaDB packages exist for x86_64, aarch64, ppc64le and s390x)", osInfo.Arch)
+	}
+
+	lg.Info("Operating system is supported", logger.String("os", osInfo.ID), logger.String("arch", osInfo.Arch))
 	return nil
 }
@@ -2,10 +2,15 @@ package system
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os/exec"
+	"sort"
 	"strings"
+	"sync"
 
+	"sfDBTools/utils/shutdown"
 	"sfDBTools/utils/terminal"
 )
 
@@ -17,24 +22,38 @@ type PackageManager interface {
 	GetInstalledPackages() ([]string, error)
 	UpdateCache() error
 	Upgrade() error
+	UpgradePackages(packages []string) error
+	GetInstalledVersions() (map[string]string, error)
+	InstallVersions(versions map[string]string) error
 }
 
 // packageManager implements PackageManager interface
 type packageManager struct {
-	packageTool string // yum, apt, dnf, etc.
+	packageTool string          // yum, apt, dnf, zypper
+	ctx         context.Context // cancels running package commands
 }
 
-// NewPackageManager creates a new package manager based on the system
+// NewPackageManager creates a new package manager based on the system.
+// Its commands are bound to the process-wide shutdown.Context(); callers that
+// have a command context should use NewPackageManagerContext instead.
 func NewPackageManager() PackageManager {
+	return NewPackageManagerContext(shutdown.Context())
+}
+
+// NewPackageManagerContext creates a package manager whose commands are
+// killed when ctx is cancelled
+func NewPackageManagerContext(ctx context.Context) PackageManager {
 	// Detect package manager
 	if isCommandAvailable("yum") {
-		return &packageManager{packageTool: "yum"}
+		return &packageManager{packageTool: "yum", ctx: ctx}
 	} else if isCommandAvailable("apt") {
-		return &packageManager{packageTool: "apt"}
+		return &packageManager{packageTool: "apt", ctx: ctx}
 	} else if isCommandAvailable("dnf") {
-		return &packageManager{packageTool: "dnf"}
+		return &packageManager{packageTool: "dnf", ctx: ctx}
+	} else if isCommandAvailable("zypper") {
+		return &packageManager{packageTool: "zypper", ctx: ctx}
 	}
-	return &packageManager{packageTool: "unknown"}
+	return &packageManager{packageTool: "unknown", ctx: ctx}
 }
 
 // Install installs the specified packages
@@ -47,13 +66,16 @@ func (pm *packageManager) Install(packages []string) error {
 	switch pm.packageTool {
 	case "yum":
 		args := append([]string{"install", "-y"}, packages...)
-		cmd = exec.Command("yum", args...)
+		cmd = shutdown.Command(pm.ctx, "yum", args...)
 	case "apt":
 		args := append([]string{"install", "-y"}, packages...)
-		cmd = exec.Command("apt", args...)
+		cmd = shutdown.Command(pm.ctx, "apt", args...)
 	case "dnf":
 		args := append([]string{"install", "-y"}, packages...)
-		cmd = exec.Command("dnf", args...)
+		cmd = shutdown.Command(pm.ctx, "dnf", args...)
+	case "zypper":
+		args := append([]string{"--non-interactive", "install"}, packages...)
+		cmd = shutdown.Command(pm.ctx, "zypper", args...)
 	default:
 		return fmt.Errorf("unsupported package manager")
 	}
@@ -105,13 +127,16 @@ func (pm *packageManager) Remove(packages []string) error {
 	switch pm.packageTool {
 	case "yum":
 		args := append([]string{"remove", "-y"}, packages...)
-		cmd = exec.Command("yum", args...)
+		cmd = shutdown.Command(pm.ctx, "yum", args...)
 	case "apt":
 		args := append([]string{"remove", "-y"}, packages...)
-		cmd = exec.Command("apt", args...)
+		cmd = shutdown.Command(pm.ctx, "apt", args...)
 	case "dnf":
 		args := append([]string{"remove", "-y"}, packages...)
-		cmd = exec.Command("dnf", args...)
+		cmd = shutdown.Command(pm.ctx, "dnf", args...)
+	case "zypper":
+		args := append([]string{"--non-interactive", "remove"}, packages...)
+		cmd = shutdown.Command(pm.ctx, "zypper", args...)
 	default:
 		return fmt.Errorf("unsupported package manager")
 	}
@@ -161,7 +186,7 @@ func (pm *packageManager) IsInstalled(pkg string) bool {
 		cmd = exec.Command("rpm", "-q", pkg)
 	case "apt":
 		cmd = exec.Command("dpkg", "-l", pkg)
-	case "dnf":
+	case "dnf", "zypper":
 		cmd = exec.Command("rpm", "-q", pkg)
 	default:
 		return false
@@ -177,7 +202,7 @@ func (pm *packageManager) GetInstalledPackages() ([]string, error) {
 	var packages []string
 
 	switch pm.packageTool {
-	case "yum", "dnf":
+	case "yum", "dnf", "zypper":
 		cmd = exec.Command("rpm", "-qa", "--queryformat", "%{NAME}\n")
 	case "apt":
 		cmd = exec.Command("dpkg", "-l")
@@ -216,11 +241,14 @@ func (pm *packageManager) UpdateCache() error {
 	var cmd *exec.Cmd
 	switch pm.packageTool {
 	case "yum":
-		cmd = exec.Command("yum", "makecache")
+		cmd = shutdown.Command(pm.ctx, "yum", "makecache")
 	case "apt":
-		cmd = exec.Command("apt", "update")
+		cmd = shutdown.Command(pm.ctx, "apt", "update")
 	case "dnf":
-		cmd = exec.Command("dnf", "makecache")
+		cmd = shutdown.Command(pm.ctx, "dnf", "makecache")
+	case "zypper":
+		// Repositories added by mariadb_repo_setup bring their own signing key
+		cmd = shutdown.Command(pm.ctx, "zypper", "--non-interactive", "--gpg-auto-import-keys", "refresh")
 	default:
 		return fmt.Errorf("unsupported package manager: %s", pm.packageTool)
 	}
@@ -269,12 +297,14 @@ func (pm *packageManager) Upgrade() error {
 	switch pm.packageTool {
 	case "yum":
 		// yum update will update packages
-		cmd = exec.Command("yum", "update", "-y")
+		cmd = shutdown.Command(pm.ctx, "yum", "update", "-y")
 	case "apt":
 		// apt upgrade with -y to auto confirm
-		cmd = exec.Command("apt", "upgrade", "-y")
+		cmd = shutdown.Command(pm.ctx, "apt", "upgrade", "-y")
 	case "dnf":
-		cmd = exec.Command("dnf", "upgrade", "-y")
+		cmd = shutdown.Command(pm.ctx, "dnf", "upgrade", "-y")
+	case "zypper":
+		cmd = shutdown.Command(pm.ctx, "zypper", "--non-interactive", "update")
 	default:
 		return fmt.Errorf("unsupported package manager: %s", pm.packageTool)
 	}
@@ -317,6 +347,142 @@ func (pm *packageManager) Upgrade() error {
 	return nil
 }
 
+// UpgradePackages upgrades only the given installed packages to the newest
+// version the configured repositories offer
+func (pm *packageManager) UpgradePackages(packages []string) error {
+	if len(packages) == 0 {
+		return nil
+	}
+
+	var cmd *exec.Cmd
+	switch pm.packageTool {
+	case "yum":
+		cmd = shutdown.Command(pm.ctx, "yum", append([]string{"update", "-y"}, packages...)...)
+	case "apt":
+		cmd = shutdown.Command(pm.ctx, "apt", append([]string{"install", "-y", "--only-upgrade"}, packages...)...)
+	case "dnf":
+		cmd = shutdown.Command(pm.ctx, "dnf", append([]string{"upgrade", "-y"}, packages...)...)
+	case "zypper":
+		cmd = shutdown.Command(pm.ctx, "zypper", append([]string{"--non-interactive", "update"}, packages...)...)
+	default:
+		return fmt.Errorf("unsupported package manager: %s", pm.packageTool)
+	}
+
+	if err := streamCommand(cmd); err != nil {
+		return fmt.Errorf("failed to upgrade packages %v: %w", packages, err)
+	}
+	return nil
+}
+
+// GetInstalledVersions returns the installed MariaDB/MySQL and Galera packages
+// with their exact versions, in the form InstallVersions accepts
+func (pm *packageManager) GetInstalledVersions() (map[string]string, error) {
+	var cmd *exec.Cmd
+	switch pm.packageTool {
+	case "yum", "dnf", "zypper":
+		cmd = exec.Command("rpm", "-qa", "--queryformat", "%{NAME} %{VERSION}-%{RELEASE}\n")
+	case "apt":
+		cmd = exec.Command("dpkg-query", "-W", "-f", "${db:Status-Abbrev} ${Package} ${Version}\n")
+	default:
+		return nil, fmt.Errorf("unsupported package manager: %s", pm.packageTool)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installed package versions: %w", err)
+	}
+
+	versions := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if pm.packageTool == "apt" {
+			// Only packages that are fully installed ("ii")
+			if len(fields) != 3 || fields[0] != "ii" {
+				continue
+			}
+			fields = fields[1:]
+		}
+		if len(fields) != 2 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if strings.Contains(name, "mariadb") || strings.Contains(name, "mysql") || strings.HasPrefix(name, "galera") {
+			versions[fields[0]] = fields[1]
+		}
+	}
+	return versions, nil
+}
+
+// InstallVersions installs the packages at exactly the given versions,
+// downgrading packages that are installed at a newer version
+func (pm *packageManager) InstallVersions(versions map[string]string) error {
+	if len(versions) == 0 {
+		return nil
+	}
+
+	specs := make([]string, 0, len(versions))
+	for pkg, version := range versions {
+		if pm.packageTool == "apt" {
+			specs = append(specs, pkg+"="+version)
+		} else {
+			specs = append(specs, pkg+"-"+version)
+		}
+	}
+	sort.Strings(specs)
+
+	var cmd *exec.Cmd
+	switch pm.packageTool {
+	case "yum":
+		cmd = shutdown.Command(pm.ctx, "yum", append([]string{"downgrade", "-y"}, specs...)...)
+	case "apt":
+		cmd = shutdown.Command(pm.ctx, "apt", append([]string{"install", "-y", "--allow-downgrades"}, specs...)...)
+	case "dnf":
+		// dnf install replaces an installed package with the exact version asked for
+		cmd = shutdown.Command(pm.ctx, "dnf", append([]string{"install", "-y"}, specs...)...)
+	case "zypper":
+		cmd = shutdown.Command(pm.ctx, "zypper", append([]string{"--non-interactive", "install", "--oldpackage"}, specs...)...)
+	default:
+		return fmt.Errorf("unsupported package manager: %s", pm.packageTool)
+	}
+
+	if err := streamCommand(cmd); err != nil {
+		return fmt.Errorf("failed to install packages %v: %w", specs, err)
+	}
+	return nil
+}
+
+// streamCommand runs cmd, printing its stdout and stderr through
+// terminal.SafePrintln so an active spinner is paused/resumed properly
+func streamCommand(cmd *exec.Cmd) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to get stderr pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", cmd.Path, err)
+	}
+
+	var wg sync.WaitGroup
+	for _, r := range []io.Reader{stdout, stderr} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scanner := bufio.NewScanner(r)
+			for scanner.Scan() {
+				terminal.SafePrintln(scanner.Text())
+			}
+		}()
+	}
+	// Pipes must be drained before Wait closes them
+	wg.Wait()
+	return cmd.Wait()
+}
+
 // isCommandAvailable checks if a command is available in PATH
 func isCommandAvailable(name string) bool {
 	cmd := exec.Command("which", name)
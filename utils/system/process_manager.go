@@ -6,6 +6,8 @@ import (
 	"os"
 	"os/exec"
 	"time"
+
+	"sfDBTools/utils/shutdown"
 )
 
 // ProcessManager interface provides abstraction for process execution
@@ -29,7 +31,7 @@ func NewProcessManager() ProcessManager {
 
 // ExecuteWithTimeout executes a command with a timeout
 func (pm *processManager) ExecuteWithTimeout(command string, args []string, timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(shutdown.Context(), timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, command, args...)
@@ -48,7 +50,7 @@ func (pm *processManager) ExecuteWithTimeout(command string, args []string, time
 
 // Execute executes a command without timeout
 func (pm *processManager) Execute(command string, args []string) error {
-	cmd := exec.Command(command, args...)
+	cmd := shutdown.Command(shutdown.Context(), command, args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("command %s failed: %w\nOutput: %s", command, err, string(output))
@@ -58,7 +60,7 @@ func (pm *processManager) Execute(command string, args []string) error {
 
 // ExecuteWithOutput executes a command and returns its output
 func (pm *processManager) ExecuteWithOutput(command string, args []string) (string, error) {
-	cmd := exec.Command(command, args...)
+	cmd := shutdown.Command(shutdown.Context(), command, args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("command %s failed: %w\nOutput: %s", command, err, string(output))
@@ -70,7 +72,7 @@ func (pm *processManager) ExecuteWithOutput(command string, args []string) (stri
 // current process. This allows interactive tools (prompts) to be used. The command
 // is run with a context that times out after the provided duration.
 func (pm *processManager) ExecuteInteractiveWithTimeout(command string, args []string, timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(shutdown.Context(), timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, command, args...)
@@ -0,0 +1,137 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/shutdown"
+)
+
+// sd_notify states understood by systemd
+const (
+	NotifyReady    = "READY=1"
+	NotifyStopping = "STOPPING=1"
+	NotifyWatchdog = "WATCHDOG=1"
+)
+
+// SdNotify sends state to the systemd notification socket. It returns false
+// without error when the process was not started by systemd with
+// NOTIFY_SOCKET set, so callers can notify unconditionally.
+func SdNotify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// A leading @ denotes an abstract socket
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to systemd notify socket: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to notify systemd: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the watchdog timeout systemd expects pings within,
+// or zero when the watchdog is not enabled for this process
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// Daemon supervises a long-running command under systemd: it reports
+// readiness, keeps the watchdog fed while the command's loop is alive and
+// turns SIGTERM/SIGINT into a cancelled context for graceful shutdown.
+type Daemon struct {
+	name     string
+	ctx      context.Context
+	cancel   context.CancelFunc
+	alive    chan struct{}
+	watchdog time.Duration
+}
+
+// NewDaemon starts signal handling for a daemon mode. The returned context is
+// cancelled on SIGTERM or SIGINT.
+func NewDaemon(name string) *Daemon {
+	ctx, cancel := context.WithCancel(shutdown.Context())
+	return &Daemon{
+		name:     name,
+		ctx:      ctx,
+		cancel:   cancel,
+		alive:    make(chan struct{}, 1),
+		watchdog: WatchdogInterval(),
+	}
+}
+
+// Context is cancelled when the daemon is asked to stop
+func (d *Daemon) Context() context.Context { return d.ctx }
+
+// Ready tells systemd startup has finished and starts feeding the watchdog.
+// With the watchdog enabled, the main loop must call Alive at least once per
+// watchdog interval or systemd restarts the service.
+func (d *Daemon) Ready(status string) {
+	lg, _ := logger.Get()
+	if _, err := SdNotify(NotifyReady + "\nSTATUS=" + status); err != nil {
+		lg.Warn("Failed to notify systemd readiness", logger.String("daemon", d.name), logger.Error(err))
+	}
+	if d.watchdog > 0 {
+		lg.Info("systemd watchdog enabled", logger.String("daemon", d.name), logger.String("interval", d.watchdog.String()))
+		go d.feedWatchdog()
+	}
+}
+
+// Alive marks the main loop as making progress
+func (d *Daemon) Alive() {
+	select {
+	case d.alive <- struct{}{}:
+	default:
+	}
+}
+
+// feedWatchdog pings systemd at half the watchdog interval, but only while
+// Alive was called since the previous ping, so a stuck loop gets restarted
+func (d *Daemon) feedWatchdog() {
+	ticker := time.NewTicker(d.watchdog / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			select {
+			case <-d.alive:
+				SdNotify(NotifyWatchdog)
+			default:
+			}
+		}
+	}
+}
+
+// WatchdogTick returns how often the main loop should call Alive so the
+// watchdog is fed even when its own work interval is longer; zero when the
+// watchdog is disabled
+func (d *Daemon) WatchdogTick() time.Duration {
+	return d.watchdog / 4
+}
+
+// Stop tells systemd the daemon is shutting down and releases signal handling
+func (d *Daemon) Stop() {
+	SdNotify(NotifyStopping)
+	d.cancel()
+}
@@ -17,6 +17,7 @@ type ServiceManager interface {
 	IsActive(name string) bool
 	IsEnabled(name string) bool
 	GetStatus(name string) (ServiceStatus, error)
+	DaemonReload() error
 }
 
 // ServiceStatus represents the status of a service
@@ -126,3 +127,13 @@ func (sm *serviceManager) GetStatus(name string) (ServiceStatus, error) {
 	}
 	return status, nil
 }
+
+// DaemonReload makes systemd re-read unit files after they were added, changed or removed
+func (sm *serviceManager) DaemonReload() error {
+	cmd := exec.Command("systemctl", "daemon-reload")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to reload systemd units: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
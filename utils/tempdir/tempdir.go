@@ -0,0 +1,74 @@
+// Package tempdir holds the directory used for temporary files: package
+// downloads, intermediate dump files and restore state. It is configured once
+// at startup from --temp-dir, SFDB_TEMP_DIR or general.temp_dir and exported
+// as TMPDIR, so os.TempDir and child processes use the same place.
+package tempdir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sfDBTools/utils/common"
+	"sfDBTools/utils/disk"
+)
+
+// DefaultMinFree is the free space required when general.temp_min_free is not set
+const DefaultMinFree int64 = 1 << 30
+
+var minFree = DefaultMinFree
+
+// Configure sets the temporary directory and the free space heavy operations
+// require in it. An empty dir keeps the system default (TMPDIR or /tmp).
+func Configure(dir string, minFreeBytes int64) error {
+	if minFreeBytes > 0 {
+		minFree = minFreeBytes
+	}
+	if dir == "" {
+		return nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid temp dir %q: %w", dir, err)
+	}
+	return os.Setenv("TMPDIR", abs)
+}
+
+// Dir returns the temporary directory
+func Dir() string {
+	return os.TempDir()
+}
+
+// Check verifies that the temporary directory exists or can be created, is
+// writable and has at least need bytes free, and never less than the
+// configured minimum. operation names the caller in the error.
+func Check(operation string, need int64) error {
+	return CheckDir(operation, Dir(), need)
+}
+
+// CheckDir is Check for a directory chosen by the operation itself, e.g. a
+// --temp-dir flag of one command
+func CheckDir(operation, dir string, need int64) error {
+	if need < minFree {
+		need = minFree
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("%s: temp dir %s cannot be created: %w; set --temp-dir or general.temp_dir", operation, dir, err)
+	}
+	probe, err := os.CreateTemp(dir, ".sfdbtools-probe-*")
+	if err != nil {
+		return fmt.Errorf("%s: temp dir %s is not writable: %w; set --temp-dir or general.temp_dir", operation, dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	free, err := disk.GetFreeBytes(dir)
+	if err != nil {
+		return fmt.Errorf("%s: failed to read free space of temp dir %s: %w", operation, dir, err)
+	}
+	if free < need {
+		return fmt.Errorf("%s: temp dir %s has %s free, %s required; set --temp-dir or general.temp_dir to a larger filesystem",
+			operation, dir, common.FormatSize(free), common.FormatSize(need))
+	}
+	return nil
+}
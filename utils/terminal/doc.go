@@ -33,4 +33,13 @@
 //		{"Jane", "30", "Los Angeles"},
 //	}
 //	terminal.FormatTable(headers, rows)
+//
+//	// Long tables: sorted by a column, paged, or scrolled in a pager
+//	terminal.RenderTable(headers, rows, terminal.TableOptions{SortBy: "Age", PageSize: 50, Interactive: true})
+//
+//	// Prompts; with the global --yes Confirm answers yes and the others take their default
+//	if terminal.Confirm("Drop the database?", false) { ... }
+//	name, err := terminal.Input("Database name", "app", terminal.NotEmpty)
+//	i, err := terminal.Select("Backup file", files, -1)
+//	picked, err := terminal.MultiSelect("Databases", databases)
 package terminal
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package terminal
+
+import "errors"
+
+// disableEcho is not supported here; masked prompts fall back to plain input
+func disableEcho(fd int) (func(), error) {
+	return nil, errors.ErrUnsupported
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"regexp"
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/common/format"
 	"strings"
 	"sync"
 	"time"
@@ -12,8 +13,8 @@ import (
 	"github.com/olekukonko/tablewriter"
 )
 
-// Colors for terminal output
-const (
+// Colors for terminal output; empty when colors are off (see Interactive)
+var (
 	ColorReset  = "\033[0m"
 	ColorRed    = "\033[31m"
 	ColorGreen  = "\033[32m"
@@ -142,13 +143,18 @@ func (ps *ProgressSpinner) Start() {
 	ps.active = true
 	ps.mu.Unlock()
 
-	HideCursor()
-
 	// Register as active spinner so print functions can coordinate
 	spinnerMu.Lock()
 	activeSpinner = ps
 	spinnerMu.Unlock()
 
+	if !interactive {
+		// No animation in logs: the message is printed once
+		fmt.Println(ps.plainLine())
+		return
+	}
+	HideCursor()
+
 	go func() {
 		ticker := time.NewTicker(ps.interval)
 		defer ticker.Stop()
@@ -221,6 +227,21 @@ func (ps *ProgressSpinner) render() {
 	fmt.Print(line.String())
 }
 
+// plainLine is the spinner message with its prefix and suffix, without the
+// animation
+func (ps *ProgressSpinner) plainLine() string {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	line := ps.message
+	if ps.prefix != "" {
+		line = ps.prefix + " " + line
+	}
+	if ps.suffix != "" {
+		line += " " + ps.suffix
+	}
+	return line
+}
+
 // advance moves to the next animation frame
 func (ps *ProgressSpinner) advance() {
 	ps.mu.Lock()
@@ -237,6 +258,10 @@ func (ps *ProgressSpinner) Stop() {
 	}
 	ps.active = false
 	ps.mu.Unlock()
+	defer ps.unregister()
+	if !interactive {
+		return
+	}
 
 	// Signal stop
 	ps.stopChan <- true
@@ -248,8 +273,10 @@ func (ps *ProgressSpinner) Stop() {
 	fmt.Print("\r\033[K")
 	fmt.Println() // Add newline for cleaner output
 	ShowCursor()
+}
 
-	// Unregister active spinner if it's this one
+// unregister clears the active spinner if it's this one
+func (ps *ProgressSpinner) unregister() {
 	spinnerMu.Lock()
 	if activeSpinner == ps {
 		activeSpinner = nil
@@ -266,6 +293,11 @@ func (ps *ProgressSpinner) StopWithMessage(message string) {
 	}
 	ps.active = false
 	ps.mu.Unlock()
+	defer ps.unregister()
+	if !interactive {
+		fmt.Println(message)
+		return
+	}
 
 	// Signal stop
 	ps.stopChan <- true
@@ -277,21 +309,22 @@ func (ps *ProgressSpinner) StopWithMessage(message string) {
 	fmt.Print("\r\033[K")
 	fmt.Println(message)
 	ShowCursor()
-
-	// Unregister active spinner if it's this one
-	spinnerMu.Lock()
-	if activeSpinner == ps {
-		activeSpinner = nil
-	}
-	spinnerMu.Unlock()
 }
 
 // UpdateMessage updates the spinner message thread-safely
 func (ps *ProgressSpinner) UpdateMessage(message string) {
 	// Update the message under write lock then trigger an immediate redraw.
 	ps.mu.Lock()
+	changed := ps.message != message
 	ps.message = message
+	active := ps.active
 	ps.mu.Unlock()
+	if !interactive {
+		if active && changed {
+			fmt.Println(ps.plainLine())
+		}
+		return
+	}
 
 	// Trigger an immediate render in a goroutine so the new message is shown
 	// without waiting for the next ticker tick. render() will check ps.active
@@ -362,7 +395,7 @@ func (ps *ProgressSpinner) temporaryStop() {
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
 
-	if !ps.active {
+	if !ps.active || !interactive {
 		return
 	}
 
@@ -382,6 +415,9 @@ type ProgressBar struct {
 	current int
 	width   int
 	message string
+	bytes   bool      // total and current are byte counts
+	started time.Time // start of a byte transfer, for its rate and ETA
+	printed int       // steps of progressLineStep percent printed when not interactive
 }
 
 // NewProgressBar creates a new progress bar
@@ -404,6 +440,20 @@ func NewProgressBar(total int, message string) *ProgressBar {
 	}
 }
 
+// NewTransferProgressBar creates a progress bar for a byte transfer that
+// shows the bytes copied, the transfer rate and the estimated time left
+func NewTransferProgressBar(total int64, message string) *ProgressBar {
+	pb := NewProgressBar(int(total), message)
+	// The size, rate and ETA need more room than the plain counter
+	pb.width -= 30
+	if pb.width < 10 {
+		pb.width = 10
+	}
+	pb.bytes = true
+	pb.started = time.Now()
+	return pb
+}
+
 // Update updates the progress bar
 func (pb *ProgressBar) Update(current int) {
 	lg, _ := logger.Get()
@@ -414,12 +464,30 @@ func (pb *ProgressBar) Update(current int) {
 	}
 
 	percentage := float64(pb.current) / float64(pb.total) * 100
+	if !interactive {
+		// One line per step instead of redrawing the bar
+		step := int(percentage)/progressLineStep + 1
+		if step <= pb.printed {
+			return
+		}
+		pb.printed = step
+		if pb.bytes {
+			fmt.Printf("%s %.0f%% %s\n", pb.message, percentage, pb.transferStats())
+		} else {
+			fmt.Printf("%s %.0f%% (%d/%d)\n", pb.message, percentage, pb.current, pb.total)
+		}
+		return
+	}
 	filled := int(float64(pb.width) * float64(pb.current) / float64(pb.total))
 
 	ClearCurrentLine()
 
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", pb.width-filled)
-	fmt.Printf("%s [%s] %.1f%% (%d/%d)", pb.message, bar, percentage, pb.current, pb.total)
+	if pb.bytes {
+		fmt.Printf("%s [%s] %.1f%% %s", pb.message, bar, percentage, pb.transferStats())
+	} else {
+		fmt.Printf("%s [%s] %.1f%% (%d/%d)", pb.message, bar, percentage, pb.current, pb.total)
+	}
 
 	lg.Debug("Progress bar updated",
 		logger.Int("current", pb.current),
@@ -427,10 +495,28 @@ func (pb *ProgressBar) Update(current int) {
 		logger.Float64("percentage", percentage))
 }
 
+// transferStats formats "copied/total rate ETA" of a byte transfer
+func (pb *ProgressBar) transferStats() string {
+	stats := format.FormatSizeWithPrecision(int64(pb.current), 1) + "/" + format.FormatSizeWithPrecision(int64(pb.total), 1)
+	elapsed := time.Since(pb.started)
+	if elapsed < time.Second || pb.current == 0 {
+		return stats
+	}
+	rate := float64(pb.current) / elapsed.Seconds()
+	stats += " " + format.FormatSizeWithPrecision(int64(rate), 1) + "/s"
+	if pb.current < pb.total {
+		eta := time.Duration(float64(pb.total-pb.current) / rate * float64(time.Second))
+		stats += " ETA " + format.FormatHMS(eta, false)
+	}
+	return stats
+}
+
 // Finish completes the progress bar
 func (pb *ProgressBar) Finish() {
 	pb.Update(pb.total)
-	fmt.Println() // Move to next line
+	if interactive {
+		fmt.Println() // Move to next line
+	}
 }
 
 // ColorText applies color to text
@@ -448,6 +534,9 @@ func PrintColoredText(text, color string) {
 
 // PrintColoredLine prints a line with the specified color
 func PrintColoredLine(text, color string) {
+	if printThroughMulti(ColorText(text, color)) {
+		return
+	}
 	s := pauseActiveSpinner()
 	fmt.Println(ColorText(text, color))
 	resumeSpinner(s)
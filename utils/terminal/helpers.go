@@ -1,10 +1,10 @@
 package terminal
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"os"
 	"sfDBTools/internal/config"
+	"sfDBTools/utils/i18n"
 	"strings"
 )
 
@@ -81,20 +81,7 @@ func ClearLastLines(n int) error {
 
 // ConfirmAndClear shows a confirmation dialog then clears screen
 func ConfirmAndClear(question string) (bool, error) {
-	// Pause any active spinner so prompt is not overwritten
-	s := pauseActiveSpinner()
-	defer resumeSpinner(s)
-
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("%s [y/N]: ", question)
-
-	response, err := reader.ReadString('\n')
-	if err != nil {
-		return false, err
-	}
-
-	response = strings.TrimSpace(strings.ToLower(response))
-	confirmed := response == "y" || response == "yes"
+	confirmed := Confirm(question, false)
 
 	if err := ClearScreen(); err != nil {
 		return confirmed, err
@@ -118,10 +105,9 @@ func ShowMenuAndClear(title string, options []string) (int, error) {
 	s := pauseActiveSpinner()
 	defer resumeSpinner(s)
 
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\nSelect option (1-%d): ", len(options))
+	fmt.Print("\n" + i18n.T("prompt.select_option", len(options)))
 
-	choice, err := reader.ReadString('\n')
+	choice, err := ReadLine()
 	if err != nil {
 		return 0, err
 	}
@@ -132,12 +118,12 @@ func ShowMenuAndClear(title string, options []string) (int, error) {
 	var selected int
 	if _, err := fmt.Sscanf(choice, "%d", &selected); err != nil {
 		ClearScreen()
-		return 0, fmt.Errorf("invalid selection: %s", choice)
+		return 0, errors.New(i18n.T("prompt.invalid_selection", choice))
 	}
 
 	if selected < 1 || selected > len(options) {
 		ClearScreen()
-		return 0, fmt.Errorf("selection out of range: %d", selected)
+		return 0, errors.New(i18n.T("prompt.selection_out_of_range", selected))
 	}
 
 	if err := ClearScreen(); err != nil {
@@ -178,11 +164,11 @@ func (im *InteractiveMenu) Show() error {
 		ClearAndShowHeader(im.Title)
 
 		// Add exit option
-		allOptions := append(im.Options, "Exit")
+		allOptions := append(im.Options, i18n.T("menu.exit"))
 
 		selected, err := ShowMenuAndClear("", allOptions)
 		if err != nil {
-			PrintError(fmt.Sprintf("Menu error: %v", err))
+			PrintError(i18n.T("menu.error", err))
 			WaitForEnter()
 			continue
 		}
@@ -198,7 +184,7 @@ func (im *InteractiveMenu) Show() error {
 		// Execute selected option
 		if im.OnSelect != nil {
 			if err := im.OnSelect(selected); err != nil {
-				PrintError(fmt.Sprintf("Action error: %v", err))
+				PrintError(i18n.T("menu.action_error", err))
 				WaitForEnter()
 			}
 		}
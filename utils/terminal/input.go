@@ -1,46 +1,54 @@
 package terminal
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"strings"
 	"syscall"
 
-	"golang.org/x/term"
+	"sfDBTools/utils/i18n"
 )
 
-// AskYesNo prompts user for yes/no input with default value
+// AskYesNo prompts user for yes/no input with default value. Unlike Confirm it
+// asks for a setting rather than permission, so --yes takes the default.
 func AskYesNo(question string, defaultValue bool) bool {
+	if assumeYes {
+		answer := i18n.T("prompt.no")
+		if defaultValue {
+			answer = i18n.T("prompt.yes")
+		}
+		fmt.Println(i18n.T("prompt.assumed", question, answer))
+		return defaultValue
+	}
+
 	// Show default in brackets like AskString
 	if defaultValue {
-		fmt.Printf("%s [Y/n]: ", question)
+		fmt.Printf("%s %s: ", question, i18n.T("prompt.yes_no.default_yes"))
 	} else {
-		fmt.Printf("%s [y/N]: ", question)
+		fmt.Printf("%s %s: ", question, i18n.T("prompt.yes_no.default_no"))
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	response := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	response := readAnswer()
 
 	if response == "" {
 		return defaultValue
 	}
 
-	return response == "y" || response == "yes"
+	return i18n.IsYes(response)
 }
 
-// AskString prompts user for string input with default value
+// AskString prompts user for string input with default value; --yes takes the default
 func AskString(question, defaultValue string) string {
+	if assumeYes {
+		fmt.Println(i18n.T("prompt.assumed", question, defaultValue))
+		return defaultValue
+	}
 	if defaultValue != "" {
 		fmt.Printf("%s [%s]: ", question, defaultValue)
 	} else {
 		fmt.Printf("%s: ", question)
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	response := strings.TrimSpace(scanner.Text())
+	response := readAnswer()
 
 	if response == "" {
 		return defaultValue
@@ -52,8 +60,13 @@ func AskString(question, defaultValue string) string {
 // AskInt prompts user for integer input with default value and validation.
 // If the user enters an empty string, the defaultValue is returned.
 // If the user enters a non-integer value, the prompt repeats until a valid integer
-// or empty input is provided.
+// or empty input is provided. --yes takes the default.
 func AskInt(question string, defaultValue int) int {
+	if assumeYes {
+		fmt.Println(i18n.T("prompt.assumed", question, defaultValue))
+		return defaultValue
+	}
+
 	defaultStr := ""
 	if defaultValue != 0 {
 		defaultStr = fmt.Sprintf("%d", defaultValue)
@@ -66,9 +79,12 @@ func AskInt(question string, defaultValue int) int {
 			fmt.Printf("%s: ", question)
 		}
 
-		scanner := bufio.NewScanner(os.Stdin)
-		scanner.Scan()
-		response := strings.TrimSpace(scanner.Text())
+		text, err := ReadLine()
+		abortPrompt(err)
+		if err == ErrPromptTimeout {
+			return defaultValue
+		}
+		response := strings.TrimSpace(text)
 
 		if response == "" {
 			return defaultValue
@@ -76,13 +92,13 @@ func AskInt(question string, defaultValue int) int {
 
 		// try parse int
 		var v int
-		_, err := fmt.Sscanf(response, "%d", &v)
+		_, err = fmt.Sscanf(response, "%d", &v)
 		if err == nil {
 			return v
 		}
 
 		// invalid int, show an error and repeat
-		fmt.Println("Invalid integer, please try again.")
+		fmt.Println(i18n.T("prompt.invalid_integer"))
 	}
 }
 
@@ -115,8 +131,12 @@ func AskPassword(question, defaultValue string) string {
 		fmt.Printf("%s: ", question)
 	}
 
-	passwordBytes, err := term.ReadPassword(int(syscall.Stdin))
+	passwordBytes, err := readPassword()
 	fmt.Println()
+	abortPrompt(err)
+	if err == ErrPromptTimeout {
+		return defaultValue
+	}
 	if err != nil {
 		// Fallback to unmasked input if terminal masking isn't available
 		return AskString(question, defaultValue)
@@ -128,3 +148,21 @@ func AskPassword(question, defaultValue string) string {
 	}
 	return password
 }
+
+// readPassword reads a masked password, honoring the configured prompt
+// timeout. It goes through ReadLine like every other prompt, so a read that
+// outlives its timeout is handed to the next prompt instead of racing it for
+// input; only echo is switched off while the password is typed. The terminal
+// is restored before the timeout reaches the caller.
+func readPassword() ([]byte, error) {
+	restore, err := disableEcho(int(syscall.Stdin))
+	if err != nil {
+		return nil, err
+	}
+	text, err := ReadLine()
+	restore()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimRight(text, "\r\n")), nil
+}
@@ -0,0 +1,296 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"sfDBTools/utils/common/format"
+)
+
+// multiRedraw is how often a MultiProgress redraws its running items
+const multiRedraw = 100 * time.Millisecond
+
+// activeMulti is the running MultiProgress; print functions write through it
+// so their lines land above the bars instead of inside them
+var activeMulti *MultiProgress
+
+// MultiProgress shows one progress line per concurrent operation, e.g. one
+// per database of a parallel backup. The lines of running items are redrawn
+// in place at the bottom; finished items and anything printed meanwhile
+// scroll up above them. When stdout is not a terminal every start, every
+// progressLineStep percent and every finish is printed as a plain line.
+type MultiProgress struct {
+	mu      sync.Mutex
+	items   []*ProgressItem
+	drawn   int // lines of the live area on screen
+	frame   int
+	partial []byte // unterminated output of Writer
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// ProgressItem is one line of a MultiProgress. With a total it is drawn as a
+// bar, without as a spinner.
+type ProgressItem struct {
+	m       *MultiProgress
+	name    string
+	status  string
+	current int64
+	total   int64
+	bytes   bool
+	started time.Time
+	printed int // steps of progressLineStep percent printed when not interactive
+}
+
+// NewMultiProgress creates a MultiProgress; call Start before adding items
+func NewMultiProgress() *MultiProgress {
+	return &MultiProgress{}
+}
+
+// Start begins redrawing and routes the print functions of this package
+// through the MultiProgress until Stop
+func (m *MultiProgress) Start() {
+	spinnerMu.Lock()
+	activeMulti = m
+	spinnerMu.Unlock()
+	if !interactive {
+		return
+	}
+	HideCursor()
+	m.stop, m.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(multiRedraw)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.mu.Lock()
+				m.frame++
+				m.redraw(nil)
+				m.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// Stop draws the final state, leaves the cursor below it and restores the
+// normal print functions
+func (m *MultiProgress) Stop() {
+	spinnerMu.Lock()
+	if activeMulti == m {
+		activeMulti = nil
+	}
+	spinnerMu.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		<-m.done
+		m.stop = nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.partial) > 0 {
+		m.redraw([]string{string(m.partial)})
+		m.partial = nil
+	}
+	if interactive {
+		ShowCursor()
+	}
+}
+
+// Add starts a new item. total is the amount of work, in bytes with
+// AddBytes; zero shows a spinner until the total is known.
+func (m *MultiProgress) Add(name string, total int64) *ProgressItem {
+	return m.add(name, total, false)
+}
+
+// AddBytes starts an item measuring a byte transfer, shown with size, rate
+// and ETA
+func (m *MultiProgress) AddBytes(name string, total int64) *ProgressItem {
+	return m.add(name, total, true)
+}
+
+func (m *MultiProgress) add(name string, total int64, bytes bool) *ProgressItem {
+	it := &ProgressItem{m: m, name: name, total: total, bytes: bytes, started: time.Now()}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items = append(m.items, it)
+	if !interactive {
+		fmt.Println(name + ": started")
+	}
+	return it
+}
+
+// Println prints a line above the running items
+func (m *MultiProgress) Println(text string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.redraw(strings.Split(strings.TrimRight(text, "\n"), "\n"))
+}
+
+// Write prints complete lines above the running items, so a logger can write
+// to the MultiProgress while it runs
+func (m *MultiProgress) Write(b []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.partial = append(m.partial, b...)
+	end := bytes.LastIndexByte(m.partial, '\n')
+	if end < 0 {
+		return len(b), nil
+	}
+	lines := strings.Split(string(m.partial[:end]), "\n")
+	m.partial = append([]byte(nil), m.partial[end+1:]...)
+	m.redraw(lines)
+	return len(b), nil
+}
+
+// SetStatus sets the text shown after the name, e.g. the current step
+func (it *ProgressItem) SetStatus(status string) {
+	it.m.mu.Lock()
+	defer it.m.mu.Unlock()
+	if !interactive && status != it.status {
+		fmt.Printf("%s: %s\n", it.name, status)
+	}
+	it.status = status
+}
+
+// Update sets the work done and the total, which may change as it becomes known
+func (it *ProgressItem) Update(current, total int64) {
+	it.m.mu.Lock()
+	defer it.m.mu.Unlock()
+	it.current, it.total = current, total
+	if interactive || total <= 0 {
+		return
+	}
+	percent := min(current*100/total, 100)
+	if step := int(percent)/progressLineStep + 1; step > it.printed {
+		it.printed = step
+		fmt.Printf("%s: %d%% %s\n", it.name, percent, it.amount())
+	}
+}
+
+// Done removes the item from the running ones and prints message for it
+func (it *ProgressItem) Done(message string) {
+	it.finish(ColorText("✅ "+it.name+": "+message, ColorGreen))
+}
+
+// Fail removes the item from the running ones and prints message for it as
+// an error
+func (it *ProgressItem) Fail(message string) {
+	it.finish(ColorText("❌ "+it.name+": "+message, ColorRed))
+}
+
+func (it *ProgressItem) finish(line string) {
+	m := it.m
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, other := range m.items {
+		if other == it {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			break
+		}
+	}
+	m.redraw([]string{line})
+}
+
+// redraw erases the live area, prints lines above it and draws the running
+// items again. Without a terminal only lines are printed. m.mu must be held.
+func (m *MultiProgress) redraw(lines []string) {
+	if !interactive {
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		return
+	}
+	width, _, err := GetTerminalSize()
+	if err != nil || width <= 0 {
+		width = 80
+	}
+
+	var out strings.Builder
+	// Back to the first line of the live area, erasing it
+	if m.drawn > 0 {
+		fmt.Fprintf(&out, "\r\033[%dA", m.drawn)
+	}
+	out.WriteString("\r\033[J")
+	for _, line := range lines {
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+	// A line wider than the terminal would wrap and break the line count
+	for _, it := range m.items {
+		out.WriteString(sliceDisplay(it.line(width, m.frame), 0, width-1))
+		out.WriteString(ColorReset + "\n")
+	}
+	m.drawn = len(m.items)
+	fmt.Print(out.String())
+}
+
+// line renders the item for a terminal of width
+func (it *ProgressItem) line(width, frame int) string {
+	name := it.name
+	if len([]rune(name)) > 24 {
+		name = Fit(name, 24)
+	}
+	prefix := fmt.Sprintf("%-24s", name)
+	status := it.status
+	if it.total <= 0 {
+		chars := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+		text := prefix + " " + ColorText(chars[frame%len(chars)], ColorCyan) + " " + status
+		if it.current > 0 {
+			text += " " + it.amount()
+		}
+		return text
+	}
+
+	percent := float64(min(it.current, it.total)) * 100 / float64(it.total)
+	stats := fmt.Sprintf("%5.1f%% %s", percent, it.amount())
+	barWidth := min(max(width-len(prefix)-len(stats)-len(status)-6, 10), 40)
+	filled := int(float64(barWidth) * percent / 100)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+	return fmt.Sprintf("%s [%s] %s %s", prefix, bar, stats, status)
+}
+
+// amount formats the work done: size, rate and ETA for bytes, a counter
+// otherwise
+func (it *ProgressItem) amount() string {
+	if !it.bytes {
+		if it.total > 0 {
+			return fmt.Sprintf("(%d/%d)", it.current, it.total)
+		}
+		return fmt.Sprintf("(%d)", it.current)
+	}
+	text := format.FormatSizeWithPrecision(it.current, 1)
+	if it.total > 0 {
+		text += "/" + format.FormatSizeWithPrecision(it.total, 1)
+	}
+	elapsed := time.Since(it.started)
+	if elapsed < time.Second || it.current == 0 {
+		return text
+	}
+	rate := float64(it.current) / elapsed.Seconds()
+	text += " " + format.FormatSizeWithPrecision(int64(rate), 1) + "/s"
+	if it.total > 0 && it.current < it.total {
+		eta := time.Duration(float64(it.total-it.current) / rate * float64(time.Second))
+		text += " ETA " + format.FormatHMS(eta, false)
+	}
+	return text
+}
+
+// printThroughMulti prints text above the bars of the running MultiProgress
+// and reports whether one was running
+func printThroughMulti(text string) bool {
+	spinnerMu.Lock()
+	m := activeMulti
+	spinnerMu.Unlock()
+	if m == nil {
+		return false
+	}
+	m.Println(text)
+	return true
+}
@@ -0,0 +1,246 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sfDBTools/utils/i18n"
+)
+
+// ErrPickCancelled is returned by Pick when the picker is closed with Esc or Ctrl+C
+var ErrPickCancelled = errors.New("selection cancelled")
+
+// PickOptions configures Pick
+type PickOptions struct {
+	Title string
+	Multi bool // choose any number of options; otherwise exactly one
+}
+
+// picker is the state of a Pick between two key presses
+type picker struct {
+	options []string
+	opts    PickOptions
+	query   string
+	matches []int // indexes of the options shown, best match first
+	cursor  int   // position in matches
+	top     int   // first position of matches on screen
+	chosen  map[int]bool
+	err     error // of the last query, e.g. an invalid regular expression
+}
+
+// Pick lets the user choose from options in a full-screen list that is
+// filtered while typing: by fuzzy search, or by a regular expression when
+// the filter starts with /. With Multi, Space or Tab toggles the option under
+// the cursor and Ctrl+A toggles every option shown; Enter without a toggled
+// option takes the one under the cursor. Without an interactive terminal, or
+// with --yes, Pick falls back to MultiSelect or Select. It returns the
+// indexes of the options chosen in list order.
+func Pick(options []string, opts PickOptions) ([]int, error) {
+	if len(options) == 0 {
+		return nil, errors.New(i18n.T("prompt.no_options"))
+	}
+	if assumeYes || !CanOpenScreen() {
+		if opts.Multi {
+			return MultiSelect(opts.Title, options)
+		}
+		i, err := Select(opts.Title, options, -1)
+		if err != nil {
+			return nil, err
+		}
+		return []int{i}, nil
+	}
+
+	screen, err := OpenScreen()
+	if err != nil {
+		return nil, err
+	}
+	defer screen.Close()
+
+	p := &picker{options: options, opts: opts, chosen: make(map[int]bool)}
+	p.filter()
+	keys := screen.Keys()
+	for {
+		width, height := screen.Size()
+		screen.Draw(p.render(width, height))
+		key, ok := <-keys
+		if !ok {
+			return nil, ErrPickCancelled
+		}
+		if done, err := p.handleKey(key, max(height-3, 1)); done {
+			return p.result(), err
+		}
+	}
+}
+
+// PickOne is Pick for a single option; it returns its index
+func PickOne(title string, options []string) (int, error) {
+	picked, err := Pick(options, PickOptions{Title: title})
+	if err != nil {
+		return 0, err
+	}
+	return picked[0], nil
+}
+
+// handleKey applies one key press. It reports whether the picker is done,
+// with ErrPickCancelled when it was cancelled.
+func (p *picker) handleKey(key Key, pageSize int) (bool, error) {
+	switch {
+	case key.Name == KeyCtrlC || key.Name == KeyEscape:
+		p.chosen = nil
+		return true, ErrPickCancelled
+	case key.Name == KeyEnter:
+		if len(p.matches) == 0 {
+			return false, nil
+		}
+		if !p.opts.Multi || len(p.chosen) == 0 {
+			p.chosen = map[int]bool{p.matches[p.cursor]: true}
+		}
+		return true, nil
+	case key.Name == KeyDown:
+		p.cursor++
+	case key.Name == KeyUp:
+		p.cursor--
+	case key.Name == KeyPageDown:
+		p.cursor += pageSize
+	case key.Name == KeyPageUp:
+		p.cursor -= pageSize
+	case key.Name == KeyHome:
+		p.cursor = 0
+	case key.Name == KeyEnd:
+		p.cursor = len(p.matches) - 1
+	case p.opts.Multi && (key.Rune == ' ' || key.Name == KeyTab):
+		if len(p.matches) > 0 {
+			i := p.matches[p.cursor]
+			p.toggle(i, !p.chosen[i])
+			p.cursor++
+		}
+	case p.opts.Multi && key.Name == KeyCtrlA:
+		all := true
+		for _, i := range p.matches {
+			all = all && p.chosen[i]
+		}
+		for _, i := range p.matches {
+			p.toggle(i, !all)
+		}
+	case key.Name == KeyBackspace:
+		if runes := []rune(p.query); len(runes) > 0 {
+			p.query = string(runes[:len(runes)-1])
+			p.filter()
+		}
+	case key.Name == KeyCtrlU:
+		p.query = ""
+		p.filter()
+	case key.Name == "" && key.Rune >= ' ':
+		p.query += string(key.Rune)
+		p.filter()
+	}
+	p.cursor = min(max(p.cursor, 0), max(len(p.matches)-1, 0))
+	return false, nil
+}
+
+func (p *picker) toggle(i int, on bool) {
+	if on {
+		p.chosen[i] = true
+	} else {
+		delete(p.chosen, i)
+	}
+}
+
+// filter shows the options matching the query; an invalid regular
+// expression keeps the previous list
+func (p *picker) filter() {
+	matches, err := p.find()
+	p.err = err
+	if err != nil {
+		return
+	}
+	p.matches, p.cursor, p.top = matches, 0, 0
+}
+
+// find ranks fuzzy matches by how well they match, then in list order
+func (p *picker) find() ([]int, error) {
+	query := strings.TrimSpace(p.query)
+	if query == "" {
+		return allIndexes(len(p.options)), nil
+	}
+	if strings.HasPrefix(query, "/") {
+		return findOptions(query, p.options)
+	}
+	term := strings.ToLower(query)
+	type match struct{ index, rank int }
+	var found []match
+	for i, option := range p.options {
+		if rank := matchRank(term, strings.ToLower(option)); rank >= 0 {
+			found = append(found, match{i, rank})
+		}
+	}
+	sort.SliceStable(found, func(a, b int) bool { return found[a].rank < found[b].rank })
+	matches := make([]int, len(found))
+	for i, m := range found {
+		matches[i] = m.index
+	}
+	return matches, nil
+}
+
+// result returns the chosen indexes in list order
+func (p *picker) result() []int {
+	var picked []int
+	for i := range p.chosen {
+		picked = append(picked, i)
+	}
+	sort.Ints(picked)
+	return picked
+}
+
+// render lays out the title, the filter line, the visible options and the
+// key help for a screen of width x height
+func (p *picker) render(width, height int) []string {
+	title := p.opts.Title
+	if p.opts.Multi {
+		title += fmt.Sprintf("  (%d selected)", len(p.chosen))
+	}
+	lines := []string{
+		ColorText(Fit(title, width), ColorBold),
+		Fit("> "+p.query+"▏", width),
+	}
+
+	rows := max(height-3, 1)
+	if p.cursor < p.top {
+		p.top = p.cursor
+	} else if p.cursor >= p.top+rows {
+		p.top = p.cursor - rows + 1
+	}
+	for pos := p.top; pos < min(p.top+rows, len(p.matches)); pos++ {
+		i := p.matches[pos]
+		line := "  "
+		if pos == p.cursor {
+			line = "▶ "
+		}
+		if p.opts.Multi {
+			if p.chosen[i] {
+				line += "[x] "
+			} else {
+				line += "[ ] "
+			}
+		}
+		line = Fit(line+p.options[i], width)
+		if pos == p.cursor {
+			line = ColorText(line, ColorBold+ColorCyan)
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+
+	status := fmt.Sprintf("%d/%d  type to filter, /regex  ↑↓ move  Enter done  Esc cancel", len(p.matches), len(p.options))
+	if p.opts.Multi {
+		status = fmt.Sprintf("%d/%d  type to filter, /regex  ↑↓ move  Space toggle  Ctrl+A all shown  Enter done  Esc cancel", len(p.matches), len(p.options))
+	}
+	if p.err != nil {
+		status = p.err.Error()
+	}
+	return append(lines, ColorText(Fit(status, width), ColorCyan))
+}
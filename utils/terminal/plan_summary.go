@@ -0,0 +1,107 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sfDBTools/utils/i18n"
+)
+
+// maxPlanDatabases limits how many database names are listed in a plan summary
+const maxPlanDatabases = 10
+
+// OperationPlan describes a destructive operation so it can be rendered as a
+// single confirmation summary. Each operation builds its plan from its own
+// resolved configuration; empty fields are omitted from the summary.
+type OperationPlan struct {
+	Operation         string
+	Source            string
+	Target            string
+	Databases         []string
+	Actions           []string
+	Destructive       []string
+	EstimatedDuration time.Duration // zero when no estimate is available
+	Backup            BackupSafety
+}
+
+// BackupSafety reports whether data touched by a plan can be recovered
+type BackupSafety struct {
+	Protected bool
+	Detail    string
+}
+
+// RenderPlanSummary prints the plan as a boxed table followed by a warning
+// listing the destructive actions
+func RenderPlanSummary(plan OperationPlan) {
+	PrintSubHeader(i18n.T("plan.title", plan.Operation))
+
+	rows := [][]string{}
+	if plan.Source != "" {
+		rows = append(rows, []string{i18n.T("plan.source"), plan.Source})
+	}
+	if plan.Target != "" {
+		rows = append(rows, []string{i18n.T("plan.target"), plan.Target})
+	}
+	if len(plan.Databases) > 0 {
+		rows = append(rows, []string{i18n.T("plan.databases", len(plan.Databases)), planDatabaseList(plan.Databases)})
+	}
+	if len(plan.Actions) > 0 {
+		rows = append(rows, []string{i18n.T("plan.actions"), strings.Join(plan.Actions, "\n")})
+	}
+	if len(plan.Destructive) > 0 {
+		rows = append(rows, []string{i18n.T("plan.destructive"), strings.Join(plan.Destructive, "\n")})
+	}
+	rows = append(rows, []string{i18n.T("plan.estimated_duration"), planDuration(plan.EstimatedDuration)})
+	rows = append(rows, []string{i18n.T("plan.backup_safety"), planBackupStatus(plan.Backup)})
+
+	FormatTable([]string{i18n.T("plan.item"), i18n.T("plan.details")}, rows)
+
+	if len(plan.Destructive) > 0 {
+		PrintWarning(i18n.T("plan.destructive_warning"))
+	}
+	if !plan.Backup.Protected {
+		PrintWarning(i18n.T("plan.unprotected_warning"))
+	}
+}
+
+// ConfirmPlan renders the plan summary and asks for a yes/no confirmation
+// that defaults to no; --yes confirms
+func ConfirmPlan(plan OperationPlan, question string) bool {
+	RenderPlanSummary(plan)
+	fmt.Println()
+	return Confirm(question, false)
+}
+
+func planDatabaseList(databases []string) string {
+	if len(databases) <= maxPlanDatabases {
+		return strings.Join(databases, "\n")
+	}
+	shown := strings.Join(databases[:maxPlanDatabases], "\n")
+	return shown + "\n" + i18n.T("plan.more", len(databases)-maxPlanDatabases)
+}
+
+func planDuration(d time.Duration) string {
+	if d <= 0 {
+		return i18n.T("plan.duration_unknown")
+	}
+	if d < time.Minute {
+		return i18n.T("plan.duration_under_minute")
+	}
+	d = d.Round(time.Minute)
+	if h := int(d.Hours()); h > 0 {
+		return fmt.Sprintf("~%dh %dm", h, int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("~%dm", int(d.Minutes()))
+}
+
+func planBackupStatus(b BackupSafety) string {
+	status := i18n.T("plan.not_protected")
+	if b.Protected {
+		status = i18n.T("plan.protected")
+	}
+	if b.Detail != "" {
+		status += " - " + b.Detail
+	}
+	return status
+}
@@ -0,0 +1,370 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sfDBTools/utils/i18n"
+)
+
+// ErrNoDefault is returned by Input, Select and MultiSelect under --yes when
+// the question has no default answer to take
+var ErrNoDefault = errors.New("no default answer to use with --yes")
+
+// assumeYes is set by the global --yes flag
+var assumeYes bool
+
+// selectionRange matches a range of option numbers such as "5-7"
+var selectionRange = regexp.MustCompile(`^(\d+)\s*-\s*(\d+)$`)
+
+// SetAssumeYes makes Confirm answer yes, and Input, Select and the Ask
+// functions take their default, without reading input
+func SetAssumeYes(v bool) {
+	assumeYes = v
+}
+
+// AssumeYes reports whether prompts are answered without asking (--yes)
+func AssumeYes() bool {
+	return assumeYes
+}
+
+// Validator checks an answer to Input; its error is shown and the question
+// asked again
+type Validator func(answer string) error
+
+// NotEmpty is a Validator that rejects an empty answer
+func NotEmpty(answer string) error {
+	if strings.TrimSpace(answer) == "" {
+		return errors.New(i18n.T("prompt.answer_required"))
+	}
+	return nil
+}
+
+// Confirm asks whether to go ahead. Enter and a prompt timeout take
+// defaultYes; with --yes the answer is yes without asking.
+func Confirm(question string, defaultYes bool) bool {
+	if assumeYes {
+		fmt.Println(i18n.T("prompt.assumed", question, i18n.T("prompt.yes")))
+		return true
+	}
+	s := pauseActiveSpinner()
+	defer resumeSpinner(s)
+
+	hint := i18n.T("prompt.yes_no.default_no")
+	if defaultYes {
+		hint = i18n.T("prompt.yes_no.default_yes")
+	}
+	fmt.Printf("%s %s: ", question, hint)
+	answer := readAnswer()
+	if answer == "" {
+		return defaultYes
+	}
+	return i18n.IsYes(answer)
+}
+
+// Input asks for a line of text. Enter takes defaultValue; an answer
+// rejected by validate (which may be nil) is asked for again. With --yes or
+// on a prompt timeout the default is taken, or ErrNoDefault or
+// ErrPromptTimeout returned when there is none.
+func Input(question, defaultValue string, validate Validator) (string, error) {
+	if assumeYes {
+		if defaultValue == "" {
+			return "", fmt.Errorf("%s: %w", question, ErrNoDefault)
+		}
+		fmt.Println(i18n.T("prompt.assumed", question, defaultValue))
+		return defaultValue, nil
+	}
+	s := pauseActiveSpinner()
+	defer resumeSpinner(s)
+
+	for {
+		if defaultValue != "" {
+			fmt.Printf("%s [%s]: ", question, defaultValue)
+		} else {
+			fmt.Printf("%s: ", question)
+		}
+		text, err := ReadLine()
+		if useDefault(err) && defaultValue != "" {
+			return defaultValue, nil
+		}
+		answer := strings.TrimSpace(text)
+		if err != nil && answer == "" {
+			return "", err
+		}
+		if answer == "" {
+			answer = defaultValue
+		}
+		if validate != nil {
+			if err := validate(answer); err != nil {
+				PrintError(err.Error())
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+// Select lists options and asks for one, by number or by typing part of it
+// or /regular expression:
+// a search term matching a single option picks it, one matching several
+// lists them to choose from. defaultIndex is taken on Enter, a prompt
+// timeout and with --yes; a negative defaultIndex means there is no default.
+// It returns the index of the option chosen.
+func Select(question string, options []string, defaultIndex int) (int, error) {
+	if len(options) == 0 {
+		return 0, errors.New(i18n.T("prompt.no_options"))
+	}
+	hasDefault := defaultIndex >= 0 && defaultIndex < len(options)
+	if assumeYes {
+		if !hasDefault {
+			return 0, fmt.Errorf("%s: %w", question, ErrNoDefault)
+		}
+		fmt.Println(i18n.T("prompt.assumed", question, options[defaultIndex]))
+		return defaultIndex, nil
+	}
+	s := pauseActiveSpinner()
+	defer resumeSpinner(s)
+
+	if question != "" {
+		PrintSubHeader(question)
+	}
+	printOptions(options, allIndexes(len(options)))
+	for {
+		prompt := i18n.T("prompt.select_hint", len(options))
+		if hasDefault {
+			prompt += fmt.Sprintf(" [%d]", defaultIndex+1)
+		}
+		fmt.Printf("\n%s: ", prompt)
+
+		text, err := ReadLine()
+		if useDefault(err) && hasDefault {
+			return defaultIndex, nil
+		}
+		answer := strings.TrimSpace(text)
+		if err != nil && answer == "" {
+			return 0, err
+		}
+		if answer == "" {
+			if hasDefault {
+				return defaultIndex, nil
+			}
+			continue
+		}
+
+		if n, err := strconv.Atoi(answer); err == nil {
+			if n < 1 || n > len(options) {
+				PrintError(i18n.T("prompt.selection_out_of_range", n))
+				continue
+			}
+			return n - 1, nil
+		}
+		matches, err := findOptions(answer, options)
+		switch {
+		case err != nil:
+			PrintError(err.Error())
+		case len(matches) == 0:
+			PrintError(i18n.T("prompt.no_match", answer))
+		case len(matches) == 1:
+			fmt.Printf("→ %s\n", options[matches[0]])
+			return matches[0], nil
+		default:
+			fmt.Println(i18n.T("prompt.several_matches", answer))
+			printOptions(options, matches)
+		}
+	}
+}
+
+// MultiSelect lists options and asks for any number of them, as a
+// comma-separated list of numbers, ranges such as 5-7, "all" or search terms
+// (a term starting with / is a regular expression);
+// a search term picks every option it matches, and such a selection is shown
+// for confirmation. An empty answer selects nothing. It returns the indexes
+// of the options chosen in list order.
+func MultiSelect(question string, options []string) ([]int, error) {
+	if len(options) == 0 {
+		return nil, errors.New(i18n.T("prompt.no_options"))
+	}
+	if assumeYes {
+		return nil, fmt.Errorf("%s: %w", question, ErrNoDefault)
+	}
+	s := pauseActiveSpinner()
+	defer resumeSpinner(s)
+
+	if question != "" {
+		PrintSubHeader(question)
+	}
+	printOptions(options, allIndexes(len(options)))
+	for {
+		fmt.Printf("\n%s: ", i18n.T("prompt.multi_select_hint"))
+		text, err := ReadLine()
+		answer := strings.TrimSpace(text)
+		if err != nil && answer == "" {
+			if useDefault(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if answer == "" {
+			return nil, nil
+		}
+
+		selected, searched, err := parseMultiSelection(answer, options)
+		if err != nil {
+			PrintError(err.Error())
+			continue
+		}
+		if !searched {
+			return selected, nil
+		}
+		names := make([]string, len(selected))
+		for i, idx := range selected {
+			names[i] = options[idx]
+		}
+		fmt.Println(i18n.T("prompt.selected", len(selected), strings.Join(names, ", ")))
+		if Confirm(i18n.T("prompt.use_selection"), true) {
+			return selected, nil
+		}
+	}
+}
+
+// parseMultiSelection resolves the answer to MultiSelect into sorted option
+// indexes. searched reports whether a search term was used.
+func parseMultiSelection(answer string, options []string) (selected []int, searched bool, err error) {
+	chosen := make(map[int]bool)
+	for _, token := range strings.Split(answer, ",") {
+		token = strings.TrimSpace(token)
+		switch {
+		case token == "":
+			continue
+		case token == "*" || strings.EqualFold(token, "all"):
+			for i := range options {
+				chosen[i] = true
+			}
+			continue
+		}
+		if n, convErr := strconv.Atoi(token); convErr == nil {
+			if n < 1 || n > len(options) {
+				return nil, false, errors.New(i18n.T("prompt.selection_out_of_range", n))
+			}
+			chosen[n-1] = true
+			continue
+		}
+		if m := selectionRange.FindStringSubmatch(token); m != nil {
+			start, _ := strconv.Atoi(m[1])
+			end, _ := strconv.Atoi(m[2])
+			if start < 1 || end > len(options) || start > end {
+				return nil, false, errors.New(i18n.T("prompt.invalid_selection", token))
+			}
+			for n := start; n <= end; n++ {
+				chosen[n-1] = true
+			}
+			continue
+		}
+		matches, err := findOptions(token, options)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(matches) == 0 {
+			return nil, false, errors.New(i18n.T("prompt.no_match", token))
+		}
+		// An option named exactly like the term is not a search
+		if len(matches) > 1 || matchRank(strings.ToLower(token), strings.ToLower(options[matches[0]])) != 0 {
+			searched = true
+		}
+		for _, idx := range matches {
+			chosen[idx] = true
+		}
+	}
+	for idx := range chosen {
+		selected = append(selected, idx)
+	}
+	sort.Ints(selected)
+	return selected, searched, nil
+}
+
+// findOptions returns the indexes of the options matching term: a regular
+// expression for a term starting with /, matched case insensitively, or
+// else a fuzzy search term
+func findOptions(term string, options []string) ([]int, error) {
+	if !strings.HasPrefix(term, "/") {
+		return fuzzyFind(term, options), nil
+	}
+	re, err := regexp.Compile("(?i)" + term[1:])
+	if err != nil {
+		return nil, errors.New(i18n.T("prompt.invalid_regex", term[1:], err))
+	}
+	var matches []int
+	for i, option := range options {
+		if re.MatchString(option) {
+			matches = append(matches, i)
+		}
+	}
+	return matches, nil
+}
+
+// fuzzyFind returns the indexes of the options matching term, case
+// insensitively. Only the best kind of match counts: an option equal to the
+// term, else options starting with it, else options containing it, else
+// options containing its characters in order.
+func fuzzyFind(term string, options []string) []int {
+	term = strings.ToLower(strings.TrimSpace(term))
+	if term == "" {
+		return nil
+	}
+	var best []int
+	bestRank := 4
+	for i, option := range options {
+		rank := matchRank(term, strings.ToLower(option))
+		switch {
+		case rank < 0 || rank > bestRank:
+		case rank < bestRank:
+			best, bestRank = []int{i}, rank
+		default:
+			best = append(best, i)
+		}
+	}
+	return best
+}
+
+// matchRank grades how well option matches term: 0 equal or equal up to
+// details after a space, 1 prefix, 2 substring, 3 subsequence, -1 no match.
+// Both are lower case.
+func matchRank(term, option string) int {
+	switch {
+	case option == term || strings.HasPrefix(option, term+" "):
+		return 0
+	case strings.HasPrefix(option, term):
+		return 1
+	case strings.Contains(option, term):
+		return 2
+	}
+	rest := []rune(term)
+	for _, r := range option {
+		if r == rest[0] {
+			rest = rest[1:]
+			if len(rest) == 0 {
+				return 3
+			}
+		}
+	}
+	return -1
+}
+
+// printOptions prints the numbered options at indexes
+func printOptions(options []string, indexes []int) {
+	for _, i := range indexes {
+		fmt.Printf("   %d. %s\n", i+1, options[i])
+	}
+}
+
+func allIndexes(n int) []int {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
@@ -0,0 +1,145 @@
+package terminal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
+)
+
+// Prompt timeout actions
+const (
+	OnTimeoutDefault = "default" // continue with the prompt's default answer
+	OnTimeoutAbort   = "abort"   // stop the command
+)
+
+// ErrPromptTimeout is returned by ReadLine when no answer arrives within the configured timeout
+var ErrPromptTimeout = errors.New("prompt timed out waiting for input")
+
+// PromptAbort is the panic value a prompt without an error result uses to
+// stop the command when it times out with OnTimeoutAbort. The command
+// middleware recovers it and returns Err, so deferred cleanup still runs.
+type PromptAbort struct {
+	Err error
+}
+
+type lineResult struct {
+	text string
+	err  error
+}
+
+var (
+	promptTimeout   time.Duration
+	promptOnTimeout = OnTimeoutDefault
+
+	stdinMu     sync.Mutex
+	stdinReader = bufio.NewReader(os.Stdin)
+	// pendingLine is non-nil while a stdin read is in flight. A read that outlives
+	// its prompt's timeout is handed to the next prompt instead of being dropped.
+	pendingLine chan lineResult
+)
+
+// ConfigurePrompts sets how long interactive prompts wait for input and what happens
+// on expiry. A timeout of zero waits forever. onTimeout is OnTimeoutDefault or OnTimeoutAbort.
+func ConfigurePrompts(timeout time.Duration, onTimeout string) error {
+	switch strings.ToLower(strings.TrimSpace(onTimeout)) {
+	case "", OnTimeoutDefault:
+		promptOnTimeout = OnTimeoutDefault
+	case OnTimeoutAbort:
+		promptOnTimeout = OnTimeoutAbort
+	default:
+		return fmt.Errorf("invalid prompt timeout action %q (use %s or %s)", onTimeout, OnTimeoutDefault, OnTimeoutAbort)
+	}
+	if timeout < 0 {
+		return fmt.Errorf("prompt timeout must not be negative")
+	}
+	promptTimeout = timeout
+	return nil
+}
+
+// PromptTimeout returns the configured prompt timeout (zero means no timeout)
+func PromptTimeout() time.Duration {
+	return promptTimeout
+}
+
+// ReadLine reads one line from stdin, honoring the configured prompt timeout.
+// The returned text still contains the trailing newline, like bufio.Reader.ReadString.
+// On timeout it returns ErrPromptTimeout. With OnTimeoutAbort prompts do not
+// fall back to their default but return the error or, when they have no error
+// result, unwind the command through PromptAbort.
+func ReadLine() (string, error) {
+	stdinMu.Lock()
+	defer stdinMu.Unlock()
+
+	if pendingLine == nil {
+		ch := make(chan lineResult, 1)
+		pendingLine = ch
+		go func() {
+			text, err := stdinReader.ReadString('\n')
+			ch <- lineResult{text: text, err: err}
+		}()
+	}
+
+	if promptTimeout <= 0 {
+		r := <-pendingLine
+		pendingLine = nil
+		return r.text, r.err
+	}
+
+	select {
+	case r := <-pendingLine:
+		pendingLine = nil
+		return r.text, r.err
+	case <-time.After(promptTimeout):
+		handlePromptTimeout()
+		return "", ErrPromptTimeout
+	}
+}
+
+// readAnswer reads a trimmed answer; a timeout yields an empty answer so callers fall back to their default
+func readAnswer() string {
+	text, err := ReadLine()
+	abortPrompt(err)
+	return strings.TrimSpace(text)
+}
+
+// useDefault reports whether a prompt that got err should take its default
+// answer: it timed out and the timeout action is not OnTimeoutAbort
+func useDefault(err error) bool {
+	return errors.Is(err, ErrPromptTimeout) && promptOnTimeout != OnTimeoutAbort
+}
+
+// abortPrompt stops the command through PromptAbort when err is a timeout
+// that aborts. Prompts that return an error return it instead.
+func abortPrompt(err error) {
+	if errors.Is(err, ErrPromptTimeout) && promptOnTimeout == OnTimeoutAbort {
+		panic(PromptAbort{Err: err})
+	}
+}
+
+// handlePromptTimeout logs the expiry and tells the user what happens next
+func handlePromptTimeout() {
+	fmt.Println()
+	lg, _ := logger.Get()
+
+	if promptOnTimeout == OnTimeoutAbort {
+		if lg != nil {
+			lg.Error("Interactive prompt timed out, aborting",
+				logger.String("timeout", promptTimeout.String()))
+		}
+		PrintError(i18n.T("prompt.timeout_abort", promptTimeout))
+		return
+	}
+
+	if lg != nil {
+		lg.Warn("Interactive prompt timed out, using default answer",
+			logger.String("timeout", promptTimeout.String()))
+	}
+	PrintWarning(i18n.T("prompt.timeout_default", promptTimeout))
+}
@@ -0,0 +1,226 @@
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// keyPoll is how long the reader of Keys waits for input before checking
+// whether the screen was closed
+const keyPoll = 100 * time.Millisecond
+
+// Key is a key press read by a full-screen view
+type Key struct {
+	Name string // one of the Key* names, or empty for a printable rune
+	Rune rune
+}
+
+// Names of the special keys
+const (
+	KeyUp        = "up"
+	KeyDown      = "down"
+	KeyLeft      = "left"
+	KeyRight     = "right"
+	KeyPageUp    = "pgup"
+	KeyPageDown  = "pgdown"
+	KeyHome      = "home"
+	KeyEnd       = "end"
+	KeyTab       = "tab"
+	KeyBackTab   = "backtab"
+	KeyEnter     = "enter"
+	KeyEscape    = "esc"
+	KeyBackspace = "backspace"
+	KeyCtrlA     = "ctrl+a"
+	KeyCtrlC     = "ctrl+c"
+	KeyCtrlU     = "ctrl+u"
+)
+
+// escapeKeys maps the input sequences after ESC to key names
+var escapeKeys = map[string]string{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[5~": KeyPageUp, "[6~": KeyPageDown,
+	"[H": KeyHome, "[F": KeyEnd, "[1~": KeyHome, "[4~": KeyEnd,
+	"[Z": KeyBackTab,
+}
+
+// Screen is a full-screen view on the alternate screen buffer with the
+// terminal in raw mode, as used by dashboards. Close must be called to give
+// the terminal back.
+type Screen struct {
+	state *term.State
+	out   *bufio.Writer
+	stop  chan struct{} // closed by Close to end the reader of Keys
+	done  chan struct{} // closed when the reader of Keys has ended
+}
+
+// CanOpenScreen reports whether stdin and stdout are both terminals, which a
+// full-screen view needs
+func CanOpenScreen() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && StdoutIsTerminal()
+}
+
+// OpenScreen switches to the alternate screen, hides the cursor and puts the
+// terminal in raw mode
+func OpenScreen() (*Screen, error) {
+	if !CanOpenScreen() {
+		return nil, fmt.Errorf("a full-screen view needs an interactive terminal")
+	}
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to put the terminal in raw mode: %w", err)
+	}
+	s := &Screen{state: state, out: bufio.NewWriter(os.Stdout), stop: make(chan struct{})}
+	fmt.Fprint(s.out, "\033[?1049h\033[?25l\033[2J")
+	s.out.Flush()
+	return s, nil
+}
+
+// Close restores the terminal mode, the cursor and the normal screen. It
+// waits for the reader of Keys to end so no later prompt loses input to it.
+func (s *Screen) Close() {
+	close(s.stop)
+	if s.done != nil {
+		<-s.done
+	}
+	fmt.Fprint(s.out, "\033[0m\033[?25h\033[?1049l")
+	s.out.Flush()
+	term.Restore(int(os.Stdin.Fd()), s.state)
+}
+
+// Size returns the current width and height of the screen
+func (s *Screen) Size() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// Draw replaces the screen content with lines. Lines may contain color codes;
+// the caller keeps them within the width.
+func (s *Screen) Draw(lines []string) {
+	fmt.Fprint(s.out, "\033[H")
+	for i, line := range lines {
+		if i > 0 {
+			// Raw mode does not turn \n into \r\n
+			fmt.Fprint(s.out, "\r\n")
+		}
+		fmt.Fprint(s.out, line, "\033[0m\033[K")
+	}
+	fmt.Fprint(s.out, "\033[J")
+	s.out.Flush()
+}
+
+// Keys reads key presses from stdin until the screen is closed. Stdin is
+// polled so the reader notices Close within keyPoll.
+func (s *Screen) Keys() <-chan Key {
+	keys := make(chan Key, 16)
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		defer close(keys)
+		fd := int(os.Stdin.Fd())
+		buf := make([]byte, 64)
+		for {
+			select {
+			case <-s.stop:
+				return
+			default:
+			}
+			fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+			n, err := unix.Poll(fds, int(keyPoll.Milliseconds()))
+			if err == unix.EINTR || (err == nil && n == 0) {
+				continue
+			}
+			if err != nil {
+				return
+			}
+			n, err = unix.Read(fd, buf)
+			if err != nil || n == 0 {
+				return
+			}
+			for _, key := range parseKeys(buf[:n]) {
+				select {
+				case keys <- key:
+				case <-s.stop:
+					return
+				}
+			}
+		}
+	}()
+	return keys
+}
+
+// parseKeys splits one read from a raw terminal into key presses. A lone ESC
+// is the Escape key; ESC followed by a known sequence is a special key.
+func parseKeys(data []byte) []Key {
+	var keys []Key
+	for len(data) > 0 {
+		switch b := data[0]; {
+		case b == 0x1b:
+			if len(data) == 1 {
+				return append(keys, Key{Name: KeyEscape})
+			}
+			matched := false
+			for seq, name := range escapeKeys {
+				if strings.HasPrefix(string(data[1:]), seq) {
+					keys = append(keys, Key{Name: name})
+					data = data[1+len(seq):]
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				// Unknown sequence: drop the rest of the read
+				return append(keys, Key{Name: KeyEscape})
+			}
+		case b == 0x03:
+			keys = append(keys, Key{Name: KeyCtrlC})
+			data = data[1:]
+		case b == 0x01:
+			keys = append(keys, Key{Name: KeyCtrlA})
+			data = data[1:]
+		case b == 0x15:
+			keys = append(keys, Key{Name: KeyCtrlU})
+			data = data[1:]
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, Key{Name: KeyBackspace})
+			data = data[1:]
+		case b == '\t':
+			keys = append(keys, Key{Name: KeyTab})
+			data = data[1:]
+		case b == '\r' || b == '\n':
+			keys = append(keys, Key{Name: KeyEnter})
+			data = data[1:]
+		default:
+			r, size := utf8.DecodeRune(data)
+			keys = append(keys, Key{Rune: r})
+			data = data[size:]
+		}
+	}
+	return keys
+}
+
+// Fit cuts text to width runes, marking the cut with an ellipsis, or pads it
+// with spaces to exactly width. text must not contain color codes.
+func Fit(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(text)
+	if len(runes) > width {
+		if width == 1 {
+			return "…"
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return text + strings.Repeat(" ", width-len(runes))
+}
@@ -0,0 +1,284 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// TableOptions configures RenderTable. The zero value renders like FormatTable.
+type TableOptions struct {
+	SortBy      string // header to sort the rows by, case-insensitive; empty keeps the row order
+	Descending  bool
+	MaxWidth    int  // longer cells are cut to this many characters; 0 for no limit
+	PageSize    int  // rows per page; 0 shows every row
+	Page        int  // 1-based page printed when not interactive
+	Interactive bool // page through the table with the keyboard when stdin and stdout are terminals
+}
+
+// ansiSequence matches the color codes cells may carry
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// quantity matches cells compared by value: numbers, percentages and sizes
+// such as "1,024", "87.5%" or "1.5 GiB"
+var quantity = regexp.MustCompile(`^(-?[\d,]*\.?\d+)\s*(%|[KMGTPE]?i?B)?$`)
+
+// RenderTable prints rows sorted, truncated and paged as opts says. With
+// Interactive and a terminal a table longer than the screen opens in a pager;
+// otherwise page opts.Page of opts.PageSize rows is printed with a page line.
+func RenderTable(headers []string, rows [][]string, opts TableOptions) error {
+	if len(headers) == 0 || len(rows) == 0 {
+		return nil
+	}
+	sortColumn := -1
+	if opts.SortBy != "" {
+		sortColumn = columnIndex(headers, opts.SortBy)
+		if sortColumn < 0 {
+			return fmt.Errorf("unknown sort column %q: expected one of %s", opts.SortBy, strings.Join(headers, ", "))
+		}
+	}
+	rows = sortRows(rows, sortColumn, opts.Descending)
+	if opts.MaxWidth > 0 {
+		rows = truncateCells(rows, opts.MaxWidth)
+	}
+
+	if opts.Interactive && CanOpenScreen() {
+		if _, height, err := GetTerminalSize(); err == nil && len(rows)+4 > height {
+			return pageTable(headers, rows, sortColumn, opts.Descending)
+		}
+	}
+	if opts.PageSize <= 0 || len(rows) <= opts.PageSize {
+		FormatTable(headers, rows)
+		return nil
+	}
+
+	pages := (len(rows) + opts.PageSize - 1) / opts.PageSize
+	page := max(opts.Page, 1)
+	if page > pages {
+		return fmt.Errorf("page %d is out of range: %d rows make %d pages of %d", page, len(rows), pages, opts.PageSize)
+	}
+	first := (page - 1) * opts.PageSize
+	last := min(first+opts.PageSize, len(rows))
+	FormatTable(headers, rows[first:last])
+	fmt.Printf("Page %d of %d, rows %d-%d of %d\n", page, pages, first+1, last, len(rows))
+	return nil
+}
+
+// columnIndex returns the index of the header named name, or -1
+func columnIndex(headers []string, name string) int {
+	for i, h := range headers {
+		if strings.EqualFold(h, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sortRows returns rows stably sorted on column, or rows unchanged for a
+// negative column. The caller's slice is not modified.
+func sortRows(rows [][]string, column int, descending bool) [][]string {
+	sorted := append([][]string(nil), rows...)
+	if column < 0 {
+		return sorted
+	}
+	cell := func(row []string) string {
+		if column < len(row) {
+			return row[column]
+		}
+		return ""
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		c := compareCells(cell(sorted[i]), cell(sorted[j]))
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	})
+	return sorted
+}
+
+// compareCells compares two cells by value when both are quantities, so
+// "9.0 MiB" sorts before "1.2 GiB", and as case-insensitive text otherwise
+func compareCells(a, b string) int {
+	a, b = ansiSequence.ReplaceAllString(a, ""), ansiSequence.ReplaceAllString(b, "")
+	va, okA := cellValue(a)
+	vb, okB := cellValue(b)
+	if okA && okB {
+		switch {
+		case va < vb:
+			return -1
+		case va > vb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// cellValue parses a quantity cell; sizes are converted to bytes
+func cellValue(cell string) (float64, bool) {
+	m := quantity.FindStringSubmatch(strings.TrimSpace(cell))
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	unit := m[2]
+	if unit == "" || unit == "%" || unit == "B" {
+		return v, true
+	}
+	base := 1000.0
+	if strings.Contains(unit, "i") {
+		base = 1024
+	}
+	return v * math.Pow(base, float64(strings.Index("KMGTPE", unit[:1])+1)), true
+}
+
+// truncateCells cuts cells longer than width, keeping the color of colored cells
+func truncateCells(rows [][]string, width int) [][]string {
+	out := make([][]string, len(rows))
+	for i, row := range rows {
+		out[i] = make([]string, len(row))
+		for j, cell := range row {
+			if plain := ansiSequence.ReplaceAllString(cell, ""); len([]rune(plain)) > width {
+				cut := strings.TrimRight(Fit(plain, width), " ")
+				if codes := ansiSequence.FindString(cell); codes != "" {
+					cut = codes + cut + ColorReset
+				}
+				cell = cut
+			}
+			out[i][j] = cell
+		}
+	}
+	return out
+}
+
+// tableLines renders a table like FormatTable into lines
+func tableLines(headers []string, rows [][]string) []string {
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	headerInterface := make([]interface{}, len(headers))
+	for i, v := range headers {
+		headerInterface[i] = v
+	}
+	table.Header(headerInterface...)
+	for _, row := range rows {
+		rowInterface := make([]interface{}, len(row))
+		for i, v := range row {
+			rowInterface[i] = v
+		}
+		table.Append(rowInterface...)
+	}
+	table.Render()
+	return strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+}
+
+// pageTable shows the table full-screen with the header kept on top. The
+// arrow keys, PgUp/PgDn, Home/End and space scroll, left/right pan wide
+// tables, s sorts on the next column, r reverses the order and q, Esc or
+// Ctrl+C close the pager.
+func pageTable(headers []string, rows [][]string, sortColumn int, descending bool) error {
+	screen, err := OpenScreen()
+	if err != nil {
+		return err
+	}
+	defer screen.Close()
+
+	// The top border, header and separator stay in place; the bottom border
+	// scrolls in with the last row
+	const headerLines = 3
+	lines := tableLines(headers, sortRows(rows, sortColumn, descending))
+	top, left := 0, 0
+	keys := screen.Keys()
+	for {
+		width, height := screen.Size()
+		bodyHeight := max(height-headerLines-1, 1)
+		body := lines[min(headerLines, len(lines)):]
+		top = min(max(top, 0), max(len(body)-bodyHeight, 0))
+		left = max(left, 0)
+
+		view := make([]string, 0, height)
+		for _, line := range lines[:min(headerLines, len(lines))] {
+			view = append(view, sliceDisplay(line, left, width))
+		}
+		for _, line := range body[top:min(top+bodyHeight, len(body))] {
+			view = append(view, sliceDisplay(line, left, width))
+		}
+		for len(view) < height-1 {
+			view = append(view, "")
+		}
+		order := "none"
+		if sortColumn >= 0 {
+			order = headers[sortColumn] + " ascending"
+			if descending {
+				order = headers[sortColumn] + " descending"
+			}
+		}
+		status := fmt.Sprintf("rows %d-%d of %d  sort: %s  ↑↓ PgUp/PgDn scroll  ←→ pan  s sort  r reverse  q quit",
+			min(top+1, len(rows)), min(top+bodyHeight, len(rows)), len(rows), order)
+		screen.Draw(append(view, ColorText(Fit(status, width), ColorCyan)))
+
+		key, ok := <-keys
+		if !ok {
+			return nil
+		}
+		switch {
+		case key.Name == KeyCtrlC || key.Name == KeyEscape || key.Rune == 'q':
+			return nil
+		case key.Name == KeyDown || key.Rune == 'j' || key.Name == KeyEnter:
+			top++
+		case key.Name == KeyUp || key.Rune == 'k':
+			top--
+		case key.Name == KeyPageDown || key.Rune == ' ':
+			top += bodyHeight
+		case key.Name == KeyPageUp:
+			top -= bodyHeight
+		case key.Name == KeyHome:
+			top = 0
+		case key.Name == KeyEnd:
+			top = len(body)
+		case key.Name == KeyRight:
+			left += 8
+		case key.Name == KeyLeft:
+			left -= 8
+		case key.Rune == 's':
+			sortColumn = (sortColumn + 1) % len(headers)
+			lines = tableLines(headers, sortRows(rows, sortColumn, descending))
+		case key.Rune == 'r' && sortColumn >= 0:
+			descending = !descending
+			lines = tableLines(headers, sortRows(rows, sortColumn, descending))
+		}
+	}
+}
+
+// sliceDisplay returns the width visible characters of line starting at
+// offset, keeping its color codes
+func sliceDisplay(line string, offset, width int) string {
+	var out strings.Builder
+	pos := 0
+	for i := 0; i < len(line); {
+		if line[i] == 0x1b {
+			if loc := ansiSequence.FindStringIndex(line[i:]); loc != nil && loc[0] == 0 {
+				out.WriteString(line[i : i+loc[1]])
+				i += loc[1]
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if pos >= offset && pos < offset+width {
+			out.WriteRune(r)
+		}
+		pos++
+		i += size
+	}
+	return out.String()
+}
@@ -9,10 +9,16 @@ import (
 	"sync"
 
 	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
 )
 
-// ClearScreen clears the terminal screen using platform-specific commands
+// ClearScreen clears the terminal screen using platform-specific commands.
+// Like the other cursor and screen controls it does nothing when stdout is
+// not a terminal, so logs and pipes get no control sequences.
 func ClearScreen() error {
+	if !interactive {
+		return nil
+	}
 	lg, _ := logger.Get()
 
 	var cmd *exec.Cmd
@@ -38,6 +44,9 @@ func ClearScreen() error {
 
 // ClearScreenANSI clears the terminal screen using ANSI escape sequences
 func ClearScreenANSI() error {
+	if !interactive {
+		return nil
+	}
 	lg, _ := logger.Get()
 
 	// ANSI escape sequence to clear screen and move cursor to top-left
@@ -53,6 +62,9 @@ func ClearScreenANSI() error {
 
 // ClearLines clears the specified number of lines from the current cursor position
 func ClearLines(lines int) error {
+	if !interactive {
+		return nil
+	}
 	lg, _ := logger.Get()
 
 	if lines <= 0 {
@@ -71,6 +83,9 @@ func ClearLines(lines int) error {
 
 // ClearCurrentLine clears the current line and moves cursor to the beginning
 func ClearCurrentLine() error {
+	if !interactive {
+		return nil
+	}
 	lg, _ := logger.Get()
 
 	// Clear current line and move cursor to beginning
@@ -86,6 +101,9 @@ func ClearCurrentLine() error {
 
 // ClearToEndOfLine clears from cursor position to end of line
 func ClearToEndOfLine() error {
+	if !interactive {
+		return nil
+	}
 	lg, _ := logger.Get()
 
 	// Clear from cursor to end of line
@@ -138,6 +156,9 @@ func SafePrint(text string) {
 
 // SafePrintln prints text with newline with spinner coordination
 func SafePrintln(text string) {
+	if printThroughMulti(text) {
+		return
+	}
 	s := pauseActiveSpinner()
 	fmt.Println(text)
 	resumeSpinner(s)
@@ -152,6 +173,9 @@ func SafePrintf(format string, args ...interface{}) {
 
 // MoveCursor moves the cursor to the specified row and column (1-indexed)
 func MoveCursor(row, col int) error {
+	if !interactive {
+		return nil
+	}
 	lg, _ := logger.Get()
 
 	if row < 1 || col < 1 {
@@ -178,6 +202,9 @@ func MoveCursorHome() error {
 
 // SaveCursorPosition saves the current cursor position
 func SaveCursorPosition() error {
+	if !interactive {
+		return nil
+	}
 	lg, _ := logger.Get()
 
 	_, err := fmt.Print("\033[s")
@@ -192,6 +219,9 @@ func SaveCursorPosition() error {
 
 // RestoreCursorPosition restores the previously saved cursor position
 func RestoreCursorPosition() error {
+	if !interactive {
+		return nil
+	}
 	lg, _ := logger.Get()
 
 	_, err := fmt.Print("\033[u")
@@ -206,6 +236,9 @@ func RestoreCursorPosition() error {
 
 // HideCursor hides the terminal cursor
 func HideCursor() error {
+	if !interactive {
+		return nil
+	}
 	lg, _ := logger.Get()
 
 	_, err := fmt.Print("\033[?25l")
@@ -220,6 +253,9 @@ func HideCursor() error {
 
 // ShowCursor shows the terminal cursor
 func ShowCursor() error {
+	if !interactive {
+		return nil
+	}
 	lg, _ := logger.Get()
 
 	_, err := fmt.Print("\033[?25h")
@@ -348,12 +384,14 @@ func PrintDashedSeparator() {
 
 // WaitForEnter waits for the user to press Enter
 func WaitForEnter() {
-	fmt.Print("Press Enter to continue...")
-	fmt.Scanln()
+	fmt.Print(i18n.T("prompt.press_enter"))
+	_, err := ReadLine()
+	abortPrompt(err)
 }
 
 // WaitForEnterWithMessage waits for the user to press Enter with a custom message
 func WaitForEnterWithMessage(message string) {
 	fmt.Print(message)
-	fmt.Scanln()
+	_, err := ReadLine()
+	abortPrompt(err)
 }
@@ -0,0 +1,171 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"sfDBTools/utils/fs/policy"
+
+	"golang.org/x/term"
+)
+
+// transcript tees everything written to stdout and stderr, including the
+// output of child processes, into a plain-text file. ANSI sequences are
+// dropped and lines rewritten with \r (spinners, progress bars) keep only
+// their final state.
+type transcript struct {
+	file           *os.File
+	stdout, stderr *os.File // the real streams, restored by StopTranscript
+	writers        []*os.File
+	wg             sync.WaitGroup
+	mu             sync.Mutex // serializes whole lines from both streams
+}
+
+var active *transcript
+
+// StartTranscript starts copying terminal output to path, appending when the
+// file exists. header is written first, e.g. the command line and start time.
+func StartTranscript(path, header string) error {
+	if active != nil {
+		return nil
+	}
+	if err := policy.MkdirAll(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+	file, err := policy.OpenAppend(path)
+	if err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+	if header != "" {
+		fmt.Fprintln(file, header)
+	}
+
+	t := &transcript{file: file, stdout: os.Stdout, stderr: os.Stderr}
+	stdoutPipe, err := t.tee(os.Stdout)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	stderrPipe, err := t.tee(os.Stderr)
+	if err != nil {
+		t.close()
+		return err
+	}
+	os.Stdout, os.Stderr = stdoutPipe, stderrPipe
+	active = t
+	return nil
+}
+
+// StopTranscript restores the terminal streams, writes footer and closes the
+// transcript. It does nothing when no transcript is active.
+func StopTranscript(footer string) error {
+	t := active
+	if t == nil {
+		return nil
+	}
+	active = nil
+	os.Stdout, os.Stderr = t.stdout, t.stderr
+	t.close()
+	if footer != "" {
+		fmt.Fprintln(t.file, footer)
+	}
+	return t.file.Close()
+}
+
+// StdoutIsTerminal reports whether standard output is a terminal, looking
+// through an active transcript
+func StdoutIsTerminal() bool {
+	out := os.Stdout
+	if active != nil {
+		out = active.stdout
+	}
+	return term.IsTerminal(int(out.Fd()))
+}
+
+// tee returns a pipe whose data goes to dst unchanged and, cleaned up, to the transcript
+func (t *transcript) tee(dst *os.File) (*os.File, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transcript pipe: %w", err)
+	}
+	t.writers = append(t.writers, w)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer r.Close()
+		clean := &lineCleaner{out: t.writeLine}
+		io.Copy(io.MultiWriter(dst, clean), r)
+		clean.flush()
+	}()
+	return w, nil
+}
+
+func (t *transcript) close() {
+	for _, w := range t.writers {
+		w.Close()
+	}
+	t.wg.Wait()
+}
+
+func (t *transcript) writeLine(line []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.file.Write(line)
+}
+
+// lineCleaner turns terminal output into plain lines: ANSI escape sequences
+// are removed and a carriage return starts the line over
+type lineCleaner struct {
+	out    func([]byte)
+	line   []byte
+	cr     bool // a \r was seen; the next character replaces the line
+	escape int  // 0 none, 1 after ESC, 2 inside a CSI sequence
+}
+
+func (c *lineCleaner) Write(p []byte) (int, error) {
+	for _, b := range p {
+		switch c.escape {
+		case 1:
+			c.escape = 0
+			if b == '[' {
+				c.escape = 2
+			}
+			continue
+		case 2:
+			if b >= 0x40 && b <= 0x7e {
+				c.escape = 0
+			}
+			continue
+		}
+		switch b {
+		case 0x1b:
+			c.escape = 1
+		case '\r':
+			c.cr = true
+		case '\n':
+			c.cr = false
+			c.out(append(c.line, '\n'))
+			c.line = c.line[:0]
+		default:
+			if c.cr {
+				c.line = c.line[:0]
+				c.cr = false
+			}
+			if b >= 0x20 || b == '\t' {
+				c.line = append(c.line, b)
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// flush writes a last line that was not terminated by a newline
+func (c *lineCleaner) flush() {
+	if len(c.line) > 0 {
+		c.out(append(c.line, '\n'))
+		c.line = c.line[:0]
+	}
+}
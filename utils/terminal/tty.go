@@ -0,0 +1,42 @@
+package terminal
+
+import "os"
+
+// Output adapts to where stdout goes. On a terminal spinners animate, progress
+// bars redraw in place and messages are colored. When stdout is a file, a pipe
+// or a CI log, spinners print their messages as plain lines, progress bars
+// print a line every progressLineStep percent, cursor and screen control
+// sequences are left out and colors are off. NO_COLOR (https://no-color.org)
+// turns colors off on a terminal too.
+
+// progressLineStep is the percentage between two lines of a progress bar when
+// stdout is not a terminal
+const progressLineStep = 10
+
+// interactive is whether stdout was a terminal at startup
+var interactive bool
+
+func init() {
+	interactive = StdoutIsTerminal()
+	if !interactive || os.Getenv("NO_COLOR") != "" {
+		disableColors()
+	}
+}
+
+// Interactive reports whether stdout is a terminal, so spinners, progress
+// bars and cursor control are drawn
+func Interactive() bool {
+	return interactive
+}
+
+// ColorsEnabled reports whether messages are colored
+func ColorsEnabled() bool {
+	return ColorReset != ""
+}
+
+// disableColors empties the color codes, so every message built from them
+// comes out plain
+func disableColors() {
+	ColorReset, ColorRed, ColorGreen, ColorYellow, ColorBlue = "", "", "", "", ""
+	ColorPurple, ColorCyan, ColorWhite, ColorBold = "", "", "", ""
+}
@@ -57,11 +57,8 @@ func (w *RemovalWizard) GatherRemovalConfig() (*RemovalConfig, error) {
 	// Step 5: Show summary and final confirmation
 	w.showRemovalSummary(config)
 
-	confirmed := AskWithContext(
-		"Proceed with removal using these settings?",
-		"⚡ This will start the removal process - make sure you're ready!",
-		false,
-	)
+	PrintInfo("⚡ This will start the removal process - make sure you're ready!")
+	confirmed := Confirm("Proceed with removal using these settings?", false)
 
 	if !confirmed {
 		return nil, fmt.Errorf("removal cancelled by user")
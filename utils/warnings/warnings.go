@@ -0,0 +1,150 @@
+// Package warnings collects every warning logged during a command, so the
+// ones scrolled past in the middle of a long run are repeated, deduplicated,
+// in a "Warnings (N)" section at its end, in the HTML report and in the JSON
+// output of commands that have one.
+package warnings
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"sfDBTools/internal/logger"
+	"sfDBTools/utils/i18n"
+	"sfDBTools/utils/terminal"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Item is one distinct warning and how often it was raised
+type Item struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+var (
+	mu       sync.Mutex
+	items    []*Item
+	index    = map[string]*Item{}
+	started  bool
+	reported bool
+)
+
+// skippedFields are not shown as context: the caller only matters in the log
+// and the error is appended after the fields
+var skippedFields = map[string]bool{"file": true, "func": true, "error": true}
+
+// hook records warning entries of the logger
+type hook struct{}
+
+func (hook) Levels() []logrus.Level { return []logrus.Level{logrus.WarnLevel} }
+
+func (hook) Fire(entry *logrus.Entry) error {
+	Record(render(entry.Message, entry.Data))
+	return nil
+}
+
+// Start collects the warnings logged from now on. Calling it again has no effect.
+func Start() {
+	mu.Lock()
+	defer mu.Unlock()
+	if started {
+		return
+	}
+	lg, err := logger.Get()
+	if err != nil {
+		return
+	}
+	lg.AddHook(hook{})
+	started = true
+}
+
+// Record adds a warning that was not logged, merging it with an identical one
+func Record(message string) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if item, ok := index[message]; ok {
+		item.Count++
+		return
+	}
+	item := &Item{Message: message, Count: 1}
+	index[message] = item
+	items = append(items, item)
+}
+
+// Items returns the distinct warnings so far in the order first raised
+func Items() []Item {
+	mu.Lock()
+	defer mu.Unlock()
+	list := make([]Item, len(items))
+	for i, item := range items {
+		list[i] = *item
+	}
+	return list
+}
+
+// Take returns the warnings for a command that reports them itself, e.g. in
+// its JSON output, and keeps PrintSummary from printing them again
+func Take() []Item {
+	list := Items()
+	mu.Lock()
+	reported = true
+	mu.Unlock()
+	return list
+}
+
+// PrintSummary prints the "Warnings (N)" section once per run; commands call
+// it before their final success message, the command middleware for all others
+func PrintSummary() {
+	mu.Lock()
+	if reported || len(items) == 0 {
+		reported = true
+		mu.Unlock()
+		return
+	}
+	reported = true
+	mu.Unlock()
+
+	list := Items()
+	fmt.Println()
+	terminal.PrintColoredLine("⚠️  "+i18n.T("warnings.summary", len(list)), terminal.ColorYellow)
+	for i, item := range list {
+		line := fmt.Sprintf("  %d. %s", i+1, item.Message)
+		if item.Count > 1 {
+			line += fmt.Sprintf(" (x%d)", item.Count)
+		}
+		terminal.PrintColoredLine(line, terminal.ColorYellow)
+	}
+	fmt.Println()
+}
+
+// render turns a log entry into one line: the message, the fields naming what
+// it is about, and the error
+func render(message string, data logrus.Fields) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if !skippedFields[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(message)
+	if len(keys) > 0 {
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			parts[i] = fmt.Sprintf("%s=%v", key, data[key])
+		}
+		b.WriteString(" (" + strings.Join(parts, ", ") + ")")
+	}
+	if err, ok := data["error"]; ok && err != nil {
+		b.WriteString(fmt.Sprintf(": %v", err))
+	}
+	return b.String()
+}